@@ -13,6 +13,6 @@ func main() {
 	rootCmd := cmd.NewRootCommand(version)
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
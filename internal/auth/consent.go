@@ -0,0 +1,204 @@
+// internal/auth/consent.go
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// ErrConsentNotFound means the user has not granted the client anything
+var ErrConsentNotFound = errors.New("no consent found for that user and client")
+
+// Consent records the scopes a user has granted a client, so the consent page is only shown
+// again when the client asks for more
+type Consent struct {
+	UserID     string    `json:"user_id"`
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name,omitempty"`
+	Scopes     []string  `json:"scopes"`
+	GrantedAt  time.Time `json:"granted_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func consentKey(userID, clientID string) string {
+
+	return userID + "\x00" + clientID
+}
+
+// SetConsentFile loads the consents kept at path and keeps them there from now on, so that
+// users are not asked again after a restart. An empty path keeps them in memory.
+func (s *AuthorizationServer) SetConsentFile(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consentFile = path
+	if path == "" {
+
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+
+		return nil
+	}
+	if err != nil {
+
+		return fmt.Errorf("failed to read OAuth consents: %w", err)
+	}
+	var saved []*Consent
+	if err := json.Unmarshal(data, &saved); err != nil {
+
+		return fmt.Errorf("failed to parse OAuth consents in %s: %w", path, err)
+	}
+	for _, consent := range saved {
+		s.consents[consentKey(consent.UserID, consent.ClientID)] = consent
+	}
+
+	return nil
+}
+
+// saveConsentsLocked writes the consents to the consent file, if there is one; s.mu is held
+func (s *AuthorizationServer) saveConsentsLocked() error {
+	if s.consentFile == "" {
+
+		return nil
+	}
+	consents := make([]*Consent, 0, len(s.consents))
+	for _, consent := range s.consents {
+		consents = append(consents, consent)
+	}
+	sortConsents(consents)
+	data, err := json.MarshalIndent(consents, "", "  ")
+	if err != nil {
+
+		return fmt.Errorf("failed to encode OAuth consents: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.consentFile), constants.SecretDirMode); err != nil {
+
+		return fmt.Errorf("failed to create OAuth consent directory: %w", err)
+	}
+	// Written beside the file and renamed over it, so a crash never leaves half of it
+	tmp := s.consentFile + ".tmp"
+	if err := os.WriteFile(tmp, data, constants.SecretFileMode); err != nil {
+
+		return fmt.Errorf("failed to save OAuth consents: %w", err)
+	}
+	if err := os.Rename(tmp, s.consentFile); err != nil {
+
+		return fmt.Errorf("failed to save OAuth consents: %w", err)
+	}
+
+	return nil
+}
+
+func sortConsents(consents []*Consent) {
+	sort.Slice(consents, func(i, j int) bool {
+		if consents[i].UserID != consents[j].UserID {
+
+			return consents[i].UserID < consents[j].UserID
+		}
+
+		return consents[i].ClientID < consents[j].ClientID
+	})
+}
+
+// hasConsent reports whether the user has already granted the client every scope requested
+func (s *AuthorizationServer) hasConsent(userID, clientID, scope string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	consent, exists := s.consents[consentKey(userID, clientID)]
+	if !exists {
+
+		return false
+	}
+	for _, requested := range strings.Fields(scope) {
+		if !contains(consent.Scopes, requested) {
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// recordConsent adds the scopes the user just approved to what they granted the client before
+func (s *AuthorizationServer) recordConsent(userID, clientID, scope string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	key := consentKey(userID, clientID)
+	consent, exists := s.consents[key]
+	if !exists {
+		consent = &Consent{UserID: userID, ClientID: clientID, Scopes: []string{}, GrantedAt: now}
+		s.consents[key] = consent
+	}
+	for _, granted := range strings.Fields(scope) {
+		if !contains(consent.Scopes, granted) {
+			consent.Scopes = append(consent.Scopes, granted)
+		}
+	}
+	sort.Strings(consent.Scopes)
+	consent.UpdatedAt = now
+
+	return s.saveConsentsLocked()
+}
+
+// Consents returns the consents users have given, or only those of userID when it is set,
+// ordered by user and client
+func (s *AuthorizationServer) Consents(userID string) []Consent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := make([]*Consent, 0)
+	for _, consent := range s.consents {
+		if userID == "" || consent.UserID == userID {
+			matched = append(matched, consent)
+		}
+	}
+	sortConsents(matched)
+	consents := make([]Consent, 0, len(matched))
+	for _, consent := range matched {
+		listed := *consent
+		listed.Scopes = append([]string{}, consent.Scopes...)
+		if client, exists := s.clients[consent.ClientID]; exists {
+			listed.ClientName = getClientDisplayName(client)
+		}
+		consents = append(consents, listed)
+	}
+
+	return consents
+}
+
+// RevokeConsent withdraws what a user granted a client. The tokens the client holds for the
+// user stop working, and the consent page is shown the next time it asks.
+func (s *AuthorizationServer) RevokeConsent(userID, clientID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := consentKey(userID, clientID)
+	if _, exists := s.consents[key]; !exists {
+
+		return ErrConsentNotFound
+	}
+	delete(s.consents, key)
+	for token, accessToken := range s.accessTokens {
+		if accessToken.ClientID == clientID && accessToken.UserID == userID {
+			delete(s.accessTokens, token)
+		}
+	}
+	for token, refreshToken := range s.refreshTokens {
+		if refreshToken.ClientID == clientID && refreshToken.UserID == userID {
+			delete(s.refreshTokens, token)
+		}
+	}
+	s.logger.Info("Consent of %s for client %s revoked", userID, clientID)
+
+	return s.saveConsentsLocked()
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func TestConsents(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "consents.json")
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	if err := authServer.SetConsentFile(file); err != nil {
+		t.Fatalf("Failed to set consent file: %v", err)
+	}
+
+	if authServer.hasConsent("alice", "client-1", "") {
+		t.Error("Expected no consent before one is given")
+	}
+	if err := authServer.recordConsent("alice", "client-1", "mcp:tools mcp:resources"); err != nil {
+		t.Fatalf("Failed to record consent: %v", err)
+	}
+	if !authServer.hasConsent("alice", "client-1", "mcp:tools") || !authServer.hasConsent("alice", "client-1", "") {
+		t.Error("Expected the granted scopes to be covered")
+	}
+	if authServer.hasConsent("alice", "client-1", "mcp:tools mcp:prompts") {
+		t.Error("Expected a new scope to need consent")
+	}
+	if authServer.hasConsent("bob", "client-1", "mcp:tools") {
+		t.Error("Expected consents to be per user")
+	}
+
+	// Granting more adds to what was granted before
+	if err := authServer.recordConsent("alice", "client-1", "mcp:prompts"); err != nil {
+		t.Fatalf("Failed to record consent: %v", err)
+	}
+	consents := authServer.Consents("alice")
+	if len(consents) != 1 || strings.Join(consents[0].Scopes, " ") != "mcp:prompts mcp:resources mcp:tools" {
+		t.Fatalf("Unexpected consents %+v", consents)
+	}
+
+	// Consents are kept across restarts
+	reloaded := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	if err := reloaded.SetConsentFile(file); err != nil {
+		t.Fatalf("Failed to load consents: %v", err)
+	}
+	if !reloaded.hasConsent("alice", "client-1", "mcp:prompts mcp:tools") {
+		t.Error("Expected the saved consent to be loaded")
+	}
+
+	// Revoking a consent drops the tokens issued under it
+	reloaded.mu.Lock()
+	accessToken, _ := reloaded.generateAccessToken("client-1", "alice", "mcp:tools")
+	refreshToken, _ := reloaded.generateRefreshToken("client-1", "alice", "mcp:tools")
+	otherToken, _ := reloaded.generateAccessToken("client-1", "bob", "mcp:tools")
+	reloaded.mu.Unlock()
+	if err := reloaded.RevokeConsent("alice", "client-1"); err != nil {
+		t.Fatalf("Failed to revoke consent: %v", err)
+	}
+	if _, err := reloaded.ValidateAccessToken(accessToken.Token); err == nil {
+		t.Error("Expected the access token to stop working")
+	}
+	if _, exists := reloaded.refreshTokens[refreshToken.Token]; exists {
+		t.Error("Expected the refresh token to be dropped")
+	}
+	if _, err := reloaded.ValidateAccessToken(otherToken.Token); err != nil {
+		t.Errorf("Expected another user's token to keep working: %v", err)
+	}
+	if err := reloaded.RevokeConsent("alice", "client-1"); err != ErrConsentNotFound {
+		t.Errorf("Expected ErrConsentNotFound, got %v", err)
+	}
+	if err := reloaded.SetConsentFile(file); err != nil || len(reloaded.Consents("")) != 0 {
+		t.Errorf("Expected the revocation to be saved (%v)", err)
+	}
+}
+
+func TestAuthorizeSkipsGrantedConsent(t *testing.T) {
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	if _, err := authServer.RegisterClient(&OAuthConfig{
+		ClientID:      "web",
+		RedirectURIs:  []string{"https://app.example.com/callback"},
+		GrantTypes:    []string{"authorization_code"},
+		ResponseTypes: []string{"code"},
+	}); err != nil {
+		t.Fatalf("Failed to register client: %v", err)
+	}
+	authorize := func(method, scope, prompt string) *httptest.ResponseRecorder {
+		params := url.Values{
+			"response_type": {"code"},
+			"client_id":     {"web"},
+			"redirect_uri":  {"https://app.example.com/callback"},
+			"scope":         {scope},
+		}
+		if prompt != "" {
+			params.Set("prompt", prompt)
+		}
+		var req *http.Request
+		if method == http.MethodGet {
+			req = httptest.NewRequest(method, "/oauth/authorize?"+params.Encode(), nil)
+		} else {
+			params.Set("action", "approve")
+			req = httptest.NewRequest(method, "/oauth/authorize", strings.NewReader(params.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, "alice"))
+		recorder := httptest.NewRecorder()
+		authServer.HandleAuthorize(recorder, req)
+
+		return recorder
+	}
+
+	if recorder := authorize(http.MethodGet, "mcp:tools", ""); recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the consent page, got %d", recorder.Code)
+	}
+	if recorder := authorize(http.MethodPost, "mcp:tools", ""); recorder.Code != http.StatusFound {
+		t.Fatalf("Expected approval to redirect, got %d", recorder.Code)
+	}
+
+	recorder := authorize(http.MethodGet, "mcp:tools", "")
+	if recorder.Code != http.StatusFound || !strings.Contains(recorder.Header().Get("Location"), "code=") {
+		t.Fatalf("Expected a granted scope to redirect with a code, got %d", recorder.Code)
+	}
+	if recorder := authorize(http.MethodGet, "mcp:tools mcp:prompts", ""); recorder.Code != http.StatusOK {
+		t.Errorf("Expected a new scope to show the consent page, got %d", recorder.Code)
+	}
+	if recorder := authorize(http.MethodGet, "mcp:tools", "consent"); recorder.Code != http.StatusOK {
+		t.Errorf("Expected prompt=consent to show the consent page, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func TestSecurePage(t *testing.T) {
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	served := ""
+	page := authServer.SecurePage(func(w http.ResponseWriter, r *http.Request) {
+		served = csrfToken(r)
+	})
+
+	recorder := httptest.NewRecorder()
+	page(recorder, httptest.NewRequest(http.MethodGet, "https://auth.mcp-compose.local/oauth/authorize", nil))
+	var cookie *http.Cookie
+	for _, c := range recorder.Result().Cookies() {
+		if c.Name == "mcp_compose_oauth_csrf" {
+			cookie = c
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly || !cookie.Secure || cookie.Value == "" || served != cookie.Value {
+		t.Fatalf("Expected the page to be served with the token of a new CSRF cookie, got %+v and %q", cookie, served)
+	}
+	for header, expected := range map[string]string{
+		"X-Frame-Options":           "DENY",
+		"X-Content-Type-Options":    "nosniff",
+		"Strict-Transport-Security": "max-age=31536000",
+		"Cache-Control":             "no-store",
+	} {
+		if got := recorder.Header().Get(header); got != expected {
+			t.Errorf("Expected %s: %s, got %q", header, expected, got)
+		}
+	}
+	if !strings.Contains(recorder.Header().Get("Content-Security-Policy"), "frame-ancestors 'none'") {
+		t.Errorf("Expected a content security policy, got %q", recorder.Header().Get("Content-Security-Policy"))
+	}
+
+	post := func(token string) int {
+		served = ""
+		req := httptest.NewRequest(http.MethodPost, "/oauth/authorize", strings.NewReader(url.Values{"csrf_token": {token}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		recorder := httptest.NewRecorder()
+		page(recorder, req)
+
+		return recorder.Code
+	}
+	if code := post(""); code != http.StatusForbidden || served != "" {
+		t.Errorf("Expected a form without the token to be refused, got %d", code)
+	}
+	if code := post("forged"); code != http.StatusForbidden || served != "" {
+		t.Errorf("Expected a form with another token to be refused, got %d", code)
+	}
+	if code := post(cookie.Value); code != http.StatusOK || served != cookie.Value {
+		t.Errorf("Expected a form with the browser's token to be served, got %d", code)
+	}
+}
+
+func TestConsentPageCarriesCSRFToken(t *testing.T) {
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	if _, err := authServer.RegisterClient(&OAuthConfig{
+		ClientID:      "web",
+		ClientName:    `Notes</strong><form action="https://evil.example.com"><input name="password">`,
+		RedirectURIs:  []string{"https://app.example.com/callback"},
+		GrantTypes:    []string{"authorization_code"},
+		ResponseTypes: []string{"code"},
+	}); err != nil {
+		t.Fatalf("Failed to register client: %v", err)
+	}
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {"web"},
+		"redirect_uri":  {"https://app.example.com/callback"},
+		"state":         {`"><script>alert(1)</script>`},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+params.Encode(), nil)
+	req.AddCookie(&http.Cookie{Name: "mcp_compose_oauth_csrf", Value: "browser-token"})
+	recorder := httptest.NewRecorder()
+	authServer.SecurePage(authServer.HandleAuthorize)(recorder, req)
+
+	body := recorder.Body.String()
+	if !strings.Contains(body, `name="csrf_token" value="browser-token"`) {
+		t.Errorf("Expected the consent form to carry the browser's token")
+	}
+	if strings.Contains(body, "<script>") {
+		t.Errorf("Expected the request parameters to be escaped")
+	}
+	if strings.Contains(body, "evil.example.com\"") || strings.Count(body, "<form") != 1 {
+		t.Errorf("Expected the client name to be escaped")
+	}
+}
@@ -0,0 +1,472 @@
+// internal/auth/device.go
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// DeviceCodeGrantType is the grant type of the device authorization grant (RFC 8628), with
+// which clients that can't take a browser redirect obtain tokens by showing a user code
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// ErrDeviceAuthorizationNotFound means no pending device authorization has the user code
+var ErrDeviceAuthorizationNotFound = errors.New("no pending device sign-in has that code")
+
+// SetDeviceVerificationURI sets where users are sent to approve device sign-ins, such as the
+// dashboard. By default it is the issuer's /oauth/device page.
+func (s *AuthorizationServer) SetDeviceVerificationURI(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deviceVerifyURI = uri
+}
+
+func (s *AuthorizationServer) deviceVerificationURI() string {
+	if s.deviceVerifyURI != "" {
+
+		return s.deviceVerifyURI
+	}
+
+	return strings.TrimRight(s.config.Issuer, "/") + constants.DeviceVerificationPath
+}
+
+// NormalizeUserCode returns a user code as it is stored, so users may type it in lower case
+// and without the dash
+func NormalizeUserCode(code string) string {
+
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+
+			return -1
+		}
+
+		return r
+	}, strings.ToUpper(code))
+}
+
+// FormatUserCode returns a user code as it is shown: in groups of four separated by dashes
+func FormatUserCode(code string) string {
+	if len(code) <= constants.DeviceUserCodeGroupSize {
+
+		return code
+	}
+
+	return code[:constants.DeviceUserCodeGroupSize] + "-" + FormatUserCode(code[constants.DeviceUserCodeGroupSize:])
+}
+
+// HandleDeviceAuthorization starts a device sign-in: the client gets a device code to poll the
+// token endpoint with and a user code for the user to approve at the verification URI
+func (s *AuthorizationServer) HandleDeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.sendTokenError(w, "invalid_request", "Failed to parse request")
+
+		return
+	}
+
+	clientID := r.Form.Get("client_id")
+	clientSecret := r.Form.Get("client_secret")
+	if clientID == "" || clientSecret == "" {
+		if username, password, ok := r.BasicAuth(); ok {
+			clientID = username
+			clientSecret = password
+		}
+	}
+	client, err := s.authenticateClient(r, clientID, clientSecret)
+	if err != nil {
+		s.sendClientError(w, err)
+
+		return
+	}
+	if !contains(client.GrantTypes, DeviceCodeGrantType) {
+		s.sendTokenError(w, "unauthorized_client", "Device authorization grant not allowed for this client")
+
+		return
+	}
+	scope := r.Form.Get("scope")
+	if scope != "" && !s.validateScope(scope) {
+		s.sendTokenError(w, "invalid_scope", "Invalid scope")
+
+		return
+	}
+
+	deviceCode, err := s.tokenGenerator.GenerateDeviceCode()
+	if err != nil {
+		s.sendTokenError(w, "server_error", "Failed to generate device code")
+
+		return
+	}
+
+	s.mu.Lock()
+	// A user code is short, so make sure no pending sign-in has it already
+	var userCode string
+	for userCode == "" || s.deviceByUserCode(userCode) != nil {
+		if userCode, err = s.tokenGenerator.GenerateUserCode(); err != nil {
+			s.mu.Unlock()
+			s.sendTokenError(w, "server_error", "Failed to generate user code")
+
+			return
+		}
+	}
+	now := time.Now()
+	device := &DeviceCode{
+		DeviceCode:      deviceCode,
+		UserCode:        userCode,
+		VerificationURI: s.deviceVerificationURI(),
+		ExpiresAt:       now.Add(constants.DeviceCodeTTL),
+		Interval:        int(constants.DevicePollInterval.Seconds()),
+		ClientID:        client.ID,
+		Scope:           scope,
+		CreatedAt:       now,
+	}
+	s.deviceCodes[deviceCode] = device
+	s.mu.Unlock()
+	s.logger.Info("Device sign-in %s started by client %s", FormatUserCode(userCode), client.ID)
+
+	complete, _ := url.Parse(device.VerificationURI)
+	query := complete.Query()
+	query.Set("user_code", FormatUserCode(userCode))
+	complete.RawQuery = query.Encode()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"device_code":               deviceCode,
+		"user_code":                 FormatUserCode(userCode),
+		"verification_uri":          device.VerificationURI,
+		"verification_uri_complete": complete.String(),
+		"expires_in":                int(constants.DeviceCodeTTL.Seconds()),
+		"interval":                  device.Interval,
+	})
+}
+
+// handleDeviceCodeGrant answers a client polling for the tokens of a device sign-in: pending
+// until the user decides, then the tokens or access_denied
+func (s *AuthorizationServer) handleDeviceCodeGrant(w http.ResponseWriter, r *http.Request) {
+	clientID := r.Form.Get("client_id")
+	clientSecret := r.Form.Get("client_secret")
+	if clientID == "" || clientSecret == "" {
+		if username, password, ok := r.BasicAuth(); ok {
+			clientID = username
+			clientSecret = password
+		}
+	}
+	client, err := s.authenticateClient(r, clientID, clientSecret)
+	if err != nil {
+		s.sendClientError(w, err)
+
+		return
+	}
+
+	code := r.Form.Get("device_code")
+	s.mu.Lock()
+	device, exists := s.deviceCodes[code]
+	if !exists || device.ClientID != client.ID {
+		s.mu.Unlock()
+		s.sendTokenError(w, "invalid_grant", "Invalid device code")
+
+		return
+	}
+	now := time.Now()
+	switch {
+	case now.After(device.ExpiresAt):
+		delete(s.deviceCodes, code)
+		s.mu.Unlock()
+		s.sendTokenError(w, "expired_token", "The device code has expired")
+
+		return
+	case device.Denied:
+		delete(s.deviceCodes, code)
+		s.mu.Unlock()
+		s.sendTokenError(w, "access_denied", "The user denied the request")
+
+		return
+	case !device.Authorized:
+		// Clients polling faster than the interval are told to slow down, for good
+		tooSoon := now.Sub(device.lastPolled) < time.Duration(device.Interval)*time.Second
+		device.lastPolled = now
+		if tooSoon {
+			device.Interval += int(constants.DevicePollInterval.Seconds())
+			s.mu.Unlock()
+			s.sendTokenError(w, "slow_down", fmt.Sprintf("Poll every %d seconds", device.Interval))
+
+			return
+		}
+		s.mu.Unlock()
+		s.sendTokenError(w, "authorization_pending", "The user has not approved the request yet")
+
+		return
+	}
+
+	accessToken, err := s.generateAccessToken(client.ID, device.UserID, device.Scope)
+	if err != nil {
+		s.mu.Unlock()
+		s.sendTokenError(w, "server_error", "Failed to generate access token")
+
+		return
+	}
+	var refreshToken *RefreshToken
+	if contains(client.GrantTypes, "refresh_token") {
+		if refreshToken, err = s.generateRefreshToken(client.ID, device.UserID, device.Scope); err != nil {
+			s.mu.Unlock()
+			s.sendTokenError(w, "server_error", "Failed to generate refresh token")
+
+			return
+		}
+	}
+	// The device code is used once
+	delete(s.deviceCodes, code)
+	s.mu.Unlock()
+
+	response := map[string]interface{}{
+		"access_token": accessToken.Token,
+		"token_type":   "Bearer",
+		"expires_in":   int(s.tokenLifetime.Seconds()),
+	}
+	if refreshToken != nil {
+		response["refresh_token"] = refreshToken.Token
+	}
+	if device.Scope != "" {
+		response["scope"] = device.Scope
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode token response: %v", err)
+	}
+}
+
+// deviceByUserCode returns the pending device sign-in with a user code; s.mu is held
+func (s *AuthorizationServer) deviceByUserCode(userCode string) *DeviceCode {
+	userCode = NormalizeUserCode(userCode)
+	now := time.Now()
+	for _, device := range s.deviceCodes {
+		if device.UserCode == userCode && !device.Authorized && !device.Denied && now.Before(device.ExpiresAt) {
+
+			return device
+		}
+	}
+
+	return nil
+}
+
+// PendingDeviceAuthorizations returns the device sign-ins waiting for a user, oldest first.
+// Device codes are left out: only the client may know them.
+func (s *AuthorizationServer) PendingDeviceAuthorizations() []DeviceCode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	pending := make([]DeviceCode, 0)
+	for _, device := range s.deviceCodes {
+		if !device.Authorized && !device.Denied && now.Before(device.ExpiresAt) {
+			listed := *device
+			listed.DeviceCode = ""
+			listed.UserCode = FormatUserCode(device.UserCode)
+			pending = append(pending, listed)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+
+	return pending
+}
+
+// PendingDeviceAuthorization returns the pending device sign-in with a user code
+func (s *AuthorizationServer) PendingDeviceAuthorization(userCode string) (DeviceCode, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	device := s.deviceByUserCode(userCode)
+	if device == nil {
+
+		return DeviceCode{}, false
+	}
+	listed := *device
+	listed.DeviceCode = ""
+	listed.UserCode = FormatUserCode(device.UserCode)
+
+	return listed, true
+}
+
+// DecideDeviceAuthorization approves a pending device sign-in on behalf of userID, who the
+// tokens the client then gets act for, or denies it
+func (s *AuthorizationServer) DecideDeviceAuthorization(userCode, userID string, approve bool) error {
+	s.mu.Lock()
+	device := s.deviceByUserCode(userCode)
+	if device == nil {
+		s.mu.Unlock()
+
+		return ErrDeviceAuthorizationNotFound
+	}
+	if approve {
+		device.Authorized = true
+		device.UserID = userID
+	} else {
+		device.Denied = true
+	}
+	clientID := device.ClientID
+	s.mu.Unlock()
+
+	if approve {
+		s.logger.Info("Device sign-in %s of client %s approved for %s", FormatUserCode(userCode), clientID, userID)
+	} else {
+		s.logger.Info("Device sign-in %s of client %s denied by %s", FormatUserCode(userCode), clientID, userID)
+	}
+
+	return nil
+}
+
+// DevicePage is what the device verification page shows
+type DevicePage struct {
+	UserCode  string
+	Device    *DeviceCode
+	Client    string
+	Scopes    []string
+	User      string // who approves; empty when the caller isn't signed in
+	CSRFToken string
+	Message   string
+	Error     string
+}
+
+var devicePageTemplate = template.Must(template.New("device").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Device Sign-in</title>
+    <style>
+        body { font-family: Arial, sans-serif; max-width: 600px; margin: 50px auto; padding: 20px; }
+        .auth-box { border: 1px solid #ddd; padding: 20px; border-radius: 5px; background: #f9f9f9; }
+        .client-info { background: #e7f3ff; padding: 10px; margin: 10px 0; border-radius: 3px; }
+        .error { background: #f8d7da; padding: 10px; margin: 10px 0; border-radius: 3px; }
+        .message { background: #d4edda; padding: 10px; margin: 10px 0; border-radius: 3px; }
+        input { padding: 10px; font-size: 20px; letter-spacing: 3px; text-transform: uppercase; width: 200px; }
+        button { padding: 10px 20px; margin: 5px; border: none; border-radius: 3px; cursor: pointer; font-size: 16px; }
+        .approve { background: #28a745; color: white; }
+        .deny { background: #dc3545; color: white; }
+    </style>
+</head>
+<body>
+    <div class="auth-box">
+        <h2>Device Sign-in</h2>
+        {{if .Error}}<div class="error">{{.Error}}</div>{{end}}
+        {{if .Message}}<div class="message">{{.Message}}</div>
+        {{else if .Device}}
+        <div class="client-info">
+            <strong>Application:</strong> {{.Client}}<br>
+            <strong>Code:</strong> {{.UserCode}}
+        </div>
+        <p><strong>Requested permissions:</strong> {{if .Scopes}}{{range .Scopes}}<br>&bull; {{.}}{{end}}{{else}}none in particular{{end}}</p>
+        <p>Only approve if the same code is shown on the device you are signing in.</p>
+        {{if .User}}
+        <form method="POST">
+            <input type="hidden" name="user_code" value="{{.UserCode}}">
+            <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+            <p>Signed in as <strong>{{.User}}</strong>.</p>
+            <button type="submit" name="action" value="approve" class="approve">Approve</button>
+            <button type="submit" name="action" value="deny" class="deny">Deny</button>
+        </form>
+        {{else}}
+        <p>Approve it on the OAuth page of the mcp-compose dashboard, where you are signed in.</p>
+        {{end}}
+        {{else}}
+        <form method="GET">
+            <p>Enter the code shown on your device:</p>
+            <input name="user_code" value="{{.UserCode}}" autocomplete="off" autofocus>
+            <button type="submit" class="approve">Continue</button>
+        </form>
+        {{end}}
+    </div>
+</body>
+</html>`))
+
+// HandleDeviceVerification serves the page users approve device sign-ins at. user is who the
+// caller was authenticated as; without one the page only shows the request, to be approved in
+// the dashboard.
+func (s *AuthorizationServer) HandleDeviceVerification(w http.ResponseWriter, r *http.Request, user string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+
+		return
+	}
+
+	page := DevicePage{UserCode: strings.TrimSpace(r.Form.Get("user_code")), User: user, CSRFToken: csrfToken(r)}
+	status := http.StatusOK
+	if page.UserCode != "" {
+		device, found := s.PendingDeviceAuthorization(page.UserCode)
+		if found {
+			page.Device = &device
+			page.UserCode = device.UserCode
+			page.Client = device.ClientID
+			if client, exists := s.GetClient(device.ClientID); exists {
+				page.Client = getClientDisplayName(client)
+			}
+			page.Scopes = strings.Fields(device.Scope)
+		} else {
+			page.Error = "That code is not valid or has expired. Check the code on your device."
+			status = http.StatusNotFound
+		}
+	}
+
+	if r.Method == http.MethodPost && page.Device != nil {
+		switch {
+		case user == "":
+			page.Error = "You must be signed in to approve a device."
+			status = http.StatusForbidden
+		case !sameOrigin(r):
+			page.Error = "The request did not come from this page."
+			status = http.StatusForbidden
+		default:
+			approve := r.Form.Get("action") == "approve"
+			if err := s.DecideDeviceAuthorization(page.UserCode, user, approve); err != nil {
+				page.Error = err.Error()
+				status = http.StatusConflict
+			} else if approve {
+				page.Message = "Device approved. You can return to your device."
+			} else {
+				page.Message = "Device sign-in denied."
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	if err := devicePageTemplate.Execute(w, page); err != nil {
+		s.logger.Error("Failed to render device verification page: %v", err)
+	}
+}
+
+// sameOrigin reports whether a form was posted from a page of this host, so another site
+// can't make a signed-in browser approve a device
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+
+		return r.Header.Get("Sec-Fetch-Site") == "" || r.Header.Get("Sec-Fetch-Site") == "same-origin"
+	}
+	parsed, err := url.Parse(origin)
+
+	return err == nil && parsed.Host == r.Host
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func postDeviceForm(t *testing.T, handler http.HandlerFunc, path string, form url.Values) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	handler(recorder, req)
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid response %q: %v", recorder.Body.String(), err)
+	}
+
+	return recorder.Code, body
+}
+
+func TestDeviceAuthorizationGrant(t *testing.T) {
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	if _, err := authServer.RegisterClient(&OAuthConfig{
+		ClientID:     "cli",
+		ClientSecret: "cli-secret",
+		GrantTypes:   []string{DeviceCodeGrantType, "refresh_token"},
+	}); err != nil {
+		t.Fatalf("Failed to register client: %v", err)
+	}
+	if _, err := authServer.RegisterClient(&OAuthConfig{ClientID: "web", ClientSecret: "web-secret", GrantTypes: []string{"authorization_code"}}); err != nil {
+		t.Fatalf("Failed to register client: %v", err)
+	}
+	credentials := url.Values{"client_id": {"cli"}, "client_secret": {"cli-secret"}}
+
+	start := func(t *testing.T) (string, string) {
+		t.Helper()
+		form := url.Values{"client_id": {"cli"}, "client_secret": {"cli-secret"}, "scope": {"mcp:tools"}}
+		status, body := postDeviceForm(t, authServer.HandleDeviceAuthorization, "/oauth/device_authorization", form)
+		if status != http.StatusOK {
+			t.Fatalf("Expected the device authorization to start, got %d: %v", status, body)
+		}
+		if body["verification_uri"] != "https://auth.mcp-compose.local/oauth/device" {
+			t.Errorf("Unexpected verification URI %v", body["verification_uri"])
+		}
+		userCode := body["user_code"].(string)
+		if !strings.Contains(body["verification_uri_complete"].(string), "user_code="+userCode) {
+			t.Errorf("Expected the complete URI to carry the code, got %v", body["verification_uri_complete"])
+		}
+
+		return body["device_code"].(string), userCode
+	}
+	poll := func(t *testing.T, deviceCode string) (int, map[string]interface{}) {
+		t.Helper()
+		form := url.Values{"grant_type": {DeviceCodeGrantType}, "device_code": {deviceCode}}
+		for name, values := range credentials {
+			form[name] = values
+		}
+
+		return postDeviceForm(t, authServer.HandleToken, "/oauth/token", form)
+	}
+
+	t.Run("unauthorized_client", func(t *testing.T) {
+		form := url.Values{"client_id": {"web"}, "client_secret": {"web-secret"}}
+		if _, body := postDeviceForm(t, authServer.HandleDeviceAuthorization, "/oauth/device_authorization", form); body["error"] != "unauthorized_client" {
+			t.Errorf("Expected unauthorized_client, got %v", body)
+		}
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		deviceCode, userCode := start(t)
+		if _, body := poll(t, deviceCode); body["error"] != "authorization_pending" {
+			t.Fatalf("Expected authorization_pending, got %v", body)
+		}
+		if _, body := poll(t, deviceCode); body["error"] != "slow_down" {
+			t.Fatalf("Expected slow_down when polling too fast, got %v", body)
+		}
+
+		pending := authServer.PendingDeviceAuthorizations()
+		if len(pending) != 1 || pending[0].UserCode != userCode || pending[0].DeviceCode != "" {
+			t.Fatalf("Expected the sign-in listed without its device code, got %+v", pending)
+		}
+		// Users may type the code in lower case and without the dash
+		if err := authServer.DecideDeviceAuthorization(strings.ToLower(strings.ReplaceAll(userCode, "-", "")), "alice", true); err != nil {
+			t.Fatalf("Failed to approve: %v", err)
+		}
+		if len(authServer.PendingDeviceAuthorizations()) != 0 {
+			t.Error("Expected no pending sign-ins once approved")
+		}
+
+		status, body := poll(t, deviceCode)
+		if status != http.StatusOK || body["access_token"] == nil || body["refresh_token"] == nil {
+			t.Fatalf("Expected tokens, got %d: %v", status, body)
+		}
+		token, err := authServer.ValidateAccessToken(body["access_token"].(string))
+		if err != nil || token.UserID != "alice" || token.Scope != "mcp:tools" {
+			t.Errorf("Expected a token for alice with mcp:tools, got %+v (%v)", token, err)
+		}
+		// The device code is used once
+		if _, body := poll(t, deviceCode); body["error"] != "invalid_grant" {
+			t.Errorf("Expected invalid_grant for a used device code, got %v", body)
+		}
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		deviceCode, userCode := start(t)
+		if err := authServer.DecideDeviceAuthorization(userCode, "alice", false); err != nil {
+			t.Fatalf("Failed to deny: %v", err)
+		}
+		if _, body := poll(t, deviceCode); body["error"] != "access_denied" {
+			t.Errorf("Expected access_denied, got %v", body)
+		}
+		if err := authServer.DecideDeviceAuthorization(userCode, "alice", true); err != ErrDeviceAuthorizationNotFound {
+			t.Errorf("Expected a decided sign-in to be gone, got %v", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		deviceCode, _ := start(t)
+		authServer.mu.Lock()
+		authServer.deviceCodes[deviceCode].ExpiresAt = time.Now().Add(-time.Second)
+		authServer.mu.Unlock()
+		if _, body := poll(t, deviceCode); body["error"] != "expired_token" {
+			t.Errorf("Expected expired_token, got %v", body)
+		}
+	})
+}
+
+func TestUserCodeFormat(t *testing.T) {
+	if code := FormatUserCode("ABCDEFGH"); code != "ABCD-EFGH" {
+		t.Errorf("Expected ABCD-EFGH, got %s", code)
+	}
+	if code := NormalizeUserCode("abcd-efgh "); code != "ABCDEFGH" {
+		t.Errorf("Expected ABCDEFGH, got %s", code)
+	}
+}
@@ -4,6 +4,7 @@ package auth
 import (
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"net/http"
 	"net/url"
 	"strings"
@@ -11,13 +12,8 @@ import (
 )
 
 func (s *AuthorizationServer) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
-	// Enable CORS for oauth endpoints
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
+	// CORS headers only for the origins the config allows
+	if s.corsPolicy().Handle(w, r) {
 
 		return
 	}
@@ -70,10 +66,29 @@ func (s *AuthorizationServer) HandleAuthorize(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Handle GET request - show authorization page
+	// The user signs in first, and may only grant the scopes their role allows
+	userID, signedIn := s.signIn(w, r, authReq, client)
+	if !signedIn {
+
+		return
+	}
+	if denied := s.userLogin.deniedScopes(userID, authReq.Scope); len(denied) > 0 {
+		s.logger.Warning("User %s may not grant client %s scope '%s'", userID, authReq.ClientID, strings.Join(denied, " "))
+		s.redirectWithError(w, r, authReq.RedirectURI, "access_denied", "Your role does not allow "+strings.Join(denied, " "), authReq.State)
+
+		return
+	}
+
+	// Handle GET request - show authorization page, unless the user granted these scopes before
 	if r.Method == http.MethodGet {
+		if authReq.Prompt != "consent" && s.hasConsent(userID, client.ID, authReq.Scope) {
+			s.logger.Info("Client %s already has the consent of %s for scope '%s'", authReq.ClientID, userID, authReq.Scope)
+			s.issueAuthorizationCode(w, r, authReq, client, userID)
+
+			return
+		}
 		s.logger.Info("Showing authorization page for client: %s", authReq.ClientID)
-		s.showAutoApprovalPage(w, r, authReq, client)
+		s.showAutoApprovalPage(w, r, authReq, client, userID)
 
 		return
 	}
@@ -81,15 +96,23 @@ func (s *AuthorizationServer) HandleAuthorize(w http.ResponseWriter, r *http.Req
 	// Handle POST request - process authorization
 	if r.Method == http.MethodPost {
 		s.logger.Info("Processing authorization POST for client: %s", authReq.ClientID)
-		s.processAuthorization(w, r, authReq, client)
+		s.processAuthorization(w, r, authReq, client, userID)
 
 		return
 	}
 }
 
-func (s *AuthorizationServer) showAutoApprovalPage(w http.ResponseWriter, _ *http.Request, authReq *AuthorizationRequest, client *OAuthClient) {
-	html := fmt.Sprintf(`
-<!DOCTYPE html>
+// ConsentPage is what the consent page shows
+type ConsentPage struct {
+	Client    string
+	ClientID  string
+	Scopes    []string
+	User      string
+	CSRFToken string
+	Request   *AuthorizationRequest
+}
+
+var consentPageTemplate = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
 <html>
 <head>
     <title>Authorization Request</title>
@@ -108,22 +131,23 @@ func (s *AuthorizationServer) showAutoApprovalPage(w http.ResponseWriter, _ *htt
     <div class="auth-box">
         <h2>Authorization Request</h2>
         <div class="client-info">
-            <strong>Application:</strong> %s<br>
-            <strong>Client ID:</strong> %s
+            <strong>Application:</strong> {{.Client}}<br>
+            <strong>Client ID:</strong> {{.ClientID}}
         </div>
         <div class="scope-list">
-            <strong>Requested Permissions:</strong><br>
-            %s
+            <strong>Requested Permissions:</strong>
+            {{range .Scopes}}<br>&bull; {{.}}{{else}}<br>No specific permissions requested{{end}}
         </div>
-        <p>Do you want to authorize this application?</p>
+        <p>Signed in as <strong>{{.User}}</strong>. Do you want to authorize this application?</p>
         <form method="POST" action="/oauth/authorize">
-            <input type="hidden" name="client_id" value="%s">
-            <input type="hidden" name="redirect_uri" value="%s">
-            <input type="hidden" name="response_type" value="%s">
-            <input type="hidden" name="scope" value="%s">
-            <input type="hidden" name="state" value="%s">
-            <input type="hidden" name="code_challenge" value="%s">
-            <input type="hidden" name="code_challenge_method" value="%s">
+            <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+            <input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+            <input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+            <input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+            <input type="hidden" name="scope" value="{{.Request.Scope}}">
+            <input type="hidden" name="state" value="{{.Request.State}}">
+            <input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+            <input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
             <div class="buttons">
                 <button type="submit" name="action" value="approve" class="approve">Approve</button>
                 <button type="submit" name="action" value="deny" class="deny">Deny</button>
@@ -131,26 +155,25 @@ func (s *AuthorizationServer) showAutoApprovalPage(w http.ResponseWriter, _ *htt
         </form>
     </div>
 </body>
-</html>`,
-		getClientDisplayName(client),
-		client.ID,
-		formatScopes(authReq.Scope),
-		authReq.ClientID,
-		authReq.RedirectURI,
-		authReq.ResponseType,
-		authReq.Scope,
-		authReq.State,
-		authReq.CodeChallenge,
-		authReq.CodeChallengeMethod,
-	)
+</html>`))
+
+func (s *AuthorizationServer) showAutoApprovalPage(w http.ResponseWriter, r *http.Request, authReq *AuthorizationRequest, client *OAuthClient, userID string) {
+	page := ConsentPage{
+		Client:    getClientDisplayName(client),
+		ClientID:  client.ID,
+		Scopes:    formatScopes(authReq.Scope),
+		User:      userID,
+		CSRFToken: csrfToken(r),
+		Request:   authReq,
+	}
 
-	w.Header().Set("Content-Type", "text/html")
-	if _, err := w.Write([]byte(html)); err != nil {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := consentPageTemplate.Execute(w, page); err != nil {
 		s.logger.Error("Failed to write authorization form: %v", err)
 	}
 }
 
-func (s *AuthorizationServer) processAuthorization(w http.ResponseWriter, r *http.Request, authReq *AuthorizationRequest, client *OAuthClient) {
+func (s *AuthorizationServer) processAuthorization(w http.ResponseWriter, r *http.Request, authReq *AuthorizationRequest, client *OAuthClient, userID string) {
 	// Parse form data
 	if err := r.ParseForm(); err != nil {
 		s.logger.Error("Failed to parse authorization form: %v", err)
@@ -169,10 +192,15 @@ func (s *AuthorizationServer) processAuthorization(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Generate authorization code
-	// For demo purposes, use a static user ID. In production, get from authenticated session
-	userID := "demo-user"
+	// The approval is remembered, so the user is only asked again for scopes they haven't granted
+	if err := s.recordConsent(userID, client.ID, authReq.Scope); err != nil {
+		s.logger.Warning("Failed to record consent of %s for client %s: %v", userID, client.ID, err)
+	}
+	s.issueAuthorizationCode(w, r, authReq, client, userID)
+}
 
+// issueAuthorizationCode redirects back to the client with an authorization code for userID
+func (s *AuthorizationServer) issueAuthorizationCode(w http.ResponseWriter, r *http.Request, authReq *AuthorizationRequest, client *OAuthClient, userID string) {
 	s.logger.Info("Generating authorization code for client: %s, user: %s", authReq.ClientID, userID)
 
 	s.mu.Lock()
@@ -225,30 +253,34 @@ func getClientDisplayName(client *OAuthClient) string {
 	return client.ID
 }
 
-func formatScopes(scope string) string {
-	if scope == "" {
-
-		return "No specific permissions requested"
-	}
-
+// formatScopes describes the scopes of a request, one line each
+func formatScopes(scope string) []string {
 	scopes := strings.Fields(scope)
 	formatted := make([]string, len(scopes))
 	for i, s := range scopes {
 		switch s {
 		case "mcp:*":
-			formatted[i] = "• Full access to all MCP resources"
+			formatted[i] = "Full access to all MCP resources"
 		case "mcp:tools":
-			formatted[i] = "• Access to MCP tools"
+			formatted[i] = "Access to MCP tools"
 		case "mcp:resources":
-			formatted[i] = "• Access to MCP resources"
+			formatted[i] = "Access to MCP resources"
 		case "mcp:prompts":
-			formatted[i] = "• Access to MCP prompts"
+			formatted[i] = "Access to MCP prompts"
+		case "mcp:tasks:read":
+			formatted[i] = "View scheduled tasks and their runs"
+		case "mcp:tasks:write":
+			formatted[i] = "Create, change and run scheduled tasks"
+		case "mcp:admin:read":
+			formatted[i] = "View servers, their status and logs"
+		case "mcp:admin:write":
+			formatted[i] = "Start, stop and restart servers and reload the configuration"
 		default:
-			formatted[i] = "• " + s
+			formatted[i] = "" + s
 		}
 	}
 
-	return strings.Join(formatted, "<br>")
+	return formatted
 }
 
 // HandleToken handles token requests
@@ -274,6 +306,8 @@ func (s *AuthorizationServer) HandleToken(w http.ResponseWriter, r *http.Request
 		s.handleClientCredentialsGrant(w, r)
 	case "refresh_token":
 		s.handleRefreshTokenGrant(w, r)
+	case DeviceCodeGrantType:
+		s.handleDeviceCodeGrant(w, r)
 	default:
 		s.sendTokenError(w, "unsupported_grant_type", "Grant type not supported")
 	}
@@ -362,6 +396,7 @@ type AuthorizationRequest struct {
 	CodeChallenge       string
 	CodeChallengeMethod string
 	Nonce               string
+	Prompt              string // "consent" asks the user even when they granted the scopes before
 }
 
 func (s *AuthorizationServer) parseAuthorizationRequest(r *http.Request) (*AuthorizationRequest, error) {
@@ -391,6 +426,7 @@ func (s *AuthorizationServer) parseAuthorizationRequest(r *http.Request) (*Autho
 		CodeChallenge:       query.Get("code_challenge"),
 		CodeChallengeMethod: query.Get("code_challenge_method"),
 		Nonce:               query.Get("nonce"),
+		Prompt:              query.Get("prompt"),
 	}
 
 	// Validate required parameters
@@ -428,9 +464,9 @@ func (s *AuthorizationServer) handleAuthorizationCodeGrant(w http.ResponseWriter
 		}
 	}
 
-	client, err := s.ValidateClient(clientID, clientSecret)
+	client, err := s.authenticateClient(r, clientID, clientSecret)
 	if err != nil {
-		s.sendTokenError(w, "invalid_client", err.Error())
+		s.sendClientError(w, err)
 
 		return
 	}
@@ -549,9 +585,9 @@ func (s *AuthorizationServer) handleClientCredentialsGrant(w http.ResponseWriter
 		}
 	}
 
-	client, err := s.ValidateClient(clientID, clientSecret)
+	client, err := s.authenticateClient(r, clientID, clientSecret)
 	if err != nil {
-		s.sendTokenError(w, "invalid_client", err.Error())
+		s.sendClientError(w, err)
 
 		return
 	}
@@ -618,9 +654,9 @@ func (s *AuthorizationServer) handleRefreshTokenGrant(w http.ResponseWriter, r *
 		}
 	}
 
-	client, err := s.ValidateClient(clientID, clientSecret)
+	client, err := s.authenticateClient(r, clientID, clientSecret)
 	if err != nil {
-		s.sendTokenError(w, "invalid_client", err.Error())
+		s.sendClientError(w, err)
 
 		return
 	}
@@ -739,21 +775,52 @@ func (s *AuthorizationServer) generateAccessToken(clientID, userID, scope string
 		return nil, err
 	}
 
+	now := time.Now()
 	accessToken := &AccessToken{
 		Token:     token,
 		Type:      "Bearer",
 		ClientID:  clientID,
 		UserID:    userID,
 		Scope:     scope,
-		ExpiresAt: time.Now().Add(s.tokenLifetime),
-		CreatedAt: time.Now(),
+		ExpiresAt: now.Add(s.tokenLifetime),
+		CreatedAt: now,
 	}
+	if s.tokenSigner != nil {
+		// Signed tokens are still recorded, so revoking one works as for opaque tokens
+		if accessToken.Token, err = s.signAccessToken(accessToken, token); err != nil {
 
-	s.accessTokens[token] = accessToken
+			return nil, err
+		}
+	}
+
+	s.accessTokens[accessToken.Token] = accessToken
 
 	return accessToken, nil
 }
 
+// signAccessToken returns an access token as a JWT with the claims of RFC 9068. Tokens from
+// the client credentials grant act for the client itself.
+func (s *AuthorizationServer) signAccessToken(token *AccessToken, id string) (string, error) {
+	subject := token.UserID
+	if subject == "" {
+		subject = token.ClientID
+	}
+	claims := map[string]interface{}{
+		"iss":       s.config.Issuer,
+		"sub":       subject,
+		"aud":       s.config.Issuer,
+		"client_id": token.ClientID,
+		"iat":       token.CreatedAt.Unix(),
+		"exp":       token.ExpiresAt.Unix(),
+		"jti":       id,
+	}
+	if token.Scope != "" {
+		claims["scope"] = token.Scope
+	}
+
+	return s.tokenSigner.Sign(claims)
+}
+
 func (s *AuthorizationServer) generateRefreshToken(clientID, userID, scope string) (*RefreshToken, error) {
 	token, err := s.tokenGenerator.GenerateRefreshToken()
 	if err != nil {
@@ -821,10 +888,14 @@ func (s *AuthorizationServer) isScopeSubset(requested, original string) bool {
 }
 
 func (s *AuthorizationServer) sendTokenError(w http.ResponseWriter, errorCode, description string) {
+	s.writeTokenError(w, http.StatusBadRequest, errorCode, description)
+}
+
+func (s *AuthorizationServer) writeTokenError(w http.ResponseWriter, status int, errorCode, description string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store")
 	w.Header().Set("Pragma", "no-cache")
-	w.WriteHeader(http.StatusBadRequest)
+	w.WriteHeader(status)
 
 	response := map[string]string{
 		"error":             errorCode,
@@ -980,7 +1051,12 @@ func (s *AuthorizationServer) HandleRevoke(w http.ResponseWriter, r *http.Reques
 			}
 		}
 
-		client, err := s.ValidateClient(clientID, clientSecret)
+		client, err := s.authenticateClient(r, clientID, clientSecret)
+		if _, throttled := err.(*throttledError); throttled {
+			s.sendClientError(w, err)
+
+			return
+		}
 		if err != nil {
 			s.sendRevokeError(w, "invalid_client", err.Error())
 
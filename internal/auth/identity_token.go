@@ -0,0 +1,265 @@
+// internal/auth/identity_token.go
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// IdentityContextKey holds the *TrustedIdentity of a request authenticated by trusted headers
+const IdentityContextKey contextKey = "trusted_identity"
+
+// IdentityClaims are the claims of an identity token beyond the registered ones
+type IdentityClaims struct {
+	Subject  string   `json:"sub"`
+	AuthType string   `json:"auth_type"`
+	ClientID string   `json:"client_id,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	Email    string   `json:"email,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Role     string   `json:"role,omitempty"`
+}
+
+// identityToken is the payload of an identity token
+type identityToken struct {
+	IdentityClaims
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti"`
+}
+
+// IdentitySigner signs identity tokens: JWTs, signed with EdDSA, that assert to a server
+// which client and user a request forwarded by the proxy came from
+type IdentitySigner struct {
+	key    ed25519.PrivateKey
+	keyID  string
+	issuer string
+	ttl    time.Duration
+}
+
+// NewIdentitySigner loads the signing key from keyFile, or generates one when it is empty.
+// The issuer defaults to defaultIssuer, then to mcp-compose.
+func NewIdentitySigner(tokens *config.IdentityTokenConfig, keyFile, defaultIssuer string) (*IdentitySigner, error) {
+	if tokens == nil {
+		tokens = &config.IdentityTokenConfig{}
+	}
+	signer := &IdentitySigner{issuer: tokens.Issuer, ttl: constants.IdentityTokenTTL}
+	if signer.issuer == "" {
+		signer.issuer = defaultIssuer
+	}
+	if signer.issuer == "" {
+		signer.issuer = constants.IdentityTokenIssuer
+	}
+	if tokens.TTL != "" {
+		ttl, err := time.ParseDuration(tokens.TTL)
+		if err != nil || ttl <= 0 {
+
+			return nil, fmt.Errorf("invalid identity token ttl '%s'", tokens.TTL)
+		}
+		signer.ttl = ttl
+	}
+
+	var err error
+	if keyFile == "" {
+		_, signer.key, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		signer.key, err = loadIdentitySigningKey(keyFile)
+	}
+	if err != nil {
+
+		return nil, err
+	}
+	// The key ID is the key's RFC 7638 thumbprint, so servers can cache keys by it
+	thumbprint := sha256.Sum256([]byte(fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, signer.publicKeyX())))
+	signer.keyID = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+
+	return signer, nil
+}
+
+func loadIdentitySigningKey(keyFile string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read identity signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+
+		return nil, fmt.Errorf("identity signing key %s is not PEM encoded", keyFile)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+
+		return nil, fmt.Errorf("invalid identity signing key %s: %w", keyFile, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+
+		return nil, fmt.Errorf("identity signing key %s is not an ed25519 key", keyFile)
+	}
+
+	return key, nil
+}
+
+func (s *IdentitySigner) publicKeyX() string {
+
+	return base64.RawURLEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// PublicKey returns the key identity tokens are verified with
+func (s *IdentitySigner) PublicKey() ed25519.PublicKey {
+
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Sign returns an identity token for the claims, addressed to the server it is sent to
+func (s *IdentitySigner) Sign(claims IdentityClaims, audience string) (string, error) {
+	id, err := generateRandomString(constants.IdentityTokenIDSize)
+	if err != nil {
+
+		return "", err
+	}
+	now := time.Now()
+	header, err := json.Marshal(map[string]string{"alg": "EdDSA", "typ": "JWT", "kid": s.keyID})
+	if err != nil {
+
+		return "", err
+	}
+	payload, err := json.Marshal(identityToken{
+		IdentityClaims: claims,
+		Issuer:         s.issuer,
+		Audience:       audience,
+		IssuedAt:       now.Unix(),
+		ExpiresAt:      now.Add(s.ttl).Unix(),
+		ID:             id,
+	})
+	if err != nil {
+
+		return "", fmt.Errorf("failed to encode identity token: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature := ed25519.Sign(s.key, []byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// JWK returns the public key identity tokens are verified with as a JSON Web Key
+func (s *IdentitySigner) JWK() map[string]string {
+
+	return map[string]string{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"alg": "EdDSA",
+		"use": "sig",
+		"kid": s.keyID,
+		"x":   s.publicKeyX(),
+	}
+}
+
+// HandleJWKS serves the public key as a JSON Web Key Set
+func (s *IdentitySigner) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	WriteJWKS(w, []map[string]string{s.JWK()})
+}
+
+// VerifyIdentityToken checks an identity token's signature, issuer, audience and expiry and
+// returns its claims
+func VerifyIdentityToken(token string, key ed25519.PublicKey, issuer, audience string) (*IdentityClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != constants.JWTParts {
+
+		return nil, errors.New("malformed identity token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !ed25519.Verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+
+		return nil, errors.New("invalid identity token signature")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(data, &header) != nil || header.Alg != "EdDSA" {
+
+		return nil, errors.New("unexpected identity token header")
+	}
+	var payload identityToken
+	data, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(data, &payload) != nil {
+
+		return nil, errors.New("malformed identity token payload")
+	}
+	switch {
+	case payload.Issuer != issuer:
+
+		return nil, fmt.Errorf("identity token issued by '%s'", payload.Issuer)
+	case payload.Audience != audience:
+
+		return nil, fmt.Errorf("identity token is for '%s'", payload.Audience)
+	case time.Now().Unix() >= payload.ExpiresAt:
+
+		return nil, errors.New("identity token expired")
+	}
+
+	return &payload.IdentityClaims, nil
+}
+
+// RequestIdentity returns the identity a request was authenticated with, or nil when it
+// carries no user or OAuth client: it was made with the proxy API key or without
+// credentials
+func RequestIdentity(ctx context.Context) *IdentityClaims {
+	authType, _ := ctx.Value(AuthTypeContextKey).(string)
+	switch authType {
+	case "oauth":
+		claims := &IdentityClaims{AuthType: authType}
+		claims.Subject, _ = ctx.Value(UserContextKey).(string)
+		claims.Scope, _ = ctx.Value(ScopeContextKey).(string)
+		if client, ok := ctx.Value(ClientContextKey).(*OAuthClient); ok && client != nil {
+			claims.ClientID = client.ID
+		}
+		// Tokens from the client credentials grant act for the client itself
+		if claims.Subject == "" {
+			claims.Subject = claims.ClientID
+		}
+		if claims.Subject == "" {
+
+			return nil
+		}
+
+		return claims
+	case AuthTypeTrustedHeader:
+		identity, ok := ctx.Value(IdentityContextKey).(*TrustedIdentity)
+		if !ok || identity == nil {
+
+			return nil
+		}
+
+		return &IdentityClaims{
+			Subject:  identity.Username,
+			AuthType: authType,
+			Scope:    identity.Scope,
+			Email:    identity.Email,
+			Groups:   identity.Groups,
+			Role:     identity.Role,
+		}
+	}
+
+	return nil
+}
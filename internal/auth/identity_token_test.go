@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestIdentityTokens(t *testing.T) {
+	signer, err := NewIdentitySigner(&config.IdentityTokenConfig{TTL: "1m"}, "", "https://proxy.example.com")
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	claims := IdentityClaims{Subject: "alice", AuthType: "oauth", ClientID: "cli", Scope: "mcp:tools"}
+	token, err := signer.Sign(claims, "notes")
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	verified, err := VerifyIdentityToken(token, signer.PublicKey(), "https://proxy.example.com", "notes")
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if verified.Subject != "alice" || verified.ClientID != "cli" || verified.Scope != "mcp:tools" {
+		t.Errorf("Unexpected claims %+v", verified)
+	}
+	if _, err := VerifyIdentityToken(token, signer.PublicKey(), "https://proxy.example.com", "other"); err == nil {
+		t.Error("Expected a token for another server to be rejected")
+	}
+	other, _ := NewIdentitySigner(nil, "", "")
+	if _, err := VerifyIdentityToken(token, other.PublicKey(), "https://proxy.example.com", "notes"); err == nil {
+		t.Error("Expected a token signed with another key to be rejected")
+	}
+
+	recorder := httptest.NewRecorder()
+	signer.HandleJWKS(recorder, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &jwks); err != nil || len(jwks.Keys) != 1 || jwks.Keys[0]["kid"] != signer.keyID || jwks.Keys[0]["crv"] != "Ed25519" {
+		t.Errorf("Unexpected key set %s (%v)", recorder.Body.String(), err)
+	}
+}
+
+func TestIdentitySigningKeyFile(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "identity.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	signer, err := NewIdentitySigner(&config.IdentityTokenConfig{Issuer: "proxy"}, keyFile, "")
+	if err != nil {
+		t.Fatalf("Failed to load key: %v", err)
+	}
+	if !signer.PublicKey().Equal(key.Public()) {
+		t.Error("Expected the signer to use the key file")
+	}
+	if _, err := NewIdentitySigner(nil, filepath.Join(t.TempDir(), "missing.pem"), ""); err == nil {
+		t.Error("Expected a missing key file to fail")
+	}
+}
+
+func TestRequestIdentity(t *testing.T) {
+	if identity := RequestIdentity(context.WithValue(context.Background(), AuthTypeContextKey, "api_key")); identity != nil {
+		t.Errorf("Expected no identity for the proxy API key, got %+v", identity)
+	}
+
+	ctx := context.WithValue(context.Background(), AuthTypeContextKey, "oauth")
+	ctx = context.WithValue(ctx, ClientContextKey, &OAuthClient{ID: "reporting"})
+	if identity := RequestIdentity(ctx); identity == nil || identity.Subject != "reporting" {
+		t.Errorf("Expected a client credentials token to act as its client, got %+v", identity)
+	}
+
+	trusted := &TrustedIdentity{Username: "bob", Email: "bob@example.com", Groups: []string{"staff"}, Role: "developer"}
+	identity := RequestIdentity(trusted.WithContext(context.Background()))
+	if identity == nil || identity.Subject != "bob" || identity.Email != "bob@example.com" || identity.Role != "developer" {
+		t.Errorf("Unexpected trusted header identity %+v", identity)
+	}
+}
@@ -0,0 +1,470 @@
+// internal/auth/login.go
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	errLoginExpired = errors.New("sign-in expired")
+	errInvalidTOTP  = errors.New("invalid code")
+)
+
+// UserLogin signs users in to the authorization endpoint with the password of a configured
+// user and, for users with a totp_secret, a code from their authenticator app. Signed-in
+// users get a session cookie, so they aren't asked again for every client they authorize.
+type UserLogin struct {
+	mu        sync.Mutex
+	users     map[string]*config.User
+	rbac      *config.RBACConfig
+	sessions  map[string]*loginSession
+	pending   map[string]*loginSession // password checked, waiting for the TOTP code
+	usedSteps map[string]int64         // TOTP step each user last signed in with, so a code works once
+}
+
+type loginSession struct {
+	username string
+	expires  time.Time
+	attempts int
+}
+
+// NewUserLogin returns nil when no enabled user has a password, in which case the
+// authorization endpoint keeps acting for a single demo user
+func NewUserLogin(users map[string]*config.User, rbac *config.RBACConfig) *UserLogin {
+	for _, user := range users {
+		if user != nil && user.Enabled && user.PasswordHash != "" {
+
+			return &UserLogin{
+				users:     users,
+				rbac:      rbac,
+				sessions:  make(map[string]*loginSession),
+				pending:   make(map[string]*loginSession),
+				usedSteps: make(map[string]int64),
+			}
+		}
+	}
+
+	return nil
+}
+
+// findUser looks up an enabled user by username or email
+func (l *UserLogin) findUser(name string) (string, *config.User) {
+	if name == "" {
+
+		return "", nil
+	}
+	for key, user := range l.users {
+		if user == nil || !user.Enabled {
+			continue
+		}
+		if key == name || user.Username == name || (user.Email != "" && strings.EqualFold(user.Email, name)) {
+			if user.Username != "" {
+
+				return user.Username, user
+			}
+
+			return key, user
+		}
+	}
+
+	return "", nil
+}
+
+// accountName returns the username that sign-in failures for name count against, so a user
+// can't be tried twice as often by username and by email
+func (l *UserLogin) accountName(name string) string {
+	if username, _ := l.findUser(name); username != "" {
+
+		return username
+	}
+
+	return strings.ToLower(name)
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// checkPassword returns the username and user for valid credentials. Unknown users still pay
+// for a bcrypt comparison so they can't be told apart by timing.
+func (l *UserLogin) checkPassword(name, password string) (string, *config.User, bool) {
+	username, user := l.findUser(name)
+	if user == nil || user.PasswordHash == "" {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("mcp-compose"), bcrypt.DefaultCost)
+		})
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+
+		return "", nil, false
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+
+		return "", nil, false
+	}
+
+	return username, user, true
+}
+
+// newSession adds a session for username to sessions and returns its ID, dropping the
+// expired ones; l.mu is held
+func newSession(sessions map[string]*loginSession, username string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	for id, session := range sessions {
+		if now.After(session.expires) {
+			delete(sessions, id)
+		}
+	}
+	id, err := generateRandomString(constants.OAuthSessionIDSize)
+	if err != nil {
+
+		return "", err
+	}
+	sessions[id] = &loginSession{username: username, expires: now.Add(ttl)}
+
+	return id, nil
+}
+
+// startTOTP remembers that username gave the right password and returns the token the TOTP
+// form carries
+func (l *UserLogin) startTOTP(username string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return newSession(l.pending, username, constants.OAuthPendingLoginTTL)
+}
+
+// checkTOTP returns the user who gave the right password for the token when code is their
+// current TOTP code. A wrong code may be retried a few times before the password is asked
+// for again; the user is returned with the error then too.
+func (l *UserLogin) checkTOTP(token, code string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pending, exists := l.pending[token]
+	if !exists || time.Now().After(pending.expires) {
+		delete(l.pending, token)
+
+		return "", errLoginExpired
+	}
+	_, user := l.findUser(pending.username)
+	if user == nil {
+		delete(l.pending, token)
+
+		return "", errLoginExpired
+	}
+	key, err := DecodeTOTPSecret(user.TOTPSecret)
+	if err != nil {
+
+		return "", err
+	}
+	step, valid := validateTOTP(key, code, time.Now())
+	if !valid || step <= l.usedSteps[pending.username] {
+		pending.attempts++
+		if pending.attempts >= constants.OAuthMaxTOTPAttempts {
+			delete(l.pending, token)
+
+			return pending.username, errLoginExpired
+		}
+
+		return pending.username, errInvalidTOTP
+	}
+	l.usedSteps[pending.username] = step
+	delete(l.pending, token)
+
+	return pending.username, nil
+}
+
+// sessionUser returns the user signed in with the request's session cookie
+func (l *UserLogin) sessionUser(r *http.Request) string {
+	cookie, err := r.Cookie(constants.OAuthSessionCookie)
+	if err != nil || cookie.Value == "" {
+
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	session, exists := l.sessions[cookie.Value]
+	if !exists || time.Now().After(session.expires) {
+
+		return ""
+	}
+	// A user who was disabled or removed since signing in is signed out
+	if _, user := l.findUser(session.username); user == nil {
+		delete(l.sessions, cookie.Value)
+
+		return ""
+	}
+
+	return session.username
+}
+
+// startSession signs username in, setting the session cookie on w
+func (l *UserLogin) startSession(w http.ResponseWriter, username string, secure bool) error {
+	l.mu.Lock()
+	id, err := newSession(l.sessions, username, constants.OAuthSessionTTL)
+	l.mu.Unlock()
+	if err != nil {
+
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     constants.OAuthSessionCookie,
+		Value:    id,
+		Path:     "/oauth/",
+		MaxAge:   int(constants.OAuthSessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// deniedScopes returns the requested scopes the user's role doesn't grant. Roles only limit
+// scopes when RBAC is enabled and defines the user's role.
+func (l *UserLogin) deniedScopes(username, scope string) []string {
+	if l == nil || l.rbac == nil || !l.rbac.Enabled {
+
+		return nil
+	}
+	_, user := l.findUser(username)
+	if user == nil {
+
+		return nil
+	}
+	role, exists := l.rbac.Roles[user.Role]
+	if !exists || contains(role.Scopes, "mcp:*") {
+
+		return nil
+	}
+	var denied []string
+	for _, requested := range strings.Fields(scope) {
+		if !contains(role.Scopes, requested) {
+			denied = append(denied, requested)
+		}
+	}
+
+	return denied
+}
+
+// SetUserLogin makes users sign in at the authorization endpoint, so the tokens issued act
+// for them. Without one, authorizations are made for a single demo user.
+func (s *AuthorizationServer) SetUserLogin(login *UserLogin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userLogin = login
+}
+
+// SessionUser returns the user signed in to the authorization endpoint with the request's
+// session cookie, if any
+func (s *AuthorizationServer) SessionUser(r *http.Request) string {
+	s.mu.RLock()
+	login := s.userLogin
+	s.mu.RUnlock()
+	if login == nil {
+
+		return ""
+	}
+
+	return login.sessionUser(r)
+}
+
+// loginPage is what the sign-in page shows
+type loginPage struct {
+	Client     string
+	Params     url.Values // the authorization request, carried through the sign-in
+	Username   string
+	LoginToken string // set once the password was right and the TOTP code is awaited
+	CSRFToken  string
+	Error      string
+}
+
+var loginPageTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Sign In</title>
+    <style>
+        body { font-family: Arial, sans-serif; max-width: 600px; margin: 50px auto; padding: 20px; }
+        .auth-box { border: 1px solid #ddd; padding: 20px; border-radius: 5px; background: #f9f9f9; }
+        .client-info { background: #e7f3ff; padding: 10px; margin: 10px 0; border-radius: 3px; }
+        .error { background: #f8d7da; padding: 10px; margin: 10px 0; border-radius: 3px; }
+        label { display: block; margin: 10px 0 5px; }
+        input[type=text], input[type=password] { padding: 8px; font-size: 16px; width: 100%; box-sizing: border-box; }
+        button { padding: 10px 20px; margin: 15px 0 5px; border: none; border-radius: 3px; cursor: pointer; font-size: 16px; background: #28a745; color: white; }
+    </style>
+</head>
+<body>
+    <div class="auth-box">
+        <h2>Sign In</h2>
+        <div class="client-info"><strong>{{.Client}}</strong> wants to access mcp-compose on your behalf.</div>
+        {{if .Error}}<div class="error">{{.Error}}</div>{{end}}
+        <form method="POST">
+            <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+            {{range $name, $values := .Params}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+            {{end}}{{end}}
+            {{if .LoginToken}}
+            <input type="hidden" name="login_token" value="{{.LoginToken}}">
+            <label for="code">Code from your authenticator app</label>
+            <input type="text" id="code" name="code" inputmode="numeric" autocomplete="one-time-code" autofocus>
+            <button type="submit" name="action" value="totp">Verify</button>
+            {{else}}
+            <label for="username">Username or email</label>
+            <input type="text" id="username" name="username" value="{{.Username}}" autocomplete="username" autofocus>
+            <label for="password">Password</label>
+            <input type="password" id="password" name="password" autocomplete="current-password">
+            <button type="submit" name="action" value="login">Sign In</button>
+            {{end}}
+        </form>
+    </div>
+</body>
+</html>`))
+
+// params returns the parameters of an authorization request, to carry it through the sign-in
+func (req *AuthorizationRequest) params() url.Values {
+	params := url.Values{}
+	for name, value := range map[string]string{
+		"response_type":         req.ResponseType,
+		"client_id":             req.ClientID,
+		"redirect_uri":          req.RedirectURI,
+		"scope":                 req.Scope,
+		"state":                 req.State,
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+		"nonce":                 req.Nonce,
+		"prompt":                req.Prompt,
+	} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+
+	return params
+}
+
+// signIn returns who an authorization request is made by: the user a trusted reverse proxy
+// signed in, the user of the session cookie, or the demo user when no users can sign in.
+// Otherwise it answers with the sign-in page, or once the user has signed in with the session
+// cookie and a redirect back to the request, and reports false. Failed sign-ins count
+// towards the login throttle, which refuses callers it has locked out.
+func (s *AuthorizationServer) signIn(w http.ResponseWriter, r *http.Request, authReq *AuthorizationRequest, client *OAuthClient) (string, bool) {
+	if user, ok := r.Context().Value(UserContextKey).(string); ok && user != "" {
+
+		return user, true
+	}
+	s.mu.RLock()
+	login := s.userLogin
+	s.mu.RUnlock()
+	if login == nil {
+
+		return "demo-user", true
+	}
+	if user := login.sessionUser(r); user != "" {
+
+		return user, true
+	}
+
+	page := loginPage{Client: getClientDisplayName(client), Params: authReq.params(), CSRFToken: csrfToken(r)}
+	status := http.StatusOK
+	username := ""
+	throttle := s.loginThrottle()
+	ipKey := throttleKey("ip", remoteIP(r))
+	action := ""
+	if r.Method == http.MethodPost {
+		action = r.Form.Get("action")
+	}
+	switch action {
+	case "login", "totp":
+		// Locked out callers are refused before their credentials are checked
+		page.Username = r.Form.Get("username")
+		keys := []string{ipKey}
+		if action == "login" {
+			keys = append(keys, throttleKey("user", login.accountName(page.Username)))
+		}
+		if wait := throttle.retryAfter(keys...); wait > 0 {
+			seconds := retryAfterSeconds(wait)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			page.Error = fmt.Sprintf("Too many failed sign-ins. Try again in %s.", time.Duration(seconds)*time.Second)
+			status = http.StatusTooManyRequests
+			action = ""
+		}
+	}
+	switch action {
+	case "login":
+		name, user, ok := login.checkPassword(page.Username, r.Form.Get("password"))
+		switch {
+		case !ok:
+			s.logger.Warning("Failed sign-in as '%s' from %s", page.Username, r.RemoteAddr)
+			throttle.failure(ipKey, throttleKey("user", login.accountName(page.Username)))
+			s.failed(r, "user", page.Username, "invalid username or password")
+			page.Error = "Invalid username or password."
+			status = http.StatusUnauthorized
+		case user.TOTPSecret != "":
+			token, err := login.startTOTP(name)
+			if err != nil {
+				page.Error = "Failed to start the sign-in. Try again."
+				status = http.StatusInternalServerError
+			}
+			page.LoginToken = token
+		default:
+			username = name
+		}
+	case "totp":
+		name, err := login.checkTOTP(r.Form.Get("login_token"), r.Form.Get("code"))
+		if err != nil && name != "" {
+			throttle.failure(ipKey, throttleKey("user", name))
+		}
+		if errors.Is(err, errInvalidTOTP) {
+			s.failed(r, "user", name, "invalid TOTP code")
+		}
+		switch {
+		case errors.Is(err, errInvalidTOTP):
+			s.logger.Warning("Wrong TOTP code in a sign-in from %s", r.RemoteAddr)
+			page.LoginToken = r.Form.Get("login_token")
+			page.Error = "That code is not valid. Enter the current code."
+			status = http.StatusUnauthorized
+		case err != nil:
+			page.Error = "The sign-in has expired. Enter your password again."
+			status = http.StatusUnauthorized
+		default:
+			username = name
+		}
+	}
+
+	if username != "" {
+		if err := login.startSession(w, username, r.TLS != nil || strings.HasPrefix(s.config.Issuer, "https://")); err != nil {
+			s.logger.Error("Failed to start a session for %s: %v", username, err)
+			http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+
+			return "", false
+		}
+		throttle.success(throttleKey("user", username))
+		s.logger.Info("User %s signed in to authorize client %s", username, client.ID)
+		// Back to the request, now signed in, which shows the consent page
+		http.Redirect(w, r, r.URL.Path+"?"+authReq.params().Encode(), http.StatusSeeOther)
+
+		return "", false
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	if err := loginPageTemplate.Execute(w, page); err != nil {
+		s.logger.Error("Failed to render sign-in page: %v", err)
+	}
+
+	return "", false
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1, cut to six digits
+	key := []byte("12345678901234567890")
+	for seconds, code := range map[int64]string{59: "287082", 1111111109: "081804", 2000000000: "279037"} {
+		if got := totpCode(key, seconds/30); got != code {
+			t.Errorf("Expected %s at %d, got %s", code, seconds, got)
+		}
+	}
+
+	now := time.Unix(1111111109, 0)
+	if _, ok := validateTOTP(key, "081804", now); !ok {
+		t.Error("Expected the current code to be valid")
+	}
+	if _, ok := validateTOTP(key, "081 804", now.Add(30*time.Second)); !ok {
+		t.Error("Expected the previous step's code to be valid, spaces and all")
+	}
+	if _, ok := validateTOTP(key, "081804", now.Add(2*time.Minute)); ok {
+		t.Error("Expected an old code to be refused")
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	if decoded, err := DecodeTOTPSecret(strings.ToLower(secret)); err != nil || len(decoded) != 20 {
+		t.Errorf("Expected the secret to decode to 20 bytes (%v)", err)
+	}
+}
+
+func TestAuthorizeSignIn(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	totpSecret, _ := GenerateTOTPSecret()
+	users := map[string]*config.User{
+		"alice": {Username: "alice", PasswordHash: string(hash), Role: "admin", Enabled: true},
+		"bob":   {Username: "bob", Email: "bob@example.com", PasswordHash: string(hash), Role: "viewer", Enabled: true, TOTPSecret: totpSecret},
+	}
+	rbac := &config.RBACConfig{Enabled: true, Roles: map[string]config.Role{"viewer": {Scopes: []string{"mcp:tools"}}}}
+
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	authServer.SetUserLogin(NewUserLogin(users, rbac))
+	if _, err := authServer.RegisterClient(&OAuthConfig{
+		ClientID:      "web",
+		RedirectURIs:  []string{"https://app.example.com/callback"},
+		GrantTypes:    []string{"authorization_code"},
+		ResponseTypes: []string{"code"},
+	}); err != nil {
+		t.Fatalf("Failed to register client: %v", err)
+	}
+
+	request := func(method, scope string, form url.Values, cookie *http.Cookie) *httptest.ResponseRecorder {
+		params := url.Values{
+			"response_type": {"code"},
+			"client_id":     {"web"},
+			"redirect_uri":  {"https://app.example.com/callback"},
+			"scope":         {scope},
+		}
+		var req *http.Request
+		if method == http.MethodGet {
+			req = httptest.NewRequest(method, "/oauth/authorize?"+params.Encode(), nil)
+		} else {
+			for name, values := range form {
+				params[name] = values
+			}
+			req = httptest.NewRequest(method, "/oauth/authorize", strings.NewReader(params.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		recorder := httptest.NewRecorder()
+		authServer.HandleAuthorize(recorder, req)
+
+		return recorder
+	}
+	sessionCookie := func(t *testing.T, recorder *httptest.ResponseRecorder) *http.Cookie {
+		t.Helper()
+		if recorder.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect back to the request, got %d: %s", recorder.Code, recorder.Body.String())
+		}
+		for _, cookie := range recorder.Result().Cookies() {
+			if cookie.Name == "mcp_compose_oauth_session" && cookie.HttpOnly && cookie.Secure {
+				return cookie
+			}
+		}
+		t.Fatal("Expected a secure session cookie")
+
+		return nil
+	}
+
+	t.Run("password", func(t *testing.T) {
+		if recorder := request(http.MethodGet, "mcp:tools", nil, nil); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `name="password"`) {
+			t.Fatalf("Expected the sign-in page, got %d", recorder.Code)
+		}
+		// Approving without signing in only shows the sign-in page
+		if recorder := request(http.MethodPost, "mcp:tools", url.Values{"action": {"approve"}}, nil); recorder.Code != http.StatusOK || recorder.Header().Get("Location") != "" {
+			t.Fatalf("Expected the sign-in page, got %d", recorder.Code)
+		}
+		if recorder := request(http.MethodPost, "mcp:tools", url.Values{"action": {"login"}, "username": {"alice"}, "password": {"wrong"}}, nil); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected a wrong password to be refused, got %d", recorder.Code)
+		}
+
+		cookie := sessionCookie(t, request(http.MethodPost, "mcp:tools", url.Values{"action": {"login"}, "username": {"alice"}, "password": {"s3cret"}}, nil))
+		if recorder := request(http.MethodGet, "mcp:tools", nil, cookie); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "Signed in as <strong>alice</strong>") {
+			t.Fatalf("Expected the consent page for alice, got %d", recorder.Code)
+		}
+		recorder := request(http.MethodPost, "mcp:tools", url.Values{"action": {"approve"}}, cookie)
+		location, _ := url.Parse(recorder.Header().Get("Location"))
+		code := location.Query().Get("code")
+		if recorder.Code != http.StatusFound || code == "" {
+			t.Fatalf("Expected a code, got %d", recorder.Code)
+		}
+		if authCode := authServer.authCodes[code]; authCode == nil || authCode.UserID != "alice" {
+			t.Errorf("Expected the code to be for alice, got %+v", authCode)
+		}
+	})
+
+	t.Run("totp", func(t *testing.T) {
+		recorder := request(http.MethodPost, "mcp:tools", url.Values{"action": {"login"}, "username": {"bob@example.com"}, "password": {"s3cret"}}, nil)
+		match := regexp.MustCompile(`name="login_token" value="([^"]+)"`).FindStringSubmatch(recorder.Body.String())
+		if recorder.Code != http.StatusOK || match == nil {
+			t.Fatalf("Expected to be asked for a code, got %d", recorder.Code)
+		}
+		token := match[1]
+		if recorder := request(http.MethodPost, "mcp:tools", url.Values{"action": {"totp"}, "login_token": {token}, "code": {"000000"}}, nil); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected a wrong code to be refused, got %d", recorder.Code)
+		}
+
+		key, _ := DecodeTOTPSecret(totpSecret)
+		code := totpCode(key, time.Now().Unix()/30)
+		cookie := sessionCookie(t, request(http.MethodPost, "mcp:tools", url.Values{"action": {"totp"}, "login_token": {token}, "code": {code}}, nil))
+
+		// The role limits what bob may grant
+		if recorder := request(http.MethodGet, "mcp:admin:write", nil, cookie); !strings.Contains(recorder.Header().Get("Location"), "error=access_denied") {
+			t.Errorf("Expected access_denied for a scope the role lacks, got %d %s", recorder.Code, recorder.Header().Get("Location"))
+		}
+
+		// A code works once
+		recorder = request(http.MethodPost, "mcp:tools", url.Values{"action": {"login"}, "username": {"bob"}, "password": {"s3cret"}}, nil)
+		token = regexp.MustCompile(`name="login_token" value="([^"]+)"`).FindStringSubmatch(recorder.Body.String())[1]
+		if recorder := request(http.MethodPost, "mcp:tools", url.Values{"action": {"totp"}, "login_token": {token}, "code": {code}}, nil); recorder.Code != http.StatusUnauthorized {
+			t.Errorf("Expected a used code to be refused, got %d", recorder.Code)
+		}
+	})
+}
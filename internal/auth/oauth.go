@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/phildougherty/mcp-compose/internal/cors"
 	"github.com/phildougherty/mcp-compose/internal/logging"
 )
 
@@ -72,6 +73,14 @@ type AuthorizationServer struct {
 	authCodeLifetime time.Duration
 	tokenLifetime    time.Duration
 	refreshLifetime  time.Duration
+	tokenSigner      *TokenSigner // issues access tokens as signed JWTs; nil issues opaque tokens
+	deviceVerifyURI  string       // where users approve device sign-ins; empty is the issuer's /oauth/device
+	consents         map[string]*Consent
+	consentFile      string         // where consents are kept across restarts; empty keeps them in memory
+	userLogin        *UserLogin     // signs users in at the authorization endpoint; nil acts for a demo user
+	throttle         *LoginThrottle // locks out repeated failed sign-ins and client authentications
+	cors             *cors.Policy   // other origins allowed to call the endpoints; nil allows none
+	onFailure        func(*http.Request, Failure)
 }
 
 // AuthorizationServerConfig contains server configuration
@@ -198,6 +207,9 @@ type DeviceCode struct {
 	Scope           string    `json:"scope"`
 	UserID          string    `json:"user_id,omitempty"`
 	Authorized      bool      `json:"authorized"`
+	Denied          bool      `json:"denied"`
+	CreatedAt       time.Time `json:"created_at"`
+	lastPolled      time.Time
 }
 
 // TokenGenerator interface for generating tokens
@@ -337,7 +349,10 @@ func NewAuthorizationServer(config *AuthorizationServerConfig, logger *logging.L
 		config.ResponseTypesSupported = []string{"code"}
 	}
 	if len(config.GrantTypesSupported) == 0 {
-		config.GrantTypesSupported = []string{"authorization_code", "client_credentials", "refresh_token"}
+		config.GrantTypesSupported = []string{"authorization_code", "client_credentials", "refresh_token", DeviceCodeGrantType}
+	}
+	if config.DeviceAuthorizationEndpoint == "" {
+		config.DeviceAuthorizationEndpoint = "/oauth/device_authorization"
 	}
 	if len(config.TokenEndpointAuthMethodsSupported) == 0 {
 		config.TokenEndpointAuthMethodsSupported = []string{"client_secret_post", "client_secret_basic", "none"}
@@ -346,7 +361,7 @@ func NewAuthorizationServer(config *AuthorizationServerConfig, logger *logging.L
 		config.CodeChallengeMethodsSupported = []string{"plain", "S256"}
 	}
 	if len(config.ScopesSupported) == 0 {
-		config.ScopesSupported = []string{"mcp:*", "mcp:tools", "mcp:resources", "mcp:prompts"}
+		config.ScopesSupported = []string{"mcp:*", "mcp:tools", "mcp:resources", "mcp:prompts", "mcp:tasks:read", "mcp:tasks:write", "mcp:admin:read", "mcp:admin:write"}
 	}
 
 	return &AuthorizationServer{
@@ -356,6 +371,7 @@ func NewAuthorizationServer(config *AuthorizationServerConfig, logger *logging.L
 		accessTokens:     make(map[string]*AccessToken),
 		refreshTokens:    make(map[string]*RefreshToken),
 		deviceCodes:      make(map[string]*DeviceCode),
+		consents:         make(map[string]*Consent),
 		logger:           logger,
 		tokenGenerator:   &DefaultTokenGenerator{},
 		codeVerifier:     &DefaultCodeVerifier{},
@@ -367,6 +383,61 @@ func NewAuthorizationServer(config *AuthorizationServerConfig, logger *logging.L
 	}
 }
 
+// SetTokenLifetimes sets how long access tokens, refresh tokens and authorization codes are
+// valid. A zero duration keeps the default.
+func (s *AuthorizationServer) SetTokenLifetimes(access, refresh, code time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if access > 0 {
+		s.tokenLifetime = access
+	}
+	if refresh > 0 {
+		s.refreshLifetime = refresh
+	}
+	if code > 0 {
+		s.authCodeLifetime = code
+	}
+}
+
+// TokenLifetime returns how long access tokens are valid
+func (s *AuthorizationServer) TokenLifetime() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tokenLifetime
+}
+
+// SetTokenSigner makes the server issue access tokens as JWTs signed by signer, which
+// resource servers can validate offline with the keys at the JWKS URI
+func (s *AuthorizationServer) SetTokenSigner(signer *TokenSigner, jwksURI string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenSigner = signer
+	s.config.JWKSUri = jwksURI
+}
+
+// TokenSigner returns the signer of access tokens, or nil when they are opaque
+func (s *AuthorizationServer) TokenSigner() *TokenSigner {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tokenSigner
+}
+
+// SetCORSPolicy sets the origins whose pages may call the endpoints from the browser
+func (s *AuthorizationServer) SetCORSPolicy(policy *cors.Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cors = policy
+}
+
+func (s *AuthorizationServer) corsPolicy() *cors.Policy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.cors
+}
+
 // RegisterClient registers a new OAuth client
 func (s *AuthorizationServer) RegisterClient(config *OAuthConfig) (*OAuthClient, error) {
 	s.mu.Lock()
@@ -511,6 +582,12 @@ func (s *AuthorizationServer) ValidateAccessToken(token string) (*AccessToken, e
 
 // HasScope checks if a token scope includes the required scope
 func (s *AuthorizationServer) HasScope(tokenScope, requiredScope string) bool {
+
+	return ScopeGranted(tokenScope, requiredScope)
+}
+
+// ScopeGranted checks if a space-separated scope list includes the required scope
+func ScopeGranted(tokenScope, requiredScope string) bool {
 	if tokenScope == "" {
 
 		return false
@@ -554,6 +631,13 @@ func (s *AuthorizationServer) CleanupExpiredTokens() {
 			delete(s.authCodes, code)
 		}
 	}
+
+	// Clean up expired device authorizations
+	for code, device := range s.deviceCodes {
+		if now.After(device.ExpiresAt) {
+			delete(s.deviceCodes, code)
+		}
+	}
 }
 
 // GetTokenCount returns the number of active tokens (for monitoring)
@@ -0,0 +1,301 @@
+// internal/auth/throttle.go
+package auth
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// throttledError is returned while the caller is locked out
+type throttledError struct {
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+
+	return fmt.Sprintf("too many failed attempts, retry in %s", time.Duration(retryAfterSeconds(e.retryAfter))*time.Second)
+}
+
+// retryAfterSeconds rounds a lockout up to whole seconds, as Retry-After takes them
+func retryAfterSeconds(wait time.Duration) int {
+
+	return int(math.Ceil(wait.Seconds()))
+}
+
+// Lockout describes an IP address, user or client locked out for failing to authenticate
+type Lockout struct {
+	Kind     string        `json:"kind"` // ip, user or client
+	Name     string        `json:"name"`
+	Failures int           `json:"failures"`
+	Lockouts int           `json:"lockouts"` // in a row, this one included
+	Duration time.Duration `json:"duration"`
+	Until    time.Time     `json:"until"`
+}
+
+// Failure describes a failed sign-in or client authentication
+type Failure struct {
+	Kind   string // user or client
+	Name   string
+	Reason string
+}
+
+type throttleEntry struct {
+	failures    int
+	windowStart time.Time
+	lastFailure time.Time
+	lockouts    int
+	lockedUntil time.Time
+}
+
+// LoginThrottle slows down password and client secret guessing. Failed sign-ins and client
+// authentications count against the IP address they came from and the user or client tried;
+// once either fails max failures times within the window it is locked out. Each lockout in a
+// row lasts twice as long as the one before, up to the max lockout, and the count starts over
+// once the key has gone the max lockout without failing.
+type LoginThrottle struct {
+	mu          sync.Mutex
+	maxFailures int
+	window      time.Duration
+	lockout     time.Duration
+	maxLockout  time.Duration
+	entries     map[string]*throttleEntry
+	lastPrune   time.Time
+	onLockout   func(Lockout)
+	now         func() time.Time
+}
+
+// NewLoginThrottle returns a throttle with the given limits; zero values take the defaults
+func NewLoginThrottle(maxFailures int, window, lockout, maxLockout time.Duration) *LoginThrottle {
+	if maxFailures <= 0 {
+		maxFailures = constants.OAuthMaxAuthFailures
+	}
+	if window <= 0 {
+		window = constants.OAuthFailureWindow
+	}
+	if lockout <= 0 {
+		lockout = constants.OAuthLockout
+	}
+	if maxLockout <= 0 {
+		maxLockout = constants.OAuthMaxLockout
+	}
+	if maxLockout < lockout {
+		maxLockout = lockout
+	}
+
+	return &LoginThrottle{
+		maxFailures: maxFailures,
+		window:      window,
+		lockout:     lockout,
+		maxLockout:  maxLockout,
+		entries:     make(map[string]*throttleEntry),
+		now:         time.Now,
+	}
+}
+
+// OnLockout sets a function called, outside the throttle's lock, whenever a key is locked out
+func (t *LoginThrottle) OnLockout(fn func(Lockout)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onLockout = fn
+}
+
+func throttleKey(kind, name string) string {
+
+	return kind + ":" + name
+}
+
+// remoteIP returns the address a request came from. X-Forwarded-For is not used, since
+// a client could set it to a new value for every guess.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// forgettable reports whether an entry has been quiet long enough to start over; t.mu is held
+func (t *LoginThrottle) forgettable(entry *throttleEntry, now time.Time) bool {
+
+	return now.After(entry.lockedUntil) && now.Sub(entry.lastFailure) > max(t.window, t.maxLockout)
+}
+
+// retryAfter returns how long the longest running lockout of keys has left, or zero when none
+// of them is locked out. A nil throttle never locks anyone out.
+func (t *LoginThrottle) retryAfter(keys ...string) time.Duration {
+	if t == nil {
+
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	var wait time.Duration
+	for _, key := range keys {
+		if entry, exists := t.entries[key]; exists && entry.lockedUntil.After(now) {
+			wait = max(wait, entry.lockedUntil.Sub(now))
+		}
+	}
+
+	return wait
+}
+
+// failure counts a failed attempt against each key and locks out those that reached the limit
+func (t *LoginThrottle) failure(keys ...string) {
+	if t == nil {
+
+		return
+	}
+	t.mu.Lock()
+	now := t.now()
+	t.pruneLocked(now)
+	var lockouts []Lockout
+	for _, key := range keys {
+		entry, exists := t.entries[key]
+		if !exists || t.forgettable(entry, now) {
+			entry = &throttleEntry{}
+			t.entries[key] = entry
+		}
+		if now.Sub(entry.windowStart) > t.window {
+			entry.failures = 0
+			entry.windowStart = now
+		}
+		entry.failures++
+		entry.lastFailure = now
+		if entry.failures < t.maxFailures {
+
+			continue
+		}
+
+		entry.lockouts++
+		duration := t.lockout
+		for i := 1; i < entry.lockouts && duration < t.maxLockout; i++ {
+			duration *= 2
+		}
+		duration = min(duration, t.maxLockout)
+		entry.lockedUntil = now.Add(duration)
+		kind, name, _ := strings.Cut(key, ":")
+		lockouts = append(lockouts, Lockout{
+			Kind:     kind,
+			Name:     name,
+			Failures: entry.failures,
+			Lockouts: entry.lockouts,
+			Duration: duration,
+			Until:    entry.lockedUntil,
+		})
+		entry.failures = 0
+		entry.windowStart = time.Time{}
+	}
+	onLockout := t.onLockout
+	t.mu.Unlock()
+
+	if onLockout != nil {
+		for _, lockout := range lockouts {
+			onLockout(lockout)
+		}
+	}
+}
+
+// success forgets the failures counted against keys
+func (t *LoginThrottle) success(keys ...string) {
+	if t == nil {
+
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range keys {
+		delete(t.entries, key)
+	}
+}
+
+// pruneLocked drops the entries that have been quiet long enough, at most once a window, so
+// guesses at made-up names don't pile up; t.mu is held
+func (t *LoginThrottle) pruneLocked(now time.Time) {
+	if now.Sub(t.lastPrune) < t.window {
+
+		return
+	}
+	t.lastPrune = now
+	for key, entry := range t.entries {
+		if t.forgettable(entry, now) {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// OnFailure sets a function called with every failed sign-in and client authentication,
+// whether or not a throttle counts them
+func (s *AuthorizationServer) OnFailure(fn func(*http.Request, Failure)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onFailure = fn
+}
+
+// failed reports a failed sign-in or client authentication to the OnFailure function
+func (s *AuthorizationServer) failed(r *http.Request, kind, name, reason string) {
+	s.mu.RLock()
+	onFailure := s.onFailure
+	s.mu.RUnlock()
+	if onFailure != nil {
+		onFailure(r, Failure{Kind: kind, Name: name, Reason: reason})
+	}
+}
+
+// SetLoginThrottle limits failed sign-ins at the authorization endpoint and failed client
+// authentications at the token, device authorization and revocation endpoints
+func (s *AuthorizationServer) SetLoginThrottle(throttle *LoginThrottle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttle = throttle
+}
+
+func (s *AuthorizationServer) loginThrottle() *LoginThrottle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.throttle
+}
+
+// authenticateClient validates client credentials like ValidateClient, refusing callers that
+// are locked out and counting the failures against the caller's address and the client
+func (s *AuthorizationServer) authenticateClient(r *http.Request, clientID, clientSecret string) (*OAuthClient, error) {
+	throttle := s.loginThrottle()
+	keys := []string{throttleKey("ip", remoteIP(r)), throttleKey("client", clientID)}
+	if wait := throttle.retryAfter(keys...); wait > 0 {
+
+		return nil, &throttledError{retryAfter: wait}
+	}
+	client, err := s.ValidateClient(clientID, clientSecret)
+	if err != nil {
+		s.logger.Warning("Failed authentication as client '%s' from %s", clientID, remoteIP(r))
+		throttle.failure(keys...)
+		s.failed(r, "client", clientID, "invalid client credentials")
+
+		return nil, err
+	}
+	throttle.success(keys[1])
+
+	return client, nil
+}
+
+// sendClientError answers a token request whose client failed to authenticate
+func (s *AuthorizationServer) sendClientError(w http.ResponseWriter, err error) {
+	if throttled, ok := err.(*throttledError); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(throttled.retryAfter)))
+		s.writeTokenError(w, http.StatusTooManyRequests, "temporarily_unavailable", err.Error())
+
+		return
+	}
+	s.sendTokenError(w, "invalid_client", err.Error())
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginThrottleBackoff(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	throttle := NewLoginThrottle(3, time.Minute, time.Minute, 5*time.Minute)
+	throttle.now = func() time.Time { return now }
+	var lockouts []Lockout
+	throttle.OnLockout(func(lockout Lockout) { lockouts = append(lockouts, lockout) })
+
+	lockOut := func() {
+		for i := 0; i < 3; i++ {
+			throttle.failure("ip:10.0.0.1", "user:alice")
+		}
+	}
+
+	throttle.failure("user:alice")
+	throttle.failure("user:alice")
+	if wait := throttle.retryAfter("user:alice"); wait != 0 {
+		t.Fatalf("Expected no lockout below the limit, got %s", wait)
+	}
+	// Failures outside the window don't add up
+	now = now.Add(2 * time.Minute)
+	throttle.failure("user:alice")
+	if wait := throttle.retryAfter("user:alice"); wait != 0 {
+		t.Fatalf("Expected old failures to be forgotten, got %s", wait)
+	}
+
+	now = now.Add(2 * time.Minute)
+	lockOut()
+	if wait := throttle.retryAfter("ip:10.0.0.1"); wait != time.Minute {
+		t.Fatalf("Expected a one minute lockout, got %s", wait)
+	}
+	if len(lockouts) != 2 || lockouts[0].Kind != "ip" || lockouts[1].Name != "alice" || lockouts[1].Failures != 3 {
+		t.Fatalf("Expected the IP address and the user to be reported, got %+v", lockouts)
+	}
+
+	// Each lockout in a row doubles, up to the maximum
+	for _, expected := range []time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute} {
+		now = throttle.entries["user:alice"].lockedUntil
+		lockOut()
+		if wait := throttle.retryAfter("ip:10.0.0.1", "user:alice"); wait != expected {
+			t.Fatalf("Expected a %s lockout, got %s", expected, wait)
+		}
+	}
+
+	// A quiet spell starts the count over
+	now = now.Add(time.Hour)
+	lockOut()
+	if wait := throttle.retryAfter("user:alice"); wait != time.Minute {
+		t.Errorf("Expected the backoff to start over, got %s", wait)
+	}
+	throttle.success("user:alice")
+	if wait := throttle.retryAfter("user:alice"); wait != 0 {
+		t.Errorf("Expected success to clear the user, got %s", wait)
+	}
+}
+
+func TestTokenEndpointLockout(t *testing.T) {
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	authServer.SetLoginThrottle(NewLoginThrottle(2, time.Minute, time.Minute, time.Hour))
+	var failures []Failure
+	authServer.OnFailure(func(_ *http.Request, failure Failure) { failures = append(failures, failure) })
+	client, err := authServer.RegisterClient(&OAuthConfig{
+		ClientID:     "ci",
+		ClientSecret: "ci-secret",
+		GrantTypes:   []string{"client_credentials"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to register client: %v", err)
+	}
+
+	token := func(secret string) *httptest.ResponseRecorder {
+		form := url.Values{"grant_type": {"client_credentials"}, "client_id": {"ci"}, "client_secret": {secret}}
+		req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		authServer.HandleToken(recorder, req)
+
+		return recorder
+	}
+
+	if recorder := token(client.Secret); recorder.Code != http.StatusOK {
+		t.Fatalf("Expected a token, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	for i := 0; i < 2; i++ {
+		if recorder := token("wrong"); recorder.Code != http.StatusBadRequest {
+			t.Fatalf("Expected invalid_client, got %d", recorder.Code)
+		}
+	}
+	recorder := token(client.Secret)
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") != "60" {
+		t.Fatalf("Expected the locked out client to be refused, got %d (Retry-After %q)", recorder.Code, recorder.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(recorder.Body.String(), "temporarily_unavailable") {
+		t.Errorf("Expected temporarily_unavailable, got %s", recorder.Body.String())
+	}
+	// Refusals of the locked out client are not failed authentications
+	if len(failures) != 2 || failures[0] != (Failure{Kind: "client", Name: "ci", Reason: "invalid client credentials"}) {
+		t.Errorf("Expected the two failed authentications to be reported, got %+v", failures)
+	}
+}
+
+func TestSignInLockout(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	authServer.SetUserLogin(NewUserLogin(map[string]*config.User{
+		"alice": {Username: "alice", Email: "alice@example.com", PasswordHash: string(hash), Enabled: true},
+	}, nil))
+	authServer.SetLoginThrottle(NewLoginThrottle(3, time.Minute, time.Minute, time.Hour))
+	if _, err := authServer.RegisterClient(&OAuthConfig{
+		ClientID:      "web",
+		RedirectURIs:  []string{"https://app.example.com/callback"},
+		GrantTypes:    []string{"authorization_code"},
+		ResponseTypes: []string{"code"},
+	}); err != nil {
+		t.Fatalf("Failed to register client: %v", err)
+	}
+
+	signIn := func(remoteAddr, username, password string) *httptest.ResponseRecorder {
+		form := url.Values{
+			"response_type": {"code"},
+			"client_id":     {"web"},
+			"redirect_uri":  {"https://app.example.com/callback"},
+			"action":        {"login"},
+			"username":      {username},
+			"password":      {password},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/oauth/authorize", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		authServer.HandleAuthorize(recorder, req)
+
+		return recorder
+	}
+
+	// Guesses by username and by email count against the same user
+	for i, name := range []string{"alice", "ALICE@example.com", "alice"} {
+		if recorder := signIn("192.0.2.1:1234", name, "guess"); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected guess %d to be refused, got %d", i+1, recorder.Code)
+		}
+	}
+	recorder := signIn("192.0.2.1:1234", "alice", "s3cret")
+	if recorder.Code != http.StatusTooManyRequests || !strings.Contains(recorder.Body.String(), "Too many failed sign-ins") {
+		t.Fatalf("Expected the lockout page, got %d", recorder.Code)
+	}
+	// The user stays locked out from another address
+	if recorder := signIn("198.51.100.7:1234", "alice", "s3cret"); recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the user to be locked out everywhere, got %d", recorder.Code)
+	}
+	// Other users at another address are not affected
+	if recorder := signIn("198.51.100.7:1234", "bob", "guess"); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a different user to be checked, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,310 @@
+// internal/auth/token_signer.go
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// Algorithms access tokens can be signed with. Any other algorithm issues opaque tokens
+// that only the proxy can validate.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// SignedTokenAlgorithm reports whether access tokens are signed with an algorithm others can
+// verify with the published keys
+func SignedTokenAlgorithm(algorithm string) bool {
+
+	return algorithm == AlgorithmRS256 || algorithm == AlgorithmES256
+}
+
+// signingKey is one key access tokens are signed with
+type signingKey struct {
+	id      string
+	key     crypto.Signer
+	created time.Time
+}
+
+// TokenSigner signs access tokens as JWTs with RS256 or ES256 and publishes the keys they are
+// verified with. The current key is replaced by a new one every rotation interval; a
+// replaced key stays published for as long as the tokens it signed may be valid.
+type TokenSigner struct {
+	mu        sync.Mutex
+	algorithm string
+	dir       string // where keys are kept across restarts; empty keeps them in memory
+	rotation  time.Duration
+	retention time.Duration
+	keys      []*signingKey // oldest first, the last one is current
+}
+
+// NewTokenSigner loads the keys for the algorithm kept in dir, and creates the first one when
+// there are none. A zero rotation never replaces the current key; retention is the lifetime
+// of the tokens signed.
+func NewTokenSigner(algorithm, dir string, rotation, retention time.Duration) (*TokenSigner, error) {
+	if !SignedTokenAlgorithm(algorithm) {
+
+		return nil, fmt.Errorf("unsupported token signing algorithm '%s': use %s or %s", algorithm, AlgorithmRS256, AlgorithmES256)
+	}
+	s := &TokenSigner{algorithm: algorithm, dir: dir, rotation: rotation, retention: retention}
+	if dir != "" {
+		if err := os.MkdirAll(dir, constants.SecretDirMode); err != nil {
+
+			return nil, fmt.Errorf("failed to create token signing key directory: %w", err)
+		}
+		if err := s.loadKeys(); err != nil {
+
+			return nil, err
+		}
+	}
+	if err := s.rotateLocked(time.Now()); err != nil {
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// loadKeys reads the keys kept for the algorithm. Keys of another algorithm, left from before
+// the algorithm was changed, are ignored.
+func (s *TokenSigner) loadKeys() error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.pem"))
+	if err != nil {
+
+		return fmt.Errorf("failed to list token signing keys: %w", err)
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+
+			return fmt.Errorf("failed to read token signing key: %w", err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+
+			return fmt.Errorf("token signing key %s is not PEM encoded", file)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+
+			return fmt.Errorf("invalid token signing key %s: %w", file, err)
+		}
+		key, ok := parsed.(crypto.Signer)
+		if !ok || !s.matchesAlgorithm(key) {
+			continue
+		}
+		s.keys = append(s.keys, &signingKey{id: keyThumbprint(key.Public()), key: key, created: info.ModTime()})
+	}
+	sort.Slice(s.keys, func(i, j int) bool { return s.keys[i].created.Before(s.keys[j].created) })
+
+	return nil
+}
+
+func (s *TokenSigner) matchesAlgorithm(key crypto.Signer) bool {
+	switch public := key.Public().(type) {
+	case *rsa.PublicKey:
+
+		return s.algorithm == AlgorithmRS256
+	case *ecdsa.PublicKey:
+
+		return s.algorithm == AlgorithmES256 && public.Curve == elliptic.P256()
+	}
+
+	return false
+}
+
+// rotateLocked creates a key when there is none or the current one is due to be replaced, and
+// drops the keys no token that is still valid was signed with
+func (s *TokenSigner) rotateLocked(now time.Time) error {
+	if len(s.keys) == 0 || (s.rotation > 0 && now.Sub(s.keys[len(s.keys)-1].created) >= s.rotation) {
+		key, err := s.generateKey(now)
+		if err != nil {
+
+			return err
+		}
+		s.keys = append(s.keys, key)
+	}
+	// A key was last used when the one after it was created
+	for len(s.keys) > 1 && now.Sub(s.keys[1].created) > s.retention {
+		if s.dir != "" {
+			_ = os.Remove(filepath.Join(s.dir, s.keys[0].id+".pem"))
+		}
+		s.keys = s.keys[1:]
+	}
+
+	return nil
+}
+
+func (s *TokenSigner) generateKey(now time.Time) (*signingKey, error) {
+	var key crypto.Signer
+	var err error
+	if s.algorithm == AlgorithmRS256 {
+		key, err = rsa.GenerateKey(rand.Reader, constants.RSAKeyBits)
+	} else {
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to generate token signing key: %w", err)
+	}
+	generated := &signingKey{id: keyThumbprint(key.Public()), key: key, created: now}
+	if s.dir == "" {
+
+		return generated, nil
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to encode token signing key: %w", err)
+	}
+	file := filepath.Join(s.dir, generated.id+".pem")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), constants.SecretFileMode); err != nil {
+
+		return nil, fmt.Errorf("failed to save token signing key: %w", err)
+	}
+	// The file's modification time records when the key was created
+	_ = os.Chtimes(file, now, now)
+
+	return generated, nil
+}
+
+// Sign returns a JWT of the claims, signed with the current key
+func (s *TokenSigner) Sign(claims map[string]interface{}) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rotateLocked(time.Now()); err != nil {
+
+		return "", err
+	}
+	current := s.keys[len(s.keys)-1]
+
+	// RFC 9068 types JWT access tokens as at+jwt
+	header, err := json.Marshal(map[string]string{"alg": s.algorithm, "typ": "at+jwt", "kid": current.id})
+	if err != nil {
+
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+
+		return "", fmt.Errorf("failed to encode access token: %w", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch key := current.key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		// JWS takes the two integers of an ECDSA signature side by side, each at full size
+		var r, sig *big.Int
+		r, sig, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		if err == nil {
+			signature = make([]byte, 2*constants.ES256CoordinateSize)
+			r.FillBytes(signature[:constants.ES256CoordinateSize])
+			sig.FillBytes(signature[constants.ES256CoordinateSize:])
+		}
+	}
+	if err != nil {
+
+		return "", fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// JWKs returns the public keys access tokens are verified with, the current one last
+func (s *TokenSigner) JWKs() []map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.rotateLocked(time.Now())
+
+	keys := make([]map[string]string, 0, len(s.keys))
+	for _, key := range s.keys {
+		jwk := publicJWK(key.key.Public())
+		jwk["kid"] = key.id
+		jwk["alg"] = s.algorithm
+		jwk["use"] = "sig"
+		keys = append(keys, jwk)
+	}
+
+	return keys
+}
+
+// publicJWK returns the members of a public key's JWK that its RFC 7638 thumbprint covers
+func publicJWK(public crypto.PublicKey) map[string]string {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+
+		return map[string]string{
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		x := make([]byte, constants.ES256CoordinateSize)
+		y := make([]byte, constants.ES256CoordinateSize)
+		key.X.FillBytes(x)
+		key.Y.FillBytes(y)
+
+		return map[string]string{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(x),
+			"y":   base64.RawURLEncoding.EncodeToString(y),
+		}
+	}
+
+	return map[string]string{}
+}
+
+// keyThumbprint identifies a key by its RFC 7638 thumbprint: the hash of its required JWK
+// members, in lexical order
+func keyThumbprint(public crypto.PublicKey) string {
+	jwk := publicJWK(public)
+	names := make([]string, 0, len(jwk))
+	for name := range jwk {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	members := make([]string, 0, len(names))
+	for _, name := range names {
+		members = append(members, fmt.Sprintf("%q:%q", name, jwk[name]))
+	}
+	sum := sha256.Sum256([]byte("{" + strings.Join(members, ",") + "}"))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// WriteJWKS serves keys as a JSON Web Key Set
+func WriteJWKS(w http.ResponseWriter, keys []map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
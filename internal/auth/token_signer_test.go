@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+// verifyWithJWKS validates a token the way a resource server does offline: with the key of
+// the published set its kid names
+func verifyWithJWKS(t *testing.T, token string, keys []map[string]string) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a JWT, got %q", token)
+	}
+	var header map[string]string
+	data, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	if err := json.Unmarshal(data, &header); err != nil {
+		t.Fatalf("Invalid header: %v", err)
+	}
+	var jwk map[string]string
+	for _, key := range keys {
+		if key["kid"] == header["kid"] {
+			jwk = key
+		}
+	}
+	if jwk == nil {
+		t.Fatalf("No published key has kid %s", header["kid"])
+	}
+	decode := func(member string) *big.Int {
+		value, _ := base64.RawURLEncoding.DecodeString(jwk[member])
+
+		return new(big.Int).SetBytes(value)
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	switch header["alg"] {
+	case AlgorithmRS256:
+		key := &rsa.PublicKey{N: decode("n"), E: int(decode("e").Int64())}
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			t.Fatalf("Invalid RS256 signature: %v", err)
+		}
+	case AlgorithmES256:
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: decode("x"), Y: decode("y")}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			t.Fatal("Invalid ES256 signature")
+		}
+	default:
+		t.Fatalf("Unexpected algorithm %s", header["alg"])
+	}
+
+	var claims map[string]interface{}
+	data, _ = base64.RawURLEncoding.DecodeString(parts[1])
+	if err := json.Unmarshal(data, &claims); err != nil {
+		t.Fatalf("Invalid payload: %v", err)
+	}
+
+	return claims
+}
+
+func TestTokenSigner(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmES256} {
+		t.Run(algorithm, func(t *testing.T) {
+			signer, err := NewTokenSigner(algorithm, "", 0, time.Hour)
+			if err != nil {
+				t.Fatalf("Failed to create signer: %v", err)
+			}
+			token, err := signer.Sign(map[string]interface{}{"sub": "alice"})
+			if err != nil {
+				t.Fatalf("Failed to sign: %v", err)
+			}
+			claims := verifyWithJWKS(t, token, signer.JWKs())
+			if claims["sub"] != "alice" {
+				t.Errorf("Unexpected claims %v", claims)
+			}
+		})
+	}
+
+	if _, err := NewTokenSigner("HS256", "", 0, time.Hour); err == nil {
+		t.Error("Expected HS256 to be rejected")
+	}
+}
+
+func TestTokenSignerRotation(t *testing.T) {
+	dir := t.TempDir()
+	signer, err := NewTokenSigner(AlgorithmES256, dir, time.Hour, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	first := signer.keys[0].id
+
+	// Keys are kept, so a restarted proxy signs with the same one
+	reloaded, err := NewTokenSigner(AlgorithmES256, dir, time.Hour, 30*time.Minute)
+	if err != nil || len(reloaded.keys) != 1 || reloaded.keys[0].id != first {
+		t.Fatalf("Expected the saved key to be loaded, got %d keys (%v)", len(reloaded.keys), err)
+	}
+	// A key for another algorithm is not
+	if other, err := NewTokenSigner(AlgorithmRS256, dir, time.Hour, 30*time.Minute); err != nil || other.keys[0].id == first {
+		t.Fatalf("Expected a new RS256 key (%v)", err)
+	}
+
+	// Once due, the key is replaced and the old one stays published for the tokens it signed
+	now := time.Now()
+	if err := reloaded.rotateLocked(now.Add(61 * time.Minute)); err != nil {
+		t.Fatalf("Rotation failed: %v", err)
+	}
+	if len(reloaded.keys) != 2 || reloaded.keys[0].id != first {
+		t.Fatalf("Expected the old and a new key, got %d", len(reloaded.keys))
+	}
+	if err := reloaded.rotateLocked(now.Add(95 * time.Minute)); err != nil {
+		t.Fatalf("Rotation failed: %v", err)
+	}
+	if len(reloaded.keys) != 1 || reloaded.keys[0].id == first {
+		t.Fatalf("Expected the old key to be dropped, got %d keys", len(reloaded.keys))
+	}
+	if _, err := os.Stat(filepath.Join(dir, first+".pem")); !os.IsNotExist(err) {
+		t.Errorf("Expected the old key file to be removed (%v)", err)
+	}
+}
+
+func TestSignedAccessTokens(t *testing.T) {
+	authServer := NewAuthorizationServer(&AuthorizationServerConfig{Issuer: "https://auth.mcp-compose.local"}, logging.NewLogger("error"))
+	signer, err := NewTokenSigner(AlgorithmRS256, "", 0, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	authServer.SetTokenSigner(signer, "https://auth.mcp-compose.local/.well-known/jwks.json")
+	authServer.SetTokenLifetimes(10*time.Minute, 0, 0)
+
+	authServer.mu.Lock()
+	accessToken, err := authServer.generateAccessToken("client-1", "", "mcp:tools")
+	authServer.mu.Unlock()
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	claims := verifyWithJWKS(t, accessToken.Token, signer.JWKs())
+	if claims["iss"] != "https://auth.mcp-compose.local" || claims["sub"] != "client-1" || claims["client_id"] != "client-1" || claims["scope"] != "mcp:tools" {
+		t.Errorf("Unexpected claims %v", claims)
+	}
+	if lifetime := int64(claims["exp"].(float64) - claims["iat"].(float64)); lifetime != 600 {
+		t.Errorf("Expected a 10 minute token, got %ds", lifetime)
+	}
+
+	// The proxy validates and revokes signed tokens as it does opaque ones
+	if _, err := authServer.ValidateAccessToken(accessToken.Token); err != nil {
+		t.Errorf("Expected the token to validate: %v", err)
+	}
+	if authServer.GetMetadata().JWKSUri == "" {
+		t.Error("Expected the metadata to name the JWKS URI")
+	}
+}
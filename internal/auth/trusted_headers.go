@@ -0,0 +1,163 @@
+// internal/auth/trusted_headers.go
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+const (
+	defaultTrustedUserHeader   = "Remote-User"
+	defaultTrustedEmailHeader  = "Remote-Email"
+	defaultTrustedGroupsHeader = "Remote-Groups"
+
+	// AuthTypeTrustedHeader marks requests authenticated by an upstream reverse proxy
+	AuthTypeTrustedHeader = "trusted_header"
+)
+
+// TrustedIdentity is the user asserted by a trusted reverse proxy
+type TrustedIdentity struct {
+	Username string
+	Email    string
+	Groups   []string
+	Role     string
+	Scope    string
+}
+
+// TrustedHeaderAuthenticator accepts identity headers set by a reverse proxy on a trusted network
+type TrustedHeaderAuthenticator struct {
+	config   *config.TrustedHeaderAuthConfig
+	networks []*net.IPNet
+	users    map[string]*config.User
+	rbac     *config.RBACConfig
+}
+
+// NewTrustedHeaderAuthenticator returns nil when trusted header auth is not enabled
+func NewTrustedHeaderAuthenticator(cfg *config.TrustedHeaderAuthConfig, users map[string]*config.User, rbac *config.RBACConfig) (*TrustedHeaderAuthenticator, error) {
+	if cfg == nil || !cfg.Enabled {
+
+		return nil, nil
+	}
+
+	authenticator := &TrustedHeaderAuthenticator{
+		config: cfg,
+		users:  users,
+		rbac:   rbac,
+	}
+	for _, entry := range cfg.TrustedProxies {
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+
+			return nil, fmt.Errorf("invalid trusted proxy '%s': %w", entry, err)
+		}
+		authenticator.networks = append(authenticator.networks, network)
+	}
+
+	return authenticator, nil
+}
+
+// Authenticate returns the asserted identity, or nil when the request carries no identity
+// headers or did not come from a trusted proxy. An error means the identity is known but
+// has no role mapping and must be refused.
+func (a *TrustedHeaderAuthenticator) Authenticate(r *http.Request) (*TrustedIdentity, error) {
+	if a == nil {
+
+		return nil, nil
+	}
+
+	username := strings.TrimSpace(r.Header.Get(headerOrDefault(a.config.UserHeader, defaultTrustedUserHeader)))
+	if username == "" || !a.isTrustedPeer(r.RemoteAddr) {
+
+		return nil, nil
+	}
+
+	identity := &TrustedIdentity{
+		Username: username,
+		Email:    strings.TrimSpace(r.Header.Get(headerOrDefault(a.config.EmailHeader, defaultTrustedEmailHeader))),
+	}
+	for _, group := range strings.Split(r.Header.Get(headerOrDefault(a.config.GroupsHeader, defaultTrustedGroupsHeader)), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			identity.Groups = append(identity.Groups, group)
+		}
+	}
+
+	identity.Role = a.resolveRole(identity)
+	if identity.Role == "" {
+
+		return nil, fmt.Errorf("user '%s' has no role mapping", username)
+	}
+	if a.rbac != nil {
+		if role, exists := a.rbac.Roles[identity.Role]; exists {
+			identity.Scope = strings.Join(role.Scopes, " ")
+		}
+	}
+
+	return identity, nil
+}
+
+// isTrustedPeer reports whether the request came directly from a trusted proxy
+func (a *TrustedHeaderAuthenticator) isTrustedPeer(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+
+		return false
+	}
+	for _, network := range a.networks {
+		if network.Contains(ip) {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveRole prefers a configured user, then the first mapped group, then the default role
+func (a *TrustedHeaderAuthenticator) resolveRole(identity *TrustedIdentity) string {
+	if user, exists := a.users[identity.Username]; exists && user != nil && user.Enabled && user.Role != "" {
+
+		return user.Role
+	}
+	for _, group := range identity.Groups {
+		if role, exists := a.config.GroupRoles[group]; exists {
+
+			return role
+		}
+	}
+
+	return a.config.DefaultRole
+}
+
+// WithContext attaches the identity to ctx using the same keys as OAuth authentication
+func (identity *TrustedIdentity) WithContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, UserContextKey, identity.Username)
+	ctx = context.WithValue(ctx, ScopeContextKey, identity.Scope)
+	ctx = context.WithValue(ctx, IdentityContextKey, identity)
+
+	return context.WithValue(ctx, AuthTypeContextKey, AuthTypeTrustedHeader)
+}
+
+func headerOrDefault(header, fallback string) string {
+	if header == "" {
+
+		return fallback
+	}
+
+	return header
+}
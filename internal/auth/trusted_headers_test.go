@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestTrustedHeaderAuthenticator(t *testing.T) {
+	authenticator, err := NewTrustedHeaderAuthenticator(&config.TrustedHeaderAuthConfig{
+		Enabled:        true,
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.1.5"},
+		GroupRoles:     map[string]string{"admins": "admin"},
+		DefaultRole:    "viewer",
+	}, map[string]*config.User{
+		"alice": {Username: "alice", Role: "developer", Enabled: true},
+	}, &config.RBACConfig{
+		Enabled: true,
+		Roles: map[string]config.Role{
+			"admin":     {Scopes: []string{"mcp:*"}},
+			"developer": {Scopes: []string{"mcp:tools", "mcp:resources"}},
+			"viewer":    {Scopes: []string{"mcp:resources"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		user         string
+		groups       string
+		expectNil    bool
+		expectedRole string
+		expectScope  string
+	}{
+		{name: "configured user", remoteAddr: "10.1.2.3:5000", user: "alice", expectedRole: "developer", expectScope: "mcp:tools"},
+		{name: "group mapping", remoteAddr: "192.168.1.5:5000", user: "bob", groups: "staff, admins", expectedRole: "admin", expectScope: "mcp:*"},
+		{name: "default role", remoteAddr: "10.0.0.1:5000", user: "carol", expectedRole: "viewer", expectScope: "mcp:resources"},
+		{name: "untrusted peer ignored", remoteAddr: "203.0.113.7:5000", user: "alice", expectNil: true},
+		{name: "single trusted IP only", remoteAddr: "192.168.1.6:5000", user: "alice", expectNil: true},
+		{name: "no identity header", remoteAddr: "10.1.2.3:5000", expectNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/filesystem", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.user != "" {
+				req.Header.Set("Remote-User", tt.user)
+			}
+			if tt.groups != "" {
+				req.Header.Set("Remote-Groups", tt.groups)
+			}
+
+			identity, err := authenticator.Authenticate(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.expectNil {
+				if identity != nil {
+					t.Errorf("Expected no identity, got %+v", identity)
+				}
+
+				return
+			}
+			if identity == nil {
+				t.Fatalf("Expected identity for %s", tt.user)
+			}
+			if identity.Role != tt.expectedRole {
+				t.Errorf("Expected role %s, got %s", tt.expectedRole, identity.Role)
+			}
+			if !ScopeGranted(identity.Scope, tt.expectScope) {
+				t.Errorf("Expected scope %s in %q", tt.expectScope, identity.Scope)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// internal/authlog/authlog.go
+package authlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/notify"
+)
+
+// Failure is a failed authentication at the proxy
+type Failure struct {
+	Time   time.Time `json:"time"`
+	Client string    `json:"client"`
+	Kind   string    `json:"kind"`           // bearer (API key or access token), user or client
+	Name   string    `json:"name,omitempty"` // the user or OAuth client tried
+	Server string    `json:"server,omitempty"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Reason string    `json:"reason"`
+}
+
+// String formats the failure as a text log line. Every field is always present, in the same
+// order, with the free-form ones quoted, so a fail2ban failregex can anchor on
+// "auth failure client=<HOST> ".
+func (f Failure) String() string {
+
+	return fmt.Sprintf("%s mcp-compose auth failure client=%s kind=%s name=%s server=%s method=%s path=%s reason=%s",
+		f.Time.UTC().Format(time.RFC3339), f.Client, f.Kind, strconv.Quote(f.Name), strconv.Quote(f.Server),
+		f.Method, strconv.Quote(f.Path), strconv.Quote(f.Reason))
+}
+
+type alertState struct {
+	last time.Time
+	held int // failures since the last alert
+}
+
+// Log writes failed authentications to the auth failure log and sends auth_failure
+// notifications, at most one per client address in the alert interval. A nil log does
+// nothing.
+type Log struct {
+	mu       sync.Mutex
+	out      io.Writer // nil when only notifications are wanted
+	json     bool
+	hooks    config.NotificationsConfig
+	alerts   bool
+	interval time.Duration
+	alerted  map[string]*alertState
+	logger   *logging.Logger
+	now      func() time.Time
+	send     func(config.NotificationsConfig, notify.Event) error
+}
+
+// Open sets up the log of a compose config, resolving its path next to the compose file. It
+// returns nil when the config neither logs failed authentications nor notifies about them.
+func Open(cfg *config.ComposeConfig, configFile string, logger *logging.Logger) (*Log, error) {
+	settings := cfg.Logging.AuthFailures
+	alerts := notify.Subscribed(cfg.Notifications, notify.AuthFailure)
+	if settings == nil && !alerts {
+
+		return nil, nil
+	}
+	l := &Log{
+		hooks:    cfg.Notifications,
+		alerts:   alerts,
+		interval: constants.AuthFailureAlertInterval,
+		alerted:  make(map[string]*alertState),
+		logger:   logger,
+		now:      time.Now,
+		send:     notify.Send,
+	}
+	if settings == nil {
+
+		return l, nil
+	}
+	l.json = settings.Format == "json"
+	if d, err := time.ParseDuration(settings.AlertInterval); err == nil && d > 0 {
+		l.interval = d
+	}
+	if settings.Path == "" {
+		l.out = os.Stderr
+
+		return l, nil
+	}
+	path := config.ResolveEnvFile(configFile, settings.Path)
+	if err := os.MkdirAll(filepath.Dir(path), constants.DefaultDirMode); err != nil {
+
+		return nil, fmt.Errorf("failed to create auth failure log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, constants.SecretFileMode)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to open auth failure log: %w", err)
+	}
+	l.out = file
+
+	return l, nil
+}
+
+// Record logs a failed authentication and alerts about it
+func (l *Log) Record(failure Failure) {
+	if l == nil {
+
+		return
+	}
+	if failure.Time.IsZero() {
+		failure.Time = l.now()
+	}
+	if l.out != nil {
+		line := failure.String()
+		if l.json {
+			data, err := json.Marshal(failure)
+			if err != nil {
+				l.logger.Warning("Failed to marshal auth failure: %v", err)
+
+				return
+			}
+			line = string(data)
+		}
+		l.mu.Lock()
+		_, err := io.WriteString(l.out, line+"\n")
+		l.mu.Unlock()
+		if err != nil {
+			l.logger.Warning("Failed to write auth failure log: %v", err)
+		}
+	}
+	if l.alerts {
+		l.alert(failure)
+	}
+}
+
+// alert notifies about a failure unless the client's last alert is recent, in which case the
+// failure is counted into the next one
+func (l *Log) alert(failure Failure) {
+	l.mu.Lock()
+	state, exists := l.alerted[failure.Client]
+	if exists && failure.Time.Sub(state.last) < l.interval {
+		state.held++
+		l.mu.Unlock()
+
+		return
+	}
+	if !exists {
+		if len(l.alerted) >= constants.AuthFailureAlertClients {
+			for client, other := range l.alerted {
+				if failure.Time.Sub(other.last) >= l.interval {
+					delete(l.alerted, client)
+				}
+			}
+		}
+		state = &alertState{}
+		l.alerted[failure.Client] = state
+	}
+	held := state.held
+	state.last, state.held = failure.Time, 0
+	l.mu.Unlock()
+
+	message := fmt.Sprintf("Failed authentication from %s at %s %s: %s", failure.Client, failure.Method, failure.Path, failure.Reason)
+	if failure.Name != "" {
+		message = fmt.Sprintf("Failed authentication from %s as %s '%s': %s", failure.Client, failure.Kind, failure.Name, failure.Reason)
+	}
+	if held > 0 {
+		message += fmt.Sprintf(" (%d more failures since the last alert)", held)
+	}
+	event := notify.Event{Type: notify.AuthFailure, Server: failure.Server, Client: failure.Client, Message: message, Time: failure.Time}
+	go func() {
+		if err := l.send(l.hooks, event); err != nil {
+			l.logger.Warning("Failed to send auth_failure notification for %s: %v", failure.Client, err)
+		}
+	}()
+}
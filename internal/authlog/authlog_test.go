@@ -0,0 +1,116 @@
+package authlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/notify"
+)
+
+var failureTime = time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+
+func TestTextLine(t *testing.T) {
+	failure := Failure{
+		Time:   failureTime,
+		Client: "203.0.113.7",
+		Kind:   "bearer",
+		Server: "filesystem",
+		Method: "POST",
+		Path:   "/filesystem",
+		Reason: "invalid access token or API key",
+	}
+	expected := `2026-10-16T12:30:00Z mcp-compose auth failure client=203.0.113.7 kind=bearer name="" server="filesystem" method=POST path="/filesystem" reason="invalid access token or API key"`
+	if got := failure.String(); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// Values a client chose cannot forge another line or client
+	failure.Name = "x\" client=198.51.100.1\nforged"
+	if line := failure.String(); strings.Contains(line, "\n") || strings.Count(line, "client=") != 2 || !strings.Contains(line, "client=203.0.113.7 ") {
+		t.Errorf("Expected the name to be quoted, got %s", line)
+	}
+}
+
+func TestOpenWritesFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.ComposeConfig{Logging: config.LoggingConfig{AuthFailures: &config.AuthFailureLog{Path: "logs/auth.log", Format: "json"}}}
+	log, err := Open(cfg, filepath.Join(dir, "mcp-compose.yaml"), logging.NewLogger("error"))
+	if err != nil {
+		t.Fatalf("Failed to open the log: %v", err)
+	}
+	log.Record(Failure{Time: failureTime, Client: "192.0.2.4", Kind: "user", Name: "alice", Method: "POST", Path: "/oauth/authorize", Reason: "invalid username or password"})
+
+	data, err := os.ReadFile(filepath.Join(dir, "logs", "auth.log"))
+	if err != nil {
+		t.Fatalf("Failed to read the log: %v", err)
+	}
+	var failure Failure
+	if err := json.Unmarshal(bytes.TrimSpace(data), &failure); err != nil || failure.Client != "192.0.2.4" || failure.Name != "alice" {
+		t.Errorf("Expected a JSON line for the failure, got %s (%v)", data, err)
+	}
+
+	if log, _ := Open(&config.ComposeConfig{}, "", nil); log != nil {
+		t.Error("Expected no log without the section or auth_failure hooks")
+	}
+	// Hooks that list no events do not get auth failures
+	hooks := config.NotificationsConfig{Webhooks: []config.WebhookNotification{{URL: "https://hooks.example.com"}}}
+	if log, _ := Open(&config.ComposeConfig{Notifications: hooks}, "", nil); log != nil {
+		t.Error("Expected no log for hooks that did not ask for auth failures")
+	}
+}
+
+func TestAlertsHeldBack(t *testing.T) {
+	sent := make(chan notify.Event, 10)
+	log := &Log{
+		alerts:   true,
+		interval: time.Minute,
+		alerted:  make(map[string]*alertState),
+		logger:   logging.NewLogger("error"),
+		send: func(_ config.NotificationsConfig, event notify.Event) error {
+			sent <- event
+
+			return nil
+		},
+	}
+	record := func(client string, after time.Duration) {
+		log.Record(Failure{Time: failureTime.Add(after), Client: client, Kind: "bearer", Method: "GET", Path: "/api/servers", Reason: "API key mismatch"})
+	}
+	receive := func() notify.Event {
+		select {
+		case event := <-sent:
+
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("Expected an alert")
+
+			return notify.Event{}
+		}
+	}
+
+	record("203.0.113.7", 0)
+	if event := receive(); event.Type != notify.AuthFailure || event.Client != "203.0.113.7" {
+		t.Errorf("Expected an auth_failure alert for the client, got %+v", event)
+	}
+	record("203.0.113.7", 10*time.Second)
+	record("203.0.113.7", 20*time.Second)
+	record("198.51.100.1", 30*time.Second)
+	if event := receive(); event.Client != "198.51.100.1" {
+		t.Errorf("Expected other clients to be alerted about at once, got %+v", event)
+	}
+	record("203.0.113.7", 2*time.Minute)
+	if event := receive(); !strings.Contains(event.Message, "(2 more failures since the last alert)") {
+		t.Errorf("Expected the held back failures to be counted, got %q", event.Message)
+	}
+	select {
+	case event := <-sent:
+		t.Errorf("Expected no more alerts, got %+v", event)
+	default:
+	}
+}
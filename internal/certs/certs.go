@@ -0,0 +1,351 @@
+// internal/certs/certs.go
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Source provides the certificate of a TLS listener, either from files it reloads when they
+// change or from an ACME CA through autocert
+type Source struct {
+	logger   *logging.Logger
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	acme    *autocert.Manager
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewSource loads a listener's certificate. Relative paths are resolved against baseDir, the
+// directory of the compose file.
+func NewSource(tlsConfig config.TLSConfig, baseDir string, logger *logging.Logger) (*Source, error) {
+	source := &Source{logger: logger, done: make(chan struct{})}
+
+	if acmeConfig := tlsConfig.ACME; acmeConfig != nil {
+		cacheDir := acmeConfig.CacheDir
+		if cacheDir == "" {
+			cacheDir = constants.ACMECacheDir
+		}
+		cacheDir = resolve(baseDir, cacheDir)
+		if err := os.MkdirAll(cacheDir, constants.SecretDirMode); err != nil {
+
+			return nil, fmt.Errorf("failed to create ACME cache directory: %w", err)
+		}
+		source.acme = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      autocert.DirCache(cacheDir),
+			HostPolicy: autocert.HostWhitelist(acmeConfig.Domains...),
+			Email:      acmeConfig.Email,
+		}
+		if acmeConfig.DirectoryURL != "" {
+			source.acme.Client = &acme.Client{DirectoryURL: acmeConfig.DirectoryURL}
+		}
+
+		return source, nil
+	}
+
+	source.certFile = resolve(baseDir, tlsConfig.CertFile)
+	source.keyFile = resolve(baseDir, tlsConfig.KeyFile)
+	if err := source.reload(); err != nil {
+
+		return nil, err
+	}
+	if err := source.watch(); err != nil {
+		// Without a watcher the certificate still works; it just needs a restart to change
+		logger.Warning("Certificate %s will not be reloaded on change: %v", source.certFile, err)
+	}
+
+	return source, nil
+}
+
+// TLSConfig returns the server configuration for the listener
+func (s *Source) TLSConfig(minVersion string) *tls.Config {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if minVersion == "1.3" {
+		tlsConfig.MinVersion = tls.VersionTLS13
+	}
+	if s.acme != nil {
+		acmeConfig := s.acme.TLSConfig()
+		tlsConfig.GetCertificate = acmeConfig.GetCertificate
+		tlsConfig.NextProtos = acmeConfig.NextProtos
+	} else {
+		tlsConfig.GetCertificate = s.getCertificate
+		tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+	}
+
+	return tlsConfig
+}
+
+// ChallengeHandler answers ACME HTTP-01 challenges and redirects everything else to HTTPS;
+// it is nil for certificates read from files
+func (s *Source) ChallengeHandler() http.Handler {
+	if s.acme == nil {
+
+		return nil
+	}
+
+	return s.acme.HTTPHandler(nil)
+}
+
+// Close stops watching the certificate files
+func (s *Source) Close() error {
+	if s.watcher == nil {
+
+		return nil
+	}
+	close(s.done)
+
+	return s.watcher.Close()
+}
+
+func (s *Source) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.cert, nil
+}
+
+// reload reads the key pair; on failure the current certificate is kept
+func (s *Source) reload() error {
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load certificate %s: %w", s.certFile, err)
+	}
+	s.mu.Lock()
+	s.cert = &cert
+	s.mu.Unlock()
+
+	return nil
+}
+
+// watch reloads the certificate when its files change. The directories are watched rather
+// than the files, so renewals that replace a file or swap a symlink are seen too.
+func (s *Source) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+
+		return err
+	}
+	dirs := map[string]bool{filepath.Dir(s.certFile): true, filepath.Dir(s.keyFile): true}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+
+			return err
+		}
+	}
+	s.watcher = watcher
+
+	go func() {
+		for {
+			select {
+			case <-s.done:
+
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+
+					return
+				}
+				if event.Op == fsnotify.Chmod {
+
+					continue
+				}
+				// A cert and key written one after the other fail to load as a pair until
+				// both are in place; the earlier certificate stays in use meanwhile
+				if err := s.reload(); err == nil {
+					s.logger.Info("Reloaded certificate %s", s.certFile)
+				} else {
+					s.logger.Debug("Certificate not reloaded after change to %s: %v", event.Name, err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+
+					return
+				}
+				s.logger.Warning("Certificate watcher error: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ListenAndServe runs server over HTTPS when tlsConfig is enabled and over plain HTTP
+// otherwise. With ACME and an http_port, HTTP-01 challenges are answered on that port too.
+func ListenAndServe(server *http.Server, tlsConfig config.TLSConfig, baseDir string, logger *logging.Logger) error {
+	addr := server.Addr
+	if addr == "" {
+		addr = ":http"
+		if tlsConfig.Enabled {
+			addr = ":https"
+		}
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+
+		return err
+	}
+
+	return Serve(server, listener, tlsConfig, baseDir, logger)
+}
+
+// Serve is ListenAndServe on a listener the caller has already opened
+func Serve(server *http.Server, listener net.Listener, tlsConfig config.TLSConfig, baseDir string, logger *logging.Logger) error {
+	if !tlsConfig.Enabled {
+
+		return server.Serve(listener)
+	}
+
+	source, err := NewSource(tlsConfig, baseDir, logger)
+	if err != nil {
+		_ = listener.Close()
+
+		return err
+	}
+	defer func() { _ = source.Close() }()
+	server.TLSConfig = source.TLSConfig(tlsConfig.MinVersion)
+
+	if handler := source.ChallengeHandler(); handler != nil && tlsConfig.ACME.HTTPPort > 0 {
+		challengeServer := &http.Server{
+			Addr:              fmt.Sprintf(":%d", tlsConfig.ACME.HTTPPort),
+			Handler:           handler,
+			ReadHeaderTimeout: constants.ACMEChallengeTimeout,
+		}
+		go func() {
+			if err := challengeServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("ACME challenge listener on port %d failed: %v", tlsConfig.ACME.HTTPPort, err)
+			}
+		}()
+		defer func() { _ = challengeServer.Close() }()
+	}
+
+	return server.ServeTLS(listener, "", "")
+}
+
+// ClientConfig returns the TLS configuration for mcp-compose's own connections to a listener
+// served with tlsConfig, such as the dashboard's calls to the proxy over the container network.
+// The certificates in cert_file are trusted in addition to the system roots, so self-signed
+// certificates work, and the server name is taken from the certificate or the ACME domains
+// since the address used to connect is rarely on it.
+func ClientConfig(tlsConfig config.TLSConfig, baseDir string) (*tls.Config, error) {
+	clientConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if tlsConfig.ACME != nil {
+		if len(tlsConfig.ACME.Domains) > 0 {
+			clientConfig.ServerName = tlsConfig.ACME.Domains[0]
+		}
+
+		return clientConfig, nil
+	}
+
+	data, err := os.ReadFile(resolve(baseDir, tlsConfig.CertFile))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read certificate %s: %w", tlsConfig.CertFile, err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+
+			continue
+		}
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to parse certificate %s: %w", tlsConfig.CertFile, err)
+		}
+		roots.AddCert(parsed)
+		if clientConfig.ServerName == "" && len(parsed.DNSNames) > 0 {
+			clientConfig.ServerName = parsed.DNSNames[0]
+		}
+	}
+	clientConfig.RootCAs = roots
+
+	return clientConfig, nil
+}
+
+// ContainerVolumes returns the bind mounts a containerized listener needs for its certificate
+// files and ACME cache, for a compose file in hostDir that is mounted in containerDir. Relative
+// paths are mounted under containerDir, absolute ones at the same path. Directories are mounted
+// rather than files so replaced certificates are seen, except for files directly in hostDir.
+// With certOnly, only the certificate is mounted, for clients that need to trust it.
+func ContainerVolumes(tlsConfig config.TLSConfig, hostDir, containerDir string, certOnly bool) ([]string, error) {
+	if !tlsConfig.Enabled {
+
+		return nil, nil
+	}
+
+	var volumes []string
+	seen := make(map[string]bool)
+	mount := func(path, mode string) {
+		hostPath, containerPath := resolve(hostDir, path), resolve(containerDir, path)
+		if filepath.Dir(hostPath) != filepath.Clean(hostDir) {
+			hostPath, containerPath = filepath.Dir(hostPath), filepath.Dir(containerPath)
+		}
+		if !seen[hostPath] {
+			seen[hostPath] = true
+			volumes = append(volumes, fmt.Sprintf("%s:%s:%s", hostPath, containerPath, mode))
+		}
+	}
+
+	if acmeConfig := tlsConfig.ACME; acmeConfig != nil {
+		if certOnly {
+
+			return nil, nil
+		}
+		cacheDir := acmeConfig.CacheDir
+		if cacheDir == "" {
+			cacheDir = constants.ACMECacheDir
+		}
+		hostCache := resolve(hostDir, cacheDir)
+		if err := os.MkdirAll(hostCache, constants.SecretDirMode); err != nil {
+
+			return nil, fmt.Errorf("failed to create ACME cache directory: %w", err)
+		}
+		volumes = append(volumes, fmt.Sprintf("%s:%s:rw", hostCache, resolve(containerDir, cacheDir)))
+
+		return volumes, nil
+	}
+
+	mount(tlsConfig.CertFile, "ro")
+	if !certOnly {
+		mount(tlsConfig.KeyFile, "ro")
+	}
+
+	return volumes, nil
+}
+
+// resolve makes a relative path relative to baseDir
+func resolve(baseDir, path string) string {
+	if path == "" || filepath.IsAbs(path) || baseDir == "" {
+
+		return path
+	}
+
+	return filepath.Join(baseDir, path)
+}
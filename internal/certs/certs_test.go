@@ -0,0 +1,195 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+// writeSelfSigned writes a self-signed certificate for name to cert.pem and key.pem in dir
+func writeSelfSigned(t *testing.T, dir, name string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	// Key first, so the pair only loads once the certificate is written too
+	if err := os.WriteFile(filepath.Join(dir, "key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+}
+
+func servedName(t *testing.T, source *Source) string {
+	t.Helper()
+	cert, err := source.getCertificate(nil)
+	if err != nil {
+		t.Fatalf("getCertificate() error: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse served certificate: %v", err)
+	}
+
+	return leaf.Subject.CommonName
+}
+
+func TestSourceReloadsChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	writeSelfSigned(t, dir, "first.test")
+	tlsConfig := config.TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"}
+
+	source, err := NewSource(tlsConfig, dir, logging.NewLogger("error"))
+	if err != nil {
+		t.Fatalf("NewSource() error: %v", err)
+	}
+	defer func() { _ = source.Close() }()
+	if name := servedName(t, source); name != "first.test" {
+		t.Fatalf("Expected first.test to be served, got %s", name)
+	}
+
+	writeSelfSigned(t, dir, "second.test")
+	deadline := time.Now().Add(5 * time.Second)
+	for servedName(t, source) != "second.test" {
+		if time.Now().After(deadline) {
+			t.Fatal("Certificate was not reloaded after its files changed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestNewSourceRejectsMissingFiles(t *testing.T) {
+	tlsConfig := config.TLSConfig{Enabled: true, CertFile: "missing.pem", KeyFile: "missing.key"}
+	if _, err := NewSource(tlsConfig, t.TempDir(), logging.NewLogger("error")); err == nil {
+		t.Error("Expected an error for missing certificate files")
+	}
+}
+
+func TestClientConfigTrustsSelfSignedListener(t *testing.T) {
+	dir := t.TempDir()
+	writeSelfSigned(t, dir, "proxy.test")
+	tlsConfig := config.TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"}
+
+	source, err := NewSource(tlsConfig, dir, logging.NewLogger("error"))
+	if err != nil {
+		t.Fatalf("NewSource() error: %v", err)
+	}
+	defer func() { _ = source.Close() }()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = source.TLSConfig("1.2")
+	server.StartTLS()
+	defer server.Close()
+
+	clientConfig, err := ClientConfig(tlsConfig, dir)
+	if err != nil {
+		t.Fatalf("ClientConfig() error: %v", err)
+	}
+	if clientConfig.ServerName != "proxy.test" {
+		t.Errorf("Expected server name proxy.test, got %q", clientConfig.ServerName)
+	}
+
+	// The listener is reached by IP address, which is not on the certificate
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request over TLS failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", resp.StatusCode)
+	}
+
+	plain := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12}}}
+	if _, err := plain.Get(server.URL); err == nil {
+		t.Error("Expected a client without the certificate to refuse the connection")
+	}
+}
+
+func TestContainerVolumes(t *testing.T) {
+	hostDir := t.TempDir()
+	tests := []struct {
+		name     string
+		tls      config.TLSConfig
+		certOnly bool
+		expected []string
+	}{
+		{name: "disabled", tls: config.TLSConfig{CertFile: "certs/cert.pem"}},
+		{
+			name:     "relative directory",
+			tls:      config.TLSConfig{Enabled: true, CertFile: "certs/cert.pem", KeyFile: "certs/key.pem"},
+			expected: []string{filepath.Join(hostDir, "certs") + ":/app/certs:ro"},
+		},
+		{
+			name: "files next to the compose file",
+			tls:  config.TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"},
+			expected: []string{
+				filepath.Join(hostDir, "cert.pem") + ":/app/cert.pem:ro",
+				filepath.Join(hostDir, "key.pem") + ":/app/key.pem:ro",
+			},
+		},
+		{
+			name:     "certificate only",
+			tls:      config.TLSConfig{Enabled: true, CertFile: "/etc/ssl/mcp/cert.pem", KeyFile: "/etc/ssl/private/key.pem"},
+			certOnly: true,
+			expected: []string{"/etc/ssl/mcp:/etc/ssl/mcp:ro"},
+		},
+		{
+			name:     "acme cache",
+			tls:      config.TLSConfig{Enabled: true, ACME: &config.ACMEConfig{Domains: []string{"mcp.example.com"}}},
+			expected: []string{filepath.Join(hostDir, ".mcp-compose/acme") + ":/app/.mcp-compose/acme:rw"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volumes, err := ContainerVolumes(tt.tls, hostDir, "/app", tt.certOnly)
+			if err != nil {
+				t.Fatalf("ContainerVolumes() error: %v", err)
+			}
+			if len(volumes) != len(tt.expected) {
+				t.Fatalf("ContainerVolumes() = %v, want %v", volumes, tt.expected)
+			}
+			for i := range tt.expected {
+				if volumes[i] != tt.expected[i] {
+					t.Errorf("volumes[%d] = %q, want %q", i, volumes[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// internal/cmd/add.go
+package cmd
+
+import (
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/compose"
+
+	"github.com/spf13/cobra"
+)
+
+func NewAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add SERVER...",
+		Short: "Add servers from the registry to the compose file",
+		Long: `Add fully configured servers from the registry of community MCP servers
+to the compose file: image, environment placeholders for the secrets they need,
+capabilities, ports and volumes. Existing servers are left alone and comments in
+the compose file are kept.
+
+The registry is built into mcp-compose and refreshed from a remote index
+(MCP_COMPOSE_REGISTRY_URL or --registry-url); use 'mcp-compose search' to browse it.
+
+Examples:
+  mcp-compose add github
+  mcp-compose add filesystem git --dir ~/code
+  mcp-compose add postgres --name analytics-db --dry-run`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			name, _ := cmd.Flags().GetString("name")
+			dir, _ := cmd.Flags().GetString("dir")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			registryURL, _ := cmd.Flags().GetString("registry-url")
+			offline, _ := cmd.Flags().GetBool("offline")
+
+			return compose.Add(file, args, compose.AddOptions{
+				Name:        name,
+				Directory:   dir,
+				DryRun:      dryRun,
+				RegistryURL: registryURL,
+				Offline:     offline,
+			})
+		},
+	}
+	cmd.Flags().String("name", "", "Name for the server in the compose file (single server only)")
+	cmd.Flags().String("dir", "", "Host directory for servers that work on files (default: current directory)")
+	cmd.Flags().Bool("dry-run", false, "Print the resulting compose file instead of writing it")
+	cmd.Flags().String("registry-url", "", "Remote registry index to use")
+	cmd.Flags().Bool("offline", false, "Only use the built-in registry")
+
+	return cmd
+}
+
+func NewSearchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search [QUERY]",
+		Short: "Search the registry of community MCP servers",
+		Long: `List the servers 'mcp-compose add' can install whose name, description or tags
+match every word of the query, or all of them without a query.
+
+Examples:
+  mcp-compose search
+  mcp-compose search database
+  mcp-compose search git official`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registryURL, _ := cmd.Flags().GetString("registry-url")
+			offline, _ := cmd.Flags().GetBool("offline")
+
+			return compose.Search(strings.Join(args, " "), registryURL, offline)
+		},
+	}
+	cmd.Flags().String("registry-url", "", "Remote registry index to use")
+	cmd.Flags().Bool("offline", false, "Only use the built-in registry")
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+// internal/cmd/ci.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "ci",
+		SilenceUsage: true,
+		Short:        "Run the stack end-to-end for CI: up, wait, test, collect artifacts, down",
+		Long: `Bring up every server, wait for them to become healthy, run the testing
+scenarios from development.testing plus any --run commands, then tear the stack
+down. Exits non-zero if any step fails and writes a logs/results bundle.
+
+The stack runs in a project namespace of its own (ci-<time>-<pid>), so it can run
+next to a stack that is already up and next to other CI jobs on the same host.
+Servers that publish fixed host ports still need those ports free.
+
+Commands run with MCP_PROXY_URL and MCP_API_KEY set for an in-process proxy.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			commands, _ := cmd.Flags().GetStringArray("run")
+			artifacts, _ := cmd.Flags().GetString("artifacts")
+			keep, _ := cmd.Flags().GetBool("keep")
+
+			return compose.CI(file, compose.CIOptions{
+				Timeout:      timeout,
+				Commands:     commands,
+				ArtifactsDir: artifacts,
+				KeepUp:       keep,
+			})
+		},
+	}
+
+	cmd.Flags().Duration("timeout", constants.CIDefaultWaitTimeout, "How long to wait for servers to become healthy")
+	cmd.Flags().StringArray("run", nil, "Extra command to run against the stack (repeatable)")
+	cmd.Flags().String("artifacts", ".", "Directory to write the failure bundle into")
+	cmd.Flags().Bool("keep", false, "Leave the stack running after the run")
+
+	return cmd
+}
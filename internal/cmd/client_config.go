@@ -0,0 +1,68 @@
+// internal/cmd/client_config.go
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewClientConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "client-config [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Print MCP client configuration pointing at the proxy",
+		Long: `Print ready-to-paste configuration for an MCP client with an entry per server
+(or one for the aggregator endpoint) that connects through the proxy, with the
+proxy API key or OAuth client credentials filled in.
+
+Formats:
+  claude   - claude_desktop_config.json (bridged with mcp-remote)
+  cursor   - .cursor/mcp.json
+  vscode   - .vscode/mcp.json
+  generic  - endpoint list with headers and OAuth details for other clients
+
+Examples:
+  mcp-compose client-config --format cursor
+  mcp-compose client-config filesystem github --format vscode -o .vscode/mcp.json
+  mcp-compose client-config --format claude --aggregator --proxy-url http://desk:9876
+  mcp-compose client-config --format generic --oauth-client openwebui`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			format, _ := cmd.Flags().GetString("format")
+			proxyURL, _ := cmd.Flags().GetString("proxy-url")
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			oauthClient, _ := cmd.Flags().GetString("oauth-client")
+			aggregator, _ := cmd.Flags().GetBool("aggregator")
+			output, _ := cmd.Flags().GetString("output")
+
+			if aggregator && len(args) > 0 {
+
+				return fmt.Errorf("--aggregator cannot be combined with server names")
+			}
+
+			return compose.ClientConfig(file, compose.ClientConfigOptions{
+				Format:      format,
+				ProxyURL:    proxyURL,
+				APIKey:      apiKey,
+				OAuthClient: oauthClient,
+				Servers:     args,
+				Aggregator:  aggregator,
+				Output:      output,
+			})
+		},
+	}
+	cmd.Flags().String("format", "generic", "Client format: "+strings.Join(compose.ClientConfigFormats, ", "))
+	cmd.Flags().String("proxy-url", fmt.Sprintf("http://localhost:%d", constants.DefaultProxyPort), "Proxy URL as seen from the client")
+	cmd.Flags().String("api-key", "", "API key to fill in (defaults to proxy_auth.api_key)")
+	cmd.Flags().String("oauth-client", "", "Fill in the credentials of this oauth_clients entry instead of the API key")
+	cmd.Flags().Bool("aggregator", false, "One entry for the aggregator endpoint instead of one per server")
+	cmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+
+	return cmd
+}
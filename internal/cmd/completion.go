@@ -3,6 +3,12 @@ package cmd
 
 import (
 	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/config"
 
 	"github.com/spf13/cobra"
 )
@@ -37,6 +43,9 @@ PowerShell:
   # To load completions for every new session, run:
   PS> mcp-compose completion powershell > mcp-compose.ps1
   # and source this file from your PowerShell profile.
+
+Server names are completed from the compose file given with --file, or
+mcp-compose.yaml in the current directory.
 `,
 		DisableFlagsInUseLine: true,
 		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
@@ -45,7 +54,7 @@ PowerShell:
 			switch args[0] {
 			case "bash":
 
-				return cmd.Root().GenBashCompletion(os.Stdout)
+				return cmd.Root().GenBashCompletionV2(os.Stdout, true)
 			case "zsh":
 
 				return cmd.Root().GenZshCompletion(os.Stdout)
@@ -63,3 +72,47 @@ PowerShell:
 
 	return cmd
 }
+
+// completeServerNames completes the names of the servers in the compose file that are not
+// already on the command line. Remote compose files are not fetched while completing.
+func completeServerNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	file, _ := cmd.Flags().GetString("file")
+	if config.IsRemoteSource(file) {
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.LoadConfig(file)
+	if err != nil {
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for name := range cfg.Servers {
+		if strings.HasPrefix(name, toComplete) && !slices.Contains(args, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeServerName completes a single server name, for commands taking one
+func completeServerName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completeServerNames(cmd, args, toComplete)
+}
+
+// addOutputFlag adds --output to a command that prints a table, for scripts that want JSON
+// or YAML instead
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", compose.OutputTable, "Output format: "+strings.Join(compose.OutputFormats, ", "))
+	_ = cmd.RegisterFlagCompletionFunc("output", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+
+		return compose.OutputFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,217 @@
+// internal/cmd/config.go
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect, validate and change the compose file",
+	}
+	cmd.AddCommand(NewValidateCommand())
+	cmd.AddCommand(newConfigRenderCommand())
+	cmd.AddCommand(newConfigKeygenCommand())
+	cmd.AddCommand(newConfigEncryptCommand())
+	cmd.AddCommand(newConfigSetCommand())
+	cmd.AddCommand(newConfigHistoryCommand())
+	cmd.AddCommand(newConfigRollbackCommand())
+
+	return cmd
+}
+
+func newConfigKeygenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Create the age key encrypted values in the compose file are decrypted with",
+		Long: `Write a new age identity to MCP_COMPOSE_AGE_KEY_FILE, or the mcp-compose
+directory of the user config directory, and print its public key. Keep the key
+file out of git; anyone who should decrypt the compose file needs a copy, or
+their own key added as a recipient when values are encrypted.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+
+			return compose.GenerateAgeKey(force)
+		},
+	}
+	cmd.Flags().Bool("force", false, "Replace an existing key file")
+
+	return cmd
+}
+
+func newConfigEncryptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encrypt [VALUE]",
+		Short: "Encrypt a value for the compose file",
+		Long: `Encrypt a secret so it can be committed in the compose file, and print it
+tagged !encrypted, ready to paste as the value of a setting:
+
+  env:
+    GITHUB_TOKEN: !encrypted YWdlLWVuY3J5cHRpb24ub3JnL3Yx...
+
+The value is read from stdin when not given, which keeps it out of the shell
+history. It is encrypted with age (https://age-encryption.org) to the given
+recipients, or to the key mcp-compose decrypts with: MCP_COMPOSE_AGE_KEY, the
+file in MCP_COMPOSE_AGE_KEY_FILE, or the one 'config keygen' writes. Armored
+files from 'age -a' are accepted as !encrypted values too.
+
+Examples:
+  mcp-compose config encrypt
+  printf %s "$TOKEN" | mcp-compose config encrypt -r age1... -r age1...`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			recipients, _ := cmd.Flags().GetStringArray("recipient")
+			value := ""
+			if len(args) > 0 {
+				value = args[0]
+			}
+
+			return compose.EncryptConfigValue(value, recipients, cmd.InOrStdin())
+		},
+	}
+	cmd.Flags().StringArrayP("recipient", "r", nil, "age public key (age1...) to encrypt to; repeatable")
+
+	return cmd
+}
+
+func newConfigSetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set PATH VALUE",
+		Short: "Change a setting of the compose file and restart the servers it affects",
+		Long: `Set a setting, given as a dotted path, to a value parsed as YAML, keeping
+the file's comments and ${VAR} references. The result is validated before it is
+written and recorded in the file's history.
+
+When the proxy runs with dashboard.config_editor, it writes the change and
+restarts the servers it changes, as the dashboard's config editor does.
+Otherwise the servers up started that the change affects are stopped and
+started again here.
+
+Examples:
+  mcp-compose config set servers.filesystem.env.DEBUG true
+  mcp-compose config set servers.github.image ghcr.io/github/github-mcp-server:v0.5.0
+  mcp-compose config set servers.fetch.args '["--timeout", "30"]'`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+
+			return compose.ConfigSet(file, args[0], args[1], configChangeOptions(cmd))
+		},
+	}
+	addConfigChangeFlags(cmd)
+
+	return cmd
+}
+
+func newConfigHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the recorded versions of the compose file",
+		Long: `List the versions of the compose file recorded when it was changed by
+'config set', 'config rollback', 'add', 'import' or the dashboard's config
+editor, newest first. Edits made by hand in between are recorded as "external"
+when the next change is made. The last 20 versions are kept in
+.mcp-compose-backups/ next to the file.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			output, _ := cmd.Flags().GetString("output")
+
+			return compose.ConfigHistory(file, output)
+		},
+	}
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func newConfigRollbackCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback VERSION",
+		Short: "Restore a recorded version of the compose file",
+		Long: `Restore a version listed by 'config history' and restart the servers it
+changes, in the proxy when it runs with dashboard.config_editor and here
+otherwise. The restored file is recorded as a new version, so the rollback can
+be undone the same way.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			version, err := strconv.Atoi(args[0])
+			if err != nil || version < 1 {
+
+				return fmt.Errorf("invalid version '%s': use a number from 'mcp-compose config history'", args[0])
+			}
+
+			return compose.ConfigRollback(file, version, configChangeOptions(cmd))
+		},
+	}
+	addConfigChangeFlags(cmd)
+
+	return cmd
+}
+
+func addConfigChangeFlags(cmd *cobra.Command) {
+	cmd.Flags().String("proxy-url", fmt.Sprintf("http://localhost:%d", constants.DefaultProxyPort), "URL of the running MCP proxy")
+	cmd.Flags().String("api-key", "", "API key for proxy authentication (defaults to proxy_auth.api_key)")
+	cmd.Flags().Bool("no-reload", false, "Only write the file; leave running servers alone")
+}
+
+func configChangeOptions(cmd *cobra.Command) compose.ConfigChangeOptions {
+	proxyURL, _ := cmd.Flags().GetString("proxy-url")
+	apiKey, _ := cmd.Flags().GetString("api-key")
+	noReload, _ := cmd.Flags().GetBool("no-reload")
+
+	return compose.ConfigChangeOptions{ProxyURL: proxyURL, APIKey: apiKey, NoReload: noReload}
+}
+
+func newConfigRenderCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print the fully resolved configuration",
+		Long: `Print the configuration as the server manager sees it: after loading .env,
+env_files and --env-file, expanding ${VAR} references, merging each server's
+env_file into its env, applying the MCP_ENV environment overrides, selecting
+profiles and adding the built-in task-scheduler and memory servers. The YAML
+output starts with the env files that were used, highest precedence first.
+
+Secret values (API keys, tokens, passwords, URL credentials) are redacted
+unless --show-secrets is given.
+
+Examples:
+  mcp-compose config render
+  MCP_ENV=production mcp-compose config render --profile debug
+  mcp-compose config render --format json --show-secrets`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			profiles, _ := cmd.Flags().GetStringSlice("profile")
+			showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+			format, _ := cmd.Flags().GetString("format")
+
+			return compose.Render(file, compose.RenderOptions{
+				Profiles:    profiles,
+				ShowSecrets: showSecrets,
+				Format:      format,
+			})
+		},
+	}
+	cmd.Flags().StringSlice("profile", nil, "Profiles to activate (default: MCP_COMPOSE_PROFILES)")
+	cmd.Flags().Bool("show-secrets", false, "Print secret values instead of redacting them")
+	cmd.Flags().String("format", "yaml", "Output format: yaml or json")
+
+	return cmd
+}
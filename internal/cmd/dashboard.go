@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"os"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
 	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/container"
 	"github.com/phildougherty/mcp-compose/internal/dashboard"
 
@@ -15,12 +21,30 @@ func NewDashboardCommand() *cobra.Command {
 	var enable bool
 	var disable bool
 	var native bool
+	var hashPassword bool
+	var totpUser string
+	var insecure bool
+	var publishedHost string
 
 	cmd := &cobra.Command{
 		Use:   "dashboard",
 		Short: "Manage the web dashboard",
-		Long:  "Start, stop, enable, or disable the MCP-Compose web dashboard",
+		Long: `Start, stop, enable, or disable the MCP-Compose web dashboard.
+
+The dashboard only accepts connections from this machine unless dashboard.expose is set, and
+then only with dashboard.admin_login enabled, unless --insecure is passed. A dashboard in a
+container listens on every interface of the container's network, and is checked against
+--published-host, the host its port is published on.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if hashPassword {
+
+				return printPasswordHash()
+			}
+			if totpUser != "" {
+
+				return printTOTPSecret(totpUser)
+			}
+
 			configFile, _ := cmd.Flags().GetString("file")
 			cfg, err := config.LoadConfig(configFile)
 			if err != nil {
@@ -61,45 +85,107 @@ func NewDashboardCommand() *cobra.Command {
 
 			// Set defaults
 			if cfg.Dashboard.Port == 0 {
-				cfg.Dashboard.Port = 3001
+				cfg.Dashboard.Port = constants.DefaultDashboardPort
+			}
+			cfg.Dashboard.Host = cfg.Dashboard.BindHost()
+			// Containers set MCP_DASHBOARD_HOST to listen on every interface of their own
+			// network, and their launcher passes the host it publishes their port on, which
+			// decides who reaches them
+			exposedHost := cfg.Dashboard.Host
+			if envHost := os.Getenv("MCP_DASHBOARD_HOST"); envHost != "" && native {
+				exposedHost = envHost
 			}
-			if cfg.Dashboard.Host == "" {
-				cfg.Dashboard.Host = "0.0.0.0"
+			if publishedHost != "" {
+				exposedHost = publishedHost
+			}
+			if err := checkDashboardExposure(cfg, exposedHost, insecure); err != nil {
+
+				return err
 			}
 
 			// Choose mode: native or containerized
 			if native {
 
-				return runNativeDashboard(cfg, runtime)
+				return runNativeDashboard(cfg, runtime, configFile, cmd.Root().Version)
 			} else {
 
-				return runContainerizedDashboard(cfg, runtime, configFile) // Pass configFile
+				return runContainerizedDashboard(cfg, runtime, configFile, insecure) // Pass configFile
 			}
 		},
 	}
 
 	cmd.Flags().IntVarP(&port, "port", "p", 0, "Dashboard port (default: 3001)")
-	cmd.Flags().StringVar(&host, "host", "", "Dashboard host interface (default: 0.0.0.0)")
+	cmd.Flags().StringVar(&host, "host", "", "Dashboard host interface (default: 127.0.0.1, or 0.0.0.0 with dashboard.expose)")
 	cmd.Flags().BoolVar(&enable, "enable", false, "Enable the dashboard in config")
 	cmd.Flags().BoolVar(&disable, "disable", false, "Disable the dashboard")
 	cmd.Flags().BoolVar(&native, "native", false, "Run dashboard natively (requires proxy to be native too)")
+	cmd.Flags().BoolVar(&hashPassword, "hash-password", false, "Read a password from stdin and print its bcrypt hash, escaped for a user's password_hash")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "Allow dashboard.expose without admin_login")
+	cmd.Flags().StringVar(&publishedHost, "published-host", "", "Host the dashboard's container port is published on, checked for exposure in place of the host it listens on")
+	cmd.Flags().StringVar(&totpUser, "totp-secret", "", "Print a new totp_secret for the user and the otpauth:// URI to add it to an authenticator app")
 
 	return cmd
 }
 
-func runNativeDashboard(cfg *config.ComposeConfig, runtime container.Runtime) error {
+func printPasswordHash() error {
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+
+		return fmt.Errorf("password is empty")
+	}
+
+	hash, err := dashboard.HashPassword(password)
+	if err != nil {
+
+		return err
+	}
+	// Compose files expand $VAR, so the hash is printed with each $ escaped as $$
+	fmt.Println(strings.ReplaceAll(hash, "$", "$$"))
+
+	return nil
+}
+
+func printTOTPSecret(username string) error {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+
+		return err
+	}
+	fmt.Printf("totp_secret: %s\n", secret)
+	fmt.Fprintf(os.Stderr, "Add it to an authenticator app with this URI, or by entering the secret:\n%s\n",
+		auth.TOTPURI(secret, "mcp-compose", username))
+
+	return nil
+}
+
+func runNativeDashboard(cfg *config.ComposeConfig, runtime container.Runtime, configFile, version string) error {
 	// For native mode, proxy must be reachable at localhost
 	proxyURL := "http://localhost:9876"
+	if cfg.ProxyListenerTLS().Enabled {
+		proxyURL = "https://localhost:9876"
+	}
+	scheme := "http"
+	if cfg.Dashboard.TLS.Enabled {
+		scheme = "https"
+	}
 
-	fmt.Printf("Starting native dashboard on http://%s:%d\n", cfg.Dashboard.Host, cfg.Dashboard.Port)
+	fmt.Printf("Starting native dashboard on %s://%s:%d\n", scheme, cfg.Dashboard.Host, cfg.Dashboard.Port)
 	fmt.Printf("Connecting to native proxy at: %s\n", proxyURL)
 
 	server := dashboard.NewDashboardServer(cfg, runtime, proxyURL, cfg.ProxyAuth.APIKey)
+	server.SetVersion(version)
+	server.SetConfigDir(config.ProjectDir(configFile))
 
 	return server.Start(cfg.Dashboard.Port, cfg.Dashboard.Host)
 }
 
-func runContainerizedDashboard(cfg *config.ComposeConfig, runtime container.Runtime, configFile string) error {
+func runContainerizedDashboard(cfg *config.ComposeConfig, runtime container.Runtime, configFile string, insecure bool) error {
 	// Check if proxy container is running
 	if !isProxyContainerRunning(runtime) {
 
@@ -112,6 +198,7 @@ Then try starting the dashboard again`, cfg.ProxyAuth.APIKey)
 
 	dashManager := dashboard.NewManager(cfg, runtime)
 	dashManager.SetConfigFile(configFile)
+	dashManager.SetInsecure(insecure)
 
 	// Just use the regular Start method - security will be handled internally
 
@@ -119,7 +206,7 @@ Then try starting the dashboard again`, cfg.ProxyAuth.APIKey)
 }
 
 func isProxyContainerRunning(runtime container.Runtime) bool {
-	status, err := runtime.GetContainerStatus("mcp-compose-http-proxy")
+	status, err := runtime.GetContainerStatus(config.ContainerName("http-proxy"))
 	if err != nil {
 
 		return false
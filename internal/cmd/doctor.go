@@ -0,0 +1,47 @@
+// internal/cmd/doctor.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the compose file and the host it runs on",
+		Long: `Check everything mcp-compose needs and print what to do about each problem:
+
+  - the compose file, with the location of every problem
+  - whether Docker or Podman is installed and answering
+  - permission to use the Docker socket
+  - host ports claimed twice, or taken by another program: the proxy's, the
+    dashboard's and those servers publish
+  - whether containers resolve running servers by name on their networks
+  - whether the container runtime's clock has drifted from the host's
+
+The DNS and clock checks run a short-lived alpine container; --quick skips them.
+The command fails when any check finds a problem.
+
+Examples:
+  mcp-compose doctor
+  mcp-compose doctor --quick --proxy-port 8080`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			proxyPort, _ := cmd.Flags().GetInt("proxy-port")
+			quick, _ := cmd.Flags().GetBool("quick")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			return compose.Doctor(file, compose.DoctorOptions{ProxyPort: proxyPort, Quick: quick, JSON: jsonOutput})
+		},
+	}
+	cmd.Flags().Int("proxy-port", constants.DefaultProxyPort, "Port the proxy listens on")
+	cmd.Flags().Bool("quick", false, "Skip the checks that run a container")
+	cmd.Flags().Bool("json", false, "Print the checks as JSON")
+
+	return cmd
+}
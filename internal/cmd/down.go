@@ -15,8 +15,9 @@ import (
 
 func NewDownCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "down [SERVER|proxy|dashboard|task-scheduler|memory]...",
-		Short: "Stop and remove MCP servers, proxy, dashboard, task-scheduler, or memory server",
+		Use:               "down [SERVER|proxy|dashboard|task-scheduler|memory]...",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Stop and remove MCP servers, proxy, dashboard, task-scheduler, or memory server",
 		Long: `Stop and remove MCP servers, the proxy server, dashboard, task-scheduler, or memory server.
 Examples:
   mcp-compose down                    # Stop and remove all servers
@@ -24,9 +25,11 @@ Examples:
   mcp-compose down proxy             # Stop and remove the HTTP proxy
   mcp-compose down dashboard         # Stop and remove the dashboard
   mcp-compose down task-scheduler    # Stop and remove the task scheduler
-  mcp-compose down memory            # Stop and remove the memory server`,
+  mcp-compose down memory            # Stop and remove the memory server
+  mcp-compose down --profile debug   # Stop the servers 'up --profile debug' started`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, _ := cmd.Flags().GetString("file")
+			applyProfileFlag(cmd)
 			// If no args provided, stop all servers and built-in services
 			if len(args) == 0 {
 
@@ -72,6 +75,7 @@ Examples:
 			return nil
 		},
 	}
+	addProfileFlag(cmd)
 
 	return cmd
 }
@@ -137,7 +141,7 @@ func downProxy() error {
 		return fmt.Errorf("failed to detect container runtime: %w", err)
 	}
 
-	proxyContainerName := "mcp-compose-http-proxy"
+	proxyContainerName := config.ContainerName("http-proxy")
 	if err := runtime.StopContainer(proxyContainerName); err != nil {
 		// Don't return error if container doesn't exist
 		fmt.Printf("Note: Proxy container may not be running: %v\n", err)
@@ -0,0 +1,55 @@
+// internal/cmd/events.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewEventsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Show server, config, tool and auth events from the running proxy",
+		Long: `Show the events recorded by the running proxy: servers starting, stopping,
+turning unhealthy or crash-looping, config reloads, failed tool calls and denied requests.
+
+A type filter matches itself and, without a dot, its whole category.
+
+Examples:
+  mcp-compose events
+  mcp-compose events --follow
+  mcp-compose events -f --type server,auth.denied --server filesystem`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			proxyURL, _ := cmd.Flags().GetString("proxy-url")
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			follow, _ := cmd.Flags().GetBool("follow")
+			types, _ := cmd.Flags().GetStringSlice("type")
+			servers, _ := cmd.Flags().GetStringSlice("server")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			return compose.Events(file, compose.EventsOptions{
+				ProxyURL: proxyURL,
+				APIKey:   apiKey,
+				Follow:   follow,
+				Types:    types,
+				Servers:  servers,
+				JSON:     jsonOutput,
+			})
+		},
+	}
+
+	cmd.Flags().String("proxy-url", fmt.Sprintf("http://localhost:%d", constants.DefaultProxyPort), "URL of the running MCP proxy")
+	cmd.Flags().String("api-key", "", "API key for proxy authentication (defaults to proxy_auth.api_key)")
+	cmd.Flags().BoolP("follow", "f", false, "Keep streaming new events")
+	cmd.Flags().StringSlice("type", nil, "Only show these event types or categories (e.g. server, tool.failed)")
+	cmd.Flags().StringSlice("server", nil, "Only show events for these servers")
+	cmd.Flags().Bool("json", false, "Print events as JSON lines")
+
+	return cmd
+}
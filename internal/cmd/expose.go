@@ -0,0 +1,35 @@
+// internal/cmd/expose.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// checkProxyExposure refuses to start a proxy other machines could reach unless the config
+// exposes it with authentication, warning when --insecure allows it without
+func checkProxyExposure(cfg *config.ComposeConfig, host, apiKey string, insecure bool) error {
+	if err := cfg.CheckProxyExposure(host, apiKey, insecure); err != nil {
+
+		return err
+	}
+	if !config.LoopbackHost(host) && !cfg.ProxyAuthenticated(apiKey) {
+		fmt.Printf("Warning: the proxy accepts unauthenticated connections from other machines on %s (--insecure)\n", host)
+	}
+
+	return nil
+}
+
+// checkDashboardExposure is checkProxyExposure for the dashboard
+func checkDashboardExposure(cfg *config.ComposeConfig, host string, insecure bool) error {
+	if err := cfg.CheckDashboardExposure(host, insecure); err != nil {
+
+		return err
+	}
+	if !config.LoopbackHost(host) && !cfg.Dashboard.Authenticated() {
+		fmt.Printf("Warning: the dashboard accepts unauthenticated connections from other machines on %s (--insecure)\n", host)
+	}
+
+	return nil
+}
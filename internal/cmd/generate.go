@@ -0,0 +1,128 @@
+// internal/cmd/generate.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewGenerateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate deployment files from the compose file",
+	}
+	cmd.AddCommand(newGenerateSystemdCommand())
+	cmd.AddCommand(newGenerateK8sCommand())
+
+	return cmd
+}
+
+func newGenerateSystemdCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "systemd [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Write systemd units for the servers, the proxy and the dashboard",
+		Long: `Write a systemd unit for each server, or for the named servers and their
+dependencies, plus one for the proxy and, when it is enabled, one for the dashboard, so a
+VM runs the whole deployment from systemd without a shell wrapper around 'mcp-compose up'.
+
+Container servers become Podman quadlets, or .service files running podman run with
+--format unit. Process servers serving HTTP run directly; stdio process servers run under
+an mcp-compose supervisor that the proxy reaches them through. The proxy unit wants every
+server unit and starts after them, the dashboard requires the proxy, and dependencies
+become Requires= and After=. Restart policies become Restart=.
+
+Each unit loads its environment from an environment file readable by its owner only. The
+proxy's file holds the variables the compose file refers to, as they are set now, since
+the proxy loads the config itself.
+
+Examples:
+  mcp-compose generate systemd
+  mcp-compose generate systemd --system --format unit
+  mcp-compose generate systemd --no-proxy -o ./deploy`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			noProxy, _ := cmd.Flags().GetBool("no-proxy")
+			proxyPort, _ := cmd.Flags().GetInt("proxy-port")
+			applyProfileFlag(cmd)
+
+			opts := systemdExportOptions(cmd)
+			opts.Proxy = !noProxy
+			opts.ProxyPort = proxyPort
+
+			return compose.SystemdExport(file, args, opts)
+		},
+	}
+	addSystemdExportFlags(cmd)
+	cmd.Flags().Bool("no-proxy", false, "Write units for the servers only")
+	cmd.Flags().Int("proxy-port", constants.DefaultProxyPort, "Port the proxy unit listens on")
+
+	return cmd
+}
+
+func newGenerateK8sCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "k8s [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Aliases:           []string{"kubernetes"},
+		Short:             "Write Kubernetes manifests for the servers and the proxy",
+		Long: `Convert the compose file into Kubernetes manifests, for every server or the named
+servers and their dependencies.
+
+Each container server becomes a Deployment with its replicas, resource limits and
+reservations, user, capabilities and health check; a Service for its ports; a ConfigMap
+and a Secret holding its environment, secret values in the Secret; claims for its named
+volumes; and a NetworkPolicy admitting only the proxy and the servers that share one of
+its networks. Restricted egress becomes an egress policy for its IP and CIDR rules.
+
+The proxy gets a Deployment running --proxy-image with the compose files from a ConfigMap
+and the variables they refer to in a Secret, a Service named like its container so
+servers reach it as they do under Docker, and an Ingress.
+
+Process servers, bind mounts and host names in egress rules have no Kubernetes
+equivalent and are left out with a warning. Manifests are printed unless --output names
+a directory.
+
+Examples:
+  mcp-compose generate k8s > mcp.yaml
+  mcp-compose generate k8s -o k8s --namespace mcp --ingress-host mcp.example.com
+  mcp-compose generate k8s github --proxy-image registry.local/mcp-compose-proxy:1.4`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			output, _ := cmd.Flags().GetString("output")
+			namespace, _ := cmd.Flags().GetString("namespace")
+			proxyImage, _ := cmd.Flags().GetString("proxy-image")
+			proxyPort, _ := cmd.Flags().GetInt("proxy-port")
+			ingressHost, _ := cmd.Flags().GetString("ingress-host")
+			ingressClass, _ := cmd.Flags().GetString("ingress-class")
+			ingressTLS, _ := cmd.Flags().GetString("ingress-tls-secret")
+			volumeSize, _ := cmd.Flags().GetString("volume-size")
+			applyProfileFlag(cmd)
+
+			return compose.GenerateK8s(file, args, compose.K8sOptions{
+				Namespace:        namespace,
+				OutputDir:        output,
+				ProxyImage:       proxyImage,
+				ProxyPort:        proxyPort,
+				IngressHost:      ingressHost,
+				IngressClass:     ingressClass,
+				IngressTLSSecret: ingressTLS,
+				VolumeSize:       volumeSize,
+			})
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "Directory to write one manifest file per server to")
+	cmd.Flags().StringP("namespace", "n", "", "Namespace to create and put every object in")
+	cmd.Flags().String("proxy-image", "mcp-compose-go-http-proxy:latest", "Image the proxy Deployment runs")
+	cmd.Flags().Int("proxy-port", constants.DefaultProxyPort, "Port the proxy listens on")
+	cmd.Flags().String("ingress-host", "", "Host name the proxy Ingress answers")
+	cmd.Flags().String("ingress-class", "", "Ingress class of the proxy Ingress")
+	cmd.Flags().String("ingress-tls-secret", "", "TLS secret for --ingress-host")
+	cmd.Flags().String("volume-size", "1Gi", "Storage each named volume's claim requests")
+
+	return cmd
+}
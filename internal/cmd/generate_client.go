@@ -0,0 +1,53 @@
+// internal/cmd/generate_client.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewGenerateClientCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate-client OUTPUT_DIR",
+		Short: "Generate typed clients from the tool schemas of running servers",
+		Long: `Generate typed client code from the tool schemas currently served through the proxy.
+
+Each tool gets an argument struct (Go) or interface (TypeScript) and a call method,
+so application code is checked against the deployed servers at compile time.
+
+Examples:
+  mcp-compose generate-client ./clients
+  mcp-compose generate-client ./clients --lang go,ts --package tools
+  mcp-compose generate-client ./clients --server filesystem --proxy-url http://proxy:9876`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			proxyURL, _ := cmd.Flags().GetString("proxy-url")
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			pkg, _ := cmd.Flags().GetString("package")
+			langs, _ := cmd.Flags().GetStringSlice("lang")
+			servers, _ := cmd.Flags().GetStringSlice("server")
+
+			return compose.GenerateClient(file, args[0], compose.GenerateClientOptions{
+				ProxyURL:    proxyURL,
+				APIKey:      apiKey,
+				PackageName: pkg,
+				Languages:   langs,
+				Servers:     servers,
+			})
+		},
+	}
+
+	cmd.Flags().String("proxy-url", fmt.Sprintf("http://localhost:%d", constants.DefaultProxyPort), "URL of the running MCP proxy")
+	cmd.Flags().String("api-key", "", "API key for proxy authentication (defaults to proxy_auth.api_key)")
+	cmd.Flags().String("package", "mcpclient", "Package name for the generated Go client")
+	cmd.Flags().StringSlice("lang", []string{"go"}, "Languages to generate: go, ts")
+	cmd.Flags().StringSlice("server", nil, "Only generate for these servers (default: all)")
+
+	return cmd
+}
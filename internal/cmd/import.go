@@ -0,0 +1,43 @@
+// internal/cmd/import.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+
+	"github.com/spf13/cobra"
+)
+
+func NewImportCommand() *cobra.Command {
+	var from string
+	var mode string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "import [SOURCE_CONFIG]",
+		Short: "Import servers from another MCP client's configuration",
+		Long: `Convert the servers defined in another MCP client's configuration into
+servers in the compose file, creating it if needed.
+
+Entries that run a well-known server through npx or uvx become containers using
+its official image (mode auto), "docker run" entries keep their image, and
+anything else becomes a process-based server. Secret-looking environment values
+are moved to the .env file next to the compose file.
+
+Example:
+  mcp-compose import --from claude-desktop ~/Library/Application\ Support/Claude/claude_desktop_config.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+
+			return compose.Import(file, args[0], compose.ImportOptions{
+				From:   from,
+				Mode:   mode,
+				DryRun: dryRun,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "claude-desktop", "Format of the source configuration (claude-desktop)")
+	cmd.Flags().StringVar(&mode, "mode", "auto", "How to run imported servers: auto, process or container")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the resulting compose file instead of writing it")
+
+	return cmd
+}
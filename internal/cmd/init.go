@@ -0,0 +1,58 @@
+// internal/cmd/init.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+
+	"github.com/spf13/cobra"
+)
+
+func NewInitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create a new mcp-compose.yaml from well-known MCP servers",
+		Long: `Interactively create a compose file from well-known MCP servers (filesystem,
+memory, fetch, github, ...) with a shared network, named volumes and proxy
+authentication. A generated proxy API key and any tokens you enter are stored
+in .env next to the compose file.
+
+Flags answer the questions up front; --yes accepts the defaults for the rest.
+
+Examples:
+  mcp-compose init
+  mcp-compose init --yes
+  mcp-compose init --server filesystem,github --dir ~/code --client claude,cursor`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			servers, _ := cmd.Flags().GetStringSlice("server")
+			dir, _ := cmd.Flags().GetString("dir")
+			noAuth, _ := cmd.Flags().GetBool("no-auth")
+			yes, _ := cmd.Flags().GetBool("yes")
+			force, _ := cmd.Flags().GetBool("force")
+
+			opts := compose.InitOptions{
+				Servers:        servers,
+				Directory:      dir,
+				NoAuth:         noAuth,
+				AcceptDefaults: yes,
+				Force:          force,
+				In:             cmd.InOrStdin(),
+			}
+			if cmd.Flags().Changed("client") {
+				opts.Clients, _ = cmd.Flags().GetStringSlice("client")
+			}
+
+			return compose.Init(file, opts)
+		},
+	}
+	cmd.Flags().StringSlice("server", nil, "Servers to include (default: ask; filesystem, memory and fetch with --yes)")
+	cmd.Flags().String("dir", "", "Directory the filesystem server exposes (default: current directory)")
+	cmd.Flags().Bool("no-auth", false, "Do not protect the proxy with an API key")
+	cmd.Flags().StringSlice("client", nil, "Generate client configuration: claude, cursor, vscode")
+	cmd.Flags().BoolP("yes", "y", false, "Accept defaults instead of prompting")
+	cmd.Flags().Bool("force", false, "Overwrite an existing compose file")
+
+	return cmd
+}
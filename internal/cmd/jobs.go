@@ -0,0 +1,67 @@
+// internal/cmd/jobs.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+
+	"github.com/spf13/cobra"
+)
+
+func NewJobsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "jobs [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Show how the init jobs of servers went the last time they ran",
+		Long: `Show the init jobs of servers: one-shot jobs under a server's init that must exit
+successfully, in order, before it starts. Their output and exit status are recorded
+in .mcp-compose/init next to the compose file each time they run.
+
+Examples:
+  mcp-compose jobs
+  mcp-compose jobs logs memory migrate
+  mcp-compose jobs run memory`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			return compose.ListInitJobs(file, args, jsonOutput)
+		},
+	}
+	cmd.Flags().Bool("json", false, "Print the results as JSON")
+	cmd.AddCommand(newJobsLogsCommand())
+	cmd.AddCommand(newJobsRunCommand())
+
+	return cmd
+}
+
+func newJobsLogsCommand() *cobra.Command {
+
+	return &cobra.Command{
+		Use:               "logs SERVER JOB",
+		ValidArgsFunction: completeServerName,
+		Short:             "Print what an init job printed the last time it ran",
+		Args:              cobra.ExactArgs(2),
+		SilenceUsage:      true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+
+			return compose.ShowInitJobLog(file, args[0], args[1])
+		},
+	}
+}
+
+func newJobsRunCommand() *cobra.Command {
+
+	return &cobra.Command{
+		Use:               "run [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Run the init jobs of servers again without starting them",
+		SilenceUsage:      true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+
+			return compose.RunInitJobs(file, args)
+		},
+	}
+}
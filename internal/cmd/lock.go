@@ -0,0 +1,36 @@
+// internal/cmd/lock.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+
+	"github.com/spf13/cobra"
+)
+
+func NewLockCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Record how every server resolves in the lock file",
+		Long: `Resolve every server of the config and write the result to the lock file next to
+it (mcp-compose.lock.yaml for mcp-compose.yaml): the digest of each image, pulling
+images that are not present, a hash of each build context, and each server's
+environment after substitution. Secret values are recorded as hashes.
+
+Commit the lock file. 'mcp-compose up --locked' then refuses to start servers that
+resolve differently on another machine or after the config or .env changed, and
+'up' without --locked still runs the locked image digests.
+
+Examples:
+  mcp-compose lock
+  mcp-compose up --locked`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+
+			return compose.WriteLock(file)
+		},
+	}
+
+	return cmd
+}
@@ -2,9 +2,12 @@
 package cmd
 
 import (
-	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
 	"github.com/phildougherty/mcp-compose/internal/compose"
-	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/config"
 
 	"github.com/spf13/cobra"
 )
@@ -21,135 +24,88 @@ Special containers:
   memory         - Shows logs from mcp-compose-memory container
   postgres-memory - Shows logs from mcp-compose-postgres-memory container
 
+Logs of several servers are read in parallel and each line is prefixed with
+the name of the server it came from, colored on a terminal (set NO_COLOR or
+pass --no-color to turn that off).
+
+Process-based servers show the output captured by their supervisor, including
+rotated files; see logging.retention to control rotation.
+
 Examples:
   mcp-compose logs                    # Show logs from all servers
+  mcp-compose logs -f --tail 20       # Follow all servers, starting from their last 20 lines
+  mcp-compose logs --since 10m        # Lines written in the last ten minutes
+  mcp-compose logs --grep 'error|warn' filesystem memory
   mcp-compose logs proxy -f           # Follow proxy logs
   mcp-compose logs dashboard -f       # Follow dashboard logs  
   mcp-compose logs task-scheduler -f  # Follow task scheduler logs
   mcp-compose logs memory -f          # Follow memory server logs
   mcp-compose logs filesystem -f      # Follow filesystem server logs
-  mcp-compose logs proxy dashboard -f # Follow both proxy and dashboard logs`,
+  mcp-compose logs proxy dashboard -f # Follow both proxy and dashboard logs
+  mcp-compose logs -f -o json         # One JSON object per line, for log shippers`,
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, _ := cmd.Flags().GetString("file")
 			follow, _ := cmd.Flags().GetBool("follow")
+			tail, _ := cmd.Flags().GetInt("tail")
+			since, _ := cmd.Flags().GetString("since")
+			grep, _ := cmd.Flags().GetString("grep")
+			noColor, _ := cmd.Flags().GetBool("no-color")
+			output, _ := cmd.Flags().GetString("output")
+
+			return runLogsCommand(file, args, compose.LogsOptions{
+				Follow:  follow,
+				Tail:    tail,
+				Since:   since,
+				Grep:    grep,
+				NoColor: noColor,
+				Output:  output,
+			})
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			names, directive := completeServerNames(cmd, args, toComplete)
+			for name := range specialContainers {
+				if strings.HasPrefix(name, toComplete) && !slices.Contains(args, name) {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
 
-			return runLogsCommand(file, args, follow)
+			return names, directive
 		},
 	}
 	cmd.Flags().BoolP("follow", "f", false, "Follow log output")
+	cmd.Flags().IntP("tail", "n", -1, "Number of lines to show from the end of each server's logs; -1 for all")
+	cmd.Flags().String("since", "", "Show lines written since a time (2024-05-01T15:04:05Z) or for a duration (10m)")
+	cmd.Flags().String("grep", "", "Only show lines matching a regular expression")
+	cmd.Flags().Bool("no-color", false, "Print the server prefixes without color")
+	addOutputFlag(cmd)
 
 	return cmd
 }
 
-func runLogsCommand(configFile string, serverNames []string, follow bool) error {
-	// Check if we have special container requests (proxy, dashboard, etc.)
-	specialContainers := make(map[string]string)
-	regularServers := make([]string, 0)
-
-	for _, name := range serverNames {
-		switch name {
-		case "proxy":
-			specialContainers["proxy"] = "mcp-compose-http-proxy"
-		case "dashboard":
-			specialContainers["dashboard"] = "mcp-compose-dashboard"
-		case "task-scheduler":
-			specialContainers["task-scheduler"] = "mcp-compose-task-scheduler"
-		case "memory":
-			specialContainers["memory"] = "mcp-compose-memory"
-		case "postgres-memory":
-			specialContainers["postgres-memory"] = "mcp-compose-postgres-memory"
-		default:
-			regularServers = append(regularServers, name)
-		}
-	}
-
-	// If we only have special containers, handle them directly
-	if len(specialContainers) > 0 && len(regularServers) == 0 {
-
-		return handleSpecialContainerLogs(specialContainers, follow)
-	}
-
-	// If we have a mix or only regular servers, use the compose logs function
-	if len(regularServers) > 0 {
-		if err := compose.Logs(configFile, regularServers, follow); err != nil {
-
-			return err
-		}
-	}
-
-	// Handle special containers after regular servers
-	if len(specialContainers) > 0 {
-		if len(regularServers) > 0 {
-			fmt.Println() // Add spacing between regular and special logs
-		}
-
-		return handleSpecialContainerLogs(specialContainers, follow)
-	}
-
-	// If no specific servers requested, default to compose.Logs behavior
-	if len(serverNames) == 0 {
-
-		return compose.Logs(configFile, serverNames, follow)
-	}
-
-	return nil
+// specialContainers are mcp-compose's own services that logs accepts in place of servers
+var specialContainers = map[string]string{
+	"proxy":           "http-proxy",
+	"dashboard":       "dashboard",
+	"task-scheduler":  "task-scheduler",
+	"memory":          "memory",
+	"postgres-memory": "postgres-memory",
 }
 
-func handleSpecialContainerLogs(containers map[string]string, follow bool) error {
-	runtime, err := container.DetectRuntime()
-	if err != nil {
-
-		return fmt.Errorf("failed to detect container runtime: %w", err)
-	}
-
-	if runtime.GetRuntimeName() == "none" {
-		fmt.Println("No container runtime detected. Cannot show logs for built-in service containers.")
-
-		return nil
-	}
-
-	containerNames := make([]string, 0, len(containers))
-	displayNames := make([]string, 0, len(containers))
-
-	for displayName, containerName := range containers {
-		// Check if container exists
-		status, err := runtime.GetContainerStatus(containerName)
-		if err != nil || status == "stopped" {
-			fmt.Printf("Warning: Container '%s' (%s) not found or not running\n", displayName, containerName)
-
-			continue
-		}
-		containerNames = append(containerNames, containerName)
-		displayNames = append(displayNames, displayName)
-	}
-
-	if len(containerNames) == 0 {
-
-		return fmt.Errorf("no running containers found for the requested services")
-	}
-
-	// Show logs for each container
-	for i, containerName := range containerNames {
-		if len(containerNames) > 1 {
-			if i > 0 && !follow {
-				fmt.Println("\n---")
+func runLogsCommand(configFile string, serverNames []string, opts compose.LogsOptions) error {
+	// Special containers (proxy, dashboard, etc.) are followed alongside the servers
+	var regularServers []string
+	for _, name := range serverNames {
+		if service, special := specialContainers[name]; special {
+			if opts.Services == nil {
+				opts.Services = make(map[string]string)
 			}
-			fmt.Printf("=== Logs for %s (%s) ===\n", displayNames[i], containerName)
-		}
-
-		if err := runtime.ShowContainerLogs(containerName, follow); err != nil {
-			fmt.Printf("Warning: failed to show logs for %s (%s): %v\n",
-				displayNames[i], containerName, err)
-		}
-
-		// For follow mode with multiple containers, we can only follow one at a time
-		// Docker/Podman doesn't support multiplexed following easily
-		if follow && len(containerNames) > 1 {
-			fmt.Printf("\nNote: Following logs for %s only. Use separate commands to follow multiple containers.\n", displayNames[0])
-
-			break
+			opts.Services[name] = config.ContainerName(service)
+		} else {
+			regularServers = append(regularServers, name)
 		}
 	}
 
-	return nil
+	return compose.Logs(configFile, regularServers, opts)
 }
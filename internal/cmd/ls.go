@@ -9,14 +9,19 @@ import (
 
 func NewLsCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "ls",
-		Short: "List all defined MCP servers and their status",
+		Use:          "ls",
+		Short:        "List all defined MCP servers and their status",
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, _ := cmd.Flags().GetString("file")
+			output, _ := cmd.Flags().GetString("output")
+			security, _ := cmd.Flags().GetBool("security")
 
-			return compose.List(file)
+			return compose.List(file, output, security)
 		},
 	}
+	addOutputFlag(cmd)
+	cmd.Flags().Bool("security", false, "Show the last vulnerability scan of each server's image")
 
 	return cmd
 }
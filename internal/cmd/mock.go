@@ -0,0 +1,82 @@
+// internal/cmd/mock.go
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/mock"
+
+	"github.com/spf13/cobra"
+)
+
+func NewMockCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "mock SERVER",
+		ValidArgsFunction: completeServerName,
+		Short:             "Serve a server's declared tools with their mocked responses",
+		Long: `Run an MCP server that stands in for SERVER, answering tools/list and
+prompts/list from the tools and prompts declared for it in the compose file and
+tools/call from each tool's mocks. A mock applies when every key of its input
+equals the call's argument; the most specific match wins, and a mock with
+status: error returns an error result.
+
+Use it to develop clients without running real, possibly expensive, backends,
+for example by pointing the server at the mock in an override file:
+
+  servers:
+    github:
+      image: !reset
+      command: mcp-compose
+      args: [mock, github, -c, /path/to/mcp-compose.yaml]`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			transport, _ := cmd.Flags().GetString("transport")
+			port, _ := cmd.Flags().GetInt("port")
+
+			cfg, err := config.LoadConfig(file)
+			if err != nil {
+
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			serverCfg, exists := cfg.Servers[args[0]]
+			if !exists {
+
+				return fmt.Errorf("server '%s' not found in %s", args[0], file)
+			}
+			server, err := mock.NewServer(args[0], serverCfg)
+			if err != nil {
+
+				return err
+			}
+
+			switch transport {
+			case "stdio":
+
+				return server.ServeStdio(os.Stdin, os.Stdout)
+			case "http":
+				addr := fmt.Sprintf(":%d", port)
+				fmt.Fprintf(os.Stderr, "Mock of server '%s' listening on %s\n", args[0], addr)
+				httpServer := &http.Server{
+					Addr:              addr,
+					Handler:           server,
+					ReadHeaderTimeout: constants.DefaultReadTimeout,
+				}
+
+				return httpServer.ListenAndServe()
+			default:
+
+				return fmt.Errorf("unknown transport '%s' (supported: stdio, http)", transport)
+			}
+		},
+	}
+
+	cmd.Flags().String("transport", "stdio", "Transport to serve on: stdio or http")
+	cmd.Flags().IntP("port", "p", constants.MockDefaultPort, "Port for the http transport")
+
+	return cmd
+}
@@ -0,0 +1,22 @@
+// internal/cmd/profiles.go
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func addProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("profile", nil, "Enable servers in these profiles (default: MCP_COMPOSE_PROFILES)")
+}
+
+// applyProfileFlag makes --profile the active profiles for this run, the same as setting
+// MCP_COMPOSE_PROFILES, so every server selection in the command sees them
+func applyProfileFlag(cmd *cobra.Command) {
+	profiles, _ := cmd.Flags().GetStringSlice("profile")
+	if len(profiles) > 0 {
+		_ = os.Setenv("MCP_COMPOSE_PROFILES", strings.Join(profiles, ","))
+	}
+}
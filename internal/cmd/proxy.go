@@ -5,21 +5,29 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/certs"
 	"github.com/phildougherty/mcp-compose/internal/compose"
 	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/egress"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/memory"
 	"github.com/phildougherty/mcp-compose/internal/server"
 
 	"github.com/spf13/cobra"
@@ -32,13 +40,31 @@ func NewProxyCommand() *cobra.Command {
 	var outputDir string
 	var apiKey string
 	var containerized bool // Keep for containerized proxy, though native is now primary
+	var upgrade bool
+	var drainTimeout time.Duration
+	var insecure bool
+	var publishedHost string
 
 	cmd := &cobra.Command{
 		Use:   "proxy",
 		Short: "Run an MCP proxy server for all services",
 		Long: `Run a proxy server that exposes all your MCP services through a unified HTTP endpoint.
 This proxy uses HTTP/SSE for communication with MCP servers, eliminating the need for docker exec.
-Servers must be configured to run in HTTP mode and expose their ports.`,
+Servers must be configured to run in HTTP mode and expose their ports.
+
+With --upgrade a new proxy takes over the port of the one already running, for example
+after installing a new mcp-compose: it starts serving, then the old proxy stops accepting
+connections and exits once its open requests and SSE streams have finished, or after
+--drain-timeout. Servers keep running throughout. Upgrades are not supported on Windows.
+
+The proxy only accepts connections from this machine unless proxy_expose is set, and then
+only when callers must authenticate with proxy_auth, unless --insecure is passed. A proxy
+in a container listens on every interface of the container's network, and is checked
+against --published-host, the host its port is published on.
+
+Examples:
+  mcp-compose proxy --port 9876
+  mcp-compose proxy --port 9876 --upgrade --drain-timeout 2m`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, _ := cmd.Flags().GetString("file")
 			// Load the configuration
@@ -47,6 +73,10 @@ Servers must be configured to run in HTTP mode and expose their ports.`,
 
 				return fmt.Errorf("failed to load config: %w", err)
 			}
+			if _, err := cfg.ApplyImageLock(file); err != nil {
+
+				return err
+			}
 			projectName := getProjectName(file)
 
 			// If only generating config, do that and exit
@@ -57,13 +87,17 @@ Servers must be configured to run in HTTP mode and expose their ports.`,
 
 			// Run containerized Go proxy (if requested)
 			if containerized {
+				if upgrade {
 
-				return startContainerizedGoProxy(cfg, projectName, port, outputDir, apiKey, file)
+					return fmt.Errorf("--upgrade is only supported by the native proxy")
+				}
+
+				return startContainerizedGoProxy(cfg, projectName, port, outputDir, apiKey, file, insecure)
 			}
 
 			// Run native Go proxy (primary mode)
 
-			return startNativeGoProxy(cfg, projectName, port, apiKey, file)
+			return startNativeGoProxy(cfg, projectName, port, apiKey, file, upgrade, drainTimeout, insecure, publishedHost)
 		},
 	}
 
@@ -73,13 +107,25 @@ Servers must be configured to run in HTTP mode and expose their ports.`,
 	cmd.Flags().StringVarP(&outputDir, "output", "o", "client-config", "Output directory for client configuration")
 	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key for securing the proxy server")
 	cmd.Flags().BoolVarP(&containerized, "container", "C", false, "Run proxy server as a container (less common now)")
+	cmd.Flags().BoolVar(&upgrade, "upgrade", false, "Take over the port from the running proxy and let it drain")
+	cmd.Flags().DurationVar(&drainTimeout, "drain-timeout", constants.ProxyDrainTimeout, "How long the proxy replaced by --upgrade serves its open connections")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "Allow proxy_expose without authentication")
+	cmd.Flags().StringVar(&publishedHost, "published-host", "", "Host the proxy's container port is published on, checked for exposure in place of the host it listens on")
 
 	return cmd
 }
 
-func startContainerizedGoProxy(cfg *config.ComposeConfig, projectName string, port int, outputDir string, apiKey string, configFile string) error {
+func startContainerizedGoProxy(cfg *config.ComposeConfig, projectName string, port int, outputDir string, apiKey string, configFile string, insecure bool) error {
 	fmt.Println("Starting containerized Go MCP proxy (HTTP transport)...")
 
+	// The published port decides who reaches the proxy, which listens on every interface of
+	// its container
+	bindHost := cfg.ProxyBindHost()
+	if err := checkProxyExposure(cfg, bindHost, apiKey, insecure); err != nil {
+
+		return err
+	}
+
 	cRuntime, err := container.DetectRuntime()
 	if err != nil {
 
@@ -91,14 +137,22 @@ func startContainerizedGoProxy(cfg *config.ComposeConfig, projectName string, po
 		return fmt.Errorf("failed to build Go HTTP proxy image: %w", err)
 	}
 
-	_ = cRuntime.StopContainer("mcp-compose-http-proxy")
-	networkExists, _ := cRuntime.NetworkExists("mcp-net")
-	if !networkExists {
-		if err := cRuntime.CreateNetwork("mcp-net"); err != nil {
+	_ = cRuntime.StopContainer(config.ContainerName("http-proxy"))
+	// The proxy joins the network of every container server that has one of its own
+	proxyNetworks := cfg.ProxyNetworks()
+	for _, networkName := range proxyNetworks {
+		networkExists, _ := cRuntime.NetworkExists(networkName)
+		if !networkExists {
+			create := cRuntime.CreateNetwork
+			if cfg.InternalNetwork(networkName) {
+				create = cRuntime.CreateInternalNetwork
+			}
+			if err := create(networkName); err != nil {
 
-			return fmt.Errorf("failed to create mcp-net network: %w", err)
+				return fmt.Errorf("failed to create %s network: %w", networkName, err)
+			}
+			fmt.Printf("Created %s network for proxy.\n", networkName)
 		}
-		fmt.Println("Created mcp-net network for proxy.")
 	}
 
 	absConfigFile, err := filepath.Abs(configFile)
@@ -108,26 +162,128 @@ func startContainerizedGoProxy(cfg *config.ComposeConfig, projectName string, po
 	}
 
 	env := map[string]string{
+		"MCP_PROXY_HOST":    constants.DefaultHostInterface,
 		"MCP_PROXY_PORT":    fmt.Sprintf("%d", port),
 		"MCP_PROJECT_NAME":  projectName,
 		"MCP_CONFIG_FILE":   "/app/mcp-compose.yaml",
 		"MCP_PROTOCOL_MODE": "enhanced",
 	}
 
+	// Override files are mounted next to the compose file and merged over it in the same order
+	configMode := "ro"
+	if cfg.Dashboard.ConfigEditor {
+		configMode = "rw"
+	}
+	volumes := []string{
+		fmt.Sprintf("%s:/app/mcp-compose.yaml:%s", absConfigFile, configMode),
+		"/var/run/docker.sock:/var/run/docker.sock:ro",
+	}
+	if cfg.Dashboard.ConfigEditor {
+		// The config editor writes the file in place and keeps its history beside it on the host
+		backupDir := filepath.Join(filepath.Dir(absConfigFile), constants.ConfigBackupDir)
+		if err := os.MkdirAll(backupDir, constants.SecretDirMode); err != nil {
+
+			return fmt.Errorf("failed to create config backup directory: %w", err)
+		}
+		volumes = append(volumes, fmt.Sprintf("%s:/app/%s", backupDir, constants.ConfigBackupDir))
+		env[config.HistoryNameEnv] = config.HistoryName(absConfigFile)
+	}
+	containerFiles := []string{"/app/mcp-compose.yaml"}
+	for i, override := range config.OverrideFiles() {
+		absOverride, err := filepath.Abs(override)
+		if err != nil {
+
+			return fmt.Errorf("failed to get absolute path for override file: %w", err)
+		}
+		containerFile := fmt.Sprintf("/app/mcp-compose.override-%d.yaml", i+1)
+		volumes = append(volumes, fmt.Sprintf("%s:%s:ro", absOverride, containerFile))
+		containerFiles = append(containerFiles, containerFile)
+	}
+	if len(containerFiles) > 1 {
+		env["MCP_COMPOSE_FILE"] = strings.Join(containerFiles, ":")
+	}
+	// The proxy decrypts the compose file's encrypted values with the same key
+	if config.HasEncryptedValues(configFile) {
+		if keyFile := config.AgeKeyFile(); keyFile == "" {
+			env[config.AgeKeyEnv] = os.Getenv(config.AgeKeyEnv)
+		} else if absKeyFile, err := filepath.Abs(keyFile); err == nil {
+			volumes = append(volumes, fmt.Sprintf("%s:/run/secrets/mcp-compose-age.txt:ro", absKeyFile))
+			env[config.AgeKeyFileEnv] = "/run/secrets/mcp-compose-age.txt"
+		}
+	}
+	// The env files the config names resolve next to the mounted compose file as on the host
+	for _, envFile := range cfg.EnvFilePaths() {
+		volumes = append(volumes, fmt.Sprintf("%s:%s:ro",
+			config.ResolveEnvFile(absConfigFile, envFile), config.ContainerEnvFile("/app/mcp-compose.yaml", envFile)))
+	}
+	// So does the key the proxy signs forwarded identities with
+	if tokens := cfg.ProxyAuth.IdentityTokens; tokens != nil && tokens.SigningKeyFile != "" {
+		volumes = append(volumes, fmt.Sprintf("%s:%s:ro",
+			config.ResolveEnvFile(absConfigFile, tokens.SigningKeyFile), config.ContainerEnvFile("/app/mcp-compose.yaml", tokens.SigningKeyFile)))
+	}
+	// The keys access tokens are signed with are kept on the host, so servers that cached them
+	// keep verifying tokens when the proxy container is recreated
+	if cfg.OAuth != nil && cfg.OAuth.Enabled && auth.SignedTokenAlgorithm(cfg.OAuth.Tokens.Algorithm) {
+		keyDir := cfg.OAuth.Tokens.SigningKeyDir()
+		hostKeyDir := config.ResolveEnvFile(absConfigFile, keyDir)
+		if err := os.MkdirAll(hostKeyDir, constants.SecretDirMode); err != nil {
+
+			return fmt.Errorf("failed to create token signing key directory: %w", err)
+		}
+		volumes = append(volumes, fmt.Sprintf("%s:%s", hostKeyDir, config.ContainerEnvFile("/app/mcp-compose.yaml", keyDir)))
+	}
+	// The auth failure log is written to the host, where fail2ban and log shippers read it
+	if authLog := cfg.Logging.AuthFailures; authLog != nil && authLog.Path != "" {
+		logDir := filepath.Dir(authLog.Path)
+		hostLogDir := config.ResolveEnvFile(absConfigFile, logDir)
+		if err := os.MkdirAll(hostLogDir, constants.DefaultDirMode); err != nil {
+
+			return fmt.Errorf("failed to create auth failure log directory: %w", err)
+		}
+		volumes = append(volumes, fmt.Sprintf("%s:%s", hostLogDir, config.ContainerEnvFile("/app/mcp-compose.yaml", logDir)))
+	}
+
+	listenerTLS := cfg.ProxyListenerTLS()
+	tlsVolumes, err := certs.ContainerVolumes(listenerTLS, filepath.Dir(absConfigFile), "/app", false)
+	if err != nil {
+
+		return err
+	}
+	volumes = append(volumes, tlsVolumes...)
+	ports := []string{fmt.Sprintf("%s:%d:%d", bindHost, port, port)}
+	if listenerTLS.ACME != nil && listenerTLS.ACME.HTTPPort > 0 {
+		ports = append(ports, fmt.Sprintf("%s:%d:%d", bindHost, listenerTLS.ACME.HTTPPort, listenerTLS.ACME.HTTPPort))
+	}
+	scheme := "http"
+	if listenerTLS.Enabled {
+		scheme = "https"
+	}
+
 	if apiKey != "" {
 		env["MCP_API_KEY"] = apiKey
 	}
+	if project := config.ProjectNamespace(); project != "" {
+		env[config.ProjectNameEnv] = project // so the proxy addresses the project's containers
+	}
+
+	// The proxy checks its exposure again, against the host its port is published on
+	proxyArgs := []string{"proxy", "--file", "/app/mcp-compose.yaml", "--port", strconv.Itoa(port), "--published-host", bindHost}
+	if apiKey != "" {
+		proxyArgs = append(proxyArgs, "--api-key", apiKey)
+	}
+	if insecure {
+		proxyArgs = append(proxyArgs, "--insecure")
+	}
 
 	opts := &container.ContainerOptions{
-		Name:     "mcp-compose-http-proxy",
+		Name:     config.ContainerName("http-proxy"),
 		Image:    "mcp-compose-go-http-proxy:latest",
-		Ports:    []string{fmt.Sprintf("%d:%d", port, port)},
+		Command:  "./mcp-compose-executable",
+		Args:     proxyArgs,
+		Ports:    ports,
 		Env:      env,
-		Networks: []string{"mcp-net"},
-		Volumes: []string{
-			fmt.Sprintf("%s:/app/mcp-compose.yaml:ro", absConfigFile),
-			"/var/run/docker.sock:/var/run/docker.sock:ro",
-		},
+		Networks: proxyNetworks,
+		Volumes:  volumes,
 
 		// ADD SECURITY CONFIGURATION FOR PROXY CONTAINER:
 		User: "root", // Proxy needs root access for Docker socket
@@ -160,7 +316,7 @@ func startContainerizedGoProxy(cfg *config.ComposeConfig, projectName string, po
 	}
 
 	fmt.Printf("Go HTTP proxy container started with ID: %s\n", containerID[:12])
-	fmt.Printf("MCP Proxy (HTTP mode) is running at http://localhost:%d\n", port)
+	fmt.Printf("MCP Proxy (HTTP mode) is running at %s://localhost:%d\n", scheme, port)
 
 	if apiKey != "" {
 		fmt.Printf("API key authentication is enabled. Use 'Bearer %s' in Authorization header.\n", apiKey)
@@ -168,12 +324,12 @@ func startContainerizedGoProxy(cfg *config.ComposeConfig, projectName string, po
 
 	// Enhanced endpoint information
 	fmt.Println("\nAvailable endpoints:")
-	fmt.Printf("  Dashboard:     http://localhost:%d/\n", port)
-	fmt.Printf("  OpenAPI Spec:  http://localhost:%d/openapi.json\n", port)
-	fmt.Printf("  Server Status: http://localhost:%d/api/servers\n", port)
-	fmt.Printf("  Discovery:     http://localhost:%d/api/discovery\n", port)
-	fmt.Printf("  Subscriptions: http://localhost:%d/api/subscriptions\n", port)
-	fmt.Printf("  Notifications: http://localhost:%d/api/notifications\n", port)
+	fmt.Printf("  Dashboard:     %s://localhost:%d/\n", scheme, port)
+	fmt.Printf("  OpenAPI Spec:  %s://localhost:%d/openapi.json\n", scheme, port)
+	fmt.Printf("  Server Status: %s://localhost:%d/api/servers\n", scheme, port)
+	fmt.Printf("  Discovery:     %s://localhost:%d/api/discovery\n", scheme, port)
+	fmt.Printf("  Subscriptions: %s://localhost:%d/api/subscriptions\n", scheme, port)
+	fmt.Printf("  Notifications: %s://localhost:%d/api/notifications\n", scheme, port)
 
 	if err := generateProxyClientConfig(cfg, projectName, port, "claude", outputDir); err != nil {
 		fmt.Printf("Warning: Failed to generate client config: %v\n", err)
@@ -186,8 +342,30 @@ func startContainerizedGoProxy(cfg *config.ComposeConfig, projectName string, po
 	return nil
 }
 
-func startNativeGoProxy(cfg *config.ComposeConfig, _ string, port int, apiKey string, configFile string) error {
-	fmt.Printf("Starting native Go MCP proxy (HTTP transport) on port %d...\n", port)
+func startNativeGoProxy(cfg *config.ComposeConfig, _ string, port int, apiKey string, configFile string, upgrade bool, drainTimeout time.Duration, insecure bool, publishedHost string) error {
+	// Containers set MCP_PROXY_HOST to listen on every interface of their own network, and
+	// their launcher passes the host it publishes their port on, which decides who reaches them
+	bindHost := os.Getenv("MCP_PROXY_HOST")
+	if bindHost == "" {
+		bindHost = cfg.ProxyBindHost()
+	}
+	exposedHost := bindHost
+	if publishedHost != "" {
+		exposedHost = publishedHost
+	}
+	if err := checkProxyExposure(cfg, exposedHost, apiKey, insecure); err != nil {
+
+		return err
+	}
+	fmt.Printf("Starting native Go MCP proxy (HTTP transport) on %s...\n", net.JoinHostPort(bindHost, strconv.Itoa(port)))
+
+	// Proxies share the port so that one started with --upgrade can take over from the last
+	listener, err := server.ListenProxy(bindHost, port, upgrade)
+	if err != nil {
+
+		return err
+	}
+	defer listener.Release()
 
 	// Detect container runtime
 	cRuntime, err := container.DetectRuntime()
@@ -202,6 +380,7 @@ func startNativeGoProxy(cfg *config.ComposeConfig, _ string, port int, apiKey st
 
 		return fmt.Errorf("failed to create server manager: %w", err)
 	}
+	mgr.WatchCrashLoops()
 
 	// Try to create composer for full protocol integration (optional)
 	var composer *compose.Composer
@@ -215,6 +394,22 @@ func startNativeGoProxy(cfg *config.ComposeConfig, _ string, port int, apiKey st
 	// Create the proxy handler
 	handler := server.NewProxyHandler(mgr, configFile, apiKey)
 
+	// Servers with restricted egress reach other hosts only through the gateway
+	if egress.Enabled(cfg) {
+		gateway := egress.NewGateway(cfg, cRuntime, logging.NewLogger(cfg.Logging.Level))
+		gatewayListener, err := server.ListenShared(fmt.Sprintf(":%d", constants.EgressGatewayPort))
+		if err != nil {
+
+			return fmt.Errorf("failed to listen for the egress gateway: %w", err)
+		}
+		go func() {
+			if err := gateway.Serve(gatewayListener); err != nil {
+				fmt.Printf("Warning: Egress gateway stopped: %v\n", err)
+			}
+		}()
+		fmt.Printf("Egress gateway listening on port %d\n", constants.EgressGatewayPort)
+	}
+
 	// Set up cleanup on shutdown
 	if composer != nil {
 		defer func() {
@@ -228,6 +423,12 @@ func startNativeGoProxy(cfg *config.ComposeConfig, _ string, port int, apiKey st
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Scheduled memory backups run for as long as the proxy
+	if cfg.Memory.Enabled && cfg.Memory.Backup != nil {
+		go memory.NewManager(cfg, cRuntime).RunBackups(ctx)
+		fmt.Printf("Memory backups scheduled every %s\n", cfg.Memory.Backup.Interval)
+	}
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
@@ -244,6 +445,7 @@ func startNativeGoProxy(cfg *config.ComposeConfig, _ string, port int, apiKey st
 			fmt.Printf("Warning: Manager shutdown error: %v\n", err)
 		}
 
+		listener.Release()
 		cancel()
 		os.Exit(0)
 	}()
@@ -265,42 +467,77 @@ func startNativeGoProxy(cfg *config.ComposeConfig, _ string, port int, apiKey st
 
 	// Create HTTP server with configurable timeouts
 	httpServer := &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
+		Addr:         net.JoinHostPort(bindHost, strconv.Itoa(port)),
 		Handler:      handler,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
 	}
 
-	fmt.Printf("MCP Proxy (HTTP mode) is running at http://localhost:%d\n", port)
+	listenerTLS := cfg.ProxyListenerTLS()
+	scheme := "http"
+	if listenerTLS.Enabled {
+		scheme = "https"
+	}
+
+	fmt.Printf("MCP Proxy (HTTP mode) is running at %s://localhost:%d\n", scheme, port)
 	if apiKey != "" {
 		fmt.Printf("API key authentication is enabled. Use 'Bearer %s' in Authorization header.\n", apiKey)
 	}
 
 	// Print enhanced endpoints available
 	fmt.Println("\nAvailable endpoints:")
-	fmt.Printf("  Dashboard:     http://localhost:%d/\n", port)
-	fmt.Printf("  OpenAPI Spec:  http://localhost:%d/openapi.json\n", port)
-	fmt.Printf("  Server Status: http://localhost:%d/api/servers\n", port)
-	fmt.Printf("  Discovery:     http://localhost:%d/api/discovery\n", port)
+	fmt.Printf("  Dashboard:     %s://localhost:%d/\n", scheme, port)
+	fmt.Printf("  OpenAPI Spec:  %s://localhost:%d/openapi.json\n", scheme, port)
+	fmt.Printf("  Server Status: %s://localhost:%d/api/servers\n", scheme, port)
+	fmt.Printf("  Discovery:     %s://localhost:%d/api/discovery\n", scheme, port)
 
 	// Print server-specific endpoints
 	for serverName := range cfg.Servers {
 		caser := cases.Title(language.English)
-		fmt.Printf("  %s Server:    http://localhost:%d/%s\n",
-			caser.String(serverName), port, serverName)
-		fmt.Printf("  %s OpenAPI:   http://localhost:%d/%s/openapi.json\n",
-			caser.String(serverName), port, serverName)
+		fmt.Printf("  %s Server:    %s://localhost:%d/%s\n",
+			caser.String(serverName), scheme, port, serverName)
+		fmt.Printf("  %s OpenAPI:   %s://localhost:%d/%s/openapi.json\n",
+			caser.String(serverName), scheme, port, serverName)
 	}
 
+	// A proxy replacing this one takes over new connections; the servers keep running
+	drain := make(chan os.Signal, 1)
+	server.NotifyDrain(drain)
+	go func() {
+		<-drain
+		drainTimeout := server.DrainTimeout(port, drainTimeout)
+		fmt.Printf("\nA new proxy took over port %d; draining connections for up to %s...\n", port, drainTimeout)
+
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer drainCancel()
+		if err := httpServer.Shutdown(drainCtx); err != nil {
+			fmt.Printf("Warning: Connections still open after %s were closed: %v\n", drainTimeout, err)
+			_ = httpServer.Close()
+		}
+
+		if err := handler.Shutdown(); err != nil {
+			fmt.Printf("Warning: ProxyHandler shutdown error: %v\n", err)
+		}
+
+		cancel()
+		os.Exit(0)
+	}()
+
 	// Start HTTP server in goroutine
 	go func() {
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := certs.Serve(httpServer, listener, listenerTLS, config.ProjectDir(configFile), logging.NewLogger(cfg.Logging.Level)); err != nil && err != http.ErrServerClosed {
 			fmt.Fprintf(os.Stderr, "HTTP server error: %v\n", err)
 			cancel()
 		}
 	}()
 
+	if err := listener.Activate(drainTimeout); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	} else if upgrade {
+		fmt.Println("Took over from the running proxy, which is draining its connections")
+	}
+
 	// Wait for cancellation
 	<-ctx.Done()
 
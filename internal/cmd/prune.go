@@ -0,0 +1,41 @@
+// internal/cmd/prune.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+
+	"github.com/spf13/cobra"
+)
+
+func NewPruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove resources left behind by servers deleted from the config",
+		Long: `Remove what 'up' created for the project that the config no longer uses:
+
+  - containers and processes of servers deleted or renamed in the config
+  - networks mcp-compose created that no server joins anymore
+  - volumes mcp-compose created that are neither declared nor mounted
+
+Resources are found by the labels mcp-compose puts on everything it creates
+(mcp-compose.managed-by, mcp-compose.project, mcp-compose.config and, on
+containers, mcp-compose.server and mcp-compose.config-hash) and by the project
+state file. Another project's resources are never touched, and volumes still
+mounted by a container are kept.
+
+Examples:
+  mcp-compose prune --dry-run
+  mcp-compose prune`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			return compose.Prune(file, dryRun)
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "List what would be removed without removing it")
+
+	return cmd
+}
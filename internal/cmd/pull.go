@@ -0,0 +1,43 @@
+// internal/cmd/pull.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewPullCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "pull [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Pull server images and record their digests in the lock file",
+		Long: `Pull the images of the named servers, or of all servers, several at a time, and
+record the digest each tag resolved to in the lock file next to the config
+(mcp-compose.lock.yaml for mcp-compose.yaml). While the lock file exists, 'up' runs the
+locked digests, so every deployment from it gets the same images.
+
+With --locked the digests already in the lock file are pulled and the file is left
+as it is, for hosts that deploy a committed lock file.
+
+Servers built from a context and servers with pull_policy: never are skipped.
+
+Examples:
+  mcp-compose pull
+  mcp-compose pull filesystem memory --parallel 2
+  mcp-compose pull --locked`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			parallel, _ := cmd.Flags().GetInt("parallel")
+			locked, _ := cmd.Flags().GetBool("locked")
+
+			return compose.Pull(file, args, compose.PullOptions{Parallel: parallel, Locked: locked})
+		},
+	}
+	cmd.Flags().Int("parallel", constants.PullDefaultParallel, "Number of images to pull at once")
+	cmd.Flags().Bool("locked", false, "Pull the digests in the lock file instead of updating it")
+
+	return cmd
+}
@@ -0,0 +1,81 @@
+// internal/cmd/quota.go
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewQuotaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quota",
+		Short: "Show and reset the monthly quotas of clients",
+		Long: `Show how much of its monthly quota each client has used, as the running proxy
+counts it. Quotas are set under quotas in the compose file, per client id as
+/api/analytics/clients reports it, and reset at the start of each UTC month.
+
+Examples:
+  mcp-compose quota
+  mcp-compose quota reset oauth:client-1
+  mcp-compose quota reset --all`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			opts := quotaOptions(cmd)
+			opts.JSON = jsonOutput
+
+			return compose.ListQuotas(file, opts)
+		},
+	}
+	addQuotaProxyFlags(cmd)
+	cmd.Flags().Bool("json", false, "Print the quotas as JSON")
+	cmd.AddCommand(newQuotaResetCommand())
+
+	return cmd
+}
+
+func newQuotaResetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "reset [CLIENT]",
+		Short:        "Clear the month's usage of a client, or of every client with --all",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			all, _ := cmd.Flags().GetBool("all")
+			if all == (len(args) == 1) {
+
+				return errors.New("name a client or pass --all")
+			}
+			clientID := ""
+			if len(args) == 1 {
+				clientID = args[0]
+			}
+
+			return compose.ResetQuota(file, clientID, quotaOptions(cmd))
+		},
+	}
+	addQuotaProxyFlags(cmd)
+	cmd.Flags().Bool("all", false, "Reset every client")
+
+	return cmd
+}
+
+func addQuotaProxyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("proxy-url", fmt.Sprintf("http://localhost:%d", constants.DefaultProxyPort), "URL of the running MCP proxy")
+	cmd.Flags().String("api-key", "", "API key for proxy authentication (defaults to proxy_auth.api_key)")
+}
+
+func quotaOptions(cmd *cobra.Command) compose.QuotaOptions {
+	proxyURL, _ := cmd.Flags().GetString("proxy-url")
+	apiKey, _ := cmd.Flags().GetString("api-key")
+
+	return compose.QuotaOptions{ProxyURL: proxyURL, APIKey: apiKey}
+}
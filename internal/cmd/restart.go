@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/phildougherty/mcp-compose/internal/compose"
 	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/container"
 	"github.com/phildougherty/mcp-compose/internal/dashboard"
 	"github.com/phildougherty/mcp-compose/internal/task_scheduler"
@@ -14,17 +15,26 @@ import (
 
 func NewRestartCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "restart [SERVER|proxy|dashboard]...",
-		Short: "Restart MCP servers, proxy, or dashboard",
+		Use:               "restart [SERVER|proxy|dashboard]...",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Restart MCP servers, proxy, or dashboard",
 		Long: `Restart MCP servers, the proxy server, or the dashboard.
 
 Examples:
   mcp-compose restart                    # Restart all servers
   mcp-compose restart server1 server2   # Restart specific servers
   mcp-compose restart proxy             # Restart the HTTP proxy
-  mcp-compose restart dashboard         # Restart the dashboard`,
+  mcp-compose restart dashboard         # Restart the dashboard
+  mcp-compose restart --rolling         # Restart servers one at a time, waiting for health`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, _ := cmd.Flags().GetString("file")
+			rolling, _ := cmd.Flags().GetBool("rolling")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			if rolling {
+
+				return compose.RollingRestart(file, args, timeout)
+			}
 
 			// If no args provided, restart all servers
 			if len(args) == 0 {
@@ -63,6 +73,9 @@ Examples:
 		},
 	}
 
+	cmd.Flags().Bool("rolling", false, "Restart servers one at a time in dependency order, waiting for each to become healthy")
+	cmd.Flags().Duration("timeout", constants.RollingRestartTimeout, "How long to wait for each server to become healthy during a rolling restart")
+
 	return cmd
 }
 
@@ -101,7 +114,7 @@ func restartProxy() error {
 		return fmt.Errorf("failed to detect container runtime: %w", err)
 	}
 
-	proxyContainerName := "mcp-compose-http-proxy"
+	proxyContainerName := config.ContainerName("http-proxy")
 
 	// Check if proxy container exists and is running
 	status, err := runtime.GetContainerStatus(proxyContainerName)
@@ -142,7 +155,12 @@ func restartDashboard(configFile string) error {
 		return fmt.Errorf("failed to detect container runtime: %w", err)
 	}
 
-	dashboardContainerName := "mcp-compose-dashboard"
+	if err := checkDashboardExposure(cfg, cfg.Dashboard.BindHost(), false); err != nil {
+
+		return fmt.Errorf("%w (to start it anyway, use: mcp-compose dashboard --insecure)", err)
+	}
+
+	dashboardContainerName := config.ContainerName("dashboard")
 
 	// Check if dashboard container exists and is running
 	status, err := runtime.GetContainerStatus(dashboardContainerName)
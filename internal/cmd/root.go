@@ -2,6 +2,13 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+
 	"github.com/spf13/cobra"
 )
 
@@ -13,25 +20,169 @@ func NewRootCommand(version string) *cobra.Command {
 		Version: version, // ← Add this line to enable --version flag
 	}
 
-	rootCmd.PersistentFlags().StringP("file", "c", "mcp-compose.yaml", "Specify compose file")
+	files := newComposeFiles()
+	rootCmd.PersistentFlags().VarP(files, "file", "c", "Specify compose file; repeat to merge override files over it")
+	rootCmd.PersistentFlags().String("signing-key", os.Getenv("MCP_COMPOSE_SIGNING_KEY"), "Base64 ed25519 public key remote compose files must be signed with")
+	rootCmd.PersistentFlags().StringArray("env-file", nil, "Load variables for ${VAR} in the compose file from this file, over .env and env_files; repeatable")
+	rootCmd.PersistentFlags().String("project-name", os.Getenv(config.ProjectNameEnv), "Prefix container, network and volume names so several stacks can run on one host")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		projectName, _ := cmd.Flags().GetString("project-name")
+		if err := config.SetProjectNamespace(projectName); err != nil {
+
+			return err
+		}
+		signingKey, _ := cmd.Flags().GetString("signing-key")
+		if err := files.fetchRemote(signingKey); err != nil {
+
+			return err
+		}
+		config.SetOverrideFiles(files.overrides())
+		envFiles, _ := cmd.Flags().GetStringArray("env-file")
+		for _, envFile := range envFiles {
+			if _, err := os.Stat(envFile); err != nil {
+
+				return fmt.Errorf("failed to read env file: %w", err)
+			}
+		}
+		config.SetEnvFiles(envFiles)
+
+		return nil
+	}
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
 
 	// Add subcommands
 	rootCmd.AddCommand(NewUpCommand())
 	rootCmd.AddCommand(NewDownCommand())
+	rootCmd.AddCommand(NewPullCommand())
+	rootCmd.AddCommand(NewLockCommand())
+	rootCmd.AddCommand(NewSystemdCommand())
+	rootCmd.AddCommand(NewGenerateCommand())
 	rootCmd.AddCommand(NewStartCommand())
 	rootCmd.AddCommand(NewStopCommand())
 	rootCmd.AddCommand(NewRestartCommand())
 	rootCmd.AddCommand(NewLsCommand())
+	rootCmd.AddCommand(NewTopCommand())
+	rootCmd.AddCommand(NewCICommand())
+	rootCmd.AddCommand(NewTestCommand())
+	rootCmd.AddCommand(NewMockCommand())
+	rootCmd.AddCommand(NewGenerateClientCommand())
 	rootCmd.AddCommand(NewLogsCommand())
+	rootCmd.AddCommand(NewJobsCommand())
+	rootCmd.AddCommand(NewEventsCommand())
+	rootCmd.AddCommand(NewQuotaCommand())
+	rootCmd.AddCommand(NewSupportBundleCommand())
+	rootCmd.AddCommand(NewVolumeCommand())
+	rootCmd.AddCommand(NewPruneCommand())
 	rootCmd.AddCommand(NewValidateCommand())
+	rootCmd.AddCommand(NewDoctorCommand())
+	rootCmd.AddCommand(NewConfigCommand())
 	rootCmd.AddCommand(NewCompletionCommand())
 	rootCmd.AddCommand(NewCreateConfigCommand())
+	rootCmd.AddCommand(NewClientConfigCommand())
+	rootCmd.AddCommand(NewImportCommand())
+	rootCmd.AddCommand(NewInitCommand())
+	rootCmd.AddCommand(NewAddCommand())
+	rootCmd.AddCommand(NewSearchCommand())
 	rootCmd.AddCommand(NewProxyCommand())
 	rootCmd.AddCommand(NewReloadCommand())
 	rootCmd.AddCommand(NewDashboardCommand())
 	rootCmd.AddCommand(NewTaskSchedulerCommand())
 	rootCmd.AddCommand(NewMemoryCommand())
+	rootCmd.AddCommand(NewSuperviseProcessCommand())
 
 	return rootCmd
 }
+
+// composeFiles is the repeatable --file flag. The first file is the project's compose file,
+// which is what GetString("file") returns; the others are merged over it when it is loaded.
+type composeFiles struct {
+	files   []string
+	changed bool
+}
+
+// newComposeFiles defaults to MCP_COMPOSE_FILE, a list of files separated like PATH, or
+// mcp-compose.yaml
+func newComposeFiles() *composeFiles {
+	f := &composeFiles{files: []string{"mcp-compose.yaml"}}
+	if env := os.Getenv("MCP_COMPOSE_FILE"); env != "" {
+		f.files = splitFileList(env)
+	}
+
+	return f
+}
+
+func (f *composeFiles) String() string {
+
+	return f.files[0]
+}
+
+func (f *composeFiles) Set(value string) error {
+	if !f.changed {
+		f.files = nil
+		f.changed = true
+	}
+	f.files = append(f.files, value)
+
+	return nil
+}
+
+func (f *composeFiles) Type() string {
+
+	return "string"
+}
+
+func (f *composeFiles) overrides() []string {
+
+	return f.files[1:]
+}
+
+// fetchRemote replaces HTTP(S) and git sources with their locally cached copies
+func (f *composeFiles) fetchRemote(signingKey string) error {
+	for i, file := range f.files {
+		if !config.IsRemoteSource(file) {
+
+			continue
+		}
+		localPath, err := config.FetchRemoteFile(file, signingKey)
+		if err != nil {
+
+			return err
+		}
+		f.files[i] = localPath
+	}
+
+	return nil
+}
+
+// splitFileList splits a list of files separated like PATH, keeping the colons of URLs
+// (scheme, port, scp-style git host) inside the URL
+func splitFileList(list string) []string {
+	var files []string
+	for _, part := range filepath.SplitList(list) {
+		if n := len(files); n > 0 && os.PathListSeparator == ':' && continuesURL(files[n-1], part) {
+			files[n-1] += ":" + part
+
+			continue
+		}
+		files = append(files, part)
+	}
+
+	return files
+}
+
+func continuesURL(previous, part string) bool {
+	switch {
+	case strings.HasPrefix(part, "//"):
+
+		return true
+	case strings.HasPrefix(previous, "git@"):
+
+		return !strings.Contains(previous, ":")
+	case config.IsRemoteSource(previous) && part != "":
+
+		return part[0] >= '0' && part[0] <= '9' && strings.Count(previous, ":") == 1
+	default:
+
+		return false
+	}
+}
@@ -9,15 +9,23 @@ import (
 
 func NewStartCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "start [SERVER...]",
-		Short: "Start specific MCP servers",
-		Args:  cobra.MinimumNArgs(1),
+		Use:               "start [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Start specific MCP servers",
+		Long: `Start the named MCP servers and their dependencies. With --profile and no
+names, start the servers that belong to those profiles.
+
+Examples:
+  mcp-compose start filesystem
+  mcp-compose start --profile debug`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, _ := cmd.Flags().GetString("file")
+			applyProfileFlag(cmd)
 
 			return compose.Start(file, args)
 		},
 	}
+	addProfileFlag(cmd)
 
 	return cmd
 }
@@ -14,8 +14,9 @@ import (
 
 func NewStopCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "stop [SERVER|proxy|dashboard]...",
-		Short: "Stop MCP servers, proxy, or dashboard",
+		Use:               "stop [SERVER|proxy|dashboard]...",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Stop MCP servers, proxy, or dashboard",
 		Long: `Stop MCP servers, the proxy server, or the dashboard.
 
 Examples:
@@ -79,7 +80,7 @@ func stopProxy() error {
 		return fmt.Errorf("failed to detect container runtime: %w", err)
 	}
 
-	proxyContainerName := "mcp-compose-http-proxy"
+	proxyContainerName := config.ContainerName("http-proxy")
 
 	if err := runtime.StopContainer(proxyContainerName); err != nil {
 
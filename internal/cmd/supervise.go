@@ -0,0 +1,44 @@
+// internal/cmd/supervise.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// NewSuperviseProcessCommand runs a process-based server under its restart policy. It is
+// started detached by mcp-compose itself and is not meant to be run by hand.
+func NewSuperviseProcessCommand() *cobra.Command {
+	var opts runtime.SupervisorOptions
+	cmd := &cobra.Command{
+		Use:    "supervise-process --name NAME [--restart POLICY] --log FILE -- COMMAND [ARGS...]",
+		Short:  "Run a process-based server and restart it according to its restart policy",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Command = args[0]
+			opts.Args = args[1:]
+
+			return runtime.RunSupervisor(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Process identifier used for its PID and state files")
+	cmd.Flags().StringVar(&opts.RestartPolicy, "restart", "", "Restart policy: no, on-failure[:max], always, unless-stopped")
+	cmd.Flags().StringVar(&opts.LogFile, "log", "", "File the process output is appended to")
+	cmd.Flags().Int64Var(&opts.LogRotation.MaxSize, "log-max-size", constants.ProcessLogMaxSize, "Rotate the log file before it grows past this many bytes")
+	cmd.Flags().IntVar(&opts.LogRotation.MaxFiles, "log-max-files", constants.ProcessLogMaxFiles, "Number of rotated log files to keep")
+	cmd.Flags().DurationVar(&opts.LogRotation.RotateEvery, "log-rotate-every", 0, "Also rotate the log file once it is this old")
+	cmd.Flags().DurationVar(&opts.LogRotation.MaxAge, "log-max-age", 0, "Remove rotated log files older than this")
+	cmd.Flags().IntVar(&opts.CrashLoop.MaxRestarts, "crash-max-restarts", 0, "Back the process off after more than this many restarts within --crash-window (0 disables)")
+	cmd.Flags().DurationVar(&opts.CrashLoop.Window, "crash-window", constants.CrashLoopWindow, "Window restarts are counted over")
+	cmd.Flags().DurationVar(&opts.CrashLoop.Backoff, "crash-backoff", constants.CrashLoopBackoff, "How long a crash-looping process waits before it is started again")
+	cmd.Flags().StringVar(&opts.NotifyFile, "notify", "", "JSON file with the notification hooks told about crash loops")
+	cmd.Flags().BoolVar(&opts.Stdio, "stdio", false, "Serve the process's stdin and stdout on a local socket, or a named pipe on Windows")
+	cmd.Flags().BoolVar(&opts.Standalone, "standalone", false, "Create the run and log directories and record the supervisor's PID, when a service manager starts it")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("log")
+
+	return cmd
+}
@@ -0,0 +1,60 @@
+// internal/cmd/support_bundle.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewSupportBundleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Collect config, logs, versions and health into a tar.gz for bug reports",
+		Long: `Collect what is needed to act on a bug report into a tar.gz: the rendered config,
+recent logs of every server and of the proxy, dashboard and task scheduler containers,
+mcp-compose, Go and container runtime versions, and server status and health history
+from the running proxy (or from the container runtime when the proxy is down).
+
+Secrets are redacted automatically: values configured under secret names, passwords in
+URLs, bearer tokens and secret NAME=value pairs are replaced in every file. Look the
+bundle over before sharing it all the same.
+
+Examples:
+  mcp-compose support-bundle
+  mcp-compose support-bundle -o bug-123.tar.gz --lines 2000`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			output, _ := cmd.Flags().GetString("output")
+			proxyURL, _ := cmd.Flags().GetString("proxy-url")
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			lines, _ := cmd.Flags().GetInt("lines")
+
+			path, err := compose.SupportBundle(file, compose.SupportBundleOptions{
+				Output:   output,
+				ProxyURL: proxyURL,
+				APIKey:   apiKey,
+				LogLines: lines,
+				Version:  cmd.Root().Version,
+			})
+			if err != nil {
+
+				return err
+			}
+			fmt.Printf("✅ Wrote support bundle to %s\n", path)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Path of the archive (default mcp-compose-support-<timestamp>.tar.gz)")
+	cmd.Flags().String("proxy-url", fmt.Sprintf("http://localhost:%d", constants.DefaultProxyPort), "URL of the running MCP proxy")
+	cmd.Flags().String("api-key", "", "API key for proxy authentication (defaults to proxy_auth.api_key)")
+	cmd.Flags().Int("lines", constants.SupportBundleLogLines, "Lines of log to include per server")
+
+	return cmd
+}
@@ -0,0 +1,81 @@
+// internal/cmd/systemd.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+
+	"github.com/spf13/cobra"
+)
+
+func NewSystemdCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "systemd",
+		Short: "Run the servers as systemd services under Podman",
+		Long: `Export the servers as systemd units so Podman starts them on boot, without
+mcp-compose or a container daemon running.`,
+	}
+	cmd.AddCommand(newSystemdExportCommand())
+
+	return cmd
+}
+
+func newSystemdExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "export [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Write Podman quadlets or systemd units for the servers",
+		Long: `Write a systemd unit for each server, or for the named servers and their
+dependencies. The default quadlet format writes .container and .network files that
+Podman 4.4 and later turn into services; --format unit writes .service files that run
+podman run, for older Podman.
+
+Units go where the current user's systemd manager reads them, so rootless servers
+start when the user's manager does; with 'loginctl enable-linger' that is at boot.
+--system writes them for the system manager instead. Dependencies become Requires= and
+After=, restart policies become Restart=, and the images pinned in the lock file are
+used. Under rootless Podman a server with a numeric user and bind mounts runs with the
+host user mapped to that user, so it can write to the mounted files.
+
+Process-based servers that serve HTTP become plain services; stdio process servers are
+skipped, as only the proxy can reach them. 'mcp-compose generate systemd' also writes
+units for the proxy and dashboard. Each server's environment goes in an environment
+file readable by its owner only.
+
+Examples:
+  mcp-compose systemd export
+  mcp-compose systemd export github --stdout
+  mcp-compose systemd export --format unit --system`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			applyProfileFlag(cmd)
+
+			return compose.SystemdExport(file, args, systemdExportOptions(cmd))
+		},
+	}
+	addSystemdExportFlags(cmd)
+
+	return cmd
+}
+
+// addSystemdExportFlags adds the flags shared by systemd export and generate systemd
+func addSystemdExportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", compose.SystemdFormatQuadlet, "Unit format: quadlet or unit")
+	cmd.Flags().StringP("output", "o", "", "Directory to write the units to instead of systemd's")
+	cmd.Flags().Bool("system", false, "Write units for the system manager instead of the current user's")
+	cmd.Flags().Bool("stdout", false, "Print the units instead of writing them")
+}
+
+func systemdExportOptions(cmd *cobra.Command) compose.SystemdExportOptions {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	system, _ := cmd.Flags().GetBool("system")
+	stdout, _ := cmd.Flags().GetBool("stdout")
+
+	return compose.SystemdExportOptions{
+		Format:    format,
+		OutputDir: output,
+		System:    system,
+		Stdout:    stdout,
+	}
+}
@@ -255,7 +255,7 @@ func enableTaskScheduler(configFile string, cfg *config.ComposeConfig) error {
 		SecurityOpt:  []string{"no-new-privileges:true"},
 		Capabilities: []string{"tools", "resources"},
 		Env: map[string]string{
-			"TZ":                                 "America/New_York",
+			"TZ":                                 cfg.ProjectTimezone(),
 			"MCP_CRON_SERVER_TRANSPORT":          "sse",
 			"MCP_CRON_SERVER_ADDRESS":            "0.0.0.0",
 			"MCP_CRON_SERVER_PORT":               fmt.Sprintf("%d", cfg.TaskScheduler.Port),
@@ -275,7 +275,7 @@ func enableTaskScheduler(configFile string, cfg *config.ComposeConfig) error {
 			"MCP_CRON_OPENWEBUI_ENABLED":         strconv.FormatBool(cfg.TaskScheduler.OpenWebUIEnabled),
 		},
 		Volumes:  append(cfg.TaskScheduler.Volumes, "task-scheduler-data:/data"),
-		Networks: []string{"mcp-net"},
+		Networks: []string{config.DefaultNetwork()},
 		Authentication: &config.ServerAuthConfig{
 			Enabled:       true,
 			RequiredScope: "mcp:tools",
@@ -311,7 +311,7 @@ func disableTaskScheduler(configFile string, cfg *config.ComposeConfig) error {
 	}
 
 	// Stop the container if running
-	if err := runtime.StopContainer("mcp-compose-task-scheduler"); err != nil {
+	if err := runtime.StopContainer(config.ContainerName("task-scheduler")); err != nil {
 		fmt.Printf("Warning: %v\n", err)
 	}
 
@@ -429,7 +429,7 @@ func runNativeTaskScheduler(cfg *config.ComposeConfig, port int, host, dbPath, w
 	}
 }
 
-func runContainerizedTaskScheduler(_ *config.ComposeConfig, _ string, port int, host, dbPath, workspace, logLevel, mcpProxyURL, mcpProxyAPIKey, ollamaURL, ollamaModel, openrouterAPIKey, openrouterModel, cpus, memory string, healthCheck, debug bool) error {
+func runContainerizedTaskScheduler(cfg *config.ComposeConfig, _ string, port int, host, dbPath, workspace, logLevel, mcpProxyURL, mcpProxyAPIKey, ollamaURL, ollamaModel, openrouterAPIKey, openrouterModel, cpus, memory string, healthCheck, debug bool) error {
 	fmt.Printf("Starting containerized task scheduler on %s:%d...\n", host, port)
 
 	runtime, err := container.DetectRuntime()
@@ -445,12 +445,12 @@ func runContainerizedTaskScheduler(_ *config.ComposeConfig, _ string, port int,
 	}
 
 	// Stop existing container
-	_ = runtime.StopContainer("mcp-compose-task-scheduler")
+	_ = runtime.StopContainer(config.ContainerName("task-scheduler"))
 
 	// Ensure network exists
-	networkExists, _ := runtime.NetworkExists("mcp-net")
+	networkExists, _ := runtime.NetworkExists(config.DefaultNetwork())
 	if !networkExists {
-		if err := runtime.CreateNetwork("mcp-net"); err != nil {
+		if err := runtime.CreateNetwork(config.DefaultNetwork()); err != nil {
 
 			return fmt.Errorf("failed to create mcp-net network: %w", err)
 		}
@@ -459,7 +459,7 @@ func runContainerizedTaskScheduler(_ *config.ComposeConfig, _ string, port int,
 
 	// Prepare environment variables with proper Docker network endpoints
 	env := map[string]string{
-		"TZ":                                 "America/New_York",
+		"TZ":                                 cfg.ProjectTimezone(),
 		"MCP_CRON_SERVER_TRANSPORT":          "sse",
 		"MCP_CRON_SERVER_ADDRESS":            "0.0.0.0",
 		"MCP_CRON_SERVER_PORT":               fmt.Sprintf("%d", port), // Use actual port (8018)
@@ -476,20 +476,21 @@ func runContainerizedTaskScheduler(_ *config.ComposeConfig, _ string, port int,
 		"OPENROUTER_ENABLED": "true",
 		"OPENROUTER_MODEL":   "anthropic/claude-3.5-sonnet",
 		// Docker network endpoints - CRITICAL FIXES
-		"MCP_PROXY_URL":           "http://mcp-compose-http-proxy:9876", // Main HTTP proxy
+		"MCP_PROXY_URL":           "http://" + config.ContainerName("http-proxy") + ":9876", // Main HTTP proxy
 		"MCP_PROXY_TOOLS_ENABLED": "true",
-		"MCP_MEMORY_SERVER_URL":   "http://mcp-compose-memory:3001",              // Memory server
-		"MCP_TOOLS_BASE_URL":      "http://mcp-compose-memory:3001",              // Alternative env var
-		"MCP_TOOLS_ENDPOINT":      "http://mcp-compose-memory:3001/openapi.json", // Direct endpoint
+		"MCP_MEMORY_SERVER_URL":   "http://" + config.ContainerName("memory") + ":3001",              // Memory server
+		"MCP_TOOLS_BASE_URL":      "http://" + config.ContainerName("memory") + ":3001",              // Alternative env var
+		"MCP_TOOLS_ENDPOINT":      "http://" + config.ContainerName("memory") + ":3001/openapi.json", // Direct endpoint
 		// Fix hardcoded localhost:3001 in model router
-		"MCP_CRON_OPENROUTER_MCP_PROXY_URL": "http://mcp-compose-memory:3001", // Model router gateway
-		"MCP_GATEWAY_URL":                   "http://mcp-compose-memory:3001", // Alternative key
+		"MCP_CRON_OPENROUTER_MCP_PROXY_URL": "http://" + config.ContainerName("memory") + ":3001", // Model router gateway
+		"MCP_GATEWAY_URL":                   "http://" + config.ContainerName("memory") + ":3001", // Alternative key
 		// Additional MCP service endpoints on Docker network
 		"MCP_FILESYSTEM_URL":         "http://mcp-compose-filesystem:3000",
 		"MCP_OPENROUTER_GATEWAY_URL": "http://mcp-compose-openrouter-gateway:8012",
 		"MCP_MEAL_LOG_URL":           "http://mcp-compose-meal-log:8011",
 		"MCP_POSTGRES_MCP_URL":       "http://mcp-compose-postgres-mcp:8013",
 	}
+	cfg.ApplyLocale("task-scheduler", env)
 
 	// Override with provided values and fix network endpoints
 	if logLevel != "" {
@@ -508,13 +509,13 @@ func runContainerizedTaskScheduler(_ *config.ComposeConfig, _ string, port int,
 	if mcpProxyURL != "" {
 		// Convert external URLs to Docker network URLs
 		if strings.Contains(mcpProxyURL, "192.168.86.201:9876") || strings.Contains(mcpProxyURL, "localhost:9876") {
-			env["MCP_PROXY_URL"] = "http://mcp-compose-http-proxy:9876"
+			env["MCP_PROXY_URL"] = "http://" + config.ContainerName("http-proxy") + ":9876"
 			fmt.Printf("Converting proxy URL from %s to Docker network address\n", mcpProxyURL)
 		} else {
 			env["MCP_PROXY_URL"] = mcpProxyURL
 		}
 	} else {
-		env["MCP_PROXY_URL"] = "http://mcp-compose-http-proxy:9876" // Default to internal network
+		env["MCP_PROXY_URL"] = "http://" + config.ContainerName("http-proxy") + ":9876" // Default to internal network
 	}
 
 	if mcpProxyAPIKey != "" {
@@ -542,7 +543,7 @@ func runContainerizedTaskScheduler(_ *config.ComposeConfig, _ string, port int,
 		env["USE_OPENROUTER"] = "true"
 		env["OPENROUTER_ENABLED"] = "true"
 		// CRITICAL: Override the MCPProxyURL to use Docker network
-		env["MCP_CRON_OPENROUTER_MCP_PROXY_URL"] = "http://mcp-compose-memory:3001"
+		env["MCP_CRON_OPENROUTER_MCP_PROXY_URL"] = "http://" + config.ContainerName("memory") + ":3001"
 		env["MCP_CRON_OPENROUTER_MCP_PROXY_KEY"] = mcpProxyAPIKey
 		fmt.Println("OpenRouter enabled with Docker network proxy address")
 	} else {
@@ -567,16 +568,16 @@ func runContainerizedTaskScheduler(_ *config.ComposeConfig, _ string, port int,
 
 	// Container options with correct field names
 	opts := &container.ContainerOptions{
-		Name:     "mcp-compose-task-scheduler",
+		Name:     config.ContainerName("task-scheduler"),
 		Image:    "mcp-compose-task-scheduler:latest",
 		Ports:    []string{fmt.Sprintf("%d:%d", port, port)}, // Map external port to same internal port
 		Env:      env,
-		Networks: []string{"mcp-net"},
-		Volumes: []string{
+		Networks: []string{config.DefaultNetwork()},
+		Volumes: cfg.NamespaceMounts([]string{
 			"task-scheduler-data:/data",
 			fmt.Sprintf("%s:/workspace:rw", workspace),
 			"/tmp:/tmp:rw",
-		},
+		}),
 		User:        "root",
 		CPUs:        cpus,
 		Memory:      memory,
@@ -603,10 +604,10 @@ func runContainerizedTaskScheduler(_ *config.ComposeConfig, _ string, port int,
 	// Wait for container to be healthy
 	if healthCheck {
 		fmt.Printf("Waiting for task scheduler to become healthy...\n")
-		if err := waitForContainerHealth(runtime, "mcp-compose-task-scheduler", constants.ContainerHealthTimeout); err != nil {
+		if err := waitForContainerHealth(runtime, config.ContainerName("task-scheduler"), constants.ContainerHealthTimeout); err != nil {
 			fmt.Printf("Warning: Health check failed: %v\n", err)
 			// Show logs to help debug
-			showRecentLogs(runtime, "mcp-compose-task-scheduler")
+			showRecentLogs(runtime, config.ContainerName("task-scheduler"))
 		} else {
 			fmt.Printf("✅ Task scheduler is healthy!\n")
 		}
@@ -0,0 +1,45 @@
+// internal/cmd/test.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewTestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "test [SCENARIO...]",
+		SilenceUsage: true,
+		Short:        "Run the testing scenarios against the stack",
+		Long: `Run the scenarios from development.testing.scenarios through an in-process
+proxy. Servers the scenarios need are started if they aren't running and stopped
+again afterwards. Each tool call and resource read is checked against its
+expected_status and expect matchers (contains, not_contains, matches, json).
+
+Exits non-zero if any check fails; --junit and --json write reports for CI.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			junit, _ := cmd.Flags().GetString("junit")
+			jsonFile, _ := cmd.Flags().GetString("json")
+			keep, _ := cmd.Flags().GetBool("keep")
+
+			return compose.Test(file, compose.TestOptions{
+				Scenarios: args,
+				Timeout:   timeout,
+				JUnitFile: junit,
+				JSONFile:  jsonFile,
+				KeepUp:    keep,
+			})
+		},
+	}
+
+	cmd.Flags().Duration("timeout", constants.CIDefaultWaitTimeout, "How long to wait for servers to become healthy")
+	cmd.Flags().String("junit", "", "Write a JUnit XML report to this file")
+	cmd.Flags().String("json", "", "Write a JSON report to this file")
+	cmd.Flags().Bool("keep", false, "Leave servers started for the tests running")
+
+	return cmd
+}
@@ -0,0 +1,31 @@
+// internal/cmd/top.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewTopCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show a live view of server status, health, resource usage and recent errors",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			interval, _ := cmd.Flags().GetDuration("interval")
+			once, _ := cmd.Flags().GetBool("once")
+
+			return compose.Top(file, compose.TopOptions{
+				Interval: interval,
+				Once:     once,
+			})
+		},
+	}
+
+	cmd.Flags().Duration("interval", constants.TopDefaultRefreshInterval, "Refresh interval")
+	cmd.Flags().Bool("once", false, "Print a single snapshot and exit")
+
+	return cmd
+}
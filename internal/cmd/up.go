@@ -2,21 +2,72 @@
 package cmd
 
 import (
+	"errors"
+
 	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
 
 	"github.com/spf13/cobra"
 )
 
 func NewUpCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "up [SERVER...]",
-		Short: "Create and start MCP servers",
+		Use:               "up [SERVER...]",
+		ValidArgsFunction: completeServerNames,
+		Short:             "Create and start MCP servers",
+		Long: `Create and start MCP servers and their dependencies, or all servers when none
+are named. Servers with 'profiles:' only start when named or when one of their
+profiles is enabled with --profile or MCP_COMPOSE_PROFILES.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, _ := cmd.Flags().GetString("file")
+			wait, _ := cmd.Flags().GetBool("wait")
+			exitCodeFrom, _ := cmd.Flags().GetString("exit-code-from")
+			timeout, _ := cmd.Flags().GetDuration("wait-timeout")
+			notify, _ := cmd.Flags().GetBool("notify")
+			locked, _ := cmd.Flags().GetBool("locked")
+			applyProfileFlag(cmd)
+
+			if !wait && exitCodeFrom == "" && !notify {
+				if locked {
+					// Differences from the lock file are reported in full; usage would bury them
+					cmd.SilenceUsage = true
+
+					return compose.UpLocked(file, args)
+				}
 
-			return compose.Up(file, args)
+				return compose.Up(file, args)
+			}
+
+			// A non-zero exit from --exit-code-from is a result, not a usage problem
+			cmd.SilenceUsage = true
+
+			return compose.UpAndWait(file, args, compose.UpWaitOptions{
+				Wait:         wait,
+				ExitCodeFrom: exitCodeFrom,
+				Timeout:      timeout,
+				Notify:       notify,
+				Locked:       locked,
+			})
 		},
 	}
 
+	cmd.Flags().Bool("wait", false, "Block until the started servers are healthy")
+	cmd.Flags().String("exit-code-from", "", "Block until this server exits and return its exit code")
+	cmd.Flags().Duration("wait-timeout", constants.UpWaitDefaultTimeout, "Maximum time to wait for servers to become healthy")
+	cmd.Flags().Bool("notify", false, "Send a desktop notification when startup completes")
+	cmd.Flags().Bool("locked", false, "Refuse to start if images, build contexts or env differ from the lock file")
+	addProfileFlag(cmd)
+
 	return cmd
 }
+
+// ExitCode maps a command error to a process exit code, honouring server exit codes from `up --exit-code-from`
+func ExitCode(err error) int {
+	var exitErr *compose.ExitCodeError
+	if errors.As(err, &exitErr) {
+
+		return exitErr.Code
+	}
+
+	return 1
+}
@@ -11,12 +11,33 @@ func NewValidateCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "validate",
 		Short: "Validate the compose file",
+		Long: `Check the compose file and report every problem with its line and column.
+
+--schema prints a JSON Schema of the compose file instead, for editors. With
+yaml-language-server (VS Code YAML extension, Neovim, ...) save it and add
+
+  # yaml-language-server: $schema=./mcp-compose.schema.json
+
+to the top of mcp-compose.yaml.
+
+Examples:
+  mcp-compose validate
+  mcp-compose config validate --schema > mcp-compose.schema.json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, _ := cmd.Flags().GetString("file")
+			schema, _ := cmd.Flags().GetBool("schema")
+
+			if schema {
+
+				return compose.WriteSchema()
+			}
 
 			return compose.Validate(file)
 		},
 	}
+	cmd.Flags().Bool("schema", false, "Print the JSON Schema of the compose file instead of validating it")
 
 	return cmd
 }
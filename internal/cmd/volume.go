@@ -0,0 +1,102 @@
+// internal/cmd/volume.go
+package cmd
+
+import (
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+func NewVolumeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "volume",
+		Short: "List, prune and back up the named volumes of the servers",
+		Long: `Manage the named volumes the servers mount. Volumes declared under the top-level
+volumes are created with their driver, options and labels on 'mcp-compose up', and
+marked as created by mcp-compose so they can be pruned once the config stops using them.`,
+	}
+	cmd.AddCommand(newVolumeListCommand())
+	cmd.AddCommand(newVolumePruneCommand())
+	cmd.AddCommand(newVolumeBackupCommand())
+
+	return cmd
+}
+
+func newVolumeListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "ls",
+		Short:        "List declared, mounted and mcp-compose-created volumes",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			output, _ := cmd.Flags().GetString("output")
+
+			return compose.ListVolumes(file, output)
+		},
+	}
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func newVolumePruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove volumes created by mcp-compose that the config no longer uses",
+		Long: `Remove the volumes mcp-compose created that are neither declared under volumes
+nor mounted by any server. Volumes still mounted by a container are kept.
+
+Examples:
+  mcp-compose volume prune --dry-run
+  mcp-compose volume prune`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			return compose.PruneVolumes(file, dryRun)
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "List the volumes that would be removed without removing them")
+
+	return cmd
+}
+
+func newVolumeBackupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup [VOLUME...]",
+		Short: "Write a tar.gz snapshot of volumes to a directory or object storage",
+		Long: `Archive the contents of the named volumes, or of every existing volume of the
+project, as <volume>-<timestamp>.tar.gz. The volume is read through a throwaway
+container that mounts it read-only; stop the server first for a consistent copy of
+data it keeps writing.
+
+With --s3 the archives are uploaded to object_storage under volume-backups/.
+
+Examples:
+  mcp-compose volume backup
+  mcp-compose volume backup postgres-data -o /backups
+  mcp-compose volume backup --s3`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			output, _ := cmd.Flags().GetString("output")
+			s3, _ := cmd.Flags().GetBool("s3")
+			image, _ := cmd.Flags().GetString("image")
+
+			return compose.BackupVolumes(file, args, compose.VolumeBackupOptions{
+				OutputDir: output,
+				S3:        s3,
+				Image:     image,
+			})
+		},
+	}
+	cmd.Flags().StringP("output", "o", constants.VolumeBackupDir, "Directory for the archives")
+	cmd.Flags().Bool("s3", false, "Upload the archives to object_storage instead")
+	cmd.Flags().String("image", constants.VolumeBackupImage, "Image used to read the volume")
+
+	return cmd
+}
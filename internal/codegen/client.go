@@ -0,0 +1,249 @@
+// internal/codegen/client.go
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+
+	"github.com/phildougherty/mcp-compose/internal/openapi"
+)
+
+// ServerTools is the tool list discovered for one server
+type ServerTools struct {
+	Server string
+	Tools  []openapi.Tool
+}
+
+// SortServerTools orders servers and their tools so generated output is stable
+func SortServerTools(servers []ServerTools) {
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Server < servers[j].Server })
+	for _, srv := range servers {
+		sort.Slice(srv.Tools, func(i, j int) bool { return srv.Tools[i].Name < srv.Tools[j].Name })
+	}
+}
+
+// Names the Go client preamble declares, which tools must not be generated under
+var (
+	goClientTypes   = []string{"Client", "NewClient", "ContentItem", "ToolResult", "RPCError"}
+	goClientMethods = []string{"CallTool", "BaseURL", "APIKey", "HTTPClient"}
+)
+
+// GenerateGo renders a Go client package with one argument struct and method per tool
+func GenerateGo(packageName string, servers []ServerTools) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by mcp-compose generate-client. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "// Package %s is a typed client for tools exposed through the mcp-compose proxy.\n", packageName)
+	fmt.Fprintf(&b, "package %s\n\n", packageName)
+	b.WriteString(goClientPreamble)
+
+	methods, types := newIdentifiers(goClientMethods...), newIdentifiers(goClientTypes...)
+	for _, srv := range servers {
+		for _, tool := range srv.Tools {
+			typeName := claimToolName(exportedName(srv.Server, tool.Name), methods, types)
+			argsType := typeName + "Args"
+
+			fmt.Fprintf(&b, "// %s holds the arguments for %s/%s\n", argsType, srv.Server, tool.Name)
+			fmt.Fprintf(&b, "type %s struct {\n", argsType)
+			props := schemaProperties(tool.InputSchema)
+			required := requiredSet(tool.InputSchema)
+			fields := newIdentifiers()
+			for _, propName := range sortedKeys(props) {
+				propSchema, _ := props[propName].(map[string]interface{})
+				if desc, _ := propSchema["description"].(string); desc != "" {
+					fmt.Fprintf(&b, "\t// %s\n", commentText(desc))
+				}
+				goType := goTypeFor(propSchema)
+				tag := propName
+				if !required[propName] {
+					tag += ",omitempty"
+					if isGoScalar(goType) {
+						goType = "*" + goType
+					}
+				}
+				fmt.Fprintf(&b, "\t%s %s `json:%s`\n", fields.claim(exportedName(propName)), goType, strconv.Quote(tag))
+			}
+			b.WriteString("}\n\n")
+
+			if tool.Description != "" {
+				fmt.Fprintf(&b, "// %s calls %s/%s: %s\n", typeName, srv.Server, tool.Name, commentText(tool.Description))
+			} else {
+				fmt.Fprintf(&b, "// %s calls %s/%s\n", typeName, srv.Server, tool.Name)
+			}
+			fmt.Fprintf(&b, "func (c *Client) %s(ctx context.Context, args %s) (*ToolResult, error) {\n", typeName, argsType)
+			fmt.Fprintf(&b, "\treturn c.CallTool(ctx, %s, %s, args)\n}\n\n", strconv.Quote(srv.Server), strconv.Quote(tool.Name))
+		}
+	}
+
+	formatted, err := format.Source(b.Bytes())
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to format generated Go client: %w", err)
+	}
+
+	return formatted, nil
+}
+
+func goTypeFor(schema map[string]interface{}) string {
+	switch schemaType(schema) {
+	case "string":
+
+		return "string"
+	case "integer":
+
+		return "int64"
+	case "number":
+
+		return "float64"
+	case "boolean":
+
+		return "bool"
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+
+			return "[]interface{}"
+		}
+
+		return "[]" + goTypeFor(items)
+	case "object":
+
+		return "map[string]interface{}"
+	default:
+
+		return "interface{}"
+	}
+}
+
+func isGoScalar(goType string) bool {
+	switch goType {
+	case "string", "int64", "float64", "bool":
+
+		return true
+	}
+
+	return false
+}
+
+// schemaType returns the JSON schema type, picking the first non-null entry for union types
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+
+				return s
+			}
+		}
+	}
+
+	return ""
+}
+
+const goClientPreamble = `import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Client calls MCP tools through the mcp-compose proxy
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+	nextID     int64
+}
+
+// NewClient creates a client for the proxy at baseURL
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		APIKey:     apiKey,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// ContentItem is one entry of a tool result
+type ContentItem struct {
+	Type     string          ` + "`json:\"type\"`" + `
+	Text     string          ` + "`json:\"text,omitempty\"`" + `
+	Data     string          ` + "`json:\"data,omitempty\"`" + `
+	MimeType string          ` + "`json:\"mimeType,omitempty\"`" + `
+	Resource json.RawMessage ` + "`json:\"resource,omitempty\"`" + `
+}
+
+// ToolResult is the result of a tools/call request
+type ToolResult struct {
+	Content []ContentItem ` + "`json:\"content\"`" + `
+	IsError bool          ` + "`json:\"isError,omitempty\"`" + `
+}
+
+// RPCError is a JSON-RPC error returned by the proxy or server
+type RPCError struct {
+	Code    int             ` + "`json:\"code\"`" + `
+	Message string          ` + "`json:\"message\"`" + `
+	Data    json.RawMessage ` + "`json:\"data,omitempty\"`" + `
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+// CallTool sends a tools/call request for any tool on the given server
+func (c *Client) CallTool(ctx context.Context, server, tool string, args interface{}) (*ToolResult, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      atomic.AddInt64(&c.nextID, 1),
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      tool,
+			"arguments": args,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/"+server, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", server, err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Result *ToolResult ` + "`json:\"result\"`" + `
+		Error  *RPCError   ` + "`json:\"error\"`" + `
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode response from %s (HTTP %d): %w", server, resp.StatusCode, err)
+	}
+	if envelope.Error != nil {
+		return nil, envelope.Error
+	}
+	if envelope.Result == nil {
+		return nil, fmt.Errorf("empty result from %s/%s", server, tool)
+	}
+
+	return envelope.Result, nil
+}
+
+`
@@ -0,0 +1,110 @@
+package codegen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/openapi"
+)
+
+// fixtureCatalog has tools whose names convert to the same identifiers
+func fixtureCatalog() []ServerTools {
+	servers := []ServerTools{
+		{Server: "a", Tools: []openapi.Tool{
+			{Name: "b_c", Description: "Tool b_c of a", InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"foo_bar": map[string]interface{}{"type": "string", "description": "snake case"},
+					"fooBar":  map[string]interface{}{"type": "integer"},
+					"1st":     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}},
+					"名前":      map[string]interface{}{"type": "string"},
+				},
+				"required": []interface{}{"foo_bar"},
+			}},
+		}},
+		{Server: "a_b", Tools: []openapi.Tool{{Name: "c"}}},
+		{Server: "call", Tools: []openapi.Tool{{Name: "tool"}}},
+		{Server: "next", Tools: []openapi.Tool{{Name: "id"}, {Name: "id-args"}}},
+	}
+	SortServerTools(servers)
+
+	return servers
+}
+
+func TestGenerateGoCompiles(t *testing.T) {
+	source, err := GenerateGo("client", fixtureCatalog())
+	if err != nil {
+		t.Fatalf("Failed to generate the Go client: %v", err)
+	}
+	for _, expected := range []string{
+		"func (c *Client) ABC(", "func (c *Client) ABC2(", "func (c *Client) CallTool2(",
+		"FooBar *int64", "FooBar2 string", "`json:\"1st,omitempty\"`",
+	} {
+		if !strings.Contains(string(source), expected) {
+			t.Errorf("Expected the generated client to contain %q", expected)
+		}
+	}
+
+	goBinary, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/client\n\ngo 1.21\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "client.go"), source, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goBinary, "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("The generated client does not compile: %v\n%s\n%s", err, output, source)
+	}
+}
+
+func TestGenerateTypeScriptNames(t *testing.T) {
+	source := string(GenerateTypeScript(fixtureCatalog()))
+
+	methods := make(map[string]bool)
+	for _, match := range regexp.MustCompile(`(?m)^  (\w+)\(args: `).FindAllStringSubmatch(source, -1) {
+		if methods[match[1]] {
+			t.Errorf("Expected each tool to have its own method, %s is repeated", match[1])
+		}
+		methods[match[1]] = true
+	}
+	for _, expected := range []string{"aBC", "aBC2", "callTool2", "nextId2"} {
+		if !methods[expected] {
+			t.Errorf("Expected a method %s, got %v", expected, methods)
+		}
+	}
+	if strings.Count(source, "export interface ABCArgs ") != 1 || !strings.Contains(source, "export interface ABC2Args ") {
+		t.Error("Expected each tool to have its own argument interface")
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"foo_bar": "FooBar",
+		"fooBar":  "FooBar",
+		"1st":     "X1st",
+		"":        "X",
+		"名前":      "X名前",
+		"é-tat":   "ÉTat",
+	}
+	for input, expected := range tests {
+		if name := exportedName(input); name != expected {
+			t.Errorf("exportedName(%q) = %q, expected %q", input, name, expected)
+		}
+	}
+
+	taken := newIdentifiers("Client")
+	if first, second, third := taken.claim("Client"), taken.claim("Client"), taken.claim("Client2"); first != "Client2" || second != "Client3" || third != "Client22" {
+		t.Errorf("Expected numbered names, got %s, %s and %s", first, second, third)
+	}
+}
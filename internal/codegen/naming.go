@@ -0,0 +1,132 @@
+// internal/codegen/naming.go
+package codegen
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// exportedName converts snake_case, kebab-case or dotted identifiers into an exported Go/TS type name
+func exportedName(parts ...string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		upperNext := true
+		for _, r := range part {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				upperNext = true
+
+				continue
+			}
+			if upperNext {
+				b.WriteRune(unicode.ToUpper(r))
+				upperNext = false
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	name := b.String()
+	if name == "" {
+
+		return "X"
+	}
+	// Digits and letters without an upper case can't start an exported name
+	if first := []rune(name)[0]; !unicode.IsUpper(first) {
+		name = "X" + name
+	}
+
+	return name
+}
+
+// identifiers records the names taken in one scope of the generated code, so different
+// inputs that convert to the same name are told apart
+type identifiers map[string]bool
+
+func newIdentifiers(reserved ...string) identifiers {
+	taken := make(identifiers, len(reserved))
+	for _, name := range reserved {
+		taken[name] = true
+	}
+
+	return taken
+}
+
+// claim returns name, numbered from 2 when it is already taken, and takes it
+func (taken identifiers) claim(name string) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	taken[unique] = true
+
+	return unique
+}
+
+// claimToolName returns the name of a tool's method, numbered until neither it nor the
+// argument type named after it is taken, and takes both
+func claimToolName(name string, methods, types identifiers) string {
+	unique := name
+	for i := 2; methods[unique] || types[unique+"Args"]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	methods[unique], types[unique+"Args"] = true, true
+
+	return unique
+}
+
+// lowerFirst returns name with its first rune lower-cased, for TS method names
+func lowerFirst(name string) string {
+	if name == "" {
+
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+
+	return string(runes)
+}
+
+// sortedKeys returns the keys of a schema properties map in stable order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// requiredSet extracts the "required" list of a JSON schema object
+func requiredSet(schema map[string]interface{}) map[string]bool {
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	return required
+}
+
+// schemaProperties returns the "properties" map of a JSON schema object
+func schemaProperties(schema map[string]interface{}) map[string]interface{} {
+	props, _ := schema["properties"].(map[string]interface{})
+	if props == nil {
+
+		return map[string]interface{}{}
+	}
+
+	return props
+}
+
+// commentText flattens a description into a single comment line
+func commentText(description string) string {
+
+	return strings.Join(strings.Fields(description), " ")
+}
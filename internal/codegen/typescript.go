@@ -0,0 +1,141 @@
+// internal/codegen/typescript.go
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Names the TypeScript client preamble declares, which tools must not be generated under.
+// Methods are recorded as the exported names they are lower-cased from.
+var (
+	tsClientTypes   = []string{"ContentItem", "ToolResult", "MCPError", "MCPComposeClientBase", "MCPComposeClient"}
+	tsClientMethods = []string{"CallTool", "Constructor", "NextId", "BaseURL", "ApiKey"}
+)
+
+// GenerateTypeScript renders a TypeScript client module with one interface and method per tool
+func GenerateTypeScript(servers []ServerTools) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("// Code generated by mcp-compose generate-client. DO NOT EDIT.\n\n")
+	b.WriteString(tsClientPreamble)
+
+	methods := new(bytes.Buffer)
+	methodNames, types := newIdentifiers(tsClientMethods...), newIdentifiers(tsClientTypes...)
+	for _, srv := range servers {
+		for _, tool := range srv.Tools {
+			typeName := claimToolName(exportedName(srv.Server, tool.Name), methodNames, types)
+			argsType := typeName + "Args"
+
+			fmt.Fprintf(&b, "/** Arguments for %s/%s */\n", srv.Server, tool.Name)
+			fmt.Fprintf(&b, "export interface %s {\n", argsType)
+			props := schemaProperties(tool.InputSchema)
+			required := requiredSet(tool.InputSchema)
+			for _, propName := range sortedKeys(props) {
+				propSchema, _ := props[propName].(map[string]interface{})
+				if desc, _ := propSchema["description"].(string); desc != "" {
+					fmt.Fprintf(&b, "  /** %s */\n", commentText(desc))
+				}
+				optional := ""
+				if !required[propName] {
+					optional = "?"
+				}
+				fmt.Fprintf(&b, "  %s%s: %s;\n", strconv.Quote(propName), optional, tsTypeFor(propSchema))
+			}
+			b.WriteString("}\n\n")
+
+			if tool.Description != "" {
+				fmt.Fprintf(methods, "  /** %s */\n", commentText(tool.Description))
+			}
+			fmt.Fprintf(methods, "  %s(args: %s): Promise<ToolResult> {\n", lowerFirst(typeName), argsType)
+			fmt.Fprintf(methods, "    return this.callTool(%s, %s, args);\n  }\n\n",
+				strconv.Quote(srv.Server), strconv.Quote(tool.Name))
+		}
+	}
+
+	b.WriteString("export class MCPComposeClient extends MCPComposeClientBase {\n")
+	b.Write(bytes.TrimRight(methods.Bytes(), "\n"))
+	b.WriteString("\n}\n")
+
+	return b.Bytes()
+}
+
+func tsTypeFor(schema map[string]interface{}) string {
+	switch schemaType(schema) {
+	case "string":
+
+		return "string"
+	case "integer", "number":
+
+		return "number"
+	case "boolean":
+
+		return "boolean"
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+
+			return "unknown[]"
+		}
+
+		return tsTypeFor(items) + "[]"
+	case "object":
+
+		return "Record<string, unknown>"
+	default:
+
+		return "unknown"
+	}
+}
+
+const tsClientPreamble = `export interface ContentItem {
+  type: string;
+  text?: string;
+  data?: string;
+  mimeType?: string;
+  resource?: unknown;
+}
+
+export interface ToolResult {
+  content: ContentItem[];
+  isError?: boolean;
+}
+
+export class MCPError extends Error {
+  constructor(public code: number, message: string, public data?: unknown) {
+    super(message);
+  }
+}
+
+export class MCPComposeClientBase {
+  private nextId = 0;
+
+  constructor(private baseURL: string, private apiKey?: string) {
+    this.baseURL = baseURL.replace(/\/+$/, "");
+  }
+
+  async callTool(server: string, tool: string, args: unknown): Promise<ToolResult> {
+    const headers: Record<string, string> = { "Content-Type": "application/json" };
+    if (this.apiKey) {
+      headers["Authorization"] = ` + "`Bearer ${this.apiKey}`" + `;
+    }
+    const response = await fetch(` + "`${this.baseURL}/${server}`" + `, {
+      method: "POST",
+      headers,
+      body: JSON.stringify({
+        jsonrpc: "2.0",
+        id: ++this.nextId,
+        method: "tools/call",
+        params: { name: tool, arguments: args },
+      }),
+    });
+    const envelope = await response.json();
+    if (envelope.error) {
+      throw new MCPError(envelope.error.code, envelope.error.message, envelope.error.data);
+    }
+    return envelope.result as ToolResult;
+  }
+}
+
+`
@@ -0,0 +1,212 @@
+// internal/compose/add.go
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/registry"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AddOptions controls how registry servers are added to the compose file
+type AddOptions struct {
+	Name        string // name for the server in the compose file; only with a single server
+	Directory   string // host directory for servers that mount a workspace
+	DryRun      bool
+	RegistryURL string
+	Offline     bool
+}
+
+// Add appends fully configured server blocks from the registry to the compose file,
+// creating the file when it does not exist
+func Add(configFile string, names []string, opts AddOptions) error {
+	if opts.Name != "" && len(names) != 1 {
+
+		return fmt.Errorf("--name can only be used when adding a single server")
+	}
+
+	reg := registry.Load(registry.LoadOptions{URL: opts.RegistryURL, Offline: opts.Offline})
+	if reg.RemoteErr != nil {
+		fmt.Fprintf(os.Stderr, "Note: using the built-in registry (%v)\n", reg.RemoteErr)
+	}
+
+	entries := make([]registry.Entry, 0, len(names))
+	for _, name := range names {
+		entry, exists := reg.Get(name)
+		if !exists {
+			suggestion := ""
+			prefix := strings.ToLower(name)
+			if len(prefix) > 3 {
+				prefix = prefix[:3]
+			}
+			for _, candidate := range reg.Entries() {
+				if strings.HasPrefix(candidate.Name, prefix) {
+					suggestion = fmt.Sprintf("; did you mean '%s'?", candidate.Name)
+
+					break
+				}
+			}
+
+			return fmt.Errorf("server '%s' is not in the registry%s (try 'mcp-compose search')", name, suggestion)
+		}
+		entries = append(entries, entry)
+	}
+
+	document, err := loadComposeDocument(configFile)
+	if err != nil {
+
+		return err
+	}
+	root := document.Content[0]
+	serversNode := mappingValue(root, "servers")
+
+	workspaceDir := ""
+	added := 0
+	var secrets []string
+	for _, entry := range entries {
+		serverName := entry.Name
+		if opts.Name != "" {
+			serverName = sanitizeServerName(opts.Name)
+		}
+		if mappingValue(serversNode, serverName) != nil {
+			fmt.Printf("[✖] Server %-30s Skipped: already defined in %s\n", serverName, configFile)
+
+			continue
+		}
+
+		if entry.Workspace != "" && workspaceDir == "" {
+			directory := opts.Directory
+			if directory == "" {
+				directory, _ = os.Getwd()
+			}
+			if workspaceDir, err = filepath.Abs(expandHome(directory)); err != nil {
+
+				return fmt.Errorf("invalid directory '%s': %w", directory, err)
+			}
+		}
+
+		var serverNode yaml.Node
+		if err := serverNode.Encode(entry.ServerConfig(workspaceDir)); err != nil {
+
+			return fmt.Errorf("failed to encode server '%s': %w", serverName, err)
+		}
+		serversNode.Content = append(serversNode.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: serverName}, &serverNode)
+
+		for _, volume := range entry.NamedVolumes() {
+			declareNamedVolume(root, volume)
+		}
+		added++
+
+		source := entry.Image
+		if source == "" {
+			source = entry.Command
+		}
+		fmt.Printf("[✔] Server %-30s Added (%s)\n", serverName, source)
+		if entry.Workspace != "" {
+			fmt.Printf("      - mounts %s at %s\n", workspaceDir, entry.Workspace)
+		}
+		secrets = append(secrets, entry.Secrets...)
+	}
+
+	if added == 0 {
+		fmt.Println("Nothing to add.")
+
+		return nil
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+
+		return fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	_ = encoder.Close()
+
+	if opts.DryRun {
+		fmt.Printf("\n# %s (dry run, not written)\n%s", configFile, out.String())
+
+		return nil
+	}
+	if _, _, err := config.WriteConfigFile(configFile, out.Bytes(), config.ConfigVersion{
+		Source:  config.VersionSourceCLI,
+		Message: "add " + strings.Join(names, ", "),
+	}); err != nil {
+
+		return fmt.Errorf("failed to write '%s': %w", configFile, err)
+	}
+
+	var missing []string
+	for _, key := range secrets {
+		if os.Getenv(key) == "" && dotEnvValue(envFilePath(configFile), key) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Printf("Set %s in %s before starting.\n", strings.Join(missing, ", "), envFilePath(configFile))
+	}
+	fmt.Printf("✅ Added %d server(s) to %s. Next: mcp-compose up\n", added, configFile)
+
+	return nil
+}
+
+// declareNamedVolume adds a local volume to the top-level volumes unless it is already there
+func declareNamedVolume(root *yaml.Node, name string) {
+	volumes := mappingValue(root, "volumes")
+	if volumes == nil || volumes.Kind != yaml.MappingNode {
+		if volumes != nil {
+			*volumes = yaml.Node{Kind: yaml.MappingNode}
+		} else {
+			volumes = &yaml.Node{Kind: yaml.MappingNode}
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "volumes"}, volumes)
+		}
+	}
+	if mappingValue(volumes, name) != nil {
+
+		return
+	}
+	volumes.Content = append(volumes.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+		&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "driver"},
+			{Kind: yaml.ScalarNode, Value: "local"},
+		}})
+}
+
+// Search lists registry servers matching the query, or all of them
+func Search(query string, registryURL string, offline bool) error {
+	reg := registry.Load(registry.LoadOptions{URL: registryURL, Offline: offline})
+	if reg.RemoteErr != nil {
+		fmt.Fprintf(os.Stderr, "Note: using the built-in registry (%v)\n", reg.RemoteErr)
+	}
+
+	matches := reg.Search(query)
+	if len(matches) == 0 {
+		fmt.Printf("No servers in the registry match '%s'.\n", query)
+
+		return nil
+	}
+
+	fmt.Printf("%-22s %-28s %s\n", "NAME", "IMAGE", "DESCRIPTION")
+	for _, entry := range matches {
+		source := entry.Image
+		if source == "" {
+			source = entry.Command
+		}
+		description := entry.Description
+		if len(entry.Secrets) > 0 {
+			description += fmt.Sprintf(" (needs %s)", strings.Join(entry.Secrets, ", "))
+		}
+		fmt.Printf("%-22s %-28s %s\n", entry.Name, source, description)
+	}
+	fmt.Printf("\nAdd one with: mcp-compose add <name>\n")
+
+	return nil
+}
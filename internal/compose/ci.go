@@ -0,0 +1,447 @@
+// internal/compose/ci.go
+package compose
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+	"github.com/phildougherty/mcp-compose/internal/server"
+)
+
+// CIOptions controls a CI run
+type CIOptions struct {
+	Timeout      time.Duration
+	Commands     []string
+	ArtifactsDir string
+	KeepUp       bool
+}
+
+// CICommandResult records the outcome of a user-supplied command
+type CICommandResult struct {
+	Command  string `json:"command"`
+	ExitCode int    `json:"exit_code"`
+	Passed   bool   `json:"passed"`
+	Duration string `json:"duration"`
+	Output   string `json:"output"`
+}
+
+// CIReport is the summary written into the artifact bundle
+type CIReport struct {
+	Project   string            `json:"project"`
+	Namespace string            `json:"namespace"` // the project namespace the stack ran in
+	StartedAt time.Time         `json:"started_at"`
+	Duration  string            `json:"duration"`
+	Passed    bool              `json:"passed"`
+	Stage     string            `json:"stage"`
+	Error     string            `json:"error,omitempty"`
+	Scenarios []ScenarioResult  `json:"scenarios"`
+	Commands  []CICommandResult `json:"commands"`
+}
+
+// CI brings the stack up in a project namespace of its own, waits for it to become
+// healthy, runs the configured test scenarios and any extra commands, then tears
+// everything down. A bundle of logs and results is written when anything fails. The
+// returned error is nil only when every check passed.
+func CI(configFile string, opts CIOptions) error {
+	// A namespace of its own keeps the run clear of a stack already up and of parallel jobs
+	previous := config.ProjectNamespace()
+	namespace := ciNamespace(previous, time.Now())
+	if err := config.SetProjectNamespace(namespace); err != nil {
+
+		return err
+	}
+	defer func() {
+		_ = config.SetProjectNamespace(previous)
+	}()
+
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+		cRuntime = container.NewNullRuntime()
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = constants.CIDefaultWaitTimeout
+	}
+
+	report := &CIReport{
+		Project:   config.GetProjectName(configFile),
+		Namespace: namespace,
+		StartedAt: time.Now(),
+		Scenarios: make([]ScenarioResult, 0),
+		Commands:  make([]CICommandResult, 0),
+	}
+
+	serverNames := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		serverNames = append(serverNames, name)
+	}
+
+	fmt.Printf("=== CI: bringing up project '%s' in namespace '%s' ===\n", report.Project, namespace)
+	runErr := runCIStages(configFile, cfg, serverNames, cRuntime, opts, report)
+	report.Duration = ShortDuration(time.Since(report.StartedAt))
+	report.Passed = runErr == nil
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+
+	printCISummary(report)
+
+	if !report.Passed {
+		bundle, bundleErr := writeCIBundle(report, cfg, serverNames, cRuntime, opts.ArtifactsDir)
+		if bundleErr != nil {
+			fmt.Printf("Warning: failed to write CI artifact bundle: %v\n", bundleErr)
+		} else {
+			fmt.Printf("CI artifacts written to %s\n", bundle)
+		}
+	}
+
+	if opts.KeepUp {
+		fmt.Printf("Leaving stack running (--keep); stop it with 'mcp-compose --project-name %s down'\n", namespace)
+	} else {
+		fmt.Println("=== CI: tearing down ===")
+		if err := Down(configFile, nil); err != nil {
+			fmt.Printf("Warning: teardown failed: %v\n", err)
+		}
+		// Down only targets containers; stop the process servers this run started
+		for _, name := range serverNames {
+			if isContainerServer(cfg.Servers[name]) {
+
+				continue
+			}
+			if proc, err := runtime.FindProcess(config.ContainerName(name)); err == nil {
+				if err := proc.Stop(); err != nil {
+					fmt.Printf("Warning: failed to stop process server '%s': %v\n", name, err)
+				}
+			}
+		}
+	}
+
+	if runErr != nil {
+
+		return fmt.Errorf("ci failed during %s: %w", report.Stage, runErr)
+	}
+
+	return nil
+}
+
+// ciNamespace names the project namespace of a run, unique to the process and start time
+// and under the namespace mcp-compose was given, if any
+func ciNamespace(parent string, started time.Time) string {
+	namespace := fmt.Sprintf("ci-%s-%d", started.Format("20060102150405"), os.Getpid())
+	if parent != "" {
+		namespace = parent + "-" + namespace
+	}
+
+	return namespace
+}
+
+func runCIStages(configFile string, cfg *config.ComposeConfig, serverNames []string, cRuntime container.Runtime, opts CIOptions, report *CIReport) error {
+	report.Stage = "up"
+	if err := Up(configFile, nil); err != nil {
+
+		return err
+	}
+
+	report.Stage = "wait"
+	fmt.Printf("=== CI: waiting up to %s for servers to become healthy ===\n", opts.Timeout)
+	if err := WaitForServers(cfg, serverNames, cRuntime, opts.Timeout); err != nil {
+
+		return err
+	}
+
+	report.Stage = "proxy"
+	apiKey := ""
+	if cfg.ProxyAuth.Enabled {
+		apiKey = cfg.ProxyAuth.APIKey
+	}
+	proxyURL, stopProxy, err := startCIProxy(configFile, cfg, cRuntime, apiKey)
+	if err != nil {
+
+		return err
+	}
+	defer stopProxy()
+
+	report.Stage = "scenarios"
+	scenarios := cfg.Development.Testing.Scenarios
+	if len(scenarios) > 0 {
+		fmt.Printf("=== CI: running %d test scenario(s) ===\n", len(scenarios))
+		report.Scenarios = RunScenarios(proxyURL, apiKey, scenarios)
+	}
+
+	report.Stage = "commands"
+	for _, command := range opts.Commands {
+		fmt.Printf("=== CI: running '%s' ===\n", command)
+		report.Commands = append(report.Commands, runCICommand(command, proxyURL, apiKey))
+	}
+
+	failed := 0
+	for _, result := range report.Scenarios {
+		if !result.Passed {
+			failed++
+		}
+	}
+	for _, result := range report.Commands {
+		if !result.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+
+	return nil
+}
+
+// startCIProxy runs the native proxy in-process on an ephemeral loopback port
+func startCIProxy(configFile string, cfg *config.ComposeConfig, cRuntime container.Runtime, apiKey string) (string, func(), error) {
+	mgr, err := server.NewManager(cfg, cRuntime)
+	if err != nil {
+
+		return "", nil, fmt.Errorf("failed to create server manager: %w", err)
+	}
+
+	handler := server.NewProxyHandler(mgr, configFile, apiKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = handler.Shutdown()
+		_ = mgr.Shutdown()
+
+		return "", nil, fmt.Errorf("failed to listen for CI proxy: %w", err)
+	}
+
+	httpServer := &http.Server{
+		Handler:      handler,
+		ReadTimeout:  constants.DefaultReadTimeout,
+		WriteTimeout: constants.CIScenarioRequestTimeout,
+	}
+	go func() {
+		_ = httpServer.Serve(listener)
+	}()
+
+	proxyURL := fmt.Sprintf("http://%s", listener.Addr().String())
+	fmt.Printf("CI proxy listening on %s\n", proxyURL)
+
+	stop := func() {
+		_ = httpServer.Close()
+		_ = handler.Shutdown()
+		_ = mgr.Shutdown()
+	}
+
+	return proxyURL, stop, nil
+}
+
+func runCICommand(command, proxyURL, apiKey string) CICommandResult {
+	var output bytes.Buffer
+	cmd, err := runtime.ShellCommand(context.Background(), "", command)
+	if err != nil {
+
+		return CICommandResult{Command: command, ExitCode: -1, Output: err.Error()}
+	}
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("MCP_PROXY_URL=%s", proxyURL),
+		fmt.Sprintf("MCP_API_KEY=%s", apiKey),
+	)
+
+	start := time.Now()
+	err = cmd.Run()
+	result := CICommandResult{
+		Command:  command,
+		Duration: ShortDuration(time.Since(start)),
+		Output:   output.String(),
+		Passed:   err == nil,
+	}
+	if err != nil {
+		result.ExitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+		}
+	}
+
+	return result
+}
+
+func printCISummary(report *CIReport) {
+	fmt.Println("\n=== CI summary ===")
+	for _, result := range report.Scenarios {
+		marker := "[✔]"
+		if !result.Passed {
+			marker = "[✖]"
+		}
+		fmt.Printf("%s %s: %s %s (%s)", marker, result.Scenario, result.Kind, result.Target, result.Duration)
+		if result.Error != "" {
+			fmt.Printf(" - %s", result.Error)
+		}
+		fmt.Println()
+	}
+	for _, result := range report.Commands {
+		marker := "[✔]"
+		if !result.Passed {
+			marker = "[✖]"
+		}
+		fmt.Printf("%s command '%s' exited %d (%s)\n", marker, result.Command, result.ExitCode, result.Duration)
+	}
+
+	if report.Passed {
+		fmt.Printf("✅ CI passed in %s\n", report.Duration)
+	} else {
+		fmt.Printf("CI failed during %s after %s: %s\n", report.Stage, report.Duration, report.Error)
+	}
+}
+
+// writeCIBundle gathers the report, server logs and container details into a tar.gz
+func writeCIBundle(report *CIReport, cfg *config.ComposeConfig, serverNames []string, cRuntime container.Runtime, artifactsDir string) (string, error) {
+	if artifactsDir == "" {
+		artifactsDir = "."
+	}
+
+	bundleName := fmt.Sprintf("mcp-compose-ci-%s", report.StartedAt.Format("20060102-150405"))
+	stagingDir := filepath.Join(artifactsDir, bundleName)
+	if err := os.MkdirAll(filepath.Join(stagingDir, "logs"), constants.DefaultDirMode); err != nil {
+
+		return "", fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	reportData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+
+		return "", fmt.Errorf("failed to marshal CI report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(stagingDir, "report.json"), reportData, constants.DefaultFileMode); err != nil {
+
+		return "", fmt.Errorf("failed to write CI report: %w", err)
+	}
+
+	for _, name := range serverNames {
+		identifier := config.ContainerName(name)
+		var lines []string
+		if isContainerServer(cfg.Servers[name]) {
+			if logLines, err := cRuntime.GetContainerLogs(identifier, constants.CIArtifactLogLines); err == nil {
+				lines = logLines
+			}
+			if info, err := cRuntime.GetContainerInfo(identifier); err == nil {
+				if infoData, err := json.MarshalIndent(info, "", "  "); err == nil {
+					_ = os.WriteFile(filepath.Join(stagingDir, name+".inspect.json"), infoData, constants.DefaultFileMode)
+				}
+			}
+		} else if proc, err := runtime.FindProcess(identifier); err == nil {
+			if logLines, err := proc.TailLogs(constants.CIArtifactLogLines); err == nil {
+				lines = logLines
+			}
+		}
+
+		logPath := filepath.Join(stagingDir, "logs", name+".log")
+		if err := os.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), constants.DefaultFileMode); err != nil {
+
+			return "", fmt.Errorf("failed to write logs for server '%s': %w", name, err)
+		}
+	}
+
+	bundlePath := stagingDir + ".tar.gz"
+	if err := writeTarGz(stagingDir, bundlePath); err != nil {
+
+		return "", err
+	}
+
+	if err := os.RemoveAll(stagingDir); err != nil {
+
+		return "", fmt.Errorf("failed to clean up artifact staging directory: %w", err)
+	}
+
+	return bundlePath, nil
+}
+
+func writeTarGz(srcDir, destPath string) error {
+	out, err := os.Create(destPath)
+	if err != nil {
+
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer func() {
+		_ = out.Close()
+	}()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	baseDir := filepath.Dir(srcDir)
+
+	walkErr := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+
+			return err
+		}
+
+		relPath, err := filepath.Rel(baseDir, path)
+		if err != nil {
+
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if err := tw.WriteHeader(header); err != nil {
+
+			return err
+		}
+		if info.IsDir() {
+
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+
+			return err
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		_, err = io.Copy(tw, file)
+
+		return err
+	})
+	if walkErr != nil {
+
+		return fmt.Errorf("failed to archive artifacts: %w", walkErr)
+	}
+
+	if err := tw.Close(); err != nil {
+
+		return fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+
+		return fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+
+	return nil
+}
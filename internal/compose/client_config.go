@@ -0,0 +1,242 @@
+// internal/compose/client_config.go
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// ClientConfigFormats are the client configuration layouts client-config can emit
+var ClientConfigFormats = []string{"claude", "cursor", "vscode", "generic"}
+
+// ClientConfigOptions controls client configuration generation
+type ClientConfigOptions struct {
+	Format      string   // claude, cursor, vscode or generic
+	ProxyURL    string   // proxy address as the client sees it
+	APIKey      string   // defaults to proxy_auth.api_key
+	OAuthClient string   // oauth_clients entry whose credentials are used instead of the API key
+	Servers     []string // servers to include (default: all)
+	Aggregator  bool     // a single entry for the aggregator endpoint instead of one per server
+	Output      string   // file to write; stdout when empty
+}
+
+// clientEndpoint is one MCP endpoint of the proxy a client connects to
+type clientEndpoint struct {
+	name string
+	url  string
+}
+
+// clientCredentials is how a client authenticates to the proxy
+type clientCredentials struct {
+	apiKey       string
+	clientID     string
+	clientSecret string
+	tokenURL     string
+	scopes       []string
+}
+
+func (c clientCredentials) oauth() bool {
+
+	return c.clientID != ""
+}
+
+// ClientConfig writes configuration for an MCP client that reaches the project's servers
+// through the proxy, with credentials filled in
+func ClientConfig(configFile string, opts ClientConfigOptions) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	if !containsString(ClientConfigFormats, opts.Format) {
+
+		return fmt.Errorf("unknown format '%s' (supported: %s)", opts.Format, strings.Join(ClientConfigFormats, ", "))
+	}
+
+	proxyURL := strings.TrimRight(opts.ProxyURL, "/")
+	if proxyURL == "" {
+		proxyURL = fmt.Sprintf("http://localhost:%d", constants.DefaultProxyPort)
+	}
+
+	var endpoints []clientEndpoint
+	if opts.Aggregator {
+		if !cfg.Aggregator.Enabled {
+
+			return fmt.Errorf("the aggregator endpoint is not enabled; set aggregator.enabled in %s", configFile)
+		}
+		endpoints = []clientEndpoint{{name: "mcp-compose", url: proxyURL + cfg.Aggregator.EndpointPath()}}
+	} else {
+		names := opts.Servers
+		if len(names) == 0 {
+			for name := range cfg.Servers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		for _, name := range names {
+			if _, exists := cfg.Servers[name]; !exists {
+
+				return fmt.Errorf("server '%s' not found in config", name)
+			}
+			endpoints = append(endpoints, clientEndpoint{name: name, url: proxyURL + "/" + name})
+		}
+	}
+	if len(endpoints) == 0 {
+
+		return fmt.Errorf("no servers defined in %s", configFile)
+	}
+
+	creds, err := clientConfigCredentials(cfg, opts, proxyURL)
+	if err != nil {
+
+		return err
+	}
+
+	data, err := renderClientConfig(opts.Format, endpoints, creds)
+	if err != nil {
+
+		return err
+	}
+
+	if opts.Output == "" {
+		_, _ = os.Stdout.Write(data)
+	} else {
+		if err := os.WriteFile(opts.Output, data, constants.SecretFileMode); err != nil {
+
+			return fmt.Errorf("failed to write '%s': %w", opts.Output, err)
+		}
+		fmt.Fprintf(os.Stderr, "[✔] Wrote %s configuration for %d endpoint(s) to %s\n", opts.Format, len(endpoints), opts.Output)
+	}
+	if creds.oauth() && (opts.Format == "cursor" || opts.Format == "vscode") {
+		fmt.Fprintf(os.Stderr, "Note: %s discovers the proxy's OAuth server itself; sign in with client '%s' when it asks.\n", opts.Format, creds.clientID)
+	}
+
+	return nil
+}
+
+func clientConfigCredentials(cfg *config.ComposeConfig, opts ClientConfigOptions, proxyURL string) (clientCredentials, error) {
+	if opts.OAuthClient == "" {
+		apiKey := opts.APIKey
+		if apiKey == "" && cfg.ProxyAuth.Enabled {
+			apiKey = cfg.ProxyAuth.APIKey
+		}
+
+		return clientCredentials{apiKey: apiKey}, nil
+	}
+
+	if cfg.OAuth == nil || !cfg.OAuth.Enabled {
+
+		return clientCredentials{}, fmt.Errorf("OAuth is not enabled in the config")
+	}
+	client, exists := cfg.OAuthClients[opts.OAuthClient]
+	if !exists || client == nil {
+
+		return clientCredentials{}, fmt.Errorf("oauth client '%s' not found in oauth_clients", opts.OAuthClient)
+	}
+
+	// Token URLs are relative to the issuer the proxy advertises in its OAuth metadata
+	issuer := strings.TrimRight(cfg.OAuth.Issuer, "/")
+	if issuer == "" {
+		issuer = proxyURL
+	}
+	tokenPath := cfg.OAuth.Endpoints.Token
+	if tokenPath == "" {
+		tokenPath = "/oauth/token"
+	}
+	creds := clientCredentials{
+		clientID: client.ClientID,
+		tokenURL: issuer + tokenPath,
+		scopes:   client.Scopes,
+	}
+	if client.ClientSecret != nil {
+		creds.clientSecret = *client.ClientSecret
+	}
+
+	return creds, nil
+}
+
+// renderClientConfig lays endpoints out in the configuration file format of a client
+func renderClientConfig(format string, endpoints []clientEndpoint, creds clientCredentials) ([]byte, error) {
+	headers := map[string]string{}
+	if creds.apiKey != "" {
+		headers["Authorization"] = "Bearer " + creds.apiKey
+	}
+
+	var document interface{}
+	switch format {
+	case "claude":
+		// Claude Desktop only launches local commands, so mcp-remote bridges to the proxy
+		servers := make(map[string]interface{}, len(endpoints))
+		for _, endpoint := range endpoints {
+			args := []string{"-y", "mcp-remote", endpoint.url}
+			entry := map[string]interface{}{"command": "npx"}
+			if creds.apiKey != "" {
+				args = append(args, "--header", "Authorization:${AUTH_HEADER}")
+				entry["env"] = map[string]string{"AUTH_HEADER": headers["Authorization"]}
+			}
+			if creds.oauth() {
+				info, _ := json.Marshal(map[string]string{"client_id": creds.clientID, "client_secret": creds.clientSecret})
+				args = append(args, "--static-oauth-client-info", string(info))
+			}
+			entry["args"] = args
+			servers[endpoint.name] = entry
+		}
+		document = map[string]interface{}{"mcpServers": servers}
+	case "cursor":
+		servers := make(map[string]interface{}, len(endpoints))
+		for _, endpoint := range endpoints {
+			entry := map[string]interface{}{"url": endpoint.url}
+			if len(headers) > 0 {
+				entry["headers"] = headers
+			}
+			servers[endpoint.name] = entry
+		}
+		document = map[string]interface{}{"mcpServers": servers}
+	case "vscode":
+		servers := make(map[string]interface{}, len(endpoints))
+		for _, endpoint := range endpoints {
+			entry := map[string]interface{}{"type": "http", "url": endpoint.url}
+			if len(headers) > 0 {
+				entry["headers"] = headers
+			}
+			servers[endpoint.name] = entry
+		}
+		document = map[string]interface{}{"servers": servers}
+	case "generic":
+		servers := make([]map[string]interface{}, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			entry := map[string]interface{}{"name": endpoint.name, "url": endpoint.url, "transport": "streamable-http"}
+			if len(headers) > 0 {
+				entry["headers"] = headers
+			}
+			servers = append(servers, entry)
+		}
+		generic := map[string]interface{}{"servers": servers}
+		if creds.oauth() {
+			generic["oauth"] = map[string]interface{}{
+				"client_id":     creds.clientID,
+				"client_secret": creds.clientSecret,
+				"token_url":     creds.tokenURL,
+				"scopes":        creds.scopes,
+			}
+		}
+		document = generic
+	default:
+
+		return nil, fmt.Errorf("unknown format '%s' (supported: %s)", format, strings.Join(ClientConfigFormats, ", "))
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to encode %s configuration: %w", format, err)
+	}
+
+	return append(data, '\n'), nil
+}
@@ -2,8 +2,11 @@
 package compose
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +19,7 @@ import (
 	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/imagescan"
 	"github.com/phildougherty/mcp-compose/internal/logging"
 	"github.com/phildougherty/mcp-compose/internal/protocol"
 	"github.com/phildougherty/mcp-compose/internal/runtime"
@@ -166,10 +170,10 @@ func (c *Composer) Shutdown() error {
 }
 
 func Up(configFile string, serverNames []string) error {
-	cfg, err := config.LoadConfig(configFile)
+	cfg, err := loadLockedConfig(configFile)
 	if err != nil {
 
-		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+		return err
 	}
 
 	cRuntime, err := container.DetectRuntime()
@@ -196,13 +200,39 @@ func Up(configFile string, serverNames []string) error {
 			networkExists, _ := cRuntime.NetworkExists(networkName)
 			if !networkExists {
 				fmt.Printf("Network '%s' does not exist, attempting to create it...\n", networkName)
-				if err := cRuntime.CreateNetwork(networkName); err != nil {
+				create := cRuntime.CreateNetwork
+				if cfg.InternalNetwork(networkName) {
+					create = cRuntime.CreateInternalNetwork
+				}
+				if err := create(networkName); err != nil {
 					fmt.Fprintf(os.Stderr, "Warning: Failed to create network '%s': %v. Some inter-server communication might fail.\n", networkName, err)
 				} else {
 					fmt.Printf("✅ Created network '%s'\n", networkName)
 				}
 			}
 		}
+
+		// A proxy container that is already running has to join the networks of the servers
+		// started after it that have one of their own
+		for _, serverName := range serversToStart {
+			networkName := config.ServerNetwork(serverName)
+			if _, required := requiredNetworks[networkName]; required && cfg.HasOwnNetwork(serverName) {
+				connected, err := container.ConnectIfRunning(cRuntime, config.ContainerName("http-proxy"), networkName)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v. The proxy will not reach server '%s'.\n", err, serverName)
+				} else if connected {
+					fmt.Printf("Connected proxy to network '%s'\n", networkName)
+				}
+			}
+		}
+	}
+
+	// Create declared volumes with their drivers before docker creates them as plain local ones
+	if cRuntime.GetRuntimeName() != "none" {
+		if err := ensureVolumes(configFile, cfg, cRuntime, serversToStart); err != nil {
+
+			return err
+		}
 	}
 
 	// Channel to collect results
@@ -214,6 +244,14 @@ func Up(configFile string, serverNames []string) error {
 
 	results := make(chan startResult, len(serversToStart))
 	var wg sync.WaitGroup
+	externalDeps := newExternalDependencyWaiter(cfg)
+	initJobs := server.InitRunner{
+		Config:   cfg,
+		Runtime:  cRuntime,
+		StateDir: server.InitStateDir(configFile),
+		Dir:      filepath.Dir(configFile),
+		Out:      os.Stdout,
+	}
 
 	// Start all servers in parallel
 	for _, serverName := range serversToStart {
@@ -250,11 +288,36 @@ func Up(configFile string, serverNames []string) error {
 				}
 			}
 
+			serverCfg.Env = cfg.ServerEnv(name)
+			if isContainerServer(serverCfg) {
+				// Another project's server of the same name would otherwise be replaced
+				if owner := containerOwner(cRuntime, config.ContainerName(name), configFile); owner != "" {
+					results <- startResult{name, fmt.Errorf("container '%s' was started by %s; stop it there or rename the server", config.ContainerName(name), owner), time.Since(startTime)}
+
+					return
+				}
+				serverCfg.Labels = projectLabels(configFile, name, serverCfg)
+			}
+
+			if err := externalDeps.WaitFor(serverCfg.ExternalDependsOn); err != nil {
+				results <- startResult{name, err, time.Since(startTime)}
+
+				return
+			}
+			if err := initJobs.Run(name); err != nil {
+				results <- startResult{name, err, time.Since(startTime)}
+
+				return
+			}
+
 			var err error
 			if isContainerServer(serverCfg) {
-				err = startServerContainer(name, serverCfg, cRuntime)
+				err = startServerContainer(cfg, configFile, name, serverCfg, cRuntime)
 			} else {
-				err = startServerProcess(name, serverCfg)
+				err = startServerProcess(name, serverCfg, cfg)
+			}
+			if err == nil {
+				recordServer(configFile, name, serverCfg, cRuntime)
 			}
 			duration := time.Since(startTime)
 			results <- startResult{name, err, duration}
@@ -336,7 +399,7 @@ func collectRequiredNetworks(cfg *config.ComposeConfig, serverNames []string) ma
 			continue
 		}
 
-		networks := determineServerNetworks(serverCfg)
+		networks := cfg.ServerNetworks(serverName)
 
 		// Track which servers use which networks
 		for _, network := range networks {
@@ -384,6 +447,9 @@ func generateNetworkDescription(networkToServers map[string][]string) string {
 // showNetworkTopology displays which servers are on which networks
 func showNetworkTopology(cfg *config.ComposeConfig, serversStarted []string) {
 	fmt.Printf("\n=== NETWORK TOPOLOGY ===\n")
+	if cfg.Isolation == config.IsolationStrict {
+		fmt.Printf("Isolation: strict (each server has its own network, shared only with the proxy and its peers)\n")
+	}
 
 	networkToServers := make(map[string][]string)
 
@@ -397,7 +463,7 @@ func showNetworkTopology(cfg *config.ComposeConfig, serversStarted []string) {
 		if serverCfg.NetworkMode != "" {
 			networks = []string{fmt.Sprintf("mode:%s", serverCfg.NetworkMode)}
 		} else {
-			networks = determineServerNetworks(serverCfg)
+			networks = cfg.ServerNetworks(serverName)
 		}
 
 		for _, network := range networks {
@@ -419,52 +485,6 @@ func showNetworkTopology(cfg *config.ComposeConfig, serversStarted []string) {
 	}
 }
 
-// determineServerNetworks determines which networks a server should join
-func determineServerNetworks(serverCfg config.ServerConfig) []string {
-	// If NetworkMode is set, don't use Networks (they're mutually exclusive)
-	if serverCfg.NetworkMode != "" {
-
-		return nil
-	}
-
-	// Start with configured networks
-	networks := make([]string, 0)
-	if len(serverCfg.Networks) > 0 {
-		networks = append(networks, serverCfg.Networks...)
-	}
-
-	// Ensure default network is included unless explicitly using custom networks only
-	hasDefaultNetwork := false
-	for _, net := range networks {
-		if net == "mcp-net" {
-			hasDefaultNetwork = true
-
-			break
-		}
-	}
-
-	if !hasDefaultNetwork && len(networks) == 0 {
-		// No networks specified, use default
-		networks = append(networks, "mcp-net")
-	} else if !hasDefaultNetwork && len(serverCfg.Networks) > 0 {
-		// Custom networks specified, but ensure connectivity with other MCP services
-		// Add mcp-net for proxy connectivity unless user explicitly excluded it
-		networks = append(networks, "mcp-net")
-	}
-
-	// Remove duplicates
-	uniqueNetworks := make([]string, 0, len(networks))
-	seen := make(map[string]bool)
-	for _, network := range networks {
-		if !seen[network] {
-			uniqueNetworks = append(uniqueNetworks, network)
-			seen[network] = true
-		}
-	}
-
-	return uniqueNetworks
-}
-
 // isContainerServer determines if a server should run as a container
 func isContainerServer(serverCfg config.ServerConfig) bool {
 	// If it has an image, it's definitely a container
@@ -510,7 +530,8 @@ func isContainerServer(serverCfg config.ServerConfig) bool {
 	// If it has resource limits (deploy section), it's a container
 	if serverCfg.Deploy.Resources.Limits.CPUs != "" ||
 		serverCfg.Deploy.Resources.Limits.Memory != "" ||
-		serverCfg.Deploy.Resources.Limits.PIDs > 0 {
+		serverCfg.Deploy.Resources.Limits.PIDs > 0 ||
+		serverCfg.GPURequest() != "" {
 
 		return true
 	}
@@ -521,8 +542,9 @@ func isContainerServer(serverCfg config.ServerConfig) bool {
 		return true
 	}
 
-	// If it has Docker/container specific environment or settings
-	if serverCfg.RestartPolicy != "" || len(serverCfg.SecurityOpt) > 0 {
+	// If it has Docker/container specific environment or settings.
+	// Restart policies apply to process-based servers too, so they don't count.
+	if len(serverCfg.SecurityOpt) > 0 {
 
 		return true
 	}
@@ -533,7 +555,7 @@ func isContainerServer(serverCfg config.ServerConfig) bool {
 }
 
 // startServerProcess handles process-based server startup
-func startServerProcess(serverName string, serverCfg config.ServerConfig) error {
+func startServerProcess(serverName string, serverCfg config.ServerConfig, cfg *config.ComposeConfig) error {
 	fmt.Printf("Starting process '%s' for server '%s'.\n", serverCfg.Command, serverName)
 
 	env := make(map[string]string)
@@ -546,9 +568,14 @@ func startServerProcess(serverName string, serverCfg config.ServerConfig) error
 	env["MCP_SERVER_NAME"] = serverName
 
 	proc, err := runtime.NewProcess(serverCfg.Command, serverCfg.Args, runtime.ProcessOptions{
-		Env:     env,
-		WorkDir: serverCfg.WorkDir,
-		Name:    fmt.Sprintf("mcp-compose-%s", serverName),
+		Env:           env,
+		WorkDir:       serverCfg.WorkDir,
+		Name:          config.ContainerName(serverName),
+		RestartPolicy: serverCfg.EffectiveRestartPolicy(),
+		LogRotation:   runtime.LogRotationFromConfig(cfg.Logging.Retention),
+		CrashLoop:     runtime.CrashLoopLimitsFromConfig(serverCfg.CrashLoop),
+		Notifications: cfg.Notifications,
+		Stdio:         serverCfg.UsesStdio(),
 	})
 	if err != nil {
 
@@ -581,6 +608,34 @@ func Down(configFile string, serverNames []string) error {
 
 		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
 	}
+	// With profiles active, only the servers up would have started for them are stopped
+	if profiles := config.ActiveProfiles(nil); len(serverNames) == 0 && len(profiles) > 0 {
+		for name, srvCfg := range cfg.Servers {
+			if srvCfg.EnabledFor(profiles) {
+				serverNames = append(serverNames, name)
+			}
+		}
+		if len(serverNames) == 0 {
+			fmt.Printf("No servers are enabled for profile(s) %s.\n", strings.Join(profiles, ", "))
+
+			return nil
+		}
+		sort.Strings(serverNames)
+	}
+	state, err := LoadProjectState(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		state = &ProjectState{Servers: map[string]ServerState{}}
+	}
+	// Servers up started that have since been renamed or removed from the config are stopped too
+	orphans := orphanedServers(state, cfg)
+	if len(serverNames) == 0 && len(orphans) > 0 {
+		fmt.Printf("Also stopping server(s) no longer in the config: %s\n", strings.Join(orphans, ", "))
+	}
+
+	// Process-based servers are stopped through their supervisors, with or without a container runtime
+	forgetServers(configFile, stopProcessServers(cfg, state, serverNames))
+
 	cRuntime, err := container.DetectRuntime()
 	if err != nil {
 
@@ -602,6 +657,11 @@ func Down(configFile string, serverNames []string) error {
 				serversToStop = append(serversToStop, name)
 			}
 		}
+		for _, name := range orphans {
+			if state.Servers[name].Kind == "container" {
+				serversToStop = append(serversToStop, name)
+			}
+		}
 	}
 
 	if len(serversToStop) == 0 {
@@ -612,28 +672,42 @@ func Down(configFile string, serverNames []string) error {
 
 	successCount := 0
 	var composeErrors []string
+	var stopped []string
 	for _, serverName := range serversToStop {
 		srvCfg, exists := cfg.Servers[serverName]
-		if !exists || (srvCfg.Image == "" && srvCfg.Runtime == "") {
+		if exists && !isContainerServer(srvCfg) {
+
+			continue
+		}
+		_, recorded := state.Servers[serverName]
+		if (!exists && !recorded) || (exists && srvCfg.Image == "" && srvCfg.Runtime == "") {
 			fmt.Printf("Skipping '%s' as it's not defined as a containerized server.\n", serverName)
 
 			continue
 		}
 
-		containerName := fmt.Sprintf("mcp-compose-%s", serverName)
-		if err := cRuntime.StopContainer(containerName); err != nil {
+		containerName := config.ContainerName(serverName)
+		if owner := containerOwner(cRuntime, containerName, configFile); owner != "" {
+			fmt.Printf("[!] Server %-30s (container %s) was started by %s; leaving it running.\n", serverName, containerName, owner)
+
+			continue
+		}
+		if err := cRuntime.StopContainer(stateContainer(state, serverName, cRuntime)); err != nil {
 			if !strings.Contains(err.Error(), "No such container") {
 				composeErrors = append(composeErrors, fmt.Sprintf("Failed to stop %s: %v", serverName, err))
 				fmt.Printf("[✖] Server %-30s Error stopping: %v\n", serverName, err)
 			} else {
 				fmt.Printf("[✔] Server %-30s (container %s) already stopped or removed.\n", serverName, containerName)
 				successCount++
+				stopped = append(stopped, serverName)
 			}
 		} else {
 			successCount++
+			stopped = append(stopped, serverName)
 			fmt.Printf("[✔] Server %-30s (container %s) stopped and removed.\n", serverName, containerName)
 		}
 	}
+	forgetServers(configFile, stopped)
 
 	fmt.Printf("\n=== SHUTDOWN SUMMARY ===\n")
 	fmt.Printf("Containerized servers processed for shutdown: %d\n", len(serversToStop))
@@ -649,7 +723,61 @@ func Down(configFile string, serverNames []string) error {
 	return nil
 }
 
+// stopProcessServers stops the named process-based servers, or all of them and those up
+// recorded that are no longer in the config when none are named. It returns the servers
+// that are no longer running.
+func stopProcessServers(cfg *config.ComposeConfig, state *ProjectState, serverNames []string) []string {
+	if len(serverNames) == 0 {
+		for name := range cfg.Servers {
+			serverNames = append(serverNames, name)
+		}
+		serverNames = append(serverNames, orphanedServers(state, cfg)...)
+		sort.Strings(serverNames)
+	}
+
+	var stopped []string
+	for _, serverName := range serverNames {
+		srvCfg, exists := cfg.Servers[serverName]
+		if exists && isContainerServer(srvCfg) {
+
+			continue
+		}
+		if !exists && state.Servers[serverName].Kind != "process" {
+
+			continue
+		}
+		identifier := config.ContainerName(serverName)
+		proc, err := runtime.FindProcess(identifier)
+		if err != nil {
+			stopped = append(stopped, serverName)
+
+			continue
+		}
+		if err := proc.Stop(); err != nil {
+			fmt.Printf("[✖] Server %-30s Error stopping process: %v\n", serverName, err)
+
+			continue
+		}
+		stopped = append(stopped, serverName)
+		fmt.Printf("[✔] Server %-30s (process %s) stopped.\n", serverName, identifier)
+	}
+
+	return stopped
+}
+
+// Start starts the named servers, or without names the servers belonging to the active profiles
 func Start(configFile string, serverNames []string) error {
+	if profiles := config.ActiveProfiles(nil); len(serverNames) == 0 && len(profiles) > 0 {
+		cfg, err := config.LoadConfig(configFile)
+		if err != nil {
+
+			return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+		}
+		if serverNames = cfg.ProfileServers(profiles); len(serverNames) == 0 {
+
+			return fmt.Errorf("no servers belong to profile(s) %s", strings.Join(profiles, ", "))
+		}
+	}
 	if len(serverNames) == 0 {
 
 		return fmt.Errorf("no server names specified to start")
@@ -668,7 +796,34 @@ func Stop(configFile string, serverNames []string) error {
 	return Down(configFile, serverNames)
 }
 
-func List(configFile string) error {
+// ServerStatus is what ls reports about a server
+type ServerStatus struct {
+	Name         string         `json:"name" yaml:"name"`
+	Kind         string         `json:"kind" yaml:"kind"` // "container" or "process"
+	Status       string         `json:"status" yaml:"status"`
+	Running      bool           `json:"running" yaml:"running"`
+	Health       string         `json:"health,omitempty" yaml:"health,omitempty"`
+	Restarts     *int           `json:"restarts,omitempty" yaml:"restarts,omitempty"`
+	Transport    string         `json:"transport,omitempty" yaml:"transport,omitempty"`
+	Identifier   string         `json:"identifier" yaml:"identifier"` // container or process name
+	Ports        []string       `json:"ports,omitempty" yaml:"ports,omitempty"`
+	Capabilities []string       `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+	StartedAt    *time.Time     `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
+	InConfig     bool           `json:"inConfig" yaml:"inConfig"`                     // false for servers up started that were since removed
+	Security     *ImageSecurity `json:"security,omitempty" yaml:"security,omitempty"` // with ls --security
+
+	statusColor func(a ...interface{}) string
+	healthColor func(a ...interface{}) string
+}
+
+// List prints the servers of the config and those still running that it no longer has, as
+// a table or in the given output format. With security it adds the last scan of each
+// container server's image.
+func List(configFile, output string, security bool) error {
+	if err := checkOutputFormat(output); err != nil {
+
+		return err
+	}
 	cfg, err := config.LoadConfig(configFile)
 	if err != nil {
 
@@ -677,81 +832,219 @@ func List(configFile string) error {
 
 	cRuntime, err := container.DetectRuntime()
 	if err != nil {
-		fmt.Printf("Warning: failed to detect container runtime: %v. Container statuses will be 'Unknown'.\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to detect container runtime: %v. Container statuses will be 'Unknown'.\n", err)
+	}
+
+	project, err := LoadProjectState(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		project = &ProjectState{Servers: map[string]ServerState{}}
+	}
+
+	statuses := CollectServerStatuses(configFile, cfg, project, cRuntime)
+	if security {
+		addImageScans(configFile, cfg, project, statuses, cRuntime)
+	}
+	if output != OutputTable {
+
+		return writeOutput(output, statuses)
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, constants.TableColumnSpacing, ' ', 0)
-	if _, err := fmt.Fprintln(w, "SERVER NAME\tSTATUS\tTRANSPORT\tCONTAINER/PROCESS NAME\tPORTS\tCAPABILITIES"); err != nil {
+	header := "SERVER NAME\tSTATUS\tHEALTH\tRESTARTS\tTRANSPORT\tCONTAINER/PROCESS NAME\tPORTS\tCAPABILITIES"
+	if security {
+		header += "\tSECURITY"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
 
 		return fmt.Errorf("failed to write header: %w", err)
 	}
+	dash := func(value string) string {
+		if value == "" {
+
+			return "-"
+		}
 
+		return value
+	}
+	for _, status := range statuses {
+		statusStr := status.Status
+		if status.Running {
+			statusStr += upSince(project, status.Name)
+		}
+		if status.statusColor != nil {
+			statusStr = status.statusColor(statusStr)
+		}
+		health := dash(status.Health)
+		if status.healthColor != nil {
+			health = status.healthColor(health)
+		}
+		restarts := "-"
+		if status.Restarts != nil {
+			restarts = strconv.Itoa(*status.Restarts)
+		}
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+			status.Name, statusStr, health, restarts, dash(status.Transport), status.Identifier,
+			dash(strings.Join(status.Ports, ", ")), dash(strings.Join(status.Capabilities, ", ")))
+		if security {
+			row += "\t" + status.Security.String()
+		}
+		_, _ = fmt.Fprintln(w, row)
+	}
+
+	if err := w.Flush(); err != nil {
+
+		return fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	return nil
+}
+
+// CollectServerStatuses reports the status of each server of the config, sorted by name,
+// followed by the recorded servers the config no longer has
+func CollectServerStatuses(configFile string, cfg *config.ComposeConfig, project *ProjectState, cRuntime container.Runtime) []ServerStatus {
 	runningColor := color.New(color.FgGreen).SprintFunc()
 	stoppedColor := color.New(color.FgRed).SprintFunc()
 	unknownColor := color.New(color.FgYellow).SprintFunc()
 	processColor := color.New(color.FgCyan).SprintFunc()
 
-	for serverName, srvConfig := range cfg.Servers {
-		identifier := fmt.Sprintf("mcp-compose-%s", serverName)
-		var statusStr string
+	serverNames := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		serverNames = append(serverNames, name)
+	}
+	sort.Strings(serverNames)
 
-		// USE THE SAME DETECTION LOGIC AS STARTUP
-		isContainer := isContainerServer(srvConfig)
+	statuses := make([]ServerStatus, 0, len(serverNames))
+	for _, serverName := range serverNames {
+		srvConfig := cfg.Servers[serverName]
+		status := ServerStatus{
+			Name:         serverName,
+			Kind:         "container",
+			Identifier:   config.ContainerName(serverName),
+			InConfig:     true,
+			Capabilities: srvConfig.Capabilities,
+			statusColor:  stoppedColor,
+		}
+		restarts := -1
 
-		if isContainer {
+		// USE THE SAME DETECTION LOGIC AS STARTUP
+		if isContainerServer(srvConfig) {
 			if cRuntime != nil && cRuntime.GetRuntimeName() != "none" {
-				rawStatus, statusErr := cRuntime.GetContainerStatus(identifier)
-				if statusErr != nil {
-					statusStr = stoppedColor("Stopped")
+				target := stateContainer(project, serverName, cRuntime)
+				rawStatus, statusErr := cRuntime.GetContainerStatus(target)
+				if owner := containerOwner(cRuntime, target, configFile); owner != "" {
+					// The container of that name is another project's, so this one's is not running
+					status.Status, status.statusColor = "Stopped (name used by "+owner+")", unknownColor
+				} else if statusErr != nil {
+					status.Status = "Stopped"
 				} else {
 					switch strings.ToLower(rawStatus) {
 					case "running":
-						statusStr = runningColor("Running")
+						status.Status, status.statusColor, status.Running = "Running", runningColor, true
 					case "exited", "dead", "stopped":
 						caser := cases.Title(language.English)
-						statusStr = stoppedColor(caser.String(strings.ToLower(rawStatus)))
+						status.Status = caser.String(strings.ToLower(rawStatus))
 					default:
-						statusStr = unknownColor(rawStatus)
+						status.Status, status.statusColor = rawStatus, unknownColor
+					}
+					if info, err := cRuntime.GetContainerInfo(target); err == nil {
+						restarts = info.RestartCount
+						status.Health = info.Health
 					}
 				}
 			} else {
-				statusStr = stoppedColor("No Runtime")
+				status.Status = "No Runtime"
 			}
 		} else {
-			// This is actually a process-based server
-			identifier = fmt.Sprintf("process-%s", serverName)
-			statusStr = processColor("Process")
+			// This is actually a process-based server, kept running by its supervisor
+			status.Kind, status.Status = "process", "Stopped"
+			if proc, err := runtime.FindProcess(status.Identifier); err == nil {
+				status.Running, _ = proc.IsRunning()
+				state, stateErr := proc.ReadState()
+				switch {
+				case status.Running:
+					status.Status, status.statusColor = "Running (process)", processColor
+				case stateErr != nil:
+					status.Status = "Exited"
+				case state.Status == runtime.StateExited:
+					status.Status = fmt.Sprintf("Exited (%d)", state.LastExitCode)
+				case state.Status == runtime.StateCrashLoop:
+					status.Status = "Crash loop"
+				case state.Status == runtime.StateBackoff && state.BackoffUntil != nil:
+					status.Status = fmt.Sprintf("Backoff (until %s)", state.BackoffUntil.Format("15:04:05"))
+				default:
+					status.Status, status.statusColor = "Restarting", unknownColor
+				}
+				if stateErr == nil {
+					restarts = state.Restarts
+				}
+			}
+		}
+		if restarts >= 0 {
+			status.Restarts = &restarts
+		}
+		if entry, exists := project.Servers[serverName]; exists && status.Running && !entry.StartedAt.IsZero() {
+			startedAt := entry.StartedAt
+			status.StartedAt = &startedAt
+		}
+
+		// The runtime already reports the result of exec checks it runs in the container
+		if check := srvConfig.ActiveHealthCheck(); status.Running && check != nil && check.CheckType() != "none" &&
+			(status.Health == "" || check.CheckType() != "exec") {
+			if err := probeServerHealth(status.Identifier, srvConfig, *check, cRuntime); err != nil {
+				status.Health, status.healthColor = "unhealthy: "+err.Error(), stoppedColor
+			} else {
+				status.Health, status.healthColor = "healthy", runningColor
+			}
+		} else if status.Health == "unhealthy" {
+			status.healthColor = stoppedColor
 		}
 
-		transport := "stdio (default)"
+		status.Transport = "stdio (default)"
 		if srvConfig.Protocol == "http" {
-			transport = fmt.Sprintf("http (:%d)", srvConfig.HttpPort)
+			status.Transport = fmt.Sprintf("http (:%d)", srvConfig.HttpPort)
 		} else if srvConfig.HttpPort > 0 {
-			transport = fmt.Sprintf("http (:%d)", srvConfig.HttpPort)
+			status.Transport = fmt.Sprintf("http (:%d)", srvConfig.HttpPort)
 		} else if serverCfgHasHTTPArg(srvConfig.Args) {
-			transport = "http (inferred)"
+			status.Transport = "http (inferred)"
 		}
 
-		ports := "-"
-		if len(srvConfig.Ports) > 0 {
-			ports = strings.Join(srvConfig.Ports, ", ")
+		if recorded := project.Servers[serverName].Ports; status.Running && len(recorded) > 0 {
+			status.Ports = recorded
+		} else {
+			status.Ports = srvConfig.Ports
 		}
 
-		capabilities := strings.Join(srvConfig.Capabilities, ", ")
-		if capabilities == "" {
-			capabilities = "-"
+		statuses = append(statuses, status)
+	}
+	for _, serverName := range orphanedServers(project, cfg) {
+		entry := project.Servers[serverName]
+		status := ServerStatus{
+			Name:        serverName,
+			Kind:        entry.Kind,
+			Status:      "Not in config ('down' stops it)",
+			Identifier:  entry.Container,
+			Ports:       entry.Ports,
+			statusColor: unknownColor,
 		}
-
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-			serverName, statusStr, transport, identifier, ports, capabilities)
+		if entry.Kind == "process" {
+			status.Identifier = config.ContainerName(serverName)
+		}
+		statuses = append(statuses, status)
 	}
 
-	if err := w.Flush(); err != nil {
+	return statuses
+}
 
-		return fmt.Errorf("failed to flush output: %w", err)
+// upSince describes how long ago up started a server, when it recorded it
+func upSince(state *ProjectState, serverName string) string {
+	entry, exists := state.Servers[serverName]
+	if !exists || entry.StartedAt.IsZero() {
+
+		return ""
 	}
 
-	return nil
+	return fmt.Sprintf(" (up %s)", time.Since(entry.StartedAt).Round(time.Second))
 }
 
 func serverCfgHasHTTPArg(args []string) bool {
@@ -769,78 +1062,47 @@ func serverCfgHasHTTPArg(args []string) bool {
 	return false
 }
 
-func Logs(configFile string, serverNames []string, follow bool) error {
-	cfg, err := config.LoadConfig(configFile)
-	if err != nil {
-
-		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
-	}
-	cRuntime, err := container.DetectRuntime()
-	if err != nil {
-
-		return fmt.Errorf("failed to detect container runtime: %w", err)
-	}
-	if cRuntime.GetRuntimeName() == "none" {
-		fmt.Println("No container runtime detected. 'logs' command is for containerized servers.")
-
-		return nil
-	}
-
-	var serversToLog []string
-	if len(serverNames) == 0 {
-		for name, srvCfg := range cfg.Servers {
-			if srvCfg.Image != "" || srvCfg.Runtime != "" {
-				serversToLog = append(serversToLog, name)
+func Validate(configFile string) error {
+	_, err := config.LoadConfig(configFile)
+	name := config.DisplayName(configFile)
+	var problems config.ValidationErrors
+	if errors.As(err, &problems) {
+		// One line per problem in file:line:column form, which editors and CI annotate
+		for _, problem := range problems {
+			location := name
+			if problem.File != "" {
+				location = problem.File
 			}
-		}
-		if len(serversToLog) == 0 {
-			fmt.Println("No containerized servers defined in configuration to show logs for.")
-
-			return nil
-		}
-	} else {
-		for _, name := range serverNames {
-			srvCfg, exists := cfg.Servers[name]
-			if !exists {
-				fmt.Fprintf(os.Stderr, "Warning: server '%s' not found in configuration, skipping logs.\n", name)
-			} else if srvCfg.Image == "" && srvCfg.Runtime == "" {
-				_, _ = fmt.Fprintf(os.Stdout, "Info: Server '%s' is process-based. View its logs directly.\n", name)
-			} else {
-				serversToLog = append(serversToLog, name)
+			if problem.Line > 0 {
+				location += fmt.Sprintf(":%d", problem.Line)
+			}
+			if problem.Column > 0 {
+				location += fmt.Sprintf(":%d", problem.Column)
 			}
+			fmt.Fprintf(os.Stderr, "%s: %s\n", location, problem.Message)
 		}
-		if len(serversToLog) == 0 {
-			fmt.Println("None of the specified servers were found or are containerized.")
 
-			return nil
-		}
+		return fmt.Errorf("configuration file '%s' has %d problem(s)", name, len(problems))
 	}
+	if err != nil {
 
-	for i, name := range serversToLog {
-		if len(serversToLog) > 1 && i > 0 && !follow {
-			fmt.Println("\n---")
-		}
-		if len(serversToLog) > 1 || len(serverNames) > 1 {
-			fmt.Printf("=== Logs for server '%s' ===\n", name)
-		}
-		containerName := fmt.Sprintf("mcp-compose-%s", name)
-		if err := cRuntime.ShowContainerLogs(containerName, follow); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to show logs for server '%s' (container %s): %v\n", name, containerName, err)
-		}
+		return fmt.Errorf("configuration file '%s' is invalid: %w", name, err)
 	}
+	fmt.Printf("Configuration file '%s' is valid.\n", name)
 
 	return nil
 }
 
-func Validate(configFile string) error {
-	_, err := config.LoadConfig(configFile)
+// WriteSchema prints the JSON Schema of the compose file for editor integration
+func WriteSchema() error {
+	schema, err := config.JSONSchema()
 	if err != nil {
 
-		return fmt.Errorf("configuration file '%s' is invalid: %w", configFile, err)
+		return fmt.Errorf("failed to generate schema: %w", err)
 	}
-	fmt.Printf("Configuration file '%s' is valid.\n", configFile)
+	_, err = os.Stdout.Write(schema)
 
-	return nil
+	return err
 }
 
 func getServersToStart(cfg *config.ComposeConfig, serverNames []string) []string {
@@ -849,9 +1111,15 @@ func getServersToStart(cfg *config.ComposeConfig, serverNames []string) []string
 		allServerNames = append(allServerNames, name)
 	}
 
+	// Without explicit names, servers behind profiles only start when a profile enables them
 	targetServers := serverNames
 	if len(targetServers) == 0 {
-		targetServers = allServerNames
+		profiles := config.ActiveProfiles(nil)
+		for _, name := range allServerNames {
+			if cfg.Servers[name].EnabledFor(profiles) {
+				targetServers = append(targetServers, name)
+			}
+		}
 	}
 
 	// Build dependency graph
@@ -1005,18 +1273,19 @@ func buildFallbackOrder(cfg *config.ComposeConfig, serverNames []string) []strin
 	return fallbackOrder
 }
 
-func convertSecurityConfig(serverName string, serverCfg config.ServerConfig) container.ContainerOptions {
+func convertSecurityConfig(cfg *config.ComposeConfig, serverName string, serverCfg config.ServerConfig) container.ContainerOptions {
 	opts := container.ContainerOptions{
-		Name:        fmt.Sprintf("mcp-compose-%s", serverName),
+		Name:        config.ContainerName(serverName),
 		Image:       serverCfg.Image,
 		Build:       serverCfg.Build,
 		Command:     serverCfg.Command,
 		Args:        serverCfg.Args,
-		Env:         config.MergeEnv(serverCfg.Env, map[string]string{"MCP_SERVER_NAME": serverName}),
+		Env:         config.MergeEnv(config.MergeEnv(serverCfg.Env, cfg.EgressEnv(serverName)), map[string]string{"MCP_SERVER_NAME": serverName}),
 		Pull:        serverCfg.Pull,
-		Volumes:     serverCfg.Volumes,
+		PullPolicy:  serverCfg.PullPolicy,
+		Volumes:     cfg.NamespaceMounts(serverCfg.Volumes),
 		Ports:       serverCfg.Ports,
-		Networks:    determineServerNetworks(serverCfg),
+		Networks:    cfg.ServerNetworks(serverName),
 		WorkDir:     serverCfg.WorkDir,
 		NetworkMode: serverCfg.NetworkMode,
 
@@ -1024,6 +1293,7 @@ func convertSecurityConfig(serverName string, serverCfg config.ServerConfig) con
 		Privileged:  serverCfg.Privileged,
 		User:        serverCfg.User,
 		Groups:      serverCfg.Groups,
+		UsernsMode:  serverCfg.UsernsMode,
 		ReadOnly:    serverCfg.ReadOnly,
 		Tmpfs:       serverCfg.Tmpfs,
 		CapAdd:      serverCfg.CapAdd,
@@ -1032,6 +1302,7 @@ func convertSecurityConfig(serverName string, serverCfg config.ServerConfig) con
 
 		// Resource limits
 		PidsLimit: serverCfg.Deploy.Resources.Limits.PIDs,
+		GPUs:      serverCfg.GPURequest(),
 
 		// Lifecycle
 		RestartPolicy: serverCfg.RestartPolicy,
@@ -1106,9 +1377,25 @@ func convertSecurityConfig(serverName string, serverCfg config.ServerConfig) con
 	return opts
 }
 
+// applyTransportEnv tells a container which transport to serve: the socat hoster port, or
+// HTTP and its port
+func applyTransportEnv(serverCfg config.ServerConfig, env map[string]string) {
+	switch {
+	case serverCfg.StdioHosterPort > 0:
+		env["MCP_SOCAT_INTERNAL_PORT"] = strconv.Itoa(serverCfg.StdioHosterPort)
+	case serverCfg.Protocol == "http" || serverCfg.HttpPort > 0:
+		if serverCfg.HttpPort > 0 {
+			env["MCP_HTTP_PORT"] = strconv.Itoa(serverCfg.HttpPort)
+		}
+		env["MCP_TRANSPORT"] = "http"
+	}
+}
+
 // UPDATE the startServerContainer function to use the new converter:
-func startServerContainer(serverName string, serverCfg config.ServerConfig, cRuntime container.Runtime) error {
-	opts := convertSecurityConfig(serverName, serverCfg)
+func startServerContainer(cfg *config.ComposeConfig, configFile, serverName string, serverCfg config.ServerConfig, cRuntime container.Runtime) error {
+	opts := convertSecurityConfig(cfg, serverName, serverCfg)
+	scanner := imagescan.Scanner{Runtime: cRuntime, CacheFile: imagescan.CacheFile(configFile), Out: os.Stdout}
+	opts.ImageCheck = scanner.Hook(serverName, serverCfg.Security.Scan)
 
 	// Transport-specific configuration
 	isSocatHostedStdio := serverCfg.StdioHosterPort > 0
@@ -1117,18 +1404,14 @@ func startServerContainer(serverName string, serverCfg config.ServerConfig, cRun
 	if isSocatHostedStdio {
 		fmt.Printf("Starting container '%s' for server '%s' (Socat STDIO Hoster mode on internal port %d).\n",
 			opts.Name, serverName, serverCfg.StdioHosterPort)
-		opts.Env["MCP_SOCAT_INTERNAL_PORT"] = strconv.Itoa(serverCfg.StdioHosterPort)
 	} else if isHttp {
 		fmt.Printf("Starting container '%s' for server '%s' (HTTP mode on internal port %d).\n",
 			opts.Name, serverName, serverCfg.HttpPort)
-		if serverCfg.HttpPort > 0 {
-			opts.Env["MCP_HTTP_PORT"] = strconv.Itoa(serverCfg.HttpPort)
-		}
-		opts.Env["MCP_TRANSPORT"] = "http"
 	} else {
 		fmt.Printf("Starting container '%s' for server '%s' (Direct STDIO mode).\n",
 			opts.Name, serverName)
 	}
+	applyTransportEnv(serverCfg, opts.Env)
 
 	// Log security configuration
 	if len(opts.CapAdd) > 0 {
@@ -1147,5 +1430,7 @@ func startServerContainer(serverName string, serverCfg config.ServerConfig, cRun
 		return fmt.Errorf("failed to start container for server '%s': %w", serverName, err)
 	}
 
+	warnMissingTzdata(serverName, opts.Name, opts.Env["TZ"], cRuntime)
+
 	return nil
 }
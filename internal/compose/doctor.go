@@ -0,0 +1,533 @@
+// internal/compose/doctor.go
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+// Doctor check statuses
+const (
+	DoctorOK      = "ok"
+	DoctorWarning = "warning"
+	DoctorProblem = "problem"
+	DoctorSkipped = "skipped"
+)
+
+// DoctorOptions controls doctor
+type DoctorOptions struct {
+	ProxyPort int  // port the proxy listens on
+	Quick     bool // skip the checks that run a container
+	JSON      bool
+}
+
+// DoctorCheck is the outcome of one diagnostic
+type DoctorCheck struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"` // what to do about a warning or problem
+}
+
+// doctor collects the checks of one run
+type doctor struct {
+	configFile  string
+	opts        DoctorOptions
+	cfg         *config.ComposeConfig
+	runtimeName string // docker, podman, or "" when neither is installed
+	cRuntime    container.Runtime
+	reachable   bool // the runtime answered
+	checks      []DoctorCheck
+}
+
+// Doctor checks the compose file and the host it runs on, printing each check with what to
+// do about any problem. The error is non-nil when any check found a problem.
+func Doctor(configFile string, opts DoctorOptions) error {
+	if opts.ProxyPort == 0 {
+		opts.ProxyPort = constants.DefaultProxyPort
+	}
+	d := &doctor{configFile: configFile, opts: opts}
+
+	d.checkConfig()
+	d.checkRuntime()
+	d.checkSocket()
+	d.checkPorts()
+	if opts.Quick {
+		d.add("Container DNS", DoctorSkipped, "skipped with --quick", "")
+		d.add("Clock", DoctorSkipped, "skipped with --quick", "")
+	} else {
+		d.checkDNS()
+		d.checkClock()
+	}
+
+	if opts.JSON {
+		data, err := json.MarshalIndent(d.checks, "", "  ")
+		if err != nil {
+
+			return fmt.Errorf("failed to encode checks: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		d.print()
+	}
+
+	problems := 0
+	for _, check := range d.checks {
+		if check.Status == DoctorProblem {
+			problems++
+		}
+	}
+	if problems > 0 {
+
+		return fmt.Errorf("doctor found %d problem(s)", problems)
+	}
+
+	return nil
+}
+
+func (d *doctor) add(check, status, detail, fix string) {
+	d.checks = append(d.checks, DoctorCheck{Check: check, Status: status, Detail: detail, Fix: fix})
+}
+
+func (d *doctor) print() {
+	marks := map[string]string{DoctorOK: "✔", DoctorWarning: "!", DoctorProblem: "✖", DoctorSkipped: "-"}
+	warnings, problems := 0, 0
+	for _, check := range d.checks {
+		fmt.Printf("[%s] %-17s %s\n", marks[check.Status], check.Check, check.Detail)
+		if check.Fix != "" {
+			fmt.Printf("    → %s\n", check.Fix)
+		}
+		switch check.Status {
+		case DoctorWarning:
+			warnings++
+		case DoctorProblem:
+			problems++
+		}
+	}
+	fmt.Println()
+	if warnings == 0 && problems == 0 {
+		fmt.Println("No problems found.")
+
+		return
+	}
+	fmt.Printf("%d problem(s), %d warning(s).\n", problems, warnings)
+}
+
+// checkConfig loads the compose file, reporting each validation problem on its own
+func (d *doctor) checkConfig() {
+	name := config.DisplayName(d.configFile)
+	cfg, err := config.LoadConfig(d.configFile)
+	var problems config.ValidationErrors
+	switch {
+	case errors.As(err, &problems):
+		for _, problem := range problems {
+			location := name
+			if problem.File != "" {
+				location = problem.File
+			}
+			if problem.Line > 0 {
+				location += fmt.Sprintf(":%d", problem.Line)
+			}
+			d.add("Config", DoctorProblem, problem.Message, "Fix the setting at "+location)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		d.add("Config", DoctorProblem, fmt.Sprintf("%s does not exist", name), "Run 'mcp-compose init' to create it, or point --file at your compose file")
+	case err != nil:
+		d.add("Config", DoctorProblem, err.Error(), "Run 'mcp-compose validate' for the location of the problem")
+	default:
+		d.cfg = cfg
+		d.add("Config", DoctorOK, fmt.Sprintf("%s is valid (%d server(s))", name, len(cfg.Servers)), "")
+	}
+}
+
+// needsRuntime reports whether any server runs in a container, or true when the config could
+// not be loaded
+func (d *doctor) needsRuntime() bool {
+	if d.cfg == nil {
+
+		return true
+	}
+	for _, srvCfg := range d.cfg.Servers {
+		if isContainerServer(srvCfg) {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRuntime finds Docker or Podman and checks that it answers
+func (d *doctor) checkRuntime() {
+	d.cRuntime = container.NewNullRuntime()
+	path, err := exec.LookPath("docker")
+	if err == nil {
+		d.runtimeName = "docker"
+	} else if path, err = exec.LookPath("podman"); err == nil {
+		d.runtimeName = "podman"
+	}
+	if d.runtimeName == "" {
+		if d.needsRuntime() {
+			d.add("Container runtime", DoctorProblem, "neither docker nor podman is installed", "Install Docker or Podman; only process servers can run without one")
+		} else {
+			d.add("Container runtime", DoctorOK, "none installed, and every server is a process", "")
+		}
+
+		return
+	}
+
+	format := "{{.ServerVersion}}"
+	if d.runtimeName == "podman" {
+		format = "{{.Version.Version}}"
+	}
+	output, err := d.run(path, "info", "--format", format)
+	if err != nil {
+		fix := "Start the container runtime and run 'mcp-compose doctor' again"
+		if d.runtimeName == "docker" {
+			fix = "Start the Docker daemon (sudo systemctl start docker, or open Docker Desktop)"
+		}
+		if d.runtimeName == "docker" && isPermissionDenied(output) {
+			fix = "See the socket check below"
+		}
+		d.add("Container runtime", DoctorProblem, fmt.Sprintf("%s is installed but does not answer: %s", d.runtimeName, firstLine(output, err)), fix)
+
+		return
+	}
+	d.reachable = true
+	if d.runtimeName == "docker" {
+		d.cRuntime, err = container.NewDockerRuntime(path)
+	} else {
+		d.cRuntime, err = container.NewPodmanRuntime(path)
+	}
+	if err != nil {
+		d.cRuntime = container.NewNullRuntime()
+		d.add("Container runtime", DoctorProblem, fmt.Sprintf("failed to set up %s: %v", d.runtimeName, err), "")
+
+		return
+	}
+	d.add("Container runtime", DoctorOK, fmt.Sprintf("%s %s", d.runtimeName, strings.TrimSpace(output)), "")
+}
+
+// checkSocket checks that the current user may use the Docker daemon's socket. Podman runs
+// without a daemon, and remote Docker hosts have no local socket to check.
+func (d *doctor) checkSocket() {
+	if d.runtimeName != "docker" {
+		d.add("Runtime socket", DoctorSkipped, "only Docker is reached through a socket", "")
+
+		return
+	}
+	socket := "/var/run/docker.sock"
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		path, isUnix := strings.CutPrefix(host, "unix://")
+		if !isUnix {
+			d.add("Runtime socket", DoctorSkipped, fmt.Sprintf("DOCKER_HOST is %s", redactURL(host)), "")
+
+			return
+		}
+		socket = path
+	}
+
+	conn, err := net.DialTimeout("unix", socket, constants.ExternalDependencyCheckTimeout)
+	switch {
+	case err == nil:
+		_ = conn.Close()
+		d.add("Runtime socket", DoctorOK, socket+" is accessible", "")
+	case errors.Is(err, os.ErrPermission):
+		d.add("Runtime socket", DoctorProblem, fmt.Sprintf("no permission to use %s", socket),
+			"Add your user to the docker group (sudo usermod -aG docker $USER) and log in again, or use rootless Docker or Podman")
+	case errors.Is(err, os.ErrNotExist):
+		if d.reachable {
+			// Docker Desktop and contexts answer on another socket
+			d.add("Runtime socket", DoctorOK, fmt.Sprintf("%s does not exist, but docker answers through its current context", socket), "")
+		} else {
+			d.add("Runtime socket", DoctorProblem, fmt.Sprintf("%s does not exist", socket), "Start the Docker daemon, or set DOCKER_HOST to its socket")
+		}
+	default:
+		d.add("Runtime socket", DoctorProblem, fmt.Sprintf("cannot connect to %s: %v", socket, err), "Start the Docker daemon")
+	}
+}
+
+// portUser is something that listens on a host port
+type portUser struct {
+	owner   string
+	running func() bool // whether it is up, so the port being taken is expected
+}
+
+// checkPorts looks for host ports claimed twice in the config, or taken by something else
+func (d *doctor) checkPorts() {
+	if d.cfg == nil {
+		d.add("Ports", DoctorSkipped, "the config could not be loaded", "")
+
+		return
+	}
+	status := make(map[string]string)
+	for _, snapshot := range CollectSnapshots(d.cfg, d.cRuntime) {
+		status[snapshot.Name] = snapshot.Status
+	}
+	containerRunning := func(name string) func() bool {
+		return func() bool { return d.containerStatus(name) == "running" }
+	}
+
+	users := map[int][]portUser{
+		d.opts.ProxyPort: {{owner: "the proxy", running: d.proxyRunning}},
+	}
+	if d.cfg.Dashboard.Enabled {
+		port := d.cfg.Dashboard.Port
+		if port == 0 {
+			port = constants.DefaultDashboardPort
+		}
+		users[port] = append(users[port], portUser{owner: "the dashboard", running: containerRunning(config.ContainerName("dashboard"))})
+	}
+	for _, name := range sortedServerNames(d.cfg) {
+		srvCfg := d.cfg.Servers[name]
+		serverName := name
+		user := portUser{owner: fmt.Sprintf("server '%s'", name), running: func() bool { return status[serverName] == "running" }}
+		for _, mapping := range srvCfg.Ports {
+			if port, ok := publishedPort(mapping); ok {
+				users[port] = append(users[port], user)
+			}
+		}
+	}
+
+	ports := make([]int, 0, len(users))
+	for port := range users {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	conflicts := 0
+	for _, port := range ports {
+		claimants := users[port]
+		if len(claimants) > 1 {
+			owners := make([]string, 0, len(claimants))
+			for _, user := range claimants {
+				owners = append(owners, user.owner)
+			}
+			d.add("Ports", DoctorProblem, fmt.Sprintf("port %d is used by %s", port, strings.Join(owners, " and ")), "Give each of them its own port")
+			conflicts++
+
+			continue
+		}
+		if portFree(port) || claimants[0].running() {
+
+			continue
+		}
+		d.add("Ports", DoctorProblem, fmt.Sprintf("port %d, used by %s, is taken by another program", port, claimants[0].owner),
+			fmt.Sprintf("Stop whatever listens on it (lsof -i :%d) or change the port", port))
+		conflicts++
+	}
+	if conflicts == 0 {
+		d.add("Ports", DoctorOK, fmt.Sprintf("%d host port(s) free or in use by mcp-compose", len(ports)), "")
+	}
+}
+
+// proxyRunning reports whether the proxy, in its container or run directly, holds its port
+func (d *doctor) proxyRunning() bool {
+	if d.containerStatus(config.ContainerName("http-proxy")) == "running" {
+
+		return true
+	}
+	client := &http.Client{Timeout: constants.ExternalDependencyCheckTimeout}
+	resp, err := client.Get(fmt.Sprintf("http://localhost:%d/api/status", d.opts.ProxyPort))
+	if err != nil {
+
+		return false
+	}
+	_ = resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden
+}
+
+func (d *doctor) containerStatus(name string) string {
+	if !d.reachable {
+
+		return ""
+	}
+	status, err := d.cRuntime.GetContainerStatus(name)
+	if err != nil {
+
+		return ""
+	}
+
+	return status
+}
+
+// publishedPort returns the host port of a host:container or ip:host:container mapping
+func publishedPort(mapping string) (int, bool) {
+	mapping, _, _ = strings.Cut(mapping, "/")
+	parts := strings.Split(mapping, ":")
+	if len(parts) < 2 {
+
+		return 0, false
+	}
+	port, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil || port <= 0 {
+
+		return 0, false
+	}
+
+	return port, true
+}
+
+// portFree reports whether nothing listens on a host port
+func portFree(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+
+		return false
+	}
+	_ = listener.Close()
+
+	return true
+}
+
+// checkDNS checks that containers resolve the running servers' containers by name on each of
+// their networks, the way the proxy and peers reach them
+func (d *doctor) checkDNS() {
+	if d.cfg == nil || !d.reachable {
+		d.add("Container DNS", DoctorSkipped, "needs a loaded config and a container runtime", "")
+
+		return
+	}
+	byNetwork := make(map[string][]string)
+	for _, name := range sortedServerNames(d.cfg) {
+		if !isContainerServer(d.cfg.Servers[name]) || d.containerStatus(config.ContainerName(name)) != "running" {
+
+			continue
+		}
+		for _, network := range d.cfg.ServerNetworks(name) {
+			byNetwork[network] = append(byNetwork[network], config.ContainerName(name))
+		}
+	}
+	if len(byNetwork) == 0 {
+		d.add("Container DNS", DoctorSkipped, "no server container is running; run it after 'mcp-compose up'", "")
+
+		return
+	}
+
+	networks := make([]string, 0, len(byNetwork))
+	for network := range byNetwork {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+	failures := 0
+	for _, network := range networks {
+		hosts := byNetwork[network]
+		args := append([]string{"run", "--rm", "--network", network, constants.DoctorProbeImage,
+			"sh", "-c", `for h in "$@"; do nslookup "$h" >/dev/null 2>&1 || echo "$h"; done`, "sh"}, hosts...)
+		output, err := d.run(d.runtimeName, args...)
+		if err != nil {
+			d.add("Container DNS", DoctorWarning, fmt.Sprintf("could not run a probe container on %s: %s", network, firstLine(output, err)),
+				fmt.Sprintf("Check that %s can be pulled, or run with --quick", constants.DoctorProbeImage))
+			failures++
+
+			continue
+		}
+		if unresolved := strings.Fields(output); len(unresolved) > 0 {
+			fix := fmt.Sprintf("Recreate the network: mcp-compose down, %s network rm %s, mcp-compose up", d.runtimeName, network)
+			if d.runtimeName == "podman" {
+				fix = "Use the netavark network backend with aardvark-dns (or the dnsname CNI plugin) so containers resolve each other by name"
+			}
+			d.add("Container DNS", DoctorProblem, fmt.Sprintf("%s on %s cannot be resolved by name", strings.Join(unresolved, ", "), network), fix)
+			failures++
+		}
+	}
+	if failures == 0 {
+		d.add("Container DNS", DoctorOK, fmt.Sprintf("running servers resolve on %s", strings.Join(networks, ", ")), "")
+	}
+}
+
+// checkClock compares the host's clock with the container runtime's, which drifts apart in
+// Docker Desktop and Podman machine VMs after the host sleeps and breaks token expiry checks
+func (d *doctor) checkClock() {
+	if !d.reachable {
+		d.add("Clock", DoctorSkipped, "needs a container runtime", "")
+
+		return
+	}
+	before := time.Now()
+	output, err := d.run(d.runtimeName, "run", "--rm", "--network", "none", constants.DoctorProbeImage, "date", "+%s")
+	after := time.Now()
+	if err != nil {
+		d.add("Clock", DoctorWarning, fmt.Sprintf("could not run a probe container: %s", firstLine(output, err)),
+			fmt.Sprintf("Check that %s can be pulled, or run with --quick", constants.DoctorProbeImage))
+
+		return
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		d.add("Clock", DoctorWarning, fmt.Sprintf("unexpected output from date: %q", strings.TrimSpace(output)), "")
+
+		return
+	}
+
+	// date has a resolution of a second, and the container ran somewhere between before and after
+	containerTime := time.Unix(seconds, 0)
+	var skew time.Duration
+	switch {
+	case containerTime.Before(before.Add(-time.Second)):
+		skew = before.Sub(containerTime)
+	case containerTime.After(after.Add(time.Second)):
+		skew = containerTime.Sub(after)
+	}
+	if skew > constants.DoctorMaxClockSkew {
+		d.add("Clock", DoctorProblem, fmt.Sprintf("containers' clock is %s off the host's", skew.Round(time.Second)),
+			"Restart Docker Desktop or the Podman machine, or enable NTP on the host (timedatectl set-ntp true)")
+
+		return
+	}
+	d.add("Clock", DoctorOK, "containers' clock matches the host's", "")
+}
+
+// run runs a runtime command with the doctor timeout and returns its combined output
+func (d *doctor) run(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DoctorCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if ctx.Err() != nil {
+
+		return string(output), fmt.Errorf("timed out after %s", constants.DoctorCommandTimeout)
+	}
+
+	return string(output), err
+}
+
+func isPermissionDenied(output string) bool {
+
+	return strings.Contains(strings.ToLower(output), "permission denied")
+}
+
+// firstLine returns the first line of a command's output, or its error when it printed nothing
+func firstLine(output string, err error) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	if line == "" && err != nil {
+
+		return err.Error()
+	}
+
+	return line
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+
+		return raw
+	}
+
+	return u.Redacted()
+}
@@ -0,0 +1,100 @@
+// internal/compose/encrypt.go
+package compose
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"filippo.io/age"
+)
+
+// GenerateAgeKey writes a new age identity to the key file compose files are decrypted with
+// and prints its public key, which values are encrypted to
+func GenerateAgeKey(force bool) error {
+	file := config.AgeKeyFile()
+	if file == "" {
+
+		return fmt.Errorf("%s is set; unset it to write a key file", config.AgeKeyEnv)
+	}
+	if _, err := os.Stat(file); err == nil && !force {
+
+		return fmt.Errorf("%s already exists; pass --force to replace it, which makes values encrypted to it undecryptable", file)
+	}
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+
+		return err
+	}
+	recipient := identity.Recipient().String()
+	content := fmt.Sprintf("# created: %s\n# public key: %s\n%s\n", time.Now().UTC().Format(time.RFC3339), recipient, identity)
+	if err := os.MkdirAll(filepath.Dir(file), constants.SecretDirMode); err != nil {
+
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := os.WriteFile(file, []byte(content), constants.SecretFileMode); err != nil {
+
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	fmt.Printf("Wrote age key to %s\n", file)
+	fmt.Printf("Public key: %s\n", recipient)
+
+	return nil
+}
+
+// EncryptConfigValue prints a value encrypted for the compose file, as !encrypted and the
+// encrypted value ready to paste. The value is read from stdin when not given. Without
+// recipients it is encrypted to the key mcp-compose decrypts with.
+func EncryptConfigValue(value string, recipientKeys []string, stdin io.Reader) error {
+	if value == "" {
+		data, err := io.ReadAll(bufio.NewReader(stdin))
+		if err != nil {
+
+			return fmt.Errorf("failed to read value: %w", err)
+		}
+		value = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	}
+	if value == "" {
+
+		return errors.New("nothing to encrypt: pass the value as an argument or on stdin")
+	}
+
+	var recipients []age.Recipient
+	for _, key := range recipientKeys {
+		recipient, err := age.ParseX25519Recipient(key)
+		if err != nil {
+
+			return err
+		}
+		recipients = append(recipients, recipient)
+	}
+	if len(recipients) == 0 {
+		identities, err := config.AgeIdentities()
+		if err != nil {
+
+			return err
+		}
+		for _, identity := range identities {
+			if x25519, ok := identity.(*age.X25519Identity); ok {
+				recipients = append(recipients, x25519.Recipient())
+			}
+		}
+	}
+
+	encrypted, err := config.EncryptValue(value, recipients)
+	if err != nil {
+
+		return fmt.Errorf("failed to encrypt value: %w", err)
+	}
+	fmt.Printf("%s %s\n", config.EncryptedTag, encrypted)
+
+	return nil
+}
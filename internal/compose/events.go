@@ -0,0 +1,113 @@
+// internal/compose/events.go
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+
+	"github.com/fatih/color"
+)
+
+// EventsOptions configures the events command
+type EventsOptions struct {
+	ProxyURL string
+	APIKey   string
+	Follow   bool
+	Types    []string
+	Servers  []string
+	JSON     bool
+}
+
+// Events prints the events recorded by the running proxy and, with Follow, keeps
+// streaming new ones, reconnecting when the proxy restarts
+func Events(configFile string, opts EventsOptions) error {
+	apiKey := opts.APIKey
+	if apiKey == "" {
+		if cfg, err := config.LoadConfig(configFile); err == nil {
+			apiKey = cfg.ProxyAuth.APIKey
+		}
+	}
+
+	query := url.Values{}
+	if len(opts.Types) > 0 {
+		query.Set("type", strings.Join(opts.Types, ","))
+	}
+	if len(opts.Servers) > 0 {
+		query.Set("server", strings.Join(opts.Servers, ","))
+	}
+	if !opts.Follow {
+		query.Set("follow", "false")
+	}
+	streamURL := strings.TrimRight(opts.ProxyURL, "/") + "/api/events?" + query.Encode()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	handle := printEvent
+	if opts.JSON {
+		handle = printEventJSON
+	}
+
+	lastEventID, err := events.Follow(ctx, http.DefaultClient, streamURL, apiKey, "", handle)
+	if !opts.Follow {
+		if err != nil {
+
+			return fmt.Errorf("failed to read events from %s (is the proxy running?): %w", opts.ProxyURL, err)
+		}
+
+		return nil
+	}
+
+	for ctx.Err() == nil {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[✖] %v; retrying in %s\n", err, constants.EventStreamRetryDelay)
+		}
+		select {
+		case <-ctx.Done():
+
+			return nil
+		case <-time.After(constants.EventStreamRetryDelay):
+		}
+		lastEventID, err = events.Follow(ctx, http.DefaultClient, streamURL, apiKey, lastEventID, handle)
+	}
+
+	return nil
+}
+
+func printEvent(event events.Event) {
+	levelColor := color.New(color.FgCyan).SprintFunc()
+	switch event.Level {
+	case events.LevelError:
+		levelColor = color.New(color.FgRed).SprintFunc()
+	case events.LevelWarn:
+		levelColor = color.New(color.FgYellow).SprintFunc()
+	}
+
+	server := event.Server
+	if server == "" {
+		server = "-"
+	}
+	fmt.Printf("%s  %s  %-18s %-20s %s\n", event.Time.Local().Format("2006-01-02 15:04:05"),
+		levelColor(fmt.Sprintf("%-5s", event.Level)), event.Type, server, event.Message)
+}
+
+func printEventJSON(event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+
+		return
+	}
+	fmt.Println(string(data))
+}
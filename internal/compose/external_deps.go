@@ -0,0 +1,198 @@
+// internal/compose/external_deps.go
+package compose
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	_ "github.com/lib/pq"
+)
+
+// externalDependencyWaiter blocks server startup until the external services they
+// depend on are reachable. Each dependency is waited for once, however many servers need it.
+type externalDependencyWaiter struct {
+	deps    map[string]config.ExternalDependency
+	mu      sync.Mutex
+	waits   map[string]*sync.Once
+	results map[string]error
+}
+
+func newExternalDependencyWaiter(cfg *config.ComposeConfig) *externalDependencyWaiter {
+
+	return &externalDependencyWaiter{
+		deps:    cfg.ExternalDependencies,
+		waits:   make(map[string]*sync.Once),
+		results: make(map[string]error),
+	}
+}
+
+// WaitFor blocks until every listed dependency is ready or one of them times out
+func (w *externalDependencyWaiter) WaitFor(refs []config.ExternalDependencyRef) error {
+	for _, ref := range refs {
+		if err := w.wait(ref); err != nil {
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *externalDependencyWaiter) wait(ref config.ExternalDependencyRef) error {
+	// Named dependencies are shared by name, inline ones by what they check
+	key := "name:" + ref.Name
+	if ref.Name == "" {
+		dep := ref.Check()
+		key = fmt.Sprintf("inline:%s|%s|%s|%d", dep.TCP, dep.HTTP, dep.Postgres, dep.ExpectStatus)
+	}
+
+	w.mu.Lock()
+	once, exists := w.waits[key]
+	if !exists {
+		once = &sync.Once{}
+		w.waits[key] = once
+	}
+	w.mu.Unlock()
+
+	once.Do(func() {
+		var err error
+		if ref.Name == "" {
+			err = waitForExternalDependency(ref.Label(), ref.ExternalDependency)
+		} else if dep, defined := w.deps[ref.Name]; defined {
+			err = waitForExternalDependency(ref.Name, dep)
+		} else {
+			err = fmt.Errorf("external dependency '%s' is not defined", ref.Name)
+		}
+		w.mu.Lock()
+		w.results[key] = err
+		w.mu.Unlock()
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.results[key]
+}
+
+// waitForExternalDependency polls a dependency until it answers or its timeout elapses
+func waitForExternalDependency(name string, dep config.ExternalDependency) error {
+	dep = dep.Check()
+	timeout := parseDurationOr(dep.Timeout, constants.ExternalDependencyTimeout)
+	interval := parseDurationOr(dep.Interval, constants.ExternalDependencyInterval)
+
+	fmt.Printf("Waiting for external dependency '%s' (%s, timeout %s)...\n", name, describeExternalDependency(dep), timeout)
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	var lastErr error
+	for {
+		lastErr = checkExternalDependency(dep)
+		if lastErr == nil {
+			fmt.Printf("[✔] Dependency %-26s Ready (%s)\n", name, ShortDuration(time.Since(start)))
+
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+
+			break
+		}
+		time.Sleep(interval)
+	}
+
+	fmt.Printf("[✖] Dependency %-26s Not ready after %s: %v\n", name, timeout, lastErr)
+
+	return fmt.Errorf("external dependency '%s' not ready after %s: %w", name, timeout, lastErr)
+}
+
+// checkExternalDependency makes a single readiness attempt
+func checkExternalDependency(dep config.ExternalDependency) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.ExternalDependencyCheckTimeout)
+	defer cancel()
+
+	switch {
+	case dep.TCP != "":
+		dialer := net.Dialer{}
+		conn, err := dialer.DialContext(ctx, "tcp", dep.TCP)
+		if err != nil {
+
+			return err
+		}
+		_ = conn.Close()
+
+		return nil
+	case dep.HTTP != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, dep.HTTP, nil)
+		if err != nil {
+
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+
+			return err
+		}
+		_ = resp.Body.Close()
+		if dep.ExpectStatus != 0 {
+			if resp.StatusCode != dep.ExpectStatus {
+
+				return fmt.Errorf("got status %d, expected %d", resp.StatusCode, dep.ExpectStatus)
+			}
+
+			return nil
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+
+			return fmt.Errorf("got status %d", resp.StatusCode)
+		}
+
+		return nil
+	case dep.Postgres != "":
+		db, err := sql.Open("postgres", dep.Postgres)
+		if err != nil {
+
+			return fmt.Errorf("failed to open postgres connection: %w", err)
+		}
+		defer db.Close()
+
+		return db.PingContext(ctx)
+	}
+
+	return fmt.Errorf("no check configured")
+}
+
+func describeExternalDependency(dep config.ExternalDependency) string {
+	switch {
+	case dep.TCP != "":
+
+		return "tcp " + dep.TCP
+	case dep.HTTP != "":
+
+		return "http " + dep.HTTP
+	default:
+		// The DSN may carry a password, so only name the kind of check
+
+		return "postgres"
+	}
+}
+
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+
+		return fallback
+	}
+
+	return d
+}
@@ -0,0 +1,160 @@
+// internal/compose/generate_client.go
+package compose
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/codegen"
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/openapi"
+)
+
+// GenerateClientOptions controls typed client generation
+type GenerateClientOptions struct {
+	ProxyURL    string
+	APIKey      string
+	PackageName string
+	Languages   []string
+	Servers     []string
+}
+
+// GenerateClient fetches the live tool schemas of each server through the proxy and
+// writes typed client code into outputDir
+func GenerateClient(configFile, outputDir string, opts GenerateClientOptions) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+
+	apiKey := opts.APIKey
+	if apiKey == "" {
+		apiKey = cfg.ProxyAuth.APIKey
+	}
+	proxyURL := strings.TrimRight(opts.ProxyURL, "/")
+
+	serverNames := opts.Servers
+	if len(serverNames) == 0 {
+		for name := range cfg.Servers {
+			serverNames = append(serverNames, name)
+		}
+	}
+
+	client := &http.Client{Timeout: constants.ToolDiscoveryTimeout}
+	var servers []codegen.ServerTools
+	for _, name := range serverNames {
+		if _, exists := cfg.Servers[name]; !exists {
+
+			return fmt.Errorf("server '%s' not found in config", name)
+		}
+
+		tools, err := fetchServerTools(client, proxyURL, apiKey, name)
+		if err != nil {
+			fmt.Printf("[✖] Server %-30s skipped: %v\n", name, err)
+
+			continue
+		}
+		fmt.Printf("[✔] Server %-30s %d tool(s)\n", name, len(tools))
+		servers = append(servers, codegen.ServerTools{Server: name, Tools: tools})
+	}
+
+	if len(servers) == 0 {
+
+		return fmt.Errorf("no tool schemas could be fetched from %s; is the proxy running?", proxyURL)
+	}
+	codegen.SortServerTools(servers)
+
+	if err := os.MkdirAll(outputDir, constants.DefaultDirMode); err != nil {
+
+		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+
+	for _, lang := range opts.Languages {
+		var (
+			fileName string
+			content  []byte
+		)
+		switch lang {
+		case "go":
+			fileName = "client.go"
+			content, err = codegen.GenerateGo(opts.PackageName, servers)
+			if err != nil {
+
+				return err
+			}
+		case "ts", "typescript":
+			fileName = "client.ts"
+			content = codegen.GenerateTypeScript(servers)
+		default:
+
+			return fmt.Errorf("unsupported client language '%s' (expected go or ts)", lang)
+		}
+
+		path := filepath.Join(outputDir, fileName)
+		if err := os.WriteFile(path, content, constants.DefaultFileMode); err != nil {
+
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("✅ Wrote %s\n", path)
+	}
+
+	return nil
+}
+
+// fetchServerTools sends tools/list to a server through the proxy
+func fetchServerTools(client *http.Client, proxyURL, apiKey, serverName string) ([]openapi.Tool, error) {
+	payload := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{}}`)
+	req, err := http.NewRequest(http.MethodPost, proxyURL+"/"+serverName, bytes.NewReader(payload))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to reach proxy: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+
+		return nil, fmt.Errorf("proxy returned HTTP %d", resp.StatusCode)
+	}
+
+	var envelope struct {
+		Result struct {
+			Tools []openapi.Tool `json:"tools"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+
+		return nil, fmt.Errorf("failed to decode tools/list response: %w", err)
+	}
+	if envelope.Error != nil {
+
+		return nil, fmt.Errorf("tools/list failed: %s", envelope.Error.Message)
+	}
+
+	return envelope.Result.Tools, nil
+}
@@ -0,0 +1,377 @@
+// internal/compose/history.go
+package compose
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigChangeOptions configures config set and config rollback, which hand the change to
+// the running proxy so it restarts the servers the change affects
+type ConfigChangeOptions struct {
+	ProxyURL string
+	APIKey   string
+	NoReload bool // only write the file
+}
+
+// errProxyUnavailable means the change has to be applied without the proxy: it isn't
+// running or its config editor is disabled
+var errProxyUnavailable = errors.New("proxy unavailable")
+
+// configApplyResult is what the proxy's /api/config/apply and /api/config/rollback return
+type configApplyResult struct {
+	Status          string               `json:"status"`
+	Version         int                  `json:"version"`
+	Changes         config.ConfigChanges `json:"changes"`
+	Restarted       []string             `json:"restarted"`
+	RestartRequired bool                 `json:"restartRequired"`
+	Error           string               `json:"error"`
+	Errors          []struct {
+		Line    int    `json:"line"`
+		Path    string `json:"path"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// ConfigHistory prints the recorded versions of the compose file, newest first
+func ConfigHistory(configFile, output string) error {
+	if err := checkOutputFormat(output); err != nil {
+
+		return err
+	}
+	versions, err := config.ConfigHistory(configFile)
+	if err != nil {
+
+		return err
+	}
+	if output != OutputTable {
+
+		return writeOutput(output, versions)
+	}
+	if len(versions) == 0 {
+		fmt.Fprintf(os.Stderr, "No history is recorded for %s yet; it starts with the first change made by\n'config set', 'add', 'import' or the dashboard's config editor.\n", configFile)
+
+		return nil
+	}
+
+	current := ""
+	if data, err := os.ReadFile(configFile); err == nil {
+		current = config.ContentHash(data)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, constants.TableColumnSpacing, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tTIME\tSOURCE\tCHANGE\t")
+	for _, version := range versions {
+		label := strconv.Itoa(version.Version)
+		if version.Hash == current {
+			label += " (current)"
+		}
+		source := version.Source
+		if version.Client != "" {
+			source += " (" + version.Client + ")"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", label, version.Time.Local().Format("2006-01-02 15:04:05"), source, version.Message)
+	}
+
+	return w.Flush()
+}
+
+// ConfigRollback restores a recorded version of the compose file and restarts the servers
+// it changes. The restored contents are recorded as a new version, so a rollback can be
+// undone in turn.
+func ConfigRollback(configFile string, version int, opts ConfigChangeOptions) error {
+	_, content, err := config.ConfigVersionContent(configFile, version)
+	if err != nil {
+
+		return err
+	}
+	if _, err := config.ParseConfig(configFile, content); err != nil {
+
+		return fmt.Errorf("version %d is not a valid configuration any more: %w", version, err)
+	}
+
+	if !opts.NoReload {
+		result, err := postConfigChange(configFile, "/api/config/rollback", map[string]interface{}{"version": version}, opts)
+		if err == nil {
+			fmt.Printf("Rolled back %s to version %d (now version %d)\n", configFile, version, result.Version)
+			printConfigApplyResult(result)
+
+			return nil
+		}
+		if !errors.Is(err, errProxyUnavailable) {
+
+			return err
+		}
+	}
+
+	return applyConfigLocally(configFile, content, config.ConfigVersion{
+		Source:  config.VersionSourceRollback,
+		Message: fmt.Sprintf("rolled back to version %d", version),
+	}, opts)
+}
+
+// ConfigSet sets a setting of the compose file, given as a dotted path such as
+// servers.filesystem.env.DEBUG, to a YAML value, and restarts the servers it changes.
+// Comments and ${VAR} references elsewhere in the file are kept.
+func ConfigSet(configFile, path, value string, opts ConfigChangeOptions) error {
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if key == "" {
+
+			return fmt.Errorf("invalid setting '%s': use a dotted path such as servers.NAME.image", path)
+		}
+	}
+	var parsed yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	replacement := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	if len(parsed.Content) > 0 {
+		replacement = parsed.Content[0]
+	}
+
+	current, err := os.ReadFile(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to read '%s': %w", configFile, err)
+	}
+	document, err := loadComposeDocument(configFile)
+	if err != nil {
+
+		return err
+	}
+	if err := setDocumentValue(document.Content[0], keys, replacement); err != nil {
+
+		return fmt.Errorf("failed to set %s: %w", path, err)
+	}
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+
+		return fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	_ = encoder.Close()
+	if bytes.Equal(out.Bytes(), current) {
+		fmt.Printf("%s is already set to that value\n", path)
+
+		return nil
+	}
+	if _, err := config.ParseConfig(configFile, out.Bytes()); err != nil {
+
+		return fmt.Errorf("setting %s makes the configuration invalid: %w", path, err)
+	}
+
+	message := fmt.Sprintf("set %s", path)
+	if !opts.NoReload {
+		result, err := postConfigChange(configFile, "/api/config/apply", map[string]interface{}{
+			"content":  out.String(),
+			"baseHash": config.ContentHash(current),
+			"source":   config.VersionSourceCLI,
+			"message":  message,
+		}, opts)
+		if err == nil {
+			fmt.Printf("Set %s in %s (version %d)\n", path, configFile, result.Version)
+			printConfigApplyResult(result)
+
+			return nil
+		}
+		if !errors.Is(err, errProxyUnavailable) {
+
+			return err
+		}
+	}
+
+	return applyConfigLocally(configFile, out.Bytes(), config.ConfigVersion{Source: config.VersionSourceCLI, Message: message}, opts)
+}
+
+// setDocumentValue sets the value at a path of keys, creating the mappings on the way.
+// Numeric keys index sequences.
+func setDocumentValue(node *yaml.Node, keys []string, value *yaml.Node) error {
+	for i, key := range keys {
+		last := i == len(keys)-1
+		switch node.Kind {
+		case yaml.MappingNode:
+			next := mappingValue(node, key)
+			if next == nil {
+				next = &yaml.Node{Kind: yaml.MappingNode}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, next)
+			}
+			if last {
+				*next = *value
+			}
+			node = next
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node.Content) {
+
+				return fmt.Errorf("'%s' is a list of %d item(s); use an index from 0", strings.Join(keys[:i], "."), len(node.Content))
+			}
+			if last {
+				*node.Content[index] = *value
+			}
+			node = node.Content[index]
+		default:
+
+			return fmt.Errorf("'%s' is not a mapping or a list", strings.Join(keys[:i], "."))
+		}
+	}
+
+	return nil
+}
+
+// postConfigChange asks the running proxy to write a change and restart the servers it
+// affects, returning errProxyUnavailable when it can't
+func postConfigChange(configFile, endpoint string, request map[string]interface{}, opts ConfigChangeOptions) (*configApplyResult, error) {
+	if _, remote := config.RemoteSource(configFile); remote {
+
+		return nil, errProxyUnavailable
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(opts.ProxyURL, "/")+endpoint, bytes.NewReader(body))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	apiKey := opts.APIKey
+	if apiKey == "" {
+		if cfg, err := config.LoadConfig(configFile); err == nil {
+			apiKey = cfg.ProxyAuth.APIKey
+		}
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: constants.SupportBundleProxyTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+
+		return nil, errProxyUnavailable
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result configApplyResult
+	data, err := io.ReadAll(io.LimitReader(resp.Body, constants.SupportBundleMaxResponseSize))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read proxy response: %w", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+
+		return nil, fmt.Errorf("unexpected proxy response (HTTP %d)", resp.StatusCode)
+	}
+	// The editor endpoints only exist with dashboard.config_editor set
+	if resp.StatusCode == http.StatusNotFound && strings.Contains(result.Error, "config editor is disabled") {
+
+		return nil, errProxyUnavailable
+	}
+	if resp.StatusCode != http.StatusOK {
+		problems := result.Error
+		for _, problem := range result.Errors {
+			problems += fmt.Sprintf("\n  line %d: %s %s", problem.Line, problem.Path, problem.Message)
+		}
+
+		return nil, fmt.Errorf("proxy refused the change: %s", problems)
+	}
+
+	return &result, nil
+}
+
+func printConfigApplyResult(result *configApplyResult) {
+	if len(result.Restarted) > 0 {
+		fmt.Printf("Restarted: %s\n", strings.Join(result.Restarted, ", "))
+	}
+	if len(result.Changes.Added) > 0 {
+		fmt.Printf("Added (not started): %s\n", strings.Join(result.Changes.Added, ", "))
+	}
+	if len(result.Changes.Removed) > 0 {
+		fmt.Printf("Removed: %s\n", strings.Join(result.Changes.Removed, ", "))
+	}
+	if result.RestartRequired {
+		fmt.Println("Settings outside servers changed; restart the proxy for them to take effect.")
+	}
+	if result.Error != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", result.Error)
+	}
+}
+
+// applyConfigLocally writes a change without the proxy: the servers it removes or changes
+// that up started are stopped with their old definitions, and the changed ones are started
+// again with the new
+func applyConfigLocally(configFile string, content []byte, change config.ConfigVersion, opts ConfigChangeOptions) error {
+	previous, err := config.LoadConfig(configFile)
+	if err != nil {
+		// Whatever runs was started from a configuration that no longer loads, so nothing
+		// can be matched against it
+		opts.NoReload = true
+	}
+
+	var stopped []string
+	if !opts.NoReload {
+		proposed, err := config.ParseConfig(configFile, content)
+		if err != nil {
+
+			return err
+		}
+		state, err := LoadProjectState(configFile)
+		if err != nil {
+
+			return err
+		}
+		changes := config.DiffConfigs(previous, proposed)
+		for _, name := range append(append([]string{}, changes.Removed...), changes.Changed...) {
+			if _, running := state.Servers[name]; running {
+				stopped = append(stopped, name)
+			}
+		}
+		if len(stopped) > 0 {
+			if err := Down(configFile, stopped); err != nil {
+
+				return fmt.Errorf("failed to stop the servers the change affects: %w", err)
+			}
+		}
+		if changes.Global {
+			defer fmt.Println("Settings outside servers changed; restart the proxy for them to take effect.")
+		}
+		stopped = slices.DeleteFunc(stopped, func(name string) bool { return slices.Contains(changes.Removed, name) })
+	}
+
+	_, written, err := config.WriteConfigFile(configFile, content, change)
+	if written == nil {
+
+		return err
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	fmt.Printf("Wrote %s (version %d)\n", configFile, written.Version)
+
+	if len(stopped) > 0 {
+		if err := Up(configFile, stopped); err != nil {
+
+			return fmt.Errorf("failed to restart %s: %w", strings.Join(stopped, ", "), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+// internal/compose/image_scans.go
+package compose
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/imagescan"
+
+	"github.com/fatih/color"
+)
+
+// ImageSecurity is what ls --security reports about the last scan of a server's image
+type ImageSecurity struct {
+	Image     string         `json:"image" yaml:"image"`
+	Scanned   bool           `json:"scanned" yaml:"scanned"`
+	Scanner   string         `json:"scanner,omitempty" yaml:"scanner,omitempty"`
+	ScannedAt *time.Time     `json:"scannedAt,omitempty" yaml:"scannedAt,omitempty"`
+	Counts    map[string]int `json:"counts,omitempty" yaml:"counts,omitempty"`
+	Summary   string         `json:"summary" yaml:"summary"`
+
+	color func(a ...interface{}) string
+}
+
+// addImageScans looks up the cached scan of the image of each container server: the image
+// its container runs, or the one it would start from when it is not running
+func addImageScans(configFile string, cfg *config.ComposeConfig, project *ProjectState, statuses []ServerStatus, cRuntime container.Runtime) {
+	if cRuntime == nil || cRuntime.GetRuntimeName() == "none" {
+
+		return
+	}
+	cacheFile := imagescan.CacheFile(configFile)
+	for i := range statuses {
+		srvConfig, exists := cfg.Servers[statuses[i].Name]
+		if !exists || !isContainerServer(srvConfig) {
+
+			continue
+		}
+		image := srvConfig.Image
+		if srvConfig.Build.Context != "" && image == "" {
+			image = fmt.Sprintf("mcp-compose-built-%s:latest", strings.ToLower(statuses[i].Identifier))
+		}
+		if statuses[i].Running {
+			if info, err := cRuntime.GetContainerInfo(stateContainer(project, statuses[i].Name, cRuntime)); err == nil && info.ImageID != "" {
+				image = info.ImageID
+			}
+		}
+		statuses[i].Security = imageSecurity(cacheFile, image, srvConfig.Security.Scan, cRuntime)
+	}
+}
+
+func imageSecurity(cacheFile, image string, scan *config.ImageScanConfig, cRuntime container.Runtime) *ImageSecurity {
+	security := &ImageSecurity{Image: image, Summary: "not scanned"}
+	info, err := cRuntime.GetImageInfo(image)
+	if err != nil {
+		security.Summary = "image not present"
+
+		return security
+	}
+	result, found := imagescan.Lookup(cacheFile, info.ID)
+	if !found {
+
+		return security
+	}
+	if scan == nil {
+		scan = &config.ImageScanConfig{}
+	}
+	scannedAt := result.ScannedAt
+	security.Scanned, security.Scanner, security.ScannedAt = true, result.Scanner, &scannedAt
+	security.Counts = result.Counts(scan.IgnoreUnfixed)
+	security.Summary = result.Summary(scan.IgnoreUnfixed)
+	failOn, warnOn := scan.Thresholds()
+	switch {
+	case result.AtLeast(failOn, scan.IgnoreUnfixed) > 0:
+		security.color = color.New(color.FgRed).SprintFunc()
+	case result.AtLeast(warnOn, scan.IgnoreUnfixed) > 0:
+		security.color = color.New(color.FgYellow).SprintFunc()
+	default:
+		security.color = color.New(color.FgGreen).SprintFunc()
+	}
+
+	return security
+}
+
+// String formats the scan for the SECURITY column of ls
+func (s *ImageSecurity) String() string {
+	if s == nil {
+
+		return "-"
+	}
+	text := s.Summary
+	if s.Scanned {
+		text += fmt.Sprintf(" (%s, %s ago)", s.Scanner, time.Since(*s.ScannedAt).Round(time.Second))
+	}
+	if s.color != nil {
+		text = s.color(text)
+	}
+
+	return text
+}
@@ -0,0 +1,490 @@
+// internal/compose/import.go
+package compose
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ImportOptions controls how another client's server definitions are converted
+type ImportOptions struct {
+	From   string // Source format; "claude-desktop"
+	Mode   string // "auto" (container when an official image is known), "process" or "container"
+	DryRun bool   // Print the servers instead of writing them
+}
+
+// claudeDesktopServer is one entry of mcpServers in claude_desktop_config.json
+type claudeDesktopServer struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	URL     string            `json:"url"`
+}
+
+// officialImages maps npm and PyPI packages of well-known MCP servers to their published images
+var officialImages = map[string]string{
+	"@modelcontextprotocol/server-filesystem":          "mcp/filesystem",
+	"@modelcontextprotocol/server-memory":              "mcp/memory",
+	"@modelcontextprotocol/server-github":              "mcp/github",
+	"@modelcontextprotocol/server-gitlab":              "mcp/gitlab",
+	"@modelcontextprotocol/server-postgres":            "mcp/postgres",
+	"@modelcontextprotocol/server-puppeteer":           "mcp/puppeteer",
+	"@modelcontextprotocol/server-brave-search":        "mcp/brave-search",
+	"@modelcontextprotocol/server-google-maps":         "mcp/google-maps",
+	"@modelcontextprotocol/server-slack":               "mcp/slack",
+	"@modelcontextprotocol/server-sequential-thinking": "mcp/sequentialthinking",
+	"@modelcontextprotocol/server-everything":          "mcp/everything",
+	"@modelcontextprotocol/server-redis":               "mcp/redis",
+	"mcp-server-fetch":                                 "mcp/fetch",
+	"mcp-server-git":                                   "mcp/git",
+	"mcp-server-time":                                  "mcp/time",
+	"mcp-server-sqlite":                                "mcp/sqlite",
+}
+
+// launcherImages run package launchers inside a container when no official image is known
+var launcherImages = map[string]string{
+	"npx": "node:22-alpine",
+	"uvx": "ghcr.io/astral-sh/uv:python3.12-alpine",
+}
+
+var (
+	invalidServerNameChars = regexp.MustCompile(`[^a-z0-9_.-]+`)
+	secretEnvName          = regexp.MustCompile(`(?i)(KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL|AUTH)`)
+)
+
+// importedServer is one converted definition with what the user should know about it
+type importedServer struct {
+	name    string
+	source  string
+	config  config.ServerConfig
+	notes   []string
+	secrets map[string]string
+}
+
+// Import converts the servers of another MCP client's configuration into the compose file,
+// creating the file when it does not exist. Servers already defined in it are left alone.
+func Import(configFile, sourcePath string, opts ImportOptions) error {
+	if opts.From != "claude-desktop" {
+
+		return fmt.Errorf("unsupported import source '%s' (supported: claude-desktop)", opts.From)
+	}
+	switch opts.Mode {
+	case "", "auto", "process", "container":
+	default:
+
+		return fmt.Errorf("unknown import mode '%s' (must be auto, process or container)", opts.Mode)
+	}
+
+	sourcePath = expandHome(sourcePath)
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+
+		return fmt.Errorf("failed to read '%s': %w", sourcePath, err)
+	}
+	var source struct {
+		MCPServers map[string]claudeDesktopServer `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &source); err != nil {
+
+		return fmt.Errorf("failed to parse '%s': %w", sourcePath, err)
+	}
+	if len(source.MCPServers) == 0 {
+
+		return fmt.Errorf("no mcpServers found in '%s'", sourcePath)
+	}
+
+	document, err := loadComposeDocument(configFile)
+	if err != nil {
+
+		return err
+	}
+	serversNode := mappingValue(document.Content[0], "servers")
+
+	names := make([]string, 0, len(source.MCPServers))
+	for name := range source.MCPServers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+
+	fmt.Printf("Importing %d server(s) from %s\n", len(names), sourcePath)
+
+	secrets := make(map[string]string)
+	imported := 0
+	for _, sourceName := range names {
+		server, err := convertClaudeDesktopServer(sourceName, source.MCPServers[sourceName], opts.Mode)
+		if err != nil {
+			fmt.Printf("[✖] Server %-30s Skipped: %v\n", sourceName, err)
+
+			continue
+		}
+		if mappingValue(serversNode, server.name) != nil {
+			fmt.Printf("[✖] Server %-30s Skipped: already defined in %s\n", server.name, configFile)
+
+			continue
+		}
+
+		var serverNode yaml.Node
+		if err := serverNode.Encode(server.config); err != nil {
+
+			return fmt.Errorf("failed to encode server '%s': %w", server.name, err)
+		}
+		serversNode.Content = append(serversNode.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: server.name}, &serverNode)
+		imported++
+
+		fmt.Printf("[✔] Server %-30s Imported as %s\n", server.name, server.source)
+		for _, note := range server.notes {
+			fmt.Printf("      - %s\n", note)
+		}
+		for key, value := range server.secrets {
+			secrets[key] = value
+		}
+	}
+
+	if imported == 0 {
+		fmt.Println("Nothing to import.")
+
+		return nil
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+
+		return fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	_ = encoder.Close()
+
+	if opts.DryRun {
+		fmt.Printf("\n# %s (dry run, not written)\n%s", configFile, out.String())
+		if len(secrets) > 0 {
+			fmt.Printf("\n# Would add to %s: %s\n", envFilePath(configFile), strings.Join(sortedKeys(secrets), ", "))
+		}
+
+		return nil
+	}
+
+	if _, _, err := config.WriteConfigFile(configFile, out.Bytes(), config.ConfigVersion{
+		Source:  config.VersionSourceCLI,
+		Message: fmt.Sprintf("import %d server(s) from %s", imported, opts.From),
+	}); err != nil {
+
+		return fmt.Errorf("failed to write '%s': %w", configFile, err)
+	}
+	if len(secrets) > 0 {
+		if err := appendDotEnv(envFilePath(configFile), secrets); err != nil {
+
+			return err
+		}
+		fmt.Printf("Moved %d secret value(s) to %s: %s\n", len(secrets), envFilePath(configFile), strings.Join(sortedKeys(secrets), ", "))
+	}
+
+	fmt.Printf("✅ Imported %d server(s) into %s. Next: mcp-compose validate && mcp-compose up\n", imported, configFile)
+
+	return nil
+}
+
+// convertClaudeDesktopServer turns one Claude Desktop entry into a compose server
+func convertClaudeDesktopServer(sourceName string, entry claudeDesktopServer, mode string) (*importedServer, error) {
+	server := &importedServer{
+		name:    sanitizeServerName(sourceName),
+		secrets: make(map[string]string),
+	}
+	if server.name != sourceName {
+		server.notes = append(server.notes, fmt.Sprintf("renamed from '%s'", sourceName))
+	}
+
+	if entry.URL != "" {
+
+		return nil, fmt.Errorf("remote server at %s is not managed by mcp-compose", entry.URL)
+	}
+	if entry.Command == "" {
+
+		return nil, fmt.Errorf("no command")
+	}
+
+	launcher := filepath.Base(entry.Command)
+	switch {
+	case launcher == "docker" && len(entry.Args) > 0 && entry.Args[0] == "run":
+		if err := convertDockerRun(server, entry.Args[1:]); err != nil {
+
+			return nil, err
+		}
+	case mode == "process":
+		convertToProcess(server, entry)
+	default:
+		pkg, pkgArgs := launcherPackage(launcher, entry.Args)
+		image, official := officialImages[pkg]
+		switch {
+		case official:
+			server.config.Image = image
+			server.config.Args = pkgArgs
+			server.source = "container " + image + " (official image for " + pkg + ")"
+		case mode == "container" && launcherImages[launcher] != "":
+			server.config.Image = launcherImages[launcher]
+			server.config.Command = launcher
+			server.config.Args = entry.Args
+			server.source = "container " + server.config.Image + " running " + launcher
+		default:
+			if mode == "container" {
+				server.notes = append(server.notes, fmt.Sprintf("no container image known for '%s'; kept as a process", entry.Command))
+			}
+			convertToProcess(server, entry)
+		}
+		if server.config.Image != "" {
+			mountPathArgs(server)
+		}
+	}
+
+	for key, value := range entry.Env {
+		if server.config.Env == nil {
+			server.config.Env = make(map[string]string)
+		}
+		if secretEnvName.MatchString(key) && !strings.HasPrefix(value, "${") {
+			server.config.Env[key] = "${" + key + "}"
+			server.secrets[key] = value
+
+			continue
+		}
+		server.config.Env[key] = value
+	}
+
+	return server, nil
+}
+
+func convertToProcess(server *importedServer, entry claudeDesktopServer) {
+	server.config.Command = entry.Command
+	server.config.Args = entry.Args
+	server.source = "process " + entry.Command
+}
+
+// convertDockerRun keeps the image, environment and volumes of a "docker run" entry
+func convertDockerRun(server *importedServer, args []string) error {
+	valueFlags := map[string]bool{
+		"--name": true, "--network": true, "-w": true, "--workdir": true, "-u": true, "--user": true,
+		"-p": true, "--publish": true, "--entrypoint": true, "--platform": true, "--mount": true,
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-e" || arg == "--env":
+			if i+1 < len(args) {
+				i++
+				key, value, hasValue := strings.Cut(args[i], "=")
+				if server.config.Env == nil {
+					server.config.Env = make(map[string]string)
+				}
+				if !hasValue || secretEnvName.MatchString(key) {
+					// Bare "-e KEY" passes the value through from Claude Desktop's env block
+					if hasValue {
+						server.secrets[key] = value
+					}
+					value = "${" + key + "}"
+				}
+				server.config.Env[key] = value
+			}
+		case arg == "-v" || arg == "--volume":
+			if i+1 < len(args) {
+				i++
+				server.config.Volumes = append(server.config.Volumes, args[i])
+			}
+		case valueFlags[arg]:
+			server.notes = append(server.notes, fmt.Sprintf("dropped docker flag %s %s", arg, args[min(i+1, len(args)-1)]))
+			i++
+		case strings.HasPrefix(arg, "-"):
+			// Flags such as -i, --rm and -t are what mcp-compose does anyway
+		default:
+			server.config.Image = arg
+			server.config.Args = args[i+1:]
+			server.source = "container " + arg
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("docker run without an image")
+}
+
+// launcherPackage finds the package an npx or uvx invocation runs and the arguments after it
+func launcherPackage(launcher string, args []string) (string, []string) {
+	if launcher != "npx" && launcher != "uvx" {
+
+		return "", args
+	}
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+
+			continue
+		}
+		pkg := arg
+		if at := strings.LastIndex(pkg, "@"); at > 0 {
+			pkg = pkg[:at]
+		}
+		pkg, _, _ = strings.Cut(pkg, "==")
+
+		return pkg, args[i+1:]
+	}
+
+	return "", nil
+}
+
+// mountPathArgs bind-mounts host paths named in a container server's arguments at the same
+// path, so servers such as filesystem see the directories they were given
+func mountPathArgs(server *importedServer) {
+	for i, arg := range server.config.Args {
+		path := expandHome(arg)
+		if !filepath.IsAbs(path) {
+
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+
+			continue
+		}
+		server.config.Args[i] = path
+		server.config.Volumes = append(server.config.Volumes, path+":"+path)
+		server.notes = append(server.notes, "mounted "+path+" into the container")
+	}
+}
+
+func sanitizeServerName(name string) string {
+	sanitized := strings.Trim(invalidServerNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+	if sanitized == "" {
+
+		return "server"
+	}
+
+	return sanitized
+}
+
+// loadComposeDocument parses the compose file as a YAML node tree so comments and ${VAR}
+// references survive the rewrite, or starts a new document when the file does not exist
+func loadComposeDocument(configFile string) (*yaml.Node, error) {
+	if source, remote := config.RemoteSource(configFile); remote {
+
+		return nil, fmt.Errorf("'%s' is fetched from a remote source and can't be edited locally", source)
+	}
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		data = []byte("version: '1'\n")
+	} else if err != nil {
+
+		return nil, fmt.Errorf("failed to read '%s': %w", configFile, err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+
+		return nil, fmt.Errorf("failed to parse '%s': %w", configFile, err)
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+
+		return nil, fmt.Errorf("'%s' is not a YAML mapping", configFile)
+	}
+	if servers := mappingValue(root, "servers"); servers == nil || servers.Kind != yaml.MappingNode {
+		if servers != nil {
+			*servers = yaml.Node{Kind: yaml.MappingNode}
+		} else {
+			root.Content = append(root.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "servers"}, &yaml.Node{Kind: yaml.MappingNode})
+		}
+	}
+
+	return &document, nil
+}
+
+// mappingValue returns the value node for a key of a YAML mapping, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func envFilePath(configFile string) string {
+
+	return filepath.Join(filepath.Dir(configFile), ".env")
+}
+
+// appendDotEnv adds variables to the .env file the compose file loads, keeping existing ones
+func appendDotEnv(path string, values map[string]string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+
+		return fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	defined := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		if key, _, found := strings.Cut(strings.TrimSpace(line), "="); found {
+			defined[strings.TrimSpace(key)] = true
+		}
+	}
+
+	var additions strings.Builder
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		additions.WriteString("\n")
+	}
+	for _, key := range sortedKeys(values) {
+		if defined[key] {
+			fmt.Printf("Keeping existing %s in %s\n", key, path)
+
+			continue
+		}
+		additions.WriteString(key + "=" + values[key] + "\n")
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, constants.SecretFileMode)
+	if err != nil {
+
+		return fmt.Errorf("failed to open '%s': %w", path, err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(additions.String()); err != nil {
+
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,368 @@
+// internal/compose/init.go
+package compose
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/registry"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InitOptions controls what mcp-compose init generates. Anything left unset is asked for
+// interactively unless AcceptDefaults is set.
+type InitOptions struct {
+	Servers        []string // well-known servers to include
+	Directory      string   // host directory the filesystem server exposes
+	NoAuth         bool     // leave the proxy without an API key
+	Clients        []string // client configurations to generate: claude, cursor, vscode
+	AcceptDefaults bool     // never prompt
+	Force          bool     // overwrite an existing compose file
+	In             io.Reader
+}
+
+const initNetwork = "mcp-net"
+
+// initFeatured are the registry servers init offers by number
+var initFeatured = []string{"filesystem", "memory", "fetch", "github", "time", "sequential-thinking", "brave-search"}
+
+var initDefaultServers = []string{"filesystem", "memory", "fetch"}
+
+// initClients are the client-config formats init can write
+var initClients = []string{"claude", "cursor", "vscode"}
+
+// initDocument is the layout of the generated compose file
+type initDocument struct {
+	Version   string                          `yaml:"version"`
+	ProxyAuth *config.ProxyAuthConfig         `yaml:"proxy_auth,omitempty"`
+	Servers   map[string]config.ServerConfig  `yaml:"servers"`
+	Networks  map[string]config.NetworkConfig `yaml:"networks,omitempty"`
+	Volumes   map[string]config.VolumeConfig  `yaml:"volumes,omitempty"`
+}
+
+// Init scaffolds a new compose file from registry servers, asking what to include
+func Init(configFile string, opts InitOptions) error {
+	if _, err := os.Stat(configFile); err == nil && !opts.Force {
+
+		return fmt.Errorf("'%s' already exists; use --force to overwrite it or 'mcp-compose import' to add servers", configFile)
+	}
+
+	in := opts.In
+	if in == nil {
+		in = os.Stdin
+	}
+	p := &prompter{reader: bufio.NewReader(in), acceptDefaults: opts.AcceptDefaults}
+
+	selected, err := selectInitServers(p, registry.Embedded(), opts.Servers)
+	if err != nil {
+
+		return err
+	}
+
+	doc := initDocument{
+		Version:  "1",
+		Servers:  make(map[string]config.ServerConfig),
+		Networks: map[string]config.NetworkConfig{initNetwork: {Driver: "bridge"}},
+	}
+	secrets := make(map[string]string)
+
+	for _, entry := range selected {
+		workspaceDir := ""
+		if entry.Workspace != "" {
+			directory := opts.Directory
+			if directory == "" {
+				cwd, _ := os.Getwd()
+				directory = p.ask(fmt.Sprintf("Directory to expose to the %s server", entry.Name), cwd)
+			}
+			if workspaceDir, err = filepath.Abs(expandHome(directory)); err != nil {
+
+				return fmt.Errorf("invalid directory '%s': %w", directory, err)
+			}
+		}
+		server := entry.ServerConfig(workspaceDir)
+		server.Networks = []string{initNetwork}
+		for _, volume := range entry.NamedVolumes() {
+			if doc.Volumes == nil {
+				doc.Volumes = make(map[string]config.VolumeConfig)
+			}
+			doc.Volumes[volume] = config.VolumeConfig{Driver: "local"}
+		}
+		for _, key := range entry.Secrets {
+			if value := p.ask(fmt.Sprintf("%s for %s (leave empty to set it later in .env)", key, entry.Name), ""); value != "" {
+				secrets[key] = value
+			}
+		}
+		doc.Servers[entry.Name] = server
+	}
+
+	apiKey := ""
+	if !opts.NoAuth && p.confirm("Protect the proxy with an API key?", true) {
+		doc.ProxyAuth = &config.ProxyAuthConfig{Enabled: true, APIKey: "${MCP_API_KEY}"}
+		if apiKey = dotEnvValue(envFilePath(configFile), "MCP_API_KEY"); apiKey == "" {
+			if apiKey, err = generateAPIKey(); err != nil {
+
+				return err
+			}
+			secrets["MCP_API_KEY"] = apiKey
+		}
+	}
+
+	clients := opts.Clients
+	if clients == nil {
+		answer := p.ask(fmt.Sprintf("Generate client configuration for (%s, or none)", strings.Join(initClients, ", ")), "none")
+		clients = splitList(answer)
+	}
+	for _, client := range clients {
+		if client != "none" && !containsString(initClients, client) {
+
+			return fmt.Errorf("unknown client '%s' (supported: %s)", client, strings.Join(initClients, ", "))
+		}
+	}
+
+	data, err := renderInitDocument(doc)
+	if err != nil {
+
+		return err
+	}
+	if err := os.WriteFile(configFile, data, constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write '%s': %w", configFile, err)
+	}
+	fmt.Printf("[✔] Wrote %s with %d server(s): %s\n", configFile, len(selected), strings.Join(entryNames(selected), ", "))
+
+	if len(secrets) > 0 {
+		if err := appendDotEnv(envFilePath(configFile), secrets); err != nil {
+
+			return err
+		}
+		fmt.Printf("[✔] Stored %s in %s\n", strings.Join(sortedKeys(secrets), ", "), envFilePath(configFile))
+	}
+
+	outputDir := filepath.Join(filepath.Dir(configFile), "client-configs")
+	for _, client := range clients {
+		if client == "none" {
+
+			continue
+		}
+		path, err := writeInitClientSnippet(client, outputDir, entryNames(selected), apiKey)
+		if err != nil {
+
+			return err
+		}
+		fmt.Printf("[✔] Wrote %s configuration to %s\n", client, path)
+	}
+
+	for _, entry := range selected {
+		for _, key := range entry.Secrets {
+			if _, provided := secrets[key]; !provided && dotEnvValue(envFilePath(configFile), key) == "" {
+				fmt.Printf("Note: set %s in %s before starting %s\n", key, envFilePath(configFile), entry.Name)
+			}
+		}
+	}
+	fmt.Println("✅ Next: mcp-compose up && mcp-compose proxy")
+
+	return nil
+}
+
+// selectInitServers resolves the requested registry servers, asking for them when none were given
+func selectInitServers(p *prompter, reg *registry.Registry, requested []string) ([]registry.Entry, error) {
+	if len(requested) == 0 && !p.acceptDefaults {
+		fmt.Println("Available MCP servers (any name from 'mcp-compose search' works too):")
+		for i, name := range initFeatured {
+			entry, _ := reg.Get(name)
+			fmt.Printf("  %d) %-20s %s\n", i+1, entry.Name, entry.Description)
+		}
+	}
+	if len(requested) == 0 {
+		answer := p.ask("Servers to include (numbers or names, comma-separated)", strings.Join(initDefaultServers, ","))
+		requested = splitList(answer)
+	}
+
+	var selected []registry.Entry
+	seen := make(map[string]bool)
+	for _, choice := range requested {
+		if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(initFeatured) {
+			choice = initFeatured[n-1]
+		}
+		entry, exists := reg.Get(choice)
+		if !exists {
+
+			return nil, fmt.Errorf("unknown server '%s' (see 'mcp-compose search')", choice)
+		}
+		if !seen[entry.Name] {
+			seen[entry.Name] = true
+			selected = append(selected, entry)
+		}
+	}
+	if len(selected) == 0 {
+
+		return nil, fmt.Errorf("no servers selected")
+	}
+
+	return selected, nil
+}
+
+// renderInitDocument encodes the compose file and checks it passes validation
+func renderInitDocument(doc initDocument) ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString("# Generated by mcp-compose init\n")
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+
+		return nil, fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	_ = encoder.Close()
+
+	var generated config.ComposeConfig
+	if err := yaml.Unmarshal(out.Bytes(), &generated); err != nil {
+
+		return nil, fmt.Errorf("failed to parse generated compose file: %w", err)
+	}
+	if err := config.ValidateConfig(&generated); err != nil {
+
+		return nil, fmt.Errorf("generated compose file is invalid: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
+
+// writeInitClientSnippet writes a client configuration that reaches the servers through the proxy
+func writeInitClientSnippet(format, outputDir string, servers []string, apiKey string) (string, error) {
+	if err := os.MkdirAll(outputDir, constants.DefaultDirMode); err != nil {
+
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	endpoints := make([]clientEndpoint, 0, len(servers))
+	for _, name := range servers {
+		endpoints = append(endpoints, clientEndpoint{
+			name: name,
+			url:  fmt.Sprintf("http://localhost:%d/%s", constants.DefaultProxyPort, name),
+		})
+	}
+	data, err := renderClientConfig(format, endpoints, clientCredentials{apiKey: apiKey})
+	if err != nil {
+
+		return "", err
+	}
+	path := filepath.Join(outputDir, format+".json")
+	if err := os.WriteFile(path, data, constants.SecretFileMode); err != nil {
+
+		return "", fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+
+	return path, nil
+}
+
+// prompter asks questions on the terminal, falling back to defaults when input ends
+type prompter struct {
+	reader         *bufio.Reader
+	acceptDefaults bool
+}
+
+func (p *prompter) ask(question, defaultValue string) string {
+	if p.acceptDefaults {
+
+		return defaultValue
+	}
+	if defaultValue != "" {
+		fmt.Printf("%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	line, err := p.reader.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+		p.acceptDefaults = true
+
+		return defaultValue
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+
+		return answer
+	}
+
+	return defaultValue
+}
+
+func (p *prompter) confirm(question string, defaultValue bool) bool {
+	hint := "y/N"
+	if defaultValue {
+		hint = "Y/n"
+	}
+	answer := strings.ToLower(p.ask(question+" ("+hint+")", ""))
+	if answer == "" {
+
+		return defaultValue
+	}
+
+	return answer == "y" || answer == "yes"
+}
+
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// dotEnvValue returns a variable already set in a .env file
+func dotEnvValue(path, key string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if k, v, found := strings.Cut(strings.TrimSpace(line), "="); found && strings.TrimSpace(k) == key {
+
+			return strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+
+	return ""
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		items = append(items, strings.TrimSpace(item))
+	}
+
+	return items
+}
+
+func entryNames(entries []registry.Entry) []string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+
+	return names
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+
+			return true
+		}
+	}
+
+	return false
+}
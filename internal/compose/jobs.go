@@ -0,0 +1,192 @@
+// internal/compose/jobs.go
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/server"
+)
+
+// ListInitJobs prints how the init jobs of the named servers, or of every server with
+// any, went the last time they ran
+func ListInitJobs(configFile string, serverNames []string, jsonOutput bool) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	names, err := initJobServers(cfg, serverNames)
+	if err != nil {
+
+		return err
+	}
+
+	stateDir := server.InitStateDir(configFile)
+	results := []server.InitJobResult{}
+	for _, name := range names {
+		recorded, err := server.LoadInitResults(stateDir, name)
+		if err != nil {
+
+			return err
+		}
+		ran := make(map[string]bool, len(recorded))
+		for _, result := range recorded {
+			ran[result.Job] = true
+		}
+		results = append(results, recorded...)
+		// Jobs after a failed one, or added since, have not run
+		for _, job := range cfg.Servers[name].Init {
+			if !ran[job.Name] {
+				results = append(results, server.InitJobResult{Server: name, Job: job.Name, ExitCode: -1})
+			}
+		}
+	}
+	if jsonOutput {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+
+			return fmt.Errorf("failed to encode init jobs: %w", err)
+		}
+		fmt.Println(string(data))
+
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Println("No server has init jobs.")
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, constants.TableColumnSpacing, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERVER\tJOB\tSTATUS\tEXIT\tFINISHED\tDURATION")
+	for _, result := range results {
+		status, exit, finished, duration := "not run", "-", "-", "-"
+		if !result.StartedAt.IsZero() {
+			status = "succeeded"
+			if !result.Succeeded() {
+				status = "failed"
+			}
+			if result.ExitCode >= 0 {
+				exit = fmt.Sprint(result.ExitCode)
+			}
+			finished = result.FinishedAt.Local().Format(time.DateTime)
+			duration = ShortDuration(time.Duration(result.DurationMS) * time.Millisecond)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", result.Server, result.Job, status, exit, finished, duration)
+	}
+
+	return w.Flush()
+}
+
+// ShowInitJobLog prints what an init job printed the last time it ran
+func ShowInitJobLog(configFile, serverName, jobName string) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	srvCfg, exists := cfg.Servers[serverName]
+	if !exists {
+
+		return fmt.Errorf("server '%s' not found in config", serverName)
+	}
+	var jobs []string
+	for _, job := range srvCfg.Init {
+		jobs = append(jobs, job.Name)
+	}
+	if !slices.Contains(jobs, jobName) {
+
+		return fmt.Errorf("server '%s' has no init job '%s'; its jobs are: %s", serverName, jobName, strings.Join(jobs, ", "))
+	}
+
+	data, err := os.ReadFile(filepath.Join(server.InitStateDir(configFile), fmt.Sprintf("%s.%s.log", serverName, jobName)))
+	if os.IsNotExist(err) {
+
+		return fmt.Errorf("init job '%s' of server '%s' has not run yet", jobName, serverName)
+	}
+	if err != nil {
+
+		return fmt.Errorf("failed to read the log of init job '%s': %w", jobName, err)
+	}
+	_, _ = os.Stdout.Write(data)
+
+	return nil
+}
+
+// RunInitJobs runs the init jobs of the named servers, or of every server with any, without
+// starting the servers
+func RunInitJobs(configFile string, serverNames []string) error {
+	cfg, err := loadLockedConfig(configFile)
+	if err != nil {
+
+		return err
+	}
+	names, err := initJobServers(cfg, serverNames)
+	if err != nil {
+
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("No server has init jobs.")
+
+		return nil
+	}
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+
+		return fmt.Errorf("failed to detect container runtime: %w", err)
+	}
+
+	runner := server.InitRunner{
+		Config:   cfg,
+		Runtime:  cRuntime,
+		StateDir: server.InitStateDir(configFile),
+		Dir:      filepath.Dir(configFile),
+		Out:      os.Stdout,
+	}
+	var failed []string
+	for _, name := range names {
+		if err := runner.Run(name); err != nil {
+			fmt.Printf("❌ %s: %v\n", name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+
+		return fmt.Errorf("init jobs failed for %d server(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// initJobServers returns the named servers, or every server with init jobs, sorted
+func initJobServers(cfg *config.ComposeConfig, serverNames []string) ([]string, error) {
+	if len(serverNames) > 0 {
+		for _, name := range serverNames {
+			if _, exists := cfg.Servers[name]; !exists {
+
+				return nil, fmt.Errorf("server '%s' not found in config", name)
+			}
+		}
+
+		return serverNames, nil
+	}
+	var names []string
+	for _, name := range sortedServerNames(cfg) {
+		if len(cfg.Servers[name].Init) > 0 {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
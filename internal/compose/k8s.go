@@ -0,0 +1,1055 @@
+// internal/compose/k8s.go
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// K8sOptions controls how the config is converted to Kubernetes manifests
+type K8sOptions struct {
+	Namespace        string // namespace of every object; created when set
+	OutputDir        string // write one file per server instead of printing the manifests
+	ProxyImage       string // image the proxy Deployment runs
+	ProxyPort        int
+	IngressHost      string
+	IngressClass     string
+	IngressTLSSecret string // TLS secret for IngressHost
+	VolumeSize       string // storage requested by each named volume's claim
+}
+
+// Names and labels of the generated objects
+const (
+	k8sProxyName     = constants.EgressGatewayHost // servers reach the proxy and its gateway by this name, as under Docker
+	k8sConfigMapName = "mcp-compose-config"
+	k8sNameLabel     = "app.kubernetes.io/name"
+	k8sPartOfLabel   = "app.kubernetes.io/part-of"
+	k8sManagedLabel  = "app.kubernetes.io/managed-by"
+	k8sNetworkLabel  = "network.mcp-compose.io/"
+	k8sDefaultImage  = "mcp-compose-go-http-proxy:latest"
+	k8sDefaultVolume = "1Gi"
+)
+
+// k8sObject is any Kubernetes object the generator writes
+type k8sObject struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMeta           `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+	Spec       interface{}       `yaml:"spec,omitempty"`
+}
+
+type k8sMeta struct {
+	Name        string            `yaml:"name,omitempty"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type k8sSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels,omitempty"`
+}
+
+type k8sDeploymentSpec struct {
+	Replicas int            `yaml:"replicas"`
+	Selector k8sSelector    `yaml:"selector"`
+	Template k8sPodTemplate `yaml:"template"`
+}
+
+type k8sPodTemplate struct {
+	Metadata k8sMeta    `yaml:"metadata"`
+	Spec     k8sPodSpec `yaml:"spec"`
+}
+
+type k8sPodSpec struct {
+	Hostname                      string          `yaml:"hostname,omitempty"`
+	TerminationGracePeriodSeconds *int            `yaml:"terminationGracePeriodSeconds,omitempty"`
+	HostAliases                   []k8sHostAlias  `yaml:"hostAliases,omitempty"`
+	DNSConfig                     *k8sDNSConfig   `yaml:"dnsConfig,omitempty"`
+	InitContainers                []k8sContainer  `yaml:"initContainers,omitempty"`
+	Containers                    []k8sContainer  `yaml:"containers"`
+	Volumes                       []k8sPodVolume  `yaml:"volumes,omitempty"`
+	SecurityContext               *k8sPodSecurity `yaml:"securityContext,omitempty"`
+}
+
+type k8sHostAlias struct {
+	IP        string   `yaml:"ip"`
+	Hostnames []string `yaml:"hostnames"`
+}
+
+type k8sDNSConfig struct {
+	Nameservers []string `yaml:"nameservers,omitempty"`
+	Searches    []string `yaml:"searches,omitempty"`
+}
+
+type k8sPodSecurity struct {
+	SupplementalGroups []int64 `yaml:"supplementalGroups,omitempty"`
+}
+
+type k8sContainer struct {
+	Name            string              `yaml:"name"`
+	Image           string              `yaml:"image"`
+	ImagePullPolicy string              `yaml:"imagePullPolicy,omitempty"`
+	Args            []string            `yaml:"args,omitempty"`
+	WorkingDir      string              `yaml:"workingDir,omitempty"`
+	Ports           []k8sContainerPort  `yaml:"ports,omitempty"`
+	Env             []k8sEnvVar         `yaml:"env,omitempty"`
+	EnvFrom         []k8sEnvFrom        `yaml:"envFrom,omitempty"`
+	Resources       *k8sResources       `yaml:"resources,omitempty"`
+	SecurityContext *k8sSecurityContext `yaml:"securityContext,omitempty"`
+	VolumeMounts    []k8sVolumeMount    `yaml:"volumeMounts,omitempty"`
+	LivenessProbe   *k8sProbe           `yaml:"livenessProbe,omitempty"`
+	ReadinessProbe  *k8sProbe           `yaml:"readinessProbe,omitempty"`
+}
+
+type k8sContainerPort struct {
+	ContainerPort int    `yaml:"containerPort"`
+	Protocol      string `yaml:"protocol,omitempty"`
+}
+
+type k8sEnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type k8sEnvFrom struct {
+	ConfigMapRef *k8sNameRef `yaml:"configMapRef,omitempty"`
+	SecretRef    *k8sNameRef `yaml:"secretRef,omitempty"`
+}
+
+type k8sNameRef struct {
+	Name string `yaml:"name"`
+}
+
+type k8sResources struct {
+	Limits   map[string]string `yaml:"limits,omitempty"`
+	Requests map[string]string `yaml:"requests,omitempty"`
+}
+
+type k8sSecurityContext struct {
+	RunAsUser                *int64           `yaml:"runAsUser,omitempty"`
+	RunAsGroup               *int64           `yaml:"runAsGroup,omitempty"`
+	ReadOnlyRootFilesystem   bool             `yaml:"readOnlyRootFilesystem,omitempty"`
+	Privileged               bool             `yaml:"privileged,omitempty"`
+	AllowPrivilegeEscalation *bool            `yaml:"allowPrivilegeEscalation,omitempty"`
+	Capabilities             *k8sCapabilities `yaml:"capabilities,omitempty"`
+}
+
+type k8sCapabilities struct {
+	Add  []string `yaml:"add,omitempty"`
+	Drop []string `yaml:"drop,omitempty"`
+}
+
+type k8sVolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath,omitempty"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type k8sPodVolume struct {
+	Name                  string           `yaml:"name"`
+	PersistentVolumeClaim *k8sClaimRef     `yaml:"persistentVolumeClaim,omitempty"`
+	EmptyDir              *k8sEmptyDir     `yaml:"emptyDir,omitempty"`
+	ConfigMap             *k8sConfigMapRef `yaml:"configMap,omitempty"`
+	Secret                *k8sSecretRef    `yaml:"secret,omitempty"`
+}
+
+type k8sClaimRef struct {
+	ClaimName string `yaml:"claimName"`
+}
+
+type k8sEmptyDir struct {
+	Medium    string `yaml:"medium,omitempty"`
+	SizeLimit string `yaml:"sizeLimit,omitempty"`
+}
+
+type k8sConfigMapRef struct {
+	Name string `yaml:"name"`
+}
+
+type k8sSecretRef struct {
+	SecretName string `yaml:"secretName"`
+}
+
+type k8sProbe struct {
+	Exec                *k8sExecAction `yaml:"exec,omitempty"`
+	HTTPGet             *k8sHTTPGet    `yaml:"httpGet,omitempty"`
+	TCPSocket           *k8sTCPSocket  `yaml:"tcpSocket,omitempty"`
+	InitialDelaySeconds int            `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int            `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds      int            `yaml:"timeoutSeconds,omitempty"`
+	FailureThreshold    int            `yaml:"failureThreshold,omitempty"`
+}
+
+type k8sExecAction struct {
+	Command []string `yaml:"command"`
+}
+
+type k8sHTTPGet struct {
+	Path string `yaml:"path"`
+	Port int    `yaml:"port"`
+}
+
+type k8sTCPSocket struct {
+	Port int `yaml:"port"`
+}
+
+type k8sServiceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []k8sServicePort  `yaml:"ports"`
+}
+
+type k8sServicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort int    `yaml:"targetPort"`
+	Protocol   string `yaml:"protocol,omitempty"`
+}
+
+type k8sClaimSpec struct {
+	AccessModes []string     `yaml:"accessModes"`
+	Resources   k8sResources `yaml:"resources"`
+}
+
+type k8sNetworkPolicySpec struct {
+	PodSelector k8sSelector  `yaml:"podSelector"`
+	PolicyTypes []string     `yaml:"policyTypes"`
+	Ingress     []k8sNetRule `yaml:"ingress,omitempty"`
+	Egress      []k8sNetRule `yaml:"egress,omitempty"`
+}
+
+type k8sNetRule struct {
+	From  []k8sNetPeer `yaml:"from,omitempty"`
+	To    []k8sNetPeer `yaml:"to,omitempty"`
+	Ports []k8sNetPort `yaml:"ports,omitempty"`
+}
+
+type k8sNetPeer struct {
+	PodSelector *k8sSelector `yaml:"podSelector,omitempty"`
+	IPBlock     *k8sIPBlock  `yaml:"ipBlock,omitempty"`
+}
+
+type k8sIPBlock struct {
+	CIDR string `yaml:"cidr"`
+}
+
+type k8sNetPort struct {
+	Protocol string `yaml:"protocol"`
+	Port     int    `yaml:"port,omitempty"`
+}
+
+type k8sIngressSpec struct {
+	IngressClassName string           `yaml:"ingressClassName,omitempty"`
+	TLS              []k8sIngressTLS  `yaml:"tls,omitempty"`
+	Rules            []k8sIngressRule `yaml:"rules"`
+}
+
+type k8sIngressTLS struct {
+	Hosts      []string `yaml:"hosts"`
+	SecretName string   `yaml:"secretName"`
+}
+
+type k8sIngressRule struct {
+	Host string         `yaml:"host,omitempty"`
+	HTTP k8sIngressHTTP `yaml:"http"`
+}
+
+type k8sIngressHTTP struct {
+	Paths []k8sIngressPath `yaml:"paths"`
+}
+
+type k8sIngressPath struct {
+	Path     string            `yaml:"path"`
+	PathType string            `yaml:"pathType"`
+	Backend  k8sIngressBackend `yaml:"backend"`
+}
+
+type k8sIngressBackend struct {
+	Service k8sIngressService `yaml:"service"`
+}
+
+type k8sIngressService struct {
+	Name string                `yaml:"name"`
+	Port k8sIngressServicePort `yaml:"port"`
+}
+
+type k8sIngressServicePort struct {
+	Number int `yaml:"number"`
+}
+
+// GenerateK8s converts the config into Kubernetes manifests: for each container server a
+// Deployment, a Service, a ConfigMap and a Secret with its environment, claims for its
+// named volumes and a NetworkPolicy keeping the isolation its networks give it under
+// Docker; and for the proxy a Deployment running it with the compose file from a ConfigMap,
+// a Service and an Ingress.
+// Warnings go to stderr so the printed manifests can be redirected to a file.
+func GenerateK8s(configFile string, serverNames []string, opts K8sOptions) error {
+	cfg, err := loadLockedConfig(configFile)
+	if err != nil {
+
+		return err
+	}
+	if opts.ProxyImage == "" {
+		opts.ProxyImage = k8sDefaultImage
+	}
+	if opts.ProxyPort == 0 {
+		opts.ProxyPort = constants.DefaultProxyPort
+	}
+	if opts.VolumeSize == "" {
+		opts.VolumeSize = k8sDefaultVolume
+	}
+	// The proxy listens on every interface of its pod and refuses to start unless exposed
+	if err := cfg.CheckProxyExposure(constants.DefaultHostInterface, "", false); err != nil {
+
+		return err
+	}
+	g := &k8sGenerator{cfg: cfg, opts: opts, project: k8sName(projectName(configFile)), claims: make(map[string]bool)}
+
+	var files []k8sFile
+	if opts.Namespace != "" {
+		files = append(files, k8sFile{name: "namespace.yaml", objects: []k8sObject{{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Metadata:   k8sMeta{Name: opts.Namespace, Labels: map[string]string{k8sManagedLabel: "mcp-compose"}},
+		}}})
+	}
+	for _, name := range getServersToStart(cfg, serverNames) {
+		serverCfg, exists := cfg.Servers[name]
+		if !exists {
+
+			return fmt.Errorf("server '%s' not found in config", name)
+		}
+		if serverCfg.Image == "" && serverCfg.Build.Context == "" {
+			fmt.Fprintf(os.Stderr, "Skipping '%s': process servers need an image to run on Kubernetes\n", name)
+
+			continue
+		}
+		objects, err := g.serverObjects(name, serverCfg)
+		if err != nil {
+
+			return err
+		}
+		files = append(files, k8sFile{name: k8sName(name) + ".yaml", objects: objects})
+	}
+	proxyObjects, err := g.proxyObjects(configFile)
+	if err != nil {
+
+		return err
+	}
+	files = append(files, k8sFile{name: "proxy.yaml", objects: proxyObjects})
+
+	if opts.OutputDir == "" {
+		for _, file := range files {
+			data, err := file.marshal()
+			if err != nil {
+
+				return err
+			}
+			fmt.Print(string(data))
+		}
+
+		return nil
+	}
+	if err := os.MkdirAll(opts.OutputDir, constants.DefaultDirMode); err != nil {
+
+		return fmt.Errorf("failed to create %s: %w", opts.OutputDir, err)
+	}
+	for _, file := range files {
+		data, err := file.marshal()
+		if err != nil {
+
+			return err
+		}
+		mode := os.FileMode(constants.DefaultFileMode)
+		if file.hasSecret() {
+			mode = constants.SecretFileMode
+		}
+		path := filepath.Join(opts.OutputDir, file.name)
+		if err := os.WriteFile(path, data, mode); err != nil {
+
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	fmt.Printf("\nApply them with:\n  kubectl apply -f %s\n", opts.OutputDir)
+
+	return nil
+}
+
+// k8sFile is one manifest file of several objects
+type k8sFile struct {
+	name    string
+	objects []k8sObject
+}
+
+func (f k8sFile) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	for _, object := range f.objects {
+		buf.WriteString("---\n")
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(object); err != nil {
+
+			return nil, fmt.Errorf("failed to encode %s %s: %w", object.Kind, object.Metadata.Name, err)
+		}
+		if err := encoder.Close(); err != nil {
+
+			return nil, fmt.Errorf("failed to encode %s %s: %w", object.Kind, object.Metadata.Name, err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (f k8sFile) hasSecret() bool {
+	for _, object := range f.objects {
+		if object.Kind == "Secret" {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// k8sGenerator renders the objects of one config
+type k8sGenerator struct {
+	cfg     *config.ComposeConfig
+	opts    K8sOptions
+	project string
+	claims  map[string]bool // named volumes whose claim has been written
+}
+
+// meta returns the metadata of an object, labelled as part of the project
+func (g *k8sGenerator) meta(name string, labels map[string]string) k8sMeta {
+	all := map[string]string{
+		k8sPartOfLabel:  g.project,
+		k8sManagedLabel: "mcp-compose",
+	}
+	for key, value := range labels {
+		all[key] = value
+	}
+
+	return k8sMeta{Name: name, Namespace: g.opts.Namespace, Labels: all}
+}
+
+// serverObjects renders the objects of a container server
+func (g *k8sGenerator) serverObjects(name string, serverCfg config.ServerConfig) ([]k8sObject, error) {
+	opts := convertSecurityConfig(g.cfg, name, serverCfg)
+	applyTransportEnv(serverCfg, opts.Env)
+	// The egress gateway finds its clients through the container runtime, so on Kubernetes
+	// the NetworkPolicy restricts egress instead
+	for key := range g.cfg.EgressEnv(name) {
+		delete(opts.Env, key)
+	}
+	if opts.Image == "" {
+		opts.Image = fmt.Sprintf("mcp-compose-built-%s:latest", strings.ToLower(opts.Name))
+		fmt.Fprintf(os.Stderr, "Note: '%s' runs the image built from %s; tag it as %s and push it where the cluster can pull it\n",
+			name, serverCfg.Build.Context, opts.Image)
+	}
+	if serverCfg.StdioHosterPort == 0 && serverCfg.Protocol != "http" && serverCfg.Protocol != "sse" && serverCfg.HttpPort == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: '%s' speaks stdio, which the proxy reaches through the container runtime; set stdio_hoster_port or serve http for Kubernetes\n", name)
+	}
+
+	objectName := k8sName(opts.Name)
+	selector := map[string]string{k8sNameLabel: objectName}
+	podLabels := map[string]string{k8sNameLabel: objectName}
+	for _, network := range g.cfg.ServerNetworks(name) {
+		podLabels[k8sNetworkLabel+k8sName(network)] = "true"
+	}
+
+	c := k8sContainer{
+		Name:            k8sName(name),
+		Image:           opts.Image,
+		ImagePullPolicy: k8sPullPolicy(serverCfg.PullPolicy, serverCfg.Pull),
+		WorkingDir:      opts.WorkDir,
+	}
+	if opts.Command != "" {
+		c.Args = append([]string{opts.Command}, opts.Args...)
+	}
+	var objects []k8sObject
+
+	plain, secret := make(map[string]string), make(map[string]string)
+	for key, value := range opts.Env {
+		if config.IsSecretEnv(key, value) {
+			secret[key] = value
+		} else {
+			plain[key] = value
+		}
+	}
+	envName := objectName + "-env"
+	if len(plain) > 0 {
+		objects = append(objects, k8sObject{APIVersion: "v1", Kind: "ConfigMap", Metadata: g.meta(envName, nil), Data: plain})
+		c.EnvFrom = append(c.EnvFrom, k8sEnvFrom{ConfigMapRef: &k8sNameRef{Name: envName}})
+	}
+	if len(secret) > 0 {
+		objects = append(objects, k8sObject{APIVersion: "v1", Kind: "Secret", Metadata: g.meta(envName, nil), Type: "Opaque", StringData: secret})
+		c.EnvFrom = append(c.EnvFrom, k8sEnvFrom{SecretRef: &k8sNameRef{Name: envName}})
+	}
+
+	ports := k8sPorts(serverCfg)
+	for _, port := range ports {
+		c.Ports = append(c.Ports, k8sContainerPort{ContainerPort: port.TargetPort, Protocol: port.Protocol})
+	}
+	c.Resources = k8sResourceLimits(name, serverCfg)
+	security, supplementalGroups := k8sSecurity(name, &opts)
+	c.SecurityContext = security
+	c.LivenessProbe = k8sLivenessProbe(serverCfg)
+
+	pod := k8sPodSpec{Hostname: opts.Hostname, TerminationGracePeriodSeconds: opts.StopTimeout}
+	for _, host := range opts.ExtraHosts {
+		hostname, ip, found := strings.Cut(host, ":")
+		if found {
+			pod.HostAliases = append(pod.HostAliases, k8sHostAlias{IP: ip, Hostnames: []string{hostname}})
+		}
+	}
+	if len(opts.DNS) > 0 || len(opts.DNSSearch) > 0 {
+		pod.DNSConfig = &k8sDNSConfig{Nameservers: opts.DNS, Searches: opts.DNSSearch}
+	}
+	if len(supplementalGroups) > 0 {
+		pod.SecurityContext = &k8sPodSecurity{SupplementalGroups: supplementalGroups}
+	}
+
+	objects = append(objects, g.volumes(name, &opts, &c, &pod)...)
+
+	pod.InitContainers = k8sInitContainers(name, serverCfg, c)
+	pod.Containers = []k8sContainer{c}
+	replicas := serverCfg.Deploy.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+	annotations := config.MergeEnv(opts.Labels, serverCfg.Annotations)
+	objects = append(objects, k8sObject{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   g.meta(objectName, selector),
+		Spec: k8sDeploymentSpec{
+			Replicas: replicas,
+			Selector: k8sSelector{MatchLabels: selector},
+			Template: k8sPodTemplate{
+				Metadata: k8sMeta{Labels: podLabels, Annotations: annotations},
+				Spec:     pod,
+			},
+		},
+	})
+	if len(ports) > 0 {
+		objects = append(objects, k8sObject{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Metadata:   g.meta(objectName, selector),
+			Spec:       k8sServiceSpec{Selector: selector, Ports: ports},
+		})
+	}
+	objects = append(objects, g.networkPolicy(name, serverCfg, objectName, selector))
+
+	return objects, nil
+}
+
+// volumes mounts the server's named volumes from claims and its tmpfs mounts from
+// memory-backed empty dirs, returning the claims not written yet. Bind mounts have no
+// portable equivalent and are left out.
+func (g *k8sGenerator) volumes(name string, opts *container.ContainerOptions, c *k8sContainer, pod *k8sPodSpec) []k8sObject {
+	var claims []k8sObject
+	for _, volume := range opts.Volumes {
+		source, rest, hasTarget := strings.Cut(volume, ":")
+		if !hasTarget {
+
+			continue
+		}
+		target, mode, _ := strings.Cut(rest, ":")
+		if source == "" || strings.ContainsAny(source[:1], "/.~$") || strings.Contains(source, "/") {
+			fmt.Fprintf(os.Stderr, "Warning: '%s' bind mounts %s, which is left out; mount a named volume for Kubernetes\n", name, source)
+
+			continue
+		}
+		if volumeCfg, exists := g.cfg.Volumes[source]; exists && volumeCfg.External {
+			fmt.Fprintf(os.Stderr, "Note: '%s' mounts external volume '%s'; create the claim %s before applying\n", name, source, k8sName(source))
+		} else if !g.claims[source] {
+			g.claims[source] = true
+			claims = append(claims, k8sObject{
+				APIVersion: "v1",
+				Kind:       "PersistentVolumeClaim",
+				Metadata:   g.meta(k8sName(source), nil),
+				Spec: k8sClaimSpec{
+					AccessModes: []string{"ReadWriteOnce"},
+					Resources:   k8sResources{Requests: map[string]string{"storage": g.opts.VolumeSize}},
+				},
+			})
+		}
+		volumeName := k8sName(source)
+		c.VolumeMounts = append(c.VolumeMounts, k8sVolumeMount{Name: volumeName, MountPath: target, ReadOnly: mode == "ro"})
+		pod.Volumes = append(pod.Volumes, k8sPodVolume{Name: volumeName, PersistentVolumeClaim: &k8sClaimRef{ClaimName: volumeName}})
+	}
+	for i, tmpfs := range opts.Tmpfs {
+		target, options, _ := strings.Cut(tmpfs, ":")
+		emptyDir := &k8sEmptyDir{Medium: "Memory"}
+		for _, option := range strings.Split(options, ",") {
+			if size, found := strings.CutPrefix(option, "size="); found {
+				emptyDir.SizeLimit = k8sQuantity(size)
+			}
+		}
+		volumeName := fmt.Sprintf("tmpfs-%d", i)
+		c.VolumeMounts = append(c.VolumeMounts, k8sVolumeMount{Name: volumeName, MountPath: target})
+		pod.Volumes = append(pod.Volumes, k8sPodVolume{Name: volumeName, EmptyDir: emptyDir})
+	}
+
+	return claims
+}
+
+// networkPolicy admits traffic to a server from the proxy and the servers it shares a
+// network with, the way its Docker networks do. Restricted egress becomes an egress policy
+// allowing DNS, those servers, the proxy and the IP and CIDR rules; host name rules can't be
+// expressed in a NetworkPolicy.
+func (g *k8sGenerator) networkPolicy(name string, serverCfg config.ServerConfig, objectName string, selector map[string]string) k8sObject {
+	peers := []k8sNetPeer{{PodSelector: &k8sSelector{MatchLabels: map[string]string{k8sNameLabel: k8sProxyName}}}}
+	for _, network := range g.cfg.ServerNetworks(name) {
+		peers = append(peers, k8sNetPeer{PodSelector: &k8sSelector{MatchLabels: map[string]string{k8sNetworkLabel + k8sName(network): "true"}}})
+	}
+	spec := k8sNetworkPolicySpec{
+		PodSelector: k8sSelector{MatchLabels: selector},
+		PolicyTypes: []string{"Ingress"},
+		Ingress:     []k8sNetRule{{From: peers}},
+	}
+
+	if egress := serverCfg.Security.Egress; egress != nil && egress.Default != config.EgressAllow {
+		spec.PolicyTypes = append(spec.PolicyTypes, "Egress")
+		spec.Egress = []k8sNetRule{
+			{Ports: []k8sNetPort{{Protocol: "UDP", Port: 53}, {Protocol: "TCP", Port: 53}}},
+			{To: peers},
+		}
+		for _, entry := range egress.Allow {
+			rule, err := config.ParseEgressRule(entry)
+			if err != nil {
+
+				continue
+			}
+			cidr := ""
+			if rule.Network != nil {
+				cidr = rule.Network.String()
+			} else if ip := net.ParseIP(rule.Host); ip != nil {
+				cidr = ip.String() + "/32"
+				if ip.To4() == nil {
+					cidr = ip.String() + "/128"
+				}
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: '%s' allows egress to %s, which a NetworkPolicy can't express; allow its addresses instead\n", name, entry)
+
+				continue
+			}
+			netRule := k8sNetRule{To: []k8sNetPeer{{IPBlock: &k8sIPBlock{CIDR: cidr}}}}
+			if rule.Port != 0 {
+				netRule.Ports = []k8sNetPort{{Protocol: "TCP", Port: rule.Port}}
+			}
+			spec.Egress = append(spec.Egress, netRule)
+		}
+	}
+
+	return k8sObject{
+		APIVersion: "networking.k8s.io/v1",
+		Kind:       "NetworkPolicy",
+		Metadata:   g.meta(objectName, nil),
+		Spec:       spec,
+	}
+}
+
+// proxyObjects renders the proxy: the compose files in a ConfigMap, the variables they refer
+// to in a Secret, and a Deployment, Service and Ingress
+func (g *k8sGenerator) proxyObjects(configFile string) ([]k8sObject, error) {
+	files := map[string]string{}
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	files["mcp-compose.yaml"] = string(data)
+	args := []string{"./mcp-compose-executable", "proxy", "--file", "/app/mcp-compose.yaml"}
+	mounts := []k8sVolumeMount{{Name: "config", MountPath: "/app/mcp-compose.yaml", SubPath: "mcp-compose.yaml", ReadOnly: true}}
+	for i, override := range config.OverrideFiles() {
+		data, err := os.ReadFile(override)
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to read override file: %w", err)
+		}
+		key := fmt.Sprintf("mcp-compose.override-%d.yaml", i+1)
+		files[key] = string(data)
+		args = append(args, "--file", "/app/"+key)
+		mounts = append(mounts, k8sVolumeMount{Name: "config", MountPath: "/app/" + key, SubPath: key, ReadOnly: true})
+	}
+	args = append(args, "--port", strconv.Itoa(g.opts.ProxyPort))
+
+	selector := map[string]string{k8sNameLabel: k8sProxyName}
+	objects := []k8sObject{{APIVersion: "v1", Kind: "ConfigMap", Metadata: g.meta(k8sConfigMapName, nil), Data: files}}
+	volumes := []k8sPodVolume{{Name: "config", ConfigMap: &k8sConfigMapRef{Name: k8sConfigMapName}}}
+
+	// The env files the config names may hold secrets, so they go in a Secret mounted where
+	// they resolve next to the compose file
+	if envFilePaths := g.cfg.EnvFilePaths(); len(envFilePaths) > 0 {
+		envFiles := make(map[string]string, len(envFilePaths))
+		for i, envFile := range envFilePaths {
+			data, err := os.ReadFile(config.ResolveEnvFile(configFile, envFile))
+			if err != nil {
+
+				return nil, fmt.Errorf("failed to read env file: %w", err)
+			}
+			key := fmt.Sprintf("env-file-%d", i+1)
+			envFiles[key] = string(data)
+			mounts = append(mounts, k8sVolumeMount{Name: "env-files", MountPath: config.ContainerEnvFile("/app/mcp-compose.yaml", envFile), SubPath: key, ReadOnly: true})
+		}
+		envFilesName := k8sProxyName + "-env-files"
+		objects = append(objects, k8sObject{APIVersion: "v1", Kind: "Secret", Metadata: g.meta(envFilesName, nil), Type: "Opaque", StringData: envFiles})
+		volumes = append(volumes, k8sPodVolume{Name: "env-files", Secret: &k8sSecretRef{SecretName: envFilesName}})
+	}
+
+	proxy := k8sContainer{
+		Name:  "proxy",
+		Image: g.opts.ProxyImage,
+		Args:  args,
+		Env: []k8sEnvVar{
+			{Name: "MCP_PROJECT_NAME", Value: g.project},
+			{Name: "MCP_PROXY_HOST", Value: constants.DefaultHostInterface}, // the Service decides who reaches it
+			{Name: "MCP_PROXY_PORT", Value: strconv.Itoa(g.opts.ProxyPort)},
+		},
+		Ports:          []k8sContainerPort{{ContainerPort: g.opts.ProxyPort}},
+		VolumeMounts:   mounts,
+		ReadinessProbe: &k8sProbe{TCPSocket: &k8sTCPSocket{Port: g.opts.ProxyPort}, PeriodSeconds: 10},
+		Resources:      &k8sResources{Limits: map[string]string{"cpu": "1", "memory": "512Mi"}},
+	}
+
+	// The proxy expands the variables the compose files refer to when it loads them
+	names, err := config.ReferencedEnv(configFile)
+	if err != nil {
+
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, name := range append(names, "MCP_ENV", "MCP_COMPOSE_PROFILES") {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+	// The proxy decrypts the compose file's encrypted values with the same key
+	if config.HasEncryptedValues(configFile) {
+		key := os.Getenv(config.AgeKeyEnv)
+		if keyFile := config.AgeKeyFile(); keyFile != "" {
+			data, err := os.ReadFile(keyFile)
+			if err != nil {
+
+				return nil, fmt.Errorf("failed to read age key file: %w", err)
+			}
+			key = string(data)
+		}
+		env[config.AgeKeyEnv] = key
+	}
+	if len(env) > 0 {
+		envName := k8sProxyName + "-env"
+		objects = append(objects, k8sObject{APIVersion: "v1", Kind: "Secret", Metadata: g.meta(envName, nil), Type: "Opaque", StringData: env})
+		proxy.EnvFrom = []k8sEnvFrom{{SecretRef: &k8sNameRef{Name: envName}}}
+	}
+
+	objects = append(objects, k8sObject{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   g.meta(k8sProxyName, selector),
+		Spec: k8sDeploymentSpec{
+			Replicas: 1,
+			Selector: k8sSelector{MatchLabels: selector},
+			Template: k8sPodTemplate{
+				Metadata: k8sMeta{Labels: selector},
+				Spec: k8sPodSpec{
+					Containers: []k8sContainer{proxy},
+					Volumes:    volumes,
+				},
+			},
+		},
+	})
+	objects = append(objects, k8sObject{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Metadata:   g.meta(k8sProxyName, selector),
+		Spec: k8sServiceSpec{
+			Selector: selector,
+			Ports:    []k8sServicePort{{Name: "http", Port: g.opts.ProxyPort, TargetPort: g.opts.ProxyPort}},
+		},
+	})
+
+	ingress := k8sIngressSpec{
+		IngressClassName: g.opts.IngressClass,
+		Rules: []k8sIngressRule{{
+			Host: g.opts.IngressHost,
+			HTTP: k8sIngressHTTP{Paths: []k8sIngressPath{{
+				Path:     "/",
+				PathType: "Prefix",
+				Backend: k8sIngressBackend{Service: k8sIngressService{
+					Name: k8sProxyName,
+					Port: k8sIngressServicePort{Number: g.opts.ProxyPort},
+				}},
+			}}},
+		}},
+	}
+	if g.opts.IngressTLSSecret != "" && g.opts.IngressHost != "" {
+		ingress.TLS = []k8sIngressTLS{{Hosts: []string{g.opts.IngressHost}, SecretName: g.opts.IngressTLSSecret}}
+	}
+	objects = append(objects, k8sObject{
+		APIVersion: "networking.k8s.io/v1",
+		Kind:       "Ingress",
+		Metadata:   g.meta(k8sProxyName, selector),
+		Spec:       ingress,
+	})
+
+	return objects, nil
+}
+
+// k8sPorts returns the ports a server listens on: its transport ports and the container
+// side of its published ports
+func k8sPorts(serverCfg config.ServerConfig) []k8sServicePort {
+	var ports []k8sServicePort
+	seen := make(map[int]bool)
+	add := func(name string, port int, protocol string) {
+		if port <= 0 || seen[port] {
+
+			return
+		}
+		seen[port] = true
+		ports = append(ports, k8sServicePort{Name: name, Port: port, TargetPort: port, Protocol: protocol})
+	}
+	add("http", serverCfg.HttpPort, "")
+	add("sse", serverCfg.SSEPort, "")
+	add("stdio", serverCfg.StdioHosterPort, "")
+	for _, mapping := range serverCfg.Ports {
+		mapping, protocol, _ := strings.Cut(mapping, "/")
+		parts := strings.Split(mapping, ":")
+		port, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+
+			continue
+		}
+		protocol = strings.ToUpper(protocol)
+		if protocol == "TCP" {
+			protocol = ""
+		}
+		add(fmt.Sprintf("port-%d", port), port, protocol)
+	}
+
+	return ports
+}
+
+// k8sInitContainers turns a server's init jobs into init containers sharing its environment,
+// mounts and security context
+func k8sInitContainers(name string, serverCfg config.ServerConfig, server k8sContainer) []k8sContainer {
+	containers := make([]k8sContainer, 0, len(serverCfg.Init))
+	for _, job := range serverCfg.Init {
+		c := k8sContainer{
+			Name:            k8sName("init-" + job.Name),
+			Image:           server.Image,
+			ImagePullPolicy: server.ImagePullPolicy,
+			Args:            job.Args,
+			WorkingDir:      job.WorkDir,
+			EnvFrom:         server.EnvFrom,
+			SecurityContext: server.SecurityContext,
+			VolumeMounts:    server.VolumeMounts,
+		}
+		if job.Image != "" {
+			c.Image = job.Image
+		}
+		if job.Command != "" {
+			c.Args = append([]string{job.Command}, job.Args...)
+		}
+		c.Env = append(c.Env, k8sEnvVar{Name: "MCP_INIT_JOB", Value: job.Name})
+		for _, key := range sortedKeys(job.Env) {
+			if config.IsSecretEnv(key, job.Env[key]) {
+				fmt.Fprintf(os.Stderr, "Warning: init job '%s' of '%s' sets the secret %s, which is left out; set it on the server instead\n", job.Name, name, key)
+
+				continue
+			}
+			c.Env = append(c.Env, k8sEnvVar{Name: key, Value: job.Env[key]})
+		}
+		if len(job.Volumes) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: init job '%s' of '%s' mounts volumes of its own, which are left out; it mounts the server's\n", job.Name, name)
+		}
+		containers = append(containers, c)
+	}
+
+	return containers
+}
+
+// k8sResourceLimits converts deploy resource limits and reservations. A number of GPUs
+// becomes a limit on nvidia.com/gpu; all GPUs or chosen ones have no equivalent.
+func k8sResourceLimits(name string, serverCfg config.ServerConfig) *k8sResources {
+	resources := serverCfg.Deploy.Resources
+	convert := func(limits config.ResourceLimitsConfig) map[string]string {
+		values := make(map[string]string)
+		if limits.CPUs != "" {
+			values["cpu"] = limits.CPUs
+		}
+		if limits.Memory != "" {
+			values["memory"] = k8sQuantity(limits.Memory)
+		}
+		if len(values) == 0 {
+
+			return nil
+		}
+
+		return values
+	}
+	result := &k8sResources{Limits: convert(resources.Limits), Requests: convert(resources.Reservations)}
+	if gpus := serverCfg.GPURequest(); gpus != "" {
+		if _, err := strconv.Atoi(gpus); err == nil {
+			if result.Limits == nil {
+				result.Limits = make(map[string]string)
+			}
+			result.Limits["nvidia.com/gpu"] = gpus
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: '%s' asks for gpus '%s', which Kubernetes can't express; ask for a number of GPUs instead\n", name, gpus)
+		}
+	}
+	if result.Limits == nil && result.Requests == nil {
+
+		return nil
+	}
+
+	return result
+}
+
+// k8sSecurity converts the container's user, capabilities and privileges. Groups given by
+// number become supplemental groups of the pod.
+func k8sSecurity(name string, opts *container.ContainerOptions) (*k8sSecurityContext, []int64) {
+	security := &k8sSecurityContext{
+		ReadOnlyRootFilesystem: opts.ReadOnly,
+		Privileged:             opts.Privileged,
+	}
+	if opts.User != "" {
+		user, group, _ := strings.Cut(opts.User, ":")
+		if uid, err := strconv.ParseInt(user, 10, 64); err == nil {
+			security.RunAsUser = &uid
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: '%s' runs as user '%s'; Kubernetes needs a numeric user\n", name, user)
+		}
+		if gid, err := strconv.ParseInt(group, 10, 64); err == nil {
+			security.RunAsGroup = &gid
+		}
+	}
+	if len(opts.CapAdd) > 0 || len(opts.CapDrop) > 0 {
+		security.Capabilities = &k8sCapabilities{Add: opts.CapAdd, Drop: opts.CapDrop}
+	}
+	for _, opt := range opts.SecurityOpt {
+		if opt == "no-new-privileges" || opt == "no-new-privileges:true" {
+			noEscalation := false
+			security.AllowPrivilegeEscalation = &noEscalation
+		}
+	}
+	var groups []int64
+	for _, group := range opts.Groups {
+		if gid, err := strconv.ParseInt(group, 10, 64); err == nil {
+			groups = append(groups, gid)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: '%s' joins group '%s'; Kubernetes needs a numeric group\n", name, group)
+		}
+	}
+
+	return security, groups
+}
+
+// k8sLivenessProbe converts a command or HTTP health check
+func k8sLivenessProbe(serverCfg config.ServerConfig) *k8sProbe {
+	hc := serverCfg.HealthCheck
+	if hc == nil {
+
+		return nil
+	}
+	probe := &k8sProbe{
+		InitialDelaySeconds: k8sSeconds(hc.StartPeriod),
+		PeriodSeconds:       k8sSeconds(hc.Interval),
+		TimeoutSeconds:      k8sSeconds(hc.Timeout),
+		FailureThreshold:    hc.Retries,
+	}
+	switch {
+	case len(hc.Test) > 1 && hc.Test[0] == "CMD":
+		probe.Exec = &k8sExecAction{Command: hc.Test[1:]}
+	case len(hc.Test) > 1 && hc.Test[0] == "CMD-SHELL":
+		probe.Exec = &k8sExecAction{Command: []string{"sh", "-c", strings.Join(hc.Test[1:], " ")}}
+	case hc.Endpoint != "" && serverCfg.HttpPort > 0:
+		probe.HTTPGet = &k8sHTTPGet{Path: hc.Endpoint, Port: serverCfg.HttpPort}
+	default:
+
+		return nil
+	}
+
+	return probe
+}
+
+// k8sPullPolicy converts a pull_policy
+func k8sPullPolicy(policy string, pull bool) string {
+	switch {
+	case policy == config.PullAlways || pull:
+
+		return "Always"
+	case policy == config.PullNever:
+
+		return "Never"
+	case policy == config.PullMissing:
+
+		return "IfNotPresent"
+	}
+
+	return ""
+}
+
+var dockerSize = regexp.MustCompile(`^(?i)([0-9.]+)\s*([kmgt]?)b?$`)
+
+// k8sQuantity converts a Docker size such as 512m to a Kubernetes quantity such as 512Mi
+func k8sQuantity(size string) string {
+	match := dockerSize.FindStringSubmatch(strings.TrimSpace(size))
+	if match == nil {
+
+		return size
+	}
+	suffix := map[string]string{"": "", "k": "Ki", "m": "Mi", "g": "Gi", "t": "Ti"}[strings.ToLower(match[2])]
+
+	return match[1] + suffix
+}
+
+// k8sSeconds converts a duration to whole seconds, or 0 when it is empty or invalid
+func k8sSeconds(duration string) int {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+
+		return 0
+	}
+
+	return int(d.Seconds())
+}
+
+var k8sInvalidName = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// k8sName turns a compose name into a valid object name
+func k8sName(name string) string {
+	name = k8sInvalidName.ReplaceAllString(strings.ToLower(name), "-")
+
+	return strings.Trim(name, "-")
+}
+
+// projectName names the project after its directory
+func projectName(configFile string) string {
+	if abs, err := filepath.Abs(configFile); err == nil {
+
+		return filepath.Base(filepath.Dir(abs))
+	}
+
+	return "mcp-compose"
+}
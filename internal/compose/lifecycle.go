@@ -4,14 +4,12 @@ package compose
 import (
 	"context"
 	"fmt"
-	"net/http"
-	"os/exec"
 	"strings"
-	"time"
 
 	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
 )
 
 // LifecycleManager handles pre/post hooks and health checks
@@ -61,7 +59,11 @@ func (lm *LifecycleManager) executeHook(phase, serverName, hook string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.LifecycleTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", hook)
+	cmd, err := runtime.ShellCommand(ctx, lm.config.Servers[serverName].Lifecycle.Shell, hook)
+	if err != nil {
+
+		return fmt.Errorf("%s hook: %w", phase, err)
+	}
 	cmd.Dir = lm.projectDir
 
 	output, err := cmd.CombinedOutput()
@@ -77,72 +79,3 @@ func (lm *LifecycleManager) executeHook(phase, serverName, hook string) error {
 
 	return nil
 }
-
-// HealthChecker performs health checks on servers
-type HealthChecker struct {
-	config  config.HealthCheck
-	logger  *logging.Logger
-	baseURL string
-}
-
-// NewHealthChecker creates a new health checker
-func NewHealthChecker(healthConfig config.HealthCheck, logger *logging.Logger, baseURL string) *HealthChecker {
-
-	return &HealthChecker{
-		config:  healthConfig,
-		logger:  logger,
-		baseURL: baseURL,
-	}
-}
-
-// Check performs a health check
-func (hc *HealthChecker) Check() error {
-	if hc.config.Endpoint == "" {
-
-		return fmt.Errorf("no health check endpoint configured")
-	}
-
-	timeout, err := time.ParseDuration(hc.config.Timeout)
-	if err != nil {
-		timeout = constants.DefaultHealthTimeout
-	}
-
-	// Implementation depends on check type
-	// For now, assume HTTP endpoint check
-
-	return hc.checkHTTPEndpoint(timeout)
-}
-
-func (hc *HealthChecker) checkHTTPEndpoint(timeout time.Duration) error {
-	// Use the timeout parameter
-	client := &http.Client{
-		Timeout: timeout,
-	}
-
-	var url string
-	if !strings.HasPrefix(hc.config.Endpoint, "http") {
-		url = hc.baseURL + hc.config.Endpoint
-	} else {
-		url = hc.config.Endpoint
-	}
-
-	resp, err := client.Get(url)
-	if err != nil {
-
-		return fmt.Errorf("health check failed: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			hc.logger.Warning("Failed to close response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-
-		return fmt.Errorf("health check returned status %d", resp.StatusCode)
-	}
-
-	hc.logger.Debug("Health check passed for endpoint: %s", url)
-
-	return nil
-}
@@ -0,0 +1,36 @@
+// internal/compose/locale.go
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+// warnMissingTzdata checks that a started container can resolve its TZ. Images without
+// tzdata silently fall back to UTC, which puts their timestamps out of line with the rest.
+func warnMissingTzdata(serverName, containerName, timezone string, cRuntime container.Runtime) {
+	if timezone == "" || timezone == "UTC" {
+
+		return
+	}
+
+	cmd, stdin, _, err := cRuntime.ExecContainer(containerName, []string{"test", "-e", "/usr/share/zoneinfo/" + timezone}, false)
+	if err != nil {
+
+		return
+	}
+	if closer, ok := stdin.(io.Closer); ok {
+		_ = closer.Close()
+	}
+
+	// Exit status 1 means the zone file is missing; anything else (no shell utilities,
+	// container already gone) says nothing about tzdata
+	var exitErr *exec.ExitError
+	if err := cmd.Wait(); errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		fmt.Printf("Warning: server '%s' sets TZ=%s but its image has no tzdata; its clock will report UTC. Install tzdata in the image.\n", serverName, timezone)
+	}
+}
@@ -0,0 +1,269 @@
+// internal/compose/lock.go
+package compose
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+// WriteLock resolves every server of the config, pulling images that are not present, and
+// writes the result to the lock file next to it
+func WriteLock(configFile string) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+
+		return fmt.Errorf("failed to detect container runtime: %w", err)
+	}
+
+	lock := &config.Lock{
+		GeneratedAt: time.Now(),
+		Images:      make(map[string]string),
+		Servers:     make(map[string]config.LockedServer),
+	}
+	for _, name := range sortedServerNames(cfg) {
+		server := cfg.Servers[name]
+		locked := config.LockedServer{Env: config.LockEnv(server.Env)}
+
+		switch {
+		case server.Build.Context != "":
+			if locked.BuildContext, err = hashBuildContext(server.Build); err != nil {
+
+				return fmt.Errorf("server '%s': %w", name, err)
+			}
+		case server.Image != "":
+			locked.Image = server.Image
+			if locked.Digest, err = lockImage(cRuntime, server); err != nil {
+
+				return fmt.Errorf("server '%s': %w", name, err)
+			}
+			if !config.IsDigestReference(server.Image) {
+				lock.Images[server.Image] = locked.Digest
+			}
+		}
+
+		lock.Servers[name] = locked
+		fmt.Printf("🔒 %s: %s\n", name, describeLockedServer(locked))
+	}
+
+	lockPath := config.LockFilePath(configFile)
+	if err := lock.Save(lockPath); err != nil {
+
+		return err
+	}
+	fmt.Printf("Locked %d server(s) in %s\n", len(lock.Servers), lockPath)
+
+	return nil
+}
+
+// VerifyLock checks that the named servers and their dependencies still resolve the way
+// the lock file records, and returns every difference found as an error
+func VerifyLock(cfg *config.ComposeConfig, configFile string, serverNames []string) error {
+	lockPath := config.LockFilePath(configFile)
+	lock, err := config.LoadLock(lockPath)
+	if err != nil {
+
+		return err
+	}
+	if lock == nil || len(lock.Servers) == 0 {
+
+		return fmt.Errorf("--locked needs a lock file with servers, but %s has none; run 'mcp-compose lock' first", lockPath)
+	}
+
+	var differences []string
+	for _, name := range getServersToStart(cfg, serverNames) {
+		locked, exists := lock.Servers[name]
+		if !exists {
+			differences = append(differences, fmt.Sprintf("server '%s' is not in the lock file", name))
+
+			continue
+		}
+		differences = append(differences, lockDifferences(name, cfg.Servers[name], locked)...)
+	}
+	if len(differences) > 0 {
+
+		return fmt.Errorf("the config no longer resolves as %s records:\n  - %s\nRun 'mcp-compose lock' to accept the changes",
+			lockPath, strings.Join(differences, "\n  - "))
+	}
+
+	return nil
+}
+
+// UpLocked starts servers like Up after VerifyLock has found them unchanged
+func UpLocked(configFile string, serverNames []string) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	if err := VerifyLock(cfg, configFile, serverNames); err != nil {
+
+		return err
+	}
+
+	return Up(configFile, serverNames)
+}
+
+// lockDifferences compares how a server resolves now with its locked resolution. Secret
+// environment values are compared by hash and never shown.
+func lockDifferences(name string, server config.ServerConfig, locked config.LockedServer) []string {
+	var differences []string
+	switch {
+	case server.Build.Context != "":
+		hash, err := hashBuildContext(server.Build)
+		if err != nil {
+			differences = append(differences, fmt.Sprintf("server '%s': %v", name, err))
+		} else if hash != locked.BuildContext {
+			differences = append(differences, fmt.Sprintf("server '%s': build context changed", name))
+		}
+	case server.Image != locked.Image:
+		differences = append(differences, fmt.Sprintf("server '%s': image is '%s', locked '%s'", name, server.Image, locked.Image))
+	case locked.Image != "" && locked.Digest == "":
+		differences = append(differences, fmt.Sprintf("server '%s': image '%s' has no locked digest", name, locked.Image))
+	}
+
+	current := config.LockEnv(server.Env)
+	keys := make(map[string]bool)
+	for key := range current {
+		keys[key] = true
+	}
+	for key := range locked.Env {
+		keys[key] = true
+	}
+	names := make([]string, 0, len(keys))
+	for key := range keys {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	for _, key := range names {
+		now, set := current[key]
+		was, wasSet := locked.Env[key]
+		switch {
+		case !wasSet:
+			differences = append(differences, fmt.Sprintf("server '%s': env %s was added", name, key))
+		case !set:
+			differences = append(differences, fmt.Sprintf("server '%s': env %s was removed", name, key))
+		case now != was:
+			differences = append(differences, fmt.Sprintf("server '%s': env %s changed", name, key))
+		}
+	}
+
+	return differences
+}
+
+// lockImage returns the digest reference of a server's image, pulling it first when it is
+// not present
+func lockImage(cRuntime container.Runtime, server config.ServerConfig) (string, error) {
+	if config.IsDigestReference(server.Image) {
+
+		return server.Image, nil
+	}
+	runtimeName := cRuntime.GetRuntimeName()
+	if runtimeName == "none" {
+
+		return "", fmt.Errorf("locking image '%s' needs a container runtime (docker or podman)", server.Image)
+	}
+	if _, err := cRuntime.GetImageInfo(server.Image); err != nil {
+		if server.PullPolicy == config.PullNever {
+
+			return "", fmt.Errorf("image '%s' is not present locally and pull_policy is never", server.Image)
+		}
+		fmt.Printf("   Pulling %s...\n", server.Image)
+		if err := pullImage(runtimeName, server.Image, server.Platform); err != nil {
+
+			return "", fmt.Errorf("failed to pull image '%s': %w", server.Image, err)
+		}
+	}
+
+	return resolveDigest(cRuntime, server.Image)
+}
+
+// hashBuildContext hashes the files of a build context, by path, mode and content, together
+// with the Dockerfile and build settings. The .git directory is left out.
+func hashBuildContext(build config.BuildConfig) (string, error) {
+	root := build.Context
+	if _, err := os.Stat(root); err != nil {
+
+		return "", fmt.Errorf("build context '%s' is not readable: %w", root, err)
+	}
+
+	sum := sha256.New()
+	fmt.Fprintf(sum, "dockerfile=%s\ntarget=%s\nplatform=%s\n", build.Dockerfile, build.Target, build.Platform)
+	args := make([]string, 0, len(build.Args))
+	for key, value := range build.Args {
+		args = append(args, key+"="+value)
+	}
+	sort.Strings(args)
+	fmt.Fprintf(sum, "args=%s\n", strings.Join(args, "\x00"))
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+
+			return err
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+
+			return err
+		}
+		fmt.Fprintf(sum, "%s %o\n", filepath.ToSlash(rel), info.Mode())
+		if !info.Mode().IsRegular() {
+
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		_, err = io.Copy(sum, file)
+
+		return err
+	})
+	if err != nil {
+
+		return "", fmt.Errorf("failed to hash build context '%s': %w", root, err)
+	}
+
+	return "sha256:" + hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// describeLockedServer summarizes a locked server for display
+func describeLockedServer(locked config.LockedServer) string {
+	switch {
+	case locked.BuildContext != "":
+
+		return "build context " + digestOf(locked.BuildContext)
+	case locked.Digest != "":
+
+		return locked.Image + " → " + digestOf(locked.Digest)
+	}
+
+	return "command " + fmt.Sprintf("(%d env)", len(locked.Env))
+}
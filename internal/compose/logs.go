@@ -0,0 +1,319 @@
+// internal/compose/logs.go
+package compose
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LogsOptions controls which log lines Logs shows and how
+type LogsOptions struct {
+	Follow   bool
+	Tail     int               // lines per source from the end of its logs; negative for all
+	Since    string            // a duration such as 10m, or a date or RFC 3339 time
+	Grep     string            // regular expression a line must match to be shown
+	NoColor  bool              // print the prefixes without color
+	Output   string            // table (plain lines), or json or yaml for one record per line
+	Services map[string]string // mcp-compose's own containers to include, by the name to show
+}
+
+// LogLine is a line of logs as --output json and yaml print it
+type LogLine struct {
+	Server string     `json:"server" yaml:"server"`
+	Stream string     `json:"stream" yaml:"stream"`
+	Time   *time.Time `json:"time,omitempty" yaml:"time,omitempty"` // known for process-based servers
+	Line   string     `json:"line" yaml:"line"`
+}
+
+// logColors are the ANSI colors of the prefixes, assigned to sources in order
+var logColors = []string{"36", "33", "32", "35", "34", "96", "93", "92", "95", "94"}
+
+// logSource is one server or service whose lines Logs shows
+type logSource struct {
+	name      string
+	container string // set for containers
+	process   string // set for process-based servers
+}
+
+// logPrinter writes the lines of every source, whole lines at a time, behind a prefix naming
+// the source when there is more than one
+type logPrinter struct {
+	mu      sync.Mutex
+	width   int
+	color   bool
+	prefix  bool
+	output  string
+	pattern *regexp.Regexp
+}
+
+func (p *logPrinter) print(source string, index int, stderr bool, at time.Time, line string) {
+	if p.pattern != nil && !p.pattern.MatchString(line) {
+
+		return
+	}
+	if p.output != OutputTable {
+		record := LogLine{Server: source, Stream: "stdout", Line: line}
+		if stderr {
+			record.Stream = "stderr"
+		}
+		if !at.IsZero() {
+			record.Time = &at
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.printRecord(record)
+
+		return
+	}
+	out := os.Stdout
+	if stderr {
+		out = os.Stderr
+	}
+	if p.prefix {
+		label := fmt.Sprintf("%-*s |", p.width, source)
+		if p.color {
+			label = "\033[" + logColors[index%len(logColors)] + "m" + label + "\033[0m"
+		}
+		line = label + " " + line
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = fmt.Fprintln(out, line)
+}
+
+// printRecord writes a record as a line of JSON or a YAML document, so records can be read as
+// they are written while following
+func (p *logPrinter) printRecord(record LogLine) {
+	if p.output == OutputYAML {
+		data, err := yaml.Marshal(record)
+		if err == nil {
+			_, _ = fmt.Fprintf(os.Stdout, "---\n%s", data)
+		}
+
+		return
+	}
+	if data, err := json.Marshal(record); err == nil {
+		_, _ = fmt.Fprintln(os.Stdout, string(data))
+	}
+}
+
+// Logs shows the logs of the named servers, or of every server when none are named and no
+// services are given. The sources are read in parallel, so following several interleaves
+// their lines as they are written, each behind its source's name.
+func Logs(configFile string, serverNames []string, opts LogsOptions) error {
+	if opts.Output == "" {
+		opts.Output = OutputTable
+	}
+	if err := checkOutputFormat(opts.Output); err != nil {
+
+		return err
+	}
+	var pattern *regexp.Regexp
+	if opts.Grep != "" {
+		var err error
+		if pattern, err = regexp.Compile(opts.Grep); err != nil {
+
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+	since, err := parseSince(opts.Since, time.Now())
+	if err != nil {
+
+		return err
+	}
+
+	var sources []logSource
+	if len(serverNames) > 0 || len(opts.Services) == 0 {
+		cfg, err := config.LoadConfig(configFile)
+		if err != nil {
+
+			return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+		}
+		sources = serverLogSources(cfg, serverNames)
+	}
+	serviceNames := make([]string, 0, len(opts.Services))
+	for name := range opts.Services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+	for _, name := range serviceNames {
+		sources = append(sources, logSource{name: name, container: opts.Services[name]})
+	}
+
+	if len(sources) == 0 {
+
+		return nil
+	}
+
+	// The container runtime is only needed for containers
+	var cRuntime container.Runtime
+	for _, source := range sources {
+		if source.container != "" {
+			if cRuntime, err = container.DetectRuntime(); err != nil {
+
+				return fmt.Errorf("failed to detect container runtime: %w", err)
+			}
+
+			break
+		}
+	}
+
+	printer := &logPrinter{prefix: len(sources) > 1, output: opts.Output, pattern: pattern, color: !opts.NoColor && colorTerminal()}
+	for _, source := range sources {
+		printer.width = max(printer.width, len(source.name))
+	}
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			emit := func(stderr bool, at time.Time, line string) { printer.print(source.name, i, stderr, at, line) }
+			var err error
+			if source.process != "" {
+				err = runtime.ProcessLogs(source.process).StreamLogs(
+					runtime.LogStreamOptions{Follow: opts.Follow, Tail: opts.Tail, Since: since},
+					func(record runtime.LogRecord) { emit(record.Stream != runtime.StreamStdout, record.Time, record.Log) })
+			} else if cRuntime.GetRuntimeName() == "none" {
+				err = fmt.Errorf("no container runtime detected")
+			} else {
+				err = streamContainerLogs(cRuntime.GetRuntimeName(), source.container, opts, since, emit)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to show logs for %s: %v\n", source.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// serverLogSources returns the sources of the named servers, or of every server
+func serverLogSources(cfg *config.ComposeConfig, serverNames []string) []logSource {
+	if len(serverNames) == 0 {
+		for name := range cfg.Servers {
+			serverNames = append(serverNames, name)
+		}
+		sort.Strings(serverNames)
+		if len(serverNames) == 0 {
+			fmt.Fprintln(os.Stderr, "No servers defined in configuration to show logs for.")
+
+			return nil
+		}
+	}
+
+	var sources []logSource
+	for _, name := range serverNames {
+		serverCfg, exists := cfg.Servers[name]
+		if !exists {
+			fmt.Fprintf(os.Stderr, "Warning: server '%s' not found in configuration, skipping logs.\n", name)
+
+			continue
+		}
+		if isContainerServer(serverCfg) {
+			sources = append(sources, logSource{name: name, container: config.ContainerName(name)})
+		} else {
+			sources = append(sources, logSource{name: name, process: config.ContainerName(name)})
+		}
+	}
+	if len(sources) == 0 {
+		fmt.Fprintln(os.Stderr, "None of the specified servers were found.")
+	}
+
+	return sources
+}
+
+// streamContainerLogs passes a container's log lines to emit as the runtime's logs command
+// prints them
+func streamContainerLogs(runtimeName, containerName string, opts LogsOptions, since time.Time, emit func(bool, time.Time, string)) error {
+	args := []string{"logs"}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	if opts.Tail >= 0 {
+		args = append(args, "--tail", strconv.Itoa(opts.Tail))
+	}
+	if !since.IsZero() {
+		args = append(args, "--since", since.Format(time.RFC3339))
+	}
+	cmd := exec.Command(runtimeName, append(args, containerName)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+
+		return fmt.Errorf("failed to run %s logs: %w", runtimeName, err)
+	}
+
+	var wg sync.WaitGroup
+	scan := func(reader io.Reader, isStderr bool) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			emit(isStderr, time.Time{}, scanner.Text())
+		}
+	}
+	wg.Add(2)
+	go scan(stdout, false)
+	go scan(stderr, true)
+	wg.Wait()
+
+	return cmd.Wait()
+}
+
+// parseSince turns --since into a time: a duration before now, an RFC 3339 time or a date
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(since); err == nil {
+
+		return now.Add(-duration), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, since, time.Local); err == nil {
+
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since '%s': use a duration such as 10m or a time such as 2024-05-01T15:04:05Z", since)
+}
+
+// colorTerminal reports whether stdout is a terminal that should get colored output
+func colorTerminal() bool {
+	if os.Getenv("NO_COLOR") != "" {
+
+		return false
+	}
+	info, err := os.Stdout.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && !strings.EqualFold(os.Getenv("TERM"), "dumb")
+}
@@ -0,0 +1,55 @@
+// internal/compose/output.go
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the commands that print tables, selected with --output
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// OutputFormats are the values --output accepts
+var OutputFormats = []string{OutputTable, OutputJSON, OutputYAML}
+
+// checkOutputFormat rejects an unknown --output value
+func checkOutputFormat(format string) error {
+	for _, known := range OutputFormats {
+		if format == known {
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown output format '%s': use %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// writeOutput prints a value in a machine-readable format for scripts
+func writeOutput(format string, value interface{}) error {
+	if format == OutputYAML {
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+
+		return encoder.Close()
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	return nil
+}
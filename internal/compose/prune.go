@@ -0,0 +1,177 @@
+// internal/compose/prune.go
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+// prunable is a resource of the project that nothing in the config uses anymore
+type prunable struct {
+	kind   string // "container", "process", "network" or "volume"
+	name   string
+	server string // the server it belonged to, for containers and processes
+}
+
+// Prune removes what up created for servers since deleted from the config: their containers
+// and processes, the networks no server joins anymore and the volumes no server mounts. With
+// dryRun they are only listed.
+func Prune(configFile string, dryRun bool) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+
+		return fmt.Errorf("failed to detect container runtime: %w", err)
+	}
+	state, err := LoadProjectState(configFile)
+	if err != nil {
+
+		return err
+	}
+	resources, err := collectPrunable(configFile, cfg, state, cRuntime)
+	if err != nil {
+
+		return err
+	}
+	if len(resources) == 0 {
+		fmt.Println("Nothing to prune.")
+
+		return nil
+	}
+
+	var failed, pruned []string
+	for _, resource := range resources {
+		label := fmt.Sprintf("%s '%s'", resource.kind, resource.name)
+		if resource.server != "" {
+			label += fmt.Sprintf(" (server '%s' is no longer in the config)", resource.server)
+		}
+		if dryRun {
+			fmt.Printf("Would remove %s\n", label)
+
+			continue
+		}
+
+		switch resource.kind {
+		case "process":
+			// Reports the outcome itself
+			if len(stopProcessServers(cfg, state, []string{resource.server})) == 0 {
+				failed = append(failed, resource.name)
+			} else {
+				pruned = append(pruned, resource.server)
+			}
+
+			continue
+		case "container":
+			err = cRuntime.StopContainer(resource.name)
+		case "network":
+			err = cRuntime.RemoveNetwork(resource.name)
+		case "volume":
+			// Not forced, so a volume still mounted by some container is kept
+			err = cRuntime.RemoveVolume(resource.name, false)
+		}
+		if err != nil {
+			fmt.Printf("[✖] Could not remove %s: %v\n", label, err)
+			failed = append(failed, resource.name)
+
+			continue
+		}
+		fmt.Printf("[✔] Removed %s\n", label)
+		if resource.server != "" {
+			pruned = append(pruned, resource.server)
+		}
+	}
+	forgetServers(configFile, pruned)
+
+	if len(failed) > 0 {
+
+		return fmt.Errorf("failed to remove %d resource(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// collectPrunable finds the project's resources that the config no longer uses, by their
+// labels and by the servers recorded in the project state
+func collectPrunable(configFile string, cfg *config.ComposeConfig, state *ProjectState, cRuntime container.Runtime) ([]prunable, error) {
+	var resources []prunable
+	for _, server := range orphanedServers(state, cfg) {
+		if state.Servers[server].Kind == "process" {
+			resources = append(resources, prunable{kind: "process", name: config.ContainerName(server), server: server})
+		}
+	}
+	if cRuntime.GetRuntimeName() == "none" {
+
+		return resources, nil
+	}
+
+	containers, err := cRuntime.ListContainers(map[string]string{"label": constants.ProjectConfigLabel + "=" + absConfigFile(configFile)})
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, info := range containers {
+		server := info.Labels[constants.ProjectServerLabel]
+		if _, exists := cfg.Servers[server]; exists || server == "" || !ownedByProject(info.Labels, configFile) {
+
+			continue
+		}
+		seen[server] = true
+		resources = append(resources, prunable{kind: "container", name: info.Name, server: server})
+	}
+	for _, server := range orphanedServers(state, cfg) {
+		if seen[server] || state.Servers[server].Kind == "process" {
+
+			continue
+		}
+		identifier := stateContainer(state, server, cRuntime)
+		if _, err := cRuntime.GetContainerInfo(identifier); err == nil && containerOwner(cRuntime, identifier, configFile) == "" {
+			resources = append(resources, prunable{kind: "container", name: identifier, server: server})
+		}
+	}
+
+	used := map[string]bool{config.DefaultNetwork(): true}
+	for name := range cfg.Servers {
+		for _, network := range cfg.ServerNetworks(name) {
+			used[network] = true
+		}
+	}
+	networks, err := cRuntime.ListNetworks()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+	var networkNames []string
+	for _, network := range networks {
+		if network.Labels[constants.ManagedByLabel] == constants.ManagedBy && !used[network.Name] &&
+			network.Labels[constants.ProjectConfigLabel] != "" && ownedByProject(network.Labels, configFile) {
+			networkNames = append(networkNames, network.Name)
+		}
+	}
+	sort.Strings(networkNames)
+	for _, name := range networkNames {
+		resources = append(resources, prunable{kind: "network", name: name})
+	}
+
+	volumes, err := CollectVolumes(configFile, cfg, cRuntime)
+	if err != nil {
+
+		return nil, err
+	}
+	for _, volume := range volumes {
+		if volume.Orphaned() {
+			resources = append(resources, prunable{kind: "volume", name: volume.Name})
+		}
+	}
+
+	return resources, nil
+}
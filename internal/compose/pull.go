@@ -0,0 +1,312 @@
+// internal/compose/pull.go
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+// PullOptions controls how images are pulled
+type PullOptions struct {
+	// Parallel is how many images are pulled at once
+	Parallel int
+	// Locked pulls the digests recorded in the lock file instead of resolving the tags
+	// again, and leaves the lock file as it is
+	Locked bool
+}
+
+// pullJob is one image to pull for one platform and the servers that run it
+type pullJob struct {
+	image    string
+	ref      string // what is pulled: the image, or its locked digest
+	platform string // os/arch[/variant] to pull, or "" for the host's
+	servers  []string
+}
+
+// Pull pulls the images of the named servers, or of all servers, in parallel and records
+// the digests they resolved to in the lock file next to the config
+func Pull(configFile string, serverNames []string, opts PullOptions) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+
+		return fmt.Errorf("failed to detect container runtime: %w", err)
+	}
+	runtimeName := cRuntime.GetRuntimeName()
+	if runtimeName == "none" {
+
+		return fmt.Errorf("pulling images needs a container runtime (docker or podman)")
+	}
+
+	lockPath := config.LockFilePath(configFile)
+	lock, err := config.LoadLock(lockPath)
+	if err != nil {
+
+		return err
+	}
+	if opts.Locked && lock == nil {
+
+		return fmt.Errorf("--locked needs a lock file, but %s does not exist; run 'mcp-compose pull' first", lockPath)
+	}
+
+	names := serverNames
+	if len(names) == 0 {
+		names = sortedServerNames(cfg)
+	}
+	jobs, err := pullJobs(cfg, names, lock, opts.Locked)
+	if err != nil {
+
+		return err
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No images to pull.")
+
+		return nil
+	}
+
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = constants.PullDefaultParallel
+	}
+	fmt.Printf("Pulling %d image(s), %d at a time...\n", len(jobs), parallel)
+
+	var (
+		mu       sync.Mutex
+		done     int
+		failed   []string
+		resolved = make(map[string]string)
+		wg       sync.WaitGroup
+		slots    = make(chan struct{}, parallel)
+	)
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job pullJob) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			mu.Lock()
+			fmt.Printf("   Pulling %s%s (%s)...\n", job.ref, platformSuffix(job.platform), strings.Join(job.servers, ", "))
+			mu.Unlock()
+
+			start := time.Now()
+			err := pullImage(runtimeName, job.ref, job.platform)
+			var (
+				pinned  string
+				warning string
+			)
+			if err == nil {
+				var info *container.ImageInfo
+				if info, err = cRuntime.GetImageInfo(job.ref); err == nil {
+					warning = container.PlatformMismatch(info, job.platform)
+					if !opts.Locked && !config.IsDigestReference(job.image) {
+						pinned, err = pinnedDigest(job.image, info)
+					}
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if err != nil {
+				failed = append(failed, job.image)
+				fmt.Printf("[%d/%d] ❌ %s: %v\n", done, len(jobs), job.image, err)
+
+				return
+			}
+			if pinned != "" {
+				resolved[job.image] = pinned
+				fmt.Printf("[%d/%d] ✅ %s → %s (%s)\n", done, len(jobs), job.image, digestOf(pinned), ShortDuration(time.Since(start)))
+			} else {
+				fmt.Printf("[%d/%d] ✅ %s (%s)\n", done, len(jobs), job.ref, ShortDuration(time.Since(start)))
+			}
+			if warning != "" {
+				fmt.Printf("   ⚠️  %s\n", warning)
+			}
+		}(job)
+	}
+	wg.Wait()
+
+	if !opts.Locked {
+		if lock == nil {
+			lock = &config.Lock{Images: make(map[string]string)}
+		}
+		for image, pinned := range resolved {
+			lock.Images[image] = pinned
+		}
+		for name, server := range lock.Servers {
+			if pinned, updated := resolved[server.Image]; updated {
+				server.Digest = pinned
+				lock.Servers[name] = server
+			}
+		}
+		// Forget images no server runs any more
+		used := make(map[string]bool)
+		for _, server := range cfg.Servers {
+			used[server.Image] = true
+		}
+		for image := range lock.Images {
+			if !used[image] {
+				delete(lock.Images, image)
+			}
+		}
+		lock.GeneratedAt = time.Now()
+		if err := lock.Save(lockPath); err != nil {
+
+			return err
+		}
+		fmt.Printf("Recorded %d digest(s) in %s\n", len(lock.Images), lockPath)
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+
+		return fmt.Errorf("failed to pull %d image(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// pullJobs returns the images the named servers run, once for each platform they run them
+// on. Servers built from a context, run as processes or never pulled are left out.
+func pullJobs(cfg *config.ComposeConfig, serverNames []string, lock *config.Lock, locked bool) ([]pullJob, error) {
+	byKey := make(map[string]*pullJob)
+	var order []string
+	for _, name := range serverNames {
+		server, exists := cfg.Servers[name]
+		if !exists {
+
+			return nil, fmt.Errorf("server '%s' not found in config", name)
+		}
+		if server.Image == "" || server.Build.Context != "" {
+
+			continue
+		}
+		if server.PullPolicy == config.PullNever {
+			fmt.Printf("   Skipping %s for '%s': pull_policy is never\n", server.Image, name)
+
+			continue
+		}
+
+		key := server.Image + " " + server.Platform
+		job, exists := byKey[key]
+		if !exists {
+			ref := server.Image
+			if locked && !config.IsDigestReference(server.Image) {
+				pinned, found := lock.Images[server.Image]
+				if !found {
+
+					return nil, fmt.Errorf("image '%s' of server '%s' is not in the lock file; run 'mcp-compose pull' without --locked", server.Image, name)
+				}
+				ref = pinned
+			}
+			job = &pullJob{image: server.Image, ref: ref, platform: server.Platform}
+			byKey[key] = job
+			order = append(order, key)
+		}
+		job.servers = append(job.servers, name)
+	}
+
+	jobs := make([]pullJob, 0, len(order))
+	for _, key := range order {
+		jobs = append(jobs, *byKey[key])
+	}
+
+	return jobs, nil
+}
+
+// pullImage pulls one image without streaming the runtime's progress bars, which would
+// interleave when several images are pulled at once
+func pullImage(runtimeName, image, platform string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.PullTimeout)
+	defer cancel()
+
+	args := []string{"pull", "--quiet"}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, runtimeName, append(args, image)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// resolveDigest returns the digest reference of a pulled image
+func resolveDigest(cRuntime container.Runtime, image string) (string, error) {
+	info, err := cRuntime.GetImageInfo(image)
+	if err != nil {
+
+		return "", err
+	}
+
+	return pinnedDigest(image, info)
+}
+
+// pinnedDigest returns the digest reference of an image from what the runtime reports of it
+func pinnedDigest(image string, info *container.ImageInfo) (string, error) {
+	pinned, ok := config.PinnedReference(image, info.Digests)
+	if !ok {
+
+		return "", fmt.Errorf("the registry reported no digest for '%s'", image)
+	}
+
+	return pinned, nil
+}
+
+// platformSuffix shows the platform an image is pulled for, if one was asked for
+func platformSuffix(platform string) string {
+	if platform == "" {
+
+		return ""
+	}
+
+	return " [" + platform + "]"
+}
+
+// digestOf shortens a digest reference to the start of its digest for display
+func digestOf(pinned string) string {
+	digest := pinned[strings.LastIndex(pinned, "@")+1:]
+	if len(digest) > 19 {
+		digest = digest[:19]
+	}
+
+	return digest
+}
+
+// loadLockedConfig loads the config with the images the lock file pins
+func loadLockedConfig(configFile string) (*config.ComposeConfig, error) {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	pinned, err := cfg.ApplyImageLock(configFile)
+	if err != nil {
+
+		return nil, err
+	}
+	if len(pinned) > 0 {
+		fmt.Printf("Using locked image digests from %s for: %s\n", config.LockFilePath(configFile), strings.Join(pinned, ", "))
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,161 @@
+// internal/compose/quotas.go
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// QuotaOptions configures the quota command
+type QuotaOptions struct {
+	ProxyURL string
+	APIKey   string
+	JSON     bool
+}
+
+// quotaList is what the proxy's /api/quotas returns
+type quotaList struct {
+	Month    string    `json:"month"`
+	ResetsAt time.Time `json:"resetsAt"`
+	Clients  []struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Calls  int64   `json:"calls"`
+		Cost   float64 `json:"cost"`
+		Limits *struct {
+			Calls int64   `json:"calls"`
+			Cost  float64 `json:"cost"`
+		} `json:"limits"`
+		Exceeded string `json:"exceeded"`
+	} `json:"clients"`
+}
+
+func (opts QuotaOptions) apiKey(configFile string) string {
+	if opts.APIKey != "" {
+
+		return opts.APIKey
+	}
+	if cfg, err := config.LoadConfig(configFile); err == nil {
+
+		return cfg.ProxyAuth.APIKey
+	}
+
+	return ""
+}
+
+// ListQuotas prints each client's use of its monthly quota, as the running proxy counts it
+func ListQuotas(configFile string, opts QuotaOptions) error {
+	client := &http.Client{Timeout: constants.SupportBundleProxyTimeout}
+	body, err := fetchProxyJSON(client, strings.TrimRight(opts.ProxyURL, "/")+"/api/quotas", opts.apiKey(configFile))
+	if err != nil {
+
+		return fmt.Errorf("failed to read quotas from %s (is the proxy running?): %w", opts.ProxyURL, err)
+	}
+	if opts.JSON {
+		fmt.Println(string(body))
+
+		return nil
+	}
+
+	var list quotaList
+	if err := json.Unmarshal(body, &list); err != nil {
+
+		return fmt.Errorf("failed to parse quotas: %w", err)
+	}
+	printQuotas(list)
+
+	return nil
+}
+
+// ResetQuota clears the month's usage of a client, or of every client when clientID is empty
+func ResetQuota(configFile, clientID string, opts QuotaOptions) error {
+	endpoint := strings.TrimRight(opts.ProxyURL, "/") + "/api/quotas/reset"
+	if clientID != "" {
+		endpoint = strings.TrimRight(opts.ProxyURL, "/") + "/api/quotas/" + url.PathEscape(clientID) + "/reset"
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, nil)
+	if err != nil {
+
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey := opts.apiKey(configFile); apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: constants.SupportBundleProxyTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+
+		return fmt.Errorf("failed to reach proxy at %s (is it running?): %w", opts.ProxyURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, constants.SupportBundleMaxResponseSize))
+	if err != nil {
+
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+
+			return fmt.Errorf("failed to reset quota: %s", apiErr.Error)
+		}
+
+		return fmt.Errorf("failed to reset quota: proxy returned HTTP %d", resp.StatusCode)
+	}
+
+	if clientID == "" {
+		fmt.Println("✅ Reset the monthly quota usage of every client")
+	} else {
+		fmt.Printf("✅ Reset the monthly quota usage of %s\n", clientID)
+	}
+
+	return nil
+}
+
+func printQuotas(list quotaList) {
+	fmt.Printf("Quotas for %s, resetting %s\n\n", list.Month, list.ResetsAt.Local().Format(time.DateTime))
+	if len(list.Clients) == 0 {
+		fmt.Println("No client has a quota or has called a tool this month.")
+
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, constants.TableColumnSpacing, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CLIENT\tNAME\tCALLS\tCOST\tSTATUS")
+	for _, client := range list.Clients {
+		calls, cost := fmt.Sprintf("%d", client.Calls), fmt.Sprintf("%.4f", client.Cost)
+		status := "no quota"
+		if client.Limits != nil {
+			status = "ok"
+			if client.Limits.Calls > 0 {
+				calls += fmt.Sprintf(" / %d", client.Limits.Calls)
+			}
+			if client.Limits.Cost > 0 {
+				cost += fmt.Sprintf(" / %.4f", client.Limits.Cost)
+			}
+		}
+		if client.Exceeded != "" {
+			status = client.Exceeded + " quota exceeded"
+		}
+		name := client.Name
+		if name == "" {
+			name = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", client.ID, name, calls, cost, status)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,113 @@
+// internal/compose/render.go
+package compose
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/server"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenderOptions controls config render
+type RenderOptions struct {
+	Profiles    []string // profiles to activate; defaults to MCP_COMPOSE_PROFILES
+	ShowSecrets bool     // print secret values instead of redacting them
+	Format      string   // yaml (default) or json
+}
+
+// Render prints the configuration the way the manager sees it: after env file loading, variable
+// expansion, environment overrides, profile selection and built-in server injection
+func Render(configFile string, opts RenderOptions) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	profiles := config.ActiveProfiles(opts.Profiles)
+	cfg.ApplyProfiles(profiles)
+	if _, err := server.AddBuiltInServers(cfg); err != nil {
+
+		return err
+	}
+
+	var document yaml.Node
+	if err := document.Encode(cfg); err != nil {
+
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if !opts.ShowSecrets {
+		config.RedactNode(&document)
+	}
+
+	switch opts.Format {
+	case "", "yaml":
+		var out bytes.Buffer
+		files := []string{config.DisplayName(configFile)}
+		for _, override := range config.OverrideFiles() {
+			files = append(files, config.DisplayName(override))
+		}
+		fmt.Fprintf(&out, "# Rendered from %s (environment: %s", strings.Join(files, " + "), cfg.CurrentEnv)
+		if len(profiles) > 0 {
+			fmt.Fprintf(&out, ", profiles: %s", strings.Join(profiles, ","))
+		}
+		if !opts.ShowSecrets {
+			out.WriteString(", secrets redacted")
+		}
+		out.WriteString(")\n")
+		writeEnvPrecedence(&out, configFile, cfg)
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&document); err != nil {
+
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		_ = encoder.Close()
+		_, err = os.Stdout.Write(out.Bytes())
+
+		return err
+	case "json":
+		var value interface{}
+		if err := document.Decode(&value); err != nil {
+
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		_, err = os.Stdout.Write(append(data, '\n'))
+
+		return err
+	default:
+
+		return fmt.Errorf("unknown format '%s' (supported: yaml, json)", opts.Format)
+	}
+}
+
+// writeEnvPrecedence notes in the rendered file which env files were loaded and which wins,
+// highest precedence first
+func writeEnvPrecedence(out *bytes.Buffer, configFile string, cfg *config.ComposeConfig) {
+	sources := []string{"environment"}
+	files := config.InterpolationEnvFiles(configFile)
+	for i := len(files) - 1; i >= 0; i-- {
+		sources = append(sources, config.DisplayName(files[i]))
+	}
+	fmt.Fprintf(out, "# ${VAR} expanded from: %s\n", strings.Join(sources, " > "))
+	for _, name := range sortedServerNames(cfg) {
+		if envFiles := cfg.Servers[name].EnvFile; len(envFiles) > 0 {
+			reversed := make([]string, 0, len(envFiles))
+			for i := len(envFiles) - 1; i >= 0; i-- {
+				reversed = append(reversed, envFiles[i])
+			}
+			fmt.Fprintf(out, "# servers.%s.env from: environments.%s.servers.%s.env > env > %s\n",
+				name, cfg.CurrentEnv, name, strings.Join(reversed, " > "))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// internal/compose/rolling.go
+package compose
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+)
+
+// RollingRestart restarts servers one at a time in dependency order, waiting for
+// each to report healthy before moving on. It stops at the first server that fails
+// to come back so the rest of the stack keeps serving.
+func RollingRestart(configFile string, serverNames []string, timeout time.Duration) error {
+	cfg, err := loadLockedConfig(configFile)
+	if err != nil {
+
+		return err
+	}
+
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+
+		return fmt.Errorf("failed to detect container runtime: %w", err)
+	}
+
+	order := rollingRestartOrder(cfg, serverNames)
+	if len(order) == 0 {
+		fmt.Println("No servers selected or defined to restart.")
+
+		return nil
+	}
+
+	fmt.Printf("Rolling restart of %d server(s): %v\n", len(order), order)
+
+	for i, name := range order {
+		startTime := time.Now()
+		fmt.Printf("[%d/%d] Restarting server '%s'...\n", i+1, len(order), name)
+
+		serverCfg := cfg.Servers[name]
+		serverCfg.Env = cfg.ServerEnv(name)
+		if isContainerServer(serverCfg) {
+			if owner := containerOwner(cRuntime, config.ContainerName(name), configFile); owner != "" {
+
+				return fmt.Errorf("rolling restart aborted at '%s': its container was started by %s", name, owner)
+			}
+			serverCfg.Labels = projectLabels(configFile, name, serverCfg)
+		}
+		if err := restartSingleServer(name, serverCfg, cfg, configFile, cRuntime); err != nil {
+			fmt.Printf("[✖] Server %-30s Error: %v\n", name, err)
+
+			return fmt.Errorf("rolling restart aborted at '%s': %w", name, err)
+		}
+		recordServer(configFile, name, serverCfg, cRuntime)
+
+		if err := WaitForServers(cfg, []string{name}, cRuntime, timeout); err != nil {
+			fmt.Printf("[✖] Server %-30s did not become healthy: %v\n", name, err)
+
+			return fmt.Errorf("rolling restart aborted at '%s': %w", name, err)
+		}
+
+		fmt.Printf("[✔] Server %-30s Healthy (%s)\n", name, ShortDuration(time.Since(startTime)))
+	}
+
+	fmt.Printf("\n✅ Rolling restart completed. %d server(s) restarted.\n", len(order))
+
+	return nil
+}
+
+// rollingRestartOrder returns the requested servers (or all) sorted so dependencies restart first.
+// Unlike startup, dependencies of the requested servers are not pulled in.
+func rollingRestartOrder(cfg *config.ComposeConfig, serverNames []string) []string {
+	ordered := getServersToStart(cfg, serverNames)
+	if len(serverNames) == 0 {
+
+		return ordered
+	}
+
+	requested := make(map[string]bool, len(serverNames))
+	for _, name := range serverNames {
+		requested[name] = true
+	}
+
+	result := make([]string, 0, len(serverNames))
+	for _, name := range ordered {
+		if requested[name] {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+func restartSingleServer(serverName string, serverCfg config.ServerConfig, cfg *config.ComposeConfig, configFile string, cRuntime container.Runtime) error {
+	identifier := config.ContainerName(serverName)
+
+	if isContainerServer(serverCfg) {
+		if err := cRuntime.StopContainer(identifier); err != nil {
+			fmt.Printf("Warning: error stopping container '%s': %v\n", identifier, err)
+		}
+
+		return startServerContainer(cfg, configFile, serverName, serverCfg, cRuntime)
+	}
+
+	if proc, err := runtime.FindProcess(identifier); err == nil {
+		if err := proc.Stop(); err != nil {
+			fmt.Printf("Warning: error stopping process '%s': %v\n", identifier, err)
+		}
+	}
+
+	return startServerProcess(serverName, serverCfg, cfg)
+}
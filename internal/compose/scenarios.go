@@ -0,0 +1,389 @@
+// internal/compose/scenarios.go
+package compose
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// ScenarioResult records the outcome of a single tool or resource check
+type ScenarioResult struct {
+	Scenario string `json:"scenario"`
+	Kind     string `json:"kind"`
+	Server   string `json:"server,omitempty"`
+	Target   string `json:"target"`
+	Expected string `json:"expected"`
+	Status   int    `json:"status"`
+	Passed   bool   `json:"passed"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+
+	elapsed time.Duration
+}
+
+// scenarioRunner sends the checks through the proxy, initializing each server's MCP
+// session before its first tools/call or resources/read
+type scenarioRunner struct {
+	client      *http.Client
+	baseURL     string
+	apiKey      string
+	initialized map[string]bool
+}
+
+// RunScenarios executes the testing scenarios against a running proxy.
+// Tool checks with a server send tools/call to it; without one they call the proxy's
+// direct tool endpoint (POST /<tool>). Resource checks with a server and uri send
+// resources/read; otherwise they issue a GET against the given proxy path.
+func RunScenarios(baseURL, apiKey string, scenarios []config.TestScenario) []ScenarioResult {
+	runner := &scenarioRunner{
+		client:      &http.Client{Timeout: constants.CIScenarioRequestTimeout},
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		apiKey:      apiKey,
+		initialized: make(map[string]bool),
+	}
+	results := make([]ScenarioResult, 0)
+
+	for _, scenario := range scenarios {
+		for _, tool := range scenario.Tools {
+			result := ScenarioResult{
+				Scenario: scenario.Name,
+				Kind:     "tool",
+				Server:   tool.Server,
+				Target:   tool.Name,
+				Expected: expectedOrDefault(tool.ExpectedStatus),
+			}
+
+			input := tool.Input
+			if input == nil {
+				input = map[string]interface{}{}
+			}
+
+			start := time.Now()
+			var response scenarioResponse
+			var err error
+			if tool.Server != "" {
+				response, err = runner.call(tool.Server, "tools/call", map[string]interface{}{"name": tool.Name, "arguments": input})
+			} else {
+				var body []byte
+				if body, err = json.Marshal(input); err == nil {
+					response, err = runner.do(http.MethodPost, "/"+tool.Name, body)
+				}
+			}
+			finishScenarioResult(&result, response, err, tool.Expect, time.Since(start))
+			results = append(results, result)
+		}
+
+		for _, resource := range scenario.Resources {
+			result := ScenarioResult{
+				Scenario: scenario.Name,
+				Kind:     "resource",
+				Server:   resource.Server,
+				Target:   resource.Path,
+				Expected: expectedOrDefault(resource.ExpectedStatus),
+			}
+
+			start := time.Now()
+			var response scenarioResponse
+			var err error
+			if resource.URI != "" {
+				result.Target = resource.URI
+				response, err = runner.call(resource.Server, "resources/read", map[string]interface{}{"uri": resource.URI})
+			} else {
+				path := resource.Path
+				if !strings.HasPrefix(path, "/") {
+					path = "/" + path
+				}
+				response, err = runner.do(http.MethodGet, path, nil)
+			}
+			finishScenarioResult(&result, response, err, resource.Expect, time.Since(start))
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// scenarioResponse is what a check observed: the HTTP status and body, and for MCP
+// requests the JSON-RPC result and whether the server reported an error
+type scenarioResponse struct {
+	status    int
+	body      []byte
+	result    interface{}
+	mcp       bool
+	mcpFailed bool
+	mcpError  string
+}
+
+func (r scenarioResponse) succeeded() bool {
+
+	return r.status >= 200 && r.status < 300 && !r.mcpFailed
+}
+
+// call sends a JSON-RPC request to a server through the proxy
+func (r *scenarioRunner) call(server, method string, params interface{}) (scenarioResponse, error) {
+	if !r.initialized[server] {
+		if _, err := r.rpc(server, "initialize", map[string]interface{}{
+			"protocolVersion": "2024-11-05",
+			"capabilities":    map[string]interface{}{},
+			"clientInfo":      map[string]interface{}{"name": "mcp-compose-test", "version": "1.0.0"},
+		}); err != nil {
+
+			return scenarioResponse{}, fmt.Errorf("failed to initialize server '%s': %w", server, err)
+		}
+		r.initialized[server] = true
+	}
+
+	return r.rpc(server, method, params)
+}
+
+func (r *scenarioRunner) rpc(server, method string, params interface{}) (scenarioResponse, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      time.Now().UnixNano(),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+
+		return scenarioResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	response, err := r.do(http.MethodPost, "/"+server, body)
+	if err != nil {
+
+		return response, err
+	}
+
+	response.mcp = true
+	var message struct {
+		Result interface{} `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(response.body, &message); err != nil {
+		if response.status >= 200 && response.status < 300 {
+
+			return response, fmt.Errorf("invalid JSON-RPC response: %w", err)
+		}
+
+		return response, nil
+	}
+	response.result = message.Result
+	switch {
+	case message.Error != nil:
+		response.mcpFailed = true
+		response.mcpError = fmt.Sprintf("JSON-RPC error %d: %s", message.Error.Code, message.Error.Message)
+	case isToolError(message.Result):
+		response.mcpFailed = true
+		response.mcpError = "tool returned isError"
+	}
+
+	return response, nil
+}
+
+func isToolError(result interface{}) bool {
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+
+		return false
+	}
+	isError, _ := resultMap["isError"].(bool)
+
+	return isError
+}
+
+// ScenarioStatusMatches checks an HTTP status against an expected_status value.
+// Accepts "success"/"ok" (2xx), "error"/"failure" (non-2xx) or an exact status code.
+func ScenarioStatusMatches(expected string, status int) bool {
+
+	return scenarioOutcomeMatches(expected, status, status >= 200 && status < 300)
+}
+
+// scenarioOutcomeMatches is ScenarioStatusMatches for checks that can fail with a 2xx
+// status, such as MCP requests answered with a JSON-RPC error
+func scenarioOutcomeMatches(expected string, status int, success bool) bool {
+	switch strings.ToLower(strings.TrimSpace(expected)) {
+	case "", "success", "ok":
+
+		return success
+	case "error", "failure", "fail":
+
+		return !success
+	}
+
+	if code, err := strconv.Atoi(strings.TrimSpace(expected)); err == nil {
+
+		return code == status
+	}
+
+	return false
+}
+
+func expectedOrDefault(expected string) string {
+	if expected == "" {
+
+		return "success"
+	}
+
+	return expected
+}
+
+func finishScenarioResult(result *ScenarioResult, response scenarioResponse, err error, expect config.ResponseExpectation, elapsed time.Duration) {
+	result.elapsed = elapsed
+	result.Duration = ShortDuration(elapsed)
+	result.Status = response.status
+	if err != nil {
+		result.Error = err.Error()
+
+		return
+	}
+
+	if !scenarioOutcomeMatches(result.Expected, response.status, response.succeeded()) {
+		result.Error = fmt.Sprintf("expected %s, got HTTP %d", result.Expected, response.status)
+		if response.mcpError != "" {
+			result.Error += " (" + response.mcpError + ")"
+		}
+
+		return
+	}
+	if problem := checkExpectation(expect, response); problem != "" {
+		result.Error = problem
+
+		return
+	}
+	result.Passed = true
+}
+
+// checkExpectation applies the response matchers, returning the first that fails
+func checkExpectation(expect config.ResponseExpectation, response scenarioResponse) string {
+	body := string(response.body)
+	for _, text := range expect.Contains {
+		if !strings.Contains(body, text) {
+
+			return fmt.Sprintf("response does not contain %q", text)
+		}
+	}
+	for _, text := range expect.NotContains {
+		if strings.Contains(body, text) {
+
+			return fmt.Sprintf("response contains %q", text)
+		}
+	}
+	if expect.Matches != "" {
+		pattern, err := regexp.Compile(expect.Matches)
+		if err != nil {
+
+			return fmt.Sprintf("invalid matches pattern: %v", err)
+		}
+		if !pattern.MatchString(body) {
+
+			return fmt.Sprintf("response does not match /%s/", expect.Matches)
+		}
+	}
+	if len(expect.JSON) == 0 {
+
+		return ""
+	}
+
+	document := response.result
+	if !response.mcp {
+		if err := json.Unmarshal(response.body, &document); err != nil {
+
+			return fmt.Sprintf("response is not JSON: %v", err)
+		}
+	}
+	paths := make([]string, 0, len(expect.JSON))
+	for path := range expect.JSON {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		actual, found := jsonPathValue(document, path)
+		if !found {
+
+			return fmt.Sprintf("response has no value at %s", path)
+		}
+		if expected := expect.JSON[path]; fmt.Sprint(actual) != fmt.Sprint(expected) {
+
+			return fmt.Sprintf("expected %s to be %v, got %v", path, expected, actual)
+		}
+	}
+
+	return ""
+}
+
+// jsonPathValue follows a dotted path through decoded JSON, using numbers to index arrays
+func jsonPathValue(document interface{}, path string) (interface{}, bool) {
+	current := document
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, exists := node[part]
+			if !exists {
+
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node) {
+
+				return nil, false
+			}
+			current = node[index]
+		default:
+
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func (r *scenarioRunner) do(method, path string, body []byte) (scenarioResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, r.baseURL+path, reader)
+	if err != nil {
+
+		return scenarioResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+	}
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+
+		return scenarioResponse{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, constants.ScenarioMaxResponseSize))
+	if err != nil {
+
+		return scenarioResponse{status: resp.StatusCode}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return scenarioResponse{status: resp.StatusCode, body: data}, nil
+}
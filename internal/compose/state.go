@@ -0,0 +1,275 @@
+// internal/compose/state.go
+package compose
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+)
+
+// ProjectState records what up started for a compose file, so ls and down find the project's
+// own containers and processes rather than whatever carries the same name
+type ProjectState struct {
+	Project    string                 `json:"project"`
+	ConfigFile string                 `json:"configFile"`
+	Servers    map[string]ServerState `json:"servers"`
+	UpdatedAt  time.Time              `json:"updatedAt"`
+}
+
+// ServerState records one started server
+type ServerState struct {
+	Kind        string    `json:"kind"` // "container" or "process"
+	Container   string    `json:"container,omitempty"`
+	ContainerID string    `json:"containerId,omitempty"`
+	PID         int       `json:"pid,omitempty"` // of the process's supervisor
+	Ports       []string  `json:"ports,omitempty"`
+	Networks    []string  `json:"networks,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+}
+
+// stateMu serializes updates of state files by the servers up starts in parallel
+var stateMu sync.Mutex
+
+// projectStatePath is where the state of a compose file's project is kept; each project
+// namespace started from the file has its own
+func projectStatePath(configFile string) string {
+	path := filepath.Join(filepath.Dir(configFile), constants.ProjectStateFile)
+	if project := config.ProjectNamespace(); project != "" {
+		path = strings.TrimSuffix(path, ".json") + "." + project + ".json"
+	}
+
+	return path
+}
+
+// LoadProjectState reads the recorded state of a compose file's project; a project that was
+// never started has no servers
+func LoadProjectState(configFile string) (*ProjectState, error) {
+	state := &ProjectState{
+		Project:    config.GetProjectName(configFile),
+		ConfigFile: absConfigFile(configFile),
+		Servers:    make(map[string]ServerState),
+	}
+	data, err := os.ReadFile(projectStatePath(configFile))
+	if errors.Is(err, os.ErrNotExist) {
+
+		return state, nil
+	}
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read project state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+
+		return nil, fmt.Errorf("failed to parse project state %s: %w", projectStatePath(configFile), err)
+	}
+	if state.Servers == nil {
+		state.Servers = make(map[string]ServerState)
+	}
+
+	return state, nil
+}
+
+// updateProjectState applies update to the recorded state and writes it back
+func updateProjectState(configFile string, update func(*ProjectState)) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	state, err := LoadProjectState(configFile)
+	if err != nil {
+
+		return err
+	}
+	update(state)
+	state.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+
+		return fmt.Errorf("failed to encode project state: %w", err)
+	}
+	path := projectStatePath(configFile)
+	if err := os.MkdirAll(filepath.Dir(path), constants.DefaultDirMode); err != nil {
+
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	// Written aside and renamed so a reader never sees half a file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write project state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+
+		return fmt.Errorf("failed to write project state: %w", err)
+	}
+
+	return nil
+}
+
+// recordServer records a server up just started, with what the runtime reports about it
+func recordServer(configFile, serverName string, serverCfg config.ServerConfig, cRuntime container.Runtime) {
+	identifier := config.ContainerName(serverName)
+	entry := ServerState{StartedAt: time.Now().UTC()}
+	if isContainerServer(serverCfg) {
+		entry.Kind = "container"
+		entry.Container = identifier
+		if info, err := cRuntime.GetContainerInfo(identifier); err == nil {
+			entry.ContainerID = info.ID
+			for _, port := range info.Ports {
+				if port.PublicPort > 0 {
+					entry.Ports = append(entry.Ports, fmt.Sprintf("%d:%d/%s", port.PublicPort, port.PrivatePort, port.Type))
+				}
+			}
+			for network := range info.Networks {
+				entry.Networks = append(entry.Networks, network)
+			}
+			sort.Strings(entry.Networks)
+		}
+		if entry.Ports == nil {
+			entry.Ports = serverCfg.Ports
+		}
+	} else {
+		entry.Kind = "process"
+		if proc, err := runtime.FindProcess(identifier); err == nil {
+			entry.PID, _ = proc.PID()
+		}
+	}
+
+	err := updateProjectState(configFile, func(state *ProjectState) {
+		state.Servers[serverName] = entry
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// forgetServers removes stopped servers from the recorded state
+func forgetServers(configFile string, serverNames []string) {
+	if len(serverNames) == 0 {
+
+		return
+	}
+	err := updateProjectState(configFile, func(state *ProjectState) {
+		for _, name := range serverNames {
+			delete(state.Servers, name)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// projectLabels returns a server's labels with those naming the project that runs it and
+// the hash of the config it is started with
+func projectLabels(configFile, serverName string, serverCfg config.ServerConfig) map[string]string {
+	merged := make(map[string]string, len(serverCfg.Labels)+5)
+	for key, value := range serverCfg.Labels {
+		merged[key] = value
+	}
+	for key, value := range resourceLabels(configFile) {
+		merged[key] = value
+	}
+	merged[constants.ProjectServerLabel] = serverName
+	merged[constants.ConfigHashLabel] = configHash(serverCfg)
+
+	return merged
+}
+
+// resourceLabels returns the labels of every container, network and volume of the project
+func resourceLabels(configFile string) map[string]string {
+
+	return map[string]string{
+		constants.ManagedByLabel:     constants.ManagedBy,
+		constants.ProjectLabel:       config.GetProjectName(configFile),
+		constants.ProjectConfigLabel: absConfigFile(configFile),
+	}
+}
+
+// ownedByProject reports whether labels mark a resource as created for the compose file's
+// project. Volumes created before resources were labeled with their compose file only carry
+// the project namespace, if any.
+func ownedByProject(labels map[string]string, configFile string) bool {
+	if owner, labeled := labels[constants.ProjectConfigLabel]; labeled {
+
+		return owner == absConfigFile(configFile) && labels[constants.ProjectLabel] == config.GetProjectName(configFile)
+	}
+
+	return labels[constants.ProjectLabel] == config.ProjectNamespace()
+}
+
+// configHash is a short hash of a server's config, which changes whenever the config does
+func configHash(serverCfg config.ServerConfig) string {
+	data, err := json.Marshal(serverCfg)
+	if err != nil {
+
+		return ""
+	}
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:6])
+}
+
+// containerOwner returns the compose file that started a container when it is not configFile,
+// or "" when the container is the project's own, unlabeled, or does not exist
+func containerOwner(cRuntime container.Runtime, containerName, configFile string) string {
+	info, err := cRuntime.GetContainerInfo(containerName)
+	if err != nil {
+
+		return ""
+	}
+	owner := info.Labels[constants.ProjectConfigLabel]
+	if owner == "" || owner == absConfigFile(configFile) {
+
+		return ""
+	}
+
+	return owner
+}
+
+// stateContainer returns what identifies a server's container: the ID up recorded while it
+// still exists, or its name
+func stateContainer(state *ProjectState, serverName string, cRuntime container.Runtime) string {
+	if entry, exists := state.Servers[serverName]; exists && entry.ContainerID != "" {
+		if _, err := cRuntime.GetContainerInfo(entry.ContainerID); err == nil {
+
+			return entry.ContainerID
+		}
+	}
+
+	return config.ContainerName(serverName)
+}
+
+// orphanedServers returns the recorded servers no longer in the config, such as renamed ones
+func orphanedServers(state *ProjectState, cfg *config.ComposeConfig) []string {
+	var names []string
+	for name := range state.Servers {
+		if _, exists := cfg.Servers[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func absConfigFile(configFile string) string {
+	if abs, err := filepath.Abs(configFile); err == nil {
+
+		return abs
+	}
+
+	return configFile
+}
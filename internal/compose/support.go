@@ -0,0 +1,320 @@
+// internal/compose/support.go
+package compose
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+	"github.com/phildougherty/mcp-compose/internal/server"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SupportBundleOptions controls support-bundle
+type SupportBundleOptions struct {
+	Output   string // archive path; defaults to mcp-compose-support-<timestamp>.tar.gz
+	ProxyURL string // proxy to ask for server status and health; skipped when unreachable
+	APIKey   string // proxy API key; defaults to proxy_auth.api_key
+	LogLines int    // lines of log to include per server
+	Version  string // mcp-compose version recorded in the bundle
+}
+
+// supportContainers returns the mcp-compose containers whose logs go into the bundle when present
+func supportContainers() []string {
+
+	return []string{config.ContainerName("http-proxy"), config.ContainerName("dashboard"), config.ContainerName("task-scheduler")}
+}
+
+// SupportBundle writes a tar.gz with everything needed to act on a bug report, with secrets
+// redacted, and returns its path
+func SupportBundle(configFile string, opts SupportBundleOptions) (string, error) {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return "", fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	if _, err := server.AddBuiltInServers(cfg); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+		cRuntime = container.NewNullRuntime()
+	}
+
+	path := opts.Output
+	if path == "" {
+		path = fmt.Sprintf("mcp-compose-support-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, constants.DefaultFileMode)
+	if err != nil {
+
+		return "", fmt.Errorf("failed to create bundle: %w", err)
+	}
+	if err := WriteSupportBundle(out, cfg, cRuntime, opts); err != nil {
+		_ = out.Close()
+		_ = os.Remove(path)
+
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+
+		return "", fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	return path, nil
+}
+
+// WriteSupportBundle writes the support bundle archive to w. It holds the redacted config,
+// versions, server status from the proxy (or from the runtime when the proxy is down), recent
+// logs of every server and of the mcp-compose containers, and a list of what could not be
+// collected. Every file passes through config.RedactText with the configured secret values.
+func WriteSupportBundle(w io.Writer, cfg *config.ComposeConfig, cRuntime container.Runtime, opts SupportBundleOptions) error {
+	if opts.LogLines <= 0 {
+		opts.LogLines = constants.SupportBundleLogLines
+	}
+	if opts.APIKey == "" {
+		opts.APIKey = cfg.ProxyAuth.APIKey
+	}
+
+	secrets, err := config.SecretValues(cfg)
+	if err != nil {
+
+		return fmt.Errorf("failed to collect secret values: %w", err)
+	}
+	if opts.APIKey != "" {
+		secrets = append([]string{opts.APIKey}, secrets...)
+	}
+
+	now := time.Now()
+	bundle := &supportBundle{
+		root:    fmt.Sprintf("mcp-compose-support-%s", now.Format("20060102-150405")),
+		secrets: secrets,
+		modTime: now.Truncate(time.Second),
+	}
+	gz := gzip.NewWriter(w)
+	bundle.tw = tar.NewWriter(gz)
+
+	var document yaml.Node
+	if err := document.Encode(cfg); err != nil {
+
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	config.RedactNode(&document)
+	var rendered bytes.Buffer
+	encoder := yaml.NewEncoder(&rendered)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	_ = encoder.Close()
+	bundle.add("config.yaml", rendered.Bytes())
+
+	bundle.add("versions.txt", []byte(supportVersions(cRuntime, opts.Version)))
+
+	names := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if opts.ProxyURL != "" {
+		bundle.collectProxyStatus(opts, names)
+	}
+	if snapshots, err := json.MarshalIndent(CollectSnapshots(cfg, cRuntime), "", "  "); err == nil {
+		bundle.add("status/runtime.json", snapshots)
+	}
+
+	for _, name := range names {
+		identifier := config.ContainerName(name)
+		var lines []string
+		if isContainerServer(cfg.Servers[name]) {
+			lines, err = cRuntime.GetContainerLogs(identifier, opts.LogLines)
+		} else {
+			lines, err = runtime.ProcessLogs(identifier).TailLogs(opts.LogLines)
+		}
+		if err != nil {
+			bundle.failed("logs for server '%s': %v", name, err)
+
+			continue
+		}
+		bundle.add("logs/"+name+".log", []byte(strings.Join(lines, "\n")+"\n"))
+	}
+
+	if cRuntime.GetRuntimeName() != "none" {
+		for _, identifier := range supportContainers() {
+			// A container that does not exist just means that component is not in use
+			if _, err := cRuntime.GetContainerInfo(identifier); err != nil {
+
+				continue
+			}
+			lines, err := cRuntime.GetContainerLogs(identifier, opts.LogLines)
+			if err != nil {
+				bundle.failed("logs for container %s: %v", identifier, err)
+
+				continue
+			}
+			bundle.add("logs/"+identifier+".log", []byte(strings.Join(lines, "\n")+"\n"))
+		}
+	}
+
+	if len(bundle.errors) > 0 {
+		bundle.add("errors.txt", []byte(strings.Join(bundle.errors, "\n")+"\n"))
+	}
+
+	if bundle.err != nil {
+
+		return fmt.Errorf("failed to write bundle: %w", bundle.err)
+	}
+	if err := bundle.tw.Close(); err != nil {
+
+		return fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+
+		return fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+
+	return nil
+}
+
+// supportBundle accumulates redacted files in a tar stream
+type supportBundle struct {
+	tw      *tar.Writer
+	root    string
+	secrets []string
+	modTime time.Time
+	errors  []string
+	err     error
+}
+
+// add redacts and writes one file; the first write error is kept and later files are skipped
+func (b *supportBundle) add(name string, data []byte) {
+	if b.err != nil {
+
+		return
+	}
+	data = []byte(config.RedactText(string(data), b.secrets))
+	header := &tar.Header{
+		Name:    b.root + "/" + name,
+		Mode:    int64(constants.DefaultFileMode),
+		Size:    int64(len(data)),
+		ModTime: b.modTime,
+	}
+	if err := b.tw.WriteHeader(header); err != nil {
+		b.err = err
+
+		return
+	}
+	_, b.err = b.tw.Write(data)
+}
+
+// failed records something that could not be collected
+func (b *supportBundle) failed(format string, args ...interface{}) {
+	b.errors = append(b.errors, fmt.Sprintf(format, args...))
+}
+
+// collectProxyStatus saves the proxy's status and each server's detail, including health history
+func (b *supportBundle) collectProxyStatus(opts SupportBundleOptions, names []string) {
+	client := &http.Client{Timeout: constants.SupportBundleProxyTimeout}
+	proxyURL := strings.TrimSuffix(opts.ProxyURL, "/")
+
+	status, err := fetchProxyJSON(client, proxyURL+"/api/status", opts.APIKey)
+	if err != nil {
+		b.failed("proxy status from %s: %v", proxyURL, err)
+
+		return
+	}
+	b.add("status/proxy.json", status)
+
+	for _, name := range names {
+		detail, err := fetchProxyJSON(client, proxyURL+"/api/servers/"+url.PathEscape(name)+"/detail", opts.APIKey)
+		if err != nil {
+			b.failed("proxy detail for server '%s': %v", name, err)
+
+			continue
+		}
+		b.add("status/servers/"+name+".json", detail)
+	}
+}
+
+// fetchProxyJSON gets a proxy API endpoint and returns its body indented
+func fetchProxyJSON(client *http.Client, endpoint, apiKey string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to reach proxy: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, constants.SupportBundleMaxResponseSize))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+
+		return nil, fmt.Errorf("proxy returned HTTP %d", resp.StatusCode)
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+
+		return body, nil
+	}
+
+	return indented.Bytes(), nil
+}
+
+// supportVersions describes the mcp-compose build, the host and the container runtime
+func supportVersions(cRuntime container.Runtime, version string) string {
+	if version == "" {
+		version = "unknown"
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "mcp-compose: %s\n", version)
+	fmt.Fprintf(&out, "go: %s\n", goruntime.Version())
+	fmt.Fprintf(&out, "os/arch: %s/%s\n", goruntime.GOOS, goruntime.GOARCH)
+	fmt.Fprintf(&out, "container runtime: %s\n", cRuntime.GetRuntimeName())
+
+	if name := cRuntime.GetRuntimeName(); name != "none" {
+		ctx, cancel := context.WithTimeout(context.Background(), constants.SupportBundleProxyTimeout)
+		defer cancel()
+		output, err := exec.CommandContext(ctx, name, "version").CombinedOutput()
+		if err != nil {
+			fmt.Fprintf(&out, "\n%s version failed: %v\n", name, err)
+		}
+		if len(output) > 0 {
+			fmt.Fprintf(&out, "\n$ %s version\n%s", name, output)
+		}
+	}
+
+	return out.String()
+}
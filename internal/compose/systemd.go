@@ -0,0 +1,800 @@
+// internal/compose/systemd.go
+package compose
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+)
+
+// Systemd export formats
+const (
+	SystemdFormatQuadlet = "quadlet"
+	SystemdFormatUnit    = "unit"
+)
+
+// SystemdExportOptions controls how servers are exported as systemd units
+type SystemdExportOptions struct {
+	// Format is quadlet (.container files for Podman 4.4 and later) or unit (.service
+	// files running podman run)
+	Format string
+	// OutputDir replaces the directory systemd reads the units from
+	OutputDir string
+	// System writes units for the system manager, started as root, instead of for the
+	// current user's manager
+	System bool
+	// Stdout prints the units instead of writing them
+	Stdout bool
+	// Proxy also writes units for the proxy and, when it is enabled, the dashboard. The
+	// proxy reaches stdio process servers through their supervisors, so those get units too.
+	Proxy bool
+	// ProxyPort is the port the proxy unit listens on
+	ProxyPort int
+}
+
+// systemdHeader starts every generated file
+const systemdHeader = "# Generated by mcp-compose\n"
+
+// systemdFile is one generated unit or environment file
+type systemdFile struct {
+	dir     string
+	name    string
+	content string
+	secret  bool // readable by its owner only
+}
+
+// SystemdExport writes a systemd unit for each of the named servers, or for all servers, and
+// their dependencies, so Podman starts them on boot without mcp-compose or a daemon running.
+// Process-based servers that serve HTTP become plain services. Each server's environment
+// goes in an environment file beside the units, readable by its owner only.
+func SystemdExport(configFile string, serverNames []string, opts SystemdExportOptions) error {
+	switch opts.Format {
+	case "":
+		opts.Format = SystemdFormatQuadlet
+	case SystemdFormatQuadlet, SystemdFormatUnit:
+	default:
+
+		return fmt.Errorf("unknown format '%s'; use %s or %s", opts.Format, SystemdFormatQuadlet, SystemdFormatUnit)
+	}
+	cfg, err := loadLockedConfig(configFile)
+	if err != nil {
+
+		return err
+	}
+	dirs, err := systemdDirs(opts)
+	if err != nil {
+
+		return err
+	}
+	podman, err := exec.LookPath("podman")
+	if err != nil {
+		podman = "/usr/bin/podman"
+	}
+	self, err := os.Executable()
+	if err != nil {
+		self = "mcp-compose"
+	}
+	podmanSocket, _ := container.PodmanSocket()
+	configDir, err := filepath.Abs(filepath.Dir(configFile))
+	if err != nil {
+
+		return fmt.Errorf("failed to resolve the config directory: %w", err)
+	}
+	quadletDir, unitDir := dirs.quadlet, dirs.unit
+
+	e := &systemdExporter{cfg: cfg, opts: opts, dirs: dirs, podman: podman, self: self, podmanSocket: podmanSocket, configDir: configDir}
+	var files []systemdFile
+	networks := make(map[string]bool)
+	var services []string
+	for _, name := range getServersToStart(cfg, serverNames) {
+		serverCfg, exists := cfg.Servers[name]
+		if !exists {
+
+			return fmt.Errorf("server '%s' not found in config", name)
+		}
+		unitName := config.ContainerName(name)
+		if len(serverCfg.Init) > 0 {
+			fmt.Printf("Note: the units do not run the init jobs of '%s'; run 'mcp-compose jobs run %s' before starting them\n", name, name)
+		}
+
+		if serverCfg.Image == "" && serverCfg.Build.Context == "" {
+			if serverCfg.UsesStdio() && !opts.Proxy {
+				fmt.Printf("Skipping '%s': a process server speaking stdio is reached through the proxy; 'mcp-compose generate systemd' writes a unit for both\n", name)
+
+				continue
+			}
+			env := config.MergeEnv(serverCfg.Env, map[string]string{"MCP_SERVER_NAME": name})
+			envFile, err := e.envFile(unitName, env, false)
+			if err != nil {
+
+				return err
+			}
+			files = append(files, envFile)
+			files = append(files, systemdFile{dir: unitDir, name: unitName + ".service", content: e.processUnit(name, serverCfg, envFile)})
+			services = append(services, unitName)
+
+			continue
+		}
+
+		containerOpts := convertSecurityConfig(cfg, name, serverCfg)
+		applyTransportEnv(serverCfg, containerOpts.Env)
+		envFile, err := e.envFile(unitName, containerOpts.Env, true)
+		if err != nil {
+
+			return err
+		}
+		if envFile.name != "" {
+			files = append(files, envFile)
+		}
+		if containerOpts.Image == "" {
+			containerOpts.Image = fmt.Sprintf("mcp-compose-built-%s:latest", strings.ToLower(containerOpts.Name))
+			fmt.Printf("Note: '%s' runs the image built from %s; build it with 'podman build -t %s %s' first\n",
+				name, serverCfg.Build.Context, containerOpts.Image, serverCfg.Build.Context)
+		}
+		if containerOpts.NetworkMode == "" {
+			for _, network := range containerOpts.Networks {
+				networks[network] = true
+			}
+		}
+
+		if opts.Format == SystemdFormatQuadlet {
+			files = append(files, systemdFile{dir: quadletDir, name: unitName + ".container", content: e.quadletContainer(name, serverCfg, &containerOpts, envFile)})
+		} else {
+			files = append(files, systemdFile{dir: unitDir, name: unitName + ".service", content: e.containerUnit(name, serverCfg, &containerOpts, envFile)})
+		}
+		services = append(services, unitName)
+	}
+	if opts.Proxy && len(services) > 0 {
+		proxyFiles, err := e.proxyUnits(configFile, services)
+		if err != nil {
+
+			return err
+		}
+		for _, file := range proxyFiles {
+			files = append(files, file)
+			if strings.HasSuffix(file.name, ".service") {
+				services = append(services, strings.TrimSuffix(file.name, ".service"))
+			}
+		}
+	}
+	if opts.Format == SystemdFormatQuadlet {
+		names := make([]string, 0, len(networks))
+		for network := range networks {
+			names = append(names, network)
+		}
+		sort.Strings(names)
+		for _, network := range names {
+			files = append(files, systemdFile{dir: quadletDir, name: network + ".network", content: quadletNetwork(network)})
+		}
+	}
+	if len(files) == 0 {
+		fmt.Println("No servers to export.")
+
+		return nil
+	}
+
+	if opts.Stdout {
+		for _, file := range files {
+			if file.name == "" {
+
+				continue
+			}
+			fmt.Printf("# %s\n%s\n", filepath.Join(file.dir, file.name), file.content)
+		}
+
+		return nil
+	}
+	for _, file := range files {
+		if file.name == "" {
+
+			continue
+		}
+		if err := os.MkdirAll(file.dir, constants.DefaultDirMode); err != nil {
+
+			return fmt.Errorf("failed to create %s: %w", file.dir, err)
+		}
+		mode := os.FileMode(constants.DefaultFileMode)
+		if file.secret {
+			mode = constants.SecretFileMode
+		}
+		path := filepath.Join(file.dir, file.name)
+		if err := os.WriteFile(path, []byte(file.content), mode); err != nil {
+
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	printSystemdNextSteps(opts, services)
+
+	return nil
+}
+
+// systemdOutputDirs are where the generated files go
+type systemdOutputDirs struct {
+	quadlet string // .container and .network files, read by Podman's quadlet generator
+	unit    string // .service files
+	env     string // environment files the units load
+}
+
+// systemdDirs returns where systemd reads quadlets and units from
+func systemdDirs(opts SystemdExportOptions) (systemdOutputDirs, error) {
+	if opts.OutputDir != "" {
+
+		return systemdOutputDirs{quadlet: opts.OutputDir, unit: opts.OutputDir, env: opts.OutputDir}, nil
+	}
+	if opts.System {
+
+		return systemdOutputDirs{quadlet: "/etc/containers/systemd", unit: "/etc/systemd/system", env: "/etc/mcp-compose"}, nil
+	}
+	configHome, err := os.UserConfigDir()
+	if err != nil {
+
+		return systemdOutputDirs{}, fmt.Errorf("failed to find the user config directory: %w", err)
+	}
+
+	return systemdOutputDirs{
+		quadlet: filepath.Join(configHome, "containers", "systemd"),
+		unit:    filepath.Join(configHome, "systemd", "user"),
+		env:     filepath.Join(configHome, "mcp-compose"),
+	}, nil
+}
+
+func printSystemdNextSteps(opts SystemdExportOptions, services []string) {
+	systemctl := "systemctl --user"
+	if opts.System {
+		systemctl = "sudo systemctl"
+	}
+	fmt.Println("\nTo start the servers now and on every boot:")
+	fmt.Printf("  %s daemon-reload\n", systemctl)
+	if opts.Format == SystemdFormatQuadlet {
+		// Quadlet units are enabled through their [Install] section by the generator
+		fmt.Printf("  %s start %s\n", systemctl, strings.Join(services, " "))
+	} else {
+		fmt.Printf("  %s enable --now %s\n", systemctl, strings.Join(services, " "))
+	}
+	if !opts.System {
+		fmt.Println("  loginctl enable-linger $USER   # start them at boot, before you log in")
+	}
+}
+
+// systemdExporter renders the units of one config
+type systemdExporter struct {
+	cfg          *config.ComposeConfig
+	opts         SystemdExportOptions
+	dirs         systemdOutputDirs
+	podman       string
+	self         string // the mcp-compose executable
+	podmanSocket string
+	configDir    string
+}
+
+// envFile renders the environment file of a unit, or returns an empty file when there is
+// no environment. Podman reads values literally and can't hold a value spanning lines;
+// systemd reads them quoted.
+func (e *systemdExporter) envFile(unitName string, env map[string]string, forPodman bool) (systemdFile, error) {
+	if len(env) == 0 {
+
+		return systemdFile{}, nil
+	}
+	var b strings.Builder
+	b.WriteString(systemdHeader)
+	for _, key := range sortedKeys(env) {
+		value := env[key]
+		if !forPodman {
+			value = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+		} else if strings.ContainsAny(value, "\r\n") {
+
+			return systemdFile{}, fmt.Errorf("env %s of %s spans several lines, which Podman environment files can't hold", key, unitName)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", key, value)
+	}
+
+	return systemdFile{dir: e.dirs.env, name: unitName + ".env", content: b.String(), secret: true}, nil
+}
+
+// path returns where a generated file is written
+func (f systemdFile) path() string {
+
+	return filepath.Join(f.dir, f.name)
+}
+
+// unitSection renders the [Unit] section, ordering the server after its dependencies
+func (e *systemdExporter) unitSection(name string, serverCfg config.ServerConfig) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=MCP server %s (mcp-compose)\n", name)
+	b.WriteString("Wants=network-online.target\nAfter=network-online.target\n")
+	for _, dep := range serverCfg.DependsOn {
+		fmt.Fprintf(&b, "Requires=%[1]s.service\nAfter=%[1]s.service\n", config.ContainerName(dep))
+	}
+	if _, maxRetries, found := strings.Cut(serverCfg.EffectiveRestartPolicy(), ":"); found {
+		fmt.Fprintf(&b, "StartLimitBurst=%s\n", maxRetries)
+	}
+
+	return b.String()
+}
+
+// serviceRestart renders the Restart= setting for a restart policy.
+// Containers restart unless told otherwise, the way mcp-compose runs them.
+func serviceRestart(policy string, isContainer bool) string {
+	mode, _, _ := strings.Cut(policy, ":")
+	switch mode {
+	case "always", "unless-stopped":
+
+		return "Restart=always\n"
+	case "on-failure":
+
+		return "Restart=on-failure\n"
+	case "":
+		if isContainer {
+
+			return "Restart=always\n"
+		}
+	}
+
+	return "Restart=no\n"
+}
+
+func (e *systemdExporter) installSection() string {
+	if e.opts.System {
+
+		return "[Install]\nWantedBy=multi-user.target\n"
+	}
+
+	return "[Install]\nWantedBy=default.target\n"
+}
+
+// volumes returns the server's mounts with relative host paths resolved against the config
+// directory, since units don't run from it, and the Docker socket mapped to Podman's
+func (e *systemdExporter) volumes(volumes []string) []string {
+	resolved := make([]string, 0, len(volumes))
+	for _, volume := range container.MapDockerSocket(volumes, e.podmanSocket) {
+		source, rest, hasTarget := strings.Cut(volume, ":")
+		if hasTarget && strings.HasPrefix(source, ".") {
+			if abs, err := filepath.Abs(filepath.Join(e.configDir, source)); err == nil {
+				volume = abs + ":" + rest
+			}
+		}
+		resolved = append(resolved, volume)
+	}
+
+	return resolved
+}
+
+// userns returns the user namespace a container runs in under the exported manager
+func (e *systemdExporter) userns(opts *container.ContainerOptions) string {
+	if e.opts.System {
+
+		return opts.UsernsMode
+	}
+
+	return container.RootlessUserns(opts)
+}
+
+// quadletContainer renders a .container file for Podman's quadlet generator
+func (e *systemdExporter) quadletContainer(name string, serverCfg config.ServerConfig, opts *container.ContainerOptions, envFile systemdFile) string {
+	var b strings.Builder
+	b.WriteString(systemdHeader)
+	b.WriteString(e.unitSection(name, serverCfg))
+	b.WriteString("\n[Container]\n")
+	fmt.Fprintf(&b, "ContainerName=%s\n", opts.Name)
+	fmt.Fprintf(&b, "Image=%s\n", opts.Image)
+	if opts.Command != "" {
+		fmt.Fprintf(&b, "Exec=%s\n", systemdCommandLine(append([]string{opts.Command}, opts.Args...)))
+	}
+	if envFile.name != "" {
+		fmt.Fprintf(&b, "EnvironmentFile=%s\n", systemdQuote(envFile.path(), false))
+	}
+	for _, port := range opts.Ports {
+		fmt.Fprintf(&b, "PublishPort=%s\n", port)
+	}
+	for _, volume := range e.volumes(opts.Volumes) {
+		fmt.Fprintf(&b, "Volume=%s\n", systemdQuote(volume, false))
+	}
+	if opts.NetworkMode != "" {
+		fmt.Fprintf(&b, "Network=%s\n", opts.NetworkMode)
+	} else {
+		for _, network := range opts.Networks {
+			fmt.Fprintf(&b, "Network=%s.network\n", network)
+		}
+	}
+	if opts.WorkDir != "" {
+		fmt.Fprintf(&b, "WorkingDir=%s\n", opts.WorkDir)
+	}
+	if opts.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", opts.User)
+	}
+	if userns := e.userns(opts); userns != "" {
+		fmt.Fprintf(&b, "UserNS=%s\n", userns)
+	}
+	if opts.ReadOnly {
+		b.WriteString("ReadOnly=true\n")
+	}
+	for _, tmpfs := range opts.Tmpfs {
+		fmt.Fprintf(&b, "Tmpfs=%s\n", tmpfs)
+	}
+	for _, capability := range opts.CapAdd {
+		fmt.Fprintf(&b, "AddCapability=%s\n", capability)
+	}
+	for _, capability := range opts.CapDrop {
+		fmt.Fprintf(&b, "DropCapability=%s\n", capability)
+	}
+	for _, device := range container.CDIGPUDevices(opts.GPUs) {
+		fmt.Fprintf(&b, "AddDevice=%s\n", device)
+	}
+	if opts.Hostname != "" {
+		fmt.Fprintf(&b, "HostName=%s\n", opts.Hostname)
+	}
+	for _, dns := range opts.DNS {
+		fmt.Fprintf(&b, "DNS=%s\n", dns)
+	}
+	for _, host := range opts.ExtraHosts {
+		fmt.Fprintf(&b, "AddHost=%s\n", host)
+	}
+	for _, key := range sortedKeys(opts.Labels) {
+		fmt.Fprintf(&b, "Label=%s\n", systemdQuote(key+"="+opts.Labels[key], false))
+	}
+	if hc := opts.HealthCheck; hc != nil {
+		if len(hc.Test) > 1 {
+			fmt.Fprintf(&b, "HealthCmd=%s\n", systemdQuote(strings.Join(hc.Test[1:], " "), true))
+		}
+		if hc.Interval != "" {
+			fmt.Fprintf(&b, "HealthInterval=%s\n", hc.Interval)
+		}
+		if hc.Timeout != "" {
+			fmt.Fprintf(&b, "HealthTimeout=%s\n", hc.Timeout)
+		}
+		if hc.Retries > 0 {
+			fmt.Fprintf(&b, "HealthRetries=%d\n", hc.Retries)
+		}
+		if hc.StartPeriod != "" {
+			fmt.Fprintf(&b, "HealthStartPeriod=%s\n", hc.StartPeriod)
+		}
+	}
+	// Settings without a quadlet key of their own are passed to podman run
+	if args := extraPodmanArgs(opts); len(args) > 0 {
+		fmt.Fprintf(&b, "PodmanArgs=%s\n", systemdCommandLine(args))
+	}
+
+	b.WriteString("\n[Service]\n")
+	b.WriteString(serviceRestart(opts.RestartPolicy, true))
+	// Pulling the image can take a while on first start
+	b.WriteString("TimeoutStartSec=900\n")
+	b.WriteString("\n")
+	b.WriteString(e.installSection())
+
+	return b.String()
+}
+
+// containerUnit renders a .service file that runs the container with podman run
+func (e *systemdExporter) containerUnit(name string, serverCfg config.ServerConfig, opts *container.ContainerOptions, envFile systemdFile) string {
+	run := []string{"--cgroups=no-conmon", "--rm", "--sdnotify=conmon", "--replace", "-d", "--name", opts.Name}
+	if envFile.name != "" {
+		run = append(run, "--env-file", envFile.path())
+	}
+	for _, port := range opts.Ports {
+		run = append(run, "-p", port)
+	}
+	for _, volume := range e.volumes(opts.Volumes) {
+		run = append(run, "-v", volume)
+	}
+	if opts.NetworkMode != "" {
+		run = append(run, "--network", opts.NetworkMode)
+	} else {
+		for _, network := range opts.Networks {
+			run = append(run, "--network", network)
+		}
+	}
+	if opts.WorkDir != "" {
+		run = append(run, "-w", opts.WorkDir)
+	}
+	if opts.User != "" {
+		run = append(run, "--user", opts.User)
+	}
+	if userns := e.userns(opts); userns != "" {
+		run = append(run, "--userns", userns)
+	}
+	if opts.ReadOnly {
+		run = append(run, "--read-only")
+	}
+	for _, tmpfs := range opts.Tmpfs {
+		run = append(run, "--tmpfs", tmpfs)
+	}
+	for _, capability := range opts.CapAdd {
+		run = append(run, "--cap-add", capability)
+	}
+	for _, capability := range opts.CapDrop {
+		run = append(run, "--cap-drop", capability)
+	}
+	for _, device := range container.CDIGPUDevices(opts.GPUs) {
+		run = append(run, "--device", device)
+	}
+	if opts.Hostname != "" {
+		run = append(run, "--hostname", opts.Hostname)
+	}
+	for _, dns := range opts.DNS {
+		run = append(run, "--dns", dns)
+	}
+	for _, host := range opts.ExtraHosts {
+		run = append(run, "--add-host", host)
+	}
+	for _, key := range sortedKeys(opts.Labels) {
+		run = append(run, "--label", key+"="+opts.Labels[key])
+	}
+	if hc := opts.HealthCheck; hc != nil {
+		if len(hc.Test) > 1 {
+			run = append(run, "--health-cmd", strings.Join(hc.Test[1:], " "))
+		}
+		if hc.Interval != "" {
+			run = append(run, "--health-interval", hc.Interval)
+		}
+		if hc.Timeout != "" {
+			run = append(run, "--health-timeout", hc.Timeout)
+		}
+		if hc.Retries > 0 {
+			run = append(run, "--health-retries", strconv.Itoa(hc.Retries))
+		}
+		if hc.StartPeriod != "" {
+			run = append(run, "--health-start-period", hc.StartPeriod)
+		}
+	}
+	run = append(run, extraPodmanArgs(opts)...)
+	run = append(run, opts.Image)
+	if opts.Command != "" {
+		run = append(run, opts.Command)
+		run = append(run, opts.Args...)
+	}
+
+	var b strings.Builder
+	b.WriteString(systemdHeader)
+	b.WriteString(e.unitSection(name, serverCfg))
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Environment=PODMAN_SYSTEMD_UNIT=%n\n")
+	b.WriteString(serviceRestart(opts.RestartPolicy, true))
+	b.WriteString("TimeoutStartSec=900\n")
+	if opts.NetworkMode == "" {
+		for _, network := range opts.Networks {
+			fmt.Fprintf(&b, "ExecStartPre=%s network create --ignore %s\n", e.podman, network)
+		}
+	}
+	b.WriteString("ExecStartPre=/bin/rm -f %t/%n.ctr-id\n")
+	fmt.Fprintf(&b, "ExecStart=%s run --cidfile=%%t/%%n.ctr-id %s\n", e.podman, systemdCommandLine(run))
+	fmt.Fprintf(&b, "ExecStop=%s stop --ignore -t 10 --cidfile=%%t/%%n.ctr-id\n", e.podman)
+	fmt.Fprintf(&b, "ExecStopPost=%s rm -f --ignore -t 10 --cidfile=%%t/%%n.ctr-id\n", e.podman)
+	b.WriteString("Type=notify\nNotifyAccess=all\n")
+	b.WriteString("\n")
+	b.WriteString(e.installSection())
+
+	return b.String()
+}
+
+// processUnit renders a .service file that runs a process-based server. A server serving
+// HTTP runs directly under systemd's restart policy; a stdio server runs under an mcp-compose
+// supervisor, which keeps its stdin and stdout open for the proxy and applies the policy itself.
+func (e *systemdExporter) processUnit(name string, serverCfg config.ServerConfig, envFile systemdFile) string {
+	command := serverCfg.Command
+	if path, err := exec.LookPath(command); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			command = abs
+		}
+	}
+	commandLine := append([]string{command}, serverCfg.Args...)
+	restart := serviceRestart(serverCfg.EffectiveRestartPolicy(), false)
+	if serverCfg.UsesStdio() {
+		supervisor := runtime.ServiceSupervisorArgs(command, serverCfg.Args, runtime.ProcessOptions{
+			Name:          config.ContainerName(name),
+			RestartPolicy: serverCfg.EffectiveRestartPolicy(),
+			LogRotation:   runtime.LogRotationFromConfig(e.cfg.Logging.Retention),
+			CrashLoop:     runtime.CrashLoopLimitsFromConfig(serverCfg.CrashLoop),
+			Stdio:         true,
+		})
+		commandLine = append([]string{e.self}, supervisor...)
+		// The supervisor restarts the server; systemd restarts only the supervisor
+		restart = "Restart=on-failure\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(systemdHeader)
+	b.WriteString(e.unitSection(name, serverCfg))
+	b.WriteString("\n[Service]\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdCommandLine(commandLine))
+	workDir := serverCfg.WorkDir
+	if workDir == "" {
+		workDir = e.configDir
+	}
+	if abs, err := filepath.Abs(workDir); err == nil {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(abs, false))
+	}
+	if envFile.name != "" {
+		fmt.Fprintf(&b, "EnvironmentFile=%s\n", systemdQuote(envFile.path(), false))
+	}
+	b.WriteString(restart)
+	b.WriteString("\n")
+	b.WriteString(e.installSection())
+
+	return b.String()
+}
+
+// proxyUnits renders the units of the proxy, which wants every server unit, and of the
+// dashboard when it is enabled. They load the config themselves, so their environment file
+// holds the variables it refers to as they are set now.
+func (e *systemdExporter) proxyUnits(configFile string, services []string) ([]systemdFile, error) {
+	names, err := config.ReferencedEnv(configFile)
+	if err != nil {
+
+		return nil, err
+	}
+	names = append(names, "MCP_ENV", "MCP_COMPOSE_PROFILES", config.ProjectNameEnv, "CONTAINER_HOST", "DOCKER_HOST", config.AgeKeyEnv)
+	env := make(map[string]string)
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+	// The units may run as another user, whose default key file is elsewhere
+	if keyFile := config.AgeKeyFile(); keyFile != "" && config.HasEncryptedValues(configFile) {
+		if abs, err := filepath.Abs(keyFile); err == nil {
+			env[config.AgeKeyFileEnv] = abs
+		}
+	}
+	envFile, err := e.envFile(config.ContainerName("proxy"), env, false)
+	if err != nil {
+
+		return nil, err
+	}
+
+	configPath, err := filepath.Abs(configFile)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to resolve the config file: %w", err)
+	}
+	files := []string{"--file", configPath}
+	for _, override := range config.OverrideFiles() {
+		if abs, err := filepath.Abs(override); err == nil {
+			files = append(files, "--file", abs)
+		}
+	}
+	port := e.opts.ProxyPort
+	if port == 0 {
+		port = constants.DefaultProxyPort
+	}
+
+	after := make([]string, len(services))
+	for i, service := range services {
+		after[i] = service + ".service"
+	}
+	units := []systemdFile{envFile, {
+		dir:  e.dirs.unit,
+		name: config.ContainerName("proxy") + ".service",
+		content: e.serviceUnit("MCP proxy", after, nil,
+			append(append([]string{e.self, "proxy"}, files...), "--port", strconv.Itoa(port)), envFile),
+	}}
+	if e.cfg.Dashboard.Enabled {
+		units = append(units, systemdFile{
+			dir:  e.dirs.unit,
+			name: config.ContainerName("dashboard") + ".service",
+			content: e.serviceUnit("MCP dashboard", nil, []string{config.ContainerName("proxy") + ".service"},
+				append(append([]string{e.self, "dashboard"}, files...), "--native"), envFile),
+		})
+	}
+
+	return units, nil
+}
+
+// serviceUnit renders a .service file for an mcp-compose command that wants the units in
+// wants and requires those in requires, starting after both
+func (e *systemdExporter) serviceUnit(description string, wants, requires, commandLine []string, envFile systemdFile) string {
+	var b strings.Builder
+	b.WriteString(systemdHeader)
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s (mcp-compose)\n", description)
+	b.WriteString("Wants=network-online.target\nAfter=network-online.target\n")
+	for _, unit := range wants {
+		fmt.Fprintf(&b, "Wants=%s\nAfter=%s\n", unit, unit)
+	}
+	for _, unit := range requires {
+		fmt.Fprintf(&b, "Requires=%s\nAfter=%s\n", unit, unit)
+	}
+	b.WriteString("\n[Service]\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdCommandLine(commandLine))
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(e.configDir, false))
+	if envFile.name != "" {
+		fmt.Fprintf(&b, "EnvironmentFile=%s\n", systemdQuote(envFile.path(), false))
+	}
+	b.WriteString("Restart=always\n")
+	b.WriteString("\n")
+	b.WriteString(e.installSection())
+
+	return b.String()
+}
+
+// quadletNetwork renders a .network file creating a network the containers join
+func quadletNetwork(name string) string {
+
+	return fmt.Sprintf("%s[Network]\nNetworkName=%s\n", systemdHeader, name)
+}
+
+// extraPodmanArgs returns the podman run flags for settings quadlet has no key for
+func extraPodmanArgs(opts *container.ContainerOptions) []string {
+	var args []string
+	for _, group := range opts.Groups {
+		args = append(args, "--group-add", group)
+	}
+	if opts.Privileged {
+		args = append(args, "--privileged")
+	}
+	for _, opt := range opts.SecurityOpt {
+		args = append(args, "--security-opt", opt)
+	}
+	if opts.CPUs != "" {
+		args = append(args, "--cpus", opts.CPUs)
+	}
+	if opts.Memory != "" {
+		args = append(args, "--memory", opts.Memory)
+	}
+	if opts.MemorySwap != "" {
+		args = append(args, "--memory-swap", opts.MemorySwap)
+	}
+	if opts.PidsLimit > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(opts.PidsLimit))
+	}
+	if opts.DomainName != "" {
+		args = append(args, "--domainname", opts.DomainName)
+	}
+	for _, search := range opts.DNSSearch {
+		args = append(args, "--dns-search", search)
+	}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+	if opts.StopSignal != "" {
+		args = append(args, "--stop-signal", opts.StopSignal)
+	}
+	if opts.StopTimeout != nil {
+		args = append(args, "--stop-timeout", strconv.Itoa(*opts.StopTimeout))
+	}
+	if opts.LogDriver != "" {
+		args = append(args, "--log-driver", opts.LogDriver)
+	}
+	for _, key := range sortedKeys(opts.LogOptions) {
+		args = append(args, "--log-opt", key+"="+opts.LogOptions[key])
+	}
+
+	return args
+}
+
+// systemdCommandLine joins a command line for ExecStart= and similar settings
+func systemdCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = systemdQuote(arg, true)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// systemdQuote escapes a value for a unit file: % starts a specifier and, on command lines,
+// $ a variable; values with spaces, quotes or backslashes are double-quoted
+func systemdQuote(value string, commandLine bool) string {
+	value = strings.ReplaceAll(value, "%", "%%")
+	if commandLine {
+		value = strings.ReplaceAll(value, "$", "$$")
+	}
+	if value != "" && !strings.ContainsAny(value, " \t\"'\\;") {
+
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+
+	return `"` + value + `"`
+}
@@ -0,0 +1,339 @@
+// internal/compose/test.go
+package compose
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+// TestOptions controls a test run
+type TestOptions struct {
+	Scenarios []string // scenario names to run; all when empty
+	Timeout   time.Duration
+	JUnitFile string
+	JSONFile  string
+	KeepUp    bool
+}
+
+// TestReport is the JSON report of a test run
+type TestReport struct {
+	Project   string           `json:"project"`
+	StartedAt time.Time        `json:"started_at"`
+	Duration  string           `json:"duration"`
+	Passed    bool             `json:"passed"`
+	Total     int              `json:"total"`
+	Failed    int              `json:"failed"`
+	Results   []ScenarioResult `json:"results"`
+}
+
+// Test runs the testing scenarios from development.testing against the stack. The servers
+// the scenarios need are brought up if they aren't running, and those it started are
+// stopped again afterwards. The returned error is nil only when every check passed.
+func Test(configFile string, opts TestOptions) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+
+	scenarios, err := selectScenarios(cfg.Development.Testing.Scenarios, opts.Scenarios)
+	if err != nil {
+
+		return err
+	}
+
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+		cRuntime = container.NewNullRuntime()
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = constants.CIDefaultWaitTimeout
+	}
+
+	report := &TestReport{
+		Project:   config.GetProjectName(configFile),
+		StartedAt: time.Now(),
+	}
+
+	serverNames := scenarioServers(cfg, scenarios)
+	var started []string
+	for _, name := range serverNames {
+		if ready, _ := ServerReady(name, cfg.Servers[name], cRuntime); !ready {
+			started = append(started, name)
+		}
+	}
+	defer func() {
+		if len(started) == 0 {
+
+			return
+		}
+		if opts.KeepUp {
+			fmt.Printf("Leaving %s running (--keep)\n", strings.Join(started, ", "))
+
+			return
+		}
+		if err := Down(configFile, started); err != nil {
+			fmt.Printf("Warning: failed to stop servers started for the tests: %v\n", err)
+		}
+	}()
+
+	if len(started) > 0 {
+		fmt.Printf("=== Starting %s ===\n", strings.Join(started, ", "))
+		if err := Up(configFile, started); err != nil {
+
+			return fmt.Errorf("failed to start servers: %w", err)
+		}
+	}
+	if err := WaitForServers(cfg, serverNames, cRuntime, opts.Timeout); err != nil {
+
+		return err
+	}
+
+	apiKey := ""
+	if cfg.ProxyAuth.Enabled {
+		apiKey = cfg.ProxyAuth.APIKey
+	}
+	proxyURL, stopProxy, err := startCIProxy(configFile, cfg, cRuntime, apiKey)
+	if err != nil {
+
+		return err
+	}
+	fmt.Printf("=== Running %d scenario(s) ===\n", len(scenarios))
+	report.Results = RunScenarios(proxyURL, apiKey, scenarios)
+	stopProxy()
+
+	report.Duration = ShortDuration(time.Since(report.StartedAt))
+	report.Total = len(report.Results)
+	for _, result := range report.Results {
+		if !result.Passed {
+			report.Failed++
+		}
+	}
+	report.Passed = report.Failed == 0
+	printTestReport(report)
+
+	if opts.JSONFile != "" {
+		if err := writeTestJSON(report, opts.JSONFile); err != nil {
+
+			return err
+		}
+	}
+	if opts.JUnitFile != "" {
+		if err := writeTestJUnit(report, opts.JUnitFile); err != nil {
+
+			return err
+		}
+	}
+
+	if !report.Passed {
+
+		return fmt.Errorf("%d of %d check(s) failed", report.Failed, report.Total)
+	}
+
+	return nil
+}
+
+func selectScenarios(scenarios []config.TestScenario, names []string) ([]config.TestScenario, error) {
+	if len(scenarios) == 0 {
+
+		return nil, fmt.Errorf("no test scenarios defined under development.testing.scenarios")
+	}
+	if len(names) == 0 {
+
+		return scenarios, nil
+	}
+
+	selected := make([]config.TestScenario, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, scenario := range scenarios {
+			if scenario.Name == name {
+				selected = append(selected, scenario)
+				found = true
+
+				break
+			}
+		}
+		if !found {
+
+			return nil, fmt.Errorf("test scenario '%s' not found", name)
+		}
+	}
+
+	return selected, nil
+}
+
+// scenarioServers returns the servers the scenarios use. Checks that go through the
+// proxy's direct tool endpoint or a proxy path could reach any server, so they need all.
+func scenarioServers(cfg *config.ComposeConfig, scenarios []config.TestScenario) []string {
+	needed := make(map[string]bool)
+	all := false
+	for _, scenario := range scenarios {
+		for _, name := range scenario.Servers {
+			needed[name] = true
+		}
+		for _, tool := range scenario.Tools {
+			if tool.Server == "" {
+				all = true
+			}
+			needed[tool.Server] = true
+		}
+		for _, resource := range scenario.Resources {
+			if resource.Server == "" {
+				all = true
+			}
+			needed[resource.Server] = true
+		}
+	}
+
+	names := make([]string, 0, len(cfg.Servers))
+	for name, srvCfg := range cfg.Servers {
+		if needed[name] || (all && srvCfg.EnabledFor(config.ActiveProfiles(nil))) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func printTestReport(report *TestReport) {
+	fmt.Println()
+	scenario := ""
+	for _, result := range report.Results {
+		if result.Scenario != scenario {
+			scenario = result.Scenario
+			fmt.Printf("%s\n", scenario)
+		}
+		marker := "[✔]"
+		if !result.Passed {
+			marker = "[✖]"
+		}
+		target := result.Target
+		if result.Server != "" {
+			target = result.Server + "/" + target
+		}
+		fmt.Printf("  %s %s %s (%s)", marker, result.Kind, target, result.Duration)
+		if result.Error != "" {
+			fmt.Printf(" - %s", result.Error)
+		}
+		fmt.Println()
+	}
+
+	if report.Passed {
+		fmt.Printf("\n✅ %d check(s) passed in %s\n", report.Total, report.Duration)
+	} else {
+		fmt.Printf("\n%d of %d check(s) failed in %s\n", report.Failed, report.Total, report.Duration)
+	}
+}
+
+func writeTestJSON(report *TestReport, path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+
+		return fmt.Errorf("failed to marshal test report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write test report: %w", err)
+	}
+
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeTestJUnit writes the results as JUnit XML, one test suite per scenario, which CI
+// systems render as test results
+func writeTestJUnit(report *TestReport, path string) error {
+	suites := junitTestSuites{Name: report.Project, Tests: report.Total, Failures: report.Failed}
+	var total time.Duration
+	var suiteTimes []time.Duration
+	index := make(map[string]int)
+	for _, result := range report.Results {
+		i, exists := index[result.Scenario]
+		if !exists {
+			i = len(suites.Suites)
+			index[result.Scenario] = i
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: result.Scenario})
+			suiteTimes = append(suiteTimes, 0)
+		}
+		suite := &suites.Suites[i]
+
+		name := result.Kind + " " + result.Target
+		if result.Server != "" {
+			name = result.Kind + " " + result.Server + "/" + result.Target
+		}
+		testCase := junitTestCase{
+			Name:      name,
+			ClassName: report.Project + "." + result.Scenario,
+			Time:      junitSeconds(result.elapsed),
+		}
+		if !result.Passed {
+			testCase.Failure = &junitFailure{Message: result.Error, Text: result.Error}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+		suiteTimes[i] += result.elapsed
+		total += result.elapsed
+	}
+	suites.Time = junitSeconds(total)
+	for i := range suites.Suites {
+		suites.Suites[i].Time = junitSeconds(suiteTimes[i])
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+	if err := os.WriteFile(path, data, constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	return nil
+}
+
+func junitSeconds(d time.Duration) string {
+
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
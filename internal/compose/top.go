@@ -0,0 +1,287 @@
+// internal/compose/top.go
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+
+	"github.com/fatih/color"
+)
+
+// TopOptions controls the live status view
+type TopOptions struct {
+	Interval time.Duration
+	Once     bool
+}
+
+// ServerSnapshot is a point-in-time view of a single server for the top view
+type ServerSnapshot struct {
+	Name      string
+	Kind      string
+	Status    string
+	Health    string
+	CPU       string
+	Memory    string
+	Restarts  string
+	LastError string
+}
+
+// Top renders a continuously refreshing status table for the servers in the compose file
+func Top(configFile string, opts TopOptions) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+		cRuntime = container.NewNullRuntime()
+	}
+
+	interval := opts.Interval
+	if interval < constants.TopMinRefreshInterval {
+		interval = constants.TopMinRefreshInterval
+	}
+
+	if opts.Once {
+
+		return renderTop(cfg, cRuntime, false)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := renderTop(cfg, cRuntime, true); err != nil {
+
+			return err
+		}
+
+		select {
+		case <-sigChan:
+			fmt.Println()
+
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// CollectSnapshots gathers the current state of every configured server
+func CollectSnapshots(cfg *config.ComposeConfig, cRuntime container.Runtime) []ServerSnapshot {
+	names := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	snapshots := make([]ServerSnapshot, 0, len(names))
+	for _, name := range names {
+		srvCfg := cfg.Servers[name]
+		if isContainerServer(srvCfg) {
+			snapshots = append(snapshots, containerSnapshot(name, cRuntime))
+		} else {
+			snapshots = append(snapshots, processSnapshot(name))
+		}
+	}
+
+	return snapshots
+}
+
+func containerSnapshot(serverName string, cRuntime container.Runtime) ServerSnapshot {
+	snap := ServerSnapshot{
+		Name:      serverName,
+		Kind:      "container",
+		Status:    "stopped",
+		Health:    "-",
+		CPU:       "-",
+		Memory:    "-",
+		Restarts:  "-",
+		LastError: "-",
+	}
+
+	if cRuntime == nil || cRuntime.GetRuntimeName() == "none" {
+		snap.Status = "no runtime"
+
+		return snap
+	}
+
+	containerName := config.ContainerName(serverName)
+	status, err := cRuntime.GetContainerStatus(containerName)
+	if err != nil {
+
+		return snap
+	}
+	snap.Status = status
+
+	if info, err := cRuntime.GetContainerInfo(containerName); err == nil {
+		snap.Restarts = fmt.Sprintf("%d", info.RestartCount)
+		if info.Health != "" {
+			snap.Health = info.Health
+		}
+	}
+
+	if status == "running" {
+		if stats, err := cRuntime.GetContainerStats(containerName); err == nil {
+			snap.CPU = fmt.Sprintf("%.1f%%", stats.CPUPercent)
+			snap.Memory = formatTopBytes(stats.MemoryUsage)
+		}
+	}
+
+	if lines, err := cRuntime.GetContainerLogs(containerName, constants.TopRecentLogLines); err == nil {
+		snap.LastError = lastErrorLine(lines)
+	}
+
+	return snap
+}
+
+func processSnapshot(serverName string) ServerSnapshot {
+	snap := ServerSnapshot{
+		Name:      serverName,
+		Kind:      "process",
+		Status:    "stopped",
+		Health:    "-",
+		CPU:       "-",
+		Memory:    "-",
+		Restarts:  "-",
+		LastError: "-",
+	}
+
+	proc, err := runtime.FindProcess(config.ContainerName(serverName))
+	if err != nil {
+
+		return snap
+	}
+
+	if running, err := proc.IsRunning(); err == nil && running {
+		snap.Status = "running"
+	}
+	if state, err := proc.ReadState(); err == nil {
+		snap.Restarts = fmt.Sprintf("%d", state.Restarts)
+		if snap.Status != "running" {
+			snap.Status = state.Status
+		}
+	}
+
+	if lines, err := proc.TailLogs(constants.TopRecentLogLines); err == nil {
+		snap.LastError = lastErrorLine(lines)
+	}
+
+	return snap
+}
+
+// lastErrorLine returns the most recent log line that looks like an error
+func lastErrorLine(lines []string) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		lower := strings.ToLower(lines[i])
+		if strings.Contains(lower, "error") || strings.Contains(lower, "panic") ||
+			strings.Contains(lower, "fatal") || strings.Contains(lower, "exception") {
+			line := strings.TrimSpace(lines[i])
+			if len(line) > constants.TopErrorColumnWidth {
+				line = line[:constants.TopErrorColumnWidth-3] + "..."
+			}
+
+			return line
+		}
+	}
+
+	return "-"
+}
+
+func formatTopBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+func renderTop(cfg *config.ComposeConfig, cRuntime container.Runtime, clear bool) error {
+	snapshots := CollectSnapshots(cfg, cRuntime)
+
+	runningColor := color.New(color.FgGreen).SprintFunc()
+	stoppedColor := color.New(color.FgRed).SprintFunc()
+	warnColor := color.New(color.FgYellow).SprintFunc()
+	headerColor := color.New(color.Bold).SprintFunc()
+
+	var buf bytes.Buffer
+	running := 0
+	for _, snap := range snapshots {
+		if snap.Status == "running" {
+			running++
+		}
+	}
+	fmt.Fprintf(&buf, "%s  %s  runtime: %s  servers: %d/%d running\n\n",
+		headerColor("mcp-compose top"), time.Now().Format("15:04:05"),
+		cRuntime.GetRuntimeName(), running, len(snapshots))
+
+	w := tabwriter.NewWriter(&buf, 0, 0, constants.TableColumnSpacing, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERVER\tKIND\tSTATUS\tHEALTH\tCPU\tMEM\tRESTARTS\tLAST ERROR")
+	for _, snap := range snapshots {
+		status := snap.Status
+		switch status {
+		case "running":
+			status = runningColor(status)
+		case "starting", "paused":
+			status = warnColor(status)
+		default:
+			status = stoppedColor(status)
+		}
+
+		health := snap.Health
+		switch health {
+		case "healthy":
+			health = runningColor(health)
+		case "unhealthy":
+			health = stoppedColor(health)
+		case "starting":
+			health = warnColor(health)
+		}
+
+		lastError := snap.LastError
+		if lastError != "-" {
+			lastError = stoppedColor(lastError)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			snap.Name, snap.Kind, status, health, snap.CPU, snap.Memory, snap.Restarts, lastError)
+	}
+	if err := w.Flush(); err != nil {
+
+		return fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	if clear {
+		buf.WriteString("\nPress Ctrl+C to exit\n")
+		// Move cursor home and clear the screen before redrawing
+		fmt.Print("\033[H\033[2J")
+	}
+
+	_, err := os.Stdout.Write(buf.Bytes())
+
+	return err
+}
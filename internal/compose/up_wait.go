@@ -0,0 +1,160 @@
+// internal/compose/up_wait.go
+package compose
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/notify"
+)
+
+// UpWaitOptions controls how `up` blocks after starting servers
+type UpWaitOptions struct {
+	Wait         bool
+	ExitCodeFrom string
+	Timeout      time.Duration
+	Notify       bool
+	// Locked refuses to start servers that no longer resolve as the lock file records
+	Locked bool
+}
+
+// ExitCodeError carries a server's exit status out to the CLI so it can be used as the process exit code
+type ExitCodeError struct {
+	Server string
+	Code   int
+}
+
+func (e *ExitCodeError) Error() string {
+
+	return fmt.Sprintf("server '%s' exited with code %d", e.Server, e.Code)
+}
+
+// UpAndWait starts servers like Up and then optionally blocks until they are healthy
+// or until a designated server exits, propagating its status.
+func UpAndWait(configFile string, serverNames []string, opts UpWaitOptions) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+
+	if opts.ExitCodeFrom != "" {
+		srvCfg, exists := cfg.Servers[opts.ExitCodeFrom]
+		if !exists {
+
+			return fmt.Errorf("--exit-code-from: server '%s' not found in configuration", opts.ExitCodeFrom)
+		}
+		if !isContainerServer(srvCfg) {
+
+			return fmt.Errorf("--exit-code-from: server '%s' is a process server; exit codes are only tracked for containers", opts.ExitCodeFrom)
+		}
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = constants.UpWaitDefaultTimeout
+	}
+
+	up := Up
+	if opts.Locked {
+		up = UpLocked
+	}
+	if err := up(configFile, serverNames); err != nil {
+		sendUpNotification(opts.Notify, "Startup failed", err.Error())
+
+		return err
+	}
+
+	if !opts.Wait && opts.ExitCodeFrom == "" {
+		sendUpNotification(opts.Notify, "Servers started", fmt.Sprintf("Project '%s' is up", config.GetProjectName(configFile)))
+
+		return nil
+	}
+
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+
+		return fmt.Errorf("failed to detect container runtime: %w", err)
+	}
+
+	if opts.ExitCodeFrom != "" {
+
+		return waitForExitCode(cfg, opts, cRuntime)
+	}
+
+	started := getServersToStart(cfg, serverNames)
+	fmt.Printf("\nWaiting up to %s for %d server(s) to become healthy...\n", opts.Timeout, len(started))
+	if err := WaitForServers(cfg, started, cRuntime, opts.Timeout); err != nil {
+		fmt.Printf("[✖] %v\n", err)
+		sendUpNotification(opts.Notify, "Servers not healthy", err.Error())
+
+		return err
+	}
+
+	fmt.Printf("✅ All %d server(s) are healthy.\n", len(started))
+	sendUpNotification(opts.Notify, "Servers healthy", fmt.Sprintf("%d server(s) are healthy", len(started)))
+
+	return nil
+}
+
+// waitForExitCode blocks until the designated server exits (or, with --wait, becomes healthy)
+func waitForExitCode(cfg *config.ComposeConfig, opts UpWaitOptions, cRuntime container.Runtime) error {
+	name := opts.ExitCodeFrom
+	containerName := config.ContainerName(name)
+	deadline := time.Now().Add(opts.Timeout)
+
+	fmt.Printf("\nWaiting for server '%s' to exit", name)
+	if opts.Wait {
+		fmt.Print(" or become healthy")
+	}
+	fmt.Println("...")
+
+	for {
+		if opts.Wait {
+			if ready, _ := ServerReady(name, cfg.Servers[name], cRuntime); ready {
+				fmt.Printf("✅ Server '%s' is healthy.\n", name)
+				sendUpNotification(opts.Notify, "Server healthy", fmt.Sprintf("Server '%s' is healthy", name))
+
+				return nil
+			}
+			if time.Now().After(deadline) {
+				err := fmt.Errorf("timed out after %s waiting for server '%s'", opts.Timeout, name)
+				sendUpNotification(opts.Notify, "Server not healthy", err.Error())
+
+				return err
+			}
+		}
+
+		status, err := cRuntime.GetContainerStatus(containerName)
+		if err == nil && status == "stopped" {
+			code, err := cRuntime.GetContainerExitCode(containerName)
+			if err != nil {
+
+				return fmt.Errorf("server '%s' stopped but its exit code could not be read: %w", name, err)
+			}
+
+			fmt.Printf("Server '%s' exited with code %d\n", name, code)
+			sendUpNotification(opts.Notify, "Server exited", fmt.Sprintf("Server '%s' exited with code %d", name, code))
+			if code != 0 {
+
+				return &ExitCodeError{Server: name, Code: code}
+			}
+
+			return nil
+		}
+
+		time.Sleep(constants.ServerReadyPollInterval)
+	}
+}
+
+func sendUpNotification(enabled bool, title, message string) {
+	if !enabled {
+
+		return
+	}
+	if err := notify.Desktop("mcp-compose: "+title, message); err != nil {
+		fmt.Printf("Warning: desktop notification failed: %v\n", err)
+	}
+}
@@ -0,0 +1,409 @@
+// internal/compose/volumes.go
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/objectstore"
+)
+
+// VolumeStatus describes a named volume of the project
+type VolumeStatus struct {
+	Name     string   `json:"name" yaml:"name"`
+	Driver   string   `json:"driver" yaml:"driver"`
+	Status   string   `json:"status" yaml:"status"`     // created, external, orphaned or missing
+	Declared bool     `json:"declared" yaml:"declared"` // listed under the top-level volumes
+	External bool     `json:"external" yaml:"external"` // declared external: created and removed outside mcp-compose
+	Exists   bool     `json:"exists" yaml:"exists"`     // present in the container runtime
+	Managed  bool     `json:"managed" yaml:"managed"`   // created by mcp-compose
+	Servers  []string `json:"servers" yaml:"servers"`   // servers that mount it
+}
+
+// Orphaned reports whether mcp-compose created the volume and nothing in the config uses it
+func (v VolumeStatus) Orphaned() bool {
+
+	return v.Managed && !v.Declared && len(v.Servers) == 0
+}
+
+// VolumeBackupOptions controls volume backup
+type VolumeBackupOptions struct {
+	OutputDir string // directory for the archives; defaults to volume-backups
+	S3        bool   // upload to object_storage instead of writing files
+	Image     string // image whose tar reads the volume; defaults to alpine
+}
+
+// mountedVolumes maps each named volume in the servers' volume mounts to the servers using it
+func mountedVolumes(cfg *config.ComposeConfig) map[string][]string {
+	volumes := make(map[string][]string)
+	for _, name := range sortedServerNames(cfg) {
+		for _, mount := range cfg.Servers[name].Volumes {
+			source, _, found := strings.Cut(mount, ":")
+			// Bind mounts start with a path; a mount without a target is anonymous
+			if !found || !config.IsNamedVolume(source) {
+
+				continue
+			}
+			volumes[source] = append(volumes[source], name)
+		}
+	}
+
+	return volumes
+}
+
+func sortedServerNames(cfg *config.ComposeConfig) []string {
+	names := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// CollectVolumes returns the volumes declared in the config, mounted by its servers or created
+// by mcp-compose for its project, sorted by name
+func CollectVolumes(configFile string, cfg *config.ComposeConfig, cRuntime container.Runtime) ([]VolumeStatus, error) {
+	existing, err := cRuntime.ListVolumes()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	statuses := make(map[string]*VolumeStatus)
+	status := func(name string) *VolumeStatus {
+		if statuses[name] == nil {
+			statuses[name] = &VolumeStatus{Name: name}
+		}
+
+		return statuses[name]
+	}
+	for name, volume := range cfg.Volumes {
+		s := status(cfg.VolumeName(name))
+		s.Declared = true
+		s.External = volume.External
+		s.Driver = volume.Driver
+	}
+	for name, servers := range mountedVolumes(cfg) {
+		status(cfg.VolumeName(name)).Servers = servers
+	}
+	for _, volume := range existing {
+		// Volumes created for another project are that project's to prune
+		managed := (volume.Labels[constants.ManagedByLabel] == constants.ManagedBy || volume.Labels[constants.VolumeManagedLabel] == "true") &&
+			ownedByProject(volume.Labels, configFile)
+		if statuses[volume.Name] == nil && !managed {
+
+			continue
+		}
+		s := status(volume.Name)
+		s.Exists = true
+		s.Managed = managed
+		s.Driver = volume.Driver
+	}
+
+	result := make([]VolumeStatus, 0, len(statuses))
+	for _, s := range statuses {
+		if s.Driver == "" {
+			s.Driver = "local"
+		}
+		s.Status = "missing"
+		switch {
+		case s.Orphaned():
+			s.Status = "orphaned"
+		case s.Exists && s.External:
+			s.Status = "external"
+		case s.Exists:
+			s.Status = "created"
+		}
+		if s.Servers == nil {
+			s.Servers = []string{}
+		}
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// ListVolumes prints the project's volumes as a table or in the given output format
+func ListVolumes(configFile, output string) error {
+	if err := checkOutputFormat(output); err != nil {
+
+		return err
+	}
+	cfg, cRuntime, err := loadVolumeContext(configFile)
+	if err != nil {
+
+		return err
+	}
+	volumes, err := CollectVolumes(configFile, cfg, cRuntime)
+	if err != nil {
+
+		return err
+	}
+	if output != OutputTable {
+
+		return writeOutput(output, volumes)
+	}
+	if len(volumes) == 0 {
+		fmt.Println("No volumes declared, mounted or created by mcp-compose.")
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, constants.TableColumnSpacing, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VOLUME\tDRIVER\tSTATUS\tSERVERS")
+	for _, volume := range volumes {
+		servers := strings.Join(volume.Servers, ", ")
+		if servers == "" {
+			servers = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", volume.Name, volume.Driver, volume.Status, servers)
+	}
+
+	return w.Flush()
+}
+
+// PruneVolumes removes the volumes mcp-compose created that the config no longer declares or
+// mounts. With dryRun they are only listed.
+func PruneVolumes(configFile string, dryRun bool) error {
+	cfg, cRuntime, err := loadVolumeContext(configFile)
+	if err != nil {
+
+		return err
+	}
+	volumes, err := CollectVolumes(configFile, cfg, cRuntime)
+	if err != nil {
+
+		return err
+	}
+
+	var failed []string
+	pruned := 0
+	for _, volume := range volumes {
+		if !volume.Orphaned() {
+
+			continue
+		}
+		if dryRun {
+			fmt.Printf("Would remove volume '%s'\n", volume.Name)
+			pruned++
+
+			continue
+		}
+		// Not forced, so a volume still mounted by some container is kept
+		if err := cRuntime.RemoveVolume(volume.Name, false); err != nil {
+			fmt.Printf("Could not remove volume '%s': %v\n", volume.Name, err)
+			failed = append(failed, volume.Name)
+
+			continue
+		}
+		fmt.Printf("Removed volume '%s'\n", volume.Name)
+		pruned++
+	}
+
+	if pruned == 0 && len(failed) == 0 {
+		fmt.Println("No orphaned volumes.")
+	}
+	if len(failed) > 0 {
+
+		return fmt.Errorf("failed to remove %d volume(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// BackupVolumes writes a tar.gz of each named volume, or of every existing volume of the
+// project when none are named, to a directory or to object storage
+func BackupVolumes(configFile string, names []string, opts VolumeBackupOptions) error {
+	cfg, cRuntime, err := loadVolumeContext(configFile)
+	if err != nil {
+
+		return err
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = constants.VolumeBackupDir
+	}
+	if opts.Image == "" {
+		opts.Image = constants.VolumeBackupImage
+	}
+
+	volumes, err := CollectVolumes(configFile, cfg, cRuntime)
+	if err != nil {
+
+		return err
+	}
+	exists := make(map[string]bool, len(volumes))
+	for _, volume := range volumes {
+		exists[volume.Name] = volume.Exists
+	}
+	if len(names) == 0 {
+		for _, volume := range volumes {
+			if volume.Exists && !volume.Orphaned() {
+				names = append(names, volume.Name)
+			}
+		}
+		if len(names) == 0 {
+
+			return fmt.Errorf("no volumes to back up")
+		}
+	}
+
+	var store *objectstore.Client
+	if opts.S3 {
+		if store, err = objectstore.New(cfg.ObjectStorage); err != nil {
+
+			return fmt.Errorf("failed to set up object storage: %w", err)
+		}
+	} else if err := os.MkdirAll(opts.OutputDir, constants.DefaultDirMode); err != nil {
+
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	timestamp := time.Now().Format("20060102-150405")
+	for _, name := range names {
+		if _, known := exists[name]; !known {
+			name = cfg.VolumeName(name) // as declared in the config
+		}
+		if !exists[name] {
+
+			return fmt.Errorf("volume '%s' does not exist or is not a volume of this project", name)
+		}
+		archive, err := snapshotVolume(cRuntime.GetRuntimeName(), name, opts.Image)
+		if err != nil {
+
+			return err
+		}
+		fileName := fmt.Sprintf("%s-%s.tar.gz", name, timestamp)
+
+		if store != nil {
+			key := store.Key(constants.VolumeBackupDir, fileName)
+			ctx, cancel := context.WithTimeout(context.Background(), constants.VolumeBackupTimeout)
+			err = store.Put(ctx, key, archive, "application/gzip", map[string]string{"volume": name})
+			cancel()
+			if err != nil {
+
+				return fmt.Errorf("failed to upload backup of volume '%s': %w", name, err)
+			}
+			fmt.Printf("Backed up volume '%s' to object storage key %s (%d bytes)\n", name, key, len(archive))
+
+			continue
+		}
+
+		path := filepath.Join(opts.OutputDir, fileName)
+		if err := os.WriteFile(path, archive, constants.DefaultFileMode); err != nil {
+
+			return fmt.Errorf("failed to write backup of volume '%s': %w", name, err)
+		}
+		fmt.Printf("Backed up volume '%s' to %s (%d bytes)\n", name, path, len(archive))
+	}
+
+	return nil
+}
+
+// snapshotVolume returns a tar.gz of a volume's contents, read by a throwaway container that
+// mounts it read-only
+func snapshotVolume(runtimeName, volume, image string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.VolumeBackupTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, runtimeName, "run", "--rm", "--network", "none",
+		"-v", volume+":/volume:ro", image, "tar", "czf", "-", "-C", "/volume", ".")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+
+		return nil, fmt.Errorf("failed to archive volume '%s': %w: %s", volume, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// ensureVolumes creates the declared volumes that the given servers mount, with their
+// configured driver, and checks that external ones exist
+func ensureVolumes(configFile string, cfg *config.ComposeConfig, cRuntime container.Runtime, serverNames []string) error {
+	starting := make(map[string]bool, len(serverNames))
+	for _, name := range serverNames {
+		starting[name] = true
+	}
+	needed := make(map[string]bool)
+	for volume, servers := range mountedVolumes(cfg) {
+		for _, server := range servers {
+			if _, declared := cfg.Volumes[volume]; declared && starting[server] {
+				needed[volume] = true
+			}
+		}
+	}
+	if len(needed) == 0 {
+
+		return nil
+	}
+
+	existing, err := cRuntime.ListVolumes()
+	if err != nil {
+
+		return fmt.Errorf("failed to list volumes: %w", err)
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, volume := range existing {
+		exists[volume.Name] = true
+	}
+
+	for volume := range needed {
+		volumeCfg := cfg.Volumes[volume]
+		volume = cfg.VolumeName(volume)
+		if exists[volume] {
+
+			continue
+		}
+		if volumeCfg.External {
+
+			return fmt.Errorf("external volume '%s' does not exist", volume)
+		}
+		labels := resourceLabels(configFile)
+		labels[constants.VolumeManagedLabel] = "true"
+		for key, value := range volumeCfg.Labels {
+			labels[key] = value
+		}
+		opts := &container.VolumeOptions{Driver: volumeCfg.Driver, DriverOpts: volumeCfg.DriverOpts, Labels: labels}
+		if err := cRuntime.CreateVolume(volume, opts); err != nil {
+
+			return fmt.Errorf("failed to create volume '%s': %w", volume, err)
+		}
+		fmt.Printf("✅ Created volume '%s'\n", volume)
+	}
+
+	return nil
+}
+
+// loadVolumeContext loads the config and the container runtime volume commands need
+func loadVolumeContext(configFile string) (*config.ComposeConfig, container.Runtime, error) {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+
+		return nil, nil, fmt.Errorf("failed to load config from %s: %w", configFile, err)
+	}
+	cRuntime, err := container.DetectRuntime()
+	if err != nil {
+
+		return nil, nil, fmt.Errorf("failed to detect container runtime: %w", err)
+	}
+	if cRuntime.GetRuntimeName() == "none" {
+
+		return nil, nil, fmt.Errorf("volumes need a container runtime (docker or podman)")
+	}
+
+	return cfg, cRuntime, nil
+}
@@ -0,0 +1,132 @@
+// internal/compose/wait.go
+package compose
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+	"github.com/phildougherty/mcp-compose/internal/server"
+)
+
+// ServerReady reports whether a server is running and, where a health check is defined, healthy
+func ServerReady(serverName string, srvCfg config.ServerConfig, cRuntime container.Runtime) (bool, error) {
+	identifier := config.ContainerName(serverName)
+
+	if isContainerServer(srvCfg) {
+		if cRuntime == nil || cRuntime.GetRuntimeName() == "none" {
+
+			return false, fmt.Errorf("no container runtime available for server '%s'", serverName)
+		}
+
+		status, err := cRuntime.GetContainerStatus(identifier)
+		if err != nil || status != "running" {
+
+			return false, nil
+		}
+
+		if info, err := cRuntime.GetContainerInfo(identifier); err == nil {
+			switch info.Health {
+			case "":
+			case "healthy":
+				// The runtime already runs the healthcheck test inside the container
+				if srvCfg.HealthCheck != nil && srvCfg.HealthCheck.CheckType() == "exec" {
+
+					return true, nil
+				}
+			default:
+
+				return false, nil
+			}
+		}
+	} else {
+		proc, err := runtime.FindProcess(identifier)
+		if err != nil {
+
+			return false, nil
+		}
+		if running, err := proc.IsRunning(); err != nil || !running {
+
+			return false, nil
+		}
+	}
+
+	if check := srvCfg.ActiveHealthCheck(); check != nil {
+		if err := probeServerHealth(identifier, srvCfg, *check, cRuntime); err != nil {
+
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// probeServerHealth runs a server's health check once, reaching HTTP and MCP checks
+// through the server's published http_port
+func probeServerHealth(identifier string, srvCfg config.ServerConfig, check config.HealthCheck, cRuntime container.Runtime) error {
+	timeout, err := time.ParseDuration(check.Timeout)
+	if err != nil {
+		timeout = constants.DefaultHealthTimeout
+	}
+	probe := server.HealthProbe{
+		Check:       check,
+		Runtime:     cRuntime,
+		Identifier:  identifier,
+		IsContainer: isContainerServer(srvCfg),
+		MCPPath:     srvCfg.HttpPath,
+	}
+	if srvCfg.HttpPort > 0 {
+		probe.BaseURL = fmt.Sprintf("http://localhost:%d", srvCfg.HttpPort)
+	}
+
+	return probe.Run(timeout)
+}
+
+// WaitForServers blocks until every named server is ready or the timeout expires
+func WaitForServers(cfg *config.ComposeConfig, serverNames []string, cRuntime container.Runtime, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	pending := make(map[string]bool, len(serverNames))
+	for _, name := range serverNames {
+		pending[name] = true
+	}
+
+	for {
+		for name := range pending {
+			srvCfg, exists := cfg.Servers[name]
+			if !exists {
+
+				return fmt.Errorf("server '%s' not found in configuration", name)
+			}
+
+			ready, err := ServerReady(name, srvCfg, cRuntime)
+			if err != nil {
+
+				return err
+			}
+			if ready {
+				delete(pending, name)
+			}
+		}
+
+		if len(pending) == 0 {
+
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			names := make([]string, 0, len(pending))
+			for name := range pending {
+				names = append(names, name)
+			}
+
+			return fmt.Errorf("timed out after %s waiting for servers to become ready: %s",
+				timeout, strings.Join(names, ", "))
+		}
+
+		time.Sleep(constants.ServerReadyPollInterval)
+	}
+}
@@ -2,14 +2,22 @@
 package config
 
 import (
+	"encoding/base32"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/phildougherty/mcp-compose/internal/constants"
+	"golang.org/x/crypto/bcrypt"
 
 	yaml "gopkg.in/yaml.v3"
 )
@@ -19,12 +27,40 @@ type ProxyAuthConfig struct {
 	Enabled       bool   `yaml:"enabled,omitempty"`
 	APIKey        string `yaml:"api_key,omitempty"`        // If you want to store the API key in the config file
 	OAuthFallback bool   `yaml:"oauth_fallback,omitempty"` // Allow OAuth as fallback
+
+	TrustedHeaders *TrustedHeaderAuthConfig `yaml:"trusted_headers,omitempty"`
+	IdentityTokens *IdentityTokenConfig     `yaml:"identity_tokens,omitempty"`
+}
+
+// IdentityTokenConfig signs the identity of the client behind a request into a JWT, which
+// the proxy sends to servers with oauth.forward_identity so they can authorize each user.
+// The public key is served at /.well-known/jwks.json.
+type IdentityTokenConfig struct {
+	Issuer         string `yaml:"issuer,omitempty"`           // Defaults to oauth.issuer, or mcp-compose
+	SigningKeyFile string `yaml:"signing_key_file,omitempty"` // PEM ed25519 private key; one is generated at startup otherwise
+	TTL            string `yaml:"ttl,omitempty"`              // Defaults to 5m
+}
+
+// TrustedHeaderAuthConfig delegates authentication to a reverse proxy (oauth2-proxy, Authelia)
+// that sets identity headers. Headers are only honoured from the listed networks.
+type TrustedHeaderAuthConfig struct {
+	Enabled        bool              `yaml:"enabled"`
+	TrustedProxies []string          `yaml:"trusted_proxies"`         // CIDRs or IPs allowed to assert identity
+	UserHeader     string            `yaml:"user_header,omitempty"`   // Defaults to Remote-User
+	EmailHeader    string            `yaml:"email_header,omitempty"`  // Defaults to Remote-Email
+	GroupsHeader   string            `yaml:"groups_header,omitempty"` // Defaults to Remote-Groups
+	GroupRoles     map[string]string `yaml:"group_roles,omitempty"`   // Group name -> RBAC role
+	DefaultRole    string            `yaml:"default_role,omitempty"`  // Role for users with no other mapping
 }
 
 // ComposeConfig represents the entire mcp-compose.yaml file
 type ComposeConfig struct {
 	Version       string                       `yaml:"version"`
 	ProxyAuth     ProxyAuthConfig              `yaml:"proxy_auth,omitempty"`
+	ProxyTLS      TLSConfig                    `yaml:"proxy_tls,omitempty"`
+	ProxyExpose   bool                         `yaml:"proxy_expose,omitempty"` // listen on every interface, not just loopback; needs proxy_auth
+	ProxyAccess   *IPAccessConfig              `yaml:"proxy_access,omitempty"` // addresses allowed to reach the proxy at all
+	CORS          *CORSConfig                  `yaml:"cors,omitempty"`         // other origins' pages allowed to call the proxy, OAuth and dashboard endpoints
 	OAuth         *OAuthConfig                 `yaml:"oauth,omitempty"`
 	Audit         *AuditConfig                 `yaml:"audit,omitempty"`
 	RBAC          *RBACConfig                  `yaml:"rbac,omitempty"`
@@ -34,14 +70,551 @@ type ComposeConfig struct {
 	Connections   map[string]ConnectionConfig  `yaml:"connections,omitempty"`
 	Logging       LoggingConfig                `yaml:"logging,omitempty"`
 	Monitoring    MonitoringConfig             `yaml:"monitoring,omitempty"`
+	Observability ObservabilityConfig          `yaml:"observability,omitempty"`
 	Development   DevelopmentConfig            `yaml:"development,omitempty"`
 	Environments  map[string]EnvironmentConfig `yaml:"environments,omitempty"`
 	CurrentEnv    string                       `yaml:"-"`
 	Dashboard     DashboardConfig              `yaml:"dashboard,omitempty"`
 	Networks      map[string]NetworkConfig     `yaml:"networks,omitempty"`
+	Isolation     string                       `yaml:"isolation,omitempty"` // "shared" (default): servers share mcp-net; "strict": one network per server
 	Volumes       map[string]VolumeConfig      `yaml:"volumes,omitempty"`
 	TaskScheduler *TaskScheduler               `yaml:"task_scheduler,omitempty"`
 	Memory        MemoryConfig                 `yaml:"memory"`
+	Builtins      map[string]BuiltinOverride   `yaml:"builtins,omitempty"` // keyed by the generated server's name
+	ObjectStorage *ObjectStorageConfig         `yaml:"object_storage,omitempty"`
+	Aggregator    AggregatorConfig             `yaml:"aggregator,omitempty"`
+	ControlServer ControlServerConfig          `yaml:"control_server,omitempty"`
+	Sampling      SamplingBrokerConfig         `yaml:"sampling,omitempty"`
+	Roots         RootsConfig                  `yaml:"roots,omitempty"`
+	Locale        LocaleConfig                 `yaml:"locale,omitempty"`
+	Notifications NotificationsConfig          `yaml:"notifications,omitempty"`
+	Quotas        QuotasConfig                 `yaml:"quotas,omitempty"`
+	Platform      string                       `yaml:"platform,omitempty"`  // default os/arch[/variant] of every server's image, e.g. linux/arm64
+	EnvFiles      []string                     `yaml:"env_files,omitempty"` // loaded over .env for ${VAR} expansion, later files winning
+
+	ExternalDependencies map[string]ExternalDependency `yaml:"external_dependencies,omitempty"`
+}
+
+var restartPolicyPattern = regexp.MustCompile(`^(no|always|unless-stopped|on-failure(:\d+)?)$`)
+
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+var gpusPattern = regexp.MustCompile(`^(all|[1-9][0-9]*|device=[A-Za-z0-9-]+(,[A-Za-z0-9-]+)*)$`)
+
+// EnabledFor reports whether the server runs with the given profiles active. Servers
+// without profiles always run; the "*" profile enables every server.
+func (s ServerConfig) EnabledFor(profiles []string) bool {
+	if len(s.Profiles) == 0 {
+
+		return true
+	}
+	for _, active := range profiles {
+		if active == "*" {
+
+			return true
+		}
+		for _, profile := range s.Profiles {
+			if profile == active {
+
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ActiveProfiles returns the requested profiles, or those in MCP_COMPOSE_PROFILES
+func ActiveProfiles(requested []string) []string {
+	if len(requested) > 0 {
+
+		return requested
+	}
+	var profiles []string
+	for _, profile := range strings.Split(os.Getenv("MCP_COMPOSE_PROFILES"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
+
+// ApplyProfiles removes the servers that are not enabled for the active profiles
+func (c *ComposeConfig) ApplyProfiles(profiles []string) {
+	for name, server := range c.Servers {
+		if !server.EnabledFor(profiles) {
+			delete(c.Servers, name)
+		}
+	}
+}
+
+// ProfileServers returns the servers that belong to one of the profiles, sorted by name
+func (c *ComposeConfig) ProfileServers(profiles []string) []string {
+	var names []string
+	for name, server := range c.Servers {
+		if len(server.Profiles) > 0 && server.EnabledFor(profiles) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// EffectiveRestartPolicy returns deploy.restart_policy when set, otherwise restart
+func (s ServerConfig) EffectiveRestartPolicy() string {
+	if s.Deploy.RestartPolicy != "" {
+
+		return s.Deploy.RestartPolicy
+	}
+
+	return s.RestartPolicy
+}
+
+// UsesStdio reports whether clients talk to the server over its stdin and stdout, the
+// default protocol
+func (s ServerConfig) UsesStdio() bool {
+
+	return s.Protocol == "" || s.Protocol == "stdio"
+}
+
+// LogFilterRule quiets a chatty server's output before it is stored or streamed.
+// Rules are applied in order to each log line.
+type LogFilterRule struct {
+	Action  string `yaml:"action"`            // "drop", "downgrade" (to debug) or "rate_limit"
+	Pattern string `yaml:"pattern,omitempty"` // Regular expression; rate_limit without one applies to every line
+	Window  string `yaml:"window,omitempty"`  // rate_limit: period identical lines are counted over (default 1m)
+	Burst   int    `yaml:"burst,omitempty"`   // rate_limit: identical lines let through per window (default 1)
+}
+
+// LocaleConfig sets the clock and language environment of servers so their timestamps line up.
+// The project-level block applies to every server; a server's own block overrides it field by field.
+type LocaleConfig struct {
+	Timezone string `yaml:"timezone,omitempty"` // IANA zone injected as TZ, e.g. Europe/Berlin
+	Lang     string `yaml:"lang,omitempty"`     // Locale injected as LANG and LC_ALL, e.g. en_US.UTF-8
+}
+
+// Env returns the environment variables for the locale
+func (l LocaleConfig) Env() map[string]string {
+	env := make(map[string]string)
+	if l.Timezone != "" {
+		env["TZ"] = l.Timezone
+	}
+	if l.Lang != "" {
+		env["LANG"] = l.Lang
+		env["LC_ALL"] = l.Lang
+	}
+
+	return env
+}
+
+// Image pull policies
+const (
+	PullAlways  = "always"
+	PullMissing = "missing"
+	PullNever   = "never"
+)
+
+// HookShells are the shells lifecycle hooks can run under; hooks use sh, or cmd on
+// Windows, when none is set
+var HookShells = []string{"sh", "bash", "cmd", "powershell", "pwsh"}
+
+// validUsernsMode reports whether mode is a user namespace mode Docker or Podman accepts
+func validUsernsMode(mode string) bool {
+	kind, options, hasOptions := strings.Cut(mode, ":")
+	switch kind {
+	case "host", "nomap", "private":
+
+		return !hasOptions
+	case "keep-id", "auto":
+
+		return !hasOptions || options != ""
+	case "ns":
+
+		return options != ""
+	}
+
+	return false
+}
+
+// Network isolation modes
+const (
+	IsolationShared = "shared"
+	IsolationStrict = "strict"
+)
+
+// ServerNetwork returns the network of a server under strict isolation
+func ServerNetwork(serverName string) string {
+
+	return Namespaced("mcp-net-" + serverName)
+}
+
+// HasOwnNetwork reports whether a server's container gets a network of its own, which it
+// does with strict isolation or when its egress is restricted
+func (c *ComposeConfig) HasOwnNetwork(serverName string) bool {
+
+	return c.Isolation == IsolationStrict || c.Servers[serverName].Security.Egress != nil
+}
+
+// ServerNetworks returns the networks a server's container joins. Normally that is its own
+// networks plus mcp-net. A server with a network of its own joins that instead of mcp-net,
+// along with the networks of its peers; only the proxy also joins it.
+func (c *ComposeConfig) ServerNetworks(serverName string) []string {
+	server := c.Servers[serverName]
+	if server.NetworkMode != "" {
+
+		return nil
+	}
+
+	var networks []string
+	ownNetwork := c.HasOwnNetwork(serverName)
+	if ownNetwork {
+		networks = append(networks, ServerNetwork(serverName))
+		for _, peer := range server.Peers {
+			networks = append(networks, ServerNetwork(peer))
+		}
+	}
+	networks = append(networks, server.Networks...)
+	if !ownNetwork {
+		networks = append(networks, DefaultNetwork())
+	}
+
+	unique := make([]string, 0, len(networks))
+	seen := make(map[string]bool, len(networks))
+	for _, network := range networks {
+		if !seen[network] {
+			seen[network] = true
+			unique = append(unique, network)
+		}
+	}
+
+	return unique
+}
+
+// InternalNetwork reports whether a network is created without outbound access, which is the
+// case for the network of a server with restricted egress
+func (c *ComposeConfig) InternalNetwork(network string) bool {
+	for name, server := range c.Servers {
+		if server.Security.Egress != nil && network == ServerNetwork(name) {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// EgressEnv returns the proxy variables that send a server's outbound HTTP through the egress
+// gateway, or nil when its egress is not restricted. The proxy and the server's peers are
+// reached directly.
+func (c *ComposeConfig) EgressEnv(serverName string) map[string]string {
+	server := c.Servers[serverName]
+	if server.Security.Egress == nil {
+
+		return nil
+	}
+
+	gatewayHost := Namespaced(constants.EgressGatewayHost)
+	gateway := fmt.Sprintf("http://%s:%d", gatewayHost, constants.EgressGatewayPort)
+	noProxy := []string{"localhost", "127.0.0.1", gatewayHost}
+	for _, peer := range server.Peers {
+		noProxy = append(noProxy, ContainerName(peer))
+	}
+
+	return map[string]string{
+		"HTTP_PROXY":  gateway,
+		"HTTPS_PROXY": gateway,
+		"NO_PROXY":    strings.Join(noProxy, ","),
+		"http_proxy":  gateway,
+		"https_proxy": gateway,
+		"no_proxy":    strings.Join(noProxy, ","),
+	}
+}
+
+// ProxyNetworks returns the networks the proxy container joins: mcp-net and the network of
+// every container server that has one of its own
+func (c *ComposeConfig) ProxyNetworks() []string {
+	networks := []string{DefaultNetwork()}
+	for _, name := range sortedMapKeys(c.Servers) {
+		server := c.Servers[name]
+		if c.HasOwnNetwork(name) && server.NetworkMode == "" && (server.Image != "" || server.Build.Context != "") {
+			networks = append(networks, ServerNetwork(name))
+		}
+	}
+
+	return networks
+}
+
+// ServerLocale resolves a server's locale from its own settings and the project defaults
+func (c *ComposeConfig) ServerLocale(serverName string) LocaleConfig {
+	locale := c.Locale
+	if server, exists := c.Servers[serverName]; exists && server.Locale != nil {
+		if server.Locale.Timezone != "" {
+			locale.Timezone = server.Locale.Timezone
+		}
+		if server.Locale.Lang != "" {
+			locale.Lang = server.Locale.Lang
+		}
+	}
+
+	return locale
+}
+
+// ProjectTimezone is the timezone given to built-in services
+func (c *ComposeConfig) ProjectTimezone() string {
+	if c.Locale.Timezone != "" {
+
+		return c.Locale.Timezone
+	}
+
+	return constants.DefaultBuiltinTimezone
+}
+
+// ApplyLocale adds a server's locale variables to env without overriding ones already set
+func (c *ComposeConfig) ApplyLocale(serverName string, env map[string]string) {
+	for key, value := range c.ServerLocale(serverName).Env() {
+		if _, set := env[key]; !set {
+			env[key] = value
+		}
+	}
+}
+
+// ServerEnv returns a copy of a server's environment with its locale variables filled in
+func (c *ComposeConfig) ServerEnv(serverName string) map[string]string {
+	env := MergeEnv(c.Servers[serverName].Env, nil)
+	c.ApplyLocale(serverName, env)
+
+	return env
+}
+
+// ExternalDependency is a service outside the compose file that servers wait for on startup.
+// Exactly one of URL, TCP, HTTP or Postgres must be set.
+type ExternalDependency struct {
+	URL          string `yaml:"url,omitempty"`           // tcp://host:port, http(s):// or postgres:// URL
+	TCP          string `yaml:"tcp,omitempty"`           // host:port that must accept connections
+	HTTP         string `yaml:"http,omitempty"`          // URL that must answer
+	Postgres     string `yaml:"postgres,omitempty"`      // DSN that must accept a ping
+	ExpectStatus int    `yaml:"expect_status,omitempty"` // HTTP status required; default any 2xx/3xx
+	Timeout      string `yaml:"timeout,omitempty"`       // Give up after this long; default 60s
+	Interval     string `yaml:"interval,omitempty"`      // Delay between attempts; default 2s
+}
+
+// Check returns the dependency with its URL, if any, moved to the field of the check it selects
+func (d ExternalDependency) Check() ExternalDependency {
+	if d.URL == "" {
+
+		return d
+	}
+	scheme, rest, _ := strings.Cut(d.URL, "://")
+	switch strings.ToLower(scheme) {
+	case "tcp":
+		d.TCP = strings.TrimSuffix(rest, "/")
+	case "http", "https":
+		d.HTTP = d.URL
+	case "postgres", "postgresql":
+		d.Postgres = d.URL
+	}
+	d.URL = ""
+
+	return d
+}
+
+// ExternalDependencyRef is an entry of a server's external_depends_on: either the name of a
+// top-level external dependency or a dependency defined in place
+type ExternalDependencyRef struct {
+	Name               string `yaml:"-"`
+	ExternalDependency `yaml:",inline"`
+}
+
+// UnmarshalYAML accepts a dependency name or an inline dependency
+func (r *ExternalDependencyRef) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+
+		return node.Decode(&r.Name)
+	}
+
+	return node.Decode(&r.ExternalDependency)
+}
+
+// MarshalYAML writes a named reference back as its name
+func (r ExternalDependencyRef) MarshalYAML() (interface{}, error) {
+	if r.Name != "" {
+
+		return r.Name, nil
+	}
+
+	return r.ExternalDependency, nil
+}
+
+// Label names the dependency in progress and error messages without revealing credentials
+func (r ExternalDependencyRef) Label() string {
+	if r.Name != "" {
+
+		return r.Name
+	}
+	if r.URL != "" {
+		if u, err := url.Parse(r.URL); err == nil {
+
+			return u.Redacted()
+		}
+	}
+	dep := r.Check()
+	switch {
+	case dep.TCP != "":
+
+		return "tcp://" + dep.TCP
+	case dep.HTTP != "":
+
+		return dep.HTTP
+	default:
+
+		return "postgres"
+	}
+}
+
+// AggregatorConfig exposes every server through one merged MCP endpoint on the proxy.
+// Tools and prompts are namespaced as <server>__<name>.
+type AggregatorConfig struct {
+	Enabled bool     `yaml:"enabled,omitempty"`
+	Path    string   `yaml:"path,omitempty"`    // Defaults to /mcp
+	Servers []string `yaml:"servers,omitempty"` // Servers to include (default: all)
+}
+
+// EndpointPath returns the configured aggregator path or the /mcp default
+func (a AggregatorConfig) EndpointPath() string {
+	if a.Path == "" {
+
+		return "/mcp"
+	}
+
+	return strings.TrimSuffix(a.Path, "/")
+}
+
+// ControlServerConfig exposes the proxy's own management operations as a built-in MCP server,
+// so agents connected through the proxy can inspect and manage the stack
+type ControlServerConfig struct {
+	Enabled  bool `yaml:"enabled,omitempty"`
+	ReadOnly bool `yaml:"read_only,omitempty"` // Leave out the tools that change servers or run tasks
+}
+
+// SamplingBrokerConfig answers sampling/createMessage requests from servers that enable the
+// sampling capability with an LLM provider. Each server's lifecycle.human_control decides
+// which requests wait for a person to approve them.
+type SamplingBrokerConfig struct {
+	Enabled   bool   `yaml:"enabled,omitempty"`
+	Provider  string `yaml:"provider,omitempty"`   // openai, openrouter or ollama
+	BaseURL   string `yaml:"base_url,omitempty"`   // Defaults to the provider's public endpoint
+	APIKey    string `yaml:"api_key,omitempty"`    // Not needed for ollama
+	Model     string `yaml:"model,omitempty"`      // Used when no model hint or server model applies
+	MaxTokens int    `yaml:"max_tokens,omitempty"` // Caps what servers may ask for
+	Timeout   string `yaml:"timeout,omitempty"`    // Per provider call, default 60s
+}
+
+// SamplingProviders are the LLM providers the sampling broker can call, with their default
+// endpoints. All of them serve the OpenAI chat completions API.
+var SamplingProviders = map[string]string{
+	"openai":     "https://api.openai.com/v1",
+	"openrouter": "https://openrouter.ai/api/v1",
+	"ollama":     "http://localhost:11434/v1",
+}
+
+// Endpoint returns the configured base URL or the provider's default
+func (s SamplingBrokerConfig) Endpoint() string {
+	if s.BaseURL != "" {
+
+		return strings.TrimSuffix(s.BaseURL, "/")
+	}
+
+	return SamplingProviders[s.Provider]
+}
+
+// RootsConfig declares the filesystem roots the proxy answers roots/list with, on behalf of
+// clients that don't implement roots themselves. Every client gets the default roots and
+// those of the active profiles; a client listed under clients also gets its own.
+type RootsConfig struct {
+	Default  []RootConfig            `yaml:"default,omitempty"`
+	Profiles map[string][]RootConfig `yaml:"profiles,omitempty"` // Keyed by profile name
+	Clients  map[string][]RootConfig `yaml:"clients,omitempty"`  // Keyed by clientInfo.name or X-Client-ID
+}
+
+// QuotasConfig limits what each client may use in a calendar month (UTC). Clients are named
+// by the ids /api/analytics/clients reports, such as oauth:<client_id> or api_key.
+type QuotasConfig struct {
+	Default *QuotaLimits           `yaml:"default,omitempty"` // Every client without a quota of its own
+	Clients map[string]QuotaLimits `yaml:"clients,omitempty"` // Keyed by client id
+}
+
+// QuotaLimits is one client's monthly allowance; a zero limit is no limit
+type QuotaLimits struct {
+	Calls int64   `yaml:"calls,omitempty"` // Tool calls
+	Cost  float64 `yaml:"cost,omitempty"`  // Cost, as /api/costs counts it
+}
+
+// For returns the quota of a client, if it has one
+func (q QuotasConfig) For(clientID string) (QuotaLimits, bool) {
+	if limits, ok := q.Clients[clientID]; ok {
+
+		return limits, true
+	}
+	if q.Default != nil {
+
+		return *q.Default, true
+	}
+
+	return QuotaLimits{}, false
+}
+
+// RootConfig is one filesystem root
+type RootConfig struct {
+	URI  string `yaml:"uri"` // A file:// URI
+	Name string `yaml:"name,omitempty"`
+}
+
+// For returns the roots a client gets with the given profiles active, in the order they
+// are declared and without repeating a URI
+func (r RootsConfig) For(client string, profiles []string) []RootConfig {
+	groups := [][]RootConfig{r.Default}
+	for _, profile := range profiles {
+		if profile == "*" {
+			for _, name := range sortedMapKeys(r.Profiles) {
+				groups = append(groups, r.Profiles[name])
+			}
+
+			continue
+		}
+		groups = append(groups, r.Profiles[profile])
+	}
+	if client != "" {
+		groups = append(groups, r.Clients[client])
+	}
+
+	roots := []RootConfig{}
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		for _, root := range group {
+			if !seen[root.URI] {
+				seen[root.URI] = true
+				roots = append(roots, root)
+			}
+		}
+	}
+
+	return roots
+}
+
+// ObjectStorageConfig points at an S3-compatible bucket (AWS S3, MinIO)
+type ObjectStorageConfig struct {
+	Endpoint        string `yaml:"endpoint,omitempty"` // Defaults to https://s3.<region>.amazonaws.com
+	Region          string `yaml:"region,omitempty"`
+	Bucket          string `yaml:"bucket"`
+	Prefix          string `yaml:"prefix,omitempty"`
+	AccessKeyID     string `yaml:"access_key_id,omitempty"`
+	SecretAccessKey string `yaml:"secret_access_key,omitempty"`
 }
 
 // OAuth 2.1 Configuration
@@ -54,6 +627,7 @@ type OAuthConfig struct {
 	GrantTypes      []string            `yaml:"grant_types"`
 	ResponseTypes   []string            `yaml:"response_types"`
 	ScopesSupported []string            `yaml:"scopes_supported"`
+	Access          *IPAccessConfig     `yaml:"access,omitempty"` // addresses allowed to reach the OAuth endpoints
 }
 
 type OAuthEndpoints struct {
@@ -62,17 +636,65 @@ type OAuthEndpoints struct {
 	UserInfo      string `yaml:"userinfo"`
 	Revoke        string `yaml:"revoke"`
 	Discovery     string `yaml:"discovery"`
+	// Where users approve device sign-ins, such as the dashboard; default the proxy's /oauth/device
+	DeviceVerification string `yaml:"device_verification,omitempty"`
 }
 
 type TokenConfig struct {
 	AccessTokenTTL  string `yaml:"access_token_ttl"`
 	RefreshTokenTTL string `yaml:"refresh_token_ttl"`
 	CodeTTL         string `yaml:"authorization_code_ttl"`
-	Algorithm       string `yaml:"algorithm"`
+	Algorithm       string `yaml:"algorithm"`              // RS256 or ES256 issue signed JWTs; HS256 (the default) opaque tokens
+	KeyDir          string `yaml:"key_dir,omitempty"`      // default .mcp-compose/oauth-keys next to the compose file
+	KeyRotation     string `yaml:"key_rotation,omitempty"` // default 720h; 0 never rotates
+}
+
+// SigningKeyDir returns the directory the keys signed access tokens are signed with are kept
+// in, relative to the compose file's directory unless absolute
+func (t TokenConfig) SigningKeyDir() string {
+	if t.KeyDir != "" {
+
+		return t.KeyDir
+	}
+
+	return constants.OAuthKeysDir
 }
 
 type OAuthSecurityConfig struct {
-	RequirePKCE bool `yaml:"require_pkce"`
+	RequirePKCE bool             `yaml:"require_pkce"`
+	BruteForce  BruteForceConfig `yaml:"brute_force,omitempty"`
+}
+
+// BruteForceConfig locks out IP addresses, users and clients that fail to sign in or
+// authenticate at the OAuth endpoints too often. It is on unless disabled.
+type BruteForceConfig struct {
+	Disabled    bool   `yaml:"disabled,omitempty"`
+	MaxFailures int    `yaml:"max_failures,omitempty"` // failures within the window before a lockout, default 5
+	Window      string `yaml:"window,omitempty"`       // default 15m
+	Lockout     string `yaml:"lockout,omitempty"`      // the first lockout, doubled for each one in a row, default 1m
+	MaxLockout  string `yaml:"max_lockout,omitempty"`  // default 1h
+}
+
+// IPAccessConfig lets clients in or keeps them out by address, checked before they
+// authenticate. Entries are IP addresses or CIDR ranges. Denied addresses are refused;
+// with an allow list, so is every address not on it. Behind a reverse proxy listed in
+// trusted_proxies, the client is the last address of X-Forwarded-For the proxies added.
+type IPAccessConfig struct {
+	Allow          []string `yaml:"allow,omitempty"`
+	Deny           []string `yaml:"deny,omitempty"`
+	TrustedProxies []string `yaml:"trusted_proxies,omitempty"`
+}
+
+// CORSConfig names the origins whose pages browsers let call the proxy, the OAuth endpoints and
+// the dashboard. Without allowed origins, responses carry no CORS headers, so only the
+// endpoints' own pages and non-browser clients can use them.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins,omitempty"`   // scheme://host[:port], or "*" for any origin
+	AllowedMethods   []string `yaml:"allowed_methods,omitempty"`   // default GET, POST, PUT, DELETE, OPTIONS
+	AllowedHeaders   []string `yaml:"allowed_headers,omitempty"`   // default the headers each endpoint's clients send
+	ExposedHeaders   []string `yaml:"exposed_headers,omitempty"`   // default the headers each endpoint's clients read
+	AllowCredentials bool     `yaml:"allow_credentials,omitempty"` // let pages send cookies; not with "*"
+	MaxAge           string   `yaml:"max_age,omitempty"`           // how long browsers may cache a preflight, e.g. 10m
 }
 
 // Audit Configuration
@@ -112,6 +734,7 @@ type User struct {
 	Username     string    `yaml:"username"`
 	Email        string    `yaml:"email"`
 	PasswordHash string    `yaml:"password_hash"`
+	TOTPSecret   string    `yaml:"totp_secret,omitempty"` // base32; asks for a code when signing in to authorize OAuth clients
 	Role         string    `yaml:"role"`
 	Enabled      bool      `yaml:"enabled"`
 	CreatedAt    time.Time `yaml:"created_at"`
@@ -144,48 +767,71 @@ type OAuthClientConfig struct {
 
 type ServerConfig struct {
 	// Process-based setup
-	Command         string              `yaml:"command,omitempty"`
-	Args            []string            `yaml:"args,omitempty"`
-	Image           string              `yaml:"image,omitempty"`
-	Build           BuildConfig         `yaml:"build,omitempty"`
-	Runtime         string              `yaml:"runtime,omitempty"`
-	Pull            bool                `yaml:"pull,omitempty"`
-	WorkDir         string              `yaml:"workdir,omitempty"`
-	Env             map[string]string   `yaml:"env,omitempty"`
-	Ports           []string            `yaml:"ports,omitempty"`
-	HttpPort        int                 `yaml:"http_port,omitempty"`
-	HttpPath        string              `yaml:"http_path,omitempty"`
-	Protocol        string              `yaml:"protocol,omitempty"` // "http", "sse", or "stdio" (default)
-	StdioHosterPort int                 `yaml:"stdio_hoster_port,omitempty"`
-	Capabilities    []string            `yaml:"capabilities,omitempty"`
-	DependsOn       []string            `yaml:"depends_on,omitempty"`
-	Volumes         []string            `yaml:"volumes,omitempty"`
-	Resources       ResourcesConfig     `yaml:"resources,omitempty"`
-	Tools           []ToolConfig        `yaml:"tools,omitempty"`
-	Prompts         []PromptConfig      `yaml:"prompts,omitempty"`
-	Sampling        SamplingConfig      `yaml:"sampling,omitempty"`
-	Security        SecurityConfig      `yaml:"security,omitempty"`
-	Lifecycle       LifecycleConfig     `yaml:"lifecycle,omitempty"`
-	CapabilityOpt   CapabilityOptConfig `yaml:"capability_options,omitempty"`
-	NetworkMode     string              `yaml:"network_mode,omitempty"`
-	Networks        []string            `yaml:"networks,omitempty"`
-	Authentication  *ServerAuthConfig   `yaml:"authentication,omitempty"`
-	OAuth           *ServerOAuthConfig  `yaml:"oauth,omitempty"`
-	SSEPath         string              `yaml:"sse_path,omitempty"`      // Path for SSE endpoint
-	SSEPort         int                 `yaml:"sse_port,omitempty"`      // Port for SSE (if different from http_port)
-	SSEHeartbeat    int                 `yaml:"sse_heartbeat,omitempty"` // SSE heartbeat interval in seconds
+	Command           string                  `yaml:"command,omitempty"`
+	Args              []string                `yaml:"args,omitempty"`
+	Image             string                  `yaml:"image,omitempty"`
+	Build             BuildConfig             `yaml:"build,omitempty"`
+	Runtime           string                  `yaml:"runtime,omitempty"`
+	Pull              bool                    `yaml:"pull,omitempty"`
+	PullPolicy        string                  `yaml:"pull_policy,omitempty"`
+	WorkDir           string                  `yaml:"workdir,omitempty"`
+	Env               map[string]string       `yaml:"env,omitempty"`
+	EnvFile           EnvFileList             `yaml:"env_file,omitempty"` // Files of KEY=value lines; env wins, then the later file
+	Ports             []string                `yaml:"ports,omitempty"`
+	HttpPort          int                     `yaml:"http_port,omitempty"`
+	HttpPath          string                  `yaml:"http_path,omitempty"`
+	Protocol          string                  `yaml:"protocol,omitempty"` // "http", "sse", or "stdio" (default)
+	StdioHosterPort   int                     `yaml:"stdio_hoster_port,omitempty"`
+	StdioSharing      string                  `yaml:"stdio_sharing,omitempty"` // "shared" (default): one session for all clients; "per_request"
+	Capabilities      []string                `yaml:"capabilities,omitempty"`
+	DependsOn         []string                `yaml:"depends_on,omitempty"`
+	ExternalDependsOn []ExternalDependencyRef `yaml:"external_depends_on,omitempty"` // Names of external_dependencies or inline dependencies
+	Profiles          []string                `yaml:"profiles,omitempty"`            // Only started when one of these profiles is active
+	Volumes           []string                `yaml:"volumes,omitempty"`
+	Resources         ResourcesConfig         `yaml:"resources,omitempty"`
+	Tools             []ToolConfig            `yaml:"tools,omitempty"`
+	Prompts           []PromptConfig          `yaml:"prompts,omitempty"`
+	Sampling          SamplingConfig          `yaml:"sampling,omitempty"`
+	Security          SecurityConfig          `yaml:"security,omitempty"`
+	Lifecycle         LifecycleConfig         `yaml:"lifecycle,omitempty"`
+	Init              []InitJob               `yaml:"init,omitempty"` // One-shot jobs that must succeed, in order, before the server starts
+	CapabilityOpt     CapabilityOptConfig     `yaml:"capability_options,omitempty"`
+	NetworkMode       string                  `yaml:"network_mode,omitempty"`
+	Networks          []string                `yaml:"networks,omitempty"`
+	Peers             []string                `yaml:"peers,omitempty"` // With strict isolation, servers this one shares a network with
+	Authentication    *ServerAuthConfig       `yaml:"authentication,omitempty"`
+	OAuth             *ServerOAuthConfig      `yaml:"oauth,omitempty"`
+	ToolsACL          *ToolACLConfig          `yaml:"tools_acl,omitempty"`
+	Middleware        []MiddlewareConfig      `yaml:"middleware,omitempty"`
+	ResourceMirror    *ResourceMirrorConfig   `yaml:"resource_mirror,omitempty"`
+	ResponseCache     *ResponseCacheConfig    `yaml:"response_cache,omitempty"`
+	Pricing           *PricingConfig          `yaml:"pricing,omitempty"`
+	Limits            *RequestLimitsConfig    `yaml:"limits,omitempty"`
+	Access            *IPAccessConfig         `yaml:"access,omitempty"`          // addresses allowed to reach the server through the proxy
+	MaxConcurrent     int                     `yaml:"max_concurrent,omitempty"`  // Requests forwarded at once; the rest wait in a queue
+	MaxQueued         int                     `yaml:"max_queued,omitempty"`      // Requests that may wait; default 8 per max_concurrent
+	CatalogTTL        string                  `yaml:"catalog_ttl,omitempty"`     // How long list results are cached; "0" disables
+	SpillThreshold    string                  `yaml:"spill_threshold,omitempty"` // Responses the proxy holds go to a temp file past this size; default 16m
+	Locale            *LocaleConfig           `yaml:"locale,omitempty"`
+	LogFilters        []LogFilterRule         `yaml:"log_filters,omitempty"`
+	SSEPath           string                  `yaml:"sse_path,omitempty"`      // Path for SSE endpoint
+	SSEPort           int                     `yaml:"sse_port,omitempty"`      // Port for SSE (if different from http_port)
+	SSEHeartbeat      int                     `yaml:"sse_heartbeat,omitempty"` // SSE heartbeat interval in seconds
 
 	// NEW: Docker-style container security and resource options
 	Privileged    bool              `yaml:"privileged,omitempty"`
 	User          string            `yaml:"user,omitempty"`
 	Groups        []string          `yaml:"groups,omitempty"`
+	UsernsMode    string            `yaml:"userns_mode,omitempty"` // host, keep-id[:...], auto[:...], nomap, private or ns:<path>
 	ReadOnly      bool              `yaml:"read_only,omitempty"`
 	Tmpfs         []string          `yaml:"tmpfs,omitempty"`
 	CapAdd        []string          `yaml:"cap_add,omitempty"`
 	CapDrop       []string          `yaml:"cap_drop,omitempty"`
 	SecurityOpt   []string          `yaml:"security_opt,omitempty"`
 	Deploy        DeployConfig      `yaml:"deploy,omitempty"`
+	GPUs          string            `yaml:"gpus,omitempty"` // all, a count, or device=<id>[,<id>...]
 	RestartPolicy string            `yaml:"restart,omitempty"`
+	CrashLoop     *CrashLoopConfig  `yaml:"crash_loop,omitempty"`
 	StopSignal    string            `yaml:"stop_signal,omitempty"`
 	StopTimeout   *int              `yaml:"stop_grace_period,omitempty"`
 	HealthCheck   *HealthCheck      `yaml:"healthcheck,omitempty"`
@@ -209,12 +855,121 @@ type ServerAuthConfig struct {
 	AllowAPIKey   *bool    `yaml:"allow_api_key,omitempty"`
 }
 
+// ToolACLConfig restricts individual tools/call requests proxied to a server
+type ToolACLConfig struct {
+	Allow        []string          `yaml:"allow,omitempty"`         // If set, only these tools may be called
+	Deny         []string          `yaml:"deny,omitempty"`          // Tools that may never be called
+	RequireScope map[string]string `yaml:"require_scope,omitempty"` // Tool name -> OAuth scope required to call it
+}
+
+// MiddlewareConfig declares one step of a server's proxy middleware chain.
+// Steps run in order on the request and on the response.
+type MiddlewareConfig struct {
+	Type    string   `yaml:"type"`              // headers, redact, default_args, trace
+	Methods []string `yaml:"methods,omitempty"` // Only run for these JSON-RPC methods
+	Tools   []string `yaml:"tools,omitempty"`   // Only run for tools/call of these tools
+
+	// headers
+	RequestHeaders  map[string]string `yaml:"request_headers,omitempty"`
+	ResponseHeaders map[string]string `yaml:"response_headers,omitempty"`
+
+	// redact
+	Fields      []string `yaml:"fields,omitempty"`
+	Patterns    []string `yaml:"patterns,omitempty"`
+	Replacement string   `yaml:"replacement,omitempty"`
+
+	// default_args
+	Arguments map[string]interface{} `yaml:"arguments,omitempty"`
+
+	// trace
+	Header   string            `yaml:"header,omitempty"`
+	Metadata map[string]string `yaml:"metadata,omitempty"`
+}
+
+// ResourceMirrorConfig copies resources read through the proxy into object storage
+type ResourceMirrorConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	TTL     string `yaml:"ttl,omitempty"`      // Serve mirrored copies younger than this instead of calling the server
+	MaxSize string `yaml:"max_size,omitempty"` // Size budget for this server's mirror, e.g. "512m"; oldest copies are evicted
+}
+
+// ResponseCacheConfig caches the results of read-only calls to a server in the proxy.
+// Entries are dropped when they expire or the server reports that the lists behind them changed.
+type ResponseCacheConfig struct {
+	TTL        string   `yaml:"ttl"`                   // How long a result is served from the cache
+	Methods    []string `yaml:"methods,omitempty"`     // Default resources/read and prompts/get
+	MaxEntries int      `yaml:"max_entries,omitempty"` // Oldest entries are evicted past this; default 1000
+}
+
+// CacheableMethods lists the methods whose results may be cached
+var CacheableMethods = []string{"resources/read", "prompts/get"}
+
+// PricingConfig estimates what tool calls to a server cost, for servers that call paid APIs.
+// A cost the server reports itself in a result's _meta takes precedence.
+type PricingConfig struct {
+	ToolPricing `yaml:",inline"`
+	Tools       map[string]ToolPricing `yaml:"tools,omitempty"` // Prices of particular tools, replacing the server's
+}
+
+// ToolPricing is the price of one call, made of a fixed part and parts per thousand
+// estimated tokens in the request and in the result
+type ToolPricing struct {
+	PerCall              float64 `yaml:"per_call,omitempty"`
+	PerThousandInTokens  float64 `yaml:"per_1k_input_tokens,omitempty"`
+	PerThousandOutTokens float64 `yaml:"per_1k_output_tokens,omitempty"`
+}
+
+// PriceOf returns the price of a call to tool
+func (p *PricingConfig) PriceOf(tool string) ToolPricing {
+	if price, ok := p.Tools[tool]; ok {
+
+		return price
+	}
+
+	return p.ToolPricing
+}
+
+// Cost is the price of a call with the given estimated tokens
+func (p ToolPricing) Cost(inputTokens, outputTokens int64) float64 {
+
+	return p.PerCall + (p.PerThousandInTokens*float64(inputTokens)+p.PerThousandOutTokens*float64(outputTokens))/1000
+}
+
+// RequestLimitsConfig bounds a single request proxied to a server, so one client cannot
+// exhaust the proxy's memory or hold it waiting
+type RequestLimitsConfig struct {
+	MaxRequestBytes  string `yaml:"max_request_bytes,omitempty"`  // Largest request body accepted, e.g. "1m"
+	MaxResponseBytes string `yaml:"max_response_bytes,omitempty"` // Largest response relayed, e.g. "10m"
+	RequestTimeout   string `yaml:"request_timeout,omitempty"`    // Give up on the server after this long, e.g. "30s"
+}
+
+// Values returns the parsed limits, zero where none is set; l may be nil
+func (l *RequestLimitsConfig) Values() (maxRequestBytes, maxResponseBytes int64, timeout time.Duration) {
+	if l == nil {
+
+		return 0, 0, 0
+	}
+	if l.MaxRequestBytes != "" {
+		maxRequestBytes, _ = ParseByteSize(l.MaxRequestBytes)
+	}
+	if l.MaxResponseBytes != "" {
+		maxResponseBytes, _ = ParseByteSize(l.MaxResponseBytes)
+	}
+	if d, err := time.ParseDuration(l.RequestTimeout); err == nil && d > 0 {
+		timeout = d
+	}
+
+	return maxRequestBytes, maxResponseBytes, timeout
+}
+
 type ServerOAuthConfig struct {
 	Enabled             bool     `yaml:"enabled"`
 	RequiredScope       string   `yaml:"required_scope"`
 	AllowAPIKeyFallback bool     `yaml:"allow_api_key_fallback"`
 	OptionalAuth        bool     `yaml:"optional_auth"`
 	AllowedClients      []string `yaml:"allowed_clients"`
+	ForwardIdentity     bool     `yaml:"forward_identity,omitempty"` // Send the caller's identity as a signed JWT
+	IdentityHeader      string   `yaml:"identity_header,omitempty"`  // Defaults to X-MCP-Identity
 }
 
 type BuildConfig struct {
@@ -246,17 +1001,55 @@ type ResourceLimitsConfig struct {
 	MemorySwap  string `yaml:"memory_swap,omitempty"`
 	PIDs        int    `yaml:"pids,omitempty"`
 	BlkioWeight int    `yaml:"blkio_weight,omitempty"`
+
+	Devices []DeviceRequest `yaml:"devices,omitempty"` // only under reservations
 }
 
-type UpdateConfig struct {
-	Parallelism     int    `yaml:"parallelism,omitempty"`
-	Delay           string `yaml:"delay,omitempty"`
-	FailureAction   string `yaml:"failure_action,omitempty"`
-	Monitor         string `yaml:"monitor,omitempty"`
-	MaxFailureRatio string `yaml:"max_failure_ratio,omitempty"`
+// DeviceRequest reserves devices for a container the way compose files do under
+// deploy.resources.reservations.devices. Only GPUs are supported.
+type DeviceRequest struct {
+	Driver       string   `yaml:"driver,omitempty"` // nvidia, the only driver supported
+	Count        string   `yaml:"count,omitempty"`  // all (the default without device_ids) or a number
+	DeviceIDs    []string `yaml:"device_ids,omitempty"`
+	Capabilities []string `yaml:"capabilities,omitempty"` // must include gpu
 }
 
-// NEW: Network configuration
+// GPURequest returns the GPUs the server asks for in the form of the gpus setting: "all", a
+// count or "device=<id>[,<id>...]". A gpus setting wins over reserved devices; "" means none.
+func (s ServerConfig) GPURequest() string {
+	if s.GPUs != "" {
+
+		return s.GPUs
+	}
+	for _, device := range s.Deploy.Resources.Reservations.Devices {
+		if !slices.Contains(device.Capabilities, "gpu") {
+
+			continue
+		}
+		if len(device.DeviceIDs) > 0 {
+
+			return "device=" + strings.Join(device.DeviceIDs, ",")
+		}
+		if device.Count == "" {
+
+			return "all"
+		}
+
+		return device.Count
+	}
+
+	return ""
+}
+
+type UpdateConfig struct {
+	Parallelism     int    `yaml:"parallelism,omitempty"`
+	Delay           string `yaml:"delay,omitempty"`
+	FailureAction   string `yaml:"failure_action,omitempty"`
+	Monitor         string `yaml:"monitor,omitempty"`
+	MaxFailureRatio string `yaml:"max_failure_ratio,omitempty"`
+}
+
+// NEW: Network configuration
 type NetworkConfig struct {
 	Driver      string            `yaml:"driver,omitempty"`
 	DriverOpts  map[string]string `yaml:"driver_opts,omitempty"`
@@ -301,6 +1094,127 @@ type ConnectionConfig struct {
 	Timeouts       TimeoutConfig `yaml:"timeouts,omitempty"`
 }
 
+// TLSConfig serves a listener over HTTPS, with a certificate read from files and reloaded
+// when they change, or obtained and renewed automatically from an ACME CA such as Let's Encrypt
+type TLSConfig struct {
+	Enabled    bool        `yaml:"enabled"`
+	CertFile   string      `yaml:"cert_file,omitempty"`   // PEM, leaf first; relative to the compose file
+	KeyFile    string      `yaml:"key_file,omitempty"`    // PEM private key
+	MinVersion string      `yaml:"min_version,omitempty"` // "1.2" (default) or "1.3"
+	ACME       *ACMEConfig `yaml:"acme,omitempty"`
+}
+
+// ACMEConfig obtains certificates for the listed domains. Challenges are answered with
+// TLS-ALPN-01 on the listener itself, which the CA must reach on port 443, or with HTTP-01
+// on http_port, which it must reach on port 80.
+type ACMEConfig struct {
+	Domains      []string `yaml:"domains"`
+	Email        string   `yaml:"email,omitempty"`
+	CacheDir     string   `yaml:"cache_dir,omitempty"`     // default .mcp-compose/acme next to the compose file
+	DirectoryURL string   `yaml:"directory_url,omitempty"` // default Let's Encrypt production
+	HTTPPort     int      `yaml:"http_port,omitempty"`     // also serve HTTP-01 challenges on this port
+}
+
+// ProxyListenerTLS returns the TLS settings of the proxy listener: proxy_tls when enabled,
+// otherwise those of the first connection, by name, with tls enabled
+func (c *ComposeConfig) ProxyListenerTLS() TLSConfig {
+	if c.ProxyTLS.Enabled {
+
+		return c.ProxyTLS
+	}
+	for _, name := range sortedMapKeys(c.Connections) {
+		if conn := c.Connections[name]; conn.TLS {
+
+			return TLSConfig{Enabled: true, CertFile: conn.CertFile, KeyFile: conn.KeyFile}
+		}
+	}
+
+	return TLSConfig{}
+}
+
+// ProxyBindHost returns the interface the proxy listens on: every interface with
+// proxy_expose, only the loopback interface otherwise
+func (c *ComposeConfig) ProxyBindHost() string {
+	if c.ProxyExpose {
+
+		return constants.DefaultHostInterface
+	}
+
+	return constants.LoopbackHostInterface
+}
+
+// ProxyAuthenticated reports whether the proxy asks callers for credentials, given the API
+// key passed on its command line. Trusted headers alone don't count: a request without them
+// falls back to the API key, and is let in when there is none.
+func (c *ComposeConfig) ProxyAuthenticated(apiKey string) bool {
+
+	return apiKey != "" || (c.ProxyAuth.Enabled && c.ProxyAuth.APIKey != "")
+}
+
+// BindHost returns the interface the dashboard listens on: host when set, otherwise every
+// interface with expose and only the loopback interface without
+func (d DashboardConfig) BindHost() string {
+	switch {
+	case d.Host != "":
+
+		return d.Host
+	case d.Expose:
+
+		return constants.DefaultHostInterface
+	}
+
+	return constants.LoopbackHostInterface
+}
+
+// Authenticated reports whether the dashboard asks users to sign in
+func (d DashboardConfig) Authenticated() bool {
+
+	return d.AdminLogin != nil && d.AdminLogin.Enabled
+}
+
+// LoopbackHost reports whether a listener bound to host only accepts connections from this
+// machine
+func LoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// CheckProxyExposure refuses a proxy listening on host that other machines can reach,
+// unless proxy_expose asks for it and callers must authenticate; insecure allows it without
+// authentication
+func (c *ComposeConfig) CheckProxyExposure(host, apiKey string, insecure bool) error {
+
+	return checkExposure("proxy", host, "proxy_expose", "proxy_auth with an api_key", c.ProxyExpose, c.ProxyAuthenticated(apiKey), insecure)
+}
+
+// CheckDashboardExposure is CheckProxyExposure for the dashboard
+func (c *ComposeConfig) CheckDashboardExposure(host string, insecure bool) error {
+
+	return checkExposure("dashboard", host, "dashboard.expose", "dashboard.admin_login", c.Dashboard.Expose, c.Dashboard.Authenticated(), insecure)
+}
+
+func checkExposure(listener, host, exposeSetting, authSetting string, expose, authenticated, insecure bool) error {
+	if LoopbackHost(host) {
+
+		return nil
+	}
+	if !expose {
+
+		return fmt.Errorf("the %s would listen on %s, reachable from other machines; set %s: true to allow it", listener, host, exposeSetting)
+	}
+	if !authenticated && !insecure {
+
+		return fmt.Errorf("refusing to expose the %s on %s without authentication; enable %s, or pass --insecure", listener, host, authSetting)
+	}
+
+	return nil
+}
+
 // TimeoutConfig defines configurable timeout values
 type TimeoutConfig struct {
 	Connect       string `yaml:"connect,omitempty"`        // Default: "10s"
@@ -361,6 +1275,13 @@ type PromptConfig struct {
 	Variables   []PromptVariable `yaml:"variables,omitempty"`
 }
 
+// PromptPlaceholderPattern matches a {{variable}} placeholder in a prompt template
+var PromptPlaceholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// PromptVariableTypes are the types a prompt variable may declare. Prompt arguments always
+// arrive as strings; other types are checked to parse.
+var PromptVariableTypes = []string{"string", "number", "integer", "boolean"}
+
 // PromptVariable defines a variable used in a prompt template
 type PromptVariable struct {
 	Name        string      `yaml:"name"`
@@ -399,6 +1320,78 @@ type SecurityConfig struct {
 	AppArmor           string            `yaml:"apparmor,omitempty"`
 	Seccomp            string            `yaml:"seccomp,omitempty"`
 	SELinux            map[string]string `yaml:"selinux,omitempty"`
+
+	Egress *EgressConfig    `yaml:"egress,omitempty"`
+	Scan   *ImageScanConfig `yaml:"scan,omitempty"`
+}
+
+// ImageScanConfig runs a vulnerability scanner against a container server's image before the
+// server starts. "scan: true" takes the defaults.
+type ImageScanConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Scanner       string `yaml:"scanner,omitempty"`        // trivy or grype; the first installed when empty
+	FailOn        string `yaml:"fail_on,omitempty"`        // lowest severity that keeps the server from starting, default critical; none never does
+	WarnOn        string `yaml:"warn_on,omitempty"`        // lowest severity warned about, default high
+	IgnoreUnfixed bool   `yaml:"ignore_unfixed,omitempty"` // leave out vulnerabilities no fix is published for
+}
+
+// ImageScanners are the vulnerability scanners security.scan can run
+var ImageScanners = []string{"trivy", "grype"}
+
+// ScanSeverities are the severities of vulnerabilities, from the least severe
+var ScanSeverities = []string{"unknown", "low", "medium", "high", "critical"}
+
+// UnmarshalYAML accepts a boolean or the scan settings, which enable scanning unless they
+// set enabled to false
+func (c *ImageScanConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+
+		return node.Decode(&c.Enabled)
+	}
+	type settings ImageScanConfig
+	scan := settings{Enabled: true}
+	if err := node.Decode(&scan); err != nil {
+
+		return err
+	}
+	*c = ImageScanConfig(scan)
+
+	return nil
+}
+
+// Thresholds returns the lowest severities that fail a scan and that are warned about, with
+// the defaults filled in; "none" is returned as it is
+func (c *ImageScanConfig) Thresholds() (failOn, warnOn string) {
+	failOn, warnOn = "critical", "high"
+	if c.FailOn != "" {
+		failOn = c.FailOn
+	}
+	if c.WarnOn != "" {
+		warnOn = c.WarnOn
+	}
+
+	return failOn, warnOn
+}
+
+// SeverityRank orders severities, unknown ones lowest
+func SeverityRank(severity string) int {
+	for i, known := range ScanSeverities {
+		if severity == known {
+
+			return i
+		}
+	}
+
+	return 0
+}
+
+// EgressConfig limits the outbound connections of a container server. The server is moved to
+// an internal network of its own and reaches other hosts only through the proxy's egress
+// gateway, which applies these rules; deny rules win over allow rules.
+type EgressConfig struct {
+	Allow   []string `yaml:"allow,omitempty"`   // host[:port], *.domain[:port], IP or CIDR
+	Deny    []string `yaml:"deny,omitempty"`    // same syntax, checked first
+	Default string   `yaml:"default,omitempty"` // "deny" (default) or "allow" for unmatched hosts
 }
 
 // AuthConfig defines authentication configuration
@@ -423,6 +1416,7 @@ type AccessRule struct {
 
 // LifecycleConfig defines server lifecycle hooks
 type LifecycleConfig struct {
+	Shell        string              `yaml:"shell,omitempty"` // one of HookShells
 	PreStart     string              `yaml:"pre_start,omitempty"`
 	PostStart    string              `yaml:"post_start,omitempty"`
 	PreStop      string              `yaml:"pre_stop,omitempty"`
@@ -431,6 +1425,30 @@ type LifecycleConfig struct {
 	HumanControl *HumanControlConfig `yaml:"human_control,omitempty"`
 }
 
+// InitJob is a one-shot job, such as a database migration, that must exit successfully
+// before its server starts. Jobs of container servers, and jobs with an image, run in a
+// container that is removed afterwards; jobs of process servers run on the host.
+type InitJob struct {
+	Name    string            `yaml:"name"`
+	Image   string            `yaml:"image,omitempty"` // defaults to the server's image
+	Command string            `yaml:"command,omitempty"`
+	Args    []string          `yaml:"args,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`     // merged over the server's environment
+	Volumes []string          `yaml:"volumes,omitempty"` // defaults to the server's volumes
+	WorkDir string            `yaml:"workdir,omitempty"`
+	Timeout string            `yaml:"timeout,omitempty"` // defaults to 5m
+}
+
+// TimeoutDuration is how long the job may run before it is stopped and counts as failed
+func (j InitJob) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(j.Timeout); err == nil && d > 0 {
+
+		return d
+	}
+
+	return constants.InitJobTimeout
+}
+
 type HumanControlConfig struct {
 	RequireApproval     bool     `yaml:"require_approval,omitempty"`
 	AutoApprovePatterns []string `yaml:"auto_approve_patterns,omitempty"`
@@ -442,13 +1460,52 @@ type HumanControlConfig struct {
 
 // HealthCheck defines health check configuration (UPDATED)
 type HealthCheck struct {
+	Type        string   `yaml:"type,omitempty"` // exec, http or mcp; inferred from test or endpoint when empty
 	Test        []string `yaml:"test,omitempty"`
 	Interval    string   `yaml:"interval,omitempty"`
 	Timeout     string   `yaml:"timeout,omitempty"`
 	Retries     int      `yaml:"retries,omitempty"`
 	StartPeriod string   `yaml:"start_period,omitempty"`
-	Endpoint    string   `yaml:"endpoint,omitempty"` // Legacy support
-	Action      string   `yaml:"action,omitempty"`   // Action when health check fails
+	Endpoint    string   `yaml:"endpoint,omitempty"`     // Legacy support
+	Action      string   `yaml:"action,omitempty"`       // Action when health check fails
+	ExpectTools []string `yaml:"expect_tools,omitempty"` // mcp checks: tools/list must include these
+}
+
+// CheckType returns how the check probes the server: exec runs test, http requests endpoint,
+// mcp sends initialize and ping, and none disables the check
+func (h HealthCheck) CheckType() string {
+	switch {
+	case h.Type != "":
+
+		return h.Type
+	case len(h.Test) > 0 && strings.EqualFold(h.Test[0], "NONE"):
+
+		return "none"
+	case len(h.Test) > 0:
+
+		return "exec"
+	case h.Endpoint != "":
+
+		return "http"
+	default:
+
+		return "none"
+	}
+}
+
+// ActiveHealthCheck returns the health check the manager runs for the server: healthcheck,
+// or lifecycle.health_check from older files. It returns nil when neither is set.
+func (s ServerConfig) ActiveHealthCheck() *HealthCheck {
+	if s.HealthCheck != nil {
+
+		return s.HealthCheck
+	}
+	if s.Lifecycle.HealthCheck.CheckType() != "none" {
+
+		return &s.Lifecycle.HealthCheck
+	}
+
+	return nil
 }
 
 type MemoryConfig struct {
@@ -467,8 +1524,31 @@ type MemoryConfig struct {
 	PostgresMemory   string            `yaml:"postgres_memory"`
 	Volumes          []string          `yaml:"volumes"`
 	Authentication   *ServerAuthConfig `yaml:"authentication"`
+	Backup           *MemoryBackup     `yaml:"backup,omitempty"`
+}
+
+// MemoryBackup schedules automated dumps of the memory server's database, taken by the proxy
+type MemoryBackup struct {
+	Interval  string `yaml:"interval"`            // time between backups, e.g. "24h"
+	Format    string `yaml:"format,omitempty"`    // json (default) or sql
+	Directory string `yaml:"directory,omitempty"` // defaults to memory-backups
+	Keep      int    `yaml:"keep,omitempty"`      // backups kept in the directory; 0 keeps all
+	S3        bool   `yaml:"s3,omitempty"`        // upload to object_storage instead of the directory
 }
 
+// BuiltinOverride adjusts a server generated from a built-in setting such as memory or
+// task_scheduler
+type BuiltinOverride struct {
+	Disabled bool      `yaml:"disabled,omitempty"`
+	Server   yaml.Node `yaml:"server,omitempty"` // merged over the generated server like an override file
+}
+
+// Memory dump formats
+const (
+	MemoryDumpJSON = "json"
+	MemoryDumpSQL  = "sql"
+)
+
 type TaskScheduler struct {
 	Enabled          bool              `yaml:"enabled"`
 	Port             int               `yaml:"port"`
@@ -532,6 +1612,94 @@ type LoggingConfig struct {
 	Level        string           `yaml:"level,omitempty"`
 	Format       string           `yaml:"format,omitempty"`
 	Destinations []LogDestination `yaml:"destinations,omitempty"`
+	Retention    LogRetention     `yaml:"retention,omitempty"`
+	AuthFailures *AuthFailureLog  `yaml:"auth_failures,omitempty"`
+}
+
+// AuthFailureLog writes the proxy's failed authentications to a log of their own, one line
+// per failure led by the client's address, for fail2ban filters and SIEM rules
+type AuthFailureLog struct {
+	Path          string `yaml:"path,omitempty"`           // relative to the compose file; standard error when empty
+	Format        string `yaml:"format,omitempty"`         // text (default) or json
+	AlertInterval string `yaml:"alert_interval,omitempty"` // auth_failure notifications per address at most this often, default 5m
+}
+
+// CrashLoopConfig stops restarting a server that keeps exiting: after more than MaxRestarts
+// restarts within Window, the server waits out Backoff before it is started again
+type CrashLoopConfig struct {
+	MaxRestarts int    `yaml:"max_restarts,omitempty"` // default 5
+	Window      string `yaml:"window,omitempty"`       // default 10m
+	Backoff     string `yaml:"backoff,omitempty"`      // default 5m
+}
+
+// Limits returns the crash-loop settings with defaults filled in; c may be nil
+func (c *CrashLoopConfig) Limits() (maxRestarts int, window, backoff time.Duration) {
+	maxRestarts, window, backoff = constants.CrashLoopMaxRestarts, constants.CrashLoopWindow, constants.CrashLoopBackoff
+	if c == nil {
+
+		return maxRestarts, window, backoff
+	}
+	if c.MaxRestarts > 0 {
+		maxRestarts = c.MaxRestarts
+	}
+	if d, err := time.ParseDuration(c.Window); err == nil && d > 0 {
+		window = d
+	}
+	if d, err := time.ParseDuration(c.Backoff); err == nil && d > 0 {
+		backoff = d
+	}
+
+	return maxRestarts, window, backoff
+}
+
+// NotificationsConfig lists the hooks server events such as crash loops are sent to
+type NotificationsConfig struct {
+	Webhooks []WebhookNotification `yaml:"webhooks,omitempty" json:"webhooks,omitempty"`
+	Slack    []SlackNotification   `yaml:"slack,omitempty" json:"slack,omitempty"`
+	Email    []EmailNotification   `yaml:"email,omitempty" json:"email,omitempty"`
+}
+
+// Empty reports whether no hooks are configured
+func (n NotificationsConfig) Empty() bool {
+
+	return len(n.Webhooks) == 0 && len(n.Slack) == 0 && len(n.Email) == 0
+}
+
+// WebhookNotification POSTs events as JSON
+type WebhookNotification struct {
+	URL     string            `yaml:"url" json:"url"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Events  []string          `yaml:"events,omitempty" json:"events,omitempty"` // all events when empty
+}
+
+// SlackNotification posts events to a Slack incoming webhook
+type SlackNotification struct {
+	WebhookURL string   `yaml:"webhook_url" json:"webhook_url"`
+	Channel    string   `yaml:"channel,omitempty" json:"channel,omitempty"`
+	Events     []string `yaml:"events,omitempty" json:"events,omitempty"`
+}
+
+// EmailNotification mails events through an SMTP server
+type EmailNotification struct {
+	SMTPHost string   `yaml:"smtp_host" json:"smtp_host"`
+	SMTPPort int      `yaml:"smtp_port,omitempty" json:"smtp_port,omitempty"` // default 587
+	Username string   `yaml:"username,omitempty" json:"username,omitempty"`
+	Password string   `yaml:"password,omitempty" json:"password,omitempty"`
+	From     string   `yaml:"from" json:"from"`
+	To       []string `yaml:"to" json:"to"`
+	Events   []string `yaml:"events,omitempty" json:"events,omitempty"`
+}
+
+// NotificationEvents are the event types hooks can subscribe to. Hooks that list no events
+// get all but auth_failure, which they have to name.
+var NotificationEvents = []string{"crash_loop", "unhealthy", "auth_failure"}
+
+// LogRetention controls rotation of the output captured from process-based servers
+type LogRetention struct {
+	MaxSize     string `yaml:"max_size,omitempty"`     // rotate at this size, e.g. "10m"
+	RotateEvery string `yaml:"rotate_every,omitempty"` // also rotate files older than this, e.g. "24h"
+	MaxFiles    int    `yaml:"max_files,omitempty"`    // rotated files kept per server
+	MaxAge      string `yaml:"max_age,omitempty"`      // rotated files older than this are removed
 }
 
 // LogDestination defines a log destination
@@ -551,6 +1719,13 @@ type MetricsConfig struct {
 	Port    int  `yaml:"port,omitempty"`
 }
 
+// ObservabilityConfig defines distributed tracing export
+type ObservabilityConfig struct {
+	OTLPEndpoint string            `yaml:"otlp_endpoint,omitempty"` // OTLP/HTTP collector, e.g. http://tempo:4318
+	ServiceName  string            `yaml:"service_name,omitempty"`
+	Headers      map[string]string `yaml:"headers,omitempty"` // Extra headers for the collector, e.g. auth
+}
+
 // DevelopmentConfig defines development and testing tools configuration
 type DevelopmentConfig struct {
 	Inspector InspectorConfig `yaml:"inspector,omitempty"`
@@ -571,21 +1746,38 @@ type TestingConfig struct {
 // TestScenario defines a test scenario
 type TestScenario struct {
 	Name      string         `yaml:"name"`
+	Servers   []string       `yaml:"servers,omitempty"` // servers to bring up besides those named by checks
 	Tools     []ToolTest     `yaml:"tools,omitempty"`
 	Resources []ResourceTest `yaml:"resources,omitempty"`
 }
 
-// ToolTest defines a tool test
+// ToolTest defines a tool test. With a server it is a tools/call to that server through
+// the proxy; without one it uses the proxy's direct tool endpoint.
 type ToolTest struct {
 	Name           string                 `yaml:"name"`
+	Server         string                 `yaml:"server,omitempty"`
 	Input          map[string]interface{} `yaml:"input"`
 	ExpectedStatus string                 `yaml:"expected_status"`
+	Expect         ResponseExpectation    `yaml:"expect,omitempty"`
 }
 
-// ResourceTest defines a resource test
+// ResourceTest defines a resource test. With a server and uri it is a resources/read;
+// otherwise path is fetched from the proxy.
 type ResourceTest struct {
-	Path           string `yaml:"path"`
-	ExpectedStatus string `yaml:"expected_status"`
+	Path           string              `yaml:"path,omitempty"`
+	Server         string              `yaml:"server,omitempty"`
+	URI            string              `yaml:"uri,omitempty"`
+	ExpectedStatus string              `yaml:"expected_status"`
+	Expect         ResponseExpectation `yaml:"expect,omitempty"`
+}
+
+// ResponseExpectation asserts on a test response. For MCP requests JSON paths are relative
+// to the JSON-RPC result.
+type ResponseExpectation struct {
+	Contains    []string               `yaml:"contains,omitempty"`
+	NotContains []string               `yaml:"not_contains,omitempty"`
+	Matches     string                 `yaml:"matches,omitempty"` // regular expression
+	JSON        map[string]interface{} `yaml:"json,omitempty"`    // dotted path (content.0.text) to expected value
 }
 
 // EnvironmentConfig defines environment-specific configuration overrides
@@ -597,23 +1789,43 @@ type EnvironmentConfig struct {
 type ServerOverrideConfig struct {
 	Env       map[string]string `yaml:"env,omitempty"`
 	Resources ResourcesConfig   `yaml:"resources,omitempty"`
+	Platform  string            `yaml:"platform,omitempty"` // e.g. linux/arm64 for the hosts this environment runs on
 }
 
 // DashboardConfig defines configuration for the MCP-Compose Dashboard
 type DashboardConfig struct {
 	Enabled      bool                 `yaml:"enabled,omitempty"`
 	Port         int                  `yaml:"port,omitempty"`
-	Host         string               `yaml:"host,omitempty"`
+	Host         string               `yaml:"host,omitempty"`   // default 127.0.0.1, or 0.0.0.0 with expose
+	Expose       bool                 `yaml:"expose,omitempty"` // listen beyond this machine; needs admin_login
+	Access       *IPAccessConfig      `yaml:"access,omitempty"` // addresses allowed to reach the dashboard
 	ProxyURL     string               `yaml:"proxy_url,omitempty"`
 	PostgresURL  string               `yaml:"postgres_url,omitempty"`
-	Theme        string               `yaml:"theme,omitempty"`
+	Theme        string               `yaml:"theme,omitempty"` // dark (default) or light, until a user picks their own
+	Branding     *DashboardBranding   `yaml:"branding,omitempty"`
 	LogStreaming bool                 `yaml:"log_streaming,omitempty"`
 	ConfigEditor bool                 `yaml:"config_editor,omitempty"`
 	Metrics      bool                 `yaml:"metrics,omitempty"`
+	TLS          TLSConfig            `yaml:"tls,omitempty"`
 	Security     *DashboardSecurity   `yaml:"security,omitempty"`
 	AdminLogin   *DashboardAdminLogin `yaml:"admin_login,omitempty"`
 }
 
+// Dashboard themes
+const (
+	DashboardThemeDark  = "dark"
+	DashboardThemeLight = "light"
+)
+
+// DashboardBranding white-labels the dashboard. Logo and favicon are image files, relative
+// to the compose file.
+type DashboardBranding struct {
+	Title       string `yaml:"title,omitempty"`        // replaces "MCP Dashboard"
+	Logo        string `yaml:"logo,omitempty"`         // shown in the header and on the login page
+	Favicon     string `yaml:"favicon,omitempty"`      // browser tab icon
+	AccentColor string `yaml:"accent_color,omitempty"` // #rrggbb for focus rings, checkboxes and highlights
+}
+
 type DashboardSecurity struct {
 	Enabled          bool `yaml:"enabled"`
 	OAuthConfig      bool `yaml:"oauth_config"`
@@ -622,76 +1834,90 @@ type DashboardSecurity struct {
 	AuditLogs        bool `yaml:"audit_logs"`
 }
 
+// DashboardAdminLogin makes dashboard visitors sign in, with a password from users or
+// through an OAuth provider. Users whose role is "admin" may change things; every other
+// role gets read-only access.
 type DashboardAdminLogin struct {
-	Enabled        bool   `yaml:"enabled"`
-	SessionTimeout string `yaml:"session_timeout"`
+	Enabled        bool                 `yaml:"enabled"`
+	SessionTimeout string               `yaml:"session_timeout"` // idle timeout, default 1h
+	OAuth          *DashboardOAuthLogin `yaml:"oauth,omitempty"`
 }
 
-// loadDotEnv loads environment variables from .env file in the same directory as the config file
-func loadDotEnv(configFilePath string) {
-	// Get the directory of the config file
-	configDir := filepath.Dir(configFilePath)
-	envFilePath := filepath.Join(configDir, ".env")
+// DashboardOAuthLogin signs dashboard users in with an OAuth 2.0 provider's authorization
+// code flow, identifying them from its userinfo endpoint
+type DashboardOAuthLogin struct {
+	Enabled       bool     `yaml:"enabled"`
+	ProviderName  string   `yaml:"provider_name,omitempty"` // shown on the login button
+	AuthorizeURL  string   `yaml:"authorize_url"`
+	TokenURL      string   `yaml:"token_url"`
+	UserInfoURL   string   `yaml:"userinfo_url"`
+	ClientID      string   `yaml:"client_id"`
+	ClientSecret  string   `yaml:"client_secret,omitempty"`
+	Scopes        []string `yaml:"scopes,omitempty"`
+	RedirectURL   string   `yaml:"redirect_url,omitempty"`   // default: <dashboard>/auth/oauth/callback
+	UsernameClaim string   `yaml:"username_claim,omitempty"` // default: preferred_username, login, email, then sub
+	DefaultRole   string   `yaml:"default_role,omitempty"`   // role for users not listed in users; empty refuses them
+}
 
-	// Check if .env file exists
-	if _, err := os.Stat(envFilePath); os.IsNotExist(err) {
+// SessionTimeoutDuration returns the dashboard session idle timeout, or zero for the default
+func (l *DashboardAdminLogin) SessionTimeoutDuration() time.Duration {
+	if l == nil || l.SessionTimeout == "" {
 
-		return // No .env file, that's okay
+		return 0
 	}
+	timeout, _ := time.ParseDuration(l.SessionTimeout)
 
-	// Read .env file
-	data, err := os.ReadFile(envFilePath)
-	if err != nil {
+	return timeout
+}
 
-		return // Could not read .env file, continue without it
-	}
+// LoadConfig loads and parses the compose file with environment support
+func LoadConfig(filePath string) (*ComposeConfig, error) {
+	// Load .env, env_files and --env-file
+	loadDotEnv(filePath, nil)
 
-	// Parse .env file and set environment variables
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+	// Read the file and any overrides with environment variables expanded, keeping the
+	// documents so problems can be reported with their file and position
+	sources := make(map[*yaml.Node]string)
+	document, err := readComposeDocument(filePath, sources)
+	if err != nil {
 
-			continue
-		}
+		return nil, err
+	}
 
-		// Split on first = sign
-		parts := strings.SplitN(line, "=", constants.EnvVarSplitParts)
-		if len(parts) != constants.EnvVarSplitParts {
+	return buildConfig(filePath, document, sources)
+}
 
-			continue
-		}
+// ParseConfig checks proposed contents for the compose file at filePath the way LoadConfig
+// checks the file itself, merging the override files over them. Problems are returned as
+// ValidationErrors with their lines.
+func ParseConfig(filePath string, data []byte) (*ComposeConfig, error) {
+	loadDotEnv(filePath, data)
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+	sources := make(map[*yaml.Node]string)
+	document, err := parseComposeDocument(DisplayName(filePath), data, sources)
+	if err != nil {
 
-		// Only set if not already set in environment
-		if os.Getenv(key) == "" {
-			_ = os.Setenv(key, value)
-		}
+		return nil, err
 	}
-}
 
-// LoadConfig loads and parses the compose file with environment support
-func LoadConfig(filePath string) (*ComposeConfig, error) {
-	// Load .env file if it exists
-	loadDotEnv(filePath)
+	return buildConfig(filePath, document, sources)
+}
 
-	// Read file
-	data, err := os.ReadFile(filePath)
-	if err != nil {
+// buildConfig merges the override files over a parsed compose document, decodes it, applies
+// the current environment's overrides and validates the result
+func buildConfig(filePath string, document *yaml.Node, sources map[*yaml.Node]string) (*ComposeConfig, error) {
+	for _, override := range overrideFiles {
+		overrideDocument, err := readComposeDocument(override, sources)
+		if err != nil {
 
-		return nil, fmt.Errorf("failed to read config file '%s': %w", filePath, err)
+			return nil, err
+		}
+		document = mergeDocuments(document, overrideDocument)
 	}
-	// Expand environment variables
-	expandedData := os.ExpandEnv(string(data)) // Use os.ExpandEnv for ${VAR} and $VAR
-	// Parse YAML
-	var config ComposeConfig
-	err = yaml.Unmarshal([]byte(expandedData), &config)
+	config, problems, err := decodeConfig(document)
 	if err != nil {
 
-		return nil, fmt.Errorf("failed to parse config file '%s': %w", filePath, err)
+		return nil, fmt.Errorf("failed to parse config file '%s': %w", DisplayName(filePath), err)
 	}
 	// Get current environment from MCP_ENV environment variable
 	envName := os.Getenv("MCP_ENV")
@@ -703,10 +1929,21 @@ func LoadConfig(filePath string) (*ComposeConfig, error) {
 	if envConfig, exists := config.Environments[envName]; exists {
 		applyEnvironmentOverrides(&config, envConfig)
 	}
+	applyPlatformDefaults(&config)
+	problems = append(problems, applyEnvFiles(filePath, &config)...)
 	// Validate config
-	if err := ValidateConfig(&config); err != nil {
+	var invalid ValidationErrors
+	if err := ValidateConfig(&config); errors.As(err, &invalid) {
+		problems = append(problems, invalid...)
+	}
+	if len(problems) > 0 {
+		files := []string{DisplayName(filePath)}
+		for _, override := range overrideFiles {
+			files = append(files, DisplayName(override))
+		}
+		problems.locate(document, sources, files)
 
-		return nil, fmt.Errorf("invalid configuration in '%s': %w", filePath, err)
+		return nil, fmt.Errorf("invalid configuration in '%s': %w", DisplayName(filePath), problems)
 	}
 
 	return &config, nil
@@ -736,65 +1973,152 @@ func applyEnvironmentOverrides(config *ComposeConfig, envConfig EnvironmentConfi
 			if overrides.Resources.CacheTTL > 0 { // Should be CacheTTL not CacheTLL
 				server.Resources.CacheTTL = overrides.Resources.CacheTTL
 			}
+			if overrides.Platform != "" {
+				server.Platform = overrides.Platform
+			}
 			// Update the server in the config
 			config.Servers[serverName] = server
 		}
 	}
 }
 
-// In internal/config/config.go, change the function signature to make it public:
+// applyPlatformDefaults gives servers without a platform the top-level one, and builds
+// without a platform the platform of their server, so pulls, builds and runs agree
+func applyPlatformDefaults(config *ComposeConfig) {
+	for name, server := range config.Servers {
+		if server.Platform == "" {
+			server.Platform = config.Platform
+		}
+		if server.Build.Platform == "" {
+			server.Build.Platform = server.Platform
+		}
+		config.Servers[name] = server
+	}
+}
+
+// ValidateConfig checks the whole configuration and reports every problem it finds as
+// ValidationErrors, each with the path of the offending setting
 func ValidateConfig(config *ComposeConfig) error {
+	v := &validation{}
 	if config.Version != "1" {
-
-		return fmt.Errorf("unsupported version: '%s', expected '1'", config.Version)
+		v.addf("version", "unsupported version: '%s', expected '1'", config.Version)
 	}
-	for name, server := range config.Servers {
-		if err := validateServerConfig(name, server); err != nil {
 
-			return err
-		}
+	names := make([]string, 0, len(config.Servers))
+	for name := range config.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		server := config.Servers[name]
+		path := "servers." + name
+		validateServerConfig(v, name, server)
 		// Validate dependencies
-		for _, dep := range server.DependsOn {
+		for i, dep := range server.DependsOn {
 			if _, exists := config.Servers[dep]; !exists {
-
-				return fmt.Errorf("server '%s' depends on undefined server '%s'", name, dep)
+				v.addf(fmt.Sprintf("%s.depends_on.%d", path, i), "server '%s' depends on undefined server '%s'", name, dep)
+			}
+		}
+		for i, peer := range server.Peers {
+			if _, exists := config.Servers[peer]; !exists || peer == name {
+				v.addf(fmt.Sprintf("%s.peers.%d", path, i), "server '%s' lists undefined peer '%s'", name, peer)
+			}
+		}
+		if len(server.Peers) > 0 && !config.HasOwnNetwork(name) {
+			v.addf(path+".peers", "server '%s' lists peers but isolation is not 'strict' and its egress is not restricted", name)
+		}
+		for i, dep := range server.ExternalDependsOn {
+			depPath := fmt.Sprintf("%s.external_depends_on.%d", path, i)
+			if dep.Name == "" {
+				v.add(depPath, validateExternalDependency(fmt.Sprintf("external dependency %d of server '%s'", i+1, name), dep.ExternalDependency))
+			} else if _, exists := config.ExternalDependencies[dep.Name]; !exists {
+				v.addf(depPath, "server '%s' depends on undefined external dependency '%s'", name, dep.Name)
 			}
 		}
+		validateInitJobs(v, path, name, server)
+		if shell := server.Lifecycle.Shell; shell != "" && !slices.Contains(HookShells, shell) {
+			v.addf(path+".lifecycle.shell", "server '%s' has invalid lifecycle shell: '%s'. Must be one of: %s", name, shell, strings.Join(HookShells, ", "))
+		}
 		// Validate human control configuration
 		if server.Lifecycle.HumanControl != nil {
-			if err := validateHumanControlConfig(name, server.Lifecycle.HumanControl); err != nil {
-
-				return err
+			v.add(path+".lifecycle.human_control", validateHumanControlConfig(name, server.Lifecycle.HumanControl))
+		}
+		if server.HealthCheck != nil {
+			v.add(path+".healthcheck", validateHealthCheck(name, server, *server.HealthCheck))
+		}
+		if server.Lifecycle.HealthCheck.CheckType() != "none" {
+			v.add(path+".lifecycle.health_check", validateHealthCheck(name, server, server.Lifecycle.HealthCheck))
+		}
+		v.add(path+".resources", validateResourcePaths(name, server.Resources))
+		v.add(path+".tools", validateToolsConfig(name, server.Tools))
+		v.add(path+".tools_acl", validateToolACL(name, server.ToolsACL))
+		v.add(path+".prompts", validatePrompts(name, server.Prompts))
+		v.add(path+".middleware", validateMiddleware(name, server.Middleware))
+		v.add(path+".resource_mirror", validateResourceMirror(name, server.ResourceMirror, config.ObjectStorage))
+		v.add(path+".limits", validateRequestLimits(name, server.Limits))
+		v.add(path+".response_cache", validateResponseCache(name, server.ResponseCache))
+		v.add(path+".pricing", validatePricing(name, server.Pricing))
+		if server.OAuth != nil && server.OAuth.ForwardIdentity {
+			if server.Protocol != "http" {
+				v.addf(path+".oauth.forward_identity", "server '%s' forwards the caller's identity in an HTTP header, which needs protocol: http", name)
+			}
+			if header := server.OAuth.IdentityHeader; header != "" && !headerNamePattern.MatchString(header) {
+				v.addf(path+".oauth.identity_header", "server '%s' has invalid identity_header '%s'", name, header)
 			}
 		}
-		// Validate resource paths
-		if err := validateResourcePaths(name, server.Resources); err != nil {
-
-			return err
+		if server.MaxConcurrent < 0 || server.MaxQueued < 0 {
+			v.addf(path+".max_concurrent", "server '%s' has negative max_concurrent or max_queued", name)
+		} else if server.MaxQueued > 0 && server.MaxConcurrent == 0 {
+			v.addf(path+".max_queued", "server '%s' sets max_queued without max_concurrent", name)
 		}
-		// Validate tools configuration
-		if err := validateToolsConfig(name, server.Tools); err != nil {
-
-			return err
+		if server.SpillThreshold != "" {
+			if n, err := ParseByteSize(server.SpillThreshold); err != nil || n <= 0 {
+				v.addf(path+".spill_threshold", "server '%s' has invalid spill_threshold '%s'", name, server.SpillThreshold)
+			}
 		}
-		// NEW: Validate security configuration
-		if err := validateSecurityConfig(name, server.Security); err != nil {
-
-			return err
+		if server.Locale != nil {
+			v.add(path+".locale", validateLocale(fmt.Sprintf("server '%s'", name), *server.Locale))
 		}
-		// NEW: Validate resource limits
-		if err := validateResourceLimits(name, server.Deploy.Resources); err != nil {
-
-			return err
+		policies := []struct{ field, value string }{
+			{"restart", server.RestartPolicy},
+			{"deploy.restart_policy", server.Deploy.RestartPolicy},
+		}
+		for _, policy := range policies {
+			if policy.value != "" && !restartPolicyPattern.MatchString(policy.value) {
+				v.addf(path+"."+policy.field, "server '%s' has invalid restart policy '%s' (must be no, on-failure[:max], always or unless-stopped)", name, policy.value)
+			}
+		}
+		v.add(path+".crash_loop", validateCrashLoop(name, server.CrashLoop))
+		for i, profile := range server.Profiles {
+			if !profileNamePattern.MatchString(profile) {
+				v.addf(fmt.Sprintf("%s.profiles.%d", path, i), "server '%s' has invalid profile name '%s'", name, profile)
+			}
+		}
+		if server.StdioSharing != "" && server.StdioSharing != "shared" && server.StdioSharing != "per_request" {
+			v.addf(path+".stdio_sharing", "server '%s' has invalid stdio_sharing '%s' (must be shared or per_request)", name, server.StdioSharing)
+		}
+		for i, rule := range server.LogFilters {
+			v.add(fmt.Sprintf("%s.log_filters.%d", path, i), validateLogFilterRule(name, i, rule))
+		}
+		if server.CatalogTTL != "" {
+			if ttl, err := time.ParseDuration(server.CatalogTTL); err != nil || ttl < 0 {
+				v.addf(path+".catalog_ttl", "server '%s' has invalid catalog_ttl '%s'", name, server.CatalogTTL)
+			}
+		}
+		v.add(path+".security", validateSecurityConfig(name, server.Security))
+		if server.Security.Egress != nil {
+			v.add(path+".security.egress", validateEgress(name, server, config.Servers))
+		}
+		v.add(path+".deploy.resources", validateResourceLimits(name, server.Deploy.Resources))
+		validateIPAccess(v, path+".access", server.Access)
+		if scan := server.Security.Scan; scan != nil && scan.Enabled {
+			validateImageScan(v, path+".security.scan", server)
 		}
 	}
 	// Validate global configuration
-	if err := validateGlobalConfig(config); err != nil {
-
-		return err
-	}
+	validateGlobalConfig(v, config)
 
-	return nil
+	return v.result()
 }
 
 // GetTimeoutDuration returns a timeout duration with fallback to default
@@ -875,45 +2199,126 @@ func (tc TimeoutConfig) GetLifecycleHookTimeout() time.Duration {
 	return constants.DefaultReadTimeout
 }
 
-func validateServerConfig(name string, server ServerConfig) error {
-	// A server must specify either command, image, OR build context
-	if server.Command == "" && server.Image == "" && server.Build.Context == "" {
-
-		return fmt.Errorf("server '%s' must specify either command, image, or build context", name)
-	}
-
-	// If build context is provided, we don't need image or command (command can be in Dockerfile)
-	if server.Build.Context != "" {
-		// Build context is sufficient - command and image are optional
-		// Command will be used to override Dockerfile CMD if provided
-		// Image will be used as the tag name if provided
-	} else if server.Image == "" && server.Command == "" {
-
-		return fmt.Errorf("server '%s' must specify either command or image when not using build", name)
-	}
-
-	// Validate protocol
-	if server.Protocol != "" {
-		validProtocols := []string{"stdio", "http", "sse", "tcp"}
-		valid := false
-		for _, p := range validProtocols {
-			if server.Protocol == p {
-				valid = true
+// validateInitJobs checks that a server's init jobs have unique names and something to run
+func validateInitJobs(v *validation, path, name string, server ServerConfig) {
+	seen := make(map[string]bool)
+	for i, job := range server.Init {
+		jobPath := fmt.Sprintf("%s.init.%d", path, i)
+		switch {
+		case job.Name == "":
+			v.addf(jobPath, "init job %d of server '%s' has no name", i+1, name)
+		case !profileNamePattern.MatchString(job.Name):
+			v.addf(jobPath+".name", "init job '%s' of server '%s' has an invalid name, use letters, digits, '_', '.' and '-'", job.Name, name)
+		case seen[job.Name]:
+			v.addf(jobPath+".name", "server '%s' has more than one init job named '%s'", name, job.Name)
+		}
+		seen[job.Name] = true
 
-				break
+		if job.Image != "" {
+			if err := ValidateImageReference(job.Image); err != nil {
+				v.addf(jobPath+".image", "init job '%s' of server '%s' has invalid image: %v", job.Name, name, err)
+			}
+		} else if server.Image == "" {
+			if server.Build.Context != "" {
+				v.addf(jobPath+".image", "init job '%s' of server '%s' needs an image, as the server's image is built", job.Name, name)
+			} else if job.Command == "" {
+				v.addf(jobPath+".command", "init job '%s' of server '%s' needs a command to run on the host", job.Name, name)
 			}
 		}
-		if !valid {
-
-			return fmt.Errorf("server '%s' has invalid protocol: '%s'. Must be one of: %v", name, server.Protocol, validProtocols)
+		if job.Timeout != "" {
+			if d, err := time.ParseDuration(job.Timeout); err != nil || d <= 0 {
+				v.addf(jobPath+".timeout", "init job '%s' of server '%s' has invalid timeout '%s'", job.Name, name, job.Timeout)
+			}
 		}
 	}
+}
 
-	// Validate HTTP/SSE configuration
-	if (server.Protocol == "http" || server.Protocol == "sse") && server.HttpPort == 0 {
-		if !hasPortInArgsOrMapping(server) {
+// validateGPUs checks the gpus setting and the devices reserved under deploy
+func validateGPUs(v *validation, path, name string, server ServerConfig) {
+	if server.GPUs != "" && !gpusPattern.MatchString(server.GPUs) {
+		v.addf(path+".gpus", "server '%s' has invalid gpus '%s', must be all, a number of GPUs or device=<id>[,<id>...]", name, server.GPUs)
+	}
+	if len(server.Deploy.Resources.Limits.Devices) > 0 {
+		v.addf(path+".deploy.resources.limits.devices", "server '%s' sets devices under limits, they belong under reservations", name)
+	}
+	devices := server.Deploy.Resources.Reservations.Devices
+	if server.GPUs != "" && len(devices) > 0 {
+		v.addf(path+".gpus", "server '%s' sets both gpus and deploy.resources.reservations.devices", name)
+	}
+	for i, device := range devices {
+		devicePath := fmt.Sprintf("%s.deploy.resources.reservations.devices.%d", path, i)
+		if device.Driver != "" && device.Driver != "nvidia" {
+			v.addf(devicePath+".driver", "server '%s' reserves devices of unsupported driver '%s', only nvidia GPUs are supported", name, device.Driver)
+		}
+		if !slices.Contains(device.Capabilities, "gpu") {
+			v.addf(devicePath+".capabilities", "server '%s' reserves devices without the gpu capability, only GPUs are supported", name)
+		}
+		if device.Count != "" && len(device.DeviceIDs) > 0 {
+			v.addf(devicePath, "server '%s' reserves devices by both count and device_ids", name)
+		}
+		if device.Count != "" && (strings.HasPrefix(device.Count, "device=") || !gpusPattern.MatchString(device.Count)) {
+			v.addf(devicePath+".count", "server '%s' reserves an invalid count of devices '%s', must be all or a number", name, device.Count)
+		}
+		for _, id := range device.DeviceIDs {
+			if !gpusPattern.MatchString("device=" + id) {
+				v.addf(devicePath+".device_ids", "server '%s' reserves invalid device id '%s'", name, id)
+			}
+		}
+	}
+}
 
-			return fmt.Errorf("server '%s' uses '%s' protocol but 'http_port' is not defined and cannot be inferred", name, server.Protocol)
+func validateServerConfig(v *validation, name string, server ServerConfig) {
+	path := "servers." + name
+	// A server must specify either command, image, OR build context; with a build context
+	// the image is only the tag name and the command overrides the Dockerfile CMD
+	if server.Command == "" && server.Image == "" && server.Build.Context == "" {
+		v.addf(path, "server '%s' must specify either command, image, or build context", name)
+	}
+	if server.Image != "" {
+		if err := ValidateImageReference(server.Image); err != nil {
+			v.addf(path+".image", "server '%s' has invalid image: %v", name, err)
+		}
+	}
+	switch server.PullPolicy {
+	case "", PullAlways, PullMissing:
+	case PullNever:
+		if server.Pull {
+			v.addf(path+".pull_policy", "server '%s' sets pull: true with pull_policy: never", name)
+		}
+	default:
+		v.addf(path+".pull_policy", "server '%s' has invalid pull_policy: '%s'. Must be one of: %s, %s, %s", name, server.PullPolicy, PullAlways, PullMissing, PullNever)
+	}
+	if server.Platform != "" && !platformPattern.MatchString(server.Platform) {
+		v.addf(path+".platform", "server '%s' has invalid platform '%s', must be os/arch[/variant] such as linux/arm64", name, server.Platform)
+	}
+	if server.Build.Platform != "" && !platformPattern.MatchString(server.Build.Platform) {
+		v.addf(path+".build.platform", "server '%s' has invalid build platform '%s', must be os/arch[/variant] such as linux/arm64", name, server.Build.Platform)
+	}
+	validateGPUs(v, path, name, server)
+	if server.UsernsMode != "" && !validUsernsMode(server.UsernsMode) {
+		v.addf(path+".userns_mode", "server '%s' has invalid userns_mode: '%s'. Must be host, keep-id, auto, nomap, private or ns:<path>", name, server.UsernsMode)
+	}
+
+	// Validate protocol
+	if server.Protocol != "" {
+		validProtocols := []string{"stdio", "http", "sse", "tcp"}
+		valid := false
+		for _, p := range validProtocols {
+			if server.Protocol == p {
+				valid = true
+
+				break
+			}
+		}
+		if !valid {
+			v.addf(path+".protocol", "server '%s' has invalid protocol: '%s'. Must be one of: %v", name, server.Protocol, validProtocols)
+		}
+	}
+
+	// Validate HTTP/SSE configuration
+	if (server.Protocol == "http" || server.Protocol == "sse") && server.HttpPort == 0 {
+		if !hasPortInArgsOrMapping(server) {
+			v.addf(path+".protocol", "server '%s' uses '%s' protocol but 'http_port' is not defined and cannot be inferred", name, server.Protocol)
 		}
 	}
 
@@ -922,22 +2327,18 @@ func validateServerConfig(name string, server ServerConfig) error {
 		"resources": true, "tools": true, "prompts": true,
 		"sampling": true, "logging": true, "roots": true,
 	}
-	for _, cap := range server.Capabilities {
+	for i, cap := range server.Capabilities {
 		if !validCaps[cap] {
-
-			return fmt.Errorf("server '%s' has invalid capability: '%s'", name, cap)
+			v.addf(fmt.Sprintf("%s.capabilities.%d", path, i), "server '%s' has invalid capability: '%s'", name, cap)
 		}
 	}
 
 	// Validate ports format
 	for i, port := range server.Ports {
 		if err := validatePortMapping(port); err != nil {
-
-			return fmt.Errorf("server '%s' has invalid port mapping at index %d: %w", name, i, err)
+			v.addf(fmt.Sprintf("%s.ports.%d", path, i), "server '%s' has invalid port mapping at index %d: %v", name, i, err)
 		}
 	}
-
-	return nil
 }
 
 // Helper function to check if port can be inferred
@@ -963,6 +2364,54 @@ func hasPortInArgsOrMapping(server ServerConfig) bool {
 	return false
 }
 
+func validateHealthCheck(serverName string, server ServerConfig, check HealthCheck) error {
+	switch check.CheckType() {
+	case "none":
+	case "exec":
+		if len(check.Test) == 0 || (len(check.Test) == 1 && (check.Test[0] == "CMD" || check.Test[0] == "CMD-SHELL")) {
+
+			return fmt.Errorf("server '%s' has an exec health check without a test command", serverName)
+		}
+	case "http":
+		if check.Endpoint == "" {
+
+			return fmt.Errorf("server '%s' has an http health check without an endpoint", serverName)
+		}
+	case "mcp":
+		if server.HttpPort == 0 && server.Protocol != "http" {
+
+			return fmt.Errorf("server '%s' has an mcp health check but no http_port to reach it on", serverName)
+		}
+	default:
+
+		return fmt.Errorf("server '%s' has invalid health check type '%s' (must be exec, http or mcp)", serverName, check.Type)
+	}
+
+	durations := []struct{ field, value string }{
+		{"interval", check.Interval}, {"timeout", check.Timeout}, {"start_period", check.StartPeriod},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+
+			continue
+		}
+		if _, err := time.ParseDuration(duration.value); err != nil {
+
+			return fmt.Errorf("server '%s' has invalid health check %s '%s': %w", serverName, duration.field, duration.value, err)
+		}
+	}
+	if len(check.ExpectTools) > 0 && check.CheckType() != "mcp" {
+
+		return fmt.Errorf("server '%s' sets health check expect_tools, which needs type: mcp", serverName)
+	}
+	if check.Retries < 0 {
+
+		return fmt.Errorf("server '%s' has invalid health check retries: %d (must be >= 0)", serverName, check.Retries)
+	}
+
+	return nil
+}
+
 // Validate human control configuration
 func validateHumanControlConfig(serverName string, hc *HumanControlConfig) error {
 	if hc.TimeoutSeconds < 0 {
@@ -1029,6 +2478,129 @@ func validateToolsConfig(serverName string, tools []ToolConfig) error {
 				return fmt.Errorf("server '%s' tool '%s' has invalid timeout '%s': %w", serverName, tool.Name, tool.Timeout, err)
 			}
 		}
+		for j, mock := range tool.Mocks {
+			switch strings.ToLower(mock.Status) {
+			case "", "success", "error":
+			default:
+
+				return fmt.Errorf("server '%s' tool '%s' mock %d has invalid status '%s' (must be success or error)", serverName, tool.Name, j, mock.Status)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validatePrompts(serverName string, prompts []PromptConfig) error {
+	promptNames := make(map[string]bool)
+	for i, prompt := range prompts {
+		if prompt.Name == "" {
+
+			return fmt.Errorf("server '%s' prompt %d missing name", serverName, i)
+		}
+		if promptNames[prompt.Name] {
+
+			return fmt.Errorf("server '%s' has duplicate prompt name: '%s'", serverName, prompt.Name)
+		}
+		promptNames[prompt.Name] = true
+		if strings.TrimSpace(prompt.Template) == "" {
+
+			return fmt.Errorf("server '%s' prompt '%s' has an empty template", serverName, prompt.Name)
+		}
+
+		variables := make(map[string]bool)
+		for j, variable := range prompt.Variables {
+			if variable.Name == "" {
+
+				return fmt.Errorf("server '%s' prompt '%s' variable %d missing name", serverName, prompt.Name, j)
+			}
+			if variables[variable.Name] {
+
+				return fmt.Errorf("server '%s' prompt '%s' has duplicate variable '%s'", serverName, prompt.Name, variable.Name)
+			}
+			variables[variable.Name] = true
+			if variable.Type != "" && !slices.Contains(PromptVariableTypes, variable.Type) {
+
+				return fmt.Errorf("server '%s' prompt '%s' variable '%s' has invalid type '%s'. Must be one of: %s", serverName, prompt.Name, variable.Name, variable.Type, strings.Join(PromptVariableTypes, ", "))
+			}
+		}
+		for _, match := range PromptPlaceholderPattern.FindAllStringSubmatch(prompt.Template, -1) {
+			if !variables[match[1]] {
+
+				return fmt.Errorf("server '%s' prompt '%s' uses undeclared variable '%s'", serverName, prompt.Name, match[1])
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateToolACL(serverName string, acl *ToolACLConfig) error {
+	if acl == nil {
+
+		return nil
+	}
+
+	denied := make(map[string]bool)
+	for _, tool := range acl.Deny {
+		if tool == "" {
+
+			return fmt.Errorf("server '%s' tools_acl.deny contains an empty tool name", serverName)
+		}
+		denied[tool] = true
+	}
+	for _, tool := range acl.Allow {
+		if tool == "" {
+
+			return fmt.Errorf("server '%s' tools_acl.allow contains an empty tool name", serverName)
+		}
+		if denied[tool] {
+
+			return fmt.Errorf("server '%s' tools_acl lists tool '%s' in both allow and deny", serverName, tool)
+		}
+	}
+	for tool, scope := range acl.RequireScope {
+		if tool == "" || scope == "" {
+
+			return fmt.Errorf("server '%s' tools_acl.require_scope entries need both a tool name and a scope", serverName)
+		}
+	}
+
+	return nil
+}
+
+func validateMiddleware(serverName string, middleware []MiddlewareConfig) error {
+	for i, mw := range middleware {
+		switch mw.Type {
+		case "headers":
+			if len(mw.RequestHeaders) == 0 && len(mw.ResponseHeaders) == 0 {
+
+				return fmt.Errorf("server '%s' middleware %d (headers) needs request_headers or response_headers", serverName, i)
+			}
+		case "redact":
+			if len(mw.Fields) == 0 && len(mw.Patterns) == 0 {
+
+				return fmt.Errorf("server '%s' middleware %d (redact) needs fields or patterns", serverName, i)
+			}
+			for _, pattern := range mw.Patterns {
+				if _, err := regexp.Compile(pattern); err != nil {
+
+					return fmt.Errorf("server '%s' middleware %d (redact) has invalid pattern '%s': %w", serverName, i, pattern, err)
+				}
+			}
+		case "default_args":
+			if len(mw.Arguments) == 0 {
+
+				return fmt.Errorf("server '%s' middleware %d (default_args) needs arguments", serverName, i)
+			}
+		case "trace":
+		case "":
+
+			return fmt.Errorf("server '%s' middleware %d missing type", serverName, i)
+		default:
+
+			return fmt.Errorf("server '%s' middleware %d has unknown type '%s'", serverName, i, mw.Type)
+		}
 	}
 
 	return nil
@@ -1073,6 +2645,53 @@ func validateSecurityConfig(serverName string, security SecurityConfig) error {
 	return nil
 }
 
+// validateEgress checks an egress policy and that nothing gives the server a route around it
+func validateEgress(serverName string, server ServerConfig, servers map[string]ServerConfig) error {
+	egress := server.Security.Egress
+	if egress.Default != "" && egress.Default != EgressAllow && egress.Default != EgressDeny {
+
+		return fmt.Errorf("server '%s' has invalid egress default '%s', must be '%s' or '%s'", serverName, egress.Default, EgressAllow, EgressDeny)
+	}
+	for _, rule := range append(append([]string{}, egress.Allow...), egress.Deny...) {
+		if _, err := ParseEgressRule(rule); err != nil {
+
+			return fmt.Errorf("server '%s': %w", serverName, err)
+		}
+	}
+	if server.NetworkMode != "" {
+
+		return fmt.Errorf("server '%s' cannot restrict egress with network_mode '%s'", serverName, server.NetworkMode)
+	}
+	if len(server.Networks) > 0 {
+
+		return fmt.Errorf("server '%s' cannot restrict egress while joining networks %v", serverName, server.Networks)
+	}
+	// Joining the network of a peer without restricted egress would give the server a route out
+	for _, peer := range server.Peers {
+		if peerServer, exists := servers[peer]; exists && peerServer.Security.Egress == nil {
+
+			return fmt.Errorf("server '%s' restricts egress, so its peer '%s' must restrict egress too", serverName, peer)
+		}
+	}
+
+	return nil
+}
+
+func validateImageScan(v *validation, path string, server ServerConfig) {
+	scan := server.Security.Scan
+	if server.Image == "" && server.Build.Context == "" {
+		v.addf(path, "only the images of container servers can be scanned")
+	}
+	if scan.Scanner != "" && !slices.Contains(ImageScanners, scan.Scanner) {
+		v.addf(path+".scanner", "unknown scanner '%s' (must be one of: %s)", scan.Scanner, strings.Join(ImageScanners, ", "))
+	}
+	for _, threshold := range []struct{ field, value string }{{"fail_on", scan.FailOn}, {"warn_on", scan.WarnOn}} {
+		if threshold.value != "" && threshold.value != "none" && SeverityRank(threshold.value) == 0 {
+			v.addf(path+"."+threshold.field, "unknown severity '%s' (must be low, medium, high, critical or none)", threshold.value)
+		}
+	}
+}
+
 // NEW: Validate resource limits
 func validateResourceLimits(serverName string, resources ResourcesDeployConfig) error {
 	// Validate CPU limits
@@ -1107,6 +2726,54 @@ func validateResourceLimits(serverName string, resources ResourcesDeployConfig)
 	return nil
 }
 
+func validateResourceMirror(serverName string, mirror *ResourceMirrorConfig, storage *ObjectStorageConfig) error {
+	if mirror == nil || !mirror.Enabled {
+
+		return nil
+	}
+	if storage == nil || storage.Bucket == "" {
+
+		return fmt.Errorf("server '%s' enables resource_mirror but object_storage.bucket is not configured", serverName)
+	}
+	if mirror.TTL != "" {
+		if _, err := time.ParseDuration(mirror.TTL); err != nil {
+
+			return fmt.Errorf("server '%s' has invalid resource_mirror.ttl '%s': %w", serverName, mirror.TTL, err)
+		}
+	}
+	if mirror.MaxSize != "" {
+		if _, err := ParseByteSize(mirror.MaxSize); err != nil {
+
+			return fmt.Errorf("server '%s' has invalid resource_mirror.max_size: %w", serverName, err)
+		}
+	}
+
+	return nil
+}
+
+// ParseByteSize parses sizes in the same format as memory limits ("512m", "1g", "2048k", "100")
+func ParseByteSize(size string) (int64, error) {
+	if size == "" || !isValidMemoryFormat(size) {
+
+		return 0, fmt.Errorf("invalid size '%s'", size)
+	}
+
+	multipliers := map[byte]int64{'b': 1, 'k': 1 << 10, 'm': 1 << 20, 'g': 1 << 30}
+	lower := strings.ToLower(size)
+	multiplier := int64(1)
+	if m, ok := multipliers[lower[len(lower)-1]]; ok {
+		multiplier = m
+		lower = lower[:len(lower)-1]
+	}
+	value, err := strconv.ParseInt(lower, 10, 64)
+	if err != nil {
+
+		return 0, fmt.Errorf("invalid size '%s': %w", size, err)
+	}
+
+	return value * multiplier, nil
+}
+
 // Helper function to validate memory format (e.g., "512m", "1g", "2048k")
 func isValidMemoryFormat(memory string) bool {
 	if memory == "" {
@@ -1160,41 +2827,700 @@ func validatePortMapping(portMapping string) error {
 }
 
 // Validate global configuration
-func validateGlobalConfig(config *ComposeConfig) error {
+func validateGlobalConfig(v *validation, config *ComposeConfig) {
 	// Validate proxy auth
 	if config.ProxyAuth.Enabled && config.ProxyAuth.APIKey == "" {
-
-		return fmt.Errorf("proxy_auth is enabled but api_key is not specified")
+		v.addf("proxy_auth", "proxy_auth is enabled but api_key is not specified")
 	}
 	// Validate dashboard config
 	if config.Dashboard.Enabled {
 		if config.Dashboard.Port <= 0 || config.Dashboard.Port > 65535 {
-
-			return fmt.Errorf("dashboard port must be between 1 and 65535")
+			v.addf("dashboard.port", "dashboard port must be between 1 and 65535")
 		}
 		if config.Dashboard.ProxyURL == "" {
-
-			return fmt.Errorf("dashboard is enabled but proxy_url is not specified")
+			v.addf("dashboard", "dashboard is enabled but proxy_url is not specified")
 		}
 	}
+	if theme := config.Dashboard.Theme; theme != "" && theme != DashboardThemeDark && theme != DashboardThemeLight {
+		v.addf("dashboard.theme", "invalid theme '%s', must be '%s' or '%s'", theme, DashboardThemeDark, DashboardThemeLight)
+	}
+	if branding := config.Dashboard.Branding; branding != nil && branding.AccentColor != "" && !hexColorPattern.MatchString(branding.AccentColor) {
+		v.addf("dashboard.branding.accent_color", "invalid accent_color '%s', must be a color like #3b82f6", branding.AccentColor)
+	}
+	validateDashboardLogin(v, config)
+	if config.Isolation != "" && config.Isolation != IsolationShared && config.Isolation != IsolationStrict {
+		v.addf("isolation", "invalid isolation '%s', must be '%s' or '%s'", config.Isolation, IsolationShared, IsolationStrict)
+	}
+	if config.Platform != "" && !platformPattern.MatchString(config.Platform) {
+		v.addf("platform", "invalid platform '%s', must be os/arch[/variant] such as linux/arm64", config.Platform)
+	}
+	validateListenerTLS(v, "proxy_tls", config.ProxyTLS)
+	validateListenerTLS(v, "dashboard.tls", config.Dashboard.TLS)
+	validateCORS(v, config.CORS)
+	validateIPAccess(v, "proxy_access", config.ProxyAccess)
+	validateIPAccess(v, "dashboard.access", config.Dashboard.Access)
+	if config.OAuth != nil {
+		validateIPAccess(v, "oauth.access", config.OAuth.Access)
+	}
 	// Validate connections
-	for name, conn := range config.Connections {
-		if err := validateConnection(name, conn); err != nil {
-
-			return err
+	for _, name := range sortedMapKeys(config.Connections) {
+		v.add("connections."+name, validateConnection(name, config.Connections[name]))
+	}
+	v.add("locale", validateLocale("project", config.Locale))
+	v.add("logging.retention", validateLogRetention(config.Logging.Retention))
+	validateAuthFailureLog(v, config.Logging.AuthFailures)
+	v.add("memory.backup", validateMemoryBackup(config.Memory.Backup, config.ObjectStorage))
+	for _, name := range sortedMapKeys(config.Builtins) {
+		server := config.Builtins[name].Server
+		if _, err := MergeServer(ServerConfig{}, &server); err != nil {
+			v.addf("builtins."+name+".server", "%v", err)
+		}
+	}
+	validateNotifications(v, config.Notifications)
+	// Validate external dependencies
+	for _, name := range sortedMapKeys(config.ExternalDependencies) {
+		v.add("external_dependencies."+name, validateExternalDependency(fmt.Sprintf("external dependency '%s'", name), config.ExternalDependencies[name]))
+	}
+	v.add("proxy_auth.trusted_headers", validateTrustedHeaderAuth(config.ProxyAuth.TrustedHeaders, config.RBAC))
+	validateIdentityTokens(v, config.ProxyAuth.IdentityTokens)
+	// Validate aggregator endpoint
+	if config.Aggregator.Enabled {
+		if config.Aggregator.Path != "" && !strings.HasPrefix(config.Aggregator.Path, "/") {
+			v.addf("aggregator.path", "aggregator.path must start with '/'")
+		}
+		if _, exists := config.Servers[strings.Trim(config.Aggregator.EndpointPath(), "/")]; exists {
+			v.addf("aggregator.path", "aggregator.path '%s' conflicts with a server of the same name", config.Aggregator.EndpointPath())
+		}
+		for i, name := range config.Aggregator.Servers {
+			if _, exists := config.Servers[name]; !exists {
+				v.addf(fmt.Sprintf("aggregator.servers.%d", i), "aggregator includes undefined server '%s'", name)
+			}
 		}
 	}
+	if config.ControlServer.Enabled {
+		if _, exists := config.Servers[constants.ControlServerName]; exists {
+			v.addf("control_server", "server name '%s' is reserved for the control server", constants.ControlServerName)
+		}
+		if config.Aggregator.Enabled && strings.Trim(config.Aggregator.EndpointPath(), "/") == constants.ControlServerName {
+			v.addf("aggregator.path", "aggregator.path '%s' conflicts with the control server", config.Aggregator.EndpointPath())
+		}
+	}
+	if config.Sampling.Enabled {
+		validateSamplingBroker(v, config.Sampling)
+	}
+	validateRoots(v, config.Roots)
+	validateQuotas(v, config.Quotas)
 	// Validate OAuth config if present
 	if config.OAuth != nil && config.OAuth.Enabled {
-		if err := validateOAuthConfig(config.OAuth); err != nil {
+		v.add("oauth", validateOAuthConfig(config.OAuth))
+	}
+	validateTestScenarios(v, config)
+}
+
+func validateResponseCache(serverName string, cache *ResponseCacheConfig) error {
+	if cache == nil {
+
+		return nil
+	}
+	if d, err := time.ParseDuration(cache.TTL); err != nil || d <= 0 {
+
+		return fmt.Errorf("server '%s' has invalid response_cache ttl '%s'", serverName, cache.TTL)
+	}
+	if cache.MaxEntries < 0 {
+
+		return fmt.Errorf("server '%s' has invalid response_cache max_entries: %d (must be >= 0)", serverName, cache.MaxEntries)
+	}
+	for _, method := range cache.Methods {
+		cacheable := false
+		for _, allowed := range CacheableMethods {
+			if method == allowed {
+				cacheable = true
+
+				break
+			}
+		}
+		if !cacheable {
+
+			return fmt.Errorf("server '%s' cannot cache method '%s' (cacheable: %s)", serverName, method, strings.Join(CacheableMethods, ", "))
+		}
+	}
+
+	return nil
+}
+
+func validatePricing(serverName string, pricing *PricingConfig) error {
+	if pricing == nil {
+
+		return nil
+	}
+	prices := map[string]ToolPricing{"": pricing.ToolPricing}
+	for tool, price := range pricing.Tools {
+		if tool == "" {
+
+			return fmt.Errorf("server '%s' prices a tool without a name", serverName)
+		}
+		prices[tool] = price
+	}
+	for tool, price := range prices {
+		if price.PerCall < 0 || price.PerThousandInTokens < 0 || price.PerThousandOutTokens < 0 {
+			owner := "pricing"
+			if tool != "" {
+				owner = fmt.Sprintf("pricing of tool '%s'", tool)
+			}
 
-			return err
+			return fmt.Errorf("server '%s' has a negative price in its %s", serverName, owner)
 		}
 	}
 
 	return nil
 }
 
+func validateRequestLimits(serverName string, limits *RequestLimitsConfig) error {
+	if limits == nil {
+
+		return nil
+	}
+	for _, size := range []struct{ field, value string }{{"max_request_bytes", limits.MaxRequestBytes}, {"max_response_bytes", limits.MaxResponseBytes}} {
+		if size.value == "" {
+
+			continue
+		}
+		if n, err := ParseByteSize(size.value); err != nil || n <= 0 {
+
+			return fmt.Errorf("server '%s' has invalid limits.%s '%s'", serverName, size.field, size.value)
+		}
+	}
+	if limits.RequestTimeout != "" {
+		if d, err := time.ParseDuration(limits.RequestTimeout); err != nil || d <= 0 {
+
+			return fmt.Errorf("server '%s' has invalid limits.request_timeout '%s'", serverName, limits.RequestTimeout)
+		}
+	}
+
+	return nil
+}
+
+func validateCrashLoop(serverName string, crashLoop *CrashLoopConfig) error {
+	if crashLoop == nil {
+
+		return nil
+	}
+	if crashLoop.MaxRestarts < 0 {
+
+		return fmt.Errorf("server '%s' has invalid crash_loop max_restarts: %d (must be >= 0)", serverName, crashLoop.MaxRestarts)
+	}
+	for _, duration := range []struct{ field, value string }{{"window", crashLoop.Window}, {"backoff", crashLoop.Backoff}} {
+		if duration.value == "" {
+
+			continue
+		}
+		if d, err := time.ParseDuration(duration.value); err != nil || d <= 0 {
+
+			return fmt.Errorf("server '%s' has invalid crash_loop %s '%s'", serverName, duration.field, duration.value)
+		}
+	}
+
+	return nil
+}
+
+func validateNotifications(v *validation, notifications NotificationsConfig) {
+	checkEvents := func(path string, events []string) {
+		for i, event := range events {
+			known := false
+			for _, name := range NotificationEvents {
+				if event == name {
+					known = true
+
+					break
+				}
+			}
+			if !known {
+				v.addf(fmt.Sprintf("%s.events.%d", path, i), "unknown notification event '%s' (must be one of: %s)", event, strings.Join(NotificationEvents, ", "))
+			}
+		}
+	}
+	checkURL := func(path, value string) {
+		if parsed, err := url.Parse(value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v.addf(path, "notification URL '%s' must be an http or https URL", value)
+		}
+	}
+
+	for i, hook := range notifications.Webhooks {
+		path := fmt.Sprintf("notifications.webhooks.%d", i)
+		checkURL(path+".url", hook.URL)
+		checkEvents(path, hook.Events)
+	}
+	for i, hook := range notifications.Slack {
+		path := fmt.Sprintf("notifications.slack.%d", i)
+		checkURL(path+".webhook_url", hook.WebhookURL)
+		checkEvents(path, hook.Events)
+	}
+	for i, hook := range notifications.Email {
+		path := fmt.Sprintf("notifications.email.%d", i)
+		if hook.SMTPHost == "" {
+			v.addf(path+".smtp_host", "email notification needs smtp_host")
+		}
+		if hook.SMTPPort < 0 || hook.SMTPPort > 65535 {
+			v.addf(path+".smtp_port", "email notification smtp_port must be between 1 and 65535")
+		}
+		if hook.From == "" || len(hook.To) == 0 {
+			v.addf(path, "email notification needs from and to addresses")
+		}
+		checkEvents(path, hook.Events)
+	}
+}
+
+func validateRoots(v *validation, roots RootsConfig) {
+	check := func(path string, group []RootConfig) {
+		for i, root := range group {
+			parsed, err := url.Parse(root.URI)
+			if err != nil || parsed.Scheme != "file" || !strings.HasPrefix(parsed.Path, "/") {
+				v.addf(fmt.Sprintf("%s.%d.uri", path, i), "root '%s' must be a file:// URI with an absolute path", root.URI)
+			}
+		}
+	}
+	check("roots.default", roots.Default)
+	for _, name := range sortedMapKeys(roots.Profiles) {
+		if !profileNamePattern.MatchString(name) {
+			v.addf("roots.profiles."+name, "invalid profile name '%s'", name)
+		}
+		check("roots.profiles."+name, roots.Profiles[name])
+	}
+	for _, name := range sortedMapKeys(roots.Clients) {
+		check("roots.clients."+name, roots.Clients[name])
+	}
+}
+
+func validateQuotas(v *validation, quotas QuotasConfig) {
+	check := func(path, owner string, limits QuotaLimits) {
+		if limits.Calls < 0 || limits.Cost < 0 {
+			v.addf(path, "%s: quota limits must be >= 0", owner)
+		}
+	}
+	if quotas.Default != nil {
+		check("quotas.default", "default quota", *quotas.Default)
+	}
+	for _, clientID := range sortedMapKeys(quotas.Clients) {
+		if clientID == "" {
+			v.addf("quotas.clients", "quota for a client without an id")
+
+			continue
+		}
+		check("quotas.clients."+clientID, fmt.Sprintf("quota of client '%s'", clientID), quotas.Clients[clientID])
+	}
+}
+
+func validateSamplingBroker(v *validation, broker SamplingBrokerConfig) {
+	if _, known := SamplingProviders[broker.Provider]; !known {
+		v.addf("sampling.provider", "unknown sampling provider '%s' (must be one of: openai, openrouter, ollama)", broker.Provider)
+	}
+	if broker.BaseURL != "" {
+		if parsed, err := url.Parse(broker.BaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v.addf("sampling.base_url", "sampling base_url '%s' must be an http or https URL", broker.BaseURL)
+		}
+	}
+	if broker.APIKey == "" && broker.Provider != "ollama" && broker.Provider != "" {
+		v.addf("sampling.api_key", "sampling provider '%s' needs an api_key", broker.Provider)
+	}
+	if broker.Model == "" {
+		v.addf("sampling.model", "sampling needs a default model")
+	}
+	if broker.MaxTokens < 0 {
+		v.addf("sampling.max_tokens", "sampling max_tokens must be >= 0")
+	}
+	if broker.Timeout != "" {
+		if timeout, err := time.ParseDuration(broker.Timeout); err != nil || timeout <= 0 {
+			v.addf("sampling.timeout", "invalid sampling timeout '%s'", broker.Timeout)
+		}
+	}
+}
+
+func validateDashboardLogin(v *validation, config *ComposeConfig) {
+	for _, name := range sortedMapKeys(config.Users) {
+		if user := config.Users[name]; user != nil && user.PasswordHash != "" {
+			if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+				v.addf("users."+name+".password_hash", "user '%s' password_hash is not a bcrypt hash (write each $ as $$)", name)
+			}
+		}
+		if user := config.Users[name]; user != nil && user.TOTPSecret != "" {
+			secret := strings.TrimRight(strings.ToUpper(strings.ReplaceAll(user.TOTPSecret, " ", "")), "=")
+			if key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret); err != nil || len(key) == 0 {
+				v.addf("users."+name+".totp_secret", "user '%s' totp_secret is not base32", name)
+			}
+		}
+	}
+
+	login := config.Dashboard.AdminLogin
+	if login == nil || !login.Enabled {
+
+		return
+	}
+	if login.SessionTimeout != "" {
+		if timeout, err := time.ParseDuration(login.SessionTimeout); err != nil || timeout <= 0 {
+			v.addf("dashboard.admin_login.session_timeout", "invalid session_timeout '%s'", login.SessionTimeout)
+		}
+	}
+	if login.OAuth == nil || !login.OAuth.Enabled {
+
+		return
+	}
+	oauth := login.OAuth
+	if oauth.ClientID == "" {
+		v.addf("dashboard.admin_login.oauth.client_id", "OAuth login needs client_id")
+	}
+	for _, endpoint := range []struct{ field, value string }{
+		{"authorize_url", oauth.AuthorizeURL}, {"token_url", oauth.TokenURL}, {"userinfo_url", oauth.UserInfoURL},
+	} {
+		if parsed, err := url.Parse(endpoint.value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v.addf("dashboard.admin_login.oauth."+endpoint.field, "OAuth login %s '%s' must be an http or https URL", endpoint.field, endpoint.value)
+		}
+	}
+}
+
+func validateIPAccess(v *validation, path string, access *IPAccessConfig) {
+	if access == nil {
+
+		return
+	}
+	for _, list := range []struct {
+		field   string
+		entries []string
+	}{{"allow", access.Allow}, {"deny", access.Deny}, {"trusted_proxies", access.TrustedProxies}} {
+		for i, entry := range list.entries {
+			if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+				v.addf(fmt.Sprintf("%s.%s.%d", path, list.field, i), "invalid address '%s', must be an IP address or CIDR range", entry)
+			}
+		}
+	}
+}
+
+func validateCORS(v *validation, cors *CORSConfig) {
+	if cors == nil {
+
+		return
+	}
+	anyOrigin := false
+	for i, origin := range cors.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+			strings.TrimSuffix(parsed.Path, "/") != "" || parsed.RawQuery != "" || parsed.Fragment != "" || parsed.User != nil {
+			v.addf(fmt.Sprintf("cors.allowed_origins.%d", i), "invalid origin '%s', must be scheme://host[:port] or '*'", origin)
+		}
+	}
+	if anyOrigin && cors.AllowCredentials {
+		v.addf("cors.allow_credentials", "allow_credentials can't be used with the '*' origin, list the origins instead")
+	}
+	for i, method := range cors.AllowedMethods {
+		if method == "" || strings.ContainsAny(method, " ,") {
+			v.addf(fmt.Sprintf("cors.allowed_methods.%d", i), "invalid method '%s'", method)
+		}
+	}
+	if cors.MaxAge != "" {
+		if maxAge, err := time.ParseDuration(cors.MaxAge); err != nil || maxAge < 0 {
+			v.addf("cors.max_age", "invalid max_age '%s'", cors.MaxAge)
+		}
+	}
+}
+
+func validateListenerTLS(v *validation, path string, tlsConfig TLSConfig) {
+	if !tlsConfig.Enabled {
+
+		return
+	}
+	if tlsConfig.MinVersion != "" && tlsConfig.MinVersion != "1.2" && tlsConfig.MinVersion != "1.3" {
+		v.addf(path+".min_version", "min_version must be 1.2 or 1.3, not '%s'", tlsConfig.MinVersion)
+	}
+	usesFiles := tlsConfig.CertFile != "" || tlsConfig.KeyFile != ""
+	acme := tlsConfig.ACME
+	if acme == nil {
+		if tlsConfig.CertFile == "" || tlsConfig.KeyFile == "" {
+			v.addf(path, "tls needs both cert_file and key_file, or acme")
+		}
+
+		return
+	}
+	if usesFiles {
+		v.addf(path, "tls takes either cert_file and key_file or acme, not both")
+	}
+	if len(acme.Domains) == 0 {
+		v.addf(path+".acme.domains", "acme needs at least one domain")
+	}
+	for _, domain := range acme.Domains {
+		if domain == "" || strings.ContainsAny(domain, "/:* ") {
+			v.addf(path+".acme.domains", "invalid acme domain '%s'", domain)
+		}
+	}
+	if acme.HTTPPort < 0 || acme.HTTPPort > 65535 {
+		v.addf(path+".acme.http_port", "acme http_port must be between 1 and 65535")
+	}
+	if acme.DirectoryURL != "" {
+		if parsed, err := url.Parse(acme.DirectoryURL); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			v.addf(path+".acme.directory_url", "acme directory_url '%s' must be an https URL", acme.DirectoryURL)
+		}
+	}
+}
+
+func validateTestScenarios(v *validation, config *ComposeConfig) {
+	checkServer := func(path, scenario, server string) {
+		if _, exists := config.Servers[server]; server != "" && !exists {
+			v.addf(path, "test scenario '%s' uses undefined server '%s'", scenario, server)
+		}
+	}
+	checkExpect := func(path, scenario string, expect ResponseExpectation) {
+		if expect.Matches != "" {
+			if _, err := regexp.Compile(expect.Matches); err != nil {
+				v.addf(path+".expect.matches", "test scenario '%s' has invalid matches pattern: %v", scenario, err)
+			}
+		}
+	}
+
+	for i, scenario := range config.Development.Testing.Scenarios {
+		path := fmt.Sprintf("development.testing.scenarios.%d", i)
+		if scenario.Name == "" {
+			v.addf(path, "test scenario %d has no name", i)
+		}
+		for j, server := range scenario.Servers {
+			checkServer(fmt.Sprintf("%s.servers.%d", path, j), scenario.Name, server)
+		}
+		for j, tool := range scenario.Tools {
+			toolPath := fmt.Sprintf("%s.tools.%d", path, j)
+			if tool.Name == "" {
+				v.addf(toolPath, "test scenario '%s' has a tool check without a name", scenario.Name)
+			}
+			checkServer(toolPath+".server", scenario.Name, tool.Server)
+			checkExpect(toolPath, scenario.Name, tool.Expect)
+		}
+		for j, resource := range scenario.Resources {
+			resourcePath := fmt.Sprintf("%s.resources.%d", path, j)
+			switch {
+			case resource.URI != "" && resource.Server == "":
+				v.addf(resourcePath, "test scenario '%s' reads resource '%s' without a server", scenario.Name, resource.URI)
+			case resource.URI == "" && resource.Path == "":
+				v.addf(resourcePath, "test scenario '%s' has a resource check without a path or uri", scenario.Name)
+			}
+			checkServer(resourcePath+".server", scenario.Name, resource.Server)
+			checkExpect(resourcePath, scenario.Name, resource.Expect)
+		}
+	}
+}
+
+func validateLogRetention(retention LogRetention) error {
+	if retention.MaxSize != "" {
+		if _, err := ParseByteSize(retention.MaxSize); err != nil {
+
+			return fmt.Errorf("logging.retention.max_size: %w", err)
+		}
+	}
+	durations := []struct{ field, value string }{
+		{"rotate_every", retention.RotateEvery},
+		{"max_age", retention.MaxAge},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+
+			continue
+		}
+		if parsed, err := time.ParseDuration(d.value); err != nil || parsed <= 0 {
+
+			return fmt.Errorf("logging.retention.%s must be a positive duration, got '%s'", d.field, d.value)
+		}
+	}
+	if retention.MaxFiles < 0 {
+
+		return fmt.Errorf("logging.retention.max_files cannot be negative")
+	}
+
+	return nil
+}
+
+func validateAuthFailureLog(v *validation, log *AuthFailureLog) {
+	if log == nil {
+
+		return
+	}
+	if log.Format != "" && log.Format != "text" && log.Format != "json" {
+		v.addf("logging.auth_failures.format", "unknown format '%s' (must be text or json)", log.Format)
+	}
+	if log.AlertInterval != "" {
+		if d, err := time.ParseDuration(log.AlertInterval); err != nil || d <= 0 {
+			v.addf("logging.auth_failures.alert_interval", "must be a positive duration, got '%s'", log.AlertInterval)
+		}
+	}
+}
+
+func validateMemoryBackup(backup *MemoryBackup, storage *ObjectStorageConfig) error {
+	if backup == nil {
+
+		return nil
+	}
+	if parsed, err := time.ParseDuration(backup.Interval); err != nil || parsed <= 0 {
+
+		return fmt.Errorf("memory.backup.interval must be a positive duration, got '%s'", backup.Interval)
+	}
+	if backup.Format != "" && backup.Format != MemoryDumpJSON && backup.Format != MemoryDumpSQL {
+
+		return fmt.Errorf("memory.backup.format must be '%s' or '%s', got '%s'", MemoryDumpJSON, MemoryDumpSQL, backup.Format)
+	}
+	if backup.Keep < 0 {
+
+		return fmt.Errorf("memory.backup.keep cannot be negative")
+	}
+	if backup.S3 && (storage == nil || storage.Bucket == "") {
+
+		return fmt.Errorf("memory.backup.s3 is set but object_storage.bucket is not configured")
+	}
+
+	return nil
+}
+
+func validateLogFilterRule(serverName string, index int, rule LogFilterRule) error {
+	switch rule.Action {
+	case "drop", "downgrade":
+		if rule.Pattern == "" {
+
+			return fmt.Errorf("server '%s' log_filters[%d]: %s requires a pattern", serverName, index, rule.Action)
+		}
+	case "rate_limit":
+		if rule.Window != "" {
+			if window, err := time.ParseDuration(rule.Window); err != nil || window <= 0 {
+
+				return fmt.Errorf("server '%s' log_filters[%d]: invalid window '%s'", serverName, index, rule.Window)
+			}
+		}
+		if rule.Burst < 0 {
+
+			return fmt.Errorf("server '%s' log_filters[%d]: burst cannot be negative", serverName, index)
+		}
+	default:
+
+		return fmt.Errorf("server '%s' log_filters[%d]: unknown action '%s' (must be drop, downgrade or rate_limit)", serverName, index, rule.Action)
+	}
+	if rule.Pattern != "" {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+
+			return fmt.Errorf("server '%s' log_filters[%d]: invalid pattern: %w", serverName, index, err)
+		}
+	}
+
+	return nil
+}
+
+func validateLocale(owner string, locale LocaleConfig) error {
+	if locale.Timezone == "" {
+
+		return nil
+	}
+	if _, err := time.LoadLocation(locale.Timezone); err != nil {
+
+		return fmt.Errorf("%s has unknown timezone '%s': %w", owner, locale.Timezone, err)
+	}
+
+	return nil
+}
+
+// validateExternalDependency checks a dependency; owner names it in errors
+func validateExternalDependency(owner string, dep ExternalDependency) error {
+	kinds := 0
+	for _, target := range []string{dep.URL, dep.TCP, dep.HTTP, dep.Postgres} {
+		if target != "" {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+
+		return fmt.Errorf("%s must set exactly one of url, tcp, http or postgres", owner)
+	}
+	if dep.URL != "" {
+		scheme, _, found := strings.Cut(dep.URL, "://")
+		if !found || !slices.Contains([]string{"tcp", "http", "https", "postgres", "postgresql"}, strings.ToLower(scheme)) {
+
+			return fmt.Errorf("%s url must start with tcp://, http://, https:// or postgres://", owner)
+		}
+		dep = dep.Check()
+	}
+	if dep.TCP != "" {
+		if _, _, err := net.SplitHostPort(dep.TCP); err != nil {
+
+			return fmt.Errorf("%s has invalid tcp address '%s': %w", owner, dep.TCP, err)
+		}
+	}
+	if dep.HTTP != "" && !strings.HasPrefix(dep.HTTP, "http://") && !strings.HasPrefix(dep.HTTP, "https://") {
+
+		return fmt.Errorf("%s http must be an http(s) URL", owner)
+	}
+	for field, value := range map[string]string{"timeout": dep.Timeout, "interval": dep.Interval} {
+		if value == "" {
+
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+
+			return fmt.Errorf("%s has invalid %s '%s': %w", owner, field, value, err)
+		}
+	}
+
+	return nil
+}
+
+func validateTrustedHeaderAuth(trusted *TrustedHeaderAuthConfig, rbac *RBACConfig) error {
+	if trusted == nil || !trusted.Enabled {
+
+		return nil
+	}
+	if len(trusted.TrustedProxies) == 0 {
+
+		return fmt.Errorf("proxy_auth.trusted_headers requires at least one trusted_proxies entry")
+	}
+	for _, entry := range trusted.TrustedProxies {
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+
+			return fmt.Errorf("proxy_auth.trusted_headers has invalid trusted proxy '%s'", entry)
+		}
+	}
+
+	roleExists := func(role string) bool {
+		if rbac == nil {
+
+			return false
+		}
+		_, exists := rbac.Roles[role]
+
+		return exists
+	}
+	for group, role := range trusted.GroupRoles {
+		if !roleExists(role) {
+
+			return fmt.Errorf("proxy_auth.trusted_headers maps group '%s' to undefined role '%s'", group, role)
+		}
+	}
+	if trusted.DefaultRole != "" && !roleExists(trusted.DefaultRole) {
+
+		return fmt.Errorf("proxy_auth.trusted_headers.default_role '%s' is not defined in rbac.roles", trusted.DefaultRole)
+	}
+
+	return nil
+}
+
+// headerNamePattern matches the characters an HTTP header name may have
+var headerNamePattern = regexp.MustCompile(`^[A-Za-z0-9!#$%&'*+.^_|~-]+$`)
+
+func validateIdentityTokens(v *validation, tokens *IdentityTokenConfig) {
+	if tokens == nil {
+
+		return
+	}
+	if tokens.TTL != "" {
+		if ttl, err := time.ParseDuration(tokens.TTL); err != nil || ttl <= 0 {
+			v.addf("proxy_auth.identity_tokens.ttl", "invalid identity token ttl '%s'", tokens.TTL)
+		}
+	}
+}
+
 // Validate OAuth configuration
 func validateOAuthConfig(oauth *OAuthConfig) error {
 	if oauth.Issuer == "" {
@@ -1214,6 +3540,52 @@ func validateOAuthConfig(oauth *OAuthConfig) error {
 			return fmt.Errorf("invalid oauth.tokens.refresh_token_ttl: %w", err)
 		}
 	}
+	if oauth.Tokens.CodeTTL != "" {
+		if _, err := time.ParseDuration(oauth.Tokens.CodeTTL); err != nil {
+
+			return fmt.Errorf("invalid oauth.tokens.authorization_code_ttl: %w", err)
+		}
+	}
+	if verification := oauth.Endpoints.DeviceVerification; verification != "" {
+		if parsed, err := url.Parse(verification); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+
+			return fmt.Errorf("oauth.endpoints.device_verification must be an absolute URL, got '%s'", verification)
+		}
+	}
+	switch oauth.Tokens.Algorithm {
+	case "", "HS256", "RS256", "ES256":
+	default:
+
+		return fmt.Errorf("unsupported oauth.tokens.algorithm '%s': use HS256, RS256 or ES256", oauth.Tokens.Algorithm)
+	}
+	if oauth.Tokens.KeyRotation != "" {
+		if rotation, err := time.ParseDuration(oauth.Tokens.KeyRotation); err != nil || rotation < 0 {
+
+			return fmt.Errorf("invalid oauth.tokens.key_rotation '%s'", oauth.Tokens.KeyRotation)
+		}
+	}
+	bruteForce := oauth.Security.BruteForce
+	if bruteForce.MaxFailures < 0 {
+
+		return fmt.Errorf("oauth.security.brute_force.max_failures cannot be negative")
+	}
+	durations := map[string]string{"window": bruteForce.Window, "lockout": bruteForce.Lockout, "max_lockout": bruteForce.MaxLockout}
+	for _, name := range []string{"window", "lockout", "max_lockout"} {
+		if value := durations[name]; value != "" {
+			if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+
+				return fmt.Errorf("invalid oauth.security.brute_force.%s '%s'", name, value)
+			}
+		}
+	}
+	if bruteForce.Lockout != "" && bruteForce.MaxLockout != "" {
+		lockout, _ := time.ParseDuration(bruteForce.Lockout)
+		maxLockout, _ := time.ParseDuration(bruteForce.MaxLockout)
+		if maxLockout < lockout {
+
+			return fmt.Errorf("oauth.security.brute_force.max_lockout cannot be shorter than lockout")
+		}
+	}
 
 	return nil
 }
@@ -1237,13 +3609,22 @@ func validateConnection(name string, conn ConnectionConfig) error {
 
 		return fmt.Errorf("connection '%s' has invalid port: %d", name, conn.Port)
 	}
+	if conn.TLS && (conn.CertFile == "" || conn.KeyFile == "") {
+
+		return fmt.Errorf("connection '%s' enables tls but does not set both cert_file and key_file", name)
+	}
 
 	return nil
 }
 
-// GetProjectName returns the project name based on the directory containing the config file
+// GetProjectName returns the project set with --project-name, or the name of the directory
+// containing the config file
 func GetProjectName(filePath string) string {
-	dir := filepath.Dir(filePath)
+	if projectNamespace != "" {
+
+		return projectNamespace
+	}
+	dir := ProjectDir(filePath)
 	if dir == "." {
 		if cwd, err := os.Getwd(); err == nil {
 			dir = cwd
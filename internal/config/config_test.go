@@ -1,9 +1,31 @@
 package config
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -89,7 +111,7 @@ func TestExpandEnvVars(t *testing.T) {
 			name:     "env var with default syntax (no expansion)",
 			input:    "${TEST_VAR:-default}",
 			envVars:  map[string]string{},
-			expected: "", // os.ExpandEnv doesn't support default values, returns empty string for unset vars
+			expected: "", // expandEnv doesn't support default values, returns empty string for unset vars
 		},
 		{
 			name:     "multiple env vars",
@@ -103,6 +125,12 @@ func TestExpandEnvVars(t *testing.T) {
 			envVars:  map[string]string{},
 			expected: "plain_text",
 		},
+		{
+			name:     "escaped dollar",
+			input:    "$$2a$$10$$hash",
+			envVars:  map[string]string{},
+			expected: "$2a$10$hash",
+		},
 	}
 
 	for _, tt := range tests {
@@ -121,7 +149,7 @@ func TestExpandEnvVars(t *testing.T) {
 				}
 			}()
 
-			result := os.ExpandEnv(tt.input)
+			result := expandEnv(tt.input)
 			if result != tt.expected {
 				t.Errorf("Expected %q, got %q", tt.expected, result)
 			}
@@ -129,6 +157,40 @@ func TestExpandEnvVars(t *testing.T) {
 	}
 }
 
+func TestReferencedEnv(t *testing.T) {
+	dir := t.TempDir()
+	base := `servers:
+  github:
+    env:
+      TOKEN: ${GITHUB_TOKEN}
+      HASH: $$2a$$10
+      HOME: $HOME
+`
+	override := `servers:
+  github:
+    env:
+      TOKEN: ${GITHUB_TOKEN}
+      LEVEL: ${LOG_LEVEL}
+`
+	basePath, overridePath := dir+"/mcp-compose.yaml", dir+"/override.yaml"
+	for path, content := range map[string]string{basePath: base, overridePath: override} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	SetOverrideFiles([]string{overridePath})
+	defer SetOverrideFiles(nil)
+
+	names, err := ReferencedEnv(basePath)
+	if err != nil {
+		t.Fatalf("ReferencedEnv failed: %v", err)
+	}
+	if got := strings.Join(names, ","); got != "GITHUB_TOKEN,HOME,LOG_LEVEL" {
+		t.Errorf("Expected GITHUB_TOKEN,HOME,LOG_LEVEL, got %s", got)
+	}
+}
+
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -212,6 +274,60 @@ func TestOAuthConfig(t *testing.T) {
 			},
 			valid: true,
 		},
+		{
+			name: "signed access tokens",
+			config: OAuthConfig{
+				Enabled: true,
+				Issuer:  "https://oauth.example.com",
+				Tokens:  TokenConfig{Algorithm: "ES256", KeyRotation: "168h"},
+			},
+			valid: true,
+		},
+		{
+			name: "unsupported algorithm",
+			config: OAuthConfig{
+				Enabled: true,
+				Issuer:  "https://oauth.example.com",
+				Tokens:  TokenConfig{Algorithm: "none"},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid key rotation",
+			config: OAuthConfig{
+				Enabled: true,
+				Issuer:  "https://oauth.example.com",
+				Tokens:  TokenConfig{Algorithm: "RS256", KeyRotation: "monthly"},
+			},
+			valid: false,
+		},
+		{
+			name: "brute force limits",
+			config: OAuthConfig{
+				Enabled:  true,
+				Issuer:   "https://oauth.example.com",
+				Security: OAuthSecurityConfig{BruteForce: BruteForceConfig{MaxFailures: 10, Window: "10m", Lockout: "30s", MaxLockout: "30m"}},
+			},
+			valid: true,
+		},
+		{
+			name: "brute force max lockout shorter than lockout",
+			config: OAuthConfig{
+				Enabled:  true,
+				Issuer:   "https://oauth.example.com",
+				Security: OAuthSecurityConfig{BruteForce: BruteForceConfig{Lockout: "1h", MaxLockout: "5m"}},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid brute force window",
+			config: OAuthConfig{
+				Enabled:  true,
+				Issuer:   "https://oauth.example.com",
+				Security: OAuthSecurityConfig{BruteForce: BruteForceConfig{Window: "0s"}},
+			},
+			valid: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -220,6 +336,9 @@ func TestOAuthConfig(t *testing.T) {
 				if tt.config.Issuer == "" {
 					t.Error("Expected issuer to be set when OAuth is enabled")
 				}
+				if err := validateOAuthConfig(&tt.config); (err == nil) != tt.valid {
+					t.Errorf("Expected valid=%v, got %v", tt.valid, err)
+				}
 			}
 		})
 	}
@@ -408,3 +527,1900 @@ func TestParseTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  int64
+		expectErr bool
+	}{
+		{name: "plain bytes", input: "100", expected: 100},
+		{name: "kilobytes", input: "2k", expected: 2048},
+		{name: "megabytes upper case", input: "512M", expected: 512 << 20},
+		{name: "gigabytes", input: "1g", expected: 1 << 30},
+		{name: "invalid suffix", input: "10x", expectErr: true},
+		{name: "empty string", input: "", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseByteSize(tt.input)
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestServerEnvLocale(t *testing.T) {
+	cfg := &ComposeConfig{
+		Locale: LocaleConfig{Timezone: "Europe/Berlin", Lang: "de_DE.UTF-8"},
+		Servers: map[string]ServerConfig{
+			"inherits":  {},
+			"overrides": {Locale: &LocaleConfig{Timezone: "Asia/Tokyo"}},
+			"explicit":  {Env: map[string]string{"TZ": "UTC"}},
+		},
+	}
+
+	tests := []struct {
+		server       string
+		expectedTZ   string
+		expectedLang string
+	}{
+		{server: "inherits", expectedTZ: "Europe/Berlin", expectedLang: "de_DE.UTF-8"},
+		{server: "overrides", expectedTZ: "Asia/Tokyo", expectedLang: "de_DE.UTF-8"},
+		{server: "explicit", expectedTZ: "UTC", expectedLang: "de_DE.UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.server, func(t *testing.T) {
+			env := cfg.ServerEnv(tt.server)
+			if env["TZ"] != tt.expectedTZ {
+				t.Errorf("Expected TZ %s, got %s", tt.expectedTZ, env["TZ"])
+			}
+			if env["LANG"] != tt.expectedLang {
+				t.Errorf("Expected LANG %s, got %s", tt.expectedLang, env["LANG"])
+			}
+		})
+	}
+
+	if _, set := cfg.Servers["inherits"].Env["TZ"]; set {
+		t.Error("Expected ServerEnv not to modify the server config")
+	}
+}
+
+func TestValidateLogFilterRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        LogFilterRule
+		expectError bool
+	}{
+		{name: "drop", rule: LogFilterRule{Action: "drop", Pattern: "GET /health"}},
+		{name: "rate limit every line", rule: LogFilterRule{Action: "rate_limit", Window: "30s", Burst: 3}},
+		{name: "drop without pattern", rule: LogFilterRule{Action: "drop"}, expectError: true},
+		{name: "bad pattern", rule: LogFilterRule{Action: "downgrade", Pattern: "("}, expectError: true},
+		{name: "bad window", rule: LogFilterRule{Action: "rate_limit", Window: "soon"}, expectError: true},
+		{name: "unknown action", rule: LogFilterRule{Action: "mute", Pattern: "x"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLogFilterRule("noisy", 0, tt.rule)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateLogRetention(t *testing.T) {
+	tests := []struct {
+		name        string
+		retention   LogRetention
+		expectError bool
+	}{
+		{name: "defaults", retention: LogRetention{}},
+		{name: "size and age", retention: LogRetention{MaxSize: "20m", RotateEvery: "24h", MaxFiles: 3, MaxAge: "168h"}},
+		{name: "bad size", retention: LogRetention{MaxSize: "lots"}, expectError: true},
+		{name: "bad rotate_every", retention: LogRetention{RotateEvery: "daily"}, expectError: true},
+		{name: "negative max_age", retention: LogRetention{MaxAge: "-1h"}, expectError: true},
+		{name: "negative max_files", retention: LogRetention{MaxFiles: -1}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLogRetention(tt.retention)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateAuthFailureLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		log         *AuthFailureLog
+		expectError bool
+	}{
+		{name: "unset"},
+		{name: "defaults", log: &AuthFailureLog{}},
+		{name: "json file", log: &AuthFailureLog{Path: "logs/auth.log", Format: "json", AlertInterval: "15m"}},
+		{name: "bad format", log: &AuthFailureLog{Format: "syslog"}, expectError: true},
+		{name: "bad alert_interval", log: &AuthFailureLog{AlertInterval: "0s"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &validation{}
+			validateAuthFailureLog(v, tt.log)
+			if tt.expectError && len(v.errs) == 0 {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && len(v.errs) > 0 {
+				t.Errorf("Unexpected error: %v", v.errs)
+			}
+		})
+	}
+}
+
+func TestImageScanConfig(t *testing.T) {
+	var server struct {
+		Security SecurityConfig `yaml:"security"`
+	}
+	for document, expected := range map[string]ImageScanConfig{
+		"security: {scan: true}":                           {Enabled: true},
+		"security: {scan: false}":                          {},
+		"security: {scan: {fail_on: high}}":                {Enabled: true, FailOn: "high"},
+		"security: {scan: {enabled: false, warn_on: low}}": {WarnOn: "low"},
+	} {
+		server.Security = SecurityConfig{}
+		if err := yaml.Unmarshal([]byte(document), &server); err != nil {
+			t.Fatalf("Failed to parse %q: %v", document, err)
+		}
+		if *server.Security.Scan != expected {
+			t.Errorf("Expected %q to give %+v, got %+v", document, expected, *server.Security.Scan)
+		}
+	}
+	if failOn, warnOn := (&ImageScanConfig{WarnOn: "none"}).Thresholds(); failOn != "critical" || warnOn != "none" {
+		t.Errorf("Expected the default fail_on and warn_on none, got %s and %s", failOn, warnOn)
+	}
+
+	tests := []struct {
+		name        string
+		server      ServerConfig
+		expectError bool
+	}{
+		{name: "image", server: ServerConfig{Image: "ghcr.io/example/mcp:1.0", Security: SecurityConfig{Scan: &ImageScanConfig{Enabled: true, Scanner: "grype", FailOn: "none", WarnOn: "medium"}}}},
+		{name: "build", server: ServerConfig{Build: BuildConfig{Context: "./server"}, Security: SecurityConfig{Scan: &ImageScanConfig{Enabled: true}}}},
+		{name: "process", server: ServerConfig{Command: "node", Security: SecurityConfig{Scan: &ImageScanConfig{Enabled: true}}}, expectError: true},
+		{name: "unknown scanner", server: ServerConfig{Image: "example/mcp", Security: SecurityConfig{Scan: &ImageScanConfig{Enabled: true, Scanner: "clair"}}}, expectError: true},
+		{name: "unknown severity", server: ServerConfig{Image: "example/mcp", Security: SecurityConfig{Scan: &ImageScanConfig{Enabled: true, FailOn: "severe"}}}, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &validation{}
+			validateImageScan(v, "servers.web.security.scan", tt.server)
+			if tt.expectError && len(v.errs) == 0 {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && len(v.errs) > 0 {
+				t.Errorf("Unexpected error: %v", v.errs)
+			}
+		})
+	}
+}
+
+func TestValidateMemoryBackup(t *testing.T) {
+	bucket := &ObjectStorageConfig{Bucket: "backups"}
+	tests := []struct {
+		name        string
+		backup      *MemoryBackup
+		storage     *ObjectStorageConfig
+		expectError bool
+	}{
+		{name: "unset", backup: nil},
+		{name: "daily json", backup: &MemoryBackup{Interval: "24h", Keep: 7}},
+		{name: "sql to s3", backup: &MemoryBackup{Interval: "6h", Format: MemoryDumpSQL, S3: true}, storage: bucket},
+		{name: "missing interval", backup: &MemoryBackup{}, expectError: true},
+		{name: "bad interval", backup: &MemoryBackup{Interval: "daily"}, expectError: true},
+		{name: "bad format", backup: &MemoryBackup{Interval: "1h", Format: "csv"}, expectError: true},
+		{name: "negative keep", backup: &MemoryBackup{Interval: "1h", Keep: -1}, expectError: true},
+		{name: "s3 without bucket", backup: &MemoryBackup{Interval: "1h", S3: true}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMemoryBackup(tt.backup, tt.storage)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateCrashLoop(t *testing.T) {
+	tests := []struct {
+		name        string
+		crashLoop   *CrashLoopConfig
+		expectError bool
+	}{
+		{name: "unset", crashLoop: nil},
+		{name: "defaults", crashLoop: &CrashLoopConfig{}},
+		{name: "limits", crashLoop: &CrashLoopConfig{MaxRestarts: 3, Window: "5m", Backoff: "1m"}},
+		{name: "negative max_restarts", crashLoop: &CrashLoopConfig{MaxRestarts: -1}, expectError: true},
+		{name: "bad window", crashLoop: &CrashLoopConfig{Window: "soon"}, expectError: true},
+		{name: "zero backoff", crashLoop: &CrashLoopConfig{Backoff: "0s"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCrashLoop("test", tt.crashLoop)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDashboardLogin(t *testing.T) {
+	// bcrypt hash of "secret"
+	const hash = "$2a$10$4L5FY2vzkZEAqH5ze1ZZ4uGpLl2pqyjLf3SSndIsb.vRcgGfH1B92"
+	oauth := &DashboardOAuthLogin{
+		Enabled:      true,
+		ClientID:     "dashboard",
+		AuthorizeURL: "https://sso.example.com/authorize",
+		TokenURL:     "https://sso.example.com/token",
+		UserInfoURL:  "https://sso.example.com/userinfo",
+	}
+
+	tests := []struct {
+		name        string
+		login       *DashboardAdminLogin
+		users       map[string]*User
+		expectError bool
+	}{
+		{name: "disabled", login: &DashboardAdminLogin{SessionTimeout: "soon"}},
+		{name: "passwords", login: &DashboardAdminLogin{Enabled: true, SessionTimeout: "8h"}, users: map[string]*User{"alice": {PasswordHash: hash}}},
+		{name: "oauth", login: &DashboardAdminLogin{Enabled: true, OAuth: oauth}},
+		{name: "bad session_timeout", login: &DashboardAdminLogin{Enabled: true, SessionTimeout: "soon"}, expectError: true},
+		{name: "plain-text password", login: &DashboardAdminLogin{Enabled: true}, users: map[string]*User{"alice": {PasswordHash: "secret"}}, expectError: true},
+		{name: "totp secret", users: map[string]*User{"alice": {PasswordHash: hash, TOTPSecret: "jbsw y3dp ehpk 3pxp"}}},
+		{name: "totp secret not base32", users: map[string]*User{"alice": {PasswordHash: hash, TOTPSecret: "not-a-secret!"}}, expectError: true},
+		{name: "oauth without client_id", login: &DashboardAdminLogin{Enabled: true, OAuth: &DashboardOAuthLogin{
+			Enabled: true, AuthorizeURL: oauth.AuthorizeURL, TokenURL: oauth.TokenURL, UserInfoURL: oauth.UserInfoURL,
+		}}, expectError: true},
+		{name: "oauth without endpoints", login: &DashboardAdminLogin{Enabled: true, OAuth: &DashboardOAuthLogin{Enabled: true, ClientID: "dashboard"}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ComposeConfig{Users: tt.users}
+			cfg.Dashboard.AdminLogin = tt.login
+
+			v := &validation{}
+			validateDashboardLogin(v, cfg)
+			if tt.expectError && len(v.errs) == 0 {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && len(v.errs) > 0 {
+				t.Errorf("Unexpected error: %v", v.errs)
+			}
+		})
+	}
+}
+
+func TestValidateListenerTLS(t *testing.T) {
+	tests := []struct {
+		name        string
+		tls         TLSConfig
+		expectError bool
+	}{
+		{name: "disabled", tls: TLSConfig{CertFile: "only-cert.pem"}},
+		{name: "files", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", MinVersion: "1.3"}},
+		{name: "acme", tls: TLSConfig{Enabled: true, ACME: &ACMEConfig{Domains: []string{"mcp.example.com"}, HTTPPort: 80}}},
+		{name: "missing key", tls: TLSConfig{Enabled: true, CertFile: "cert.pem"}, expectError: true},
+		{name: "no certificate source", tls: TLSConfig{Enabled: true}, expectError: true},
+		{name: "files and acme", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", ACME: &ACMEConfig{Domains: []string{"mcp.example.com"}}}, expectError: true},
+		{name: "acme without domains", tls: TLSConfig{Enabled: true, ACME: &ACMEConfig{}}, expectError: true},
+		{name: "acme wildcard domain", tls: TLSConfig{Enabled: true, ACME: &ACMEConfig{Domains: []string{"*.example.com"}}}, expectError: true},
+		{name: "plain http directory", tls: TLSConfig{Enabled: true, ACME: &ACMEConfig{Domains: []string{"mcp.example.com"}, DirectoryURL: "http://ca.local/dir"}}, expectError: true},
+		{name: "bad min_version", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", MinVersion: "1.0"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &validation{}
+			validateListenerTLS(v, "proxy_tls", tt.tls)
+			if tt.expectError && len(v.errs) == 0 {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && len(v.errs) > 0 {
+				t.Errorf("Unexpected error: %v", v.errs)
+			}
+		})
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		cors        *CORSConfig
+		expectError bool
+	}{
+		{name: "unset"},
+		{name: "origins", cors: &CORSConfig{AllowedOrigins: []string{"https://app.example.com", "http://localhost:5173/"}, AllowCredentials: true, MaxAge: "10m"}},
+		{name: "any origin", cors: &CORSConfig{AllowedOrigins: []string{"*"}}},
+		{name: "origin with a path", cors: &CORSConfig{AllowedOrigins: []string{"https://app.example.com/ui"}}, expectError: true},
+		{name: "origin without a scheme", cors: &CORSConfig{AllowedOrigins: []string{"app.example.com"}}, expectError: true},
+		{name: "any origin with credentials", cors: &CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, expectError: true},
+		{name: "bad method", cors: &CORSConfig{AllowedMethods: []string{"GET, POST"}}, expectError: true},
+		{name: "bad max_age", cors: &CORSConfig{MaxAge: "soon"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &validation{}
+			validateCORS(v, tt.cors)
+			if tt.expectError && len(v.errs) == 0 {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && len(v.errs) > 0 {
+				t.Errorf("Unexpected error: %v", v.errs)
+			}
+		})
+	}
+}
+
+func TestValidateIPAccess(t *testing.T) {
+	v := &validation{}
+	validateIPAccess(v, "proxy_access", &IPAccessConfig{
+		Allow:          []string{"192.168.1.0/24", "10.0.0.5", "fd00::/8"},
+		Deny:           []string{"192.168.1.66"},
+		TrustedProxies: []string{"172.17.0.1"},
+	})
+	validateIPAccess(v, "dashboard.access", nil)
+	if len(v.errs) > 0 {
+		t.Fatalf("Unexpected error: %v", v.errs)
+	}
+
+	validateIPAccess(v, "servers.files.access", &IPAccessConfig{Allow: []string{"192.168.1.0/33", "lan"}})
+	if len(v.errs) != 2 {
+		t.Errorf("Expected both invalid entries to be reported, got %v", v.errs)
+	}
+}
+
+func TestExposure(t *testing.T) {
+	cfg := &ComposeConfig{}
+	if cfg.ProxyBindHost() != "127.0.0.1" || cfg.Dashboard.BindHost() != "127.0.0.1" {
+		t.Fatalf("Expected loopback by default, got %s and %s", cfg.ProxyBindHost(), cfg.Dashboard.BindHost())
+	}
+	if err := cfg.CheckDashboardExposure("0.0.0.0", true); err == nil {
+		t.Error("Expected a wildcard dashboard host without expose to be refused, even with --insecure")
+	}
+
+	cfg.ProxyExpose = true
+	cfg.Dashboard.Expose = true
+	if cfg.ProxyBindHost() != "0.0.0.0" || cfg.Dashboard.BindHost() != "0.0.0.0" {
+		t.Fatalf("Expected every interface with expose, got %s and %s", cfg.ProxyBindHost(), cfg.Dashboard.BindHost())
+	}
+	if err := cfg.CheckProxyExposure(cfg.ProxyBindHost(), "", false); err == nil {
+		t.Error("Expected an exposed proxy without authentication to be refused")
+	}
+	if err := cfg.CheckProxyExposure(cfg.ProxyBindHost(), "", true); err != nil {
+		t.Errorf("Expected --insecure to allow it, got %v", err)
+	}
+	cfg.ProxyAuth.TrustedHeaders = &TrustedHeaderAuthConfig{Enabled: true, TrustedProxies: []string{"10.0.0.1"}}
+	if err := cfg.CheckProxyExposure(cfg.ProxyBindHost(), "", false); err == nil {
+		t.Error("Expected trusted headers without an API key to be refused, as clients outside the trusted proxies get in without them")
+	}
+	if err := cfg.CheckProxyExposure(cfg.ProxyBindHost(), "flag-key", false); err != nil {
+		t.Errorf("Expected an API key to allow it, got %v", err)
+	}
+	if err := cfg.CheckDashboardExposure(cfg.Dashboard.BindHost(), false); err == nil {
+		t.Error("Expected an exposed dashboard without admin_login to be refused")
+	}
+	cfg.Dashboard.AdminLogin = &DashboardAdminLogin{Enabled: true}
+	if err := cfg.CheckDashboardExposure(cfg.Dashboard.BindHost(), false); err != nil {
+		t.Errorf("Expected admin_login to allow it, got %v", err)
+	}
+
+	for host, loopback := range map[string]bool{"127.0.0.1": true, "localhost": true, "[::1]": true, "0.0.0.0": false, "::": false, "192.168.1.5": false} {
+		if LoopbackHost(host) != loopback {
+			t.Errorf("Expected LoopbackHost(%q) to be %v", host, loopback)
+		}
+	}
+}
+
+func TestProxyListenerTLS(t *testing.T) {
+	cfg := &ComposeConfig{Connections: map[string]ConnectionConfig{
+		"b": {Transport: "https", TLS: true, CertFile: "b.pem", KeyFile: "b.key"},
+		"a": {Transport: "http"},
+		"c": {Transport: "https", TLS: true, CertFile: "c.pem", KeyFile: "c.key"},
+	}}
+	if got := cfg.ProxyListenerTLS(); !got.Enabled || got.CertFile != "b.pem" || got.KeyFile != "b.key" {
+		t.Errorf("Expected the first TLS connection's certificate, got %+v", got)
+	}
+
+	cfg.ProxyTLS = TLSConfig{Enabled: true, ACME: &ACMEConfig{Domains: []string{"mcp.example.com"}}}
+	if got := cfg.ProxyListenerTLS(); got.ACME == nil || got.CertFile != "" {
+		t.Errorf("Expected proxy_tls to take precedence, got %+v", got)
+	}
+
+	if got := (&ComposeConfig{}).ProxyListenerTLS(); got.Enabled {
+		t.Errorf("Expected TLS to be off without configuration, got %+v", got)
+	}
+}
+
+func TestServerNetworks(t *testing.T) {
+	servers := map[string]ServerConfig{
+		"web":   {Image: "web", Peers: []string{"db"}},
+		"db":    {Image: "postgres", Networks: []string{"storage"}},
+		"host":  {Image: "tool", NetworkMode: "host"},
+		"local": {Command: "node"},
+	}
+	tests := []struct {
+		name      string
+		isolation string
+		server    string
+		expected  []string
+	}{
+		{name: "shared default", server: "web", expected: []string{"mcp-net"}},
+		{name: "shared with own networks", isolation: IsolationShared, server: "db", expected: []string{"storage", "mcp-net"}},
+		{name: "strict", isolation: IsolationStrict, server: "db", expected: []string{"mcp-net-db", "storage"}},
+		{name: "strict with peers", isolation: IsolationStrict, server: "web", expected: []string{"mcp-net-web", "mcp-net-db"}},
+		{name: "network mode", isolation: IsolationStrict, server: "host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ComposeConfig{Isolation: tt.isolation, Servers: servers}
+			networks := cfg.ServerNetworks(tt.server)
+			if strings.Join(networks, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("ServerNetworks(%q) = %v, want %v", tt.server, networks, tt.expected)
+			}
+		})
+	}
+
+	cfg := &ComposeConfig{Isolation: IsolationStrict, Servers: servers}
+	if networks := strings.Join(cfg.ProxyNetworks(), ","); networks != "mcp-net,mcp-net-db,mcp-net-web" {
+		t.Errorf("ProxyNetworks() = %s, want the network of every container server", networks)
+	}
+	cfg.Isolation = ""
+	if networks := strings.Join(cfg.ProxyNetworks(), ","); networks != "mcp-net" {
+		t.Errorf("ProxyNetworks() = %s, want only mcp-net with shared isolation", networks)
+	}
+}
+
+func TestValidateIsolation(t *testing.T) {
+	tests := []struct {
+		name      string
+		isolation string
+		peers     []string
+		wantErr   string
+	}{
+		{name: "strict with peers", isolation: IsolationStrict, peers: []string{"db"}},
+		{name: "unknown mode", isolation: "total", wantErr: "invalid isolation"},
+		{name: "undefined peer", isolation: IsolationStrict, peers: []string{"cache"}, wantErr: "undefined peer 'cache'"},
+		{name: "self as peer", isolation: IsolationStrict, peers: []string{"web"}, wantErr: "undefined peer 'web'"},
+		{name: "peers without strict", peers: []string{"db"}, wantErr: "isolation is not 'strict'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ComposeConfig{
+				Version:   "1",
+				Isolation: tt.isolation,
+				Servers: map[string]ServerConfig{
+					"web": {Image: "web", Peers: tt.peers},
+					"db":  {Image: "postgres"},
+				},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateUsernsMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{mode: ""},
+		{mode: "host"},
+		{mode: "keep-id"},
+		{mode: "keep-id:uid=1000,gid=1000"},
+		{mode: "auto:size=65536"},
+		{mode: "ns:/proc/42/ns/user"},
+		{mode: "host:uid=0", wantErr: true},
+		{mode: "ns:", wantErr: true},
+		{mode: "keepid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := &ComposeConfig{
+				Version: "1",
+				Servers: map[string]ServerConfig{
+					"files": {Image: "mcp/filesystem", UsernsMode: tt.mode},
+				},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid userns_mode") {
+					t.Errorf("Expected invalid userns_mode error, got %v", err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRequestLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  RequestLimitsConfig
+		wantErr string
+	}{
+		{name: "sizes and timeout", limits: RequestLimitsConfig{MaxRequestBytes: "1m", MaxResponseBytes: "10485760", RequestTimeout: "30s"}},
+		{name: "bad request size", limits: RequestLimitsConfig{MaxRequestBytes: "1mb"}, wantErr: "limits.max_request_bytes"},
+		{name: "zero response size", limits: RequestLimitsConfig{MaxResponseBytes: "0"}, wantErr: "limits.max_response_bytes"},
+		{name: "bad timeout", limits: RequestLimitsConfig{RequestTimeout: "30"}, wantErr: "limits.request_timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := tt.limits
+			cfg := &ComposeConfig{
+				Version: "1",
+				Servers: map[string]ServerConfig{
+					"files": {Image: "mcp/filesystem", Limits: &limits},
+				},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected %s error, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			maxRequest, maxResponse, timeout := limits.Values()
+			if maxRequest != 1<<20 || maxResponse != 10485760 || timeout != 30*time.Second {
+				t.Errorf("Values() = %d, %d, %s", maxRequest, maxResponse, timeout)
+			}
+		})
+	}
+}
+
+func TestValidateResponseCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		cache   ResponseCacheConfig
+		wantErr string
+	}{
+		{name: "ttl only", cache: ResponseCacheConfig{TTL: "30s"}},
+		{name: "methods and size", cache: ResponseCacheConfig{TTL: "5m", Methods: []string{"prompts/get"}, MaxEntries: 100}},
+		{name: "missing ttl", cache: ResponseCacheConfig{}, wantErr: "response_cache ttl"},
+		{name: "negative max entries", cache: ResponseCacheConfig{TTL: "1m", MaxEntries: -1}, wantErr: "max_entries"},
+		{name: "method with side effects", cache: ResponseCacheConfig{TTL: "1m", Methods: []string{"tools/call"}}, wantErr: "cannot cache method 'tools/call'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := tt.cache
+			cfg := &ComposeConfig{
+				Version: "1",
+				Servers: map[string]ServerConfig{
+					"files": {Image: "mcp/filesystem", ResponseCache: &cache},
+				},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %s error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidatePricing(t *testing.T) {
+	var server ServerConfig
+	err := yaml.Unmarshal([]byte(`
+pricing:
+  per_call: 0.001
+  per_1k_output_tokens: 0.002
+  tools:
+    generate: {per_call: 0.05}
+`), &server)
+	if err != nil {
+		t.Fatalf("Failed to parse pricing: %v", err)
+	}
+	cfg := &ComposeConfig{Version: "1", Servers: map[string]ServerConfig{"gateway": {Image: "gateway", Pricing: server.Pricing}}}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cost := server.Pricing.PriceOf("search").Cost(500, 1500); math.Abs(cost-0.004) > 1e-9 {
+		t.Errorf("Cost of search = %v, want 0.004", cost)
+	}
+	if cost := server.Pricing.PriceOf("generate").Cost(500, 1500); cost != 0.05 {
+		t.Errorf("Cost of generate = %v, want the tool's own price 0.05", cost)
+	}
+
+	server.Pricing.Tools["generate"] = ToolPricing{PerThousandInTokens: -1}
+	if err := ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "pricing of tool 'generate'") {
+		t.Errorf("Expected a negative price error, got %v", err)
+	}
+}
+
+func TestQuotasConfig(t *testing.T) {
+	var cfg ComposeConfig
+	err := yaml.Unmarshal([]byte(`
+version: "1"
+servers:
+  gateway: {image: gateway}
+quotas:
+  default: {calls: 1000}
+  clients:
+    "oauth:ci": {cost: 25}
+`), &cfg)
+	if err != nil {
+		t.Fatalf("Failed to parse quotas: %v", err)
+	}
+	if err := ValidateConfig(&cfg); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if limits, ok := cfg.Quotas.For("oauth:ci"); !ok || limits.Cost != 25 || limits.Calls != 0 {
+		t.Errorf("A client's own quota should replace the default, got %+v", limits)
+	}
+	if limits, ok := cfg.Quotas.For("api_key"); !ok || limits.Calls != 1000 {
+		t.Errorf("Other clients should get the default quota, got %+v", limits)
+	}
+	if _, ok := (QuotasConfig{}).For("api_key"); ok {
+		t.Error("Without quotas no client should have one")
+	}
+
+	cfg.Quotas.Clients["oauth:ci"] = QuotaLimits{Calls: -1}
+	if err := ValidateConfig(&cfg); err == nil || !strings.Contains(err.Error(), "quota of client 'oauth:ci'") {
+		t.Errorf("Expected a negative quota error, got %v", err)
+	}
+}
+
+func TestValidateDashboardTheme(t *testing.T) {
+	cfg := &ComposeConfig{Version: "1", Servers: map[string]ServerConfig{"app": {Image: "app"}}}
+	cfg.Dashboard.Theme = DashboardThemeLight
+	cfg.Dashboard.Branding = &DashboardBranding{Title: "Acme MCP", Logo: "branding/logo.svg", AccentColor: "#ff6600"}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg.Dashboard.Theme = "solarized"
+	cfg.Dashboard.Branding.AccentColor = "orange"
+	err := ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "invalid theme 'solarized'") || !strings.Contains(err.Error(), "invalid accent_color 'orange'") {
+		t.Errorf("Expected theme and accent color errors, got %v", err)
+	}
+}
+
+func TestPlatformDefaults(t *testing.T) {
+	configYAML := `version: "1"
+platform: linux/amd64
+servers:
+  search:
+    image: mcp/search
+  files:
+    image: mcp/filesystem
+    platform: linux/arm/v7
+  builder:
+    build:
+      context: ./builder
+environments:
+  pi:
+    servers:
+      search:
+        platform: linux/arm64
+`
+	path := t.TempDir() + "/mcp-compose.yaml"
+	if err := os.WriteFile(path, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	t.Setenv("MCP_ENV", "pi")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if platform := cfg.Servers["search"].Platform; platform != "linux/arm64" {
+		t.Errorf("the pi environment should run search on linux/arm64, got %q", platform)
+	}
+	if platform := cfg.Servers["files"].Platform; platform != "linux/arm/v7" {
+		t.Errorf("a server's own platform should win over the default, got %q", platform)
+	}
+	if builder := cfg.Servers["builder"]; builder.Platform != "linux/amd64" || builder.Build.Platform != "linux/amd64" {
+		t.Errorf("the default platform should apply to builds, got %q and %q", builder.Platform, builder.Build.Platform)
+	}
+
+	invalid := &ComposeConfig{Version: "1", Platform: "arm64", Servers: map[string]ServerConfig{"app": {Image: "app", Platform: "Linux/AMD64"}}}
+	err = ValidateConfig(invalid)
+	if err == nil || !strings.Contains(err.Error(), "invalid platform 'arm64'") || !strings.Contains(err.Error(), "invalid platform 'Linux/AMD64'") {
+		t.Errorf("Expected platform errors, got %v", err)
+	}
+}
+
+func TestGPURequest(t *testing.T) {
+	gpu := []string{"gpu"}
+	tests := []struct {
+		name     string
+		server   ServerConfig
+		expected string
+		wantErr  string
+	}{
+		{name: "none", server: ServerConfig{Image: "ollama"}},
+		{name: "gpus", server: ServerConfig{Image: "ollama", GPUs: "all"}, expected: "all"},
+		{name: "gpus by device", server: ServerConfig{Image: "ollama", GPUs: "device=0,1"}, expected: "device=0,1"},
+		{name: "reserved, count defaults to all", server: gpuServer(DeviceRequest{Driver: "nvidia", Capabilities: gpu}), expected: "all"},
+		{name: "reserved count", server: gpuServer(DeviceRequest{Count: "2", Capabilities: gpu}), expected: "2"},
+		{name: "reserved devices", server: gpuServer(DeviceRequest{DeviceIDs: []string{"0", "GPU-3f2a"}, Capabilities: gpu}), expected: "device=0,GPU-3f2a"},
+		{name: "invalid gpus", server: ServerConfig{Image: "ollama", GPUs: "some"}, wantErr: "invalid gpus 'some'"},
+		{name: "not a gpu", server: gpuServer(DeviceRequest{Capabilities: []string{"compute"}}), wantErr: "without the gpu capability"},
+		{name: "other driver", server: gpuServer(DeviceRequest{Driver: "amd", Capabilities: gpu}), wantErr: "unsupported driver 'amd'"},
+		{name: "count and ids", server: gpuServer(DeviceRequest{Count: "1", DeviceIDs: []string{"0"}, Capabilities: gpu}), wantErr: "both count and device_ids"},
+		{name: "invalid count", server: gpuServer(DeviceRequest{Count: "device=0", Capabilities: gpu}), wantErr: "invalid count of devices"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfig(&ComposeConfig{Version: "1", Servers: map[string]ServerConfig{"ollama": tt.server}})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if gpus := tt.server.GPURequest(); gpus != tt.expected {
+				t.Errorf("GPURequest() = %q, want %q", gpus, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateInitJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  ServerConfig
+		wantErr string
+	}{
+		{name: "container jobs", server: ServerConfig{Image: "mcp/memory", Init: []InitJob{{Name: "migrate", Command: "migrate"}, {Name: "seed", Image: "registry.local/seed", Timeout: "10m"}}}},
+		{name: "host job", server: ServerConfig{Command: "memory-server", Init: []InitJob{{Name: "migrate", Command: "./migrate.sh"}}}},
+		{name: "unnamed", server: ServerConfig{Image: "mcp/memory", Init: []InitJob{{Command: "migrate"}}}, wantErr: "init job 1 of server 'memory' has no name"},
+		{name: "duplicate", server: ServerConfig{Image: "mcp/memory", Init: []InitJob{{Name: "migrate"}, {Name: "migrate"}}}, wantErr: "more than one init job named 'migrate'"},
+		{name: "bad name", server: ServerConfig{Image: "mcp/memory", Init: []InitJob{{Name: "db migrate"}}}, wantErr: "invalid name"},
+		{name: "nothing to run", server: ServerConfig{Command: "memory-server", Init: []InitJob{{Name: "migrate"}}}, wantErr: "needs a command to run on the host"},
+		{name: "built image", server: ServerConfig{Build: BuildConfig{Context: "./memory"}, Init: []InitJob{{Name: "migrate", Command: "migrate"}}}, wantErr: "needs an image, as the server's image is built"},
+		{name: "bad timeout", server: ServerConfig{Image: "mcp/memory", Init: []InitJob{{Name: "migrate", Timeout: "soon"}}}, wantErr: "invalid timeout 'soon'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfig(&ComposeConfig{Version: "1", Servers: map[string]ServerConfig{"memory": tt.server}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExternalDependsOn(t *testing.T) {
+	var server ServerConfig
+	err := yaml.Unmarshal([]byte(`
+image: mcp/memory
+external_depends_on:
+  - vault
+  - url: tcp://db:5432
+    timeout: 90s
+  - url: postgres://memory:secret@db:5432/memory
+  - http: http://search:9200/_cluster/health
+`), &server)
+	if err != nil {
+		t.Fatalf("Failed to parse external_depends_on: %v", err)
+	}
+	deps := server.ExternalDependsOn
+	if len(deps) != 4 || deps[0].Name != "vault" || deps[1].URL != "tcp://db:5432" || deps[1].Timeout != "90s" {
+		t.Fatalf("Unexpected dependencies: %+v", deps)
+	}
+	if tcp := deps[1].Check().TCP; tcp != "db:5432" {
+		t.Errorf("Check().TCP = %q, want db:5432", tcp)
+	}
+	if label := deps[2].Label(); strings.Contains(label, "secret") {
+		t.Errorf("Label() = %q reveals the password", label)
+	}
+
+	cfg := &ComposeConfig{
+		Version:              "1",
+		Servers:              map[string]ServerConfig{"memory": server},
+		ExternalDependencies: map[string]ExternalDependency{"vault": {HTTP: "http://vault:8200/v1/sys/health"}},
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	server.ExternalDependsOn = append(server.ExternalDependsOn, ExternalDependencyRef{ExternalDependency: ExternalDependency{URL: "redis://cache:6379"}})
+	cfg.Servers["memory"] = server
+	if err := ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "external dependency 5 of server 'memory' url must start with") {
+		t.Errorf("Expected an unsupported url error, got %v", err)
+	}
+
+	data, err := yaml.Marshal(server)
+	if err != nil {
+		t.Fatalf("Failed to encode server: %v", err)
+	}
+	if !strings.Contains(string(data), "- vault\n") {
+		t.Errorf("Expected the named dependency to be written as its name, got:\n%s", data)
+	}
+}
+
+func gpuServer(device DeviceRequest) ServerConfig {
+	server := ServerConfig{Image: "ollama"}
+	server.Deploy.Resources.Reservations.Devices = []DeviceRequest{device}
+
+	return server
+}
+
+func TestValidatePrompts(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompts []PromptConfig
+		wantErr string
+	}{
+		{name: "valid", prompts: []PromptConfig{{Name: "review", Template: "Review {{ file }}", Variables: []PromptVariable{{Name: "file", Type: "string", Required: true}}}}},
+		{name: "duplicate name", prompts: []PromptConfig{{Name: "a", Template: "x"}, {Name: "a", Template: "y"}}, wantErr: "duplicate prompt name"},
+		{name: "empty template", prompts: []PromptConfig{{Name: "a"}}, wantErr: "empty template"},
+		{name: "undeclared variable", prompts: []PromptConfig{{Name: "a", Template: "{{file}} {{line}}", Variables: []PromptVariable{{Name: "file"}}}}, wantErr: "undeclared variable 'line'"},
+		{name: "bad type", prompts: []PromptConfig{{Name: "a", Template: "{{n}}", Variables: []PromptVariable{{Name: "n", Type: "float"}}}}, wantErr: "invalid type 'float'"},
+		{name: "duplicate variable", prompts: []PromptConfig{{Name: "a", Template: "{{n}}", Variables: []PromptVariable{{Name: "n"}, {Name: "n"}}}}, wantErr: "duplicate variable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ComposeConfig{
+				Version: "1",
+				Servers: map[string]ServerConfig{
+					"files": {Image: "mcp/filesystem", Prompts: tt.prompts},
+				},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %s error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateSamplingBroker(t *testing.T) {
+	tests := []struct {
+		name    string
+		broker  SamplingBrokerConfig
+		wantErr string
+	}{
+		{name: "openai", broker: SamplingBrokerConfig{Enabled: true, Provider: "openai", APIKey: "sk-test", Model: "gpt-4o-mini"}},
+		{name: "ollama without a key", broker: SamplingBrokerConfig{Enabled: true, Provider: "ollama", Model: "llama3", Timeout: "2m"}},
+		{name: "disabled", broker: SamplingBrokerConfig{Provider: "bedrock"}},
+		{name: "unknown provider", broker: SamplingBrokerConfig{Enabled: true, Provider: "bedrock", Model: "x"}, wantErr: "unknown sampling provider 'bedrock'"},
+		{name: "missing key", broker: SamplingBrokerConfig{Enabled: true, Provider: "openrouter", Model: "x"}, wantErr: "needs an api_key"},
+		{name: "missing model", broker: SamplingBrokerConfig{Enabled: true, Provider: "ollama"}, wantErr: "needs a default model"},
+		{name: "bad base url", broker: SamplingBrokerConfig{Enabled: true, Provider: "ollama", Model: "x", BaseURL: "localhost:11434"}, wantErr: "must be an http or https URL"},
+		{name: "bad timeout", broker: SamplingBrokerConfig{Enabled: true, Provider: "ollama", Model: "x", Timeout: "soon"}, wantErr: "invalid sampling timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ComposeConfig{
+				Version:  "1",
+				Servers:  map[string]ServerConfig{"files": {Image: "mcp/filesystem"}},
+				Sampling: tt.broker,
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %s error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRootsConfig(t *testing.T) {
+	roots := RootsConfig{
+		Default: []RootConfig{{URI: "file:///srv/shared", Name: "shared"}},
+		Profiles: map[string][]RootConfig{
+			"dev": {{URI: "file:///home/me/dev"}, {URI: "file:///srv/shared"}},
+			"ops": {{URI: "file:///etc/ops"}},
+		},
+		Clients: map[string][]RootConfig{"cursor": {{URI: "file:///home/me/cursor"}}},
+	}
+
+	uris := func(list []RootConfig) string {
+		var joined []string
+		for _, root := range list {
+			joined = append(joined, root.URI)
+		}
+
+		return strings.Join(joined, " ")
+	}
+	if got := uris(roots.For("", nil)); got != "file:///srv/shared" {
+		t.Errorf("default roots = %s", got)
+	}
+	if got := uris(roots.For("cursor", []string{"dev"})); got != "file:///srv/shared file:///home/me/dev file:///home/me/cursor" {
+		t.Errorf("roots for cursor with dev = %s", got)
+	}
+	if got := uris(roots.For("other", []string{"*"})); got != "file:///srv/shared file:///home/me/dev file:///etc/ops" {
+		t.Errorf("roots with every profile = %s", got)
+	}
+
+	cfg := &ComposeConfig{
+		Version: "1",
+		Servers: map[string]ServerConfig{"files": {Image: "mcp/filesystem"}},
+		Roots:   roots,
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	cfg.Roots.Clients["claude"] = []RootConfig{{URI: "/home/me/claude"}}
+	cfg.Roots.Default = append(cfg.Roots.Default, RootConfig{URI: "https://example.com/repo"})
+	err := ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "'/home/me/claude' must be a file:// URI") || !strings.Contains(err.Error(), "'https://example.com/repo' must be a file:// URI") {
+		t.Errorf("Expected errors for both invalid roots, got %v", err)
+	}
+}
+
+func TestEgressAllows(t *testing.T) {
+	egress := &EgressConfig{
+		Allow: []string{"api.github.com:443", "*.googleapis.com", "10.1.0.0/16:5432"},
+		Deny:  []string{"evil.googleapis.com"},
+	}
+	tests := []struct {
+		host     string
+		port     int
+		expected bool
+	}{
+		{host: "api.github.com", port: 443, expected: true},
+		{host: "API.GitHub.com.", port: 443, expected: true},
+		{host: "api.github.com", port: 80},
+		{host: "github.com", port: 443},
+		{host: "storage.googleapis.com", port: 443, expected: true},
+		{host: "googleapis.com", port: 443},
+		{host: "evil.googleapis.com", port: 443},
+		{host: "10.1.2.3", port: 5432, expected: true},
+		{host: "10.2.0.1", port: 5432},
+		{host: "140.82.112.5", port: 443},
+	}
+
+	for _, tt := range tests {
+		if got := egress.Allows(tt.host, tt.port); got != tt.expected {
+			t.Errorf("Allows(%s, %d) = %v, want %v", tt.host, tt.port, got, tt.expected)
+		}
+	}
+
+	if !(&EgressConfig{Default: EgressAllow}).Allows("example.com", 443) {
+		t.Error("Expected default allow to allow unmatched hosts")
+	}
+}
+
+func TestValidateEgress(t *testing.T) {
+	tests := []struct {
+		name    string
+		web     ServerConfig
+		wantErr string
+	}{
+		{name: "valid", web: ServerConfig{Image: "web", Security: SecurityConfig{Egress: &EgressConfig{Allow: []string{"api.github.com:443"}}}}},
+		{name: "bad default", web: ServerConfig{Image: "web", Security: SecurityConfig{Egress: &EgressConfig{Default: "block"}}}, wantErr: "invalid egress default"},
+		{name: "bad port", web: ServerConfig{Image: "web", Security: SecurityConfig{Egress: &EgressConfig{Allow: []string{"api.github.com:https"}}}}, wantErr: "invalid port"},
+		{name: "bad cidr", web: ServerConfig{Image: "web", Security: SecurityConfig{Egress: &EgressConfig{Deny: []string{"10.0.0.0/33"}}}}, wantErr: "invalid CIDR"},
+		{name: "network mode", web: ServerConfig{Image: "web", NetworkMode: "host", Security: SecurityConfig{Egress: &EgressConfig{}}}, wantErr: "network_mode"},
+		{name: "extra networks", web: ServerConfig{Image: "web", Networks: []string{"public"}, Security: SecurityConfig{Egress: &EgressConfig{}}}, wantErr: "joining networks"},
+		{name: "unrestricted peer", web: ServerConfig{Image: "web", Peers: []string{"db"}, Security: SecurityConfig{Egress: &EgressConfig{}}}, wantErr: "peer 'db' must restrict egress"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ComposeConfig{Version: "1", Servers: map[string]ServerConfig{"web": tt.web, "db": {Image: "postgres"}}}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestEgressNetworks(t *testing.T) {
+	cfg := &ComposeConfig{Servers: map[string]ServerConfig{
+		"web": {Image: "web", Security: SecurityConfig{Egress: &EgressConfig{}}},
+		"db":  {Image: "postgres"},
+	}}
+	if networks := strings.Join(cfg.ServerNetworks("web"), ","); networks != "mcp-net-web" {
+		t.Errorf("ServerNetworks(web) = %s, want only its own network", networks)
+	}
+	if networks := strings.Join(cfg.ServerNetworks("db"), ","); networks != "mcp-net" {
+		t.Errorf("ServerNetworks(db) = %s, want mcp-net", networks)
+	}
+	if networks := strings.Join(cfg.ProxyNetworks(), ","); networks != "mcp-net,mcp-net-web" {
+		t.Errorf("ProxyNetworks() = %s, want mcp-net and the egress server's network", networks)
+	}
+	if !cfg.InternalNetwork("mcp-net-web") || cfg.InternalNetwork("mcp-net") {
+		t.Error("Expected only the egress server's network to be internal")
+	}
+	if env := cfg.EgressEnv("web"); env["HTTPS_PROXY"] != "http://mcp-compose-http-proxy:3128" {
+		t.Errorf("EgressEnv(web) HTTPS_PROXY = %q", env["HTTPS_PROXY"])
+	}
+	if env := cfg.EgressEnv("db"); env != nil {
+		t.Errorf("EgressEnv(db) = %v, want nil", env)
+	}
+}
+
+func TestLoadConfigReportsAllProblems(t *testing.T) {
+	configYAML := `version: "1"
+servers:
+  web:
+    image: nginx
+    protocol: htp
+    http_port: abc
+  worker:
+    command: run
+    depends_on: [db]
+`
+	path := t.TempDir() + "/mcp-compose.yaml"
+	if err := os.WriteFile(path, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(path)
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+
+	expected := []struct {
+		line, column int
+		contains     string
+	}{
+		{5, 5, "invalid protocol"},
+		{6, 16, "cannot unmarshal"},
+		{9, 18, "undefined server 'db'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		got := problems[i]
+		if got.Line != want.line || got.Column != want.column || !strings.Contains(got.Message, want.contains) {
+			t.Errorf("Problem %d: expected %d:%d %q, got %d:%d %q", i, want.line, want.column, want.contains, got.Line, got.Column, got.Message)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	var schema struct {
+		Properties  map[string]interface{} `json:"properties"`
+		Definitions map[string]struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+	for _, key := range []string{"version", "servers", "proxy_auth"} {
+		if _, exists := schema.Properties[key]; !exists {
+			t.Errorf("Expected top-level property %q", key)
+		}
+	}
+	protocol := schema.Definitions["ServerConfig"].Properties["protocol"]
+	if _, exists := protocol["enum"]; !exists {
+		t.Errorf("Expected servers.*.protocol to list its allowed values, got %v", protocol)
+	}
+}
+
+func TestServerEnabledFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []string
+		active   []string
+		expected bool
+	}{
+		{"no profiles always runs", nil, nil, true},
+		{"profile not active", []string{"debug"}, nil, false},
+		{"profile active", []string{"debug", "test"}, []string{"test"}, true},
+		{"other profile active", []string{"debug"}, []string{"test"}, false},
+		{"wildcard", []string{"debug"}, []string{"*"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := ServerConfig{Profiles: tt.profiles}
+			if got := server.EnabledFor(tt.active); got != tt.expected {
+				t.Errorf("EnabledFor(%v) = %v, expected %v", tt.active, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMergesOverrideFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := `version: "1"
+servers:
+  web:
+    image: web
+    args: ["--port", "80"]
+    env:
+      LOG_LEVEL: info
+      REGION: eu
+    volumes:
+      - shared:/data
+      - cache:/cache
+  legacy:
+    image: legacy
+`
+	override := `servers:
+  web:
+    args: ["--port", "8080"]
+    env:
+      LOG_LEVEL: debug
+    volumes:
+      - ./data:/data
+  legacy: !reset
+`
+	basePath, overridePath := dir+"/mcp-compose.yaml", dir+"/override.yaml"
+	for path, content := range map[string]string{basePath: base, overridePath: override} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	SetOverrideFiles([]string{overridePath})
+	defer SetOverrideFiles(nil)
+	cfg, err := LoadConfig(basePath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if _, exists := cfg.Servers["legacy"]; exists {
+		t.Error("Expected !reset to remove server 'legacy'")
+	}
+	web := cfg.Servers["web"]
+	if strings.Join(web.Args, " ") != "--port 8080" {
+		t.Errorf("Expected args to be replaced, got %v", web.Args)
+	}
+	if web.Env["LOG_LEVEL"] != "debug" || web.Env["REGION"] != "eu" {
+		t.Errorf("Expected env to be merged by key, got %v", web.Env)
+	}
+	if strings.Join(web.Volumes, ",") != "./data:/data,cache:/cache" {
+		t.Errorf("Expected volumes to be merged by container path, got %v", web.Volumes)
+	}
+}
+
+func TestEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"mcp-compose.yaml": `version: "1"
+env_files: [shared.env]
+servers:
+  web:
+    image: web:${WEB_TAG}
+    env_file: [web.env, web.local.env]
+    env:
+      MODE: explicit
+  api:
+    image: api:${API_TAG}
+    env_file: api.env
+`,
+		".env":          "WEB_TAG=dotenv\nAPI_TAG=dotenv\nREGION=eu\n",
+		"shared.env":    "WEB_TAG=shared\nAPI_TAG=shared\n",
+		"cli.env":       "API_TAG=cli\n",
+		"web.env":       "MODE=file\nTOKEN=first\nURL=https://${REGION}.example.com\n",
+		"web.local.env": "# overrides\nTOKEN=second\n",
+		"api.env":       "DEBUG=1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(dir+"/"+name, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	// Registered so the variables loadDotEnv sets are removed afterwards
+	for _, name := range []string{"WEB_TAG", "API_TAG", "REGION"} {
+		t.Setenv(name, "")
+	}
+
+	SetEnvFiles([]string{dir + "/cli.env"})
+	defer SetEnvFiles(nil)
+	cfg, err := LoadConfig(dir + "/mcp-compose.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if image := cfg.Servers["web"].Image; image != "web:shared" {
+		t.Errorf("Expected env_files to win over .env, got %s", image)
+	}
+	if image := cfg.Servers["api"].Image; image != "api:cli" {
+		t.Errorf("Expected --env-file to win over env_files, got %s", image)
+	}
+	web := cfg.Servers["web"].Env
+	if web["MODE"] != "explicit" || web["TOKEN"] != "second" || web["URL"] != "https://eu.example.com" {
+		t.Errorf("Expected env over the later env_file over the earlier one, got %v", web)
+	}
+	if cfg.Servers["api"].Env["DEBUG"] != "1" {
+		t.Errorf("Expected a single env_file to be loaded, got %v", cfg.Servers["api"].Env)
+	}
+	if got := strings.Join(cfg.EnvFilePaths(), ","); got != "api.env,shared.env,web.env,web.local.env" {
+		t.Errorf("Unexpected env file paths %s", got)
+	}
+
+	if err := os.Remove(dir + "/web.local.env"); err != nil {
+		t.Fatal(err)
+	}
+	var problems ValidationErrors
+	if _, err := LoadConfig(dir + "/mcp-compose.yaml"); !errors.As(err, &problems) || problems[0].Line != 6 {
+		t.Errorf("Expected the missing env_file to be reported on its line, got %v", err)
+	}
+}
+
+func TestEncryptedValues(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate identity: %v", err)
+	}
+	t.Setenv(AgeKeyEnv, identity.String())
+	recipients := []age.Recipient{identity.Recipient()}
+
+	token, err := EncryptValue("ghp_encrypted_token", recipients)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	var file bytes.Buffer
+	armorWriter := armor.NewWriter(&file)
+	writer, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	_, _ = io.WriteString(writer, "postgres://app:hunter22@db/app")
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if err := armorWriter.Close(); err != nil {
+		t.Fatalf("Failed to armor: %v", err)
+	}
+	armored := "    " + strings.ReplaceAll(strings.TrimSpace(file.String()), "\n", "\n        ")
+	content := `version: "1"
+servers:
+  github:
+    image: github
+    env:
+      GITHUB_TOKEN: !encrypted ` + token + `
+      DATABASE_URL: !encrypted |
+    ` + armored + `
+`
+	path := t.TempDir() + "/mcp-compose.yaml"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if !HasEncryptedValues(path) {
+		t.Error("Expected the file to be reported as having encrypted values")
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	env := cfg.Servers["github"].Env
+	if env["GITHUB_TOKEN"] != "ghp_encrypted_token" || env["DATABASE_URL"] != "postgres://app:hunter22@db/app" {
+		t.Errorf("Expected decrypted values, got %v", env)
+	}
+
+	redacted, err := Redact(map[string]string{"note": "ghp_encrypted_token"})
+	if err != nil {
+		t.Fatalf("Failed to redact: %v", err)
+	}
+	if redacted.(map[string]interface{})["note"] != RedactedValue {
+		t.Errorf("Expected a decrypted value to be redacted under any name, got %v", redacted)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	t.Setenv(AgeKeyEnv, other.String())
+	var problems ValidationErrors
+	if _, err := LoadConfig(path); !errors.As(err, &problems) || len(problems) != 2 || problems[0].Line != 6 {
+		t.Errorf("Expected both values to fail to decrypt with another key, got %v", err)
+	}
+}
+
+func TestFetchRemoteFile(t *testing.T) {
+	content := []byte("version: \"1\"\nservers: {}\n")
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, content))
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+
+			return
+		}
+		switch r.URL.Path {
+		case "/mcp-compose.yaml":
+			_, _ = w.Write(content)
+		case "/mcp-compose.yaml.sig":
+			_, _ = w.Write([]byte(signature))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	sum := sha256.Sum256(content)
+	otherKey, _, _ := ed25519.GenerateKey(nil)
+	tests := []struct {
+		name      string
+		source    string
+		publicKey string
+		expectErr bool
+	}{
+		{"plain", server.URL + "/mcp-compose.yaml", "", false},
+		{"matching checksum", server.URL + "/mcp-compose.yaml#sha256=" + hex.EncodeToString(sum[:]), "", false},
+		{"checksum mismatch", server.URL + "/mcp-compose.yaml#sha256=" + strings.Repeat("0", 64), "", true},
+		{"valid signature", server.URL + "/mcp-compose.yaml", base64.StdEncoding.EncodeToString(publicKey), false},
+		{"signed with another key", server.URL + "/mcp-compose.yaml", base64.StdEncoding.EncodeToString(otherKey), true},
+		{"not found", server.URL + "/missing.yaml", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localPath, err := FetchRemoteFile(tt.source, tt.publicKey)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("FetchRemoteFile() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+			data, err := os.ReadFile(localPath)
+			if err != nil || string(data) != string(content) {
+				t.Errorf("Expected cached copy to match, got %q (%v)", data, err)
+			}
+			if source, _ := RemoteSource(localPath); source != strings.Split(tt.source, "#")[0] {
+				t.Errorf("Expected source %s, got %s", tt.source, source)
+			}
+		})
+	}
+
+	// Without the source, a cached copy is only used once it verifies under the key
+	source, key := server.URL+"/mcp-compose.yaml", base64.StdEncoding.EncodeToString(publicKey)
+	if _, err := FetchRemoteFile(source, ""); err != nil {
+		t.Fatalf("Failed to fetch without a key: %v", err)
+	}
+	down.Store(true)
+	if _, err := FetchRemoteFile(source, key); err == nil {
+		t.Error("Expected a copy cached without a key not to be used with one")
+	}
+	down.Store(false)
+	if _, err := FetchRemoteFile(source, key); err != nil {
+		t.Fatalf("Failed to fetch with a key: %v", err)
+	}
+	down.Store(true)
+	if _, err := FetchRemoteFile(source, key); err != nil {
+		t.Errorf("Expected the verified copy to be used, got %v", err)
+	}
+	if _, err := FetchRemoteFile(source, base64.StdEncoding.EncodeToString(otherKey)); err == nil {
+		t.Error("Expected the copy not to be used under another key")
+	}
+
+	if _, err := parseRemoteRef("git+https://github.com/org/stack.git//mcp-compose.yaml?ref=--upload-pack=touch /tmp/x"); err == nil {
+		t.Error("Expected a ref that looks like an option to be refused")
+	}
+	for _, source := range []string{"git+https://github.com/org/stack.git//../../etc/x.yaml", "git@github.com:org/stack.git///etc/x.yaml"} {
+		if _, err := parseRemoteRef(source); err == nil {
+			t.Errorf("Expected a file path outside the checkout to be refused for %s", source)
+		}
+	}
+	if ref, err := parseRemoteRef("git+https://github.com/org/stack.git//stacks/../mcp-compose.yaml"); err != nil || ref.file != "stacks/../mcp-compose.yaml" {
+		t.Errorf("Expected a file path inside the checkout to be accepted, got %+v (%v)", ref, err)
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	old := &ComposeConfig{Version: "1", Servers: map[string]ServerConfig{
+		"kept":    {Command: "kept"},
+		"edited":  {Command: "edited"},
+		"dropped": {Command: "dropped"},
+	}}
+	updated := &ComposeConfig{Version: "1", Servers: map[string]ServerConfig{
+		"kept":   {Command: "kept"},
+		"edited": {Command: "edited", Args: []string{"--verbose"}},
+		"new":    {Command: "new"},
+	}}
+
+	changes := DiffConfigs(old, updated)
+	if strings.Join(changes.Added, ",") != "new" || strings.Join(changes.Removed, ",") != "dropped" ||
+		strings.Join(changes.Changed, ",") != "edited" || changes.Global {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+
+	updated.Logging.Level = "debug"
+	if !DiffConfigs(old, updated).Global {
+		t.Error("Expected a logging change to be global")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	updated := "a\nb\nc\nd\nE\nf\ng\nh\ni\nj\nk\n"
+
+	expected := `--- old
++++ new
+@@ -2,9 +2,10 @@
+ b
+ c
+ d
+-e
++E
+ f
+ g
+ h
+ i
+ j
++k
+`
+	if got := UnifiedDiff("old", "new", old, updated); got != expected {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, expected)
+	}
+	if got := UnifiedDiff("old", "new", old, old); got != "" {
+		t.Errorf("Expected no diff for identical text, got %q", got)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	server := ServerConfig{
+		Command: "server",
+		Args:    []string{"--api-key", "abc", "--token=xyz", "--port", "80"},
+		Env: map[string]string{
+			"GITHUB_TOKEN": "ghp_secret",
+			"DATABASE_URL": "postgres://user:hunter2@db:5432/app",
+			"LOG_LEVEL":    "debug",
+		},
+	}
+
+	redacted, err := Redact(server)
+	if err != nil {
+		t.Fatalf("Redact() error: %v", err)
+	}
+	values := redacted.(map[string]interface{})
+	env := values["env"].(map[string]interface{})
+	if env["GITHUB_TOKEN"] != RedactedValue {
+		t.Errorf("Expected GITHUB_TOKEN to be redacted, got %v", env["GITHUB_TOKEN"])
+	}
+	if env["DATABASE_URL"] != "postgres://user:"+RedactedValue+"@db:5432/app" {
+		t.Errorf("Expected the URL password to be redacted, got %v", env["DATABASE_URL"])
+	}
+	if env["LOG_LEVEL"] != "debug" {
+		t.Errorf("Expected LOG_LEVEL to be kept, got %v", env["LOG_LEVEL"])
+	}
+	args := values["args"].([]interface{})
+	expectedArgs := []interface{}{"--api-key", RedactedValue, "--token=" + RedactedValue, "--port", "80"}
+	for i := range expectedArgs {
+		if args[i] != expectedArgs[i] {
+			t.Errorf("args[%d] = %v, want %v", i, args[i], expectedArgs[i])
+		}
+	}
+
+	tests := []struct {
+		entry    string
+		expected string
+	}{
+		{"API_KEY=abc", "API_KEY=" + RedactedValue},
+		{"PATH=/usr/bin", "PATH=/usr/bin"},
+		{"PROXY=http://user:pw@proxy:3128", "PROXY=http://user:" + RedactedValue + "@proxy:3128"},
+		{"EMPTY_TOKEN=", "EMPTY_TOKEN="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			if got := RedactEnvEntry(tt.entry); got != tt.expected {
+				t.Errorf("RedactEnvEntry(%q) = %q, want %q", tt.entry, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	server := ServerConfig{
+		Command: "server",
+		Args:    []string{"--api-key", "abc", "--token=xyz-long-token"},
+		Env: map[string]string{
+			"GITHUB_TOKEN": "ghp_secret",
+			"DATABASE_URL": "postgres://user:hunter2@db:5432/app",
+			"LOG_LEVEL":    "debug",
+		},
+	}
+	secrets, err := SecretValues(server)
+	if err != nil {
+		t.Fatalf("SecretValues() error: %v", err)
+	}
+	expectedSecrets := []string{"xyz-long-token", "ghp_secret", "hunter2", "abc"}
+	if len(secrets) != len(expectedSecrets) {
+		t.Fatalf("SecretValues() = %v, want %v", secrets, expectedSecrets)
+	}
+	for i := range expectedSecrets {
+		if secrets[i] != expectedSecrets[i] {
+			t.Errorf("SecretValues()[%d] = %q, want %q", i, secrets[i], expectedSecrets[i])
+		}
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"configured secret", "using token ghp_secret", "using token " + RedactedValue},
+		{"short secret kept", "abc is too short to replace", "abc is too short to replace"},
+		{"url password", "dial mysql://root:s3cr3t@db failed", "dial mysql://root:" + RedactedValue + "@db failed"},
+		{"bearer token", "Authorization: Bearer eyJhbGci.payload", "Authorization: Bearer " + RedactedValue},
+		{"env assignment", "OPENAI_API_KEY=sk-123 LOG_LEVEL=info", "OPENAI_API_KEY=" + RedactedValue + " LOG_LEVEL=info"},
+		{"json field", `{"client_secret": "shh", "user": "bob"}`, `{"client_secret": "` + RedactedValue + `", "user": "bob"}`},
+		{"plain text", "listening on port 8080", "listening on port 8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactText(tt.text, secrets); got != tt.expected {
+				t.Errorf("RedactText(%q) = %q, want %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateImageReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	tests := []struct {
+		name      string
+		image     string
+		expectErr bool
+	}{
+		{name: "tag", image: "mcp/filesystem:latest"},
+		{name: "registry port", image: "registry.local:5000/tools/fetch:1.2"},
+		{name: "digest", image: "postgres@" + digest},
+		{name: "tag and digest", image: "postgres:15-alpine@" + digest},
+		{name: "short sha256", image: "postgres@sha256:abc", expectErr: true},
+		{name: "uppercase sha256", image: "postgres@sha256:" + strings.Repeat("AB", 32), expectErr: true},
+		{name: "malformed digest", image: "postgres@latest", expectErr: true},
+		{name: "no repository", image: "@" + digest, expectErr: true},
+		{name: "whitespace", image: "mcp/filesystem latest", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateImageReference(tt.image)
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected '%s' to be rejected", tt.image)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPinnedReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    string
+		digests  []string
+		expected string
+	}{
+		{name: "docker hub library", image: "postgres:15", digests: []string{"docker.io/library/postgres@sha256:aa"}, expected: "postgres@sha256:aa"},
+		{name: "matching repository", image: "mcp/fetch:latest", digests: []string{"other/fetch@sha256:bb", "mcp/fetch@sha256:cc"}, expected: "mcp/fetch@sha256:cc"},
+		{name: "registry with port", image: "registry.local:5000/tools/fetch", digests: []string{"registry.local:5000/tools/fetch@sha256:dd"}, expected: "registry.local:5000/tools/fetch@sha256:dd"},
+		{name: "no digest", image: "local/thing:1", digests: nil, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PinnedReference(tt.image, tt.digests)
+			if got != tt.expected || ok != (tt.expected != "") {
+				t.Errorf("PinnedReference(%q) = %q, %v, want %q", tt.image, got, ok, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyImageLock(t *testing.T) {
+	dir := t.TempDir()
+	configFile := dir + "/mcp-compose.yaml"
+	pinned := "mcp/fetch@sha256:" + strings.Repeat("0", 64)
+	lock := &Lock{Images: map[string]string{"mcp/fetch:latest": pinned}}
+	if err := lock.Save(LockFilePath(configFile)); err != nil {
+		t.Fatalf("Failed to save lock file: %v", err)
+	}
+
+	cfg := &ComposeConfig{Servers: map[string]ServerConfig{
+		"fetch": {Image: "mcp/fetch:latest"},
+		"built": {Image: "mcp/fetch:latest", Build: BuildConfig{Context: "."}},
+		"other": {Image: "mcp/other:latest"},
+	}}
+	servers, err := cfg.ApplyImageLock(configFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(servers) != 1 || servers[0] != "fetch" {
+		t.Errorf("Expected only fetch to be pinned, got %v", servers)
+	}
+	if cfg.Servers["fetch"].Image != pinned || cfg.Servers["built"].Image != "mcp/fetch:latest" {
+		t.Errorf("Unexpected images: %+v", cfg.Servers)
+	}
+
+	if servers, err := (&ComposeConfig{}).ApplyImageLock(dir + "/missing.yaml"); err != nil || servers != nil {
+		t.Errorf("Expected no lock file to leave the config alone, got %v, %v", servers, err)
+	}
+}
+
+func TestLockEnv(t *testing.T) {
+	locked := LockEnv(map[string]string{
+		"LOG_LEVEL":    "info",
+		"API_TOKEN":    "abc123",
+		"DATABASE_URL": "postgres://user:secret@db:5432/app",
+	})
+	if locked["LOG_LEVEL"] != "info" {
+		t.Errorf("Expected plain values to be kept, got %q", locked["LOG_LEVEL"])
+	}
+	sum := sha256.Sum256([]byte("abc123"))
+	if locked["API_TOKEN"] != "sha256:"+hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the token to be hashed, got %q", locked["API_TOKEN"])
+	}
+	if strings.Contains(locked["DATABASE_URL"], "secret") {
+		t.Errorf("Expected the URL with a password to be hashed, got %q", locked["DATABASE_URL"])
+	}
+	if LockEnv(nil) != nil {
+		t.Error("Expected no environment to lock as nil")
+	}
+}
+
+func TestLoadLock(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		expectErr bool
+	}{
+		{name: "version 1", content: "version: 1\nimages:\n  mcp/fetch:latest: mcp/fetch@sha256:" + strings.Repeat("0", 64) + "\n"},
+		{name: "version 2", content: "version: 2\nservers:\n  fetch:\n    image: mcp/fetch:latest\n    env:\n      LOG_LEVEL: info\n"},
+		{name: "newer version", content: "version: 3\n", expectErr: true},
+		{name: "tag instead of digest", content: "version: 2\nimages:\n  mcp/fetch:latest: mcp/fetch:1.0\n", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir() + "/mcp-compose.lock.yaml"
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write lock file: %v", err)
+			}
+			lock, err := LoadLock(path)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if lock.Images == nil {
+				t.Error("Expected images to be initialized")
+			}
+		})
+	}
+
+	if lock, err := LoadLock(t.TempDir() + "/missing.lock.yaml"); lock != nil || err != nil {
+		t.Errorf("Expected a missing lock file to load as nil, got %v, %v", lock, err)
+	}
+	if path := LockFilePath("deploy/mcp-compose.yaml"); path != "deploy/mcp-compose.lock.yaml" {
+		t.Errorf("Unexpected lock file path %s", path)
+	}
+}
+
+func TestValidateLifecycleShell(t *testing.T) {
+	tests := []struct {
+		name    string
+		shell   string
+		wantErr string
+	}{
+		{name: "default shell"},
+		{name: "powershell", shell: "powershell"},
+		{name: "cmd", shell: "cmd"},
+		{name: "unknown shell", shell: "zsh", wantErr: "invalid lifecycle shell"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ComposeConfig{
+				Version: "1",
+				Servers: map[string]ServerConfig{
+					"web": {Command: "node", Lifecycle: LifecycleConfig{Shell: tt.shell, PreStart: "echo starting"}},
+				},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestProjectNamespace(t *testing.T) {
+	t.Setenv(ProjectNameEnv, "")
+	defer func() { _ = SetProjectNamespace("") }()
+
+	cfg := &ComposeConfig{
+		Servers: map[string]ServerConfig{"memory": {Volumes: []string{"memory-data:/data", "./config:/config:ro", "cache:/cache"}}},
+		Volumes: map[string]VolumeConfig{"memory-data": {}, "cache": {External: true}},
+	}
+	if name := ContainerName("memory"); name != "mcp-compose-memory" {
+		t.Errorf("ContainerName() = %q without a project, want mcp-compose-memory", name)
+	}
+
+	if err := SetProjectNamespace("Work"); err == nil {
+		t.Error("Expected an invalid project name error for 'Work'")
+	}
+	if err := SetProjectNamespace("work"); err != nil {
+		t.Fatalf("SetProjectNamespace() failed: %v", err)
+	}
+	if os.Getenv(ProjectNameEnv) != "work" {
+		t.Errorf("%s was not exported", ProjectNameEnv)
+	}
+	if name := ContainerName("memory"); name != "work-mcp-compose-memory" {
+		t.Errorf("ContainerName() = %q, want work-mcp-compose-memory", name)
+	}
+	if server := ServerForContainer("work-mcp-compose-memory"); server != "memory" {
+		t.Errorf("ServerForContainer() = %q, want memory", server)
+	}
+	if network := DefaultNetwork(); network != "work-mcp-net" {
+		t.Errorf("DefaultNetwork() = %q, want work-mcp-net", network)
+	}
+	if network := ServerNetwork("memory"); network != "work-mcp-net-memory" {
+		t.Errorf("ServerNetwork() = %q, want work-mcp-net-memory", network)
+	}
+	want := []string{"work-memory-data:/data", "./config:/config:ro", "cache:/cache"}
+	if volumes := cfg.ServerVolumes("memory"); !slices.Equal(volumes, want) {
+		t.Errorf("ServerVolumes() = %v, want %v", volumes, want)
+	}
+	if project := GetProjectName("/srv/stack/mcp-compose.yaml"); project != "work" {
+		t.Errorf("GetProjectName() = %q, want work", project)
+	}
+}
+
+func TestConfigHistory(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "mcp-compose.yaml")
+	original := []byte("version: \"1\"\nservers: {}\n")
+	if err := os.WriteFile(configFile, original, 0640); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	previous, written, err := WriteConfigFile(configFile, []byte("version: \"1\"\n# edited\nservers: {}\n"), ConfigVersion{Source: VersionSourceCLI, Message: "first"})
+	if err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	// The contents replaced were not recorded yet, so they become version 1
+	if previous == nil || previous.Version != 1 || previous.Source != VersionSourceExternal || written.Version != 2 {
+		t.Fatalf("Unexpected versions %+v and %+v", previous, written)
+	}
+	if info, _ := os.Stat(configFile); info.Mode().Perm() != 0640 {
+		t.Errorf("Expected the file mode to be kept, got %v", info.Mode().Perm())
+	}
+
+	if _, _, err := WriteConfigFile(configFile, []byte("version: \"1\"\n# again\nservers: {}\n"), ConfigVersion{Source: VersionSourceDashboard}); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	versions, err := ConfigHistory(configFile)
+	if err != nil || len(versions) != 3 || versions[0].Version != 3 || versions[2].Version != 1 {
+		t.Fatalf("Expected versions 3 to 1, got %+v (%v)", versions, err)
+	}
+
+	version, content, err := ConfigVersionContent(configFile, 1)
+	if err != nil || string(content) != string(original) || version.Hash != ContentHash(original) {
+		t.Errorf("Expected the original contents for version 1, got %q (%v)", content, err)
+	}
+	if _, _, err := ConfigVersionContent(configFile, 9); err == nil {
+		t.Error("Expected an unknown version to fail")
+	}
+
+	for i := 0; i < constants.ConfigBackupsKept; i++ {
+		if _, _, err := WriteConfigFile(configFile, []byte(fmt.Sprintf("version: \"1\"\n# %d\n", i)), ConfigVersion{Source: VersionSourceCLI}); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+	versions, _ = ConfigHistory(configFile)
+	if len(versions) != constants.ConfigBackupsKept || versions[len(versions)-1].Version != 4 {
+		t.Errorf("Expected the last %d versions to be kept, got %d from version %d", constants.ConfigBackupsKept, len(versions), versions[len(versions)-1].Version)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(configFile), constants.ConfigBackupDir, "mcp-compose.v1.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected the pruned version's copy to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+// internal/config/diff.go
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ConfigChanges lists what differs between two configurations
+type ConfigChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+	Global  bool     `json:"global"` // settings outside servers changed
+}
+
+// Empty reports whether nothing changed
+func (c ConfigChanges) Empty() bool {
+
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0 && !c.Global
+}
+
+// DiffConfigs compares two configurations server by server
+func DiffConfigs(old, updated *ComposeConfig) ConfigChanges {
+	changes := ConfigChanges{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	for _, name := range sortedMapKeys(updated.Servers) {
+		previous, exists := old.Servers[name]
+		if !exists {
+			changes.Added = append(changes.Added, name)
+		} else if !sameYAML(previous, updated.Servers[name]) {
+			changes.Changed = append(changes.Changed, name)
+		}
+	}
+	for _, name := range sortedMapKeys(old.Servers) {
+		if _, exists := updated.Servers[name]; !exists {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	oldGlobal, updatedGlobal := *old, *updated
+	oldGlobal.Servers, updatedGlobal.Servers = nil, nil
+	changes.Global = !sameYAML(oldGlobal, updatedGlobal)
+
+	return changes
+}
+
+func sameYAML(a, b interface{}) bool {
+	encodedA, errA := yaml.Marshal(a)
+	encodedB, errB := yaml.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+// diffContext is how many unchanged lines surround each hunk of a unified diff
+const diffContext = 3
+
+// maxDiffCells bounds the line comparison table; larger edits are shown as one hunk
+const maxDiffCells = 4_000_000
+
+// UnifiedDiff returns the changes from oldText to newText in unified diff format, or an
+// empty string when they are the same
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+
+		return ""
+	}
+	oldLines, newLines := splitLines(oldText), splitLines(newText)
+	ops := diffLines(oldLines, newLines)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// Find the next change and the run of changes close enough to share its hunk
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+
+			break
+		}
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+
+				break
+			}
+			end = next
+		}
+
+		from := max(start-diffContext, 0)
+		to := min(end+diffContext, len(ops))
+		oldStart, newStart := ops[from].oldLine, ops[from].newLine
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		for _, op := range ops[from:to] {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.text)
+		}
+		start = to
+	}
+
+	return out.String()
+}
+
+type diffOp struct {
+	kind             byte // ' ', '-' or '+'
+	text             string
+	oldLine, newLine int // 1-based position before the line is applied
+}
+
+// diffLines matches the lines of a and b by longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	// Lines shared at both ends need no table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	var middle []byte // edit script for the middle: ' ', '-', '+'
+	if len(midA)*len(midB) > maxDiffCells {
+		middle = append(bytes.Repeat([]byte{'-'}, len(midA)), bytes.Repeat([]byte{'+'}, len(midB))...)
+	} else {
+		lcs := make([][]int, len(midA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(midB)+1)
+		}
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(midA) || j < len(midB) {
+			switch {
+			case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+				middle = append(middle, ' ')
+				i++
+				j++
+			case i < len(midA) && (j == len(midB) || lcs[i+1][j] >= lcs[i][j+1]):
+				middle = append(middle, '-')
+				i++
+			default:
+				middle = append(middle, '+')
+				j++
+			}
+		}
+	}
+
+	script := append(bytes.Repeat([]byte{' '}, prefix), middle...)
+	script = append(script, bytes.Repeat([]byte{' '}, suffix)...)
+
+	ops := make([]diffOp, 0, len(script))
+	i, j := 0, 0
+	for _, kind := range script {
+		op := diffOp{kind: kind, oldLine: i + 1, newLine: j + 1}
+		switch kind {
+		case ' ':
+			op.text = a[i]
+			i++
+			j++
+		case '-':
+			op.text = a[i]
+			i++
+		case '+':
+			op.text = b[j]
+			j++
+		}
+		ops = append(ops, op)
+	}
+
+	return ops
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// An empty range names the line before it
+		start--
+	}
+	if count == 1 {
+
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
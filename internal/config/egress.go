@@ -0,0 +1,125 @@
+// internal/config/egress.go
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Egress policy defaults
+const (
+	EgressAllow = "allow"
+	EgressDeny  = "deny"
+)
+
+// EgressRule is one entry of an egress allow or deny list: a host name, "*.domain" for every
+// subdomain of domain, an IP address or a CIDR, optionally followed by ":port"
+type EgressRule struct {
+	Host     string
+	Wildcard bool
+	Network  *net.IPNet
+	Port     int // 0 matches any port
+}
+
+// ParseEgressRule parses an egress allow or deny entry
+func ParseEgressRule(rule string) (EgressRule, error) {
+	var parsed EgressRule
+	host := strings.TrimSpace(rule)
+	if host == "" {
+
+		return parsed, fmt.Errorf("empty egress rule")
+	}
+
+	// A single colon separates the port; IPv6 addresses with a port need brackets
+	if strings.HasPrefix(host, "[") || strings.Count(host, ":") == 1 {
+		h, port, err := net.SplitHostPort(host)
+		if err != nil {
+
+			return parsed, fmt.Errorf("invalid egress rule '%s': %w", rule, err)
+		}
+		if port != "*" {
+			number, err := strconv.Atoi(port)
+			if err != nil || number < 1 || number > 65535 {
+
+				return parsed, fmt.Errorf("invalid port in egress rule '%s'", rule)
+			}
+			parsed.Port = number
+		}
+		host = h
+	}
+
+	if strings.Contains(host, "/") {
+		_, network, err := net.ParseCIDR(host)
+		if err != nil {
+
+			return parsed, fmt.Errorf("invalid CIDR in egress rule '%s': %w", rule, err)
+		}
+		parsed.Network = network
+
+		return parsed, nil
+	}
+
+	if strings.HasPrefix(host, "*.") {
+		parsed.Wildcard = true
+		host = host[2:]
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" || strings.ContainsAny(host, "*/ ") {
+
+		return parsed, fmt.Errorf("invalid host in egress rule '%s'", rule)
+	}
+	parsed.Host = host
+
+	return parsed, nil
+}
+
+// Matches reports whether a connection to host:port falls under the rule. Host names only
+// match name rules, so allowing a name does not allow its IP addresses.
+func (r EgressRule) Matches(host string, port int) bool {
+	if r.Port != 0 && r.Port != port {
+
+		return false
+	}
+	if r.Network != nil {
+		ip := net.ParseIP(host)
+
+		return ip != nil && r.Network.Contains(ip)
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if r.Wildcard {
+
+		return strings.HasSuffix(host, "."+r.Host)
+	}
+
+	return host == r.Host
+}
+
+// Allows reports whether the policy lets a server connect to host:port. Deny rules are checked
+// first, then allow rules; anything else follows the default, which is to deny.
+func (e *EgressConfig) Allows(host string, port int) bool {
+	if matchesEgressRules(e.Deny, host, port) {
+
+		return false
+	}
+	if matchesEgressRules(e.Allow, host, port) {
+
+		return true
+	}
+
+	return e.Default == EgressAllow
+}
+
+func matchesEgressRules(rules []string, host string, port int) bool {
+	for _, rule := range rules {
+		// Invalid rules are rejected when the config is validated
+		if parsed, err := ParseEgressRule(rule); err == nil && parsed.Matches(host, port) {
+
+			return true
+		}
+	}
+
+	return false
+}
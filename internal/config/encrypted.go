@@ -0,0 +1,202 @@
+// internal/config/encrypted.go
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// EncryptedTag marks a value in the compose file that is encrypted with age and decrypted
+// when the file is loaded, so the file can be committed with its secrets
+const EncryptedTag = "!encrypted"
+
+// Where the age identities that decrypt the compose file's values come from, in order: the
+// key itself, a key file, or the default key file
+const (
+	AgeKeyEnv     = "MCP_COMPOSE_AGE_KEY"
+	AgeKeyFileEnv = "MCP_COMPOSE_AGE_KEY_FILE"
+)
+
+var (
+	decryptedMu     sync.RWMutex
+	decryptedValues = make(map[string]bool) // redacted wherever they appear, whatever their setting's name
+)
+
+// DefaultAgeKeyFile is the key file used when neither MCP_COMPOSE_AGE_KEY nor
+// MCP_COMPOSE_AGE_KEY_FILE is set
+func DefaultAgeKeyFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "mcp-compose", "age.txt")
+}
+
+// AgeKeyFile returns the key file in use, or "" when the key is given in MCP_COMPOSE_AGE_KEY
+func AgeKeyFile() string {
+	if os.Getenv(AgeKeyEnv) != "" {
+
+		return ""
+	}
+	if file := os.Getenv(AgeKeyFileEnv); file != "" {
+
+		return file
+	}
+
+	return DefaultAgeKeyFile()
+}
+
+// AgeIdentities loads the identities that decrypt the compose file's encrypted values
+func AgeIdentities() ([]age.Identity, error) {
+	if key := os.Getenv(AgeKeyEnv); key != "" {
+		identities, err := age.ParseIdentities(strings.NewReader(key))
+		if err != nil {
+
+			return nil, fmt.Errorf("invalid %s: %w", AgeKeyEnv, err)
+		}
+
+		return identities, nil
+	}
+	file := AgeKeyFile()
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+
+		return nil, fmt.Errorf("no age key to decrypt with: set %s or %s, or create %s with 'mcp-compose config keygen'",
+			AgeKeyEnv, AgeKeyFileEnv, file)
+	}
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read age key file: %w", err)
+	}
+	identities, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+
+		return nil, fmt.Errorf("invalid age key file %s: %w", file, err)
+	}
+
+	return identities, nil
+}
+
+// EncryptValue encrypts a value for the compose file, returning what follows !encrypted
+func EncryptValue(value string, recipients []age.Recipient) (string, error) {
+	var file bytes.Buffer
+	writer, err := age.Encrypt(&file, recipients...)
+	if err != nil {
+
+		return "", err
+	}
+	if _, err := io.WriteString(writer, value); err != nil {
+
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(file.Bytes()), nil
+}
+
+// decryptValue decrypts what follows !encrypted: a base64 or armored age file
+func decryptValue(value string, identities []age.Identity) (string, error) {
+	var file io.Reader
+	if armored := strings.TrimSpace(value); strings.HasPrefix(armored, armor.Header) {
+		file = armor.NewReader(strings.NewReader(armored))
+	} else {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+		if err != nil {
+
+			return "", fmt.Errorf("not base64 or an armored age file")
+		}
+		file = bytes.NewReader(data)
+	}
+	reader, err := age.Decrypt(file, identities...)
+	if err != nil {
+
+		return "", err
+	}
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// decryptNodes replaces the !encrypted values of a parsed compose document with their
+// plaintext. The key is only loaded when the document has such values.
+func decryptNodes(document *yaml.Node, name string) ValidationErrors {
+	var problems ValidationErrors
+	var identities []age.Identity
+	var keyErr error
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		for _, child := range node.Content {
+			walk(child)
+		}
+		if node.Kind != yaml.ScalarNode || node.Tag != EncryptedTag {
+
+			return
+		}
+		if identities == nil && keyErr == nil {
+			identities, keyErr = AgeIdentities()
+		}
+		plaintext, err := "", keyErr
+		if err == nil {
+			plaintext, err = decryptValue(node.Value, identities)
+		}
+		if err != nil {
+			problems = append(problems, ValidationError{
+				File:    name,
+				Line:    node.Line,
+				Column:  node.Column,
+				Message: fmt.Sprintf("failed to decrypt value: %v", err),
+			})
+
+			return
+		}
+		node.Tag, node.Value, node.Style = "!!str", plaintext, 0
+		if len(plaintext) >= minSecretLength {
+			decryptedMu.Lock()
+			decryptedValues[plaintext] = true
+			decryptedMu.Unlock()
+		}
+	}
+	walk(document)
+
+	return problems
+}
+
+// isDecrypted reports whether a value was encrypted in the compose file
+func isDecrypted(value string) bool {
+	decryptedMu.RLock()
+	defer decryptedMu.RUnlock()
+
+	return decryptedValues[value]
+}
+
+// HasEncryptedValues reports whether the compose file or its override files have !encrypted
+// values, so whatever loads them elsewhere needs the age key
+func HasEncryptedValues(filePath string) bool {
+	for _, path := range append([]string{filePath}, overrideFiles...) {
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), EncryptedTag) {
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,236 @@
+// internal/config/envfile.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// envFiles are the files given with --env-file, loaded over the compose file's own
+var envFiles []string
+
+// SetEnvFiles sets the files, in order, whose variables the compose files are expanded with
+// in preference to .env and env_files
+func SetEnvFiles(files []string) {
+	envFiles = append([]string(nil), files...)
+}
+
+// EnvFiles returns the files given with --env-file
+func EnvFiles() []string {
+
+	return append([]string(nil), envFiles...)
+}
+
+// EnvFileList is the env_file of a server: one file or a list of them
+type EnvFileList []string
+
+// UnmarshalYAML accepts a single file in place of the list
+func (l *EnvFileList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = EnvFileList{node.Value}
+
+		return nil
+	}
+	var files []string
+	if err := node.Decode(&files); err != nil {
+
+		return err
+	}
+	*l = files
+
+	return nil
+}
+
+// ResolveEnvFile returns where an env file given relative to the compose file's directory is
+func ResolveEnvFile(filePath, envFile string) string {
+	if filepath.IsAbs(envFile) {
+
+		return envFile
+	}
+
+	return filepath.Join(ProjectDir(filePath), envFile)
+}
+
+// ContainerEnvFile returns where an env file is mounted for a process that reads the compose
+// file at containerConfig, so it resolves there as it does on the host
+func ContainerEnvFile(containerConfig, envFile string) string {
+	if filepath.IsAbs(envFile) {
+
+		return filepath.ToSlash(envFile)
+	}
+
+	return path.Join(path.Dir(containerConfig), filepath.ToSlash(envFile))
+}
+
+// InterpolationEnvFiles returns the existing files whose variables the compose files are
+// expanded with, from the lowest precedence to the highest: .env next to the compose file,
+// the env_files it lists and the --env-file files. Variables set in the environment win
+// over all of them.
+func InterpolationEnvFiles(filePath string) []string {
+
+	return interpolationEnvFiles(filePath, nil)
+}
+
+// interpolationEnvFiles reads env_files from data in place of the compose file when given
+func interpolationEnvFiles(filePath string, data []byte) []string {
+	files := []string{filepath.Join(ProjectDir(filePath), ".env")}
+	documents := [][]byte{data}
+	if data == nil {
+		documents[0], _ = os.ReadFile(filePath)
+	}
+	for _, override := range overrideFiles {
+		overrideData, _ := os.ReadFile(override)
+		documents = append(documents, overrideData)
+	}
+	for _, document := range documents {
+		// env_files is read before variables are expanded, since they may come from these files
+		var listed struct {
+			EnvFiles []string `yaml:"env_files"`
+		}
+		_ = yaml.Unmarshal(document, &listed)
+		for _, envFile := range listed.EnvFiles {
+			files = append(files, ResolveEnvFile(filePath, envFile))
+		}
+	}
+	files = append(files, envFiles...)
+
+	existing := files[:0]
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			existing = append(existing, file)
+		}
+	}
+
+	return existing
+}
+
+// loadDotEnv sets the variables of .env, env_files and --env-file that are not already set in
+// the environment, a later file winning over an earlier one
+func loadDotEnv(filePath string, data []byte) {
+	values := make(map[string]string)
+	for _, file := range interpolationEnvFiles(filePath, data) {
+		fileValues, err := parseEnvFile(file)
+		if err != nil {
+
+			continue // Could not read the file, continue without it
+		}
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+	for key, value := range values {
+		// Only set if not already set in environment
+		if os.Getenv(key) == "" {
+			_ = os.Setenv(key, value)
+		}
+	}
+}
+
+// parseEnvFile reads KEY=value lines, skipping blank lines and # comments
+func parseEnvFile(file string) (map[string]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+
+		return nil, err
+	}
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+
+			continue
+		}
+		// Split on first = sign
+		parts := strings.SplitN(line, "=", constants.EnvVarSplitParts)
+		if len(parts) != constants.EnvVarSplitParts {
+
+			continue
+		}
+		values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return values, nil
+}
+
+// applyEnvFiles checks that the env_files exist and fills each server's env with the
+// variables of its env_file that env does not set, a later file winning over an earlier one.
+// Values are expanded like the compose file.
+func applyEnvFiles(filePath string, config *ComposeConfig) ValidationErrors {
+	var v validation
+	for i, envFile := range config.EnvFiles {
+		if _, err := os.Stat(ResolveEnvFile(filePath, envFile)); err != nil {
+			v.addf(fmt.Sprintf("env_files.%d", i), "env file '%s' not found", envFile)
+		}
+	}
+	for _, name := range sortedNames(config.Servers) {
+		server := config.Servers[name]
+		if len(server.EnvFile) == 0 {
+
+			continue
+		}
+		values := make(map[string]string)
+		for i, envFile := range server.EnvFile {
+			fileValues, err := parseEnvFile(ResolveEnvFile(filePath, envFile))
+			if err != nil {
+				v.addf(fmt.Sprintf("servers.%s.env_file.%d", name, i), "env file '%s' not found", envFile)
+
+				continue
+			}
+			for key, value := range fileValues {
+				values[key] = expandEnv(value)
+			}
+		}
+		if server.Env == nil {
+			server.Env = make(map[string]string, len(values))
+		}
+		for key, value := range values {
+			if _, set := server.Env[key]; !set {
+				server.Env[key] = value
+			}
+		}
+		config.Servers[name] = server
+	}
+
+	return v.errs
+}
+
+// EnvFilePaths returns every file env_files and the servers' env_file name, as written
+func (c *ComposeConfig) EnvFilePaths() []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(file string) {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	for _, file := range c.EnvFiles {
+		add(file)
+	}
+	for _, server := range c.Servers {
+		for _, file := range server.EnvFile {
+			add(file)
+		}
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+func sortedNames(servers map[string]ServerConfig) []string {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,250 @@
+// internal/config/history.go
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// Where a version of the compose file came from
+const (
+	VersionSourceDashboard = "dashboard"
+	VersionSourceCLI       = "cli"
+	VersionSourceRollback  = "rollback"
+	VersionSourceExternal  = "external" // edited by hand between recorded changes
+)
+
+// ConfigVersion is one recorded version of the compose file. Its contents are kept in the
+// backup directory next to the file.
+type ConfigVersion struct {
+	Version int       `json:"version" yaml:"version"`
+	Time    time.Time `json:"time" yaml:"time"`
+	Source  string    `json:"source" yaml:"source"`
+	Client  string    `json:"client,omitempty" yaml:"client,omitempty"`
+	Message string    `json:"message,omitempty" yaml:"message,omitempty"`
+	Hash    string    `json:"hash" yaml:"hash"`
+	File    string    `json:"file" yaml:"file"`
+}
+
+// HistoryNameEnv names the history a compose file's versions are recorded in, for a file
+// mounted under another name than it has on the host, as in the proxy container
+const HistoryNameEnv = "MCP_COMPOSE_HISTORY_NAME"
+
+var historyMu sync.Mutex
+
+// ContentHash identifies the contents of a compose file
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// ConfigHistory returns the recorded versions of the compose file, newest first
+func ConfigHistory(configFile string) ([]ConfigVersion, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	versions, err := readHistory(configFile)
+	if err != nil {
+
+		return nil, err
+	}
+	newestFirst := make([]ConfigVersion, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		newestFirst = append(newestFirst, versions[i])
+	}
+
+	return newestFirst, nil
+}
+
+// ConfigVersionContent returns the contents of a recorded version of the compose file
+func ConfigVersionContent(configFile string, version int) (*ConfigVersion, []byte, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	versions, err := readHistory(configFile)
+	if err != nil {
+
+		return nil, nil, err
+	}
+	for _, recorded := range versions {
+		if recorded.Version != version {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(historyDir(configFile), recorded.File))
+		if err != nil {
+
+			return nil, nil, fmt.Errorf("failed to read version %d: %w", version, err)
+		}
+
+		return &recorded, data, nil
+	}
+	if len(versions) == 0 {
+
+		return nil, nil, fmt.Errorf("no history is recorded for %s", DisplayName(configFile))
+	}
+
+	return nil, nil, fmt.Errorf("version %d is not in the history (versions %d to %d are kept)",
+		version, versions[0].Version, versions[len(versions)-1].Version)
+}
+
+// WriteConfigFile writes new contents to the compose file and records them as a version.
+// The contents it replaces are recorded first when the history doesn't hold them, so an
+// edit made by hand can be rolled back to as well. The previous version is returned with
+// the new one; it is nil when the file did not exist.
+func WriteConfigFile(configFile string, content []byte, change ConfigVersion) (*ConfigVersion, *ConfigVersion, error) {
+	if source, remote := RemoteSource(configFile); remote {
+
+		return nil, nil, fmt.Errorf("'%s' is fetched from a remote source and can't be edited locally", source)
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	versions, err := readHistory(configFile)
+	if err != nil {
+
+		return nil, nil, err
+	}
+
+	var previous *ConfigVersion
+	mode := os.FileMode(constants.DefaultFileMode)
+	current, err := os.ReadFile(configFile)
+	switch {
+	case err == nil:
+		if info, statErr := os.Stat(configFile); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+		if len(versions) == 0 || versions[len(versions)-1].Hash != ContentHash(current) {
+			if versions, err = addVersion(configFile, versions, current, ConfigVersion{Source: VersionSourceExternal}); err != nil {
+
+				return nil, nil, err
+			}
+		}
+		latest := versions[len(versions)-1]
+		previous = &latest
+	case !errors.Is(err, os.ErrNotExist):
+
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Written in place rather than renamed over, so a bind-mounted file is updated
+	if err := os.WriteFile(configFile, content, mode); err != nil {
+
+		return nil, nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	if versions, err = addVersion(configFile, versions, content, change); err != nil {
+
+		return previous, nil, err
+	}
+	if err := writeHistory(configFile, versions); err != nil {
+
+		return previous, nil, err
+	}
+
+	return previous, &versions[len(versions)-1], nil
+}
+
+// addVersion copies contents into the backup directory as the next version and prunes the
+// oldest versions beyond the ones kept
+func addVersion(configFile string, versions []ConfigVersion, content []byte, change ConfigVersion) ([]ConfigVersion, error) {
+	dir := historyDir(configFile)
+	if err := os.MkdirAll(dir, constants.SecretDirMode); err != nil {
+
+		return versions, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	change.Version = 1
+	if len(versions) > 0 {
+		change.Version = versions[len(versions)-1].Version + 1
+	}
+	if change.Time.IsZero() {
+		change.Time = time.Now().UTC()
+	}
+	change.Hash = ContentHash(content)
+	change.File = fmt.Sprintf("%s.v%d%s", HistoryName(configFile), change.Version, filepath.Ext(configFile))
+	if err := os.WriteFile(filepath.Join(dir, change.File), content, constants.SecretFileMode); err != nil {
+
+		return versions, fmt.Errorf("failed to back up config file: %w", err)
+	}
+
+	versions = append(versions, change)
+	for len(versions) > constants.ConfigBackupsKept {
+		_ = os.Remove(filepath.Join(dir, versions[0].File))
+		versions = versions[1:]
+	}
+
+	return versions, nil
+}
+
+func historyDir(configFile string) string {
+
+	return filepath.Join(filepath.Dir(configFile), constants.ConfigBackupDir)
+}
+
+// HistoryName is the name a compose file's versions are recorded under: its own without the
+// extension, so files sharing a directory keep separate histories
+func HistoryName(configFile string) string {
+	if name := os.Getenv(HistoryNameEnv); name != "" {
+
+		return name
+	}
+	base := filepath.Base(configFile)
+
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func historyIndex(configFile string) string {
+
+	return filepath.Join(historyDir(configFile), HistoryName(configFile)+"."+constants.ConfigHistoryIndex)
+}
+
+func readHistory(configFile string) ([]ConfigVersion, error) {
+	data, err := os.ReadFile(historyIndex(configFile))
+	if errors.Is(err, os.ErrNotExist) {
+
+		return nil, nil
+	}
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read config history: %w", err)
+	}
+	var versions []ConfigVersion
+	if err := json.Unmarshal(data, &versions); err != nil {
+
+		return nil, fmt.Errorf("failed to parse config history %s: %w", historyIndex(configFile), err)
+	}
+
+	return versions, nil
+}
+
+// writeHistory replaces the index through a temporary file, so a crash never leaves half of it
+func writeHistory(configFile string, versions []ConfigVersion) error {
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+
+		return fmt.Errorf("failed to encode config history: %w", err)
+	}
+	index := historyIndex(configFile)
+	temp := index + ".tmp"
+	if err := os.WriteFile(temp, append(data, '\n'), constants.SecretFileMode); err != nil {
+
+		return fmt.Errorf("failed to write config history: %w", err)
+	}
+	if err := os.Rename(temp, index); err != nil {
+		_ = os.Remove(temp)
+
+		return fmt.Errorf("failed to write config history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+// internal/config/lock.go
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// LockVersion is the format version written to lock files. Version 1 files, which only
+// record image digests, are still read.
+const LockVersion = 2
+
+var (
+	digestPattern = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]+$`)
+	sha256Pattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+)
+
+// Lock records what a compose file resolved to: the digest each image reference was pulled
+// as, and for each server its image, build context and environment, so that other machines
+// and later deployments get exactly the same servers
+type Lock struct {
+	Version     int                     `yaml:"version"`
+	GeneratedAt time.Time               `yaml:"generated_at"`
+	Images      map[string]string       `yaml:"images"`
+	Servers     map[string]LockedServer `yaml:"servers,omitempty"`
+}
+
+// LockedServer is how one server resolved when the lock file was written
+type LockedServer struct {
+	// Image is the image as configured and Digest the reference it resolved to
+	Image  string `yaml:"image,omitempty"`
+	Digest string `yaml:"digest,omitempty"`
+	// BuildContext is a hash of the build context, the Dockerfile and the build settings
+	BuildContext string `yaml:"build_context,omitempty"`
+	// Env holds the environment after substitution, with secret values replaced by hashes
+	Env map[string]string `yaml:"env,omitempty"`
+}
+
+// LockFilePath returns the lock file that belongs to a compose file: mcp-compose.yaml is
+// locked by mcp-compose.lock.yaml next to it
+func LockFilePath(filePath string) string {
+
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".lock.yaml"
+}
+
+// LockEnv returns an environment as it is recorded in a lock file. Secret values, found the
+// way rendered configs are redacted, are replaced by their SHA-256 so the file can be
+// committed while changes to them are still detected.
+func LockEnv(env map[string]string) map[string]string {
+	if len(env) == 0 {
+
+		return nil
+	}
+	locked := make(map[string]string, len(env))
+	for name, value := range env {
+		if IsSecretEnv(name, value) {
+			sum := sha256.Sum256([]byte(value))
+			value = "sha256:" + hex.EncodeToString(sum[:])
+		}
+		locked[name] = value
+	}
+
+	return locked
+}
+
+// LoadLock reads a lock file, returning nil without an error when there is none
+func LoadLock(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+
+		return nil, nil
+	}
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	var lock Lock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", path, err)
+	}
+	if lock.Version > LockVersion {
+
+		return nil, fmt.Errorf("lock file %s has version %d, newer than this mcp-compose supports (%d)", path, lock.Version, LockVersion)
+	}
+	if lock.Images == nil {
+		lock.Images = make(map[string]string)
+	}
+	for image, pinned := range lock.Images {
+		if !IsDigestReference(pinned) {
+
+			return nil, fmt.Errorf("lock file %s pins '%s' to '%s', which is not a digest reference", path, image, pinned)
+		}
+	}
+
+	return &lock, nil
+}
+
+// Save writes the lock file at the current version
+func (l *Lock) Save(path string) error {
+	l.Version = LockVersion
+	l.GeneratedAt = l.GeneratedAt.UTC().Truncate(time.Second)
+	var out bytes.Buffer
+	out.WriteString("# Written by mcp-compose lock and pull. Commit it so everyone runs the same servers.\n")
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(l); err != nil {
+
+		return fmt.Errorf("failed to encode lock file: %w", err)
+	}
+	_ = encoder.Close()
+
+	if err := os.WriteFile(path, out.Bytes(), constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyImageLock runs the servers whose image is in the lock file of filePath from the
+// locked digest, and returns their names. Servers built locally or already pinned by digest
+// keep their image.
+func (c *ComposeConfig) ApplyImageLock(filePath string) ([]string, error) {
+	lock, err := LoadLock(LockFilePath(filePath))
+	if err != nil || lock == nil {
+
+		return nil, err
+	}
+
+	var pinned []string
+	for name, server := range c.Servers {
+		if server.Build.Context != "" || IsDigestReference(server.Image) {
+
+			continue
+		}
+		if locked, exists := lock.Images[server.Image]; exists {
+			server.Image = locked
+			c.Servers[name] = server
+			pinned = append(pinned, name)
+		}
+	}
+	sort.Strings(pinned)
+
+	return pinned, nil
+}
+
+// PinnedReference returns image pinned to the digest, among the repository digests a runtime
+// reports for it, that belongs to the image's repository
+func PinnedReference(image string, repoDigests []string) (string, bool) {
+	repository := imageRepository(image)
+	for _, repoDigest := range repoDigests {
+		at := strings.LastIndex(repoDigest, "@")
+		if at < 0 {
+
+			continue
+		}
+		if normalizeRepository(repoDigest[:at]) == normalizeRepository(repository) {
+
+			return repository + repoDigest[at:], true
+		}
+	}
+
+	return "", false
+}
+
+// imageRepository strips the tag and digest from an image reference
+func imageRepository(image string) string {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		image = image[:colon]
+	}
+
+	return image
+}
+
+// normalizeRepository spells Docker Hub repositories the same way whether or not the
+// registry and library/ are written out
+func normalizeRepository(repository string) string {
+	repository = strings.TrimPrefix(repository, "docker.io/")
+	repository = strings.TrimPrefix(repository, "index.docker.io/")
+
+	return strings.TrimPrefix(repository, "library/")
+}
+
+// IsDigestReference reports whether an image reference names its image by digest
+func IsDigestReference(image string) bool {
+
+	return strings.Contains(image, "@")
+}
+
+// ValidateImageReference checks the parts of an image reference mcp-compose relies on: no
+// whitespace, and a well-formed digest when the image is pinned by one
+func ValidateImageReference(image string) error {
+	if strings.ContainsAny(image, " \t\n") {
+
+		return fmt.Errorf("image reference '%s' contains whitespace", image)
+	}
+	at := strings.LastIndex(image, "@")
+	if at < 0 {
+
+		return nil
+	}
+	if at == 0 {
+
+		return fmt.Errorf("image reference '%s' has a digest but no repository", image)
+	}
+	digest := image[at+1:]
+	if !digestPattern.MatchString(digest) {
+
+		return fmt.Errorf("image reference '%s' has a malformed digest", image)
+	}
+	if strings.HasPrefix(digest, "sha256:") && !sha256Pattern.MatchString(digest) {
+
+		return fmt.Errorf("image reference '%s' has a sha256 digest that is not 64 lowercase hex characters", image)
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+// internal/config/merge.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// overrideFiles are merged over the compose file by LoadConfig, in order
+var overrideFiles []string
+
+// SetOverrideFiles sets the files LoadConfig merges over the compose file, the way
+// repeated docker compose -f flags layer a per-developer or per-environment file on a base
+func SetOverrideFiles(files []string) {
+	overrideFiles = append([]string(nil), files...)
+}
+
+// OverrideFiles returns the files merged over the compose file
+func OverrideFiles() []string {
+
+	return append([]string(nil), overrideFiles...)
+}
+
+// mergedSequences are the lists an override adds to rather than replaces
+var mergedSequences = map[string]bool{
+	"ports": true, "volumes": true, "networks": true, "depends_on": true,
+	"external_depends_on": true, "capabilities": true, "profiles": true, "tmpfs": true,
+	"cap_add": true, "cap_drop": true, "security_opt": true, "dns": true,
+	"dns_search": true, "extra_hosts": true,
+}
+
+// Tags an override can put on a value to change how it merges
+const (
+	resetTag    = "!reset"    // remove the setting from the base
+	overrideTag = "!override" // replace the value instead of merging into it
+)
+
+// readComposeDocument reads one compose file with environment variables expanded,
+// recording which file every node came from
+func readComposeDocument(path string, sources map[*yaml.Node]string) (*yaml.Node, error) {
+	name := DisplayName(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read config file '%s': %w", name, err)
+	}
+
+	return parseComposeDocument(name, data, sources)
+}
+
+// parseComposeDocument parses compose file contents; a syntax error is returned as
+// ValidationErrors carrying its line
+func parseComposeDocument(name string, data []byte, sources map[*yaml.Node]string) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(expandEnv(string(data))), &document); err != nil {
+		problem := parseYAMLError(strings.TrimPrefix(err.Error(), "yaml: "))
+		problem.File = name
+
+		return nil, fmt.Errorf("failed to parse config file '%s': %w", name, ValidationErrors{problem})
+	}
+	recordSources(&document, name, sources)
+	if problems := decryptNodes(&document, name); len(problems) > 0 {
+
+		return nil, fmt.Errorf("failed to decrypt config file '%s': %w", name, problems)
+	}
+
+	return &document, nil
+}
+
+// expandEnv expands ${VAR} and $VAR from the environment; $$ stands for a literal $, as in
+// bcrypt password hashes
+func expandEnv(data string) string {
+
+	return os.Expand(data, func(name string) string {
+		if name == "$" {
+
+			return "$"
+		}
+
+		return os.Getenv(name)
+	})
+}
+
+// ReferencedEnv returns the environment variables the compose file and its override files
+// refer to, sorted, so they can be handed to a process that loads the config later
+func ReferencedEnv(filePath string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, path := range append([]string{filePath}, overrideFiles...) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to read config file '%s': %w", DisplayName(path), err)
+		}
+		os.Expand(string(data), func(name string) string {
+			if name != "$" && name != "" {
+				seen[name] = true
+			}
+
+			return ""
+		})
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func recordSources(node *yaml.Node, path string, sources map[*yaml.Node]string) {
+	sources[node] = path
+	for _, child := range node.Content {
+		recordSources(child, path, sources)
+	}
+}
+
+// mergeDocuments merges override into base: mappings merge key by key, the lists in
+// mergedSequences are combined, and anything else in override replaces base
+func mergeDocuments(base, override *yaml.Node) *yaml.Node {
+	if len(override.Content) == 0 {
+
+		return base
+	}
+	if len(base.Content) == 0 {
+
+		return override
+	}
+	base.Content[0] = mergeNode(base.Content[0], override.Content[0], "")
+
+	return base
+}
+
+func mergeNode(base, override *yaml.Node, key string) *yaml.Node {
+	if override.Tag == overrideTag {
+		override.Tag = ""
+
+		return override
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && override.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(override.Content); i += 2 {
+			name, value := override.Content[i], override.Content[i+1]
+			index := mappingIndex(base, name.Value)
+			switch {
+			case value.Tag == resetTag && index >= 0:
+				base.Content = append(base.Content[:index], base.Content[index+2:]...)
+			case value.Tag == resetTag:
+			case index >= 0:
+				base.Content[index+1] = mergeNode(base.Content[index+1], value, name.Value)
+			default:
+				base.Content = append(base.Content, name, value)
+			}
+		}
+
+		return base
+	case base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode && mergedSequences[key]:
+		positions := make(map[string]int, len(base.Content))
+		for i, item := range base.Content {
+			positions[sequenceItemID(key, item)] = i
+		}
+		for _, item := range override.Content {
+			if i, exists := positions[sequenceItemID(key, item)]; exists {
+				base.Content[i] = item
+			} else {
+				positions[sequenceItemID(key, item)] = len(base.Content)
+				base.Content = append(base.Content, item)
+			}
+		}
+
+		return base
+	default:
+
+		return override
+	}
+}
+
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+
+			return i
+		}
+	}
+
+	return -1
+}
+
+// sequenceItemID identifies list entries an override replaces rather than adds to:
+// volumes and tmpfs mounts by their container path, everything else by value
+func sequenceItemID(key string, item *yaml.Node) string {
+	if item.Kind != yaml.ScalarNode {
+
+		return fmt.Sprintf("%p", item)
+	}
+	if key == "volumes" || key == "tmpfs" {
+		parts := strings.Split(item.Value, ":")
+		if key == "volumes" && len(parts) > 1 {
+
+			return parts[1]
+		}
+
+		return parts[0]
+	}
+
+	return item.Value
+}
+
+// MergeServer merges override over server the way an override file merges over a server of
+// the compose file, so !reset and !override apply. override is left unchanged.
+func MergeServer(server ServerConfig, override *yaml.Node) (ServerConfig, error) {
+	if override == nil || override.Kind == 0 {
+
+		return server, nil
+	}
+	if override.Kind != yaml.MappingNode {
+
+		return server, fmt.Errorf("server override must be a mapping")
+	}
+	var base yaml.Node
+	if err := base.Encode(server); err != nil {
+
+		return server, fmt.Errorf("failed to encode server: %w", err)
+	}
+	var merged ServerConfig
+	if err := mergeNode(&base, cloneNode(override), "").Decode(&merged); err != nil {
+
+		return server, fmt.Errorf("invalid server override: %w", err)
+	}
+
+	return merged, nil
+}
+
+// cloneNode deep-copies a node, since merging takes over and retags override nodes
+func cloneNode(node *yaml.Node) *yaml.Node {
+	clone := *node
+	clone.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		clone.Content[i] = cloneNode(child)
+	}
+
+	return &clone
+}
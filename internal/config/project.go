@@ -0,0 +1,112 @@
+// internal/config/project.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ProjectNameEnv sets the project namespace, like --project-name. It is also passed to the
+// proxy, dashboard and task scheduler containers so they address the project's containers.
+const ProjectNameEnv = "MCP_COMPOSE_PROJECT_NAME"
+
+var projectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// projectNamespace prefixes the names of the containers, networks and volumes mcp-compose
+// creates, so several stacks can run on one host; empty keeps the plain names
+var projectNamespace = os.Getenv(ProjectNameEnv)
+
+// SetProjectNamespace sets the project whose containers, networks and volumes are used, and
+// exports it to the processes mcp-compose starts
+func SetProjectNamespace(name string) error {
+	if name != "" && !projectNamePattern.MatchString(name) {
+
+		return fmt.Errorf("invalid project name '%s': use lowercase letters, digits, '-' and '_', starting with a letter or digit", name)
+	}
+	projectNamespace = name
+	if name == "" {
+
+		return os.Unsetenv(ProjectNameEnv)
+	}
+
+	return os.Setenv(ProjectNameEnv, name)
+}
+
+// ProjectNamespace returns the project set with --project-name or MCP_COMPOSE_PROJECT_NAME,
+// or "" when the plain names are used
+func ProjectNamespace() string {
+
+	return projectNamespace
+}
+
+// Namespaced prefixes a container, network or volume name with the project, if one is set
+func Namespaced(name string) string {
+	if projectNamespace == "" {
+
+		return name
+	}
+
+	return projectNamespace + "-" + name
+}
+
+// ContainerName is the name of the container, or the identifier of the process, running a
+// server or one of mcp-compose's own services such as http-proxy
+func ContainerName(serverName string) string {
+
+	return Namespaced("mcp-compose-" + serverName)
+}
+
+// ServerForContainer returns the server a container name from ContainerName belongs to
+func ServerForContainer(containerName string) string {
+
+	return strings.TrimPrefix(containerName, Namespaced("mcp-compose-"))
+}
+
+// DefaultNetwork is the network servers without one of their own share with the proxy
+func DefaultNetwork() string {
+
+	return Namespaced("mcp-net")
+}
+
+// VolumeName is the runtime name of a named volume: external volumes keep their name, and
+// the others are prefixed with the project
+func (c *ComposeConfig) VolumeName(name string) string {
+	if volume, declared := c.Volumes[name]; declared && volume.External {
+
+		return name
+	}
+
+	return Namespaced(name)
+}
+
+// ServerVolumes returns a server's volume mounts with named volumes given their runtime names
+func (c *ComposeConfig) ServerVolumes(serverName string) []string {
+
+	return c.NamespaceMounts(c.Servers[serverName].Volumes)
+}
+
+// NamespaceMounts gives the named volumes of source:target mounts their runtime names;
+// bind mounts and anonymous volumes are left as they are
+func (c *ComposeConfig) NamespaceMounts(mounts []string) []string {
+	if projectNamespace == "" || len(mounts) == 0 {
+
+		return mounts
+	}
+	result := make([]string, len(mounts))
+	for i, mount := range mounts {
+		result[i] = mount
+		if source, rest, found := strings.Cut(mount, ":"); found && IsNamedVolume(source) {
+			result[i] = c.VolumeName(source) + ":" + rest
+		}
+	}
+
+	return result
+}
+
+// IsNamedVolume reports whether the source of a mount names a volume rather than a host path
+func IsNamedVolume(source string) bool {
+
+	return source != "" && !strings.ContainsAny(source[:1], "/.~$") && !strings.Contains(source, "/")
+}
@@ -0,0 +1,203 @@
+// internal/config/redact.go
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces secrets in rendered configuration
+const RedactedValue = "[REDACTED]"
+
+// secretWords are the trailing words of setting and variable names that hold secrets
+var secretWords = map[string]bool{
+	"secret": true, "token": true, "password": true, "passwd": true, "pass": true,
+	"key": true, "apikey": true, "hash": true, "credentials": true, "pat": true,
+}
+
+var (
+	nameSeparator = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+	urlPassword   = regexp.MustCompile(`(\w+://[^:/@\s]+:)[^@\s]+@`)
+	flagWithValue = regexp.MustCompile(`^(--?[\w-]+)=(.+)$`)
+	bearerToken   = regexp.MustCompile(`(?i)(bearer\s+)[\w.~+/=-]+`)
+	namedValue    = regexp.MustCompile(`("?)([A-Za-z_][\w.-]*)("?\s*[=:]\s*"?)([^\s"',&;}]+)`)
+)
+
+// minSecretLength keeps short values such as "1" or "yes" from being replaced everywhere in
+// free text when they happen to be configured under a secret name
+const minSecretLength = 4
+
+// RedactNode replaces secret values in place: values of settings and variables whose names
+// end in a secret word, values that were encrypted in the compose file, values of secret
+// command-line flags, and passwords in URLs
+func RedactNode(node *yaml.Node) {
+	redactNode(node, false)
+}
+
+// Redact returns a generic copy of a configuration value with its secrets redacted,
+// ready to be encoded as JSON
+func Redact(value interface{}) (interface{}, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+
+		return nil, err
+	}
+	redactNode(&node, false)
+
+	var redacted interface{}
+	if err := node.Decode(&redacted); err != nil {
+
+		return nil, err
+	}
+
+	return redacted, nil
+}
+
+// RedactEnvEntry redacts the value of a NAME=value environment entry when the name holds a secret
+func RedactEnvEntry(entry string) string {
+	name, value, found := strings.Cut(entry, "=")
+	if !found || value == "" {
+
+		return entry
+	}
+	if IsSecretName(name) {
+
+		return name + "=" + RedactedValue
+	}
+
+	return name + "=" + urlPassword.ReplaceAllString(value, "${1}"+RedactedValue+"@")
+}
+
+// SecretValues returns the secret values RedactNode would hide in a configuration value, so
+// they can also be removed from text that never went through the configuration, such as logs
+func SecretValues(value interface{}) ([]string, error) {
+	var original, redacted yaml.Node
+	if err := original.Encode(value); err != nil {
+
+		return nil, err
+	}
+	if err := redacted.Encode(value); err != nil {
+
+		return nil, err
+	}
+	redactNode(&redacted, false)
+
+	seen := make(map[string]bool)
+	collectSecrets(&original, &redacted, seen)
+	secrets := make([]string, 0, len(seen))
+	for secret := range seen {
+		secrets = append(secrets, secret)
+	}
+	// Longest first, so a secret containing another is replaced whole
+	sort.Slice(secrets, func(i, j int) bool {
+		if len(secrets[i]) != len(secrets[j]) {
+
+			return len(secrets[i]) > len(secrets[j])
+		}
+
+		return secrets[i] < secrets[j]
+	})
+
+	return secrets, nil
+}
+
+// RedactText hides secrets in free text: the given secret values, passwords in URLs, bearer
+// tokens, and values assigned to secret names as in NAME=value or "name": "value"
+func RedactText(text string, secrets []string) string {
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			text = strings.ReplaceAll(text, secret, RedactedValue)
+		}
+	}
+	text = urlPassword.ReplaceAllString(text, "${1}"+RedactedValue+"@")
+	text = bearerToken.ReplaceAllString(text, "${1}"+RedactedValue)
+
+	return namedValue.ReplaceAllStringFunc(text, func(match string) string {
+		parts := namedValue.FindStringSubmatch(match)
+		if !IsSecretName(parts[2]) || parts[4] == RedactedValue {
+
+			return match
+		}
+
+		return parts[1] + parts[2] + parts[3] + RedactedValue
+	})
+}
+
+// IsSecretName reports whether a setting or variable name ends in a secret word
+func IsSecretName(name string) bool {
+	words := nameSeparator.Split(strings.Trim(name, "-_"), -1)
+
+	return secretWords[strings.ToLower(words[len(words)-1])]
+}
+
+// IsSecretEnv reports whether an environment variable holds a secret: its name ends in a
+// secret word or its value is a URL with a password
+func IsSecretEnv(name, value string) bool {
+
+	return IsSecretName(name) || urlPassword.MatchString(value)
+}
+
+func redactNode(node *yaml.Node, secret bool) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			redactNode(child, false)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			redactNode(node.Content[i+1], IsSecretName(node.Content[i].Value))
+		}
+	case yaml.SequenceNode:
+		secretFlag := false
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				if secretFlag && item.Value != "" {
+					item.Value = RedactedValue
+				} else if match := flagWithValue.FindStringSubmatch(item.Value); match != nil && IsSecretName(match[1]) {
+					item.Value = match[1] + "=" + RedactedValue
+				}
+				secretFlag = strings.HasPrefix(item.Value, "-") && !strings.Contains(item.Value, "=") && IsSecretName(item.Value)
+			}
+			redactNode(item, secret)
+		}
+	case yaml.ScalarNode:
+		if (secret || isDecrypted(node.Value)) && node.Value != "" && node.Tag == "!!str" {
+			node.Value = RedactedValue
+		} else {
+			node.Value = urlPassword.ReplaceAllString(node.Value, "${1}"+RedactedValue+"@")
+		}
+	}
+}
+
+// collectSecrets walks a configuration node alongside its redacted copy and records the part
+// of every scalar that redaction replaced
+func collectSecrets(original, redacted *yaml.Node, secrets map[string]bool) {
+	if original.Kind == yaml.ScalarNode {
+		if original.Value == redacted.Value {
+
+			return
+		}
+		prefix := 0
+		for prefix < len(original.Value) && prefix < len(redacted.Value) && original.Value[prefix] == redacted.Value[prefix] {
+			prefix++
+		}
+		suffix := 0
+		for suffix < len(original.Value)-prefix && suffix < len(redacted.Value)-prefix &&
+			original.Value[len(original.Value)-1-suffix] == redacted.Value[len(redacted.Value)-1-suffix] {
+			suffix++
+		}
+		if secret := original.Value[prefix : len(original.Value)-suffix]; secret != "" {
+			secrets[secret] = true
+		}
+
+		return
+	}
+	for i := range original.Content {
+		if i < len(redacted.Content) {
+			collectSecrets(original.Content[i], redacted.Content[i], secrets)
+		}
+	}
+}
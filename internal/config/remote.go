@@ -0,0 +1,368 @@
+// internal/config/remote.go
+package config
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// remoteSources maps the cached copy of each remote compose file to where it came from.
+// It is filled in before any config is loaded.
+var remoteSources = make(map[string]string)
+
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// remoteRef is a compose file fetched over HTTP(S) or from a git repository:
+//
+//	https://example.com/mcp-compose.yaml#sha256=<hex>
+//	git+https://github.com/org/stack.git//path/mcp-compose.yaml?ref=v1.2.0
+//	git@github.com:org/stack.git//mcp-compose.yaml?ref=<commit>
+type remoteRef struct {
+	location string // the reference without the checksum
+	url      string // file URL, or repository for git
+	git      bool
+	file     string // file within the repository
+	ref      string // git branch, tag or commit
+	checksum string // expected sha256 of the file, hex
+}
+
+// IsRemoteSource reports whether a --file value refers to a remote compose file
+func IsRemoteSource(source string) bool {
+	for _, prefix := range []string{"http://", "https://", "git+", "git@", "ssh://"} {
+		if strings.HasPrefix(source, prefix) {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// RemoteSource returns the URL a cached remote compose file was fetched from
+func RemoteSource(filePath string) (string, bool) {
+	source, exists := remoteSources[filePath]
+
+	return source, exists
+}
+
+func parseRemoteRef(source string) (remoteRef, error) {
+	ref := remoteRef{location: source}
+	if location, fragment, found := strings.Cut(source, "#"); found {
+		checksum, isChecksum := strings.CutPrefix(fragment, "sha256=")
+		if !isChecksum {
+
+			return ref, fmt.Errorf("unsupported fragment '#%s' in %s (expected #sha256=<hex>)", fragment, source)
+		}
+		ref.location, ref.checksum = location, strings.ToLower(checksum)
+	}
+
+	location := ref.location
+	ref.git = strings.HasPrefix(location, "git+") || strings.HasPrefix(location, "git@") || strings.HasPrefix(location, "ssh://")
+	if !ref.git {
+		ref.url = location
+
+		return ref, nil
+	}
+
+	location = strings.TrimPrefix(location, "git+")
+	if repo, query, found := strings.Cut(location, "?"); found {
+		gitRef, isRef := strings.CutPrefix(query, "ref=")
+		if !isRef {
+
+			return ref, fmt.Errorf("unsupported query '?%s' in %s (expected ?ref=<branch, tag or commit>)", query, source)
+		}
+		// A ref is passed to git fetch, which would take one starting with '-' as an option
+		if strings.HasPrefix(gitRef, "-") {
+
+			return ref, fmt.Errorf("invalid git ref '%s' in %s", gitRef, source)
+		}
+		location, ref.ref = repo, gitRef
+	}
+	// The file path follows the repository after a double slash, as in repo.git//dir/file.yaml
+	start := 0
+	if i := strings.Index(location, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	ref.url, ref.file = location, "mcp-compose.yaml"
+	if i := strings.Index(location[start:], "//"); i >= 0 {
+		ref.url, ref.file = location[:start+i], location[start+i+2:]
+	}
+	// The file is read from the checkout, so it must not lead out of it
+	if cleaned := path.Clean(ref.file); path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+
+		return ref, fmt.Errorf("invalid file path '%s' in %s", ref.file, source)
+	}
+
+	return ref, nil
+}
+
+// pinned reports whether the reference always resolves to the same content, so a cached
+// copy never needs to be fetched again
+func (r remoteRef) pinned() bool {
+
+	return r.checksum != "" || commitPattern.MatchString(r.ref)
+}
+
+func (r remoteRef) verifyChecksum(data []byte) error {
+	if r.checksum == "" {
+
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); actual != r.checksum {
+
+		return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", r.location, r.checksum, actual)
+	}
+
+	return nil
+}
+
+// FetchRemoteFile downloads a remote compose file into the user cache and returns the path
+// of the local copy. When publicKey (base64 ed25519) is set, the file must come with a
+// detached signature next to it (<file>.sig, base64). If the source cannot be reached, the
+// last copy is used, as long as it verifies against the same checksum and key.
+func FetchRemoteFile(source string, publicKey string) (string, error) {
+	ref, err := parseRemoteRef(source)
+	if err != nil {
+
+		return "", err
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(ref.location))
+	name := path.Base(ref.url)
+	if ref.git {
+		name = path.Base(ref.file)
+	}
+	localPath := filepath.Join(cacheDir, "mcp-compose", "remote", hex.EncodeToString(sum[:8]), name)
+
+	if ref.pinned() && ref.verifyCached(localPath, publicKey) == nil {
+		remoteSources[localPath] = ref.location
+
+		return localPath, nil
+	}
+
+	data, signature, err := ref.fetch(publicKey != "")
+	if err != nil {
+		if ref.verifyCached(localPath, publicKey) == nil {
+			fmt.Fprintf(os.Stderr, "Warning: using cached copy of %s: %v\n", ref.location, err)
+			remoteSources[localPath] = ref.location
+
+			return localPath, nil
+		}
+
+		return "", err
+	}
+	if err := ref.verifyChecksum(data); err != nil {
+
+		return "", err
+	}
+	if publicKey != "" {
+		if err := verifySignature(data, signature, publicKey); err != nil {
+
+			return "", fmt.Errorf("%s: %w", ref.location, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), constants.DefaultDirMode); err != nil {
+
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(localPath, data, constants.DefaultFileMode); err != nil {
+
+		return "", fmt.Errorf("failed to cache %s: %w", ref.location, err)
+	}
+	// The signature is kept with the copy so it can be verified again before it is reused
+	if publicKey != "" {
+		err = os.WriteFile(localPath+".sig", signature, constants.DefaultFileMode)
+	} else if err = os.Remove(localPath + ".sig"); os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+
+		return "", fmt.Errorf("failed to cache the signature of %s: %w", ref.location, err)
+	}
+	remoteSources[localPath] = ref.location
+
+	return localPath, nil
+}
+
+// verifyCached checks a cached copy against the checksum and, when publicKey is set, the
+// signature cached with it
+func (r remoteRef) verifyCached(localPath, publicKey string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+
+		return err
+	}
+	if err := r.verifyChecksum(data); err != nil {
+
+		return err
+	}
+	if publicKey == "" {
+
+		return nil
+	}
+	signature, err := os.ReadFile(localPath + ".sig")
+	if err != nil {
+
+		return fmt.Errorf("no signature cached: %w", err)
+	}
+
+	return verifySignature(data, signature, publicKey)
+}
+
+// fetch returns the file and, when asked for, its detached signature
+func (r remoteRef) fetch(withSignature bool) ([]byte, []byte, error) {
+	if r.git {
+
+		return r.fetchGit(withSignature)
+	}
+
+	data, err := fetchHTTP(r.url)
+	if err != nil || !withSignature {
+
+		return data, nil, err
+	}
+	signature, err := fetchHTTP(r.url + ".sig")
+	if err != nil {
+
+		return nil, nil, fmt.Errorf("failed to fetch signature: %w", err)
+	}
+
+	return data, signature, nil
+}
+
+func fetchHTTP(url string) ([]byte, error) {
+	client := &http.Client{Timeout: constants.RemoteConfigFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, constants.RemoteConfigMaxSize+1))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if len(data) > constants.RemoteConfigMaxSize {
+
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, constants.RemoteConfigMaxSize)
+	}
+
+	return data, nil
+}
+
+// fetchGit reads the file from a shallow fetch of the ref, which works for branches, tags
+// and (on hosts that allow it) commits alike
+func (r remoteRef) fetchGit(withSignature bool) ([]byte, []byte, error) {
+	checkout, err := os.MkdirTemp("", "mcp-compose-git-")
+	if err != nil {
+
+		return nil, nil, fmt.Errorf("failed to create checkout directory: %w", err)
+	}
+	defer os.RemoveAll(checkout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.RemoteConfigFetchTimeout)
+	defer cancel()
+
+	ref := r.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	steps := [][]string{
+		{"init", "--quiet", checkout},
+		{"-C", checkout, "fetch", "--quiet", "--depth", "1", "--", r.url, ref},
+		{"-C", checkout, "checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			message, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+
+			return nil, nil, fmt.Errorf("failed to fetch %s at %s: %s", r.url, ref, message)
+		}
+	}
+
+	filePath := filepath.Join(checkout, filepath.FromSlash(r.file))
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+
+		return nil, nil, fmt.Errorf("'%s' not found in %s at %s", r.file, r.url, ref)
+	}
+	if !withSignature {
+
+		return data, nil, nil
+	}
+	signature, err := os.ReadFile(filePath + ".sig")
+	if err != nil {
+
+		return nil, nil, fmt.Errorf("signature '%s.sig' not found in %s at %s", r.file, r.url, ref)
+	}
+
+	return data, signature, nil
+}
+
+func verifySignature(data, signature []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+
+		return fmt.Errorf("signing key must be a base64 ed25519 public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+
+		return fmt.Errorf("signature is not valid base64: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, sig) {
+
+		return fmt.Errorf("signature verification failed")
+	}
+
+	return nil
+}
+
+// ProjectDir is the directory a compose file's relative paths and .env belong to. Remote
+// files belong to the directory mcp-compose runs in, not the cache.
+func ProjectDir(filePath string) string {
+	if _, remote := remoteSources[filePath]; remote {
+
+		return "."
+	}
+
+	return filepath.Dir(filePath)
+}
+
+// DisplayName is how a compose file is referred to in messages: its URL when remote
+func DisplayName(filePath string) string {
+	if source, remote := remoteSources[filePath]; remote {
+
+		return source
+	}
+
+	return filePath
+}
@@ -0,0 +1,206 @@
+// internal/config/schema.go
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID identifies the JSON Schema of the compose file
+const SchemaID = "https://github.com/phildougherty/mcp-compose/mcp-compose.schema.json"
+
+// schemaEnums lists the allowed values of settings that take one of a fixed set of
+// strings, keyed by Go type and YAML field name
+var schemaEnums = map[string][]string{
+	"ServerConfig.protocol":      {"stdio", "http", "sse", "tcp"},
+	"ServerConfig.capabilities":  {"resources", "tools", "prompts", "sampling", "logging", "roots"},
+	"ServerConfig.stdio_sharing": {"shared", "per_request"},
+	"ServerConfig.pull_policy":   {PullAlways, PullMissing, PullNever},
+	"LifecycleConfig.shell":      HookShells,
+	"ConnectionConfig.transport": {"stdio", "http", "https", "tcp", "websocket", "http+sse"},
+	"MiddlewareConfig.type":      {"headers", "redact", "default_args", "trace"},
+	"LogFilterRule.action":       {"drop", "downgrade", "rate_limit"},
+	"ComposeConfig.isolation":    {IsolationShared, IsolationStrict},
+	"EgressConfig.default":       {EgressAllow, EgressDeny},
+	"MemoryBackup.format":        {MemoryDumpJSON, MemoryDumpSQL},
+	"DashboardConfig.theme":      {DashboardThemeDark, DashboardThemeLight},
+	"DeviceRequest.driver":       {"nvidia"},
+}
+
+// schemaPatterns constrains string settings with a fixed syntax
+var schemaPatterns = map[string]string{
+	"ServerConfig.restart":           restartPolicyPattern.String(),
+	"DeployConfig.restart_policy":    restartPolicyPattern.String(),
+	"DashboardBranding.accent_color": hexColorPattern.String(),
+	"ComposeConfig.platform":         platformPattern.String(),
+	"ServerConfig.platform":          platformPattern.String(),
+	"BuildConfig.platform":           platformPattern.String(),
+	"ServerOverrideConfig.platform":  platformPattern.String(),
+	"ServerConfig.gpus":              gpusPattern.String(),
+}
+
+// schemaTypes describes settings kept as raw YAML by the type they are decoded into
+var schemaTypes = map[string]reflect.Type{
+	"BuiltinOverride.server": reflect.TypeOf(ServerConfig{}),
+}
+
+// schemaScalarForms are structs and lists that also accept a scalar of the given type in place
+// of their mapping or list
+var schemaScalarForms = map[string]string{
+	"ExternalDependencyRef": "string",  // the name of a top-level external dependency
+	"EnvFileList":           "string",  // a single env file
+	"ImageScanConfig":       "boolean", // scan: true
+}
+
+// envReference matches values substituted from the environment when the file is loaded
+const envReference = `^\$(\{[A-Za-z_][A-Za-z0-9_]*([:]?[-=?+][^}]*)?\}|[A-Za-z_][A-Za-z0-9_]*)$`
+
+// JSONSchema returns a JSON Schema (draft-07) describing mcp-compose.yaml, for editors
+// such as yaml-language-server to validate and complete against
+func JSONSchema() ([]byte, error) {
+	g := &schemaGenerator{definitions: make(map[string]interface{})}
+	root := g.structSchema(reflect.TypeOf(ComposeConfig{}))
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["$id"] = SchemaID
+	root["title"] = "mcp-compose configuration"
+	root["required"] = []string{"version"}
+	root["definitions"] = g.definitions
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+// typeSchema describes a Go type, referencing named structs through definitions so
+// recursive types terminate
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+
+			return g.structSchema(t)
+		}
+		if _, exists := g.definitions[name]; !exists {
+			g.definitions[name] = true // placeholder while the struct is generated
+			g.definitions[name] = g.structSchema(t)
+			if scalar := schemaScalarForms[name]; scalar != "" {
+				g.definitions[name] = map[string]interface{}{"anyOf": []interface{}{
+					map[string]interface{}{"type": scalar},
+					g.definitions[name],
+				}}
+			}
+		}
+
+		return map[string]interface{}{"$ref": "#/definitions/" + name}
+	case reflect.Map:
+
+		return map[string]interface{}{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		schema := map[string]interface{}{"type": "array", "items": g.typeSchema(t.Elem())}
+		if scalar := schemaScalarForms[t.Name()]; scalar != "" {
+
+			return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": scalar}, schema}}
+		}
+
+		return schema
+	case reflect.String:
+
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+
+		return orEnvReference(map[string]interface{}{"type": "boolean"})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+
+		return orEnvReference(map[string]interface{}{"type": "integer"})
+	case reflect.Float32, reflect.Float64:
+
+		return orEnvReference(map[string]interface{}{"type": "number"})
+	default:
+
+		return map[string]interface{}{}
+	}
+}
+
+// orEnvReference also accepts ${VAR} for non-string settings, since the file is checked
+// by editors before environment variables are substituted
+func orEnvReference(schema map[string]interface{}) map[string]interface{} {
+
+	return map[string]interface{}{"anyOf": []interface{}{
+		schema,
+		map[string]interface{}{"type": "string", "pattern": envReference},
+	}}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	g.addProperties(t, properties)
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func (g *schemaGenerator) addProperties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" {
+
+			continue
+		}
+		if strings.Contains(options, "inline") {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				g.addProperties(fieldType, properties)
+			}
+
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		key := t.Name() + "." + name
+		fieldType := field.Type
+		if described, exists := schemaTypes[key]; exists {
+			fieldType = described
+		}
+		schema := g.typeSchema(fieldType)
+		if values, exists := schemaEnums[key]; exists {
+			if schema["type"] == "array" {
+				schema["items"] = map[string]interface{}{"type": "string", "enum": values}
+			} else {
+				schema["enum"] = values
+			}
+		}
+		if pattern, exists := schemaPatterns[key]; exists {
+			schema["pattern"] = pattern
+		}
+		properties[name] = schema
+	}
+}
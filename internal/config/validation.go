@@ -0,0 +1,226 @@
+// internal/config/validation.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ValidationError is one problem with a setting in the compose file
+type ValidationError struct {
+	Path    string // dotted path of the setting, e.g. servers.web.protocol
+	File    string // compose file the setting is in, when known
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 && e.Column > 0 {
+
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	if e.Line > 0 {
+
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+
+	return e.Message
+}
+
+// ValidationErrors is every problem found in a compose file
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+
+		return e[0].Error()
+	}
+	problems := make([]string, 0, len(e))
+	for _, problem := range e {
+		problems = append(problems, problem.Error())
+	}
+
+	return fmt.Sprintf("%d problems:\n  %s", len(e), strings.Join(problems, "\n  "))
+}
+
+// validation collects problems instead of stopping at the first one
+type validation struct {
+	errs ValidationErrors
+}
+
+func (v *validation) add(path string, err error) {
+	if err != nil {
+		v.errs = append(v.errs, ValidationError{Path: path, Message: err.Error()})
+	}
+}
+
+func (v *validation) addf(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validation) result() error {
+	if len(v.errs) == 0 {
+
+		return nil
+	}
+
+	return v.errs
+}
+
+// decodeConfig decodes a parsed compose document. Type mismatches don't stop decoding;
+// they are returned as ValidationErrors alongside the partially decoded config.
+func decodeConfig(document *yaml.Node) (ComposeConfig, ValidationErrors, error) {
+	var config ComposeConfig
+	if document.Kind == 0 {
+
+		return config, nil, nil
+	}
+	err := document.Decode(&config)
+	var typeErr *yaml.TypeError
+	if err == nil || !errors.As(err, &typeErr) {
+
+		return config, nil, err
+	}
+
+	var errs ValidationErrors
+	for _, message := range typeErr.Errors {
+		errs = append(errs, parseYAMLError(message))
+	}
+
+	return config, errs, nil
+}
+
+var yamlErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+func parseYAMLError(message string) ValidationError {
+	if match := yamlErrorLine.FindStringSubmatch(message); match != nil {
+		line, _ := strconv.Atoi(match[1])
+
+		return ValidationError{Line: line, Message: match[2]}
+	}
+
+	return ValidationError{Message: message}
+}
+
+// locate fills in the file, line and column of each error from the document it was found
+// in, using the closest enclosing setting when the one at fault is missing, and orders the
+// errors as they appear in the files
+func (e ValidationErrors) locate(document *yaml.Node, sources map[*yaml.Node]string, files []string) {
+	for i := range e {
+		switch {
+		case e[i].Line > 0 && e[i].Column == 0:
+			// YAML decoding errors only carry a line; point at the value on it
+			if node := nodeOnLine(document, e[i].Line); node != nil {
+				e[i].File, e[i].Column = sources[node], node.Column
+			}
+		case e[i].Line == 0 && e[i].Path != "":
+			if node := findNode(document, strings.Split(e[i].Path, ".")); node != nil {
+				e[i].File, e[i].Line, e[i].Column = sources[node], node.Line, node.Column
+			}
+		}
+	}
+	sort.SliceStable(e, func(i, j int) bool {
+		if e[i].Line == 0 || e[j].Line == 0 {
+
+			return e[i].Line != 0 && e[j].Line == 0
+		}
+		if e[i].File != e[j].File {
+
+			return fileOrder(files, e[i].File) < fileOrder(files, e[j].File)
+		}
+
+		return e[i].Line < e[j].Line
+	})
+}
+
+func fileOrder(files []string, file string) int {
+	for i, f := range files {
+		if f == file {
+
+			return i
+		}
+	}
+
+	return len(files)
+}
+
+// findNode returns the key (or sequence item) of the deepest setting along path
+func findNode(node *yaml.Node, path []string) *yaml.Node {
+	if node == nil {
+
+		return nil
+	}
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+
+			return nil
+		}
+
+		return findNode(node.Content[0], path)
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if len(path) == 0 {
+
+		return nil
+	}
+
+	var key, value *yaml.Node
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == path[0] {
+				key, value = node.Content[i], node.Content[i+1]
+
+				break
+			}
+		}
+	case yaml.SequenceNode:
+		if index, err := strconv.Atoi(path[0]); err == nil && index >= 0 && index < len(node.Content) {
+			key, value = node.Content[index], node.Content[index]
+		}
+	}
+	if key == nil {
+
+		return nil
+	}
+	if deeper := findNode(value, path[1:]); deeper != nil {
+
+		return deeper
+	}
+
+	return key
+}
+
+// nodeOnLine returns the last scalar on a line, which for "key: value" is the value
+func nodeOnLine(node *yaml.Node, line int) *yaml.Node {
+	var found *yaml.Node
+	if node.Kind == yaml.ScalarNode && node.Line == line {
+		found = node
+	}
+	for _, child := range node.Content {
+		if match := nodeOnLine(child, line); match != nil {
+			found = match
+		}
+	}
+
+	return found
+}
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -37,13 +37,14 @@ const (
 
 	// String parsing constants
 	StringSplitParts   = 2
-	LogFieldCount      = 6
 	RandomStringLength = 6
 
 	// File permissions
 	DefaultFileMode    = 0644
 	DefaultDirMode     = 0755
 	ExecutableFileMode = 0755
+	SecretFileMode     = 0600
+	SecretDirMode      = 0700
 
 	// WebSocket constants
 	WebSocketPingIntervalOld = 54 * time.Second
@@ -69,6 +70,10 @@ const (
 	// Port constants
 	DefaultProxyPort      = 9876
 	DefaultMemoryHTTPPort = 3001
+	DefaultDashboardPort  = 3001
+
+	// ProxyDrainTimeout is how long a proxy replaced by proxy --upgrade keeps serving open connections
+	ProxyDrainTimeout = 30 * time.Second
 
 	// Time conversion constants
 	NanosecondsToMilliseconds = 1e6
@@ -76,6 +81,241 @@ const (
 	// Table formatting constants
 	TableColumnSpacing = 2
 
+	// Live status view (top) constants
+	TopDefaultRefreshInterval = 2 * time.Second
+	TopMinRefreshInterval     = 500 * time.Millisecond
+	TopRecentLogLines         = 50
+	TopErrorColumnWidth       = 60
+
+	// CI harness constants
+	ServerReadyPollInterval  = 1 * time.Second
+	CIDefaultWaitTimeout     = 2 * time.Minute
+	CIScenarioRequestTimeout = 60 * time.Second
+	CIArtifactLogLines       = 1000
+	ScenarioMaxResponseSize  = 10 * 1024 * 1024
+
+	// Up --wait constants
+	UpWaitDefaultTimeout = 2 * time.Minute
+
+	// Rolling restart constants
+	RollingRestartTimeout = 60 * time.Second
+
+	// External dependency wait constants
+	ExternalDependencyTimeout      = 60 * time.Second
+	ExternalDependencyInterval     = 2 * time.Second
+	ExternalDependencyCheckTimeout = 5 * time.Second
+
+	// SSE replay and backend reconnect constants
+	SSEReplayBufferSize      = 500
+	SSEClientKeepAlive       = 15 * time.Second
+	SSEClientRetryHint       = 3 * time.Second
+	SSEReconnectInitialDelay = 1 * time.Second
+	SSEReconnectMaxDelay     = 30 * time.Second
+	SSEReconnectMaxAttempts  = 10
+
+	// Resource subscription constants
+	ResourceSubscriptionGrace = 30 * time.Second       // Subscriptions outlive a client's last event stream this long
+	ResourceChangeDebounce    = 250 * time.Millisecond // Watched files must be quiet this long before their changes are reported
+
+	// Locale constants
+	DefaultBuiltinTimezone = "America/New_York"
+
+	// Server registry constants
+	RegistryIndexURL     = "https://raw.githubusercontent.com/phildougherty/mcp-compose/main/internal/registry/servers.json"
+	RegistryCacheTTL     = 24 * time.Hour
+	RegistryFetchTimeout = 5 * time.Second
+	RegistryMaxIndexSize = 5 * 1024 * 1024
+
+	// Remote compose file constants
+	RemoteConfigFetchTimeout = 30 * time.Second
+	RemoteConfigMaxSize      = 1024 * 1024
+
+	// Config editor constants
+	ConfigEditorMaxSize = 1024 * 1024
+	ConfigBackupDir     = ".mcp-compose-backups"
+	ConfigBackupsKept   = 20
+	ConfigHistoryIndex  = "history.json"
+
+	// Identity forwarding constants
+	IdentityTokenHeader = "X-MCP-Identity"
+	IdentityTokenIssuer = "mcp-compose"
+	IdentityTokenTTL    = 5 * time.Minute
+	IdentityTokenIDSize = 16
+	JWTParts            = 3
+
+	// Device authorization grant constants
+	DeviceCodeTTL           = 10 * time.Minute
+	DevicePollInterval      = 5 * time.Second // Also what slow_down adds to the interval
+	DeviceVerificationPath  = "/oauth/device"
+	DeviceUserCodeGroupSize = 4 // User codes are shown as ABCD-EFGH
+
+	// OAuth consent constants
+	OAuthConsentsFile = ".mcp-compose/oauth-consents.json" // Scopes users granted each client, next to the compose file
+
+	// Signed access token constants
+	JWKSPath            = "/.well-known/jwks.json"
+	OAuthKeysDir        = ".mcp-compose/oauth-keys" // Keys access tokens are signed with, next to the compose file
+	OAuthKeyRotation    = 30 * 24 * time.Hour
+	RSAKeyBits          = 2048
+	ES256CoordinateSize = 32
+
+	// Support bundle constants
+	SupportBundleLogLines        = 500
+	SupportBundleProxyTimeout    = 10 * time.Second
+	SupportBundleMaxResponseSize = 10 * 1024 * 1024
+
+	// Listener TLS constants
+	ACMECacheDir         = ".mcp-compose/acme"
+	ACMEChallengeTimeout = 10 * time.Second
+
+	// Doctor constants
+	DoctorProbeImage     = "alpine:3"       // Runs the DNS and clock checks on the container runtime
+	DoctorCommandTimeout = 30 * time.Second // Longest a single check may take, pulling the probe image included
+	DoctorMaxClockSkew   = 5 * time.Second
+
+	// Volume management constants
+	VolumeManagedLabel  = "mcp-compose.managed"
+	VolumeBackupImage   = "alpine:3"
+	VolumeBackupDir     = "volume-backups"
+	VolumeBackupTimeout = 30 * time.Minute
+
+	// Memory backup constants
+	MemoryBackupDir     = "memory-backups"
+	MemoryBackupTimeout = 10 * time.Minute
+	MemoryDumpVersion   = 1
+
+	// Task API constants
+	TasksAPIMaxBodySize     = 1024 * 1024
+	TasksAPIPageSize        = 50
+	TasksAPIMaxPageSize     = 500
+	TasksAPIPreviewCount    = 5
+	TasksAPIMaxPreviewCount = 100
+
+	// Tool bridge constants
+	ToolBridgeMaxListPages = 20
+
+	// Admin API constants
+	AdminAPILogLines    = 100
+	AdminAPIMaxLogLines = 10000
+
+	// Control server constants
+	ControlServerName        = "mcp-compose-control"
+	ControlServerMaxBodySize = 1024 * 1024
+
+	// Sampling broker constants
+	SamplingProviderTimeout  = 60 * time.Second
+	SamplingApprovalTimeout  = 5 * time.Minute  // when human_control sets no timeout_seconds
+	SamplingDefaultMaxTokens = 1024             // when the server asks for none
+	SamplingRequestRetention = 1 * time.Hour    // reviewed and answered requests stay listed this long
+	SamplingMaxResponseSize  = 10 * 1024 * 1024 // of a provider's answer
+	SamplingAPIMaxBodySize   = 1024 * 1024
+
+	// Image pull constants
+	PullDefaultParallel = 4
+	PullTimeout         = 15 * time.Minute
+
+	// Egress gateway constants
+	EgressGatewayHost        = "mcp-compose-http-proxy"
+	EgressGatewayPort        = 3128
+	EgressGatewayDialTimeout = 10 * time.Second
+	EgressGatewayLookupDelay = time.Second
+
+	// Mock server constants
+	MockMaxMessageSize = 10 * 1024 * 1024
+	MockDefaultPort    = 8811
+
+	// Inspector constants
+	InspectorHistoryLimit  = 100
+	InspectorMaxToolsPages = 20
+
+	// Catalog cache constants
+	CatalogCacheDefaultTTL = 5 * time.Minute
+
+	// Response cache constants
+	ResponseCacheMaxEntries    = 1000
+	ResponseCacheMaxEntryBytes = 1 << 20 // Larger results are relayed but not cached
+
+	// Log filter constants
+	LogFilterDefaultWindow   = time.Minute
+	LogFilterDefaultBurst    = 1
+	LogFilterMaxTrackedLines = 10000
+
+	// Process supervisor constants
+	ProcessRestartInitialDelay = 1 * time.Second
+	ProcessRestartMaxDelay     = 30 * time.Second
+	ProcessStableRuntime       = 10 * time.Second
+	ProcessCrashLoopThreshold  = 5
+	ProcessStopTimeout         = 10 * time.Second
+
+	// Crash-loop detection and notification constants
+	CrashLoopMaxRestarts    = 5
+	CrashLoopWindow         = 10 * time.Minute
+	CrashLoopBackoff        = 5 * time.Minute
+	CrashLoopPollInterval   = 15 * time.Second
+	NotificationTimeout     = 10 * time.Second
+	NotificationDefaultSMTP = 587
+
+	// Authentication failure log constants
+	AuthFailureAlertInterval = 5 * time.Minute
+	AuthFailureAlertClients  = 1000 // addresses alerts are held back for before quiet ones are forgotten
+
+	// Event bus constants
+	EventHistorySize      = 500
+	EventSubscriberBuffer = 256
+	EventStreamRetryDelay = 5 * time.Second
+
+	// Dashboard login constants
+	DashboardSessionTimeout = 1 * time.Hour
+	DashboardOAuthStateTTL  = 10 * time.Minute
+	DashboardSessionCookie  = "mcp_dashboard_session"
+	DashboardCSRFCookie     = "mcp_dashboard_csrf" // read by the dashboard's scripts to send as X-CSRF-Token
+
+	// OAuth sign-in constants
+	OAuthSessionCookie   = "mcp_compose_oauth_session"
+	OAuthSessionTTL      = 8 * time.Hour
+	OAuthSessionIDSize   = 32
+	OAuthPendingLoginTTL = 5 * time.Minute // to enter the TOTP code once the password was right
+	OAuthMaxTOTPAttempts = 5               // wrong codes before the password is asked for again
+	OAuthCSRFCookie      = "mcp_compose_oauth_csrf"
+
+	// Browser-facing page security constants
+	CSRFTokenSize = 32
+	HSTSMaxAge    = 365 * 24 * time.Hour
+
+	// TOTP second factor constants (RFC 6238)
+	TOTPSecretSize = 20
+	TOTPDigits     = 6
+	TOTPModulus    = 1000000 // 10^TOTPDigits
+	TOTPPeriod     = 30 * time.Second
+	TOTPSkewSteps  = 1 // codes of the step before and after are accepted too
+	TOTPOffsetMask = 0x0f
+
+	// OAuth brute-force protection defaults
+	OAuthMaxAuthFailures = 5 // failed sign-ins or client authentications within the window before a lockout
+	OAuthFailureWindow   = 15 * time.Minute
+	OAuthLockout         = time.Minute // doubled for each lockout in a row
+	OAuthMaxLockout      = time.Hour
+
+	// Dashboard proxy call constants
+	DashboardMaxResponseSize     = 1024 * 1024
+	DashboardServerActionTimeout = 5 * time.Minute // starting a server may pull its image
+
+	// Dashboard theme and branding constants
+	DashboardThemeCookie       = "mcp_dashboard_theme"
+	DashboardThemeCookieMaxAge = 365 * 24 * 60 * 60 // seconds a picked theme is remembered
+	DashboardBrandingCacheAge  = 5 * 60             // seconds browsers may cache the logo and favicon
+
+	// Process log capture constants
+	ProcessLogMaxSize        = 10 * 1024 * 1024
+	ProcessLogMaxFiles       = 5
+	ProcessLogMaxLineLength  = 64 * 1024
+	ProcessLogFollowInterval = 250 * time.Millisecond
+
+	// Trace export constants
+	TraceExportInterval  = 5 * time.Second
+	TraceExportBatchSize = 512
+	TraceExportQueueSize = 4096
+
 	// Additional timeout constants
 	WebSocketReadTimeout = 60 * time.Second
 	MaxIdleTime          = 10 * time.Minute
@@ -119,6 +359,7 @@ const (
 
 	// ID generation
 	IDGenerationBase = 10
+	TraceIDBytes     = 16
 
 	// HTTP status codes
 	HTTPStatusNotFound = 404
@@ -132,6 +373,7 @@ const (
 	DefaultWorkspacePath     = "/home/phil"
 	DefaultDatabasePath      = "/data/task-scheduler.db"
 	DefaultHostInterface     = "0.0.0.0"
+	LoopbackHostInterface    = "127.0.0.1"
 	DefaultLogLevel          = "info"
 	DefaultGoModulesProxy    = "proxy.golang.org"
 
@@ -143,6 +385,12 @@ const (
 	ContainerStartRetryDelay  = 2 * time.Second
 	ContainerHealthCheckDelay = 1 * time.Second
 
+	// Health check constants
+	HealthCheckMaxResponseSize = 1024 * 1024
+	HealthCheckDefaultRetries  = 3
+	HealthCheckMaxToolsPages   = 20
+	HealthCheckHistorySize     = 20
+
 	// WebSocket write timeout
 	WebSocketWriteDeadline = 5 * time.Second
 
@@ -167,6 +415,52 @@ const (
 	HTTPErrorBufferSize    = 256
 	HTTPLogBufferSize      = 512
 
+	// Streamed resources/read responses
+	ResponseStreamChunkSize = 64 * 1024
+	ResponseSpillThreshold  = 16 << 20 // Buffered responses larger than this go to a temp file
+	ResponseErrorBodyLimit  = 64 * 1024
+
+	// Per-server request queues (max_concurrent)
+	RequestQueuePerSlot = 8 // Default max_queued per max_concurrent slot
+	RequestQueueMaxWait = 60 * time.Second
+
+	// JSON-RPC batches
+	BatchMaxRequests    = 100 // Requests allowed in one batch
+	BatchMaxConcurrency = 8   // Requests of a batch forwarded at once
+
+	// Per-client usage analytics
+	AnalyticsBytesPerToken     = 4    // Rough size of a model token, for estimating token usage
+	AnalyticsTopTools          = 10   // Busiest tools listed per client
+	AnalyticsMaxClients        = 1000 // Clients tracked before the rest are counted together
+	AnalyticsMaxToolsPerClient = 500  // Tools tracked per client before the rest are counted together
+
+	// Cost tracking
+	CostRetentionDays = 90 // Days of costs kept in memory
+	CostReportDays    = 30 // Days /api/costs covers by default
+
+	// Quotas
+	QuotaStateFile    = ".mcp-compose/quotas.json" // Month's quota usage, next to the compose file
+	QuotaSaveInterval = 30 * time.Second           // Most often quota usage is written to disk
+
+	// Project state
+	ProjectStateFile   = ".mcp-compose/state.json" // What up started, next to the compose file
+	ProjectLabel       = "mcp-compose.project"     // Container label naming the project that started it
+	ProjectConfigLabel = "mcp-compose.config"      // Container label holding the compose file that started it
+	ProjectServerLabel = "mcp-compose.server"      // Container label naming the server it runs
+	ManagedByLabel     = "mcp-compose.managed-by"  // Set to ManagedBy on the containers, networks and volumes mcp-compose creates
+	ManagedBy          = "mcp-compose"
+	ConfigHashLabel    = "mcp-compose.config-hash" // Container label with a hash of the server config it was started with
+
+	// Init jobs
+	InitJobsDir    = ".mcp-compose/init" // Logs and results of the last init job runs, next to the compose file
+	InitJobLogTail = 20                  // Lines of a failed job's log printed with its error
+
+	// Image vulnerability scans
+	ImageScanCacheFile   = ".mcp-compose/image-scans.json" // Scan results by image digest, next to the compose file
+	ImageScanCacheMaxAge = 24 * time.Hour                  // Results older than this are scanned again, as new vulnerabilities are published
+	ImageScanTimeout     = 10 * time.Minute                // The first scan downloads the scanner's vulnerability database
+	ImageScanCacheKeep   = 30 * 24 * time.Hour             // Results of images not scanned again for this long are dropped
+
 	// Retry and backoff
 	RetryBackoffBase       = 2
 	RetryBackoffMultiplier = 3
@@ -221,6 +515,7 @@ const (
 	// Additional timeout constants
 	HTTPClientTimeout            = 60 * time.Second
 	LifecycleTimeout             = 5 * time.Minute
+	InitJobTimeout               = 5 * time.Minute
 	PingTimeout                  = 30 * time.Second
 	KeepAlivePeriod              = 15 * time.Second
 	WriteDeadlineTimeout         = 60 * time.Second
@@ -8,9 +8,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 )
 
@@ -186,6 +188,36 @@ func (d *DockerRuntime) ShowContainerLogs(name string, follow bool) error {
 	return cmd.Run()
 }
 
+// GetContainerExitCode returns the exit code of a stopped container
+func (d *DockerRuntime) GetContainerExitCode(name string) (int, error) {
+	cmd := exec.Command(d.execPath, "inspect", "--format", "{{.State.ExitCode}}", name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+
+		return -1, fmt.Errorf("failed to inspect container '%s': %w", name, err)
+	}
+
+	code, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+
+		return -1, fmt.Errorf("failed to parse exit code for container '%s': %w", name, err)
+	}
+
+	return code, nil
+}
+
+// GetContainerLogs returns the last tail lines of a container's combined output
+func (d *DockerRuntime) GetContainerLogs(name string, tail int) ([]string, error) {
+	cmd := exec.Command(d.execPath, "logs", "--tail", strconv.Itoa(tail), name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to get logs for container '%s': %w", name, err)
+	}
+
+	return splitLogLines(output), nil
+}
+
 func (d *DockerRuntime) NetworkExists(name string) (bool, error) {
 	cmd := exec.Command(d.execPath, "network", "inspect", name)
 	// If `Run` returns an error, the network likely doesn't exist or cannot be inspected.
@@ -196,7 +228,14 @@ func (d *DockerRuntime) NetworkExists(name string) (bool, error) {
 }
 
 func (d *DockerRuntime) CreateNetwork(name string) error {
-	cmd := exec.Command(d.execPath, "network", "create", name)
+
+	return d.createNetwork(name, nil)
+}
+
+// createNetwork creates a network with labels
+func (d *DockerRuntime) createNetwork(name string, labels map[string]string) error {
+	args := append([]string{"network", "create"}, labelArgs(labels)...)
+	cmd := exec.Command(d.execPath, append(args, name)...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		// Check if the error is because the network already exists
@@ -213,6 +252,23 @@ func (d *DockerRuntime) CreateNetwork(name string) error {
 	return nil
 }
 
+// CreateInternalNetwork creates a network without outbound access
+func (d *DockerRuntime) CreateInternalNetwork(name string) error {
+	cmd := exec.Command(d.execPath, "network", "create", "--internal", name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "already exists") {
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to create internal network '%s': %w, output: %s", name, err, string(output))
+	}
+	fmt.Printf("Internal network '%s' created.\n", name)
+
+	return nil
+}
+
 // ADD these methods to DockerRuntime:
 
 func (d *DockerRuntime) RestartContainer(name string) error {
@@ -239,33 +295,14 @@ func (d *DockerRuntime) UnpauseContainer(name string) error {
 }
 
 func (d *DockerRuntime) GetContainerInfo(name string) (*ContainerInfo, error) {
-	format := `{
-        "ID": "{{.Id}}",
-        "Name": "{{.Name}}",
-        "Image": "{{.Config.Image}}",
-        "Status": "{{.State.Status}}",
-        "State": "{{.State.Status}}",
-        "Created": "{{.Created}}",
-        "Command": {{json .Config.Cmd}},
-        "Labels": {{json .Config.Labels}},
-        "Env": {{json .Config.Env}},
-        "RestartCount": {{.RestartCount}}
-    }`
-
-	cmd := exec.Command(d.execPath, "inspect", "--format", format, name)
-	output, err := cmd.CombinedOutput()
+	cmd := exec.Command(d.execPath, "inspect", "--type", "container", name)
+	output, err := cmd.Output()
 	if err != nil {
 
 		return nil, fmt.Errorf("failed to inspect container '%s': %w", name, err)
 	}
 
-	var info ContainerInfo
-	if err := json.Unmarshal(output, &info); err != nil {
-
-		return nil, fmt.Errorf("failed to parse container info: %w", err)
-	}
-
-	return &info, nil
+	return parseContainerInspect(name, output)
 }
 
 func (d *DockerRuntime) ListContainers(filters map[string]string) ([]ContainerInfo, error) {
@@ -289,23 +326,42 @@ func (d *DockerRuntime) ListContainers(filters map[string]string) ([]ContainerIn
 
 			continue
 		}
-		var container ContainerInfo
-		if err := json.Unmarshal([]byte(line), &container); err != nil {
+		// Docker prints names and labels as comma-separated strings
+		var raw struct {
+			ID     string
+			Names  string
+			Image  string
+			State  string
+			Status string
+			Labels string
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
 
 			continue // Skip malformed entries
 		}
-		containers = append(containers, container)
+		name, _, _ := strings.Cut(raw.Names, ",")
+		containers = append(containers, ContainerInfo{
+			ID:     raw.ID,
+			Name:   name,
+			Image:  raw.Image,
+			State:  raw.State,
+			Status: raw.Status,
+			Labels: parseLabelList(raw.Labels),
+		})
 	}
 
 	return containers, nil
 }
 
-func (d *DockerRuntime) PullImage(image string, auth *ImageAuth) error {
+func (d *DockerRuntime) PullImage(image, platform string, auth *ImageAuth) error {
 	args := []string{"pull"}
 	if auth != nil {
 		// Add authentication if provided
 		args = append(args, "--username", auth.Username, "--password", auth.Password)
 	}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
 	args = append(args, image)
 
 	cmd := exec.Command(d.execPath, args...)
@@ -374,19 +430,13 @@ func (d *DockerRuntime) BuildImage(opts *BuildOptions) error {
 
 func (d *DockerRuntime) GetContainerStats(name string) (*ContainerStats, error) {
 	cmd := exec.Command(d.execPath, "stats", "--no-stream", "--format", "json", name)
-	output, err := cmd.CombinedOutput()
+	output, err := cmd.Output()
 	if err != nil {
 
 		return nil, fmt.Errorf("failed to get stats for container '%s': %w", name, err)
 	}
 
-	var stats ContainerStats
-	if err := json.Unmarshal(output, &stats); err != nil {
-
-		return nil, fmt.Errorf("failed to parse stats: %w", err)
-	}
-
-	return &stats, nil
+	return parseContainerStats(name, output)
 }
 
 func (d *DockerRuntime) ValidateSecurityContext(opts *ContainerOptions) error {
@@ -607,12 +657,17 @@ func (d *DockerRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 		return "", fmt.Errorf("no image specified or could be built for server '%s'", opts.Name)
 	}
 
-	// Pull image if requested AND no build was performed
-	if opts.Pull && opts.Build.Context == "" {
-		fmt.Printf("Pulling image '%s'...\n", imageToRun)
-		if err := d.PullImage(imageToRun, nil); err != nil {
+	// Apply the pull policy unless the image was just built
+	if opts.Build.Context == "" {
+		if err := ensureImage(d, opts, imageToRun); err != nil {
 
-			return "", fmt.Errorf("failed to pull image '%s': %w", imageToRun, err)
+			return "", err
+		}
+	}
+	if opts.ImageCheck != nil {
+		if err := opts.ImageCheck(imageToRun); err != nil {
+
+			return "", err
 		}
 	}
 
@@ -624,11 +679,11 @@ func (d *DockerRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 	}
 
 	// Ensure networks exist
-	networkName := "mcp-net"
+	networkName := config.DefaultNetwork()
 	if d.GetRuntimeName() != "none" {
 		networkExists, _ := d.NetworkExists(networkName)
 		if !networkExists {
-			if err := d.CreateNetwork(networkName); err != nil {
+			if err := d.createNetwork(networkName, networkLabels(opts.Labels)); err != nil {
 				fmt.Printf("Warning: Failed to create default network %s: %v.\n", networkName, err)
 			} else {
 				fmt.Printf("Created Docker network: %s\n", networkName)
@@ -660,11 +715,20 @@ func (d *DockerRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 	if opts.PidsLimit > 0 {
 		runArgs = append(runArgs, "--pids-limit", fmt.Sprintf("%d", opts.PidsLimit))
 	}
+	if opts.GPUs != "" {
+		runArgs = append(runArgs, "--gpus", dockerGPUFlag(opts.GPUs))
+	}
 
 	// Security options
 	if opts.User != "" {
 		runArgs = append(runArgs, "--user", opts.User)
 	}
+	for _, group := range opts.Groups {
+		runArgs = append(runArgs, "--group-add", group)
+	}
+	if opts.UsernsMode != "" {
+		runArgs = append(runArgs, "--userns", opts.UsernsMode)
+	}
 	if opts.Privileged {
 		runArgs = append(runArgs, "--privileged")
 	}
@@ -721,9 +785,7 @@ func (d *DockerRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 	}
 
 	// Labels
-	for k, v := range opts.Labels {
-		runArgs = append(runArgs, "--label", fmt.Sprintf("%s=%s", k, v))
-	}
+	runArgs = append(runArgs, labelArgs(managedLabels(opts.Labels))...)
 
 	// Health check
 	if opts.HealthCheck != nil {
@@ -816,7 +878,7 @@ func (d *DockerRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 		if net != primaryNetworkConnected && net != "" {
 			exists, _ := d.NetworkExists(net)
 			if !exists {
-				if errNetCreate := d.CreateNetwork(net); errNetCreate != nil {
+				if errNetCreate := d.createNetwork(net, networkLabels(opts.Labels)); errNetCreate != nil {
 					fmt.Printf("Warning: Failed to create additional network %s for container %s: %v\n", net, opts.Name, errNetCreate)
 
 					continue
@@ -934,11 +996,25 @@ func (d *DockerRuntime) ListVolumes() ([]VolumeInfo, error) {
 
 			continue
 		}
-		var volume VolumeInfo
-		if err := json.Unmarshal([]byte(line), &volume); err != nil {
+		// Docker prints labels as one "key=value,key=value" string
+		var raw struct {
+			Name       string
+			Driver     string
+			Mountpoint string
+			Scope      string
+			Labels     string
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
 
 			continue // Skip malformed entries
 		}
+		volume := VolumeInfo{
+			Name:       raw.Name,
+			Driver:     raw.Driver,
+			Mountpoint: raw.Mountpoint,
+			Scope:      raw.Scope,
+			Labels:     parseLabelList(raw.Labels),
+		}
 		volumes = append(volumes, volume)
 	}
 
@@ -983,6 +1059,17 @@ func (d *DockerRuntime) ListImages() ([]ImageInfo, error) {
 	return images, nil
 }
 
+func (d *DockerRuntime) GetImageInfo(image string) (*ImageInfo, error) {
+	cmd := exec.Command(d.execPath, "image", "inspect", image)
+	output, err := cmd.Output()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to inspect image '%s': %w", image, err)
+	}
+
+	return parseImageInspect(image, output)
+}
+
 func (d *DockerRuntime) ListNetworks() ([]NetworkInfo, error) {
 	cmd := exec.Command(d.execPath, "network", "ls", "--format", "json")
 	output, err := cmd.CombinedOutput()
@@ -998,12 +1085,27 @@ func (d *DockerRuntime) ListNetworks() ([]NetworkInfo, error) {
 
 			continue
 		}
-		var network NetworkInfo
-		if err := json.Unmarshal([]byte(line), &network); err != nil {
+		// Docker prints booleans and labels as strings here
+		var raw struct {
+			ID       string
+			Name     string
+			Driver   string
+			Scope    string
+			Internal string
+			Labels   string
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
 
 			continue // Skip malformed entries
 		}
-		networks = append(networks, network)
+		networks = append(networks, NetworkInfo{
+			ID:       raw.ID,
+			Name:     raw.Name,
+			Driver:   raw.Driver,
+			Scope:    raw.Scope,
+			Internal: raw.Internal == "true",
+			Labels:   parseLabelList(raw.Labels),
+		})
 	}
 
 	return networks, nil
@@ -0,0 +1,48 @@
+// internal/container/gpu.go
+package container
+
+import (
+	"strconv"
+	"strings"
+)
+
+// cdiGPUKind is the CDI device kind the NVIDIA Container Toolkit registers GPUs under
+const cdiGPUKind = "nvidia.com/gpu"
+
+// dockerGPUFlag turns a gpus setting ("all", a count or "device=0,1") into the value of
+// docker run --gpus. Docker splits the value on commas, so a list of devices is quoted.
+func dockerGPUFlag(gpus string) string {
+	if strings.HasPrefix(gpus, "device=") && strings.Contains(gpus, ",") {
+
+		return `"` + gpus + `"`
+	}
+
+	return gpus
+}
+
+// CDIGPUDevices turns a gpus setting into the CDI devices Podman passes through with
+// --device: nvidia.com/gpu=all, or one per GPU index or UUID
+func CDIGPUDevices(gpus string) []string {
+	if gpus == "" {
+
+		return nil
+	}
+	if gpus == "all" {
+
+		return []string{cdiGPUKind + "=all"}
+	}
+	var ids []string
+	if list, ok := strings.CutPrefix(gpus, "device="); ok {
+		ids = strings.Split(list, ",")
+	} else if count, err := strconv.Atoi(gpus); err == nil {
+		for i := range count {
+			ids = append(ids, strconv.Itoa(i))
+		}
+	}
+	devices := make([]string, 0, len(ids))
+	for _, id := range ids {
+		devices = append(devices, cdiGPUKind+"="+id)
+	}
+
+	return devices
+}
@@ -0,0 +1,33 @@
+package container
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGPUFlags(t *testing.T) {
+	tests := []struct {
+		gpus    string
+		docker  string
+		devices []string
+	}{
+		{gpus: "all", docker: "all", devices: []string{"nvidia.com/gpu=all"}},
+		{gpus: "2", docker: "2", devices: []string{"nvidia.com/gpu=0", "nvidia.com/gpu=1"}},
+		{gpus: "device=1", docker: "device=1", devices: []string{"nvidia.com/gpu=1"}},
+		{gpus: "device=0,GPU-3f2a", docker: `"device=0,GPU-3f2a"`, devices: []string{"nvidia.com/gpu=0", "nvidia.com/gpu=GPU-3f2a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gpus, func(t *testing.T) {
+			if flag := dockerGPUFlag(tt.gpus); flag != tt.docker {
+				t.Errorf("dockerGPUFlag(%q) = %q, want %q", tt.gpus, flag, tt.docker)
+			}
+			if devices := CDIGPUDevices(tt.gpus); !slices.Equal(devices, tt.devices) {
+				t.Errorf("CDIGPUDevices(%q) = %v, want %v", tt.gpus, devices, tt.devices)
+			}
+		})
+	}
+
+	if devices := CDIGPUDevices(""); devices != nil {
+		t.Errorf("no gpus should pass no devices, got %v", devices)
+	}
+}
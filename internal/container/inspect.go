@@ -0,0 +1,163 @@
+// internal/container/inspect.go
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// containerInspect is the part of `docker inspect` and `podman inspect` output for a
+// container that ContainerInfo carries; both runtimes use the same field names
+type containerInspect struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	Image        string `json:"Image"` // image ID
+	Created      string `json:"Created"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		Status string `json:"Status"`
+		Health *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Cmd    []string          `json:"Cmd"`
+		Env    []string          `json:"Env"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	Mounts          []MountInfo `json:"Mounts"`
+	NetworkSettings struct {
+		Ports map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"Ports"`
+		Networks map[string]struct {
+			EndpointID        string `json:"EndpointID"`
+			MacAddress        string `json:"MacAddress"`
+			IPAddress         string `json:"IPAddress"`
+			GlobalIPv6Address string `json:"GlobalIPv6Address"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// parseContainerInspect converts the JSON array printed by `inspect` for one container
+func parseContainerInspect(name string, output []byte) (*ContainerInfo, error) {
+	var inspected []containerInspect
+	if err := json.Unmarshal(output, &inspected); err != nil {
+
+		return nil, fmt.Errorf("failed to parse container info: %w", err)
+	}
+	if len(inspected) == 0 {
+
+		return nil, fmt.Errorf("container '%s' not found", name)
+	}
+	raw := inspected[0]
+
+	info := &ContainerInfo{
+		ID:           raw.ID,
+		Name:         raw.Name,
+		Image:        raw.Config.Image,
+		ImageID:      raw.Image,
+		Status:       raw.State.Status,
+		State:        raw.State.Status,
+		Created:      raw.Created,
+		Ports:        []PortBinding{},
+		Mounts:       raw.Mounts,
+		Networks:     make(map[string]NetworkEndpoint, len(raw.NetworkSettings.Networks)),
+		Labels:       raw.Config.Labels,
+		Env:          raw.Config.Env,
+		Command:      raw.Config.Cmd,
+		RestartCount: raw.RestartCount,
+	}
+	if raw.State.Health != nil {
+		info.Health = raw.State.Health.Status
+	}
+	if info.Mounts == nil {
+		info.Mounts = []MountInfo{}
+	}
+
+	for port, bindings := range raw.NetworkSettings.Ports {
+		number, protocol, _ := strings.Cut(port, "/")
+		privatePort, _ := strconv.Atoi(number)
+		if len(bindings) == 0 {
+			info.Ports = append(info.Ports, PortBinding{PrivatePort: privatePort, Type: protocol})
+
+			continue
+		}
+		for _, binding := range bindings {
+			publicPort, _ := strconv.Atoi(binding.HostPort)
+			info.Ports = append(info.Ports, PortBinding{
+				PrivatePort: privatePort,
+				PublicPort:  publicPort,
+				Type:        protocol,
+				IP:          binding.HostIP,
+			})
+		}
+	}
+	sort.Slice(info.Ports, func(i, j int) bool {
+		if info.Ports[i].PrivatePort != info.Ports[j].PrivatePort {
+
+			return info.Ports[i].PrivatePort < info.Ports[j].PrivatePort
+		}
+
+		return info.Ports[i].IP < info.Ports[j].IP
+	})
+
+	for network, endpoint := range raw.NetworkSettings.Networks {
+		info.Networks[network] = NetworkEndpoint{
+			EndpointID:  endpoint.EndpointID,
+			MacAddress:  endpoint.MacAddress,
+			IPv4Address: endpoint.IPAddress,
+			IPv6Address: endpoint.GlobalIPv6Address,
+		}
+	}
+
+	return info, nil
+}
+
+// imageInspect is the part of `docker image inspect` and `podman image inspect` output
+// that ImageInfo carries
+type imageInspect struct {
+	ID          string   `json:"Id"`
+	RepoTags    []string `json:"RepoTags"`
+	RepoDigests []string `json:"RepoDigests"`
+	Size        int64    `json:"Size"`
+	Created     string   `json:"Created"`
+	Os          string   `json:"Os"`
+	Arch        string   `json:"Architecture"`
+	Variant     string   `json:"Variant"`
+	Config      struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// parseImageInspect converts the JSON array printed by `image inspect` for one image
+func parseImageInspect(image string, output []byte) (*ImageInfo, error) {
+	var inspected []imageInspect
+	if err := json.Unmarshal(output, &inspected); err != nil {
+
+		return nil, fmt.Errorf("failed to parse image info: %w", err)
+	}
+	if len(inspected) == 0 {
+
+		return nil, fmt.Errorf("image '%s' not found", image)
+	}
+	raw := inspected[0]
+
+	return &ImageInfo{
+		ID:      raw.ID,
+		Tags:    raw.RepoTags,
+		Digests: raw.RepoDigests,
+		Size:    raw.Size,
+		Created: raw.Created,
+		Labels:  raw.Config.Labels,
+
+		OS:           raw.Os,
+		Architecture: raw.Arch,
+		Variant:      raw.Variant,
+	}, nil
+}
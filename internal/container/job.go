@@ -0,0 +1,81 @@
+// internal/container/job.go
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+)
+
+// RunOnce runs a container to completion and returns what it printed and its exit code.
+// The container is removed when it exits, and stopped and removed when ctx ends first.
+// Only the settings a one-shot job needs are honored: image, command, environment,
+// volumes, networks, working directory, user and platform.
+func RunOnce(ctx context.Context, rt Runtime, opts *ContainerOptions) ([]byte, int, error) {
+	runtimeName := rt.GetRuntimeName()
+	if runtimeName == "none" {
+
+		return nil, -1, fmt.Errorf("no container runtime available, cannot run container '%s'", opts.Name)
+	}
+	if err := ensureImage(rt, opts, opts.Image); err != nil {
+
+		return nil, -1, err
+	}
+	// A container left over from a run that was interrupted would block the name
+	_ = exec.Command(runtimeName, "rm", "-f", opts.Name).Run()
+
+	args := []string{"run", "--rm", "--name", opts.Name}
+	keys := make([]string, 0, len(opts.Env))
+	for key := range opts.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-e", key+"="+opts.Env[key])
+	}
+	for _, volume := range opts.Volumes {
+		args = append(args, "-v", volume)
+	}
+	if opts.NetworkMode != "" {
+		args = append(args, "--network", opts.NetworkMode)
+	} else {
+		for _, network := range opts.Networks {
+			args = append(args, "--network", network)
+		}
+	}
+	if opts.WorkDir != "" {
+		args = append(args, "-w", opts.WorkDir)
+	}
+	if opts.User != "" {
+		args = append(args, "--user", opts.User)
+	}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+	args = append(args, opts.Image)
+	if opts.Command != "" {
+		args = append(args, opts.Command)
+	}
+	args = append(args, opts.Args...)
+
+	cmd := exec.CommandContext(ctx, runtimeName, args...)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		_ = exec.Command(runtimeName, "rm", "-f", opts.Name).Run()
+
+		return output, -1, fmt.Errorf("container '%s' did not finish in time: %w", opts.Name, ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+
+		return output, exitErr.ExitCode(), nil
+	}
+	if err != nil {
+
+		return output, -1, fmt.Errorf("failed to run container '%s': %w", opts.Name, err)
+	}
+
+	return output, 0, nil
+}
@@ -0,0 +1,56 @@
+// internal/container/labels.go
+package container
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// managedLabels returns a container's labels with the one marking it as created by mcp-compose
+func managedLabels(labels map[string]string) map[string]string {
+	merged := make(map[string]string, len(labels)+1)
+	for key, value := range labels {
+		merged[key] = value
+	}
+	merged[constants.ManagedByLabel] = constants.ManagedBy
+
+	return merged
+}
+
+// networkLabels returns the labels a network created for a container carries: the project
+// labels of the container, without those naming its server or describing its config
+func networkLabels(containerLabels map[string]string) map[string]string {
+	labels := map[string]string{constants.ManagedByLabel: constants.ManagedBy}
+	for _, key := range []string{constants.ProjectLabel, constants.ProjectConfigLabel} {
+		if value, exists := containerLabels[key]; exists {
+			labels[key] = value
+		}
+	}
+
+	return labels
+}
+
+// labelArgs renders labels as --label flags
+func labelArgs(labels map[string]string) []string {
+	args := make([]string, 0, 2*len(labels))
+	for key, value := range labels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", key, value))
+	}
+
+	return args
+}
+
+// parseLabelList parses the "key=value,key=value" form `docker ps` and `docker network ls`
+// print labels in
+func parseLabelList(list string) map[string]string {
+	labels := make(map[string]string)
+	for _, label := range strings.Split(list, ",") {
+		if key, value, found := strings.Cut(label, "="); found {
+			labels[key] = value
+		}
+	}
+
+	return labels
+}
@@ -0,0 +1,29 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+func TestNetworkLabels(t *testing.T) {
+	containerLabels := managedLabels(map[string]string{
+		constants.ProjectLabel:       "work",
+		constants.ProjectConfigLabel: "/srv/work/mcp-compose.yaml",
+		constants.ProjectServerLabel: "search",
+		constants.ConfigHashLabel:    "3f2a9c01d4e5",
+	})
+	if containerLabels[constants.ManagedByLabel] != constants.ManagedBy {
+		t.Errorf("managedLabels() = %v, missing %s", containerLabels, constants.ManagedByLabel)
+	}
+
+	labels := networkLabels(containerLabels)
+	if len(labels) != 3 || labels[constants.ProjectLabel] != "work" || labels[constants.ManagedByLabel] != constants.ManagedBy {
+		t.Errorf("networkLabels() = %v, want the managed-by and project labels only", labels)
+	}
+
+	parsed := parseLabelList("mcp-compose.project=work,mcp-compose.config-hash=3f2a9c01d4e5,broken")
+	if len(parsed) != 2 || parsed[constants.ConfigHashLabel] != "3f2a9c01d4e5" {
+		t.Errorf("parseLabelList() = %v", parsed)
+	}
+}
@@ -46,6 +46,16 @@ func (n *NullRuntime) ShowContainerLogs(name string, follow bool) error {
 	return fmt.Errorf("no container runtime available, cannot show logs for container '%s'", name)
 }
 
+func (n *NullRuntime) GetContainerLogs(name string, tail int) ([]string, error) {
+
+	return nil, fmt.Errorf("no container runtime available, cannot get logs for container '%s'", name)
+}
+
+func (n *NullRuntime) GetContainerExitCode(name string) (int, error) {
+
+	return -1, fmt.Errorf("no container runtime available, cannot get exit code for container '%s'", name)
+}
+
 func (n *NullRuntime) NetworkExists(name string) (bool, error) {
 
 	return false, fmt.Errorf("no container runtime available, cannot check network '%s'", name)
@@ -56,6 +66,11 @@ func (n *NullRuntime) CreateNetwork(name string) error {
 	return fmt.Errorf("no container runtime available, cannot create network '%s'", name)
 }
 
+func (n *NullRuntime) CreateInternalNetwork(name string) error {
+
+	return fmt.Errorf("no container runtime available, cannot create network '%s'", name)
+}
+
 // ExecContainer executes a command in a running container
 func (n *NullRuntime) ExecContainer(containerName string, command []string, interactive bool) (*exec.Cmd, io.Writer, io.Reader, error) {
 
@@ -87,7 +102,7 @@ func (n *NullRuntime) ListContainers(filters map[string]string) ([]ContainerInfo
 	return nil, fmt.Errorf("no container runtime available, cannot list containers")
 }
 
-func (n *NullRuntime) PullImage(image string, auth *ImageAuth) error {
+func (n *NullRuntime) PullImage(image, platform string, auth *ImageAuth) error {
 
 	return fmt.Errorf("no container runtime available, cannot pull image '%s'", image)
 }
@@ -102,6 +117,11 @@ func (n *NullRuntime) RemoveImage(image string, force bool) error {
 	return fmt.Errorf("no container runtime available, cannot remove image '%s'", image)
 }
 
+func (n *NullRuntime) GetImageInfo(image string) (*ImageInfo, error) {
+
+	return nil, fmt.Errorf("no container runtime available, cannot inspect image '%s'", image)
+}
+
 func (n *NullRuntime) ListImages() ([]ImageInfo, error) {
 
 	return nil, fmt.Errorf("no container runtime available, cannot list images")
@@ -7,18 +7,37 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
 // PodmanRuntime implements container runtime using Podman
 type PodmanRuntime struct {
 	execPath string
+	rootless bool
+	socket   string // the Podman API socket, when one is listening
 }
 
-// NewPodmanRuntime creates a Podman runtime
+// NewPodmanRuntime creates a Podman runtime, finding out whether Podman runs rootless and
+// where its API socket is
 func NewPodmanRuntime(path string) (Runtime, error) {
+	p := &PodmanRuntime{execPath: path}
+	output, err := exec.Command(path, "info", "--format", "{{.Host.Security.Rootless}}").Output()
+	if err == nil {
+		p.rootless = strings.TrimSpace(string(output)) == "true"
+	}
+	p.socket, _ = PodmanSocket()
+	if p.rootless {
+		fmt.Println("Podman is running rootless")
+	}
+
+	return p, nil
+}
+
+// Rootless reports whether Podman runs as an unprivileged user
+func (p *PodmanRuntime) Rootless() bool {
 
-	return &PodmanRuntime{execPath: path}, nil
+	return p.rootless
 }
 
 func (p *PodmanRuntime) RemoveNetwork(name string) error {
@@ -53,15 +72,14 @@ func (p *PodmanRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 			return "", fmt.Errorf("failed to remove existing container: %w", err)
 		}
 	}
-	// Pull image if requested
-	if opts.Pull {
-		fmt.Printf("Pulling image '%s'...\n", opts.Image)
-		pullCmd := exec.Command(p.execPath, "pull", opts.Image)
-		pullCmd.Stdout = os.Stdout
-		pullCmd.Stderr = os.Stderr
-		if err := pullCmd.Run(); err != nil {
+	if err := ensureImage(p, opts, opts.Image); err != nil {
 
-			return "", fmt.Errorf("failed to pull image: %w", err)
+		return "", err
+	}
+	if opts.ImageCheck != nil {
+		if err := opts.ImageCheck(opts.Image); err != nil {
+
+			return "", err
 		}
 	}
 	// Prepare podman run command
@@ -75,13 +93,28 @@ func (p *PodmanRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 		args = append(args, "-p", p)
 	}
 	// Add volumes
-	for _, v := range opts.Volumes {
+	for _, v := range MapDockerSocket(opts.Volumes, p.socket) {
 		args = append(args, "-v", v)
 	}
 	// Set working directory
 	if opts.WorkDir != "" {
 		args = append(args, "-w", opts.WorkDir)
 	}
+	args = append(args, labelArgs(managedLabels(opts.Labels))...)
+	// Set the user, its groups and the user namespace it runs in
+	if opts.User != "" {
+		args = append(args, "--user", opts.User)
+	}
+	for _, group := range opts.Groups {
+		args = append(args, "--group-add", group)
+	}
+	userns := opts.UsernsMode
+	if p.rootless {
+		userns = RootlessUserns(opts)
+	}
+	if userns != "" {
+		args = append(args, "--userns", userns)
+	}
 	// Add network mode if specified
 	if opts.NetworkMode != "" {
 		args = append(args, "--network", opts.NetworkMode)
@@ -92,7 +125,7 @@ func (p *PodmanRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 		networkExists, _ := p.NetworkExists(network)
 		if !networkExists {
 			// Create the network
-			if err := p.CreateNetwork(network); err != nil {
+			if err := p.createNetwork(network, networkLabels(opts.Labels)); err != nil {
 
 				return "", err
 			}
@@ -101,6 +134,13 @@ func (p *PodmanRuntime) StartContainer(opts *ContainerOptions) (string, error) {
 			args = append(args, "--network", network)
 		}
 	}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+	// GPUs are passed through as CDI devices
+	for _, device := range CDIGPUDevices(opts.GPUs) {
+		args = append(args, "--device", device)
+	}
 	// Add image
 	args = append(args, opts.Image)
 	// Add command and arguments if specified
@@ -179,6 +219,36 @@ func (p *PodmanRuntime) ShowContainerLogs(name string, follow bool) error {
 	return cmd.Run()
 }
 
+// GetContainerExitCode returns the exit code of a stopped container
+func (p *PodmanRuntime) GetContainerExitCode(name string) (int, error) {
+	cmd := exec.Command(p.execPath, "inspect", "--format", "{{.State.ExitCode}}", name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+
+		return -1, fmt.Errorf("failed to inspect container '%s': %w", name, err)
+	}
+
+	code, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+
+		return -1, fmt.Errorf("failed to parse exit code for container '%s': %w", name, err)
+	}
+
+	return code, nil
+}
+
+// GetContainerLogs returns the last tail lines of a container's combined output
+func (p *PodmanRuntime) GetContainerLogs(name string, tail int) ([]string, error) {
+	cmd := exec.Command(p.execPath, "logs", "--tail", strconv.Itoa(tail), name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to get logs for container '%s': %w", name, err)
+	}
+
+	return splitLogLines(output), nil
+}
+
 func (p *PodmanRuntime) NetworkExists(name string) (bool, error) {
 	cmd := exec.Command(p.execPath, "network", "inspect", name)
 	err := cmd.Run()
@@ -187,7 +257,14 @@ func (p *PodmanRuntime) NetworkExists(name string) (bool, error) {
 }
 
 func (p *PodmanRuntime) CreateNetwork(name string) error {
-	cmd := exec.Command(p.execPath, "network", "create", name)
+
+	return p.createNetwork(name, nil)
+}
+
+// createNetwork creates a network with labels
+func (p *PodmanRuntime) createNetwork(name string, labels map[string]string) error {
+	args := append([]string{"network", "create"}, labelArgs(labels)...)
+	cmd := exec.Command(p.execPath, append(args, name)...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 
@@ -197,6 +274,18 @@ func (p *PodmanRuntime) CreateNetwork(name string) error {
 	return nil
 }
 
+// CreateInternalNetwork creates a network without outbound access
+func (p *PodmanRuntime) CreateInternalNetwork(name string) error {
+	cmd := exec.Command(p.execPath, "network", "create", "--internal", name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+
+		return fmt.Errorf("failed to create internal network '%s': %w, %s", name, err, string(output))
+	}
+
+	return nil
+}
+
 func (p *PodmanRuntime) ExecContainer(containerName string, command []string, interactive bool) (*exec.Cmd, io.Writer, io.Reader, error) {
 	args := []string{"exec"}
 	if interactive {
@@ -262,25 +351,14 @@ func (p *PodmanRuntime) UnpauseContainer(name string) error {
 }
 
 func (p *PodmanRuntime) GetContainerInfo(name string) (*ContainerInfo, error) {
-	cmd := exec.Command(p.execPath, "inspect", name)
-	output, err := cmd.CombinedOutput()
+	cmd := exec.Command(p.execPath, "container", "inspect", name)
+	output, err := cmd.Output()
 	if err != nil {
 
 		return nil, fmt.Errorf("failed to inspect container '%s': %w", name, err)
 	}
 
-	var containers []ContainerInfo
-	if err := json.Unmarshal(output, &containers); err != nil {
-
-		return nil, fmt.Errorf("failed to parse container info: %w", err)
-	}
-
-	if len(containers) == 0 {
-
-		return nil, fmt.Errorf("container '%s' not found", name)
-	}
-
-	return &containers[0], nil
+	return parseContainerInspect(name, output)
 }
 
 func (p *PodmanRuntime) ListContainers(filters map[string]string) ([]ContainerInfo, error) {
@@ -297,17 +375,24 @@ func (p *PodmanRuntime) ListContainers(filters map[string]string) ([]ContainerIn
 		return nil, fmt.Errorf("failed to list containers: %w", err)
 	}
 
-	var containers []ContainerInfo
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		if line == "" {
-
-			continue
-		}
-		var container ContainerInfo
-		if err := json.Unmarshal([]byte(line), &container); err != nil {
+	// Podman prints one JSON array, with the names as a list
+	var raw []struct {
+		ID     string `json:"Id"`
+		Names  []string
+		Image  string
+		State  string
+		Status string
+		Labels map[string]string
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
 
-			continue
+		return nil, fmt.Errorf("failed to parse containers: %w", err)
+	}
+	containers := make([]ContainerInfo, 0, len(raw))
+	for _, entry := range raw {
+		container := ContainerInfo{ID: entry.ID, Image: entry.Image, State: entry.State, Status: entry.Status, Labels: entry.Labels}
+		if len(entry.Names) > 0 {
+			container.Name = entry.Names[0]
 		}
 		containers = append(containers, container)
 	}
@@ -315,11 +400,14 @@ func (p *PodmanRuntime) ListContainers(filters map[string]string) ([]ContainerIn
 	return containers, nil
 }
 
-func (p *PodmanRuntime) PullImage(image string, auth *ImageAuth) error {
+func (p *PodmanRuntime) PullImage(image, platform string, auth *ImageAuth) error {
 	args := []string{"pull"}
 	if auth != nil {
 		args = append(args, "--username", auth.Username, "--password", auth.Password)
 	}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
 	args = append(args, image)
 
 	cmd := exec.Command(p.execPath, args...)
@@ -403,6 +491,17 @@ func (p *PodmanRuntime) ListImages() ([]ImageInfo, error) {
 	return images, nil
 }
 
+func (p *PodmanRuntime) GetImageInfo(image string) (*ImageInfo, error) {
+	cmd := exec.Command(p.execPath, "image", "inspect", image)
+	output, err := cmd.Output()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to inspect image '%s': %w", image, err)
+	}
+
+	return parseImageInspect(image, output)
+}
+
 func (p *PodmanRuntime) CreateVolume(name string, opts *VolumeOptions) error {
 	args := []string{"volume", "create"}
 
@@ -514,19 +613,13 @@ func (p *PodmanRuntime) DisconnectFromNetwork(containerName, networkName string)
 
 func (p *PodmanRuntime) GetContainerStats(name string) (*ContainerStats, error) {
 	cmd := exec.Command(p.execPath, "stats", "--no-stream", "--format", "json", name)
-	output, err := cmd.CombinedOutput()
+	output, err := cmd.Output()
 	if err != nil {
 
 		return nil, fmt.Errorf("failed to get stats: %w", err)
 	}
 
-	var stats ContainerStats
-	if err := json.Unmarshal(output, &stats); err != nil {
-
-		return nil, fmt.Errorf("failed to parse stats: %w", err)
-	}
-
-	return &stats, nil
+	return parseContainerStats(name, output)
 }
 
 func (p *PodmanRuntime) WaitForContainer(name string, condition string) error {
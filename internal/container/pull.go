@@ -0,0 +1,92 @@
+// internal/container/pull.go
+package container
+
+import (
+	"fmt"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// ensureImage applies a container's pull policy to the image it is about to run. With
+// missing, the default, the runtime pulls the image on run if it is not present.
+func ensureImage(rt Runtime, opts *ContainerOptions, image string) error {
+	policy := opts.PullPolicy
+	if policy == "" && opts.Pull {
+		policy = config.PullAlways
+	}
+
+	switch policy {
+	case config.PullAlways:
+		fmt.Printf("Pulling image '%s'...\n", image)
+		if err := rt.PullImage(image, opts.Platform, nil); err != nil {
+
+			return fmt.Errorf("failed to pull image '%s': %w", image, err)
+		}
+	case config.PullNever:
+		if _, err := rt.GetImageInfo(image); err != nil {
+
+			return fmt.Errorf("image '%s' is not present locally and pull_policy is never", image)
+		}
+	}
+
+	if info, err := rt.GetImageInfo(image); err == nil {
+		if warning := PlatformMismatch(info, opts.Platform); warning != "" {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+	}
+
+	return nil
+}
+
+// PlatformMismatch explains why an image will not run natively: it was built for another
+// platform than the one the server asks for or, without one, for another architecture than
+// this host's. It returns "" when the platforms agree or the image does not say.
+func PlatformMismatch(info *ImageInfo, platform string) string {
+	imagePlatform := info.Platform()
+	if imagePlatform == "" {
+
+		return ""
+	}
+	if platform != "" {
+		if platformMatches(imagePlatform, platform) {
+
+			return ""
+		}
+
+		return fmt.Sprintf("image '%s' is %s, not the %s the server asks for; the registry may not publish that platform", imageName(info), imagePlatform, platform)
+	}
+	if info.Architecture == goruntime.GOARCH {
+
+		return ""
+	}
+
+	return fmt.Sprintf("image '%s' is %s but this host is %s/%s, so it runs under emulation if at all; set platform or use a multi-arch image", imageName(info), imagePlatform, goruntime.GOOS, goruntime.GOARCH)
+}
+
+// platformMatches compares two os/arch[/variant] platforms, ignoring the variant unless both
+// name one
+func platformMatches(a, b string) bool {
+	partsA, partsB := strings.Split(a, "/"), strings.Split(b, "/")
+	if len(partsA) < 2 || len(partsB) < 2 {
+
+		return a == b
+	}
+	if partsA[0] != partsB[0] || partsA[1] != partsB[1] {
+
+		return false
+	}
+
+	return len(partsA) < 3 || len(partsB) < 3 || partsA[2] == partsB[2]
+}
+
+// imageName is the first tag of an image, or its ID
+func imageName(info *ImageInfo) string {
+	if len(info.Tags) > 0 {
+
+		return info.Tags[0]
+	}
+
+	return info.ID
+}
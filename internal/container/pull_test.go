@@ -0,0 +1,44 @@
+package container
+
+import (
+	goruntime "runtime"
+	"strings"
+	"testing"
+)
+
+func TestPlatformMismatch(t *testing.T) {
+	info, err := parseImageInspect("mcp/search", []byte(`[{"Id":"sha256:3f2a","RepoTags":["mcp/search:latest"],"Os":"linux","Architecture":"arm","Variant":"v7"}]`))
+	if err != nil {
+		t.Fatalf("parseImageInspect: %v", err)
+	}
+	if platform := info.Platform(); platform != "linux/arm/v7" {
+		t.Fatalf("Platform() = %q, want linux/arm/v7", platform)
+	}
+
+	tests := []struct {
+		name     string
+		platform string
+		mismatch bool
+	}{
+		{name: "same platform", platform: "linux/arm/v7"},
+		{name: "no variant asked for", platform: "linux/arm"},
+		{name: "other variant", platform: "linux/arm/v6", mismatch: true},
+		{name: "other architecture", platform: "linux/arm64", mismatch: true},
+		{name: "host architecture", mismatch: goruntime.GOARCH != "arm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning := PlatformMismatch(info, tt.platform)
+			if (warning != "") != tt.mismatch {
+				t.Errorf("PlatformMismatch(%q) = %q, want a warning: %v", tt.platform, warning, tt.mismatch)
+			}
+			if warning != "" && !strings.Contains(warning, "mcp/search:latest") {
+				t.Errorf("the warning should name the image, got %q", warning)
+			}
+		})
+	}
+
+	if warning := PlatformMismatch(&ImageInfo{ID: "sha256:3f2a"}, "linux/amd64"); warning != "" {
+		t.Errorf("an image without a platform should not warn, got %q", warning)
+	}
+}
@@ -0,0 +1,108 @@
+// internal/container/rootless.go
+package container
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// dockerSocketPaths are where servers that drive containers expect the Docker socket
+var dockerSocketPaths = []string{"/var/run/docker.sock", "/run/docker.sock"}
+
+// PodmanSocket returns the Podman API socket of the current user: CONTAINER_HOST when it
+// names a unix socket, the rootless socket under XDG_RUNTIME_DIR or /run/user/<uid>, or
+// the system socket. It reports false when none exists, for example when podman.socket
+// has not been enabled.
+func PodmanSocket() (string, bool) {
+	if host := os.Getenv("CONTAINER_HOST"); strings.HasPrefix(host, "unix://") {
+
+		return strings.TrimPrefix(host, "unix://"), true
+	}
+
+	var candidates []string
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	if uid := os.Getuid(); uid > 0 {
+		candidates = append(candidates, fmt.Sprintf("/run/user/%d/podman/podman.sock", uid))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode()&os.ModeSocket != 0 {
+
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// RootlessUserns picks the user namespace of a container under rootless Podman. Files in
+// bind mounts belong to the host user, who is root in the container by default, so a
+// server running as a numeric user gets the host user mapped to that user instead and can
+// write to them. An explicit userns_mode is kept as it is.
+func RootlessUserns(opts *ContainerOptions) string {
+	if opts.UsernsMode != "" || opts.User == "" || !hasBindMount(opts.Volumes) {
+
+		return opts.UsernsMode
+	}
+	uid, gid, hasGID := strings.Cut(opts.User, ":")
+	if _, err := strconv.Atoi(uid); err != nil {
+
+		return ""
+	}
+	if !hasGID {
+		gid = uid
+	}
+	if _, err := strconv.Atoi(gid); err != nil {
+
+		return ""
+	}
+
+	return fmt.Sprintf("keep-id:uid=%s,gid=%s", uid, gid)
+}
+
+// hasBindMount reports whether any volume mounts a host path rather than a named volume
+func hasBindMount(volumes []string) bool {
+	for _, volume := range volumes {
+		source, _, _ := strings.Cut(volume, ":")
+		if strings.HasPrefix(source, "/") || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "~") {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// MapDockerSocket points mounts of the Docker socket at the Podman socket when there is no
+// Docker socket on the host, so servers that manage containers work under Podman
+func MapDockerSocket(volumes []string, podmanSocket string) []string {
+	if podmanSocket == "" {
+
+		return volumes
+	}
+	mapped := make([]string, 0, len(volumes))
+	for _, volume := range volumes {
+		source, rest, hasTarget := strings.Cut(volume, ":")
+		if !hasTarget {
+			rest = source
+		}
+		for _, socket := range dockerSocketPaths {
+			if source != socket {
+
+				continue
+			}
+			if _, err := os.Stat(socket); os.IsNotExist(err) {
+				fmt.Printf("Mounting the Podman socket %s in place of %s\n", podmanSocket, socket)
+				volume = podmanSocket + ":" + rest
+			}
+		}
+		mapped = append(mapped, volume)
+	}
+
+	return mapped
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"github.com/phildougherty/mcp-compose/internal/config"
+	"os"
 	"os/exec"
+	"strings"
 )
 
 // ContainerOptions holds container creation options
@@ -19,6 +21,7 @@ type ContainerOptions struct {
 	Volumes     []string
 	WorkDir     string
 	Pull        bool
+	PullPolicy  string // always, missing or never; Pull means always
 	NetworkMode string
 	Networks    []string
 	Build       config.BuildConfig
@@ -27,6 +30,7 @@ type ContainerOptions struct {
 	Privileged  bool     `yaml:"privileged,omitempty"`
 	User        string   `yaml:"user,omitempty"`
 	Groups      []string `yaml:"groups,omitempty"`
+	UsernsMode  string   `yaml:"userns_mode,omitempty"`
 	CapAdd      []string `yaml:"cap_add,omitempty"`
 	CapDrop     []string `yaml:"cap_drop,omitempty"`
 	SecurityOpt []string `yaml:"security_opt,omitempty"`
@@ -38,6 +42,7 @@ type ContainerOptions struct {
 	Memory     string `yaml:"memory,omitempty"`
 	MemorySwap string `yaml:"memory_swap,omitempty"`
 	PidsLimit  int    `yaml:"pids_limit,omitempty"`
+	GPUs       string `yaml:"gpus,omitempty"` // all, a count or device=<id>[,<id>...]
 
 	// Lifecycle
 	RestartPolicy string       `yaml:"restart,omitempty"`
@@ -64,6 +69,10 @@ type ContainerOptions struct {
 
 	// Security configuration for validation
 	Security SecurityConfig `yaml:"security,omitempty"`
+
+	// ImageCheck, when set, vets the image once it is built or pulled and before the
+	// container is created; an error stops the start
+	ImageCheck func(image string) error `yaml:"-"`
 }
 
 // HealthCheck defines health check configuration
@@ -88,6 +97,7 @@ type ContainerInfo struct {
 	ID           string                     `json:"id"`
 	Name         string                     `json:"name"`
 	Image        string                     `json:"image"`
+	ImageID      string                     `json:"image_id"`
 	Status       string                     `json:"status"`
 	State        string                     `json:"state"`
 	Created      string                     `json:"created"`
@@ -98,15 +108,35 @@ type ContainerInfo struct {
 	Env          []string                   `json:"env"`
 	Command      []string                   `json:"command"`
 	RestartCount int                        `json:"restart_count"`
+	Health       string                     `json:"health"`
 }
 
 // ImageInfo represents image information
 type ImageInfo struct {
 	ID      string            `json:"id"`
 	Tags    []string          `json:"tags"`
+	Digests []string          `json:"digests"`
 	Size    int64             `json:"size"`
 	Created string            `json:"created"`
 	Labels  map[string]string `json:"labels"`
+
+	OS           string `json:"os,omitempty"`
+	Architecture string `json:"architecture,omitempty"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Platform is the os/arch[/variant] the image was built for, or "" if the runtime did not say
+func (i *ImageInfo) Platform() string {
+	if i.OS == "" || i.Architecture == "" {
+
+		return ""
+	}
+	platform := i.OS + "/" + i.Architecture
+	if i.Variant != "" {
+		platform += "/" + i.Variant
+	}
+
+	return platform
 }
 
 // VolumeInfo represents volume information
@@ -159,10 +189,12 @@ type MountInfo struct {
 
 // ContainerStats represents container statistics
 type ContainerStats struct {
-	CPUUsage    float64 `json:"cpu_usage"`
-	MemoryUsage int64   `json:"memory_usage"`
-	MemoryLimit int64   `json:"memory_limit"`
-	NetworkIO   struct {
+	Name          string  `json:"name"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryUsage   int64   `json:"memory_usage"` // bytes
+	MemoryLimit   int64   `json:"memory_limit"` // bytes
+	MemoryPercent float64 `json:"memory_percent"`
+	NetworkIO     struct {
 		RxBytes int64 `json:"rx_bytes"`
 		TxBytes int64 `json:"tx_bytes"`
 	} `json:"network_io"`
@@ -170,6 +202,7 @@ type ContainerStats struct {
 		ReadBytes  int64 `json:"read_bytes"`
 		WriteBytes int64 `json:"write_bytes"`
 	} `json:"block_io"`
+	PIDs int64 `json:"pids"`
 }
 
 // ImageAuth represents image authentication credentials
@@ -217,6 +250,7 @@ type Runtime interface {
 
 	// Container inspection and monitoring
 	GetContainerStatus(name string) (string, error)
+	GetContainerExitCode(name string) (int, error)
 	GetContainerInfo(name string) (*ContainerInfo, error)
 	ListContainers(filters map[string]string) ([]ContainerInfo, error)
 	GetContainerStats(name string) (*ContainerStats, error)
@@ -224,13 +258,15 @@ type Runtime interface {
 
 	// Container logs and execution
 	ShowContainerLogs(name string, follow bool) error
+	GetContainerLogs(name string, tail int) ([]string, error)
 	ExecContainer(containerName string, command []string, interactive bool) (*exec.Cmd, io.Writer, io.Reader, error)
 
 	// Image management
-	PullImage(image string, auth *ImageAuth) error
+	PullImage(image, platform string, auth *ImageAuth) error
 	BuildImage(opts *BuildOptions) error
 	RemoveImage(image string, force bool) error
 	ListImages() ([]ImageInfo, error)
+	GetImageInfo(image string) (*ImageInfo, error)
 
 	// Volume management
 	CreateVolume(name string, opts *VolumeOptions) error
@@ -240,6 +276,7 @@ type Runtime interface {
 	// Network management
 	NetworkExists(name string) (bool, error)
 	CreateNetwork(name string) error
+	CreateInternalNetwork(name string) error
 	RemoveNetwork(name string) error
 	ListNetworks() ([]NetworkInfo, error)
 	GetNetworkInfo(name string) (*NetworkInfo, error)
@@ -256,12 +293,13 @@ type Runtime interface {
 	GetRuntimeName() string
 }
 
-// DetectRuntime tries to detect and initialize a container runtime
+// DetectRuntime tries to detect and initialize a container runtime. What it found is reported
+// on stderr, so it never mixes with machine-readable output.
 func DetectRuntime() (Runtime, error) {
 	// Try Docker first
 	dockerPath, err := exec.LookPath("docker")
 	if err == nil {
-		fmt.Println("Detected Docker runtime")
+		fmt.Fprintln(os.Stderr, "Detected Docker runtime")
 
 		return NewDockerRuntime(dockerPath)
 	}
@@ -269,13 +307,13 @@ func DetectRuntime() (Runtime, error) {
 	// Try Podman next
 	podmanPath, err := exec.LookPath("podman")
 	if err == nil {
-		fmt.Println("Detected Podman runtime")
+		fmt.Fprintln(os.Stderr, "Detected Podman runtime")
 
 		return NewPodmanRuntime(podmanPath)
 	}
 
 	// Return a null runtime that can only handle process-based servers
-	fmt.Println("No container runtime detected, only process-based servers will be supported")
+	fmt.Fprintln(os.Stderr, "No container runtime detected, only process-based servers will be supported")
 
 	return NewNullRuntime(), nil
 }
@@ -370,13 +408,14 @@ func validateMemoryLimit(memory string) error {
 // ConvertConfigToContainerOptions converts server config to container options
 func ConvertConfigToContainerOptions(serverName string, serverCfg config.ServerConfig) *ContainerOptions {
 	opts := &ContainerOptions{
-		Name:        fmt.Sprintf("mcp-compose-%s", serverName),
+		Name:        config.ContainerName(serverName),
 		Image:       serverCfg.Image,
 		Build:       serverCfg.Build,
 		Command:     serverCfg.Command,
 		Args:        serverCfg.Args,
 		Env:         config.MergeEnv(serverCfg.Env, map[string]string{"MCP_SERVER_NAME": serverName}),
 		Pull:        serverCfg.Pull,
+		PullPolicy:  serverCfg.PullPolicy,
 		Volumes:     serverCfg.Volumes,
 		Ports:       serverCfg.Ports,
 		Networks:    serverCfg.Networks,
@@ -387,6 +426,7 @@ func ConvertConfigToContainerOptions(serverName string, serverCfg config.ServerC
 		Privileged:  serverCfg.Privileged,
 		User:        serverCfg.User,
 		Groups:      serverCfg.Groups,
+		UsernsMode:  serverCfg.UsernsMode,
 		ReadOnly:    serverCfg.ReadOnly,
 		Tmpfs:       serverCfg.Tmpfs,
 		CapAdd:      serverCfg.CapAdd,
@@ -395,6 +435,7 @@ func ConvertConfigToContainerOptions(serverName string, serverCfg config.ServerC
 
 		// Resource limits
 		PidsLimit: serverCfg.Deploy.Resources.Limits.PIDs,
+		GPUs:      serverCfg.GPURequest(),
 
 		// Lifecycle
 		RestartPolicy: serverCfg.RestartPolicy,
@@ -470,7 +511,7 @@ func GetDefaultContainerOptions() *ContainerOptions {
 
 	return &ContainerOptions{
 		RestartPolicy: "unless-stopped",
-		Networks:      []string{"mcp-net"},
+		Networks:      []string{config.DefaultNetwork()},
 		Security: SecurityConfig{
 			AllowDockerSocket:  false,
 			AllowPrivilegedOps: false,
@@ -511,9 +552,44 @@ func IsContainerRunning(runtime Runtime, containerName string) bool {
 	return status == "running"
 }
 
+// ConnectIfRunning attaches a running container to a network it is not on yet. It reports
+// whether the container was connected; a container that is not running is left alone.
+func ConnectIfRunning(runtime Runtime, containerName, networkName string) (bool, error) {
+	info, err := runtime.GetContainerInfo(containerName)
+	if err != nil || info.State != "running" {
+
+		return false, nil
+	}
+	if _, connected := info.Networks[networkName]; connected {
+
+		return false, nil
+	}
+	if err := runtime.ConnectToNetwork(containerName, networkName); err != nil {
+
+		return false, fmt.Errorf("failed to connect %s to network %s: %w", containerName, networkName, err)
+	}
+
+	return true, nil
+}
+
 // WaitForContainerReady waits for a container to be ready (running and healthy)
 func WaitForContainerReady(runtime Runtime, containerName string, maxWait int) error {
 	// This can be enhanced with more sophisticated readiness checking
 
 	return runtime.WaitForContainer(containerName, "running")
 }
+
+// splitLogLines splits raw log output into non-empty lines
+func splitLogLines(output []byte) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
@@ -0,0 +1,164 @@
+// internal/container/stats.go
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// byteUnits are the size suffixes `docker stats` and `podman stats` print: decimal units for
+// network and block IO, binary ones for memory
+var byteUnits = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// statsFields names each value in the JSON of both runtimes: Docker's template field names,
+// then Podman's
+var statsFields = struct {
+	name, cpu, memUsage, memPercent, netIO, blockIO, pids []string
+}{
+	name:       []string{"Name", "name"},
+	cpu:        []string{"CPUPerc", "cpu_percent"},
+	memUsage:   []string{"MemUsage", "mem_usage"},
+	memPercent: []string{"MemPerc", "mem_percent"},
+	netIO:      []string{"NetIO", "net_io"},
+	blockIO:    []string{"BlockIO", "block_io"},
+	pids:       []string{"PIDs", "pids"},
+}
+
+// parseContainerStats converts what `stats --no-stream --format json` prints for one
+// container. Docker prints an object per line and Podman a JSON array; both give the values
+// as display strings such as "0.52%" and "12.5MiB / 1.944GiB", which are turned into numbers.
+func parseContainerStats(name string, output []byte) (*ContainerStats, error) {
+	output = bytes.TrimSpace(output)
+	var entries []map[string]interface{}
+	if bytes.HasPrefix(output, []byte("[")) {
+		if err := json.Unmarshal(output, &entries); err != nil {
+
+			return nil, fmt.Errorf("failed to parse stats: %w", err)
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(output))
+		for decoder.More() {
+			var entry map[string]interface{}
+			if err := decoder.Decode(&entry); err != nil {
+
+				return nil, fmt.Errorf("failed to parse stats: %w", err)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+
+		return nil, fmt.Errorf("no stats reported for container '%s'", name)
+	}
+	raw := entries[0]
+
+	field := func(names []string) string {
+		for _, key := range names {
+			if value, ok := raw[key]; ok {
+
+				return strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+
+		return ""
+	}
+
+	stats := &ContainerStats{Name: strings.TrimPrefix(field(statsFields.name), "/")}
+	var err error
+	if stats.CPUPercent, err = parsePercent(field(statsFields.cpu)); err != nil {
+
+		return nil, fmt.Errorf("failed to parse CPU usage: %w", err)
+	}
+	if stats.MemoryPercent, err = parsePercent(field(statsFields.memPercent)); err != nil {
+
+		return nil, fmt.Errorf("failed to parse memory usage: %w", err)
+	}
+	if stats.MemoryUsage, stats.MemoryLimit, err = parseBytePair(field(statsFields.memUsage)); err != nil {
+
+		return nil, fmt.Errorf("failed to parse memory usage: %w", err)
+	}
+	if stats.NetworkIO.RxBytes, stats.NetworkIO.TxBytes, err = parseBytePair(field(statsFields.netIO)); err != nil {
+
+		return nil, fmt.Errorf("failed to parse network IO: %w", err)
+	}
+	if stats.BlockIO.ReadBytes, stats.BlockIO.WriteBytes, err = parseBytePair(field(statsFields.blockIO)); err != nil {
+
+		return nil, fmt.Errorf("failed to parse block IO: %w", err)
+	}
+	if pids := field(statsFields.pids); pids != "" && pids != "--" {
+		if stats.PIDs, err = strconv.ParseInt(pids, 10, 64); err != nil {
+
+			return nil, fmt.Errorf("failed to parse PIDs '%s': %w", pids, err)
+		}
+	}
+
+	return stats, nil
+}
+
+// parsePercent reads a value such as "12.50%"; an empty or "--" value is 0
+func parsePercent(value string) (float64, error) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	if value == "" || value == "--" {
+
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseBytePair reads a "used / total" or "in / out" pair of sizes
+func parseBytePair(value string) (int64, int64, error) {
+	first, second, _ := strings.Cut(value, "/")
+	a, err := parseByteSize(first)
+	if err != nil {
+
+		return 0, 0, err
+	}
+	b, err := parseByteSize(second)
+	if err != nil {
+
+		return 0, 0, err
+	}
+
+	return a, b, nil
+}
+
+// parseByteSize reads a size such as "1.5MiB", "830kB" or "0B"; an empty or "--" value is 0
+func parseByteSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "--" {
+
+		return 0, nil
+	}
+	split := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	number, unit := value, "b"
+	if split >= 0 {
+		number, unit = strings.TrimSpace(value[:split]), strings.ToLower(strings.TrimSpace(value[split:]))
+	}
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+
+		return 0, fmt.Errorf("unknown size unit in '%s'", value)
+	}
+	parsed, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+
+		return 0, fmt.Errorf("invalid size '%s': %w", value, err)
+	}
+
+	return int64(math.Round(parsed * multiplier)), nil
+}
@@ -0,0 +1,49 @@
+package container
+
+import "testing"
+
+func TestParseContainerStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{
+			name:   "docker",
+			output: `{"BlockIO":"4.1MB / 0B","CPUPerc":"12.50%","Container":"mcp-compose-search","ID":"3f2a","MemPerc":"1.56%","MemUsage":"32MiB / 2GiB","Name":"mcp-compose-search","NetIO":"1.5kB / 648B","PIDs":"7"}` + "\n",
+		},
+		{
+			name:   "podman",
+			output: `[{"id":"3f2a","name":"mcp-compose-search","cpu_percent":"12.50%","mem_usage":"32MiB / 2GiB","mem_percent":"1.56%","net_io":"1.5kB / 648B","block_io":"4.1MB / 0B","pids":"7"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := parseContainerStats("mcp-compose-search", []byte(tt.output))
+			if err != nil {
+				t.Fatalf("parseContainerStats: %v", err)
+			}
+			if stats.Name != "mcp-compose-search" || stats.CPUPercent != 12.5 || stats.MemoryPercent != 1.56 || stats.PIDs != 7 {
+				t.Errorf("unexpected stats %+v", stats)
+			}
+			if stats.MemoryUsage != 32<<20 || stats.MemoryLimit != 2<<30 {
+				t.Errorf("memory = %d / %d, want %d / %d", stats.MemoryUsage, stats.MemoryLimit, 32<<20, 2<<30)
+			}
+			if stats.NetworkIO.RxBytes != 1500 || stats.NetworkIO.TxBytes != 648 {
+				t.Errorf("network IO = %+v", stats.NetworkIO)
+			}
+			if stats.BlockIO.ReadBytes != 4100000 || stats.BlockIO.WriteBytes != 0 {
+				t.Errorf("block IO = %+v", stats.BlockIO)
+			}
+		})
+	}
+
+	if _, err := parseContainerStats("gone", []byte("[]")); err == nil {
+		t.Error("expected an error when no stats are reported")
+	}
+	if _, err := parseByteSize("12 parsecs"); err == nil {
+		t.Error("expected an error for an unknown unit")
+	}
+	if size, err := parseByteSize("--"); err != nil || size != 0 {
+		t.Errorf("a missing size should be 0, got %d, %v", size, err)
+	}
+}
@@ -0,0 +1,146 @@
+// internal/cors/cors.go
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// DefaultMethods are the methods allowed origins may use when the config names none
+var DefaultMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions}
+
+// Policy tells browsers which other origins' pages may call an endpoint. Without allowed
+// origins, as with a nil policy, responses carry no CORS headers and browsers keep pages of
+// other origins from reading them.
+type Policy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	methods     string
+	headers     string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+// New builds the policy of a cors config section. headers and exposed are the request and
+// response headers the endpoint's clients use, taken unless the config names its own.
+func New(cfg *config.CORSConfig, headers, exposed []string) *Policy {
+	policy := &Policy{origins: make(map[string]bool)}
+	if cfg == nil {
+
+		return policy
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			policy.anyOrigin = true
+
+			continue
+		}
+		policy.origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+	methods := DefaultMethods
+	if len(cfg.AllowedMethods) > 0 {
+		methods = cfg.AllowedMethods
+	}
+	if len(cfg.AllowedHeaders) > 0 {
+		headers = cfg.AllowedHeaders
+	}
+	if len(cfg.ExposedHeaders) > 0 {
+		exposed = cfg.ExposedHeaders
+	}
+	policy.methods = strings.ToUpper(strings.Join(methods, ", "))
+	policy.headers = strings.Join(headers, ", ")
+	policy.exposed = strings.Join(exposed, ", ")
+	policy.credentials = cfg.AllowCredentials
+	if maxAge, err := time.ParseDuration(cfg.MaxAge); err == nil && maxAge > 0 {
+		policy.maxAge = strconv.Itoa(int(maxAge.Seconds()))
+	}
+
+	return policy
+}
+
+// Enabled reports whether any other origin is allowed
+func (p *Policy) Enabled() bool {
+
+	return p != nil && (p.anyOrigin || len(p.origins) > 0)
+}
+
+// Allows reports whether pages of origin may call the endpoint
+func (p *Policy) Allows(origin string) bool {
+	if p == nil || origin == "" {
+
+		return false
+	}
+
+	return p.anyOrigin || p.origins[strings.ToLower(origin)]
+}
+
+// AllowsCredentials reports whether pages of origin may call the endpoint with the browser's
+// cookies
+func (p *Policy) AllowsCredentials(origin string) bool {
+
+	return p.Allows(origin) && p.credentials
+}
+
+// Handle sets the CORS headers of the response to r and answers preflight requests, refusing
+// those from origins that are not allowed. It reports whether it answered the request.
+func (p *Policy) Handle(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	allowed := p.Allows(origin)
+	header := w.Header()
+	if p.Enabled() && !p.anyOrigin {
+		addVary(header, "Origin")
+	}
+	if allowed {
+		if p.anyOrigin {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.credentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if p.exposed != "" {
+			header.Set("Access-Control-Expose-Headers", p.exposed)
+		}
+	}
+
+	if r.Method != http.MethodOptions {
+
+		return false
+	}
+	if origin != "" && !allowed {
+		http.Error(w, "Cross-origin requests from "+origin+" are not allowed", http.StatusForbidden)
+
+		return true
+	}
+	if allowed {
+		header.Set("Access-Control-Allow-Methods", p.methods)
+		if p.headers != "" {
+			header.Set("Access-Control-Allow-Headers", p.headers)
+		}
+		if p.maxAge != "" {
+			header.Set("Access-Control-Max-Age", p.maxAge)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+
+	return true
+}
+
+// addVary adds a value to the Vary header once, however often the policy is applied
+func addVary(header http.Header, value string) {
+	for _, existing := range header.Values("Vary") {
+		for _, field := range strings.Split(existing, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), value) {
+
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
@@ -0,0 +1,90 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func preflight(policy *Policy, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/filesystem", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+	recorder := httptest.NewRecorder()
+	if !policy.Handle(recorder, req) {
+		recorder.Code = 0
+	}
+
+	return recorder
+}
+
+func TestLockedDownByDefault(t *testing.T) {
+	for name, policy := range map[string]*Policy{"nil": nil, "unset": New(nil, []string{"Content-Type"}, nil)} {
+		recorder := preflight(policy, "https://evil.example.com")
+		if recorder.Code != http.StatusForbidden || recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("%s: expected other origins to be refused, got %d %v", name, recorder.Code, recorder.Header())
+		}
+		// OPTIONS requests that aren't from a browser page are still answered
+		if recorder := preflight(policy, ""); recorder.Code != http.StatusOK {
+			t.Errorf("%s: expected a plain OPTIONS request to be answered, got %d", name, recorder.Code)
+		}
+	}
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	policy := New(&config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com/"},
+		AllowCredentials: true,
+		MaxAge:           "10m",
+	}, []string{"Content-Type", "Authorization"}, []string{"Mcp-Session-Id"})
+
+	recorder := preflight(policy, "https://App.example.com")
+	header := recorder.Header()
+	if recorder.Code != http.StatusOK || header.Get("Access-Control-Allow-Origin") != "https://App.example.com" {
+		t.Fatalf("Expected the allowed origin to be echoed, got %d %v", recorder.Code, header)
+	}
+	for name, expected := range map[string]string{
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST, PUT, DELETE, OPTIONS",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+		"Access-Control-Expose-Headers":    "Mcp-Session-Id",
+		"Access-Control-Max-Age":           "600",
+		"Vary":                             "Origin",
+	} {
+		if got := header.Get(name); got != expected {
+			t.Errorf("Expected %s: %s, got %q", name, expected, got)
+		}
+	}
+	if recorder := preflight(policy, "https://other.example.com"); recorder.Code != http.StatusForbidden {
+		t.Errorf("Expected an unlisted origin to be refused, got %d", recorder.Code)
+	}
+
+	// Other requests get their headers and go on to the handler, Vary set once
+	req := httptest.NewRequest(http.MethodPost, "/filesystem", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	recorder = httptest.NewRecorder()
+	if policy.Handle(recorder, req) || policy.Handle(recorder, req) {
+		t.Fatal("Expected a POST to be left to the handler")
+	}
+	if recorder.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || len(recorder.Header().Values("Vary")) != 1 {
+		t.Errorf("Expected the CORS headers once, got %v", recorder.Header())
+	}
+	if !policy.AllowsCredentials("https://app.example.com") || policy.AllowsCredentials("https://other.example.com") {
+		t.Error("Expected credentials for the allowed origin only")
+	}
+}
+
+func TestAnyOrigin(t *testing.T) {
+	policy := New(&config.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"get", "post"}}, nil, nil)
+	recorder := preflight(policy, "https://anywhere.example.com")
+	if recorder.Header().Get("Access-Control-Allow-Origin") != "*" || recorder.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Errorf("Expected the wildcard policy, got %v", recorder.Header())
+	}
+	if recorder.Header().Get("Access-Control-Allow-Credentials") != "" || recorder.Header().Get("Vary") != "" {
+		t.Errorf("Expected no credentials or Vary with the wildcard, got %v", recorder.Header())
+	}
+}
@@ -0,0 +1,262 @@
+// internal/cron/cron.go
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	second, minute, hour, dom, month, dow uint64
+	// domStar and dowStar record an unrestricted day field; when both day fields are
+	// restricted a time matches either of them, as in standard cron
+	domStar, dowStar bool
+	// every is the interval of an @every schedule
+	every time.Duration
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = field{name: "second", min: 0, max: 59}
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// maxSearchYears bounds the search for a matching time, so that expressions that can never
+// match, such as 30 February, end instead of looping
+const maxSearchYears = 5
+
+// Parse parses a standard five-field cron expression, a six-field one whose first field is
+// seconds, a descriptor such as @daily, or @every followed by a Go duration
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+
+		return nil, fmt.Errorf("empty cron expression")
+	}
+
+	if strings.HasPrefix(expr, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+
+			return nil, fmt.Errorf("invalid @every interval: %w", err)
+		}
+		if every < time.Second {
+
+			return nil, fmt.Errorf("@every interval must be at least 1s")
+		}
+
+		return &Schedule{every: every}, nil
+	}
+	if strings.HasPrefix(expr, "@") {
+		spec, ok := descriptors[strings.ToLower(expr)]
+		if !ok {
+
+			return nil, fmt.Errorf("unknown descriptor '%s'", expr)
+		}
+		expr = spec
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+
+		return nil, fmt.Errorf("expected 5 or 6 fields, got %d in '%s'", len(fields), expr)
+	}
+
+	schedule := &Schedule{}
+	var err error
+	if schedule.second, err = secondField.parse(fields[0]); err != nil {
+
+		return nil, err
+	}
+	if schedule.minute, err = minuteField.parse(fields[1]); err != nil {
+
+		return nil, err
+	}
+	if schedule.hour, err = hourField.parse(fields[2]); err != nil {
+
+		return nil, err
+	}
+	if schedule.dom, err = domField.parse(fields[3]); err != nil {
+
+		return nil, err
+	}
+	if schedule.month, err = monthField.parse(fields[4]); err != nil {
+
+		return nil, err
+	}
+	if schedule.dow, err = dowField.parse(fields[5]); err != nil {
+
+		return nil, err
+	}
+	// Sunday may be written as 7
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domStar = isStar(fields[3])
+	schedule.dowStar = isStar(fields[5])
+
+	return schedule, nil
+}
+
+// Next returns the first time after t the schedule fires, or the zero time if it never does
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+
+		return t.Truncate(time.Second).Add(s.every)
+	}
+
+	t = t.Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		case s.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(time.Second)
+		default:
+
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// NextN returns up to n times the schedule fires after t
+func (s *Schedule) NextN(t time.Time, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+	for len(times) < n {
+		t = s.Next(t)
+		if t.IsZero() {
+
+			break
+		}
+		times = append(times, t)
+	}
+
+	return times
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+func isStar(spec string) bool {
+
+	return spec == "*" || spec == "?"
+}
+
+// parse turns a field of comma-separated values, ranges and steps into a bit set
+func (f field) parse(spec string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(spec, ",") {
+		rangeSpec, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangeSpec = part[:i]
+			parsed, err := strconv.Atoi(part[i+1:])
+			if err != nil || parsed < 1 {
+
+				return 0, fmt.Errorf("invalid step in %s field '%s'", f.name, part)
+			}
+			step = parsed
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rangeSpec == "*" || rangeSpec == "?":
+		case strings.Contains(rangeSpec, "-"):
+			bounds := strings.SplitN(rangeSpec, "-", 2)
+			var err error
+			if start, err = f.value(bounds[0]); err != nil {
+
+				return 0, err
+			}
+			if end, err = f.value(bounds[1]); err != nil {
+
+				return 0, err
+			}
+			if start > end {
+
+				return 0, fmt.Errorf("invalid range in %s field '%s'", f.name, part)
+			}
+		default:
+			value, err := f.value(rangeSpec)
+			if err != nil {
+
+				return 0, err
+			}
+			start = value
+			if step == 1 {
+				end = value
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func (f field) value(spec string) (int, error) {
+	if value, ok := f.names[strings.ToLower(spec)]; ok {
+
+		return value, nil
+	}
+	value, err := strconv.Atoi(spec)
+	if err != nil {
+
+		return 0, fmt.Errorf("invalid %s '%s'", f.name, spec)
+	}
+	if value < f.min || value > f.max {
+
+		return 0, fmt.Errorf("%s %d is out of range %d-%d", f.name, value, f.min, f.max)
+	}
+
+	return value, nil
+}
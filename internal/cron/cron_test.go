@@ -0,0 +1,109 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		expectErr bool
+	}{
+		{name: "five fields", expr: "*/15 9-17 * * MON-FRI"},
+		{name: "six fields", expr: "30 0 12 1,15 * ?"},
+		{name: "descriptor", expr: "@daily"},
+		{name: "every", expr: "@every 90m"},
+		{name: "sunday as seven", expr: "0 0 * * 7"},
+		{name: "empty", expr: "", expectErr: true},
+		{name: "too few fields", expr: "* * *", expectErr: true},
+		{name: "out of range", expr: "60 * * * *", expectErr: true},
+		{name: "bad name", expr: "0 0 * FOO *", expectErr: true},
+		{name: "reversed range", expr: "0 17-9 * * *", expectErr: true},
+		{name: "zero step", expr: "*/0 * * * *", expectErr: true},
+		{name: "unknown descriptor", expr: "@fortnightly", expectErr: true},
+		{name: "bad interval", expr: "@every soon", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected '%s' to be rejected", tt.expr)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error for '%s': %v", tt.expr, err)
+			}
+		})
+	}
+}
+
+func TestNextN(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2026, time.January, 14, 16, 50, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		expr   string
+		expect []string
+	}{
+		{
+			name:   "weekday working hours",
+			expr:   "*/15 9-17 * * MON-FRI",
+			expect: []string{"2026-01-14T17:00:00Z", "2026-01-14T17:15:00Z", "2026-01-14T17:30:00Z"},
+		},
+		{
+			name:   "rolls over to the next day",
+			expr:   "0 9 * * *",
+			expect: []string{"2026-01-15T09:00:00Z", "2026-01-16T09:00:00Z"},
+		},
+		{
+			name:   "day of month or day of week",
+			expr:   "0 0 15 * SUN",
+			expect: []string{"2026-01-15T00:00:00Z", "2026-01-18T00:00:00Z", "2026-01-25T00:00:00Z"},
+		},
+		{
+			name:   "month end skips short months",
+			expr:   "0 0 31 * *",
+			expect: []string{"2026-01-31T00:00:00Z", "2026-03-31T00:00:00Z"},
+		},
+		{
+			name:   "seconds",
+			expr:   "*/20 50 16 * * *",
+			expect: []string{"2026-01-14T16:50:20Z", "2026-01-14T16:50:40Z", "2026-01-15T16:50:00Z"},
+		},
+		{
+			name:   "every",
+			expr:   "@every 2h",
+			expect: []string{"2026-01-14T18:50:00Z", "2026-01-14T20:50:00Z"},
+		},
+		{
+			name:   "never",
+			expr:   "0 0 30 2 *",
+			expect: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Failed to parse '%s': %v", tt.expr, err)
+			}
+			got := schedule.NextN(from, len(tt.expect)+1)
+			if len(tt.expect) == 0 {
+				if len(got) != 0 {
+					t.Errorf("Expected no runs, got %v", got)
+				}
+
+				return
+			}
+			for i, want := range tt.expect {
+				if got[i].Format(time.RFC3339) != want {
+					t.Errorf("Run %d: expected %s, got %s", i, want, got[i].Format(time.RFC3339))
+				}
+			}
+		})
+	}
+}
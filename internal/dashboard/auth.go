@@ -0,0 +1,725 @@
+// internal/dashboard/auth.go
+package dashboard
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Dashboard roles. Admins may start, stop and restart servers, reload the proxy and manage
+// OAuth clients; viewers may only look.
+const (
+	RoleAdmin  = "admin"
+	RoleViewer = "viewer"
+)
+
+type authContextKey string
+
+const sessionContextKey authContextKey = "dashboard_session"
+
+// dashboardSession is a signed-in dashboard user
+type dashboardSession struct {
+	ID       string    `json:"-"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	Method   string    `json:"method"` // password, oauth or api_key
+	Expires  time.Time `json:"expires"`
+}
+
+// sessionStore keeps dashboard sessions in memory; every request extends its session by the
+// idle timeout
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*dashboardSession
+	timeout  time.Duration
+}
+
+func newSessionStore(timeout time.Duration) *sessionStore {
+
+	return &sessionStore{sessions: make(map[string]*dashboardSession), timeout: timeout}
+}
+
+func (s *sessionStore) create(username, role, method string) (*dashboardSession, error) {
+	id, err := randomToken()
+	if err != nil {
+
+		return nil, err
+	}
+	session := &dashboardSession{ID: id, Username: username, Role: role, Method: method, Expires: time.Now().Add(s.timeout)}
+
+	s.mu.Lock()
+	s.sessions[id] = session
+	s.mu.Unlock()
+
+	return session, nil
+}
+
+func (s *sessionStore) get(id string) (*dashboardSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+
+		return nil, false
+	}
+	if time.Now().After(session.Expires) {
+		delete(s.sessions, id)
+
+		return nil, false
+	}
+	session.Expires = time.Now().Add(s.timeout)
+	current := *session
+
+	return &current, true
+}
+
+func (s *sessionStore) delete(id string) {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+}
+
+func (s *sessionStore) cleanup() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	now := time.Now()
+	for id, session := range s.sessions {
+		if now.After(session.Expires) {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+type oauthLoginState struct {
+	verifier string
+	next     string
+	expires  time.Time
+}
+
+// dashboardAuth signs dashboard users in and checks every request against their role
+type dashboardAuth struct {
+	config   *config.ComposeConfig
+	login    *config.DashboardAdminLogin
+	apiKey   string
+	sessions *sessionStore
+	logger   *logging.Logger
+	client   *http.Client
+
+	mu          sync.Mutex
+	oauthStates map[string]oauthLoginState
+}
+
+func newDashboardAuth(cfg *config.ComposeConfig, apiKey string, logger *logging.Logger) *dashboardAuth {
+	timeout := cfg.Dashboard.AdminLogin.SessionTimeoutDuration()
+	if timeout <= 0 {
+		timeout = constants.DashboardSessionTimeout
+	}
+
+	return &dashboardAuth{
+		config:      cfg,
+		login:       cfg.Dashboard.AdminLogin,
+		apiKey:      apiKey,
+		sessions:    newSessionStore(timeout),
+		logger:      logger,
+		client:      &http.Client{Timeout: constants.DefaultStatsTimeout},
+		oauthStates: make(map[string]oauthLoginState),
+	}
+}
+
+func (a *dashboardAuth) oauthEnabled() bool {
+
+	return a.login.OAuth != nil && a.login.OAuth.Enabled
+}
+
+func (a *dashboardAuth) startCleanup() {
+	ticker := time.NewTicker(constants.DefaultCleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if count := a.sessions.cleanup(); count > 0 {
+			a.logger.Debug("Cleaned up %d expired dashboard sessions", count)
+		}
+		a.mu.Lock()
+		for state, pending := range a.oauthStates {
+			if time.Now().After(pending.expires) {
+				delete(a.oauthStates, state)
+			}
+		}
+		a.mu.Unlock()
+	}
+}
+
+// dashboardRole maps a configured user role onto the dashboard's admin and viewer roles
+func dashboardRole(role string) string {
+	if strings.EqualFold(role, RoleAdmin) {
+
+		return RoleAdmin
+	}
+
+	return RoleViewer
+}
+
+// findUser looks up an enabled user by username or email
+func (a *dashboardAuth) findUser(name string) (string, *config.User) {
+	if name == "" {
+
+		return "", nil
+	}
+	for key, user := range a.config.Users {
+		if user == nil || !user.Enabled {
+			continue
+		}
+		if key == name || user.Username == name || (user.Email != "" && strings.EqualFold(user.Email, name)) {
+			if user.Username != "" {
+
+				return user.Username, user
+			}
+
+			return key, user
+		}
+	}
+
+	return "", nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// checkPassword returns the signed-in username and role for valid credentials. Unknown
+// users still pay for a bcrypt comparison so they can't be told apart by timing.
+func (a *dashboardAuth) checkPassword(name, password string) (string, string, bool) {
+	username, user := a.findUser(name)
+	if user == nil || user.PasswordHash == "" {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("mcp-compose"), bcrypt.DefaultCost)
+		})
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+
+		return "", "", false
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+
+		return "", "", false
+	}
+
+	return username, dashboardRole(user.Role), true
+}
+
+// HashPassword returns the bcrypt hash to put in a user's password_hash
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// authenticate returns the session behind a request, from its cookie or, for scripts, the
+// proxy API key as a bearer token
+func (a *dashboardAuth) authenticate(r *http.Request) (*dashboardSession, bool) {
+	if cookie, err := r.Cookie(constants.DashboardSessionCookie); err == nil && cookie.Value != "" {
+		if session, ok := a.sessions.get(cookie.Value); ok {
+
+			return session, true
+		}
+	}
+	if a.apiKey != "" {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if found && subtle.ConstantTimeCompare([]byte(token), []byte(a.apiKey)) == 1 {
+
+			return &dashboardSession{Username: "api-key", Role: RoleAdmin, Method: "api_key"}, true
+		}
+	}
+
+	return nil, false
+}
+
+// isPublicPath reports whether a path is served without signing in: the login flow, static
+// assets and the activity intake used by the task scheduler
+func isPublicPath(path string) bool {
+
+	return path == "/login" || strings.HasPrefix(path, "/auth/") ||
+		strings.HasPrefix(path, "/static/") || path == "/api/activity" ||
+		path == "/theme.css" || strings.HasPrefix(path, "/branding/")
+}
+
+// requireLogin sends visitors without a session to the login page and refuses anything but
+// reads from viewers
+func (d *DashboardServer) requireLogin(next http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		session, ok := d.auth.authenticate(r)
+		if !ok {
+			if r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+				http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+
+				return
+			}
+			writeAuthError(w, http.StatusUnauthorized, "Sign in to the dashboard first")
+
+			return
+		}
+
+		// Picking a theme only changes how the dashboard looks to the user, and users may revoke
+		// what they granted OAuth clients, which handleOAuthConsents checks
+		if session.Role != RoleAdmin && r.Method != http.MethodGet && r.Method != http.MethodHead &&
+			r.URL.Path != themeAPIPath && !(r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, consentsAPIPath+"/")) {
+			d.logger.Warning("Dashboard user %s (%s) was refused %s %s", session.Username, session.Role, r.Method, r.URL.Path)
+			writeAuthError(w, http.StatusForbidden, "Your dashboard role is read-only")
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionContextKey, session)))
+	})
+}
+
+func requestSession(r *http.Request) *dashboardSession {
+	session, _ := r.Context().Value(sessionContextKey).(*dashboardSession)
+
+	return session
+}
+
+// sessionUsername names the signed-in user for logs
+func sessionUsername(r *http.Request) string {
+	if session := requestSession(r); session != nil {
+
+		return session.Username
+	}
+
+	return "dashboard"
+}
+
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// LoginPageData is rendered by login.html
+type LoginPageData struct {
+	Title        string
+	BrandTitle   string
+	Logo         bool
+	Favicon      bool
+	Error        string
+	Next         string
+	Passwords    bool
+	OAuth        bool
+	ProviderName string
+	CSRFToken    string
+}
+
+func (d *DashboardServer) registerAuthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/login", d.handleLoginPage)
+	mux.HandleFunc("/auth/login", d.handleLogin)
+	mux.HandleFunc("/auth/logout", d.handleLogout)
+	mux.HandleFunc("/auth/session", d.handleSession)
+	mux.HandleFunc("/auth/oauth/start", d.handleOAuthLoginStart)
+	mux.HandleFunc("/auth/oauth/callback", d.handleOAuthLoginCallback)
+	d.logger.Info("Registered: /login, /auth/")
+}
+
+func (d *DashboardServer) renderLogin(w http.ResponseWriter, r *http.Request, status int, next, message string) {
+	branding := d.branding()
+	data := LoginPageData{
+		Title:      "Sign in - MCP-Compose Dashboard",
+		BrandTitle: "MCP-Compose Dashboard",
+		Logo:       branding.Logo != "",
+		Favicon:    branding.Favicon != "",
+		Error:      message,
+		Next:       next,
+		Passwords:  len(d.config.Users) > 0,
+		OAuth:      d.auth.oauthEnabled(),
+		CSRFToken:  requestCSRFToken(r),
+	}
+	if branding.Title != "" {
+		data.Title = "Sign in - " + branding.Title
+		data.BrandTitle = branding.Title
+	}
+	if data.OAuth {
+		data.ProviderName = d.auth.login.OAuth.ProviderName
+		if data.ProviderName == "" {
+			data.ProviderName = "single sign-on"
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+	if err := d.templates.ExecuteTemplate(w, "login.html", data); err != nil {
+		d.logger.Error("Failed to execute login template: %v", err)
+	}
+}
+
+func (d *DashboardServer) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	if d.auth == nil {
+		http.Redirect(w, r, "/", http.StatusFound)
+
+		return
+	}
+	next := safeNext(r.URL.Query().Get("next"))
+	if _, ok := d.auth.authenticate(r); ok {
+		http.Redirect(w, r, next, http.StatusFound)
+
+		return
+	}
+	d.renderLogin(w, r, http.StatusOK, next, r.URL.Query().Get("error"))
+}
+
+func (d *DashboardServer) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	if d.auth == nil {
+		http.Redirect(w, r, "/", http.StatusFound)
+
+		return
+	}
+
+	next := safeNext(r.PostFormValue("next"))
+	username, role, ok := d.auth.checkPassword(r.PostFormValue("username"), r.PostFormValue("password"))
+	if !ok {
+		d.logger.Warning("Failed dashboard sign-in for '%s' from %s", r.PostFormValue("username"), r.RemoteAddr)
+		d.renderLogin(w, r, http.StatusUnauthorized, next, "Invalid username or password")
+
+		return
+	}
+
+	d.startSession(w, r, username, role, "password", next)
+}
+
+func (d *DashboardServer) startSession(w http.ResponseWriter, r *http.Request, username, role, method, next string) {
+	session, err := d.auth.sessions.create(username, role, method)
+	if err != nil {
+		d.logger.Error("Failed to create dashboard session: %v", err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     constants.DashboardSessionCookie,
+		Value:    session.ID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	d.logger.Info("Dashboard user %s signed in as %s (%s)", username, role, method)
+	http.Redirect(w, r, next, http.StatusFound)
+}
+
+func (d *DashboardServer) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	if d.auth != nil {
+		if cookie, err := r.Cookie(constants.DashboardSessionCookie); err == nil {
+			d.auth.sessions.delete(cookie.Value)
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     constants.DashboardSessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// handleSession tells the UI who is signed in
+func (d *DashboardServer) handleSession(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{"loginEnabled": d.auth != nil, "authenticated": d.auth == nil, "role": RoleAdmin}
+	if d.auth != nil {
+		response["role"] = ""
+		if session, ok := d.auth.authenticate(r); ok {
+			response["authenticated"] = true
+			response["username"] = session.Username
+			response["role"] = session.Role
+			response["method"] = session.Method
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (d *DashboardServer) handleOAuthLoginStart(w http.ResponseWriter, r *http.Request) {
+	if d.auth == nil || !d.auth.oauthEnabled() {
+		http.NotFound(w, r)
+
+		return
+	}
+	oauth := d.auth.login.OAuth
+
+	state, err := randomToken()
+	if err != nil {
+		http.Error(w, "Failed to start sign-in", http.StatusInternalServerError)
+
+		return
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		http.Error(w, "Failed to start sign-in", http.StatusInternalServerError)
+
+		return
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	d.auth.mu.Lock()
+	d.auth.oauthStates[state] = oauthLoginState{
+		verifier: verifier,
+		next:     safeNext(r.URL.Query().Get("next")),
+		expires:  time.Now().Add(constants.DashboardOAuthStateTTL),
+	}
+	d.auth.mu.Unlock()
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", oauth.ClientID)
+	query.Set("redirect_uri", d.oauthRedirectURL(r))
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	if len(oauth.Scopes) > 0 {
+		query.Set("scope", strings.Join(oauth.Scopes, " "))
+	}
+
+	separator := "?"
+	if strings.Contains(oauth.AuthorizeURL, "?") {
+		separator = "&"
+	}
+	http.Redirect(w, r, oauth.AuthorizeURL+separator+query.Encode(), http.StatusFound)
+}
+
+func (d *DashboardServer) handleOAuthLoginCallback(w http.ResponseWriter, r *http.Request) {
+	if d.auth == nil || !d.auth.oauthEnabled() {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	query := r.URL.Query()
+	state := query.Get("state")
+	d.auth.mu.Lock()
+	pending, ok := d.auth.oauthStates[state]
+	delete(d.auth.oauthStates, state)
+	d.auth.mu.Unlock()
+
+	if !ok || time.Now().After(pending.expires) {
+		d.renderLogin(w, r, http.StatusBadRequest, "/", "The sign-in attempt expired, please try again")
+
+		return
+	}
+	if errorParam := query.Get("error"); errorParam != "" {
+		d.logger.Warning("OAuth provider refused dashboard sign-in: %s %s", errorParam, query.Get("error_description"))
+		d.renderLogin(w, r, http.StatusUnauthorized, pending.next, "Sign-in was refused by the provider")
+
+		return
+	}
+
+	claims, err := d.fetchOAuthClaims(r, query.Get("code"), pending.verifier)
+	if err != nil {
+		d.logger.Error("Dashboard OAuth sign-in failed: %v", err)
+		d.renderLogin(w, r, http.StatusBadGateway, pending.next, "Sign-in with the provider failed")
+
+		return
+	}
+
+	username, role, ok := d.auth.resolveOAuthUser(claims)
+	if !ok {
+		d.logger.Warning("Dashboard OAuth user '%s' is not allowed to sign in", username)
+		d.renderLogin(w, r, http.StatusForbidden, pending.next, fmt.Sprintf("%s is not allowed to use this dashboard", username))
+
+		return
+	}
+
+	d.startSession(w, r, username, role, "oauth", pending.next)
+}
+
+// fetchOAuthClaims exchanges an authorization code for a token and returns the provider's
+// userinfo for it
+func (d *DashboardServer) fetchOAuthClaims(r *http.Request, code, verifier string) (map[string]interface{}, error) {
+	oauth := d.auth.login.OAuth
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", d.oauthRedirectURL(r))
+	form.Set("client_id", oauth.ClientID)
+	form.Set("code_verifier", verifier)
+	if oauth.ClientSecret != "" {
+		form.Set("client_secret", oauth.ClientSecret)
+	}
+
+	tokenReq, err := http.NewRequestWithContext(r.Context(), http.MethodPost, oauth.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	tokenReq.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := d.auth.doJSON(tokenReq, &token); err != nil {
+
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	if token.AccessToken == "" {
+
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	userReq, err := http.NewRequestWithContext(r.Context(), http.MethodGet, oauth.UserInfoURL, nil)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create userinfo request: %w", err)
+	}
+	userReq.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	userReq.Header.Set("Accept", "application/json")
+
+	claims := make(map[string]interface{})
+	if err := d.auth.doJSON(userReq, &claims); err != nil {
+
+		return nil, fmt.Errorf("failed to fetch userinfo: %w", err)
+	}
+
+	return claims, nil
+}
+
+func (a *dashboardAuth) doJSON(req *http.Request, target interface{}) error {
+	resp, err := a.client.Do(req)
+	if err != nil {
+
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, constants.DashboardMaxResponseSize))
+	if err != nil {
+
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+
+		return fmt.Errorf("%s returned status %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, target)
+}
+
+// resolveOAuthUser names the provider's user and gives them the role of the matching
+// configured user, or the default role when one is set
+func (a *dashboardAuth) resolveOAuthUser(claims map[string]interface{}) (string, string, bool) {
+	oauth := a.login.OAuth
+	claimNames := []string{"preferred_username", "login", "email", "sub"}
+	if oauth.UsernameClaim != "" {
+		claimNames = []string{oauth.UsernameClaim}
+	}
+
+	username := ""
+	for _, claim := range claimNames {
+		if value, ok := claims[claim].(string); ok && value != "" {
+			username = value
+
+			break
+		}
+	}
+	if username == "" {
+
+		return "", "", false
+	}
+
+	email, _ := claims["email"].(string)
+	for _, name := range []string{username, email} {
+		if configured, user := a.findUser(name); user != nil {
+
+			return configured, dashboardRole(user.Role), true
+		}
+	}
+	if oauth.DefaultRole != "" {
+
+		return username, dashboardRole(oauth.DefaultRole), true
+	}
+
+	return username, "", false
+}
+
+func (d *DashboardServer) oauthRedirectURL(r *http.Request) string {
+	if redirect := d.auth.login.OAuth.RedirectURL; redirect != "" {
+
+		return redirect
+	}
+	scheme := "http"
+	if isSecureRequest(r) {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/auth/oauth/callback", scheme, r.Host)
+}
+
+func isSecureRequest(r *http.Request) bool {
+
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// safeNext keeps post-login redirects on the dashboard
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+
+		return "/"
+	}
+
+	return next
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
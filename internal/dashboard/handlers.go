@@ -16,7 +16,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/phildougherty/mcp-compose/internal/compose"
+	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/logfilter"
 
 	"github.com/gorilla/websocket"
 )
@@ -202,10 +206,15 @@ func (d *DashboardServer) getContainerLogs(containerName, tail string, follow bo
 		return nil, fmt.Errorf("docker logs command failed: %w", err)
 	}
 	lines := strings.Split(string(output), "\n")
-	// Filter out empty lines
+	// Filter out empty lines and anything the server's log filters drop
+	filter := d.logFilterFor(containerName)
 	var filteredLines []string
 	for _, line := range lines {
-		if strings.TrimSpace(line) != "" {
+		if strings.TrimSpace(line) == "" {
+
+			continue
+		}
+		if line, _, keep := filter.Apply(line); keep {
 			filteredLines = append(filteredLines, line)
 		}
 	}
@@ -213,6 +222,16 @@ func (d *DashboardServer) getContainerLogs(containerName, tail string, follow bo
 	return filteredLines, nil
 }
 
+// logFilterFor returns the log_filters rules of the server running in a container
+func (d *DashboardServer) logFilterFor(containerName string) *logfilter.Filter {
+	filter, err := logfilter.ForServer(d.config, config.ServerForContainer(containerName))
+	if err != nil {
+		d.logger.Warning("Ignoring log filters for %s: %v", containerName, err)
+	}
+
+	return filter
+}
+
 func (d *DashboardServer) handleActivityReceive(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -289,6 +308,20 @@ func getClientIP(r *http.Request) string {
 	return r.RemoteAddr
 }
 
+// setForwardedFor passes the address of the client behind r on to a request forwarded to the
+// proxy, so the proxy's auth failure log names the client rather than the dashboard when
+// the dashboard is one of its trusted proxies
+func setForwardedFor(req, r *http.Request) {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if forwarded := strings.Join(r.Header.Values("X-Forwarded-For"), ", "); forwarded != "" {
+		peer = forwarded + ", " + peer
+	}
+	req.Header.Set("X-Forwarded-For", peer)
+}
+
 func (d *DashboardServer) handleServerDocs(w http.ResponseWriter, r *http.Request) {
 	// Extract server name from path /api/server-docs/{serverName}
 	path := strings.TrimPrefix(r.URL.Path, "/api/server-docs/")
@@ -399,7 +432,7 @@ func (d *DashboardServer) handleServerLogs(w http.ResponseWriter, r *http.Reques
 	if tail == "" {
 		tail = "100"
 	}
-	containerName := "mcp-compose-" + path
+	containerName := config.ContainerName(path)
 	logs, err := d.getContainerLogs(containerName, tail, false)
 	if err != nil {
 		d.logger.Error("Failed to get logs for %s: %v", containerName, err)
@@ -708,6 +741,7 @@ func (d *DashboardServer) handleOAuthToken(w http.ResponseWriter, r *http.Reques
 	if r.Header.Get("Authorization") != "" {
 		req.Header.Set("Authorization", r.Header.Get("Authorization"))
 	}
+	setForwardedFor(req, r)
 
 	// Make request to main server
 	resp, err := d.httpClient.Do(req)
@@ -748,27 +782,38 @@ func (d *DashboardServer) handleOAuthAuthorize(w http.ResponseWriter, r *http.Re
 		endpoint += "?" + queryString
 	}
 
-	if r.Method == http.MethodPost {
-		// Handle POST requests (form submissions)
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+	// Pages and form submissions are both passed through with the browser's cookies, which
+	// carry the authorization server's sign-in session and CSRF token
+	if r.Method == http.MethodGet || r.Method == http.MethodPost {
+		var body []byte
+		if r.Method == http.MethodPost {
+			var err error
+			if body, err = io.ReadAll(r.Body); err != nil {
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
 
-			return
+				return
+			}
 		}
 
 		proxyURL := d.proxyURL + endpoint
-		req, err := http.NewRequest("POST", proxyURL, bytes.NewBuffer(body))
+		req, err := http.NewRequest(r.Method, proxyURL, bytes.NewBuffer(body))
 		if err != nil {
 			http.Error(w, "Failed to create request", http.StatusInternalServerError)
 
 			return
 		}
 
-		req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if cookies := r.Header.Get("Cookie"); cookies != "" {
+			req.Header.Set("Cookie", cookies)
+		}
+		setForwardedFor(req, r)
 
 		// CRITICAL: Set a custom redirect policy - don't follow redirects!
 		client := &http.Client{
+			Transport: d.proxyTransport,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 
 				return http.ErrUseLastResponse // Don't follow redirects automatically
@@ -793,6 +838,10 @@ func (d *DashboardServer) handleOAuthAuthorize(w http.ResponseWriter, r *http.Re
 			location := resp.Header.Get("Location")
 			if location != "" {
 				d.logger.Info("OAuth server wants to redirect to: %s", location)
+				// Signing in redirects back to the request with the session cookie
+				for _, cookie := range resp.Header.Values("Set-Cookie") {
+					w.Header().Add("Set-Cookie", cookie)
+				}
 
 				// Parse the redirect URL
 				redirectURL, err := url.Parse(location)
@@ -842,19 +891,7 @@ func (d *DashboardServer) handleOAuthAuthorize(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// For GET requests, proxy to main server
-	resp, err := d.proxyRequest(endpoint)
-	if err != nil {
-		d.logger.Error("Failed to get OAuth authorize from proxy: %v", err)
-		http.Error(w, "Failed to process authorization", http.StatusInternalServerError)
-
-		return
-	}
-
-	w.Header().Set("Content-Type", "text/html")
-	if _, err := w.Write(resp); err != nil {
-		d.logger.Error("Failed to write response: %v", err)
-	}
+	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 }
 
 func (d *DashboardServer) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
@@ -1354,200 +1391,52 @@ func (d *DashboardServer) handleAPIProxy(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// Update the task scheduler proxy to use the inspector service
+// handleTaskSchedulerProxy forwards /api/task-scheduler/tasks... to the proxy's task API
 func (d *DashboardServer) handleTaskSchedulerProxy(w http.ResponseWriter, r *http.Request) {
-	// Extract the path after /api/task-scheduler/
 	path := strings.TrimPrefix(r.URL.Path, "/api/task-scheduler")
-
-	d.logger.Info("Task scheduler proxy request: %s %s", r.Method, path)
-
-	// Map REST-like calls to MCP tool calls
-	var toolName string
-	var toolArgs map[string]interface{}
-
-	switch {
-	case path == "/tasks" && r.Method == "GET":
-		toolName = "list_tasks"
-		toolArgs = map[string]interface{}{}
-
-	case path == "/tasks" && r.Method == "POST":
-		toolName = "add_task"
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Failed to read body", http.StatusBadRequest)
-
-			return
-		}
-		if err := json.Unmarshal(body, &toolArgs); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
-
-			return
-		}
-
-	case strings.HasSuffix(path, "/run") && r.Method == "POST":
-		toolName = "run_task"
-		// Extract task ID from path like /tasks/123/run
-		pathParts := strings.Split(strings.Trim(path, "/"), "/")
-		if len(pathParts) >= 2 && pathParts[0] == "tasks" {
-			toolArgs = map[string]interface{}{
-				"id": pathParts[1],
-			}
-		} else {
-			http.Error(w, "Invalid task ID in path", http.StatusBadRequest)
-
-			return
-		}
-
-	case strings.HasSuffix(path, "/enable") && r.Method == "POST":
-		toolName = "enable_task"
-		pathParts := strings.Split(strings.Trim(path, "/"), "/")
-		if len(pathParts) >= 2 && pathParts[0] == "tasks" {
-			toolArgs = map[string]interface{}{
-				"id": pathParts[1],
-			}
-		} else {
-			http.Error(w, "Invalid task ID in path", http.StatusBadRequest)
-
-			return
-		}
-
-	case strings.HasSuffix(path, "/disable") && r.Method == "POST":
-		toolName = "disable_task"
-		pathParts := strings.Split(strings.Trim(path, "/"), "/")
-		if len(pathParts) >= 2 && pathParts[0] == "tasks" {
-			toolArgs = map[string]interface{}{
-				"id": pathParts[1],
-			}
-		} else {
-			http.Error(w, "Invalid task ID in path", http.StatusBadRequest)
-
-			return
-		}
-
-	case strings.Contains(path, "/tasks/") && strings.HasSuffix(path, "/output"):
-		toolName = "get_run_output"
-		pathParts := strings.Split(strings.Trim(path, "/"), "/")
-		if len(pathParts) >= 2 && pathParts[0] == "tasks" {
-			toolArgs = map[string]interface{}{
-				"task_id": pathParts[1],
-			}
-		} else {
-			http.Error(w, "Invalid task ID in path", http.StatusBadRequest)
-
-			return
-		}
-
-	case path == "/runs/status" || path == "/runs/status/":
-		toolName = "list_run_status"
-		toolArgs = map[string]interface{}{}
-
-	case path == "/metrics" || path == "/metrics/":
-		toolName = "get_metrics"
-		toolArgs = map[string]interface{}{}
-
-	default:
-		http.Error(w, fmt.Sprintf("Unsupported operation: %s %s", r.Method, path), http.StatusBadRequest)
+	if path != "/tasks" && !strings.HasPrefix(path, "/tasks/") {
+		http.Error(w, fmt.Sprintf("Unsupported operation: %s %s", r.Method, path), http.StatusNotFound)
 
 		return
 	}
 
-	d.logger.Info("Calling MCP tool: %s with args: %v", toolName, toolArgs)
-
-	// Use the inspector service to make the MCP call
-	if d.inspectorService == nil {
-		http.Error(w, `{"error": "Inspector service not available"}`, http.StatusServiceUnavailable)
-
-		return
+	endpoint := "/api" + path
+	if r.URL.RawQuery != "" {
+		endpoint += "?" + r.URL.RawQuery
 	}
+	d.logger.Info("Task scheduler proxy request: %s %s", r.Method, endpoint)
 
-	// Create a session for the task-scheduler server
-	session, err := d.inspectorService.CreateSession("task-scheduler")
+	req, err := http.NewRequest(r.Method, d.proxyURL+endpoint, r.Body)
 	if err != nil {
-		d.logger.Error("Failed to create task scheduler session: %v", err)
-		http.Error(w, fmt.Sprintf(`{"error": "Failed to create session: %v"}`, err), http.StatusServiceUnavailable)
+		http.Error(w, fmt.Sprintf(`{"error": "Failed to create request: %v"}`, err), http.StatusInternalServerError)
 
 		return
 	}
-
-	// Create the inspector request
-	inspectorReq := InspectorRequest{
-		SessionID: session.ID,
-		Method:    "tools/call",
-		Params:    json.RawMessage(fmt.Sprintf(`{"name": "%s", "arguments": %s}`, toolName, mustJSON(toolArgs))),
+	req.Header.Set("Content-Type", "application/json")
+	if d.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+d.apiKey)
 	}
 
-	// Execute the request
-	response, err := d.inspectorService.ExecuteRequest(session.ID, inspectorReq)
+	resp, err := d.httpClient.Do(req)
 	if err != nil {
-		d.logger.Error("Task scheduler tool call failed: %v", err)
-		http.Error(w, fmt.Sprintf(`{"error": "Tool call failed: %v"}`, err), http.StatusInternalServerError)
+		d.logger.Error("Task API request failed: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error": "Task API request failed: %v"}`, err), http.StatusBadGateway)
 
 		return
 	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			d.logger.Error("Failed to close response body: %v", err)
+		}
+	}()
 
-	// Clean up session
-	if err := d.inspectorService.DestroySession(session.ID); err != nil {
-		d.logger.Error("Failed to destroy session: %v", err)
-	}
-
-	// Return the result
 	w.Header().Set("Content-Type", "application/json")
-
-	// Extract the tool call result
-	if response.Result != nil {
-		// The result should contain the tool output
-		if resultMap, ok := response.Result.(map[string]interface{}); ok {
-			if content, exists := resultMap["content"]; exists {
-				// Tool results are usually in content array
-				if contentArray, ok := content.([]interface{}); ok && len(contentArray) > 0 {
-					if contentItem, ok := contentArray[0].(map[string]interface{}); ok {
-						if text, exists := contentItem["text"]; exists {
-							// Try to parse as JSON, fallback to raw text
-							var jsonResult interface{}
-							if err := json.Unmarshal([]byte(text.(string)), &jsonResult); err == nil {
-								if err := json.NewEncoder(w).Encode(jsonResult); err != nil {
-									d.logger.Error("Failed to encode JSON result: %v", err)
-								}
-
-								return
-							}
-						}
-					}
-				}
-			}
-			// Fallback to returning the whole result
-			if err := json.NewEncoder(w).Encode(resultMap); err != nil {
-				d.logger.Error("Failed to encode result map: %v", err)
-			}
-
-			return
-		}
-		if err := json.NewEncoder(w).Encode(response.Result); err != nil {
-			d.logger.Error("Failed to encode response result: %v", err)
-		}
-	} else if response.Error != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		if err := json.NewEncoder(w).Encode(map[string]interface{}{
-			"error": response.Error,
-		}); err != nil {
-			d.logger.Error("Failed to encode error response: %v", err)
-		}
-	} else {
-		if err := json.NewEncoder(w).Encode(map[string]interface{}{
-			"result": "success",
-		}); err != nil {
-			d.logger.Error("Failed to encode success response: %v", err)
-		}
+	w.WriteHeader(resp.StatusCode)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		d.logger.Error("Failed to write response: %v", err)
 	}
 }
 
-// Helper function to marshal JSON safely
-func mustJSON(v interface{}) string {
-	b, _ := json.Marshal(v)
-
-	return string(b)
-}
-
 // Update health check to use inspector
 func (d *DashboardServer) handleTaskSchedulerHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -1829,7 +1718,7 @@ func (d *DashboardServer) getLogsFromRuntime(ctx context.Context, containerName
 		d.logger.Warning("Command stderr for %s: %s", containerName, stderr.String())
 	}
 
-	return d.parseLogOutput(stdout.String()), nil
+	return d.parseLogOutput(stdout.String(), d.logFilterFor(containerName)), nil
 }
 
 func (d *DashboardServer) streamLogsFromRuntime(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, containerName, tail string, timestamps bool, since string) error {
@@ -1904,6 +1793,7 @@ func (d *DashboardServer) streamLogsFromRuntime(ctx context.Context, w http.Resp
 
 	// Stream stdout line by line
 	scanner := bufio.NewScanner(stdout)
+	filter := d.logFilterFor(containerName)
 	lineCount := 0
 
 	for scanner.Scan() {
@@ -1915,11 +1805,15 @@ func (d *DashboardServer) streamLogsFromRuntime(ctx context.Context, w http.Resp
 		default:
 		}
 
-		line := scanner.Text()
+		line, debug, keep := filter.Apply(scanner.Text())
+		if !keep {
+
+			continue
+		}
 		lineCount++
 
 		// Parse and format the log line
-		logEntry := d.parseLogLine(line, lineCount)
+		logEntry := d.parseLogLine(line, lineCount, debug)
 
 		// Send as SSE event
 		if _, err := fmt.Fprintf(w, "event: log\n"); err != nil {
@@ -1967,7 +1861,7 @@ func (d *DashboardServer) streamLogsFromRuntime(ctx context.Context, w http.Resp
 	return nil
 }
 
-func (d *DashboardServer) parseLogOutput(output string) []string {
+func (d *DashboardServer) parseLogOutput(output string, filter *logfilter.Filter) []string {
 	if output == "" {
 
 		return []string{}
@@ -1977,15 +1871,20 @@ func (d *DashboardServer) parseLogOutput(output string) []string {
 	var result []string
 
 	for i, line := range lines {
-		if line != "" { // Skip empty lines
-			result = append(result, d.parseLogLine(line, i+1))
+		if line == "" { // Skip empty lines
+
+			continue
+		}
+		if line, debug, keep := filter.Apply(line); keep {
+			result = append(result, d.parseLogLine(line, i+1, debug))
 		}
 	}
 
 	return result
 }
 
-func (d *DashboardServer) parseLogLine(line string, lineNumber int) string {
+// parseLogLine formats a line as a JSON log entry; debug forces the level for lines a log filter downgraded
+func (d *DashboardServer) parseLogLine(line string, lineNumber int, debug bool) string {
 	logEntry := map[string]interface{}{
 		"line":      lineNumber,
 		"content":   line,
@@ -2005,7 +1904,9 @@ func (d *DashboardServer) parseLogLine(line string, lineNumber int) string {
 
 	// Try to detect log level
 	content := strings.ToLower(line)
-	if strings.Contains(content, "error") || strings.Contains(content, "err") {
+	if debug {
+		logEntry["level"] = "debug"
+	} else if strings.Contains(content, "error") || strings.Contains(content, "err") {
 		logEntry["level"] = "error"
 	} else if strings.Contains(content, "warn") {
 		logEntry["level"] = "warning"
@@ -2029,25 +1930,13 @@ func (d *DashboardServer) parseLogLine(line string, lineNumber int) string {
 }
 
 func (d *DashboardServer) handleContainerStats(w http.ResponseWriter, _ *http.Request, containerName string) {
-	runtime := d.detectContainerRuntime()
-
-	var cmd *exec.Cmd
-	switch runtime {
-	case "docker":
-		cmd = exec.Command("docker", "stats", "--no-stream", "--format",
-			"table {{.Name}}\t{{.CPUPerc}}\t{{.MemUsage}}\t{{.MemPerc}}\t{{.NetIO}}\t{{.BlockIO}}",
-			containerName)
-	case "podman":
-		cmd = exec.Command("podman", "stats", "--no-stream", "--format",
-			"table {{.Name}}\t{{.CPUPerc}}\t{{.MemUsage}}\t{{.MemPerc}}\t{{.NetIO}}\t{{.BlockIO}}",
-			containerName)
-	default:
-		http.Error(w, "Unsupported container runtime", http.StatusInternalServerError)
+	if d.runtime == nil {
+		http.Error(w, "No container runtime available", http.StatusServiceUnavailable)
 
 		return
 	}
 
-	output, err := cmd.Output()
+	stats, err := d.runtime.GetContainerStats(containerName)
 	if err != nil {
 		d.logger.Error("Failed to get container stats for %s: %v", containerName, err)
 		http.Error(w, fmt.Sprintf("Failed to get stats: %v", err), http.StatusInternalServerError)
@@ -2055,34 +1944,14 @@ func (d *DashboardServer) handleContainerStats(w http.ResponseWriter, _ *http.Re
 		return
 	}
 
-	// Parse stats output
-	lines := strings.Split(string(output), "\n")
-	var stats map[string]interface{}
-	if len(lines) >= 2 && lines[1] != "" {
-		fields := strings.Fields(lines[1])
-		if len(fields) >= constants.LogFieldCount {
-			stats = map[string]interface{}{
-				"name":      fields[0],
-				"cpu_perc":  fields[1],
-				"mem_usage": fields[2],
-				"mem_perc":  fields[3],
-				"net_io":    fields[4],
-				"block_io":  fields[5],
-				"timestamp": time.Now().Format(time.RFC3339),
-				"runtime":   runtime,
-			}
-		}
-	}
-
-	if stats == nil {
-		stats = map[string]interface{}{
-			"error":   "No stats available for container " + containerName,
-			"runtime": runtime,
-		}
-	}
+	response := struct {
+		*container.ContainerStats
+		Timestamp string `json:"timestamp"`
+		Runtime   string `json:"runtime"`
+	}{stats, time.Now().Format(time.RFC3339), d.runtime.GetRuntimeName()}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(stats); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		d.logger.Error("Failed to encode stats: %v", err)
 	}
 }
@@ -2110,65 +1979,207 @@ func (d *DashboardServer) handleServerAction(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	containerName := fmt.Sprintf("mcp-compose-%s", req.Server)
-	runtime := d.detectContainerRuntime()
+	// The proxy's manager holds the full server config, so it can recreate containers
+	// and start process servers the runtime knows nothing about
+	status := d.forwardToProxy(w, r, fmt.Sprintf("/api/v1/servers/%s/%s", url.PathEscape(req.Server), action), nil)
+	switch {
+	case status == 0:
+		d.logger.Error("Failed to reach the proxy to %s server %s", action, req.Server)
+	case status != http.StatusOK:
+		d.logger.Error("Proxy refused to %s server %s: status %d", action, req.Server, status)
+	default:
+		d.logger.Info("Server %s %s requested by %s", req.Server, action, sessionUsername(r))
+	}
+}
 
-	var cmd *exec.Cmd
-	switch action {
-	case "start":
-		// Starting requires rebuilding the container with proper config
-		response := map[string]string{
-			"error": fmt.Sprintf("Server start not implemented in dashboard yet. Use CLI: mcp-compose start %s", req.Server),
+// handleConfigEditor forwards the config editor's requests to the proxy, which owns the
+// compose file and the servers an edit restarts
+func (d *DashboardServer) handleConfigEditor(w http.ResponseWriter, r *http.Request) {
+	if !d.config.Dashboard.ConfigEditor {
+		http.Error(w, "The config editor is disabled", http.StatusNotFound)
+
+		return
+	}
+	// The compose file can hold credentials, so viewers cannot read it either
+	if session := requestSession(r); session != nil && session.Role != RoleAdmin {
+		writeAuthError(w, http.StatusForbidden, "Editing the configuration requires the admin role")
+
+		return
+	}
+
+	var body io.Reader
+	if r.Method == http.MethodPost {
+		body = io.LimitReader(r.Body, constants.ConfigEditorMaxSize)
+	}
+	status := d.forwardToProxy(w, r, r.URL.Path, body)
+	if status == http.StatusOK {
+		switch r.URL.Path {
+		case "/api/config/apply":
+			d.logger.Info("Config file edited by %s", sessionUsername(r))
+		case "/api/config/rollback":
+			d.logger.Info("Config file rolled back by %s", sessionUsername(r))
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotImplemented)
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			d.logger.Error("Failed to encode response: %v", err)
+	}
+}
+
+// handleSamplingQueue forwards the sampling approval queue's requests to the proxy. Anyone
+// signed in may watch the queue; reviewing requests needs the admin role, and the review is
+// recorded under the reviewer's name.
+func (d *DashboardServer) handleSamplingQueue(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader
+	if r.Method == http.MethodPost {
+		if session := requestSession(r); session != nil && session.Role != RoleAdmin {
+			writeAuthError(w, http.StatusForbidden, "Reviewing sampling requests requires the admin role")
+
+			return
 		}
+		review := make(map[string]interface{})
+		if err := json.NewDecoder(io.LimitReader(r.Body, constants.SamplingAPIMaxBodySize)).Decode(&review); err != nil && err != io.EOF {
+			writeAuthError(w, http.StatusBadRequest, fmt.Sprintf("Invalid review: %v", err))
 
-		return
-	case "stop":
-		if runtime == "docker" {
-			cmd = exec.Command("docker", "stop", containerName)
-		} else {
-			cmd = exec.Command("podman", "stop", containerName)
+			return
 		}
-	case "restart":
-		if runtime == "docker" {
-			cmd = exec.Command("docker", "restart", containerName)
-		} else {
-			cmd = exec.Command("podman", "restart", containerName)
+		review["reviewer"] = sessionUsername(r)
+		data, err := json.Marshal(review)
+		if err != nil {
+			writeAuthError(w, http.StatusInternalServerError, "Failed to encode review")
+
+			return
 		}
-	default:
-		http.Error(w, "Unknown action", http.StatusBadRequest)
+		body = bytes.NewReader(data)
+	}
 
-		return
+	path := r.URL.Path
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
 	}
+	d.forwardToProxy(w, r, path, body)
+}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		d.logger.Error("Failed to %s container %s: %v. Output: %s", action, containerName, err, string(output))
-		response := map[string]string{
-			"error":   fmt.Sprintf("Failed to %s container: %v", action, err),
-			"output":  string(output),
-			"runtime": runtime,
+// handleDeviceSignIns lists the device sign-ins waiting to be approved and approves or denies
+// them on behalf of the signed-in user, whom an approved device's tokens act for
+func (d *DashboardServer) handleDeviceSignIns(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader
+	if r.Method == http.MethodPost {
+		if session := requestSession(r); session != nil && session.Role != RoleAdmin {
+			writeAuthError(w, http.StatusForbidden, "Approving device sign-ins requires the admin role")
+
+			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			d.logger.Error("Failed to encode response: %v", err)
+		data, err := json.Marshal(map[string]string{"user": sessionUsername(r)})
+		if err != nil {
+			writeAuthError(w, http.StatusInternalServerError, "Failed to encode review")
+
+			return
+		}
+		body = bytes.NewReader(data)
+	}
+	d.forwardToProxy(w, r, r.URL.Path, body)
+}
+
+const consentsAPIPath = "/api/oauth/consents"
+
+// handleOAuthConsents lists the scopes users have granted OAuth clients and revokes them.
+// Admins see and revoke everyone's; other users only their own.
+func (d *DashboardServer) handleOAuthConsents(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	session := requestSession(r)
+	if session == nil || session.Role == RoleAdmin {
+		if r.URL.RawQuery != "" {
+			path += "?" + r.URL.RawQuery
+		}
+		d.forwardToProxy(w, r, path, nil)
+
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		parts := strings.Split(strings.TrimPrefix(path, consentsAPIPath+"/"), "/")
+		if user, err := url.PathUnescape(parts[0]); err != nil || user != session.Username {
+			writeAuthError(w, http.StatusForbidden, "Revoking another user's consent requires the admin role")
+
+			return
 		}
+	} else {
+		path += "?user=" + url.QueryEscape(session.Username)
+	}
+	d.forwardToProxy(w, r, path, nil)
+}
+
+// handleSupportBundle streams a support bundle: redacted config, recent logs, versions and
+// health, the same archive as `mcp-compose support-bundle`
+func (d *DashboardServer) handleSupportBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 
 		return
 	}
+	if session := requestSession(r); session != nil && session.Role != RoleAdmin {
+		writeAuthError(w, http.StatusForbidden, "Downloading a support bundle requires the admin role")
 
-	response := map[string]string{
-		"success": fmt.Sprintf("Container %s %sed successfully", containerName, action),
-		"output":  string(output),
-		"runtime": runtime,
+		return
 	}
+
+	fileName := fmt.Sprintf("mcp-compose-support-%s.tar.gz", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	err := compose.WriteSupportBundle(w, d.config, d.runtime, compose.SupportBundleOptions{
+		ProxyURL: d.proxyURL,
+		APIKey:   d.apiKey,
+		Version:  d.version,
+	})
+	if err != nil {
+		// Headers are already sent, so the download ends up truncated
+		d.logger.Error("Failed to write support bundle: %v", err)
+
+		return
+	}
+	d.logger.Info("Support bundle downloaded by %s", sessionUsername(r))
+}
+
+// forwardToProxy sends the request's method and body to a proxy endpoint and passes the
+// proxy's status and JSON response back. It returns the proxy's status, or 0 when the
+// proxy could not be reached.
+func (d *DashboardServer) forwardToProxy(w http.ResponseWriter, r *http.Request, path string, body io.Reader) int {
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		d.logger.Error("Failed to encode response: %v", err)
+
+	proxyReq, err := http.NewRequestWithContext(r.Context(), r.Method, d.proxyURL+path, body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create request"})
+
+		return 0
+	}
+	if body != nil {
+		proxyReq.Header.Set("Content-Type", "application/json")
+	}
+	if d.apiKey != "" {
+		proxyReq.Header.Set("Authorization", "Bearer "+d.apiKey)
 	}
+
+	client := &http.Client{Timeout: constants.DashboardServerActionTimeout, Transport: d.proxyTransport}
+	resp, err := client.Do(proxyReq)
+	if err != nil {
+		d.logger.Error("Failed to reach the proxy at %s: %v", path, err)
+		w.WriteHeader(http.StatusBadGateway)
+		if err := json.NewEncoder(w).Encode(map[string]string{
+			"error": fmt.Sprintf("Failed to reach the proxy: %v", err),
+		}); err != nil {
+			d.logger.Error("Failed to encode response: %v", err)
+		}
+
+		return 0
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			d.logger.Error("Failed to close response body: %v", err)
+		}
+	}()
+
+	w.WriteHeader(resp.StatusCode)
+	if _, err := io.Copy(w, io.LimitReader(resp.Body, constants.DashboardMaxResponseSize)); err != nil {
+		d.logger.Error("Failed to write response: %v", err)
+	}
+
+	return resp.StatusCode
 }
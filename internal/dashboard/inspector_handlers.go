@@ -224,6 +224,95 @@ func (d *DashboardServer) handleInspectorDisconnect(w http.ResponseWriter, r *ht
 	}
 }
 
+// handleInspectorTools lists the session's tools with their input schemas, for building
+// tools/call forms
+func (d *DashboardServer) handleInspectorTools(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	tools, err := d.inspectorService.ListTools(r.URL.Query().Get("sessionId"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusBadGateway
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		http.Error(w, jsonError(err.Error()), status)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"tools": tools,
+	}); err != nil {
+		d.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
+
+func (d *DashboardServer) handleInspectorHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	history, err := d.inspectorService.History(r.URL.Query().Get("sessionId"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, jsonError(err.Error()), http.StatusNotFound)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"history": history,
+	}); err != nil {
+		d.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
+
+func (d *DashboardServer) handleInspectorReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var request struct {
+		SessionID string `json:"sessionId"`
+		EntryID   int    `json:"entryId"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, jsonError("Invalid request body"), http.StatusBadRequest)
+
+		return
+	}
+
+	response, err := d.inspectorService.Replay(request.SessionID, request.EntryID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, jsonError(err.Error()), http.StatusNotFound)
+		} else {
+			http.Error(w, jsonError(err.Error()), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		d.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
+
 func jsonError(message string) string {
 
 	return `{"error": "` + strings.ReplaceAll(message, `"`, `\"`) + `"}`
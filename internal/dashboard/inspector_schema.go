@@ -0,0 +1,266 @@
+// internal/dashboard/inspector_schema.go
+package dashboard
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// validateAgainstSchema checks a value against the subset of JSON Schema that tool input
+// schemas use (type, required, properties, additionalProperties, items, enum, const,
+// bounds, lengths, pattern and the anyOf/oneOf/allOf combinators), returning one problem
+// per violation
+func validateAgainstSchema(schema map[string]interface{}, value interface{}, path string) []string {
+	if schema == nil {
+
+		return nil
+	}
+	var problems []string
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesSchemaType(t, value) {
+				matched = true
+
+				break
+			}
+		}
+		if !matched {
+
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(types, " or "), jsonTypeName(value))}
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsJSONValue(enum, value) {
+		problems = append(problems, fmt.Sprintf("%s: must be one of %s", path, formatJSONValues(enum)))
+	}
+	if constant, exists := schema["const"]; exists && !jsonValuesEqual(constant, value) {
+		problems = append(problems, fmt.Sprintf("%s: must be %v", path, constant))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		problems = append(problems, validateObject(schema, v, path)...)
+	case []interface{}:
+		if min, ok := schemaNumber(schema, "minItems"); ok && float64(len(v)) < min {
+			problems = append(problems, fmt.Sprintf("%s: must have at least %v items", path, min))
+		}
+		if max, ok := schemaNumber(schema, "maxItems"); ok && float64(len(v)) > max {
+			problems = append(problems, fmt.Sprintf("%s: must have at most %v items", path, max))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				problems = append(problems, validateAgainstSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := schemaNumber(schema, "minLength"); ok && length < min {
+			problems = append(problems, fmt.Sprintf("%s: must be at least %v characters", path, min))
+		}
+		if max, ok := schemaNumber(schema, "maxLength"); ok && length > max {
+			problems = append(problems, fmt.Sprintf("%s: must be at most %v characters", path, max))
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				problems = append(problems, fmt.Sprintf("%s: must match pattern %s", path, pattern))
+			}
+		}
+	case float64:
+		if min, ok := schemaNumber(schema, "minimum"); ok && v < min {
+			problems = append(problems, fmt.Sprintf("%s: must be at least %v", path, min))
+		}
+		if max, ok := schemaNumber(schema, "maximum"); ok && v > max {
+			problems = append(problems, fmt.Sprintf("%s: must be at most %v", path, max))
+		}
+		if min, ok := schemaNumber(schema, "exclusiveMinimum"); ok && v <= min {
+			problems = append(problems, fmt.Sprintf("%s: must be greater than %v", path, min))
+		}
+		if max, ok := schemaNumber(schema, "exclusiveMaximum"); ok && v >= max {
+			problems = append(problems, fmt.Sprintf("%s: must be less than %v", path, max))
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				problems = append(problems, validateAgainstSchema(subSchema, value, path)...)
+			}
+		}
+	}
+	// oneOf is checked like anyOf: the inspector only needs to catch values no branch accepts
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		branches, ok := schema[keyword].([]interface{})
+		if !ok || len(branches) == 0 {
+
+			continue
+		}
+		matched := false
+		for _, sub := range branches {
+			if subSchema, ok := sub.(map[string]interface{}); ok && len(validateAgainstSchema(subSchema, value, path)) == 0 {
+				matched = true
+
+				break
+			}
+		}
+		if !matched {
+			problems = append(problems, fmt.Sprintf("%s: does not match any of the allowed schemas", path))
+		}
+	}
+
+	return problems
+}
+
+func validateObject(schema map[string]interface{}, object map[string]interface{}, path string) []string {
+	var problems []string
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, exists := object[key]; !exists {
+					problems = append(problems, fmt.Sprintf("%s.%s: is required", path, key))
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if property, ok := properties[key].(map[string]interface{}); ok {
+			problems = append(problems, validateAgainstSchema(property, object[key], path+"."+key)...)
+
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				problems = append(problems, fmt.Sprintf("%s.%s: is not an allowed property", path, key))
+			}
+		case map[string]interface{}:
+			problems = append(problems, validateAgainstSchema(additional, object[key], path+"."+key)...)
+		}
+	}
+
+	return problems
+}
+
+func schemaTypes(value interface{}) []string {
+	switch t := value.(type) {
+	case string:
+
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+
+		return types
+	default:
+
+		return nil
+	}
+}
+
+func matchesSchemaType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+
+		return ok
+	case "string":
+		_, ok := value.(string)
+
+		return ok
+	case "number":
+		_, ok := value.(float64)
+
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+
+		return ok
+	case "null":
+
+		return value == nil
+	default:
+
+		return true
+	}
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+
+		return "object"
+	case []interface{}:
+
+		return "array"
+	case string:
+
+		return "string"
+	case float64:
+
+		return "number"
+	case bool:
+
+		return "boolean"
+	case nil:
+
+		return "null"
+	default:
+
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func schemaNumber(schema map[string]interface{}, keyword string) (float64, bool) {
+	number, ok := schema[keyword].(float64)
+
+	return number, ok
+}
+
+func containsJSONValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if jsonValuesEqual(candidate, value) {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonValuesEqual(a, b interface{}) bool {
+
+	return fmt.Sprintf("%#v", a) == fmt.Sprintf("%#v", b)
+}
+
+func formatJSONValues(values []interface{}) string {
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		formatted = append(formatted, fmt.Sprintf("%v", value))
+	}
+
+	return strings.Join(formatted, ", ")
+}
@@ -3,6 +3,7 @@ package dashboard
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/telemetry"
 )
 
 type InspectorService struct {
@@ -21,16 +23,39 @@ type InspectorService struct {
 	proxyURL   string
 	apiKey     string
 	httpClient *http.Client
+	tracer     *telemetry.Tracer
 	sessions   map[string]*InspectorSession
 	sessionsMu sync.RWMutex
 }
 
 type InspectorSession struct {
-	ID           string                 `json:"id"`
-	ServerName   string                 `json:"serverName"`
-	CreatedAt    time.Time              `json:"createdAt"`
-	LastUsed     time.Time              `json:"lastUsed"`
-	Capabilities map[string]interface{} `json:"capabilities,omitempty"`
+	ID           string                  `json:"id"`
+	ServerName   string                  `json:"serverName"`
+	CreatedAt    time.Time               `json:"createdAt"`
+	LastUsed     time.Time               `json:"lastUsed"`
+	Capabilities map[string]interface{}  `json:"capabilities,omitempty"`
+	History      []InspectorHistoryEntry `json:"history,omitempty"`
+
+	tools       map[string]InspectorTool // from the last tools/list, for validating tools/call
+	nextEntryID int
+}
+
+// InspectorTool is a tool as listed by the server, with the schema its arguments must match
+type InspectorTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"inputSchema,omitempty"`
+}
+
+// InspectorHistoryEntry is a request made in a session, kept so it can be inspected and replayed
+type InspectorHistoryEntry struct {
+	ID         int                `json:"id"`
+	Method     string             `json:"method"`
+	Params     json.RawMessage    `json:"params,omitempty"`
+	Response   *InspectorResponse `json:"response,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Timestamp  time.Time          `json:"timestamp"`
+	DurationMs int64              `json:"durationMs"`
 }
 
 type InspectorRequest struct {
@@ -46,12 +71,13 @@ type InspectorResponse struct {
 	Error   interface{} `json:"error,omitempty"`
 }
 
-func NewInspectorService(logger *logging.Logger, proxyURL, apiKey string) *InspectorService {
+func NewInspectorService(logger *logging.Logger, proxyURL, apiKey string, tracer *telemetry.Tracer) *InspectorService {
 
 	return &InspectorService{
 		logger:   logger,
 		proxyURL: proxyURL,
 		apiKey:   apiKey,
+		tracer:   tracer,
 		sessions: make(map[string]*InspectorSession),
 		httpClient: &http.Client{
 			Timeout: constants.DefaultReadTimeout,
@@ -122,19 +148,222 @@ func (is *InspectorService) ExecuteRequest(sessionID string, req InspectorReques
 		is.logger.Info("Using empty params for %s.%s", session.ServerName, req.Method)
 	}
 
+	started := time.Now()
+
+	// Check tool arguments against the tool's input schema before sending them, answering
+	// the way a server would reject invalid params
+	if req.Method == "tools/call" {
+		if problems := is.validateToolCall(session, params); len(problems) > 0 {
+			response := &InspectorResponse{
+				JSONRPC: "2.0",
+				Error: map[string]interface{}{
+					"code":    -32602,
+					"message": "Invalid params: " + strings.Join(problems, "; "),
+					"data":    map[string]interface{}{"problems": problems, "validatedBy": "inspector"},
+				},
+			}
+			is.recordHistory(session, req, response, nil, started)
+
+			return response, nil
+		}
+	}
+
 	// Execute the request via the MCP proxy
 	response, err := is.proxyRequest(session.ServerName, req.Method, params)
+	is.recordHistory(session, req, response, err, started)
 	if err != nil {
 		is.logger.Error("Proxy request failed for %s.%s: %v", session.ServerName, req.Method, err)
 
 		return nil, fmt.Errorf("proxy request failed: %w", err)
 	}
+	if req.Method == "tools/list" && response.Error == nil {
+		if tools, ok := parseTools(response.Result); ok {
+			is.sessionsMu.Lock()
+			if session.tools == nil {
+				session.tools = make(map[string]InspectorTool, len(tools))
+			}
+			for _, tool := range tools {
+				session.tools[tool.Name] = tool
+			}
+			is.sessionsMu.Unlock()
+		}
+	}
 
 	is.logger.Info("Inspector request %s.%s executed successfully", session.ServerName, req.Method)
 
 	return response, nil
 }
 
+// ListTools fetches every page of the server's tools with their input schemas
+func (is *InspectorService) ListTools(sessionID string) ([]InspectorTool, error) {
+	session, err := is.GetSession(sessionID)
+	if err != nil {
+
+		return nil, err
+	}
+
+	var tools []InspectorTool
+	cursor := ""
+	for page := 0; page < constants.InspectorMaxToolsPages; page++ {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		response, err := is.proxyRequest(session.ServerName, "tools/list", params)
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to list tools: %w", err)
+		}
+		if response.Error != nil {
+
+			return nil, fmt.Errorf("failed to list tools: %v", response.Error)
+		}
+		pageTools, _ := parseTools(response.Result)
+		tools = append(tools, pageTools...)
+
+		result, _ := response.Result.(map[string]interface{})
+		next, _ := result["nextCursor"].(string)
+		if next == "" {
+
+			break
+		}
+		cursor = next
+	}
+
+	is.sessionsMu.Lock()
+	session.tools = make(map[string]InspectorTool, len(tools))
+	for _, tool := range tools {
+		session.tools[tool.Name] = tool
+	}
+	is.sessionsMu.Unlock()
+
+	return tools, nil
+}
+
+// History returns the requests made in a session, oldest first
+func (is *InspectorService) History(sessionID string) ([]InspectorHistoryEntry, error) {
+	is.sessionsMu.RLock()
+	defer is.sessionsMu.RUnlock()
+
+	session, exists := is.sessions[sessionID]
+	if !exists {
+
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	return append([]InspectorHistoryEntry(nil), session.History...), nil
+}
+
+// Replay sends a request from the session's history again
+func (is *InspectorService) Replay(sessionID string, entryID int) (*InspectorResponse, error) {
+	history, err := is.History(sessionID)
+	if err != nil {
+
+		return nil, err
+	}
+	for _, entry := range history {
+		if entry.ID == entryID {
+
+			return is.ExecuteRequest(sessionID, InspectorRequest{SessionID: sessionID, Method: entry.Method, Params: entry.Params})
+		}
+	}
+
+	return nil, fmt.Errorf("history entry %d not found in session %s", entryID, sessionID)
+}
+
+// validateToolCall checks tools/call arguments against the tool's input schema, listing the
+// tools first if the session hasn't yet. Servers that can't list tools aren't validated.
+func (is *InspectorService) validateToolCall(session *InspectorSession, params interface{}) []string {
+	call, ok := params.(map[string]interface{})
+	if !ok {
+
+		return []string{"params: expected an object with name and arguments"}
+	}
+	name, ok := call["name"].(string)
+	if !ok || name == "" {
+
+		return []string{"name: is required"}
+	}
+
+	is.sessionsMu.RLock()
+	listed := session.tools != nil
+	is.sessionsMu.RUnlock()
+	if !listed {
+		if _, err := is.ListTools(session.ID); err != nil {
+			is.logger.Info("Skipping argument validation for %s.%s: %v", session.ServerName, name, err)
+
+			return nil
+		}
+	}
+
+	is.sessionsMu.RLock()
+	tool, exists := session.tools[name]
+	is.sessionsMu.RUnlock()
+	if !exists {
+
+		return []string{fmt.Sprintf("name: unknown tool '%s'", name)}
+	}
+
+	arguments, present := call["arguments"]
+	if !present || arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+
+	return validateAgainstSchema(tool.InputSchema, arguments, "arguments")
+}
+
+func (is *InspectorService) recordHistory(session *InspectorSession, req InspectorRequest, response *InspectorResponse, err error, started time.Time) {
+	is.sessionsMu.Lock()
+	defer is.sessionsMu.Unlock()
+
+	session.nextEntryID++
+	entry := InspectorHistoryEntry{
+		ID:         session.nextEntryID,
+		Method:     req.Method,
+		Params:     req.Params,
+		Response:   response,
+		Timestamp:  started,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	session.History = append(session.History, entry)
+	if len(session.History) > constants.InspectorHistoryLimit {
+		session.History = session.History[len(session.History)-constants.InspectorHistoryLimit:]
+	}
+}
+
+func parseTools(result interface{}) ([]InspectorTool, bool) {
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+
+		return nil, false
+	}
+	items, ok := resultMap["tools"].([]interface{})
+	if !ok {
+
+		return nil, false
+	}
+
+	tools := make([]InspectorTool, 0, len(items))
+	for _, item := range items {
+		tool, ok := item.(map[string]interface{})
+		if !ok {
+
+			continue
+		}
+		name, _ := tool["name"].(string)
+		description, _ := tool["description"].(string)
+		schema, _ := tool["inputSchema"].(map[string]interface{})
+		if name != "" {
+			tools = append(tools, InspectorTool{Name: name, Description: description, InputSchema: schema})
+		}
+	}
+
+	return tools, true
+}
+
 func (is *InspectorService) DestroySession(sessionID string) error {
 	is.sessionsMu.Lock()
 	session, exists := is.sessions[sessionID]
@@ -228,6 +457,21 @@ func (is *InspectorService) getServerCapabilities(serverName string) (map[string
 }
 
 func (is *InspectorService) proxyRequest(serverName, method string, params interface{}) (*InspectorResponse, error) {
+	ctx, span := is.tracer.StartSpan(context.Background(), "inspector "+method, telemetry.SpanKindClient)
+	span.SetAttribute("rpc.system", "jsonrpc")
+	span.SetAttribute("rpc.method", method)
+	span.SetAttribute("mcp.server", serverName)
+
+	response, err := is.doProxyRequest(ctx, serverName, method, params)
+	if err == nil && response.Error != nil {
+		span.SetError(fmt.Sprintf("%v", response.Error))
+	}
+	span.End(err)
+
+	return response, err
+}
+
+func (is *InspectorService) doProxyRequest(ctx context.Context, serverName, method string, params interface{}) (*InspectorResponse, error) {
 	is.logger.Info("Creating MCP request for %s.%s with params: %v (type: %T)", serverName, method, params, params)
 
 	mcpRequest := map[string]interface{}{
@@ -265,7 +509,7 @@ func (is *InspectorService) proxyRequest(serverName, method string, params inter
 	// Create a proper reader for the request body using bytes.Buffer
 	bodyBuffer := bytes.NewBuffer(requestBytes)
 
-	req, err := http.NewRequest("POST", proxyURL, bodyBuffer)
+	req, err := http.NewRequestWithContext(ctx, "POST", proxyURL, bodyBuffer)
 	if err != nil {
 		is.logger.Error("Failed to create HTTP request: %v", err)
 
@@ -280,6 +524,7 @@ func (is *InspectorService) proxyRequest(serverName, method string, params inter
 	if is.apiKey != "" {
 		req.Header.Set("Authorization", "Bearer "+is.apiKey)
 	}
+	telemetry.Inject(ctx, req.Header)
 
 	is.logger.Info("About to send HTTP request with headers: %v", req.Header)
 	is.logger.Info("Request body buffer size: %d", bodyBuffer.Len())
@@ -2,13 +2,17 @@ package dashboard
 
 import (
 	"fmt"
+	"github.com/phildougherty/mcp-compose/internal/certs"
 	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/container"
 	"github.com/phildougherty/mcp-compose/internal/logging"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 type Manager struct {
@@ -16,6 +20,7 @@ type Manager struct {
 	runtime         container.Runtime
 	logger          *logging.Logger
 	configFile      string
+	insecure        bool
 	activityStorage *ActivityStorage
 }
 
@@ -45,6 +50,12 @@ func (m *Manager) SetConfigFile(configFile string) {
 	m.configFile = configFile
 }
 
+// SetInsecure lets the dashboard in the container start exposed without admin_login, as
+// --insecure did the command starting it
+func (m *Manager) SetInsecure(insecure bool) {
+	m.insecure = insecure
+}
+
 func (m *Manager) Start() error {
 	if !m.config.Dashboard.Enabled {
 
@@ -52,7 +63,7 @@ func (m *Manager) Start() error {
 	}
 
 	// Check if dashboard container is already running
-	status, err := m.runtime.GetContainerStatus("mcp-compose-dashboard")
+	status, err := m.runtime.GetContainerStatus(config.ContainerName("dashboard"))
 	if err == nil && status == "running" {
 		m.logger.Info("Dashboard container is already running")
 
@@ -79,7 +90,7 @@ func (m *Manager) Stop() error {
 		}
 	}
 
-	err := m.runtime.StopContainer("mcp-compose-dashboard")
+	err := m.runtime.StopContainer(config.ContainerName("dashboard"))
 	if err != nil {
 
 		return fmt.Errorf("failed to stop dashboard container: %w", err)
@@ -116,9 +127,9 @@ func (m *Manager) buildDashboardImage() error {
 
 func (m *Manager) startDashboardContainer() error {
 	// Ensure network exists
-	networkExists, _ := m.runtime.NetworkExists("mcp-net")
+	networkExists, _ := m.runtime.NetworkExists(config.DefaultNetwork())
 	if !networkExists {
-		if err := m.runtime.CreateNetwork("mcp-net"); err != nil {
+		if err := m.runtime.CreateNetwork(config.DefaultNetwork()); err != nil {
 
 			return fmt.Errorf("failed to create network: %w", err)
 		}
@@ -158,10 +169,15 @@ func (m *Manager) startDashboardContainer() error {
 	// Container always listens on port 3001 internally
 	containerPort := 3001
 
+	proxyURL := "http://" + config.ContainerName("http-proxy") + ":9876" // Container network URL
+	if m.config.ProxyListenerTLS().Enabled {
+		proxyURL = "https://" + config.ContainerName("http-proxy") + ":9876"
+	}
+
 	// Prepare environment variables for container
 	env := map[string]string{
-		"MCP_DASHBOARD_HOST":          "0.0.0.0",                            // Must bind to all interfaces in container
-		"MCP_PROXY_URL":               "http://mcp-compose-http-proxy:9876", // Container network URL
+		"MCP_DASHBOARD_HOST":          "0.0.0.0", // Must bind to all interfaces in container
+		"MCP_PROXY_URL":               proxyURL,
 		"MCP_API_KEY":                 m.config.ProxyAuth.APIKey,
 		"MCP_DASHBOARD_THEME":         m.config.Dashboard.Theme,
 		"MCP_DASHBOARD_LOG_STREAMING": strconv.FormatBool(m.config.Dashboard.LogStreaming),
@@ -169,19 +185,72 @@ func (m *Manager) startDashboardContainer() error {
 		"MCP_DASHBOARD_METRICS":       strconv.FormatBool(m.config.Dashboard.Metrics),
 		"POSTGRES_URL":                m.config.Dashboard.PostgresURL,
 	}
+	if project := config.ProjectNamespace(); project != "" {
+		env[config.ProjectNameEnv] = project
+	}
 
 	// Prepare volumes - mount config file and docker socket
 	volumes := []string{
 		"/var/run/docker.sock:/var/run/docker.sock:ro",         // For Docker API access
 		fmt.Sprintf("%s:/app/mcp-compose.yaml:ro", configPath), // Mount config file
 	}
+	// The dashboard's own certificate, and the proxy's so the dashboard can trust it
+	dashboardTLS, err := certs.ContainerVolumes(m.config.Dashboard.TLS, filepath.Dir(configPath), "/app", false)
+	if err != nil {
+
+		return err
+	}
+	proxyTLS, err := certs.ContainerVolumes(m.config.ProxyListenerTLS(), filepath.Dir(configPath), "/app", true)
+	if err != nil {
+
+		return err
+	}
+	for _, volume := range append(dashboardTLS, proxyTLS...) {
+		if !slices.Contains(volumes, volume) {
+			volumes = append(volumes, volume)
+		}
+	}
+	// And the logo and favicon it brands itself with
+	if branding := m.config.Dashboard.Branding; branding != nil {
+		for _, asset := range []string{branding.Logo, branding.Favicon} {
+			if asset == "" {
+
+				continue
+			}
+			hostPath, containerPath := asset, asset
+			if !filepath.IsAbs(asset) {
+				hostPath, containerPath = filepath.Join(filepath.Dir(configPath), asset), filepath.Join("/app", asset)
+			}
+			if volume := fmt.Sprintf("%s:%s:ro", hostPath, containerPath); !slices.Contains(volumes, volume) {
+				volumes = append(volumes, volume)
+			}
+		}
+	}
+	// The published port decides who reaches the dashboard: only this machine unless exposed
+	publishHost := m.config.Dashboard.BindHost()
+	if strings.EqualFold(publishHost, "localhost") {
+		publishHost = constants.LoopbackHostInterface
+	}
+	ports := []string{fmt.Sprintf("%s:%d:%d", publishHost, hostPort, containerPort)} // host:hostPort:3001
+	if acme := m.config.Dashboard.TLS.ACME; m.config.Dashboard.TLS.Enabled && acme != nil && acme.HTTPPort > 0 {
+		ports = append(ports, fmt.Sprintf("%s:%d:%d", publishHost, acme.HTTPPort, acme.HTTPPort))
+	}
+
+	// The dashboard checks its exposure again, against the host its port is published on
+	dashboardArgs := []string{"dashboard", "--native", "--file", "/app/mcp-compose.yaml",
+		"--port", strconv.Itoa(containerPort), "--host", constants.DefaultHostInterface, "--published-host", publishHost}
+	if m.insecure {
+		dashboardArgs = append(dashboardArgs, "--insecure")
+	}
 
 	opts := &container.ContainerOptions{
-		Name:     "mcp-compose-dashboard",
+		Name:     config.ContainerName("dashboard"),
 		Image:    "mcp-compose-dashboard:latest",
+		Command:  "./mcp-compose",
+		Args:     dashboardArgs,
 		Env:      env,
-		Ports:    []string{fmt.Sprintf("%d:%d", hostPort, containerPort)}, // hostPort:3001
-		Networks: []string{"mcp-net"},
+		Ports:    ports,
+		Networks: []string{config.DefaultNetwork()},
 		Volumes:  volumes,
 		// Security configuration for dashboard:
 		User: "1000:1000", // Run as non-root user
@@ -214,7 +283,11 @@ func (m *Manager) startDashboardContainer() error {
 	}
 
 	m.logger.Info("Dashboard container started with ID: %s", containerID[:12])
-	m.logger.Info("Dashboard available at http://localhost:%d", hostPort)
+	scheme := "http"
+	if m.config.Dashboard.TLS.Enabled {
+		scheme = "https"
+	}
+	m.logger.Info("Dashboard available at %s://localhost:%d", scheme, hostPort)
 	m.logger.Info("Config file mounted from: %s", configPath)
 	m.logger.Info("Container listening on port %d, mapped to host port %d", containerPort, hostPort)
 
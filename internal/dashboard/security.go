@@ -0,0 +1,176 @@
+// internal/dashboard/security.go
+package dashboard
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+const (
+	csrfHeader = "X-CSRF-Token"
+	csrfField  = "csrf_token"
+
+	csrfContextKey authContextKey = "dashboard_csrf"
+)
+
+// dashboardCORSHeaders are the headers the dashboard's API clients send, unless the cors
+// config names its own
+var dashboardCORSHeaders = []string{"Content-Type", "Authorization", csrfHeader}
+
+// contentSecurityPolicy lets the dashboard load Vue and Tailwind from their CDNs. Vue compiles
+// the templates in the page, which takes 'unsafe-eval'.
+const contentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' 'unsafe-eval' https://unpkg.com https://cdn.tailwindcss.com; " +
+	"style-src 'self' 'unsafe-inline'; img-src 'self' data:; connect-src 'self' ws: wss:; " +
+	"object-src 'none'; frame-ancestors 'none'; base-uri 'self'"
+
+// setSecurityHeaders sets the headers every dashboard response is served with
+func setSecurityHeaders(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("Referrer-Policy", "same-origin")
+	header.Set("Content-Security-Policy", contentSecurityPolicy)
+	if isSecureRequest(r) {
+		header.Set("Strict-Transport-Security", fmt.Sprintf("max-age=%d", int(constants.HSTSMaxAge.Seconds())))
+	}
+}
+
+func safeMethod(method string) bool {
+
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// csrfExempt reports whether a path is called by other programs rather than the dashboard's
+// pages: the activity intake and the OAuth endpoints clients use, which the authorization
+// server guards itself
+func csrfExempt(path string) bool {
+
+	return path == "/api/activity" || strings.HasPrefix(path, "/oauth/")
+}
+
+// sameOriginRequest reports whether a browser request came from one of the dashboard's pages.
+// Requests without Origin or Sec-Fetch-Site headers don't come from a browser page and pass.
+func sameOriginRequest(r *http.Request) bool {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		parsed, err := url.Parse(origin)
+
+		return err == nil && (parsed.Host == r.Host || parsed.Host == r.Header.Get("X-Forwarded-Host"))
+	}
+	site := r.Header.Get("Sec-Fetch-Site")
+
+	return site == "" || site == "same-origin" || site == "none"
+}
+
+// submittedCSRFToken returns the token a request carries, in the X-CSRF-Token header or the
+// csrf_token field of a form. Other bodies are left unread for the handlers.
+func submittedCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(csrfHeader); token != "" {
+
+		return token
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+
+		return r.PostFormValue(csrfField)
+	}
+
+	return ""
+}
+
+// requestCSRFToken returns the token the dashboard's forms carry, set by protect
+func requestCSRFToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfContextKey).(string)
+
+	return token
+}
+
+// restrictAddresses refuses clients dashboard.access keeps out, and those oauth.access keeps
+// out of the OAuth pages, before they sign in
+func (d *DashboardServer) restrictAddresses(next http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setting := ""
+		switch {
+		case !d.access.Allows(r):
+			setting = "dashboard.access"
+		case strings.HasPrefix(r.URL.Path, "/oauth/") && !d.oauthAccess.Allows(r):
+			setting = "oauth.access"
+		}
+		if setting != "" {
+			d.logger.Warning("Refused %s %s from %s, not allowed by %s", r.Method, r.URL.Path, r.RemoteAddr, setting)
+			writeAuthError(w, http.StatusForbidden, "Your address is not allowed to use the dashboard")
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// protect serves every response with security headers and the CORS headers of the configured
+// origins, and refuses requests that change something from other sites. Browsers signed in with the session cookie, and the sign-in form, must
+// also send the token of the dashboard's CSRF cookie, which other sites can't read, as the
+// X-CSRF-Token header or the csrf_token form field.
+func (d *DashboardServer) protect(next http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setSecurityHeaders(w, r)
+		if d.corsPolicy.Handle(w, r) {
+
+			return
+		}
+
+		issued := ""
+		if cookie, err := r.Cookie(constants.DashboardCSRFCookie); err == nil {
+			issued = cookie.Value
+		}
+		token := issued
+		if token == "" {
+			var err error
+			if token, err = randomToken(); err != nil {
+				http.Error(w, "Failed to start a session", http.StatusInternalServerError)
+
+				return
+			}
+			// Not HttpOnly: the dashboard's scripts send it back as the X-CSRF-Token header
+			http.SetCookie(w, &http.Cookie{
+				Name:     constants.DashboardCSRFCookie,
+				Value:    token,
+				Path:     "/",
+				Secure:   isSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		r = r.WithContext(context.WithValue(r.Context(), csrfContextKey, token))
+
+		if !safeMethod(r.Method) && !csrfExempt(r.URL.Path) {
+			if !sameOriginRequest(r) && !d.corsPolicy.Allows(r.Header.Get("Origin")) {
+				d.logger.Warning("Refused cross-site %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+				writeAuthError(w, http.StatusForbidden, "Cross-site requests are not allowed")
+
+				return
+			}
+			_, sessionErr := r.Cookie(constants.DashboardSessionCookie)
+			if (sessionErr == nil || r.URL.Path == "/auth/login") &&
+				(issued == "" || subtle.ConstantTimeCompare([]byte(issued), []byte(submittedCSRFToken(r))) != 1) {
+				d.logger.Warning("Refused %s %s from %s without a valid CSRF token", r.Method, r.URL.Path, r.RemoteAddr)
+				if r.URL.Path == "/auth/login" && d.auth != nil {
+					d.renderLogin(w, r, http.StatusForbidden, safeNext(r.PostFormValue("next")), "The sign-in form expired, please try again")
+
+					return
+				}
+				writeAuthError(w, http.StatusForbidden, "Missing or invalid CSRF token, reload the dashboard")
+
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
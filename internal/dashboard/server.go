@@ -13,10 +13,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/phildougherty/mcp-compose/internal/certs"
 	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/cors"
+	"github.com/phildougherty/mcp-compose/internal/ipaccess"
 	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/telemetry"
 
 	"github.com/gorilla/websocket"
 )
@@ -37,14 +41,29 @@ type DashboardServer struct {
 	templates        *template.Template
 	httpClient       *http.Client
 	inspectorService *InspectorService
+	auth             *dashboardAuth // nil unless admin_login is enabled
+	version          string         // mcp-compose version recorded in support bundles
+	configDir        string         // directory relative certificate paths are resolved against
+	proxyTransport   http.RoundTripper
+	corsPolicy       *cors.Policy
+	access           *ipaccess.List // addresses let in; nil lets everyone in
+	oauthAccess      *ipaccess.List // and to the OAuth pages it forwards to the proxy
 }
 
 type PageData struct {
-	Title    string
-	ProxyURL string
-	APIKey   string
-	Theme    string
-	Port     int
+	Title        string
+	BrandTitle   string
+	Logo         bool
+	Favicon      bool
+	ProxyURL     string
+	APIKey       string
+	Theme        string
+	Port         int
+	LoginEnabled bool
+	Username     string
+	Role         string
+	ConfigEditor bool
+	Sampling     bool
 }
 
 func NewDashboardServer(cfg *config.ComposeConfig, runtime container.Runtime, proxyURL, apiKey string) *DashboardServer {
@@ -92,19 +111,16 @@ func NewDashboardServer(cfg *config.ComposeConfig, runtime container.Runtime, pr
 	}
 
 	server := &DashboardServer{
-		config:    cfg,
-		runtime:   runtime,
-		logger:    logging.NewLogger(cfg.Logging.Level),
-		proxyURL:  proxyURL,
-		apiKey:    apiKey,
-		templates: tmpl,
+		config:     cfg,
+		runtime:    runtime,
+		logger:     logging.NewLogger(cfg.Logging.Level),
+		proxyURL:   proxyURL,
+		apiKey:     apiKey,
+		templates:  tmpl,
+		corsPolicy: cors.New(cfg.CORS, dashboardCORSHeaders, nil),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  constants.WebSocketBufferSize,
 			WriteBufferSize: constants.WebSocketBufferSize,
-			CheckOrigin: func(r *http.Request) bool {
-
-				return true // In production, implement proper origin checking
-			},
 		},
 		httpClient: &http.Client{
 			Timeout: func() time.Duration {
@@ -121,12 +137,36 @@ func NewDashboardServer(cfg *config.ComposeConfig, runtime container.Runtime, pr
 		},
 	}
 
+	if server.access, err = ipaccess.New(cfg.Dashboard.Access); err != nil {
+		server.logger.Error("Ignoring dashboard.access: %v", err)
+	}
+	if cfg.OAuth != nil {
+		if server.oauthAccess, err = ipaccess.New(cfg.OAuth.Access); err != nil {
+			server.logger.Error("Ignoring oauth.access: %v", err)
+		}
+	}
+
+	// Websockets carry the browser's cookies, so other origins need credentialed CORS
+	server.upgrader.CheckOrigin = func(r *http.Request) bool {
+
+		return sameOriginRequest(r) || server.corsPolicy.AllowsCredentials(r.Header.Get("Origin"))
+	}
+
 	// Initialize inspector service
-	server.inspectorService = NewInspectorService(server.logger, proxyURL, apiKey)
+	server.inspectorService = NewInspectorService(server.logger, proxyURL, apiKey,
+		telemetry.NewTracer(cfg.Observability, "mcp-compose-dashboard", server.logger))
 
 	// Start cleanup goroutine
 	go server.startInspectorCleanup()
 
+	if login := cfg.Dashboard.AdminLogin; login != nil && login.Enabled {
+		server.auth = newDashboardAuth(cfg, apiKey, server.logger)
+		go server.auth.startCleanup()
+		if len(cfg.Users) == 0 && !server.auth.oauthEnabled() {
+			server.logger.Warning("Dashboard login is enabled but no users are configured; only the API key can sign in")
+		}
+	}
+
 	return server
 }
 
@@ -142,7 +182,44 @@ func (d *DashboardServer) startInspectorCleanup() {
 	}
 }
 
+// SetVersion records the mcp-compose version included in support bundles
+func (d *DashboardServer) SetVersion(version string) {
+	d.version = version
+}
+
+// SetConfigDir sets the directory of the compose file, which relative certificate paths
+// are resolved against
+func (d *DashboardServer) SetConfigDir(dir string) {
+	d.configDir = dir
+}
+
+// configureProxyTLS makes the dashboard's clients trust the proxy's certificate when the
+// proxy is reached over HTTPS
+func (d *DashboardServer) configureProxyTLS() {
+	proxyTLS := d.config.ProxyListenerTLS()
+	if !proxyTLS.Enabled || !strings.HasPrefix(d.proxyURL, "https://") {
+
+		return
+	}
+	clientConfig, err := certs.ClientConfig(proxyTLS, d.configDir)
+	if err != nil {
+		d.logger.Warning("Trusting only system roots for the proxy: %v", err)
+
+		return
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = clientConfig
+	d.proxyTransport = transport
+	d.httpClient.Transport = transport
+	d.inspectorService.httpClient.Transport = transport
+}
+
 func (d *DashboardServer) Start(port int, host string) error {
+	d.configureProxyTLS()
+
+	// Feed the proxy's events into the activity stream
+	go d.followProxyEvents()
+
 	mux := http.NewServeMux()
 
 	// Add debug logging
@@ -176,6 +253,15 @@ func (d *DashboardServer) Start(port int, host string) error {
 	mux.HandleFunc("/", d.handleIndex)
 	d.logger.Info("Registered: /")
 
+	// Themes and branding
+	mux.HandleFunc("/theme.css", d.handleThemeCSS)
+	mux.HandleFunc(themeAPIPath, d.handleTheme)
+	mux.HandleFunc("/branding/", d.handleBranding)
+	d.logger.Info("Registered: /theme.css, /api/theme, /branding/")
+
+	// Dashboard sign-in
+	d.registerAuthRoutes(mux)
+
 	// CRITICAL: CONTAINERS ROUTE MUST BE FIRST - Register with explicit logging
 	d.logger.Info("Registering containers route: /api/containers/")
 	mux.HandleFunc("/api/containers/", func(w http.ResponseWriter, r *http.Request) {
@@ -216,6 +302,26 @@ func (d *DashboardServer) Start(port int, host string) error {
 	mux.HandleFunc("/api/proxy/reload", d.handleProxyReload)
 	d.logger.Info("Registered: /api/proxy/reload")
 
+	mux.HandleFunc("/api/config", d.handleConfigEditor)
+	mux.HandleFunc("/api/config/", d.handleConfigEditor)
+	d.logger.Info("Registered: /api/config")
+
+	mux.HandleFunc("/api/sampling", d.handleSamplingQueue)
+	mux.HandleFunc("/api/sampling/", d.handleSamplingQueue)
+	d.logger.Info("Registered: /api/sampling")
+
+	mux.HandleFunc("/api/analytics/", func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if r.URL.RawQuery != "" {
+			path += "?" + r.URL.RawQuery
+		}
+		d.forwardToProxy(w, r, path, nil)
+	})
+	d.logger.Info("Registered: /api/analytics/")
+
+	mux.HandleFunc("/api/support-bundle", d.handleSupportBundle)
+	d.logger.Info("Registered: /api/support-bundle")
+
 	// Server documentation endpoints
 	mux.HandleFunc("/api/server-docs/", d.handleServerDocs)
 	d.logger.Info("Registered: /api/server-docs/")
@@ -242,6 +348,14 @@ func (d *DashboardServer) Start(port int, host string) error {
 	mux.HandleFunc("/api/oauth/scopes", d.handleOAuthScopes)
 	d.logger.Info("Registered: /api/oauth/scopes")
 
+	mux.HandleFunc("/api/oauth/device", d.handleDeviceSignIns)
+	mux.HandleFunc("/api/oauth/device/", d.handleDeviceSignIns)
+	d.logger.Info("Registered: /api/oauth/device")
+
+	mux.HandleFunc(consentsAPIPath, d.handleOAuthConsents)
+	mux.HandleFunc(consentsAPIPath+"/", d.handleOAuthConsents)
+	d.logger.Info("Registered: /api/oauth/consents")
+
 	mux.HandleFunc("/oauth/register", d.handleOAuthRegister)
 	d.logger.Info("Registered: /oauth/register")
 
@@ -288,15 +402,24 @@ func (d *DashboardServer) Start(port int, host string) error {
 	mux.HandleFunc("/api/inspector/disconnect", d.handleInspectorDisconnect)
 	d.logger.Info("Registered: /api/inspector/disconnect")
 
-	// Task scheduler endpoints (if available)
+	mux.HandleFunc("/api/inspector/tools", d.handleInspectorTools)
+	d.logger.Info("Registered: /api/inspector/tools")
+
+	mux.HandleFunc("/api/inspector/history", d.handleInspectorHistory)
+	d.logger.Info("Registered: /api/inspector/history")
+
+	mux.HandleFunc("/api/inspector/replay", d.handleInspectorReplay)
+	d.logger.Info("Registered: /api/inspector/replay")
+
+	// Task scheduler endpoints, served by the proxy's task API
+	mux.HandleFunc("/api/task-scheduler/", d.handleTaskSchedulerProxy)
+	d.logger.Info("Registered: /api/task-scheduler/")
+
 	if d.inspectorService != nil {
 		mux.HandleFunc("/api/task-scheduler/health", d.handleTaskSchedulerHealth)
 		d.logger.Info("Registered: /api/task-scheduler/health")
-
-		mux.HandleFunc("/api/task-scheduler/", d.handleTaskSchedulerProxy)
-		d.logger.Info("Registered: /api/task-scheduler/")
 	} else {
-		d.logger.Info("Inspector service not available, skipping task scheduler routes")
+		d.logger.Info("Inspector service not available, skipping task scheduler health route")
 	}
 
 	// Server-specific OAuth endpoints - MUST be before catch-all /api/servers/
@@ -313,6 +436,11 @@ func (d *DashboardServer) Start(port int, host string) error {
 
 			return
 		}
+		if strings.HasSuffix(r.URL.Path, "/detail") {
+			d.forwardToProxy(w, r, r.URL.Path, nil)
+
+			return
+		}
 		d.logger.Info("Routing to general API proxy")
 		d.handleAPIProxy(w, r)
 	})
@@ -354,17 +482,29 @@ func (d *DashboardServer) Start(port int, host string) error {
 		}
 	}
 
+	var handler http.Handler = mux
+	if d.auth != nil {
+		handler = d.requireLogin(mux)
+		d.logger.Info("Dashboard login is required")
+	}
+	handler = d.protect(handler)
+	handler = d.restrictAddresses(handler)
+
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      handler,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
 	}
 
-	d.logger.Info("Dashboard server starting...")
+	if d.config.Dashboard.TLS.Enabled {
+		d.logger.Info("Dashboard server starting with TLS...")
+	} else {
+		d.logger.Info("Dashboard server starting...")
+	}
 
-	return server.ListenAndServe()
+	return certs.ListenAndServe(server, d.config.Dashboard.TLS, d.configDir, d.logger)
 }
 
 // Helper to handle API methods properly
@@ -383,12 +523,31 @@ func (d *DashboardServer) handleAPIRequest(handler func(http.ResponseWriter, *ht
 }
 
 func (d *DashboardServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	branding := d.branding()
 	data := PageData{
-		Title:    "MCP-Compose Dashboard",
-		ProxyURL: d.proxyURL,
-		APIKey:   d.apiKey,
-		Theme:    d.config.Dashboard.Theme,
-		Port:     d.config.Dashboard.Port,
+		Title:        "MCP-Compose Dashboard",
+		BrandTitle:   "MCP Dashboard",
+		Logo:         branding.Logo != "",
+		Favicon:      branding.Favicon != "",
+		ProxyURL:     d.proxyURL,
+		APIKey:       d.apiKey,
+		Theme:        d.requestTheme(r),
+		Port:         d.config.Dashboard.Port,
+		Role:         RoleAdmin,
+		ConfigEditor: d.config.Dashboard.ConfigEditor,
+		Sampling:     d.config.Sampling.Enabled,
+	}
+	if branding.Title != "" {
+		data.Title = branding.Title
+		data.BrandTitle = branding.Title
+	}
+	// Signed-in users talk to the dashboard with their session cookie, so the page does
+	// not carry the API key
+	if session := requestSession(r); d.auth != nil && session != nil {
+		data.APIKey = ""
+		data.LoginEnabled = true
+		data.Username = session.Username
+		data.Role = session.Role
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -446,7 +605,7 @@ func (d *DashboardServer) handleLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	containerName := "mcp-compose-" + path
+	containerName := config.ContainerName(path)
 	tail := r.URL.Query().Get("tail")
 	if tail == "" {
 		tail = "100"
@@ -19,6 +19,10 @@ if (typeof window.mcpApp === 'undefined') {
   window.mcpApp.component('oauth-config', OAuthConfig);
   window.mcpApp.component('audit-log', AuditLog);
   window.mcpApp.component('server-oauth-config', ServerOAuthConfig);
+  window.mcpApp.component('config-editor', ConfigEditor);
+  window.mcpApp.component('sampling-queue', SamplingQueue);
+  window.mcpApp.component('client-analytics', ClientAnalytics);
+  window.mcpApp.component('server-detail', ServerDetail);
   
   // Mount the app
   window.mcpApp.mount('#app');
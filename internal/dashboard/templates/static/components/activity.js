@@ -221,12 +221,15 @@ const ActivityViewer = {
             this.activityStats.total++;
             switch (activity.type) {
                 case 'request':
+                case 'request.failed':
                     this.activityStats.requests++;
                     break;
                 case 'connection':
                     this.activityStats.connections++;
                     break;
                 case 'tool':
+                case 'tool.called':
+                case 'tool.failed':
                     this.activityStats.toolCalls++;
                     break;
             }
@@ -315,12 +318,18 @@ const ActivityViewer = {
                 'tool': 'bg-purple-600 text-white', 
                 'connection': 'bg-blue-600 text-white',
                 'task': 'bg-indigo-600 text-white',
-                'error': 'bg-red-600 text-white'
+                'error': 'bg-red-600 text-white',
+                'tool.failed': 'bg-red-600 text-white',
+                'request.failed': 'bg-red-600 text-white',
+                'server': 'bg-teal-600 text-white',
+                'config': 'bg-indigo-600 text-white',
+                'auth': 'bg-orange-600 text-white'
             };
-            return classes[type] || 'bg-gray-600 text-white';
+            // Event bus types fall back to their category, e.g. server.started to server
+            return classes[type] || classes[(type || '').split('.')[0]] || 'bg-gray-600 text-white';
         },
         getTypeIcon(type) {
-            switch (type) {
+            switch ((type || '').split('.')[0]) {
                 case 'request':
                     return 'M8 4H6a2 2 0 00-2 2v12a2 2 0 002 2h8a2 2 0 002-2V6a2 2 0 00-2-2h-2m-4-1v8m0 0l3-3m-3 3L9 8m-5 5h2.586a1 1 0 01.707.293L12 17';
                 case 'connection':
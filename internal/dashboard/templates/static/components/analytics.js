@@ -0,0 +1,201 @@
+const ClientAnalytics = {
+    props: ['config'],
+    data() {
+        return {
+            clients: [],
+            since: null,
+            servers: [],
+            serverFilter: '',
+            expanded: {},
+            loading: false,
+            error: '',
+            timer: null
+        }
+    },
+    computed: {
+        totals() {
+            return this.clients.reduce((sum, client) => ({
+                requests: sum.requests + client.requests,
+                errors: sum.errors + client.errors,
+                tokens: sum.tokens + client.inputTokens + client.outputTokens
+            }), { requests: 0, errors: 0, tokens: 0 });
+        },
+        busiest() {
+            return this.clients.length ? this.clients[0].requests : 0;
+        }
+    },
+    methods: {
+        async request(endpoint) {
+            const headers = {};
+            if (this.config.apiKey) {
+                headers['Authorization'] = `Bearer ${this.config.apiKey}`;
+            }
+            const response = await fetch(endpoint, { headers });
+            if (response.status === 401 && this.config.loginEnabled) {
+                window.location.href = '/login?next=' + encodeURIComponent(window.location.pathname);
+                throw new Error('Session expired');
+            }
+            let data = {};
+            try {
+                data = await response.json();
+            } catch (e) {
+                data = { error: `Unexpected response (HTTP ${response.status})` };
+            }
+            return { status: response.status, data };
+        },
+
+        async loadClients() {
+            this.loading = true;
+            try {
+                const query = this.serverFilter ? `?server=${encodeURIComponent(this.serverFilter)}` : '';
+                const { status, data } = await this.request('/api/analytics/clients' + query);
+                if (status !== 200) {
+                    throw new Error(data.error || `HTTP ${status}`);
+                }
+                this.since = data.since;
+                this.clients = data.clients || [];
+                if (!this.serverFilter) {
+                    const names = new Set(this.servers);
+                    this.clients.forEach(client => client.servers.forEach(server => names.add(server.name)));
+                    this.servers = [...names].sort();
+                }
+                this.error = '';
+            } catch (err) {
+                this.error = err.message;
+            } finally {
+                this.loading = false;
+            }
+        },
+
+        toggle(client) {
+            this.expanded = { ...this.expanded, [client.id]: !this.expanded[client.id] };
+        },
+
+        authLabel(authType) {
+            switch (authType) {
+                case 'oauth': return 'OAuth client';
+                case 'api_key': return 'API key';
+                case 'trusted_header': return 'User';
+                case 'none': return 'Unauthenticated';
+                default: return authType;
+            }
+        },
+
+        errorClass(rate) {
+            if (rate >= 0.25) return 'text-red-300';
+            if (rate >= 0.05) return 'text-yellow-300';
+            return 'text-green-300';
+        },
+
+        percent(rate) {
+            return `${(rate * 100).toFixed(1)}%`;
+        },
+
+        formatNumber(value) {
+            return Number(value || 0).toLocaleString();
+        },
+
+        formatTime(value) {
+            return value ? new Date(value).toLocaleString() : '';
+        },
+
+        barWidth(requests) {
+            return this.busiest ? `${Math.max(2, Math.round(requests / this.busiest * 100))}%` : '0%';
+        }
+    },
+    watch: {
+        serverFilter() {
+            this.loadClients();
+        }
+    },
+    mounted() {
+        this.loadClients();
+        this.timer = setInterval(() => this.loadClients(), 5000);
+    },
+    beforeUnmount() {
+        clearInterval(this.timer);
+    },
+    template: `
+        <div class="space-y-6 animate-fade-in">
+            <div class="flex flex-col sm:flex-row sm:items-center sm:justify-between gap-3">
+                <div>
+                    <h2 class="text-2xl font-bold text-white mb-1">Analytics</h2>
+                    <p class="text-gray-400 text-sm">
+                        Requests, errors and estimated tokens per client<span v-if="since"> since {{ formatTime(since) }}</span>.
+                    </p>
+                </div>
+                <div class="flex space-x-2">
+                    <select v-model="serverFilter" class="bg-gray-700 border border-gray-600 rounded-md text-xs text-gray-200 px-2 py-1.5">
+                        <option value="">All servers</option>
+                        <option v-for="server in servers" :key="server" :value="server">{{ server }}</option>
+                    </select>
+                    <button @click="loadClients" :disabled="loading" class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-gray-500 disabled:opacity-50 transition-all touch-target">Refresh</button>
+                </div>
+            </div>
+
+            <div v-if="error" class="rounded border border-red-700 bg-red-900/40 px-4 py-3 text-sm text-red-200">{{ error }}</div>
+
+            <div class="grid grid-cols-1 sm:grid-cols-3 gap-4">
+                <div class="bg-gray-800 border border-gray-700 rounded-lg p-4">
+                    <p class="text-xs text-gray-400">Requests</p>
+                    <p class="text-2xl font-semibold text-white">{{ formatNumber(totals.requests) }}</p>
+                </div>
+                <div class="bg-gray-800 border border-gray-700 rounded-lg p-4">
+                    <p class="text-xs text-gray-400">Errors</p>
+                    <p class="text-2xl font-semibold" :class="errorClass(totals.requests ? totals.errors / totals.requests : 0)">
+                        {{ formatNumber(totals.errors) }}
+                        <span class="text-sm text-gray-400" v-if="totals.requests">({{ percent(totals.errors / totals.requests) }})</span>
+                    </p>
+                </div>
+                <div class="bg-gray-800 border border-gray-700 rounded-lg p-4">
+                    <p class="text-xs text-gray-400">Estimated tokens</p>
+                    <p class="text-2xl font-semibold text-white">{{ formatNumber(totals.tokens) }}</p>
+                </div>
+            </div>
+
+            <p v-if="!clients.length && !error" class="text-gray-400 text-sm">No requests have been forwarded yet.</p>
+
+            <div v-for="client in clients" :key="client.id" class="bg-gray-800 border border-gray-700 rounded-lg p-4 space-y-3">
+                <div class="flex flex-wrap items-center justify-between gap-2 cursor-pointer" @click="toggle(client)">
+                    <div class="flex items-center space-x-2">
+                        <span class="font-semibold text-white">{{ client.name || client.id }}</span>
+                        <span class="text-xs px-2 py-0.5 rounded border bg-gray-700 text-gray-300 border-gray-600">{{ authLabel(client.authType) }}</span>
+                    </div>
+                    <div class="flex items-center space-x-4 text-sm">
+                        <span class="text-gray-200">{{ formatNumber(client.requests) }} requests</span>
+                        <span :class="errorClass(client.errorRate)">{{ percent(client.errorRate) }} errors</span>
+                        <span class="text-gray-400">{{ formatNumber(client.inputTokens + client.outputTokens) }} tokens</span>
+                    </div>
+                </div>
+                <div class="h-1.5 bg-gray-700 rounded">
+                    <div class="h-1.5 bg-blue-500 rounded" :style="{ width: barWidth(client.requests) }"></div>
+                </div>
+
+                <div v-if="expanded[client.id]" class="grid grid-cols-1 lg:grid-cols-2 gap-4 text-sm">
+                    <div>
+                        <p class="text-xs text-gray-400 mb-1">Servers</p>
+                        <table class="w-full">
+                            <tr v-for="server in client.servers" :key="server.name" class="border-t border-gray-700">
+                                <td class="py-1 text-gray-200">{{ server.name }}</td>
+                                <td class="py-1 text-right text-gray-300">{{ formatNumber(server.requests) }}</td>
+                                <td class="py-1 text-right" :class="errorClass(server.requests ? server.errors / server.requests : 0)">{{ formatNumber(server.errors) }} errors</td>
+                            </tr>
+                        </table>
+                    </div>
+                    <div>
+                        <p class="text-xs text-gray-400 mb-1">Top tools</p>
+                        <p v-if="!client.topTools.length" class="text-gray-500">No tool calls</p>
+                        <table v-else class="w-full">
+                            <tr v-for="tool in client.topTools" :key="tool.name" class="border-t border-gray-700">
+                                <td class="py-1 font-mono text-gray-200">{{ tool.name }}</td>
+                                <td class="py-1 text-right text-gray-300">{{ formatNumber(tool.requests) }}</td>
+                                <td class="py-1 text-right text-gray-400">{{ formatNumber(tool.inputTokens + tool.outputTokens) }} tokens</td>
+                            </tr>
+                        </table>
+                    </div>
+                    <p class="text-xs text-gray-500 lg:col-span-2">First seen {{ formatTime(client.firstSeen) }}, last seen {{ formatTime(client.lastSeen) }}</p>
+                </div>
+            </div>
+        </div>
+    `
+};
@@ -0,0 +1,329 @@
+const ConfigEditor = {
+    props: ['config'],
+    data() {
+        return {
+            file: '',
+            original: '',
+            content: '',
+            hash: '',
+            editable: true,
+            overrides: [],
+            loading: false,
+            validating: false,
+            applying: false,
+            validation: null,
+            result: null,
+            history: [],
+            currentHash: '',
+            rollingBack: null,
+            error: ''
+        }
+    },
+    computed: {
+        dirty() {
+            return this.content !== this.original;
+        },
+        diffLines() {
+            if (!this.validation || !this.validation.diff) return [];
+            return this.validation.diff.split('\n').filter(line => line !== '').map(line => ({
+                text: line,
+                kind: line.startsWith('@@') ? 'hunk'
+                    : line.startsWith('+++') || line.startsWith('---') ? 'header'
+                    : line.startsWith('+') ? 'add'
+                    : line.startsWith('-') ? 'remove'
+                    : 'context'
+            }));
+        },
+        changeSummary() {
+            const changes = this.validation && this.validation.changes;
+            if (!changes) return [];
+            const summary = [];
+            if (changes.added.length) summary.push({ label: 'Added', servers: changes.added, color: 'text-green-400' });
+            if (changes.removed.length) summary.push({ label: 'Removed', servers: changes.removed, color: 'text-red-400' });
+            if (changes.changed.length) summary.push({ label: 'Restarted if running', servers: changes.changed, color: 'text-yellow-400' });
+            return summary;
+        }
+    },
+    methods: {
+        async request(endpoint, options = {}) {
+            const headers = { 'Content-Type': 'application/json' };
+            if (this.config.apiKey) {
+                headers['Authorization'] = `Bearer ${this.config.apiKey}`;
+            }
+            const response = await fetch(endpoint, { headers, ...options });
+            if (response.status === 401 && this.config.loginEnabled) {
+                window.location.href = '/login?next=' + encodeURIComponent(window.location.pathname);
+                throw new Error('Session expired');
+            }
+            let data = {};
+            try {
+                data = await response.json();
+            } catch (e) {
+                data = { error: `Unexpected response (HTTP ${response.status})` };
+            }
+            return { status: response.status, data };
+        },
+
+        async loadConfig() {
+            if (this.dirty && !confirm('Discard your unsaved edits?')) return;
+            this.loading = true;
+            this.error = '';
+            this.validation = null;
+            this.result = null;
+            try {
+                const { status, data } = await this.request('/api/config');
+                if (status !== 200) {
+                    throw new Error(data.error || `HTTP ${status}`);
+                }
+                this.file = data.file;
+                this.original = data.content;
+                this.content = data.content;
+                this.hash = data.hash;
+                this.editable = data.editable;
+                this.overrides = data.overrides || [];
+                await this.loadHistory();
+            } catch (err) {
+                this.error = err.message;
+            } finally {
+                this.loading = false;
+            }
+        },
+
+        async loadHistory() {
+            const { status, data } = await this.request('/api/config/history');
+            if (status !== 200) return;
+            this.history = data.versions || [];
+            this.currentHash = data.currentHash;
+        },
+
+        async rollback(version) {
+            if (this.dirty && !confirm('Discard your unsaved edits?')) return;
+            if (!confirm(`Restore version ${version.version} of ${this.file} and restart the servers it changes?`)) return;
+            this.rollingBack = version.version;
+            this.error = '';
+            this.validation = null;
+            try {
+                const { status, data } = await this.request('/api/config/rollback', {
+                    method: 'POST',
+                    body: JSON.stringify({ version: version.version })
+                });
+                if (status === 422) {
+                    this.validation = { valid: false, errors: data.errors || [] };
+                    throw new Error(data.error);
+                }
+                if (status !== 200) {
+                    throw new Error(data.error || `HTTP ${status}`);
+                }
+                this.original = this.content;
+                await this.loadConfig();
+                this.result = data;
+                this.showToast(`Rolled back to version ${version.version}`, data.status === 'partial' ? 'warning' : 'success');
+                this.$emit('applied');
+            } catch (err) {
+                this.error = err.message;
+            } finally {
+                this.rollingBack = null;
+            }
+        },
+
+        formatTime(time) {
+            return new Date(time).toLocaleString();
+        },
+
+        async validate() {
+            this.validating = true;
+            this.error = '';
+            this.result = null;
+            try {
+                const { status, data } = await this.request('/api/config/validate', {
+                    method: 'POST',
+                    body: JSON.stringify({ content: this.content })
+                });
+                if (status !== 200) {
+                    throw new Error(data.error || `HTTP ${status}`);
+                }
+                this.validation = data;
+            } catch (err) {
+                this.error = err.message;
+            } finally {
+                this.validating = false;
+            }
+        },
+
+        async apply() {
+            if (!confirm(`Write ${this.file} and restart the servers this edit changes?`)) return;
+            this.applying = true;
+            this.error = '';
+            try {
+                const { status, data } = await this.request('/api/config/apply', {
+                    method: 'POST',
+                    body: JSON.stringify({ content: this.content, baseHash: this.hash })
+                });
+                if (status === 422) {
+                    this.validation = { ...(this.validation || {}), valid: false, errors: data.errors || [] };
+                    throw new Error(data.error);
+                }
+                if (status !== 200) {
+                    throw new Error(data.error || `HTTP ${status}`);
+                }
+                this.result = data;
+                this.validation = null;
+                this.original = this.content;
+                const { data: reloaded } = await this.request('/api/config');
+                this.hash = reloaded.hash || this.hash;
+                await this.loadHistory();
+                if (data.status === 'partial') {
+                    this.showToast(`Config written, but some servers failed: ${data.error}`, 'warning');
+                } else {
+                    this.showToast('Configuration applied', 'success');
+                }
+                this.$emit('applied');
+            } catch (err) {
+                this.error = err.message;
+            } finally {
+                this.applying = false;
+            }
+        },
+
+        revert() {
+            this.content = this.original;
+            this.validation = null;
+        },
+
+        jumpToLine(line) {
+            const textarea = this.$refs.editor;
+            if (!textarea || !line) return;
+            const lines = this.content.split('\n');
+            const start = lines.slice(0, line - 1).reduce((total, text) => total + text.length + 1, 0);
+            textarea.focus();
+            textarea.setSelectionRange(start, start + (lines[line - 1] || '').length);
+        },
+
+        showToast(message, type = 'info') {
+            window.showToast && window.showToast(message, type);
+        }
+    },
+    watch: {
+        content() {
+            // A preview describes the text it was made from
+            if (this.validation && this.validation.valid) this.validation = null;
+        }
+    },
+    mounted() {
+        this.loadConfig();
+    },
+    template: `
+        <div class="space-y-6 animate-fade-in">
+            <div class="flex flex-col sm:flex-row sm:items-center sm:justify-between gap-3">
+                <div>
+                    <h2 class="text-2xl font-bold text-white mb-1">Configuration</h2>
+                    <p class="text-gray-400 text-sm">
+                        Editing <code class="text-gray-300">{{ file }}</code>.
+                        Changes are validated, every version is kept and only affected servers restart.
+                    </p>
+                    <p v-if="overrides.length" class="text-gray-500 text-xs mt-1">
+                        Merged with {{ overrides.join(', ') }}, which stay unchanged.
+                    </p>
+                </div>
+                <div class="flex space-x-2">
+                    <button @click="loadConfig" :disabled="loading" class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-gray-500 disabled:opacity-50 transition-all touch-target">Reload</button>
+                    <button @click="revert" :disabled="!dirty" class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-gray-500 disabled:opacity-50 transition-all touch-target">Revert</button>
+                    <button @click="validate" :disabled="validating || !editable" class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-gray-500 disabled:opacity-50 transition-all touch-target">
+                        {{ validating ? 'Validating...' : 'Validate' }}
+                    </button>
+                    <button
+                        @click="apply"
+                        :disabled="applying || !dirty || !editable || !validation || !validation.valid"
+                        title="Validate the edit first"
+                        class="inline-flex items-center px-3 py-1.5 border border-blue-600/30 text-xs font-medium rounded-md text-white bg-blue-600 hover:bg-blue-700 focus:outline-none focus:ring-2 focus:ring-blue-500 disabled:opacity-50 transition-all touch-target">
+                        {{ applying ? 'Applying...' : 'Apply' }}
+                    </button>
+                </div>
+            </div>
+
+            <div v-if="error" class="rounded border border-red-700 bg-red-900/40 px-4 py-3 text-sm text-red-200">{{ error }}</div>
+            <div v-if="!editable" class="rounded border border-yellow-700 bg-yellow-900/30 px-4 py-3 text-sm text-yellow-200">
+                This compose file is fetched from a remote source and is read-only here.
+            </div>
+
+            <textarea
+                ref="editor"
+                v-model="content"
+                :readonly="!editable"
+                spellcheck="false"
+                class="w-full h-[32rem] font-mono text-sm bg-gray-800 border border-gray-700 rounded-lg p-4 text-gray-100 focus:border-blue-500 focus:outline-none"
+            ></textarea>
+
+            <div v-if="validation && !validation.valid" class="bg-gray-800 border border-red-700 rounded-lg p-4">
+                <h3 class="text-red-300 font-semibold mb-2">{{ validation.errors.length }} problem(s) found</h3>
+                <ul class="space-y-1 text-sm">
+                    <li v-for="(problem, index) in validation.errors" :key="index" class="text-gray-200">
+                        <button
+                            v-if="problem.line"
+                            @click="jumpToLine(problem.line)"
+                            class="font-mono text-blue-400 hover:underline mr-2">
+                            {{ problem.file ? problem.file + ':' : '' }}{{ problem.line }}{{ problem.column ? ':' + problem.column : '' }}
+                        </button>
+                        <span v-if="problem.path" class="font-mono text-gray-400 mr-2">{{ problem.path }}</span>
+                        {{ problem.message }}
+                    </li>
+                </ul>
+            </div>
+
+            <div v-if="validation && validation.valid" class="bg-gray-800 border border-gray-700 rounded-lg p-4 space-y-3">
+                <h3 class="text-green-300 font-semibold">Valid configuration</h3>
+                <p v-if="!validation.diff" class="text-sm text-gray-400">No changes.</p>
+                <ul v-if="changeSummary.length" class="text-sm space-y-1">
+                    <li v-for="entry in changeSummary" :key="entry.label">
+                        <span :class="entry.color">{{ entry.label }}:</span>
+                        <span class="text-gray-200">{{ entry.servers.join(', ') }}</span>
+                    </li>
+                </ul>
+                <p v-if="validation.changes && validation.changes.global" class="text-sm text-yellow-300">
+                    Settings outside servers changed; restart the proxy for them to take effect.
+                </p>
+                <pre v-if="diffLines.length" class="text-xs font-mono bg-gray-900 rounded p-3 overflow-x-auto"><div
+                    v-for="(line, index) in diffLines" :key="index"
+                    :class="{
+                        'text-green-400': line.kind === 'add',
+                        'text-red-400': line.kind === 'remove',
+                        'text-blue-400': line.kind === 'hunk',
+                        'text-gray-500': line.kind === 'header',
+                        'text-gray-300': line.kind === 'context'
+                    }">{{ line.text }}</div></pre>
+            </div>
+
+            <div v-if="result" class="bg-gray-800 border border-green-700 rounded-lg p-4 text-sm space-y-1">
+                <p class="text-green-300 font-semibold">Applied</p>
+                <p class="text-gray-300">Saved as version {{ result.version }}<span v-if="result.backup">; the previous file is kept as <code>{{ result.backup }}</code></span></p>
+                <p v-if="result.restarted.length" class="text-gray-300">Restarted: {{ result.restarted.join(', ') }}</p>
+                <p v-if="result.restartRequired" class="text-yellow-300">Restart the proxy to apply settings outside servers.</p>
+                <p v-if="result.error" class="text-red-300">{{ result.error }}</p>
+            </div>
+
+            <div v-if="history.length" class="bg-gray-800 border border-gray-700 rounded-lg p-4">
+                <h3 class="text-white font-semibold mb-3">History</h3>
+                <table class="w-full text-sm">
+                    <tbody>
+                        <tr v-for="version in history" :key="version.version" class="border-t border-gray-700">
+                            <td class="py-2 pr-3 font-mono text-gray-300">v{{ version.version }}</td>
+                            <td class="py-2 pr-3 text-gray-400">{{ formatTime(version.time) }}</td>
+                            <td class="py-2 pr-3 text-gray-300">{{ version.source }}<span v-if="version.client" class="text-gray-500"> ({{ version.client }})</span></td>
+                            <td class="py-2 pr-3 text-gray-400">{{ version.message }}</td>
+                            <td class="py-2 text-right">
+                                <span v-if="version.hash === currentHash" class="text-xs text-green-400">current</span>
+                                <button
+                                    v-else
+                                    @click="rollback(version)"
+                                    :disabled="rollingBack !== null || !editable"
+                                    class="inline-flex items-center px-2 py-1 border border-gray-600 text-xs font-medium rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 disabled:opacity-50 transition-all touch-target">
+                                    {{ rollingBack === version.version ? 'Rolling back...' : 'Roll back' }}
+                                </button>
+                            </td>
+                        </tr>
+                    </tbody>
+                </table>
+            </div>
+        </div>
+    `
+};
@@ -25,10 +25,19 @@ const DashboardApp = {
             // Server tools discovered by inspector
             serverTools: {},
             securitySection: 'oauth',
+            detailServer: null,
+            downloadingBundle: false,
+            theme: this.config.theme || 'dark',
+            themes: ['dark', 'light'],
         }
     },
     
     computed: {
+        // Viewers signed in to the dashboard may look but not change anything
+        isAdmin() {
+            return !this.config.loginEnabled || this.config.role === 'admin';
+        },
+
         tabs() {
             return [
                 {
@@ -61,11 +70,29 @@ const DashboardApp = {
                     icon: 'M13 10V3L4 14h7v7l9-11h-7z',
                     enabled: true
                 },
+                {
+                    id: 'analytics',
+                    name: 'Analytics',
+                    icon: 'M9 19v-6a2 2 0 00-2-2H5a2 2 0 00-2 2v6a2 2 0 002 2h2a2 2 0 002-2zm0 0V9a2 2 0 012-2h2a2 2 0 012 2v10m-6 0a2 2 0 002 2h2a2 2 0 002-2m0 0V5a2 2 0 012-2h2a2 2 0 012 2v14a2 2 0 01-2 2h-2a2 2 0 01-2-2z',
+                    enabled: true
+                },
+                {
+                    id: 'sampling',
+                    name: 'Sampling',
+                    icon: 'M8 10h.01M12 10h.01M16 10h.01M9 16H5a2 2 0 01-2-2V6a2 2 0 012-2h14a2 2 0 012 2v8a2 2 0 01-2 2h-5l-5 5v-5z',
+                    enabled: this.config.sampling
+                },
                 {
                     id: 'security',
                     name: 'Security',
                     icon: 'M12 15v2m-6 4h12a2 2 0 002-2v-6a2 2 0 00-2-2H6a2 2 0 00-2 2v6a2 2 0 002 2zm10-10V7a4 4 0 00-8 0v4h8z',
-                    enabled: true
+                    enabled: this.isAdmin
+                },
+                {
+                    id: 'config',
+                    name: 'Config',
+                    icon: 'M10.325 4.317c.426-1.756 2.924-1.756 3.35 0a1.724 1.724 0 002.573 1.066c1.543-.94 3.31.826 2.37 2.37a1.724 1.724 0 001.065 2.572c1.756.426 1.756 2.924 0 3.35a1.724 1.724 0 00-1.066 2.573c.94 1.543-.826 3.31-2.37 2.37a1.724 1.724 0 00-2.572 1.065c-.426 1.756-2.924 1.756-3.35 0a1.724 1.724 0 00-2.573-1.066c-1.543.94-3.31-.826-2.37-2.37a1.724 1.724 0 00-1.065-2.572c-1.756-.426-1.756-2.924 0-3.35a1.724 1.724 0 001.066-2.573c-.94-1.543.826-3.31 2.37-2.37.996.608 2.296.07 2.572-1.065zM15 12a3 3 0 11-6 0 3 3 0 016 0z',
+                    enabled: this.isAdmin && this.config.configEditor
                 },
             ].filter(tab => tab.enabled);
         },
@@ -298,6 +325,11 @@ const DashboardApp = {
             
             const response = await fetch(url, { headers, ...options });
             
+            if (response.status === 401 && this.config.loginEnabled) {
+                window.location.href = '/login?next=' + encodeURIComponent(window.location.pathname);
+                throw new Error('Session expired');
+            }
+            
             if (!response.ok) {
                 const contentType = response.headers.get('content-type');
                 if (contentType && contentType.includes('application/json')) {
@@ -451,6 +483,11 @@ const DashboardApp = {
             }
         },
         
+        async signOut() {
+            await fetch('/auth/logout', { method: 'POST' });
+            window.location.href = '/login';
+        },
+        
         async reloadProxy() {
             const confirmed = confirm('Restart Proxy?\n\nThis will drop all active connections and reload configuration.');
             if (!confirmed) return;
@@ -468,6 +505,36 @@ const DashboardApp = {
             }
         },
         
+        async downloadSupportBundle() {
+            try {
+                this.downloadingBundle = true;
+                const headers = {};
+                if (this.config.apiKey) {
+                    headers['Authorization'] = `Bearer ${this.config.apiKey}`;
+                }
+                const response = await fetch('/api/support-bundle', { headers });
+                if (response.status === 401 && this.config.loginEnabled) {
+                    window.location.href = '/login?next=' + encodeURIComponent(window.location.pathname);
+                    return;
+                }
+                if (!response.ok) {
+                    throw new Error(`HTTP ${response.status}`);
+                }
+                const disposition = response.headers.get('content-disposition') || '';
+                const match = disposition.match(/filename="([^"]+)"/);
+                const link = document.createElement('a');
+                link.href = URL.createObjectURL(await response.blob());
+                link.download = match ? match[1] : 'mcp-compose-support.tar.gz';
+                link.click();
+                URL.revokeObjectURL(link.href);
+                this.showToast('Support bundle downloaded - secrets are redacted, but look it over before sharing', 'success');
+            } catch (err) {
+                this.showToast(`Failed to download support bundle: ${err.message}`, 'error');
+            } finally {
+                this.downloadingBundle = false;
+            }
+        },
+        
         setupAutoRefresh() {
             if (this.refreshInterval) {
                 clearInterval(this.refreshInterval);
@@ -479,6 +546,15 @@ const DashboardApp = {
             }
         },
         
+        async changeTheme(theme) {
+            try {
+                await window.setTheme(theme);
+                this.theme = theme;
+            } catch (err) {
+                this.showToast(`Failed to change theme: ${err.message}`, 'error');
+            }
+        },
+
         toggleAutoRefresh() {
             this.autoRefresh = !this.autoRefresh;
             this.setupAutoRefresh();
@@ -563,6 +639,12 @@ const DashboardApp = {
     mounted() {
         this.loadData();
         this.checkMobileView();
+
+        // Links to approve a device sign-in open the OAuth page with its code filled in
+        if (new URLSearchParams(window.location.search).has('user_code')) {
+            this.activeTab = 'security';
+            this.securitySection = 'oauth';
+        }
         
         window.addEventListener('resize', this.checkMobileView);
         
@@ -593,12 +675,13 @@ const DashboardApp = {
                 <div class="flex justify-between items-center h-12">
                     <!-- Logo and Title -->
                     <div class="flex items-center space-x-3">
-                        <div class="w-7 h-7 bg-gradient-to-r from-blue-500 to-purple-600 rounded-lg flex items-center justify-center">
+                        <img v-if="config.logo" src="/branding/logo" alt="" class="h-7 max-w-[8rem] object-contain">
+                        <div v-else class="w-7 h-7 bg-gradient-to-r from-blue-500 to-purple-600 rounded-lg flex items-center justify-center">
                             <svg class="w-4 h-4 text-white" fill="currentColor" viewBox="0 0 20 20">
                                 <path fill-rule="evenodd" d="M3 3a1 1 0 000 2v8a2 2 0 002 2h2.586l-1.293 1.293a1 1 0 101.414 1.414L10 15.414l2.293 2.293a1 1 0 001.414-1.414L12.414 15H15a2 2 0 002-2V5a1 1 0 100-2H3zm11.707 4.707a1 1 0 00-1.414-1.414L10 9.586 8.707 8.293a1 1 0 00-1.414 1.414l2 2a1 1 0 001.414 0l4-4z" clip-rule="evenodd"></path>
                             </svg>
                         </div>
-                        <h1 class="text-base font-semibold text-gray-900 dark:text-white hidden sm:block">MCP Dashboard</h1>
+                        <h1 class="text-base font-semibold text-gray-900 dark:text-white hidden sm:block">{{ config.brandTitle || 'MCP Dashboard' }}</h1>
                     </div>
 
                     <!-- Desktop Controls -->
@@ -680,6 +763,26 @@ const DashboardApp = {
                                         </div>
                                     </div>
                                     
+                                    <!-- Theme -->
+                                    <div class="flex items-center justify-between">
+                                        <span class="text-xs font-medium text-gray-200">Theme</span>
+                                        <div class="flex space-x-1">
+                                            <button
+                                                v-for="option in themes"
+                                                :key="option"
+                                                @click="changeTheme(option)"
+                                                :class="[
+                                                    'px-2 py-1 text-xs rounded capitalize transition-colors',
+                                                    theme === option
+                                                        ? 'bg-blue-600 text-white'
+                                                        : 'text-gray-300 hover:bg-gray-700'
+                                                ]"
+                                            >
+                                                {{ option }}
+                                            </button>
+                                        </div>
+                                    </div>
+
                                     <!-- Status -->
                                     <div class="border-t border-gray-600 pt-2 space-y-1 text-xs">
                                         <div class="flex justify-between text-gray-400">
@@ -697,6 +800,7 @@ const DashboardApp = {
 
                         <!-- Restart Proxy Button -->
                         <button
+                            v-if="isAdmin"
                             @click="reloadProxy"
                             :disabled="loading"
                             class="inline-flex items-center px-3 py-1.5 border border-orange-600/30 text-xs font-medium rounded-md text-orange-200 bg-orange-900/40 hover:bg-orange-900/60 focus:outline-none focus:ring-2 focus:ring-orange-500 disabled:opacity-50 transition-all"
@@ -707,6 +811,34 @@ const DashboardApp = {
                             </svg>
                             <span>Restart</span>
                         </button>
+
+                        <!-- Support Bundle Button -->
+                        <button
+                            v-if="isAdmin"
+                            @click="downloadSupportBundle"
+                            :disabled="downloadingBundle"
+                            class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-300 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-blue-500 disabled:opacity-50 transition-all"
+                            title="Download config, logs, versions and health with secrets redacted"
+                        >
+                            <svg class="w-4 h-4 mr-1.5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                                <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M4 16v1a3 3 0 003 3h10a3 3 0 003-3v-1m-4-4l-4 4m0 0l-4-4m4 4V4"></path>
+                            </svg>
+                            <span>{{ downloadingBundle ? 'Collecting...' : 'Support bundle' }}</span>
+                        </button>
+
+                        <!-- Signed-in User -->
+                        <div v-if="config.loginEnabled" class="flex items-center space-x-2 text-xs text-gray-300">
+                            <span :title="'Signed in as ' + config.username + ' (' + config.role + ')'">
+                                {{ config.username }}
+                                <span class="ml-1 px-1.5 py-0.5 rounded bg-gray-700 text-gray-400">{{ config.role }}</span>
+                            </span>
+                            <button
+                                @click="signOut"
+                                class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-300 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-blue-500 transition-all"
+                            >
+                                Sign out
+                            </button>
+                        </div>
                     </div>
 
                     <!-- Mobile Hamburger Menu -->
@@ -747,6 +879,7 @@ const DashboardApp = {
                     </button>
                     
                     <button
+                        v-if="isAdmin"
                         @click="reloadProxy(); mobileMenuOpen = false"
                         :disabled="loading"
                         class="w-full flex items-center justify-center px-3 py-2 border border-orange-600/30 text-sm font-medium rounded-md text-orange-200 bg-orange-900/40 hover:bg-orange-900/60 focus:outline-none focus:ring-2 focus:ring-orange-500 disabled:opacity-50 transition-all"
@@ -756,6 +889,23 @@ const DashboardApp = {
                         </svg>
                         Restart Proxy
                     </button>
+                    
+                    <button
+                        v-if="isAdmin"
+                        @click="downloadSupportBundle(); mobileMenuOpen = false"
+                        :disabled="downloadingBundle"
+                        class="w-full flex items-center justify-center px-3 py-2 border border-gray-600 text-sm font-medium rounded-md text-gray-300 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-blue-500 disabled:opacity-50 transition-all"
+                    >
+                        Support Bundle
+                    </button>
+                    
+                    <button
+                        v-if="config.loginEnabled"
+                        @click="signOut"
+                        class="w-full flex items-center justify-center px-3 py-2 border border-gray-600 text-sm font-medium rounded-md text-gray-300 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-blue-500 transition-all"
+                    >
+                        Sign out {{ config.username }}
+                    </button>
                 </div>
                 
                 <!-- Mobile Auto Refresh Toggle -->
@@ -1185,7 +1335,7 @@ const DashboardApp = {
                                         <!-- Primary Actions -->
                                         <div class="responsive-grid cols-3 gap-2">
                                             <button
-                                                v-if="!isContainerRunning(server)"
+                                                v-if="isAdmin && !isContainerRunning(server)"
                                                 @click="serverAction('start', server.name)"
                                                 :disabled="loading"
                                                 class="touch-target flex items-center justify-center px-3 py-2 text-sm font-medium rounded-lg text-white bg-green-600 hover:bg-green-700 disabled:bg-gray-400 transition-colors"
@@ -1197,7 +1347,7 @@ const DashboardApp = {
                                             </button>
                                             
                                             <button
-                                                v-if="isContainerRunning(server)"
+                                                v-if="isAdmin && isContainerRunning(server)"
                                                 @click="serverAction('stop', server.name)"
                                                 :disabled="loading"
                                                 class="touch-target flex items-center justify-center px-3 py-2 text-sm font-medium rounded-lg text-white bg-red-600 hover:bg-red-700 disabled:bg-gray-400 transition-colors"
@@ -1209,7 +1359,7 @@ const DashboardApp = {
                                             </button>
                                             
                                             <button
-                                                v-if="isContainerRunning(server)"
+                                                v-if="isAdmin && isContainerRunning(server)"
                                                 @click="serverAction('restart', server.name)"
                                                 :disabled="loading"
                                                 class="touch-target flex items-center justify-center px-3 py-2 text-sm font-medium rounded-lg text-white bg-yellow-600 hover:bg-yellow-700 disabled:bg-gray-400 transition-colors"
@@ -1220,6 +1370,13 @@ const DashboardApp = {
                                                 Restart
                                             </button>
                                             
+                                            <button
+                                                @click="detailServer = server.name"
+                                                class="touch-target flex items-center justify-center px-3 py-2 text-sm font-medium rounded-lg text-gray-700 dark:text-gray-300 bg-white dark:bg-gray-700 border border-gray-300 dark:border-gray-600 hover:bg-gray-50 dark:hover:bg-gray-600 transition-colors"
+                                            >
+                                                Details
+                                            </button>
+                                            
                                             <button
                                                 @click="viewServerLogs(server.name)"
                                                 class="touch-target flex items-center justify-center px-3 py-2 text-sm font-medium rounded-lg text-gray-700 dark:text-gray-300 bg-white dark:bg-gray-700 border border-gray-300 dark:border-gray-600 hover:bg-gray-50 dark:hover:bg-gray-600 transition-colors"
@@ -1233,6 +1390,12 @@ const DashboardApp = {
                         </div>
                     </div>
                 </div>
+                <server-detail
+                    v-if="detailServer"
+                    :server-name="detailServer"
+                    :config="config"
+                    @close="detailServer = null"
+                ></server-detail>
                 <task-scheduler
                     v-if="activeTab === 'tasks'"
                     :config="config"
@@ -1252,6 +1415,19 @@ const DashboardApp = {
                     v-if="activeTab === 'activity'"
                     :config="config"
                 ></activity-viewer>
+                <client-analytics
+                    v-if="activeTab === 'analytics'"
+                    :config="config"
+                ></client-analytics>
+                <sampling-queue
+                    v-if="activeTab === 'sampling'"
+                    :config="config"
+                ></sampling-queue>
+                <config-editor
+                    v-if="activeTab === 'config'"
+                    :config="config"
+                    @applied="loadData"
+                ></config-editor>
                 <!-- Security Tab -->
                 <div v-if="activeTab === 'security'" class="space-y-6 animate-fade-in">
                     <div class="mb-6">
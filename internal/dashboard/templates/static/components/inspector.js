@@ -12,6 +12,16 @@ const MCPInspector = {
             request: '',
             availableMethods: [],
             discoveredTools: [],
+            discoveredResources: [],
+            discoveredPrompts: [],
+            history: [],
+            builder: {
+                kind: 'tool',
+                name: '',
+                uri: '',
+                values: {},
+                errors: []
+            },
             inspectorAvailable: null, // null = unknown, true = available, false = not available
             requestTemplates: {
                 'initialize': {
@@ -29,20 +39,21 @@ const MCPInspector = {
                         }
                     }
                 },
+                'ping': { method: 'ping', params: {} },
                 'tools/list': { method: 'tools/list', params: {} },
-                'tools/get': {
-                    method: 'tools/get',
-                    params: { tool: 'example_tool', parameters: {} }
+                'tools/call': {
+                    method: 'tools/call',
+                    params: { name: 'example_tool', arguments: {} }
                 },
                 'resources/list': { method: 'resources/list', params: {} },
-                'resources/get': {
-                    method: 'resources/get',
-                    params: { path: '/example' }
+                'resources/read': {
+                    method: 'resources/read',
+                    params: { uri: 'file:///example' }
                 },
                 'prompts/list': { method: 'prompts/list', params: {} },
-                'prompts/render': {
-                    method: 'prompts/render',
-                    params: { name: 'example_prompt', variables: {} }
+                'prompts/get': {
+                    method: 'prompts/get',
+                    params: { name: 'example_prompt', arguments: {} }
                 }
             }
         }
@@ -63,6 +74,36 @@ const MCPInspector = {
     computed: {
         isHealthy() {
             return this.connected && this.session;
+        },
+        selectedTool() {
+            return this.discoveredTools.find(tool => tool.name === this.builder.name) || null;
+        },
+        selectedPrompt() {
+            return this.discoveredPrompts.find(prompt => prompt.name === this.builder.name) || null;
+        },
+        // Form fields for the selected tool's inputSchema properties or prompt arguments
+        builderFields() {
+            if (this.builder.kind === 'tool' && this.selectedTool) {
+                const schema = this.selectedTool.inputSchema || {};
+                const required = schema.required || [];
+                return Object.entries(schema.properties || {}).map(([name, property]) => ({
+                    name,
+                    description: property.description || '',
+                    type: this.fieldType(property),
+                    enum: property.enum || null,
+                    required: required.includes(name)
+                }));
+            }
+            if (this.builder.kind === 'prompt' && this.selectedPrompt) {
+                return (this.selectedPrompt.arguments || []).map(argument => ({
+                    name: argument.name,
+                    description: argument.description || '',
+                    type: 'string',
+                    enum: null,
+                    required: !!argument.required
+                }));
+            }
+            return [];
         }
     },
     async mounted() {
@@ -163,35 +204,163 @@ const MCPInspector = {
             this.error = null;
             this.availableMethods = [];
             this.discoveredTools = [];
+            this.discoveredResources = [];
+            this.discoveredPrompts = [];
+            this.history = [];
             this.showToast('Inspector disconnected', 'info');
         },
         discoverMethods(initializeResult) {
-            const methods = ['initialize', 'shutdown'];
+            const methods = ['initialize', 'ping'];
             if (initializeResult && initializeResult.capabilities) {
                 const caps = initializeResult.capabilities;
                 if (caps.resources) {
-                    methods.push('resources/list', 'resources/get');
+                    methods.push('resources/list');
                 }
                 if (caps.tools) {
-                    methods.push('tools/list', 'tools/get');
+                    methods.push('tools/list');
                 }
                 if (caps.prompts) {
-                    methods.push('prompts/list', 'prompts/render');
+                    methods.push('prompts/list');
                 }
             }
             this.availableMethods = methods;
         },
         async discoverTools() {
             try {
-                const response = await this.executeMethod('tools/list', {});
-                if (response && response.result && response.result.tools) {
-                    this.discoveredTools = response.result.tools;
-                    this.$emit('tools-discovered', this.discoveredTools);
+                // Lists every page of tools with their input schemas, without adding to the history
+                const response = await fetch(`/api/inspector/tools?sessionId=${encodeURIComponent(this.session)}`);
+                if (!response.ok) {
+                    return;
                 }
+                const data = await response.json();
+                this.discoveredTools = data.tools || [];
+                this.$emit('tools-discovered', this.discoveredTools);
             } catch (err) {
                 console.warn(`Failed to discover tools for ${this.serverName}:`, err);
             }
         },
+        async discoverResources() {
+            try {
+                const response = await this.executeMethod('resources/list', {});
+                this.discoveredResources = (response && response.result && response.result.resources) || [];
+            } catch (err) {
+                this.error = err.message;
+            }
+        },
+        async discoverPrompts() {
+            try {
+                const response = await this.executeMethod('prompts/list', {});
+                this.discoveredPrompts = (response && response.result && response.result.prompts) || [];
+            } catch (err) {
+                this.error = err.message;
+            }
+        },
+        async setBuilderKind(kind) {
+            this.builder = { kind, name: '', uri: '', values: {}, errors: [] };
+            if (kind === 'resource' && this.discoveredResources.length === 0) {
+                await this.discoverResources();
+            } else if (kind === 'prompt' && this.discoveredPrompts.length === 0) {
+                await this.discoverPrompts();
+            }
+        },
+        selectBuilderItem(kind, name) {
+            this.builder = { kind, name, uri: '', values: {}, errors: [] };
+        },
+        fieldType(property) {
+            const type = Array.isArray(property.type) ? property.type.find(t => t !== 'null') : property.type;
+            if (property.enum) return 'enum';
+            return ['string', 'number', 'integer', 'boolean', 'object', 'array'].includes(type) ? type : 'json';
+        },
+        // Converts form values to typed params, collecting problems instead of sending
+        buildParams() {
+            const errors = [];
+            const values = {};
+            for (const field of this.builderFields) {
+                const raw = this.builder.values[field.name];
+                const empty = raw === undefined || raw === '' || (field.type === 'boolean' && raw === undefined);
+                if (empty) {
+                    if (field.required) errors.push(`${field.name} is required`);
+                    continue;
+                }
+                if (field.type === 'number' || field.type === 'integer') {
+                    const number = Number(raw);
+                    if (Number.isNaN(number) || (field.type === 'integer' && !Number.isInteger(number))) {
+                        errors.push(`${field.name} must be ${field.type === 'integer' ? 'an integer' : 'a number'}`);
+                        continue;
+                    }
+                    values[field.name] = number;
+                } else if (field.type === 'object' || field.type === 'array' || field.type === 'json') {
+                    try {
+                        values[field.name] = JSON.parse(raw);
+                    } catch (err) {
+                        errors.push(`${field.name} must be valid JSON`);
+                    }
+                } else if (field.type === 'enum') {
+                    const option = field.enum.find(value => String(value) === String(raw));
+                    values[field.name] = option !== undefined ? option : raw;
+                } else {
+                    values[field.name] = raw;
+                }
+            }
+            this.builder.errors = errors;
+            if (errors.length > 0) return null;
+            if (this.builder.kind === 'resource') {
+                return { method: 'resources/read', params: { uri: this.builder.uri } };
+            }
+            const method = this.builder.kind === 'tool' ? 'tools/call' : 'prompts/get';
+            return { method, params: { name: this.builder.name, arguments: values } };
+        },
+        async sendBuilderRequest() {
+            const request = this.buildParams();
+            if (!request) return;
+            try {
+                const data = await this.executeMethod(request.method, request.params);
+                if (data && data.error && data.error.data && data.error.data.problems) {
+                    this.builder.errors = data.error.data.problems;
+                }
+            } catch (err) {
+                this.error = err.message;
+                this.showToast(`Error: ${err.message}`, 'error');
+            }
+        },
+        async loadHistory() {
+            if (!this.session) return;
+            try {
+                const response = await fetch(`/api/inspector/history?sessionId=${encodeURIComponent(this.session)}`);
+                if (response.ok) {
+                    const data = await response.json();
+                    this.history = (data.history || []).slice().reverse();
+                }
+            } catch (err) {
+                console.warn('Failed to load inspector history:', err);
+            }
+        },
+        async replay(entry) {
+            try {
+                const response = await fetch('/api/inspector/replay', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ sessionId: this.session, entryId: entry.id })
+                });
+                const data = await response.json();
+                if (!response.ok) {
+                    throw new Error(data.error || `Replay failed: ${response.status}`);
+                }
+                this.response = data;
+            } catch (err) {
+                this.error = err.message;
+                this.showToast(`Replay failed: ${err.message}`, 'error');
+            }
+            await this.loadHistory();
+        },
+        editHistoryEntry(entry) {
+            this.request = JSON.stringify({ method: entry.method, params: entry.params || {} }, null, 2);
+        },
+        historyStatus(entry) {
+            if (entry.error) return 'failed';
+            if (entry.response && entry.response.error) return 'error';
+            return 'ok';
+        },
         async executeMethod(method, params = {}) {
             if (!this.session) {
                 throw new Error('No active session');
@@ -216,6 +385,7 @@ const MCPInspector = {
                 }
                 const data = await response.json();
                 this.response = data;
+                await this.loadHistory();
                 // Update tools list if we just listed tools
                 if (method === 'tools/list' && data.result && data.result.tools) {
                     this.discoveredTools = data.result.tools;
@@ -385,6 +555,102 @@ const MCPInspector = {
                     </button>
                 </div>
             </div>
+            <!-- Request Builder -->
+            <div>
+                <div class="flex items-center justify-between mb-2">
+                    <h6 class="text-xs font-medium text-gray-500 dark:text-gray-400 uppercase tracking-wide">Request Builder</h6>
+                    <div class="flex gap-1">
+                        <button
+                            v-for="kind in ['tool', 'resource', 'prompt']"
+                            :key="kind"
+                            @click="setBuilderKind(kind)"
+                            :class="builder.kind === kind ? 'bg-blue-600 text-white' : 'bg-white dark:bg-gray-700 text-gray-700 dark:text-gray-300'"
+                            class="text-xs px-2 py-1 rounded border border-gray-300 dark:border-gray-600 capitalize"
+                        >
+                            {{ kind }}
+                        </button>
+                    </div>
+                </div>
+                <div class="space-y-2">
+                    <select
+                        v-if="builder.kind === 'tool'"
+                        :value="builder.name"
+                        @change="selectBuilderItem('tool', $event.target.value)"
+                        class="w-full text-xs border border-gray-300 dark:border-gray-600 rounded px-2 py-1 bg-white dark:bg-gray-700 text-gray-700 dark:text-gray-300"
+                    >
+                        <option value="">Select a tool...</option>
+                        <option v-for="tool in discoveredTools" :key="tool.name" :value="tool.name">{{ tool.name }}</option>
+                    </select>
+                    <select
+                        v-if="builder.kind === 'prompt'"
+                        :value="builder.name"
+                        @change="selectBuilderItem('prompt', $event.target.value)"
+                        class="w-full text-xs border border-gray-300 dark:border-gray-600 rounded px-2 py-1 bg-white dark:bg-gray-700 text-gray-700 dark:text-gray-300"
+                    >
+                        <option value="">Select a prompt...</option>
+                        <option v-for="prompt in discoveredPrompts" :key="prompt.name" :value="prompt.name">{{ prompt.name }}</option>
+                    </select>
+                    <div v-if="builder.kind === 'resource'" class="space-y-2">
+                        <select
+                            @change="builder.uri = $event.target.value"
+                            class="w-full text-xs border border-gray-300 dark:border-gray-600 rounded px-2 py-1 bg-white dark:bg-gray-700 text-gray-700 dark:text-gray-300"
+                        >
+                            <option value="">Select a resource...</option>
+                            <option v-for="resource in discoveredResources" :key="resource.uri" :value="resource.uri">{{ resource.name || resource.uri }}</option>
+                        </select>
+                        <input
+                            v-model="builder.uri"
+                            placeholder="Resource URI"
+                            class="w-full px-2 py-1 border border-gray-300 dark:border-gray-600 rounded bg-white dark:bg-gray-700 text-gray-900 dark:text-white font-mono text-xs"
+                        />
+                    </div>
+                    <p v-if="builder.kind === 'tool' && selectedTool && selectedTool.description" class="text-xs text-gray-500 dark:text-gray-400">{{ selectedTool.description }}</p>
+                    <p v-if="builder.kind === 'prompt' && selectedPrompt && selectedPrompt.description" class="text-xs text-gray-500 dark:text-gray-400">{{ selectedPrompt.description }}</p>
+                    <div v-for="field in builderFields" :key="field.name">
+                        <label class="block text-xs font-medium text-gray-700 dark:text-gray-300">
+                            {{ field.name }}<span v-if="field.required" class="text-red-500">*</span>
+                            <span class="text-gray-400 font-normal ml-1">{{ field.type }}</span>
+                        </label>
+                        <select
+                            v-if="field.type === 'enum'"
+                            v-model="builder.values[field.name]"
+                            class="w-full text-xs border border-gray-300 dark:border-gray-600 rounded px-2 py-1 bg-white dark:bg-gray-700 text-gray-700 dark:text-gray-300"
+                        >
+                            <option value=""></option>
+                            <option v-for="option in field.enum" :key="String(option)" :value="option">{{ option }}</option>
+                        </select>
+                        <input
+                            v-else-if="field.type === 'boolean'"
+                            type="checkbox"
+                            v-model="builder.values[field.name]"
+                            class="rounded border-gray-300 dark:border-gray-600"
+                        />
+                        <textarea
+                            v-else-if="field.type === 'object' || field.type === 'array' || field.type === 'json'"
+                            v-model="builder.values[field.name]"
+                            :placeholder="field.type === 'array' ? '[]' : '{}'"
+                            class="w-full h-16 px-2 py-1 border border-gray-300 dark:border-gray-600 rounded bg-white dark:bg-gray-700 text-gray-900 dark:text-white font-mono text-xs resize-none"
+                        ></textarea>
+                        <input
+                            v-else
+                            :type="field.type === 'number' || field.type === 'integer' ? 'number' : 'text'"
+                            v-model="builder.values[field.name]"
+                            class="w-full px-2 py-1 border border-gray-300 dark:border-gray-600 rounded bg-white dark:bg-gray-700 text-gray-900 dark:text-white text-xs"
+                        />
+                        <p v-if="field.description" class="text-xs text-gray-400 mt-0.5">{{ field.description }}</p>
+                    </div>
+                    <ul v-if="builder.errors.length > 0" class="text-xs text-red-500 list-disc list-inside">
+                        <li v-for="problem in builder.errors" :key="problem">{{ problem }}</li>
+                    </ul>
+                    <button
+                        @click="sendBuilderRequest"
+                        :disabled="builder.kind === 'resource' ? !builder.uri : !builder.name"
+                        class="touch-target w-full inline-flex items-center justify-center px-3 py-2 border border-transparent text-sm font-medium rounded-lg text-white bg-blue-600 hover:bg-blue-700 disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
+                    >
+                        {{ builder.kind === 'tool' ? 'Call Tool' : builder.kind === 'resource' ? 'Read Resource' : 'Get Prompt' }}
+                    </button>
+                </div>
+            </div>
             <!-- Response Display -->
             <div v-if="response" class="bg-gray-900 rounded-lg p-4 max-h-64 overflow-y-auto custom-scrollbar">
                 <div class="flex items-center justify-between mb-2">
@@ -401,6 +667,29 @@ const MCPInspector = {
                 </div>
                 <pre class="text-sm text-green-400 font-mono whitespace-pre-wrap">{{ formatJSON(response) }}</pre>
             </div>
+            <!-- Request History -->
+            <div v-if="history.length > 0">
+                <h6 class="text-xs font-medium text-gray-500 dark:text-gray-400 uppercase tracking-wide mb-2">History ({{ history.length }})</h6>
+                <div class="space-y-1 max-h-48 overflow-y-auto custom-scrollbar">
+                    <div
+                        v-for="entry in history"
+                        :key="entry.id"
+                        class="flex items-center justify-between text-xs bg-white dark:bg-gray-800 px-2 py-1 rounded border border-gray-200 dark:border-gray-700"
+                    >
+                        <div class="flex items-center gap-2 min-w-0">
+                            <span :class="historyStatus(entry) === 'ok' ? 'bg-green-500' : 'bg-red-500'" class="w-2 h-2 rounded-full flex-shrink-0"></span>
+                            <span class="font-mono text-gray-900 dark:text-white">{{ entry.method }}</span>
+                            <span v-if="entry.params && entry.params.name" class="text-gray-500 truncate">{{ entry.params.name }}</span>
+                            <span class="text-gray-400">{{ entry.durationMs }}ms</span>
+                        </div>
+                        <div class="flex gap-1 flex-shrink-0">
+                            <button @click="response = entry.response || { error: entry.error }" class="text-gray-500 hover:text-gray-700 dark:hover:text-gray-300 px-1">View</button>
+                            <button @click="editHistoryEntry(entry)" class="text-gray-500 hover:text-gray-700 dark:hover:text-gray-300 px-1">Edit</button>
+                            <button @click="replay(entry)" class="text-blue-600 hover:text-blue-800 dark:text-blue-400 px-1">Replay</button>
+                        </div>
+                    </div>
+                </div>
+            </div>
             <!-- Discovered Tools Display -->
             <div v-if="discoveredTools.length > 0">
                 <h6 class="text-xs font-medium text-gray-500 dark:text-gray-400 uppercase tracking-wide mb-2">Discovered Tools ({{ discoveredTools.length }})</h6>
@@ -418,7 +707,7 @@ const MCPInspector = {
                                 </div>
                             </div>
                             <button
-                                @click="executeTemplate('tools/get')"
+                                @click="selectBuilderItem('tool', tool.name)"
                                 class="text-xs text-blue-600 hover:text-blue-800 dark:text-blue-400 dark:hover:text-blue-200 touch-target px-2 py-1 rounded hover:bg-blue-50 dark:hover:bg-blue-900/20"
                             >
                                 Test
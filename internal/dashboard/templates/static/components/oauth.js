@@ -23,7 +23,13 @@ const OAuthConfig = {
             sortBy: 'name',
             expandedSections: new Set(),
             autoRefresh: false,
-            refreshInterval: null
+            refreshInterval: null,
+            // Device sign-ins waiting for approval; a verification link fills in its code
+            deviceSignIns: [],
+            deviceUserCode: new URLSearchParams(window.location.search).get('user_code') || '',
+            decidingDevice: null,
+            // What users have granted clients; non-admins only see their own
+            consents: []
         }
     },
     computed: {
@@ -120,6 +126,8 @@ const OAuthConfig = {
                     fetch('/api/oauth/status'),
                     fetch('/api/oauth/clients')
                 ]);
+                this.loadDeviceSignIns();
+                this.loadConsents();
                 
                 if (statusRes.ok && statusRes.headers.get('content-type')?.includes('application/json')) {
                     this.oauthStatus = await statusRes.json();
@@ -232,6 +240,70 @@ const OAuthConfig = {
             }
         },
 
+        async loadDeviceSignIns() {
+            try {
+                const response = await fetch('/api/oauth/device');
+                if (response.ok) {
+                    const data = await response.json();
+                    this.deviceSignIns = data.devices || [];
+                }
+            } catch (error) {
+                console.warn('Device sign-ins not available:', error);
+            }
+        },
+
+        async loadConsents() {
+            try {
+                const response = await fetch('/api/oauth/consents');
+                if (response.ok) {
+                    const data = await response.json();
+                    this.consents = data.consents || [];
+                }
+            } catch (error) {
+                console.warn('OAuth consents not available:', error);
+            }
+        },
+
+        async revokeConsent(consent) {
+            const client = consent.client_name || consent.client_id;
+            if (!confirm(`Revoke what ${consent.user_id} granted ${client}?\n\nIts tokens stop working and it has to ask for consent again.`)) return;
+            try {
+                const response = await fetch(`/api/oauth/consents/${encodeURIComponent(consent.user_id)}/${encodeURIComponent(consent.client_id)}`, { method: 'DELETE' });
+                const data = await response.json().catch(() => ({}));
+                if (!response.ok) {
+                    throw new Error(data.error || `HTTP ${response.status}`);
+                }
+                this.showToast(`Revoked access of ${client}`, 'success');
+                await this.loadConsents();
+            } catch (error) {
+                this.showToast(`Failed to revoke consent: ${error.message}`, 'error');
+            }
+        },
+
+        matchesDeviceCode(device) {
+            const normalize = code => code.toUpperCase().replace(/[-\s]/g, '');
+            return !this.deviceUserCode || normalize(device.user_code) === normalize(this.deviceUserCode);
+        },
+
+        async decideDevice(userCode, action) {
+            if (action === 'approve' && !confirm(`Approve device sign-in ${userCode}?\n\nOnly approve if the device shows the same code. It will act as you.`)) return;
+            this.decidingDevice = userCode;
+            try {
+                const response = await fetch(`/api/oauth/device/${encodeURIComponent(userCode)}/${action}`, { method: 'POST' });
+                const data = await response.json().catch(() => ({}));
+                if (!response.ok) {
+                    throw new Error(data.error || `HTTP ${response.status}`);
+                }
+                this.showToast(action === 'approve' ? `Device ${userCode} approved` : `Device ${userCode} denied`, 'success');
+                this.deviceUserCode = '';
+                await this.loadDeviceSignIns();
+            } catch (error) {
+                this.showToast(`Failed to ${action} device sign-in: ${error.message}`, 'error');
+            } finally {
+                this.decidingDevice = null;
+            }
+        },
+
         viewClientDetails(client) {
             this.showClientDetails = client;
         },
@@ -619,6 +691,94 @@ const OAuthConfig = {
                     </div>
                 </div>
 
+                <!-- Device Sign-ins -->
+                <div class="enhanced-card">
+                    <div class="p-4 lg:p-6">
+                        <div class="flex flex-col lg:flex-row lg:items-center lg:justify-between space-y-4 lg:space-y-0 mb-4">
+                            <div class="flex items-center space-x-3">
+                                <div class="w-10 h-10 bg-amber-500 rounded-lg flex items-center justify-center">
+                                    <svg class="w-5 h-5 text-white heroicon" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" :d="getHeroIcon('key')"></path>
+                                    </svg>
+                                </div>
+                                <div>
+                                    <h4 class="text-lg font-medium text-gray-100">Device Sign-ins</h4>
+                                    <p class="text-sm text-gray-300">CLI tools and headless clients waiting for someone to approve the code they show</p>
+                                </div>
+                            </div>
+                            <input v-model="deviceUserCode" type="text" placeholder="Code shown on the device" class="form-input w-full lg:w-64 uppercase tracking-widest">
+                        </div>
+                        <div v-if="deviceSignIns.filter(matchesDeviceCode).length === 0" class="text-sm text-gray-400 py-4">
+                            {{ deviceUserCode ? 'No device is waiting with that code. It may have expired.' : 'No device sign-ins are waiting.' }}
+                        </div>
+                        <div v-else class="space-y-2">
+                            <div v-for="device in deviceSignIns.filter(matchesDeviceCode)" :key="device.user_code"
+                                 class="flex flex-col sm:flex-row sm:items-center sm:justify-between bg-gray-800 border border-gray-700 rounded-lg p-3 space-y-2 sm:space-y-0">
+                                <div>
+                                    <div class="font-mono text-lg text-gray-100 tracking-widest">{{ device.user_code }}</div>
+                                    <div class="text-xs text-gray-400">
+                                        Client {{ device.client_id }} &middot; {{ device.scope || 'no scope requested' }} &middot; expires {{ new Date(device.expires_at).toLocaleTimeString() }}
+                                    </div>
+                                </div>
+                                <div class="flex space-x-2">
+                                    <button @click="decideDevice(device.user_code, 'approve')" :disabled="decidingDevice === device.user_code"
+                                            class="px-3 py-1 bg-green-600 text-white rounded hover:bg-green-700 text-sm disabled:opacity-50">Approve</button>
+                                    <button @click="decideDevice(device.user_code, 'deny')" :disabled="decidingDevice === device.user_code"
+                                            class="px-3 py-1 bg-red-600 text-white rounded hover:bg-red-700 text-sm disabled:opacity-50">Deny</button>
+                                </div>
+                            </div>
+                        </div>
+                    </div>
+                </div>
+
+                <!-- Granted Consents -->
+                <div class="enhanced-card">
+                    <div class="p-4 lg:p-6">
+                        <div class="flex items-center space-x-3 mb-4">
+                            <div class="w-10 h-10 bg-teal-500 rounded-lg flex items-center justify-center">
+                                <svg class="w-5 h-5 text-white heroicon" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                                    <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" :d="getHeroIcon('shield-check')"></path>
+                                </svg>
+                            </div>
+                            <div>
+                                <h4 class="text-lg font-medium text-gray-100">Granted Access</h4>
+                                <p class="text-sm text-gray-300">Scopes users approved for each client; they aren't asked again unless a client wants more</p>
+                            </div>
+                        </div>
+                        <div v-if="consents.length === 0" class="text-sm text-gray-400 py-4">No client has been granted access yet.</div>
+                        <div v-else class="overflow-x-auto">
+                            <table class="min-w-full text-sm">
+                                <thead>
+                                    <tr class="text-left text-gray-400 border-b border-gray-700">
+                                        <th class="py-2 pr-4">User</th>
+                                        <th class="py-2 pr-4">Client</th>
+                                        <th class="py-2 pr-4">Scopes</th>
+                                        <th class="py-2 pr-4">Last granted</th>
+                                        <th class="py-2"></th>
+                                    </tr>
+                                </thead>
+                                <tbody>
+                                    <tr v-for="consent in consents" :key="consent.user_id + '/' + consent.client_id" class="border-b border-gray-800 text-gray-200">
+                                        <td class="py-2 pr-4">{{ consent.user_id }}</td>
+                                        <td class="py-2 pr-4">
+                                            {{ consent.client_name || consent.client_id }}
+                                            <div v-if="consent.client_name && consent.client_name !== consent.client_id" class="text-xs text-gray-500 font-mono">{{ consent.client_id }}</div>
+                                        </td>
+                                        <td class="py-2 pr-4">
+                                            <span v-for="scope in consent.scopes" :key="scope" class="inline-block bg-gray-700 text-gray-200 rounded px-2 py-0.5 mr-1 mb-1 text-xs font-mono">{{ scope }}</span>
+                                            <span v-if="consent.scopes.length === 0" class="text-gray-500">none</span>
+                                        </td>
+                                        <td class="py-2 pr-4 text-gray-400">{{ new Date(consent.updated_at).toLocaleString() }}</td>
+                                        <td class="py-2 text-right">
+                                            <button @click="revokeConsent(consent)" class="px-3 py-1 bg-red-600 text-white rounded hover:bg-red-700 text-xs">Revoke</button>
+                                        </td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                    </div>
+                </div>
+
                 <!-- OAuth Clients Management -->
                 <div class="enhanced-card">
                     <div class="p-4 lg:p-6">
@@ -0,0 +1,242 @@
+const SamplingQueue = {
+    props: ['config'],
+    data() {
+        return {
+            requests: [],
+            enabled: true,
+            statusFilter: 'awaiting_approval',
+            loading: false,
+            error: '',
+            editing: null,
+            edit: { systemPrompt: '', maxTokens: 0, messages: [], comments: '' },
+            busy: {},
+            timer: null
+        }
+    },
+    computed: {
+        canReview() {
+            return !this.config.loginEnabled || this.config.role === 'admin';
+        }
+    },
+    methods: {
+        async request(endpoint, options = {}) {
+            const headers = { 'Content-Type': 'application/json' };
+            if (this.config.apiKey) {
+                headers['Authorization'] = `Bearer ${this.config.apiKey}`;
+            }
+            const response = await fetch(endpoint, { headers, ...options });
+            if (response.status === 401 && this.config.loginEnabled) {
+                window.location.href = '/login?next=' + encodeURIComponent(window.location.pathname);
+                throw new Error('Session expired');
+            }
+            let data = {};
+            try {
+                data = await response.json();
+            } catch (e) {
+                data = { error: `Unexpected response (HTTP ${response.status})` };
+            }
+            return { status: response.status, data };
+        },
+
+        async loadRequests() {
+            this.loading = true;
+            try {
+                const query = this.statusFilter ? `?status=${encodeURIComponent(this.statusFilter)}` : '';
+                const { status, data } = await this.request('/api/sampling' + query);
+                if (status !== 200) {
+                    throw new Error(data.error || `HTTP ${status}`);
+                }
+                this.enabled = data.enabled;
+                this.requests = data.requests || [];
+                this.error = '';
+            } catch (err) {
+                this.error = err.message;
+            } finally {
+                this.loading = false;
+            }
+        },
+
+        startEdit(request) {
+            this.editing = request.id;
+            this.edit = {
+                systemPrompt: request.systemPrompt || '',
+                maxTokens: request.maxTokens || 0,
+                messages: request.messages.map(message => ({ role: message.role, content: { ...message.content } })),
+                comments: ''
+            };
+        },
+
+        cancelEdit() {
+            this.editing = null;
+        },
+
+        async review(request, action, changes = {}) {
+            let comments = changes.comments || '';
+            if (action === 'deny') {
+                const reason = prompt('Reason for denying (the server sees it):', '');
+                if (reason === null) return;
+                comments = reason;
+            }
+            this.busy = { ...this.busy, [request.id]: true };
+            try {
+                const { status, data } = await this.request(`/api/sampling/${encodeURIComponent(request.id)}/${action}`, {
+                    method: 'POST',
+                    body: JSON.stringify({ ...changes, comments })
+                });
+                if (status !== 200) {
+                    throw new Error(data.error || `HTTP ${status}`);
+                }
+                this.editing = null;
+                this.showToast(`Sampling request ${action === 'approve' ? 'approved' : 'denied'}`, action === 'approve' ? 'success' : 'info');
+                await this.loadRequests();
+            } catch (err) {
+                this.showToast(`Failed to ${action} request: ${err.message}`, 'error');
+            } finally {
+                const { [request.id]: _, ...rest } = this.busy;
+                this.busy = rest;
+            }
+        },
+
+        approveEdited(request) {
+            const changes = { messages: this.edit.messages, comments: this.edit.comments };
+            if (this.edit.systemPrompt !== (request.systemPrompt || '')) {
+                changes.systemPrompt = this.edit.systemPrompt;
+            }
+            if (Number(this.edit.maxTokens) !== (request.maxTokens || 0)) {
+                changes.maxTokens = Number(this.edit.maxTokens) || 0;
+            }
+            this.review(request, 'approve', changes);
+        },
+
+        statusClass(status) {
+            switch (status) {
+                case 'awaiting_approval': return 'bg-yellow-900/50 text-yellow-300 border-yellow-700';
+                case 'completed': return 'bg-green-900/50 text-green-300 border-green-700';
+                case 'rejected':
+                case 'failed': return 'bg-red-900/50 text-red-300 border-red-700';
+                default: return 'bg-gray-700 text-gray-300 border-gray-600';
+            }
+        },
+
+        formatTime(value) {
+            return value ? new Date(value).toLocaleString() : '';
+        },
+
+        messageText(message) {
+            const content = message.content || {};
+            return content.type === 'image' ? `[image ${content.mimeType || ''}]` : content.text;
+        },
+
+        showToast(message, type = 'info') {
+            window.showToast && window.showToast(message, type);
+        }
+    },
+    watch: {
+        statusFilter() {
+            this.loadRequests();
+        }
+    },
+    mounted() {
+        this.loadRequests();
+        this.timer = setInterval(() => {
+            // Don't pull the form out from under a reviewer
+            if (!this.editing) this.loadRequests();
+        }, 3000);
+    },
+    beforeUnmount() {
+        clearInterval(this.timer);
+    },
+    template: `
+        <div class="space-y-6 animate-fade-in">
+            <div class="flex flex-col sm:flex-row sm:items-center sm:justify-between gap-3">
+                <div>
+                    <h2 class="text-2xl font-bold text-white mb-1">Sampling</h2>
+                    <p class="text-gray-400 text-sm">
+                        Completions servers ask the proxy for. Requests that need approval wait here until
+                        they are approved, edited or denied.
+                    </p>
+                </div>
+                <div class="flex space-x-2">
+                    <select v-model="statusFilter" class="bg-gray-700 border border-gray-600 rounded-md text-xs text-gray-200 px-2 py-1.5">
+                        <option value="awaiting_approval">Awaiting approval</option>
+                        <option value="">All requests</option>
+                        <option value="completed">Completed</option>
+                        <option value="rejected">Rejected</option>
+                        <option value="failed">Failed</option>
+                    </select>
+                    <button @click="loadRequests" :disabled="loading" class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 focus:outline-none focus:ring-2 focus:ring-gray-500 disabled:opacity-50 transition-all touch-target">Refresh</button>
+                </div>
+            </div>
+
+            <div v-if="error" class="rounded border border-red-700 bg-red-900/40 px-4 py-3 text-sm text-red-200">{{ error }}</div>
+            <div v-if="!enabled" class="rounded border border-yellow-700 bg-yellow-900/30 px-4 py-3 text-sm text-yellow-200">
+                The sampling broker is disabled. Set <code>sampling.enabled</code> in the compose file to answer servers' sampling requests.
+            </div>
+
+            <p v-if="!requests.length && !error" class="text-gray-400 text-sm">
+                {{ statusFilter === 'awaiting_approval' ? 'No requests are waiting for approval.' : 'No sampling requests yet.' }}
+            </p>
+
+            <div v-for="request in requests" :key="request.id" class="bg-gray-800 border border-gray-700 rounded-lg p-4 space-y-3">
+                <div class="flex flex-wrap items-center justify-between gap-2">
+                    <div class="flex items-center space-x-2">
+                        <span class="font-semibold text-white">{{ request.serverName }}</span>
+                        <span :class="statusClass(request.status)" class="text-xs px-2 py-0.5 rounded border">{{ request.status.replace('_', ' ') }}</span>
+                        <span class="text-xs text-gray-400">{{ request.model }}</span>
+                    </div>
+                    <span class="text-xs text-gray-500">{{ formatTime(request.created) }}</span>
+                </div>
+
+                <template v-if="editing !== request.id">
+                    <div v-if="request.systemPrompt" class="text-sm">
+                        <span class="text-gray-400">System:</span>
+                        <span class="text-gray-200 whitespace-pre-wrap">{{ request.systemPrompt }}</span>
+                    </div>
+                    <div v-for="(message, index) in request.messages" :key="index" class="text-sm">
+                        <span class="text-gray-400 capitalize">{{ message.role }}:</span>
+                        <span class="text-gray-200 whitespace-pre-wrap">{{ messageText(message) }}</span>
+                    </div>
+                    <p v-if="request.maxTokens" class="text-xs text-gray-500">Up to {{ request.maxTokens }} tokens</p>
+                </template>
+
+                <div v-else class="space-y-2">
+                    <label class="block text-xs text-gray-400">System prompt</label>
+                    <textarea v-model="edit.systemPrompt" rows="2" class="w-full font-mono text-sm bg-gray-900 border border-gray-700 rounded p-2 text-gray-100 focus:border-blue-500 focus:outline-none"></textarea>
+                    <div v-for="(message, index) in edit.messages" :key="index">
+                        <label class="block text-xs text-gray-400 capitalize">{{ message.role }}</label>
+                        <textarea v-if="message.content.type !== 'image'" v-model="message.content.text" rows="3" class="w-full font-mono text-sm bg-gray-900 border border-gray-700 rounded p-2 text-gray-100 focus:border-blue-500 focus:outline-none"></textarea>
+                        <p v-else class="text-sm text-gray-400">{{ messageText(message) }}</p>
+                    </div>
+                    <div class="flex flex-wrap gap-3">
+                        <label class="text-xs text-gray-400">Max tokens
+                            <input v-model="edit.maxTokens" type="number" min="0" class="ml-1 w-24 bg-gray-900 border border-gray-700 rounded px-2 py-1 text-sm text-gray-100">
+                        </label>
+                        <input v-model="edit.comments" placeholder="Comment (optional)" class="flex-1 bg-gray-900 border border-gray-700 rounded px-2 py-1 text-sm text-gray-100">
+                    </div>
+                </div>
+
+                <div v-if="request.humanReview" class="text-xs text-gray-400">
+                    {{ request.humanReview.approved ? 'Approved' : 'Rejected' }} by {{ request.humanReview.reviewer }}
+                    {{ formatTime(request.humanReview.reviewTime) }}<span v-if="request.humanReview.comments">: {{ request.humanReview.comments }}</span>
+                </div>
+                <div v-if="request.response" class="text-sm border-t border-gray-700 pt-2">
+                    <span class="text-gray-400">Answer:</span>
+                    <span class="text-gray-200 whitespace-pre-wrap">{{ request.response.content.text }}</span>
+                </div>
+                <div v-if="request.error" class="text-sm text-red-300">{{ request.error }}</div>
+
+                <div v-if="request.status === 'awaiting_approval' && canReview" class="flex space-x-2">
+                    <template v-if="editing !== request.id">
+                        <button @click="review(request, 'approve')" :disabled="busy[request.id]" class="inline-flex items-center px-3 py-1.5 border border-green-600/30 text-xs font-medium rounded-md text-white bg-green-600 hover:bg-green-700 disabled:opacity-50 transition-all touch-target">Approve</button>
+                        <button @click="startEdit(request)" :disabled="busy[request.id]" class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 disabled:opacity-50 transition-all touch-target">Edit</button>
+                        <button @click="review(request, 'deny')" :disabled="busy[request.id]" class="inline-flex items-center px-3 py-1.5 border border-red-600/30 text-xs font-medium rounded-md text-white bg-red-600 hover:bg-red-700 disabled:opacity-50 transition-all touch-target">Deny</button>
+                    </template>
+                    <template v-else>
+                        <button @click="approveEdited(request)" :disabled="busy[request.id]" class="inline-flex items-center px-3 py-1.5 border border-green-600/30 text-xs font-medium rounded-md text-white bg-green-600 hover:bg-green-700 disabled:opacity-50 transition-all touch-target">Approve edited</button>
+                        <button @click="cancelEdit" class="inline-flex items-center px-3 py-1.5 border border-gray-600 text-xs font-medium rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 transition-all touch-target">Cancel</button>
+                    </template>
+                </div>
+            </div>
+        </div>
+    `
+};
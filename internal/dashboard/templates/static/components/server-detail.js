@@ -0,0 +1,255 @@
+const ServerDetail = {
+    props: ['serverName', 'config'],
+    emits: ['close'],
+    data() {
+        return {
+            detail: null,
+            loading: false,
+            error: '',
+            section: 'overview'
+        }
+    },
+    computed: {
+        sections() {
+            const sections = [{ id: 'overview', name: 'Overview' }];
+            if (this.detail && this.detail.container) sections.push({ id: 'container', name: 'Container' });
+            if (this.detail && this.detail.security) sections.push({ id: 'security', name: 'Security' });
+            sections.push({ id: 'health', name: 'Health' });
+            sections.push({ id: 'capabilities', name: 'Capabilities' });
+            sections.push({ id: 'config', name: 'Config' });
+            return sections;
+        },
+        container() {
+            return (this.detail && this.detail.container) || null;
+        },
+        healthHistory() {
+            const history = (this.detail && this.detail.health && this.detail.health.history) || [];
+            return [...history].reverse();
+        },
+        security() {
+            return (this.detail && this.detail.security) || null;
+        },
+        catalog() {
+            return (this.detail && this.detail.capabilities && this.detail.capabilities.catalog) || {};
+        }
+    },
+    methods: {
+        async loadDetail() {
+            this.loading = true;
+            this.error = '';
+            try {
+                const headers = {};
+                if (this.config.apiKey) {
+                    headers['Authorization'] = `Bearer ${this.config.apiKey}`;
+                }
+                const response = await fetch(`/api/servers/${encodeURIComponent(this.serverName)}/detail`, { headers });
+                if (response.status === 401 && this.config.loginEnabled) {
+                    window.location.href = '/login?next=' + encodeURIComponent(window.location.pathname);
+                    return;
+                }
+                const data = await response.json();
+                if (!response.ok) {
+                    throw new Error(data.error || `HTTP ${response.status}`);
+                }
+                this.detail = data;
+            } catch (err) {
+                this.error = err.message;
+            } finally {
+                this.loading = false;
+            }
+        },
+
+        formatTime(value) {
+            return value ? new Date(value).toLocaleString() : '—';
+        },
+
+        formatPort(port) {
+            const published = port.public_port ? `${port.ip || '0.0.0.0'}:${port.public_port} → ` : '';
+            return `${published}${port.private_port}/${port.type}`;
+        },
+
+        healthClass(status) {
+            if (status === 'healthy') return 'text-green-400';
+            if (status === 'unhealthy') return 'text-red-400';
+            if (status && status.startsWith('failing')) return 'text-yellow-400';
+            return 'text-gray-400';
+        },
+
+        securityClass(security) {
+            if (security.failing > 0) return 'text-red-400';
+            if (security.warning > 0) return 'text-yellow-400';
+            return 'text-green-400';
+        },
+
+        severityClass(severity) {
+            if (severity === 'critical') return 'text-red-400';
+            if (severity === 'high') return 'text-orange-400';
+            if (severity === 'medium') return 'text-yellow-400';
+            return 'text-gray-400';
+        },
+
+        pretty(value) {
+            return JSON.stringify(value, null, 2);
+        }
+    },
+    mounted() {
+        this.loadDetail();
+    },
+    template: `
+        <div class="fixed inset-0 z-50 flex items-center justify-center bg-black/60 px-4" @click.self="$emit('close')">
+            <div class="w-full max-w-4xl max-h-[90vh] flex flex-col bg-gray-800 border border-gray-700 rounded-lg shadow-xl">
+                <div class="flex items-center justify-between px-5 py-4 border-b border-gray-700">
+                    <div>
+                        <h2 class="text-lg font-semibold text-white">{{ serverName }}</h2>
+                        <p v-if="detail" class="text-xs text-gray-400">
+                            {{ detail.isContainer ? 'Container' : 'Process' }} · {{ detail.status }}
+                            <span v-if="detail.startTime"> · started {{ formatTime(detail.startTime) }}</span>
+                            <span v-if="detail.backoffUntil" class="text-yellow-400"> · backed off until {{ formatTime(detail.backoffUntil) }}</span>
+                        </p>
+                    </div>
+                    <div class="flex items-center space-x-2">
+                        <button @click="loadDetail" :disabled="loading" class="px-3 py-1.5 text-xs rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600 disabled:opacity-50">Refresh</button>
+                        <button @click="$emit('close')" class="px-3 py-1.5 text-xs rounded-md text-gray-200 bg-gray-700 hover:bg-gray-600">Close</button>
+                    </div>
+                </div>
+
+                <nav class="flex space-x-1 px-5 pt-3">
+                    <button
+                        v-for="entry in sections" :key="entry.id"
+                        @click="section = entry.id"
+                        :class="['px-3 py-1.5 text-sm rounded-md', section === entry.id ? 'bg-gray-700 text-white' : 'text-gray-400 hover:text-gray-200']">
+                        {{ entry.name }}
+                    </button>
+                </nav>
+
+                <div class="flex-1 overflow-y-auto px-5 py-4 text-sm text-gray-200">
+                    <div v-if="loading && !detail" class="text-gray-400">Loading...</div>
+                    <div v-if="error" class="rounded border border-red-700 bg-red-900/40 px-3 py-2 text-red-200">{{ error }}</div>
+
+                    <template v-if="detail">
+                        <div v-if="section === 'overview'" class="space-y-4">
+                            <dl class="grid grid-cols-1 sm:grid-cols-2 gap-x-6 gap-y-2">
+                                <div><dt class="text-gray-400">Status</dt><dd>{{ detail.status }}</dd></div>
+                                <div><dt class="text-gray-400">Health</dt><dd :class="healthClass(detail.health.status)">{{ detail.health.status }} ({{ detail.health.type }})</dd></div>
+                                <div v-if="container"><dt class="text-gray-400">Image</dt><dd class="font-mono break-all">{{ container.image }}</dd></div>
+                                <div v-if="container"><dt class="text-gray-400">Restarts</dt><dd>{{ container.restartCount }}</dd></div>
+                                <div v-if="security"><dt class="text-gray-400">Vulnerabilities</dt><dd :class="securityClass(security)">{{ security.summary }}</dd></div>
+                                <div v-if="detail.process"><dt class="text-gray-400">PID</dt><dd>{{ detail.process.childPid || '—' }} (supervisor {{ detail.process.supervisorPid }})</dd></div>
+                                <div v-if="detail.process"><dt class="text-gray-400">Restarts</dt><dd>{{ detail.process.restarts }} (last exit code {{ detail.process.lastExitCode }})</dd></div>
+                            </dl>
+                            <div v-if="detail.containerError" class="rounded border border-yellow-700 bg-yellow-900/30 px-3 py-2 text-yellow-200">{{ detail.containerError }}</div>
+                        </div>
+
+                        <div v-if="section === 'container' && container" class="space-y-4">
+                            <dl class="grid grid-cols-1 gap-y-2">
+                                <div><dt class="text-gray-400">Container ID</dt><dd class="font-mono break-all">{{ container.id }}</dd></div>
+                                <div><dt class="text-gray-400">Image ID</dt><dd class="font-mono break-all">{{ container.imageId }}</dd></div>
+                                <div>
+                                    <dt class="text-gray-400">Image digests</dt>
+                                    <dd v-if="container.imageDigests && container.imageDigests.length" class="font-mono break-all">
+                                        <div v-for="digest in container.imageDigests" :key="digest">{{ digest }}</div>
+                                    </dd>
+                                    <dd v-else class="text-gray-500">None (image built locally)</dd>
+                                </div>
+                                <div><dt class="text-gray-400">Command</dt><dd class="font-mono break-all">{{ (container.command || []).join(' ') || '—' }}</dd></div>
+                            </dl>
+                            <div>
+                                <h3 class="text-gray-400 mb-1">Ports</h3>
+                                <div v-if="!container.ports.length" class="text-gray-500">None</div>
+                                <div v-for="(port, index) in container.ports" :key="index" class="font-mono">{{ formatPort(port) }}</div>
+                            </div>
+                            <div>
+                                <h3 class="text-gray-400 mb-1">Mounts</h3>
+                                <div v-if="!container.mounts.length" class="text-gray-500">None</div>
+                                <div v-for="(mount, index) in container.mounts" :key="index" class="font-mono break-all">
+                                    {{ mount.source }} → {{ mount.destination }} <span class="text-gray-500">({{ mount.type }}, {{ mount.rw ? 'rw' : 'ro' }})</span>
+                                </div>
+                            </div>
+                            <div>
+                                <h3 class="text-gray-400 mb-1">Networks</h3>
+                                <div v-for="(endpoint, network) in container.networks" :key="network" class="font-mono">
+                                    {{ network }} <span class="text-gray-500">{{ endpoint.ipv4_address }}</span>
+                                </div>
+                            </div>
+                            <div>
+                                <h3 class="text-gray-400 mb-1">Environment</h3>
+                                <div v-for="entry in container.env" :key="entry" class="font-mono text-xs break-all">{{ entry }}</div>
+                            </div>
+                        </div>
+
+                        <div v-if="section === 'security' && security" class="space-y-3">
+                            <p>
+                                <span :class="securityClass(security)">{{ security.summary }}</span>
+                                <span class="text-gray-400"> · scanned with {{ security.scanner }} {{ formatTime(security.scannedAt) }}</span>
+                            </p>
+                            <p class="text-gray-400 text-xs">
+                                <template v-if="security.enabled">Fails to start on {{ security.failOn }} or worse, warns on {{ security.warnOn }} or worse</template>
+                                <template v-else>Scanning is not enabled for this server; this is the last cached scan of its image</template>
+                            </p>
+                            <table v-if="security.vulnerabilities && security.vulnerabilities.length" class="w-full text-left text-xs">
+                                <thead class="text-gray-400"><tr><th class="py-1">Severity</th><th>ID</th><th>Package</th><th>Installed</th><th>Fixed in</th></tr></thead>
+                                <tbody>
+                                    <tr v-for="(vulnerability, index) in security.vulnerabilities" :key="index" class="border-t border-gray-700" :title="vulnerability.title">
+                                        <td :class="['py-1 pr-3', severityClass(vulnerability.severity)]">{{ vulnerability.severity }}</td>
+                                        <td class="pr-3 font-mono whitespace-nowrap">{{ vulnerability.id }}</td>
+                                        <td class="pr-3 font-mono break-all">{{ vulnerability.package }}</td>
+                                        <td class="pr-3 font-mono break-all">{{ vulnerability.installedVersion }}</td>
+                                        <td class="font-mono break-all">{{ vulnerability.fixedVersion || '—' }}</td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                            <p v-else class="text-gray-500">No known vulnerabilities.</p>
+                        </div>
+
+                        <div v-if="section === 'health'" class="space-y-3">
+                            <p>
+                                <span :class="healthClass(detail.health.status)">{{ detail.health.status }}</span>
+                                <span class="text-gray-400"> · last checked {{ formatTime(detail.health.lastChecked) }}</span>
+                            </p>
+                            <p v-if="detail.health.error" class="text-red-300">{{ detail.health.error }}</p>
+                            <table v-if="healthHistory.length" class="w-full text-left text-xs">
+                                <thead class="text-gray-400"><tr><th class="py-1">Time</th><th>Status</th><th>Error</th></tr></thead>
+                                <tbody>
+                                    <tr v-for="(record, index) in healthHistory" :key="index" class="border-t border-gray-700">
+                                        <td class="py-1 pr-3 whitespace-nowrap">{{ formatTime(record.time) }}</td>
+                                        <td :class="['pr-3', healthClass(record.status)]">{{ record.status }}</td>
+                                        <td class="text-gray-400 break-all">{{ record.error }}</td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                            <p v-else class="text-gray-500">No health checks have run yet.</p>
+                        </div>
+
+                        <div v-if="section === 'capabilities'" class="space-y-4">
+                            <div v-if="detail.capabilities.serverInfo">
+                                <h3 class="text-gray-400 mb-1">Server info</h3>
+                                <pre class="text-xs font-mono bg-gray-900 rounded p-3 overflow-x-auto">{{ pretty(detail.capabilities.serverInfo) }}</pre>
+                            </div>
+                            <div>
+                                <h3 class="text-gray-400 mb-1">Reported capabilities</h3>
+                                <pre v-if="detail.capabilities.reported" class="text-xs font-mono bg-gray-900 rounded p-3 overflow-x-auto">{{ pretty(detail.capabilities.reported) }}</pre>
+                                <p v-else class="text-gray-500">The proxy has not initialized a session with this server yet.</p>
+                            </div>
+                            <div>
+                                <h3 class="text-gray-400 mb-1">Cached lists</h3>
+                                <p v-if="!Object.keys(catalog).length" class="text-gray-500">Nothing cached yet.</p>
+                                <div v-for="(summary, kind) in catalog" :key="kind" class="mb-2">
+                                    <span class="font-medium">{{ kind }}</span>
+                                    <span class="text-gray-400"> · {{ summary.count }} · fetched {{ formatTime(summary.fetchedAt) }}</span>
+                                    <div class="flex flex-wrap gap-1 mt-1">
+                                        <span v-for="name in summary.names" :key="name" class="px-2 py-0.5 rounded-full text-xs bg-blue-900 text-blue-200">{{ name }}</span>
+                                    </div>
+                                </div>
+                            </div>
+                        </div>
+
+                        <div v-if="section === 'config'">
+                            <p class="text-gray-400 text-xs mb-2">Resolved configuration, secrets redacted</p>
+                            <pre class="text-xs font-mono bg-gray-900 rounded p-3 overflow-x-auto">{{ pretty(detail.config) }}</pre>
+                        </div>
+                    </template>
+                </div>
+            </div>
+        </div>
+    `
+};
@@ -63,7 +63,9 @@ const ServerManager = {
             const containerStatus = server.containerStatus?.toLowerCase();
             const connectionStatus = this.getConnectionStatus(server);
             
-            if (containerStatus === 'running' && connectionStatus === 'Connected') {
+            if (containerStatus === 'running' && server.health?.status === 'unhealthy') {
+                return { label: 'Unhealthy', class: 'error', icon: 'x-circle' };
+            } else if (containerStatus === 'running' && connectionStatus === 'Connected') {
                 return { label: 'Healthy', class: 'healthy', icon: 'check-circle' };
             } else if (containerStatus === 'running') {
                 return { label: 'Running', class: 'running', icon: 'play-circle' };
@@ -125,12 +127,29 @@ const ServerManager = {
         },
 
         isServerHealthy(server) {
-            return this.isContainerRunning(server) && this.getConnectionStatus(server) === 'Connected';
+            return this.isContainerRunning(server) && this.getConnectionStatus(server) === 'Connected' &&
+                server.health?.status !== 'unhealthy';
         },
 
         hasError(server) {
             const status = server.containerStatus?.toLowerCase();
-            return status?.includes('error') || status?.includes('failed') || status === 'dead';
+            return status?.includes('error') || status?.includes('failed') || status === 'dead' ||
+                server.health?.status === 'unhealthy';
+        },
+
+        // Result of the manager's health check, when the server defines one
+        getHealthCheckBadge(server) {
+            const health = server.health;
+            if (!health) return null;
+            const title = health.error || (health.lastChecked ? `Last checked ${health.lastChecked}` : 'Not checked yet');
+            switch (health.status) {
+                case 'healthy':
+                    return { label: `${health.type} check: healthy`, class: 'bg-green-900 text-green-200 border-green-700', title };
+                case 'unhealthy':
+                    return { label: `${health.type} check: unhealthy`, class: 'bg-red-900 text-red-200 border-red-700', title };
+                default:
+                    return { label: `${health.type} check: ${health.status || 'unknown'}`, class: 'bg-yellow-900 text-yellow-200 border-yellow-700', title };
+            }
         },
 
         getServerHealthScore(server) {
@@ -622,7 +641,15 @@ const ServerManager = {
                                 ]">
                                     {{ getConnectionStatus(server) }}
                                 </span>
+                                <span
+                                    v-if="getHealthCheckBadge(server)"
+                                    :class="['inline-flex items-center px-2.5 py-0.5 rounded-full text-xs font-medium border', getHealthCheckBadge(server).class]"
+                                    :title="getHealthCheckBadge(server).title"
+                                >
+                                    {{ getHealthCheckBadge(server).label }}
+                                </span>
                             </div>
+                            <p v-if="server.health?.error" class="text-xs text-red-300 mb-4">{{ server.health.error }}</p>
 
                             <!-- Capabilities -->
                             <div v-if="server.configCapabilities?.length" class="mb-4">
@@ -239,7 +239,7 @@ const TaskScheduler = {
             this.error = null;
             try {
                 const response = await this.taskSchedulerRequest('/api/tasks', 'GET');
-                this.tasks = Array.isArray(response) ? response : [];
+                this.tasks = Array.isArray(response?.tasks) ? response.tasks : [];
                 await Promise.all([
                     this.loadTaskRuns(),
                     this.loadMetrics()
@@ -253,8 +253,8 @@ const TaskScheduler = {
         },
         async loadTaskRuns() {
             try {
-                const response = await this.taskSchedulerRequest('/api/runs/status', 'GET');
-                this.taskRuns = Array.isArray(response) ? response : [];
+                const response = await this.taskSchedulerRequest('/api/tasks/runs', 'GET');
+                this.taskRuns = Array.isArray(response?.runs) ? response.runs : [];
             } catch (err) {
                 console.warn('Failed to load task runs:', err);
                 this.taskRuns = [];
@@ -262,7 +262,7 @@ const TaskScheduler = {
         },
         async loadMetrics() {
             try {
-                const response = await this.taskSchedulerRequest('/api/metrics', 'GET');
+                const response = await this.taskSchedulerRequest('/api/tasks/metrics', 'GET');
                 this.metrics = response || {};
             } catch (err) {
                 console.warn('Failed to load metrics:', err);
@@ -285,7 +285,8 @@ const TaskScheduler = {
             }
             const response = await fetch(dashboardUrl, options);
             if (!response.ok) {
-                throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+                const body = await response.json().catch(() => ({}));
+                throw new Error(body.error || `HTTP ${response.status}: ${response.statusText}`);
             }
             return response.json();
         },
@@ -353,11 +354,12 @@ const TaskScheduler = {
                     default:
                         throw new Error('Unknown task type');
                 }
-                await this.taskSchedulerRequest(endpoint, 'POST', requestData);
+                const created = await this.taskSchedulerRequest(endpoint, 'POST', requestData);
                 this.showCreateTask = false;
                 this.resetNewTask();
                 await this.loadTasks();
-                this.showToast('Task created successfully', 'success');
+                const nextRun = created?.next_runs?.[0];
+                this.showToast(nextRun ? `Task created, next run ${new Date(nextRun).toLocaleString()}` : 'Task created successfully', 'success');
             } catch (err) {
                 this.showToast(`Failed to create task: ${err.message}`, 'error');
             }
@@ -398,10 +400,9 @@ const TaskScheduler = {
         },
         async viewTaskOutput(taskId, runId = null) {
             try {
-                const endpoint = runId
-                    ? `/api/tasks/${taskId}/runs/${runId}/output`
-                    : `/api/tasks/${taskId}/output`;
-                const output = await this.taskSchedulerRequest(endpoint, 'GET');
+                const response = await this.taskSchedulerRequest(`/api/tasks/${taskId}/runs`, 'GET');
+                const runs = response?.runs || [];
+                const output = runId ? runs.find(run => run.id === runId) : runs;
                 const outputKey = runId ? `${taskId}-${runId}` : taskId;
                 this.showRunOutput[outputKey] = {
                     taskId,
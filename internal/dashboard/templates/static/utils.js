@@ -1,3 +1,25 @@
+// Requests that change something carry the dashboard's CSRF token, which the server checks
+// against its cookie to tell the dashboard's own pages from other sites
+window.csrfToken = function() {
+    const match = document.cookie.match(/(?:^|;\s*)mcp_dashboard_csrf=([^;]*)/);
+    return match ? decodeURIComponent(match[1]) : '';
+};
+
+(function() {
+    const originalFetch = window.fetch.bind(window);
+    window.fetch = function(input, init = {}) {
+        const request = input instanceof Request ? input : null;
+        const method = (init.method || (request ? request.method : 'GET')).toUpperCase();
+        const url = new URL(request ? request.url : input, window.location.href);
+        if (!['GET', 'HEAD', 'OPTIONS'].includes(method) && url.origin === window.location.origin) {
+            const headers = new Headers(init.headers || (request ? request.headers : undefined));
+            headers.set('X-CSRF-Token', window.csrfToken());
+            init = { ...init, headers };
+        }
+        return originalFetch(input, init);
+    };
+})();
+
 // Global toast notification system
 window.showToast = function(message, type = 'info', duration = 5000) {
     const container = document.getElementById('toast-container');
@@ -135,14 +157,27 @@ window.debounce = function(func, wait, immediate) {
     };
 };
 
-// Theme utilities
-window.setTheme = function(theme) {
-    document.documentElement.className = theme;
-    localStorage.setItem('theme', theme);
+// Theme utilities. The dashboard remembers the picked theme in a cookie and serves its
+// palette at /theme.css, so switching reloads that stylesheet.
+window.setTheme = async function(theme) {
+    const response = await fetch('/api/theme', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ theme })
+    });
+    if (!response.ok) {
+        const data = await response.json().catch(() => ({}));
+        throw new Error(data.error || `HTTP ${response.status}`);
+    }
+    document.documentElement.dataset.theme = theme;
+    const stylesheet = document.getElementById('theme-stylesheet');
+    if (stylesheet) {
+        stylesheet.href = `/theme.css?theme=${encodeURIComponent(theme)}`;
+    }
 };
 
 window.getTheme = function() {
-    return localStorage.getItem('theme') || 'light';
+    return document.documentElement.dataset.theme || 'dark';
 };
 
 // Copy to clipboard utility
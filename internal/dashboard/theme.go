@@ -0,0 +1,197 @@
+// internal/dashboard/theme.go
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+const themeAPIPath = "/api/theme"
+
+// themeVariable is one color of a theme's palette, as the r, g, b style.css expects
+type themeVariable struct {
+	name  string
+	value string
+}
+
+// dashboardThemes are the palettes /theme.css serves. style.css falls back to the dark one.
+var dashboardThemes = map[string][]themeVariable{
+	config.DashboardThemeDark: {
+		{"bg", "17, 24, 39"},
+		{"surface", "31, 41, 55"},
+		{"raised", "55, 65, 81"},
+		{"control", "75, 85, 99"},
+		{"border", "75, 85, 99"},
+		{"border-subtle", "55, 65, 81"},
+		{"text", "243, 244, 246"},
+		{"text-secondary", "209, 213, 219"},
+		{"text-muted", "156, 163, 175"},
+		{"accent", "59, 130, 246"},
+	},
+	config.DashboardThemeLight: {
+		{"bg", "243, 244, 246"},
+		{"surface", "255, 255, 255"},
+		{"raised", "249, 250, 251"},
+		{"control", "229, 231, 235"},
+		{"border", "209, 213, 219"},
+		{"border-subtle", "229, 231, 235"},
+		{"text", "17, 24, 39"},
+		{"text-secondary", "55, 65, 81"},
+		{"text-muted", "107, 114, 128"},
+		{"accent", "37, 99, 235"},
+	},
+}
+
+// themeNames lists the themes in the order the dashboard offers them
+var themeNames = []string{config.DashboardThemeDark, config.DashboardThemeLight}
+
+// defaultTheme is the theme of users who have not picked one
+func (d *DashboardServer) defaultTheme() string {
+	if _, ok := dashboardThemes[d.config.Dashboard.Theme]; ok {
+
+		return d.config.Dashboard.Theme
+	}
+
+	return config.DashboardThemeDark
+}
+
+// requestTheme is the theme the user picked, remembered in a cookie, or else the default
+func (d *DashboardServer) requestTheme(r *http.Request) string {
+	if cookie, err := r.Cookie(constants.DashboardThemeCookie); err == nil {
+		if _, ok := dashboardThemes[cookie.Value]; ok {
+
+			return cookie.Value
+		}
+	}
+
+	return d.defaultTheme()
+}
+
+// branding returns the configured branding, or none
+func (d *DashboardServer) branding() config.DashboardBranding {
+	if d.config.Dashboard.Branding == nil {
+
+		return config.DashboardBranding{}
+	}
+
+	return *d.config.Dashboard.Branding
+}
+
+// accentRGB turns a #rrggbb color into the r, g, b style.css expects
+func accentRGB(color string) (string, bool) {
+	if len(color) != 7 || color[0] != '#' {
+
+		return "", false
+	}
+	value, err := strconv.ParseUint(color[1:], 16, 32)
+	if err != nil {
+
+		return "", false
+	}
+
+	return fmt.Sprintf("%d, %d, %d", value>>16, (value>>8)&0xff, value&0xff), true
+}
+
+// handleThemeCSS serves /theme.css: the palette of the requesting user's theme as CSS
+// variables, with the configured accent color
+func (d *DashboardServer) handleThemeCSS(w http.ResponseWriter, r *http.Request) {
+	theme := d.requestTheme(r)
+	var css strings.Builder
+	fmt.Fprintf(&css, "/* %s theme */\n:root {\n", theme)
+	for _, variable := range dashboardThemes[theme] {
+		value := variable.value
+		if variable.name == "accent" {
+			if accent, ok := accentRGB(d.branding().AccentColor); ok {
+				value = accent
+			}
+		}
+		fmt.Fprintf(&css, "    --mcp-%s: %s;\n", variable.name, value)
+	}
+	css.WriteString("}\n")
+
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Vary", "Cookie")
+	_, _ = w.Write([]byte(css.String()))
+}
+
+// handleTheme serves /api/theme: GET reports the user's theme and the themes to pick from,
+// POST {"theme": "light"} picks one and remembers it in a cookie
+func (d *DashboardServer) handleTheme(w http.ResponseWriter, r *http.Request) {
+	theme := d.requestTheme(r)
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var request struct {
+			Theme string `json:"theme"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeAuthError(w, http.StatusBadRequest, "Invalid JSON body")
+
+			return
+		}
+		if _, ok := dashboardThemes[request.Theme]; !ok {
+			writeAuthError(w, http.StatusBadRequest, fmt.Sprintf("Unknown theme '%s', must be one of %s", request.Theme, strings.Join(themeNames, ", ")))
+
+			return
+		}
+		theme = request.Theme
+		http.SetCookie(w, &http.Cookie{
+			Name:     constants.DashboardThemeCookie,
+			Value:    theme,
+			Path:     "/",
+			MaxAge:   constants.DashboardThemeCookieMaxAge,
+			HttpOnly: true,
+			Secure:   isSecureRequest(r),
+			SameSite: http.SameSiteLaxMode,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"theme":   theme,
+		"default": d.defaultTheme(),
+		"themes":  themeNames,
+	})
+}
+
+// handleBranding serves the configured logo and favicon at /branding/logo and
+// /branding/favicon
+func (d *DashboardServer) handleBranding(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	branding := d.branding()
+	var file string
+	switch strings.TrimPrefix(r.URL.Path, "/branding/") {
+	case "logo":
+		file = branding.Logo
+	case "favicon":
+		file = branding.Favicon
+	}
+	if file == "" {
+		http.NotFound(w, r)
+
+		return
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(d.configDir, file)
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", constants.DashboardBrandingCacheAge))
+	http.ServeFile(w, r, file)
+}
@@ -17,7 +17,10 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+	"github.com/phildougherty/mcp-compose/internal/logfilter"
 )
 
 // Message types for different WebSocket streams
@@ -393,7 +396,7 @@ func (d *DashboardServer) handleLogWebSocket(w http.ResponseWriter, r *http.Requ
 		return nil
 	})
 
-	containerName := "mcp-compose-" + serverName
+	containerName := config.ContainerName(serverName)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -446,8 +449,9 @@ func (d *DashboardServer) handleLogWebSocket(w http.ResponseWriter, r *http.Requ
 		done <- cmd.Wait()
 	}()
 
-	go d.streamLogs(safeConn, stdout, serverName, "stdout", cancel)
-	go d.streamLogs(safeConn, stderr, serverName, "stderr", cancel)
+	filter := d.logFilterFor(containerName)
+	go d.streamLogs(safeConn, stdout, serverName, "stdout", filter, cancel)
+	go d.streamLogs(safeConn, stderr, serverName, "stderr", filter, cancel)
 
 	pingTicker := time.NewTicker(constants.WebSocketPingInterval)
 	defer pingTicker.Stop()
@@ -539,6 +543,10 @@ func (d *DashboardServer) streamLogsViaProxyEndpoint(safeConn *SafeWebSocketConn
 						Level:     d.parseLogLevel(content),
 						Message:   content,
 					}
+					// Keep the level of lines the proxy's log filters downgraded
+					if level, _ := logData["level"].(string); level == "debug" {
+						msg.Level = "DEBUG"
+					}
 
 					if err := safeConn.SetWriteDeadline(time.Now().Add(constants.WebSocketWriteDeadline)); err != nil {
 						d.logger.Debug("Failed to set write deadline: %v", err)
@@ -562,11 +570,11 @@ func (d *DashboardServer) streamLogsViaProxyEndpoint(safeConn *SafeWebSocketConn
 	return true
 }
 
-func (d *DashboardServer) streamLogs(safeConn *SafeWebSocketConn, reader io.Reader, serverName, source string, cancel context.CancelFunc) {
+func (d *DashboardServer) streamLogs(safeConn *SafeWebSocketConn, reader io.Reader, serverName, source string, filter *logfilter.Filter, cancel context.CancelFunc) {
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
+		line, debug, keep := filter.Apply(scanner.Text())
+		if line == "" || !keep {
 			continue
 		}
 
@@ -576,6 +584,9 @@ func (d *DashboardServer) streamLogs(safeConn *SafeWebSocketConn, reader io.Read
 			Level:     d.parseLogLevel(line),
 			Message:   line,
 		}
+		if debug {
+			msg.Level = "DEBUG"
+		}
 
 		if err := safeConn.SetWriteDeadline(time.Now().Add(constants.WebSocketWriteDeadline)); err != nil {
 			d.logger.Debug("Failed to set write deadline for log message to WebSocket for %s: %v", serverName, err)
@@ -724,7 +735,39 @@ func (d *DashboardServer) parseLogLevel(message string) string {
 	return "INFO"
 }
 
-// Public API for activity broadcasting
+// followProxyEvents subscribes to the proxy's event bus and feeds its events into the
+// activity stream, resuming after the last event seen whenever the connection drops
+func (d *DashboardServer) followProxyEvents() {
+	streamURL := strings.TrimRight(d.proxyURL, "/") + "/api/events?history=false"
+	lastEventID := ""
+	for {
+		var err error
+		lastEventID, err = events.Follow(context.Background(), &http.Client{Transport: d.proxyTransport}, streamURL, d.apiKey, lastEventID, func(event events.Event) {
+			activity := ActivityMessage{
+				ID:        event.ID,
+				Timestamp: event.Time.Format(time.RFC3339Nano),
+				Level:     event.Level,
+				Type:      event.Type,
+				Server:    event.Server,
+				Client:    event.Client,
+				Message:   event.Message,
+				Details:   event.Details,
+			}
+			select {
+			case activityBroadcaster.broadcast <- activity:
+			default:
+				log.Printf("[ACTIVITY] Channel full, dropping event: %s", event.Message)
+			}
+		})
+		if err != nil {
+			d.logger.Debug("Proxy event stream unavailable: %v", err)
+		}
+		time.Sleep(constants.EventStreamRetryDelay)
+	}
+}
+
+// BroadcastActivity publishes activity from services that run outside the proxy, such as
+// the task scheduler; the proxy's own events reach the dashboard through its event bus
 func BroadcastActivity(level, activityType, server, client, message string, details map[string]interface{}) {
 	activity := ActivityMessage{
 		ID:        generateID(),
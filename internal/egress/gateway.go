@@ -0,0 +1,255 @@
+// internal/egress/gateway.go
+package egress
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+// hopHeaders are removed from requests forwarded over plain HTTP
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// Gateway is the forward proxy that servers with restricted egress use to reach other hosts.
+// It runs in the proxy, which shares each such server's internal network, tells servers apart
+// by their container addresses and applies the server's security.egress rules.
+type Gateway struct {
+	cfg       *config.ComposeConfig
+	runtime   container.Runtime
+	logger    *logging.Logger
+	transport *http.Transport
+
+	mu         sync.Mutex
+	clients    map[string]string // client IP -> server name
+	lastLookup time.Time
+	lookup     func() map[string]string
+}
+
+// NewGateway creates the egress gateway for the servers in cfg
+func NewGateway(cfg *config.ComposeConfig, runtime container.Runtime, logger *logging.Logger) *Gateway {
+	g := &Gateway{
+		cfg:       cfg,
+		runtime:   runtime,
+		logger:    logger,
+		transport: &http.Transport{Proxy: nil, DialContext: (&net.Dialer{Timeout: constants.EgressGatewayDialTimeout}).DialContext},
+		clients:   make(map[string]string),
+	}
+	g.lookup = g.containerAddresses
+
+	return g
+}
+
+// Enabled reports whether any server restricts its egress
+func Enabled(cfg *config.ComposeConfig) bool {
+	for _, server := range cfg.Servers {
+		if server.Security.Egress != nil {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListenAndServe serves the gateway on its port until it fails
+func (g *Gateway) ListenAndServe() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", constants.EgressGatewayPort))
+	if err != nil {
+
+		return err
+	}
+
+	return g.Serve(listener)
+}
+
+// Serve is ListenAndServe on a listener the caller has already opened
+func (g *Gateway) Serve(listener net.Listener) error {
+	server := &http.Server{
+		Handler:           g,
+		ReadHeaderTimeout: constants.EgressGatewayDialTimeout,
+	}
+
+	return server.Serve(listener)
+}
+
+// ServeHTTP tunnels CONNECT requests and forwards plain HTTP requests that the client's
+// egress policy allows
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+	serverName := g.serverFor(clientIP)
+	if serverName == "" {
+		g.logger.Warning("Egress gateway refused %s: not a server with restricted egress", clientIP)
+		http.Error(w, "egress gateway: unknown client", http.StatusForbidden)
+
+		return
+	}
+
+	target := r.Host
+	defaultPort := "80"
+	if r.Method != http.MethodConnect {
+		if r.URL.Host == "" {
+			http.Error(w, "egress gateway: expected a proxy request", http.StatusBadRequest)
+
+			return
+		}
+		target = r.URL.Host
+		if r.URL.Scheme == "https" {
+			defaultPort = "443"
+		}
+	}
+	host, portText, err := net.SplitHostPort(target)
+	if err != nil {
+		host, portText = target, defaultPort
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		http.Error(w, "egress gateway: invalid port", http.StatusBadRequest)
+
+		return
+	}
+
+	if !g.cfg.Servers[serverName].Security.Egress.Allows(host, port) {
+		g.logger.Warning("Egress denied: server '%s' to %s:%d", serverName, host, port)
+		http.Error(w, fmt.Sprintf("egress to %s:%d is not allowed for server '%s'", host, port, serverName), http.StatusForbidden)
+
+		return
+	}
+	g.logger.Debug("Egress allowed: server '%s' to %s:%d", serverName, host, port)
+
+	if r.Method == http.MethodConnect {
+		g.tunnel(w, net.JoinHostPort(host, strconv.Itoa(port)))
+
+		return
+	}
+	g.forward(w, r)
+}
+
+// tunnel connects the client to address for the rest of the connection
+func (g *Gateway) tunnel(w http.ResponseWriter, address string) {
+	upstream, err := net.DialTimeout("tcp", address, constants.EgressGatewayDialTimeout)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("egress gateway: failed to connect to %s", address), http.StatusBadGateway)
+
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		_ = upstream.Close()
+		http.Error(w, "egress gateway: tunneling not supported", http.StatusInternalServerError)
+
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		_ = upstream.Close()
+
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		_ = client.Close()
+		_ = upstream.Close()
+
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		// Bytes the client sent after the CONNECT request are already buffered
+		_, _ = io.Copy(upstream, buffered)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(client, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+	_ = client.Close()
+	_ = upstream.Close()
+}
+
+// forward sends a plain HTTP request on and copies back the response
+func (g *Gateway) forward(w http.ResponseWriter, r *http.Request) {
+	outbound := r.Clone(r.Context())
+	outbound.RequestURI = ""
+	for _, header := range hopHeaders {
+		outbound.Header.Del(header)
+	}
+
+	resp, err := g.transport.RoundTrip(outbound)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("egress gateway: %v", err), http.StatusBadGateway)
+
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	for _, header := range hopHeaders {
+		w.Header().Del(header)
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// serverFor returns the server a client address belongs to. Addresses are looked up again
+// when an unknown client connects, at most once per lookup delay.
+func (g *Gateway) serverFor(clientIP string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if name, ok := g.clients[clientIP]; ok {
+
+		return name
+	}
+	if time.Since(g.lastLookup) < constants.EgressGatewayLookupDelay {
+
+		return ""
+	}
+	g.lastLookup = time.Now()
+	g.clients = g.lookup()
+
+	return g.clients[clientIP]
+}
+
+// containerAddresses maps the addresses of each server with restricted egress to the server.
+// A server reaches the gateway over its own network or a peer's, so all of them count.
+func (g *Gateway) containerAddresses() map[string]string {
+	clients := make(map[string]string)
+	for name, server := range g.cfg.Servers {
+		if server.Security.Egress == nil {
+
+			continue
+		}
+		info, err := g.runtime.GetContainerInfo(config.ContainerName(name))
+		if err != nil {
+
+			continue
+		}
+		for _, endpoint := range info.Networks {
+			if endpoint.IPv4Address != "" {
+				clients[endpoint.IPv4Address] = name
+			}
+		}
+	}
+
+	return clients
+}
@@ -0,0 +1,106 @@
+package egress
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func newTestGateway(t *testing.T, egress *config.EgressConfig, clients map[string]string) *url.URL {
+	t.Helper()
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"web": {Image: "web", Security: config.SecurityConfig{Egress: egress}},
+	}}
+	gateway := NewGateway(cfg, container.NewNullRuntime(), logging.NewLogger("error"))
+	gateway.lookup = func() map[string]string { return clients }
+
+	server := httptest.NewServer(gateway)
+	t.Cleanup(server.Close)
+	proxyURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse gateway URL: %v", err)
+	}
+
+	return proxyURL
+}
+
+func TestGatewayAppliesEgressPolicy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+	upstreamTLS := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer upstreamTLS.Close()
+
+	tests := []struct {
+		name       string
+		egress     *config.EgressConfig
+		clients    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "allowed",
+			egress:     &config.EgressConfig{Allow: []string{"127.0.0.0/8"}},
+			clients:    map[string]string{"127.0.0.1": "web"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "denied by default",
+			egress:     &config.EgressConfig{Allow: []string{"api.github.com:443"}},
+			clients:    map[string]string{"127.0.0.1": "web"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "deny wins over allow",
+			egress:     &config.EgressConfig{Allow: []string{"127.0.0.0/8"}, Deny: []string{"127.0.0.1"}},
+			clients:    map[string]string{"127.0.0.1": "web"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "unknown client",
+			egress:     &config.EgressConfig{Default: config.EgressAllow},
+			clients:    map[string]string{},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxyURL := newTestGateway(t, tt.egress, tt.clients)
+			client := &http.Client{Transport: &http.Transport{
+				Proxy:           http.ProxyURL(proxyURL),
+				TLSClientConfig: upstreamTLS.Client().Transport.(*http.Transport).TLSClientConfig,
+			}}
+
+			// Plain HTTP is forwarded
+			resp, err := client.Get(upstream.URL)
+			if err != nil {
+				t.Fatalf("HTTP request through gateway failed: %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("HTTP status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			// HTTPS is tunneled with CONNECT; a refused CONNECT fails the request
+			resp, err = client.Get(upstreamTLS.URL)
+			if tt.wantStatus == http.StatusOK {
+				if err != nil {
+					t.Fatalf("HTTPS request through gateway failed: %v", err)
+				}
+				_ = resp.Body.Close()
+			} else if err == nil {
+				_ = resp.Body.Close()
+				t.Error("Expected the HTTPS tunnel to be refused")
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// internal/events/bus.go
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// Event types published by the proxy
+const (
+	ServerStarted   = "server.started"
+	ServerStopped   = "server.stopped"
+	ServerUnhealthy = "server.unhealthy"
+	ServerCrashLoop = "server.crash_loop"
+	ConfigReloaded  = "config.reloaded"
+	ToolCalled      = "tool.called"
+	ToolFailed      = "tool.failed"
+	AuthDenied      = "auth.denied"
+	AuthLockout     = "auth.lockout"
+	Request         = "request"
+	RequestFailed   = "request.failed"
+
+	SamplingRequested = "sampling.requested"
+	SamplingReviewed  = "sampling.reviewed"
+	SamplingCompleted = "sampling.completed"
+	SamplingFailed    = "sampling.failed"
+
+	QuotaExceeded = "quota.exceeded"
+
+	InitJobCompleted = "init.completed"
+	InitJobFailed    = "init.failed"
+)
+
+// Event levels, matching the dashboard's activity levels
+const (
+	LevelInfo  = "INFO"
+	LevelWarn  = "WARN"
+	LevelError = "ERROR"
+)
+
+// Event is something that happened in the proxy or to one of its servers
+type Event struct {
+	ID      string                 `json:"id"`
+	Time    time.Time              `json:"time"`
+	Type    string                 `json:"type"`
+	Level   string                 `json:"level"`
+	Server  string                 `json:"server,omitempty"`
+	Client  string                 `json:"client,omitempty"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Filter selects events by type and server. A type matches itself and, without a dot,
+// every type in its category: "server" matches "server.started" and "server.stopped".
+type Filter struct {
+	Types   []string
+	Servers []string
+}
+
+// Match reports whether the filter selects the event
+func (f Filter) Match(event Event) bool {
+	if len(f.Servers) > 0 && !contains(f.Servers, event.Server) {
+
+		return false
+	}
+	if len(f.Types) == 0 {
+
+		return true
+	}
+	for _, eventType := range f.Types {
+		if event.Type == eventType || strings.HasPrefix(event.Type, eventType+".") {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+type subscriber struct {
+	filter Filter
+	ch     chan Event
+}
+
+// Bus fans events out to subscribers and keeps the most recent ones so late subscribers
+// can catch up. Event IDs are "<epoch>-<seq>"; the epoch changes when the process
+// restarts, so IDs from a previous run replay the whole history.
+type Bus struct {
+	epoch       string
+	mu          sync.Mutex
+	history     []Event
+	nextSeq     uint64
+	subscribers map[*subscriber]struct{}
+}
+
+// NewBus creates an empty bus
+func NewBus() *Bus {
+
+	return &Bus{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		nextSeq:     1,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish stamps the event with an ID, time and level and hands it to the subscribers
+// whose filter matches. A subscriber that cannot keep up is disconnected; it can resume
+// from its last event ID.
+func (b *Bus) Publish(event Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event.ID = fmt.Sprintf("%s-%d", b.epoch, b.nextSeq)
+	b.nextSeq++
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Level == "" {
+		event.Level = LevelInfo
+	}
+
+	if len(b.history) >= constants.EventHistorySize {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:len(b.history)-1]
+	}
+	b.history = append(b.history, event)
+
+	for sub := range b.subscribers {
+		if !sub.filter.Match(event) {
+
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+
+	return event
+}
+
+// Subscribe returns the recorded events after lastEventID that match the filter, and a
+// channel of new ones that is closed by the returned cancel function. An empty
+// lastEventID replays the whole history.
+func (b *Bus) Subscribe(filter Filter, lastEventID string) ([]Event, <-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	after := uint64(0)
+	if epoch, seq, found := strings.Cut(lastEventID, "-"); found && epoch == b.epoch {
+		after, _ = strconv.ParseUint(seq, 10, 64)
+	}
+	var backlog []Event
+	for _, event := range b.history {
+		if b.seq(event) > after && filter.Match(event) {
+			backlog = append(backlog, event)
+		}
+	}
+
+	sub := &subscriber{filter: filter, ch: make(chan Event, constants.EventSubscriberBuffer)}
+	b.subscribers[sub] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, exists := b.subscribers[sub]; exists {
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+
+	return backlog, sub.ch, cancel
+}
+
+func (b *Bus) seq(event Event) uint64 {
+	_, seq, _ := strings.Cut(event.ID, "-")
+	n, _ := strconv.ParseUint(seq, 10, 64)
+
+	return n
+}
+
+// Default is the process-wide bus the proxy publishes to
+var Default = NewBus()
+
+// Publish publishes an event on the default bus
+func Publish(event Event) Event {
+
+	return Default.Publish(event)
+}
@@ -0,0 +1,67 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	event := Event{Type: ServerStarted, Server: "filesystem"}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		match  bool
+	}{
+		{"empty filter", Filter{}, true},
+		{"exact type", Filter{Types: []string{ServerStarted}}, true},
+		{"category", Filter{Types: []string{"server"}}, true},
+		{"category prefix is not a category", Filter{Types: []string{"serv"}}, false},
+		{"other type", Filter{Types: []string{ToolFailed}}, false},
+		{"server", Filter{Servers: []string{"filesystem"}}, true},
+		{"other server", Filter{Servers: []string{"memory"}}, false},
+		{"type and server", Filter{Types: []string{"server"}, Servers: []string{"memory"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(event); got != tt.match {
+				t.Errorf("Match() = %v, want %v", got, tt.match)
+			}
+		})
+	}
+}
+
+func TestBusSubscribe(t *testing.T) {
+	bus := NewBus()
+	first := bus.Publish(Event{Type: ServerStarted, Server: "a"})
+	bus.Publish(Event{Type: ToolFailed, Server: "a"})
+	bus.Publish(Event{Type: ServerStopped, Server: "b"})
+
+	if first.Level != LevelInfo || first.Time.IsZero() || first.ID == "" {
+		t.Fatalf("Publish did not stamp the event: %+v", first)
+	}
+
+	backlog, _, cancel := bus.Subscribe(Filter{}, "")
+	cancel()
+	if len(backlog) != 3 {
+		t.Errorf("Expected the whole history, got %d events", len(backlog))
+	}
+
+	backlog, _, cancel = bus.Subscribe(Filter{}, first.ID)
+	cancel()
+	if len(backlog) != 2 {
+		t.Errorf("Expected 2 events after %s, got %d", first.ID, len(backlog))
+	}
+
+	backlog, stream, cancel := bus.Subscribe(Filter{Types: []string{"server"}}, "")
+	defer cancel()
+	if len(backlog) != 2 {
+		t.Errorf("Expected 2 server events in the history, got %d", len(backlog))
+	}
+
+	bus.Publish(Event{Type: ToolFailed})
+	bus.Publish(Event{Type: ServerUnhealthy, Server: "a"})
+	if event := <-stream; event.Type != ServerUnhealthy {
+		t.Errorf("Expected the filtered stream to deliver %s, got %s", ServerUnhealthy, event.Type)
+	}
+}
@@ -0,0 +1,69 @@
+// internal/events/stream.go
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Follow reads the event stream served at streamURL (a proxy's /api/events) and calls
+// handle for every event until the stream ends or ctx is cancelled. Passing the ID of the
+// last event seen resumes after it. Follow returns the ID of the last event handled.
+func Follow(ctx context.Context, client *http.Client, streamURL, apiKey, lastEventID string, handle func(Event)) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+
+		return lastEventID, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+
+		return lastEventID, fmt.Errorf("failed to connect to event stream: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+
+		return lastEventID, fmt.Errorf("event stream returned status %d", resp.StatusCode)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		case line == "" && len(data) > 0:
+			var event Event
+			if jsonErr := json.Unmarshal([]byte(strings.Join(data, "\n")), &event); jsonErr == nil {
+				handle(event)
+				lastEventID = event.ID
+			}
+			data = nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+
+				return lastEventID, nil
+			}
+
+			return lastEventID, fmt.Errorf("event stream interrupted: %w", err)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+// internal/imagescan/imagescan.go
+package imagescan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+// Vulnerability is one known vulnerability of a package in an image
+type Vulnerability struct {
+	ID               string `json:"id"`
+	Package          string `json:"package"`
+	InstalledVersion string `json:"installedVersion"`
+	FixedVersion     string `json:"fixedVersion,omitempty"` // empty when no fix is published
+	Severity         string `json:"severity"`               // one of config.ScanSeverities
+	Title            string `json:"title,omitempty"`
+}
+
+// Result is the scan of one image
+type Result struct {
+	Image           string          `json:"image"`
+	Digest          string          `json:"digest"` // the image ID the result is cached by
+	Scanner         string          `json:"scanner"`
+	ScannedAt       time.Time       `json:"scannedAt"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Counts returns the number of vulnerabilities of each severity, leaving out those without
+// a fix when ignoreUnfixed is set
+func (r *Result) Counts(ignoreUnfixed bool) map[string]int {
+	counts := make(map[string]int)
+	for _, vulnerability := range r.Vulnerabilities {
+		if ignoreUnfixed && vulnerability.FixedVersion == "" {
+
+			continue
+		}
+		counts[vulnerability.Severity]++
+	}
+
+	return counts
+}
+
+// AtLeast returns the number of vulnerabilities of severity or worse; "none" matches none
+func (r *Result) AtLeast(severity string, ignoreUnfixed bool) int {
+	if severity == "none" {
+
+		return 0
+	}
+	total := 0
+	for found, count := range r.Counts(ignoreUnfixed) {
+		if config.SeverityRank(found) >= config.SeverityRank(severity) {
+			total += count
+		}
+	}
+
+	return total
+}
+
+// Summary describes the counts from the most severe down, such as "2 critical, 5 high", or
+// "no known vulnerabilities"
+func (r *Result) Summary(ignoreUnfixed bool) string {
+	counts := r.Counts(ignoreUnfixed)
+	var parts []string
+	for i := len(config.ScanSeverities) - 1; i >= 0; i-- {
+		if count := counts[config.ScanSeverities[i]]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, config.ScanSeverities[i]))
+		}
+	}
+	if len(parts) == 0 {
+
+		return "no known vulnerabilities"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// CacheFile is where the image scans of the servers of a compose file are kept
+func CacheFile(configFile string) string {
+
+	return filepath.Join(filepath.Dir(configFile), constants.ImageScanCacheFile)
+}
+
+// cacheMu serializes updates of cache files by the servers started in parallel
+var cacheMu sync.Mutex
+
+// Lookup returns the cached scan of an image digest, however old
+func Lookup(cacheFile, digest string) (*Result, bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	result, found := readCache(cacheFile)[digest]
+
+	return result, found
+}
+
+func readCache(cacheFile string) map[string]*Result {
+	results := make(map[string]*Result)
+	if data, err := os.ReadFile(cacheFile); err == nil {
+		_ = json.Unmarshal(data, &results)
+	}
+
+	return results
+}
+
+func store(cacheFile string, result *Result) error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	results := readCache(cacheFile)
+	results[result.Digest] = result
+	// Results of images scanned long ago are dropped
+	for digest, cached := range results {
+		if time.Since(cached.ScannedAt) > constants.ImageScanCacheKeep {
+			delete(results, digest)
+		}
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+
+		return fmt.Errorf("failed to marshal scan results: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), constants.DefaultDirMode); err != nil {
+
+		return fmt.Errorf("failed to create scan cache directory: %w", err)
+	}
+	tmp := cacheFile + ".tmp"
+	if err := os.WriteFile(tmp, data, constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write scan results: %w", err)
+	}
+
+	return os.Rename(tmp, cacheFile)
+}
+
+// Scanner checks the images of servers for known vulnerabilities before they start
+type Scanner struct {
+	Runtime   container.Runtime
+	CacheFile string    // where results are cached by image digest; empty caches none
+	Out       io.Writer // scan summaries and warnings; nil for none
+
+	// run runs a scanner command and returns its standard output; nil runs it on the host
+	run func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// Hook returns the check a container runtime runs on a server's image once it is present,
+// or nil when the server does not scan its image
+func (s Scanner) Hook(serverName string, scan *config.ImageScanConfig) func(image string) error {
+	if scan == nil || !scan.Enabled {
+
+		return nil
+	}
+
+	return func(image string) error {
+
+		return s.Check(serverName, image, scan)
+	}
+}
+
+// Check scans a server's image, or takes a recent cached scan of the same digest, and fails
+// when it has vulnerabilities as severe as fail_on. Those as severe as warn_on are reported.
+func (s Scanner) Check(serverName, image string, scan *config.ImageScanConfig) error {
+	failOn, warnOn := scan.Thresholds()
+	result, err := s.Scan(image, scan.Scanner)
+	if err != nil {
+		if failOn == "none" {
+			s.printf("Warning: could not scan image '%s' of server '%s': %v\n", image, serverName, err)
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to scan image '%s' of server '%s': %w", image, serverName, err)
+	}
+
+	summary := result.Summary(scan.IgnoreUnfixed)
+	if count := result.AtLeast(failOn, scan.IgnoreUnfixed); count > 0 {
+
+		return fmt.Errorf("image '%s' of server '%s' has %d vulnerabilities of %s severity or worse (%s); raise security.scan.fail_on to start it anyway", image, serverName, count, failOn, summary)
+	}
+	if result.AtLeast(warnOn, scan.IgnoreUnfixed) > 0 {
+		s.printf("Warning: image '%s' of server '%s' has known vulnerabilities: %s\n", image, serverName, summary)
+	} else {
+		s.printf("Scanned image '%s' of server '%s' with %s: %s\n", image, serverName, result.Scanner, summary)
+	}
+
+	return nil
+}
+
+// Scan returns the scan of an image that is present, from the cache when the same digest was
+// scanned by the same scanner recently. An empty scanner picks the first one installed.
+func (s Scanner) Scan(image, scanner string) (*Result, error) {
+	info, err := s.Runtime.GetImageInfo(image)
+	if err != nil {
+
+		return nil, err
+	}
+	if s.CacheFile != "" {
+		if cached, found := Lookup(s.CacheFile, info.ID); found && (scanner == "" || cached.Scanner == scanner) &&
+			time.Since(cached.ScannedAt) < constants.ImageScanCacheMaxAge {
+
+			return cached, nil
+		}
+	}
+	if scanner == "" {
+		if scanner = installedScanner(); scanner == "" {
+
+			return nil, fmt.Errorf("no vulnerability scanner found; install one of %s", strings.Join(config.ImageScanners, ", "))
+		}
+	}
+
+	s.printf("Scanning image '%s' with %s...\n", image, scanner)
+	ctx, cancel := context.WithTimeout(context.Background(), constants.ImageScanTimeout)
+	defer cancel()
+	run := s.run
+	if run == nil {
+		run = runCommand
+	}
+	var vulnerabilities []Vulnerability
+	switch scanner {
+	case "trivy":
+		output, err := run(ctx, "trivy", "image", "--quiet", "--format", "json", image)
+		if err != nil {
+
+			return nil, err
+		}
+		vulnerabilities, err = parseTrivy(output)
+		if err != nil {
+
+			return nil, err
+		}
+	case "grype":
+		output, err := run(ctx, "grype", "--quiet", "--output", "json", image)
+		if err != nil {
+
+			return nil, err
+		}
+		vulnerabilities, err = parseGrype(output)
+		if err != nil {
+
+			return nil, err
+		}
+	default:
+
+		return nil, fmt.Errorf("unknown scanner '%s'", scanner)
+	}
+
+	result := &Result{Image: image, Digest: info.ID, Scanner: scanner, ScannedAt: time.Now(), Vulnerabilities: vulnerabilities}
+	if s.CacheFile != "" {
+		if err := store(s.CacheFile, result); err != nil {
+			s.printf("Warning: %v\n", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (s Scanner) printf(format string, args ...interface{}) {
+	if s.Out != nil {
+		_, _ = fmt.Fprintf(s.Out, format, args...)
+	}
+}
+
+// installedScanner returns the first scanner found on the PATH, or ""
+func installedScanner() string {
+	for _, scanner := range config.ImageScanners {
+		if _, err := exec.LookPath(scanner); err == nil {
+
+			return scanner
+		}
+	}
+
+	return ""
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stderr strings.Builder
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+
+		return nil, fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
+}
+
+// normalizeSeverity maps a scanner's severity onto config.ScanSeverities
+func normalizeSeverity(severity string) string {
+	severity = strings.ToLower(severity)
+	if severity == "negligible" {
+
+		return "low"
+	}
+	if config.SeverityRank(severity) == 0 {
+
+		return "unknown"
+	}
+
+	return severity
+}
+
+// parseTrivy reads the vulnerabilities of trivy's JSON report, once each when several of
+// the image's targets report the same one
+func parseTrivy(output []byte) ([]Vulnerability, error) {
+	var report struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID  string
+				PkgName          string
+				InstalledVersion string
+				FixedVersion     string
+				Severity         string
+				Title            string
+			}
+		}
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+
+		return nil, fmt.Errorf("failed to parse trivy report: %w", err)
+	}
+	var vulnerabilities []Vulnerability
+	for _, result := range report.Results {
+		for _, found := range result.Vulnerabilities {
+			vulnerabilities = append(vulnerabilities, Vulnerability{
+				ID:               found.VulnerabilityID,
+				Package:          found.PkgName,
+				InstalledVersion: found.InstalledVersion,
+				FixedVersion:     found.FixedVersion,
+				Severity:         normalizeSeverity(found.Severity),
+				Title:            found.Title,
+			})
+		}
+	}
+
+	return deduplicate(vulnerabilities), nil
+}
+
+// parseGrype reads the vulnerabilities of grype's JSON report
+func parseGrype(output []byte) ([]Vulnerability, error) {
+	var report struct {
+		Matches []struct {
+			Vulnerability struct {
+				ID          string `json:"id"`
+				Severity    string `json:"severity"`
+				Description string `json:"description"`
+				Fix         struct {
+					Versions []string `json:"versions"`
+				} `json:"fix"`
+			} `json:"vulnerability"`
+			Artifact struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"artifact"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+
+		return nil, fmt.Errorf("failed to parse grype report: %w", err)
+	}
+	var vulnerabilities []Vulnerability
+	for _, match := range report.Matches {
+		vulnerabilities = append(vulnerabilities, Vulnerability{
+			ID:               match.Vulnerability.ID,
+			Package:          match.Artifact.Name,
+			InstalledVersion: match.Artifact.Version,
+			FixedVersion:     strings.Join(match.Vulnerability.Fix.Versions, ", "),
+			Severity:         normalizeSeverity(match.Vulnerability.Severity),
+			Title:            match.Vulnerability.Description,
+		})
+	}
+
+	return deduplicate(vulnerabilities), nil
+}
+
+// deduplicate drops repeats of a vulnerability in the same package version and sorts the
+// rest from the most severe down
+func deduplicate(vulnerabilities []Vulnerability) []Vulnerability {
+	seen := make(map[string]bool)
+	unique := make([]Vulnerability, 0, len(vulnerabilities))
+	for _, vulnerability := range vulnerabilities {
+		key := vulnerability.ID + "|" + vulnerability.Package + "|" + vulnerability.InstalledVersion
+		if seen[key] {
+
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, vulnerability)
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+
+		return config.SeverityRank(unique[i].Severity) > config.SeverityRank(unique[j].Severity)
+	})
+
+	return unique
+}
@@ -0,0 +1,116 @@
+package imagescan
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+const trivyReport = `{"Results": [
+	{"Target": "alpine", "Vulnerabilities": [
+		{"VulnerabilityID": "CVE-2026-0001", "PkgName": "openssl", "InstalledVersion": "3.1.0", "FixedVersion": "3.1.5", "Severity": "HIGH"},
+		{"VulnerabilityID": "CVE-2026-0002", "PkgName": "busybox", "InstalledVersion": "1.36", "Severity": "CRITICAL"}
+	]},
+	{"Target": "usr/lib/node_modules", "Vulnerabilities": [
+		{"VulnerabilityID": "CVE-2026-0001", "PkgName": "openssl", "InstalledVersion": "3.1.0", "FixedVersion": "3.1.5", "Severity": "HIGH"},
+		{"VulnerabilityID": "CVE-2026-0003", "PkgName": "lodash", "InstalledVersion": "4.17.0", "Severity": "bogus"}
+	]}
+]}`
+
+const grypeReport = `{"matches": [
+	{"vulnerability": {"id": "GHSA-1234", "severity": "Negligible", "fix": {"versions": []}}, "artifact": {"name": "zlib", "version": "1.2"}},
+	{"vulnerability": {"id": "CVE-2026-0004", "severity": "Medium", "fix": {"versions": ["2.0", "2.1"]}}, "artifact": {"name": "curl", "version": "1.9"}}
+]}`
+
+func TestParseReports(t *testing.T) {
+	vulnerabilities, err := parseTrivy([]byte(trivyReport))
+	if err != nil {
+		t.Fatalf("Failed to parse the trivy report: %v", err)
+	}
+	if len(vulnerabilities) != 3 {
+		t.Fatalf("Expected the repeated vulnerability once, got %+v", vulnerabilities)
+	}
+	if vulnerabilities[0].ID != "CVE-2026-0002" || vulnerabilities[0].Severity != "critical" {
+		t.Errorf("Expected the critical vulnerability first, got %+v", vulnerabilities[0])
+	}
+	if vulnerabilities[2].Severity != "unknown" {
+		t.Errorf("Expected an unrecognized severity to be unknown, got %+v", vulnerabilities[2])
+	}
+
+	vulnerabilities, err = parseGrype([]byte(grypeReport))
+	if err != nil {
+		t.Fatalf("Failed to parse the grype report: %v", err)
+	}
+	if len(vulnerabilities) != 2 || vulnerabilities[0].FixedVersion != "2.0, 2.1" || vulnerabilities[1].Severity != "low" {
+		t.Errorf("Unexpected grype vulnerabilities %+v", vulnerabilities)
+	}
+
+	if _, err := parseTrivy([]byte("not json")); err == nil {
+		t.Error("Expected an error for a report that is not JSON")
+	}
+}
+
+type imageRuntime struct {
+	*container.NullRuntime
+	id string
+}
+
+func (r imageRuntime) GetImageInfo(_ string) (*container.ImageInfo, error) {
+
+	return &container.ImageInfo{ID: r.id}, nil
+}
+
+func TestCheckThresholds(t *testing.T) {
+	runs := 0
+	scanner := Scanner{
+		Runtime:   imageRuntime{id: "sha256:abc"},
+		CacheFile: filepath.Join(t.TempDir(), "image-scans.json"),
+		run: func(_ context.Context, name string, args ...string) ([]byte, error) {
+			runs++
+			if name != "trivy" || args[len(args)-1] != "example/server:1.0" {
+				t.Errorf("Unexpected scanner command %s %v", name, args)
+			}
+
+			return []byte(trivyReport), nil
+		},
+	}
+
+	err := scanner.Check("server", "example/server:1.0", &config.ImageScanConfig{Enabled: true, Scanner: "trivy"})
+	if err == nil || !strings.Contains(err.Error(), "1 vulnerabilities of critical severity or worse") {
+		t.Errorf("Expected the critical vulnerability to fail the check, got %v", err)
+	}
+	if err := scanner.Check("server", "example/server:1.0", &config.ImageScanConfig{Enabled: true, Scanner: "trivy", FailOn: "none"}); err != nil {
+		t.Errorf("Expected fail_on none to only warn, got %v", err)
+	}
+	// Only the vulnerability with a fix counts, and it is high
+	if err := scanner.Check("server", "example/server:1.0", &config.ImageScanConfig{Enabled: true, Scanner: "trivy", IgnoreUnfixed: true}); err != nil {
+		t.Errorf("Expected unfixed vulnerabilities to be ignored, got %v", err)
+	}
+	if runs != 1 {
+		t.Errorf("Expected the digest to be scanned once and then cached, ran %d times", runs)
+	}
+
+	result, found := Lookup(scanner.CacheFile, "sha256:abc")
+	if !found || result.Scanner != "trivy" || result.Summary(false) != "1 critical, 1 high, 1 unknown" {
+		t.Errorf("Expected the scan to be cached by digest, got %+v", result)
+	}
+
+	// An old scan is repeated
+	result.ScannedAt = time.Now().Add(-48 * time.Hour)
+	if err := store(scanner.CacheFile, result); err != nil {
+		t.Fatalf("Failed to store the scan: %v", err)
+	}
+	_, _ = scanner.Scan("example/server:1.0", "trivy")
+	if runs != 2 {
+		t.Errorf("Expected a stale scan to be repeated, ran %d times", runs)
+	}
+
+	if hook := scanner.Hook("server", &config.ImageScanConfig{}); hook != nil {
+		t.Error("Expected no hook when scanning is disabled")
+	}
+}
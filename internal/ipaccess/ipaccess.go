@@ -0,0 +1,123 @@
+// internal/ipaccess/ipaccess.go
+package ipaccess
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// List lets clients in or keeps them out by address. A nil list lets everyone in.
+type List struct {
+	allow   []*net.IPNet
+	deny    []*net.IPNet
+	proxies []*net.IPNet
+}
+
+// New builds the list of an access config section, or returns nil when there is none. A
+// section with only trusted proxies lets everyone in but still finds the client behind them.
+func New(cfg *config.IPAccessConfig) (*List, error) {
+	if cfg == nil || (len(cfg.Allow) == 0 && len(cfg.Deny) == 0 && len(cfg.TrustedProxies) == 0) {
+
+		return nil, nil
+	}
+	list := &List{}
+	for _, entries := range []struct {
+		networks *[]*net.IPNet
+		entries  []string
+	}{{&list.allow, cfg.Allow}, {&list.deny, cfg.Deny}, {&list.proxies, cfg.TrustedProxies}} {
+		for _, entry := range entries.entries {
+			network, err := ParseNetwork(entry)
+			if err != nil {
+
+				return nil, err
+			}
+			*entries.networks = append(*entries.networks, network)
+		}
+	}
+
+	return list, nil
+}
+
+// ParseNetwork parses a CIDR range, or an IP address as the range of just that address
+func ParseNetwork(entry string) (*net.IPNet, error) {
+	if !strings.Contains(entry, "/") {
+		ip := net.ParseIP(entry)
+		if ip == nil {
+
+			return nil, fmt.Errorf("invalid address '%s'", entry)
+		}
+		bits := net.IPv6len * 8
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), net.IPv4len*8
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(entry)
+	if err != nil {
+
+		return nil, fmt.Errorf("invalid address range '%s': %w", entry, err)
+	}
+
+	return network, nil
+}
+
+func contains(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns the address of the client behind a request: the peer, unless the peer is
+// a trusted proxy, then the last address of X-Forwarded-For not added by a trusted proxy
+func (l *List) ClientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if l == nil || ip == nil || !contains(l.proxies, ip) {
+
+		return ip
+	}
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if hop == nil {
+
+			break
+		}
+		ip = hop
+		if !contains(l.proxies, hop) {
+
+			break
+		}
+	}
+
+	return ip
+}
+
+// Allows reports whether the client behind r may connect: it is not denied and, with an
+// allow list, it is on it
+func (l *List) Allows(r *http.Request) bool {
+	if l == nil {
+
+		return true
+	}
+	ip := l.ClientIP(r)
+	if ip == nil || contains(l.deny, ip) {
+
+		return false
+	}
+
+	return len(l.allow) == 0 || contains(l.allow, ip)
+}
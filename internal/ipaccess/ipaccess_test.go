@@ -0,0 +1,82 @@
+package ipaccess
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func request(remoteAddr, forwardedFor string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+
+	return req
+}
+
+func TestAllows(t *testing.T) {
+	list, err := New(&config.IPAccessConfig{
+		Allow: []string{"192.168.1.0/24", "10.0.0.5", "fd00::/8"},
+		Deny:  []string{"192.168.1.66"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build list: %v", err)
+	}
+
+	for remoteAddr, allowed := range map[string]bool{
+		"192.168.1.20:5000":  true,
+		"192.168.1.66:5000":  false, // deny wins
+		"10.0.0.5:5000":      true,
+		"10.0.0.6:5000":      false, // not on the allow list
+		"[fd12::1]:5000":     true,
+		"[2001:db8::1]:5000": false,
+		"garbage":            false,
+	} {
+		if got := list.Allows(request(remoteAddr, "")); got != allowed {
+			t.Errorf("Expected %s allowed to be %v", remoteAddr, allowed)
+		}
+	}
+
+	var none *List
+	if !none.Allows(request("203.0.113.9:5000", "")) {
+		t.Error("Expected a nil list to let everyone in")
+	}
+	if list, _ := New(&config.IPAccessConfig{}); list != nil {
+		t.Error("Expected no list without entries")
+	}
+	if list, _ := New(&config.IPAccessConfig{TrustedProxies: []string{"10.0.0.1"}}); !list.Allows(request("203.0.113.9:5000", "")) {
+		t.Error("Expected a list of only trusted proxies to let everyone in")
+	}
+	if _, err := New(&config.IPAccessConfig{Deny: []string{"not-an-ip"}}); err == nil {
+		t.Error("Expected an invalid entry to be refused")
+	}
+}
+
+func TestClientIPBehindTrustedProxies(t *testing.T) {
+	list, err := New(&config.IPAccessConfig{
+		Deny:           []string{"203.0.113.0/24"},
+		TrustedProxies: []string{"10.0.0.1", "10.0.1.0/24"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build list: %v", err)
+	}
+
+	// The client is the last address the trusted proxies didn't add
+	if got := list.ClientIP(request("10.0.0.1:443", "198.51.100.1, 203.0.113.7, 10.0.1.9")); got.String() != "203.0.113.7" {
+		t.Errorf("Expected the client behind the proxies, got %s", got)
+	}
+	if list.Allows(request("10.0.0.1:443", "203.0.113.7")) {
+		t.Error("Expected the denied client behind a trusted proxy to be refused")
+	}
+	// Other peers can't claim an address with X-Forwarded-For
+	if got := list.ClientIP(request("198.51.100.1:443", "192.0.2.1")); got.String() != "198.51.100.1" {
+		t.Errorf("Expected an untrusted peer's own address, got %s", got)
+	}
+	if !list.Allows(request("198.51.100.1:443", "203.0.113.7")) {
+		t.Error("Expected an untrusted peer to be judged by its own address")
+	}
+}
@@ -0,0 +1,176 @@
+// internal/logfilter/filter.go
+package logfilter
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// leadingTimestamp matches a timestamp at the start of a line, such as the one docker logs -t
+// adds or one the server writes itself, so repeated lines compare equal when rate limiting
+var leadingTimestamp = regexp.MustCompile(`^\[?\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?\]?\s*`)
+
+type rule struct {
+	action  string
+	pattern *regexp.Regexp
+	window  time.Duration
+	burst   int
+}
+
+type lineCount struct {
+	windowStart time.Time
+	count       int
+	suppressed  int
+}
+
+// Filter applies a server's log_filters rules to its log lines. Rate limiting is counted
+// per Filter, so each log view gets its own. A nil Filter keeps every line unchanged.
+type Filter struct {
+	rules []rule
+	mu    sync.Mutex
+	seen  map[string]*lineCount
+}
+
+// New compiles a server's rules; it returns nil when there are none
+func New(rules []config.LogFilterRule) (*Filter, error) {
+	if len(rules) == 0 {
+
+		return nil, nil
+	}
+
+	f := &Filter{seen: make(map[string]*lineCount)}
+	for i, r := range rules {
+		compiled := rule{
+			action: r.Action,
+			window: constants.LogFilterDefaultWindow,
+			burst:  constants.LogFilterDefaultBurst,
+		}
+		if r.Pattern != "" {
+			pattern, err := regexp.Compile(r.Pattern)
+			if err != nil {
+
+				return nil, fmt.Errorf("failed to compile log filter %d pattern: %w", i, err)
+			}
+			compiled.pattern = pattern
+		}
+		if r.Window != "" {
+			window, err := time.ParseDuration(r.Window)
+			if err != nil {
+
+				return nil, fmt.Errorf("failed to parse log filter %d window: %w", i, err)
+			}
+			compiled.window = window
+		}
+		if r.Burst > 0 {
+			compiled.burst = r.Burst
+		}
+		f.rules = append(f.rules, compiled)
+	}
+
+	return f, nil
+}
+
+// ForServer builds the filter for a server in the project, or nil when it has no rules
+func ForServer(cfg *config.ComposeConfig, serverName string) (*Filter, error) {
+	if cfg == nil {
+
+		return nil, nil
+	}
+	serverCfg, exists := cfg.Servers[serverName]
+	if !exists {
+
+		return nil, nil
+	}
+
+	return New(serverCfg.LogFilters)
+}
+
+// Apply runs the rules over one line. It returns the line to keep, which carries a note when
+// identical lines were suppressed before it, whether it was downgraded to debug, and whether
+// it should be kept at all.
+func (f *Filter) Apply(line string) (string, bool, bool) {
+	if f == nil {
+
+		return line, false, true
+	}
+
+	debug := false
+	for _, r := range f.rules {
+		if r.pattern != nil && !r.pattern.MatchString(line) {
+
+			continue
+		}
+		switch r.action {
+		case "drop":
+
+			return "", false, false
+		case "downgrade":
+			debug = true
+		case "rate_limit":
+			suppressed, keep := f.allow(line, r)
+			if !keep {
+
+				return "", false, false
+			}
+			if suppressed > 0 {
+				line = fmt.Sprintf("%s [%d identical lines suppressed]", line, suppressed)
+			}
+		}
+	}
+
+	return line, debug, true
+}
+
+// allow counts a line against its rate limit window. When a new window starts it reports how
+// many identical lines the previous window suppressed.
+func (f *Filter) allow(line string, r rule) (int, bool) {
+	key := normalize(line)
+	now := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, exists := f.seen[key]
+	if !exists {
+		if len(f.seen) >= constants.LogFilterMaxTrackedLines {
+			f.seen = make(map[string]*lineCount)
+		}
+		entry = &lineCount{windowStart: now}
+		f.seen[key] = entry
+	}
+
+	suppressed := 0
+	if now.Sub(entry.windowStart) >= r.window {
+		suppressed = entry.suppressed
+		entry.windowStart = now
+		entry.count = 0
+		entry.suppressed = 0
+	}
+
+	entry.count++
+	if entry.count > r.burst {
+		entry.suppressed++
+
+		return 0, false
+	}
+
+	return suppressed, true
+}
+
+func normalize(line string) string {
+	for i := 0; i < 2; i++ {
+		stripped := leadingTimestamp.ReplaceAllString(line, "")
+		if stripped == line {
+
+			break
+		}
+		line = stripped
+	}
+
+	return line
+}
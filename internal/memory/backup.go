@@ -0,0 +1,303 @@
+// internal/memory/backup.go
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/objectstore"
+)
+
+// Dump is the portable JSON form of the memory database: the rows of every table, such as
+// entities and relations, as JSON objects keyed by column
+type Dump struct {
+	Version    int                        `json:"version"`
+	ExportedAt time.Time                  `json:"exported_at"`
+	Database   string                     `json:"database"`
+	Tables     map[string]json.RawMessage `json:"tables"`
+}
+
+// resequence moves every serial sequence past the highest imported id
+const resequence = `DO $$
+DECLARE r record;
+BEGIN
+  FOR r IN
+    SELECT table_name, column_name, pg_get_serial_sequence(quote_ident(table_name), column_name) AS seq
+    FROM information_schema.columns
+    WHERE table_schema = 'public' AND pg_get_serial_sequence(quote_ident(table_name), column_name) IS NOT NULL
+  LOOP
+    EXECUTE format('SELECT setval(%L, coalesce((SELECT max(%I) FROM %I), 0) + 1, false)', r.seq, r.column_name, r.table_name);
+  END LOOP;
+END $$;
+`
+
+// DetectDumpFormat tells a JSON dump from a SQL one by its content
+func DetectDumpFormat(data []byte) string {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+
+		return config.MemoryDumpJSON
+	}
+
+	return config.MemoryDumpSQL
+}
+
+// Export dumps the memory database as JSON or as SQL from pg_dump
+func (m *Manager) Export(format string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.MemoryBackupTimeout)
+	defer cancel()
+
+	user, database := m.database()
+	switch format {
+	case config.MemoryDumpSQL:
+		// --clean makes the dump replace the tables it contains when it is imported
+
+		return m.exec(ctx, nil, "pg_dump", "-U", user, "-d", database, "--no-owner", "--no-privileges", "--clean", "--if-exists")
+	case config.MemoryDumpJSON, "":
+	default:
+
+		return nil, fmt.Errorf("unsupported dump format '%s', must be '%s' or '%s'", format, config.MemoryDumpJSON, config.MemoryDumpSQL)
+	}
+
+	out, err := m.query(ctx, "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_type = 'BASE TABLE' ORDER BY table_name")
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to list memory tables: %w", err)
+	}
+	dump := Dump{
+		Version:    constants.MemoryDumpVersion,
+		ExportedAt: time.Now().UTC(),
+		Database:   database,
+		Tables:     make(map[string]json.RawMessage),
+	}
+	for _, table := range strings.Fields(string(out)) {
+		rows, err := m.query(ctx, fmt.Sprintf("SELECT coalesce(json_agg(t), '[]'::json) FROM public.%s t", pq.QuoteIdentifier(table)))
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to export table '%s': %w", table, err)
+		}
+		dump.Tables[table] = json.RawMessage(bytes.TrimSpace(rows))
+	}
+
+	return json.MarshalIndent(dump, "", "  ")
+}
+
+// Import loads a dump into the memory database. A JSON dump is merged, skipping rows that
+// already exist, unless replace empties its tables first; a SQL dump always replaces them.
+func (m *Manager) Import(data []byte, format string, replace bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.MemoryBackupTimeout)
+	defer cancel()
+
+	if format == "" {
+		format = DetectDumpFormat(data)
+	}
+	var script []byte
+	switch format {
+	case config.MemoryDumpSQL:
+		script = data
+	case config.MemoryDumpJSON:
+		var err error
+		if script, err = importScript(data, replace); err != nil {
+
+			return err
+		}
+	default:
+
+		return fmt.Errorf("unsupported dump format '%s', must be '%s' or '%s'", format, config.MemoryDumpJSON, config.MemoryDumpSQL)
+	}
+
+	user, database := m.database()
+	if _, err := m.exec(ctx, script, "psql", "-U", user, "-d", database, "-q", "-v", "ON_ERROR_STOP=1", "--single-transaction"); err != nil {
+
+		return fmt.Errorf("failed to import memory dump: %w", err)
+	}
+
+	return nil
+}
+
+// importScript turns a JSON dump into SQL that inserts its rows. Foreign keys are not
+// checked while loading, so tables can come in any order.
+func importScript(data []byte, replace bool) ([]byte, error) {
+	var dump Dump
+	if err := json.Unmarshal(data, &dump); err != nil {
+
+		return nil, fmt.Errorf("failed to parse memory dump: %w", err)
+	}
+	if dump.Version > constants.MemoryDumpVersion {
+
+		return nil, fmt.Errorf("memory dump version %d is newer than this mcp-compose supports (%d)", dump.Version, constants.MemoryDumpVersion)
+	}
+	tables := make([]string, 0, len(dump.Tables))
+	for table := range dump.Tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var script strings.Builder
+	script.WriteString("SET session_replication_role = replica;\n")
+	if replace && len(tables) > 0 {
+		quoted := make([]string, len(tables))
+		for i, table := range tables {
+			quoted[i] = "public." + pq.QuoteIdentifier(table)
+		}
+		fmt.Fprintf(&script, "TRUNCATE %s CASCADE;\n", strings.Join(quoted, ", "))
+	}
+	for _, table := range tables {
+		name := "public." + pq.QuoteIdentifier(table)
+		fmt.Fprintf(&script, "INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, %s) ON CONFLICT DO NOTHING;\n",
+			name, name, pq.QuoteLiteral(string(dump.Tables[table])))
+	}
+	script.WriteString(resequence)
+	script.WriteString("SET session_replication_role = DEFAULT;\n")
+
+	return []byte(script.String()), nil
+}
+
+// Backup writes a dump as configured under memory.backup and returns where it went
+func (m *Manager) Backup() (string, error) {
+	backup := m.cfg.Memory.Backup
+	if backup == nil {
+
+		return "", fmt.Errorf("memory.backup is not configured")
+	}
+	format := backup.Format
+	if format == "" {
+		format = config.MemoryDumpJSON
+	}
+	data, err := m.Export(format)
+	if err != nil {
+
+		return "", err
+	}
+	fileName := fmt.Sprintf("memory-%s.%s", time.Now().Format("20060102-150405"), format)
+
+	if backup.S3 {
+		store, err := objectstore.New(m.cfg.ObjectStorage)
+		if err != nil {
+
+			return "", fmt.Errorf("failed to set up object storage: %w", err)
+		}
+		key := store.Key(constants.MemoryBackupDir, fileName)
+		ctx, cancel := context.WithTimeout(context.Background(), constants.MemoryBackupTimeout)
+		defer cancel()
+		if err := store.Put(ctx, key, data, "application/"+format, map[string]string{"format": format}); err != nil {
+
+			return "", fmt.Errorf("failed to upload memory backup: %w", err)
+		}
+
+		return key, nil
+	}
+
+	dir := backup.Directory
+	if dir == "" {
+		dir = constants.MemoryBackupDir
+	}
+	if err := os.MkdirAll(dir, constants.DefaultDirMode); err != nil {
+
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	path := filepath.Join(dir, fileName)
+	if err := os.WriteFile(path, data, constants.SecretFileMode); err != nil {
+
+		return "", fmt.Errorf("failed to write memory backup: %w", err)
+	}
+
+	if backup.Keep > 0 {
+		backups, _ := filepath.Glob(filepath.Join(dir, "memory-*.*"))
+		sort.Strings(backups)
+		for len(backups) > backup.Keep {
+			_ = os.Remove(backups[0])
+			backups = backups[1:]
+		}
+	}
+
+	return path, nil
+}
+
+// RunBackups takes a backup every memory.backup.interval until ctx is done
+func (m *Manager) RunBackups(ctx context.Context) {
+	interval, err := time.ParseDuration(m.cfg.Memory.Backup.Interval)
+	if err != nil || interval <= 0 {
+		fmt.Printf("Warning: Memory backups disabled: invalid interval '%s'\n", m.cfg.Memory.Backup.Interval)
+
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+
+			return
+		case <-ticker.C:
+			location, err := m.Backup()
+			if err != nil {
+				fmt.Printf("Warning: Memory backup failed: %v\n", err)
+
+				continue
+			}
+			fmt.Printf("Memory backup written to %s\n", location)
+		}
+	}
+}
+
+// query runs a statement with psql and returns its unaligned, tuples-only output
+func (m *Manager) query(ctx context.Context, statement string) ([]byte, error) {
+	user, database := m.database()
+
+	return m.exec(ctx, nil, "psql", "-U", user, "-d", database, "-At", "-v", "ON_ERROR_STOP=1", "-c", statement)
+}
+
+// exec runs a command inside the postgres-memory container, feeding it input when given
+func (m *Manager) exec(ctx context.Context, input []byte, command ...string) ([]byte, error) {
+	runtimeName := m.runtime.GetRuntimeName()
+	if runtimeName == "none" {
+
+		return nil, fmt.Errorf("memory export and import need a container runtime (docker or podman)")
+	}
+	args := []string{"exec"}
+	if input != nil {
+		args = append(args, "-i")
+	}
+	args = append(args, config.ContainerName("postgres-memory"))
+	args = append(args, command...)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, runtimeName, args...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+
+		return nil, fmt.Errorf("%s failed: %w: %s", command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// database returns the postgres user and database of the memory server
+func (m *Manager) database() (string, string) {
+	user := "postgres"
+	if m.cfg.Memory.PostgresUser != "" {
+		user = m.cfg.Memory.PostgresUser
+	}
+	database := "memory_graph"
+	if m.cfg.Memory.PostgresDB != "" {
+		database = m.cfg.Memory.PostgresDB
+	}
+
+	return user, database
+}
@@ -42,7 +42,7 @@ func (m *Manager) Start() error {
 	}
 
 	// Check if postgres-memory is running first
-	postgresStatus, err := m.runtime.GetContainerStatus("mcp-compose-postgres-memory")
+	postgresStatus, err := m.runtime.GetContainerStatus(config.ContainerName("postgres-memory"))
 	if err != nil || postgresStatus != "running" {
 		if err := m.startPostgres(pgPassword); err != nil {
 
@@ -57,12 +57,12 @@ func (m *Manager) Start() error {
 	}
 
 	// Stop existing container
-	_ = m.runtime.StopContainer("mcp-compose-memory")
+	_ = m.runtime.StopContainer(config.ContainerName("memory"))
 
 	// Ensure network exists
-	networkExists, _ := m.runtime.NetworkExists("mcp-net")
+	networkExists, _ := m.runtime.NetworkExists(config.DefaultNetwork())
 	if !networkExists {
-		if err := m.runtime.CreateNetwork("mcp-net"); err != nil {
+		if err := m.runtime.CreateNetwork(config.DefaultNetwork()); err != nil {
 
 			return fmt.Errorf("failed to create mcp-net network: %w", err)
 		}
@@ -70,7 +70,7 @@ func (m *Manager) Start() error {
 	}
 
 	// Get configuration values with defaults
-	dbURL := fmt.Sprintf("postgresql://postgres:%s@mcp-compose-postgres-memory:5432/memory_graph?sslmode=disable", pgPassword)
+	dbURL := fmt.Sprintf("postgresql://postgres:%s@%s:5432/memory_graph?sslmode=disable", pgPassword, config.ContainerName("postgres-memory"))
 	if m.cfg.Memory.DatabaseURL != "" {
 		dbURL = m.cfg.Memory.DatabaseURL
 		// Ensure sslmode=disable is included if not present
@@ -96,10 +96,10 @@ func (m *Manager) Start() error {
 
 	// Start memory server
 	opts := &container.ContainerOptions{
-		Name:     "mcp-compose-memory",
+		Name:     config.ContainerName("memory"),
 		Image:    "mcp-compose-memory:latest",
 		Ports:    []string{"3001:3001"},
-		Networks: []string{"mcp-net"},
+		Networks: []string{config.DefaultNetwork()},
 		Env: map[string]string{
 			"NODE_ENV":          "production",
 			"DATABASE_URL":      dbURL,
@@ -159,15 +159,15 @@ func (m *Manager) startPostgres(pgPassword string) error {
 	}
 
 	opts := &container.ContainerOptions{
-		Name:     "mcp-compose-postgres-memory",
+		Name:     config.ContainerName("postgres-memory"),
 		Image:    "postgres:15-alpine",
-		Networks: []string{"mcp-net"},
+		Networks: []string{config.DefaultNetwork()},
 		Env: map[string]string{
 			"POSTGRES_DB":       pgDB,
 			"POSTGRES_USER":     pgUser,
 			"POSTGRES_PASSWORD": pgPassword,
 		},
-		Volumes:     volumes,
+		Volumes:     m.cfg.NamespaceMounts(volumes),
 		User:        "postgres",
 		CPUs:        pgCpus,
 		Memory:      pgMemory,
@@ -221,11 +221,11 @@ func (m *Manager) buildMemoryImage() error {
 func (m *Manager) Stop() error {
 	fmt.Println("Stopping MCP memory server...")
 
-	if err := m.runtime.StopContainer("mcp-compose-memory"); err != nil {
+	if err := m.runtime.StopContainer(config.ContainerName("memory")); err != nil {
 		fmt.Printf("Warning: Failed to stop memory container: %v\n", err)
 	}
 
-	if err := m.runtime.StopContainer("mcp-compose-postgres-memory"); err != nil {
+	if err := m.runtime.StopContainer(config.ContainerName("postgres-memory")); err != nil {
 		fmt.Printf("Warning: Failed to stop postgres-memory container: %v\n", err)
 	}
 
@@ -246,5 +246,5 @@ func (m *Manager) Restart() error {
 
 func (m *Manager) Status() (string, error) {
 
-	return m.runtime.GetContainerStatus("mcp-compose-memory")
+	return m.runtime.GetContainerStatus(config.ContainerName("memory"))
 }
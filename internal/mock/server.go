@@ -0,0 +1,312 @@
+// internal/mock/server.go
+package mock
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/prompts"
+)
+
+const protocolVersion = "2024-11-05"
+
+// Server is an MCP server that answers with the tools and prompts declared for a server in
+// the compose file, using the tools' mocks instead of running the real backend
+type Server struct {
+	name    string
+	tools   []config.ToolConfig
+	prompts []config.PromptConfig
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      interface{} `json:"id"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *rpcError   `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewServer creates a mock of the named server
+func NewServer(name string, serverCfg config.ServerConfig) (*Server, error) {
+	if len(serverCfg.Tools) == 0 && len(serverCfg.Prompts) == 0 {
+
+		return nil, fmt.Errorf("server '%s' declares no tools or prompts to mock", name)
+	}
+
+	return &Server{name: name, tools: serverCfg.Tools, prompts: serverCfg.Prompts}, nil
+}
+
+// ServeStdio answers newline-delimited JSON-RPC messages until in is closed
+func (s *Server) ServeStdio(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, constants.MockMaxMessageSize), constants.MockMaxMessageSize)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+
+			continue
+		}
+		if resp := s.Handle([]byte(line)); resp != nil {
+			if err := encoder.Encode(resp); err != nil {
+
+				return fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// ServeHTTP answers JSON-RPC messages POSTed to any path
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, constants.MockMaxMessageSize))
+	if err != nil {
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+
+		return
+	}
+
+	resp := s.Handle(body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Handle answers one JSON-RPC message; notifications get no response
+func (s *Server) Handle(message []byte) *response {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+
+		return &response{JSONRPC: "2.0", Error: &rpcError{Code: -32700, Message: "Parse error"}}
+	}
+	if len(req.ID) == 0 {
+
+		return nil
+	}
+	var id interface{}
+	_ = json.Unmarshal(req.ID, &id)
+
+	result, rpcErr := s.dispatch(req.Method, req.Params)
+
+	return &response{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr}
+}
+
+func (s *Server) dispatch(method string, params json.RawMessage) (interface{}, *rpcError) {
+	switch method {
+	case "initialize":
+		capabilities := map[string]interface{}{}
+		if len(s.tools) > 0 {
+			capabilities["tools"] = map[string]interface{}{}
+		}
+		if len(s.prompts) > 0 {
+			capabilities["prompts"] = map[string]interface{}{}
+		}
+
+		return map[string]interface{}{
+			"protocolVersion": protocolVersion,
+			"capabilities":    capabilities,
+			"serverInfo":      map[string]interface{}{"name": s.name + " (mock)", "version": "mock"},
+		}, nil
+	case "ping":
+
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		tools := make([]map[string]interface{}, 0, len(s.tools))
+		for _, tool := range s.tools {
+			tools = append(tools, map[string]interface{}{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"inputSchema": inputSchema(tool.Parameters),
+			})
+		}
+
+		return map[string]interface{}{"tools": tools}, nil
+	case "tools/call":
+		var call struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &call); err != nil {
+
+			return nil, &rpcError{Code: -32602, Message: "Invalid params"}
+		}
+		for _, tool := range s.tools {
+			if tool.Name == call.Name {
+
+				return callResult(tool, call.Arguments), nil
+			}
+		}
+
+		return nil, &rpcError{Code: -32602, Message: fmt.Sprintf("Unknown tool: %s", call.Name)}
+	case "prompts/list":
+
+		return map[string]interface{}{"prompts": prompts.List(s.prompts)}, nil
+	case "prompts/get":
+		var get struct {
+			Name      string            `json:"name"`
+			Arguments map[string]string `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &get); err != nil {
+
+			return nil, &rpcError{Code: -32602, Message: "Invalid params"}
+		}
+		prompt, exists := prompts.Find(s.prompts, get.Name)
+		if !exists {
+
+			return nil, &rpcError{Code: -32602, Message: fmt.Sprintf("Unknown prompt: %s", get.Name)}
+		}
+		result, err := prompts.Render(prompt, get.Arguments)
+		if err != nil {
+
+			return nil, &rpcError{Code: -32602, Message: err.Error()}
+		}
+
+		return result, nil
+	case "resources/list":
+
+		return map[string]interface{}{"resources": []interface{}{}}, nil
+	default:
+
+		return nil, &rpcError{Code: -32601, Message: fmt.Sprintf("Method not found: %s", method)}
+	}
+}
+
+// inputSchema describes a tool's declared parameters as JSON Schema
+func inputSchema(parameters []config.ToolParameter) map[string]interface{} {
+	properties := make(map[string]interface{}, len(parameters))
+	required := make([]string, 0)
+	for _, parameter := range parameters {
+		property := map[string]interface{}{}
+		switch parameter.Type {
+		case "string", "number", "integer", "boolean", "object", "array":
+			property["type"] = parameter.Type
+		}
+		if parameter.Description != "" {
+			property["description"] = parameter.Description
+		}
+		if parameter.Default != nil {
+			property["default"] = normalize(parameter.Default)
+		}
+		properties[parameter.Name] = property
+		if parameter.Required {
+			required = append(required, parameter.Name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// callResult picks the mock whose input matches the arguments most specifically: every key
+// in a mock's input must equal the argument, and an empty input matches anything
+func callResult(tool config.ToolConfig, arguments map[string]interface{}) map[string]interface{} {
+	args := normalize(arguments)
+	best := -1
+	for i, mock := range tool.Mocks {
+		if len(mock.Input) > 0 && !matches(normalize(mock.Input), args) {
+
+			continue
+		}
+		if best < 0 || len(mock.Input) > len(tool.Mocks[best].Input) {
+			best = i
+		}
+	}
+	if best < 0 {
+		data, _ := json.Marshal(args)
+
+		return map[string]interface{}{
+			"content": []interface{}{map[string]interface{}{
+				"type": "text",
+				"text": fmt.Sprintf("No mock for tool '%s' matches arguments %s", tool.Name, data),
+			}},
+			"isError": true,
+		}
+	}
+
+	mock := tool.Mocks[best]
+	result, _ := normalize(mock.Response).(map[string]interface{})
+	if _, isResult := result["content"]; !isResult {
+		// A bare value is returned as text and as structured content
+		data, _ := json.Marshal(result)
+		result = map[string]interface{}{
+			"content":           []interface{}{map[string]interface{}{"type": "text", "text": string(data)}},
+			"structuredContent": result,
+		}
+	}
+	if strings.EqualFold(mock.Status, "error") {
+		result["isError"] = true
+	}
+
+	return result
+}
+
+func matches(expected, actual interface{}) bool {
+	expectedMap, ok := expected.(map[string]interface{})
+	if !ok {
+
+		return reflect.DeepEqual(expected, actual)
+	}
+	actualMap, ok := actual.(map[string]interface{})
+	if !ok {
+
+		return len(expectedMap) == 0
+	}
+	for key, value := range expectedMap {
+		if !matches(value, actualMap[key]) {
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalize converts YAML-decoded values to their JSON-decoded form so they compare equal
+// to request arguments
+func normalize(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+
+		return value
+	}
+
+	return normalized
+}
@@ -0,0 +1,59 @@
+package mock
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestMockToolCall(t *testing.T) {
+	server, err := NewServer("github", config.ServerConfig{
+		Tools: []config.ToolConfig{{
+			Name: "get_issue",
+			Mocks: []config.ToolMockResponse{
+				{Response: map[string]interface{}{"title": "any"}},
+				{Input: map[string]interface{}{"number": 42}, Response: map[string]interface{}{"title": "answer"}},
+				{Input: map[string]interface{}{"number": 404}, Response: map[string]interface{}{"title": "missing"}, Status: "error"},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		arguments string
+		title     string
+		isError   bool
+	}{
+		{"most specific mock wins", `{"repo": "a", "number": 42}`, "answer", false},
+		{"error status", `{"number": 404}`, "missing", true},
+		{"catch-all mock", `{"number": 7}`, "any", false},
+		{"no arguments", `null`, "any", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_issue","arguments":` + tt.arguments + `}}`
+			resp := server.Handle([]byte(message))
+			if resp == nil || resp.Error != nil {
+				t.Fatalf("Expected a result, got %+v", resp)
+			}
+			data, _ := json.Marshal(resp.Result)
+			var result struct {
+				StructuredContent struct {
+					Title string `json:"title"`
+				} `json:"structuredContent"`
+				IsError bool `json:"isError"`
+			}
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatalf("Failed to decode result: %v", err)
+			}
+			if result.StructuredContent.Title != tt.title || result.IsError != tt.isError {
+				t.Errorf("Expected title %q (isError %v), got %s", tt.title, tt.isError, data)
+			}
+		})
+	}
+}
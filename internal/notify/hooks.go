@@ -0,0 +1,234 @@
+// internal/notify/hooks.go
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// AuthFailure is the type of events about failed authentications at the proxy
+const AuthFailure = "auth_failure"
+
+// Event is a server event delivered to the notification hooks
+type Event struct {
+	Type    string    `json:"type"` // one of config.NotificationEvents
+	Server  string    `json:"server"`
+	Client  string    `json:"client,omitempty"` // the address behind an auth_failure
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Send delivers the event to every configured hook that subscribes to its type. Every hook
+// is tried; the returned error joins the failures.
+func Send(hooks config.NotificationsConfig, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	var errs []error
+
+	for _, hook := range hooks.Webhooks {
+		if subscribed(hook.Events, event.Type) {
+			if err := sendWebhook(hook, event); err != nil {
+				errs = append(errs, fmt.Errorf("webhook %s: %w", hook.URL, err))
+			}
+		}
+	}
+	for _, hook := range hooks.Slack {
+		if subscribed(hook.Events, event.Type) {
+			if err := sendSlack(hook, event); err != nil {
+				errs = append(errs, fmt.Errorf("slack: %w", err))
+			}
+		}
+	}
+	for _, hook := range hooks.Email {
+		if subscribed(hook.Events, event.Type) {
+			if err := sendEmail(hook, event); err != nil {
+				errs = append(errs, fmt.Errorf("email via %s: %w", hook.SMTPHost, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Subscribed reports whether any configured hook subscribes to the event type
+func Subscribed(hooks config.NotificationsConfig, eventType string) bool {
+	for _, hook := range hooks.Webhooks {
+		if subscribed(hook.Events, eventType) {
+
+			return true
+		}
+	}
+	for _, hook := range hooks.Slack {
+		if subscribed(hook.Events, eventType) {
+
+			return true
+		}
+	}
+	for _, hook := range hooks.Email {
+		if subscribed(hook.Events, eventType) {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// subscribed reports whether a hook's events take the type. Auth failures can be frequent,
+// so hooks that list no events get them only when they ask.
+func subscribed(events []string, eventType string) bool {
+	if len(events) == 0 {
+
+		return eventType != AuthFailure
+	}
+	for _, event := range events {
+		if event == eventType {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e Event) summary() string {
+	if e.Server == "" {
+
+		return "mcp-compose: " + strings.ReplaceAll(e.Type, "_", " ")
+	}
+
+	return fmt.Sprintf("mcp-compose: %s %s", e.Server, strings.ReplaceAll(e.Type, "_", " "))
+}
+
+func sendWebhook(hook config.WebhookNotification, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return postJSON(hook.URL, hook.Headers, body)
+}
+
+func sendSlack(hook config.SlackNotification, event Event) error {
+	message := map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", event.summary(), event.Message),
+	}
+	if hook.Channel != "" {
+		message["channel"] = hook.Channel
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	return postJSON(hook.WebhookURL, nil, body)
+}
+
+func postJSON(url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	client := &http.Client{Timeout: constants.NotificationTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, constants.HTTPErrorBufferSize))
+
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	return nil
+}
+
+// sendEmail sends the event as a plain-text mail, upgrading to TLS when the server offers
+// STARTTLS and authenticating when a username is set
+func sendEmail(hook config.EmailNotification, event Event) error {
+	port := hook.SMTPPort
+	if port == 0 {
+		port = constants.NotificationDefaultSMTP
+	}
+	address := net.JoinHostPort(hook.SMTPHost, strconv.Itoa(port))
+
+	conn, err := net.DialTimeout("tcp", address, constants.NotificationTimeout)
+	if err != nil {
+
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(constants.NotificationTimeout))
+	client, err := smtp.NewClient(conn, hook.SMTPHost)
+	if err != nil {
+		_ = conn.Close()
+
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: hook.SMTPHost}); err != nil {
+
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if hook.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", hook.Username, hook.Password, hook.SMTPHost)); err != nil {
+
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(hook.From); err != nil {
+
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	for _, to := range hook.To {
+		if err := client.Rcpt(to); err != nil {
+
+			return fmt.Errorf("recipient %s rejected: %w", to, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		hook.From, strings.Join(hook.To, ", "), event.summary(), event.Time.Format(time.RFC1123Z), event.Message)
+	if _, err := writer.Write([]byte(message)); err != nil {
+
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+
+		return fmt.Errorf("message rejected: %w", err)
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,39 @@
+// internal/notify/notify.go
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	goruntime "runtime"
+	"strconv"
+)
+
+// Desktop sends a best-effort desktop notification using the platform's native tooling.
+// macOS uses osascript, Linux uses notify-send. Other platforms return an error.
+func Desktop(title, message string) error {
+	var cmd *exec.Cmd
+
+	switch goruntime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			strconv.Quote(message), strconv.Quote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		path, err := exec.LookPath("notify-send")
+		if err != nil {
+
+			return fmt.Errorf("notify-send not found: %w", err)
+		}
+		cmd = exec.Command(path, "--app-name=mcp-compose", title, message)
+	default:
+
+		return fmt.Errorf("desktop notifications are not supported on %s", goruntime.GOOS)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+
+		return fmt.Errorf("failed to send notification: %w, output: %s", err, string(output))
+	}
+
+	return nil
+}
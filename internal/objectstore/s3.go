@@ -0,0 +1,271 @@
+// internal/objectstore/s3.go
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+const (
+	defaultRegion   = "us-east-1"
+	signingService  = "s3"
+	signingAlgo     = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	shortDateFormat = "20060102"
+)
+
+// ErrNotFound is returned by Get when the object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Client is a minimal S3-compatible client (AWS S3, MinIO) using path-style
+// addressing and Signature Version 4
+type Client struct {
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	prefix          string
+	accessKeyID     string
+	secretAccessKey string
+	httpClient      *http.Client
+}
+
+// New creates a client for the configured bucket
+func New(cfg *config.ObjectStorageConfig) (*Client, error) {
+	if cfg == nil || cfg.Bucket == "" {
+
+		return nil, fmt.Errorf("object_storage.bucket is not configured")
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = defaultRegion
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || parsed.Host == "" {
+
+		return nil, fmt.Errorf("invalid object_storage.endpoint '%s'", endpoint)
+	}
+
+	return &Client{
+		endpoint:        parsed,
+		region:          region,
+		bucket:          cfg.Bucket,
+		prefix:          strings.Trim(cfg.Prefix, "/"),
+		accessKeyID:     cfg.AccessKeyID,
+		secretAccessKey: cfg.SecretAccessKey,
+		httpClient:      &http.Client{Timeout: constants.HTTPRequestTimeout},
+	}, nil
+}
+
+// Key joins the configured prefix with the given path parts
+func (c *Client) Key(parts ...string) string {
+	if c.prefix != "" {
+		parts = append([]string{c.prefix}, parts...)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// Put uploads an object, attaching metadata as x-amz-meta-* headers
+func (c *Client) Put(ctx context.Context, key string, body []byte, contentType string, metadata map[string]string) error {
+	headers := make(http.Header)
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	for name, value := range metadata {
+		headers.Set("X-Amz-Meta-"+name, value)
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, key, body, headers)
+	if err != nil {
+
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+
+		return responseError("put", key, resp)
+	}
+
+	return nil
+}
+
+// Get downloads an object
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+
+		return nil, responseError("get", key, resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+
+	return data, nil
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (c *Client) Delete(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+
+		return responseError("delete", key, resp)
+	}
+
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, key string, body []byte, headers http.Header) (*http.Response, error) {
+	target := *c.endpoint
+	target.Path = c.endpoint.Path + "/" + c.bucket + "/" + key
+	target.RawPath = c.endpoint.Path + "/" + uriEncode(c.bucket, false) + "/" + uriEncode(key, false)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create %s request for %s: %w", method, key, err)
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.ContentLength = int64(len(body))
+
+	if c.accessKeyID != "" {
+		c.sign(req, body, time.Now().UTC())
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+
+		return nil, fmt.Errorf("%s %s failed: %w", method, key, err)
+	}
+
+	return resp, nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header
+func (c *Client) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format(amzDateFormat)
+	shortDate := now.Format(shortDateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			signed[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, c.region, signingService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{signingAlgo, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.secretAccessKey), shortDate)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, signingService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgo, c.accessKeyID, scope, signedHeaders, signature))
+}
+
+// uriEncode escapes everything except RFC 3986 unreserved characters, keeping '/' unless encodeSlash
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9'),
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			b.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func responseError(op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, constants.HTTPErrorBufferSize))
+
+	return fmt.Errorf("%s %s returned HTTP %d: %s", op, key, resp.StatusCode, strings.TrimSpace(string(body)))
+}
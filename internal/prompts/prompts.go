@@ -0,0 +1,135 @@
+// internal/prompts/prompts.go
+package prompts
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// ArgumentError reports prompt arguments that are missing or do not match their variable's type
+type ArgumentError struct {
+	Prompt   string
+	Argument string
+	Reason   string
+}
+
+func (e *ArgumentError) Error() string {
+
+	return fmt.Sprintf("prompt '%s' argument '%s' %s", e.Prompt, e.Argument, e.Reason)
+}
+
+// Find returns the prompt with the given name
+func Find(prompts []config.PromptConfig, name string) (config.PromptConfig, bool) {
+	for _, prompt := range prompts {
+		if prompt.Name == name {
+
+			return prompt, true
+		}
+	}
+
+	return config.PromptConfig{}, false
+}
+
+// Definition describes a prompt as prompts/list reports it
+func Definition(prompt config.PromptConfig) map[string]interface{} {
+	arguments := make([]map[string]interface{}, 0, len(prompt.Variables))
+	for _, variable := range prompt.Variables {
+		argument := map[string]interface{}{
+			"name":     variable.Name,
+			"required": variable.Required && variable.Default == nil,
+		}
+		if variable.Description != "" {
+			argument["description"] = variable.Description
+		}
+		arguments = append(arguments, argument)
+	}
+
+	definition := map[string]interface{}{
+		"name":      prompt.Name,
+		"arguments": arguments,
+	}
+	if prompt.Description != "" {
+		definition["description"] = prompt.Description
+	}
+
+	return definition
+}
+
+// List describes every prompt as prompts/list reports them
+func List(prompts []config.PromptConfig) []map[string]interface{} {
+	definitions := make([]map[string]interface{}, 0, len(prompts))
+	for _, prompt := range prompts {
+		definitions = append(definitions, Definition(prompt))
+	}
+
+	return definitions
+}
+
+// Render fills the template's {{variable}} placeholders from arguments, falling back to the
+// variables' defaults, and returns the prompts/get result. Placeholders of optional
+// variables without a value become empty.
+func Render(prompt config.PromptConfig, arguments map[string]string) (map[string]interface{}, error) {
+	values := make(map[string]string, len(prompt.Variables))
+	for _, variable := range prompt.Variables {
+		value, exists := arguments[variable.Name]
+		if !exists && variable.Default != nil {
+			value, exists = fmt.Sprint(variable.Default), true
+		}
+		if !exists {
+			if variable.Required {
+
+				return nil, &ArgumentError{Prompt: prompt.Name, Argument: variable.Name, Reason: "is required"}
+			}
+			values[variable.Name] = ""
+
+			continue
+		}
+		if err := checkType(variable.Type, value); err != nil {
+
+			return nil, &ArgumentError{Prompt: prompt.Name, Argument: variable.Name, Reason: err.Error()}
+		}
+		values[variable.Name] = value
+	}
+
+	text := config.PromptPlaceholderPattern.ReplaceAllStringFunc(prompt.Template, func(placeholder string) string {
+		name := config.PromptPlaceholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, declared := values[name]; declared {
+
+			return value
+		}
+
+		return placeholder
+	})
+
+	result := map[string]interface{}{
+		"messages": []interface{}{map[string]interface{}{
+			"role":    "user",
+			"content": map[string]interface{}{"type": "text", "text": text},
+		}},
+	}
+	if prompt.Description != "" {
+		result["description"] = prompt.Description
+	}
+
+	return result, nil
+}
+
+func checkType(kind, value string) error {
+	var err error
+	switch kind {
+	case "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "integer":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+
+		return fmt.Errorf("must be a %s, got '%s'", kind, value)
+	}
+
+	return nil
+}
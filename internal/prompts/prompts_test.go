@@ -0,0 +1,70 @@
+package prompts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestRender(t *testing.T) {
+	prompt := config.PromptConfig{
+		Name:     "review",
+		Template: "Review {{ file }} in {{language}} at depth {{depth}}{{note}}. Keep {{other}}.",
+		Variables: []config.PromptVariable{
+			{Name: "file", Required: true},
+			{Name: "language", Default: "Go"},
+			{Name: "depth", Type: "integer", Default: 2},
+			{Name: "note"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		arguments map[string]string
+		want      string
+		wantErr   bool
+	}{
+		{name: "defaults", arguments: map[string]string{"file": "main.go"}, want: "Review main.go in Go at depth 2. Keep {{other}}."},
+		{name: "arguments win", arguments: map[string]string{"file": "a.py", "language": "Python", "depth": "5", "note": "!"}, want: "Review a.py in Python at depth 5!. Keep {{other}}."},
+		{name: "missing required", arguments: map[string]string{}, wantErr: true},
+		{name: "wrong type", arguments: map[string]string{"file": "x", "depth": "deep"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Render(prompt, tt.arguments)
+			if tt.wantErr {
+				var argumentErr *ArgumentError
+				if !errors.As(err, &argumentErr) {
+					t.Fatalf("expected an argument error, got %v", err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			message := result["messages"].([]interface{})[0].(map[string]interface{})
+			if text := message["content"].(map[string]interface{})["text"]; text != tt.want {
+				t.Errorf("got %q, want %q", text, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	definition := Definition(config.PromptConfig{
+		Name: "review",
+		Variables: []config.PromptVariable{
+			{Name: "file", Required: true, Description: "File to review"},
+			{Name: "language", Required: true, Default: "Go"},
+		},
+	})
+	arguments := definition["arguments"].([]map[string]interface{})
+	if arguments[0]["required"] != true || arguments[1]["required"] != false {
+		t.Errorf("a default should make an argument optional, got %v", arguments)
+	}
+	if _, exists := definition["description"]; exists {
+		t.Error("expected no empty description")
+	}
+}
@@ -37,6 +37,7 @@ const (
 	ExecutionError      = -31990
 	StateError          = -31989
 	ConfigurationError  = -31988
+	QuotaExceeded       = -31987
 )
 
 // MCPError represents a complete MCP protocol error
@@ -2,7 +2,9 @@
 package protocol
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +15,7 @@ type SamplingManager struct {
 	requests      map[string]*SamplingRequest
 	handlers      map[string]SamplingHandler
 	humanControls map[string]*HumanControlConfig
+	reviews       map[string]chan struct{} // closed once a request awaiting approval is reviewed
 	mu            sync.RWMutex
 }
 
@@ -21,14 +24,25 @@ type SamplingRequest struct {
 	ID           string             `json:"id"`
 	ServerName   string             `json:"serverName"`
 	Messages     []SamplingMessage  `json:"messages"`
+	SystemPrompt string             `json:"systemPrompt,omitempty"`
+	Model        string             `json:"model,omitempty"` // model the request is answered with
 	ModelPrefs   ModelPreferences   `json:"modelPrefs,omitempty"`
 	MaxTokens    int                `json:"maxTokens,omitempty"`
 	StopSequence []string           `json:"stopSequence,omitempty"`
 	Temperature  float64            `json:"temperature,omitempty"`
 	Context      SamplingContext    `json:"context,omitempty"`
 	Created      time.Time          `json:"created"`
-	Status       string             `json:"status"` // "pending", "approved", "rejected", "completed", "failed"
+	Status       string             `json:"status"` // "pending", "awaiting_approval", "approved", "rejected", "completed", "failed"
 	HumanReview  *HumanReviewResult `json:"humanReview,omitempty"`
+	Response     *SamplingResponse  `json:"response,omitempty"`
+	Error        string             `json:"error,omitempty"`
+}
+
+// SamplingChanges are edits a reviewer makes to a request while approving it
+type SamplingChanges struct {
+	Messages     []SamplingMessage `json:"messages,omitempty"`
+	SystemPrompt *string           `json:"systemPrompt,omitempty"`
+	MaxTokens    int               `json:"maxTokens,omitempty"`
 }
 
 // SamplingMessage represents a message in the sampling request
@@ -138,6 +152,7 @@ func NewSamplingManager() *SamplingManager {
 		requests:      make(map[string]*SamplingRequest),
 		handlers:      make(map[string]SamplingHandler),
 		humanControls: make(map[string]*HumanControlConfig),
+		reviews:       make(map[string]chan struct{}),
 	}
 }
 
@@ -157,39 +172,55 @@ func (sm *SamplingManager) SetHumanControls(serverName string, config *HumanCont
 
 // CreateSamplingRequest creates a new sampling request
 func (sm *SamplingManager) CreateSamplingRequest(serverName string, messages []SamplingMessage, prefs ModelPreferences, context SamplingContext) (*SamplingRequest, error) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	requestID := fmt.Sprintf("sampling_%s_%d", serverName, time.Now().UnixNano())
 
-	request := &SamplingRequest{
-		ID:         requestID,
+	return sm.SubmitRequest(&SamplingRequest{
 		ServerName: serverName,
 		Messages:   messages,
 		ModelPrefs: prefs,
 		Context:    context,
-		Created:    time.Now(),
-		Status:     "pending",
-	}
+	}), nil
+}
+
+// SubmitRequest records a sampling request and applies the server's human controls to it:
+// requests matching a block pattern are rejected, those matching an auto-approve pattern go
+// ahead, and the rest wait for review when the controls ask for it
+func (sm *SamplingManager) SubmitRequest(request *SamplingRequest) *SamplingRequest {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
-	// Check if human review is required
-	if humanConfig, exists := sm.humanControls[serverName]; exists {
-		if humanConfig.RequireApproval {
-			if sm.requiresHumanApproval(request, humanConfig) {
-				request.Status = "awaiting_approval"
+	request.ID = fmt.Sprintf("sampling_%s_%d", request.ServerName, time.Now().UnixNano())
+	request.Created = time.Now()
+	request.Status = "pending"
+
+	if humanConfig, exists := sm.humanControls[request.ServerName]; exists {
+		if pattern, blocked := sm.matchesAny(request, humanConfig.BlockPatterns); blocked {
+			request.Status = "rejected"
+			request.HumanReview = &HumanReviewResult{
+				Reviewer:   "block_patterns",
+				ReviewTime: request.Created,
+				Comments:   fmt.Sprintf("matches block pattern '%s'", pattern),
 			}
+		} else if sm.requiresHumanApproval(request, humanConfig) {
+			request.Status = "awaiting_approval"
+			sm.reviews[request.ID] = make(chan struct{})
 		}
 	}
 
-	sm.requests[requestID] = request
+	sm.requests[request.ID] = request
 
-	return request, nil
+	return request.snapshot()
 }
 
 // ProcessSamplingRequest processes a sampling request
 func (sm *SamplingManager) ProcessSamplingRequest(requestID string) (*SamplingResponse, error) {
 	sm.mu.RLock()
 	request, exists := sm.requests[requestID]
+	var snapshot *SamplingRequest
+	var handler SamplingHandler
+	if exists {
+		snapshot = request.snapshot()
+		handler = sm.selectHandler(request)
+	}
 	sm.mu.RUnlock()
 
 	if !exists {
@@ -198,28 +229,28 @@ func (sm *SamplingManager) ProcessSamplingRequest(requestID string) (*SamplingRe
 	}
 
 	// Check if request needs approval
-	if request.Status == "awaiting_approval" {
+	if snapshot.Status == "awaiting_approval" {
 
 		return nil, fmt.Errorf("sampling request %s is awaiting human approval", requestID)
 	}
 
-	if request.Status == "rejected" {
+	if snapshot.Status == "rejected" {
 
 		return nil, fmt.Errorf("sampling request %s was rejected", requestID)
 	}
 
 	// Find appropriate handler
-	handler := sm.selectHandler(request)
 	if handler == nil {
 
 		return nil, fmt.Errorf("no suitable handler found for sampling request")
 	}
 
 	// Process the request
-	response, err := handler.HandleSamplingRequest(request)
+	response, err := handler.HandleSamplingRequest(snapshot)
 	if err != nil {
 		sm.mu.Lock()
 		request.Status = "failed"
+		request.Error = err.Error()
 		sm.mu.Unlock()
 
 		return nil, fmt.Errorf("sampling request failed: %w", err)
@@ -227,6 +258,7 @@ func (sm *SamplingManager) ProcessSamplingRequest(requestID string) (*SamplingRe
 
 	sm.mu.Lock()
 	request.Status = "completed"
+	request.Response = response
 	sm.mu.Unlock()
 
 	return response, nil
@@ -234,6 +266,12 @@ func (sm *SamplingManager) ProcessSamplingRequest(requestID string) (*SamplingRe
 
 // ApproveRequest approves a sampling request
 func (sm *SamplingManager) ApproveRequest(requestID, reviewer, comments string) error {
+
+	return sm.ApproveRequestWithChanges(requestID, reviewer, comments, nil)
+}
+
+// ApproveRequestWithChanges approves a sampling request after applying the reviewer's edits
+func (sm *SamplingManager) ApproveRequestWithChanges(requestID, reviewer, comments string, changes *SamplingChanges) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -248,6 +286,21 @@ func (sm *SamplingManager) ApproveRequest(requestID, reviewer, comments string)
 		return fmt.Errorf("sampling request %s is not awaiting approval", requestID)
 	}
 
+	modifications := make(map[string]interface{})
+	if changes != nil {
+		if len(changes.Messages) > 0 {
+			request.Messages = changes.Messages
+			modifications["messages"] = len(changes.Messages)
+		}
+		if changes.SystemPrompt != nil {
+			request.SystemPrompt = *changes.SystemPrompt
+			modifications["systemPrompt"] = *changes.SystemPrompt
+		}
+		if changes.MaxTokens > 0 {
+			request.MaxTokens = changes.MaxTokens
+			modifications["maxTokens"] = changes.MaxTokens
+		}
+	}
 	request.Status = "approved"
 	request.HumanReview = &HumanReviewResult{
 		Approved:   true,
@@ -255,6 +308,10 @@ func (sm *SamplingManager) ApproveRequest(requestID, reviewer, comments string)
 		ReviewTime: time.Now(),
 		Comments:   comments,
 	}
+	if len(modifications) > 0 {
+		request.HumanReview.Modifications = modifications
+	}
+	sm.finishReview(requestID)
 
 	return nil
 }
@@ -282,26 +339,90 @@ func (sm *SamplingManager) RejectRequest(requestID, reviewer, reason string) err
 		ReviewTime: time.Now(),
 		Comments:   reason,
 	}
+	sm.finishReview(requestID)
 
 	return nil
 }
 
-// requiresHumanApproval checks if a request requires human approval
-func (sm *SamplingManager) requiresHumanApproval(request *SamplingRequest, config *HumanControlConfig) bool {
-	// Check auto-approve patterns
-	for _, pattern := range config.AutoApprovePatterns {
-		if sm.matchesPattern(request, pattern) {
+// finishReview wakes anyone waiting on the request's review. The caller holds sm.mu.
+func (sm *SamplingManager) finishReview(requestID string) {
+	if done, exists := sm.reviews[requestID]; exists {
+		close(done)
+		delete(sm.reviews, requestID)
+	}
+}
 
-			return false
+// WaitForReview blocks until a request awaiting approval is approved or rejected, and
+// rejects it when ctx ends first. It returns the request as reviewed.
+func (sm *SamplingManager) WaitForReview(ctx context.Context, requestID string) (*SamplingRequest, error) {
+	sm.mu.RLock()
+	request, exists := sm.requests[requestID]
+	done := sm.reviews[requestID]
+	sm.mu.RUnlock()
+	if !exists {
+
+		return nil, fmt.Errorf("sampling request %s not found", requestID)
+	}
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			// Losing the race to a reviewer is fine: their decision stands
+			_ = sm.RejectRequest(requestID, "timeout", "not reviewed in time")
 		}
 	}
 
-	// Check block patterns
-	for _, pattern := range config.BlockPatterns {
-		if sm.matchesPattern(request, pattern) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 
-			return true // Block and require approval
-		}
+	return request.snapshot(), nil
+}
+
+// GetRequest returns a copy of a sampling request
+func (sm *SamplingManager) GetRequest(requestID string) (*SamplingRequest, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	request, exists := sm.requests[requestID]
+	if !exists {
+
+		return nil, false
+	}
+
+	return request.snapshot(), true
+}
+
+// ListRequests returns copies of every sampling request, newest first
+func (sm *SamplingManager) ListRequests() []*SamplingRequest {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	requests := make([]*SamplingRequest, 0, len(sm.requests))
+	for _, request := range sm.requests {
+		requests = append(requests, request.snapshot())
+	}
+	sort.Slice(requests, func(i, j int) bool {
+
+		return requests[i].Created.After(requests[j].Created)
+	})
+
+	return requests
+}
+
+func (r *SamplingRequest) snapshot() *SamplingRequest {
+	copied := *r
+	copied.Messages = append([]SamplingMessage(nil), r.Messages...)
+
+	return &copied
+}
+
+// requiresHumanApproval checks if a request requires human approval
+func (sm *SamplingManager) requiresHumanApproval(request *SamplingRequest, config *HumanControlConfig) bool {
+	// Check auto-approve patterns
+	if _, autoApproved := sm.matchesAny(request, config.AutoApprovePatterns); autoApproved {
+
+		return false
 	}
 
 	// Check token limits
@@ -315,9 +436,25 @@ func (sm *SamplingManager) requiresHumanApproval(request *SamplingRequest, confi
 	return config.RequireApproval
 }
 
+// matchesAny returns the first of the patterns the request matches
+func (sm *SamplingManager) matchesAny(request *SamplingRequest, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		if sm.matchesPattern(request, pattern) {
+
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
 // matchesPattern checks if a request matches a pattern
 func (sm *SamplingManager) matchesPattern(request *SamplingRequest, pattern string) bool {
 	// Simple pattern matching - in production, you might want regex or more sophisticated matching
+	if strings.Contains(strings.ToLower(request.SystemPrompt), strings.ToLower(pattern)) {
+
+		return true
+	}
 	for _, msg := range request.Messages {
 		if strings.Contains(strings.ToLower(msg.Content.Text), strings.ToLower(pattern)) {
 
@@ -355,7 +492,7 @@ func (sm *SamplingManager) GetPendingRequests() []*SamplingRequest {
 	var pending []*SamplingRequest
 	for _, request := range sm.requests {
 		if request.Status == "awaiting_approval" {
-			pending = append(pending, request)
+			pending = append(pending, request.snapshot())
 		}
 	}
 
@@ -30,6 +30,7 @@ type ResourceSubscription struct {
 	ID           string              `json:"id"`
 	ClientID     string              `json:"clientId"`
 	SessionID    string              `json:"sessionId"`
+	Server       string              `json:"server,omitempty"`
 	URI          string              `json:"uri"`
 	IsTemplate   bool                `json:"isTemplate"`
 	Filters      []ResourceFilter    `json:"filters,omitempty"`
@@ -100,6 +101,7 @@ type SubscribeRequest struct {
 	URI     string              `json:"uri"`
 	Filters []ResourceFilter    `json:"filters,omitempty"`
 	Options SubscriptionOptions `json:"options,omitempty"`
+	Server  string              `json:"-"` // Set by the proxy to the server that owns the resource
 }
 
 // SubscribeResponse represents a resources/subscribe response
@@ -107,9 +109,12 @@ type SubscribeResponse struct {
 	SubscriptionID string `json:"subscriptionId"`
 }
 
-// UnsubscribeRequest represents a resources/unsubscribe request
+// UnsubscribeRequest represents a resources/unsubscribe request. Clients name either the
+// subscription or, as the MCP specification does, the resource's URI.
 type UnsubscribeRequest struct {
-	SubscriptionID string `json:"subscriptionId"`
+	SubscriptionID string `json:"subscriptionId,omitempty"`
+	URI            string `json:"uri,omitempty"`
+	Server         string `json:"-"` // Set by the proxy to the server that owns the resource
 }
 
 // NewSubscriptionManager creates a new subscription manager
@@ -132,6 +137,23 @@ func (sm *SubscriptionManager) Subscribe(clientID, sessionID string, req Subscri
 		return nil, NewValidationError("uri", req.URI, "URI cannot be empty")
 	}
 
+	// Subscribing to the same resource again updates the existing subscription
+	if client := sm.clients[clientID]; client != nil {
+		for _, existing := range client.Subscriptions {
+			if existing.Server == req.Server && existing.URI == req.URI {
+				if err := sm.validateFilters(req.Filters); err != nil {
+
+					return nil, err
+				}
+				existing.Filters = req.Filters
+				existing.Options = req.Options
+				client.LastSeen = time.Now()
+
+				return &SubscribeResponse{SubscriptionID: existing.ID}, nil
+			}
+		}
+	}
+
 	// Generate subscription ID
 	subscriptionID := fmt.Sprintf("sub_%s_%d", clientID, time.Now().UnixNano())
 
@@ -149,6 +171,7 @@ func (sm *SubscriptionManager) Subscribe(clientID, sessionID string, req Subscri
 		ID:         subscriptionID,
 		ClientID:   clientID,
 		SessionID:  sessionID,
+		Server:     req.Server,
 		URI:        req.URI,
 		IsTemplate: isTemplate,
 		Filters:    req.Filters,
@@ -178,43 +201,117 @@ func (sm *SubscriptionManager) Subscribe(clientID, sessionID string, req Subscri
 	}, nil
 }
 
-// Unsubscribe removes a resource subscription
-func (sm *SubscriptionManager) Unsubscribe(clientID string, req UnsubscribeRequest) error {
+// Unsubscribe removes a client's subscription, or its subscriptions to a URI, and returns
+// the subscriptions it removed
+func (sm *SubscriptionManager) Unsubscribe(clientID string, req UnsubscribeRequest) ([]*ResourceSubscription, error) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	subscription, exists := sm.subscriptions[req.SubscriptionID]
-	if !exists {
+	var removed []*ResourceSubscription
+	if req.SubscriptionID != "" {
+		subscription, exists := sm.subscriptions[req.SubscriptionID]
+		if !exists {
+
+			return nil, NewValidationError("subscriptionId", req.SubscriptionID, "subscription not found")
+		}
+
+		// Verify ownership
+		if subscription.ClientID != clientID {
 
-		return NewValidationError("subscriptionId", req.SubscriptionID, "subscription not found")
+			return nil, NewAuthorizationError(req.SubscriptionID, "unsubscribe")
+		}
+		removed = append(removed, subscription)
+	} else if client, exists := sm.clients[clientID]; exists {
+		for _, subscription := range client.Subscriptions {
+			if subscription.URI == req.URI && (req.Server == "" || subscription.Server == req.Server) {
+				removed = append(removed, subscription)
+			}
+		}
 	}
+	if len(removed) == 0 {
 
-	// Verify ownership
-	if subscription.ClientID != clientID {
+		return nil, NewValidationError("uri", req.URI, "no subscription to this resource")
+	}
 
-		return NewAuthorizationError(req.SubscriptionID, "unsubscribe")
+	for _, subscription := range removed {
+		sm.removeSubscription(subscription)
 	}
 
-	// Remove subscription
-	delete(sm.subscriptions, req.SubscriptionID)
+	return removed, nil
+}
 
-	// Remove from client tracking
-	if client, exists := sm.clients[clientID]; exists {
-		delete(client.Subscriptions, req.SubscriptionID)
+// RemoveClient removes every subscription of a client, as when it disconnects, and returns them
+func (sm *SubscriptionManager) RemoveClient(clientID string) []*ResourceSubscription {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	client, exists := sm.clients[clientID]
+	if !exists {
+
+		return nil
+	}
+	removed := make([]*ResourceSubscription, 0, len(client.Subscriptions))
+	for _, subscription := range client.Subscriptions {
+		removed = append(removed, subscription)
+	}
+	for _, subscription := range removed {
+		sm.removeSubscription(subscription)
+	}
+
+	return removed
+}
+
+// removeSubscription forgets a subscription, and its client once it has none left. The
+// caller holds the lock.
+func (sm *SubscriptionManager) removeSubscription(subscription *ResourceSubscription) {
+	delete(sm.subscriptions, subscription.ID)
+	if client, exists := sm.clients[subscription.ClientID]; exists {
+		delete(client.Subscriptions, subscription.ID)
 
 		// Clean up empty client
 		if len(client.Subscriptions) == 0 {
-			delete(sm.clients, clientID)
+			delete(sm.clients, subscription.ClientID)
 		}
 	}
+}
 
-	return nil
+// Touch keeps a connected client's subscriptions from expiring
+func (sm *SubscriptionManager) Touch(clientID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if client, exists := sm.clients[clientID]; exists {
+		client.LastSeen = time.Now()
+	}
+}
+
+// CountSubscriptions returns how many subscriptions there are to a server's resource, or to
+// any of the server's resources when uri is empty
+func (sm *SubscriptionManager) CountSubscriptions(server, uri string) int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	count := 0
+	for _, subscription := range sm.subscriptions {
+		if subscription.Server == server && (uri == "" || subscription.URI == uri) {
+			count++
+		}
+	}
+
+	return count
 }
 
 // NotifyResourceUpdate sends notifications to matching subscriptions
 func (sm *SubscriptionManager) NotifyResourceUpdate(uri string, updateType string, content *ResourceContent, metadata map[string]interface{}) error {
+
+	return sm.NotifyServerResourceUpdate("", uri, updateType, content, metadata)
+}
+
+// NotifyServerResourceUpdate sends notifications to the subscriptions matching one server's
+// resource. An empty server matches subscriptions to every server.
+func (sm *SubscriptionManager) NotifyServerResourceUpdate(server, uri string, updateType string, content *ResourceContent, metadata map[string]interface{}) error {
 	sm.mu.RLock()
-	matchingSubscriptions := sm.findMatchingSubscriptions(uri)
+	matchingSubscriptions := sm.findMatchingSubscriptions(server, uri)
 	sm.mu.RUnlock()
 
 	if len(matchingSubscriptions) == 0 {
@@ -250,11 +347,15 @@ func (sm *SubscriptionManager) NotifyResourceUpdate(uri string, updateType strin
 	return nil
 }
 
-// findMatchingSubscriptions finds all subscriptions that match a URI
-func (sm *SubscriptionManager) findMatchingSubscriptions(uri string) []*ResourceSubscription {
+// findMatchingSubscriptions finds all subscriptions that match a server's URI
+func (sm *SubscriptionManager) findMatchingSubscriptions(server, uri string) []*ResourceSubscription {
 	var matches []*ResourceSubscription
 
 	for _, subscription := range sm.subscriptions {
+		if server != "" && subscription.Server != server {
+
+			continue
+		}
 		if sm.doesURIMatch(subscription, uri) {
 			matches = append(matches, subscription)
 		}
@@ -459,20 +560,24 @@ func (sm *SubscriptionManager) GetSubscriptions(clientID string) []*ResourceSubs
 	return subscriptions
 }
 
-// CleanupExpiredSubscriptions removes expired client subscriptions
-func (sm *SubscriptionManager) CleanupExpiredSubscriptions(maxAge time.Duration) {
+// CleanupExpiredSubscriptions removes expired client subscriptions and returns them
+func (sm *SubscriptionManager) CleanupExpiredSubscriptions(maxAge time.Duration) []*ResourceSubscription {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
 	cutoff := time.Now().Add(-maxAge)
 
+	var removed []*ResourceSubscription
 	for clientID, client := range sm.clients {
 		if client.LastSeen.Before(cutoff) {
 			// Remove all subscriptions for this client
-			for subID := range client.Subscriptions {
+			for subID, subscription := range client.Subscriptions {
 				delete(sm.subscriptions, subID)
+				removed = append(removed, subscription)
 			}
 			delete(sm.clients, clientID)
 		}
 	}
+
+	return removed
 }
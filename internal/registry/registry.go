@@ -0,0 +1,283 @@
+// internal/registry/registry.go
+package registry
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+//go:embed servers.json
+var embeddedIndex []byte
+
+// Entry describes an installable MCP server
+type Entry struct {
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Tags         []string          `json:"tags,omitempty"`
+	Homepage     string            `json:"homepage,omitempty"`
+	Image        string            `json:"image,omitempty"`
+	Command      string            `json:"command,omitempty"`
+	Args         []string          `json:"args,omitempty"`
+	Protocol     string            `json:"protocol,omitempty"`
+	HTTPPort     int               `json:"http_port,omitempty"`
+	Ports        []string          `json:"ports,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+	Secrets      []string          `json:"secrets,omitempty"` // variables the user supplies, referenced as ${NAME}
+	Capabilities []string          `json:"capabilities,omitempty"`
+	Volumes      []string          `json:"volumes,omitempty"`
+	Workspace    string            `json:"workspace,omitempty"` // container path a host directory is mounted at
+}
+
+// index is the layout of servers.json and of the remote index
+type index struct {
+	Version int     `json:"version"`
+	Servers []Entry `json:"servers"`
+}
+
+// Registry is the catalog of servers add and search work from: the index built into
+// mcp-compose, overlaid with the remote index when it could be fetched
+type Registry struct {
+	entries map[string]Entry
+	// RemoteErr is why the remote index was not used, if it wasn't
+	RemoteErr error
+}
+
+// LoadOptions controls where the remote index comes from
+type LoadOptions struct {
+	URL     string // remote index; defaults to MCP_COMPOSE_REGISTRY_URL or the project's index
+	Offline bool   // only use the built-in index
+}
+
+// Embedded returns the registry built into mcp-compose
+func Embedded() *Registry {
+	r := &Registry{entries: make(map[string]Entry)}
+	var idx index
+	if err := json.Unmarshal(embeddedIndex, &idx); err != nil {
+		panic(fmt.Sprintf("invalid embedded registry: %v", err))
+	}
+	r.merge(idx.Servers)
+
+	return r
+}
+
+// Load returns the built-in registry updated from the remote index. A remote index that
+// cannot be fetched is not an error: the last cached copy or the built-in one is used.
+func Load(opts LoadOptions) *Registry {
+	r := Embedded()
+	if opts.Offline {
+
+		return r
+	}
+
+	url := opts.URL
+	if url == "" {
+		url = os.Getenv("MCP_COMPOSE_REGISTRY_URL")
+	}
+	if url == "" {
+		url = constants.RegistryIndexURL
+	}
+
+	servers, err := remoteServers(url)
+	if err != nil {
+		r.RemoteErr = err
+	}
+	r.merge(servers)
+
+	return r
+}
+
+func (r *Registry) merge(servers []Entry) {
+	for _, entry := range servers {
+		if entry.Name != "" {
+			r.entries[strings.ToLower(entry.Name)] = entry
+		}
+	}
+}
+
+// Get returns the entry for a server name
+func (r *Registry) Get(name string) (Entry, bool) {
+	entry, exists := r.entries[strings.ToLower(name)]
+
+	return entry, exists
+}
+
+// Entries returns every entry sorted by name
+func (r *Registry) Entries() []Entry {
+	entries := make([]Entry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	return entries
+}
+
+// Search returns the entries whose name, description or tags contain every word of the query
+func (r *Registry) Search(query string) []Entry {
+	words := strings.Fields(strings.ToLower(query))
+	var matches []Entry
+	for _, entry := range r.Entries() {
+		text := strings.ToLower(entry.Name + " " + entry.Description + " " + strings.Join(entry.Tags, " "))
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				matched = false
+
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, entry)
+		}
+	}
+
+	return matches
+}
+
+// ServerConfig builds the compose server block for the entry. workspaceDir is the host
+// directory mounted at the entry's workspace, if it has one.
+func (e Entry) ServerConfig(workspaceDir string) config.ServerConfig {
+	server := config.ServerConfig{
+		Image:        e.Image,
+		Command:      e.Command,
+		Args:         append([]string(nil), e.Args...),
+		Protocol:     e.Protocol,
+		HttpPort:     e.HTTPPort,
+		Ports:        append([]string(nil), e.Ports...),
+		Capabilities: append([]string(nil), e.Capabilities...),
+		Volumes:      append([]string(nil), e.Volumes...),
+	}
+
+	for key, value := range e.Env {
+		if server.Env == nil {
+			server.Env = make(map[string]string)
+		}
+		server.Env[key] = value
+	}
+	// Secrets the arguments don't already use are passed in the environment
+	args := strings.Join(e.Args, " ")
+	for _, key := range e.Secrets {
+		if strings.Contains(args, "${"+key+"}") {
+
+			continue
+		}
+		if server.Env == nil {
+			server.Env = make(map[string]string)
+		}
+		server.Env[key] = "${" + key + "}"
+	}
+
+	if e.Workspace != "" && workspaceDir != "" {
+		server.Volumes = append(server.Volumes, workspaceDir+":"+e.Workspace)
+	}
+
+	return server
+}
+
+// NamedVolumes returns the named volumes the entry mounts, which the compose file declares
+func (e Entry) NamedVolumes() []string {
+	var names []string
+	for _, volume := range e.Volumes {
+		source, _, found := strings.Cut(volume, ":")
+		if !found || source == "" || strings.ContainsAny(source[:1], "/.~$") {
+
+			continue
+		}
+		names = append(names, source)
+	}
+
+	return names
+}
+
+// remoteServers fetches the remote index, caching it per URL for RegistryCacheTTL
+func remoteServers(url string) ([]Entry, error) {
+	cachePath := ""
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		sum := sha256.Sum256([]byte(url))
+		cachePath = filepath.Join(cacheDir, "mcp-compose", fmt.Sprintf("registry-%x.json", sum[:8]))
+	}
+
+	if cachePath != "" {
+		if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < constants.RegistryCacheTTL {
+			if servers, err := readIndexFile(cachePath); err == nil {
+
+				return servers, nil
+			}
+		}
+	}
+
+	servers, data, err := fetchIndex(url)
+	if err != nil {
+		// Fall back to a stale copy rather than nothing
+		if cachePath != "" {
+			if cached, cacheErr := readIndexFile(cachePath); cacheErr == nil {
+
+				return cached, err
+			}
+		}
+
+		return nil, err
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), constants.DefaultDirMode); err == nil {
+			_ = os.WriteFile(cachePath, data, constants.DefaultFileMode)
+		}
+	}
+
+	return servers, nil
+}
+
+func fetchIndex(url string) ([]Entry, []byte, error) {
+	client := &http.Client{Timeout: constants.RegistryFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+
+		return nil, nil, fmt.Errorf("failed to fetch registry index: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+
+		return nil, nil, fmt.Errorf("failed to fetch registry index: %s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, constants.RegistryMaxIndexSize))
+	if err != nil {
+
+		return nil, nil, fmt.Errorf("failed to read registry index: %w", err)
+	}
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+
+		return nil, nil, fmt.Errorf("failed to parse registry index from %s: %w", url, err)
+	}
+
+	return idx.Servers, data, nil
+}
+
+func readIndexFile(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+
+		return nil, err
+	}
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+
+		return nil, err
+	}
+
+	return idx.Servers, nil
+}
@@ -0,0 +1,70 @@
+// internal/runtime/crashloop.go
+package runtime
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// CrashLoopLimits decide when a server that keeps restarting is backed off
+type CrashLoopLimits struct {
+	MaxRestarts int           // restarts allowed within Window
+	Window      time.Duration // sliding window restarts are counted over
+	Backoff     time.Duration // how long a crash-looping server waits before its next start
+}
+
+// CrashLoopLimitsFromConfig resolves a server's crash_loop settings, filling in defaults
+func CrashLoopLimitsFromConfig(crashLoop *config.CrashLoopConfig) CrashLoopLimits {
+	maxRestarts, window, backoff := crashLoop.Limits()
+
+	return CrashLoopLimits{MaxRestarts: maxRestarts, Window: window, Backoff: backoff}
+}
+
+// args renders the limits as supervise-process flags
+func (l CrashLoopLimits) args() []string {
+
+	return []string{
+		"--crash-max-restarts", strconv.Itoa(l.MaxRestarts),
+		"--crash-window", l.Window.String(),
+		"--crash-backoff", l.Backoff.String(),
+	}
+}
+
+// CrashLoopDetector counts a server's restarts over a sliding window
+type CrashLoopDetector struct {
+	limits   CrashLoopLimits
+	restarts []time.Time
+}
+
+// NewCrashLoopDetector creates a detector for the given limits
+func NewCrashLoopDetector(limits CrashLoopLimits) *CrashLoopDetector {
+
+	return &CrashLoopDetector{limits: limits}
+}
+
+// Record notes a restart at the given time and reports whether the server has now restarted
+// more than MaxRestarts times within the window
+func (d *CrashLoopDetector) Record(at time.Time) bool {
+	recent := d.restarts[:0]
+	for _, restart := range d.restarts {
+		if at.Sub(restart) < d.limits.Window {
+			recent = append(recent, restart)
+		}
+	}
+	d.restarts = append(recent, at)
+
+	return d.limits.MaxRestarts > 0 && len(d.restarts) > d.limits.MaxRestarts
+}
+
+// Count returns the number of restarts recorded within the window
+func (d *CrashLoopDetector) Count() int {
+
+	return len(d.restarts)
+}
+
+// Reset forgets the recorded restarts, once a backoff has been served
+func (d *CrashLoopDetector) Reset() {
+	d.restarts = nil
+}
@@ -0,0 +1,519 @@
+// internal/runtime/logs.go
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// Log streams recorded for a process
+const (
+	StreamStdout     = "stdout"
+	StreamStderr     = "stderr"
+	StreamSupervisor = "supervisor"
+)
+
+// LogRotation controls when a supervisor rotates its process's log file and which rotated
+// files it keeps
+type LogRotation struct {
+	MaxSize     int64         // rotate before the file grows past this many bytes
+	RotateEvery time.Duration // rotate once the file's first line is this old; zero disables
+	MaxFiles    int           // rotated files kept as <log>.1 (newest) to <log>.N
+	MaxAge      time.Duration // rotated files older than this are removed; zero disables
+}
+
+// LogRotationFromConfig resolves the project's logging.retention settings, filling in defaults
+func LogRotationFromConfig(retention config.LogRetention) LogRotation {
+	rotation := LogRotation{
+		MaxSize:  constants.ProcessLogMaxSize,
+		MaxFiles: constants.ProcessLogMaxFiles,
+	}
+	if size, err := config.ParseByteSize(retention.MaxSize); err == nil && size > 0 {
+		rotation.MaxSize = size
+	}
+	if retention.MaxFiles > 0 {
+		rotation.MaxFiles = retention.MaxFiles
+	}
+	rotation.RotateEvery, _ = time.ParseDuration(retention.RotateEvery)
+	rotation.MaxAge, _ = time.ParseDuration(retention.MaxAge)
+
+	return rotation
+}
+
+// args renders the rotation as supervise-process flags
+func (r LogRotation) args() []string {
+
+	return []string{
+		"--log-max-size", strconv.FormatInt(r.MaxSize, 10),
+		"--log-max-files", strconv.Itoa(r.MaxFiles),
+		"--log-rotate-every", r.RotateEvery.String(),
+		"--log-max-age", r.MaxAge.String(),
+	}
+}
+
+// LogRecord is one captured line. Records are stored one JSON object per line, like the
+// json-file logging driver of docker.
+type LogRecord struct {
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"`
+	Log    string    `json:"log"`
+}
+
+// parseLogRecord decodes a stored line; lines written before capture started are kept as stdout
+func parseLogRecord(line string) LogRecord {
+	var record LogRecord
+	if err := json.Unmarshal([]byte(line), &record); err != nil || record.Stream == "" {
+
+		return LogRecord{Stream: StreamStdout, Log: line}
+	}
+
+	return record
+}
+
+// rotatingLog appends records to a log file, moving it to <path>.1 when it gets too big or old
+type rotatingLog struct {
+	path     string
+	rotation LogRotation
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func openRotatingLog(path string, rotation LogRotation) (*rotatingLog, error) {
+	l := &rotatingLog{path: path, rotation: rotation}
+	if err := l.open(); err != nil {
+
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *rotatingLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, constants.DefaultFileMode)
+	if err != nil {
+
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	l.openedAt = time.Now()
+	if l.size > 0 {
+		l.openedAt = firstRecordTime(l.path, info.ModTime())
+	}
+
+	return nil
+}
+
+// firstRecordTime is when the first line of an existing log file was written
+func firstRecordTime(path string, fallback time.Time) time.Time {
+	file, err := os.Open(path)
+	if err != nil {
+
+		return fallback
+	}
+	defer file.Close()
+
+	line, _ := bufio.NewReader(file).ReadString('\n')
+	if record := parseLogRecord(strings.TrimSpace(line)); !record.Time.IsZero() {
+
+		return record.Time
+	}
+
+	return fallback
+}
+
+func (l *rotatingLog) write(stream, line string) {
+	data, err := json.Marshal(LogRecord{Time: time.Now().UTC(), Stream: stream, Log: line})
+	if err != nil {
+
+		return
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+
+		return
+	}
+	if l.shouldRotate(int64(len(data))) {
+		if err := l.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to rotate %s: %v\n", l.path, err)
+		}
+		if l.file == nil {
+
+			return
+		}
+	}
+	n, _ := l.file.Write(data)
+	l.size += int64(n)
+}
+
+func (l *rotatingLog) shouldRotate(next int64) bool {
+	if l.size == 0 {
+
+		return false
+	}
+	if l.rotation.MaxSize > 0 && l.size+next > l.rotation.MaxSize {
+
+		return true
+	}
+
+	return l.rotation.RotateEvery > 0 && time.Since(l.openedAt) >= l.rotation.RotateEvery
+}
+
+// rotate shifts <path>.N-1 to <path>.N down to <path> to <path>.1, dropping the oldest file,
+// then removes rotated files past max_age and starts a new file
+func (l *rotatingLog) rotate() error {
+	_ = l.file.Close()
+	l.file = nil
+
+	maxFiles := l.rotation.MaxFiles
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	_ = os.Remove(rotatedLogPath(l.path, maxFiles))
+	for i := maxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(rotatedLogPath(l.path, i), rotatedLogPath(l.path, i+1))
+	}
+	renameErr := os.Rename(l.path, rotatedLogPath(l.path, 1))
+
+	if l.rotation.MaxAge > 0 {
+		for _, rotated := range rotatedLogFiles(l.path) {
+			if info, err := os.Stat(rotated); err == nil && time.Since(info.ModTime()) > l.rotation.MaxAge {
+				_ = os.Remove(rotated)
+			}
+		}
+	}
+
+	if err := l.open(); err != nil {
+
+		return err
+	}
+	if renameErr != nil {
+
+		return fmt.Errorf("failed to rotate log file: %w", renameErr)
+	}
+
+	return nil
+}
+
+func (l *rotatingLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		_ = l.file.Close()
+		l.file = nil
+	}
+}
+
+func rotatedLogPath(path string, n int) string {
+
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// rotatedLogFiles lists a log's rotated files from newest to oldest
+func rotatedLogFiles(path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+	numbered := make(map[string]int, len(matches))
+	rotated := make([]string, 0, len(matches))
+	for _, match := range matches {
+		n, err := strconv.Atoi(strings.TrimPrefix(match, path+"."))
+		if err != nil || n < 1 {
+
+			continue
+		}
+		numbered[match] = n
+		rotated = append(rotated, match)
+	}
+	sort.Slice(rotated, func(i, j int) bool { return numbered[rotated[i]] < numbered[rotated[j]] })
+
+	return rotated
+}
+
+// streamWriter splits one output stream of the process into records
+type streamWriter struct {
+	log    *rotatingLog
+	stream string
+	buf    []byte
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+
+			break
+		}
+		w.log.write(w.stream, strings.TrimSuffix(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= constants.ProcessLogMaxLineLength {
+		w.flush()
+	}
+
+	return len(p), nil
+}
+
+// flush records a trailing line that did not end with a newline
+func (w *streamWriter) flush() {
+	if len(w.buf) > 0 {
+		w.log.write(w.stream, string(w.buf))
+		w.buf = nil
+	}
+}
+
+// ProcessLogs returns a process for reading the logs kept under name, whether or not it is
+// still running
+func ProcessLogs(name string) *Process {
+	runDir, logDir := processDirs()
+
+	return &Process{
+		pidFile:   pidFilePath(runDir, name),
+		logFile:   filepath.Join(logDir, fmt.Sprintf("%s.log", name)),
+		statePath: statePath(runDir, name),
+		name:      name,
+	}
+}
+
+// logFiles returns the process's log files from oldest to newest
+func (p *Process) logFiles() []string {
+	rotated := rotatedLogFiles(p.logFile)
+	files := make([]string, 0, len(rotated)+1)
+	for i := len(rotated) - 1; i >= 0; i-- {
+		files = append(files, rotated[i])
+	}
+	if _, err := os.Stat(p.logFile); err == nil {
+		files = append(files, p.logFile)
+	}
+
+	return files
+}
+
+// ReadLogs returns every record kept for the process, oldest first
+func (p *Process) ReadLogs() ([]LogRecord, error) {
+	files := p.logFiles()
+	if len(files) == 0 {
+
+		return nil, fmt.Errorf("log file not found: %s", p.logFile)
+	}
+
+	var records []LogRecord
+	for _, path := range files {
+		fileRecords, err := readLogFile(path)
+		if err != nil {
+
+			return nil, err
+		}
+		records = append(records, fileRecords...)
+	}
+
+	return records, nil
+}
+
+func readLogFile(path string) ([]LogRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+	defer file.Close()
+
+	var records []LogRecord
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if trimmed := strings.TrimRight(line, "\r\n"); trimmed != "" {
+			records = append(records, parseLogRecord(trimmed))
+		}
+		if err != nil {
+
+			break
+		}
+	}
+
+	return records, nil
+}
+
+// writeLogRecord prints a record the way docker logs does: stdout to stdout, the rest to stderr
+func writeLogRecord(record LogRecord) {
+	out := os.Stdout
+	if record.Stream != StreamStdout {
+		out = os.Stderr
+	}
+	_, _ = fmt.Fprintln(out, record.Log)
+}
+
+// followLog passes records appended to the current log file from offset on to emit, starting
+// over from the top of the new file whenever the supervisor rotates it. It runs until
+// interrupted.
+func (p *Process) followLog(offset int64, emit func(LogRecord)) error {
+	file, err := openLogAt(p.logFile, offset)
+	if err != nil {
+
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := bufio.NewReader(file)
+	partial := ""
+	drain := func() {
+		for {
+			line, err := reader.ReadString('\n')
+			partial += line
+			if err != nil {
+
+				return
+			}
+			if trimmed := strings.TrimRight(partial, "\r\n"); trimmed != "" {
+				emit(parseLogRecord(trimmed))
+			}
+			partial = ""
+		}
+	}
+
+	for {
+		drain()
+		time.Sleep(constants.ProcessLogFollowInterval)
+
+		current, err := os.Stat(p.logFile)
+		if err != nil {
+
+			continue
+		}
+		if opened, err := file.Stat(); err == nil && os.SameFile(opened, current) {
+
+			continue
+		}
+
+		// Rotated: finish the old file, then continue with the new one
+		drain()
+		next, err := openLogAt(p.logFile, 0)
+		if err != nil {
+
+			continue
+		}
+		_ = file.Close()
+		file, reader, partial = next, bufio.NewReader(next), ""
+	}
+}
+
+func openLogAt(path string, offset int64) (*os.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to seek log file: %w", err)
+	}
+
+	return file, nil
+}
+
+// ShowLogs shows logs for a process
+func (p *Process) ShowLogs(follow bool) error {
+
+	return p.StreamLogs(LogStreamOptions{Follow: follow, Tail: -1}, writeLogRecord)
+}
+
+// LogStreamOptions selects the records StreamLogs passes on
+type LogStreamOptions struct {
+	Follow bool      // keep passing records as they are written
+	Tail   int       // only the last Tail records written so far; negative for all
+	Since  time.Time // only records written from then on; zero for all
+}
+
+// StreamLogs passes the process's records to emit, oldest first, and with Follow those
+// written afterwards until interrupted
+func (p *Process) StreamLogs(opts LogStreamOptions, emit func(LogRecord)) error {
+	records, err := p.ReadLogs()
+	if err != nil {
+
+		return err
+	}
+	if !opts.Since.IsZero() {
+		kept := records[:0]
+		for _, record := range records {
+			// Lines from before capture started carry no time and are older than any that do
+			if !record.Time.Before(opts.Since) {
+				kept = append(kept, record)
+			}
+		}
+		records = kept
+	}
+	if opts.Tail >= 0 && len(records) > opts.Tail {
+		records = records[len(records)-opts.Tail:]
+	}
+	for _, record := range records {
+		emit(record)
+	}
+	if !opts.Follow {
+
+		return nil
+	}
+
+	info, err := os.Stat(p.logFile)
+	if err != nil {
+
+		return fmt.Errorf("log file not found: %w", err)
+	}
+
+	return p.followLog(info.Size(), emit)
+}
+
+// TailLogs returns up to n of the most recent lines the process wrote, reading rotated
+// files only when the current one is too short
+func (p *Process) TailLogs(n int) ([]string, error) {
+	files := p.logFiles()
+	if len(files) == 0 {
+
+		return nil, fmt.Errorf("log file not found: %s", p.logFile)
+	}
+
+	var records []LogRecord
+	for i := len(files) - 1; i >= 0 && len(records) < n; i-- {
+		fileRecords, err := readLogFile(files[i])
+		if err != nil {
+
+			return nil, err
+		}
+		records = append(fileRecords, records...)
+	}
+	if len(records) > n {
+		records = records[len(records)-n:]
+	}
+
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		lines = append(lines, record.Log)
+	}
+
+	return lines, nil
+}
@@ -2,37 +2,73 @@
 package runtime
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"syscall"
 
+	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 )
 
 // ProcessOptions contains options for process start
 type ProcessOptions struct {
-	Env     map[string]string
-	WorkDir string
-	Name    string
+	Env           map[string]string
+	WorkDir       string
+	Name          string
+	RestartPolicy string // no, on-failure[:max], always or unless-stopped
+	LogRotation   LogRotation
+	CrashLoop     CrashLoopLimits
+	Notifications config.NotificationsConfig // hooks told when the process is backed off
+	Stdio         bool                       // serve the process's stdio for DialStdio
 }
 
-// Process represents a running server process
+// Process represents a running server process. The PID file holds the PID of the
+// supervisor that keeps the server's command running; see RunSupervisor.
 type Process struct {
-	cmd     *exec.Cmd
-	pidFile string
-	logFile string
-	name    string
+	cmd       *exec.Cmd
+	pidFile   string
+	logFile   string
+	statePath string
+	name      string
+}
+
+func processDirs() (string, string) {
+
+	return filepath.Join(os.TempDir(), "mcp-compose", "run"), filepath.Join(os.TempDir(), "mcp-compose", "logs")
+}
+
+// RunDir returns the directory holding the PID files of processes mcp-compose runs
+func RunDir() string {
+	runDir, _ := processDirs()
+
+	return runDir
+}
+
+// ProcessAlive reports whether a process with pid exists
+func ProcessAlive(pid int) bool {
+
+	return processAlive(pid)
+}
+
+func pidFilePath(runDir, name string) string {
+
+	return filepath.Join(runDir, fmt.Sprintf("%s.pid", name))
+}
+
+func statePath(runDir, name string) string {
+
+	return filepath.Join(runDir, fmt.Sprintf("%s.state.json", name))
 }
 
 // NewProcess creates a new process
 func NewProcess(command string, args []string, opts ProcessOptions) (*Process, error) {
 	// Create run directory if not exists
-	runDir := filepath.Join(os.TempDir(), "mcp-compose", "run")
-	logDir := filepath.Join(os.TempDir(), "mcp-compose", "logs")
+	runDir, logDir := processDirs()
 
 	if err := os.MkdirAll(runDir, constants.DefaultDirMode); err != nil {
 
@@ -45,11 +81,42 @@ func NewProcess(command string, args []string, opts ProcessOptions) (*Process, e
 	}
 
 	// Set up PID file and log file
-	pidFile := filepath.Join(runDir, fmt.Sprintf("%s.pid", opts.Name))
+	pidFile := pidFilePath(runDir, opts.Name)
 	logFile := filepath.Join(logDir, fmt.Sprintf("%s.log", opts.Name))
 
-	// Create command
-	cmd := exec.Command(command, args...)
+	if _, err := ParseRestartPolicy(opts.RestartPolicy); err != nil {
+
+		return nil, err
+	}
+
+	// Run the command under a supervisor, which is this binary in supervise-process mode
+	self, err := os.Executable()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to locate mcp-compose executable: %w", err)
+	}
+	supervisorFlags := supervisorArgs(opts, logFile)
+
+	// The hooks can hold credentials, so they are handed over in a private file rather
+	// than on the command line
+	notifyFile := filepath.Join(runDir, fmt.Sprintf("%s.notify.json", opts.Name))
+	if opts.Notifications.Empty() {
+		_ = os.Remove(notifyFile)
+	} else {
+		data, err := json.Marshal(opts.Notifications)
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to marshal notification hooks: %w", err)
+		}
+		if err := os.WriteFile(notifyFile, data, constants.SecretFileMode); err != nil {
+
+			return nil, fmt.Errorf("failed to write notification hooks: %w", err)
+		}
+		supervisorFlags = append(supervisorFlags, "--notify", notifyFile)
+	}
+	supervisorFlags = append(supervisorFlags, "--", command)
+	supervisorFlags = append(supervisorFlags, args...)
+	cmd := exec.Command(self, supervisorFlags...)
 
 	// Setup environment
 	env := os.Environ()
@@ -73,21 +140,51 @@ func NewProcess(command string, args []string, opts ProcessOptions) (*Process, e
 	cmd.Stdout = stdout
 	cmd.Stderr = stdout
 
-	// Set process group to detach from parent
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
-	}
+	// Detach from the parent so the supervisor outlives it
+	detach(cmd)
 
 	return &Process{
-		cmd:     cmd,
-		pidFile: pidFile,
-		logFile: logFile,
-		name:    opts.Name,
+		cmd:       cmd,
+		pidFile:   pidFile,
+		logFile:   logFile,
+		statePath: statePath(runDir, opts.Name),
+		name:      opts.Name,
 	}, nil
 }
 
+// supervisorArgs returns the supervise-process arguments for a process, up to its command
+func supervisorArgs(opts ProcessOptions, logFile string) []string {
+	args := []string{"supervise-process",
+		"--name", opts.Name,
+		"--restart", opts.RestartPolicy,
+		"--log", logFile}
+	args = append(args, opts.LogRotation.args()...)
+	args = append(args, opts.CrashLoop.args()...)
+	if opts.Stdio {
+		args = append(args, "--stdio")
+	}
+
+	return args
+}
+
+// ServiceSupervisorArgs returns the mcp-compose arguments that run a process under a
+// supervisor started by a service manager such as systemd rather than by NewProcess. The
+// supervisor records its own PID, so ls, logs and the proxy find it as usual. The
+// environment and working directory are left to the service manager, and notification
+// hooks are not passed on.
+func ServiceSupervisorArgs(command string, args []string, opts ProcessOptions) []string {
+	_, logDir := processDirs()
+	supervisor := supervisorArgs(opts, filepath.Join(logDir, fmt.Sprintf("%s.log", opts.Name)))
+	supervisor = append(supervisor, "--standalone", "--", command)
+
+	return append(supervisor, args...)
+}
+
 // Start starts the process
 func (p *Process) Start() error {
+	// Forget the state of any previous run
+	_ = os.Remove(p.statePath)
+
 	// Start the process
 	if err := p.cmd.Start(); err != nil {
 
@@ -119,6 +216,8 @@ func (p *Process) Start() error {
 
 // Stop stops the process
 func (p *Process) Stop() error {
+	defer func() { _ = os.Remove(p.statePath) }()
+
 	// Read PID from file
 	pidBytes, err := os.ReadFile(p.pidFile)
 	if err != nil {
@@ -132,22 +231,10 @@ func (p *Process) Stop() error {
 		return fmt.Errorf("invalid PID: %w", err)
 	}
 
-	// Try to find process
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		// Process doesn't exist, clean up PID file
-		if removeErr := os.Remove(p.pidFile); removeErr != nil {
-
-			return fmt.Errorf("process not found and failed to remove PID file: %v, remove error: %w", err, removeErr)
-		}
-
-		return nil
-	}
-
-	// Send SIGTERM
-	if err := process.Signal(syscall.SIGTERM); err != nil {
+	// Ask the supervisor to stop: SIGTERM, or its stop event on Windows
+	if err := signalStop(pid, p.name); err != nil {
 		// If process doesn't exist, clean up PID file
-		if err.Error() == "os: process already finished" {
+		if errors.Is(err, os.ErrProcessDone) {
 			if removeErr := os.Remove(p.pidFile); removeErr != nil {
 
 				return fmt.Errorf("process already finished and failed to remove PID file: %w", removeErr)
@@ -156,7 +243,7 @@ func (p *Process) Stop() error {
 			return nil
 		}
 
-		return fmt.Errorf("failed to send SIGTERM: %w", err)
+		return fmt.Errorf("failed to stop process: %w", err)
 	}
 
 	// Clean up PID file
@@ -170,38 +257,47 @@ func (p *Process) Stop() error {
 
 // IsRunning checks if the process is running
 func (p *Process) IsRunning() (bool, error) {
-	// Read PID from file
-	pidBytes, err := os.ReadFile(p.pidFile)
+	pid, err := p.PID()
 	if err != nil {
 
-		return false, fmt.Errorf("failed to read PID file: %w", err)
+		return false, err
 	}
 
-	pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
-	if err != nil {
+	if !processAlive(pid) {
 
-		return false, fmt.Errorf("invalid PID: %w", err)
+		return false, nil
 	}
 
-	// Try to find process
-	process, err := os.FindProcess(pid)
-	if err != nil {
+	// The supervisor is alive; the server only counts as running while its command is up
+	if state, err := p.ReadState(); err == nil && state.Status != StateRunning {
 
 		return false, nil
 	}
 
-	// Send signal 0 to check if process exists
-	err = process.Signal(syscall.Signal(0))
+	return true, nil
+}
+
+// PID returns the process ID of the process's supervisor, as recorded in its PID file
+func (p *Process) PID() (int, error) {
+	pidBytes, err := os.ReadFile(p.pidFile)
+	if err != nil {
+
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+	if err != nil {
+
+		return 0, fmt.Errorf("invalid PID: %w", err)
+	}
 
-	return err == nil, nil
+	return pid, nil
 }
 
 // FindProcess finds a process by name
 func FindProcess(name string) (*Process, error) {
-	runDir := filepath.Join(os.TempDir(), "mcp-compose", "run")
-	logDir := filepath.Join(os.TempDir(), "mcp-compose", "logs")
+	runDir, logDir := processDirs()
 
-	pidFile := filepath.Join(runDir, fmt.Sprintf("%s.pid", name))
+	pidFile := pidFilePath(runDir, name)
 	logFile := filepath.Join(logDir, fmt.Sprintf("%s.log", name))
 
 	// Check if PID file exists
@@ -211,32 +307,9 @@ func FindProcess(name string) (*Process, error) {
 	}
 
 	return &Process{
-		pidFile: pidFile,
-		logFile: logFile,
-		name:    name,
+		pidFile:   pidFile,
+		logFile:   logFile,
+		statePath: statePath(runDir, name),
+		name:      name,
 	}, nil
 }
-
-// ShowLogs shows logs for a process
-func (p *Process) ShowLogs(follow bool) error {
-	if _, err := os.Stat(p.logFile); err != nil {
-
-		return fmt.Errorf("log file not found: %w", err)
-	}
-
-	if follow {
-		// Use tail -f to show logs
-		cmd := exec.Command("tail", "-f", p.logFile)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
-		return cmd.Run()
-	} else {
-		// Use cat to show logs
-		cmd := exec.Command("cat", p.logFile)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
-		return cmd.Run()
-	}
-}
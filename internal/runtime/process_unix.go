@@ -0,0 +1,136 @@
+// internal/runtime/process_unix.go
+
+//go:build !windows
+
+package runtime
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+const defaultShell = "sh"
+
+// setScriptCommandLine is only needed where the command line is a single string
+func setScriptCommandLine(*exec.Cmd, string, string) {}
+
+// detach starts cmd in its own process group, so it outlives the terminal that started it
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// processGroup is a supervised command together with every process it started
+type processGroup struct {
+	pid int
+}
+
+// startGroup starts cmd as the leader of a new process group
+func startGroup(cmd *exec.Cmd) (*processGroup, error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+
+		return nil, err
+	}
+
+	return &processGroup{pid: cmd.Process.Pid}, nil
+}
+
+// interrupt asks the group to stop with SIGTERM
+func (g *processGroup) interrupt() error {
+
+	return syscall.Kill(-g.pid, syscall.SIGTERM)
+}
+
+// kill stops the group with SIGKILL
+func (g *processGroup) kill() error {
+
+	return syscall.Kill(-g.pid, syscall.SIGKILL)
+}
+
+// release is called once the command has exited. Children it left behind keep running,
+// as they would from a shell.
+func (g *processGroup) release() {}
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+
+		return false
+	}
+
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// signalStop asks the supervisor with pid to stop with SIGTERM. It returns os.ErrProcessDone
+// when the supervisor has already exited.
+func signalStop(pid int, _ string) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+
+		return err
+	}
+
+	return process.Signal(syscall.SIGTERM)
+}
+
+// notifyStop relays SIGTERM and SIGINT to stop until the returned function is called
+func notifyStop(stop chan<- os.Signal, _ string) (func(), error) {
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	return func() { signal.Stop(stop) }, nil
+}
+
+// stdioSocketPath is the Unix socket the supervisor of name serves the server's stdio on
+func stdioSocketPath(name string) string {
+	runDir, _ := processDirs()
+
+	return filepath.Join(runDir, name+".sock")
+}
+
+type socketListener struct {
+	net.Listener
+	path string
+}
+
+func (l *socketListener) Accept() (io.ReadWriteCloser, error) {
+
+	return l.Listener.Accept()
+}
+
+func (l *socketListener) Close() error {
+	defer func() { _ = os.Remove(l.path) }()
+
+	return l.Listener.Close()
+}
+
+// listenStdio serves a process's stdio on a Unix socket only its user can connect to
+func listenStdio(name string) (stdioListener, error) {
+	path := stdioSocketPath(name)
+	_ = os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+
+		return nil, err
+	}
+	if err := os.Chmod(path, constants.SecretFileMode); err != nil {
+		_ = listener.Close()
+
+		return nil, err
+	}
+
+	return &socketListener{Listener: listener, path: path}, nil
+}
+
+// DialStdio connects to the stdio of a process-based server whose supervisor serves it
+func DialStdio(name string) (io.ReadWriteCloser, error) {
+
+	return net.Dial("unix", stdioSocketPath(name))
+}
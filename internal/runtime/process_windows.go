@@ -0,0 +1,274 @@
+// internal/runtime/process_windows.go
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	defaultShell = "cmd"
+
+	// stillActive is the exit code GetExitCodeProcess reports for a running process
+	stillActive = 259
+	// pipeRejectRemoteClients keeps a named pipe off the network
+	pipeRejectRemoteClients = 0x8
+	// pipeSecurity lets only SYSTEM, administrators and the pipe's owner connect
+	pipeSecurity = "D:P(A;;GA;;;SY)(A;;GA;;;BA)(A;;GA;;;OW)"
+)
+
+// setScriptCommandLine hands a cmd script over verbatim: cmd does not parse its command line
+// the way the quoting exec applies to arguments expects
+func setScriptCommandLine(cmd *exec.Cmd, shell, script string) {
+	if shell != "cmd" {
+
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: fmt.Sprintf(`cmd /S /C "%s"`, script)}
+}
+
+// detach starts cmd without a console and in its own process group, so it outlives the
+// terminal that started it
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+		HideWindow:    true,
+	}
+}
+
+// processGroup is a supervised command together with every process it started, held in a
+// job object that terminates them all when it is closed
+type processGroup struct {
+	pid int
+	job windows.Handle
+}
+
+// startGroup starts cmd in a new process group and assigns it to a job object. Processes
+// the command starts before it is assigned, right after it starts, are not in the job.
+func startGroup(cmd *exec.Cmd) (*processGroup, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create job object: %w", err)
+	}
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		_ = windows.CloseHandle(job)
+
+		return nil, fmt.Errorf("failed to configure job object: %w", err)
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    true,
+	}
+	if err := cmd.Start(); err != nil {
+		_ = windows.CloseHandle(job)
+
+		return nil, err
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err == nil {
+		err = windows.AssignProcessToJobObject(job, process)
+		_ = windows.CloseHandle(process)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = windows.CloseHandle(job)
+
+		return nil, fmt.Errorf("failed to assign process to job object: %w", err)
+	}
+
+	return &processGroup{pid: cmd.Process.Pid, job: job}, nil
+}
+
+// interrupt sends CTRL_BREAK to the group. It fails when the supervisor has no console to
+// share with it, which is how mcp-compose starts supervisors.
+func (g *processGroup) interrupt() error {
+
+	return windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(g.pid))
+}
+
+// kill terminates every process in the job
+func (g *processGroup) kill() error {
+
+	return windows.TerminateJobObject(g.job, 1)
+}
+
+// release closes the job once the command has exited, which terminates the processes it
+// left behind
+func (g *processGroup) release() {
+	_ = windows.CloseHandle(g.job)
+}
+
+// processAlive reports whether a process with pid is still running
+func processAlive(pid int) bool {
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+
+		return false
+	}
+	defer func() { _ = windows.CloseHandle(process) }()
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(process, &code); err != nil {
+
+		return false
+	}
+
+	return code == stillActive
+}
+
+// stopEventName is the event the supervisor of name waits on to be told to stop, since
+// Windows has no signal to send a process without a console
+func stopEventName(name string) (*uint16, error) {
+
+	return windows.UTF16PtrFromString(`Local\mcp-compose-stop-` + name)
+}
+
+// signalStop asks the supervisor with pid to stop by setting its stop event. It returns
+// os.ErrProcessDone when the supervisor has already exited.
+func signalStop(pid int, name string) error {
+	eventName, err := stopEventName(name)
+	if err != nil {
+
+		return err
+	}
+	event, err := windows.OpenEvent(windows.EVENT_MODIFY_STATE, false, eventName)
+	if err != nil {
+		if !processAlive(pid) {
+
+			return os.ErrProcessDone
+		}
+
+		return fmt.Errorf("failed to open stop event of supervisor %d: %w", pid, err)
+	}
+	defer func() { _ = windows.CloseHandle(event) }()
+
+	return windows.SetEvent(event)
+}
+
+// notifyStop relays Ctrl+C and the supervisor's stop event to stop, the event as SIGTERM,
+// until the returned function is called
+func notifyStop(stop chan<- os.Signal, name string) (func(), error) {
+	eventName, err := stopEventName(name)
+	if err != nil {
+
+		return nil, err
+	}
+	event, err := windows.CreateEvent(nil, 1, 0, eventName)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create stop event: %w", err)
+	}
+	signal.Notify(stop, os.Interrupt)
+	go func() {
+		if result, _ := windows.WaitForSingleObject(event, windows.INFINITE); result == windows.WAIT_OBJECT_0 {
+			stop <- syscall.SIGTERM
+		}
+	}()
+
+	return func() {
+		signal.Stop(stop)
+		_ = windows.CloseHandle(event)
+	}, nil
+}
+
+// stdioPipePath is the named pipe the supervisor of name serves the server's stdio on
+func stdioPipePath(name string) string {
+
+	return `\\.\pipe\` + name
+}
+
+// pipeListener accepts connections on a named pipe, one pipe instance per connection
+type pipeListener struct {
+	path     string
+	security *windows.SECURITY_DESCRIPTOR
+	mu       sync.Mutex
+	closed   bool
+}
+
+func (l *pipeListener) Accept() (io.ReadWriteCloser, error) {
+	path, err := windows.UTF16PtrFromString(l.path)
+	if err != nil {
+
+		return nil, err
+	}
+	attributes := &windows.SecurityAttributes{SecurityDescriptor: l.security}
+	attributes.Length = uint32(unsafe.Sizeof(*attributes))
+	handle, err := windows.CreateNamedPipe(path,
+		windows.PIPE_ACCESS_DUPLEX,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT|pipeRejectRemoteClients,
+		windows.PIPE_UNLIMITED_INSTANCES, constants.STDIOBufferSize, constants.STDIOBufferSize, 0, attributes)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create named pipe %s: %w", l.path, err)
+	}
+	if err := windows.ConnectNamedPipe(handle, nil); err != nil && !errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+		_ = windows.CloseHandle(handle)
+
+		return nil, fmt.Errorf("failed to accept on named pipe %s: %w", l.path, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		_ = windows.CloseHandle(handle)
+
+		return nil, net.ErrClosed
+	}
+
+	return os.NewFile(uintptr(handle), l.path), nil
+}
+
+// Close stops the listener, connecting to it once to release a pending Accept
+func (l *pipeListener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	if conn, err := os.OpenFile(l.path, os.O_RDWR, 0); err == nil {
+		_ = conn.Close()
+	}
+
+	return nil
+}
+
+// listenStdio serves a process's stdio on a local named pipe only its user can connect to
+func listenStdio(name string) (stdioListener, error) {
+	security, err := windows.SecurityDescriptorFromString(pipeSecurity)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to build named pipe security: %w", err)
+	}
+
+	return &pipeListener{path: stdioPipePath(name), security: security}, nil
+}
+
+// DialStdio connects to the stdio of a process-based server whose supervisor serves it
+func DialStdio(name string) (io.ReadWriteCloser, error) {
+
+	return os.OpenFile(stdioPipePath(name), os.O_RDWR, 0)
+}
@@ -0,0 +1,35 @@
+// internal/runtime/shell.go
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// shellArgs is how each of config.HookShells is told to run a script
+var shellArgs = map[string][]string{
+	"sh":         {"sh", "-c"},
+	"bash":       {"bash", "-c"},
+	"cmd":        {"cmd", "/S", "/C"},
+	"powershell": {"powershell", "-NoProfile", "-NonInteractive", "-Command"},
+	"pwsh":       {"pwsh", "-NoProfile", "-NonInteractive", "-Command"},
+}
+
+// ShellCommand returns a command that runs script under shell, or under the host's default
+// shell when shell is empty: sh, or cmd on Windows
+func ShellCommand(ctx context.Context, shell, script string) (*exec.Cmd, error) {
+	if shell == "" {
+		shell = defaultShell
+	}
+	args, ok := shellArgs[shell]
+	if !ok {
+
+		return nil, fmt.Errorf("unknown shell '%s'", shell)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:len(args):len(args)], script)...)
+	setScriptCommandLine(cmd, shell, script)
+
+	return cmd, nil
+}
@@ -0,0 +1,129 @@
+// internal/runtime/stdio.go
+package runtime
+
+import (
+	"io"
+	"sync"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// stdioListener accepts connections to a supervised process's stdio: on a Unix socket, or
+// on a named pipe on Windows
+type stdioListener interface {
+	Accept() (io.ReadWriteCloser, error)
+	Close() error
+}
+
+// stdioBridge connects the stdin and stdout of the supervised process to one client of its
+// stdio endpoint at a time. A new client replaces the previous one, and the client is
+// disconnected when the process exits, so it sees the session end. While no client is
+// connected the process's output goes to its log.
+type stdioBridge struct {
+	listener stdioListener
+	mu       sync.Mutex
+	client   io.ReadWriteCloser
+	stdin    io.Writer
+	log      io.Writer
+}
+
+func newStdioBridge(name string) (*stdioBridge, error) {
+	listener, err := listenStdio(name)
+	if err != nil {
+
+		return nil, err
+	}
+	bridge := &stdioBridge{listener: listener}
+	go bridge.serve()
+
+	return bridge, nil
+}
+
+func (b *stdioBridge) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+
+			return
+		}
+		b.mu.Lock()
+		if b.client != nil {
+			_ = b.client.Close()
+		}
+		b.client = conn
+		b.mu.Unlock()
+		go b.forward(conn)
+	}
+}
+
+// forward copies what a client writes to the process's stdin
+func (b *stdioBridge) forward(conn io.ReadWriteCloser) {
+	buf := make([]byte, constants.STDIOBufferSize)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			b.mu.Lock()
+			stdin := b.stdin
+			if b.client != conn {
+				stdin = nil
+			}
+			b.mu.Unlock()
+			if stdin != nil {
+				_, _ = stdin.Write(buf[:n])
+			}
+		}
+		if err != nil {
+			b.mu.Lock()
+			if b.client == conn {
+				b.client = nil
+			}
+			b.mu.Unlock()
+			_ = conn.Close()
+
+			return
+		}
+	}
+}
+
+// attach connects a newly started process
+func (b *stdioBridge) attach(stdin io.Writer, log io.Writer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stdin = stdin
+	b.log = log
+}
+
+// detach disconnects the process once it has exited, and with it the client
+func (b *stdioBridge) detach() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stdin = nil
+	if b.client != nil {
+		_ = b.client.Close()
+		b.client = nil
+	}
+}
+
+// Write passes the process's stdout to the client, or to the log without one
+func (b *stdioBridge) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	client, log := b.client, b.log
+	b.mu.Unlock()
+	if client != nil {
+		if _, err := client.Write(p); err == nil {
+
+			return len(p), nil
+		}
+	}
+	if log != nil {
+
+		return log.Write(p)
+	}
+
+	return len(p), nil
+}
+
+func (b *stdioBridge) close() {
+	_ = b.listener.Close()
+	b.detach()
+}
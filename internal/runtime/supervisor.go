@@ -0,0 +1,428 @@
+// internal/runtime/supervisor.go
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/notify"
+)
+
+// Supervisor states reported through the state file
+const (
+	StateRunning    = "running"
+	StateRestarting = "restarting"
+	StateCrashLoop  = "crash-loop"
+	StateBackoff    = "backoff"
+	StateExited     = "exited"
+)
+
+// RestartPolicy decides whether a supervised process is started again after it exits
+type RestartPolicy struct {
+	Mode       string // "no", "on-failure", "always" or "unless-stopped"
+	MaxRetries int    // on-failure only; zero means unlimited
+}
+
+// ParseRestartPolicy parses the docker-style restart values: no, on-failure[:max], always, unless-stopped
+func ParseRestartPolicy(value string) (RestartPolicy, error) {
+	mode, maxRetries, hasMax := strings.Cut(value, ":")
+	switch mode {
+	case "", "no":
+
+		return RestartPolicy{Mode: "no"}, nil
+	case "always", "unless-stopped":
+		if hasMax {
+
+			return RestartPolicy{}, fmt.Errorf("restart policy '%s' does not take a retry count", mode)
+		}
+
+		return RestartPolicy{Mode: mode}, nil
+	case "on-failure":
+		policy := RestartPolicy{Mode: mode}
+		if hasMax {
+			n, err := strconv.Atoi(maxRetries)
+			if err != nil || n < 0 {
+
+				return RestartPolicy{}, fmt.Errorf("invalid retry count in restart policy '%s'", value)
+			}
+			policy.MaxRetries = n
+		}
+
+		return policy, nil
+	}
+
+	return RestartPolicy{}, fmt.Errorf("unknown restart policy '%s'", value)
+}
+
+// shouldRestart reports whether a process that exited with exitCode is started again
+func (p RestartPolicy) shouldRestart(exitCode, restarts int) bool {
+	switch p.Mode {
+	case "always", "unless-stopped":
+
+		return true
+	case "on-failure":
+
+		return exitCode != 0 && (p.MaxRetries == 0 || restarts < p.MaxRetries)
+	}
+
+	return false
+}
+
+// SupervisorState is what a supervisor records about its process for ls, top and up --wait
+type SupervisorState struct {
+	SupervisorPID int        `json:"supervisorPid"`
+	ChildPID      int        `json:"childPid,omitempty"`
+	Policy        string     `json:"policy"`
+	Status        string     `json:"status"`
+	Restarts      int        `json:"restarts"`
+	LastExitCode  int        `json:"lastExitCode"`
+	LastExitAt    *time.Time `json:"lastExitAt,omitempty"`
+	StartedAt     time.Time  `json:"startedAt"`
+	BackoffUntil  *time.Time `json:"backoffUntil,omitempty"`
+}
+
+// SupervisorOptions describes the process a supervisor keeps running
+type SupervisorOptions struct {
+	Name          string
+	Command       string
+	Args          []string
+	RestartPolicy string
+	LogFile       string
+	LogRotation   LogRotation
+	CrashLoop     CrashLoopLimits
+	NotifyFile    string // JSON notification hooks told when the process is backed off
+	Stdio         bool   // serve the process's stdin and stdout for DialStdio instead of logging stdout
+	Standalone    bool   // started by a service manager: create the directories and record its own PID
+}
+
+// RunSupervisor runs a process in the foreground, restarting it according to its restart
+// policy with exponential backoff, until the process is done or the supervisor is told to stop.
+// mcp-compose starts one detached supervisor per process-based server.
+func RunSupervisor(opts SupervisorOptions) error {
+	policy, err := ParseRestartPolicy(opts.RestartPolicy)
+	if err != nil {
+
+		return err
+	}
+
+	runDir, _ := processDirs()
+	pidFile := pidFilePath(runDir, opts.Name)
+	if opts.Standalone {
+		if err := recordSupervisorPID(runDir, pidFile, opts.LogFile); err != nil {
+
+			return err
+		}
+		defer removeSupervisorPID(pidFile)
+	}
+
+	logFile, err := openRotatingLog(opts.LogFile, opts.LogRotation)
+	if err != nil {
+
+		return err
+	}
+	defer logFile.close()
+
+	logf := func(format string, args ...interface{}) {
+		logFile.write(StreamSupervisor, "[supervisor] "+fmt.Sprintf(format, args...))
+	}
+
+	s := &supervisor{
+		pidFile:   pidFile,
+		statePath: statePath(runDir, opts.Name),
+		state: SupervisorState{
+			SupervisorPID: os.Getpid(),
+			Policy:        policy.Mode,
+		},
+	}
+	if policy.Mode == "on-failure" && policy.MaxRetries > 0 {
+		s.state.Policy = fmt.Sprintf("on-failure:%d", policy.MaxRetries)
+	}
+
+	stop := make(chan os.Signal, 1)
+	stopNotify, err := notifyStop(stop, opts.Name)
+	if err != nil {
+
+		return err
+	}
+	defer stopNotify()
+
+	var bridge *stdioBridge
+	if opts.Stdio {
+		if bridge, err = newStdioBridge(opts.Name); err != nil {
+
+			return fmt.Errorf("failed to serve stdio: %w", err)
+		}
+		defer bridge.close()
+	}
+
+	delay := constants.ProcessRestartInitialDelay
+	quickFailures := 0
+	crashLoop := NewCrashLoopDetector(opts.CrashLoop)
+	for {
+		stdout := &streamWriter{log: logFile, stream: StreamStdout}
+		stderr := &streamWriter{log: logFile, stream: StreamStderr}
+		cmd := exec.Command(opts.Command, opts.Args...)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if bridge != nil {
+			stdin, err := cmd.StdinPipe()
+			if err != nil {
+
+				return fmt.Errorf("failed to create stdin pipe: %w", err)
+			}
+			bridge.attach(stdin, stdout)
+			cmd.Stdout = bridge
+		}
+		// Don't wait on output held open by children that outlive the process
+		cmd.WaitDelay = constants.ProcessStopTimeout
+
+		startedAt := time.Now()
+		group, err := startGroup(cmd)
+		if err != nil {
+			logf("failed to start %s: %v", opts.Command, err)
+			s.state.Status = StateExited
+			s.state.LastExitCode = -1
+			s.state.LastExitAt = timePtr(time.Now())
+			s.save()
+
+			return fmt.Errorf("failed to start process: %w", err)
+		}
+
+		if s.state.Status != StateCrashLoop {
+			s.state.Status = StateRunning
+		}
+		s.state.ChildPID = cmd.Process.Pid
+		s.state.StartedAt = startedAt
+		s.save()
+		logf("started %s (pid %d)", opts.Command, cmd.Process.Pid)
+
+		exited := make(chan error, 1)
+		go func() {
+			err := cmd.Wait()
+			if bridge != nil {
+				bridge.detach()
+			}
+			stdout.flush()
+			stderr.flush()
+			exited <- err
+		}()
+
+		var waitErr error
+		select {
+		case waitErr = <-exited:
+			group.release()
+		case sig := <-stop:
+			logf("received %s, stopping pid %d", sig, cmd.Process.Pid)
+			terminate(group, exited)
+
+			return nil
+		}
+
+		exitCode := exitCodeOf(waitErr)
+		s.state.ChildPID = 0
+		s.state.LastExitCode = exitCode
+		s.state.LastExitAt = timePtr(time.Now())
+
+		if !policy.shouldRestart(exitCode, s.state.Restarts) {
+			logf("process exited with code %d; restart policy '%s' does not restart it", exitCode, s.state.Policy)
+			s.state.Status = StateExited
+			s.save()
+
+			return nil
+		}
+
+		// Back off while the process keeps failing quickly; a stable run resets the delay
+		if time.Since(startedAt) >= constants.ProcessStableRuntime {
+			delay = constants.ProcessRestartInitialDelay
+			quickFailures = 0
+		} else {
+			quickFailures++
+		}
+		if quickFailures >= constants.ProcessCrashLoopThreshold {
+			s.state.Status = StateCrashLoop
+		} else {
+			s.state.Status = StateRestarting
+		}
+		s.state.Restarts++
+
+		// Restarting more often than the crash-loop limits allow backs the process off for a
+		// while instead of hot-looping
+		wait := delay
+		if crashLoop.Record(time.Now()) {
+			wait = opts.CrashLoop.Backoff
+			s.state.Status = StateBackoff
+			s.state.BackoffUntil = timePtr(time.Now().Add(wait))
+			message := fmt.Sprintf("Server '%s' restarted %d times within %s (last exit code %d); waiting %s before starting it again",
+				serverName(opts.Name), crashLoop.Count(), opts.CrashLoop.Window, exitCode, wait)
+			logf("%s", message)
+			notifyCrashLoop(opts, message, logf)
+			crashLoop.Reset()
+		}
+		s.save()
+		logf("process exited with code %d; restart %d in %s (%s)", exitCode, s.state.Restarts, wait, s.state.Status)
+
+		select {
+		case <-time.After(wait):
+		case sig := <-stop:
+			logf("received %s while waiting to restart", sig)
+
+			return nil
+		}
+
+		s.state.BackoffUntil = nil
+		if s.state.Status == StateBackoff {
+			delay = constants.ProcessRestartInitialDelay
+			quickFailures = 0
+
+			continue
+		}
+		delay *= 2
+		if delay > constants.ProcessRestartMaxDelay {
+			delay = constants.ProcessRestartMaxDelay
+		}
+	}
+}
+
+// recordSupervisorPID writes the PID file NewProcess would have written for a supervisor
+// that a service manager started
+func recordSupervisorPID(runDir, pidFile, logFile string) error {
+	for _, dir := range []string{runDir, filepath.Dir(logFile)} {
+		if err := os.MkdirAll(dir, constants.DefaultDirMode); err != nil {
+
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+
+	return nil
+}
+
+// removeSupervisorPID removes the PID file unless another supervisor has taken it over
+func removeSupervisorPID(pidFile string) {
+	pidBytes, err := os.ReadFile(pidFile)
+	if err == nil && strings.TrimSpace(string(pidBytes)) == strconv.Itoa(os.Getpid()) {
+		_ = os.Remove(pidFile)
+	}
+}
+
+// serverName returns the compose server name of a process identifier
+func serverName(identifier string) string {
+
+	return config.ServerForContainer(identifier)
+}
+
+// notifyCrashLoop sends the crash_loop event to the hooks in the notify file, if any
+func notifyCrashLoop(opts SupervisorOptions, message string, logf func(string, ...interface{})) {
+	if opts.NotifyFile == "" {
+
+		return
+	}
+	data, err := os.ReadFile(opts.NotifyFile)
+	if err != nil {
+		logf("failed to read notification hooks: %v", err)
+
+		return
+	}
+	var hooks config.NotificationsConfig
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		logf("failed to parse notification hooks: %v", err)
+
+		return
+	}
+	event := notify.Event{Type: "crash_loop", Server: serverName(opts.Name), Message: message}
+	if err := notify.Send(hooks, event); err != nil {
+		logf("failed to send crash-loop notification: %v", err)
+	}
+}
+
+type supervisor struct {
+	pidFile   string
+	statePath string
+	state     SupervisorState
+}
+
+// save writes the state file unless this supervisor has been replaced or stopped, which
+// removes or rewrites its PID file
+func (s *supervisor) save() {
+	pidBytes, err := os.ReadFile(s.pidFile)
+	if err != nil || strings.TrimSpace(string(pidBytes)) != strconv.Itoa(s.state.SupervisorPID) {
+
+		return
+	}
+	data, err := json.Marshal(s.state)
+	if err != nil {
+
+		return
+	}
+	tmp := s.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, constants.DefaultFileMode); err != nil {
+
+		return
+	}
+	_ = os.Rename(tmp, s.statePath)
+}
+
+// terminate asks the process group to stop, then kills it after the stop timeout, or right
+// away when it can't be asked
+func terminate(group *processGroup, exited <-chan error) {
+	defer group.release()
+	if err := group.interrupt(); err != nil {
+		_ = group.kill()
+		<-exited
+
+		return
+	}
+	select {
+	case <-exited:
+	case <-time.After(constants.ProcessStopTimeout):
+		_ = group.kill()
+		<-exited
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+
+	return &t
+}
+
+func exitCodeOf(err error) int {
+	if err == nil {
+
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
+// ReadState returns what the process's supervisor last recorded
+func (p *Process) ReadState() (*SupervisorState, error) {
+	data, err := os.ReadFile(p.statePath)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read supervisor state: %w", err)
+	}
+	var state SupervisorState
+	if err := json.Unmarshal(data, &state); err != nil {
+
+		return nil, fmt.Errorf("failed to parse supervisor state: %w", err)
+	}
+
+	return &state, nil
+}
@@ -0,0 +1,273 @@
+// internal/sampling/provider.go
+package sampling
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// Provider answers sampling requests through an OpenAI-compatible chat completions API,
+// which OpenAI, OpenRouter and Ollama all serve
+type Provider struct {
+	name      string
+	endpoint  string
+	apiKey    string
+	model     string
+	maxTokens int
+	client    *http.Client
+}
+
+// NewProvider creates the provider the sampling broker is configured with
+func NewProvider(broker config.SamplingBrokerConfig) (*Provider, error) {
+	endpoint := broker.Endpoint()
+	if endpoint == "" {
+
+		return nil, fmt.Errorf("unknown sampling provider '%s'", broker.Provider)
+	}
+	timeout := constants.SamplingProviderTimeout
+	if broker.Timeout != "" {
+		parsed, err := time.ParseDuration(broker.Timeout)
+		if err != nil {
+
+			return nil, fmt.Errorf("invalid sampling timeout '%s': %w", broker.Timeout, err)
+		}
+		timeout = parsed
+	}
+
+	return &Provider{
+		name:      broker.Provider,
+		endpoint:  endpoint,
+		apiKey:    broker.APIKey,
+		model:     broker.Model,
+		maxTokens: broker.MaxTokens,
+		client:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type chatMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"` // a string, or parts when the message holds an image
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	Stop        []string      `json:"stop,omitempty"`
+}
+
+type chatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// HandleSamplingRequest sends the request's messages to the provider and returns its answer
+func (p *Provider) HandleSamplingRequest(request *protocol.SamplingRequest) (*protocol.SamplingResponse, error) {
+	body := chatRequest{
+		Model:     request.Model,
+		MaxTokens: p.tokenLimit(request.MaxTokens),
+		Stop:      request.StopSequence,
+	}
+	if body.Model == "" {
+		body.Model = p.model
+	}
+	if request.Temperature > 0 {
+		temperature := request.Temperature
+		body.Temperature = &temperature
+	}
+	if request.SystemPrompt != "" {
+		body.Messages = append(body.Messages, chatMessage{Role: "system", Content: request.SystemPrompt})
+	}
+	for _, message := range request.Messages {
+		content, err := chatContent(message.Content)
+		if err != nil {
+
+			return nil, err
+		}
+		body.Messages = append(body.Messages, chatMessage{Role: message.Role, Content: content})
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to encode chat request: %w", err)
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, p.endpoint+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create chat request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to reach %s: %w", p.name, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, constants.SamplingMaxResponseSize))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read %s response: %w", p.name, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail := strings.TrimSpace(string(data))
+		if len(detail) > 300 {
+			detail = detail[:300] + "..."
+		}
+
+		return nil, fmt.Errorf("%s returned HTTP %d: %s", p.name, resp.StatusCode, detail)
+	}
+
+	var completion chatResponse
+	if err := json.Unmarshal(data, &completion); err != nil {
+
+		return nil, fmt.Errorf("unexpected %s response: %w", p.name, err)
+	}
+	if len(completion.Choices) == 0 {
+
+		return nil, fmt.Errorf("%s returned no choices", p.name)
+	}
+	model := completion.Model
+	if model == "" {
+		model = body.Model
+	}
+
+	return &protocol.SamplingResponse{
+		Content:    protocol.SamplingContent{Type: "text", Text: completion.Choices[0].Message.Content},
+		Model:      model,
+		StopReason: stopReason(completion.Choices[0].FinishReason),
+		Usage: protocol.SamplingUsage{
+			InputTokens:  completion.Usage.PromptTokens,
+			OutputTokens: completion.Usage.CompletionTokens,
+			TotalTokens:  completion.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// GetSupportedModels returns the provider's default model
+func (p *Provider) GetSupportedModels() []string {
+
+	return []string{p.model}
+}
+
+// GetCapabilities describes what the provider accepts
+func (p *Provider) GetCapabilities() protocol.SamplingCapabilities {
+
+	return protocol.SamplingCapabilities{
+		Models:         p.GetSupportedModels(),
+		MaxTokens:      p.maxTokens,
+		SupportsImages: true,
+	}
+}
+
+// tokenLimit applies the default to requests without a limit and caps the rest at the
+// configured maximum
+func (p *Provider) tokenLimit(requested int) int {
+	if requested <= 0 {
+		requested = constants.SamplingDefaultMaxTokens
+	}
+	if p.maxTokens > 0 && requested > p.maxTokens {
+
+		return p.maxTokens
+	}
+
+	return requested
+}
+
+func chatContent(content protocol.SamplingContent) (interface{}, error) {
+	switch content.Type {
+	case "", "text":
+
+		return content.Text, nil
+	case "image":
+
+		return []map[string]interface{}{{
+			"type":      "image_url",
+			"image_url": map[string]string{"url": fmt.Sprintf("data:%s;base64,%s", content.MimeType, content.ImageData)},
+		}}, nil
+	default:
+
+		return nil, fmt.Errorf("sampling content of type '%s' is not supported", content.Type)
+	}
+}
+
+// stopReason maps OpenAI finish reasons to the MCP names
+func stopReason(finishReason string) string {
+	switch finishReason {
+	case "stop":
+
+		return "endTurn"
+	case "length":
+
+		return "maxTokens"
+	default:
+
+		return finishReason
+	}
+}
+
+// ChooseModel picks the model for a request: the first allowed model a hint names, else
+// the configured default when allowed, else the first allowed model. Without an allowed
+// list, the server's own sampling models stand in for it and the default is always allowed.
+func ChooseModel(hints []protocol.ModelHint, allowed []string, serverModels []config.ModelConfig, fallback string) string {
+	candidates := allowed
+	if len(candidates) == 0 {
+		for _, model := range serverModels {
+			candidates = append(candidates, model.Name)
+		}
+	}
+	for _, hint := range hints {
+		if hint.Name == "" {
+			continue
+		}
+		for _, candidate := range candidates {
+			if strings.Contains(candidate, hint.Name) {
+
+				return candidate
+			}
+		}
+	}
+
+	if len(allowed) == 0 {
+		if len(serverModels) > 0 && serverModels[0].Name != "" {
+
+			return serverModels[0].Name
+		}
+
+		return fallback
+	}
+	for _, model := range allowed {
+		if model == fallback {
+
+			return fallback
+		}
+	}
+
+	return allowed[0]
+}
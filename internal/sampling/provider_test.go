@@ -0,0 +1,99 @@
+package sampling
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestChooseModel(t *testing.T) {
+	hints := []protocol.ModelHint{{Name: "sonnet"}}
+	serverModels := []config.ModelConfig{{Name: "llama3"}, {Name: "claude-3-5-sonnet"}}
+
+	tests := []struct {
+		name         string
+		hints        []protocol.ModelHint
+		allowed      []string
+		serverModels []config.ModelConfig
+		want         string
+	}{
+		{"default", nil, nil, nil, "gpt-4o-mini"},
+		{"hint matches a server model", hints, nil, serverModels, "claude-3-5-sonnet"},
+		{"first server model without a matching hint", []protocol.ModelHint{{Name: "opus"}}, nil, serverModels, "llama3"},
+		{"hint matches an allowed model", hints, []string{"gpt-4o", "anthropic/claude-3.5-sonnet"}, serverModels, "anthropic/claude-3.5-sonnet"},
+		{"allowed default", nil, []string{"gpt-4o", "gpt-4o-mini"}, nil, "gpt-4o-mini"},
+		{"default not allowed", nil, []string{"gpt-4o"}, nil, "gpt-4o"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChooseModel(tt.hints, tt.allowed, tt.serverModels, "gpt-4o-mini"); got != tt.want {
+				t.Errorf("ChooseModel() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderHandleSamplingRequest(t *testing.T) {
+	var received chatRequest
+	var rawMessages []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			chatRequest
+			Messages []map[string]interface{} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		received, rawMessages = body.chatRequest, body.Messages
+		if body.Model == "broken" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":"rate limited"}`))
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"done"},"finish_reason":"length"}],"usage":{"prompt_tokens":3,"completion_tokens":5,"total_tokens":8}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(config.SamplingBrokerConfig{Provider: "ollama", BaseURL: server.URL + "/", Model: "llama3", MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	response, err := provider.HandleSamplingRequest(&protocol.SamplingRequest{
+		SystemPrompt: "Be brief",
+		MaxTokens:    500,
+		Messages: []protocol.SamplingMessage{
+			{Role: "user", Content: protocol.SamplingContent{Type: "image", ImageData: "aGk=", MimeType: "image/png"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleSamplingRequest: %v", err)
+	}
+	if response.Content.Text != "done" || response.Model != "llama3" || response.StopReason != "maxTokens" || response.Usage.TotalTokens != 8 {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if received.Model != "llama3" || received.MaxTokens != 100 || len(rawMessages) != 2 || rawMessages[0]["role"] != "system" {
+		t.Errorf("unexpected chat request %+v %+v", received, rawMessages)
+	}
+	if parts, _ := rawMessages[1]["content"].([]interface{}); len(parts) != 1 || !strings.Contains(mustString(parts[0]), "data:image/png;base64,aGk=") {
+		t.Errorf("image content should be sent as a data URL, got %+v", rawMessages[1])
+	}
+
+	_, err = provider.HandleSamplingRequest(&protocol.SamplingRequest{Model: "broken", Messages: []protocol.SamplingMessage{{Role: "user", Content: protocol.SamplingContent{Text: "hi"}}}})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 429") {
+		t.Errorf("expected the provider's status in the error, got %v", err)
+	}
+
+	if _, err := NewProvider(config.SamplingBrokerConfig{Provider: "unknown"}); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func mustString(v interface{}) string {
+	data, _ := json.Marshal(v)
+
+	return string(data)
+}
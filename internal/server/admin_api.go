@@ -0,0 +1,499 @@
+// internal/server/admin_api.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+	"github.com/phildougherty/mcp-compose/internal/logfilter"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// Scopes the admin API requires of OAuth tokens and trusted-header identities. The proxy API
+// key carries both; write also grants read.
+const (
+	AdminReadScope  = "mcp:admin:read"
+	AdminWriteScope = "mcp:admin:write"
+)
+
+// adminAPIPath is the versioned admin API. Its paths and response shapes only change in
+// backwards compatible ways; breaking changes get a new version.
+const (
+	adminAPIVersion = "v1"
+	adminAPIPath    = "/api/" + adminAPIVersion
+)
+
+// adminServer is a server as the admin API reports it
+type adminServer struct {
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	Health          string `json:"health,omitempty"`
+	HealthError     string `json:"healthError,omitempty"`
+	LastHealthCheck string `json:"lastHealthCheck,omitempty"`
+	Protocol        string `json:"protocol"`
+	Container       bool   `json:"container"`
+	Image           string `json:"image,omitempty"`
+	Command         string `json:"command,omitempty"`
+	StartedAt       string `json:"startedAt,omitempty"`
+}
+
+// adminStatus summarizes the proxy for the admin API
+type adminStatus struct {
+	Version        string `json:"version"`
+	StartedAt      string `json:"startedAt"`
+	Uptime         string `json:"uptime"`
+	ProjectName    string `json:"projectName,omitempty"`
+	Servers        int    `json:"servers"`
+	RunningServers int    `json:"runningServers"`
+	MCPVersion     string `json:"mcpVersion"`
+}
+
+// adminRoute is one admin API endpoint. The routes serve requests and describe the API in
+// its OpenAPI document, so the two cannot drift apart.
+type adminRoute struct {
+	method   string
+	path     string // relative to adminAPIPath; {name} matches a configured server
+	summary  string
+	write    bool   // needs AdminWriteScope
+	response string // schema of a successful response
+	query    []adminQueryParam
+	handle   func(h *ProxyHandler, w http.ResponseWriter, r *http.Request, serverName string)
+}
+
+type adminQueryParam struct {
+	name        string
+	kind        string
+	description string
+}
+
+var adminRoutes = []adminRoute{
+	{method: http.MethodGet, path: "/status", summary: "Proxy status", response: "Status",
+		handle: (*ProxyHandler).handleAdminStatus},
+	{method: http.MethodGet, path: "/servers", summary: "List servers", response: "ServerList",
+		handle: (*ProxyHandler).handleAdminServers},
+	{method: http.MethodGet, path: "/servers/{name}", summary: "Show a server", response: "Server",
+		handle: (*ProxyHandler).handleAdminServer},
+	{method: http.MethodPost, path: "/servers/{name}/start", summary: "Start a server", write: true, response: "Server",
+		handle: adminServerAction("start")},
+	{method: http.MethodPost, path: "/servers/{name}/stop", summary: "Stop a server", write: true, response: "Server",
+		handle: adminServerAction("stop")},
+	{method: http.MethodPost, path: "/servers/{name}/restart", summary: "Restart a server", write: true, response: "Server",
+		handle: adminServerAction("restart")},
+	{method: http.MethodGet, path: "/servers/{name}/logs", summary: "Recent log lines of a server", response: "Logs",
+		query: []adminQueryParam{
+			{name: "tail", kind: "integer", description: fmt.Sprintf("Number of lines, default %d, at most %d", constants.AdminAPILogLines, constants.AdminAPIMaxLogLines)},
+			{name: "raw", kind: "boolean", description: "Skip the server's log_filters"},
+		},
+		handle: (*ProxyHandler).handleAdminLogs},
+	{method: http.MethodPost, path: "/reload", summary: "Reload the compose file and apply its changes", write: true, response: "Reload",
+		handle: (*ProxyHandler).handleAdminReload},
+}
+
+// resolveAdminRoute finds the route serving method and path, with the server it names.
+// On failure it returns the HTTP status to answer with.
+func (h *ProxyHandler) resolveAdminRoute(method, path string) (*adminRoute, string, int, error) {
+	rest := strings.TrimPrefix(path, adminAPIPath)
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+
+	var allowed []string
+	for i := range adminRoutes {
+		route := &adminRoutes[i]
+		pattern := strings.Split(strings.Trim(route.path, "/"), "/")
+		if len(pattern) != len(segments) {
+
+			continue
+		}
+		serverName, matched := "", true
+		for j, part := range pattern {
+			if part == "{name}" {
+				serverName = segments[j]
+			} else if part != segments[j] {
+				matched = false
+
+				break
+			}
+		}
+		if !matched {
+
+			continue
+		}
+		if route.method != method {
+			allowed = append(allowed, route.method)
+
+			continue
+		}
+		if serverName != "" {
+			if _, exists := h.Manager.GetServerInstance(serverName); !exists {
+
+				return nil, "", http.StatusNotFound, fmt.Errorf("server '%s' not found in configuration", serverName)
+			}
+		}
+
+		return route, serverName, 0, nil
+	}
+	if len(allowed) > 0 {
+
+		return nil, "", http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed on %s, use %s", method, path, strings.Join(allowed, " or "))
+	}
+
+	return nil, "", http.StatusNotFound, fmt.Errorf("unknown admin API endpoint %s", path)
+}
+
+// handleAdminAPI serves the versioned admin API, the stable surface for managing the
+// proxy's servers from scripts, orchestration tools and the dashboard
+func (h *ProxyHandler) handleAdminAPI(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// The API description is public so clients can be generated without credentials
+	if path == adminAPIPath+"/openapi.json" {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use GET")
+
+			return
+		}
+		_ = json.NewEncoder(w).Encode(adminOpenAPISpec())
+
+		return
+	}
+
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+	route, serverName, status, err := h.resolveAdminRoute(r.Method, path)
+	if err != nil {
+		writeAPIError(w, status, err.Error())
+
+		return
+	}
+	scope := AdminReadScope
+	if route.write {
+		scope = AdminWriteScope
+	}
+	if !h.scopeGranted(r, scope, AdminWriteScope) {
+		publishAuthDenied(r, serverName, "admin API scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", scope))
+
+		return
+	}
+
+	route.handle(h, w, r, serverName)
+}
+
+func (h *ProxyHandler) handleAdminStatus(w http.ResponseWriter, _ *http.Request, _ string) {
+	_ = json.NewEncoder(w).Encode(h.proxyStatus())
+}
+
+func (h *ProxyHandler) proxyStatus() adminStatus {
+	cfg := h.Manager.Config()
+	status := adminStatus{
+		Version:     adminAPIVersion,
+		StartedAt:   h.ProxyStarted.Format(time.RFC3339),
+		Uptime:      time.Since(h.ProxyStarted).Round(time.Second).String(),
+		ProjectName: config.GetProjectName(h.ConfigFile),
+		Servers:     len(cfg.Servers),
+		MCPVersion:  protocol.MCPVersion,
+	}
+	for name := range cfg.Servers {
+		if serverStatus, _ := h.Manager.GetServerStatus(name); serverStatus == "running" {
+			status.RunningServers++
+		}
+	}
+
+	return status
+}
+
+func (h *ProxyHandler) handleAdminServers(w http.ResponseWriter, _ *http.Request, _ string) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"servers": h.adminServers()})
+}
+
+// adminServers returns every configured server, sorted by name
+func (h *ProxyHandler) adminServers() []adminServer {
+	cfg := h.Manager.Config()
+	names := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	servers := make([]adminServer, 0, len(names))
+	for _, name := range names {
+		servers = append(servers, h.adminServerFor(name))
+	}
+
+	return servers
+}
+
+func (h *ProxyHandler) handleAdminServer(w http.ResponseWriter, _ *http.Request, serverName string) {
+	_ = json.NewEncoder(w).Encode(h.adminServerFor(serverName))
+}
+
+// adminServerAction returns the handler of a lifecycle action
+func adminServerAction(action string) func(*ProxyHandler, http.ResponseWriter, *http.Request, string) {
+
+	return func(h *ProxyHandler, w http.ResponseWriter, r *http.Request, serverName string) {
+		h.logger.Info("Admin API: %s server '%s' from %s", action, serverName, getClientIP(r))
+		if err := h.runServerAction(serverName, action); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to %s server '%s': %v", action, serverName, err))
+
+			return
+		}
+		_ = json.NewEncoder(w).Encode(h.adminServerFor(serverName))
+	}
+}
+
+func (h *ProxyHandler) handleAdminLogs(w http.ResponseWriter, r *http.Request, serverName string) {
+	tail := constants.AdminAPILogLines
+	if value := r.URL.Query().Get("tail"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > constants.AdminAPIMaxLogLines {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("tail must be between 1 and %d", constants.AdminAPIMaxLogLines))
+
+			return
+		}
+		tail = n
+	}
+
+	lines, err := h.serverLogLines(serverName, tail, r.URL.Query().Get("raw") == "true")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"server": serverName, "lines": lines})
+}
+
+// serverLogLines returns the last tail lines of a server's logs, after its log_filters unless raw
+func (h *ProxyHandler) serverLogLines(serverName string, tail int, raw bool) ([]string, error) {
+	lines, err := h.Manager.ServerLogs(serverName, tail)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read logs of server '%s': %w", serverName, err)
+	}
+	if !raw {
+		filter, err := logfilter.ForServer(h.Manager.Config(), serverName)
+		if err != nil {
+			h.logger.Warning("Ignoring log filters for %s: %v", serverName, err)
+		}
+		filtered := make([]string, 0, len(lines))
+		for _, line := range lines {
+			if line, _, keep := filter.Apply(line); keep {
+				filtered = append(filtered, line)
+			}
+		}
+		lines = filtered
+	}
+	if lines == nil {
+		lines = []string{}
+	}
+
+	return lines, nil
+}
+
+// handleAdminReload reads the compose file again and applies its changes, restarting only
+// the servers it changes
+func (h *ProxyHandler) handleAdminReload(w http.ResponseWriter, r *http.Request, _ string) {
+	content, err := os.ReadFile(h.ConfigFile)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to read config file: %v", err))
+
+		return
+	}
+	proposed, problems, err := h.parseProposedConfig(string(content))
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+	if len(problems) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  "the compose file is invalid",
+			"errors": problems,
+		})
+
+		return
+	}
+
+	changes, restarted, applyErr := h.applyConfig(proposed)
+	if restarted == nil {
+		restarted = []string{}
+	}
+	h.catalog.clear()
+	h.responses.clear()
+	events.Publish(events.Event{
+		Type:    events.ConfigReloaded,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("Config reloaded: %d added, %d removed, %d changed, %d restarted", len(changes.Added), len(changes.Removed), len(changes.Changed), len(restarted)),
+		Details: map[string]interface{}{"changes": changes, "restarted": restarted},
+	})
+
+	response := map[string]interface{}{
+		"changes":         changes,
+		"restarted":       restarted,
+		"restartRequired": changes.Global,
+	}
+	if applyErr != nil {
+		response["error"] = applyErr.Error()
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (h *ProxyHandler) adminServerFor(serverName string) adminServer {
+	server := adminServer{Name: serverName, Protocol: "stdio"}
+	status, err := h.Manager.GetServerStatus(serverName)
+	if err != nil {
+		status = "unknown"
+	}
+	server.Status = status
+
+	health, healthError, checkedAt := h.Manager.GetServerHealth(serverName)
+	server.Health, server.HealthError = health, healthError
+	if !checkedAt.IsZero() {
+		server.LastHealthCheck = checkedAt.Format(time.RFC3339)
+	}
+
+	if instance, exists := h.Manager.GetServerInstance(serverName); exists {
+		server.Container = instance.IsContainer
+		if instance.Config.Protocol != "" {
+			server.Protocol = instance.Config.Protocol
+		}
+		server.Image = instance.Config.Image
+		server.Command = strings.TrimSpace(strings.Join(append([]string{instance.Config.Command}, instance.Config.Args...), " "))
+		if !instance.StartTime.IsZero() && status == "running" {
+			server.StartedAt = instance.StartTime.Format(time.RFC3339)
+		}
+	}
+
+	return server
+}
+
+// adminOpenAPISpec describes the admin API as an OpenAPI 3.1 document
+func adminOpenAPISpec() map[string]interface{} {
+	str := map[string]interface{}{"type": "string"}
+	schemas := map[string]interface{}{
+		"Error": objectSchema(map[string]interface{}{"error": str}, "error"),
+		"Status": objectSchema(map[string]interface{}{
+			"version":        str,
+			"startedAt":      map[string]interface{}{"type": "string", "format": "date-time"},
+			"uptime":         str,
+			"projectName":    str,
+			"servers":        map[string]interface{}{"type": "integer"},
+			"runningServers": map[string]interface{}{"type": "integer"},
+			"mcpVersion":     str,
+		}, "version", "servers", "runningServers"),
+		"Server": objectSchema(map[string]interface{}{
+			"name":            str,
+			"status":          map[string]interface{}{"type": "string", "description": "running, stopped, exited or unknown"},
+			"health":          str,
+			"healthError":     str,
+			"lastHealthCheck": map[string]interface{}{"type": "string", "format": "date-time"},
+			"protocol":        map[string]interface{}{"type": "string", "enum": []string{"stdio", "http", "sse"}},
+			"container":       map[string]interface{}{"type": "boolean"},
+			"image":           str,
+			"command":         str,
+			"startedAt":       map[string]interface{}{"type": "string", "format": "date-time"},
+		}, "name", "status", "protocol", "container"),
+		"ServerList": objectSchema(map[string]interface{}{
+			"servers": map[string]interface{}{"type": "array", "items": schemaRef("Server")},
+		}, "servers"),
+		"Logs": objectSchema(map[string]interface{}{
+			"server": str,
+			"lines":  map[string]interface{}{"type": "array", "items": str},
+		}, "server", "lines"),
+		"Reload": objectSchema(map[string]interface{}{
+			"changes":         map[string]interface{}{"type": "object", "description": "Servers added, removed and changed"},
+			"restarted":       map[string]interface{}{"type": "array", "items": str},
+			"restartRequired": map[string]interface{}{"type": "boolean", "description": "Settings changed that only a proxy restart applies"},
+			"error":           str,
+		}, "changes", "restarted"),
+	}
+
+	errorResponse := func(description string) map[string]interface{} {
+
+		return map[string]interface{}{
+			"description": description,
+			"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": schemaRef("Error")}},
+		}
+	}
+
+	paths := make(map[string]interface{})
+	for _, route := range adminRoutes {
+		scope := AdminReadScope
+		if route.write {
+			scope = AdminWriteScope
+		}
+		operation := map[string]interface{}{
+			"summary":     route.summary,
+			"operationId": managementOperationID(route.method, route.path),
+			"tags":        []string{"admin"},
+			"security":    []map[string][]string{{"bearer": {scope}}},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": route.summary,
+					"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": schemaRef(route.response)}},
+				},
+				"401": errorResponse("Missing or invalid credentials"),
+				"403": errorResponse("Scope " + scope + " not granted"),
+			},
+		}
+		var parameters []map[string]interface{}
+		if strings.Contains(route.path, "{name}") {
+			parameters = append(parameters, map[string]interface{}{
+				"name": "name", "in": "path", "required": true, "schema": str, "description": "Server name",
+			})
+			operation["responses"].(map[string]interface{})["404"] = errorResponse("Server not found")
+		}
+		for _, param := range route.query {
+			parameters = append(parameters, map[string]interface{}{
+				"name": param.name, "in": "query", "schema": map[string]interface{}{"type": param.kind}, "description": param.description,
+			})
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+
+		path := adminAPIPath + route.path
+		item, _ := paths[path].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[path] = item
+		}
+		item[strings.ToLower(route.method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "mcp-compose admin API",
+			"description": "Manage the servers behind an mcp-compose proxy. Read endpoints need " + AdminReadScope + " and changes need " + AdminWriteScope + "; the proxy API key grants both.",
+			"version":     adminAPIVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer", "description": "The proxy API key or an OAuth access token"},
+			},
+		},
+	}
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+
+	return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+}
+
+func schemaRef(name string) map[string]interface{} {
+
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestResolveAdminRoute(t *testing.T) {
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{"files": {}}}
+	h := &ProxyHandler{Manager: newTestManager(cfg, nil, map[string]*ServerInstance{"files": {Name: "files"}})}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		route      string
+		server     string
+		write      bool
+		expectCode int
+	}{
+		{name: "status", method: http.MethodGet, path: "/api/v1/status", route: "/status"},
+		{name: "list servers", method: http.MethodGet, path: "/api/v1/servers", route: "/servers"},
+		{name: "show server", method: http.MethodGet, path: "/api/v1/servers/files", route: "/servers/{name}", server: "files"},
+		{name: "restart", method: http.MethodPost, path: "/api/v1/servers/files/restart", route: "/servers/{name}/restart", server: "files", write: true},
+		{name: "logs", method: http.MethodGet, path: "/api/v1/servers/files/logs", route: "/servers/{name}/logs", server: "files"},
+		{name: "reload", method: http.MethodPost, path: "/api/v1/reload", route: "/reload", write: true},
+		{name: "unknown server", method: http.MethodPost, path: "/api/v1/servers/nope/start", expectCode: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodGet, path: "/api/v1/servers/files/stop", expectCode: http.StatusMethodNotAllowed},
+		{name: "unknown action", method: http.MethodPost, path: "/api/v1/servers/files/pause", expectCode: http.StatusNotFound},
+		{name: "version root", method: http.MethodGet, path: "/api/v1", expectCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, server, code, err := h.resolveAdminRoute(tt.method, tt.path)
+			if tt.expectCode != 0 {
+				if err == nil || code != tt.expectCode {
+					t.Errorf("Expected status %d, got %d (err: %v)", tt.expectCode, code, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if route.path != tt.route || server != tt.server || route.write != tt.write {
+				t.Errorf("Unexpected route %s for server %q (write %v)", route.path, server, route.write)
+			}
+		})
+	}
+}
+
+func TestAdminOpenAPISpec(t *testing.T) {
+	spec := adminOpenAPISpec()
+	paths := spec["paths"].(map[string]interface{})
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	operationIDs := make(map[string]bool)
+	for _, route := range adminRoutes {
+		item, exists := paths[adminAPIPath+route.path].(map[string]interface{})
+		if !exists {
+			t.Fatalf("path %s missing from the spec", route.path)
+		}
+		operation, exists := item[strings.ToLower(route.method)].(map[string]interface{})
+		if !exists {
+			t.Fatalf("%s %s missing from the spec", route.method, route.path)
+		}
+		id := operation["operationId"].(string)
+		if operationIDs[id] {
+			t.Errorf("duplicate operationId %s", id)
+		}
+		operationIDs[id] = true
+		if _, exists := schemas[route.response]; !exists {
+			t.Errorf("%s %s answers with undefined schema %s", route.method, route.path, route.response)
+		}
+	}
+	if !operationIDs["postServersNameRestart"] {
+		t.Errorf("unexpected operation ids %v", operationIDs)
+	}
+}
@@ -0,0 +1,300 @@
+// internal/server/aggregator.go
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// aggregatorSeparator joins a server name and a tool or prompt name on the aggregator endpoint
+const aggregatorSeparator = "__"
+
+// isAggregatorPath reports whether the request targets the merged MCP endpoint
+func (h *ProxyHandler) isAggregatorPath(path string) bool {
+	aggregator := h.Manager.Config().Aggregator
+
+	return aggregator.Enabled && path == aggregator.EndpointPath()
+}
+
+// handleAggregatorRequest serves all configured servers as a single MCP server
+func (h *ProxyHandler) handleAggregatorRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.corsError(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	body, ok := h.readBodyWithin(w, r, "aggregator", "the aggregator", h.aggregatorRequestLimit())
+	if !ok {
+
+		return
+	}
+
+	// Each message of a batch is routed to its own server
+	if isBatch(body) {
+		h.serveBatch(w, r, body, h.handleAggregatorMessage)
+
+		return
+	}
+	h.handleAggregatorMessage(w, r, body)
+}
+
+// handleAggregatorMessage routes one JSON-RPC message sent to the aggregator
+func (h *ProxyHandler) handleAggregatorMessage(w http.ResponseWriter, r *http.Request, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var requestPayload map[string]interface{}
+	if err := json.Unmarshal(body, &requestPayload); err != nil {
+		h.sendMCPError(w, nil, protocol.ParseError, "Invalid JSON in request")
+
+		return
+	}
+	reqIDVal := requestPayload["id"]
+	reqMethodVal, _ := requestPayload["method"].(string)
+	params, _ := requestPayload["params"].(map[string]interface{})
+
+	h.logger.Info("Aggregator request: Method=%s, ID=%v", reqMethodVal, reqIDVal)
+
+	switch {
+	case isProxyStandardMethod(reqMethodVal):
+		h.handleProxyStandardMethod(w, r, requestPayload, reqIDVal, reqMethodVal)
+	case strings.HasPrefix(reqMethodVal, "notifications/"):
+		w.WriteHeader(http.StatusAccepted)
+	case reqMethodVal == "tools/list":
+		h.aggregateList(w, r, reqIDVal, "tools/list", "tools", true)
+	case reqMethodVal == "prompts/list":
+		h.aggregateList(w, r, reqIDVal, "prompts/list", "prompts", false)
+	case reqMethodVal == "resources/list":
+		h.aggregateList(w, r, reqIDVal, "resources/list", "resources", false)
+	case reqMethodVal == "tools/call", reqMethodVal == "prompts/get":
+		name, _ := params["name"].(string)
+		serverName, innerName, ok := h.splitAggregatedName(name)
+		if !ok {
+			h.sendMCPError(w, reqIDVal, protocol.InvalidParams, "Unknown or non-namespaced name: "+name)
+
+			return
+		}
+		routedParams := make(map[string]interface{}, len(params))
+		for key, value := range params {
+			routedParams[key] = value
+		}
+		routedParams["name"] = innerName
+		h.routeAggregated(w, r, serverName, reqIDVal, reqMethodVal, routedParams)
+	case reqMethodVal == "resources/read":
+		uri, _ := params["uri"].(string)
+		serverName := h.resourceOwner(r, uri)
+		if serverName == "" {
+			h.sendMCPError(w, reqIDVal, protocol.InvalidParams, "Unknown resource: "+uri)
+
+			return
+		}
+		h.routeAggregated(w, r, serverName, reqIDVal, reqMethodVal, params)
+	default:
+		h.sendMCPError(w, reqIDVal, protocol.MethodNotFound, "Method not supported by aggregator: "+reqMethodVal)
+	}
+}
+
+// aggregatedServers returns the running servers exposed through the aggregator, sorted by name
+func (h *ProxyHandler) aggregatedServers() []string {
+	cfg := h.Manager.Config()
+	names := cfg.Aggregator.Servers
+	if len(names) == 0 {
+		for name := range cfg.Servers {
+			names = append(names, name)
+		}
+	}
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, exists := h.Manager.GetServerInstance(name); exists {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// splitAggregatedName resolves <server>__<name>, preferring the longest matching server name
+func (h *ProxyHandler) splitAggregatedName(name string) (string, string, bool) {
+	best := ""
+	for _, serverName := range h.aggregatedServers() {
+		if strings.HasPrefix(name, serverName+aggregatorSeparator) && len(serverName) > len(best) {
+			best = serverName
+		}
+	}
+	if best == "" {
+
+		return "", "", false
+	}
+
+	return best, strings.TrimPrefix(name, best+aggregatorSeparator), true
+}
+
+// callServer sends a request to one server through the normal forwarding path and
+// returns its JSON-RPC result
+func (h *ProxyHandler) callServer(r *http.Request, serverName, method string, params map[string]interface{}) (map[string]interface{}, bool) {
+	instance, exists := h.Manager.GetServerInstance(serverName)
+	if !exists {
+
+		return nil, false
+	}
+
+	reqID := h.getNextRequestID()
+	payload := map[string]interface{}{"jsonrpc": "2.0", "id": reqID, "method": method}
+	if params != nil {
+		payload["params"] = params
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+
+		return nil, false
+	}
+
+	recorder := &mcpResponseRecorder{statusCode: http.StatusOK, headers: make(http.Header)}
+	h.forwardToServerWithBody(recorder, r, serverName, instance, body, reqID, method)
+
+	var response struct {
+		Result map[string]interface{} `json:"result"`
+		Error  *MCPError              `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.body, &response); err != nil || response.Error != nil || response.Result == nil {
+		h.logger.Warning("Aggregator: %s on server %s returned no result (HTTP %d)", method, serverName, recorder.statusCode)
+
+		return nil, false
+	}
+
+	return response.Result, true
+}
+
+// aggregateList fans a list request out to every server and merges the results.
+// Tool and prompt names are namespaced; resources keep their URIs and are routed by owner.
+func (h *ProxyHandler) aggregateList(w http.ResponseWriter, r *http.Request, reqIDVal interface{}, method, key string, isTools bool) {
+	servers := h.aggregatedServers()
+	results := make([]map[string]interface{}, len(servers))
+
+	var wg sync.WaitGroup
+	for i, serverName := range servers {
+		wg.Add(1)
+		go func(i int, serverName string) {
+			defer wg.Done()
+			if result, ok := h.callServer(r, serverName, method, nil); ok {
+				results[i] = result
+			}
+		}(i, serverName)
+	}
+	wg.Wait()
+
+	hasScope := h.callerScopeChecker(r)
+	merged := make([]interface{}, 0)
+	owners := make(map[string]string)
+	for i, serverName := range servers {
+		if results[i] == nil {
+
+			continue
+		}
+		items, _ := results[i][key].([]interface{})
+		for _, item := range items {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+
+				continue
+			}
+			if key == "resources" {
+				if uri, _ := entry["uri"].(string); uri != "" {
+					owners[uri] = serverName
+				}
+				merged = append(merged, entry)
+
+				continue
+			}
+
+			name, _ := entry["name"].(string)
+			if isTools && checkToolACL(h.Manager.Config().Servers[serverName].ToolsACL, name, hasScope) != nil {
+
+				continue
+			}
+			namespaced := make(map[string]interface{}, len(entry))
+			for field, value := range entry {
+				namespaced[field] = value
+			}
+			namespaced["name"] = serverName + aggregatorSeparator + name
+			merged = append(merged, namespaced)
+		}
+	}
+
+	if key == "resources" {
+		h.aggregatorMu.Lock()
+		h.aggregatorResourceOwners = owners
+		h.aggregatorMu.Unlock()
+	}
+
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      reqIDVal,
+		"result":  map[string]interface{}{key: merged},
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode aggregated %s response: %v", method, err)
+	}
+}
+
+// resourceOwner finds the server that listed a resource URI, refreshing the list once if needed
+func (h *ProxyHandler) resourceOwner(r *http.Request, uri string) string {
+	if uri == "" {
+
+		return ""
+	}
+
+	h.aggregatorMu.Lock()
+	owner := h.aggregatorResourceOwners[uri]
+	h.aggregatorMu.Unlock()
+	if owner != "" {
+
+		return owner
+	}
+
+	h.aggregateList(&mcpResponseRecorder{headers: make(http.Header)}, r, nil, "resources/list", "resources", false)
+
+	h.aggregatorMu.Lock()
+	defer h.aggregatorMu.Unlock()
+
+	return h.aggregatorResourceOwners[uri]
+}
+
+// routeAggregated forwards a single-server request, keeping the client's request ID
+func (h *ProxyHandler) routeAggregated(w http.ResponseWriter, r *http.Request, serverName string, reqIDVal interface{}, method string, params map[string]interface{}) {
+	instance, exists := h.Manager.GetServerInstance(serverName)
+	if !exists {
+		h.sendMCPError(w, reqIDVal, -32002, "Server not available: "+serverName)
+
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      reqIDVal,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		h.sendMCPError(w, reqIDVal, protocol.InternalError, "Failed to encode routed request")
+
+		return
+	}
+	// Each server's own limit applies to what is routed to it
+	if maxBytes := h.requestLimitsFor(serverName).maxRequestBytes; maxBytes > 0 && int64(len(body)) > maxBytes {
+		h.logger.Warning("Rejected aggregated %s to %s from %s: body exceeds %d bytes", method, serverName, getClientIP(r), maxBytes)
+		h.sendRequestTooLarge(w, reqIDVal, "server '"+serverName+"'", maxBytes)
+
+		return
+	}
+
+	h.forwardToServerWithBody(w, r, serverName, instance, body, reqIDVal, method)
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+	"github.com/phildougherty/mcp-compose/internal/logfilter"
 	"github.com/phildougherty/mcp-compose/internal/protocol"
 )
 
@@ -61,6 +63,11 @@ func (h *ProxyHandler) handleAPIReload(w http.ResponseWriter, r *http.Request) {
 	}
 	h.StdioConnections = make(map[string]*MCPSTDIOConnection)
 	h.StdioMutex.Unlock()
+	oldSTDIOConnCount += h.closeStdioMuxes()
+
+	// Drop cached catalogs and responses so they are fetched from the reloaded servers
+	h.catalog.clear()
+	h.responses.clear()
 
 	// Refresh tool cache
 	h.toolCacheMu.Lock()
@@ -70,6 +77,16 @@ func (h *ProxyHandler) handleAPIReload(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Info("Proxy reload completed: cleared %d HTTP, %d SSE, %d STDIO connections",
 		oldHTTPConnCount, oldSSEConnCount, oldSTDIOConnCount)
+	events.Publish(events.Event{
+		Type:    events.ConfigReloaded,
+		Client:  getClientIP(r),
+		Message: "Proxy configuration reloaded",
+		Details: map[string]interface{}{
+			"httpConnections":  oldHTTPConnCount,
+			"sseConnections":   oldSSEConnCount,
+			"stdioConnections": oldSTDIOConnCount,
+		},
+	})
 
 	response := map[string]interface{}{
 		"status":  "success",
@@ -92,7 +109,7 @@ func (h *ProxyHandler) handleAPIServers(w http.ResponseWriter, _ *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	serverList := make(map[string]map[string]interface{})
 
-	for name := range h.Manager.config.Servers {
+	for name := range h.Manager.Config().Servers {
 		instance, exists := h.Manager.GetServerInstance(name)
 		if !exists {
 			h.logger.Warning("Server %s in config but not in manager instance list for /api/servers.", name)
@@ -102,7 +119,7 @@ func (h *ProxyHandler) handleAPIServers(w http.ResponseWriter, _ *http.Request)
 		}
 
 		containerStatus, _ := h.Manager.GetServerStatus(name)
-		serverConfig := h.Manager.config.Servers[name]
+		serverConfig := h.Manager.Config().Servers[name]
 
 		serverInfo := map[string]interface{}{
 			"name":               name,
@@ -113,6 +130,21 @@ func (h *ProxyHandler) handleAPIServers(w http.ResponseWriter, _ *http.Request)
 			"isContainer":        instance.IsContainer,
 			"proxyTransportMode": "HTTP",
 		}
+		if healthCheck := serverConfig.ActiveHealthCheck(); healthCheck != nil && healthCheck.CheckType() != "none" {
+			status, errText, checkedAt := h.Manager.GetServerHealth(name)
+			health := map[string]interface{}{
+				"type":   healthCheck.CheckType(),
+				"status": status,
+				"error":  errText,
+			}
+			if !checkedAt.IsZero() {
+				health["lastChecked"] = checkedAt.Format(time.RFC3339)
+			}
+			serverInfo["health"] = health
+		}
+		if until := h.Manager.BackoffUntil(name); !until.IsZero() {
+			serverInfo["backoffUntil"] = until.Format(time.RFC3339)
+		}
 
 		h.ConnectionMutex.RLock()
 		if conn, connExists := h.ServerConnections[name]; connExists {
@@ -146,9 +178,9 @@ func (h *ProxyHandler) handleAPIStatus(w http.ResponseWriter, _ *http.Request) {
 	runningContainers := 0
 	activeHTTPConnections := 0
 	initializedHTTPSessions := 0
-	totalServersInConfig := len(h.Manager.config.Servers)
+	totalServersInConfig := len(h.Manager.Config().Servers)
 
-	for name := range h.Manager.config.Servers {
+	for name := range h.Manager.Config().Servers {
 		if status, _ := h.Manager.GetServerStatus(name); status == "running" {
 			runningContainers++
 		}
@@ -180,6 +212,7 @@ func (h *ProxyHandler) handleAPIStatus(w http.ResponseWriter, _ *http.Request) {
 		"standardMethodsSupported":       true,
 		"standardHandlerInitialized":     h.standardHandler.IsInitialized(),
 		"supportedCapabilities":          h.standardHandler.GetCapabilities(),
+		"requestQueues":                  h.requestQueueStats(),
 	}
 
 	if err := json.NewEncoder(w).Encode(apiStatus); err != nil {
@@ -198,7 +231,7 @@ func (h *ProxyHandler) handleDiscoveryEndpoint(w http.ResponseWriter, r *http.Re
 	}
 	proxyExternalBaseURL := fmt.Sprintf("%s://%s", scheme, r.Host)
 
-	for serverNameInConfig, serverConfigFromFile := range h.Manager.config.Servers {
+	for serverNameInConfig, serverConfigFromFile := range h.Manager.Config().Servers {
 		clientReachableEndpoint := fmt.Sprintf("%s/%s", proxyExternalBaseURL, serverNameInConfig)
 		var currentCapabilities interface{} = serverConfigFromFile.Capabilities
 
@@ -239,6 +272,15 @@ func (h *ProxyHandler) handleDiscoveryEndpoint(w http.ResponseWriter, r *http.Re
 		serversForDiscovery = append(serversForDiscovery, serverEntry)
 	}
 
+	if h.Manager.Config().ControlServer.Enabled {
+		serversForDiscovery = append(serversForDiscovery, map[string]interface{}{
+			"name":         constants.ControlServerName,
+			"httpEndpoint": fmt.Sprintf("%s/%s", proxyExternalBaseURL, constants.ControlServerName),
+			"capabilities": map[string]interface{}{"tools": map[string]interface{}{}},
+			"description":  "Built-in server for managing this mcp-compose stack",
+		})
+	}
+
 	discoveryResponse := map[string]interface{}{
 		"servers": serversForDiscovery,
 	}
@@ -400,6 +442,10 @@ func (h *ProxyHandler) handleOAuthScopesList(w http.ResponseWriter, r *http.Requ
 		{"name": "mcp:tools", "description": "Access to MCP tools"},
 		{"name": "mcp:resources", "description": "Access to MCP resources"},
 		{"name": "mcp:prompts", "description": "Access to MCP prompts"},
+		{"name": TasksReadScope, "description": "View scheduled tasks and their runs"},
+		{"name": TasksWriteScope, "description": "Create, change and run scheduled tasks"},
+		{"name": AdminReadScope, "description": "View servers, their status and logs"},
+		{"name": AdminWriteScope, "description": "Start, stop and restart servers and reload the configuration"},
 		{"name": "mcp:*", "description": "Full access to all MCP capabilities"},
 	}
 
@@ -639,7 +685,8 @@ func (h *ProxyHandler) handleServerTokens(w http.ResponseWriter, r *http.Request
 
 func (h *ProxyHandler) getServerOAuthConfig(serverName string) config.ServerOAuthConfig {
 	// Check if server exists in config
-	if h.Manager == nil || h.Manager.config == nil {
+	cfg := h.composeConfig()
+	if cfg == nil {
 
 		return config.ServerOAuthConfig{
 			Enabled:             false,
@@ -649,7 +696,7 @@ func (h *ProxyHandler) getServerOAuthConfig(serverName string) config.ServerOAut
 		}
 	}
 
-	serverConfig, exists := h.Manager.config.Servers[serverName]
+	serverConfig, exists := cfg.Servers[serverName]
 	if !exists {
 
 		return config.ServerOAuthConfig{
@@ -689,12 +736,13 @@ func (h *ProxyHandler) getServerOAuthConfig(serverName string) config.ServerOAut
 }
 
 func (h *ProxyHandler) updateServerOAuthConfig(serverName string, newConfig config.ServerOAuthConfig) error {
-	if h.Manager == nil || h.Manager.config == nil {
+	cfg := h.composeConfig()
+	if cfg == nil {
 
 		return fmt.Errorf("manager not initialized")
 	}
 
-	serverConfig, exists := h.Manager.config.Servers[serverName]
+	serverConfig, exists := cfg.Servers[serverName]
 	if !exists {
 
 		return fmt.Errorf("server %s not found", serverName)
@@ -703,18 +751,20 @@ func (h *ProxyHandler) updateServerOAuthConfig(serverName string, newConfig conf
 	// Update the OAuth config directly
 	serverConfig.OAuth = &newConfig
 
-	// Also update the legacy authentication config for backward compatibility
-	if serverConfig.Authentication == nil {
-		serverConfig.Authentication = &config.ServerAuthConfig{}
+	// Also update the legacy authentication config for backward compatibility, on a copy
+	// as requests may be reading the current one
+	authentication := config.ServerAuthConfig{}
+	if serverConfig.Authentication != nil {
+		authentication = *serverConfig.Authentication
 	}
-
-	serverConfig.Authentication.Enabled = newConfig.Enabled
-	serverConfig.Authentication.RequiredScope = newConfig.RequiredScope
-	serverConfig.Authentication.OptionalAuth = newConfig.OptionalAuth
-	serverConfig.Authentication.AllowAPIKey = &newConfig.AllowAPIKeyFallback
+	authentication.Enabled = newConfig.Enabled
+	authentication.RequiredScope = newConfig.RequiredScope
+	authentication.OptionalAuth = newConfig.OptionalAuth
+	authentication.AllowAPIKey = &newConfig.AllowAPIKeyFallback
+	serverConfig.Authentication = &authentication
 
 	// Update the server config in the manager
-	h.Manager.config.Servers[serverName] = serverConfig
+	h.Manager.SetServerConfig(serverName, serverConfig)
 
 	h.logger.Info("Updated OAuth configuration for server %s", serverName)
 
@@ -937,14 +987,24 @@ func (h *ProxyHandler) handleContainerLogs(w http.ResponseWriter, r *http.Reques
 
 	h.logger.Debug("Executing: docker %v", args)
 
+	// raw=true shows the output as the server wrote it, without its log_filters rules
+	var filter *logfilter.Filter
+	if r.URL.Query().Get("raw") != "true" {
+		var err error
+		filter, err = logfilter.ForServer(h.Manager.Config(), config.ServerForContainer(containerName))
+		if err != nil {
+			h.logger.Warning("Ignoring log filters for %s: %v", containerName, err)
+		}
+	}
+
 	if follow {
-		h.streamContainerLogs(w, r, containerName, args)
+		h.streamContainerLogs(w, r, containerName, args, filter)
 	} else {
-		h.getStaticContainerLogs(w, r, containerName, args)
+		h.getStaticContainerLogs(w, r, containerName, args, filter)
 	}
 }
 
-func (h *ProxyHandler) getStaticContainerLogs(w http.ResponseWriter, r *http.Request, containerName string, args []string) {
+func (h *ProxyHandler) getStaticContainerLogs(w http.ResponseWriter, r *http.Request, containerName string, args []string, filter *logfilter.Filter) {
 	ctx, cancel := context.WithTimeout(r.Context(), constants.HTTPRequestTimeout)
 	defer cancel()
 
@@ -961,7 +1021,11 @@ func (h *ProxyHandler) getStaticContainerLogs(w http.ResponseWriter, r *http.Req
 	lines := strings.Split(string(output), "\n")
 	filteredLines := make([]string, 0) // Initialize as empty slice instead of nil
 	for _, line := range lines {
-		if strings.TrimSpace(line) != "" {
+		if strings.TrimSpace(line) == "" {
+
+			continue
+		}
+		if line, _, keep := filter.Apply(line); keep {
 			filteredLines = append(filteredLines, line)
 		}
 	}
@@ -978,7 +1042,7 @@ func (h *ProxyHandler) getStaticContainerLogs(w http.ResponseWriter, r *http.Req
 	}
 }
 
-func (h *ProxyHandler) streamContainerLogs(w http.ResponseWriter, r *http.Request, containerName string, args []string) {
+func (h *ProxyHandler) streamContainerLogs(w http.ResponseWriter, r *http.Request, containerName string, args []string, filter *logfilter.Filter) {
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -1030,7 +1094,11 @@ func (h *ProxyHandler) streamContainerLogs(w http.ResponseWriter, r *http.Reques
 		default:
 		}
 
-		line := scanner.Text()
+		line, debug, keep := filter.Apply(scanner.Text())
+		if !keep {
+
+			continue
+		}
 		lineCount++
 
 		// Format log entry
@@ -1042,7 +1110,9 @@ func (h *ProxyHandler) streamContainerLogs(w http.ResponseWriter, r *http.Reques
 
 		// Detect log level
 		content := strings.ToLower(line)
-		if strings.Contains(content, "error") {
+		if debug {
+			logEntry["level"] = "debug"
+		} else if strings.Contains(content, "error") {
 			logEntry["level"] = "error"
 		} else if strings.Contains(content, "warn") {
 			logEntry["level"] = "warning"
@@ -1079,16 +1149,15 @@ func (h *ProxyHandler) streamContainerLogs(w http.ResponseWriter, r *http.Reques
 }
 
 func (h *ProxyHandler) handleContainerStats(w http.ResponseWriter, r *http.Request, containerName string) {
-	ctx, cancel := context.WithTimeout(r.Context(), constants.HTTPQuickTimeout)
-	defer cancel()
+	if h.Manager.containerRuntime == nil {
+		http.Error(w, "No container runtime available", http.StatusServiceUnavailable)
 
-	cmd := exec.CommandContext(ctx, "docker", "stats", "--no-stream", "--format",
-		"table {{.Name}}\t{{.CPUPerc}}\t{{.MemUsage}}\t{{.MemPerc}}\t{{.NetIO}}\t{{.BlockIO}}",
-		containerName)
+		return
+	}
 
-	output, err := cmd.Output()
+	stats, err := h.Manager.containerRuntime.GetContainerStats(containerName)
 	if err != nil {
-		h.logger.Error("Docker stats command failed for %s: %v", containerName, err)
+		h.logger.Error("Container stats failed for %s: %v", containerName, err)
 		http.Error(w, fmt.Sprintf("Failed to get stats: %v", err), http.StatusInternalServerError)
 
 		return
@@ -1096,7 +1165,7 @@ func (h *ProxyHandler) handleContainerStats(w http.ResponseWriter, r *http.Reque
 
 	response := map[string]interface{}{
 		"container": containerName,
-		"stats":     string(output),
+		"stats":     stats,
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
 
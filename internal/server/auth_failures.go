@@ -0,0 +1,51 @@
+// internal/server/auth_failures.go
+package server
+
+import (
+	"net/http"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/authlog"
+)
+
+// authFailed records a request refused for its credentials: the denial is published like
+// any other and, when the request carried credentials, written to the auth failure log
+func (h *ProxyHandler) authFailed(r *http.Request, serverName, reason string) {
+	publishAuthDenied(r, serverName, reason)
+	if r.Header.Get("Authorization") == "" {
+
+		return
+	}
+	h.authFailures.Record(authlog.Failure{
+		Client: h.clientAddress(r),
+		Kind:   "bearer",
+		Server: serverName,
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Reason: reason,
+	})
+}
+
+// recordOAuthFailure writes a failed sign-in or client authentication at the OAuth
+// endpoints to the auth failure log
+func (h *ProxyHandler) recordOAuthFailure(r *http.Request, failure auth.Failure) {
+	h.authFailures.Record(authlog.Failure{
+		Client: h.clientAddress(r),
+		Kind:   failure.Kind,
+		Name:   failure.Name,
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Reason: failure.Reason,
+	})
+}
+
+// clientAddress returns the address a request came from, looking behind the reverse proxies
+// proxy_access trusts but not taking X-Forwarded-For from anyone else
+func (h *ProxyHandler) clientAddress(r *http.Request) string {
+	if ip := h.proxyAccess.ClientIP(r); ip != nil {
+
+		return ip.String()
+	}
+
+	return r.RemoteAddr
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/authlog"
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/ipaccess"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func TestAuthFailureLog(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.ComposeConfig{Logging: config.LoggingConfig{AuthFailures: &config.AuthFailureLog{Path: "auth.log"}}}
+	logger := logging.NewLogger("error")
+	authFailures, err := authlog.Open(cfg, filepath.Join(dir, "mcp-compose.yaml"), logger)
+	if err != nil {
+		t.Fatalf("Failed to open the auth failure log: %v", err)
+	}
+	proxyAccess, _ := ipaccess.New(&config.IPAccessConfig{TrustedProxies: []string{"10.0.0.2"}})
+	h := &ProxyHandler{
+		Manager:      newTestManager(cfg, logger, map[string]*ServerInstance{}),
+		APIKey:       "secret",
+		logger:       logger,
+		proxyAccess:  proxyAccess,
+		authFailures: authFailures,
+	}
+
+	serve := func(remoteAddr, authorization, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/servers", nil)
+		req.RemoteAddr = remoteAddr
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+	// Only wrong credentials are failures, and only trusted proxies name the client
+	for _, request := range []struct{ remoteAddr, authorization, forwardedFor string }{
+		{"203.0.113.7:5000", "Bearer guess", "192.0.2.1"},
+		{"10.0.0.2:5000", "Bearer guess", "198.51.100.4"},
+		{"203.0.113.8:5000", "", ""},
+	} {
+		if code := serve(request.remoteAddr, request.authorization, request.forwardedFor); code != http.StatusUnauthorized {
+			t.Fatalf("Expected %s to be refused, got %d", request.remoteAddr, code)
+		}
+	}
+	if code := serve("203.0.113.7:5000", "Bearer secret", ""); code == http.StatusUnauthorized {
+		t.Fatal("Expected the API key to be accepted")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "auth.log"))
+	if err != nil {
+		t.Fatalf("Failed to read the auth failure log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected two failures, got %q", lines)
+	}
+	for i, client := range []string{"203.0.113.7", "198.51.100.4"} {
+		if !strings.Contains(lines[i], "auth failure client="+client+" kind=bearer ") || !strings.Contains(lines[i], `reason="API key mismatch"`) {
+			t.Errorf("Expected a failure from %s, got %s", client, lines[i])
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// internal/server/batch.go
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// isBatch reports whether a request body is a JSON-RPC batch rather than a single message
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// serveBatch splits a JSON-RPC batch and hands each message to handle as if it had been sent
+// alone. Up to constants.BatchMaxConcurrency messages are handled at once; their responses
+// are returned as one array in the order of the batch, leaving out notifications.
+func (h *ProxyHandler) serveBatch(w http.ResponseWriter, r *http.Request, body []byte, handle func(http.ResponseWriter, *http.Request, []byte)) {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(body, &messages); err != nil {
+		h.sendMCPError(w, nil, protocol.ParseError, "Invalid JSON in batch")
+
+		return
+	}
+	if len(messages) == 0 {
+		h.sendMCPError(w, nil, protocol.InvalidRequest, "Empty batch")
+
+		return
+	}
+	if len(messages) > constants.BatchMaxRequests {
+		h.sendMCPError(w, nil, protocol.InvalidRequest,
+			fmt.Sprintf("Batch of %d requests exceeds the limit of %d", len(messages), constants.BatchMaxRequests))
+
+		return
+	}
+
+	h.logger.Info("Handling batch of %d messages for %s", len(messages), r.URL.Path)
+	recorders := make([]*mcpResponseRecorder, len(messages))
+	notifications := make([]bool, len(messages))
+	slots := make(chan struct{}, constants.BatchMaxConcurrency)
+	var wg sync.WaitGroup
+	for i, message := range messages {
+		recorders[i] = &mcpResponseRecorder{headers: make(http.Header)}
+		if trimmed := bytes.TrimSpace(message); len(trimmed) == 0 || trimmed[0] != '{' {
+			h.sendMCPError(recorders[i], nil, protocol.InvalidRequest, "Batch entries must be JSON-RPC objects")
+
+			continue
+		}
+
+		var envelope struct {
+			ID     *json.RawMessage `json:"id"`
+			Method string           `json:"method"`
+		}
+		notifications[i] = json.Unmarshal(message, &envelope) == nil && envelope.Method != "" && envelope.ID == nil
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			// Each message gets its own copy of the request, as handlers may annotate it
+			handle(recorders[i], r.Clone(r.Context()), message)
+		}()
+	}
+	wg.Wait()
+
+	responses := make([]json.RawMessage, 0, len(messages))
+	for i, recorder := range recorders {
+		for key, values := range recorder.headers {
+			if key != "Content-Length" && w.Header().Get(key) == "" {
+				w.Header()[key] = values
+			}
+		}
+		// Notifications are never answered, even when handling them failed
+		if notifications[i] {
+
+			continue
+		}
+		if response := bytes.TrimSpace(recorder.body); len(response) > 0 && response[0] == '{' {
+			responses = append(responses, response)
+		}
+	}
+
+	// A batch of notifications gets no response at all
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(responses); err != nil {
+		h.logger.Error("Failed to encode batch response: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestBatchRequests(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	backendFor := func(serverName string) *httptest.Server {
+
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request struct {
+				ID     json.RawMessage        `json:"id"`
+				Method string                 `json:"method"`
+				Params map[string]interface{} `json:"params"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			name, _ := request.Params["name"].(string)
+			if request.Method == "tools/call" && name == "slow" {
+				mu.Lock()
+				running++
+				maxRunning = max(maxRunning, running)
+				mu.Unlock()
+				time.Sleep(20 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"server":%q,"tool":%q}}`, request.ID, serverName, name)
+		}))
+	}
+	alpha, beta := backendFor("alpha"), backendFor("beta")
+	defer alpha.Close()
+	defer beta.Close()
+
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"alpha": {Protocol: "http"},
+		"beta":  {Protocol: "http"},
+	}}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager: newTestManager(cfg, logger, map[string]*ServerInstance{
+			"alpha": {Name: "alpha", Config: cfg.Servers["alpha"]},
+			"beta":  {Name: "beta", Config: cfg.Servers["beta"]},
+		}),
+		logger:          logger,
+		ctx:             context.Background(),
+		httpClient:      http.DefaultClient,
+		catalog:         newCatalogCache(),
+		responses:       newResponseCache(),
+		standardHandler: protocol.NewStandardMethodHandler(protocol.ServerInfo{Name: "mcp-compose"}, protocol.CapabilitiesOpts{}, logger),
+		ServerConnections: map[string]*MCPHTTPConnection{
+			"alpha": {ServerName: "alpha", BaseURL: alpha.URL, Initialized: true, Healthy: true},
+			"beta":  {ServerName: "beta", BaseURL: beta.URL, Initialized: true, Healthy: true},
+		},
+	}
+
+	post := func(handle http.HandlerFunc, body string) (int, []MCPResponse) {
+		recorder := httptest.NewRecorder()
+		handle(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		var responses []MCPResponse
+		if recorder.Body.Len() > 0 {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &responses); err != nil {
+				t.Fatalf("expected an array of responses, got %s", recorder.Body.String())
+			}
+		}
+
+		return recorder.Code, responses
+	}
+	toServer := func(serverName string) http.HandlerFunc {
+
+		return func(w http.ResponseWriter, r *http.Request) {
+			instance, _ := h.Manager.GetServerInstance(serverName)
+			h.handleMCPMethodForwarding(w, r, serverName, instance)
+		}
+	}
+	resultOf := func(response MCPResponse) map[string]interface{} {
+		result, _ := response.Result.(map[string]interface{})
+
+		return result
+	}
+
+	_, responses := post(h.handleAggregatorRequest, `[
+		{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"beta__echo"}},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":"two","method":"tools/call","params":{"name":"alpha__echo"}},
+		5,
+		{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"gamma__echo"}}
+	]`)
+	if len(responses) != 4 {
+		t.Fatalf("aggregator batch: expected 4 responses, got %+v", responses)
+	}
+	if responses[0].ID != float64(1) || resultOf(responses[0])["server"] != "beta" || resultOf(responses[0])["tool"] != "echo" {
+		t.Errorf("first request should be routed to beta, got %+v", responses[0])
+	}
+	if responses[1].ID != "two" || resultOf(responses[1])["server"] != "alpha" {
+		t.Errorf("second request should be routed to alpha, got %+v", responses[1])
+	}
+	if responses[2].Error == nil || responses[2].Error.Code != protocol.InvalidRequest || responses[2].ID != nil {
+		t.Errorf("an entry that is not an object should get an invalid request error, got %+v", responses[2])
+	}
+	if responses[3].ID != float64(3) || responses[3].Error == nil {
+		t.Errorf("a request for an unknown server should fail on its own, got %+v", responses[3])
+	}
+
+	calls := make([]string, 3*constants.BatchMaxConcurrency)
+	for i := range calls {
+		calls[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"slow"}}`, i)
+	}
+	_, responses = post(toServer("alpha"), "["+strings.Join(calls, ",")+"]")
+	if len(responses) != len(calls) {
+		t.Fatalf("server batch: expected %d responses, got %d", len(calls), len(responses))
+	}
+	for i, response := range responses {
+		if response.ID != float64(i) {
+			t.Errorf("responses should keep the order of the batch, got ID %v at %d", response.ID, i)
+		}
+	}
+	if maxRunning < 2 || maxRunning > constants.BatchMaxConcurrency {
+		t.Errorf("batch requests should run concurrently up to %d at once, ran %d", constants.BatchMaxConcurrency, maxRunning)
+	}
+
+	if code, responses := post(toServer("alpha"), `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`); code != http.StatusAccepted || len(responses) != 0 {
+		t.Errorf("a batch of notifications should get no response, got %d %+v", code, responses)
+	}
+	recorder := httptest.NewRecorder()
+	toServer("alpha")(recorder, httptest.NewRequest(http.MethodPost, "/alpha", strings.NewReader(`[]`)))
+	var response MCPResponse
+	if _ = json.Unmarshal(recorder.Body.Bytes(), &response); response.Error == nil || response.Error.Code != protocol.InvalidRequest {
+		t.Errorf("an empty batch should be an invalid request, got %s", recorder.Body.String())
+	}
+}
@@ -0,0 +1,103 @@
+// internal/server/builtin.go
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// BuiltinService generates a server from one of the top-level built-in settings, such as
+// memory or task_scheduler. Users adjust or disable the generated server under builtins.
+type BuiltinService interface {
+	// Name is the name the generated server is added under
+	Name() string
+	// Enabled reports whether the settings turn the service on
+	Enabled(cfg *config.ComposeConfig) bool
+	// DefaultConfig returns the server generated from the settings
+	DefaultConfig(cfg *config.ComposeConfig) config.ServerConfig
+	// Render completes the server after the user's overrides are merged, filling in
+	// whatever follows from other fields
+	Render(cfg *config.ComposeConfig, server config.ServerConfig) config.ServerConfig
+}
+
+var (
+	builtinMu       sync.RWMutex
+	builtinServices = []BuiltinService{taskSchedulerService{}, memoryService{}, postgresMemoryService{}}
+)
+
+// RegisterBuiltinService adds a built-in service, replacing any registered under its name
+func RegisterBuiltinService(service BuiltinService) {
+	builtinMu.Lock()
+	defer builtinMu.Unlock()
+
+	for i, existing := range builtinServices {
+		if existing.Name() == service.Name() {
+			builtinServices[i] = service
+
+			return
+		}
+	}
+	builtinServices = append(builtinServices, service)
+}
+
+// BuiltinServices returns the registered built-in services in registration order
+func BuiltinServices() []BuiltinService {
+	builtinMu.RLock()
+	defer builtinMu.RUnlock()
+
+	return append([]BuiltinService(nil), builtinServices...)
+}
+
+// IsBuiltinService reports whether name is the server of a registered built-in service
+func IsBuiltinService(name string) bool {
+	for _, service := range BuiltinServices() {
+		if service.Name() == name {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddBuiltInServers adds the servers of the enabled built-in services to cfg.Servers, with
+// the overrides under builtins merged in, and returns the names it added
+func AddBuiltInServers(cfg *config.ComposeConfig) ([]string, error) {
+	unknown := make([]string, 0, len(cfg.Builtins))
+	for name := range cfg.Builtins {
+		if !IsBuiltinService(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return nil, fmt.Errorf("builtins.%s: no built-in service is named '%s'", unknown[0], unknown[0])
+	}
+
+	var added []string
+	for _, service := range BuiltinServices() {
+		name := service.Name()
+		override := cfg.Builtins[name]
+		if !service.Enabled(cfg) || override.Disabled {
+
+			continue
+		}
+		server, err := config.MergeServer(service.DefaultConfig(cfg), &override.Server)
+		if err != nil {
+
+			return nil, fmt.Errorf("builtins.%s.server: %w", name, err)
+		}
+
+		if cfg.Servers == nil {
+			cfg.Servers = make(map[string]config.ServerConfig)
+		}
+		cfg.Servers[name] = service.Render(cfg, server)
+		added = append(added, name)
+	}
+
+	return added, nil
+}
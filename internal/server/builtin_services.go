@@ -0,0 +1,158 @@
+// internal/server/builtin_services.go
+package server
+
+import (
+	"fmt"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// taskSchedulerService is the server behind the task_scheduler settings
+type taskSchedulerService struct{}
+
+func (taskSchedulerService) Name() string {
+
+	return "task-scheduler"
+}
+
+func (taskSchedulerService) Enabled(cfg *config.ComposeConfig) bool {
+
+	return cfg.TaskScheduler != nil && cfg.TaskScheduler.Enabled
+}
+
+func (taskSchedulerService) DefaultConfig(cfg *config.ComposeConfig) config.ServerConfig {
+	server := config.ServerConfig{
+		Image:        "mcp-compose-task-scheduler:latest",
+		Protocol:     "sse",
+		HttpPort:     cfg.TaskScheduler.Port,
+		SSEPath:      "/sse",
+		User:         "root",
+		ReadOnly:     false,
+		Privileged:   false,
+		Capabilities: []string{"tools", "resources"},
+		Env: map[string]string{
+			"TZ":                                 cfg.ProjectTimezone(),
+			"MCP_CRON_SERVER_TRANSPORT":          "sse",
+			"MCP_CRON_SERVER_ADDRESS":            "0.0.0.0",
+			"MCP_CRON_DATABASE_PATH":             cfg.TaskScheduler.DatabasePath,
+			"MCP_CRON_DATABASE_ENABLED":          "true",
+			"MCP_CRON_LOGGING_LEVEL":             cfg.TaskScheduler.LogLevel,
+			"MCP_CRON_SCHEDULER_DEFAULT_TIMEOUT": "10m",
+			"MCP_CRON_OLLAMA_ENABLED":            "true",
+			"MCP_CRON_OLLAMA_BASE_URL":           cfg.TaskScheduler.OllamaURL,
+			"MCP_CRON_OLLAMA_DEFAULT_MODEL":      cfg.TaskScheduler.OllamaModel,
+			"USE_OPENROUTER":                     "true",
+			"OPENROUTER_ENABLED":                 "true",
+			"OPENROUTER_API_KEY":                 cfg.TaskScheduler.OpenRouterAPIKey,
+			"OPENROUTER_MODEL":                   cfg.TaskScheduler.OpenRouterModel,
+			"MCP_PROXY_URL":                      cfg.TaskScheduler.MCPProxyURL,
+			"MCP_PROXY_API_KEY":                  cfg.TaskScheduler.MCPProxyAPIKey,
+			"MCP_MEMORY_SERVER_URL":              "http://" + config.ContainerName("memory") + ":3001",
+			"MCP_FILESYSTEM_URL":                 "http://" + config.ContainerName("filesystem") + ":3000",
+			"MCP_OPENROUTER_GATEWAY_URL":         "http://" + config.ContainerName("openrouter-gateway") + ":8012",
+		},
+		Networks: []string{config.DefaultNetwork()},
+		Authentication: &config.ServerAuthConfig{
+			Enabled:       true,
+			RequiredScope: "mcp:tools",
+			OptionalAuth:  false,
+			AllowAPIKey:   &[]bool{true}[0],
+		},
+		Volumes: cfg.TaskScheduler.Volumes,
+	}
+	for k, v := range cfg.TaskScheduler.Env {
+		server.Env[k] = v
+	}
+
+	return server
+}
+
+// Render has the scheduler listen on the server's final http_port unless its env says otherwise
+func (taskSchedulerService) Render(cfg *config.ComposeConfig, server config.ServerConfig) config.ServerConfig {
+	if _, set := cfg.TaskScheduler.Env["MCP_CRON_SERVER_PORT"]; set {
+
+		return server
+	}
+	if server.Env == nil {
+		server.Env = make(map[string]string)
+	}
+	server.Env["MCP_CRON_SERVER_PORT"] = fmt.Sprintf("%d", server.HttpPort)
+
+	return server
+}
+
+// memoryService is the knowledge graph server behind the memory settings
+type memoryService struct{}
+
+func (memoryService) Name() string {
+
+	return "memory"
+}
+
+func (memoryService) Enabled(cfg *config.ComposeConfig) bool {
+
+	return cfg.Memory.Enabled
+}
+
+func (memoryService) DefaultConfig(cfg *config.ComposeConfig) config.ServerConfig {
+
+	return config.ServerConfig{
+		// Use the built image name that will be created by the memory manager
+		Image:        "mcp-compose-memory:latest",
+		Protocol:     "http",
+		HttpPort:     cfg.Memory.Port,
+		User:         "root",
+		ReadOnly:     false,
+		Privileged:   false,
+		Capabilities: []string{"tools", "resources"},
+		Env: map[string]string{
+			"NODE_ENV":     "production",
+			"DATABASE_URL": cfg.Memory.DatabaseURL,
+		},
+		Networks:       []string{config.DefaultNetwork()},
+		Authentication: cfg.Memory.Authentication,
+		DependsOn:      []string{"postgres-memory"},
+	}
+}
+
+func (memoryService) Render(_ *config.ComposeConfig, server config.ServerConfig) config.ServerConfig {
+
+	return server
+}
+
+// postgresMemoryService is the database the memory server keeps its graph in
+type postgresMemoryService struct{}
+
+func (postgresMemoryService) Name() string {
+
+	return "postgres-memory"
+}
+
+func (postgresMemoryService) Enabled(cfg *config.ComposeConfig) bool {
+
+	return cfg.Memory.Enabled
+}
+
+func (postgresMemoryService) DefaultConfig(cfg *config.ComposeConfig) config.ServerConfig {
+
+	return config.ServerConfig{
+		Image:       "postgres:15-alpine",
+		User:        "postgres",
+		ReadOnly:    false,
+		Privileged:  false,
+		SecurityOpt: []string{"no-new-privileges:true"},
+		Env: map[string]string{
+			"POSTGRES_DB":       cfg.Memory.PostgresDB,
+			"POSTGRES_USER":     cfg.Memory.PostgresUser,
+			"POSTGRES_PASSWORD": cfg.Memory.PostgresPassword,
+		},
+		Volumes:       cfg.Memory.Volumes,
+		Networks:      []string{config.DefaultNetwork()},
+		RestartPolicy: "unless-stopped",
+	}
+}
+
+func (postgresMemoryService) Render(_ *config.ComposeConfig, server config.ServerConfig) config.ServerConfig {
+
+	return server
+}
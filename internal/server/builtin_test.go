@@ -0,0 +1,107 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestAddBuiltInServers(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		expectErr bool
+		check     func(t *testing.T, servers map[string]config.ServerConfig)
+	}{
+		{
+			name: "memory defaults",
+			yaml: "memory: {enabled: true, port: 3001, database_url: postgres://db}\n",
+			check: func(t *testing.T, servers map[string]config.ServerConfig) {
+				if servers["memory"].Env["DATABASE_URL"] != "postgres://db" || servers["memory"].HttpPort != 3001 {
+					t.Errorf("Unexpected memory server: %+v", servers["memory"])
+				}
+				if _, exists := servers["postgres-memory"]; !exists {
+					t.Error("Expected postgres-memory to be added")
+				}
+			},
+		},
+		{
+			name: "override fields",
+			yaml: `memory: {enabled: true, port: 3001}
+builtins:
+  memory:
+    server:
+      image: registry.local/memory:2
+      env: {NODE_ENV: development}
+      networks: !override [backend]
+      depends_on: !reset []
+`,
+			check: func(t *testing.T, servers map[string]config.ServerConfig) {
+				memory := servers["memory"]
+				if memory.Image != "registry.local/memory:2" || memory.Env["NODE_ENV"] != "development" {
+					t.Errorf("Override not applied: %+v", memory)
+				}
+				if _, kept := memory.Env["DATABASE_URL"]; !kept {
+					t.Error("Expected generated env to be kept")
+				}
+				if !reflect.DeepEqual(memory.Networks, []string{"backend"}) || len(memory.DependsOn) != 0 {
+					t.Errorf("Expected replaced networks and no depends_on, got %v and %v", memory.Networks, memory.DependsOn)
+				}
+			},
+		},
+		{
+			name: "disabled",
+			yaml: "memory: {enabled: true}\nbuiltins:\n  postgres-memory: {disabled: true}\n",
+			check: func(t *testing.T, servers map[string]config.ServerConfig) {
+				if _, exists := servers["postgres-memory"]; exists {
+					t.Error("Expected postgres-memory to be left out")
+				}
+				if _, exists := servers["memory"]; !exists {
+					t.Error("Expected memory to be added")
+				}
+			},
+		},
+		{
+			name: "rendered from final port",
+			yaml: "task_scheduler: {enabled: true, port: 8080}\nbuiltins:\n  task-scheduler:\n    server: {http_port: 9090}\n",
+			check: func(t *testing.T, servers map[string]config.ServerConfig) {
+				if got := servers["task-scheduler"].Env["MCP_CRON_SERVER_PORT"]; got != "9090" {
+					t.Errorf("Expected MCP_CRON_SERVER_PORT 9090, got %q", got)
+				}
+			},
+		},
+		{
+			name: "not enabled",
+			yaml: "builtins:\n  memory:\n    server: {image: other}\n",
+			check: func(t *testing.T, servers map[string]config.ServerConfig) {
+				if len(servers) != 0 {
+					t.Errorf("Expected no servers, got %v", servers)
+				}
+			},
+		},
+		{name: "unknown service", yaml: "builtins:\n  vector-db: {disabled: true}\n", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config.ComposeConfig
+			if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+				t.Fatalf("Failed to parse config: %v", err)
+			}
+			_, err := AddBuiltInServers(&cfg)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tt.check(t, cfg.Servers)
+		})
+	}
+}
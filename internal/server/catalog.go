@@ -0,0 +1,349 @@
+// internal/server/catalog.go
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+const catalogStatusHeader = "X-MCP-Catalog"
+
+// catalogListKeys maps each cacheable list method to the result field holding its items
+var catalogListKeys = map[string]string{
+	"tools/list":               "tools",
+	"resources/list":           "resources",
+	"resources/templates/list": "resourceTemplates",
+	"prompts/list":             "prompts",
+}
+
+// catalogMethods fixes the order lists are fetched and reported in
+var catalogMethods = []string{"tools/list", "resources/list", "resources/templates/list", "prompts/list"}
+
+// catalogInvalidations maps listChanged notifications to the list methods they make stale
+var catalogInvalidations = map[string][]string{
+	protocol.NotificationToolsListChanged:     {"tools/list"},
+	protocol.NotificationResourcesListChanged: {"resources/list", "resources/templates/list"},
+	protocol.NotificationPromptsListChanged:   {"prompts/list"},
+}
+
+// catalogCache holds the latest list results for each server, keyed by server then method
+type catalogCache struct {
+	mu      sync.RWMutex
+	entries map[string]map[string]*catalogEntry
+}
+
+type catalogEntry struct {
+	result    json.RawMessage
+	fetchedAt time.Time
+	expiresAt time.Time
+}
+
+func newCatalogCache() *catalogCache {
+
+	return &catalogCache{entries: make(map[string]map[string]*catalogEntry)}
+}
+
+// get returns an unexpired entry
+func (c *catalogCache) get(serverName, method string) (*catalogEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.entries[serverName][method]
+	if !exists || time.Now().After(entry.expiresAt) {
+
+		return nil, false
+	}
+
+	return entry, true
+}
+
+func (c *catalogCache) put(serverName, method string, result json.RawMessage, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[serverName] == nil {
+		c.entries[serverName] = make(map[string]*catalogEntry)
+	}
+	now := time.Now()
+	c.entries[serverName][method] = &catalogEntry{
+		result:    result,
+		fetchedAt: now,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+// invalidate drops the given methods for a server, or everything cached for it when none are given
+func (c *catalogCache) invalidate(serverName string, methods ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(methods) == 0 {
+		delete(c.entries, serverName)
+
+		return
+	}
+	for _, method := range methods {
+		delete(c.entries[serverName], method)
+	}
+}
+
+func (c *catalogCache) clear() {
+	c.mu.Lock()
+	c.entries = make(map[string]map[string]*catalogEntry)
+	c.mu.Unlock()
+}
+
+// catalogTTL returns how long a server's list results may be cached; zero disables caching
+func (h *ProxyHandler) catalogTTL(serverName string) time.Duration {
+	serverConfig, exists := h.Manager.Config().Servers[serverName]
+	if !exists || serverConfig.CatalogTTL == "" {
+
+		return constants.CatalogCacheDefaultTTL
+	}
+	ttl, err := time.ParseDuration(serverConfig.CatalogTTL)
+	if err != nil {
+
+		return constants.CatalogCacheDefaultTTL
+	}
+
+	return ttl
+}
+
+// isCacheableList reports whether a request is a first-page list call the catalog can answer
+func isCacheableList(method string, requestPayload map[string]interface{}) bool {
+	if _, exists := catalogListKeys[method]; !exists {
+
+		return false
+	}
+	params, _ := requestPayload["params"].(map[string]interface{})
+	cursor, _ := params["cursor"].(string)
+
+	return cursor == ""
+}
+
+// listThroughCatalog answers a list request from the cache, or forwards it and caches a successful result
+func (h *ProxyHandler) listThroughCatalog(w http.ResponseWriter, serverName, method string, ttl time.Duration, forward func(http.ResponseWriter), reqIDVal interface{}) {
+	if entry, ok := h.catalog.get(serverName, method); ok {
+		response, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      reqIDVal,
+			"result":  entry.result,
+		})
+		if err == nil {
+			h.logger.Debug("Serving %s for %s from catalog cache", method, serverName)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(catalogStatusHeader, "hit")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(append(response, '\n'))
+
+			return
+		}
+	}
+
+	recorder := &mcpResponseRecorder{
+		statusCode: http.StatusOK,
+		headers:    make(http.Header),
+	}
+	forward(recorder)
+
+	for key, values := range recorder.headers {
+		w.Header()[key] = values
+	}
+	w.Header().Set(catalogStatusHeader, "miss")
+	w.WriteHeader(recorder.statusCode)
+	_, _ = w.Write(recorder.body)
+
+	if recorder.statusCode != http.StatusOK {
+
+		return
+	}
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.body, &response); err != nil || len(response.Result) == 0 || len(response.Error) > 0 {
+
+		return
+	}
+	h.catalog.put(serverName, method, response.Result, ttl)
+}
+
+// observeServerNotification drops cached lists and responses when a server reports they
+// changed, and passes tool and prompt changes on to subscribed clients
+func (h *ProxyHandler) observeServerNotification(serverName string, message map[string]interface{}) {
+	method, _ := message["method"].(string)
+	if stale, exists := responseCacheInvalidations[method]; exists {
+		h.responses.invalidate(serverName, stale...)
+	}
+	if method == protocol.NotificationResourcesUpdated {
+		if params, ok := message["params"].(map[string]interface{}); ok {
+			if uri, ok := params["uri"].(string); ok {
+				h.responses.invalidateURI(serverName, uri)
+			}
+		}
+
+		return
+	}
+
+	methods, exists := catalogInvalidations[method]
+	if !exists {
+
+		return
+	}
+
+	h.logger.Info("Server %s sent %s, invalidating cached catalog", serverName, method)
+	h.catalog.invalidate(serverName, methods...)
+
+	switch method {
+	case protocol.NotificationToolsListChanged:
+		_ = h.changeNotificationManager.ForceNotifyToolChanges()
+	case protocol.NotificationPromptsListChanged:
+		_ = h.changeNotificationManager.ForceNotifyPromptChanges()
+	}
+}
+
+// catalogItem is one tool, resource, template or prompt together with the server it came from
+type catalogItem struct {
+	Server     string                 `json:"server"`
+	Name       string                 `json:"name,omitempty"`
+	URI        string                 `json:"uri,omitempty"`
+	Definition map[string]interface{} `json:"definition"`
+}
+
+// catalogListStatus describes where one server's list came from
+type catalogListStatus struct {
+	Available bool       `json:"available"`
+	Cached    bool       `json:"cached"`
+	Count     int        `json:"count"`
+	FetchedAt *time.Time `json:"fetchedAt,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// handleCatalogAPI returns the merged catalog of every server with per-item provenance.
+// Passing refresh=true drops the cache first so every list is fetched again.
+func (h *ProxyHandler) handleCatalogAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.corsError(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if r.URL.Query().Get("refresh") == "true" {
+		h.catalog.clear()
+	}
+
+	serverNames := make([]string, 0, len(h.Manager.Config().Servers))
+	for name := range h.Manager.Config().Servers {
+		serverNames = append(serverNames, name)
+	}
+	sort.Strings(serverNames)
+
+	type serverCatalog struct {
+		lists map[string]catalogListStatus
+		items map[string][]catalogItem
+	}
+	collected := make([]serverCatalog, len(serverNames))
+
+	var wg sync.WaitGroup
+	for i, serverName := range serverNames {
+		wg.Add(1)
+		go func(i int, serverName string) {
+			defer wg.Done()
+			sc := serverCatalog{
+				lists: make(map[string]catalogListStatus),
+				items: make(map[string][]catalogItem),
+			}
+			for _, method := range catalogMethods {
+				status, items := h.catalogList(r, serverName, method)
+				sc.lists[method] = status
+				sc.items[catalogListKeys[method]] = items
+			}
+			collected[i] = sc
+		}(i, serverName)
+	}
+	wg.Wait()
+
+	servers := make(map[string]map[string]catalogListStatus, len(serverNames))
+	merged := make(map[string][]catalogItem)
+	for _, method := range catalogMethods {
+		merged[catalogListKeys[method]] = []catalogItem{}
+	}
+	for i, serverName := range serverNames {
+		servers[serverName] = collected[i].lists
+		for key, items := range collected[i].items {
+			merged[key] = append(merged[key], items...)
+		}
+	}
+
+	response := map[string]interface{}{
+		"servers":     servers,
+		"generatedAt": time.Now().Format(time.RFC3339Nano),
+	}
+	for key, items := range merged {
+		response[key] = items
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode /api/catalog response: %v", err)
+	}
+}
+
+// catalogList returns one server's list for the catalog, fetching it when it is not cached
+func (h *ProxyHandler) catalogList(r *http.Request, serverName, method string) (catalogListStatus, []catalogItem) {
+	status := catalogListStatus{}
+
+	var result map[string]interface{}
+	entry, cached := h.catalog.get(serverName, method)
+	if cached {
+		if err := json.Unmarshal(entry.result, &result); err != nil {
+			cached = false
+		}
+	}
+	if !cached {
+		fetched, ok := h.callServer(r, serverName, method, nil)
+		if !ok {
+
+			return status, nil
+		}
+		result = fetched
+		if entry, ok = h.catalog.get(serverName, method); !ok {
+			now := time.Now()
+			entry = &catalogEntry{fetchedAt: now}
+		}
+	}
+
+	status.Available = true
+	status.Cached = cached
+	status.FetchedAt = &entry.fetchedAt
+	if !entry.expiresAt.IsZero() {
+		status.ExpiresAt = &entry.expiresAt
+	}
+
+	rawItems, _ := result[catalogListKeys[method]].([]interface{})
+	items := make([]catalogItem, 0, len(rawItems))
+	for _, raw := range rawItems {
+		definition, ok := raw.(map[string]interface{})
+		if !ok {
+
+			continue
+		}
+		item := catalogItem{Server: serverName, Definition: definition}
+		item.Name, _ = definition["name"].(string)
+		if uri, ok := definition["uri"].(string); ok {
+			item.URI = uri
+		} else {
+			item.URI, _ = definition["uriTemplate"].(string)
+		}
+		items = append(items, item)
+	}
+	status.Count = len(items)
+
+	return status, items
+}
@@ -0,0 +1,359 @@
+// internal/server/client_analytics.go
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+const analyticsAPIPath = "/api/analytics"
+
+// otherUsageKey collects usage past the limits on tracked clients and tools
+const otherUsageKey = "(other)"
+
+// usageCounts are the totals of one client, or of one of its servers or tools
+type usageCounts struct {
+	Requests     int64 `json:"requests"`
+	Errors       int64 `json:"errors"`
+	InputTokens  int64 `json:"inputTokens"`
+	OutputTokens int64 `json:"outputTokens"`
+}
+
+func (c *usageCounts) add(failed bool, inputTokens, outputTokens int64) {
+	c.Requests++
+	if failed {
+		c.Errors++
+	}
+	c.InputTokens += inputTokens
+	c.OutputTokens += outputTokens
+}
+
+// clientUsage is what one OAuth client, API key or other caller sent through the proxy
+type clientUsage struct {
+	id        string
+	name      string
+	authType  string
+	firstSeen time.Time
+	lastSeen  time.Time
+	totals    usageCounts
+	servers   map[string]*usageCounts
+	tools     map[string]*usageCounts // keyed by server and tool, as server__tool
+}
+
+// clientAnalytics counts requests, errors and estimated tokens per caller since the proxy
+// started
+type clientAnalytics struct {
+	mu      sync.Mutex
+	since   time.Time
+	clients map[string]*clientUsage
+}
+
+func newClientAnalytics() *clientAnalytics {
+
+	return &clientAnalytics{since: time.Now(), clients: make(map[string]*clientUsage)}
+}
+
+// callerIdentity names the caller of an authenticated request: the OAuth client, the proxy
+// API key or the user a trusted reverse proxy asserted. Unauthenticated callers are told
+// apart by address.
+func callerIdentity(r *http.Request) (id, name, authType string) {
+	authType, _ = r.Context().Value(auth.AuthTypeContextKey).(string)
+	switch authType {
+	case "oauth":
+		if client, ok := r.Context().Value(auth.ClientContextKey).(*auth.OAuthClient); ok && client != nil {
+			name = client.ClientName
+			if name == "" {
+				name = client.ID
+			}
+
+			return "oauth:" + client.ID, name, authType
+		}
+		user, _ := r.Context().Value(auth.UserContextKey).(string)
+
+		return "oauth-user:" + user, user, authType
+	case "api_key":
+
+		return "api_key", "Proxy API key", authType
+	case auth.AuthTypeTrustedHeader:
+		user, _ := r.Context().Value(auth.UserContextKey).(string)
+
+		return "user:" + user, user, authType
+	}
+	address := getClientIP(r)
+
+	return "address:" + address, address, "none"
+}
+
+// estimateTokens approximates the model tokens a message takes up in a client's context
+func estimateTokens(size int) int64 {
+
+	return int64((size + constants.AnalyticsBytesPerToken - 1) / constants.AnalyticsBytesPerToken)
+}
+
+// record adds a finished request to its caller's usage
+func (a *clientAnalytics) record(r *http.Request, serverName, toolName string, failed bool, requestBytes, responseBytes int) {
+	id, name, authType := callerIdentity(r)
+	inputTokens, outputTokens := estimateTokens(requestBytes), estimateTokens(responseBytes)
+	now := time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	usage := a.clients[id]
+	if usage == nil {
+		if len(a.clients) >= constants.AnalyticsMaxClients {
+			id, name, authType = otherUsageKey, "Other clients", "mixed"
+			usage = a.clients[id]
+		}
+		if usage == nil {
+			usage = &clientUsage{
+				id:        id,
+				name:      name,
+				authType:  authType,
+				firstSeen: now,
+				servers:   make(map[string]*usageCounts),
+				tools:     make(map[string]*usageCounts),
+			}
+			a.clients[id] = usage
+		}
+	}
+	usage.lastSeen = now
+	usage.totals.add(failed, inputTokens, outputTokens)
+	countIn(usage.servers, serverName, 0).add(failed, inputTokens, outputTokens)
+	if toolName != "" {
+		countIn(usage.tools, serverName+"__"+toolName, constants.AnalyticsMaxToolsPerClient).add(failed, inputTokens, outputTokens)
+	}
+}
+
+// countIn returns the counts for key, folding keys past a positive limit into otherUsageKey
+func countIn(counts map[string]*usageCounts, key string, limit int) *usageCounts {
+	if c := counts[key]; c != nil {
+
+		return c
+	}
+	if limit > 0 && len(counts) >= limit {
+		key = otherUsageKey
+		if c := counts[key]; c != nil {
+
+			return c
+		}
+	}
+	c := &usageCounts{}
+	counts[key] = c
+
+	return c
+}
+
+// rankedUsage is one entry of a client's busiest servers or tools
+type rankedUsage struct {
+	Name string `json:"name"`
+	usageCounts
+}
+
+// clientUsageReport is the API view of a client's usage
+type clientUsageReport struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	AuthType  string        `json:"authType"`
+	FirstSeen time.Time     `json:"firstSeen"`
+	LastSeen  time.Time     `json:"lastSeen"`
+	ErrorRate float64       `json:"errorRate"`
+	Servers   []rankedUsage `json:"servers"`
+	TopTools  []rankedUsage `json:"topTools"`
+	usageCounts
+}
+
+// ranked orders counts by requests, busiest first, and keeps the first limit of them
+func ranked(counts map[string]*usageCounts, limit int) []rankedUsage {
+	entries := make([]rankedUsage, 0, len(counts))
+	for name, c := range counts {
+		entries = append(entries, rankedUsage{Name: name, usageCounts: *c})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Requests != entries[j].Requests {
+
+			return entries[i].Requests > entries[j].Requests
+		}
+
+		return entries[i].Name < entries[j].Name
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries
+}
+
+// report lists the usage of every client, busiest first. Clients that only used servers
+// other than serverName are left out when it is set, and the rest count only their use of it.
+func (a *clientAnalytics) report(serverName string) []clientUsageReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	reports := make([]clientUsageReport, 0, len(a.clients))
+	for _, usage := range a.clients {
+		report := clientUsageReport{
+			ID:          usage.id,
+			Name:        usage.name,
+			AuthType:    usage.authType,
+			FirstSeen:   usage.firstSeen,
+			LastSeen:    usage.lastSeen,
+			Servers:     ranked(usage.servers, 0),
+			TopTools:    ranked(usage.tools, constants.AnalyticsTopTools),
+			usageCounts: usage.totals,
+		}
+		if serverName != "" {
+			counts := usage.servers[serverName]
+			if counts == nil {
+
+				continue
+			}
+			report.usageCounts = *counts
+			report.Servers = []rankedUsage{{Name: serverName, usageCounts: *counts}}
+			tools := make(map[string]*usageCounts)
+			for name, c := range usage.tools {
+				if strings.HasPrefix(name, serverName+"__") {
+					tools[name] = c
+				}
+			}
+			report.TopTools = ranked(tools, constants.AnalyticsTopTools)
+		}
+		if report.Requests > 0 {
+			report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+		}
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Requests != reports[j].Requests {
+
+			return reports[i].Requests > reports[j].Requests
+		}
+
+		return reports[i].ID < reports[j].ID
+	})
+
+	return reports
+}
+
+// usageResponseWriter measures a forwarded call's response and notes whether it failed and
+// what the server said it cost
+type usageResponseWriter struct {
+	http.ResponseWriter
+	statusCode   int
+	size         int
+	failed       bool
+	cost         float64
+	costReported bool
+	inspected    bool
+}
+
+func (uw *usageResponseWriter) WriteHeader(statusCode int) {
+	if uw.statusCode == 0 {
+		uw.statusCode = statusCode
+	}
+	uw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (uw *usageResponseWriter) Write(body []byte) (int, error) {
+	if !uw.inspected {
+		uw.inspected = true
+		uw.failed, uw.cost, uw.costReported = inspectResult(body)
+	}
+	n, err := uw.ResponseWriter.Write(body)
+	uw.size += n
+
+	return n, err
+}
+
+func (uw *usageResponseWriter) Flush() {
+	if flusher, ok := uw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// inspectResult reports whether a response is a JSON-RPC error or a tool result flagged
+// isError, and the cost the server reported for the call. Responses that are not a single
+// JSON object, such as streams, count as successes.
+func inspectResult(body []byte) (failed bool, cost float64, costReported bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+
+		return false, 0, false
+	}
+
+	var envelope struct {
+		Error  json.RawMessage        `json:"error"`
+		Result map[string]interface{} `json:"result"`
+	}
+	if json.Unmarshal(trimmed, &envelope) != nil {
+
+		return false, 0, false
+	}
+	cost, costReported = reportedCost(envelope.Result)
+	failed = (len(envelope.Error) > 0 && string(envelope.Error) != "null") || envelope.Result["isError"] == true
+
+	return failed, cost, costReported
+}
+
+// trackUsage measures a request forwarded to a server; the returned finish records it and
+// its cost against the caller once the response is written
+func (h *ProxyHandler) trackUsage(w http.ResponseWriter, r *http.Request, serverName, toolName string, requestBytes int) (*usageResponseWriter, func()) {
+	uw := &usageResponseWriter{ResponseWriter: w}
+
+	return uw, func() {
+		failed := uw.failed || uw.statusCode >= http.StatusBadRequest
+		h.analytics.record(r, serverName, toolName, failed, requestBytes, uw.size)
+		cost := 0.0
+		if h.costs != nil {
+			cost = h.recordCost(r, serverName, toolName, uw, requestBytes)
+		}
+		if h.quotas != nil && (toolName != "" || cost > 0) {
+			clientID, clientName, _ := callerIdentity(r)
+			calls := int64(0)
+			if toolName != "" {
+				calls = 1
+			}
+			h.quotas.record(clientID, clientName, calls, cost)
+		}
+	}
+}
+
+// handleAnalyticsAPI serves GET /api/analytics/clients: requests, error rates, estimated
+// tokens and top tools per client since the proxy started. ?server= narrows it to the use
+// of one server.
+func (h *ProxyHandler) handleAnalyticsAPI(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+	if path != analyticsAPIPath+"/clients" {
+		writeAPIError(w, http.StatusNotFound, "not found")
+
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use GET")
+
+		return
+	}
+	if !h.scopeGranted(r, AdminReadScope, AdminWriteScope) {
+		publishAuthDenied(r, "", "analytics API scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", AdminReadScope))
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"since":   h.analytics.since,
+		"clients": h.analytics.report(r.URL.Query().Get("server")),
+	})
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func TestClientAnalytics(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage        `json:"id"`
+			Params map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		switch request.Params["name"] {
+		case "broken":
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"isError":true,"content":[]}}`, request.ID)
+		case "missing":
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32602,"message":"unknown tool"}}`, request.ID)
+		default:
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"content":[{"type":"text","text":"ok"}]}}`, request.ID)
+		}
+	}))
+	defer backend.Close()
+
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"alpha": {Protocol: "http"},
+		"beta":  {Protocol: "http"},
+	}}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager: newTestManager(cfg, logger, map[string]*ServerInstance{
+			"alpha": {Name: "alpha", Config: cfg.Servers["alpha"]},
+			"beta":  {Name: "beta", Config: cfg.Servers["beta"]},
+		}),
+		logger:     logger,
+		ctx:        context.Background(),
+		httpClient: http.DefaultClient,
+		catalog:    newCatalogCache(),
+		responses:  newResponseCache(),
+		analytics:  newClientAnalytics(),
+		ServerConnections: map[string]*MCPHTTPConnection{
+			"alpha": {ServerName: "alpha", BaseURL: backend.URL, Initialized: true, Healthy: true},
+			"beta":  {ServerName: "beta", BaseURL: backend.URL, Initialized: true, Healthy: true},
+		},
+	}
+
+	ide := &auth.OAuthClient{ID: "client-1", ClientName: "IDE plugin"}
+	call := func(serverName, tool string, ctx context.Context) {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q}}`, tool)
+		r := httptest.NewRequest(http.MethodPost, "/"+serverName, strings.NewReader(body)).WithContext(ctx)
+		instance, _ := h.Manager.GetServerInstance(serverName)
+		h.handleMCPMethodForwarding(httptest.NewRecorder(), r, serverName, instance)
+	}
+	oauthCtx := context.WithValue(context.WithValue(context.Background(), auth.AuthTypeContextKey, "oauth"), auth.ClientContextKey, ide)
+	for range 3 {
+		call("alpha", "search", oauthCtx)
+	}
+	call("alpha", "broken", oauthCtx)
+	call("beta", "missing", oauthCtx)
+	call("beta", "search", context.WithValue(context.Background(), auth.AuthTypeContextKey, "api_key"))
+
+	type report struct {
+		Clients []clientUsageReport `json:"clients"`
+	}
+	get := func(query string) report {
+		recorder := httptest.NewRecorder()
+		h.handleAnalyticsAPI(recorder, httptest.NewRequest(http.MethodGet, "/api/analytics/clients"+query, nil), "/api/analytics/clients")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("analytics API: expected 200, got %d %s", recorder.Code, recorder.Body.String())
+		}
+		var decoded report
+		if err := json.Unmarshal(recorder.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("analytics API: %v", err)
+		}
+
+		return decoded
+	}
+
+	all := get("")
+	if len(all.Clients) != 2 {
+		t.Fatalf("expected 2 clients, got %+v", all.Clients)
+	}
+	plugin := all.Clients[0]
+	if plugin.ID != "oauth:client-1" || plugin.Name != "IDE plugin" || plugin.AuthType != "oauth" {
+		t.Errorf("the busiest client should be the OAuth client, got %+v", plugin)
+	}
+	if plugin.Requests != 5 || plugin.Errors != 2 || plugin.ErrorRate != 0.4 {
+		t.Errorf("expected 5 requests with 2 errors, got %d requests, %d errors, rate %v", plugin.Requests, plugin.Errors, plugin.ErrorRate)
+	}
+	if plugin.InputTokens == 0 || plugin.OutputTokens == 0 {
+		t.Errorf("token usage should be estimated, got %+v", plugin.usageCounts)
+	}
+	if len(plugin.TopTools) != 3 || plugin.TopTools[0].Name != "alpha__search" || plugin.TopTools[0].Requests != 3 {
+		t.Errorf("top tools should be ordered by calls, got %+v", plugin.TopTools)
+	}
+	if len(plugin.Servers) != 2 || plugin.Servers[0].Name != "alpha" || plugin.Servers[0].Requests != 4 {
+		t.Errorf("servers should be ordered by requests, got %+v", plugin.Servers)
+	}
+	if key := all.Clients[1]; key.ID != "api_key" || key.Requests != 1 || key.Errors != 0 {
+		t.Errorf("the API key should be counted on its own, got %+v", key)
+	}
+
+	beta := get("?server=beta")
+	if len(beta.Clients) != 2 || beta.Clients[0].Requests != 1 || len(beta.Clients[0].TopTools) != 1 {
+		t.Errorf("a server filter should count only that server, got %+v", beta.Clients)
+	}
+	if alpha := get("?server=alpha"); len(alpha.Clients) != 1 {
+		t.Errorf("clients that never used a server should be left out, got %+v", alpha.Clients)
+	}
+}
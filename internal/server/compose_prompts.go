@@ -0,0 +1,99 @@
+// internal/server/compose_prompts.go
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/prompts"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// serveComposePrompt answers prompts/get for a prompt declared under the server in the
+// compose file. It reports false when the server should answer instead.
+func (h *ProxyHandler) serveComposePrompt(w http.ResponseWriter, serverName string, declared []config.PromptConfig, requestPayload map[string]interface{}, reqIDVal interface{}) bool {
+	params, _ := requestPayload["params"].(map[string]interface{})
+	name, _ := params["name"].(string)
+	prompt, exists := prompts.Find(declared, name)
+	if !exists {
+
+		return false
+	}
+
+	arguments := make(map[string]string)
+	if raw, ok := params["arguments"].(map[string]interface{}); ok {
+		for key, value := range raw {
+			text, isString := value.(string)
+			if !isString {
+				h.sendMCPError(w, reqIDVal, protocol.InvalidParams, "Prompt arguments must be strings: "+key)
+
+				return true
+			}
+			arguments[key] = text
+		}
+	}
+
+	result, err := prompts.Render(prompt, arguments)
+	if err != nil {
+		h.sendMCPError(w, reqIDVal, protocol.InvalidParams, err.Error())
+
+		return true
+	}
+	h.logger.Debug("Serving prompt %s of %s from the compose file", name, serverName)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": reqIDVal, "result": result}); err != nil {
+		h.logger.Error("Failed to encode prompts/get response: %v", err)
+	}
+
+	return true
+}
+
+// listWithComposePrompts adds the prompts declared under the server in the compose file to
+// the first page of its prompts/list result, replacing server prompts of the same name.
+// Servers without prompts of their own, or that cannot be reached, list just the declared ones.
+func (h *ProxyHandler) listWithComposePrompts(w http.ResponseWriter, serverName string, declared []config.PromptConfig, forward func(http.ResponseWriter), requestPayload map[string]interface{}, reqIDVal interface{}) {
+	if params, _ := requestPayload["params"].(map[string]interface{}); params["cursor"] != nil {
+		forward(w)
+
+		return
+	}
+
+	recorder := &mcpResponseRecorder{statusCode: http.StatusOK, headers: make(http.Header)}
+	forward(recorder)
+
+	var response struct {
+		Result *struct {
+			Prompts    []map[string]interface{} `json:"prompts"`
+			NextCursor string                   `json:"nextCursor,omitempty"`
+		} `json:"result"`
+		Error *MCPError `json:"error"`
+	}
+	merged := make([]interface{}, 0)
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(recorder.body, &response); err != nil || response.Result == nil {
+		h.logger.Debug("Listing only compose file prompts for %s (HTTP %d)", serverName, recorder.statusCode)
+	} else {
+		for _, prompt := range response.Result.Prompts {
+			if name, _ := prompt["name"].(string); name != "" {
+				if _, overridden := prompts.Find(declared, name); overridden {
+
+					continue
+				}
+			}
+			merged = append(merged, prompt)
+		}
+		if response.Result.NextCursor != "" {
+			result["nextCursor"] = response.Result.NextCursor
+		}
+	}
+	for _, definition := range prompts.List(declared) {
+		merged = append(merged, definition)
+	}
+	result["prompts"] = merged
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": reqIDVal, "result": result}); err != nil {
+		h.logger.Error("Failed to encode prompts/list response: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestComposePrompts(t *testing.T) {
+	var forwarded atomic.Int32
+	var withoutPrompts atomic.Bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded.Add(1)
+		var request map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		if withoutPrompts.Load() {
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"error":{"code":-32601,"message":"Method not found"}}`, request["id"])
+
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{"prompts":[{"name":"own"},{"name":"review","description":"old"}]}}`, request["id"])
+	}))
+	defer backend.Close()
+
+	h := limitedHandler(nil)
+	h.Manager.Config().Servers["files"] = config.ServerConfig{
+		Protocol: "http",
+		Prompts: []config.PromptConfig{{
+			Name:      "review",
+			Template:  "Review {{file}}",
+			Variables: []config.PromptVariable{{Name: "file", Required: true}},
+		}},
+	}
+	h.ctx = context.Background()
+	h.httpClient = http.DefaultClient
+	h.sseClient = http.DefaultClient
+	h.catalog = newCatalogCache()
+	h.responses = newResponseCache()
+	h.ServerConnections = map[string]*MCPHTTPConnection{
+		"files": {ServerName: "files", BaseURL: backend.URL, Initialized: true, Healthy: true},
+	}
+	instance := &ServerInstance{Name: "files"}
+
+	send := func(method, params string) MCPResponse {
+		body := []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"%s","params":%s}`, method, params))
+		var payload map[string]interface{}
+		_ = json.Unmarshal(body, &payload)
+		recorder := httptest.NewRecorder()
+		h.dispatchToTransport(recorder, httptest.NewRequest(http.MethodPost, "/files", nil), "files", instance, body, payload, 1, method)
+		var response MCPResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: response is not JSON-RPC: %s", method, recorder.Body.String())
+		}
+
+		return response
+	}
+
+	listed := string(mustJSON(t, send("prompts/list", `{}`).Result))
+	if !strings.Contains(listed, `"own"`) || strings.Contains(listed, `"old"`) || !strings.Contains(listed, `"file"`) {
+		t.Errorf("expected the server's prompts with review replaced, got %s", listed)
+	}
+
+	withoutPrompts.Store(true)
+	h.catalog.clear()
+	listed = string(mustJSON(t, send("prompts/list", `{}`).Result))
+	if strings.Contains(listed, `"own"`) || !strings.Contains(listed, `"review"`) {
+		t.Errorf("expected only the compose file's prompts, got %s", listed)
+	}
+
+	before := forwarded.Load()
+	got := send("prompts/get", `{"name":"review","arguments":{"file":"main.go"}}`)
+	if !strings.Contains(string(mustJSON(t, got.Result)), "Review main.go") {
+		t.Errorf("unexpected prompts/get result %+v", got)
+	}
+	if missing := send("prompts/get", `{"name":"review"}`); missing.Error == nil || missing.Error.Code != -32602 {
+		t.Errorf("expected invalid params for a missing argument, got %+v", missing)
+	}
+	if forwarded.Load() != before {
+		t.Error("compose file prompts should not reach the server")
+	}
+	send("prompts/get", `{"name":"own"}`)
+	if forwarded.Load() == before {
+		t.Error("the server's own prompts should be forwarded")
+	}
+}
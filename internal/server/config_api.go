@@ -0,0 +1,358 @@
+// internal/server/config_api.go
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+)
+
+// configProblem is a validation error as the config editor shows it
+type configProblem struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+type configEditRequest struct {
+	Content  string `json:"content"`
+	BaseHash string `json:"baseHash,omitempty"` // hash of the file the edit started from
+	Source   string `json:"source,omitempty"`   // "cli" for mcp-compose config set; the dashboard otherwise
+	Message  string `json:"message,omitempty"`  // recorded in the file's history
+}
+
+type configRollbackRequest struct {
+	Version int `json:"version"`
+}
+
+// handleConfigAPI serves the dashboard's config editor: GET /api/config returns the compose
+// file, POST /api/config/validate checks proposed contents and previews their effect, and
+// POST /api/config/apply writes the new contents, recording them in the file's history, and
+// restarts the servers they change. GET /api/config/history lists the recorded versions and
+// POST /api/config/rollback brings one back the same way. The editor is off unless
+// dashboard.config_editor is set.
+func (h *ProxyHandler) handleConfigAPI(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.Manager.Config().Dashboard.ConfigEditor {
+		h.writeConfigError(w, http.StatusNotFound, "the config editor is disabled (set dashboard.config_editor)")
+
+		return
+	}
+
+	switch path {
+	case "/api/config":
+		if r.Method != http.MethodGet {
+			h.writeConfigError(w, http.StatusMethodNotAllowed, "Method not allowed - use GET")
+
+			return
+		}
+		h.handleConfigGet(w)
+	case "/api/config/history":
+		if r.Method != http.MethodGet {
+			h.writeConfigError(w, http.StatusMethodNotAllowed, "Method not allowed - use GET")
+
+			return
+		}
+		h.handleConfigHistory(w)
+	case "/api/config/rollback":
+		if r.Method != http.MethodPost {
+			h.writeConfigError(w, http.StatusMethodNotAllowed, "Method not allowed - use POST")
+
+			return
+		}
+		var req configRollbackRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, constants.ConfigEditorMaxSize)).Decode(&req); err != nil {
+			h.writeConfigError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+
+			return
+		}
+		h.handleConfigRollback(w, r, req)
+	case "/api/config/validate", "/api/config/apply":
+		if r.Method != http.MethodPost {
+			h.writeConfigError(w, http.StatusMethodNotAllowed, "Method not allowed - use POST")
+
+			return
+		}
+		var req configEditRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, constants.ConfigEditorMaxSize)).Decode(&req); err != nil {
+			h.writeConfigError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+
+			return
+		}
+		if path == "/api/config/validate" {
+			h.handleConfigValidate(w, req)
+		} else {
+			h.handleConfigApply(w, r, req)
+		}
+	default:
+		h.writeConfigError(w, http.StatusNotFound, "unknown config endpoint")
+	}
+}
+
+func (h *ProxyHandler) handleConfigGet(w http.ResponseWriter) {
+	data, err := os.ReadFile(h.ConfigFile)
+	if err != nil {
+		h.writeConfigError(w, http.StatusInternalServerError, fmt.Sprintf("failed to read config file: %v", err))
+
+		return
+	}
+	_, remote := config.RemoteSource(h.ConfigFile)
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"file":      config.DisplayName(h.ConfigFile),
+		"content":   string(data),
+		"hash":      config.ContentHash(data),
+		"editable":  !remote,
+		"overrides": config.OverrideFiles(),
+	})
+}
+
+func (h *ProxyHandler) handleConfigValidate(w http.ResponseWriter, req configEditRequest) {
+	current, err := os.ReadFile(h.ConfigFile)
+	if err != nil {
+		h.writeConfigError(w, http.StatusInternalServerError, fmt.Sprintf("failed to read config file: %v", err))
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"diff": config.UnifiedDiff(config.DisplayName(h.ConfigFile), config.DisplayName(h.ConfigFile)+" (edited)", string(current), req.Content),
+	}
+	proposed, problems, err := h.parseProposedConfig(req.Content)
+	if err != nil {
+		h.writeConfigError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+	response["valid"] = len(problems) == 0
+	response["errors"] = problems
+	if proposed != nil {
+		response["changes"] = config.DiffConfigs(h.Manager.Config(), proposed)
+	}
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (h *ProxyHandler) handleConfigApply(w http.ResponseWriter, r *http.Request, req configEditRequest) {
+	if source, remote := config.RemoteSource(h.ConfigFile); remote {
+		h.writeConfigError(w, http.StatusConflict, fmt.Sprintf("the compose file is fetched from %s; edit it there", source))
+
+		return
+	}
+	current, err := os.ReadFile(h.ConfigFile)
+	if err != nil {
+		h.writeConfigError(w, http.StatusInternalServerError, fmt.Sprintf("failed to read config file: %v", err))
+
+		return
+	}
+	if req.BaseHash != "" && req.BaseHash != config.ContentHash(current) {
+		h.writeConfigError(w, http.StatusConflict, "the compose file changed since it was loaded; reload it and apply your edits again")
+
+		return
+	}
+
+	proposed, problems, err := h.parseProposedConfig(req.Content)
+	if err != nil {
+		h.writeConfigError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+	if len(problems) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"valid":  false,
+			"error":  "the edited configuration is invalid",
+			"errors": problems,
+		})
+
+		return
+	}
+
+	source := config.VersionSourceDashboard
+	if req.Source == config.VersionSourceCLI {
+		source = config.VersionSourceCLI
+	}
+	h.writeAndApplyConfig(w, r, []byte(req.Content), proposed, config.ConfigVersion{
+		Source:  source,
+		Client:  getClientIP(r),
+		Message: req.Message,
+	})
+}
+
+func (h *ProxyHandler) handleConfigHistory(w http.ResponseWriter) {
+	versions, err := config.ConfigHistory(h.ConfigFile)
+	if err != nil {
+		h.writeConfigError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+	current := ""
+	if data, err := os.ReadFile(h.ConfigFile); err == nil {
+		current = config.ContentHash(data)
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"file":        config.DisplayName(h.ConfigFile),
+		"currentHash": current,
+		"versions":    versions,
+	})
+}
+
+func (h *ProxyHandler) handleConfigRollback(w http.ResponseWriter, r *http.Request, req configRollbackRequest) {
+	if source, remote := config.RemoteSource(h.ConfigFile); remote {
+		h.writeConfigError(w, http.StatusConflict, fmt.Sprintf("the compose file is fetched from %s; edit it there", source))
+
+		return
+	}
+	_, content, err := config.ConfigVersionContent(h.ConfigFile, req.Version)
+	if err != nil {
+		h.writeConfigError(w, http.StatusNotFound, err.Error())
+
+		return
+	}
+
+	// A version valid when it was written can be invalid now, e.g. when a secret it uses is gone
+	proposed, problems, err := h.parseProposedConfig(string(content))
+	if err != nil {
+		h.writeConfigError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+	if len(problems) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"valid":  false,
+			"error":  fmt.Sprintf("version %d is not a valid configuration any more", req.Version),
+			"errors": problems,
+		})
+
+		return
+	}
+
+	h.writeAndApplyConfig(w, r, content, proposed, config.ConfigVersion{
+		Source:  config.VersionSourceRollback,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("rolled back to version %d", req.Version),
+	})
+}
+
+// writeAndApplyConfig writes validated contents to the compose file, recording them in its
+// history, and restarts the servers they change
+func (h *ProxyHandler) writeAndApplyConfig(w http.ResponseWriter, r *http.Request, content []byte, proposed *config.ComposeConfig, change config.ConfigVersion) {
+	previous, written, err := config.WriteConfigFile(h.ConfigFile, content, change)
+	if written == nil {
+		h.writeConfigError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+	if err != nil {
+		h.logger.Warning("Config file %s written but its history was not updated: %v", h.ConfigFile, err)
+	}
+	backup := ""
+	if previous != nil {
+		backup = filepath.Join(constants.ConfigBackupDir, previous.File)
+	}
+	h.logger.Info("Config file %s updated to version %d from %s (%s)", h.ConfigFile, written.Version, r.RemoteAddr, change.Source)
+
+	changes, restarted, applyErr := h.applyConfig(proposed)
+
+	verb := "edited"
+	if change.Source == config.VersionSourceRollback {
+		verb = change.Message
+	}
+	events.Publish(events.Event{
+		Type:    events.ConfigReloaded,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("Config %s: %d added, %d removed, %d changed, %d restarted", verb, len(changes.Added), len(changes.Removed), len(changes.Changed), len(restarted)),
+		Details: map[string]interface{}{"changes": changes, "restarted": restarted, "backup": backup, "version": written.Version},
+	})
+
+	response := map[string]interface{}{
+		"status":          "success",
+		"version":         written.Version,
+		"backup":          backup,
+		"changes":         changes,
+		"restarted":       restarted,
+		"restartRequired": changes.Global,
+		"timestamp":       time.Now().Format(time.RFC3339),
+	}
+	if applyErr != nil {
+		response["status"] = "partial"
+		response["error"] = applyErr.Error()
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// applyConfig hands a validated configuration to the manager, which restarts the servers it
+// changes, drops the proxy's connections to those servers, follows their watched resource
+// paths and tells the others when their roots changed
+func (h *ProxyHandler) applyConfig(proposed *config.ComposeConfig) (config.ConfigChanges, []string, error) {
+	previous := h.Manager.Config()
+	changes, restarted, err := h.Manager.ApplyConfig(proposed)
+	h.setMiddlewareChains(h.Manager.Config())
+	h.setServerAccess(h.Manager.Config())
+	for _, name := range append(append(append([]string{}, changes.Added...), changes.Removed...), changes.Changed...) {
+		h.dropServerConnections(name)
+	}
+	h.syncResourceWatchers()
+	h.notifyRootsChangedAfterEdit(previous, proposed)
+	h.toolCacheMu.Lock()
+	h.cacheExpiry = time.Now()
+	h.toolCache = make(map[string]string)
+	h.toolCacheMu.Unlock()
+
+	return changes, restarted, err
+}
+
+// parseProposedConfig validates proposed compose file contents, returning the problems
+// found with their lines
+func (h *ProxyHandler) parseProposedConfig(content string) (*config.ComposeConfig, []configProblem, error) {
+	proposed, err := config.ParseConfig(h.ConfigFile, []byte(content))
+	if err == nil {
+		if _, err := AddBuiltInServers(proposed); err != nil {
+
+			return nil, []configProblem{{Path: "builtins", Message: err.Error()}}, nil
+		}
+		// Servers keep running the images the lock file pins, as they were started with them
+		if _, err := proposed.ApplyImageLock(h.ConfigFile); err != nil {
+
+			return nil, []configProblem{{Message: err.Error()}}, nil
+		}
+
+		return proposed, []configProblem{}, nil
+	}
+	var invalid config.ValidationErrors
+	if !errors.As(err, &invalid) {
+
+		return nil, []configProblem{{Message: err.Error()}}, nil
+	}
+
+	problems := make([]configProblem, 0, len(invalid))
+	for _, problem := range invalid {
+		problems = append(problems, configProblem{
+			File:    problem.File,
+			Line:    problem.Line,
+			Column:  problem.Column,
+			Path:    problem.Path,
+			Message: problem.Message,
+		})
+	}
+
+	return nil, problems, nil
+}
+
+func (h *ProxyHandler) writeConfigError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
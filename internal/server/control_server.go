@@ -0,0 +1,330 @@
+// internal/server/control_server.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// controlTool is one tool of the built-in control server
+type controlTool struct {
+	name        string
+	description string
+	write       bool // needs AdminWriteScope; left out when the control server is read-only
+	properties  map[string]interface{}
+	required    []string
+	call        func(h *ProxyHandler, r *http.Request, args map[string]interface{}) (interface{}, error)
+}
+
+var controlServerArg = map[string]interface{}{"type": "string", "description": "Name of a server in the compose file"}
+
+var controlTools = []controlTool{
+	{name: "get_status", description: "Show the proxy's version, uptime and server counts",
+		call: func(h *ProxyHandler, _ *http.Request, _ map[string]interface{}) (interface{}, error) {
+
+			return h.proxyStatus(), nil
+		}},
+	{name: "list_servers", description: "List the servers of the compose file with their status and health",
+		call: func(h *ProxyHandler, _ *http.Request, _ map[string]interface{}) (interface{}, error) {
+
+			return map[string]interface{}{"servers": h.adminServers()}, nil
+		}},
+	{name: "get_server", description: "Show the status, health and command of one server",
+		properties: map[string]interface{}{"name": controlServerArg}, required: []string{"name"},
+		call: func(h *ProxyHandler, _ *http.Request, args map[string]interface{}) (interface{}, error) {
+			serverName, err := h.controlServerName(args)
+			if err != nil {
+
+				return nil, err
+			}
+
+			return h.adminServerFor(serverName), nil
+		}},
+	{name: "get_logs", description: "Read the most recent log lines of a server",
+		properties: map[string]interface{}{
+			"name": controlServerArg,
+			"tail": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": constants.AdminAPIMaxLogLines,
+				"description": fmt.Sprintf("Number of lines, default %d", constants.AdminAPILogLines)},
+			"raw": map[string]interface{}{"type": "boolean", "description": "Skip the server's log_filters"},
+		},
+		required: []string{"name"},
+		call: func(h *ProxyHandler, _ *http.Request, args map[string]interface{}) (interface{}, error) {
+			serverName, err := h.controlServerName(args)
+			if err != nil {
+
+				return nil, err
+			}
+			tail := constants.AdminAPILogLines
+			if value, ok := args["tail"].(float64); ok {
+				if value < 1 || value > constants.AdminAPIMaxLogLines {
+
+					return nil, fmt.Errorf("tail must be between 1 and %d", constants.AdminAPIMaxLogLines)
+				}
+				tail = int(value)
+			}
+			raw, _ := args["raw"].(bool)
+			lines, err := h.serverLogLines(serverName, tail, raw)
+			if err != nil {
+
+				return nil, err
+			}
+
+			return map[string]interface{}{"server": serverName, "lines": lines}, nil
+		}},
+	{name: "start_server", description: "Start a stopped server", write: true,
+		properties: map[string]interface{}{"name": controlServerArg}, required: []string{"name"},
+		call: controlServerAction("start")},
+	{name: "stop_server", description: "Stop a running server", write: true,
+		properties: map[string]interface{}{"name": controlServerArg}, required: []string{"name"},
+		call: controlServerAction("stop")},
+	{name: "restart_server", description: "Restart a server, for example after it stopped responding", write: true,
+		properties: map[string]interface{}{"name": controlServerArg}, required: []string{"name"},
+		call: controlServerAction("restart")},
+	{name: "list_tasks", description: "List the task scheduler's tasks",
+		call: func(h *ProxyHandler, r *http.Request, _ map[string]interface{}) (interface{}, error) {
+
+			return h.callControlTaskTool(r, "list_tasks", map[string]interface{}{})
+		}},
+	{name: "run_task", description: "Run a scheduled task now", write: true,
+		properties: map[string]interface{}{"id": map[string]interface{}{"type": "string", "description": "ID of the task"}},
+		required:   []string{"id"},
+		call: func(h *ProxyHandler, r *http.Request, args map[string]interface{}) (interface{}, error) {
+			id, _ := args["id"].(string)
+			if !taskIDPattern.MatchString(id) {
+
+				return nil, fmt.Errorf("invalid task id '%s'", id)
+			}
+
+			return h.callControlTaskTool(r, "run_task", map[string]interface{}{"id": id})
+		}},
+}
+
+// controlServerAction returns the call of a lifecycle tool
+func controlServerAction(action string) func(*ProxyHandler, *http.Request, map[string]interface{}) (interface{}, error) {
+
+	return func(h *ProxyHandler, r *http.Request, args map[string]interface{}) (interface{}, error) {
+		serverName, err := h.controlServerName(args)
+		if err != nil {
+
+			return nil, err
+		}
+		h.logger.Info("Control server: %s server '%s' from %s", action, serverName, getClientIP(r))
+		if err := h.runServerAction(serverName, action); err != nil {
+
+			return nil, fmt.Errorf("failed to %s server '%s': %w", action, serverName, err)
+		}
+
+		return h.adminServerFor(serverName), nil
+	}
+}
+
+// controlServerName returns the server a tool call names, which must be in the compose file
+func (h *ProxyHandler) controlServerName(args map[string]interface{}) (string, error) {
+	serverName, _ := args["name"].(string)
+	if _, exists := h.Manager.Config().Servers[serverName]; !exists {
+
+		return "", fmt.Errorf("unknown server '%s'", serverName)
+	}
+
+	return serverName, nil
+}
+
+func (h *ProxyHandler) callControlTaskTool(r *http.Request, tool string, args map[string]interface{}) (interface{}, error) {
+	instance, exists := h.Manager.GetServerInstance(taskSchedulerService{}.Name())
+	if !exists {
+
+		return nil, fmt.Errorf("the task scheduler is not enabled")
+	}
+	result, _, err := h.callTaskTool(r, instance, tool, args)
+
+	return result, err
+}
+
+// isControlServerPath reports whether the request targets the built-in control server
+func (h *ProxyHandler) isControlServerPath(path string) bool {
+
+	return h.Manager.Config().ControlServer.Enabled && path == "/"+constants.ControlServerName
+}
+
+// controlToolsFor returns the tools the caller may see: all of them with AdminWriteScope,
+// only the read-only ones otherwise or when the control server is read-only
+func (h *ProxyHandler) controlToolsFor(r *http.Request) []controlTool {
+	canWrite := !h.Manager.Config().ControlServer.ReadOnly && h.scopeGranted(r, AdminWriteScope, AdminWriteScope)
+	tools := make([]controlTool, 0, len(controlTools))
+	for _, tool := range controlTools {
+		if !tool.write || canWrite {
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
+}
+
+// handleControlServer serves the proxy's management operations as an MCP server, so agents
+// connected through the proxy can manage the stack. It takes the admin API's credentials.
+func (h *ProxyHandler) handleControlServer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.corsError(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+	if !h.scopeGranted(r, AdminReadScope, AdminWriteScope) {
+		publishAuthDenied(r, constants.ControlServerName, "admin scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", AdminReadScope))
+
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, constants.ControlServerMaxBodySize))
+	if err != nil {
+		h.sendMCPError(w, nil, protocol.ParseError, "Error reading request body")
+
+		return
+	}
+	var requestPayload map[string]interface{}
+	if err := json.Unmarshal(body, &requestPayload); err != nil {
+		h.sendMCPError(w, nil, protocol.ParseError, "Invalid JSON in request")
+
+		return
+	}
+	reqIDVal := requestPayload["id"]
+	reqMethodVal, _ := requestPayload["method"].(string)
+	params, _ := requestPayload["params"].(map[string]interface{})
+
+	switch {
+	case reqMethodVal == "initialize":
+		h.writeControlResult(w, reqIDVal, protocol.CreateInitializeResponse(
+			protocol.ServerInfo{Name: constants.ControlServerName, Version: adminAPIVersion},
+			protocol.CapabilitiesOpts{Tools: &protocol.ToolsOpts{}},
+			nil,
+		))
+	case reqMethodVal == "ping":
+		h.writeControlResult(w, reqIDVal, map[string]interface{}{})
+	case strings.HasPrefix(reqMethodVal, "notifications/"):
+		w.WriteHeader(http.StatusAccepted)
+	case reqMethodVal == "tools/list":
+		tools := make([]map[string]interface{}, 0, len(controlTools))
+		for _, tool := range h.controlToolsFor(r) {
+			tools = append(tools, tool.definition())
+		}
+		h.writeControlResult(w, reqIDVal, map[string]interface{}{"tools": tools})
+	case reqMethodVal == "tools/call":
+		h.callControlTool(w, r, reqIDVal, params)
+	default:
+		h.sendMCPError(w, reqIDVal, protocol.MethodNotFound, "Method not supported by the control server: "+reqMethodVal)
+	}
+}
+
+func (h *ProxyHandler) callControlTool(w http.ResponseWriter, r *http.Request, reqIDVal interface{}, params map[string]interface{}) {
+	name, _ := params["name"].(string)
+	args, _ := params["arguments"].(map[string]interface{})
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+
+	var tool *controlTool
+	for i := range controlTools {
+		if controlTools[i].name == name {
+			tool = &controlTools[i]
+
+			break
+		}
+	}
+	if tool == nil {
+		h.sendMCPError(w, reqIDVal, protocol.InvalidParams, "Unknown tool: "+name)
+
+		return
+	}
+	if tool.write {
+		if h.Manager.Config().ControlServer.ReadOnly {
+			h.sendMCPError(w, reqIDVal, -32001, "Forbidden", "the control server is read-only")
+
+			return
+		}
+		if !h.scopeGranted(r, AdminWriteScope, AdminWriteScope) {
+			publishAuthDenied(r, constants.ControlServerName, "admin write scope not granted")
+			h.sendMCPError(w, reqIDVal, -32001, "Forbidden", fmt.Sprintf("tool '%s' requires scope '%s'", name, AdminWriteScope))
+
+			return
+		}
+	}
+	if err := validateToolArguments(tool.definition()["inputSchema"].(map[string]interface{}), args); err != nil {
+		h.sendMCPError(w, reqIDVal, protocol.InvalidParams, err.Error())
+
+		return
+	}
+
+	events.Publish(events.Event{
+		Type:    events.ToolCalled,
+		Server:  constants.ControlServerName,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("Control server tool %s called", name),
+		Details: map[string]interface{}{"tool": name, "arguments": args},
+	})
+
+	// Failures are tool results, so the agent sees what went wrong
+	result, err := tool.call(h, r, args)
+	if err != nil {
+		h.writeControlResult(w, reqIDVal, map[string]interface{}{
+			"content": []map[string]interface{}{{"type": "text", "text": err.Error()}},
+			"isError": true,
+		})
+
+		return
+	}
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		h.sendMCPError(w, reqIDVal, protocol.InternalError, fmt.Sprintf("failed to encode %s result: %v", name, err))
+
+		return
+	}
+	h.writeControlResult(w, reqIDVal, map[string]interface{}{
+		"content": []map[string]interface{}{{"type": "text", "text": string(text)}},
+	})
+}
+
+// definition describes the tool as tools/list reports it
+func (tool controlTool) definition() map[string]interface{} {
+	properties := tool.properties
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(tool.required) > 0 {
+		required := make([]interface{}, len(tool.required))
+		for i, name := range tool.required {
+			required[i] = name
+		}
+		schema["required"] = required
+	}
+
+	return map[string]interface{}{
+		"name":        tool.name,
+		"description": tool.description,
+		"inputSchema": schema,
+		"annotations": map[string]interface{}{"readOnlyHint": !tool.write, "destructiveHint": tool.write},
+	}
+}
+
+func (h *ProxyHandler) writeControlResult(w http.ResponseWriter, reqIDVal interface{}, result interface{}) {
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      reqIDVal,
+		"result":  result,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode control server response: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func TestControlServer(t *testing.T) {
+	cfg := &config.ComposeConfig{
+		Servers:       map[string]config.ServerConfig{"files": {Command: "cat"}},
+		ControlServer: config.ControlServerConfig{Enabled: true},
+	}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{Manager: newTestManager(cfg, logger, map[string]*ServerInstance{}), logger: logger}
+
+	if !h.isControlServerPath("/"+constants.ControlServerName) || h.isControlServerPath("/files") {
+		t.Fatal("control server path not recognized")
+	}
+
+	call := func(scope, body string) (int, MCPResponse) {
+		ctx := context.WithValue(context.Background(), auth.AuthTypeContextKey, "oauth")
+		ctx = context.WithValue(ctx, auth.ScopeContextKey, scope)
+		request := httptest.NewRequest(http.MethodPost, "/"+constants.ControlServerName, strings.NewReader(body)).WithContext(ctx)
+		recorder := httptest.NewRecorder()
+		h.handleControlServer(recorder, request)
+		var response MCPResponse
+		_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		return recorder.Code, response
+	}
+	toolNames := func(response MCPResponse) []string {
+		var result struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+		}
+		data, _ := json.Marshal(response.Result)
+		_ = json.Unmarshal(data, &result)
+		names := make([]string, 0, len(result.Tools))
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+
+		return names
+	}
+	list := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+
+	if status, _ := call("mcp:tools", list); status != http.StatusForbidden {
+		t.Errorf("caller without an admin scope: expected 403, got %d", status)
+	}
+
+	_, response := call(AdminReadScope, list)
+	readTools := strings.Join(toolNames(response), ",")
+	if !strings.Contains(readTools, "list_servers") || strings.Contains(readTools, "restart_server") {
+		t.Errorf("read scope should only see read-only tools, got %s", readTools)
+	}
+	_, response = call(AdminWriteScope, list)
+	if writeTools := strings.Join(toolNames(response), ","); !strings.Contains(writeTools, "restart_server") || !strings.Contains(writeTools, "run_task") {
+		t.Errorf("write scope should see every tool, got %s", writeTools)
+	}
+
+	_, response = call(AdminReadScope, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"restart_server","arguments":{"name":"files"}}}`)
+	if response.Error == nil || response.Error.Code != -32001 {
+		t.Errorf("restart with read scope: expected a forbidden error, got %+v", response)
+	}
+	_, response = call(AdminReadScope, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_logs","arguments":{}}}`)
+	if response.Error == nil || response.Error.Code != -32602 {
+		t.Errorf("missing server name: expected invalid params, got %+v", response)
+	}
+
+	_, response = call(AdminReadScope, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"list_servers"}}`)
+	if response.Error != nil || !strings.Contains(string(mustJSON(t, response.Result)), `\"name\": \"files\"`) {
+		t.Errorf("unexpected list_servers result %+v", response)
+	}
+	_, response = call(AdminReadScope, `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"get_server","arguments":{"name":"nope"}}}`)
+	if !strings.Contains(string(mustJSON(t, response.Result)), `"isError":true`) {
+		t.Errorf("unknown server should be a tool error, got %+v", response)
+	}
+	_, response = call(AdminWriteScope, `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"run_task","arguments":{"id":"nightly"}}}`)
+	if !strings.Contains(string(mustJSON(t, response.Result)), "task scheduler is not enabled") {
+		t.Errorf("run_task without a scheduler should say so, got %+v", response)
+	}
+
+	cfg.ControlServer.ReadOnly = true
+	_, response = call(AdminWriteScope, list)
+	if strings.Contains(strings.Join(toolNames(response), ","), "restart_server") {
+		t.Error("read-only control server should hide write tools")
+	}
+}
+
+func mustJSON(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	return data
+}
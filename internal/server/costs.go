@@ -0,0 +1,270 @@
+// internal/server/costs.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+const costsAPIPath = "/api/costs"
+
+// costMetaKey is where a server reports what a call cost, in a result's _meta
+const costMetaKey = "mcp-compose/cost"
+
+// costKey is one line of the cost ledger: the calls a client made to one tool on one day
+type costKey struct {
+	day      string // UTC date, as YYYY-MM-DD
+	clientID string
+	server   string
+	tool     string
+}
+
+// costEntry is what the calls behind a costKey cost
+type costEntry struct {
+	Calls    int64   `json:"calls"`
+	Cost     float64 `json:"cost"`
+	Reported int64   `json:"reported"` // Calls whose cost the server reported, rather than the proxy estimated
+}
+
+// costLedger adds up the cost of calls to paid servers per day, client and tool, keeping
+// the last constants.CostRetentionDays days
+type costLedger struct {
+	mu          sync.Mutex
+	entries     map[costKey]*costEntry
+	clientNames map[string]string
+	today       string
+}
+
+func newCostLedger() *costLedger {
+
+	return &costLedger{entries: make(map[costKey]*costEntry), clientNames: make(map[string]string)}
+}
+
+func costDay(t time.Time) string {
+
+	return t.UTC().Format(time.DateOnly)
+}
+
+// record adds the cost of one call
+func (l *costLedger) record(r *http.Request, serverName, toolName string, cost float64, reported bool) {
+	clientID, clientName, _ := callerIdentity(r)
+	now := time.Now()
+	day := costDay(now)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if day != l.today {
+		l.today = day
+		oldest := costDay(now.AddDate(0, 0, 1-constants.CostRetentionDays))
+		for key := range l.entries {
+			if key.day < oldest {
+				delete(l.entries, key)
+			}
+		}
+	}
+	key := costKey{day: day, clientID: clientID, server: serverName, tool: toolName}
+	entry := l.entries[key]
+	if entry == nil {
+		entry = &costEntry{}
+		l.entries[key] = entry
+	}
+	entry.Calls++
+	entry.Cost += cost
+	if reported {
+		entry.Reported++
+	}
+	l.clientNames[clientID] = clientName
+}
+
+// toolCost is a client's spending on one tool
+type toolCost struct {
+	Server string `json:"server"`
+	Tool   string `json:"tool"`
+	costEntry
+}
+
+// clientCost is a client's spending over a day or a whole report
+type clientCost struct {
+	ID    string     `json:"id"`
+	Name  string     `json:"name"`
+	Calls int64      `json:"calls"`
+	Total float64    `json:"total"`
+	Tools []toolCost `json:"tools,omitempty"`
+}
+
+// dayCost is the spending of every client on one day
+type dayCost struct {
+	Date    string       `json:"date"`
+	Calls   int64        `json:"calls"`
+	Total   float64      `json:"total"`
+	Clients []clientCost `json:"clients"`
+}
+
+// costReport is the API view of the ledger between two dates
+type costReport struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Calls   int64        `json:"calls"`
+	Total   float64      `json:"total"`
+	Clients []clientCost `json:"clients"`
+	Days    []dayCost    `json:"days"`
+}
+
+// costFilter narrows a report to dates from..to, inclusive, and optionally a client or server
+type costFilter struct {
+	from, to, clientID, server string
+}
+
+// report totals the ledger per day and client, and per client over the whole range
+func (l *costLedger) report(filter costFilter) costReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	report := costReport{From: filter.from, To: filter.to, Clients: []clientCost{}, Days: []dayCost{}}
+	days := make(map[string]map[string]*clientCost)
+	totals := make(map[string]*clientCost)
+	for key, entry := range l.entries {
+		if key.day < filter.from || key.day > filter.to ||
+			(filter.clientID != "" && key.clientID != filter.clientID) ||
+			(filter.server != "" && key.server != filter.server) {
+
+			continue
+		}
+		if days[key.day] == nil {
+			days[key.day] = make(map[string]*clientCost)
+		}
+		daily := days[key.day][key.clientID]
+		if daily == nil {
+			daily = &clientCost{ID: key.clientID, Name: l.clientNames[key.clientID]}
+			days[key.day][key.clientID] = daily
+		}
+		daily.Calls += entry.Calls
+		daily.Total += entry.Cost
+		daily.Tools = append(daily.Tools, toolCost{Server: key.server, Tool: key.tool, costEntry: *entry})
+
+		total := totals[key.clientID]
+		if total == nil {
+			total = &clientCost{ID: key.clientID, Name: l.clientNames[key.clientID]}
+			totals[key.clientID] = total
+		}
+		total.Calls += entry.Calls
+		total.Total += entry.Cost
+		report.Calls += entry.Calls
+		report.Total += entry.Cost
+	}
+
+	for date, clients := range days {
+		day := dayCost{Date: date, Clients: make([]clientCost, 0, len(clients))}
+		for _, client := range clients {
+			sort.Slice(client.Tools, func(i, j int) bool {
+				if client.Tools[i].Cost != client.Tools[j].Cost {
+
+					return client.Tools[i].Cost > client.Tools[j].Cost
+				}
+
+				return client.Tools[i].Server+client.Tools[i].Tool < client.Tools[j].Server+client.Tools[j].Tool
+			})
+			day.Calls += client.Calls
+			day.Total += client.Total
+			day.Clients = append(day.Clients, *client)
+		}
+		sortClientCosts(day.Clients)
+		report.Days = append(report.Days, day)
+	}
+	sort.Slice(report.Days, func(i, j int) bool { return report.Days[i].Date < report.Days[j].Date })
+	for _, total := range totals {
+		report.Clients = append(report.Clients, *total)
+	}
+	sortClientCosts(report.Clients)
+
+	return report
+}
+
+// sortClientCosts orders clients by spending, highest first
+func sortClientCosts(clients []clientCost) {
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Total != clients[j].Total {
+
+			return clients[i].Total > clients[j].Total
+		}
+
+		return clients[i].ID < clients[j].ID
+	})
+}
+
+// reportedCost returns the cost a server put in a result's _meta, if it did
+func reportedCost(result map[string]interface{}) (float64, bool) {
+	meta, _ := result["_meta"].(map[string]interface{})
+	cost, ok := meta[costMetaKey].(float64)
+
+	return cost, ok && cost >= 0
+}
+
+// recordCost books a finished call to a server, and returns its cost: the cost the server
+// reported for it, or else the server's configured price for tool calls
+func (h *ProxyHandler) recordCost(r *http.Request, serverName, toolName string, uw *usageResponseWriter, requestBytes int) float64 {
+	cost, reported := uw.cost, uw.costReported
+	if !reported {
+		serverCfg, exists := h.Manager.Config().Servers[serverName]
+		if !exists || serverCfg.Pricing == nil || toolName == "" {
+
+			return 0
+		}
+		cost = serverCfg.Pricing.PriceOf(toolName).Cost(estimateTokens(requestBytes), estimateTokens(uw.size))
+	}
+	h.costs.record(r, serverName, toolName, cost, reported)
+
+	return cost
+}
+
+// handleCostsAPI serves GET /api/costs: what calls to paid servers cost per day and client.
+// ?from= and ?to= bound the dates, by default the last constants.CostReportDays days, and
+// ?client= and ?server= narrow the report.
+func (h *ProxyHandler) handleCostsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use GET")
+
+		return
+	}
+	if !h.scopeGranted(r, AdminReadScope, AdminWriteScope) {
+		publishAuthDenied(r, "", "costs API scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", AdminReadScope))
+
+		return
+	}
+
+	query := r.URL.Query()
+	now := time.Now()
+	filter := costFilter{
+		from:     costDay(now.AddDate(0, 0, 1-constants.CostReportDays)),
+		to:       costDay(now),
+		clientID: query.Get("client"),
+		server:   query.Get("server"),
+	}
+	for _, bound := range []struct {
+		name  string
+		value *string
+	}{{"from", &filter.from}, {"to", &filter.to}} {
+		if value := query.Get(bound.name); value != "" {
+			if _, err := time.Parse(time.DateOnly, value); err != nil {
+				writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("%s must be a date like 2006-01-02", bound.name))
+
+				return
+			}
+			*bound.value = value
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(h.costs.report(filter))
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func TestCostTracking(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage        `json:"id"`
+			Params map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		if request.Params["name"] == "generate" {
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"content":[],"_meta":{"mcp-compose/cost":0.25}}}`, request.ID)
+
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"content":[]}}`, request.ID)
+	}))
+	defer backend.Close()
+
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"gateway": {Protocol: "http", Pricing: &config.PricingConfig{
+			ToolPricing: config.ToolPricing{PerCall: 0.01},
+			Tools:       map[string]config.ToolPricing{"generate": {PerCall: 1}},
+		}},
+		"free": {Protocol: "http"},
+	}}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager: newTestManager(cfg, logger, map[string]*ServerInstance{
+			"gateway": {Name: "gateway", Config: cfg.Servers["gateway"]},
+			"free":    {Name: "free", Config: cfg.Servers["free"]},
+		}),
+		logger:     logger,
+		ctx:        context.Background(),
+		httpClient: http.DefaultClient,
+		catalog:    newCatalogCache(),
+		responses:  newResponseCache(),
+		analytics:  newClientAnalytics(),
+		costs:      newCostLedger(),
+		ServerConnections: map[string]*MCPHTTPConnection{
+			"gateway": {ServerName: "gateway", BaseURL: backend.URL, Initialized: true, Healthy: true},
+			"free":    {ServerName: "free", BaseURL: backend.URL, Initialized: true, Healthy: true},
+		},
+	}
+
+	agent := context.WithValue(context.WithValue(context.Background(), auth.AuthTypeContextKey, "oauth"),
+		auth.ClientContextKey, &auth.OAuthClient{ID: "agent", ClientName: "Agent"})
+	call := func(ctx context.Context, serverName, method, tool string) {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q,"params":{"name":%q}}`, method, tool)
+		r := httptest.NewRequest(http.MethodPost, "/"+serverName, strings.NewReader(body)).WithContext(ctx)
+		instance, _ := h.Manager.GetServerInstance(serverName)
+		h.handleMCPMethodForwarding(httptest.NewRecorder(), r, serverName, instance)
+	}
+	call(agent, "gateway", "tools/call", "search")
+	call(agent, "gateway", "tools/call", "search")
+	call(agent, "gateway", "tools/call", "generate")
+	call(agent, "gateway", "prompts/get", "summary")
+	call(agent, "free", "tools/call", "search")
+	call(context.WithValue(context.Background(), auth.AuthTypeContextKey, "api_key"), "gateway", "tools/call", "search")
+
+	get := func(query string) (int, costReport) {
+		recorder := httptest.NewRecorder()
+		h.handleCostsAPI(recorder, httptest.NewRequest(http.MethodGet, "/api/costs"+query, nil))
+		var report costReport
+		_ = json.Unmarshal(recorder.Body.Bytes(), &report)
+
+		return recorder.Code, report
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	code, report := get("")
+	if code != http.StatusOK {
+		t.Fatalf("costs API: expected 200, got %d", code)
+	}
+	today := time.Now().UTC().Format(time.DateOnly)
+	if report.To != today || len(report.Days) != 1 || report.Days[0].Date != today {
+		t.Fatalf("expected one day of costs ending today, got %+v", report)
+	}
+	if report.Calls != 4 || !near(report.Total, 0.28) {
+		t.Errorf("expected 4 priced calls costing 0.28, got %d costing %v", report.Calls, report.Total)
+	}
+	if len(report.Clients) != 2 || report.Clients[0].ID != "oauth:agent" || report.Clients[0].Name != "Agent" || !near(report.Clients[0].Total, 0.27) {
+		t.Errorf("the agent should have spent the most, got %+v", report.Clients)
+	}
+	tools := report.Days[0].Clients[0].Tools
+	if len(tools) != 2 || tools[0].Tool != "generate" || tools[0].Reported != 1 || !near(tools[0].Cost, 0.25) {
+		t.Errorf("a cost the server reports should replace the configured price, got %+v", tools)
+	}
+	if tools[1].Tool != "search" || tools[1].Calls != 2 || tools[1].Reported != 0 || !near(tools[1].Cost, 0.02) {
+		t.Errorf("other calls should be priced from the compose file, got %+v", tools)
+	}
+
+	if _, filtered := get("?client=api_key&server=gateway"); filtered.Calls != 1 || len(filtered.Clients) != 1 {
+		t.Errorf("filters should narrow the report to one client, got %+v", filtered)
+	}
+	if _, past := get("?from=2020-01-01&to=2020-01-31"); past.Calls != 0 || len(past.Days) != 0 {
+		t.Errorf("a range without calls should be empty, got %+v", past)
+	}
+	if code, _ := get("?from=yesterday"); code != http.StatusBadRequest {
+		t.Errorf("an invalid date should be refused, got %d", code)
+	}
+
+	old := costKey{day: time.Now().AddDate(0, 0, -constants.CostRetentionDays).UTC().Format(time.DateOnly), clientID: "api_key", server: "gateway", tool: "search"}
+	h.costs.entries[old] = &costEntry{Calls: 1, Cost: 1}
+	h.costs.today = ""
+	call(agent, "gateway", "tools/call", "search")
+	if _, kept := h.costs.entries[old]; kept {
+		t.Errorf("costs older than %d days should be dropped", constants.CostRetentionDays)
+	}
+}
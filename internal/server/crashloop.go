@@ -0,0 +1,221 @@
+// internal/server/crashloop.go
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+	"github.com/phildougherty/mcp-compose/internal/notify"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+)
+
+// crashLoopState tracks the restarts of one container server
+type crashLoopState struct {
+	detector     *runtime.CrashLoopDetector
+	restartCount int
+	backoffUntil time.Time
+}
+
+// WatchCrashLoops polls the restart counts of container servers until the manager shuts
+// down. A server restarted more often than its crash_loop limits allow is stopped, put in
+// the backoff state and started again once the backoff is over. Process servers are
+// backed off by their supervisor instead.
+func (m *Manager) WatchCrashLoops() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(constants.CrashLoopPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.checkCrashLoops()
+			case <-m.ctx.Done():
+
+				return
+			}
+		}
+	}()
+}
+
+func (m *Manager) checkCrashLoops() {
+	if m.containerRuntime == nil || m.containerRuntime.GetRuntimeName() == "none" {
+
+		return
+	}
+
+	m.mu.Lock()
+	names := make([]string, 0, len(m.servers))
+	for name, instance := range m.servers {
+		if instance.IsContainer && instance.Status != "backoff" {
+			names = append(names, name)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, name := range names {
+		info, err := m.containerRuntime.GetContainerInfo(config.ContainerName(name))
+		if err != nil {
+
+			continue
+		}
+
+		state := m.crashLoopState(name)
+		m.crashLoopMu.Lock()
+		restarts := info.RestartCount - state.restartCount
+		state.restartCount = info.RestartCount
+		looping := false
+		for i := 0; i < restarts; i++ {
+			looping = state.detector.Record(time.Now()) || looping
+		}
+		count := state.detector.Count()
+		m.crashLoopMu.Unlock()
+
+		if looping {
+			m.backOff(name, fmt.Sprintf("container restarted %d times", count))
+		}
+	}
+}
+
+// recordRestart notes a restart made by the manager itself and reports whether the server
+// is now crash-looping
+func (m *Manager) recordRestart(name string) bool {
+	state := m.crashLoopState(name)
+	m.crashLoopMu.Lock()
+	defer m.crashLoopMu.Unlock()
+
+	return state.detector.Record(time.Now())
+}
+
+func (m *Manager) crashLoopState(name string) *crashLoopState {
+	m.crashLoopMu.Lock()
+	state, exists := m.crashLoops[name]
+	m.crashLoopMu.Unlock()
+	if exists {
+
+		return state
+	}
+
+	var limits runtime.CrashLoopLimits
+	m.mu.Lock()
+	if instance, ok := m.servers[name]; ok {
+		limits = runtime.CrashLoopLimitsFromConfig(instance.Config.CrashLoop)
+	}
+	m.mu.Unlock()
+	state = &crashLoopState{detector: runtime.NewCrashLoopDetector(limits)}
+	if m.containerRuntime != nil {
+		if info, err := m.containerRuntime.GetContainerInfo(config.ContainerName(name)); err == nil {
+			state.restartCount = info.RestartCount
+		}
+	}
+
+	m.crashLoopMu.Lock()
+	defer m.crashLoopMu.Unlock()
+	if existing, exists := m.crashLoops[name]; exists {
+
+		return existing
+	}
+	m.crashLoops[name] = state
+
+	return state
+}
+
+// backOff stops a crash-looping server, notifies the hooks and starts it again once its
+// backoff is over
+func (m *Manager) backOff(name, reason string) {
+	m.mu.Lock()
+	instance, exists := m.servers[name]
+	if !exists || instance.Status == "backoff" {
+		m.mu.Unlock()
+
+		return
+	}
+	_, window, backoff := instance.Config.CrashLoop.Limits()
+	isContainer := instance.IsContainer
+	m.mu.Unlock()
+
+	message := fmt.Sprintf("Server '%s' is crash-looping (%s within %s); waiting %s before starting it again", name, reason, window, backoff)
+	m.logger.Error("CRASH LOOP: %s", message)
+	if err := m.StopServer(name); err != nil {
+		m.logger.Warning("CRASH LOOP: Failed to stop server '%s': %v", name, err)
+	}
+	// A container the runtime keeps restarting isn't reported as running, so StopServer
+	// may have left it in place
+	if identifier := config.ContainerName(name); isContainer {
+		if _, err := m.containerRuntime.GetContainerStatus(identifier); err == nil {
+			if err := m.containerRuntime.StopContainer(identifier); err != nil {
+				m.logger.Warning("CRASH LOOP: Failed to stop container '%s': %v", identifier, err)
+			}
+		}
+	}
+
+	m.mu.Lock()
+	instance.Status = "backoff"
+	m.mu.Unlock()
+	state := m.crashLoopState(name)
+	m.crashLoopMu.Lock()
+	state.backoffUntil = time.Now().Add(backoff)
+	m.crashLoopMu.Unlock()
+	m.notify("crash_loop", name, message)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		select {
+		case <-time.After(backoff):
+		case <-m.ctx.Done():
+
+			return
+		}
+
+		m.crashLoopMu.Lock()
+		state.detector.Reset()
+		state.backoffUntil = time.Time{}
+		m.crashLoopMu.Unlock()
+		m.logger.Info("CRASH LOOP: Backoff for server '%s' is over, starting it again", name)
+		if err := m.StartServer(name); err != nil {
+			m.logger.Error("CRASH LOOP: Failed to start server '%s' after its backoff: %v", name, err)
+		}
+		// The new container's restart count starts from zero
+		m.crashLoopMu.Lock()
+		state.restartCount = 0
+		m.crashLoopMu.Unlock()
+	}()
+}
+
+// BackoffUntil returns when a crash-looping server is started again, or the zero time
+func (m *Manager) BackoffUntil(name string) time.Time {
+	m.crashLoopMu.Lock()
+	defer m.crashLoopMu.Unlock()
+	if state, exists := m.crashLoops[name]; exists {
+
+		return state.backoffUntil
+	}
+
+	return time.Time{}
+}
+
+// notify publishes an event on the event bus and sends it to the notification hooks
+// without holding up the caller
+func (m *Manager) notify(eventType, serverName, message string) {
+	events.Publish(events.Event{
+		Type:    "server." + eventType,
+		Level:   events.LevelError,
+		Server:  serverName,
+		Message: message,
+	})
+	if m.Config().Notifications.Empty() {
+
+		return
+	}
+	event := notify.Event{Type: eventType, Server: serverName, Message: message, Time: time.Now()}
+	go func() {
+		if err := notify.Send(m.Config().Notifications, event); err != nil {
+			m.logger.Warning("Failed to send %s notification for server '%s': %v", eventType, serverName, err)
+		}
+	}()
+}
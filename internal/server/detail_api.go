@@ -0,0 +1,242 @@
+// internal/server/detail_api.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/imagescan"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+)
+
+// catalogSummary describes one cached list of a server's catalog
+type catalogSummary struct {
+	Count     int       `json:"count"`
+	Names     []string  `json:"names"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// handleServerDetail returns everything known about one server for the dashboard's detail
+// view: its resolved configuration with secrets redacted, what the container runtime or
+// process supervisor reports, health check history and the MCP capabilities the proxy has
+// seen. Nothing is fetched from the server itself, so the call stays fast when it is down.
+func (h *ProxyHandler) handleServerDetail(w http.ResponseWriter, r *http.Request, serverName string) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Method not allowed - use GET"})
+
+		return
+	}
+	instance, exists := h.Manager.GetServerInstance(serverName)
+	serverConfig, configured := h.Manager.Config().Servers[serverName]
+	if !exists || !configured {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("server '%s' not found in configuration", serverName)})
+
+		return
+	}
+
+	status, _ := h.Manager.GetServerStatus(serverName)
+	detail := map[string]interface{}{
+		"name":        serverName,
+		"status":      status,
+		"isContainer": instance.IsContainer,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	}
+	if !instance.StartTime.IsZero() {
+		detail["startTime"] = instance.StartTime.Format(time.RFC3339)
+	}
+	if until := h.Manager.BackoffUntil(serverName); !until.IsZero() {
+		detail["backoffUntil"] = until.Format(time.RFC3339)
+	}
+
+	if redacted, err := config.Redact(serverConfig); err == nil {
+		detail["config"] = redacted
+	} else {
+		h.logger.Warning("Failed to redact configuration of server %s: %v", serverName, err)
+	}
+
+	identifier := config.ContainerName(serverName)
+	if instance.IsContainer {
+		if container, err := h.containerDetail(identifier); err == nil {
+			detail["container"] = container
+			if security := h.imageScanDetail(container["imageId"].(string), serverConfig.Security.Scan); security != nil {
+				detail["security"] = security
+			}
+		} else {
+			detail["containerError"] = err.Error()
+		}
+	} else if proc, err := runtime.FindProcess(identifier); err == nil {
+		if state, err := proc.ReadState(); err == nil {
+			detail["process"] = state
+		}
+	}
+
+	health := map[string]interface{}{"type": "none"}
+	if healthCheck := serverConfig.ActiveHealthCheck(); healthCheck != nil {
+		health["type"] = healthCheck.CheckType()
+	}
+	healthStatus, healthError, checkedAt := h.Manager.GetServerHealth(serverName)
+	health["status"] = healthStatus
+	health["error"] = healthError
+	if !checkedAt.IsZero() {
+		health["lastChecked"] = checkedAt.Format(time.RFC3339)
+	}
+	health["history"] = h.Manager.GetHealthHistory(serverName)
+	detail["health"] = health
+
+	detail["capabilities"] = h.capabilityDetail(serverName, serverConfig)
+	if queue := h.requestQueueFor(serverName); queue != nil {
+		detail["queue"] = queue.stats()
+	}
+	if serverConfig.ResponseCache != nil {
+		detail["responseCache"] = h.responses.stats(serverName)
+	}
+
+	if err := json.NewEncoder(w).Encode(detail); err != nil {
+		h.logger.Error("Failed to encode detail response for server %s: %v", serverName, err)
+	}
+}
+
+// containerDetail inspects a server's container and its image
+func (h *ProxyHandler) containerDetail(identifier string) (map[string]interface{}, error) {
+	info, err := h.Manager.containerRuntime.GetContainerInfo(identifier)
+	if err != nil {
+
+		return nil, err
+	}
+
+	env := make([]string, 0, len(info.Env))
+	for _, entry := range info.Env {
+		env = append(env, config.RedactEnvEntry(entry))
+	}
+	container := map[string]interface{}{
+		"id":           info.ID,
+		"name":         info.Name,
+		"image":        info.Image,
+		"imageId":      info.ImageID,
+		"created":      info.Created,
+		"state":        info.State,
+		"health":       info.Health,
+		"restartCount": info.RestartCount,
+		"ports":        info.Ports,
+		"mounts":       info.Mounts,
+		"networks":     info.Networks,
+		"env":          env,
+		"command":      info.Command,
+		"labels":       info.Labels,
+	}
+	// Digests name the exact image pulled from a registry; locally built images have none
+	if info.ImageID != "" {
+		if image, err := h.Manager.containerRuntime.GetImageInfo(info.ImageID); err == nil {
+			container["imageDigests"] = image.Digests
+			container["imageTags"] = image.Tags
+			container["imageCreated"] = image.Created
+		}
+	}
+
+	return container, nil
+}
+
+// imageScanDetail returns the cached vulnerability scan of a container's image, counted
+// against the server's thresholds, or nil when the image was never scanned
+func (h *ProxyHandler) imageScanDetail(imageID string, scan *config.ImageScanConfig) map[string]interface{} {
+	if imageID == "" {
+
+		return nil
+	}
+	image, err := h.Manager.containerRuntime.GetImageInfo(imageID)
+	if err != nil {
+
+		return nil
+	}
+	result, found := imagescan.Lookup(filepath.Join(h.Manager.projectDir, constants.ImageScanCacheFile), image.ID)
+	if !found {
+
+		return nil
+	}
+	if scan == nil {
+		scan = &config.ImageScanConfig{}
+	}
+	failOn, warnOn := scan.Thresholds()
+
+	return map[string]interface{}{
+		"enabled":         scan.Enabled,
+		"scanner":         result.Scanner,
+		"scannedAt":       result.ScannedAt,
+		"counts":          result.Counts(scan.IgnoreUnfixed),
+		"summary":         result.Summary(scan.IgnoreUnfixed),
+		"failOn":          failOn,
+		"warnOn":          warnOn,
+		"failing":         result.AtLeast(failOn, scan.IgnoreUnfixed),
+		"warning":         result.AtLeast(warnOn, scan.IgnoreUnfixed),
+		"vulnerabilities": result.Vulnerabilities,
+	}
+}
+
+// capabilityDetail combines the capabilities a server is configured with, those it reported
+// when the proxy initialized a session with it, and the lists the proxy has cached
+func (h *ProxyHandler) capabilityDetail(serverName string, serverConfig config.ServerConfig) map[string]interface{} {
+	capabilities := map[string]interface{}{
+		"configured": serverConfig.Capabilities,
+	}
+
+	h.ConnectionMutex.RLock()
+	if conn, exists := h.ServerConnections[serverName]; exists {
+		conn.mu.Lock()
+		if conn.Initialized {
+			capabilities["reported"] = conn.Capabilities
+			capabilities["serverInfo"] = conn.ServerInfo
+		}
+		conn.mu.Unlock()
+	}
+	h.ConnectionMutex.RUnlock()
+
+	if _, reported := capabilities["reported"]; !reported {
+		h.SSEMutex.RLock()
+		if conn, exists := h.SSEConnections[serverName]; exists && conn != nil {
+			conn.mu.Lock()
+			if conn.Initialized {
+				capabilities["reported"] = conn.Capabilities
+				capabilities["serverInfo"] = conn.ServerInfo
+			}
+			conn.mu.Unlock()
+		}
+		h.SSEMutex.RUnlock()
+	}
+
+	catalog := make(map[string]catalogSummary)
+	for _, method := range catalogMethods {
+		entry, cached := h.catalog.get(serverName, method)
+		if !cached {
+
+			continue
+		}
+		key := catalogListKeys[method]
+		var result map[string]json.RawMessage
+		var items []map[string]interface{}
+		if json.Unmarshal(entry.result, &result) != nil || json.Unmarshal(result[key], &items) != nil {
+
+			continue
+		}
+		summary := catalogSummary{Names: []string{}, FetchedAt: entry.fetchedAt}
+		for _, item := range items {
+			name, _ := item["name"].(string)
+			if name == "" {
+				name, _ = item["uri"].(string)
+			}
+			summary.Names = append(summary.Names, name)
+		}
+		summary.Count = len(items)
+		catalog[key] = summary
+	}
+	capabilities["catalog"] = catalog
+
+	return capabilities
+}
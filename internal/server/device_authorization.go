@@ -0,0 +1,129 @@
+// internal/server/device_authorization.go
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+const deviceAPIPath = "/api/oauth/device"
+
+// deviceReview is the body of an approve or deny call
+type deviceReview struct {
+	User string `json:"user,omitempty"`
+}
+
+// handleDeviceVerification serves the page users enter a device sign-in's code at. Users a
+// trusted reverse proxy signed in, or who signed in to authorize a client before, approve it
+// there; everyone else approves it in the dashboard.
+func (h *ProxyHandler) handleDeviceVerification(w http.ResponseWriter, r *http.Request) {
+	user := ""
+	if handled, ok := h.authenticateTrustedHeaders(w, r); handled {
+		if !ok {
+
+			return
+		}
+		user, _ = r.Context().Value(auth.UserContextKey).(string)
+	}
+	if user == "" {
+		user = h.authServer.SessionUser(r)
+	}
+	h.authServer.HandleDeviceVerification(w, r, user)
+}
+
+// handleDeviceAPI serves the device sign-ins waiting for a user: GET /api/oauth/device lists
+// them, and POST /api/oauth/device/{code}/approve or /deny decides one. An approval makes
+// the device's tokens act for the caller's user, or for the user the body names when the
+// caller is the dashboard or another holder of the API key.
+func (h *ProxyHandler) handleDeviceAPI(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, deviceAPIPath), "/"), "/")
+	if parts[0] == "" {
+		parts = nil
+	}
+	write := len(parts) == 2
+	if len(parts) == 1 || len(parts) > 2 || (write && parts[1] != "approve" && parts[1] != "deny") {
+		writeAPIError(w, http.StatusNotFound, "not found")
+
+		return
+	}
+	method := http.MethodGet
+	if write {
+		method = http.MethodPost
+	}
+	if r.Method != method {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use "+method)
+
+		return
+	}
+	scope := AdminReadScope
+	if write {
+		scope = AdminWriteScope
+	}
+	if !h.scopeGranted(r, scope, AdminWriteScope) {
+		publishAuthDenied(r, "", "device API scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", scope))
+
+		return
+	}
+
+	if !write {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"devices": h.authServer.PendingDeviceAuthorizations()})
+
+		return
+	}
+
+	var review deviceReview
+	body, err := io.ReadAll(io.LimitReader(r.Body, constants.SamplingAPIMaxBodySize))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &review); err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("request body must be a JSON object: %v", err))
+
+			return
+		}
+	}
+	// A signed-in caller approves for themselves; only the API key, which the dashboard uses,
+	// may name the user, as may anyone when the proxy requires no authentication
+	user, _ := r.Context().Value(auth.UserContextKey).(string)
+	if authType, _ := r.Context().Value(auth.AuthTypeContextKey).(string); authType == "api_key" || authType == "" {
+		user = review.User
+	}
+	approve := parts[1] == "approve"
+	if approve && user == "" {
+		writeAPIError(w, http.StatusBadRequest, "user is required: the tokens of an approved device act for a user")
+
+		return
+	}
+
+	if err := h.authServer.DecideDeviceAuthorization(parts[0], user, approve); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrDeviceAuthorizationNotFound) {
+			status = http.StatusNotFound
+		}
+		writeAPIError(w, status, err.Error())
+
+		return
+	}
+	status := "approved"
+	if !approve {
+		status = "denied"
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": status, "user_code": auth.FormatUserCode(auth.NormalizeUserCode(parts[0])), "user": user})
+}
@@ -9,7 +9,7 @@ import (
 	"strings"
 
 	"github.com/phildougherty/mcp-compose/internal/constants"
-	"github.com/phildougherty/mcp-compose/internal/dashboard"
+	"github.com/phildougherty/mcp-compose/internal/events"
 )
 
 // mcpResponseRecorder captures HTTP responses for MCP tool calls
@@ -40,14 +40,15 @@ func (r *mcpResponseRecorder) Write(body []byte) (int, error) {
 func (h *ProxyHandler) handleDirectToolCall(w http.ResponseWriter, r *http.Request, toolName string) {
 	// Authenticate
 	apiKeyToCheck := h.APIKey
-	if h.Manager != nil && h.Manager.config != nil && h.Manager.config.ProxyAuth.Enabled {
-		apiKeyToCheck = h.Manager.config.ProxyAuth.APIKey
+	if cfg := h.composeConfig(); cfg != nil && cfg.ProxyAuth.Enabled {
+		apiKeyToCheck = cfg.ProxyAuth.APIKey
 	}
 
-	if apiKeyToCheck != "" {
+	if apiKeyToCheck != "" && !isTrustedHeaderRequest(r) {
 		authHeader := r.Header.Get("Authorization")
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token != apiKeyToCheck {
+			h.authFailed(r, "", "API key mismatch")
 			h.corsError(w, "Unauthorized", http.StatusUnauthorized)
 
 			return
@@ -76,9 +77,26 @@ func (h *ProxyHandler) handleDirectToolCall(w http.ResponseWriter, r *http.Reque
 
 	h.logger.Info("Routing tool %s to server %s", toolName, serverName)
 
-	dashboard.BroadcastActivity("INFO", "tool", serverName, getClientIP(r),
-		fmt.Sprintf("Tool called: %s", toolName),
-		map[string]interface{}{"tool": toolName, "arguments": arguments})
+	instance, exists := h.Manager.GetServerInstance(serverName)
+	if !exists {
+		h.corsError(w, "Server not found", http.StatusNotFound)
+
+		return
+	}
+	h.forwardToolCall(w, r, serverName, instance, toolName, arguments)
+}
+
+// forwardToolCall calls a tool as tools/call through the server's usual request path, with
+// its authentication, access control and middleware, and answers with the tool's content
+// in plain JSON for REST clients
+func (h *ProxyHandler) forwardToolCall(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance, toolName string, arguments map[string]interface{}) {
+	events.Publish(events.Event{
+		Type:    events.ToolCalled,
+		Server:  serverName,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("Tool called: %s", toolName),
+		Details: map[string]interface{}{"tool": toolName, "arguments": arguments},
+	})
 
 	// Create MCP tools/call request
 	mcpRequest := map[string]interface{}{
@@ -91,72 +109,81 @@ func (h *ProxyHandler) handleDirectToolCall(w http.ResponseWriter, r *http.Reque
 		},
 	}
 
-	// Forward to the appropriate server and get response
-	if instance, exists := h.Manager.GetServerInstance(serverName); exists {
-		// Convert to request body
-		requestBody, err := json.Marshal(mcpRequest)
-		if err != nil {
-			h.logger.Error("Failed to marshal MCP request for tool %s: %v", toolName, err)
-			h.corsError(w, "Internal server error", http.StatusInternalServerError)
-
-			return
-		}
+	// Convert to request body
+	requestBody, err := json.Marshal(mcpRequest)
+	if err != nil {
+		h.logger.Error("Failed to marshal MCP request for tool %s: %v", toolName, err)
+		h.corsError(w, "Internal server error", http.StatusInternalServerError)
 
-		// Create new request
-		newRequest := r.Clone(r.Context())
-		newRequest.Body = io.NopCloser(bytes.NewReader(requestBody))
-		newRequest.ContentLength = int64(len(requestBody))
+		return
+	}
 
-		// Create a simple response recorder
-		recorder := &mcpResponseRecorder{
-			statusCode: constants.HTTPStatusSuccess,
-			headers:    make(http.Header),
-		}
+	// Create new request
+	newRequest := r.Clone(r.Context())
+	newRequest.Body = io.NopCloser(bytes.NewReader(requestBody))
+	newRequest.ContentLength = int64(len(requestBody))
 
-		h.handleServerForward(recorder, newRequest, serverName, instance)
+	// Create a simple response recorder
+	recorder := &mcpResponseRecorder{
+		statusCode: constants.HTTPStatusSuccess,
+		headers:    make(http.Header),
+	}
 
-		// Parse and format the MCP response
-		if recorder.statusCode == 200 && len(recorder.body) > 0 {
-			var mcpResponse map[string]interface{}
-			if err := json.Unmarshal(recorder.body, &mcpResponse); err == nil {
-				// Check for MCP error
-				if mcpError, hasError := mcpResponse["error"].(map[string]interface{}); hasError {
-					errorResponse := map[string]interface{}{
-						"error": mcpError["message"],
-					}
-					if data, hasData := mcpError["data"]; hasData {
-						errorResponse["details"] = data
-					}
-					w.Header().Set("Content-Type", "application/json")
-					w.WriteHeader(http.StatusInternalServerError)
-					_ = json.NewEncoder(w).Encode(errorResponse)
+	h.handleServerForward(recorder, newRequest, serverName, instance)
 
-					return
+	// Parse and format the MCP response
+	if recorder.statusCode == 200 && len(recorder.body) > 0 {
+		var mcpResponse map[string]interface{}
+		if err := json.Unmarshal(recorder.body, &mcpResponse); err == nil {
+			// Check for MCP error
+			if mcpError, hasError := mcpResponse["error"].(map[string]interface{}); hasError {
+				errorResponse := map[string]interface{}{
+					"error": mcpError["message"],
+				}
+				if data, hasData := mcpError["data"]; hasData {
+					errorResponse["details"] = data
 				}
+				status := http.StatusInternalServerError
+				switch code, _ := mcpError["code"].(float64); int(code) {
+				case -32001: // Denied by the server's tools_acl
+					status = http.StatusForbidden
+				case -32602:
+					status = http.StatusBadRequest
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				_ = json.NewEncoder(w).Encode(errorResponse)
 
-				// Extract and format the successful result
-				if result, exists := mcpResponse["result"]; exists {
-					if resultMap, ok := result.(map[string]interface{}); ok {
-						if content, exists := resultMap["content"]; exists {
-							// Process the content like MCPO does
-							cleanResult := h.processMCPContent(content)
-							w.Header().Set("Content-Type", "application/json")
-							_ = json.NewEncoder(w).Encode(cleanResult)
+				return
+			}
+
+			// Extract and format the successful result
+			if result, exists := mcpResponse["result"]; exists {
+				if resultMap, ok := result.(map[string]interface{}); ok {
+					if content, exists := resultMap["content"]; exists {
+						// Process the content like MCPO does
+						cleanResult := h.processMCPContent(content)
+						w.Header().Set("Content-Type", "application/json")
+						// A tool that reports a failure is an error to REST clients too
+						if isError, _ := resultMap["isError"].(bool); isError {
+							w.WriteHeader(http.StatusInternalServerError)
+							_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": fmt.Sprintf("tool %s failed", toolName), "details": cleanResult})
 
 							return
 						}
+						_ = json.NewEncoder(w).Encode(cleanResult)
+
+						return
 					}
 				}
 			}
 		}
-
-		// Fallback to original response if formatting fails
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(recorder.statusCode)
-		_, _ = w.Write(recorder.body)
-	} else {
-		h.corsError(w, "Server not found", http.StatusNotFound)
 	}
+
+	// Fallback to original response if formatting fails
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(recorder.statusCode)
+	_, _ = w.Write(recorder.body)
 }
 
 func (h *ProxyHandler) handleServerForward(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance) {
@@ -168,11 +195,9 @@ func (h *ProxyHandler) handleServerForward(w http.ResponseWriter, r *http.Reques
 
 	w.Header().Set("Content-Type", "application/json")
 
-	// Read request body ONCE and store it
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		h.logger.Error("Failed to read request body for %s: %v", serverName, err)
-		h.sendMCPError(w, nil, -32700, "Error reading request body")
+	// Read request body ONCE and store it, within the server's size limit
+	body, ok := h.readRequestBody(w, r, serverName)
+	if !ok {
 
 		return
 	}
@@ -189,13 +214,23 @@ func (h *ProxyHandler) handleServerForward(w http.ResponseWriter, r *http.Reques
 	reqIDVal := requestPayload["id"]
 	reqMethodVal, _ := requestPayload["method"].(string)
 
-	dashboard.BroadcastActivity("INFO", "request", serverName, getClientIP(r),
-		fmt.Sprintf("MCP Request: %s", reqMethodVal),
-		map[string]interface{}{
+	events.Publish(events.Event{
+		Type:    events.Request,
+		Server:  serverName,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("MCP Request: %s", reqMethodVal),
+		Details: map[string]interface{}{
 			"method":   reqMethodVal,
 			"id":       reqIDVal,
 			"endpoint": r.URL.Path,
-		})
+		},
+	})
+
+	// Enforce per-tool access control before anything reaches the server
+	if !h.authorizeToolCall(w, r, serverName, requestPayload, reqIDVal) {
+
+		return
+	}
 
 	// ONLY handle proxy-specific standard methods, NOT server methods
 	if isProxyStandardMethod(reqMethodVal) {
@@ -204,41 +239,15 @@ func (h *ProxyHandler) handleServerForward(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// FORWARD ALL OTHER METHODS TO THE ACTUAL MCP SERVERS
-	// Get server config
-	serverConfig, exists := h.Manager.config.Servers[serverName]
-	if !exists {
-		h.logger.Error("Server config not found for %s", serverName)
-		h.sendMCPError(w, reqIDVal, -32602, "Server configuration not found")
+	// Run the server's middleware chain around the forwarded call
+	if chain := h.middlewareChainFor(serverName); len(chain) > 0 {
+		h.forwardWithMiddleware(w, r, serverName, instance, chain, requestPayload, reqIDVal, reqMethodVal)
 
 		return
 	}
 
-	// Determine transport protocol
-	protocolType := serverConfig.Protocol
-	if protocolType == "" {
-		protocolType = "stdio" // default
-	}
-
-	h.logger.Info("Forwarding request to server '%s' using '%s' transport: Method=%s, ID=%v",
-		serverName, protocolType, reqMethodVal, reqIDVal)
-
-	// Route based on transport protocol - pass the original body bytes
-	switch protocolType {
-	case "http":
-		h.handleHTTPServerRequestWithBody(w, r, serverName, instance, body, reqIDVal, reqMethodVal)
-	case "sse":
-		h.handleSSEServerRequest(w, r, serverName, instance, requestPayload, reqIDVal, reqMethodVal)
-	case "stdio":
-		if serverConfig.StdioHosterPort > 0 {
-			h.handleSocatSTDIOServerRequest(w, r, serverName, requestPayload, reqIDVal, reqMethodVal)
-		} else {
-			h.handleSTDIOServerRequest(w, r, serverName, requestPayload, reqIDVal, reqMethodVal)
-		}
-	default:
-		h.logger.Error("Unsupported transport protocol '%s' for server %s", protocolType, serverName)
-		h.sendMCPError(w, reqIDVal, -32602, fmt.Sprintf("Unsupported transport protocol: %s", protocolType))
-	}
+	// FORWARD ALL OTHER METHODS TO THE ACTUAL MCP SERVERS
+	h.dispatchToTransport(w, r, serverName, instance, body, requestPayload, reqIDVal, reqMethodVal)
 }
 
 // processMCPContent processes MCP content like the official MCPO tool does
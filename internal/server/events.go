@@ -0,0 +1,199 @@
+// internal/server/events.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+)
+
+// publishAuthDenied records a request refused by authentication or authorization
+func publishAuthDenied(r *http.Request, serverName, reason string) {
+	events.Publish(events.Event{
+		Type:    events.AuthDenied,
+		Level:   events.LevelWarn,
+		Server:  serverName,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("Access denied to %s: %s", r.URL.Path, reason),
+		Details: map[string]interface{}{"method": r.Method, "endpoint": r.URL.Path},
+	})
+}
+
+// publishAuthLockout records an IP address, user or client locked out of the OAuth endpoints
+// for failing to authenticate too often
+func publishAuthLockout(lockout auth.Lockout) {
+	client := ""
+	if lockout.Kind == "ip" {
+		client = lockout.Name
+	}
+	events.Publish(events.Event{
+		Type:    events.AuthLockout,
+		Level:   events.LevelWarn,
+		Client:  client,
+		Message: fmt.Sprintf("OAuth %s '%s' locked out for %s after %d failed attempts", lockout.Kind, lockout.Name, lockout.Duration, lockout.Failures),
+		Details: map[string]interface{}{
+			"kind":     lockout.Kind,
+			"name":     lockout.Name,
+			"failures": lockout.Failures,
+			"lockouts": lockout.Lockouts,
+			"until":    lockout.Until,
+		},
+	})
+}
+
+// publishMCPResult records the outcome of a request forwarded to a server. A tools/call
+// that fails, or whose result is flagged isError, becomes a tool.failed event.
+func publishMCPResult(r *http.Request, serverName, method, toolName string, response map[string]interface{}, err error) {
+	failure := ""
+	switch {
+	case err != nil:
+		failure = err.Error()
+	case response["error"] != nil:
+		failure = "error response"
+		if rpcErr, ok := response["error"].(map[string]interface{}); ok {
+			failure = fmt.Sprintf("%v", rpcErr["message"])
+		}
+	case method == "tools/call":
+		if result, ok := response["result"].(map[string]interface{}); ok && result["isError"] == true {
+			failure = "tool reported an error"
+		}
+	}
+
+	if failure == "" {
+		events.Publish(events.Event{
+			Type:    events.Request,
+			Server:  serverName,
+			Client:  getClientIP(r),
+			Message: fmt.Sprintf("Response: %s completed successfully", method),
+		})
+
+		return
+	}
+
+	event := events.Event{
+		Type:    events.RequestFailed,
+		Level:   events.LevelError,
+		Server:  serverName,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("Error: %s failed: %s", method, failure),
+		Details: map[string]interface{}{"method": method, "error": failure},
+	}
+	if method == "tools/call" {
+		event.Type = events.ToolFailed
+		event.Message = fmt.Sprintf("Tool %s failed: %s", toolName, failure)
+		event.Details["tool"] = toolName
+	}
+	events.Publish(event)
+}
+
+// toolCallName returns the tool a tools/call request payload names
+func toolCallName(requestPayload map[string]interface{}) string {
+	params, _ := requestPayload["params"].(map[string]interface{})
+	name, _ := params["name"].(string)
+
+	return name
+}
+
+// handleAPIEvents streams the event bus as server-sent events. The type and server query
+// parameters filter the stream and may repeat or hold comma-separated lists; clients
+// resuming with Last-Event-ID receive the recorded events they missed, new clients the
+// whole history unless history=false. With follow=false the response ends after the
+// history.
+func (h *ProxyHandler) handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.corsError(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.corsError(w, "Streaming not supported", http.StatusInternalServerError)
+
+		return
+	}
+
+	query := r.URL.Query()
+	filter := events.Filter{Types: splitQueryList(query["type"]), Servers: splitQueryList(query["server"])}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("lastEventId")
+	}
+
+	backlog, stream, cancel := events.Default.Subscribe(filter, lastEventID)
+	defer cancel()
+	if lastEventID == "" && query.Get("history") == "false" {
+		backlog = nil
+	}
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = fmt.Fprintf(w, "retry: %d\n\n", constants.SSEClientRetryHint.Milliseconds())
+	for _, event := range backlog {
+		writeBusEvent(w, event)
+	}
+	flusher.Flush()
+	if query.Get("follow") == "false" {
+
+		return
+	}
+
+	keepAlive := time.NewTicker(constants.SSEClientKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+
+			return
+		case <-h.ctx.Done():
+
+			return
+		case event, open := <-stream:
+			if !open {
+				h.logger.Warning("Event bus client %s fell behind; closing so it can resume", r.RemoteAddr)
+
+				return
+			}
+			writeBusEvent(w, event)
+			flusher.Flush()
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeBusEvent(w http.ResponseWriter, event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+
+		return
+	}
+	_, _ = fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", event.ID, data)
+}
+
+func splitQueryList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,396 @@
+// internal/server/health.go
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// HealthProbe runs a server's health check once
+type HealthProbe struct {
+	Check       config.HealthCheck
+	Runtime     container.Runtime // runs exec checks inside containers
+	Identifier  string            // container or process name
+	IsContainer bool
+	BaseURL     string // scheme, host and port the server listens on, for http and mcp checks
+	MCPPath     string // path of the server's MCP endpoint, for mcp checks
+	Dir         string // working directory of exec checks for process servers
+}
+
+// Run probes the server, giving up after timeout
+func (p HealthProbe) Run(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	switch p.Check.CheckType() {
+	case "exec":
+
+		return p.runExec(ctx, timeout)
+	case "http":
+
+		return p.runHTTP(ctx, timeout)
+	case "mcp":
+
+		return p.runMCP(ctx, timeout)
+	case "none":
+
+		return nil
+	default:
+
+		return fmt.Errorf("unknown health check type '%s'", p.Check.Type)
+	}
+}
+
+// execCommand turns a Docker-style test into a command line: CMD runs the arguments as
+// given, CMD-SHELL runs them with sh -c, and a list without a prefix is run as given
+func execCommand(test []string) []string {
+	if len(test) == 0 {
+
+		return nil
+	}
+	switch strings.ToUpper(test[0]) {
+	case "CMD":
+
+		return test[1:]
+	case "CMD-SHELL":
+
+		return []string{"sh", "-c", strings.Join(test[1:], " ")}
+	default:
+
+		return test
+	}
+}
+
+func (p HealthProbe) runExec(ctx context.Context, timeout time.Duration) error {
+	command := execCommand(p.Check.Test)
+	if len(command) == 0 {
+
+		return fmt.Errorf("health check has no test command")
+	}
+
+	if !p.IsContainer {
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Dir = p.Dir
+		output, err := cmd.CombinedOutput()
+		if ctx.Err() != nil {
+
+			return fmt.Errorf("health check command timed out after %s", timeout)
+		}
+
+		return execResult(err, output)
+	}
+
+	if p.Runtime == nil {
+
+		return fmt.Errorf("no container runtime to run the health check in '%s'", p.Identifier)
+	}
+	cmd, stdin, stdout, err := p.Runtime.ExecContainer(p.Identifier, command, false)
+	if err != nil {
+
+		return fmt.Errorf("failed to run health check in '%s': %w", p.Identifier, err)
+	}
+	if closer, ok := stdin.(io.Closer); ok {
+		_ = closer.Close()
+	}
+
+	type execDone struct {
+		output []byte
+		err    error
+	}
+	done := make(chan execDone, 1)
+	go func() {
+		output, _ := io.ReadAll(stdout)
+		done <- execDone{output: output, err: cmd.Wait()}
+	}()
+
+	select {
+	case result := <-done:
+
+		return execResult(result.err, result.output)
+	case <-ctx.Done():
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+
+		return fmt.Errorf("health check command timed out after %s", timeout)
+	}
+}
+
+func execResult(err error, output []byte) error {
+	if err == nil {
+
+		return nil
+	}
+	detail := firstLine(output)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if detail != "" {
+
+			return fmt.Errorf("health check command exited with status %d: %s", exitErr.ExitCode(), detail)
+		}
+
+		return fmt.Errorf("health check command exited with status %d", exitErr.ExitCode())
+	}
+
+	return fmt.Errorf("failed to run health check command: %w", err)
+}
+
+func firstLine(output []byte) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	if len(line) > constants.HTTPLogBufferSize {
+		line = line[:constants.HTTPLogBufferSize] + "..."
+	}
+
+	return line
+}
+
+func (p HealthProbe) runHTTP(ctx context.Context, timeout time.Duration) error {
+	url := p.Check.Endpoint
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = p.BaseURL + url
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+
+		return fmt.Errorf("invalid health check URL %s: %w", url, err)
+	}
+	resp, err := healthClient(timeout).Do(req)
+	if err != nil {
+
+		return describeRequestError(url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, constants.HTTPLogBufferSize))
+
+		return fmt.Errorf("health check failed: status %d from %s: %s", resp.StatusCode, url, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// runMCP performs the MCP handshake and a ping, so a server that accepts connections but
+// can't answer protocol requests is reported unhealthy. With expect_tools it also lists the
+// server's tools and fails when one of them is missing.
+func (p HealthProbe) runMCP(ctx context.Context, timeout time.Duration) error {
+	path := p.MCPPath
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	url := p.BaseURL + path
+	client := healthClient(timeout)
+
+	initialize := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "initialize",
+		"params": map[string]interface{}{
+			"protocolVersion": protocol.MCPVersion,
+			"capabilities":    map[string]interface{}{},
+			"clientInfo":      map[string]interface{}{"name": "mcp-compose-healthcheck", "version": "1.0.0"},
+		},
+	}
+	sessionID, _, err := mcpHealthRequest(ctx, client, url, "", initialize)
+	if err != nil {
+
+		return fmt.Errorf("initialize failed: %w", err)
+	}
+
+	initialized := map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/initialized"}
+	if _, _, err := mcpHealthRequest(ctx, client, url, sessionID, initialized); err != nil {
+
+		return fmt.Errorf("initialized notification failed: %w", err)
+	}
+
+	ping := map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "ping"}
+	if _, _, err := mcpHealthRequest(ctx, client, url, sessionID, ping); err != nil {
+
+		return fmt.Errorf("ping failed: %w", err)
+	}
+
+	if len(p.Check.ExpectTools) == 0 {
+
+		return nil
+	}
+	available := make(map[string]bool)
+	cursor := ""
+	for page := 0; page < constants.HealthCheckMaxToolsPages; page++ {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		list := map[string]interface{}{"jsonrpc": "2.0", "id": 3 + page, "method": "tools/list", "params": params}
+		_, result, err := mcpHealthRequest(ctx, client, url, sessionID, list)
+		if err != nil {
+
+			return fmt.Errorf("tools/list failed: %w", err)
+		}
+		var tools struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(result, &tools); err != nil {
+
+			return fmt.Errorf("invalid tools/list result: %w", err)
+		}
+		for _, tool := range tools.Tools {
+			available[tool.Name] = true
+		}
+		if tools.NextCursor == "" {
+
+			break
+		}
+		cursor = tools.NextCursor
+	}
+
+	var missing []string
+	for _, name := range p.Check.ExpectTools {
+		if !available[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+
+		return fmt.Errorf("missing expected tools: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// mcpHealthRequest posts one JSON-RPC message and checks the reply, which may come as JSON
+// or as a server-sent event. It returns the session ID the server assigned, if any, and the
+// result of requests.
+func mcpHealthRequest(ctx context.Context, client *http.Client, url, sessionID string, message map[string]interface{}) (string, json.RawMessage, error) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+
+		return "", nil, fmt.Errorf("invalid MCP URL %s: %w", url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+
+		return "", nil, describeRequestError(url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		sessionID = id
+	}
+	if _, isRequest := message["id"]; !isRequest {
+		if resp.StatusCode >= 400 {
+
+			return sessionID, nil, fmt.Errorf("status %d from %s", resp.StatusCode, url)
+		}
+
+		return sessionID, nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, constants.HTTPLogBufferSize))
+
+		return sessionID, nil, fmt.Errorf("status %d from %s: %s", resp.StatusCode, url, strings.TrimSpace(string(body)))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, constants.HealthCheckMaxResponseSize))
+	if err != nil {
+
+		return sessionID, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		body = firstEventData(body)
+	}
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &reply); err != nil {
+
+		return sessionID, nil, fmt.Errorf("invalid JSON-RPC response: %w", err)
+	}
+	if reply.Error != nil {
+
+		return sessionID, nil, fmt.Errorf("error %d: %s", reply.Error.Code, reply.Error.Message)
+	}
+	if len(reply.Result) == 0 {
+
+		return sessionID, nil, fmt.Errorf("response has no result")
+	}
+
+	return sessionID, reply.Result, nil
+}
+
+func firstEventData(body []byte) []byte {
+	var data []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data:") {
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		} else if line == "" && len(data) > 0 {
+
+			break
+		}
+	}
+
+	return []byte(strings.Join(data, "\n"))
+}
+
+func healthClient(timeout time.Duration) *http.Client {
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DisableKeepAlives: true, // Don't keep connections alive for health checks
+			IdleConnTimeout:   timeout / constants.ManagerIdleConnDivisor,
+		},
+	}
+}
+
+func describeRequestError(url string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "connection refused"):
+
+		return fmt.Errorf("not reachable at %s: connection refused", url)
+	case strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "deadline exceeded"):
+
+		return fmt.Errorf("timed out at %s", url)
+	case strings.Contains(err.Error(), "no such host"):
+
+		return fmt.Errorf("hostname not found for %s", url)
+	default:
+
+		return fmt.Errorf("request to %s failed: %w", url, err)
+	}
+}
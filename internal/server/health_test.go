@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestHealthProbe(t *testing.T) {
+	mcpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		if _, isRequest := message["id"]; !isRequest {
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+		if message["method"] == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		} else if r.Header.Get("Mcp-Session-Id") != "session-1" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+
+			return
+		}
+		result := `{}`
+		if message["method"] == "tools/list" {
+			result = `{"tools":[{"name":"read_file"}],"nextCursor":"page-2"}`
+			if params, _ := message["params"].(map[string]interface{}); params["cursor"] == "page-2" {
+				result = `{"tools":[{"name":"write_file"}]}`
+			}
+		}
+		// Answer as a server-sent event, as streamable HTTP servers may
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":%s}\n\n", message["id"], result)
+	}))
+	defer mcpServer.Close()
+
+	brokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32603,"message":"backend down"}}`))
+	}))
+	defer brokenServer.Close()
+
+	tests := []struct {
+		name    string
+		probe   HealthProbe
+		healthy bool
+	}{
+		{"exec shell success", HealthProbe{Check: config.HealthCheck{Test: []string{"CMD-SHELL", "exit 0"}}}, true},
+		{"exec shell failure", HealthProbe{Check: config.HealthCheck{Test: []string{"CMD-SHELL", "exit 3"}}}, false},
+		{"exec command", HealthProbe{Check: config.HealthCheck{Test: []string{"CMD", "true"}}}, true},
+		{"exec disabled", HealthProbe{Check: config.HealthCheck{Test: []string{"NONE"}}}, true},
+		{"http endpoint", HealthProbe{Check: config.HealthCheck{Endpoint: "/health"}, BaseURL: mcpServer.URL}, true},
+		{"http unreachable", HealthProbe{Check: config.HealthCheck{Endpoint: "http://127.0.0.1:1/health"}}, false},
+		{"mcp handshake", HealthProbe{Check: config.HealthCheck{Type: "mcp"}, BaseURL: mcpServer.URL, MCPPath: "mcp"}, true},
+		{"mcp expected tools", HealthProbe{Check: config.HealthCheck{Type: "mcp", ExpectTools: []string{"read_file", "write_file"}}, BaseURL: mcpServer.URL}, true},
+		{"mcp missing tool", HealthProbe{Check: config.HealthCheck{Type: "mcp", ExpectTools: []string{"read_file", "delete_file"}}, BaseURL: mcpServer.URL}, false},
+		{"mcp error", HealthProbe{Check: config.HealthCheck{Type: "mcp"}, BaseURL: brokenServer.URL}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.probe.Run(5 * time.Second)
+			if tt.healthy && err != nil {
+				t.Errorf("Expected healthy, got: %v", err)
+			}
+			if !tt.healthy && err == nil {
+				t.Error("Expected the check to fail")
+			}
+		})
+	}
+}
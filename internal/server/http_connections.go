@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -50,7 +51,7 @@ func (h *ProxyHandler) getServerConnection(serverName string) (*MCPHTTPConnectio
 	}
 
 	h.logger.Info("Creating new HTTP connection for server: %s", serverName)
-	serverConfig, cfgExists := h.Manager.config.Servers[serverName]
+	serverConfig, cfgExists := h.Manager.Config().Servers[serverName]
 	if !cfgExists {
 
 		return nil, fmt.Errorf("configuration for server '%s' not found", serverName)
@@ -460,11 +461,14 @@ func (h *ProxyHandler) isConnectionHealthy(conn *MCPHTTPConnection) bool {
 	return true
 }
 
-func (h *ProxyHandler) forwardHTTPRequest(conn *MCPHTTPConnection, requestData []byte, timeout time.Duration) (map[string]interface{}, error) {
+// forwardHTTPRequest sends a client's request to an HTTP server, giving up when ctx ends or
+// the timeout passes. Servers may answer with an event stream, sending notifications such as
+// progress ahead of the response.
+func (h *ProxyHandler) forwardHTTPRequest(ctx context.Context, conn *MCPHTTPConnection, requestData []byte, timeout time.Duration, extraHeaders http.Header) (map[string]interface{}, error) {
 	targetURL := conn.BaseURL
 	h.logger.Debug("Forwarding request to %s (%s): %s", conn.ServerName, targetURL, string(requestData))
 
-	reqCtx, cancel := context.WithTimeout(h.ctx, timeout)
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	httpReq, err := http.NewRequestWithContext(reqCtx, "POST", targetURL, bytes.NewBuffer(requestData))
@@ -473,8 +477,13 @@ func (h *ProxyHandler) forwardHTTPRequest(conn *MCPHTTPConnection, requestData [
 		return nil, fmt.Errorf("create HTTP request for %s: %w", conn.ServerName, err)
 	}
 
+	for key, values := range extraHeaders {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
 
 	conn.mu.Lock()
 	if conn.SessionID != "" {
@@ -499,8 +508,13 @@ func (h *ProxyHandler) forwardHTTPRequest(conn *MCPHTTPConnection, requestData [
 	}
 	conn.mu.Unlock()
 
-	// Read and parse response
-	responseData, err := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+
+		return h.readHTTPEventStream(conn, resp.Body)
+	}
+
+	// Read and parse response, up to the server's size limit
+	responseData, err := h.readLimitedResponse(conn.ServerName, resp.Body)
 	if err != nil {
 
 		return nil, fmt.Errorf("failed to read response from %s: %w", targetURL, err)
@@ -525,6 +539,54 @@ func (h *ProxyHandler) forwardHTTPRequest(conn *MCPHTTPConnection, requestData [
 	return responseMap, nil
 }
 
+// readHTTPEventStream reads a server's event stream up to the response to the forwarded
+// request. Notifications on the way are handled as the server's other notifications, and
+// requests from the server are answered on the connection.
+func (h *ProxyHandler) readHTTPEventStream(conn *MCPHTTPConnection, body io.Reader) (map[string]interface{}, error) {
+	maxBytes := h.requestLimitsFor(conn.ServerName).maxResponseBytes
+	reader := bufio.NewReaderSize(body, constants.ResponseStreamChunkSize)
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadBytes('\n')
+		line = bytes.TrimRight(line, "\r\n")
+		if bytes.HasPrefix(line, []byte("data:")) {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.Write(bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" ")))
+			if maxBytes > 0 && int64(data.Len()) > maxBytes {
+
+				return nil, &responseTooLargeError{serverName: conn.ServerName, maxBytes: maxBytes}
+			}
+		}
+		if (len(line) == 0 || err != nil) && data.Len() > 0 {
+			var message map[string]interface{}
+			if jsonErr := json.Unmarshal(data.Bytes(), &message); jsonErr != nil {
+				h.logger.Debug("Skipping non-JSON event from %s: %s", conn.ServerName, data.String())
+			} else if isServerRequest(message) {
+				go h.replyToServerRequest(conn.ServerName, message, func(reply map[string]interface{}) error {
+
+					return h.sendHTTPNotification(conn, reply)
+				})
+			} else if _, hasMethod := message["method"]; hasMethod {
+				h.handleServerNotification(conn.ServerName, message)
+			} else {
+
+				return message, nil
+			}
+			data.Reset()
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+
+				return nil, fmt.Errorf("event stream from %s ended without a response", conn.ServerName)
+			}
+
+			return nil, fmt.Errorf("failed to read event stream from %s: %w", conn.ServerName, err)
+		}
+	}
+}
+
 func (h *ProxyHandler) maintainHttpConnections() {
 	h.ConnectionMutex.Lock()
 	defer h.ConnectionMutex.Unlock()
@@ -571,7 +633,8 @@ func (h *ProxyHandler) getConnectionHealthStatus(conn *MCPHTTPConnection) string
 
 // establishInitialHTTPConnections proactively establishes HTTP connections to all configured HTTP servers
 func (h *ProxyHandler) establishInitialHTTPConnections() {
-	if h.Manager == nil || h.Manager.config == nil {
+	composeCfg := h.composeConfig()
+	if composeCfg == nil {
 
 		return
 	}
@@ -581,7 +644,7 @@ func (h *ProxyHandler) establishInitialHTTPConnections() {
 
 	h.logger.Info("Establishing initial HTTP connections to configured servers")
 
-	for serverName, serverConfig := range h.Manager.config.Servers {
+	for serverName, serverConfig := range composeCfg.Servers {
 		// Only establish connections for HTTP servers
 		if serverConfig.Protocol == "http" || serverConfig.HttpPort > 0 {
 			go func(name string, cfg config.ServerConfig) {
@@ -618,14 +681,15 @@ func (h *ProxyHandler) establishInitialHTTPConnections() {
 // ensureHTTPConnectionsEstablished ensures HTTP connections are established for all configured HTTP servers
 // This can be called on-demand (e.g., from API endpoints) to refresh connections
 func (h *ProxyHandler) ensureHTTPConnectionsEstablished() {
-	if h.Manager == nil || h.Manager.config == nil {
+	composeCfg := h.composeConfig()
+	if composeCfg == nil {
 
 		return
 	}
 
 	h.logger.Debug("Ensuring HTTP connections are established for all configured servers")
 
-	for serverName, serverConfig := range h.Manager.config.Servers {
+	for serverName, serverConfig := range composeCfg.Servers {
 		// Only establish connections for HTTP servers
 		if serverConfig.Protocol == "http" || serverConfig.HttpPort > 0 {
 			// Check if we already have a healthy connection
@@ -3,15 +3,18 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
-	"github.com/phildougherty/mcp-compose/internal/dashboard"
+	"github.com/phildougherty/mcp-compose/internal/events"
 	"github.com/phildougherty/mcp-compose/internal/protocol"
+	"github.com/phildougherty/mcp-compose/internal/telemetry"
 )
 
 // MCPRequest, MCPResponse, MCPError structs (standard JSON-RPC definitions)
@@ -35,31 +38,32 @@ type MCPError struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// proxyCORSHeaders and proxyCORSExposedHeaders are the headers browser clients of the proxy
+// send and read, unless the cors config names its own
+var (
+	proxyCORSHeaders        = []string{"Content-Type", "Authorization", "X-Request-ID", "Mcp-Session-Id", "X-Client-ID", "X-MCP-Capabilities", "X-Supports-Notifications"}
+	proxyCORSExposedHeaders = []string{"Mcp-Session-Id", "Content-Type"}
+)
+
 func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 
-	dashboard.BroadcastActivity("INFO", "request", getServerNameFromPath(r.URL.Path), getClientIP(r),
-		fmt.Sprintf("Request: %s to %s", r.Method, r.URL.Path),
-		map[string]interface{}{
-			"method":   r.Method,
-			"endpoint": r.URL.Path,
-		})
-
 	h.logger.Info("Request: %s %s from %s (User-Agent: %s)", r.Method, r.URL.Path, r.RemoteAddr, r.Header.Get("User-Agent"))
 
-	// CORS Headers
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Mcp-Session-Id, X-Client-ID, X-MCP-Capabilities, X-Supports-Notifications")
-	w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id, Content-Type")
+	path := strings.TrimSuffix(r.URL.Path, "/")
 
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
+	// Addresses the access config keeps out are refused before anything else
+	if !h.allowedAddress(w, r, path) {
+
+		return
+	}
+
+	// CORS headers for the origins the config allows; preflights end here
+	if h.corsPolicy.Handle(w, r) {
 
 		return
 	}
 
-	path := strings.TrimSuffix(r.URL.Path, "/")
 	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", constants.URLPathParts)
 
 	// Handle OAuth endpoints FIRST - these should NOT require API key authentication
@@ -70,16 +74,98 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Servers verify the tokens the proxy issues with these keys, without credentials
+	if path == constants.JWKSPath {
+		h.handleJWKS(w, r)
+
+		return
+	}
+
+	// The task API authenticates on its own, as it also takes scoped OAuth tokens
+	if h.EnableAPI && (path == tasksAPIPath || strings.HasPrefix(path, tasksAPIPath+"/")) {
+		h.handleTasksAPI(w, r, path)
+		h.logger.Debug("Processed task API request %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+
+		return
+	}
+
+	// So does the admin API
+	if h.EnableAPI && (path == adminAPIPath || strings.HasPrefix(path, adminAPIPath+"/")) {
+		h.handleAdminAPI(w, r, path)
+		h.logger.Debug("Processed admin API request %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+
+		return
+	}
+
+	// And the sampling approval queue, which takes the admin API's scopes
+	if h.EnableAPI && (path == samplingAPIPath || strings.HasPrefix(path, samplingAPIPath+"/")) {
+		h.handleSamplingAPI(w, r, path)
+		h.logger.Debug("Processed sampling API request %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+
+		return
+	}
+
+	// And per-client usage analytics
+	if h.EnableAPI && (path == analyticsAPIPath || strings.HasPrefix(path, analyticsAPIPath+"/")) {
+		h.handleAnalyticsAPI(w, r, path)
+		h.logger.Debug("Processed analytics API request %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+
+		return
+	}
+
+	// And the cost of calls to paid servers
+	if h.EnableAPI && path == costsAPIPath {
+		h.handleCostsAPI(w, r)
+		h.logger.Debug("Processed costs API request %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+
+		return
+	}
+
+	// And the monthly quotas of clients
+	if h.EnableAPI && h.quotas != nil && (path == quotasAPIPath || strings.HasPrefix(path, quotasAPIPath+"/")) {
+		h.handleQuotasAPI(w, r, path)
+		h.logger.Debug("Processed quotas API request %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+
+		return
+	}
+
+	// And so does the built-in control server, which takes the admin API's scopes
+	if h.isControlServerPath(path) {
+		h.handleControlServer(w, r)
+		h.logger.Debug("Processed control server request %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+
+		return
+	}
+
+	// The management API's description needs no credentials
+	if h.EnableAPI && path == managementSpecPath {
+		h.handleManagementSpec(w, r)
+
+		return
+	}
+
 	// NOW do authentication check for other endpoints
 	if !h.authenticateAPIRequest(w, r) {
 
 		return
 	}
 
+	// Handle the merged MCP endpoint that fronts every server
+	if h.isAggregatorPath(path) {
+		h.handleAggregatorRequest(w, r)
+		h.logger.Debug("Processed aggregator request %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+
+		return
+	}
+
 	// Handle server-specific OpenAPI specs
 	if len(parts) >= 2 && parts[1] == "openapi.json" {
 		serverName := parts[0]
-		if _, exists := h.Manager.config.Servers[serverName]; exists {
+		if _, exists := h.Manager.Config().Servers[serverName]; exists {
+			if !h.allowedServerAddress(w, r, serverName) {
+
+				return
+			}
 			h.handleServerOpenAPISpec(w, r, serverName)
 			h.logger.Debug("Processed server OpenAPI spec %s %s in %v", r.Method, r.URL.Path, time.Since(start))
 
@@ -90,7 +176,11 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Handle server-specific docs
 	if len(parts) >= 2 && parts[1] == "docs" {
 		serverName := parts[0]
-		if _, exists := h.Manager.config.Servers[serverName]; exists {
+		if _, exists := h.Manager.Config().Servers[serverName]; exists {
+			if !h.allowedServerAddress(w, r, serverName) {
+
+				return
+			}
 			h.handleServerDocs(w, r, serverName)
 			h.logger.Debug("Processed server docs %s %s in %v", r.Method, r.URL.Path, time.Since(start))
 
@@ -146,9 +236,18 @@ handleServer:
 	if len(parts) > 0 && parts[0] != "api" {
 		serverName := parts[0]
 		if instance, exists := h.Manager.GetServerInstance(serverName); exists {
-			if r.Method == http.MethodPost {
+			if len(parts) > 1 && parts[1] == "tools" {
+				// REST bridge to the server's tools
+				toolName := ""
+				if len(parts) > 2 {
+					toolName = parts[2]
+				}
+				h.handleToolBridge(w, r, serverName, instance, toolName)
+			} else if r.Method == http.MethodPost {
 				// Use the new notification-aware method handler
 				h.handleMCPMethodForwarding(w, r, serverName, instance)
+			} else if r.Method == http.MethodGet && len(parts) == 1 && acceptsEventStream(r) {
+				h.handleServerEventStream(w, r, serverName, instance)
 			} else if r.Method == http.MethodGet && (len(parts) == 1 || (len(parts) > 1 && strings.HasSuffix(parts[1], ".json"))) {
 				h.handleServerDetails(w, r, serverName, instance)
 			} else if r.Method == http.MethodDelete && len(parts) == 1 && r.Header.Get("Mcp-Session-Id") != "" {
@@ -182,7 +281,7 @@ func (h *ProxyHandler) handleOAuthEndpoints(w http.ResponseWriter, r *http.Reque
 
 		return true
 	case "/oauth/authorize":
-		h.authServer.HandleAuthorize(w, r)
+		h.authServer.SecurePage(h.handleAuthorize)(w, r)
 
 		return true
 	case "/oauth/token":
@@ -200,6 +299,22 @@ func (h *ProxyHandler) handleOAuthEndpoints(w http.ResponseWriter, r *http.Reque
 	case "/oauth/register":
 		h.authServer.HandleRegister(w, r)
 
+		return true
+	case "/oauth/device_authorization":
+		h.authServer.HandleDeviceAuthorization(w, r)
+
+		return true
+	case "/oauth/device":
+		h.authServer.SecurePage(h.handleDeviceVerification)(w, r)
+
+		return true
+	case "/api/oauth/device":
+		h.handleDeviceAPI(w, r, path)
+
+		return true
+	case "/api/oauth/consents":
+		h.handleConsentsAPI(w, r, path)
+
 		return true
 	case "/oauth/callback":
 		h.handleOAuthCallback(w, r)
@@ -219,6 +334,17 @@ func (h *ProxyHandler) handleOAuthEndpoints(w http.ResponseWriter, r *http.Reque
 		return true
 	}
 
+	if strings.HasPrefix(path, deviceAPIPath+"/") {
+		h.handleDeviceAPI(w, r, path)
+
+		return true
+	}
+	if strings.HasPrefix(path, consentsAPIPath+"/") {
+		h.handleConsentsAPI(w, r, path)
+
+		return true
+	}
+
 	// Handle OAuth client deletion (path starts with /api/oauth/clients/)
 	if strings.HasPrefix(path, "/api/oauth/clients/") && r.Method == http.MethodDelete {
 		h.handleOAuthClientDelete(w, r)
@@ -258,10 +384,22 @@ func (h *ProxyHandler) handleAPIEndpoints(w http.ResponseWriter, r *http.Request
 	case "/api/notifications":
 		h.handleNotificationsAPI(w, r)
 
+		return true
+	case "/api/events":
+		h.handleAPIEvents(w, r)
+
+		return true
+	case "/api/catalog":
+		h.handleCatalogAPI(w, r)
+
 		return true
 	case "/openapi.json":
 		h.handleOpenAPISpec(w, r)
 
+		return true
+	case "/api/config", "/api/config/validate", "/api/config/apply", "/api/config/history", "/api/config/rollback":
+		h.handleConfigAPI(w, r, path)
+
 		return true
 	}
 
@@ -272,7 +410,7 @@ func (h *ProxyHandler) handleAPIEndpoints(w http.ResponseWriter, r *http.Request
 		return true
 	}
 
-	// Handle server-specific OAuth endpoints
+	// Handle server-specific OAuth and lifecycle endpoints
 	if strings.HasPrefix(path, "/api/servers/") {
 		pathParts := strings.Split(strings.Trim(path, "/"), "/")
 		if len(pathParts) >= constants.URLPathPartsExtended {
@@ -288,6 +426,14 @@ func (h *ProxyHandler) handleAPIEndpoints(w http.ResponseWriter, r *http.Request
 			case "tokens":
 				h.handleServerTokens(w, r)
 
+				return true
+			case "start", "stop", "restart":
+				h.handleServerLifecycle(w, r, pathParts[2], pathParts[3])
+
+				return true
+			case "detail":
+				h.handleServerDetail(w, r, pathParts[2])
+
 				return true
 			}
 		}
@@ -297,19 +443,18 @@ func (h *ProxyHandler) handleAPIEndpoints(w http.ResponseWriter, r *http.Request
 }
 
 func (h *ProxyHandler) authenticateAPIRequest(w http.ResponseWriter, r *http.Request) bool {
-	var apiKeyToCheck string
-	if h.Manager != nil && h.Manager.config != nil && h.Manager.config.ProxyAuth.Enabled {
-		apiKeyToCheck = h.Manager.config.ProxyAuth.APIKey
-	}
-	if h.APIKey != "" {
-		apiKeyToCheck = h.APIKey
+	if handled, ok := h.authenticateTrustedHeaders(w, r); handled {
+
+		return ok
 	}
 
+	apiKeyToCheck := h.getAPIKeyToCheck()
 	if apiKeyToCheck != "" {
 		authHeader := r.Header.Get("Authorization")
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token != apiKeyToCheck {
 			h.logger.Warning("Unauthorized access attempt to %s from %s (API key mismatch)", r.URL.Path, r.RemoteAddr)
+			h.authFailed(r, "", "API key mismatch")
 			h.corsError(w, "Unauthorized", http.StatusUnauthorized)
 
 			return false
@@ -322,15 +467,28 @@ func (h *ProxyHandler) authenticateAPIRequest(w http.ResponseWriter, r *http.Req
 func (h *ProxyHandler) handleMCPMethodForwarding(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Read request body
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		h.logger.Error("Failed to read request body for %s: %v", serverName, err)
-		h.sendMCPError(w, nil, -32700, "Error reading request body")
+	// Read request body, within the server's size limit
+	body, ok := h.readRequestBody(w, r, serverName)
+	if !ok {
 
 		return
 	}
 
+	// Batches are split and each message handled on its own
+	if isBatch(body) {
+		h.serveBatch(w, r, body, func(w http.ResponseWriter, r *http.Request, message []byte) {
+			h.handleMCPMessage(w, r, serverName, instance, message)
+		})
+
+		return
+	}
+	h.handleMCPMessage(w, r, serverName, instance, body)
+}
+
+// handleMCPMessage handles one JSON-RPC message sent to a server
+func (h *ProxyHandler) handleMCPMessage(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+
 	// Parse JSON payload
 	var requestPayload map[string]interface{}
 	if err := json.Unmarshal(body, &requestPayload); err != nil {
@@ -343,22 +501,26 @@ func (h *ProxyHandler) handleMCPMethodForwarding(w http.ResponseWriter, r *http.
 	reqIDVal := requestPayload["id"]
 	reqMethodVal, _ := requestPayload["method"].(string)
 
-	dashboard.BroadcastActivity("INFO", "request", serverName, getClientIP(r),
-		fmt.Sprintf("MCP Request: %s", reqMethodVal),
-		map[string]interface{}{
+	events.Publish(events.Event{
+		Type:    events.Request,
+		Server:  serverName,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("MCP Request: %s", reqMethodVal),
+		Details: map[string]interface{}{
 			"method":   reqMethodVal,
 			"id":       reqIDVal,
 			"endpoint": r.URL.Path,
-		})
+		},
+	})
 
 	// Handle notification-related methods first
 	switch reqMethodVal {
 	case "resources/subscribe":
-		h.handleResourceSubscribe(w, r, serverName, requestPayload)
+		h.handleResourceSubscribe(w, r, serverName, instance, requestPayload)
 
 		return
 	case "resources/unsubscribe":
-		h.handleResourceUnsubscribe(w, r, serverName, requestPayload)
+		h.handleResourceUnsubscribe(w, r, serverName, instance, requestPayload)
 
 		return
 	case "tools/list":
@@ -412,6 +574,24 @@ func (h *ProxyHandler) forwardToServerWithBody(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Enforce per-tool access control before anything reaches the server
+	if !h.authorizeToolCall(w, r, serverName, requestPayload, reqIDVal) {
+
+		return
+	}
+
+	// Servers that use roots get the roots of the client that initialized last
+	if reqMethodVal == "initialize" {
+		h.recordRootsClient(r, serverName, requestPayload)
+	}
+
+	// Cancellations end the client's request at the proxy and at the server
+	if reqMethodVal == protocol.NotificationCancelled {
+		h.cancelClientRequest(w, r, serverName, requestPayload)
+
+		return
+	}
+
 	// ONLY handle proxy-specific standard methods, NOT server methods
 	if isProxyStandardMethod(reqMethodVal) {
 		h.handleProxyStandardMethod(w, r, requestPayload, reqIDVal, reqMethodVal)
@@ -419,9 +599,21 @@ func (h *ProxyHandler) forwardToServerWithBody(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Run the server's middleware chain around the forwarded call
+	if chain := h.middlewareChainFor(serverName); len(chain) > 0 {
+		h.forwardWithMiddleware(w, r, serverName, instance, chain, requestPayload, reqIDVal, reqMethodVal)
+
+		return
+	}
+
 	// FORWARD ALL OTHER METHODS TO THE ACTUAL MCP SERVERS
+	h.dispatchToTransport(w, r, serverName, instance, body, requestPayload, reqIDVal, reqMethodVal)
+}
+
+// dispatchToTransport forwards a request to a server over its configured transport
+func (h *ProxyHandler) dispatchToTransport(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance, body []byte, requestPayload map[string]interface{}, reqIDVal interface{}, reqMethodVal string) {
 	// Get server config
-	serverConfig, exists := h.Manager.config.Servers[serverName]
+	serverConfig, exists := h.Manager.Config().Servers[serverName]
 	if !exists {
 		h.logger.Error("Server config not found for %s", serverName)
 		h.sendMCPError(w, reqIDVal, -32602, "Server configuration not found")
@@ -438,14 +630,129 @@ func (h *ProxyHandler) forwardToServerWithBody(w http.ResponseWriter, r *http.Re
 	h.logger.Info("Forwarding request to server '%s' using '%s' transport: Method=%s, ID=%v",
 		serverName, protocolType, reqMethodVal, reqIDVal)
 
+	tracedWriter, r := h.startProxySpan(w, r, serverName, protocolType, reqMethodVal, requestPayload)
+	defer tracedWriter.finish()
+	w = tracedWriter
+
+	// Refuse tool calls from clients over their monthly quota, then count the request and its
+	// cost against the caller's usage
+	if h.quotas != nil && reqIDVal != nil && reqMethodVal == "tools/call" && !h.checkQuota(w, r, serverName, reqIDVal) {
+
+		return
+	}
+	if h.analytics != nil && reqIDVal != nil {
+		toolName := ""
+		if reqMethodVal == "tools/call" {
+			toolName = toolCallName(requestPayload)
+		}
+		usageWriter, finish := h.trackUsage(w, r, serverName, toolName, len(body))
+		defer finish()
+		w = usageWriter
+	}
+
+	// Requests can be cancelled by the client, and their progress goes back to it alone
+	var request *inflightRequest
+	if reqIDVal != nil {
+		request, r, body = h.trackRequest(r, serverName, body, requestPayload, reqIDVal)
+		defer h.untrackRequest(request)
+	}
+
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		// Prompts declared in the compose file are rendered by the proxy
+		if reqMethodVal == "prompts/get" && len(serverConfig.Prompts) > 0 {
+			if h.serveComposePrompt(w, serverName, serverConfig.Prompts, requestPayload, reqIDVal) {
+
+				return
+			}
+		}
+
+		// Wait for a free slot when the server limits concurrent requests
+		if queue := h.requestQueueFor(serverName); queue != nil {
+			release, ok := h.waitForSlot(w, r, queue, serverName, reqIDVal)
+			if !ok {
+
+				return
+			}
+			if request != nil {
+				release = request.holdSlot(release)
+			}
+			defer release()
+		}
+
+		forward := func(target http.ResponseWriter) {
+			h.forwardOverTransport(target, r, serverName, protocolType, serverConfig, instance, body, requestPayload, reqIDVal, reqMethodVal)
+		}
+		if reqMethodVal == "prompts/list" && len(serverConfig.Prompts) > 0 {
+			listServerPrompts := forward
+			forward = func(target http.ResponseWriter) {
+				h.listWithComposePrompts(target, serverName, serverConfig.Prompts, listServerPrompts, requestPayload, reqIDVal)
+			}
+		}
+
+		// Serve list methods from the catalog cache until they expire or the server reports a change
+		if isCacheableList(reqMethodVal, requestPayload) {
+			if ttl := h.catalogTTL(serverName); ttl > 0 {
+				h.listThroughCatalog(w, serverName, reqMethodVal, ttl, forward, reqIDVal)
+
+				return
+			}
+		}
+
+		// Mirror resources into object storage, serving fresh copies when a TTL is set
+		read := forward
+		if reqMethodVal == "resources/read" {
+			if mirror := h.resourceMirrorFor(serverName); mirror != nil {
+				read = func(target http.ResponseWriter) {
+					h.readThroughMirror(target, r, mirror, forward, requestPayload, reqIDVal)
+				}
+			}
+		}
+
+		// Answer repeated read-only calls from the response cache
+		if cacheConfig, ttl, ok := h.responseCacheFor(serverName, reqMethodVal); ok {
+			h.callThroughResponseCache(w, serverName, reqMethodVal, cacheConfig, ttl, read, requestPayload, reqIDVal)
+
+			return
+		}
+
+		read(w)
+	}
+
+	// Hold the response to the server's size and time limits; the time limit includes any
+	// wait for a free slot
+	if limits := h.requestLimitsFor(serverName); limits.maxResponseBytes > 0 || limits.timeout > 0 {
+		serveUnlimited := serve
+		serve = func(w http.ResponseWriter, r *http.Request) {
+			h.serveWithinLimits(w, r, serverName, limits, reqIDVal, serveUnlimited)
+		}
+	}
+
+	if request != nil {
+		h.serveCancellable(w, r, request, serve)
+
+		return
+	}
+	serve(w, r)
+}
+
+// forwardOverTransport routes a request to the handler for the server's transport protocol
+func (h *ProxyHandler) forwardOverTransport(w http.ResponseWriter, r *http.Request, serverName, protocolType string, serverConfig config.ServerConfig, instance *ServerInstance, body []byte, requestPayload map[string]interface{}, reqIDVal interface{}, reqMethodVal string) {
 	// Route based on transport protocol - pass the body bytes
 	switch protocolType {
 	case "http":
+		// Resources can be far larger than other results, so they are streamed through
+		if reqMethodVal == "resources/read" {
+			h.streamHTTPServerRequest(w, r, serverName, body, reqIDVal, reqMethodVal)
+
+			return
+		}
 		h.handleHTTPServerRequestWithBody(w, r, serverName, instance, body, reqIDVal, reqMethodVal)
 	case "sse":
 		h.handleSSEServerRequest(w, r, serverName, instance, requestPayload, reqIDVal, reqMethodVal)
 	case "stdio":
-		if serverConfig.StdioHosterPort > 0 {
+		if stdioSharingEnabled(serverConfig) {
+			h.handleMultiplexedSTDIORequest(w, r, serverName, serverConfig, requestPayload, reqIDVal, reqMethodVal)
+		} else if serverConfig.StdioHosterPort > 0 {
 			h.handleSocatSTDIOServerRequest(w, r, serverName, requestPayload, reqIDVal, reqMethodVal)
 		} else {
 			h.handleSTDIOServerRequest(w, r, serverName, requestPayload, reqIDVal, reqMethodVal)
@@ -532,12 +839,36 @@ func (h *ProxyHandler) handleHTTPServerRequestWithBody(w http.ResponseWriter, r
 	}
 	conn.mu.Unlock()
 
+	var toolName string
+	if reqMethodVal == "tools/call" {
+		var requestPayload map[string]interface{}
+		_ = json.Unmarshal(body, &requestPayload)
+		toolName = toolCallName(requestPayload)
+	}
+
 	// Use the pre-read body bytes directly
-	responsePayload, err := h.forwardHTTPRequest(conn, body, mcpCallTimeout)
+	ctx, span := h.Manager.tracer.StartSpan(r.Context(), "mcp.http "+reqMethodVal, telemetry.SpanKindClient)
+	span.SetAttribute("mcp.server", serverName)
+	span.SetAttribute("rpc.method", reqMethodVal)
+	headers, err := h.backendHeaders(ctx, r, serverName)
+	if err != nil {
+		span.End(err)
+		h.logger.Error("Refusing to forward %s to %s: %v", reqMethodVal, serverName, err)
+		h.sendMCPError(w, reqIDVal, -32603, fmt.Sprintf("Proxy cannot forward the caller's identity to '%s'", serverName))
+
+		return
+	}
+	responsePayload, err := h.forwardHTTPRequest(ctx, conn, body, mcpCallTimeout, headers)
+	span.End(err)
 	if err != nil {
-		dashboard.BroadcastActivity("ERROR", "request", serverName, getClientIP(r),
-			fmt.Sprintf("Error: %s failed: %v", reqMethodVal, err),
-			map[string]interface{}{"error": err.Error()})
+		publishMCPResult(r, serverName, reqMethodVal, toolName, nil, err)
+
+		var tooLarge *responseTooLargeError
+		if errors.As(err, &tooLarge) {
+			h.sendResponseTooLarge(w, serverName, tooLarge.maxBytes, reqIDVal)
+
+			return
+		}
 
 		h.logger.Error("MCP request to %s (method: %s) failed: %v", serverName, reqMethodVal, err)
 		errData := map[string]interface{}{"details": err.Error()}
@@ -565,8 +896,7 @@ func (h *ProxyHandler) handleHTTPServerRequestWithBody(w http.ResponseWriter, r
 	if err := json.NewEncoder(w).Encode(responsePayload); err != nil {
 		h.logger.Error("Failed to encode/send response for %s: %v", serverName, err)
 	} else {
-		dashboard.BroadcastActivity("INFO", "request", serverName, getClientIP(r),
-			fmt.Sprintf("Response: %s completed successfully", reqMethodVal), nil)
+		publishMCPResult(r, serverName, reqMethodVal, toolName, responsePayload, nil)
 	}
 
 	h.logger.Info("Successfully forwarded HTTP request to %s (method: %s, ID: %v)", serverName, reqMethodVal, reqIDVal)
@@ -609,9 +939,7 @@ func (h *ProxyHandler) handleSSEServerRequest(w http.ResponseWriter, r *http.Req
 	// Send request via optimal SSE connection
 	responsePayload, err := h.sendOptimalSSERequest(serverName, requestPayload)
 	if err != nil {
-		dashboard.BroadcastActivity("ERROR", "request", serverName, getClientIP(r),
-			fmt.Sprintf("Error: %s failed: %v", reqMethodVal, err),
-			map[string]interface{}{"error": err.Error()})
+		publishMCPResult(r, serverName, reqMethodVal, toolCallName(requestPayload), nil, err)
 
 		h.logger.Error("SSE request to %s (method: %s) failed: %v", serverName, reqMethodVal, err)
 		errData := map[string]interface{}{"details": err.Error()}
@@ -647,8 +975,7 @@ func (h *ProxyHandler) handleSSEServerRequest(w http.ResponseWriter, r *http.Req
 	if err := json.NewEncoder(w).Encode(responsePayload); err != nil {
 		h.logger.Error("Failed to encode/send response for %s: %v", serverName, err)
 	} else {
-		dashboard.BroadcastActivity("INFO", "request", serverName, getClientIP(r),
-			fmt.Sprintf("Response: %s completed successfully", reqMethodVal), nil)
+		publishMCPResult(r, serverName, reqMethodVal, toolCallName(requestPayload), responsePayload, nil)
 	}
 
 	h.logger.Info("Successfully forwarded SSE request to %s (method: %s, ID: %v)", serverName, reqMethodVal, reqIDVal)
@@ -661,6 +988,7 @@ func (h *ProxyHandler) handleSessionTermination(w http.ResponseWriter, r *http.R
 
 		return
 	}
+	h.dropClientSubscriptions(clientSessionID)
 
 	h.logger.Info("Received DELETE request to terminate session '%s' for server '%s'", clientSessionID, serverName)
 
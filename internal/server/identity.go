@@ -0,0 +1,140 @@
+// internal/server/identity.go
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// identityTokens returns the signer of identity tokens, created when first needed so a key
+// is only generated for a configuration that forwards identities
+func (h *ProxyHandler) identityTokens() (*auth.IdentitySigner, error) {
+	h.identitySignerMu.Lock()
+	defer h.identitySignerMu.Unlock()
+	if h.identitySigner != nil {
+
+		return h.identitySigner, nil
+	}
+
+	cfg := h.Manager.Config()
+	tokens := cfg.ProxyAuth.IdentityTokens
+	keyFile := ""
+	if tokens != nil && tokens.SigningKeyFile != "" {
+		keyFile = tokens.SigningKeyFile
+		if !filepath.IsAbs(keyFile) {
+			keyFile = filepath.Join(config.ProjectDir(h.ConfigFile), keyFile)
+		}
+	}
+	issuer := ""
+	if cfg.OAuth != nil && cfg.OAuth.Enabled {
+		issuer = cfg.OAuth.Issuer
+	}
+	signer, err := auth.NewIdentitySigner(tokens, keyFile, issuer)
+	if err != nil {
+
+		return nil, err
+	}
+	if keyFile == "" {
+		h.logger.Info("Signing forwarded identities with a key generated at startup; servers fetch it from %s", constants.JWKSPath)
+	}
+	h.identitySigner = signer
+
+	return signer, nil
+}
+
+// forwardsIdentity reports whether the proxy sends a server the caller's identity, and in
+// which header
+func (h *ProxyHandler) forwardsIdentity(serverName string) (string, bool) {
+	serverConfig, exists := h.Manager.Config().Servers[serverName]
+	if !exists || serverConfig.OAuth == nil || !serverConfig.OAuth.ForwardIdentity {
+
+		return "", false
+	}
+	if serverConfig.OAuth.IdentityHeader != "" {
+
+		return serverConfig.OAuth.IdentityHeader, true
+	}
+
+	return constants.IdentityTokenHeader, true
+}
+
+// backendHeaders returns the headers a request forwarded to an HTTP server carries: those
+// middleware and tracing add and, for servers with forward_identity, the caller's identity
+// signed by the proxy. Requests made with the proxy API key or without credentials carry
+// no identity.
+func (h *ProxyHandler) backendHeaders(ctx context.Context, r *http.Request, serverName string) (http.Header, error) {
+	headers := tracedUpstreamHeaders(ctx)
+	header, forward := h.forwardsIdentity(serverName)
+	if !forward {
+
+		return headers, nil
+	}
+	// Whatever the client sent in the header never reaches the server
+	headers.Del(header)
+	identity := auth.RequestIdentity(r.Context())
+	if identity == nil {
+
+		return headers, nil
+	}
+	signer, err := h.identityTokens()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to sign the caller's identity: %w", err)
+	}
+	token, err := signer.Sign(*identity, serverName)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to sign the caller's identity: %w", err)
+	}
+	headers.Set(header, token)
+
+	return headers, nil
+}
+
+// handleJWKS serves the keys the tokens the proxy issues are verified with, to anyone: they
+// are public. They are the keys of signed access tokens and of the identity tokens sent to
+// servers with forward_identity.
+func (h *ProxyHandler) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.corsError(w, "Method not allowed - use GET", http.StatusMethodNotAllowed)
+
+		return
+	}
+	var keys []map[string]string
+	if h.authServer != nil {
+		if signer := h.authServer.TokenSigner(); signer != nil {
+			keys = append(keys, signer.JWKs()...)
+		}
+	}
+
+	cfg := h.Manager.Config()
+	forwarding := cfg.ProxyAuth.IdentityTokens != nil
+	for name := range cfg.Servers {
+		if _, forward := h.forwardsIdentity(name); forward {
+			forwarding = true
+		}
+	}
+	if forwarding {
+		signer, err := h.identityTokens()
+		if err != nil {
+			h.logger.Error("Cannot serve identity token keys: %v", err)
+			h.corsError(w, "Identity signing key unavailable", http.StatusInternalServerError)
+
+			return
+		}
+		keys = append(keys, signer.JWK())
+	}
+
+	if len(keys) == 0 {
+		h.corsError(w, "The proxy signs no tokens: set oauth.tokens.algorithm or a server's oauth.forward_identity", http.StatusNotFound)
+
+		return
+	}
+	auth.WriteJWKS(w, keys)
+}
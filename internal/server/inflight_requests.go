@@ -0,0 +1,311 @@
+// internal/server/inflight_requests.go
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// inflightRequest is a client request the proxy is forwarding to a server. The client can
+// cancel it with notifications/cancelled, and the server's progress notifications for it go
+// back to that client alone.
+type inflightRequest struct {
+	serverName    string
+	clientID      string
+	requestID     interface{}
+	progressToken interface{} // The client's progress token, if it asked for progress
+	proxyToken    string      // Token the server sees instead, unique across clients
+	cancel        context.CancelCauseFunc
+	cancelled     chan struct{}
+	cancelOnce    sync.Once
+	reason        string
+	mu            sync.Mutex
+	release       func() // Frees the request's slot in the server's queue, once it holds one
+}
+
+// holdSlot records the release of the queue slot the request holds, so that cancelling the
+// request frees the slot at once. The returned release is safe to call again.
+func (req *inflightRequest) holdSlot(release func()) func() {
+	once := sync.OnceFunc(release)
+	req.mu.Lock()
+	req.release = once
+	req.mu.Unlock()
+
+	return once
+}
+
+// cancelWith ends the request's context and frees its slot
+func (req *inflightRequest) cancelWith(reason string) {
+	req.cancelOnce.Do(func() {
+		req.reason = reason
+		close(req.cancelled)
+		req.cancel(errors.New(reason))
+		req.mu.Lock()
+		release := req.release
+		req.mu.Unlock()
+		if release != nil {
+			release()
+		}
+	})
+}
+
+func inflightKey(serverName, clientID string, requestID interface{}) string {
+	id, _ := json.Marshal(requestID)
+
+	return serverName + "\x00" + clientID + "\x00" + string(id)
+}
+
+// trackRequest registers a request the client may cancel. A progress token in its _meta is
+// swapped for one unique to the proxy, so that servers shared by several clients report
+// progress the proxy can route; the returned body carries the swapped token.
+func (h *ProxyHandler) trackRequest(r *http.Request, serverName string, body []byte, requestPayload map[string]interface{}, reqIDVal interface{}) (*inflightRequest, *http.Request, []byte) {
+	ctx, cancel := context.WithCancelCause(r.Context())
+	request := &inflightRequest{
+		serverName: serverName,
+		clientID:   h.getClientID(r),
+		requestID:  reqIDVal,
+		cancel:     cancel,
+		cancelled:  make(chan struct{}),
+	}
+
+	params, _ := requestPayload["params"].(map[string]interface{})
+	meta, _ := params["_meta"].(map[string]interface{})
+	if token := meta["progressToken"]; token != nil {
+		request.progressToken = token
+		request.proxyToken = fmt.Sprintf("progress-%d", atomic.AddUint64(&h.nextProgressToken, 1))
+		meta["progressToken"] = request.proxyToken
+		if data, err := json.Marshal(requestPayload); err == nil {
+			body = data
+		}
+	}
+
+	h.inflightMu.Lock()
+	if h.inflight == nil {
+		h.inflight = make(map[string]*inflightRequest)
+		h.inflightProgress = make(map[string]*inflightRequest)
+	}
+	h.inflight[inflightKey(serverName, request.clientID, reqIDVal)] = request
+	if request.proxyToken != "" {
+		h.inflightProgress[request.proxyToken] = request
+	}
+	h.inflightMu.Unlock()
+
+	return request, r.WithContext(ctx), body
+}
+
+// untrackRequest forgets a request once its response is sent
+func (h *ProxyHandler) untrackRequest(request *inflightRequest) {
+	request.cancel(context.Canceled)
+
+	h.inflightMu.Lock()
+	defer h.inflightMu.Unlock()
+	key := inflightKey(request.serverName, request.clientID, request.requestID)
+	if h.inflight[key] == request {
+		delete(h.inflight, key)
+	}
+	if request.proxyToken != "" {
+		delete(h.inflightProgress, request.proxyToken)
+	}
+}
+
+// cancellableResponseWriter passes a response through until the request is cancelled before
+// the response started, after which the rest of it is dropped
+type cancellableResponseWriter struct {
+	http.ResponseWriter
+	mu        sync.Mutex
+	started   bool
+	abandoned bool
+}
+
+func (cw *cancellableResponseWriter) WriteHeader(statusCode int) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if cw.abandoned {
+
+		return
+	}
+	cw.started = true
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (cw *cancellableResponseWriter) Write(data []byte) (int, error) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if cw.abandoned {
+
+		return len(data), nil
+	}
+	cw.started = true
+
+	return cw.ResponseWriter.Write(data)
+}
+
+func (cw *cancellableResponseWriter) Flush() {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok && !cw.abandoned {
+		flusher.Flush()
+	}
+}
+
+// abandon drops the rest of the response, unless it has already started
+func (cw *cancellableResponseWriter) abandon() bool {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if !cw.started {
+		cw.abandoned = true
+	}
+
+	return cw.abandoned
+}
+
+// serveCancellable runs serve until it finishes or the client cancels the request. A
+// cancelled request is answered at once with a RequestCancelled error; the server's late
+// response is dropped.
+func (h *ProxyHandler) serveCancellable(w http.ResponseWriter, r *http.Request, request *inflightRequest, serve func(http.ResponseWriter, *http.Request)) {
+	cw := &cancellableResponseWriter{ResponseWriter: w}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve(cw, r)
+	}()
+
+	select {
+	case <-done:
+
+		return
+	case <-request.cancelled:
+	}
+	if !cw.abandon() {
+		// The response was already on its way; let it finish
+		<-done
+
+		return
+	}
+	h.sendMCPError(w, request.requestID, protocol.RequestCancelled,
+		fmt.Sprintf("Request cancelled: %s", request.reason))
+}
+
+// cancelClientRequest handles a client's notifications/cancelled: the request is answered as
+// cancelled, its queue slot freed and the server told to stop working on it
+func (h *ProxyHandler) cancelClientRequest(w http.ResponseWriter, r *http.Request, serverName string, requestPayload map[string]interface{}) {
+	params, _ := requestPayload["params"].(map[string]interface{})
+	reason, _ := params["reason"].(string)
+	if reason == "" {
+		reason = "cancelled by the client"
+	}
+	clientID := h.getClientID(r)
+
+	h.inflightMu.Lock()
+	request := h.inflight[inflightKey(serverName, clientID, params["requestId"])]
+	h.inflightMu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+	if request == nil {
+		h.logger.Debug("Ignoring cancellation of unknown request %v on %s from %s", params["requestId"], serverName, clientID)
+
+		return
+	}
+
+	h.logger.Info("Client %s cancelled request %v on %s: %s", clientID, request.requestID, serverName, reason)
+	request.cancelWith(reason)
+
+	// A shared stdio session tells the server itself, under the ID the server knows
+	serverCfg, exists := h.Manager.Config().Servers[serverName]
+	if exists && (serverCfg.Protocol == "" || serverCfg.Protocol == "stdio") && stdioSharingEnabled(serverCfg) {
+
+		return
+	}
+	if err := h.notifyServer(serverName, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  protocol.NotificationCancelled,
+		"params":  map[string]interface{}{"requestId": request.requestID, "reason": reason},
+	}); err != nil {
+		h.logger.Warning("Failed to tell %s request %v was cancelled: %v", serverName, request.requestID, err)
+	}
+}
+
+// routeProgress sends a server's progress notification to the client whose request it
+// reports on, under the client's own token. Progress for requests that already finished
+// is dropped.
+func (h *ProxyHandler) routeProgress(serverName string, message map[string]interface{}) {
+	params, _ := message["params"].(map[string]interface{})
+	token, _ := params["progressToken"].(string)
+
+	h.inflightMu.Lock()
+	request := h.inflightProgress[token]
+	h.inflightMu.Unlock()
+	if request == nil || request.serverName != serverName {
+		h.logger.Debug("Dropping progress from %s for unknown token %v", serverName, params["progressToken"])
+
+		return
+	}
+
+	routed := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		routed[key] = value
+	}
+	routed["progressToken"] = request.progressToken
+	data, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  protocol.NotificationProgress,
+		"params":  routed,
+	})
+	if err != nil {
+		h.logger.Warning("Failed to encode progress from %s: %v", serverName, err)
+
+		return
+	}
+	h.eventBufferFor(serverName).publishTo(request.clientID, data)
+}
+
+// notifyServer sends a notification over the proxy's open sessions with a server
+func (h *ProxyHandler) notifyServer(serverName string, notification map[string]interface{}) error {
+	var errs []error
+
+	h.stdioMuxesMu.Lock()
+	mux := h.stdioMuxes[serverName]
+	h.stdioMuxesMu.Unlock()
+	if mux != nil && !mux.isClosed() {
+		errs = append(errs, mux.send(notification))
+	}
+
+	h.StdioMutex.RLock()
+	stdioConn := h.StdioConnections[serverName]
+	h.StdioMutex.RUnlock()
+	if stdioConn != nil {
+		stdioConn.mu.Lock()
+		errs = append(errs, h.sendStdioRequestWithoutLock(stdioConn, notification))
+		stdioConn.mu.Unlock()
+	}
+
+	h.SSEMutex.RLock()
+	sseConn := h.SSEConnections[serverName]
+	h.SSEMutex.RUnlock()
+	if sseConn != nil {
+		errs = append(errs, h.sendSSERequestNoResponse(sseConn, notification))
+	}
+
+	h.EnhancedSSEMutex.RLock()
+	enhancedConn := h.EnhancedSSEConnections[serverName]
+	h.EnhancedSSEMutex.RUnlock()
+	if enhancedConn != nil {
+		_, err := h.sendEnhancedSSERequestNoResponse(enhancedConn, notification)
+		errs = append(errs, err)
+	}
+
+	h.ConnectionMutex.RLock()
+	httpConn := h.ServerConnections[serverName]
+	h.ConnectionMutex.RUnlock()
+	if httpConn != nil {
+		errs = append(errs, h.sendHTTPNotification(httpConn, notification))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestProgressAndCancellation(t *testing.T) {
+	var mu sync.Mutex
+	var cancellations []map[string]interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		params, _ := request["params"].(map[string]interface{})
+		switch request["method"] {
+		case protocol.NotificationCancelled:
+			mu.Lock()
+			cancellations = append(cancellations, params)
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		case "tools/call":
+			if params["name"] == "fast" {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{"content":[]}}`, request["id"])
+
+				return
+			}
+			meta, _ := params["_meta"].(map[string]interface{})
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{\"progressToken\":%q,\"progress\":1,\"total\":4}}\n\n", meta["progressToken"])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{}}`, request["id"])
+		}
+	}))
+	defer backend.Close()
+
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"tools": {Protocol: "http", MaxConcurrent: 1},
+	}}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager: newTestManager(cfg, logger, map[string]*ServerInstance{
+			"tools": {Name: "tools", Config: cfg.Servers["tools"]},
+		}),
+		logger:          logger,
+		ctx:             context.Background(),
+		httpClient:      http.DefaultClient,
+		catalog:         newCatalogCache(),
+		responses:       newResponseCache(),
+		sseEventBuffers: make(map[string]*sseEventBuffer),
+		ServerConnections: map[string]*MCPHTTPConnection{
+			"tools": {ServerName: "tools", BaseURL: backend.URL, Initialized: true, Healthy: true},
+		},
+	}
+
+	send := func(client, body string) (int, MCPResponse) {
+		r := httptest.NewRequest(http.MethodPost, "/tools", strings.NewReader(body))
+		r.Header.Set("X-Client-ID", client)
+		recorder := httptest.NewRecorder()
+		instance, _ := h.Manager.GetServerInstance("tools")
+		h.handleMCPMethodForwarding(recorder, r, "tools", instance)
+		var response MCPResponse
+		_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		return recorder.Code, response
+	}
+	_, alice := h.eventBufferFor("tools").subscribe("", "alice")
+	_, bob := h.eventBufferFor("tools").subscribe("", "bob")
+
+	replies := make(chan MCPResponse, 1)
+	go func() {
+		_, response := send("alice", `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"slow","_meta":{"progressToken":"tok-a"}}}`)
+		replies <- response
+	}()
+	select {
+	case event := <-alice:
+		var progress struct {
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		_ = json.Unmarshal(event.data, &progress)
+		if progress.Method != protocol.NotificationProgress || progress.Params["progressToken"] != "tok-a" || progress.Params["total"] != float64(4) {
+			t.Errorf("progress should reach the client under its own token, got %s", event.data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no progress for the client that asked for it")
+	}
+	select {
+	case event := <-bob:
+		t.Errorf("progress for alice's request reached bob: %s", event.data)
+	default:
+	}
+
+	if code, _ := send("bob", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`); code != http.StatusAccepted {
+		t.Errorf("cancelling another client's request: expected 202, got %d", code)
+	}
+	if code, _ := send("alice", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user stopped"}}`); code != http.StatusAccepted {
+		t.Errorf("cancellation: expected 202, got %d", code)
+	}
+	select {
+	case response := <-replies:
+		if response.Error == nil || response.Error.Code != protocol.RequestCancelled || !strings.Contains(response.Error.Message, "user stopped") {
+			t.Errorf("cancelled request: expected a cancellation error, got %+v", response)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the cancelled request was not answered")
+	}
+	mu.Lock()
+	told := cancellations
+	mu.Unlock()
+	if len(told) != 1 || told[0]["requestId"] != float64(7) || told[0]["reason"] != "user stopped" {
+		t.Errorf("the server should be told which request was cancelled, got %+v", told)
+	}
+
+	done := make(chan MCPResponse, 1)
+	go func() {
+		_, response := send("alice", `{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"fast"}}`)
+		done <- response
+	}()
+	select {
+	case response := <-done:
+		if response.Error != nil {
+			t.Errorf("call after cancellation: unexpected error %+v", response.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the cancelled request still holds the server's only slot")
+	}
+	h.inflightMu.Lock()
+	tracked := len(h.inflight) + len(h.inflightProgress)
+	h.inflightMu.Unlock()
+	if tracked != 0 {
+		t.Errorf("finished requests should be forgotten, %d still tracked", tracked)
+	}
+}
+
+func TestStdioMuxCancellationReason(t *testing.T) {
+	proxySide, serverSide := net.Pipe()
+	defer func() { _ = serverSide.Close() }()
+	mux := &stdioMux{serverName: "files", transport: proxySide, pending: make(map[string]chan map[string]interface{}), closed: make(chan struct{})}
+	defer mux.shutdown(nil)
+
+	received := make(chan map[string]interface{}, 2)
+	go func() {
+		scanner := bufio.NewScanner(serverSide)
+		for scanner.Scan() {
+			var message map[string]interface{}
+			_ = json.Unmarshal(scanner.Bytes(), &message)
+			received <- message
+		}
+	}()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		<-received
+		cancel(errors.New("user stopped"))
+	}()
+	if _, err := mux.request(ctx, map[string]interface{}{"jsonrpc": "2.0", "method": "tools/call"}); err == nil {
+		t.Fatal("a cancelled request should fail")
+	}
+	select {
+	case message := <-received:
+		params, _ := message["params"].(map[string]interface{})
+		if message["method"] != protocol.NotificationCancelled || params["reason"] != "user stopped" || params["requestId"] != "mux-1" {
+			t.Errorf("the server should be told the client's reason under its own ID, got %+v", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the server was not told the request was cancelled")
+	}
+}
@@ -0,0 +1,259 @@
+// internal/server/init_jobs.go
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/events"
+)
+
+// InitJobResult records the last run of one init job
+type InitJobResult struct {
+	Server     string    `json:"server"`
+	Job        string    `json:"job"`
+	Container  bool      `json:"container"` // run in a container rather than on the host
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	DurationMS int64     `json:"durationMs"`
+	ExitCode   int       `json:"exitCode"`        // -1 when the job could not be run or timed out
+	Error      string    `json:"error,omitempty"` // why the job could not be run
+	Log        string    `json:"log"`             // file holding what the job printed
+}
+
+// Succeeded reports whether the job ran and exited with status 0
+func (r InitJobResult) Succeeded() bool {
+
+	return r.Error == "" && r.ExitCode == 0
+}
+
+// InitRunner runs the init jobs of servers before they start
+type InitRunner struct {
+	Config   *config.ComposeConfig
+	Runtime  container.Runtime // runs the jobs of container servers
+	StateDir string            // where each job's log and the results of a server's jobs are written
+	Dir      string            // working directory of jobs run on the host
+	Out      io.Writer         // progress, and the end of a failed job's log; nil for none
+}
+
+// InitStateDir is where the init jobs of the servers of a compose file are recorded
+func InitStateDir(configFile string) string {
+
+	return filepath.Join(filepath.Dir(configFile), constants.InitJobsDir)
+}
+
+// Run runs a server's init jobs in order, stopping at the first that fails. Each job's
+// output and exit status are recorded in the state directory.
+func (r InitRunner) Run(serverName string) error {
+	srvCfg, exists := r.Config.Servers[serverName]
+	if !exists || len(srvCfg.Init) == 0 {
+
+		return nil
+	}
+	if err := os.MkdirAll(r.StateDir, constants.DefaultDirMode); err != nil {
+
+		return fmt.Errorf("failed to create init job directory: %w", err)
+	}
+
+	results := make([]InitJobResult, 0, len(srvCfg.Init))
+	for _, job := range srvCfg.Init {
+		r.printf("Running init job '%s' of server '%s'...\n", job.Name, serverName)
+		result := r.runJob(serverName, srvCfg, job)
+		results = append(results, result)
+		if err := r.saveResults(serverName, results); err != nil {
+			r.printf("Warning: %v\n", err)
+		}
+
+		if !result.Succeeded() {
+			reason := fmt.Sprintf("exited with status %d", result.ExitCode)
+			if result.Error != "" {
+				reason = "failed: " + result.Error
+			}
+			events.Publish(events.Event{
+				Type: events.InitJobFailed, Level: events.LevelError, Server: serverName,
+				Message: fmt.Sprintf("Init job '%s' of server '%s' %s", job.Name, serverName, reason),
+				Details: map[string]interface{}{"job": job.Name, "exitCode": result.ExitCode, "log": result.Log},
+			})
+			if tail := logTail(result.Log, constants.InitJobLogTail); tail != "" {
+				r.printf("--- last lines of %s ---\n%s\n", result.Log, tail)
+			}
+
+			return fmt.Errorf("init job '%s' %s; its output is in %s", job.Name, reason, result.Log)
+		}
+		events.Publish(events.Event{
+			Type: events.InitJobCompleted, Level: events.LevelInfo, Server: serverName,
+			Message: fmt.Sprintf("Init job '%s' of server '%s' completed in %s", job.Name, serverName, result.FinishedAt.Sub(result.StartedAt).Round(time.Millisecond)),
+			Details: map[string]interface{}{"job": job.Name, "durationMs": result.DurationMS},
+		})
+		r.printf("Init job '%s' of server '%s' completed (%s)\n", job.Name, serverName, result.FinishedAt.Sub(result.StartedAt).Round(time.Millisecond))
+	}
+
+	return nil
+}
+
+// runJob runs one job, in a container when it or its server has an image and on the host
+// otherwise, and writes what it printed to its log
+func (r InitRunner) runJob(serverName string, srvCfg config.ServerConfig, job config.InitJob) InitJobResult {
+	env := config.MergeEnv(r.Config.ServerEnv(serverName), job.Env)
+	env = config.MergeEnv(env, map[string]string{"MCP_SERVER_NAME": serverName, "MCP_INIT_JOB": job.Name})
+	image := job.Image
+	if image == "" {
+		image = srvCfg.Image
+	}
+
+	result := InitJobResult{
+		Server:    serverName,
+		Job:       job.Name,
+		Container: image != "",
+		StartedAt: time.Now(),
+		Log:       filepath.Join(r.StateDir, fmt.Sprintf("%s.%s.log", serverName, job.Name)),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), job.TimeoutDuration())
+	defer cancel()
+
+	var (
+		output []byte
+		err    error
+	)
+	if result.Container {
+		volumes := job.Volumes
+		if len(volumes) == 0 {
+			volumes = srvCfg.Volumes
+		}
+		volumes = r.Config.NamespaceMounts(volumes)
+		output, result.ExitCode, err = container.RunOnce(ctx, r.Runtime, &container.ContainerOptions{
+			Name:        config.ContainerName(serverName + "-init-" + job.Name),
+			Image:       image,
+			Command:     job.Command,
+			Args:        job.Args,
+			Env:         env,
+			Volumes:     volumes,
+			Networks:    r.Config.ServerNetworks(serverName),
+			NetworkMode: srvCfg.NetworkMode,
+			WorkDir:     job.WorkDir,
+			User:        srvCfg.User,
+			Platform:    srvCfg.Platform,
+			Pull:        srvCfg.Pull,
+			PullPolicy:  srvCfg.PullPolicy,
+		})
+	} else {
+		output, result.ExitCode, err = r.runHostJob(ctx, srvCfg, job, env)
+	}
+	result.FinishedAt = time.Now()
+	result.DurationMS = result.FinishedAt.Sub(result.StartedAt).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if writeErr := os.WriteFile(result.Log, output, constants.DefaultFileMode); writeErr != nil {
+		r.printf("Warning: failed to write the log of init job '%s': %v\n", job.Name, writeErr)
+	}
+
+	return result
+}
+
+// runHostJob runs a job of a process server on the host
+func (r InitRunner) runHostJob(ctx context.Context, srvCfg config.ServerConfig, job config.InitJob, env map[string]string) ([]byte, int, error) {
+	cmd := exec.CommandContext(ctx, job.Command, job.Args...)
+	cmd.Dir = r.Dir
+	for _, dir := range []string{srvCfg.WorkDir, job.WorkDir} {
+		if dir == "" {
+
+			continue
+		}
+		if filepath.IsAbs(dir) {
+			cmd.Dir = dir
+		} else {
+			cmd.Dir = filepath.Join(cmd.Dir, dir)
+		}
+	}
+	cmd.Env = os.Environ()
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+
+		return output, -1, fmt.Errorf("did not finish in %s", job.TimeoutDuration())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+
+		return output, exitErr.ExitCode(), nil
+	}
+	if err != nil {
+
+		return output, -1, fmt.Errorf("failed to run '%s': %w", job.Command, err)
+	}
+
+	return output, 0, nil
+}
+
+// saveResults records the results of a server's jobs so far, replacing those of its last run
+func (r InitRunner) saveResults(serverName string, results []InitJobResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+
+		return fmt.Errorf("failed to encode init job results: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.StateDir, serverName+".json"), data, constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to record init job results: %w", err)
+	}
+
+	return nil
+}
+
+func (r InitRunner) printf(format string, args ...interface{}) {
+	if r.Out != nil {
+		_, _ = fmt.Fprintf(r.Out, format, args...)
+	}
+}
+
+// LoadInitResults returns the recorded results of the last run of a server's init jobs, or
+// none if they never ran
+func LoadInitResults(stateDir, serverName string) ([]InitJobResult, error) {
+	data, err := os.ReadFile(filepath.Join(stateDir, serverName+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+
+		return nil, nil
+	}
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read init job results of server '%s': %w", serverName, err)
+	}
+	var results []InitJobResult
+	if err := json.Unmarshal(data, &results); err != nil {
+
+		return nil, fmt.Errorf("failed to parse init job results of server '%s': %w", serverName, err)
+	}
+
+	return results, nil
+}
+
+// logTail returns the last lines of a log file, or "" if it is empty or missing
+func logTail(path string, lines int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+
+		return ""
+	}
+	all := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+
+	return strings.TrimSpace(strings.Join(all, "\n"))
+}
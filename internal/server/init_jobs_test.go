@@ -0,0 +1,59 @@
+package server
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/container"
+)
+
+func TestInitRunner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("init jobs on the host need sh for this test")
+	}
+
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"memory": {Command: "memory-server", Env: map[string]string{"DB": "memory.db"}, Init: []config.InitJob{
+			{Name: "migrate", Command: "sh", Args: []string{"-c", `echo "migrating $DB for $MCP_SERVER_NAME"`}},
+			{Name: "seed", Command: "sh", Args: []string{"-c", "echo seeding; echo no vectors >&2; exit 3"}},
+			{Name: "never", Command: "sh", Args: []string{"-c", "echo unreachable"}},
+		}},
+	}}
+	stateDir := filepath.Join(t.TempDir(), "init")
+	runner := InitRunner{Config: cfg, Runtime: &container.NullRuntime{}, StateDir: stateDir, Dir: t.TempDir()}
+
+	err := runner.Run("memory")
+	if err == nil || !strings.Contains(err.Error(), "init job 'seed' exited with status 3") {
+		t.Fatalf("expected the seed job to fail with status 3, got %v", err)
+	}
+
+	results, err := LoadInitResults(stateDir, "memory")
+	if err != nil {
+		t.Fatalf("LoadInitResults: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("jobs after a failed one should not run, got %+v", results)
+	}
+	if migrate := results[0]; !migrate.Succeeded() || migrate.Container || migrate.FinishedAt.Before(migrate.StartedAt) {
+		t.Errorf("unexpected result of the migrate job: %+v", migrate)
+	}
+	if seed := results[1]; seed.Succeeded() || seed.ExitCode != 3 {
+		t.Errorf("unexpected result of the seed job: %+v", seed)
+	}
+
+	log, err := os.ReadFile(results[0].Log)
+	if err != nil || strings.TrimSpace(string(log)) != "migrating memory.db for memory" {
+		t.Errorf("the migrate job's log should hold its output with the server's environment, got %q, %v", log, err)
+	}
+	if log, _ := os.ReadFile(results[1].Log); !strings.Contains(string(log), "no vectors") {
+		t.Errorf("the seed job's log should hold its stderr, got %q", log)
+	}
+
+	if results, err := LoadInitResults(stateDir, "search"); err != nil || results != nil {
+		t.Errorf("a server whose jobs never ran should have no results, got %v, %v", results, err)
+	}
+}
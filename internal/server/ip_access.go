@@ -0,0 +1,98 @@
+// internal/server/ip_access.go
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/ipaccess"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+// isOAuthEndpointPath reports whether path is one of the endpoints oauth.access guards: the
+// authorization server's own and its discovery documents
+func isOAuthEndpointPath(path string) bool {
+
+	return strings.HasPrefix(path, "/oauth/") || strings.HasPrefix(path, "/.well-known/oauth-")
+}
+
+// allowedAddress checks the client behind r against the proxy's access list, the OAuth
+// endpoints' for those, and refuses it when either keeps it out
+func (h *ProxyHandler) allowedAddress(w http.ResponseWriter, r *http.Request, path string) bool {
+	if !h.proxyAccess.Allows(r) {
+		h.refuseAddress(w, r, "", "proxy_access")
+
+		return false
+	}
+	if isOAuthEndpointPath(path) && !h.oauthAccess.Allows(r) {
+		h.refuseAddress(w, r, "", "oauth.access")
+
+		return false
+	}
+
+	return true
+}
+
+// serverAccessList is a server's compiled access list; err keeps a server whose list is
+// invalid closed to everyone
+type serverAccessList struct {
+	list *ipaccess.List
+	err  error
+}
+
+// buildServerAccess compiles the access lists of the servers of a config. A server's section
+// without trusted_proxies looks behind those of proxy_access, which the requests to it come
+// through as well.
+func buildServerAccess(cfg *config.ComposeConfig, logger *logging.Logger) map[string]serverAccessList {
+	lists := make(map[string]serverAccessList)
+	for serverName, serverConfig := range cfg.Servers {
+		if serverConfig.Access == nil {
+
+			continue
+		}
+		access := *serverConfig.Access
+		if len(access.TrustedProxies) == 0 && cfg.ProxyAccess != nil {
+			access.TrustedProxies = cfg.ProxyAccess.TrustedProxies
+		}
+		list, err := ipaccess.New(&access)
+		if err != nil {
+			logger.Error("Invalid access list of server %s, refusing every address: %v", serverName, err)
+		}
+		lists[serverName] = serverAccessList{list: list, err: err}
+	}
+
+	return lists
+}
+
+// setServerAccess replaces the compiled server access lists with those of cfg
+func (h *ProxyHandler) setServerAccess(cfg *config.ComposeConfig) {
+	lists := buildServerAccess(cfg, h.logger)
+	h.serverAccessMu.Lock()
+	h.serverAccess = lists
+	h.serverAccessMu.Unlock()
+}
+
+// allowedServerAddress checks the client behind r against a server's access list
+func (h *ProxyHandler) allowedServerAddress(w http.ResponseWriter, r *http.Request, serverName string) bool {
+	h.serverAccessMu.RLock()
+	access, exists := h.serverAccess[serverName]
+	h.serverAccessMu.RUnlock()
+	if !exists {
+
+		return true
+	}
+	if access.err != nil || !access.list.Allows(r) {
+		h.refuseAddress(w, r, serverName, "servers."+serverName+".access")
+
+		return false
+	}
+
+	return true
+}
+
+func (h *ProxyHandler) refuseAddress(w http.ResponseWriter, r *http.Request, serverName, setting string) {
+	h.logger.Warning("Refused %s %s from %s, not allowed by %s", r.Method, r.URL.Path, r.RemoteAddr, setting)
+	publishAuthDenied(r, serverName, "address not allowed by "+setting)
+	h.corsError(w, "Forbidden", http.StatusForbidden)
+}
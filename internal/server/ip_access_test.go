@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/ipaccess"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func TestAddressAccess(t *testing.T) {
+	proxyAccess, _ := ipaccess.New(&config.IPAccessConfig{Allow: []string{"192.168.1.0/24"}})
+	oauthAccess, _ := ipaccess.New(&config.IPAccessConfig{Deny: []string{"192.168.1.50"}})
+	logger := logging.NewLogger("error")
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"files": {Access: &config.IPAccessConfig{Deny: []string{"192.168.1.0/28"}}},
+	}}
+	h := &ProxyHandler{
+		Manager:     newTestManager(cfg, logger, map[string]*ServerInstance{}),
+		logger:      logger,
+		proxyAccess: proxyAccess,
+		oauthAccess: oauthAccess,
+	}
+
+	serve := func(remoteAddr, path string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+	if code := serve("203.0.113.9:5000", "/api/servers"); code != http.StatusForbidden {
+		t.Errorf("Expected an address off the allow list to be refused, got %d", code)
+	}
+	if code := serve("192.168.1.50:5000", "/oauth/token"); code != http.StatusForbidden {
+		t.Errorf("Expected oauth.access to refuse the address, got %d", code)
+	}
+	if code := serve("192.168.1.50:5000", "/.well-known/jwks.json"); code == http.StatusForbidden {
+		t.Errorf("Expected oauth.access to leave other endpoints alone, got %d", code)
+	}
+
+	// A server's list applies on top of the proxy's
+	h.setServerAccess(cfg)
+	reach := func(remoteAddr, forwardedFor string) (bool, int) {
+		req := httptest.NewRequest(http.MethodPost, "/files", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		recorder := httptest.NewRecorder()
+		allowed := h.allowedServerAddress(recorder, req, "files")
+
+		return allowed, recorder.Code
+	}
+	for remoteAddr, allowed := range map[string]bool{"192.168.1.5:5000": false, "192.168.1.20:5000": true} {
+		got, code := reach(remoteAddr, "")
+		if got != allowed {
+			t.Errorf("Expected %s allowed to reach the server to be %v", remoteAddr, allowed)
+		}
+		if !allowed && code != http.StatusForbidden {
+			t.Errorf("Expected a refused address to get 403, got %d", code)
+		}
+	}
+
+	// Behind the proxy's trusted reverse proxy, the server's list checks the client
+	cfg.ProxyAccess = &config.IPAccessConfig{TrustedProxies: []string{"10.0.0.1"}}
+	h.setServerAccess(cfg)
+	if allowed, _ := reach("10.0.0.1:5000", "192.168.1.5"); allowed {
+		t.Error("Expected the client behind the trusted proxy to be refused")
+	}
+	if allowed, _ := reach("10.0.0.1:5000", "192.168.1.20"); !allowed {
+		t.Error("Expected the client behind the trusted proxy to be let in")
+	}
+
+	cfg.Servers["files"] = config.ServerConfig{Access: &config.IPAccessConfig{Allow: []string{"not an address"}}}
+	h.setServerAccess(cfg)
+	if allowed, _ := reach("192.168.1.20:5000", ""); allowed {
+		t.Error("Expected a server with an invalid list to refuse every address")
+	}
+}
@@ -0,0 +1,106 @@
+// internal/server/lifecycle_api.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// handleServerLifecycle starts, stops or restarts a server through the manager, which
+// holds its full configuration and so can recreate a container that was removed.
+// The proxy's connections to the server are dropped so the next request reconnects.
+func (h *ProxyHandler) handleServerLifecycle(w http.ResponseWriter, r *http.Request, serverName, action string) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Method not allowed - use POST"})
+
+		return
+	}
+	if _, exists := h.Manager.GetServerInstance(serverName); !exists {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("server '%s' not found in configuration", serverName)})
+
+		return
+	}
+
+	h.logger.Info("Received %s request for server '%s' from %s", action, serverName, r.RemoteAddr)
+
+	if err := h.runServerAction(serverName, action); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("failed to %s server '%s': %v", action, serverName, err)})
+
+		return
+	}
+
+	status, _ := h.Manager.GetServerStatus(serverName)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"server":       serverName,
+		"action":       action,
+		"serverStatus": status,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}); err != nil {
+		h.logger.Error("Failed to encode %s response: %v", action, err)
+	}
+}
+
+// runServerAction starts, stops or restarts a server and drops the proxy's connections to it
+func (h *ProxyHandler) runServerAction(serverName, action string) error {
+	var err error
+	switch action {
+	case "start":
+		err = h.Manager.StartServer(serverName)
+	case "stop":
+		err = h.Manager.StopServer(serverName)
+	case "restart":
+		if err = h.Manager.StopServer(serverName); err == nil {
+			err = h.Manager.StartServer(serverName)
+		}
+	default:
+
+		return fmt.Errorf("unknown server action '%s'", action)
+	}
+	h.dropServerConnections(serverName)
+	if err != nil {
+		h.logger.Error("Failed to %s server '%s': %v", action, serverName, err)
+	}
+
+	return err
+}
+
+// dropServerConnections closes the proxy's HTTP, SSE and STDIO connections to one server
+func (h *ProxyHandler) dropServerConnections(serverName string) {
+	h.ConnectionMutex.Lock()
+	delete(h.ServerConnections, serverName)
+	h.ConnectionMutex.Unlock()
+
+	h.SSEMutex.Lock()
+	if conn, exists := h.SSEConnections[serverName]; exists && conn != nil {
+		h.closeSSEConnection(conn)
+	}
+	delete(h.SSEConnections, serverName)
+	h.SSEMutex.Unlock()
+
+	h.StdioMutex.Lock()
+	if conn, exists := h.StdioConnections[serverName]; exists && conn != nil && conn.Connection != nil {
+		if err := conn.Connection.Close(); err != nil {
+			h.logger.Debug("Failed to close STDIO connection to server %s: %v", serverName, err)
+		}
+	}
+	delete(h.StdioConnections, serverName)
+	h.StdioMutex.Unlock()
+
+	h.stdioMuxesMu.Lock()
+	mux := h.stdioMuxes[serverName]
+	delete(h.stdioMuxes, serverName)
+	h.stdioMuxesMu.Unlock()
+	if mux != nil {
+		mux.shutdown(fmt.Errorf("session closed by proxy"))
+	}
+
+	h.catalog.invalidate(serverName)
+	h.responses.invalidate(serverName)
+}
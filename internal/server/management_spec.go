@@ -0,0 +1,237 @@
+// internal/server/management_spec.go
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const managementSpecPath = "/api/openapi.json"
+
+// managementEndpoint describes one of the proxy's management endpoints for its OpenAPI document
+type managementEndpoint struct {
+	method   string
+	path     string // {name} and similar segments are path parameters
+	summary  string
+	tag      string
+	public   bool // needs no credentials
+	query    []adminQueryParam
+	request  string // schema of the JSON request body, if any
+	response string // schema of a successful response; empty for a free-form object
+	stream   bool   // answers with server-sent events
+}
+
+// managementEndpoints are the endpoints outside the versioned admin API, which describes
+// itself. Keep this list in step with handleAPIEndpoints and handleOAuthEndpoints.
+var managementEndpoints = []managementEndpoint{
+	{method: http.MethodGet, path: "/api/status", summary: "Proxy status and request queues", tag: "proxy"},
+	{method: http.MethodGet, path: "/api/servers", summary: "Servers with their proxy connection status", tag: "servers"},
+	{method: http.MethodPost, path: "/api/reload", summary: "Drop the proxy's connections and caches", tag: "proxy"},
+	{method: http.MethodGet, path: "/api/discovery", summary: "MCP discovery document", tag: "proxy"},
+	{method: http.MethodGet, path: "/api/connections", summary: "Open HTTP connections to servers", tag: "proxy"},
+	{method: http.MethodGet, path: "/api/subscriptions", summary: "Resource subscriptions of the calling client", tag: "notifications"},
+	{method: http.MethodDelete, path: "/api/subscriptions", summary: "Remove the calling client's subscriptions", tag: "notifications"},
+	{method: http.MethodGet, path: "/api/notifications", summary: "Clients subscribed to list change notifications", tag: "notifications"},
+	{method: http.MethodGet, path: "/api/events", summary: "Stream proxy events", tag: "proxy", stream: true,
+		query: []adminQueryParam{
+			{name: "type", kind: "string", description: "Event types to include, comma-separated or repeated"},
+			{name: "server", kind: "string", description: "Servers to include, comma-separated or repeated"},
+			{name: "history", kind: "boolean", description: "Send recorded events first, default true"},
+			{name: "follow", kind: "boolean", description: "Keep streaming new events, default true"},
+			{name: "lastEventId", kind: "string", description: "Resume after this event, as the Last-Event-ID header does"},
+		}},
+	{method: http.MethodGet, path: "/api/catalog", summary: "Tools, resources and prompts of every server", tag: "catalog",
+		query: []adminQueryParam{{name: "refresh", kind: "boolean", description: "Fetch the lists again instead of using the cache"}}},
+	{method: http.MethodGet, path: "/openapi.json", summary: "OpenAPI description of the tools of every server", tag: "catalog"},
+	{method: http.MethodGet, path: "/api/config", summary: "The compose file, for the config editor", tag: "config"},
+	{method: http.MethodPost, path: "/api/config/validate", summary: "Check edited compose file contents and preview their changes", tag: "config", request: "ConfigEdit"},
+	{method: http.MethodPost, path: "/api/config/apply", summary: "Write edited compose file contents and restart the servers they change", tag: "config", request: "ConfigEdit"},
+	{method: http.MethodGet, path: "/api/config/history", summary: "Recorded versions of the compose file, newest first", tag: "config"},
+	{method: http.MethodPost, path: "/api/config/rollback", summary: "Restore a recorded version of the compose file and restart the servers it changes", tag: "config", request: "ConfigRollback"},
+	{method: http.MethodGet, path: "/api/containers/{name}/logs", summary: "Logs of a container", tag: "servers",
+		query: []adminQueryParam{
+			{name: "tail", kind: "integer", description: "Number of lines, default 100"},
+			{name: "follow", kind: "boolean", description: "Stream new lines as server-sent events"},
+			{name: "timestamps", kind: "boolean", description: "Prefix lines with their time"},
+			{name: "since", kind: "string", description: "Only lines after this time or duration"},
+			{name: "raw", kind: "boolean", description: "Skip the server's log_filters"},
+		}},
+	{method: http.MethodGet, path: "/api/containers/{name}/stats", summary: "Resource usage of a container", tag: "servers"},
+	{method: http.MethodGet, path: "/api/servers/{name}/detail", summary: "Configuration, runtime state, health and capabilities of a server", tag: "servers"},
+	{method: http.MethodPost, path: "/api/servers/{name}/start", summary: "Start a server", tag: "servers"},
+	{method: http.MethodPost, path: "/api/servers/{name}/stop", summary: "Stop a server", tag: "servers"},
+	{method: http.MethodPost, path: "/api/servers/{name}/restart", summary: "Restart a server", tag: "servers"},
+	{method: http.MethodGet, path: "/api/servers/{name}/oauth", summary: "OAuth settings of a server", tag: "oauth"},
+	{method: http.MethodPut, path: "/api/servers/{name}/oauth", summary: "Change the OAuth settings of a server", tag: "oauth", request: "ServerOAuth"},
+	{method: http.MethodPost, path: "/api/servers/{name}/test-oauth", summary: "Check a server's OAuth settings", tag: "oauth"},
+	{method: http.MethodGet, path: "/api/servers/{name}/tokens", summary: "Access tokens that can reach a server", tag: "oauth"},
+	{method: http.MethodGet, path: "/api/oauth/status", summary: "OAuth server status", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/api/oauth/clients", summary: "Registered OAuth clients", tag: "oauth", public: true},
+	{method: http.MethodDelete, path: "/api/oauth/clients/{id}", summary: "Remove an OAuth client", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/api/oauth/scopes", summary: "Scopes the OAuth server grants", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/api/oauth/device", summary: "Device sign-ins waiting to be approved", tag: "oauth"},
+	{method: http.MethodPost, path: "/api/oauth/device/{code}/approve", summary: "Approve a device sign-in by its user code", tag: "oauth", request: "DeviceReview"},
+	{method: http.MethodPost, path: "/api/oauth/device/{code}/deny", summary: "Deny a device sign-in by its user code", tag: "oauth", request: "DeviceReview"},
+	{method: http.MethodGet, path: "/api/oauth/consents", summary: "Scopes users have granted OAuth clients", tag: "oauth",
+		query: []adminQueryParam{{name: "user", kind: "string", description: "Only the consents of this user"}}},
+	{method: http.MethodDelete, path: "/api/oauth/consents/{user}/{client_id}", summary: "Revoke a consent and the tokens issued under it", tag: "oauth"},
+	{method: http.MethodGet, path: "/.well-known/oauth-authorization-server", summary: "OAuth authorization server metadata", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/.well-known/oauth-protected-resource", summary: "OAuth protected resource metadata", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/.well-known/jwks.json", summary: "Keys signed access tokens and the identity tokens sent to servers with forward_identity are verified with", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/oauth/authorize", summary: "Authorization endpoint", tag: "oauth", public: true},
+	{method: http.MethodPost, path: "/oauth/token", summary: "Token endpoint", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/oauth/userinfo", summary: "Identity of an access token's user", tag: "oauth", public: true},
+	{method: http.MethodPost, path: "/oauth/revoke", summary: "Revoke a token", tag: "oauth", public: true},
+	{method: http.MethodPost, path: "/oauth/register", summary: "Register a client dynamically", tag: "oauth", public: true},
+	{method: http.MethodPost, path: "/oauth/device_authorization", summary: "Device authorization endpoint: start a device sign-in", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/oauth/device", summary: "Page users enter a device sign-in's code at", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/oauth/callback", summary: "Redirect target of upstream OAuth providers", tag: "oauth", public: true},
+	{method: http.MethodGet, path: "/api/tasks", summary: "List scheduled tasks", tag: "tasks"},
+	{method: http.MethodPost, path: "/api/tasks", summary: "Create a scheduled task", tag: "tasks", request: "Object"},
+	{method: http.MethodGet, path: "/api/tasks/{id}", summary: "Show a scheduled task", tag: "tasks"},
+	{method: http.MethodPut, path: "/api/tasks/{id}", summary: "Update a scheduled task", tag: "tasks", request: "Object"},
+	{method: http.MethodDelete, path: "/api/tasks/{id}", summary: "Remove a scheduled task", tag: "tasks"},
+	{method: http.MethodPost, path: "/api/tasks/{id}/run", summary: "Run a task now", tag: "tasks"},
+	{method: http.MethodGet, path: "/api/tasks/{id}/runs", summary: "Runs of a task with their output", tag: "tasks"},
+	{method: http.MethodGet, path: "/api/tasks/runs", summary: "Latest run of every task", tag: "tasks"},
+	{method: http.MethodGet, path: "/api/tasks/metrics", summary: "Scheduler metrics", tag: "tasks"},
+	{method: http.MethodGet, path: "/api/analytics/clients", summary: "Requests, errors, estimated tokens and top tools per client", tag: "analytics",
+		query: []adminQueryParam{{name: "server", kind: "string", description: "Only usage of this server"}}},
+	{method: http.MethodGet, path: "/api/costs", summary: "Cost of calls to paid servers per day and client", tag: "analytics",
+		query: []adminQueryParam{
+			{name: "from", kind: "string", description: "First date, as YYYY-MM-DD; default 29 days ago"},
+			{name: "to", kind: "string", description: "Last date, as YYYY-MM-DD; default today"},
+			{name: "client", kind: "string", description: "Only this client, by the id /api/analytics/clients reports"},
+			{name: "server", kind: "string", description: "Only calls to this server"},
+		}},
+	{method: http.MethodGet, path: "/api/quotas", summary: "Each client's monthly quota and what it used of it", tag: "analytics"},
+	{method: http.MethodPost, path: "/api/quotas/{client}/reset", summary: "Clear the month's quota usage of a client", tag: "analytics"},
+	{method: http.MethodPost, path: "/api/quotas/reset", summary: "Clear the month's quota usage of every client", tag: "analytics"},
+	{method: http.MethodGet, path: "/api/sampling", summary: "Sampling requests from servers, newest first", tag: "sampling",
+		query: []adminQueryParam{{name: "status", kind: "string", description: "Only requests with this status, such as awaiting_approval"}}},
+	{method: http.MethodGet, path: "/api/sampling/{id}", summary: "Show a sampling request", tag: "sampling"},
+	{method: http.MethodPost, path: "/api/sampling/{id}/approve", summary: "Approve a sampling request, optionally editing it", tag: "sampling", request: "SamplingReview"},
+	{method: http.MethodPost, path: "/api/sampling/{id}/deny", summary: "Deny a sampling request", tag: "sampling", request: "SamplingReview"},
+}
+
+// handleManagementSpec serves the OpenAPI description of the proxy's management API
+func (h *ProxyHandler) handleManagementSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use GET")
+
+		return
+	}
+	_ = json.NewEncoder(w).Encode(managementOpenAPISpec())
+}
+
+// managementOpenAPISpec describes every management endpoint: the admin API as its own
+// document does, and the other endpoints from managementEndpoints
+func managementOpenAPISpec() map[string]interface{} {
+	spec := adminOpenAPISpec()
+	spec["info"] = map[string]interface{}{
+		"title":       "mcp-compose proxy management API",
+		"description": "Endpoints for managing an mcp-compose proxy and its servers. The versioned admin API under " + adminAPIPath + " is the stable surface; the other endpoints serve the dashboard and may change between releases.",
+		"version":     adminAPIVersion,
+	}
+	paths := spec["paths"].(map[string]interface{})
+	components := spec["components"].(map[string]interface{})
+	schemas := components["schemas"].(map[string]interface{})
+	schemas["Object"] = map[string]interface{}{"type": "object", "additionalProperties": true}
+	schemas["ConfigEdit"] = objectSchema(map[string]interface{}{
+		"content":  map[string]interface{}{"type": "string", "description": "Proposed compose file contents"},
+		"baseHash": map[string]interface{}{"type": "string", "description": "Hash of the file the edit started from, to catch concurrent edits"},
+	}, "content")
+	schemas["ConfigRollback"] = objectSchema(map[string]interface{}{
+		"version": map[string]interface{}{"type": "integer", "description": "Version from /api/config/history"},
+	}, "version")
+	schemas["ServerOAuth"] = objectSchema(map[string]interface{}{
+		"enabled":        map[string]interface{}{"type": "boolean"},
+		"requiredScope":  map[string]interface{}{"type": "string"},
+		"optionalAuth":   map[string]interface{}{"type": "boolean"},
+		"allowedClients": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+	})
+	schemas["SamplingReview"] = objectSchema(map[string]interface{}{
+		"reviewer":     map[string]interface{}{"type": "string", "description": "Defaults to the caller's address"},
+		"comments":     map[string]interface{}{"type": "string", "description": "Note on an approval, or the reason for a denial, which the server sees"},
+		"messages":     map[string]interface{}{"type": "array", "items": schemaRef("Object"), "description": "Replacement messages, on approval"},
+		"systemPrompt": map[string]interface{}{"type": "string", "description": "Replacement system prompt, on approval"},
+		"maxTokens":    map[string]interface{}{"type": "integer", "description": "Replacement token limit, on approval"},
+	})
+	schemas["DeviceReview"] = objectSchema(map[string]interface{}{
+		"user": map[string]interface{}{"type": "string", "description": "User the device's tokens act for; defaults to the caller's"},
+	})
+	schemas["Error"] = map[string]interface{}{
+		"type":        "object",
+		"description": "Older endpoints may answer errors with plain text instead",
+		"properties":  map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+	}
+
+	for _, endpoint := range managementEndpoints {
+		response := endpoint.response
+		if response == "" {
+			response = "Object"
+		}
+		content := map[string]interface{}{"application/json": map[string]interface{}{"schema": schemaRef(response)}}
+		if endpoint.stream {
+			content = map[string]interface{}{"text/event-stream": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}
+		}
+		operation := map[string]interface{}{
+			"summary":     endpoint.summary,
+			"operationId": managementOperationID(endpoint.method, endpoint.path),
+			"tags":        []string{endpoint.tag},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{"description": endpoint.summary, "content": content},
+			},
+		}
+		if endpoint.public {
+			operation["security"] = []map[string][]string{}
+		} else {
+			operation["security"] = []map[string][]string{{"bearer": {}}}
+		}
+
+		var parameters []map[string]interface{}
+		for _, segment := range strings.Split(endpoint.path, "/") {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				parameters = append(parameters, map[string]interface{}{
+					"name": strings.Trim(segment, "{}"), "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+				})
+			}
+		}
+		for _, param := range endpoint.query {
+			parameters = append(parameters, map[string]interface{}{
+				"name": param.name, "in": "query", "schema": map[string]interface{}{"type": param.kind}, "description": param.description,
+			})
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+		if endpoint.request != "" {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": schemaRef(endpoint.request)}},
+			}
+		}
+
+		item, _ := paths[endpoint.path].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[endpoint.path] = item
+		}
+		item[strings.ToLower(endpoint.method)] = operation
+	}
+
+	return spec
+}
+
+// managementOperationID names an operation after its method and path, as in getApiServersNameDetail
+func managementOperationID(method, path string) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '.' || r == '-' || r == '{' || r == '}' }) {
+		id.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	return id.String()
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestManagementSpecCoversRoutes fails when a route is added to the proxy's routers without
+// being described in the management API's OpenAPI document
+func TestManagementSpecCoversRoutes(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "http_router.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parse http_router.go: %v", err)
+	}
+	paths := managementOpenAPISpec()["paths"].(map[string]interface{})
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || (fn.Name.Name != "handleAPIEndpoints" && fn.Name.Name != "handleOAuthEndpoints") {
+
+			continue
+		}
+		ast.Inspect(fn.Body, func(node ast.Node) bool {
+			clause, ok := node.(*ast.CaseClause)
+			if !ok {
+
+				return true
+			}
+			for _, expr := range clause.List {
+				literal, ok := expr.(*ast.BasicLit)
+				if !ok || literal.Kind != token.STRING {
+
+					continue
+				}
+				route, _ := strconv.Unquote(literal.Value)
+				if strings.HasPrefix(route, "/") && paths[route] == nil {
+					t.Errorf("%s routes %s, which the management spec does not describe", fn.Name.Name, route)
+				}
+			}
+
+			return true
+		})
+	}
+}
+
+func TestManagementOpenAPISpec(t *testing.T) {
+	spec := managementOpenAPISpec()
+	paths := spec["paths"].(map[string]interface{})
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	operationIDs := make(map[string]bool)
+	for path, item := range paths {
+		for method, operation := range item.(map[string]interface{}) {
+			op := operation.(map[string]interface{})
+			id := op["operationId"].(string)
+			if operationIDs[id] {
+				t.Errorf("duplicate operationId %s (%s %s)", id, method, path)
+			}
+			operationIDs[id] = true
+
+			for _, param := range pathParams(path) {
+				found := false
+				params, _ := op["parameters"].([]map[string]interface{})
+				for _, p := range params {
+					found = found || (p["name"] == param && p["in"] == "path")
+				}
+				if !found {
+					t.Errorf("%s %s does not declare path parameter %s", method, path, param)
+				}
+			}
+		}
+	}
+	for _, name := range []string{"Object", "ConfigEdit", "ServerOAuth", "Server"} {
+		if schemas[name] == nil {
+			t.Errorf("schema %s missing", name)
+		}
+	}
+	if paths["/api/v1/servers/{name}"] == nil || paths["/api/servers/{name}/detail"] == nil {
+		t.Error("expected both admin and dashboard endpoints in the spec")
+	}
+}
+
+func pathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") {
+			params = append(params, strings.Trim(segment, "{}"))
+		}
+	}
+
+	return params
+}
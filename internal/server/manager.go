@@ -3,52 +3,51 @@ package server
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"io/fs" // Keep for filepath.Walk, os.Stat etc.
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/events"
+	"github.com/phildougherty/mcp-compose/internal/imagescan"
 	"github.com/phildougherty/mcp-compose/internal/logging"
 	"github.com/phildougherty/mcp-compose/internal/protocol"
 	"github.com/phildougherty/mcp-compose/internal/runtime"
-
-	"github.com/fsnotify/fsnotify" // Keep if ResourcesWatcher uses it
+	"github.com/phildougherty/mcp-compose/internal/telemetry"
 )
 
 // ServerInstance represents a running server instance
 type ServerInstance struct {
-	Name             string
-	Config           config.ServerConfig
-	ContainerID      string
-	Process          *runtime.Process
-	IsContainer      bool
-	Status           string
-	StartTime        time.Time
-	Capabilities     map[string]bool
-	ConnectionInfo   map[string]string
-	HealthStatus     string
-	ResourcesWatcher *ResourcesWatcher
-	ProgressManager  *protocol.ProgressManager
-	ResourceManager  *protocol.ResourceManager
-	SamplingManager  *protocol.SamplingManager
-	mu               sync.RWMutex
-	ctx              context.Context
-	cancel           context.CancelFunc
+	Name            string
+	Config          config.ServerConfig
+	ContainerID     string
+	Process         *runtime.Process
+	IsContainer     bool
+	Status          string
+	StartTime       time.Time
+	Capabilities    map[string]bool
+	ConnectionInfo  map[string]string
+	HealthStatus    string
+	HealthError     string    // why the last health check failed
+	LastHealthCheck time.Time // when the last health check ran
+	HealthHistory   []HealthCheckRecord
+	ProgressManager *protocol.ProgressManager
+	ResourceManager *protocol.ResourceManager
+	SamplingManager *protocol.SamplingManager
+	mu              sync.RWMutex
+	ctx             context.Context
+	cancel          context.CancelFunc
 }
 
 // Manager handles server lifecycle operations
 type Manager struct {
-	config           *config.ComposeConfig
+	config           atomic.Pointer[config.ComposeConfig] // swapped by ApplyConfig while requests read it
 	containerRuntime container.Runtime
 	projectDir       string // For running lifecycle hooks and resolving relative paths
 	servers          map[string]*ServerInstance
@@ -61,6 +60,9 @@ type Manager struct {
 	shutdownCh       chan struct{}
 	healthCheckers   map[string]context.CancelFunc
 	healthCheckMu    sync.Mutex
+	crashLoops       map[string]*crashLoopState
+	crashLoopMu      sync.Mutex
+	tracer           *telemetry.Tracer
 }
 
 func NewManager(cfg *config.ComposeConfig, rt container.Runtime) (*Manager, error) {
@@ -84,117 +86,14 @@ func NewManager(cfg *config.ComposeConfig, rt container.Runtime) (*Manager, erro
 	// Create a temporary manager with logger for validation
 	tempManager := &Manager{logger: logger}
 
-	// Add task-scheduler as a built-in service if enabled
-	if cfg.TaskScheduler != nil && cfg.TaskScheduler.Enabled {
-		logger.Info("Task scheduler enabled in config, adding as built-in server")
-
-		// Create task-scheduler server config
-		taskSchedulerConfig := config.ServerConfig{
-			// CRITICAL: Add image so validation passes
-			Image:        "mcp-compose-task-scheduler:latest",
-			Protocol:     "sse",
-			HttpPort:     cfg.TaskScheduler.Port,
-			SSEPath:      "/sse",
-			User:         "root",
-			ReadOnly:     false,
-			Privileged:   false,
-			Capabilities: []string{"tools", "resources"},
-			Env: map[string]string{
-				"TZ":                                 "America/New_York",
-				"MCP_CRON_SERVER_TRANSPORT":          "sse",
-				"MCP_CRON_SERVER_ADDRESS":            "0.0.0.0",
-				"MCP_CRON_SERVER_PORT":               fmt.Sprintf("%d", cfg.TaskScheduler.Port),
-				"MCP_CRON_DATABASE_PATH":             cfg.TaskScheduler.DatabasePath,
-				"MCP_CRON_DATABASE_ENABLED":          "true",
-				"MCP_CRON_LOGGING_LEVEL":             cfg.TaskScheduler.LogLevel,
-				"MCP_CRON_SCHEDULER_DEFAULT_TIMEOUT": "10m",
-				"MCP_CRON_OLLAMA_ENABLED":            "true",
-				"MCP_CRON_OLLAMA_BASE_URL":           cfg.TaskScheduler.OllamaURL,
-				"MCP_CRON_OLLAMA_DEFAULT_MODEL":      cfg.TaskScheduler.OllamaModel,
-				"USE_OPENROUTER":                     "true",
-				"OPENROUTER_ENABLED":                 "true",
-				"OPENROUTER_API_KEY":                 cfg.TaskScheduler.OpenRouterAPIKey,
-				"OPENROUTER_MODEL":                   cfg.TaskScheduler.OpenRouterModel,
-				"MCP_PROXY_URL":                      cfg.TaskScheduler.MCPProxyURL,
-				"MCP_PROXY_API_KEY":                  cfg.TaskScheduler.MCPProxyAPIKey,
-				"MCP_MEMORY_SERVER_URL":              "http://mcp-compose-memory:3001",
-				"MCP_FILESYSTEM_URL":                 "http://mcp-compose-filesystem:3000",
-				"MCP_OPENROUTER_GATEWAY_URL":         "http://mcp-compose-openrouter-gateway:8012",
-			},
-			Networks: []string{"mcp-net"},
-			Authentication: &config.ServerAuthConfig{
-				Enabled:       true,
-				RequiredScope: "mcp:tools",
-				OptionalAuth:  false,
-				AllowAPIKey:   &[]bool{true}[0],
-			},
-			// Add volumes if specified in task scheduler config
-			Volumes: cfg.TaskScheduler.Volumes,
-		}
-
-		// Merge any additional env vars from task scheduler config
-		if cfg.TaskScheduler.Env != nil {
-			for k, v := range cfg.TaskScheduler.Env {
-				taskSchedulerConfig.Env[k] = v
-			}
-		}
-
-		// Add to servers map
-		if cfg.Servers == nil {
-			cfg.Servers = make(map[string]config.ServerConfig)
-		}
-		cfg.Servers["task-scheduler"] = taskSchedulerConfig
-
-		logger.Info("Added task-scheduler as built-in server on port %d", cfg.TaskScheduler.Port)
-	}
-
-	if cfg.Memory.Enabled {
-		logger.Info("Memory server enabled in config, adding as built-in server")
-
-		// Create memory server config
-		memoryConfig := config.ServerConfig{
-			// Use the built image name that will be created by the memory manager
-			Image:        "mcp-compose-memory:latest",
-			Protocol:     "http",
-			HttpPort:     cfg.Memory.Port,
-			User:         "root",
-			ReadOnly:     false,
-			Privileged:   false,
-			Capabilities: []string{"tools", "resources"},
-			Env: map[string]string{
-				"NODE_ENV":     "production",
-				"DATABASE_URL": cfg.Memory.DatabaseURL,
-			},
-			Networks:       []string{"mcp-net"},
-			Authentication: cfg.Memory.Authentication,
-			DependsOn:      []string{"postgres-memory"},
-		}
-
-		// Add postgres-memory config too
-		postgresMemoryConfig := config.ServerConfig{
-			Image:       "postgres:15-alpine",
-			User:        "postgres",
-			ReadOnly:    false,
-			Privileged:  false,
-			SecurityOpt: []string{"no-new-privileges:true"},
-			Env: map[string]string{
-				"POSTGRES_DB":       cfg.Memory.PostgresDB,
-				"POSTGRES_USER":     cfg.Memory.PostgresUser,
-				"POSTGRES_PASSWORD": cfg.Memory.PostgresPassword,
-			},
-			Volumes:       cfg.Memory.Volumes,
-			Networks:      []string{"mcp-net"},
-			RestartPolicy: "unless-stopped",
-		}
-
-		// Add to servers map
-		if cfg.Servers == nil {
-			cfg.Servers = make(map[string]config.ServerConfig)
-		}
-		cfg.Servers["memory"] = memoryConfig
-		cfg.Servers["postgres-memory"] = postgresMemoryConfig
+	// Add the servers of the enabled built-in services, such as the task scheduler and memory
+	added, err := AddBuiltInServers(cfg)
+	if err != nil {
 
-		logger.Info("Added memory as built-in server on port %d", cfg.Memory.Port)
+		return nil, fmt.Errorf("invalid built-in service configuration: %w", err)
+	}
+	for _, name := range added {
+		logger.Info("Added %s as built-in server", name)
 	}
 
 	// Validate each server configuration using our method
@@ -209,7 +108,6 @@ func NewManager(cfg *config.ComposeConfig, rt container.Runtime) (*Manager, erro
 	ctx, cancel := context.WithCancel(context.Background())
 
 	manager := &Manager{
-		config:           cfg,
 		containerRuntime: rt,
 		projectDir:       wd,
 		servers:          make(map[string]*ServerInstance),
@@ -219,34 +117,14 @@ func NewManager(cfg *config.ComposeConfig, rt container.Runtime) (*Manager, erro
 		cancel:           cancel,
 		shutdownCh:       make(chan struct{}),
 		healthCheckers:   make(map[string]context.CancelFunc),
+		crashLoops:       make(map[string]*crashLoopState),
+		tracer:           telemetry.NewTracer(cfg.Observability, "mcp-compose-proxy", logger),
 	}
+	manager.config.Store(cfg)
 
 	// Initialize server instances
 	for name, serverCfg := range cfg.Servers {
-		instanceCtx, instanceCancel := context.WithCancel(ctx)
-
-		// INITIALIZE PROTOCOL MANAGERS
-		progressManager := protocol.NewProgressManager()
-		resourceManager := protocol.NewResourceManager()
-		samplingManager := protocol.NewSamplingManager()
-
-		// Register default text transformer
-		resourceManager.RegisterTransformer("default", &protocol.DefaultTextTransformer{})
-
-		manager.servers[name] = &ServerInstance{
-			Name:            name,
-			Config:          serverCfg,
-			IsContainer:     serverCfg.Image != "" || serverCfg.Runtime != "" || manager.isLikelyContainer(name, serverCfg),
-			Status:          "stopped",
-			Capabilities:    make(map[string]bool),
-			ConnectionInfo:  make(map[string]string),
-			HealthStatus:    "unknown",
-			ProgressManager: progressManager,
-			ResourceManager: resourceManager,
-			SamplingManager: samplingManager,
-			ctx:             instanceCtx,
-			cancel:          instanceCancel,
-		}
+		manager.servers[name] = manager.newServerInstance(name, serverCfg)
 
 		logger.Info("Initialized server instance '%s' (container: %t)", name, manager.servers[name].IsContainer)
 	}
@@ -256,7 +134,151 @@ func NewManager(cfg *config.ComposeConfig, rt container.Runtime) (*Manager, erro
 	return manager, nil
 }
 
+func (m *Manager) newServerInstance(name string, serverCfg config.ServerConfig) *ServerInstance {
+	instanceCtx, instanceCancel := context.WithCancel(m.ctx)
+
+	// INITIALIZE PROTOCOL MANAGERS
+	progressManager := protocol.NewProgressManager()
+	resourceManager := protocol.NewResourceManager()
+	samplingManager := protocol.NewSamplingManager()
+
+	// Register default text transformer
+	resourceManager.RegisterTransformer("default", &protocol.DefaultTextTransformer{})
+
+	return &ServerInstance{
+		Name:            name,
+		Config:          serverCfg,
+		IsContainer:     serverCfg.Image != "" || serverCfg.Runtime != "" || m.isLikelyContainer(name, serverCfg),
+		Status:          "stopped",
+		Capabilities:    make(map[string]bool),
+		ConnectionInfo:  make(map[string]string),
+		HealthStatus:    "unknown",
+		ProgressManager: progressManager,
+		ResourceManager: resourceManager,
+		SamplingManager: samplingManager,
+		ctx:             instanceCtx,
+		cancel:          instanceCancel,
+	}
+}
+
+// Config returns the configuration the manager is running with
+func (m *Manager) Config() *config.ComposeConfig {
+
+	return m.config.Load()
+}
+
+// SetServerConfig replaces the configuration of one server. The manager's configuration is
+// copied rather than changed in place, as requests may be reading it.
+func (m *Manager) SetServerConfig(name string, serverCfg config.ServerConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.config.Load()
+	updated := *current
+	updated.Servers = make(map[string]config.ServerConfig, len(current.Servers))
+	for serverName, cfg := range current.Servers {
+		updated.Servers[serverName] = cfg
+	}
+	updated.Servers[name] = serverCfg
+	m.config.Store(&updated)
+}
+
+// ApplyConfig switches the manager to an edited configuration, touching only the servers
+// it changes: removed servers are stopped, changed servers that were running are restarted
+// with their new settings, and added servers are registered ready to start. It returns the
+// changes and the servers it restarted.
+func (m *Manager) ApplyConfig(cfg *config.ComposeConfig) (config.ConfigChanges, []string, error) {
+	if _, err := AddBuiltInServers(cfg); err != nil {
+
+		return config.ConfigChanges{}, nil, fmt.Errorf("invalid built-in service configuration: %w", err)
+	}
+	for name, serverCfg := range cfg.Servers {
+		if err := m.validateServerConfig(name, serverCfg); err != nil {
+
+			return config.ConfigChanges{}, nil, fmt.Errorf("invalid server configuration: %w", err)
+		}
+	}
+
+	m.mu.RLock()
+	changes := config.DiffConfigs(m.Config(), cfg)
+	wasRunning := make(map[string]bool)
+	for _, name := range append(append([]string{}, changes.Changed...), changes.Removed...) {
+		if instance, ok := m.servers[name]; ok {
+			wasRunning[name] = instance.Status == "running"
+		}
+	}
+	m.mu.RUnlock()
+
+	var problems []string
+	for _, name := range append(append([]string{}, changes.Removed...), changes.Changed...) {
+		if !wasRunning[name] {
+
+			continue
+		}
+		if err := m.StopServer(name); err != nil {
+			problems = append(problems, fmt.Sprintf("failed to stop '%s': %v", name, err))
+		}
+	}
+
+	m.mu.Lock()
+	m.config.Store(cfg)
+	for _, name := range changes.Removed {
+		if instance, ok := m.servers[name]; ok {
+			instance.cancel()
+			delete(m.servers, name)
+		}
+	}
+	for _, name := range changes.Changed {
+		if previous, ok := m.servers[name]; ok {
+			previous.cancel()
+		}
+		m.servers[name] = m.newServerInstance(name, cfg.Servers[name])
+	}
+	for _, name := range changes.Added {
+		m.servers[name] = m.newServerInstance(name, cfg.Servers[name])
+	}
+	m.mu.Unlock()
+
+	m.crashLoopMu.Lock()
+	for _, name := range append(append([]string{}, changes.Removed...), changes.Changed...) {
+		delete(m.crashLoops, name)
+	}
+	m.crashLoopMu.Unlock()
+
+	var restarted []string
+	for _, name := range changes.Changed {
+		if !wasRunning[name] {
+
+			continue
+		}
+		if err := m.StartServer(name); err != nil {
+			problems = append(problems, fmt.Sprintf("failed to start '%s': %v", name, err))
+
+			continue
+		}
+		restarted = append(restarted, name)
+	}
+
+	m.logger.Info("Applied edited configuration: %d added, %d removed, %d changed, %d restarted",
+		len(changes.Added), len(changes.Removed), len(changes.Changed), len(restarted))
+	if len(problems) > 0 {
+
+		return changes, restarted, fmt.Errorf("configuration applied with problems: %s", strings.Join(problems, "; "))
+	}
+
+	return changes, restarted, nil
+}
+
 func (m *Manager) StartServer(name string) error {
+	_, span := m.tracer.StartSpan(m.ctx, "manager.start_server", telemetry.SpanKindInternal)
+	span.SetAttribute("mcp.server", name)
+	err := m.startServer(name)
+	span.End(err)
+
+	return err
+}
+
+func (m *Manager) startServer(name string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -270,7 +292,7 @@ func (m *Manager) StartServer(name string) error {
 	}
 
 	srvCfg := instance.Config
-	fixedIdentifier := fmt.Sprintf("mcp-compose-%s", name)
+	fixedIdentifier := config.ContainerName(name)
 	m.logger.Info("MANAGER: Determined fixedIdentifier for '%s' as '%s'", name, fixedIdentifier)
 
 	// Check current status
@@ -290,7 +312,7 @@ func (m *Manager) StartServer(name string) error {
 	// Pre-start hooks
 	if srvCfg.Lifecycle.PreStart != "" {
 		m.logger.Info("MANAGER: Running pre-start hook for server '%s'...", name)
-		if hookErr := m.runLifecycleHook(srvCfg.Lifecycle.PreStart); hookErr != nil {
+		if hookErr := m.runLifecycleHook(srvCfg.Lifecycle.Shell, srvCfg.Lifecycle.PreStart); hookErr != nil {
 			m.logger.Error("MANAGER: Pre-start hook for server '%s' failed: %v", name, hookErr)
 
 			return fmt.Errorf("pre-start hook for server '%s' failed: %w", name, hookErr)
@@ -311,6 +333,23 @@ func (m *Manager) StartServer(name string) error {
 		m.logger.Info("MANAGER: Networks ensured for server '%s'.", name)
 	}
 
+	// Init jobs must all succeed before the server starts
+	if len(srvCfg.Init) > 0 {
+		m.logger.Info("MANAGER: Running %d init job(s) for server '%s'...", len(srvCfg.Init), name)
+		initJobs := InitRunner{
+			Config:   m.Config(),
+			Runtime:  m.containerRuntime,
+			StateDir: filepath.Join(m.projectDir, constants.InitJobsDir),
+			Dir:      m.projectDir,
+		}
+		if initErr := initJobs.Run(name); initErr != nil {
+			m.logger.Error("MANAGER: Init jobs of server '%s' failed: %v", name, initErr)
+
+			return fmt.Errorf("init jobs of server '%s' failed: %w", name, initErr)
+		}
+		m.logger.Info("MANAGER: Init jobs of server '%s' completed.", name)
+	}
+
 	var startErr error
 	if instance.IsContainer {
 		m.logger.Info("MANAGER: Server '%s' is container. Calling startContainerServer with identifier '%s'.", name, fixedIdentifier)
@@ -332,6 +371,7 @@ func (m *Manager) StartServer(name string) error {
 	instance.Status = "running"
 	instance.StartTime = time.Now()
 	m.logger.Info("MANAGER: Server '%s' (identifier: %s) marked as started successfully. ContainerID (if any): %s", name, fixedIdentifier, instance.ContainerID)
+	events.Publish(events.Event{Type: events.ServerStarted, Server: name, Message: fmt.Sprintf("Server '%s' started", name)})
 
 	// REMOVE ALL THE BLOCKING POST-START ACTIVITIES
 	// Just start them in background goroutines without waiting
@@ -340,35 +380,15 @@ func (m *Manager) StartServer(name string) error {
 	if srvCfg.Lifecycle.PostStart != "" {
 		go func() {
 			m.logger.Info("MANAGER: Running post-start hook for server '%s' (background)...", name)
-			if hookErr := m.runLifecycleHook(srvCfg.Lifecycle.PostStart); hookErr != nil {
+			if hookErr := m.runLifecycleHook(srvCfg.Lifecycle.Shell, srvCfg.Lifecycle.PostStart); hookErr != nil {
 				m.logger.Warning("MANAGER: Post-start hook for server '%s' failed: %v", name, hookErr)
 			} else {
 				m.logger.Info("MANAGER: Post-start hook for server '%s' completed.", name)
 			}
 		}()
 	}
-	if config.IsCapabilityEnabled(srvCfg, "resources") && len(srvCfg.Resources.Paths) > 0 {
-		go func() {
-			m.logger.Info("MANAGER: Initializing resource watcher for server '%s' (background)...", name)
-			// Fix: Pass the instance as the second parameter
-			watcher, watchErr := NewResourcesWatcher(&srvCfg, instance, m.logger)
-			if watchErr != nil {
-				m.logger.Warning("MANAGER: Failed to initialize resource watcher for server '%s': %v", name, watchErr)
-
-				return
-			}
-
-			instance.mu.Lock()
-			instance.ResourcesWatcher = watcher
-			instance.mu.Unlock()
-
-			watcher.Start()
-			m.logger.Info("MANAGER: Resource watcher started for server '%s'", name)
-		}()
-	}
-
 	// Health check (non-blocking)
-	if srvCfg.Lifecycle.HealthCheck.Endpoint != "" {
+	if healthCheck := srvCfg.ActiveHealthCheck(); healthCheck != nil && healthCheck.CheckType() != "none" {
 		go func() {
 			m.logger.Info("MANAGER: Starting health check for server '%s' (background)...", name)
 			m.startHealthCheck(name, fixedIdentifier)
@@ -404,22 +424,27 @@ func (m *Manager) startContainerServer(serverKeyName, containerNameToUse string,
 		return fmt.Errorf("server '%s' (container: %s) has no image specified", serverKeyName, containerNameToUse)
 	}
 	m.logger.Info("Preparing to start container '%s' for server '%s' with image '%s'", containerNameToUse, serverKeyName, srvCfg.Image)
+	cfg := m.Config()
 
-	// Ensure mcp-net network exists FIRST
+	// Ensure the server's networks exist FIRST
+	networks := cfg.ServerNetworks(serverKeyName)
+	if len(networks) == 0 {
+		networks = []string{config.DefaultNetwork()} // network_mode is not applied to managed containers
+	}
 	if m.containerRuntime != nil && m.containerRuntime.GetRuntimeName() != "none" {
-		networkExists, _ := m.containerRuntime.NetworkExists("mcp-net")
-		if !networkExists {
-			if err := m.containerRuntime.CreateNetwork("mcp-net"); err != nil {
-				m.logger.Warning("Failed to create mcp-net network: %v", err)
-			} else {
-				m.logger.Info("Created mcp-net network")
+		for _, networkName := range networks {
+			if err := m.ensureNetworkExists(networkName, true); err != nil {
+				m.logger.Warning("Failed to create %s network: %v", networkName, err)
 			}
 		}
+		if cfg.HasOwnNetwork(serverKeyName) {
+			m.connectProxyToNetwork(config.ServerNetwork(serverKeyName))
+		}
 	}
 
 	var volumes []string
 	if srvCfg.Volumes != nil {
-		volumes = append([]string{}, srvCfg.Volumes...) // Copy existing volumes
+		volumes = append([]string{}, cfg.NamespaceMounts(srvCfg.Volumes)...) // Copy existing volumes
 	}
 	for _, resourcePath := range srvCfg.Resources.Paths {
 		absPath, err := filepath.Abs(resourcePath.Source)
@@ -435,7 +460,8 @@ func (m *Manager) startContainerServer(serverKeyName, containerNameToUse string,
 	}
 
 	// Prepare environment variables, including MCP_SERVER_NAME
-	envVars := config.MergeEnv(srvCfg.Env, map[string]string{"MCP_SERVER_NAME": serverKeyName})
+	envVars := config.MergeEnv(cfg.ServerEnv(serverKeyName), map[string]string{"MCP_SERVER_NAME": serverKeyName})
+	envVars = config.MergeEnv(envVars, cfg.EgressEnv(serverKeyName))
 
 	// Use existing ports from config (no auto HTTP port exposure)
 	ports := make([]string, len(srvCfg.Ports))
@@ -464,14 +490,6 @@ func (m *Manager) startContainerServer(serverKeyName, containerNameToUse string,
 		m.logger.Info("Using configured command '%s' with args %v", command, args)
 	}
 
-	// Ensure networks include mcp-net
-	networks := []string{"mcp-net"} // Always include mcp-net
-	for _, net := range srvCfg.Networks {
-		if net != "mcp-net" { // Don't duplicate
-			networks = append(networks, net)
-		}
-	}
-
 	opts := &container.ContainerOptions{
 		Name:        containerNameToUse, // This is the name Docker/Podman will use
 		Image:       srvCfg.Image,
@@ -479,15 +497,23 @@ func (m *Manager) startContainerServer(serverKeyName, containerNameToUse string,
 		Args:        args,    // Don't override for HTTP wrappers
 		Env:         envVars,
 		Pull:        srvCfg.Pull,
+		PullPolicy:  srvCfg.PullPolicy,
 		Volumes:     volumes,
-		Ports:       ports,    // Only explicitly configured ports, no auto HTTP ports
-		NetworkMode: "",       // Don't use NetworkMode, use Networks instead
-		Networks:    networks, // Ensure mcp-net is included
+		Ports:       ports, // Only explicitly configured ports, no auto HTTP ports
+		NetworkMode: "",    // Don't use NetworkMode, use Networks instead
+		Networks:    networks,
 		WorkDir:     srvCfg.WorkDir,
+		User:        srvCfg.User,
+		Groups:      srvCfg.Groups,
+		UsernsMode:  srvCfg.UsernsMode,
+		Platform:    srvCfg.Platform,
+		GPUs:        srvCfg.GPURequest(),
 	}
+	scanner := imagescan.Scanner{Runtime: m.containerRuntime, CacheFile: filepath.Join(m.projectDir, constants.ImageScanCacheFile), Out: os.Stdout}
+	opts.ImageCheck = scanner.Hook(serverKeyName, srvCfg.Security.Scan)
 
 	// Add globally defined connection ports if exposed
-	for connKey, connCfg := range m.config.Connections {
+	for connKey, connCfg := range cfg.Connections {
 		if connCfg.Expose && connCfg.Port > 0 {
 			portMapping := fmt.Sprintf("%d:%d", connCfg.Port, connCfg.Port) // hostPort:containerPort
 			if !contains(opts.Ports, portMapping) {
@@ -528,16 +554,12 @@ func (m *Manager) startContainerServer(serverKeyName, containerNameToUse string,
 func (m *Manager) startProcessServer(serverKeyName, processIdentifier string, srvCfg *config.ServerConfig) error {
 	m.logger.Info("Preparing to start process '%s' for server '%s' with command '%s'", processIdentifier, serverKeyName, srvCfg.Command)
 
-	env := make(map[string]string)
-	if srvCfg.Env != nil {
-		for k, v := range srvCfg.Env {
-			env[k] = v
-		}
-	}
+	cfg := m.Config()
+	env := cfg.ServerEnv(serverKeyName)
 	// Add standard MCP environment variables
 	env["MCP_SERVER_NAME"] = serverKeyName
 	// Add connection-related environment variables from global config
-	for connKey, connCfg := range m.config.Connections {
+	for connKey, connCfg := range cfg.Connections {
 		prefix := fmt.Sprintf("MCP_CONN_%s_", strings.ToUpper(connKey))
 		env[prefix+"TRANSPORT"] = connCfg.Transport
 		if connCfg.Port > 0 {
@@ -552,9 +574,14 @@ func (m *Manager) startProcessServer(serverKeyName, processIdentifier string, sr
 	}
 
 	proc, err := runtime.NewProcess(srvCfg.Command, srvCfg.Args, runtime.ProcessOptions{
-		Env:     env,
-		WorkDir: srvCfg.WorkDir,
-		Name:    processIdentifier, // runtime.Process uses this for its internal tracking (e.g., PID file name)
+		Env:           env,
+		WorkDir:       srvCfg.WorkDir,
+		Name:          processIdentifier, // runtime.Process uses this for its internal tracking (e.g., PID file name)
+		RestartPolicy: srvCfg.EffectiveRestartPolicy(),
+		LogRotation:   runtime.LogRotationFromConfig(cfg.Logging.Retention),
+		CrashLoop:     runtime.CrashLoopLimitsFromConfig(srvCfg.CrashLoop),
+		Notifications: cfg.Notifications,
+		Stdio:         srvCfg.UsesStdio(),
 	})
 	if err != nil {
 
@@ -573,6 +600,15 @@ func (m *Manager) startProcessServer(serverKeyName, processIdentifier string, sr
 
 // StopServer stops a server using its fixed identifier
 func (m *Manager) StopServer(name string) error {
+	_, span := m.tracer.StartSpan(m.ctx, "manager.stop_server", telemetry.SpanKindInternal)
+	span.SetAttribute("mcp.server", name)
+	err := m.stopServer(name)
+	span.End(err)
+
+	return err
+}
+
+func (m *Manager) stopServer(name string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -582,7 +618,7 @@ func (m *Manager) StopServer(name string) error {
 		return fmt.Errorf("server '%s' not found in manager", name)
 	}
 	srvCfg := instance.Config
-	fixedIdentifier := fmt.Sprintf("mcp-compose-%s", name)
+	fixedIdentifier := config.ContainerName(name)
 
 	currentStatus, _ := m.getServerStatusUnsafe(name, fixedIdentifier)
 	if currentStatus != "running" {
@@ -595,17 +631,11 @@ func (m *Manager) StopServer(name string) error {
 
 	if srvCfg.Lifecycle.PreStop != "" {
 		m.logger.Info("Running pre-stop hook for server '%s'", name)
-		if err := m.runLifecycleHook(srvCfg.Lifecycle.PreStop); err != nil {
+		if err := m.runLifecycleHook(srvCfg.Lifecycle.Shell, srvCfg.Lifecycle.PreStop); err != nil {
 			m.logger.Warning("Pre-stop hook for server '%s' failed: %v", name, err) // Log but continue stopping
 		}
 	}
 
-	if instance.ResourcesWatcher != nil {
-		instance.ResourcesWatcher.Stop()
-		instance.ResourcesWatcher = nil
-		m.logger.Debug("Resource watcher stopped for server '%s'", name)
-	}
-
 	var stopErr error
 	if instance.IsContainer {
 		m.logger.Info("Stopping container '%s' for server '%s'", fixedIdentifier, name)
@@ -627,11 +657,13 @@ func (m *Manager) StopServer(name string) error {
 
 	instance.Status = "stopped"
 	instance.HealthStatus = "unknown"
+	instance.HealthError = ""
 	m.logger.Info("Server '%s' (identifier: %s) has been stopped", name, fixedIdentifier)
+	events.Publish(events.Event{Type: events.ServerStopped, Server: name, Message: fmt.Sprintf("Server '%s' stopped", name)})
 
 	if srvCfg.Lifecycle.PostStop != "" {
 		m.logger.Info("Running post-stop hook for server '%s'", name)
-		if err := m.runLifecycleHook(srvCfg.Lifecycle.PostStop); err != nil {
+		if err := m.runLifecycleHook(srvCfg.Lifecycle.Shell, srvCfg.Lifecycle.PostStop); err != nil {
 			m.logger.Warning("Post-stop hook for server '%s' failed: %v", name, err)
 		}
 	}
@@ -644,7 +676,7 @@ func (m *Manager) StopServer(name string) error {
 func (m *Manager) GetServerStatus(name string) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	fixedIdentifier := fmt.Sprintf("mcp-compose-%s", name)
+	fixedIdentifier := config.ContainerName(name)
 
 	// Check if this is a built-in service that might have different container handling
 	if m.isBuiltInService(name) {
@@ -706,14 +738,12 @@ func (m *Manager) getServerStatusUnsafe(name string, fixedIdentifier string) (st
 
 // isBuiltInService checks if a server is a built-in service with special handling
 func (m *Manager) isBuiltInService(name string) bool {
-	builtInServices := []string{"memory", "task-scheduler", "dashboard", "proxy"}
-	for _, service := range builtInServices {
-		if name == service {
-			return true
-		}
+	if name == "dashboard" || name == "proxy" {
+
+		return true
 	}
 
-	return false
+	return IsBuiltinService(name)
 }
 
 // getBuiltInServiceStatus handles status checking for built-in services
@@ -769,7 +799,7 @@ func (m *Manager) isLikelyContainer(serverName string, serverCfg config.ServerCo
 	}
 
 	// Check if there's a corresponding container name that exists
-	expectedContainerName := fmt.Sprintf("mcp-compose-%s", serverName)
+	expectedContainerName := config.ContainerName(serverName)
 	if m.containerRuntime != nil {
 		// Try to check if container exists (ignore errors, just check existence)
 		_, err := m.containerRuntime.GetContainerStatus(expectedContainerName)
@@ -790,7 +820,7 @@ func (m *Manager) ShowLogs(name string, follow bool) error {
 
 		return fmt.Errorf("server '%s' not found for showing logs", name)
 	}
-	fixedIdentifier := fmt.Sprintf("mcp-compose-%s", name)
+	fixedIdentifier := config.ContainerName(name)
 	m.logger.Debug("Requesting logs for server '%s' (identifier: %s)", name, fixedIdentifier)
 
 	if instance.IsContainer {
@@ -810,287 +840,56 @@ func (m *Manager) ShowLogs(name string, follow bool) error {
 	}
 }
 
-type ResourcesWatcher struct {
-	config          *config.ServerConfig
-	fsWatcher       *fsnotify.Watcher // Simplified to one watcher for the example
-	stopCh          chan struct{}
-	active          bool
-	logger          *logging.Logger
-	mu              sync.Mutex
-	changedFiles    map[string]time.Time
-	ticker          *time.Ticker
-	resourceManager *protocol.ResourceManager
-	serverInstance  *ServerInstance
-}
-
-func NewResourcesWatcher(cfg *config.ServerConfig, instance *ServerInstance, loggerInstance ...*logging.Logger) (*ResourcesWatcher, error) {
-	var logger *logging.Logger
-	if len(loggerInstance) > 0 && loggerInstance[0] != nil {
-		logger = loggerInstance[0]
-	} else {
-		logger = logging.NewLogger("info")
-	}
-
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-
-		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
-	}
-
-	return &ResourcesWatcher{
-		config:          cfg,
-		fsWatcher:       watcher,
-		stopCh:          make(chan struct{}),
-		logger:          logger,
-		changedFiles:    make(map[string]time.Time),
-		resourceManager: instance.ResourceManager,
-		serverInstance:  instance,
-	}, nil
-}
-
-func (w *ResourcesWatcher) Start() {
-	w.mu.Lock()
-	if w.active {
-		w.mu.Unlock()
-		w.logger.Debug("Resource watcher already active.")
-
-		return
-	}
-	w.active = true
-	w.mu.Unlock()
-
-	w.logger.Info("Starting resource watcher for paths: %v", w.config.Resources.Paths)
-
-	for _, rp := range w.config.Resources.Paths {
-		if rp.Watch {
-			// Walk the path to add all subdirectories
-			err := filepath.WalkDir(rp.Source, func(path string, d fs.DirEntry, err error) error {
-				if err != nil {
-					w.logger.Error("Error walking path %s for watcher: %v", path, err)
-
-					return err // Or return nil to continue walking other parts
-				}
-				if d.IsDir() {
-					w.logger.Debug("Adding path to watcher: %s", path)
-					if addErr := w.fsWatcher.Add(path); addErr != nil {
-						w.logger.Error("Failed to add path %s to watcher: %v", path, addErr)
-						// Potentially continue to try and watch other directories
-					}
-				}
-
-				return nil
-			})
-			if err != nil {
-				w.logger.Error("Error setting up watch for path %s: %v", rp.Source, err)
-				// Potentially stop or handle error
-			}
-		}
-	}
+// ServerLogs returns the last tail lines a server logged
+func (m *Manager) ServerLogs(name string, tail int) ([]string, error) {
+	instance, ok := m.servers[name]
+	if !ok {
 
-	syncInterval := constants.SyncIntervalDefault // Default sync interval
-	if w.config.Resources.SyncInterval != "" {
-		parsedInterval, err := time.ParseDuration(w.config.Resources.SyncInterval)
-		if err == nil {
-			syncInterval = parsedInterval
-		} else {
-			w.logger.Warning("Invalid resource sync interval '%s', using default %v: %v", w.config.Resources.SyncInterval, syncInterval, err)
-		}
+		return nil, fmt.Errorf("server '%s' not found for showing logs", name)
 	}
-	w.ticker = time.NewTicker(syncInterval)
-
-	go func() {
-		defer w.cleanupWatcher()
-		for {
-			select {
-			case <-w.stopCh:
-				w.logger.Info("Resource watcher stop signal received.")
-
-				return
-			case event, ok := <-w.fsWatcher.Events:
-				if !ok {
-					w.logger.Info("Watcher events channel closed.")
-
-					return
-				}
-				if w.shouldProcessEvent(event) {
-					w.recordChange(event.Name)
-				}
-			case err, ok := <-w.fsWatcher.Errors:
-				if !ok {
-					w.logger.Info("Watcher errors channel closed.")
-
-					return
-				}
-				w.logger.Error("Watcher error: %v", err)
-			case <-w.ticker.C:
-				w.processChanges()
-			}
-		}
-	}()
-}
-func (w *ResourcesWatcher) cleanupWatcher() {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	if w.ticker != nil {
-		w.ticker.Stop()
-	}
-	if w.fsWatcher != nil {
-		if err := w.fsWatcher.Close(); err != nil {
-			w.logger.Warning("Failed to close filesystem watcher: %v", err)
-		}
-	}
-	w.active = false
-	w.logger.Info("Resource watcher cleaned up.")
-}
-
-func (w *ResourcesWatcher) shouldProcessEvent(event fsnotify.Event) bool {
-	// Basic filtering, can be expanded
-	if strings.HasPrefix(filepath.Base(event.Name), ".") { // Ignore hidden files/dirs
+	fixedIdentifier := config.ContainerName(name)
+	if instance.IsContainer {
 
-		return false
+		return m.containerRuntime.GetContainerLogs(fixedIdentifier, tail)
 	}
-	// Only interested in these operations
-
-	return event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
-}
 
-func (w *ResourcesWatcher) recordChange(path string) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	w.changedFiles[path] = time.Now()
-	w.logger.Debug("Resource change detected: %s", path)
+	return runtime.ProcessLogs(fixedIdentifier).TailLogs(tail)
 }
 
-func (w *ResourcesWatcher) processChanges() {
-	w.mu.Lock()
-	if len(w.changedFiles) == 0 {
-		w.mu.Unlock()
+func (m *Manager) startHealthCheck(serverName, fixedIdentifier string) {
+	instance, ok := m.servers[serverName]
+	if !ok {
+		m.logger.Error("HealthCheck: Server '%s' not found.", serverName)
 
 		return
 	}
-	// Create a copy to process, then clear the map
-	changesToProcess := make(map[string]time.Time, len(w.changedFiles))
-	for k, v := range w.changedFiles {
-		changesToProcess[k] = v
-	}
-	w.changedFiles = make(map[string]time.Time) // Clear original map
-	w.mu.Unlock()
 
-	if len(changesToProcess) == 0 {
+	activeCheck := instance.Config.ActiveHealthCheck()
+	if activeCheck == nil || activeCheck.CheckType() == "none" {
+		m.logger.Debug("HealthCheck: No health check for server '%s'.", serverName)
 
 		return
 	}
+	healthCfg := *activeCheck
 
-	mappedChanges := make(map[string]string) // Path -> "file" | "directory" | "deleted"
-	for changedPath := range changesToProcess {
-		// Determine type or if deleted
-		info, err := os.Stat(changedPath)
-		var changeType string
-		if err == nil {
-			changeType = "file"
-			if info.IsDir() {
-				changeType = "directory"
-			}
-		} else if os.IsNotExist(err) {
-			changeType = "deleted"
+	interval := constants.SyncIntervalLong
+	if healthCfg.Interval != "" {
+		if parsed, err := time.ParseDuration(healthCfg.Interval); err == nil {
+			interval = parsed
 		} else {
-			w.logger.Warning("Error stating changed path %s: %v", changedPath, err)
-
-			continue // Skip if cannot determine state
+			m.logger.Warning("HealthCheck: Invalid interval '%s' for '%s', using default %v: %v", healthCfg.Interval, serverName, interval, err)
 		}
-
-		// Map this changedPath to the target path in the MCP server's context
-		var targetPath string
-		foundMapping := false
-		for _, rp := range w.config.Resources.Paths {
-			if strings.HasPrefix(changedPath, rp.Source) {
-				relPath, _ := filepath.Rel(rp.Source, changedPath)
-				targetPath = filepath.Join(rp.Target, relPath)
-				mappedChanges[targetPath] = changeType
-				foundMapping = true
-
-				break
-			}
-		}
-		if !foundMapping {
-			w.logger.Debug("No resource mapping found for changed path: %s", changedPath)
-		}
-	}
-
-	if len(mappedChanges) > 0 {
-		w.notifyChanges(mappedChanges)
 	}
-}
-
-func (w *ResourcesWatcher) notifyChanges(changes map[string]string) {
-	// Placeholder for actual notification
-	// This would involve constructing an MCP resources/list-changed notification
-	// and sending it to the associated MCP server instance.
-	changesJSON, _ := json.MarshalIndent(changes, "", "  ")
-	w.logger.Info("Server notified of resource changes: %s", string(changesJSON))
-}
-
-func (w *ResourcesWatcher) Stop() {
-	w.mu.Lock()
-	if !w.active {
-		w.mu.Unlock()
 
-		return
-	}
-	// Set active to false first to prevent new operations from starting
-	w.active = false
-	w.mu.Unlock()
-
-	// Signal the watcher goroutine to stop by closing stopCh
-	// Check if stopCh is nil or already closed to prevent panic
-	w.mu.Lock()
-	if w.stopCh != nil {
-		select {
-		case <-w.stopCh:
-			// Already closed or being closed
-		default:
-			close(w.stopCh) // Close the channel
-			w.stopCh = nil  // Mark as closed
+	// Failures during the start period don't count towards retries, like Docker's start_period
+	var startPeriod time.Duration
+	if healthCfg.StartPeriod != "" {
+		if parsed, err := time.ParseDuration(healthCfg.StartPeriod); err == nil {
+			startPeriod = parsed
+		} else {
+			m.logger.Warning("HealthCheck: Invalid start period '%s' for '%s', ignoring it: %v", healthCfg.StartPeriod, serverName, err)
 		}
 	}
-	w.mu.Unlock() // Unlock before logging
-
-	// Wait for cleanup to complete with timeout
-	done := make(chan struct{})
-	go func() {
-		// Wait a bit for the goroutine to finish cleanly
-		time.Sleep(constants.ShortSleepDuration)
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		w.logger.Info("Resource watcher stopped successfully")
-	case <-time.After(constants.LongSleepDuration):
-		w.logger.Warning("Resource watcher stop timeout")
-	}
-}
-
-func (m *Manager) startHealthCheck(serverName, fixedIdentifier string) {
-	instance, ok := m.servers[serverName]
-	if !ok {
-		m.logger.Error("HealthCheck: Server '%s' not found.", serverName)
-
-		return
-	}
-
-	healthCfg := instance.Config.Lifecycle.HealthCheck
-	if healthCfg.Endpoint == "" {
-		m.logger.Debug("HealthCheck: No endpoint for server '%s'.", serverName)
-
-		return
-	}
-
-	interval, err := time.ParseDuration(healthCfg.Interval)
-	if err != nil {
-		interval = constants.SyncIntervalLong
-		m.logger.Warning("HealthCheck: Invalid interval '%s' for '%s', using default %v: %v", healthCfg.Interval, serverName, interval, err)
-	}
 
 	// Get configurable timeout for health checks
 	timeout := constants.SyncFallbackTimeout // Default fallback
@@ -1100,9 +899,9 @@ func (m *Manager) startHealthCheck(serverName, fixedIdentifier string) {
 		} else {
 			m.logger.Warning("HealthCheck: Invalid timeout '%s' for '%s', using default %v: %v", healthCfg.Timeout, serverName, timeout, parseErr)
 		}
-	} else if len(m.config.Connections) > 0 {
+	} else if len(m.Config().Connections) > 0 {
 		// Use global connection timeout config as fallback
-		for _, conn := range m.config.Connections {
+		for _, conn := range m.Config().Connections {
 			timeout = conn.Timeouts.GetHealthCheckTimeout()
 
 			break
@@ -1111,17 +910,18 @@ func (m *Manager) startHealthCheck(serverName, fixedIdentifier string) {
 
 	retries := healthCfg.Retries
 	if retries <= 0 {
-		retries = 3
+		retries = constants.HealthCheckDefaultRetries
 	}
 
 	// USE fixedIdentifier in the logging here
-	m.logger.Info("HealthCheck: Starting for server '%s' (container: %s), endpoint: %s, interval: %v, timeout: %v, retries: %d",
-		serverName, fixedIdentifier, healthCfg.Endpoint, interval, timeout, retries)
+	m.logger.Info("HealthCheck: Starting %s check for server '%s' (container: %s), interval: %v, timeout: %v, retries: %d, start period: %v",
+		healthCfg.CheckType(), serverName, fixedIdentifier, interval, timeout, retries, startPeriod)
 
 	go func() {
 		healthCheckTicker := time.NewTicker(interval)
 		defer healthCheckTicker.Stop()
 		failCount := 0
+		startedAt := time.Now()
 
 		for {
 			select {
@@ -1141,7 +941,7 @@ func (m *Manager) startHealthCheck(serverName, fixedIdentifier string) {
 				}
 
 				// USE fixedIdentifier in the health check call
-				healthy, checkErr := m.checkServerHealth(serverName, fixedIdentifier, healthCfg.Endpoint, timeout)
+				healthy, checkErr := m.checkServerHealth(serverName, fixedIdentifier, healthCfg, timeout)
 
 				m.mu.Lock()
 				instance, stillExists = m.servers[serverName]
@@ -1152,25 +952,48 @@ func (m *Manager) startHealthCheck(serverName, fixedIdentifier string) {
 					return
 				}
 
+				instance.LastHealthCheck = time.Now()
+				instance.HealthError = ""
+				if checkErr != nil {
+					instance.HealthError = checkErr.Error()
+				}
 				if healthy {
 					if instance.HealthStatus != "healthy" {
 						m.logger.Info("HealthCheck: Server '%s' (container: %s) is now healthy.", serverName, fixedIdentifier)
 					}
 					instance.HealthStatus = "healthy"
+					instance.recordHealthCheck()
 					failCount = 0
+				} else if time.Since(startedAt) < startPeriod && instance.HealthStatus != "healthy" {
+					instance.HealthStatus = "starting"
+					instance.recordHealthCheck()
+					m.logger.Debug("HealthCheck: Server '%s' (container: %s) not healthy yet during its start period: %v", serverName, fixedIdentifier, checkErr)
 				} else {
 					failCount++
 					instance.HealthStatus = fmt.Sprintf("failing (%d/%d)", failCount, retries)
 					m.logger.Warning("HealthCheck: Server '%s' (container: %s) failed check %d/%d. Error: %v", serverName, fixedIdentifier, failCount, retries, checkErr)
 
 					if failCount >= retries {
+						if failCount == retries {
+							m.notify("unhealthy", serverName, fmt.Sprintf("Server '%s' is unhealthy after %d failed health checks: %v", serverName, retries, checkErr))
+						}
 						instance.HealthStatus = "unhealthy"
 						m.logger.Error("HealthCheck: Server '%s' (container: %s) is now unhealthy after %d retries.", serverName, fixedIdentifier, retries)
+					}
+					instance.recordHealthCheck()
 
+					if failCount >= retries {
 						if healthCfg.Action == "restart" {
 							m.logger.Info("HealthCheck: Restart action configured for unhealthy server '%s' (container: %s). Attempting restart...", serverName, fixedIdentifier)
 							m.mu.Unlock()
 							go func(sName, containerName string) {
+								// A server that keeps failing its health check right after each restart
+								// is backed off instead of restarted again
+								if m.recordRestart(sName) {
+									m.backOff(sName, "restarted by failing health checks")
+
+									return
+								}
 								m.logger.Info("HealthCheck: Restart goroutine initiated for '%s' (container: %s).", sName, containerName)
 								if err := m.StopServer(sName); err != nil {
 									m.logger.Error("HealthCheck: Failed to stop unhealthy server '%s': %v", sName, err)
@@ -1200,135 +1023,108 @@ func (m *Manager) startHealthCheck(serverName, fixedIdentifier string) {
 	}()
 }
 
-func (m *Manager) checkServerHealth(serverName, fixedIdentifier, endpoint string, timeout time.Duration) (bool, error) {
+func (m *Manager) checkServerHealth(serverName, fixedIdentifier string, healthCfg config.HealthCheck, timeout time.Duration) (bool, error) {
 	instance, ok := m.servers[serverName]
 	if !ok {
 
 		return false, fmt.Errorf("server '%s' not found for health check", serverName)
 	}
 
-	var url string
-	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
-		url = endpoint
-	} else {
-		// Construct URL based on server configuration
-		var hostPort string
-		var host string // DECLARE host here, outside the if blocks
-
-		if instance.IsContainer {
-			// Use the fixed identifier (container name) for internal health checks
-			host = fixedIdentifier
-
-			// Determine port from configuration
-			if instance.Config.HttpPort > 0 {
-				hostPort = fmt.Sprintf("%d", instance.Config.HttpPort)
-			} else if instance.Config.SSEPort > 0 && instance.Config.Protocol == "sse" {
-				hostPort = fmt.Sprintf("%d", instance.Config.SSEPort)
-			} else if len(instance.Config.Ports) > 0 {
-				// Try to extract port from port mappings
-				parts := strings.Split(instance.Config.Ports[0], ":")
-				if len(parts) >= constants.ServerNameParts {
-					hostPort = parts[1] // container port
-				} else {
-					hostPort = parts[0]
-				}
+	probe := HealthProbe{
+		Check:       healthCfg,
+		Runtime:     m.containerRuntime,
+		Identifier:  fixedIdentifier,
+		IsContainer: instance.IsContainer,
+		BaseURL:     m.healthCheckBaseURL(instance, fixedIdentifier),
+		MCPPath:     instance.Config.HttpPath,
+		Dir:         m.projectDir,
+	}
+
+	// Log with both server name and identifier for better debugging
+	m.logger.Debug("HealthCheck: Running %s check for server '%s' (container: %s)", healthCfg.CheckType(), serverName, fixedIdentifier)
+
+	if err := probe.Run(timeout); err != nil {
+
+		return false, fmt.Errorf("server '%s' (%s) health check failed: %w", serverName, fixedIdentifier, err)
+	}
+	m.logger.Debug("HealthCheck: Server '%s' (%s) is healthy", serverName, fixedIdentifier)
+
+	return true, nil
+}
+
+// healthCheckBaseURL returns where HTTP and MCP health checks reach the server
+func (m *Manager) healthCheckBaseURL(instance *ServerInstance, fixedIdentifier string) string {
+	var hostPort string
+	var host string // DECLARE host here, outside the if blocks
+
+	if instance.IsContainer {
+		// Use the fixed identifier (container name) for internal health checks
+		host = fixedIdentifier
+
+		// Determine port from configuration
+		if instance.Config.HttpPort > 0 {
+			hostPort = fmt.Sprintf("%d", instance.Config.HttpPort)
+		} else if instance.Config.SSEPort > 0 && instance.Config.Protocol == "sse" {
+			hostPort = fmt.Sprintf("%d", instance.Config.SSEPort)
+		} else if len(instance.Config.Ports) > 0 {
+			// Try to extract port from port mappings
+			parts := strings.Split(instance.Config.Ports[0], ":")
+			if len(parts) >= constants.ServerNameParts {
+				hostPort = parts[1] // container port
 			} else {
-				// Default ports based on protocol
-				switch instance.Config.Protocol {
-				case "http":
-					hostPort = "80"
-				case "sse":
-					hostPort = "8080"
-				default:
-					hostPort = "80"
-				}
+				hostPort = parts[0]
 			}
 		} else {
-			// For processes, use localhost
-			host = "localhost"
-
-			// For processes, try to determine port from various sources
-			if instance.Config.HttpPort > 0 {
-				hostPort = fmt.Sprintf("%d", instance.Config.HttpPort)
-			} else if len(m.config.Connections) > 0 {
-				// Check global connections for port
-				for _, conn := range m.config.Connections {
-					if (conn.Transport == "http" || conn.Transport == "https") && conn.Port > 0 {
-						hostPort = fmt.Sprintf("%d", conn.Port)
-
-						break
-					}
+			// Default ports based on protocol
+			switch instance.Config.Protocol {
+			case "http":
+				hostPort = "80"
+			case "sse":
+				hostPort = "8080"
+			default:
+				hostPort = "80"
+			}
+		}
+	} else {
+		// For processes, use localhost
+		host = "localhost"
+
+		// For processes, try to determine port from various sources
+		if instance.Config.HttpPort > 0 {
+			hostPort = fmt.Sprintf("%d", instance.Config.HttpPort)
+		} else if len(m.Config().Connections) > 0 {
+			// Check global connections for port
+			for _, conn := range m.Config().Connections {
+				if (conn.Transport == "http" || conn.Transport == "https") && conn.Port > 0 {
+					hostPort = fmt.Sprintf("%d", conn.Port)
+
+					break
 				}
 			}
+		}
 
-			// If still no port found, try to extract from args
-			if hostPort == "" {
-				for i, arg := range instance.Config.Args {
-					if (arg == "--port" || arg == "-p") && i+1 < len(instance.Config.Args) {
-						hostPort = instance.Config.Args[i+1]
+		// If still no port found, try to extract from args
+		if hostPort == "" {
+			for i, arg := range instance.Config.Args {
+				if (arg == "--port" || arg == "-p") && i+1 < len(instance.Config.Args) {
+					hostPort = instance.Config.Args[i+1]
 
-						break
-					} else if strings.HasPrefix(arg, "--port=") {
-						hostPort = strings.TrimPrefix(arg, "--port=")
+					break
+				} else if strings.HasPrefix(arg, "--port=") {
+					hostPort = strings.TrimPrefix(arg, "--port=")
 
-						break
-					}
+					break
 				}
 			}
-
-			// Final fallback
-			if hostPort == "" {
-				hostPort = "80"
-			}
 		}
 
-		url = fmt.Sprintf("http://%s:%s%s", host, hostPort, endpoint)
-	}
-
-	client := http.Client{
-		Timeout: timeout,
-		Transport: &http.Transport{
-			DisableKeepAlives: true, // Don't keep connections alive for health checks
-			IdleConnTimeout:   timeout / constants.ManagerIdleConnDivisor,
-		},
-	}
-
-	// Log with both server name and identifier for better debugging
-	m.logger.Debug("HealthCheck: Pinging %s for server '%s' (container: %s)", url, serverName, fixedIdentifier)
-
-	resp, err := client.Get(url)
-	if err != nil {
-		// Provide more detailed error information
-		if strings.Contains(err.Error(), "connection refused") {
-
-			return false, fmt.Errorf("server '%s' (%s) not reachable at %s: connection refused", serverName, fixedIdentifier, url)
-		} else if strings.Contains(err.Error(), "timeout") {
-
-			return false, fmt.Errorf("server '%s' (%s) health check timed out at %s", serverName, fixedIdentifier, url)
-		} else if strings.Contains(err.Error(), "no such host") {
-			// Extract host from url for error message instead of using the variable
-			urlParts := strings.Split(strings.TrimPrefix(url, "http://"), ":")
-			hostFromURL := urlParts[0]
-
-			return false, fmt.Errorf("server '%s' (%s) hostname not found: %s", serverName, fixedIdentifier, hostFromURL)
+		// Final fallback
+		if hostPort == "" {
+			hostPort = "80"
 		}
-
-		return false, fmt.Errorf("health check request to %s failed for server '%s' (%s): %w", url, serverName, fixedIdentifier, err)
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// Check for healthy status codes
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		m.logger.Debug("HealthCheck: Server '%s' (%s) is healthy (status: %d)", serverName, fixedIdentifier, resp.StatusCode)
 
-		return true, nil
-	}
-
-	// Read response body for error details
-	body, _ := io.ReadAll(io.LimitReader(resp.Body, constants.HTTPLogBufferSize))
-
-	return false, fmt.Errorf("server '%s' (%s) health check failed: status %d from %s: %s",
-		serverName, fixedIdentifier, resp.StatusCode, url, string(body))
+	return fmt.Sprintf("http://%s:%s", host, hostPort)
 }
 
 // Add this method to validate server configuration
@@ -1374,13 +1170,13 @@ func (m *Manager) validateServerConfig(name string, config config.ServerConfig)
 	return nil
 }
 
-func (m *Manager) runLifecycleHook(hookScript string) error {
+func (m *Manager) runLifecycleHook(shell, hookScript string) error {
 	m.logger.Info("Running lifecycle hook: %s", hookScript)
 
 	// Get configurable timeout for lifecycle hooks
 	timeout := constants.HTTPRequestTimeout // Default fallback
-	if len(m.config.Connections) > 0 {
-		for _, conn := range m.config.Connections {
+	if len(m.Config().Connections) > 0 {
+		for _, conn := range m.Config().Connections {
 			timeout = conn.Timeouts.GetLifecycleHookTimeout()
 
 			break // Use first connection's timeout config
@@ -1391,7 +1187,11 @@ func (m *Manager) runLifecycleHook(hookScript string) error {
 	ctx, cancel := context.WithTimeout(m.ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", hookScript)
+	cmd, err := runtime.ShellCommand(ctx, shell, hookScript)
+	if err != nil {
+
+		return fmt.Errorf("lifecycle hook '%s': %w", hookScript, err)
+	}
 	cmd.Env = append(os.Environ(),
 		"MCP_PROJECT_DIR="+m.projectDir,
 		"MCP_CONFIG_DIR="+filepath.Dir(m.projectDir),
@@ -1403,7 +1203,7 @@ func (m *Manager) runLifecycleHook(hookScript string) error {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 
 	// Log hook output
 	if stdout.Len() > 0 {
@@ -1458,7 +1258,11 @@ func (m *Manager) ensureNetworkExists(networkName string, lockedByCaller bool) e
 
 	if !exists {
 		m.logger.Info("Creating network '%s'...", networkName)
-		if err := m.containerRuntime.CreateNetwork(networkName); err != nil {
+		create := m.containerRuntime.CreateNetwork
+		if m.Config().InternalNetwork(networkName) {
+			create = m.containerRuntime.CreateInternalNetwork
+		}
+		if err := create(networkName); err != nil {
 
 			return fmt.Errorf("failed to create network '%s': %w", networkName, err)
 		}
@@ -1472,6 +1276,17 @@ func (m *Manager) ensureNetworkExists(networkName string, lockedByCaller bool) e
 	return nil
 }
 
+// connectProxyToNetwork lets the proxy container reach a server on its own network. A proxy
+// running natively on the host is not a container and is left alone.
+func (m *Manager) connectProxyToNetwork(networkName string) {
+	connected, err := container.ConnectIfRunning(m.containerRuntime, config.ContainerName("http-proxy"), networkName)
+	if err != nil {
+		m.logger.Warning("Proxy cannot join network '%s': %v", networkName, err)
+	} else if connected {
+		m.logger.Info("Connected proxy to network '%s'", networkName)
+	}
+}
+
 func (m *Manager) cleanupNetworks() error {
 	if m.containerRuntime == nil || m.containerRuntime.GetRuntimeName() == "none" {
 
@@ -1483,7 +1298,7 @@ func (m *Manager) cleanupNetworks() error {
 
 	for networkName := range m.networks {
 		// Only clean up networks we created (exclude default ones)
-		if networkName == "mcp-net" || strings.HasPrefix(networkName, "mcp-") {
+		if strings.HasPrefix(networkName, config.Namespaced("mcp-")) {
 			exists, err := m.containerRuntime.NetworkExists(networkName)
 			if err != nil {
 				m.logger.Warning("Failed to check network '%s' during cleanup: %v", networkName, err)
@@ -1527,14 +1342,10 @@ func (m *Manager) Shutdown() error {
 	m.healthCheckers = make(map[string]context.CancelFunc)
 	m.healthCheckMu.Unlock()
 
-	// Stop all resource watchers
 	m.mu.RLock()
 	serverNames := make([]string, 0, len(m.servers))
-	for name, instance := range m.servers {
+	for name := range m.servers {
 		serverNames = append(serverNames, name)
-		if instance.ResourcesWatcher != nil {
-			go instance.ResourcesWatcher.Stop() // Stop in parallel
-		}
 	}
 	m.mu.RUnlock()
 
@@ -1606,6 +1417,9 @@ func (m *Manager) Shutdown() error {
 		close(m.shutdownCh)
 	}
 
+	// Flush any spans still waiting for export
+	m.tracer.Shutdown()
+
 	if stopErr != nil {
 
 		return fmt.Errorf("shutdown completed with errors: %w", stopErr)
@@ -1672,14 +1486,7 @@ func (m *Manager) initializeServerCapabilities(serverName string) error {
 	if instance.SamplingManager != nil && config.IsCapabilityEnabled(instance.Config, "sampling") {
 		// Set up human control configuration if specified
 		if instance.Config.Lifecycle.HumanControl != nil {
-			humanConfig := &protocol.HumanControlConfig{
-				RequireApproval:     instance.Config.Lifecycle.HumanControl.RequireApproval,
-				AutoApprovePatterns: instance.Config.Lifecycle.HumanControl.AutoApprovePatterns,
-				BlockPatterns:       instance.Config.Lifecycle.HumanControl.BlockPatterns,
-				MaxTokens:           instance.Config.Lifecycle.HumanControl.MaxTokens,
-				TimeoutSeconds:      instance.Config.Lifecycle.HumanControl.TimeoutSeconds,
-			}
-			instance.SamplingManager.SetHumanControls(serverName, humanConfig)
+			instance.SamplingManager.SetHumanControls(serverName, humanControlsFor(instance.Config.Lifecycle.HumanControl))
 		}
 	}
 
@@ -1696,6 +1503,52 @@ func (m *Manager) GetServerInstance(serverName string) (*ServerInstance, bool) {
 	return instance, exists
 }
 
+// HealthCheckRecord is the outcome of one health check
+type HealthCheckRecord struct {
+	Time   time.Time `json:"time"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// recordHealthCheck adds the latest health check result to the instance's history,
+// keeping the most recent ones. The manager's lock must be held.
+func (instance *ServerInstance) recordHealthCheck() {
+	instance.HealthHistory = append(instance.HealthHistory, HealthCheckRecord{
+		Time:   instance.LastHealthCheck,
+		Status: instance.HealthStatus,
+		Error:  instance.HealthError,
+	})
+	if excess := len(instance.HealthHistory) - constants.HealthCheckHistorySize; excess > 0 {
+		instance.HealthHistory = append([]HealthCheckRecord(nil), instance.HealthHistory[excess:]...)
+	}
+}
+
+// GetHealthHistory returns the server's recent health check results, oldest first
+func (m *Manager) GetHealthHistory(serverName string) []HealthCheckRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	instance, exists := m.servers[serverName]
+	if !exists {
+
+		return nil
+	}
+
+	return append([]HealthCheckRecord(nil), instance.HealthHistory...)
+}
+
+// GetServerHealth returns the result of the server's last health check
+func (m *Manager) GetServerHealth(serverName string) (status, errText string, checkedAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	instance, exists := m.servers[serverName]
+	if !exists {
+
+		return "unknown", "", time.Time{}
+	}
+
+	return instance.HealthStatus, instance.HealthError, instance.LastHealthCheck
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
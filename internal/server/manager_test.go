@@ -5,8 +5,17 @@ import (
 
 	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/container"
+	"github.com/phildougherty/mcp-compose/internal/logging"
 )
 
+// newTestManager returns a manager running cfg without the runtime NewManager sets up
+func newTestManager(cfg *config.ComposeConfig, logger *logging.Logger, servers map[string]*ServerInstance) *Manager {
+	m := &Manager{logger: logger, servers: servers}
+	m.config.Store(cfg)
+
+	return m
+}
+
 func TestNewManager(t *testing.T) {
 	cfg := &config.ComposeConfig{
 		Version: "1",
@@ -27,7 +36,7 @@ func TestNewManager(t *testing.T) {
 		t.Fatal("Expected manager to be created")
 	}
 
-	if manager.config != cfg {
+	if manager.Config() != cfg {
 		t.Error("Expected config to be set")
 	}
 
@@ -286,3 +295,29 @@ func TestManagerConcurrentAccess(t *testing.T) {
 		t.Errorf("Expected instance name to be 'test-server', got %q", instance.Name)
 	}
 }
+
+func TestManagerConfigSwap(t *testing.T) {
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{"files": {}}}
+	h := &ProxyHandler{Manager: newTestManager(cfg, nil, map[string]*ServerInstance{})}
+
+	// Requests read the configuration while the dashboard edits it
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			h.Manager.SetServerConfig("files", config.ServerConfig{MaxConcurrent: i + 1})
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		_ = h.requestLimitsFor("files")
+		_ = h.getAPIKeyToCheck()
+	}
+	<-done
+
+	if h.Manager.Config() == cfg || cfg.Servers["files"].MaxConcurrent != 0 {
+		t.Error("Expected the configuration to be replaced rather than changed in place")
+	}
+	if h.Manager.Config().Servers["files"].MaxConcurrent != 100 {
+		t.Errorf("Expected the last edit to win, got %+v", h.Manager.Config().Servers["files"])
+	}
+}
@@ -0,0 +1,314 @@
+// internal/server/middleware.go
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+type middlewareContextKey string
+
+const upstreamHeadersContextKey middlewareContextKey = "upstream_headers"
+
+const (
+	defaultRedactReplacement = "[REDACTED]"
+	defaultTraceHeader       = "X-MCP-Trace-Id"
+)
+
+// middlewareCall carries per-request state through a server's middleware chain
+type middlewareCall struct {
+	serverName      string
+	method          string
+	toolName        string
+	payload         map[string]interface{}
+	upstreamHeaders http.Header
+	responseHeaders http.Header
+	incoming        http.Header
+}
+
+// proxyMiddleware is one configured step of the chain
+type proxyMiddleware struct {
+	config   config.MiddlewareConfig
+	patterns []*regexp.Regexp
+}
+
+// buildMiddlewareChain compiles a server's middleware configuration
+func buildMiddlewareChain(configs []config.MiddlewareConfig) ([]*proxyMiddleware, error) {
+	chain := make([]*proxyMiddleware, 0, len(configs))
+	for _, cfg := range configs {
+		mw := &proxyMiddleware{config: cfg}
+		for _, pattern := range cfg.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+
+				return nil, fmt.Errorf("invalid redact pattern '%s': %w", pattern, err)
+			}
+			mw.patterns = append(mw.patterns, re)
+		}
+		chain = append(chain, mw)
+	}
+
+	return chain, nil
+}
+
+// buildMiddlewareChains compiles the middleware of every server of a config, once rather
+// than on each request. Validation rejects bad patterns, so a server whose chain fails to
+// compile here is logged and runs without one.
+func buildMiddlewareChains(cfg *config.ComposeConfig, logger *logging.Logger) map[string][]*proxyMiddleware {
+	chains := make(map[string][]*proxyMiddleware)
+	for serverName, serverConfig := range cfg.Servers {
+		if len(serverConfig.Middleware) == 0 {
+
+			continue
+		}
+		chain, err := buildMiddlewareChain(serverConfig.Middleware)
+		if err != nil {
+			logger.Error("Ignoring middleware for server %s: %v", serverName, err)
+
+			continue
+		}
+		chains[serverName] = chain
+	}
+
+	return chains
+}
+
+// setMiddlewareChains replaces the compiled chains with those of cfg
+func (h *ProxyHandler) setMiddlewareChains(cfg *config.ComposeConfig) {
+	chains := buildMiddlewareChains(cfg, h.logger)
+	h.middlewareMu.Lock()
+	h.middlewareChains = chains
+	h.middlewareMu.Unlock()
+}
+
+// middlewareChainFor returns the compiled chain for a server, or nil when none is configured
+func (h *ProxyHandler) middlewareChainFor(serverName string) []*proxyMiddleware {
+	h.middlewareMu.RLock()
+	defer h.middlewareMu.RUnlock()
+
+	return h.middlewareChains[serverName]
+}
+
+// applies reports whether the step is scoped to this call
+func (mw *proxyMiddleware) applies(call *middlewareCall) bool {
+	if len(mw.config.Methods) > 0 && !contains(mw.config.Methods, call.method) {
+
+		return false
+	}
+	if len(mw.config.Tools) > 0 && (call.method != "tools/call" || !contains(mw.config.Tools, call.toolName)) {
+
+		return false
+	}
+
+	return true
+}
+
+func (mw *proxyMiddleware) processRequest(call *middlewareCall) {
+	switch mw.config.Type {
+	case "headers":
+		for key, value := range mw.config.RequestHeaders {
+			call.upstreamHeaders.Set(key, value)
+		}
+	case "default_args":
+		if call.method != "tools/call" {
+
+			return
+		}
+		params, _ := call.payload["params"].(map[string]interface{})
+		if params == nil {
+			params = make(map[string]interface{})
+			call.payload["params"] = params
+		}
+		arguments, _ := params["arguments"].(map[string]interface{})
+		if arguments == nil {
+			arguments = make(map[string]interface{})
+			params["arguments"] = arguments
+		}
+		for key, value := range mw.config.Arguments {
+			if _, exists := arguments[key]; !exists {
+				arguments[key] = value
+			}
+		}
+	case "trace":
+		header := mw.traceHeader()
+		traceID := call.incoming.Get(header)
+		if traceID == "" {
+			traceID = newTraceID()
+		}
+		call.upstreamHeaders.Set(header, traceID)
+		call.responseHeaders.Set(header, traceID)
+
+		params, _ := call.payload["params"].(map[string]interface{})
+		if params == nil {
+			params = make(map[string]interface{})
+			call.payload["params"] = params
+		}
+		meta, _ := params["_meta"].(map[string]interface{})
+		if meta == nil {
+			meta = make(map[string]interface{})
+			params["_meta"] = meta
+		}
+		meta["traceId"] = traceID
+		meta["proxyServer"] = call.serverName
+		for key, value := range mw.config.Metadata {
+			meta[key] = value
+		}
+	}
+}
+
+func (mw *proxyMiddleware) processResponse(call *middlewareCall, response map[string]interface{}) {
+	switch mw.config.Type {
+	case "headers":
+		for key, value := range mw.config.ResponseHeaders {
+			call.responseHeaders.Set(key, value)
+		}
+	case "redact":
+		if result, exists := response["result"]; exists {
+			response["result"] = mw.redact(result)
+		}
+	}
+}
+
+func (mw *proxyMiddleware) traceHeader() string {
+	if mw.config.Header != "" {
+
+		return mw.config.Header
+	}
+
+	return defaultTraceHeader
+}
+
+// redact walks a decoded JSON value replacing configured fields and pattern matches
+func (mw *proxyMiddleware) redact(value interface{}) interface{} {
+	replacement := mw.config.Replacement
+	if replacement == "" {
+		replacement = defaultRedactReplacement
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if contains(mw.config.Fields, key) {
+				v[key] = replacement
+
+				continue
+			}
+			v[key] = mw.redact(item)
+		}
+
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = mw.redact(item)
+		}
+
+		return v
+	case string:
+		// Tool results often carry JSON documents inside text content
+		var nested interface{}
+		if len(mw.config.Fields) > 0 && json.Unmarshal([]byte(v), &nested) == nil {
+			if _, isObject := nested.(map[string]interface{}); isObject {
+				if encoded, err := json.Marshal(mw.redact(nested)); err == nil {
+					v = string(encoded)
+				}
+			}
+		}
+		for _, re := range mw.patterns {
+			v = re.ReplaceAllString(v, replacement)
+		}
+
+		return v
+	default:
+
+		return v
+	}
+}
+
+// forwardWithMiddleware runs the request half of the chain, forwards the call, then runs
+// the response half in order before writing the result to the client
+func (h *ProxyHandler) forwardWithMiddleware(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance, chain []*proxyMiddleware, requestPayload map[string]interface{}, reqIDVal interface{}, reqMethodVal string) {
+	call := &middlewareCall{
+		serverName:      serverName,
+		method:          reqMethodVal,
+		payload:         requestPayload,
+		upstreamHeaders: make(http.Header),
+		responseHeaders: make(http.Header),
+		incoming:        r.Header,
+	}
+	if params, ok := requestPayload["params"].(map[string]interface{}); ok {
+		call.toolName, _ = params["name"].(string)
+	}
+
+	for _, mw := range chain {
+		if mw.applies(call) {
+			mw.processRequest(call)
+		}
+	}
+
+	body, err := json.Marshal(call.payload)
+	if err != nil {
+		h.logger.Error("Failed to marshal request after middleware for %s: %v", serverName, err)
+		h.sendMCPError(w, reqIDVal, -32603, "Internal error applying middleware")
+
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), upstreamHeadersContextKey, call.upstreamHeaders)
+	recorder := &mcpResponseRecorder{
+		statusCode: http.StatusOK,
+		headers:    make(http.Header),
+	}
+	h.dispatchToTransport(recorder, r.WithContext(ctx), serverName, instance, body, call.payload, reqIDVal, reqMethodVal)
+
+	responseBody := recorder.body
+	var response map[string]interface{}
+	if err := json.Unmarshal(responseBody, &response); err == nil {
+		for _, mw := range chain {
+			if mw.applies(call) {
+				mw.processResponse(call, response)
+			}
+		}
+		if encoded, err := json.Marshal(response); err == nil {
+			responseBody = append(encoded, '\n')
+		}
+	}
+
+	for key, values := range recorder.headers {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	for key, values := range call.responseHeaders {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(responseBody)))
+	w.WriteHeader(recorder.statusCode)
+	_, _ = w.Write(responseBody)
+}
+
+// upstreamHeadersFromContext returns headers middleware asked to add to backend requests
+func upstreamHeadersFromContext(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(upstreamHeadersContextKey).(http.Header)
+
+	return headers
+}
+
+func newTraceID() string {
+	buf := make([]byte, constants.TraceIDBytes)
+	if _, err := rand.Read(buf); err != nil {
+
+		return "unknown"
+	}
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+)
+
+func middlewareStep(t *testing.T, cfg config.MiddlewareConfig) *proxyMiddleware {
+	t.Helper()
+	chain, err := buildMiddlewareChain([]config.MiddlewareConfig{cfg})
+	if err != nil {
+		t.Fatalf("Failed to build middleware: %v", err)
+	}
+
+	return chain[0]
+}
+
+func toolCall(tool string, arguments map[string]interface{}) *middlewareCall {
+	payload := map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]interface{}{"name": tool}}
+	if arguments != nil {
+		payload["params"].(map[string]interface{})["arguments"] = arguments
+	}
+
+	return &middlewareCall{
+		serverName:      "files",
+		method:          "tools/call",
+		toolName:        tool,
+		payload:         payload,
+		upstreamHeaders: make(http.Header),
+		responseHeaders: make(http.Header),
+		incoming:        make(http.Header),
+	}
+}
+
+func TestRedactMiddleware(t *testing.T) {
+	mw := middlewareStep(t, config.MiddlewareConfig{
+		Type:     "redact",
+		Fields:   []string{"password", "token"},
+		Patterns: []string{`sk-[a-z0-9]+`},
+	})
+	response := map[string]interface{}{
+		"result": map[string]interface{}{
+			"content": []interface{}{
+				map[string]interface{}{"type": "text", "text": `{"user":"alice","password":"hunter2","nested":{"token":"abc"}}`},
+				map[string]interface{}{"type": "text", "text": "key is sk-abc123"},
+			},
+			"token": "plain",
+		},
+	}
+	mw.processResponse(toolCall("read", nil), response)
+
+	result := response["result"].(map[string]interface{})
+	if result["token"] != defaultRedactReplacement {
+		t.Errorf("Expected the token field to be redacted, got %v", result["token"])
+	}
+	content := result["content"].([]interface{})
+	var document map[string]interface{}
+	if err := json.Unmarshal([]byte(content[0].(map[string]interface{})["text"].(string)), &document); err != nil {
+		t.Fatalf("Expected the text to stay JSON: %v", err)
+	}
+	if document["user"] != "alice" || document["password"] != defaultRedactReplacement ||
+		document["nested"].(map[string]interface{})["token"] != defaultRedactReplacement {
+		t.Errorf("Expected the fields of the JSON text to be redacted, got %v", document)
+	}
+	if text := content[1].(map[string]interface{})["text"]; text != "key is "+defaultRedactReplacement {
+		t.Errorf("Expected the pattern to be replaced, got %v", text)
+	}
+
+	custom := middlewareStep(t, config.MiddlewareConfig{Type: "redact", Patterns: []string{`\d{4}`}, Replacement: "****"})
+	if redacted := custom.redact("card 1234"); redacted != "card ****" {
+		t.Errorf("Expected the custom replacement, got %v", redacted)
+	}
+}
+
+func TestDefaultArgsMiddleware(t *testing.T) {
+	mw := middlewareStep(t, config.MiddlewareConfig{
+		Type:      "default_args",
+		Tools:     []string{"search"},
+		Arguments: map[string]interface{}{"limit": 10, "safe": true},
+	})
+
+	call := toolCall("search", map[string]interface{}{"limit": 3})
+	if !mw.applies(call) {
+		t.Fatal("Expected the step to apply to its tool")
+	}
+	mw.processRequest(call)
+	arguments := call.payload["params"].(map[string]interface{})["arguments"].(map[string]interface{})
+	if arguments["limit"] != 3 || arguments["safe"] != true {
+		t.Errorf("Expected missing arguments to be added without overriding the client's, got %v", arguments)
+	}
+
+	call = toolCall("search", nil)
+	mw.processRequest(call)
+	if arguments := call.payload["params"].(map[string]interface{})["arguments"]; arguments == nil {
+		t.Error("Expected arguments to be created for a call without any")
+	}
+
+	if mw.applies(toolCall("fetch", nil)) {
+		t.Error("Expected the step not to apply to other tools")
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	mw := middlewareStep(t, config.MiddlewareConfig{Type: "trace", Metadata: map[string]string{"team": "search"}})
+
+	call := toolCall("search", nil)
+	call.incoming.Set(defaultTraceHeader, "trace-1")
+	mw.processRequest(call)
+	meta := call.payload["params"].(map[string]interface{})["_meta"].(map[string]interface{})
+	if meta["traceId"] != "trace-1" || meta["proxyServer"] != "files" || meta["team"] != "search" {
+		t.Errorf("Unexpected trace metadata %v", meta)
+	}
+	if call.upstreamHeaders.Get(defaultTraceHeader) != "trace-1" || call.responseHeaders.Get(defaultTraceHeader) != "trace-1" {
+		t.Error("Expected the client's trace ID to be passed on")
+	}
+
+	call = toolCall("search", nil)
+	mw.processRequest(call)
+	if traceID := call.upstreamHeaders.Get(defaultTraceHeader); traceID == "" || traceID == "trace-1" {
+		t.Errorf("Expected a new trace ID, got %q", traceID)
+	}
+}
+
+func TestMiddlewareChainsBuiltOnce(t *testing.T) {
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"files": {Middleware: []config.MiddlewareConfig{{Type: "redact", Fields: []string{"token"}}}},
+		"plain": {},
+		"bad":   {Middleware: []config.MiddlewareConfig{{Type: "redact", Patterns: []string{"("}}}},
+	}}
+	h := &ProxyHandler{Manager: newTestManager(cfg, nil, nil), logger: logging.NewLogger("error")}
+	h.setMiddlewareChains(cfg)
+
+	if chain := h.middlewareChainFor("files"); len(chain) != 1 {
+		t.Errorf("Expected the chain of files, got %v", chain)
+	}
+	if h.middlewareChainFor("plain") != nil || h.middlewareChainFor("bad") != nil {
+		t.Error("Expected no chain for servers without valid middleware")
+	}
+}
@@ -3,16 +3,25 @@ package server
 
 import (
 	"encoding/json"
-	"github.com/phildougherty/mcp-compose/internal/constants"
-	"github.com/phildougherty/mcp-compose/internal/protocol"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
 )
 
-// handleResourceSubscribe handles resources/subscribe requests
-func (h *ProxyHandler) handleResourceSubscribe(w http.ResponseWriter, r *http.Request, _ string, requestPayload map[string]interface{}) {
+// handleResourceSubscribe handles resources/subscribe requests. The proxy keeps each
+// client's subscriptions and subscribes the server once per resource, however many clients
+// follow it; resources under watched local paths are followed by the proxy itself.
+func (h *ProxyHandler) handleResourceSubscribe(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance, requestPayload map[string]interface{}) {
 	reqIDVal := requestPayload["id"]
+	if !h.authenticateRequest(w, r, serverName, instance) {
+
+		return
+	}
 
 	// Parse subscribe request
 	paramsData, _ := json.Marshal(requestPayload["params"])
@@ -24,6 +33,7 @@ func (h *ProxyHandler) handleResourceSubscribe(w http.ResponseWriter, r *http.Re
 
 		return
 	}
+	subscribeReq.Server = serverName
 
 	// Get client ID from request context or session
 	clientID := h.getClientID(r)
@@ -36,6 +46,7 @@ func (h *ProxyHandler) handleResourceSubscribe(w http.ResponseWriter, r *http.Re
 	}
 
 	// Subscribe to resource changes
+	first := subscribeReq.URI != "" && h.subscriptionManager.CountSubscriptions(serverName, subscribeReq.URI) == 0
 	response, err := h.subscriptionManager.Subscribe(clientID, sessionID, subscribeReq, notifyFunc)
 	if err != nil {
 		h.sendMCPError(w, reqIDVal, protocol.ValidationError, err.Error())
@@ -43,6 +54,17 @@ func (h *ProxyHandler) handleResourceSubscribe(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	watched := hasWatchedResourcePaths(h.Manager.Config().Servers[serverName])
+	if first && !strings.Contains(subscribeReq.URI, "{") {
+		if err := h.sendResourceSubscription(r, serverName, "resources/subscribe", subscribeReq.URI); err != nil && !watched {
+			_, _ = h.subscriptionManager.Unsubscribe(clientID, protocol.UnsubscribeRequest{SubscriptionID: response.SubscriptionID})
+			h.sendMCPError(w, reqIDVal, protocol.InternalError, fmt.Sprintf("Server '%s' did not accept the subscription: %v", serverName, err))
+
+			return
+		}
+	}
+	h.logger.Info("Client %s subscribed to %s on %s", clientID, subscribeReq.URI, serverName)
+
 	// Send success response
 	successResponse := map[string]interface{}{
 		"jsonrpc": "2.0",
@@ -55,8 +77,12 @@ func (h *ProxyHandler) handleResourceSubscribe(w http.ResponseWriter, r *http.Re
 }
 
 // handleResourceUnsubscribe handles resources/unsubscribe requests
-func (h *ProxyHandler) handleResourceUnsubscribe(w http.ResponseWriter, r *http.Request, _ string, requestPayload map[string]interface{}) {
+func (h *ProxyHandler) handleResourceUnsubscribe(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance, requestPayload map[string]interface{}) {
 	reqIDVal := requestPayload["id"]
+	if !h.authenticateRequest(w, r, serverName, instance) {
+
+		return
+	}
 
 	// Parse unsubscribe request
 	paramsData, _ := json.Marshal(requestPayload["params"])
@@ -68,16 +94,19 @@ func (h *ProxyHandler) handleResourceUnsubscribe(w http.ResponseWriter, r *http.
 
 		return
 	}
+	unsubscribeReq.Server = serverName
 
 	// Get client ID
 	clientID := h.getClientID(r)
 
 	// Unsubscribe from resource changes
-	if err := h.subscriptionManager.Unsubscribe(clientID, unsubscribeReq); err != nil {
+	removed, err := h.subscriptionManager.Unsubscribe(clientID, unsubscribeReq)
+	if err != nil {
 		h.sendMCPError(w, reqIDVal, protocol.ValidationError, err.Error())
 
 		return
 	}
+	h.releaseResourceSubscriptions(removed)
 
 	// Send success response
 	successResponse := map[string]interface{}{
@@ -90,6 +119,127 @@ func (h *ProxyHandler) handleResourceUnsubscribe(w http.ResponseWriter, r *http.
 	_ = json.NewEncoder(w).Encode(successResponse)
 }
 
+// sendResourceSubscription sends resources/subscribe or resources/unsubscribe for one URI
+// to a server
+func (h *ProxyHandler) sendResourceSubscription(r *http.Request, serverName, method, uri string) error {
+	instance, exists := h.Manager.GetServerInstance(serverName)
+	if !exists {
+
+		return fmt.Errorf("server '%s' not found", serverName)
+	}
+	reqID := h.getNextRequestID()
+	payload := map[string]interface{}{"jsonrpc": "2.0", "id": reqID, "method": method, "params": map[string]interface{}{"uri": uri}}
+	body, err := json.Marshal(payload)
+	if err != nil {
+
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	recorder := &mcpResponseRecorder{statusCode: http.StatusOK, headers: make(http.Header)}
+	h.dispatchToTransport(recorder, r, serverName, instance, body, payload, reqID, method)
+
+	var response MCPResponse
+	if err := json.Unmarshal(recorder.body, &response); err != nil {
+
+		return fmt.Errorf("invalid %s response (HTTP %d)", method, recorder.statusCode)
+	}
+	if response.Error != nil {
+
+		return fmt.Errorf("%s", response.Error.Message)
+	}
+
+	return nil
+}
+
+// releaseResourceSubscriptions unsubscribes servers from the resources no client follows
+// any more, and stops watching the local paths of servers without subscriptions
+func (h *ProxyHandler) releaseResourceSubscriptions(removed []*protocol.ResourceSubscription) {
+	released := make(map[string]bool)
+	for _, subscription := range removed {
+		key := subscription.Server + "\x00" + subscription.URI
+		if released[key] || subscription.Server == "" || h.subscriptionManager.CountSubscriptions(subscription.Server, subscription.URI) > 0 {
+
+			continue
+		}
+		released[key] = true
+		if !strings.Contains(subscription.URI, "{") {
+			r, err := http.NewRequestWithContext(h.ctx, http.MethodPost, "/"+subscription.Server, nil)
+			if err == nil {
+				err = h.sendResourceSubscription(r, subscription.Server, "resources/unsubscribe", subscription.URI)
+			}
+			if err != nil {
+				h.logger.Debug("Failed to unsubscribe %s from %s: %v", subscription.Server, subscription.URI, err)
+			}
+		}
+	}
+}
+
+// dropClientSubscriptions removes every subscription of a client that went away
+func (h *ProxyHandler) dropClientSubscriptions(clientID string) {
+	removed := h.subscriptionManager.RemoveClient(clientID)
+	if len(removed) > 0 {
+		h.logger.Info("Dropped %d resource subscription(s) of disconnected client %s", len(removed), clientID)
+		h.releaseResourceSubscriptions(removed)
+	}
+}
+
+// clientStreamOpened counts a client's open event streams
+func (h *ProxyHandler) clientStreamOpened(clientID string) {
+	h.clientStreamsMu.Lock()
+	defer h.clientStreamsMu.Unlock()
+
+	if h.clientStreams == nil {
+		h.clientStreams = make(map[string]int)
+	}
+	h.clientStreams[clientID]++
+}
+
+// clientStreamClosed drops a client's subscriptions when it has had no event stream open
+// for the grace period, which leaves time to reconnect
+func (h *ProxyHandler) clientStreamClosed(clientID string) {
+	h.clientStreamsMu.Lock()
+	h.clientStreams[clientID]--
+	if h.clientStreams[clientID] > 0 {
+		h.clientStreamsMu.Unlock()
+
+		return
+	}
+	delete(h.clientStreams, clientID)
+	h.clientStreamsMu.Unlock()
+
+	time.AfterFunc(constants.ResourceSubscriptionGrace, func() {
+		h.clientStreamsMu.Lock()
+		reconnected := h.clientStreams[clientID] > 0
+		h.clientStreamsMu.Unlock()
+		if !reconnected {
+			h.dropClientSubscriptions(clientID)
+		}
+	})
+}
+
+// notifyResourceSubscribers tells the clients subscribed to a server's resource that it changed
+func (h *ProxyHandler) notifyResourceSubscribers(serverName, uri, updateType string) {
+	if uri == "" {
+
+		return
+	}
+	if err := h.subscriptionManager.NotifyServerResourceUpdate(serverName, uri, updateType, nil, map[string]interface{}{"server": serverName}); err != nil {
+		h.logger.Warning("Failed to notify subscribers of %s on %s: %v", uri, serverName, err)
+	}
+}
+
+// hasWatchedResourcePaths reports whether the proxy watches any of a server's resource paths
+func hasWatchedResourcePaths(serverCfg config.ServerConfig) bool {
+	for _, path := range serverCfg.Resources.Paths {
+		if path.Watch {
+
+			return true
+		}
+	}
+
+	return false
+}
+
 // Helper methods
 
 func (h *ProxyHandler) getClientID(r *http.Request) string {
@@ -117,11 +267,26 @@ func (h *ProxyHandler) supportsNotifications(r *http.Request) bool {
 		r.Header.Get("X-Supports-Notifications") == "true"
 }
 
+// sendNotificationToClient queues notifications/resources/updated for one client on the
+// event streams of the servers whose resources changed
 func (h *ProxyHandler) sendNotificationToClient(clientID string, notification *protocol.ResourceUpdateNotification) error {
-	// Implementation depends on your transport mechanism
-	// For HTTP, you might need WebSocket or Server-Sent Events
-	// For now, log the notification
-	h.logger.Info("Would send notification to client %s: %+v", clientID, notification)
+	for _, update := range notification.Params.Resources {
+		serverName, _ := update.Metadata["server"].(string)
+		if serverName == "" {
+
+			continue
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  protocol.NotificationResourcesUpdated,
+			"params":  map[string]interface{}{"uri": update.URI},
+		})
+		if err != nil {
+
+			return fmt.Errorf("failed to marshal resource update: %w", err)
+		}
+		h.eventBufferFor(serverName).publishTo(clientID, data)
+	}
 
 	return nil
 }
@@ -138,6 +303,7 @@ func (h *ProxyHandler) initializeNotificationSupport() {
 	// Managers are already initialized in NewProxyHandler
 	// Start cleanup routine
 	go h.startNotificationCleanup()
+	h.syncResourceWatchers()
 }
 
 func (h *ProxyHandler) startNotificationCleanup() {
@@ -148,7 +314,7 @@ func (h *ProxyHandler) startNotificationCleanup() {
 	for {
 		select {
 		case <-ticker.C:
-			h.subscriptionManager.CleanupExpiredSubscriptions(constants.CleanupIntervalDefault)
+			h.releaseResourceSubscriptions(h.subscriptionManager.CleanupExpiredSubscriptions(constants.CleanupIntervalDefault))
 			h.changeNotificationManager.CleanupInactiveSubscribers(constants.CleanupIntervalDefault)
 		case <-h.ctx.Done():
 
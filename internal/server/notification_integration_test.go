@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestResourceSubscriptions(t *testing.T) {
+	var mu sync.Mutex
+	var backendCalls []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		params, _ := request["params"].(map[string]interface{})
+		w.Header().Set("Content-Type", "application/json")
+		if request["method"] != "ping" {
+			mu.Lock()
+			backendCalls = append(backendCalls, fmt.Sprintf("%v %v", request["method"], params["uri"]))
+			mu.Unlock()
+		}
+		if strings.Contains(fmt.Sprint(params["uri"]), "denied") {
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"error":{"code":-32601,"message":"Method not found"}}`, request["id"])
+
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{}}`, request["id"])
+	}))
+	defer backend.Close()
+	takeCalls := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		calls := backendCalls
+		backendCalls = nil
+
+		return calls
+	}
+
+	dir := t.TempDir()
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{
+		"files": {Protocol: "http"},
+		"local": {Protocol: "http", Resources: config.ResourcesConfig{
+			SyncInterval: "20ms",
+			Paths:        []config.ResourcePath{{Source: dir, Target: "/data", Watch: true}},
+		}},
+	}}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager: newTestManager(cfg, logger, map[string]*ServerInstance{
+			"files": {Name: "files", Config: cfg.Servers["files"]},
+			"local": {Name: "local", Config: cfg.Servers["local"]},
+		}),
+		logger:              logger,
+		ctx:                 context.Background(),
+		httpClient:          http.DefaultClient,
+		catalog:             newCatalogCache(),
+		responses:           newResponseCache(),
+		subscriptionManager: protocol.NewSubscriptionManager(),
+		sseEventBuffers:     make(map[string]*sseEventBuffer),
+		ServerConnections: map[string]*MCPHTTPConnection{
+			"files": {ServerName: "files", BaseURL: backend.URL, Initialized: true, Healthy: true},
+			"local": {ServerName: "local", BaseURL: backend.URL, Initialized: true, Healthy: true},
+		},
+	}
+
+	send := func(client, serverName, method, uri string) MCPResponse {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"%s","params":{"uri":"%s"}}`, method, uri)
+		r := httptest.NewRequest(http.MethodPost, "/"+serverName, strings.NewReader(body))
+		r.Header.Set("X-Client-ID", client)
+		recorder := httptest.NewRecorder()
+		instance, _ := h.Manager.GetServerInstance(serverName)
+		h.handleMCPMethodForwarding(recorder, r, serverName, instance)
+		var response MCPResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: response is not JSON-RPC: %s", method, recorder.Body.String())
+		}
+
+		return response
+	}
+	stream := func(serverName, client string) chan sseEvent {
+		_, events := h.eventBufferFor(serverName).subscribe("", client)
+
+		return events
+	}
+	expectUpdate := func(events chan sseEvent, uri, who string) {
+		select {
+		case event := <-events:
+			if !strings.Contains(string(event.data), `"uri":"`+uri+`"`) || !strings.Contains(string(event.data), protocol.NotificationResourcesUpdated) {
+				t.Errorf("%s: unexpected event %s", who, event.data)
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("%s: no update for %s", who, uri)
+		}
+	}
+	expectNothing := func(events chan sseEvent, who string) {
+		select {
+		case event := <-events:
+			t.Errorf("%s: unexpected event %s", who, event.data)
+		default:
+		}
+	}
+
+	alice, bob, carol := stream("files", "alice"), stream("files", "bob"), stream("files", "carol")
+	if response := send("alice", "files", "resources/subscribe", "file:///data/a.txt"); response.Error != nil {
+		t.Fatalf("subscribe: unexpected error %+v", response.Error)
+	}
+	if response := send("bob", "files", "resources/subscribe", "file:///data/a.txt"); response.Error != nil {
+		t.Fatalf("second subscribe: unexpected error %+v", response.Error)
+	}
+	if calls := takeCalls(); len(calls) != 1 || calls[0] != "resources/subscribe file:///data/a.txt" {
+		t.Errorf("the server should be subscribed once per resource, got %v", calls)
+	}
+
+	update := func(uri string) {
+		h.handleServerNotification("files", map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  protocol.NotificationResourcesUpdated,
+			"params":  map[string]interface{}{"uri": uri},
+		})
+	}
+	update("file:///data/a.txt")
+	expectUpdate(alice, "file:///data/a.txt", "alice")
+	expectUpdate(bob, "file:///data/a.txt", "bob")
+	expectNothing(carol, "unsubscribed client")
+	update("file:///data/other.txt")
+	expectNothing(alice, "update of another resource")
+
+	if response := send("alice", "files", "resources/unsubscribe", "file:///data/a.txt"); response.Error != nil {
+		t.Fatalf("unsubscribe: unexpected error %+v", response.Error)
+	}
+	if calls := takeCalls(); len(calls) != 0 {
+		t.Errorf("the server should stay subscribed while bob follows the resource, got %v", calls)
+	}
+	update("file:///data/a.txt")
+	expectUpdate(bob, "file:///data/a.txt", "bob after alice unsubscribed")
+	expectNothing(alice, "alice after unsubscribing")
+	if response := send("alice", "files", "resources/unsubscribe", "file:///data/a.txt"); response.Error == nil {
+		t.Error("unsubscribing twice should fail")
+	}
+
+	h.dropClientSubscriptions("bob")
+	if calls := takeCalls(); len(calls) != 1 || calls[0] != "resources/unsubscribe file:///data/a.txt" {
+		t.Errorf("the server should be unsubscribed when the last client goes, got %v", calls)
+	}
+
+	if response := send("alice", "files", "resources/subscribe", "file:///denied"); response.Error == nil {
+		t.Error("a subscription the server rejects should fail")
+	}
+	if count := h.subscriptionManager.CountSubscriptions("files", "file:///denied"); count != 0 {
+		t.Errorf("a rejected subscription should not be kept, got %d", count)
+	}
+	takeCalls()
+
+	h.syncResourceWatchers()
+	defer h.stopResourceWatchers()
+	watching := stream("local", "alice")
+	if response := send("alice", "local", "resources/subscribe", "file:///data/denied"); response.Error != nil {
+		t.Fatalf("subscribe to a watched path: unexpected error %+v", response.Error)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "denied"), []byte("changed"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	expectUpdate(watching, "file:///data/denied", "watched path")
+	h.dropClientSubscriptions("alice")
+	h.resourceWatchersMu.Lock()
+	watchers := len(h.resourceWatchers)
+	h.resourceWatchersMu.Unlock()
+	if watchers != 1 {
+		t.Error("watched paths should be followed without subscriptions")
+	}
+}
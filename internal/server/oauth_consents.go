@@ -0,0 +1,86 @@
+// internal/server/oauth_consents.go
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+)
+
+const consentsAPIPath = "/api/oauth/consents"
+
+// handleAuthorize serves the authorization endpoint as the user a trusted reverse proxy
+// signed in, so consents are remembered for them
+func (h *ProxyHandler) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	if handled, ok := h.authenticateTrustedHeaders(w, r); handled && !ok {
+
+		return
+	}
+	h.authServer.HandleAuthorize(w, r)
+}
+
+// handleConsentsAPI serves the scopes users have granted OAuth clients: GET
+// /api/oauth/consents lists them, optionally for ?user=, and DELETE
+// /api/oauth/consents/{user}/{client_id} revokes one along with the tokens it was issued
+func (h *ProxyHandler) handleConsentsAPI(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, consentsAPIPath), "/"), "/")
+	if parts[0] == "" {
+		parts = nil
+	}
+	if len(parts) != 0 && len(parts) != 2 {
+		writeAPIError(w, http.StatusNotFound, "not found")
+
+		return
+	}
+	revoke := len(parts) == 2
+	method, scope := http.MethodGet, AdminReadScope
+	if revoke {
+		method, scope = http.MethodDelete, AdminWriteScope
+	}
+	if r.Method != method {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use "+method)
+
+		return
+	}
+	if !h.scopeGranted(r, scope, AdminWriteScope) {
+		publishAuthDenied(r, "", "consents API scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", scope))
+
+		return
+	}
+
+	if !revoke {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"consents": h.authServer.Consents(r.URL.Query().Get("user"))})
+
+		return
+	}
+
+	user, userErr := url.PathUnescape(parts[0])
+	clientID, clientErr := url.PathUnescape(parts[1])
+	if userErr != nil || clientErr != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid user or client ID")
+
+		return
+	}
+	if err := h.authServer.RevokeConsent(user, clientID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrConsentNotFound) {
+			status = http.StatusNotFound
+		}
+		writeAPIError(w, status, err.Error())
+
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "revoked", "user": user, "client_id": clientID})
+}
@@ -13,14 +13,15 @@ import (
 func (h *ProxyHandler) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
 	// Authentication code
 	apiKeyToCheck := h.APIKey
-	if h.Manager != nil && h.Manager.config != nil && h.Manager.config.ProxyAuth.Enabled {
-		apiKeyToCheck = h.Manager.config.ProxyAuth.APIKey
+	if cfg := h.composeConfig(); cfg != nil && cfg.ProxyAuth.Enabled {
+		apiKeyToCheck = cfg.ProxyAuth.APIKey
 	}
 
-	if apiKeyToCheck != "" {
+	if apiKeyToCheck != "" && !isTrustedHeaderRequest(r) {
 		authHeader := r.Header.Get("Authorization")
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token != apiKeyToCheck {
+			h.authFailed(r, "", "API key mismatch")
 			w.Header().Set("WWW-Authenticate", "Bearer")
 			h.corsError(w, "Unauthorized", http.StatusUnauthorized)
 
@@ -72,7 +73,7 @@ func (h *ProxyHandler) handleOpenAPISpec(w http.ResponseWriter, r *http.Request)
 	paths := make(map[string]interface{})
 
 	// Discover tools from each server and create endpoints
-	for serverName := range h.Manager.config.Servers {
+	for serverName := range h.Manager.Config().Servers {
 		tools, err := h.discoverServerTools(serverName)
 		if err != nil {
 			h.logger.Warning("Failed to discover tools for %s: %v", serverName, err)
@@ -135,9 +136,16 @@ func (h *ProxyHandler) handleOpenAPISpec(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-func (h *ProxyHandler) handleServerOpenAPISpec(w http.ResponseWriter, _ *http.Request, serverName string) {
+// handleServerOpenAPISpec describes a server's tools as the REST endpoints of its tool
+// bridge, for OpenAPI tool clients such as OpenWebUI
+func (h *ProxyHandler) handleServerOpenAPISpec(w http.ResponseWriter, r *http.Request, serverName string) {
 	h.logger.Info("Generating OpenAPI spec for server: %s", serverName)
 
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
 	// Create server-specific OpenAPI spec
 	schema := map[string]interface{}{
 		"openapi": "3.1.0",
@@ -148,7 +156,7 @@ func (h *ProxyHandler) handleServerOpenAPISpec(w http.ResponseWriter, _ *http.Re
 		},
 		"servers": []map[string]interface{}{
 			{
-				"url":         "http://192.168.86.201:9876",
+				"url":         fmt.Sprintf("%s://%s/%s", scheme, r.Host, serverName),
 				"description": serverName + " MCP Server\n\n- [back to tool list](/docs)"},
 		},
 		"paths": map[string]interface{}{},
@@ -178,8 +186,13 @@ func (h *ProxyHandler) handleServerOpenAPISpec(w http.ResponseWriter, _ *http.Re
 
 	paths := make(map[string]interface{})
 
-	// Get tools for this specific server only
-	tools, err := h.discoverServerTools(serverName)
+	// Get the tools this caller may call on this server only
+	var tools []bridgeTool
+	instance, exists := h.Manager.GetServerInstance(serverName)
+	err := fmt.Errorf("server instance not found")
+	if exists {
+		tools, err = h.bridgeTools(r, serverName, instance)
+	}
 	if err != nil {
 		h.logger.Warning("Failed to discover tools for %s: %v", serverName, err)
 		// Return empty spec but still valid
@@ -188,7 +201,7 @@ func (h *ProxyHandler) handleServerOpenAPISpec(w http.ResponseWriter, _ *http.Re
 		h.logger.Info("Discovered %d tools for server %s", len(tools), serverName)
 		// Add tools for this server
 		for _, tool := range tools {
-			toolPath := fmt.Sprintf("/%s", tool.Name)
+			toolPath := fmt.Sprintf("/tools/%s", tool.Name)
 			paths[toolPath] = map[string]interface{}{
 				"post": map[string]interface{}{
 					"summary":     cases.Title(language.English).String(strings.ReplaceAll(tool.Name, "_", " ")),
@@ -199,7 +212,7 @@ func (h *ProxyHandler) handleServerOpenAPISpec(w http.ResponseWriter, _ *http.Re
 						"required": true,
 						"content": map[string]interface{}{
 							"application/json": map[string]interface{}{
-								"schema": tool.Parameters,
+								"schema": tool.InputSchema,
 							},
 						},
 					},
@@ -304,7 +317,7 @@ func (h *ProxyHandler) handleServerDetails(w http.ResponseWriter, r *http.Reques
 		conn.mu.Unlock()
 	} else {
 		connectionStatusDisplay = "○ No Active HTTP Connection via Proxy"
-		if srvCfg, ok := h.Manager.config.Servers[serverName]; ok {
+		if srvCfg, ok := h.Manager.Config().Servers[serverName]; ok {
 			internalURL = h.getServerHTTPURL(serverName, srvCfg)
 		}
 	}
@@ -411,8 +424,8 @@ func (h *ProxyHandler) handleIndex(w http.ResponseWriter, _ *http.Request) {
     <h2>Available MCP Servers:</h2>
     <div class="server-list">`)
 
-	serverNames := make([]string, 0, len(h.Manager.config.Servers))
-	for name := range h.Manager.config.Servers {
+	serverNames := make([]string, 0, len(h.Manager.Config().Servers))
+	for name := range h.Manager.Config().Servers {
 		serverNames = append(serverNames, name)
 	}
 
@@ -466,6 +479,7 @@ func (h *ProxyHandler) handleIndex(w http.ResponseWriter, _ *http.Request) {
             <li><a href="/api/discovery">/api/discovery</a> &ndash; MCP discovery endpoint.</li>
             <li><a href="/api/connections">/api/connections</a> &ndash; Detailed status of active HTTP connections.</li>
             <li><a href="/openapi.json">/openapi.json</a> &ndash; Combined OpenAPI specification.</li>
+            <li><a href="/api/openapi.json">/api/openapi.json</a> &ndash; OpenAPI specification of the management API.</li>
         </ul>
     </div>
     <div style="margin-top: 40px; padding: 25px; background-color: #fff3cd; border-radius: 8px;">
@@ -11,8 +11,11 @@ import (
 	"time"
 
 	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/authlog"
 	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/cors"
+	"github.com/phildougherty/mcp-compose/internal/ipaccess"
 	"github.com/phildougherty/mcp-compose/internal/logging"
 	"github.com/phildougherty/mcp-compose/internal/protocol"
 )
@@ -52,6 +55,44 @@ type ProxyHandler struct {
 	resourceMeta              *auth.ResourceMetadataHandler
 	oauthEnabled              bool
 	connectionManager         *ConnectionManager
+	resourceMirrors           map[string]*resourceMirror
+	resourceMirrorsMu         sync.Mutex
+	aggregatorResourceOwners  map[string]string
+	aggregatorMu              sync.Mutex
+	middlewareChains          map[string][]*proxyMiddleware // compiled middleware by server
+	middlewareMu              sync.RWMutex
+	serverAccess              map[string]serverAccessList // compiled access lists by server
+	serverAccessMu            sync.RWMutex
+	trustedHeaderAuth         *auth.TrustedHeaderAuthenticator
+	corsPolicy                *cors.Policy
+	proxyAccess               *ipaccess.List // nil lets every address in
+	oauthAccess               *ipaccess.List
+	authFailures              *authlog.Log // nil when failed authentications are neither logged nor alerted
+	catalog                   *catalogCache
+	responses                 *responseCache
+	sseEventBuffers           map[string]*sseEventBuffer
+	sseEventBuffersMu         sync.Mutex
+	sseEpoch                  string
+	stdioMuxes                map[string]*stdioMux
+	stdioMuxesMu              sync.Mutex
+	requestQueues             map[string]*requestQueue
+	requestQueuesMu           sync.Mutex
+	sampling                  *protocol.SamplingManager // requests of the sampling broker
+	rootsClients              map[string]*rootsClient   // keyed by server name
+	rootsClientsMu            sync.Mutex
+	clientStreams             map[string]int // open event streams per client
+	clientStreamsMu           sync.Mutex
+	resourceWatchers          map[string]*ResourcesWatcher // watched resource paths by server
+	resourceWatchersMu        sync.Mutex
+	inflight                  map[string]*inflightRequest // cancellable requests by server, client and ID
+	inflightProgress          map[string]*inflightRequest // the same requests by the progress token servers see
+	inflightMu                sync.Mutex
+	nextProgressToken         uint64
+	analytics                 *clientAnalytics     // usage per client since the proxy started
+	costs                     *costLedger          // cost of calls per day and client
+	quotas                    *quotaTracker        // monthly usage of clients against their quotas
+	identitySigner            *auth.IdentitySigner // signs the caller's identity for servers with forward_identity
+	identitySignerMu          sync.Mutex
 }
 
 // ConnectionStats tracks connection performance
@@ -93,9 +134,10 @@ func NewProxyHandler(mgr *Manager, configFile, apiKey string) *ProxyHandler {
 		MaxConnsPerHost:       constants.HTTP2TransportMaxConnsPerHost,
 	}
 
+	cfg := mgr.Config()
 	logLvl := "info"
-	if mgr.config != nil && mgr.config.Logging.Level != "" {
-		logLvl = mgr.config.Logging.Level
+	if cfg != nil && cfg.Logging.Level != "" {
+		logLvl = cfg.Logging.Level
 	}
 	logger := logging.NewLogger(logLvl)
 
@@ -119,12 +161,48 @@ func NewProxyHandler(mgr *Manager, configFile, apiKey string) *ProxyHandler {
 	var resourceMeta *auth.ResourceMetadataHandler
 	var oauthEnabled bool
 
-	if mgr.config.OAuth != nil && mgr.config.OAuth.Enabled {
-		authServer, authMiddleware, resourceMeta = initializeOAuth(mgr.config.OAuth, logger)
+	if cfg.OAuth != nil && cfg.OAuth.Enabled {
+		authServer, authMiddleware, resourceMeta = initializeOAuth(cfg.OAuth, configFile, logger)
 		oauthEnabled = true
+		// Users with a password sign in to authorize clients, so tokens act for them
+		if login := auth.NewUserLogin(cfg.Users, cfg.RBAC); login != nil {
+			authServer.SetUserLogin(login)
+			logger.Info("OAuth authorization requires users to sign in")
+		}
 		logger.Info("OAuth 2.1 authorization server initialized")
 	}
 
+	corsPolicy := cors.New(cfg.CORS, proxyCORSHeaders, proxyCORSExposedHeaders)
+	if corsPolicy.Enabled() {
+		logger.Info("Cross-origin requests allowed from %v", cfg.CORS.AllowedOrigins)
+	}
+	if authServer != nil {
+		authServer.SetCORSPolicy(corsPolicy)
+	}
+
+	proxyAccess, err := ipaccess.New(cfg.ProxyAccess)
+	if err != nil {
+		logger.Error("Ignoring proxy_access: %v", err)
+	}
+	var oauthAccess *ipaccess.List
+	if cfg.OAuth != nil {
+		if oauthAccess, err = ipaccess.New(cfg.OAuth.Access); err != nil {
+			logger.Error("Ignoring oauth.access: %v", err)
+		}
+	}
+
+	authFailures, err := authlog.Open(cfg, configFile, logger)
+	if err != nil {
+		logger.Error("Failed authentications will not be logged: %v", err)
+	}
+
+	trustedHeaderAuth, err := auth.NewTrustedHeaderAuthenticator(cfg.ProxyAuth.TrustedHeaders, cfg.Users, cfg.RBAC)
+	if err != nil {
+		logger.Error("Trusted header authentication disabled: %v", err)
+	} else if trustedHeaderAuth != nil {
+		logger.Info("Trusted header authentication enabled for %v", cfg.ProxyAuth.TrustedHeaders.TrustedProxies)
+	}
+
 	handler := &ProxyHandler{
 		Manager:                mgr,
 		ConfigFile:             configFile,
@@ -135,6 +213,7 @@ func NewProxyHandler(mgr *Manager, configFile, apiKey string) *ProxyHandler {
 		SSEConnections:         make(map[string]*MCPSSEConnection),
 		EnhancedSSEConnections: make(map[string]*EnhancedMCPSSEConnection),
 		StdioConnections:       make(map[string]*MCPSTDIOConnection),
+		stdioMuxes:             make(map[string]*stdioMux),
 		httpClient: &http.Client{
 			Transport: customTransport,
 			Timeout:   constants.HTTPClientTimeout,
@@ -155,12 +234,34 @@ func NewProxyHandler(mgr *Manager, configFile, apiKey string) *ProxyHandler {
 		authMiddleware:            authMiddleware,
 		resourceMeta:              resourceMeta,
 		oauthEnabled:              oauthEnabled,
+		resourceMirrors:           make(map[string]*resourceMirror),
+		trustedHeaderAuth:         trustedHeaderAuth,
+		corsPolicy:                corsPolicy,
+		proxyAccess:               proxyAccess,
+		oauthAccess:               oauthAccess,
+		authFailures:              authFailures,
+		catalog:                   newCatalogCache(),
+		responses:                 newResponseCache(),
+		sseEventBuffers:           make(map[string]*sseEventBuffer),
+		sseEpoch:                  strconv.FormatInt(time.Now().UnixNano(), 36),
+		sampling:                  protocol.NewSamplingManager(),
+		analytics:                 newClientAnalytics(),
+		costs:                     newCostLedger(),
+	}
+
+	quotas, err := newQuotaTracker(quotaStatePath(configFile))
+	if err != nil {
+		logger.Warning("Starting quota usage afresh: %v", err)
 	}
+	handler.quotas = quotas
+	handler.setMiddlewareChains(cfg)
+	handler.setServerAccess(cfg)
 
 	// Initialize connection manager after handler is created
 	handler.connectionManager = NewConnectionManager(handler)
 
 	if oauthEnabled && authServer != nil {
+		authServer.OnFailure(handler.recordOAuthFailure)
 		go handler.startOAuthTokenCleanup()
 		// Register default OAuth clients
 		handler.registerDefaultOAuthClients()
@@ -195,6 +296,11 @@ func (h *ProxyHandler) Shutdown() error {
 	// Close HTTP client connections
 	h.httpClient.CloseIdleConnections()
 
+	// Keep the month's quota usage for the next start
+	if h.quotas != nil {
+		h.quotas.save()
+	}
+
 	// Close HTTP connections
 	h.ConnectionMutex.Lock()
 	for name := range h.ServerConnections {
@@ -237,6 +343,8 @@ func (h *ProxyHandler) Shutdown() error {
 	}
 	h.StdioConnections = make(map[string]*MCPSTDIOConnection)
 	h.StdioMutex.Unlock()
+	h.closeStdioMuxes()
+	h.stopResourceWatchers()
 
 	// CLEANUP NOTIFICATIONS
 	if h.subscriptionManager != nil {
@@ -260,11 +368,9 @@ func (h *ProxyHandler) Shutdown() error {
 	return nil
 }
 
+// corsError writes an error response; ServeHTTP has already set the CORS headers, so
+// browser clients of allowed origins can read it
 func (h *ProxyHandler) corsError(w http.ResponseWriter, message string, code int) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Mcp-Session-Id")
-	w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id, Content-Type")
 	http.Error(w, message, code)
 }
 
@@ -313,12 +419,12 @@ func (h *ProxyHandler) getServerHTTPURL(serverName string, serverConfig config.S
 	if serverName == "task-scheduler" {
 		// Check if it's running as a container or external process
 		if h.isTaskSchedulerContainer() {
-			targetHost = "mcp-compose-task-scheduler"
+			targetHost = config.ContainerName("task-scheduler")
 		} else {
 			targetHost = "localhost" // Running natively
 		}
 	} else {
-		targetHost = fmt.Sprintf("mcp-compose-%s", serverName)
+		targetHost = config.ContainerName(serverName)
 	}
 
 	targetPort := serverConfig.HttpPort
@@ -390,7 +496,7 @@ func (h *ProxyHandler) isTaskSchedulerContainer() bool {
 	}
 
 	// Check if container exists
-	status, err := h.Manager.containerRuntime.GetContainerStatus("mcp-compose-task-scheduler")
+	status, err := h.Manager.containerRuntime.GetContainerStatus(config.ContainerName("task-scheduler"))
 
 	return err == nil && status == "running"
 }
@@ -0,0 +1,366 @@
+// internal/server/quotas.go
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+const quotasAPIPath = "/api/quotas"
+
+// quotaUsage is what a client used of its quota this month
+type quotaUsage struct {
+	Name  string  `json:"name,omitempty"`
+	Calls int64   `json:"calls"`
+	Cost  float64 `json:"cost"`
+}
+
+// quotaState is the usage the proxy keeps on disk, so that a restart does not reset quotas
+type quotaState struct {
+	Month   string                 `json:"month"` // UTC month, as YYYY-MM
+	Clients map[string]*quotaUsage `json:"clients"`
+}
+
+// quotaTracker counts each client's use of its monthly quota. Usage is saved to path, when
+// set, at most every constants.QuotaSaveInterval and when the proxy shuts down.
+type quotaTracker struct {
+	mu       sync.Mutex
+	path     string
+	state    quotaState
+	exceeded map[string]bool // Clients already reported as over their quota this month
+	dirty    bool
+	saved    time.Time
+}
+
+func quotaMonth(t time.Time) string {
+
+	return t.UTC().Format("2006-01")
+}
+
+// quotaResetTime is when the month's quotas run out, at the start of the next UTC month
+func quotaResetTime(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// quotaStatePath is where the proxy for a compose file keeps quota usage
+func quotaStatePath(configFile string) string {
+	if configFile == "" {
+
+		return ""
+	}
+
+	return filepath.Join(filepath.Dir(configFile), constants.QuotaStateFile)
+}
+
+// newQuotaTracker picks up the usage saved at path, if it is for this month
+func newQuotaTracker(path string) (*quotaTracker, error) {
+	tracker := &quotaTracker{
+		path:     path,
+		state:    quotaState{Month: quotaMonth(time.Now()), Clients: make(map[string]*quotaUsage)},
+		exceeded: make(map[string]bool),
+	}
+	if path == "" {
+
+		return tracker, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+
+		return tracker, nil
+	}
+	if err != nil {
+
+		return tracker, fmt.Errorf("failed to read quota usage: %w", err)
+	}
+	var saved quotaState
+	if err := json.Unmarshal(data, &saved); err != nil {
+
+		return tracker, fmt.Errorf("failed to parse quota usage in %s: %w", path, err)
+	}
+	if saved.Month == tracker.state.Month && saved.Clients != nil {
+		tracker.state = saved
+	}
+
+	return tracker, nil
+}
+
+// rollOver starts a new month's usage once the month changes. Callers hold t.mu.
+func (t *quotaTracker) rollOver(now time.Time) {
+	if month := quotaMonth(now); month != t.state.Month {
+		t.state = quotaState{Month: month, Clients: make(map[string]*quotaUsage)}
+		t.exceeded = make(map[string]bool)
+		t.dirty = true
+	}
+}
+
+// usageOf returns a copy of a client's usage this month
+func (t *quotaTracker) usageOf(clientID string) quotaUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollOver(time.Now())
+	if usage := t.state.Clients[clientID]; usage != nil {
+
+		return *usage
+	}
+
+	return quotaUsage{}
+}
+
+// record adds a call, and what it cost, to a client's usage
+func (t *quotaTracker) record(clientID, clientName string, calls int64, cost float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.rollOver(now)
+	usage := t.state.Clients[clientID]
+	if usage == nil {
+		usage = &quotaUsage{}
+		t.state.Clients[clientID] = usage
+	}
+	usage.Name = clientName
+	usage.Calls += calls
+	usage.Cost += cost
+	t.dirty = true
+	if now.Sub(t.saved) >= constants.QuotaSaveInterval {
+		t.saveLocked(now)
+	}
+}
+
+// reset clears the usage of a client, or of every client when clientID is empty, and
+// reports whether there was any
+func (t *quotaTracker) reset(clientID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollOver(time.Now())
+	found := len(t.state.Clients) > 0
+	if clientID == "" {
+		t.state.Clients = make(map[string]*quotaUsage)
+		t.exceeded = make(map[string]bool)
+	} else {
+		_, found = t.state.Clients[clientID]
+		delete(t.state.Clients, clientID)
+		delete(t.exceeded, clientID)
+	}
+	t.dirty = true
+	t.saveLocked(time.Now())
+
+	return found
+}
+
+// firstExceeded reports whether a client went over its quota for the first time this month
+func (t *quotaTracker) firstExceeded(clientID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.exceeded[clientID] {
+
+		return false
+	}
+	t.exceeded[clientID] = true
+
+	return true
+}
+
+// save writes unsaved usage to disk
+func (t *quotaTracker) save() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.saveLocked(time.Now())
+}
+
+func (t *quotaTracker) saveLocked(now time.Time) {
+	if t.path == "" || !t.dirty {
+
+		return
+	}
+	t.saved = now
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), constants.DefaultDirMode); err != nil {
+
+		return
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, constants.DefaultFileMode); err != nil {
+
+		return
+	}
+	if os.Rename(tmp, t.path) == nil {
+		t.dirty = false
+	}
+}
+
+// quotaExceededBy returns which limit a client's usage has reached, if any
+func quotaExceededBy(limits config.QuotaLimits, usage quotaUsage) (string, float64, float64) {
+	if limits.Calls > 0 && usage.Calls >= limits.Calls {
+
+		return "calls", float64(usage.Calls), float64(limits.Calls)
+	}
+	if limits.Cost > 0 && usage.Cost >= limits.Cost {
+
+		return "cost", usage.Cost, limits.Cost
+	}
+
+	return "", 0, 0
+}
+
+// checkQuota refuses a tool call from a client that has used up its monthly quota, with a
+// quota_exceeded error saying which limit it reached and when the quota resets. It writes the
+// error and returns false when the call is refused.
+func (h *ProxyHandler) checkQuota(w http.ResponseWriter, r *http.Request, serverName string, reqIDVal interface{}) bool {
+	clientID, clientName, _ := callerIdentity(r)
+	limits, ok := h.Manager.Config().Quotas.For(clientID)
+	if !ok {
+
+		return true
+	}
+	usage := h.quotas.usageOf(clientID)
+	limit, used, quota := quotaExceededBy(limits, usage)
+	if limit == "" {
+
+		return true
+	}
+
+	resetsAt := quotaResetTime(time.Now())
+	if h.quotas.firstExceeded(clientID) {
+		events.Publish(events.Event{
+			Type:    events.QuotaExceeded,
+			Level:   events.LevelWarn,
+			Server:  serverName,
+			Client:  clientID,
+			Message: fmt.Sprintf("Client %s used up its monthly %s quota (%g of %g)", clientName, limit, used, quota),
+			Details: map[string]interface{}{"limit": limit, "used": used, "quota": quota, "resetsAt": resetsAt},
+		})
+	}
+	h.logger.Warning("Refused tools/call to %s from %s: monthly %s quota used up", serverName, clientID, limit)
+	h.sendMCPError(w, reqIDVal, protocol.QuotaExceeded, "Quota exceeded", map[string]interface{}{
+		"type":     "quota_exceeded",
+		"client":   clientID,
+		"limit":    limit,
+		"used":     used,
+		"quota":    quota,
+		"resetsAt": resetsAt,
+	})
+
+	return false
+}
+
+// clientQuota is the API view of a client's quota and what it used of it
+type clientQuota struct {
+	ID       string              `json:"id"`
+	Name     string              `json:"name,omitempty"`
+	Calls    int64               `json:"calls"`
+	Cost     float64             `json:"cost"`
+	Limits   *config.QuotaLimits `json:"limits,omitempty"`
+	Exceeded string              `json:"exceeded,omitempty"` // The limit the client has reached
+}
+
+// quotaReport lists every client that has a quota or used anything this month
+func (h *ProxyHandler) quotaReport() []clientQuota {
+	quotas := h.Manager.Config().Quotas
+
+	h.quotas.mu.Lock()
+	h.quotas.rollOver(time.Now())
+	usages := make(map[string]quotaUsage, len(h.quotas.state.Clients))
+	for clientID, usage := range h.quotas.state.Clients {
+		usages[clientID] = *usage
+	}
+	h.quotas.mu.Unlock()
+	for clientID := range quotas.Clients {
+		if _, seen := usages[clientID]; !seen {
+			usages[clientID] = quotaUsage{}
+		}
+	}
+
+	report := make([]clientQuota, 0, len(usages))
+	for clientID, usage := range usages {
+		entry := clientQuota{ID: clientID, Name: usage.Name, Calls: usage.Calls, Cost: usage.Cost}
+		if limits, ok := quotas.For(clientID); ok {
+			entry.Limits = &limits
+			entry.Exceeded, _, _ = quotaExceededBy(limits, usage)
+		}
+		report = append(report, entry)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].ID < report[j].ID })
+
+	return report
+}
+
+// handleQuotasAPI serves the monthly quotas: GET /api/quotas lists every client's usage and
+// limits, POST /api/quotas/{client}/reset clears a client's usage and POST
+// /api/quotas/reset clears everyone's
+func (h *ProxyHandler) handleQuotasAPI(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+
+	rest := strings.Trim(strings.TrimPrefix(path, quotasAPIPath), "/")
+	write := rest == "reset" || strings.HasSuffix(rest, "/reset")
+	if rest != "" && !write {
+		writeAPIError(w, http.StatusNotFound, "not found")
+
+		return
+	}
+	method, scope := http.MethodGet, AdminReadScope
+	if write {
+		method, scope = http.MethodPost, AdminWriteScope
+	}
+	if r.Method != method {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use "+method)
+
+		return
+	}
+	if !h.scopeGranted(r, scope, AdminWriteScope) {
+		publishAuthDenied(r, "", "quotas API scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", scope))
+
+		return
+	}
+
+	if write {
+		clientID, err := url.PathUnescape(strings.TrimSuffix(strings.TrimSuffix(rest, "reset"), "/"))
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid client id")
+
+			return
+		}
+		if !h.quotas.reset(clientID) && clientID != "" {
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("client '%s' has not used its quota this month", clientID))
+
+			return
+		}
+		if clientID == "" {
+			h.logger.Info("Reset the monthly quota usage of every client")
+		} else {
+			h.logger.Info("Reset the monthly quota usage of %s", clientID)
+		}
+	}
+
+	now := time.Now()
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"month":    quotaMonth(now),
+		"resetsAt": quotaResetTime(now),
+		"clients":  h.quotaReport(),
+	})
+}
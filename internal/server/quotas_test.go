@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestQuotaEnforcement(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID json.RawMessage `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"content":[]}}`, request.ID)
+	}))
+	defer backend.Close()
+
+	cfg := &config.ComposeConfig{
+		Servers: map[string]config.ServerConfig{
+			"gateway": {Protocol: "http", Pricing: &config.PricingConfig{ToolPricing: config.ToolPricing{PerCall: 0.5}}},
+		},
+		Quotas: config.QuotasConfig{
+			Default: &config.QuotaLimits{Calls: 2},
+			Clients: map[string]config.QuotaLimits{"oauth:agent": {Cost: 1}},
+		},
+	}
+	statePath := filepath.Join(t.TempDir(), "quotas.json")
+	quotas, err := newQuotaTracker(statePath)
+	if err != nil {
+		t.Fatalf("newQuotaTracker: %v", err)
+	}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager: newTestManager(cfg, logger, map[string]*ServerInstance{
+			"gateway": {Name: "gateway", Config: cfg.Servers["gateway"]},
+		}),
+		logger:     logger,
+		ctx:        context.Background(),
+		httpClient: http.DefaultClient,
+		catalog:    newCatalogCache(),
+		responses:  newResponseCache(),
+		analytics:  newClientAnalytics(),
+		costs:      newCostLedger(),
+		quotas:     quotas,
+		ServerConnections: map[string]*MCPHTTPConnection{
+			"gateway": {ServerName: "gateway", BaseURL: backend.URL, Initialized: true, Healthy: true},
+		},
+	}
+
+	agent := context.WithValue(context.WithValue(context.Background(), auth.AuthTypeContextKey, "oauth"),
+		auth.ClientContextKey, &auth.OAuthClient{ID: "agent", ClientName: "Agent"})
+	apiKey := context.WithValue(context.Background(), auth.AuthTypeContextKey, "api_key")
+	call := func(ctx context.Context, method string) map[string]interface{} {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":7,"method":%q,"params":{"name":"search"}}`, method)
+		r := httptest.NewRequest(http.MethodPost, "/gateway", strings.NewReader(body)).WithContext(ctx)
+		recorder := httptest.NewRecorder()
+		instance, _ := h.Manager.GetServerInstance("gateway")
+		h.handleMCPMethodForwarding(recorder, r, "gateway", instance)
+		var response map[string]interface{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		return response
+	}
+	refusal := func(response map[string]interface{}) map[string]interface{} {
+		rpcErr, _ := response["error"].(map[string]interface{})
+		if rpcErr == nil || rpcErr["code"] != float64(protocol.QuotaExceeded) {
+
+			return nil
+		}
+		data, _ := rpcErr["data"].(map[string]interface{})
+
+		return data
+	}
+
+	for i := range 2 {
+		if refused := refusal(call(apiKey, "tools/call")); refused != nil {
+			t.Fatalf("call %d within the default quota was refused: %v", i+1, refused)
+		}
+	}
+	refused := refusal(call(apiKey, "tools/call"))
+	if refused == nil || refused["type"] != "quota_exceeded" || refused["limit"] != "calls" || refused["quota"] != float64(2) || refused["resetsAt"] == nil {
+		t.Fatalf("a third call should exceed the default quota of 2 calls, got %v", refused)
+	}
+	if refused := refusal(call(apiKey, "tools/list")); refused != nil {
+		t.Errorf("only tool calls should count against quotas, got %v", refused)
+	}
+
+	call(agent, "tools/call")
+	call(agent, "tools/call")
+	refused = refusal(call(agent, "tools/call"))
+	if refused == nil || refused["limit"] != "cost" || refused["client"] != "oauth:agent" {
+		t.Fatalf("the agent should run out of its cost quota of 1 after two calls at 0.5, got %v", refused)
+	}
+
+	api := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		h.handleQuotasAPI(recorder, httptest.NewRequest(method, path, nil), path)
+
+		return recorder
+	}
+	var list struct {
+		Month   string        `json:"month"`
+		Clients []clientQuota `json:"clients"`
+	}
+	recorder := api(http.MethodGet, "/api/quotas")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &list); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("quotas API: %d %s", recorder.Code, recorder.Body.String())
+	}
+	if len(list.Clients) != 2 || list.Clients[0].ID != "api_key" || list.Clients[0].Calls != 2 || list.Clients[0].Exceeded != "calls" {
+		t.Errorf("the API key should have used its 2 calls, got %+v", list.Clients)
+	}
+	if agentQuota := list.Clients[1]; agentQuota.Name != "Agent" || agentQuota.Cost != 1 || agentQuota.Limits == nil || agentQuota.Limits.Cost != 1 {
+		t.Errorf("the agent should have used its cost quota, got %+v", agentQuota)
+	}
+
+	if code := api(http.MethodGet, "/api/quotas/oauth:agent/reset").Code; code != http.StatusMethodNotAllowed {
+		t.Errorf("a reset should need POST, got %d", code)
+	}
+	if code := api(http.MethodPost, "/api/quotas/oauth:agent/reset").Code; code != http.StatusOK {
+		t.Fatalf("reset: expected 200, got %d", code)
+	}
+	if refused := refusal(call(agent, "tools/call")); refused != nil {
+		t.Errorf("a reset client should be able to call again, got %v", refused)
+	}
+	if code := api(http.MethodPost, "/api/quotas/oauth:nobody/reset").Code; code != http.StatusNotFound {
+		t.Errorf("resetting an unknown client should be 404, got %d", code)
+	}
+
+	h.quotas.save()
+	reloaded, err := newQuotaTracker(statePath)
+	if err != nil {
+		t.Fatalf("reloading quota usage: %v", err)
+	}
+	if usage := reloaded.usageOf("api_key"); usage.Calls != 2 {
+		t.Errorf("quota usage should survive a restart, got %+v", usage)
+	}
+}
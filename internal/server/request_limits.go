@@ -0,0 +1,257 @@
+// internal/server/request_limits.go
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// requestLimits are a server's configured limits; zero means unlimited
+type requestLimits struct {
+	maxRequestBytes  int64
+	maxResponseBytes int64
+	timeout          time.Duration
+}
+
+// responseTooLargeError reports a server response cut off at the server's limit
+type responseTooLargeError struct {
+	serverName string
+	maxBytes   int64
+}
+
+func (e *responseTooLargeError) Error() string {
+
+	return fmt.Sprintf("response from %s exceeds %d bytes", e.serverName, e.maxBytes)
+}
+
+func (h *ProxyHandler) requestLimitsFor(serverName string) requestLimits {
+	cfg := h.composeConfig()
+	if cfg == nil {
+
+		return requestLimits{}
+	}
+	serverConfig, exists := cfg.Servers[serverName]
+	if !exists {
+
+		return requestLimits{}
+	}
+	maxRequestBytes, maxResponseBytes, timeout := serverConfig.Limits.Values()
+
+	return requestLimits{maxRequestBytes: maxRequestBytes, maxResponseBytes: maxResponseBytes, timeout: timeout}
+}
+
+// readRequestBody reads a request for serverName, answering with a JSON-RPC error and
+// returning false when the body cannot be read or exceeds the server's max_request_bytes
+func (h *ProxyHandler) readRequestBody(w http.ResponseWriter, r *http.Request, serverName string) ([]byte, bool) {
+
+	return h.readBodyWithin(w, r, serverName, "server '"+serverName+"'", h.requestLimitsFor(serverName).maxRequestBytes)
+}
+
+// readBodyWithin reads a request body of at most maxBytes, zero for any size, for target
+func (h *ProxyHandler) readBodyWithin(w http.ResponseWriter, r *http.Request, serverName, target string, maxBytes int64) ([]byte, bool) {
+	reader := r.Body
+	if maxBytes > 0 {
+		reader = http.MaxBytesReader(w, r.Body, maxBytes)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.logger.Warning("Rejected request to %s from %s: body exceeds %d bytes", serverName, getClientIP(r), tooLarge.Limit)
+			h.sendRequestTooLarge(w, nil, target, tooLarge.Limit)
+
+			return nil, false
+		}
+		h.logger.Error("Failed to read request body for %s: %v", serverName, err)
+		h.sendMCPError(w, nil, -32700, "Error reading request body")
+
+		return nil, false
+	}
+
+	return body, true
+}
+
+// sendRequestTooLarge answers a request whose body passed the max_request_bytes of target
+func (h *ProxyHandler) sendRequestTooLarge(w http.ResponseWriter, reqIDVal interface{}, target string, maxBytes int64) {
+	h.sendMCPError(w, reqIDVal, protocol.InvalidRequest,
+		fmt.Sprintf("Request to %s exceeds its limit of %d bytes", target, maxBytes),
+		map[string]interface{}{"limit": "max_request_bytes", "maxBytes": maxBytes})
+}
+
+// aggregatorRequestLimit is the largest max_request_bytes of the servers behind the
+// aggregator, which bounds what it reads before routing; zero when one of them has none
+func (h *ProxyHandler) aggregatorRequestLimit() int64 {
+	cfg := h.composeConfig()
+	if cfg == nil {
+
+		return 0
+	}
+	names := cfg.Aggregator.Servers
+	if len(names) == 0 {
+		for name := range cfg.Servers {
+			names = append(names, name)
+		}
+	}
+	var largest int64
+	for _, name := range names {
+		maxBytes := h.requestLimitsFor(name).maxRequestBytes
+		if maxBytes <= 0 {
+
+			return 0
+		}
+		largest = max(largest, maxBytes)
+	}
+
+	return largest
+}
+
+// readLimitedResponse reads a server's response body, failing once it passes the server's
+// max_response_bytes rather than holding all of it in memory
+func (h *ProxyHandler) readLimitedResponse(serverName string, body io.Reader) ([]byte, error) {
+	maxBytes := h.requestLimitsFor(serverName).maxResponseBytes
+	if maxBytes <= 0 {
+
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+
+		return nil, &responseTooLargeError{serverName: serverName, maxBytes: maxBytes}
+	}
+
+	return data, nil
+}
+
+// sendResponseTooLarge answers a call whose response passed the server's max_response_bytes
+func (h *ProxyHandler) sendResponseTooLarge(w http.ResponseWriter, serverName string, maxBytes int64, reqIDVal interface{}) {
+	h.logger.Warning("Dropped response from %s: exceeds %d bytes", serverName, maxBytes)
+	h.sendMCPError(w, reqIDVal, protocol.RequestFailed,
+		fmt.Sprintf("Response from server '%s' exceeds its limit of %d bytes", serverName, maxBytes),
+		map[string]interface{}{"limit": "max_response_bytes", "maxBytes": maxBytes})
+}
+
+// limitedResponseWriter holds a forwarded response until it is complete, so that it can be
+// replaced by an error if it grows past the server's limit or arrives after the timeout.
+// Large responses are held in a temp file rather than in memory.
+type limitedResponseWriter struct {
+	mu         sync.Mutex
+	header     http.Header
+	statusCode int
+	body       *spillBuffer
+	maxBytes   int64
+	exceeded   bool
+	abandoned  bool
+	err        error
+}
+
+func (lw *limitedResponseWriter) Header() http.Header {
+
+	return lw.header
+}
+
+func (lw *limitedResponseWriter) WriteHeader(statusCode int) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.statusCode == 0 {
+		lw.statusCode = statusCode
+	}
+}
+
+func (lw *limitedResponseWriter) Write(data []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.abandoned || lw.exceeded || lw.err != nil {
+
+		return len(data), nil
+	}
+	if lw.maxBytes > 0 && lw.body.Len()+int64(len(data)) > lw.maxBytes {
+		lw.exceeded = true
+		lw.body.Close()
+
+		return len(data), nil
+	}
+	if _, err := lw.body.Write(data); err != nil {
+		lw.err = err
+		lw.body.Close()
+	}
+
+	return len(data), nil
+}
+
+// Flush is a no-op: the response is only sent once it is complete
+func (lw *limitedResponseWriter) Flush() {}
+
+// serveWithinLimits runs serve against a buffered response, sending it on to w only when it
+// fits in the server's max_response_bytes and finishes within its request_timeout
+func (h *ProxyHandler) serveWithinLimits(w http.ResponseWriter, r *http.Request, serverName string, limits requestLimits, reqIDVal interface{}, serve func(http.ResponseWriter, *http.Request)) {
+	lw := &limitedResponseWriter{
+		header:   make(http.Header),
+		body:     &spillBuffer{threshold: h.spillThresholdFor(serverName)},
+		maxBytes: limits.maxResponseBytes,
+	}
+
+	ctx := r.Context()
+	if limits.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.timeout)
+		defer cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve(lw, r.WithContext(ctx))
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		lw.mu.Lock()
+		lw.abandoned = true
+		lw.body.Close()
+		lw.mu.Unlock()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			h.logger.Warning("Request to %s timed out after %s", serverName, limits.timeout)
+			h.sendMCPError(w, reqIDVal, protocol.RequestTimeout,
+				fmt.Sprintf("Server '%s' did not respond within %s", serverName, limits.timeout),
+				map[string]interface{}{"limit": "request_timeout", "timeout": limits.timeout.String()})
+		}
+
+		return
+	}
+
+	defer lw.body.Close()
+	if lw.exceeded {
+		h.sendResponseTooLarge(w, serverName, limits.maxResponseBytes, reqIDVal)
+
+		return
+	}
+	if lw.err != nil {
+		h.logger.Error("Failed to hold response from %s: %v", serverName, lw.err)
+		h.sendMCPError(w, reqIDVal, protocol.InternalError, fmt.Sprintf("Proxy could not hold the response from server '%s'", serverName))
+
+		return
+	}
+	for key, values := range lw.header {
+		w.Header()[key] = values
+	}
+	if lw.statusCode != 0 {
+		w.WriteHeader(lw.statusCode)
+	}
+	if _, err := lw.body.WriteTo(w); err != nil {
+		h.logger.Error("Failed to send response from %s: %v", serverName, err)
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func limitedHandler(limits *config.RequestLimitsConfig) *ProxyHandler {
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{"files": {Limits: limits}}}
+
+	return &ProxyHandler{Manager: newTestManager(cfg, nil, nil), logger: logging.NewLogger("error")}
+}
+
+func errorCode(t *testing.T, body []byte) int {
+	t.Helper()
+	var response MCPResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("response is not JSON-RPC: %s", body)
+	}
+	if response.Error == nil {
+
+		return 0
+	}
+
+	return response.Error.Code
+}
+
+func TestReadRequestBody(t *testing.T) {
+	h := limitedHandler(&config.RequestLimitsConfig{MaxRequestBytes: "16"})
+
+	tests := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{"within limit", `{"id":1}`, true},
+		{"over limit", `{"id":1,"method":"tools/call"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/files", strings.NewReader(tt.body))
+			body, ok := h.readRequestBody(recorder, request, "files")
+			if ok != tt.ok {
+				t.Fatalf("readRequestBody() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && string(body) != tt.body {
+				t.Errorf("body = %q", body)
+			}
+			if !ok && errorCode(t, recorder.Body.Bytes()) != protocol.InvalidRequest {
+				t.Errorf("unexpected error response: %s", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestServeWithinLimits(t *testing.T) {
+	h := limitedHandler(&config.RequestLimitsConfig{MaxResponseBytes: "32", RequestTimeout: "50ms"})
+	limits := h.requestLimitsFor("files")
+
+	tests := []struct {
+		name     string
+		serve    func(http.ResponseWriter, *http.Request)
+		wantCode int
+	}{
+		{"relayed", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1}`))
+		}, 0},
+		{"too large", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"text":"` + strings.Repeat("x", 64) + `"}}`))
+		}, protocol.RequestFailed},
+		{"timed out", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1}`))
+		}, protocol.RequestTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/files", nil)
+			h.serveWithinLimits(recorder, request, "files", limits, 1, tt.serve)
+			if code := errorCode(t, recorder.Body.Bytes()); code != tt.wantCode {
+				t.Errorf("error code = %d, want %d (%s)", code, tt.wantCode, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestAggregatorRequestLimits(t *testing.T) {
+	cfg := &config.ComposeConfig{
+		Aggregator: config.AggregatorConfig{Enabled: true},
+		Servers: map[string]config.ServerConfig{
+			"files": {Limits: &config.RequestLimitsConfig{MaxRequestBytes: "64"}},
+			"notes": {Limits: &config.RequestLimitsConfig{MaxRequestBytes: "256"}},
+		},
+	}
+	h := &ProxyHandler{Manager: newTestManager(cfg, logging.NewLogger("error"), map[string]*ServerInstance{
+		"files": {Name: "files"},
+		"notes": {Name: "notes"},
+	}), logger: logging.NewLogger("error")}
+	if limit := h.aggregatorRequestLimit(); limit != 256 {
+		t.Errorf("Expected the largest server limit to bound the aggregator, got %d", limit)
+	}
+
+	post := func(body string) []byte {
+		recorder := httptest.NewRecorder()
+		h.handleAggregatorRequest(recorder, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+
+		return recorder.Body.Bytes()
+	}
+	call := func(tool string, size int) string {
+
+		return `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + tool + `","arguments":{"text":"` + strings.Repeat("x", size) + `"}}}`
+	}
+	if code := errorCode(t, post(call("notes__write", 300))); code != protocol.InvalidRequest {
+		t.Errorf("Expected a body over every limit to be refused, got code %d", code)
+	}
+	response := post(call("files__write", 100))
+	if code := errorCode(t, response); code != protocol.InvalidRequest || !strings.Contains(string(response), "server 'files'") {
+		t.Errorf("Expected the call to be held to its server's limit, got %s", response)
+	}
+
+	// A server without a limit leaves the aggregator unbounded
+	cfg.Servers["open"] = config.ServerConfig{}
+	if limit := h.aggregatorRequestLimit(); limit != 0 {
+		t.Errorf("Expected no aggregator limit, got %d", limit)
+	}
+}
@@ -0,0 +1,270 @@
+// internal/server/request_queue.go
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+var errQueueFull = errors.New("request queue is full")
+
+// requestQueue limits how many requests are forwarded to a server at once. Requests past
+// the limit wait, and freed slots go to waiting clients in turn, so a client sending many
+// requests cannot keep the others waiting behind all of them.
+type requestQueue struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	maxQueued     int
+	active        int
+	queued        int
+	waiting       map[string][]*queuedRequest // Waiting requests of each client, oldest first
+	clients       []string                    // Clients with waiting requests, next to be served first
+
+	served    int64
+	rejected  int64
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+type queuedRequest struct {
+	ready    chan struct{}
+	enqueued time.Time
+	granted  bool
+}
+
+// queueStats is what the API reports about a server's queue
+type queueStats struct {
+	MaxConcurrent int     `json:"maxConcurrent"`
+	MaxQueued     int     `json:"maxQueued"`
+	Active        int     `json:"active"`
+	Queued        int     `json:"queued"`
+	Served        int64   `json:"served"`
+	Rejected      int64   `json:"rejected"`
+	AvgWaitMs     float64 `json:"avgWaitMs"`
+	MaxWaitMs     float64 `json:"maxWaitMs"`
+}
+
+func newRequestQueue(maxConcurrent, maxQueued int) *requestQueue {
+	q := &requestQueue{waiting: make(map[string][]*queuedRequest)}
+	q.resize(maxConcurrent, maxQueued)
+
+	return q
+}
+
+// resize applies new limits; requests already forwarded are not affected
+func (q *requestQueue) resize(maxConcurrent, maxQueued int) {
+	if maxQueued <= 0 {
+		maxQueued = maxConcurrent * constants.RequestQueuePerSlot
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.maxConcurrent == maxConcurrent && q.maxQueued == maxQueued {
+
+		return
+	}
+	q.maxConcurrent = maxConcurrent
+	q.maxQueued = maxQueued
+	q.grantLocked()
+}
+
+// acquire waits for a slot for client's request. The returned release must be called once
+// the request is done. It fails with errQueueFull when too many requests are waiting, or
+// with the context's error when ctx ends first.
+func (q *requestQueue) acquire(ctx context.Context, client string) (func(), error) {
+	q.mu.Lock()
+	if q.active < q.maxConcurrent && q.queued == 0 {
+		q.active++
+		q.served++
+		q.mu.Unlock()
+
+		return q.release, nil
+	}
+	if q.queued >= q.maxQueued {
+		q.rejected++
+		q.mu.Unlock()
+
+		return nil, errQueueFull
+	}
+
+	request := &queuedRequest{ready: make(chan struct{}), enqueued: time.Now()}
+	if len(q.waiting[client]) == 0 {
+		q.clients = append(q.clients, client)
+	}
+	q.waiting[client] = append(q.waiting[client], request)
+	q.queued++
+	q.mu.Unlock()
+
+	select {
+	case <-request.ready:
+
+		return q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if request.granted {
+			// The slot arrived as the caller gave up; pass it on
+			q.active--
+			q.grantLocked()
+		} else {
+			q.removeLocked(client, request)
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+func (q *requestQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.active--
+	q.grantLocked()
+}
+
+// grantLocked hands free slots to waiting requests, one client at a time
+func (q *requestQueue) grantLocked() {
+	for q.active < q.maxConcurrent && len(q.clients) > 0 {
+		client := q.clients[0]
+		q.clients = q.clients[1:]
+		requests := q.waiting[client]
+		request := requests[0]
+		if len(requests) > 1 {
+			q.waiting[client] = requests[1:]
+			q.clients = append(q.clients, client)
+		} else {
+			delete(q.waiting, client)
+		}
+
+		wait := time.Since(request.enqueued)
+		q.totalWait += wait
+		if wait > q.maxWait {
+			q.maxWait = wait
+		}
+		q.queued--
+		q.active++
+		q.served++
+		request.granted = true
+		close(request.ready)
+	}
+}
+
+func (q *requestQueue) removeLocked(client string, request *queuedRequest) {
+	requests := q.waiting[client]
+	for i, waiting := range requests {
+		if waiting != request {
+
+			continue
+		}
+		requests = append(requests[:i:i], requests[i+1:]...)
+		q.queued--
+
+		break
+	}
+	if len(requests) > 0 {
+		q.waiting[client] = requests
+
+		return
+	}
+	delete(q.waiting, client)
+	for i, waiting := range q.clients {
+		if waiting == client {
+			q.clients = append(q.clients[:i:i], q.clients[i+1:]...)
+
+			break
+		}
+	}
+}
+
+func (q *requestQueue) stats() queueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := queueStats{
+		MaxConcurrent: q.maxConcurrent,
+		MaxQueued:     q.maxQueued,
+		Active:        q.active,
+		Queued:        q.queued,
+		Served:        q.served,
+		Rejected:      q.rejected,
+		MaxWaitMs:     float64(q.maxWait) / float64(time.Millisecond),
+	}
+	if q.served > 0 {
+		stats.AvgWaitMs = float64(q.totalWait) / float64(q.served) / float64(time.Millisecond)
+	}
+
+	return stats
+}
+
+// requestQueueFor returns the queue of a server with max_concurrent set, or nil
+func (h *ProxyHandler) requestQueueFor(serverName string) *requestQueue {
+	cfg := h.composeConfig()
+	if cfg == nil {
+
+		return nil
+	}
+	serverConfig, exists := cfg.Servers[serverName]
+	if !exists || serverConfig.MaxConcurrent <= 0 {
+
+		return nil
+	}
+
+	h.requestQueuesMu.Lock()
+	defer h.requestQueuesMu.Unlock()
+	if h.requestQueues == nil {
+		h.requestQueues = make(map[string]*requestQueue)
+	}
+	queue, exists := h.requestQueues[serverName]
+	if !exists {
+		queue = newRequestQueue(serverConfig.MaxConcurrent, serverConfig.MaxQueued)
+		h.requestQueues[serverName] = queue
+	} else {
+		queue.resize(serverConfig.MaxConcurrent, serverConfig.MaxQueued)
+	}
+
+	return queue
+}
+
+// requestQueueStats reports the queues of the servers that set max_concurrent
+func (h *ProxyHandler) requestQueueStats() map[string]queueStats {
+	stats := make(map[string]queueStats)
+	for name := range h.Manager.Config().Servers {
+		if queue := h.requestQueueFor(name); queue != nil {
+			stats[name] = queue.stats()
+		}
+	}
+
+	return stats
+}
+
+// waitForSlot holds a request until the server's queue lets it through. When the queue
+// is full or the wait too long it answers with a JSON-RPC error and returns false.
+func (h *ProxyHandler) waitForSlot(w http.ResponseWriter, r *http.Request, queue *requestQueue, serverName string, reqIDVal interface{}) (func(), bool) {
+	ctx, cancel := context.WithTimeout(r.Context(), constants.RequestQueueMaxWait)
+	defer cancel()
+
+	release, err := queue.acquire(ctx, h.getClientID(r))
+	switch {
+	case err == nil:
+
+		return release, true
+	case errors.Is(err, errQueueFull):
+		stats := queue.stats()
+		h.logger.Warning("Rejected request to %s from %s: %d requests already queued", serverName, getClientIP(r), stats.Queued)
+		w.Header().Set("Retry-After", "1")
+		h.sendMCPError(w, reqIDVal, protocol.RateLimitError,
+			fmt.Sprintf("Server '%s' is busy: %d requests running and %d waiting", serverName, stats.Active, stats.Queued),
+			map[string]interface{}{"limit": "max_queued", "maxConcurrent": stats.MaxConcurrent, "maxQueued": stats.MaxQueued})
+	case r.Context().Err() == nil:
+		h.logger.Warning("Request to %s waited %s for a free slot and was dropped", serverName, constants.RequestQueueMaxWait)
+		h.sendMCPError(w, reqIDVal, protocol.RequestTimeout,
+			fmt.Sprintf("Server '%s' stayed busy for %s", serverName, constants.RequestQueueMaxWait),
+			map[string]interface{}{"limit": "max_concurrent"})
+	}
+
+	return nil, false
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestQueueFairness(t *testing.T) {
+	queue := newRequestQueue(1, 4)
+	release, err := queue.acquire(context.Background(), "busy")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	// A chatty client queues three requests before a second client queues one
+	order := make(chan string, 4)
+	enqueue := func(client, label string, queued int) {
+		go func() {
+			done, err := queue.acquire(context.Background(), client)
+			if err != nil {
+				t.Errorf("acquire %s: %v", label, err)
+
+				return
+			}
+			order <- label
+			done()
+		}()
+		waitFor(t, func() bool { return queue.stats().Queued == queued })
+	}
+	enqueue("chatty", "chatty-1", 1)
+	enqueue("chatty", "chatty-2", 2)
+	enqueue("chatty", "chatty-3", 3)
+	enqueue("quiet", "quiet-1", 4)
+
+	if _, err := queue.acquire(context.Background(), "late"); !errors.Is(err, errQueueFull) {
+		t.Errorf("expected a full queue, got %v", err)
+	}
+
+	release()
+	want := []string{"chatty-1", "quiet-1", "chatty-2", "chatty-3"}
+	for i, label := range want {
+		select {
+		case got := <-order:
+			if got != label {
+				t.Errorf("request %d: got %s, want %s", i, got, label)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("request %d never ran", i)
+		}
+	}
+
+	stats := queue.stats()
+	if stats.Active != 0 || stats.Queued != 0 || stats.Served != 5 || stats.Rejected != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRequestQueueCancel(t *testing.T) {
+	queue := newRequestQueue(1, 0)
+	release, _ := queue.acquire(context.Background(), "a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := queue.acquire(ctx, "b"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+	if stats := queue.stats(); stats.Queued != 0 || stats.MaxQueued != 8 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	release()
+	if _, err := queue.acquire(context.Background(), "c"); err != nil {
+		t.Errorf("slot not freed: %v", err)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
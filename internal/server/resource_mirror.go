@@ -0,0 +1,300 @@
+// internal/server/resource_mirror.go
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/objectstore"
+)
+
+const mirrorStatusHeader = "X-MCP-Mirror"
+
+// resourceMirror copies resources/read results for one server into object storage.
+// Objects are content addressed, so identical resources share one stored copy.
+type resourceMirror struct {
+	serverName string
+	store      *objectstore.Client
+	ttl        time.Duration
+	maxSize    int64
+	mu         sync.Mutex
+	entries    map[string]*mirrorEntry  // resource URI -> latest mirrored copy
+	objects    map[string]*mirrorObject // content hash -> stored object
+	totalSize  int64
+}
+
+type mirrorEntry struct {
+	hash     string
+	storedAt time.Time
+}
+
+type mirrorObject struct {
+	key  string
+	size int64
+	refs int
+}
+
+// resourceMirrorFor returns the mirror for a server, or nil when mirroring is not enabled
+func (h *ProxyHandler) resourceMirrorFor(serverName string) *resourceMirror {
+	serverConfig, exists := h.Manager.Config().Servers[serverName]
+	if !exists || serverConfig.ResourceMirror == nil || !serverConfig.ResourceMirror.Enabled {
+
+		return nil
+	}
+
+	h.resourceMirrorsMu.Lock()
+	defer h.resourceMirrorsMu.Unlock()
+
+	if mirror, ok := h.resourceMirrors[serverName]; ok {
+
+		return mirror
+	}
+
+	mirror, err := newResourceMirror(serverName, serverConfig.ResourceMirror, h.Manager.Config().ObjectStorage)
+	if err != nil {
+		h.logger.Error("Resource mirroring disabled for server %s: %v", serverName, err)
+		mirror = nil
+	}
+	h.resourceMirrors[serverName] = mirror
+
+	return mirror
+}
+
+func newResourceMirror(serverName string, cfg *config.ResourceMirrorConfig, storage *config.ObjectStorageConfig) (*resourceMirror, error) {
+	store, err := objectstore.New(storage)
+	if err != nil {
+
+		return nil, err
+	}
+
+	mirror := &resourceMirror{
+		serverName: serverName,
+		store:      store,
+		entries:    make(map[string]*mirrorEntry),
+		objects:    make(map[string]*mirrorObject),
+	}
+	if cfg.TTL != "" {
+		if mirror.ttl, err = time.ParseDuration(cfg.TTL); err != nil {
+
+			return nil, err
+		}
+	}
+	if cfg.MaxSize != "" {
+		if mirror.maxSize, err = config.ParseByteSize(cfg.MaxSize); err != nil {
+
+			return nil, err
+		}
+	}
+
+	return mirror, nil
+}
+
+// readThroughMirror answers resources/read from a fresh mirrored copy when the server has a TTL,
+// otherwise forwards to the server and mirrors a successful result in the background
+func (h *ProxyHandler) readThroughMirror(w http.ResponseWriter, r *http.Request, mirror *resourceMirror, forward func(http.ResponseWriter), requestPayload map[string]interface{}, reqIDVal interface{}) {
+	var uri string
+	if params, ok := requestPayload["params"].(map[string]interface{}); ok {
+		uri, _ = params["uri"].(string)
+	}
+	if uri == "" {
+		forward(w)
+
+		return
+	}
+
+	if result, ok := mirror.lookup(r.Context(), uri); ok {
+		h.logger.Debug("Serving resource %s for %s from mirror", uri, mirror.serverName)
+		response, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      reqIDVal,
+			"result":  result,
+		})
+		if err == nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(mirrorStatusHeader, "hit")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(append(response, '\n'))
+
+			return
+		}
+	}
+
+	recorder := &mcpResponseRecorder{
+		statusCode: http.StatusOK,
+		headers:    make(http.Header),
+	}
+	forward(recorder)
+
+	for key, values := range recorder.headers {
+		w.Header()[key] = values
+	}
+	w.Header().Set(mirrorStatusHeader, "miss")
+	w.WriteHeader(recorder.statusCode)
+	_, _ = w.Write(recorder.body)
+
+	if recorder.statusCode != http.StatusOK {
+
+		return
+	}
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.body, &response); err != nil || len(response.Result) == 0 || len(response.Error) > 0 {
+
+		return
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ctx, cancel := context.WithTimeout(h.ctx, constants.HTTPRequestTimeout)
+		defer cancel()
+		if err := mirror.save(ctx, uri, response.Result); err != nil {
+			h.logger.Warning("Failed to mirror resource %s for %s: %v", uri, mirror.serverName, err)
+		}
+	}()
+}
+
+// lookup returns a mirrored result younger than the TTL
+func (m *resourceMirror) lookup(ctx context.Context, uri string) (json.RawMessage, bool) {
+	if m.ttl <= 0 {
+
+		return nil, false
+	}
+
+	m.mu.Lock()
+	entry, ok := m.entries[uri]
+	var key string
+	if ok && time.Since(entry.storedAt) < m.ttl {
+		key = m.objects[entry.hash].key
+	}
+	m.mu.Unlock()
+
+	if key == "" {
+
+		return nil, false
+	}
+
+	data, err := m.store.Get(ctx, key)
+	if err != nil {
+
+		return nil, false
+	}
+
+	return json.RawMessage(data), true
+}
+
+// save uploads a result under its content hash and records it for the URI, then
+// enforces the server's size budget
+func (m *resourceMirror) save(ctx context.Context, uri string, result json.RawMessage) error {
+	sum := sha256.Sum256(result)
+	hash := hex.EncodeToString(sum[:])
+	size := int64(len(result))
+
+	if m.maxSize > 0 && size > m.maxSize {
+
+		return nil
+	}
+
+	m.mu.Lock()
+	_, exists := m.objects[hash]
+	m.mu.Unlock()
+
+	key := m.store.Key(m.serverName, hash+".json")
+	if !exists {
+		metadata := map[string]string{
+			"Uri":         url.QueryEscape(uri),
+			"Sha256":      hash,
+			"Server":      m.serverName,
+			"Mirrored-At": strconv.FormatInt(time.Now().Unix(), 10),
+		}
+		if err := m.store.Put(ctx, key, result, "application/json", metadata); err != nil {
+
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	if previous, ok := m.entries[uri]; ok {
+		m.release(previous.hash)
+	}
+	object, ok := m.objects[hash]
+	if !ok {
+		object = &mirrorObject{key: key, size: size}
+		m.objects[hash] = object
+		m.totalSize += size
+	}
+	object.refs++
+	m.entries[uri] = &mirrorEntry{hash: hash, storedAt: time.Now()}
+	evicted := m.evict()
+	m.mu.Unlock()
+
+	for _, evictedKey := range evicted {
+		if err := m.store.Delete(ctx, evictedKey); err != nil {
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// release drops a reference to an object; callers must hold m.mu
+func (m *resourceMirror) release(hash string) string {
+	object, ok := m.objects[hash]
+	if !ok {
+
+		return ""
+	}
+	object.refs--
+	if object.refs > 0 {
+
+		return ""
+	}
+	delete(m.objects, hash)
+	m.totalSize -= object.size
+
+	return object.key
+}
+
+// evict removes the oldest entries until the mirror fits its size budget. Expired entries
+// are kept for offline analysis and only stop being served. It returns the object keys that
+// are no longer referenced; callers must hold m.mu
+func (m *resourceMirror) evict() []string {
+	var keys []string
+	if m.maxSize <= 0 || m.totalSize <= m.maxSize {
+
+		return keys
+	}
+
+	uris := make([]string, 0, len(m.entries))
+	for uri := range m.entries {
+		uris = append(uris, uri)
+	}
+	sort.Slice(uris, func(i, j int) bool {
+		return m.entries[uris[i]].storedAt.Before(m.entries[uris[j]].storedAt)
+	})
+	for _, uri := range uris {
+		if m.totalSize <= m.maxSize {
+
+			break
+		}
+		if key := m.release(m.entries[uri].hash); key != "" {
+			keys = append(keys, key)
+		}
+		delete(m.entries, uri)
+	}
+
+	return keys
+}
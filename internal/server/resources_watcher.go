@@ -0,0 +1,347 @@
+// internal/server/resources_watcher.go
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ResourceChange is a change to a file under a watched resource path, as the server sees it
+type ResourceChange struct {
+	Target string // Path under the resource path's target
+	Kind   string // "created", "updated" or "deleted"
+}
+
+// ResourcesWatcher follows a server's resource paths marked watch: true. Changes are collected
+// until the files have been quiet for constants.ResourceChangeDebounce, or for at most the
+// server's sync_interval while they keep changing, and reported together as one batch.
+type ResourcesWatcher struct {
+	config    *config.ServerConfig
+	fsWatcher *fsnotify.Watcher
+	logger    *logging.Logger
+	debounce  time.Duration
+	maxDelay  time.Duration
+	onChange  func(changes []ResourceChange)
+	pending   map[string]fsnotify.Op // Source path -> first change of the batch, owned by the watch loop
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewResourcesWatcher creates a watcher reporting batches of changes to onChange
+func NewResourcesWatcher(cfg *config.ServerConfig, onChange func(changes []ResourceChange), logger *logging.Logger) (*ResourcesWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+
+	maxDelay := constants.SyncIntervalDefault
+	if cfg.Resources.SyncInterval != "" {
+		if parsed, err := time.ParseDuration(cfg.Resources.SyncInterval); err == nil && parsed > 0 {
+			maxDelay = parsed
+		} else {
+			logger.Warning("Invalid resource sync interval '%s', using default %v", cfg.Resources.SyncInterval, maxDelay)
+		}
+	}
+
+	return &ResourcesWatcher{
+		config:    cfg,
+		fsWatcher: watcher,
+		logger:    logger,
+		debounce:  min(constants.ResourceChangeDebounce, maxDelay),
+		maxDelay:  maxDelay,
+		onChange:  onChange,
+		pending:   make(map[string]fsnotify.Op),
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// Start watches every directory under the watched paths and begins reporting changes
+func (w *ResourcesWatcher) Start() {
+	w.startOnce.Do(func() {
+		for _, rp := range w.config.Resources.Paths {
+			if rp.Watch {
+				w.addTree(rp.Source, false)
+			}
+		}
+		go w.run()
+	})
+}
+
+// Stop ends the watch, dropping changes not reported yet
+func (w *ResourcesWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+	w.startOnce.Do(func() {
+		close(w.done)
+		_ = w.fsWatcher.Close()
+	})
+
+	select {
+	case <-w.done:
+		w.logger.Debug("Resource watcher stopped")
+	case <-time.After(constants.LongSleepDuration):
+		w.logger.Warning("Resource watcher stop timeout")
+	}
+}
+
+func (w *ResourcesWatcher) run() {
+	defer close(w.done)
+	defer func() {
+		if err := w.fsWatcher.Close(); err != nil {
+			w.logger.Warning("Failed to close filesystem watcher: %v", err)
+		}
+	}()
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+	var flush <-chan time.Time
+	var batchStarted time.Time
+	for {
+		select {
+		case <-w.stopCh:
+
+			return
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+
+				return
+			}
+			if !w.shouldProcessEvent(event) {
+
+				continue
+			}
+			if len(w.pending) == 0 {
+				batchStarted = time.Now()
+			}
+			w.recordChange(event)
+			// Wait for the files to be quiet, but not past the batch's deadline
+			timer.Reset(max(min(w.debounce, time.Until(batchStarted.Add(w.maxDelay))), 0))
+			flush = timer.C
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+
+				return
+			}
+			w.logger.Error("Watcher error: %v", err)
+		case <-flush:
+			flush = nil
+			w.processChanges()
+		}
+	}
+}
+
+// addTree watches a directory and the directories under it. Files already in a directory
+// that appeared while watching are recorded as created, as their own events came too early.
+func (w *ResourcesWatcher) addTree(root string, appeared bool) {
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			w.logger.Warning("Failed to walk %s for the resource watcher: %v", path, err)
+
+			return nil
+		}
+		if !d.IsDir() {
+			if appeared {
+				w.recordChange(fsnotify.Event{Name: path, Op: fsnotify.Create})
+			}
+
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+
+			return filepath.SkipDir
+		}
+		if addErr := w.fsWatcher.Add(path); addErr != nil {
+			w.logger.Warning("Failed to watch %s: %v", path, addErr)
+		}
+
+		return nil
+	})
+	if err != nil {
+		w.logger.Warning("Failed to watch resource path %s: %v", root, err)
+	}
+}
+
+func (w *ResourcesWatcher) shouldProcessEvent(event fsnotify.Event) bool {
+	if strings.HasPrefix(filepath.Base(event.Name), ".") { // Ignore hidden files/dirs
+
+		return false
+	}
+
+	return event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+}
+
+func (w *ResourcesWatcher) recordChange(event fsnotify.Event) {
+	path := filepath.Clean(event.Name)
+	if _, seen := w.pending[path]; !seen {
+		w.pending[path] = event.Op
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			w.addTree(path, true)
+		}
+	}
+}
+
+// processChanges reports the batch collected so far. A path the batch created is reported as
+// created, or not at all if it is gone again; other paths as updated, or deleted if gone.
+func (w *ResourcesWatcher) processChanges() {
+	paths := make([]string, 0, len(w.pending))
+	for path := range w.pending {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	changes := make([]ResourceChange, 0, len(paths))
+	for _, path := range paths {
+		created := w.pending[path].Has(fsnotify.Create)
+		kind := "updated"
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if created {
+
+				continue
+			}
+			kind = "deleted"
+		} else if err != nil {
+			w.logger.Warning("Error stating changed path %s: %v", path, err)
+
+			continue
+		} else if created {
+			kind = "created"
+		}
+
+		target, mapped := w.targetFor(path)
+		if !mapped {
+			w.logger.Debug("No resource mapping found for changed path: %s", path)
+
+			continue
+		}
+		changes = append(changes, ResourceChange{Target: target, Kind: kind})
+	}
+	w.pending = make(map[string]fsnotify.Op)
+
+	if len(changes) == 0 {
+
+		return
+	}
+	w.logger.Debug("Reporting %d resource change(s) on watched paths", len(changes))
+	if w.onChange != nil {
+		w.onChange(changes)
+	}
+}
+
+// targetFor maps a changed path under a watched source to the path the server sees
+func (w *ResourcesWatcher) targetFor(path string) (string, bool) {
+	for _, rp := range w.config.Resources.Paths {
+		if !rp.Watch {
+
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Clean(rp.Source), path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+
+			continue
+		}
+
+		return filepath.Join(rp.Target, rel), true
+	}
+
+	return "", false
+}
+
+// syncResourceWatchers runs a watcher for every server with watched resource paths, restarting
+// those whose resources configuration changed and stopping those no longer watched
+func (h *ProxyHandler) syncResourceWatchers() {
+	servers := h.Manager.Config().Servers
+
+	h.resourceWatchersMu.Lock()
+	defer h.resourceWatchersMu.Unlock()
+
+	if h.resourceWatchers == nil {
+		h.resourceWatchers = make(map[string]*ResourcesWatcher)
+	}
+	for name, watcher := range h.resourceWatchers {
+		serverCfg, exists := servers[name]
+		if exists && hasWatchedResourcePaths(serverCfg) && reflect.DeepEqual(watcher.config.Resources, serverCfg.Resources) {
+
+			continue
+		}
+		watcher.Stop()
+		delete(h.resourceWatchers, name)
+	}
+	for name, serverCfg := range servers {
+		if _, running := h.resourceWatchers[name]; running || !hasWatchedResourcePaths(serverCfg) {
+
+			continue
+		}
+		watcher, err := NewResourcesWatcher(&serverCfg, func(changes []ResourceChange) {
+			h.deliverResourceChanges(name, changes)
+		}, h.logger)
+		if err != nil {
+			h.logger.Warning("Failed to watch resource paths of %s: %v", name, err)
+
+			continue
+		}
+		watcher.Start()
+		h.resourceWatchers[name] = watcher
+		h.logger.Info("Watching resource paths of %s", name)
+	}
+}
+
+// stopResourceWatchers stops every resource watcher when the proxy shuts down
+func (h *ProxyHandler) stopResourceWatchers() {
+	h.resourceWatchersMu.Lock()
+	defer h.resourceWatchersMu.Unlock()
+
+	for name, watcher := range h.resourceWatchers {
+		watcher.Stop()
+		delete(h.resourceWatchers, name)
+	}
+}
+
+// deliverResourceChanges reports a batch of changes under a server's watched paths to its
+// clients: an update to the clients subscribed to each file, and a single list change to
+// every client when the batch created or deleted files
+func (h *ProxyHandler) deliverResourceChanges(serverName string, changes []ResourceChange) {
+	listChanged := false
+	for _, change := range changes {
+		uri := "file://" + filepath.ToSlash(change.Target)
+		h.observeServerNotification(serverName, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  protocol.NotificationResourcesUpdated,
+			"params":  map[string]interface{}{"uri": uri},
+		})
+		updateType := "updated"
+		if change.Kind == "deleted" {
+			updateType = "deleted"
+		}
+		h.notifyResourceSubscribers(serverName, uri, updateType)
+		listChanged = listChanged || change.Kind != "updated"
+	}
+	if listChanged {
+		h.handleServerNotification(serverName, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  protocol.NotificationResourcesListChanged,
+		})
+	}
+	h.logger.Info("Reported %d change(s) to the watched resources of %s", len(changes), serverName)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestResourcesWatcher(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("notes.txt", "v0")
+	write("old.txt", "old")
+
+	serverCfg := &config.ServerConfig{Resources: config.ResourcesConfig{
+		SyncInterval: "2s",
+		Paths:        []config.ResourcePath{{Source: dir, Target: "/data", Watch: true}},
+	}}
+	batches := make(chan []ResourceChange, 4)
+	watcher, err := NewResourcesWatcher(serverCfg, func(changes []ResourceChange) {
+		batches <- changes
+	}, logging.NewLogger("error"))
+	if err != nil {
+		t.Fatalf("NewResourcesWatcher: %v", err)
+	}
+	watcher.Start()
+	defer watcher.Stop()
+	expectBatch := func(when string, want []ResourceChange) {
+		select {
+		case got := <-batches:
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: got %+v, want %+v", when, got, want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("%s: no changes reported", when)
+		}
+	}
+
+	for i := 1; i <= 5; i++ {
+		write("notes.txt", strings.Repeat("v", i))
+	}
+	write("new.txt", "new")
+	write("scratch.txt", "gone soon")
+	if err := os.Remove(filepath.Join(dir, "scratch.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "old.txt")); err != nil {
+		t.Fatal(err)
+	}
+	write(".hidden", "ignored")
+	expectBatch("rapid changes", []ResourceChange{
+		{Target: "/data/new.txt", Kind: "created"},
+		{Target: "/data/notes.txt", Kind: "updated"},
+		{Target: "/data/old.txt", Kind: "deleted"},
+	})
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	write("sub/inner.txt", "inner")
+	expectBatch("new directory", []ResourceChange{
+		{Target: "/data/sub", Kind: "created"},
+		{Target: "/data/sub/inner.txt", Kind: "created"},
+	})
+	write("sub/inner.txt", "changed")
+	expectBatch("file in a new directory", []ResourceChange{{Target: "/data/sub/inner.txt", Kind: "updated"}})
+
+	select {
+	case extra := <-batches:
+		t.Errorf("unexpected batch %+v", extra)
+	case <-time.After(400 * time.Millisecond):
+	}
+}
+
+func TestDeliverResourceChanges(t *testing.T) {
+	watchedCfg := config.ServerConfig{Resources: config.ResourcesConfig{
+		Paths: []config.ResourcePath{{Source: t.TempDir(), Target: "/data", Watch: true}},
+	}}
+	cfg := &config.ComposeConfig{Servers: map[string]config.ServerConfig{"files": watchedCfg}}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager:             newTestManager(cfg, logger, map[string]*ServerInstance{}),
+		logger:              logger,
+		ctx:                 context.Background(),
+		catalog:             newCatalogCache(),
+		responses:           newResponseCache(),
+		subscriptionManager: protocol.NewSubscriptionManager(),
+		sseEventBuffers:     make(map[string]*sseEventBuffer),
+	}
+	_, events := h.eventBufferFor("files").subscribe("", "alice")
+	expectEvent := func(when, method string) {
+		select {
+		case event := <-events:
+			if !strings.Contains(string(event.data), method) {
+				t.Errorf("%s: expected %s, got %s", when, method, event.data)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s: no %s", when, method)
+		}
+	}
+	expectNothing := func(when string) {
+		select {
+		case event := <-events:
+			t.Errorf("%s: unexpected event %s", when, event.data)
+		default:
+		}
+	}
+
+	h.deliverResourceChanges("files", []ResourceChange{{Target: "/data/a.txt", Kind: "updated"}})
+	expectNothing("update without subscribers")
+	h.deliverResourceChanges("files", []ResourceChange{
+		{Target: "/data/b.txt", Kind: "created"},
+		{Target: "/data/c.txt", Kind: "deleted"},
+	})
+	expectEvent("files added and removed", protocol.NotificationResourcesListChanged)
+	expectNothing("one list change per batch")
+
+	watcherFor := func(serverName string) *ResourcesWatcher {
+		h.resourceWatchersMu.Lock()
+		defer h.resourceWatchersMu.Unlock()
+
+		return h.resourceWatchers[serverName]
+	}
+	h.syncResourceWatchers()
+	first := watcherFor("files")
+	if first == nil {
+		t.Fatal("servers with watched paths should be watched")
+	}
+	h.syncResourceWatchers()
+	if watcherFor("files") != first {
+		t.Error("an unchanged server should keep its watcher")
+	}
+	edited := &config.ComposeConfig{Servers: map[string]config.ServerConfig{"files": {}}}
+	h.Manager.config.Store(edited)
+	h.syncResourceWatchers()
+	if watcherFor("files") != nil {
+		t.Error("the watcher should stop when the server no longer watches its paths")
+	}
+}
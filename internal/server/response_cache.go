@@ -0,0 +1,304 @@
+// internal/server/response_cache.go
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+const responseCacheStatusHeader = "X-MCP-Cache"
+
+// responseCacheInvalidations maps server notifications to the cached methods they make stale
+var responseCacheInvalidations = map[string][]string{
+	protocol.NotificationResourcesListChanged: {"resources/read"},
+	protocol.NotificationPromptsListChanged:   {"prompts/get"},
+}
+
+// responseCache holds results of read-only calls, keyed by server, then by method and a
+// hash of the call's params
+type responseCache struct {
+	mu      sync.Mutex
+	servers map[string]*serverResponseCache
+}
+
+type serverResponseCache struct {
+	entries map[string]*responseCacheEntry
+	order   []string // Keys oldest first, for eviction
+	hits    int64
+	misses  int64
+}
+
+type responseCacheEntry struct {
+	method    string
+	uri       string // For resources/read, so resource updates can drop it
+	result    json.RawMessage
+	expiresAt time.Time
+}
+
+// responseCacheStats is what the API reports about a server's response cache
+type responseCacheStats struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+func newResponseCache() *responseCache {
+
+	return &responseCache{servers: make(map[string]*serverResponseCache)}
+}
+
+func (c *responseCache) server(serverName string) *serverResponseCache {
+	cache, exists := c.servers[serverName]
+	if !exists {
+		cache = &serverResponseCache{entries: make(map[string]*responseCacheEntry)}
+		c.servers[serverName] = cache
+	}
+
+	return cache
+}
+
+func (c *responseCache) get(serverName, key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cache := c.server(serverName)
+	entry, exists := cache.entries[key]
+	if !exists || time.Now().After(entry.expiresAt) {
+		cache.misses++
+
+		return nil, false
+	}
+	cache.hits++
+
+	return entry.result, true
+}
+
+func (c *responseCache) put(serverName, key string, entry *responseCacheEntry, maxEntries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cache := c.server(serverName)
+	if _, exists := cache.entries[key]; !exists {
+		cache.order = append(cache.order, key)
+	}
+	cache.entries[key] = entry
+	for len(cache.entries) > maxEntries && len(cache.order) > 0 {
+		delete(cache.entries, cache.order[0])
+		cache.order = cache.order[1:]
+	}
+}
+
+// invalidate drops a server's entries for the given methods, or all of them when none are given
+func (c *responseCache) invalidate(serverName string, methods ...string) {
+	c.invalidateWhere(serverName, func(entry *responseCacheEntry) bool {
+		if len(methods) == 0 {
+
+			return true
+		}
+		for _, method := range methods {
+			if entry.method == method {
+
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// invalidateURI drops a server's cached reads of one resource
+func (c *responseCache) invalidateURI(serverName, uri string) {
+	c.invalidateWhere(serverName, func(entry *responseCacheEntry) bool {
+
+		return entry.method == "resources/read" && entry.uri == uri
+	})
+}
+
+func (c *responseCache) invalidateWhere(serverName string, stale func(*responseCacheEntry) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cache, exists := c.servers[serverName]
+	if !exists {
+
+		return
+	}
+	order := cache.order[:0]
+	for _, key := range cache.order {
+		if stale(cache.entries[key]) {
+			delete(cache.entries, key)
+
+			continue
+		}
+		order = append(order, key)
+	}
+	cache.order = order
+}
+
+func (c *responseCache) clear() {
+	c.mu.Lock()
+	c.servers = make(map[string]*serverResponseCache)
+	c.mu.Unlock()
+}
+
+func (c *responseCache) stats(serverName string) responseCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cache, exists := c.servers[serverName]
+	if !exists {
+
+		return responseCacheStats{}
+	}
+
+	return responseCacheStats{Entries: len(cache.entries), Hits: cache.hits, Misses: cache.misses}
+}
+
+// responseCacheKey identifies a call by method and params. Params are hashed in their
+// canonical JSON form, leaving out _meta, which carries per-call details such as progress tokens.
+func responseCacheKey(method string, requestPayload map[string]interface{}) (string, bool) {
+	params, _ := requestPayload["params"].(map[string]interface{})
+	canonical := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if key != "_meta" {
+			canonical[key] = value
+		}
+	}
+	data, err := json.Marshal(canonical)
+	if err != nil {
+
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+
+	return method + ":" + hex.EncodeToString(sum[:]), true
+}
+
+// responseCacheFor returns a server's cache settings when it caches method
+func (h *ProxyHandler) responseCacheFor(serverName, method string) (*config.ResponseCacheConfig, time.Duration, bool) {
+	serverConfig, exists := h.Manager.Config().Servers[serverName]
+	if !exists || serverConfig.ResponseCache == nil {
+
+		return nil, 0, false
+	}
+	cacheConfig := serverConfig.ResponseCache
+	ttl, err := time.ParseDuration(cacheConfig.TTL)
+	if err != nil || ttl <= 0 {
+
+		return nil, 0, false
+	}
+	methods := cacheConfig.Methods
+	if len(methods) == 0 {
+		methods = config.CacheableMethods
+	}
+	for _, cached := range methods {
+		if cached == method {
+
+			return cacheConfig, ttl, true
+		}
+	}
+
+	return nil, 0, false
+}
+
+// cacheCapture passes a response through to the client while keeping a copy small enough to cache
+type cacheCapture struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+	overflow   bool
+}
+
+func (cw *cacheCapture) WriteHeader(statusCode int) {
+	if cw.statusCode == 0 {
+		cw.statusCode = statusCode
+	}
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (cw *cacheCapture) Write(data []byte) (int, error) {
+	if cw.statusCode == 0 {
+		cw.statusCode = http.StatusOK
+	}
+	if !cw.overflow {
+		if cw.body.Len()+len(data) > constants.ResponseCacheMaxEntryBytes {
+			cw.overflow = true
+			cw.body.Reset()
+		} else {
+			cw.body.Write(data)
+		}
+	}
+
+	return cw.ResponseWriter.Write(data)
+}
+
+func (cw *cacheCapture) Flush() {
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// callThroughResponseCache answers a read-only call from the cache, or forwards it and caches
+// a successful result. Results are relayed as they arrive, so caching never holds back a
+// streamed resource.
+func (h *ProxyHandler) callThroughResponseCache(w http.ResponseWriter, serverName, method string, cacheConfig *config.ResponseCacheConfig, ttl time.Duration, forward func(http.ResponseWriter), requestPayload map[string]interface{}, reqIDVal interface{}) {
+	key, ok := responseCacheKey(method, requestPayload)
+	if !ok {
+		forward(w)
+
+		return
+	}
+
+	if result, ok := h.responses.get(serverName, key); ok {
+		response, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      reqIDVal,
+			"result":  result,
+		})
+		if err == nil {
+			h.logger.Debug("Serving %s for %s from response cache", method, serverName)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(responseCacheStatusHeader, "hit")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(append(response, '\n'))
+
+			return
+		}
+	}
+
+	w.Header().Set(responseCacheStatusHeader, "miss")
+	capture := &cacheCapture{ResponseWriter: w}
+	forward(capture)
+
+	if capture.statusCode != http.StatusOK || capture.overflow {
+
+		return
+	}
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(capture.body.Bytes(), &response); err != nil || len(response.Result) == 0 || len(response.Error) > 0 {
+
+		return
+	}
+
+	entry := &responseCacheEntry{method: method, result: response.Result, expiresAt: time.Now().Add(ttl)}
+	if params, ok := requestPayload["params"].(map[string]interface{}); ok {
+		entry.uri, _ = params["uri"].(string)
+	}
+	maxEntries := cacheConfig.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = constants.ResponseCacheMaxEntries
+	}
+	h.responses.put(serverName, key, entry, maxEntries)
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestResponseCacheKey(t *testing.T) {
+	call := func(params string) map[string]interface{} {
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(`{"params":`+params+`}`), &payload); err != nil {
+			t.Fatalf("bad params %s: %v", params, err)
+		}
+
+		return payload
+	}
+
+	key, _ := responseCacheKey("prompts/get", call(`{"name":"review","arguments":{"lang":"go","style":"short"}}`))
+	same, _ := responseCacheKey("prompts/get", call(`{"arguments":{"style":"short","lang":"go"},"name":"review","_meta":{"progressToken":3}}`))
+	other, _ := responseCacheKey("prompts/get", call(`{"name":"review","arguments":{"lang":"rust"}}`))
+	if key != same {
+		t.Error("expected reordered params and _meta to share a key")
+	}
+	if key == other {
+		t.Error("expected different arguments to get different keys")
+	}
+}
+
+func TestCallThroughResponseCache(t *testing.T) {
+	h := limitedHandler(nil)
+	h.responses = newResponseCache()
+	h.Manager.Config().Servers["files"] = config.ServerConfig{ResponseCache: &config.ResponseCacheConfig{TTL: "1m", MaxEntries: 2}}
+
+	calls := 0
+	forward := func(w http.ResponseWriter) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"contents":[{"text":"v%d"}]}}`, calls)
+	}
+	read := func(uri string, id int) *httptest.ResponseRecorder {
+		payload := map[string]interface{}{"method": "resources/read", "params": map[string]interface{}{"uri": uri}}
+		cacheConfig, ttl, ok := h.responseCacheFor("files", "resources/read")
+		if !ok {
+			t.Fatal("expected resources/read to be cached")
+		}
+		recorder := httptest.NewRecorder()
+		h.callThroughResponseCache(recorder, "files", "resources/read", cacheConfig, ttl, forward, payload, id)
+
+		return recorder
+	}
+
+	if got := read("file:///a", 1); got.Header().Get(responseCacheStatusHeader) != "miss" {
+		t.Fatalf("first read: expected a miss, got %q", got.Header().Get(responseCacheStatusHeader))
+	}
+	hit := read("file:///a", 2)
+	var response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(hit.Body.Bytes(), &response); err != nil {
+		t.Fatalf("bad cached response %q: %v", hit.Body.String(), err)
+	}
+	if hit.Header().Get(responseCacheStatusHeader) != "hit" || response.ID != 2 || string(response.Result) != `{"contents":[{"text":"v1"}]}` {
+		t.Errorf("expected v1 from the cache with id 2, got %s", hit.Body.String())
+	}
+	if calls != 1 {
+		t.Errorf("expected one forwarded call, got %d", calls)
+	}
+
+	// An update to the resource drops its entry
+	h.observeServerNotification("files", map[string]interface{}{
+		"method": protocol.NotificationResourcesUpdated,
+		"params": map[string]interface{}{"uri": "file:///a"},
+	})
+	if got := read("file:///a", 3); got.Header().Get(responseCacheStatusHeader) != "miss" || calls != 2 {
+		t.Errorf("expected a miss after the update, forwarded %d calls", calls)
+	}
+
+	// Entries past max_entries evict the oldest
+	read("file:///b", 4)
+	read("file:///c", 5)
+	if stats := h.responses.stats("files"); stats.Entries != 2 || stats.Hits != 1 || stats.Misses != 4 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if got := read("file:///a", 6); got.Header().Get(responseCacheStatusHeader) != "miss" {
+		t.Error("expected the oldest entry to be evicted")
+	}
+
+	// A list change drops every cached read
+	h.responses.invalidate("files", responseCacheInvalidations[protocol.NotificationResourcesListChanged]...)
+	if stats := h.responses.stats("files"); stats.Entries != 0 {
+		t.Errorf("expected no entries after a list change, got %d", stats.Entries)
+	}
+
+	if _, _, ok := h.responseCacheFor("files", "tools/call"); ok {
+		t.Error("tools/call must never be cached")
+	}
+}
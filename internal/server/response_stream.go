@@ -0,0 +1,291 @@
+// internal/server/response_stream.go
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/telemetry"
+)
+
+// spillBuffer holds a response in memory up to a threshold and in a temp file past it,
+// so responses the proxy has to hold whole do not all sit in memory
+type spillBuffer struct {
+	threshold int64
+	mem       bytes.Buffer
+	file      *os.File
+	size      int64
+}
+
+func (b *spillBuffer) Write(data []byte) (int, error) {
+	if b.file == nil && int64(b.mem.Len()+len(data)) > b.threshold {
+		file, err := os.CreateTemp("", "mcp-compose-response-*")
+		if err != nil {
+
+			return 0, fmt.Errorf("failed to create spill file: %w", err)
+		}
+		if _, err := b.mem.WriteTo(file); err != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+
+			return 0, fmt.Errorf("failed to write spill file: %w", err)
+		}
+		b.file = file
+	}
+
+	var n int
+	var err error
+	if b.file != nil {
+		n, err = b.file.Write(data)
+	} else {
+		n, err = b.mem.Write(data)
+	}
+	b.size += int64(n)
+
+	return n, err
+}
+
+// Len returns the number of bytes written
+func (b *spillBuffer) Len() int64 {
+
+	return b.size
+}
+
+// WriteTo copies everything written to w
+func (b *spillBuffer) WriteTo(w io.Writer) (int64, error) {
+	if b.file == nil {
+
+		return io.Copy(w, bytes.NewReader(b.mem.Bytes()))
+	}
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+
+		return 0, err
+	}
+
+	return io.CopyBuffer(w, b.file, make([]byte, constants.ResponseStreamChunkSize))
+}
+
+// Close discards the contents and removes the spill file
+func (b *spillBuffer) Close() {
+	b.mem.Reset()
+	b.size = 0
+	if b.file != nil {
+		_ = b.file.Close()
+		_ = os.Remove(b.file.Name())
+		b.file = nil
+	}
+}
+
+func (h *ProxyHandler) spillThresholdFor(serverName string) int64 {
+	cfg := h.composeConfig()
+	if cfg == nil {
+
+		return constants.ResponseSpillThreshold
+	}
+	serverConfig, exists := cfg.Servers[serverName]
+	if !exists || serverConfig.SpillThreshold == "" {
+
+		return constants.ResponseSpillThreshold
+	}
+	threshold, err := config.ParseByteSize(serverConfig.SpillThreshold)
+	if err != nil || threshold <= 0 {
+
+		return constants.ResponseSpillThreshold
+	}
+
+	return threshold
+}
+
+// flushWriter flushes every chunk through to the client as it is written
+type flushWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (fw flushWriter) Write(data []byte) (int, error) {
+	n, err := fw.w.Write(data)
+	if fw.flusher != nil {
+		fw.flusher.Flush()
+	}
+
+	return n, err
+}
+
+// streamHTTPServerRequest forwards a request to an HTTP server and copies the response to
+// the client as it arrives, without holding it in memory. It is used for resources/read,
+// whose results can be far larger than anything else a server returns.
+func (h *ProxyHandler) streamHTTPServerRequest(w http.ResponseWriter, r *http.Request, serverName string, body []byte, reqIDVal interface{}, reqMethodVal string) {
+	conn, err := h.getServerConnection(serverName)
+	if err != nil {
+		h.logger.Error("Failed to get/create HTTP connection for %s: %v", serverName, err)
+		h.sendMCPError(w, reqIDVal, -32002, fmt.Sprintf("Proxy cannot connect to server '%s'", serverName))
+
+		return
+	}
+
+	// The server has until the usual call timeout to start responding; the body may then
+	// take as long as it needs while the client is still there
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	headerTimer := time.AfterFunc(constants.HTTPExtendedTimeout, cancel)
+
+	ctx, span := h.Manager.tracer.StartSpan(ctx, "mcp.http "+reqMethodVal, telemetry.SpanKindClient)
+	span.SetAttribute("mcp.server", serverName)
+	span.SetAttribute("rpc.method", reqMethodVal)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", conn.BaseURL, bytes.NewReader(body))
+	if err != nil {
+		headerTimer.Stop()
+		span.End(err)
+		h.sendMCPError(w, reqIDVal, -32003, fmt.Sprintf("Error during MCP call to '%s'", serverName))
+
+		return
+	}
+	headers, err := h.backendHeaders(ctx, r, serverName)
+	if err != nil {
+		headerTimer.Stop()
+		span.End(err)
+		h.logger.Error("Refusing to forward %s to %s: %v", reqMethodVal, serverName, err)
+		h.sendMCPError(w, reqIDVal, -32603, fmt.Sprintf("Proxy cannot forward the caller's identity to '%s'", serverName))
+
+		return
+	}
+	for key, values := range headers {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	conn.mu.Lock()
+	if clientSessionID := r.Header.Get("Mcp-Session-Id"); clientSessionID != "" && conn.SessionID == "" {
+		conn.SessionID = clientSessionID
+	}
+	if conn.SessionID != "" {
+		httpReq.Header.Set("Mcp-Session-Id", conn.SessionID)
+	}
+	conn.mu.Unlock()
+
+	resp, err := h.sseClient.Do(httpReq)
+	headerTimer.Stop()
+	if err != nil {
+		span.End(err)
+		conn.mu.Lock()
+		conn.Healthy = false
+		conn.mu.Unlock()
+		publishMCPResult(r, serverName, reqMethodVal, "", nil, err)
+		h.logger.Error("MCP request to %s (method: %s) failed: %v", serverName, reqMethodVal, err)
+		h.sendMCPError(w, reqIDVal, -32001, fmt.Sprintf("Server '%s' is unreachable or did not respond in time", serverName),
+			map[string]interface{}{"details": err.Error()})
+
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	conn.mu.Lock()
+	conn.LastUsed = time.Now()
+	conn.Healthy = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		conn.SessionID = sessionID
+	}
+	sessionID := conn.SessionID
+	conn.mu.Unlock()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		errorBody, _ := io.ReadAll(io.LimitReader(resp.Body, constants.ResponseErrorBodyLimit))
+		err := fmt.Errorf("HTTP request to %s failed with status %d: %s", conn.BaseURL, resp.StatusCode, string(errorBody))
+		span.End(err)
+		publishMCPResult(r, serverName, reqMethodVal, "", nil, err)
+		h.logger.Error("MCP request to %s (method: %s) failed: %v", serverName, reqMethodVal, err)
+		h.sendMCPError(w, reqIDVal, -32003, fmt.Sprintf("Error during MCP call to '%s'", serverName),
+			map[string]interface{}{"details": err.Error(), "targetUrl": conn.BaseURL})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if sessionID != "" {
+		w.Header().Set("Mcp-Session-Id", sessionID)
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	out := flushWriter{w: w, flusher: flusher}
+
+	var written int64
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		written, err = copySSEData(out, resp.Body)
+	} else {
+		written, err = io.CopyBuffer(out, resp.Body, make([]byte, constants.ResponseStreamChunkSize))
+	}
+	span.End(err)
+	if err != nil {
+		// The status line is gone; all that is left is to cut the response short
+		publishMCPResult(r, serverName, reqMethodVal, "", nil, err)
+		h.logger.Error("Streaming %s from %s stopped after %d bytes: %v", reqMethodVal, serverName, written, err)
+
+		return
+	}
+
+	publishMCPResult(r, serverName, reqMethodVal, "", nil, nil)
+	h.logger.Info("Streamed %d bytes of %s from %s (ID: %v)", written, reqMethodVal, serverName, reqIDVal)
+}
+
+// copySSEData copies the data of the first event of an SSE response to w, a chunk at a time,
+// so a server answering with text/event-stream is relayed as a plain JSON response
+func copySSEData(w io.Writer, body io.Reader) (int64, error) {
+	reader := bufio.NewReaderSize(body, constants.ResponseStreamChunkSize)
+	var written int64
+	inData := false
+	for {
+		line, err := reader.ReadSlice('\n')
+		switch {
+		case inData:
+			// Continuation of a data line longer than the buffer
+		case bytes.HasPrefix(line, []byte("data:")):
+			inData = true
+			line = bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" "))
+		case len(bytes.TrimSpace(line)) == 0 && written > 0:
+
+			return written, nil
+		default:
+			line = nil
+		}
+
+		complete := len(line) > 0 && line[len(line)-1] == '\n'
+		if inData && len(line) > 0 {
+			n, writeErr := w.Write(bytes.TrimRight(line, "\r\n"))
+			written += int64(n)
+			if writeErr != nil {
+
+				return written, writeErr
+			}
+		}
+		if complete {
+			inData = false
+		}
+
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+		case errors.Is(err, io.EOF):
+			if written == 0 {
+
+				return written, fmt.Errorf("SSE response ended without a data event")
+			}
+
+			return written, nil
+		case err != nil:
+
+			return written, err
+		}
+	}
+}
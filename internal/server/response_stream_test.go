@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestSpillBuffer(t *testing.T) {
+	buffer := &spillBuffer{threshold: 8}
+	for _, chunk := range []string{"0123", "4567", "89abcdef"} {
+		if _, err := buffer.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if buffer.file == nil {
+		t.Fatal("expected the buffer to spill to a file past its threshold")
+	}
+	spillFile := buffer.file.Name()
+
+	var out bytes.Buffer
+	if _, err := buffer.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if out.String() != "0123456789abcdef" || buffer.Len() != 16 {
+		t.Errorf("got %q (%d bytes)", out.String(), buffer.Len())
+	}
+
+	buffer.Close()
+	if _, err := os.Stat(spillFile); !os.IsNotExist(err) {
+		t.Errorf("spill file %s not removed", spillFile)
+	}
+}
+
+func TestCopySSEData(t *testing.T) {
+	large := `{"jsonrpc":"2.0","id":1,"result":{"contents":[{"text":"` + strings.Repeat("x", 200000) + `"}]}}`
+
+	tests := []struct {
+		name    string
+		stream  string
+		want    string
+		wantErr bool
+	}{
+		{"single event", "event: message\ndata: {\"id\":1}\n\n", `{"id":1}`, false},
+		{"data longer than the read buffer", "data: " + large + "\r\n\r\nevent: ignored\n", large, false},
+		{"no data", "event: message\n\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			written, err := copySSEData(&out, strings.NewReader(tt.stream))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("copySSEData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if out.String() != tt.want || written != int64(len(tt.want)) {
+				t.Errorf("copied %d bytes, want %d", written, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestStreamHTTPServerRequest(t *testing.T) {
+	resource := `{"jsonrpc":"2.0","id":7,"result":{"contents":[{"text":"` + strings.Repeat("r", 1<<20) + `"}]}}`
+	sse := false
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		if request["method"] != "resources/read" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{}}`, request["id"])
+
+			return
+		}
+		if sse {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "event: message\ndata: "+resource+"\n\n")
+
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, resource)
+	}))
+	defer backend.Close()
+
+	h := limitedHandler(nil)
+	h.Manager.Config().Servers["files"] = config.ServerConfig{Limits: &config.RequestLimitsConfig{RequestTimeout: "10s"}, SpillThreshold: "64k"}
+	h.ctx = context.Background()
+	h.httpClient = http.DefaultClient
+	h.sseClient = http.DefaultClient
+	h.ServerConnections = map[string]*MCPHTTPConnection{
+		"files": {ServerName: "files", BaseURL: backend.URL, Initialized: true, Healthy: true},
+	}
+
+	for _, useSSE := range []bool{false, true} {
+		sse = useSSE
+		body := []byte(`{"jsonrpc":"2.0","id":7,"method":"resources/read","params":{"uri":"file:///big"}}`)
+		serve := func(w http.ResponseWriter, r *http.Request) {
+			h.streamHTTPServerRequest(w, r, "files", body, 7, "resources/read")
+		}
+
+		direct := httptest.NewRecorder()
+		serve(direct, httptest.NewRequest(http.MethodPost, "/files", nil))
+		if direct.Body.String() != resource {
+			t.Errorf("sse=%v: streamed %d bytes, want %d", useSSE, direct.Body.Len(), len(resource))
+		}
+
+		// Held for the request timeout, the response spills to disk and is relayed intact
+		held := httptest.NewRecorder()
+		h.serveWithinLimits(held, httptest.NewRequest(http.MethodPost, "/files", nil), "files", h.requestLimitsFor("files"), 7, serve)
+		if held.Body.String() != resource {
+			t.Errorf("sse=%v: relayed %d bytes within limits, want %d", useSSE, held.Body.Len(), len(resource))
+		}
+	}
+}
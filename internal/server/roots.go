@@ -0,0 +1,113 @@
+// internal/server/roots.go
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// rootsClient is the client a server's roots/list requests are answered for: the last one
+// to initialize a session with the server through the proxy
+type rootsClient struct {
+	name     string          // X-Client-ID header, or clientInfo.name from initialize
+	declared []protocol.Root // Roots the client sent with initialize, if it has its own
+}
+
+// rootsInitializeParams are the parts of a client's initialize params that decide its roots
+type rootsInitializeParams struct {
+	ClientInfo struct {
+		Name string `json:"name"`
+	} `json:"clientInfo"`
+	Capabilities struct {
+		Roots *struct{} `json:"roots"`
+	} `json:"capabilities"`
+	Roots []protocol.Root `json:"roots"`
+}
+
+// recordRootsClient notes which client initialized a session with a server that uses roots,
+// and tells the server its roots changed when the new client's roots differ
+func (h *ProxyHandler) recordRootsClient(r *http.Request, serverName string, requestPayload map[string]interface{}) {
+	serverCfg, exists := h.Manager.Config().Servers[serverName]
+	if !exists || !config.IsCapabilityEnabled(serverCfg, "roots") {
+
+		return
+	}
+
+	var params rootsInitializeParams
+	data, _ := json.Marshal(requestPayload["params"])
+	_ = json.Unmarshal(data, &params)
+	client := &rootsClient{name: params.ClientInfo.Name}
+	if id := r.Header.Get("X-Client-ID"); id != "" {
+		client.name = id
+	}
+	if params.Capabilities.Roots != nil && len(params.Roots) > 0 {
+		client.declared = params.Roots
+	}
+
+	before := h.rootsFor(serverName)
+	h.rootsClientsMu.Lock()
+	if h.rootsClients == nil {
+		h.rootsClients = make(map[string]*rootsClient)
+	}
+	h.rootsClients[serverName] = client
+	h.rootsClientsMu.Unlock()
+
+	if !reflect.DeepEqual(before, h.rootsFor(serverName)) {
+		h.logger.Info("Roots for %s changed with client '%s'", serverName, client.name)
+		go h.notifyRootsChanged(serverName)
+	}
+}
+
+// rootsFor returns the roots the proxy answers a server's roots/list with: those the
+// server's client declared, or the compose file's roots for that client
+func (h *ProxyHandler) rootsFor(serverName string) []protocol.Root {
+	h.rootsClientsMu.Lock()
+	client := h.rootsClients[serverName]
+	h.rootsClientsMu.Unlock()
+
+	name := ""
+	if client != nil {
+		if len(client.declared) > 0 {
+
+			return client.declared
+		}
+		name = client.name
+	}
+
+	roots := []protocol.Root{}
+	for _, root := range h.Manager.Config().Roots.For(name, config.ActiveProfiles(nil)) {
+		roots = append(roots, protocol.Root{URI: root.URI, Name: root.Name})
+	}
+
+	return roots
+}
+
+// notifyRootsChangedAfterEdit tells every server that uses roots that its roots changed,
+// when an edited configuration changes the roots section
+func (h *ProxyHandler) notifyRootsChangedAfterEdit(previous, updated *config.ComposeConfig) {
+	if reflect.DeepEqual(previous.Roots, updated.Roots) {
+
+		return
+	}
+	for name, serverCfg := range updated.Servers {
+		if config.IsCapabilityEnabled(serverCfg, "roots") {
+			h.notifyRootsChanged(name)
+		}
+	}
+}
+
+// notifyRootsChanged sends notifications/roots/list_changed over the proxy's open sessions
+// with a server. Servers without one ask for their roots when their next session starts.
+func (h *ProxyHandler) notifyRootsChanged(serverName string) {
+	notification := map[string]interface{}{"jsonrpc": "2.0", "method": protocol.NotificationRootsListChanged}
+	if err := h.notifyServer(serverName, notification); err != nil {
+		h.logger.Warning("Failed to tell %s its roots changed: %v", serverName, err)
+
+		return
+	}
+	h.logger.Debug("Told %s its roots changed", serverName)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestRootsForServers(t *testing.T) {
+	t.Setenv("MCP_COMPOSE_PROFILES", "")
+	cfg := &config.ComposeConfig{
+		Servers: map[string]config.ServerConfig{
+			"files": {Command: "cat", Capabilities: []string{"roots"}},
+			"plain": {Command: "cat"},
+		},
+		Roots: config.RootsConfig{
+			Default: []config.RootConfig{{URI: "file:///srv/shared", Name: "shared"}},
+			Clients: map[string][]config.RootConfig{"cursor": {{URI: "file:///home/me/cursor"}}},
+		},
+	}
+	proxySide, serverSide := net.Pipe()
+	defer func() { _ = serverSide.Close() }()
+	mux := &stdioMux{serverName: "files", transport: proxySide, closed: make(chan struct{})}
+	defer mux.shutdown(nil)
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager:    newTestManager(cfg, logger, map[string]*ServerInstance{}),
+		logger:     logger,
+		ctx:        context.Background(),
+		stdioMuxes: map[string]*stdioMux{"files": mux},
+	}
+
+	received := make(chan map[string]interface{}, 4)
+	go func() {
+		scanner := bufio.NewScanner(serverSide)
+		for scanner.Scan() {
+			var message map[string]interface{}
+			_ = json.Unmarshal(scanner.Bytes(), &message)
+			received <- message
+		}
+	}()
+	expectNotification := func(when string) {
+		select {
+		case message := <-received:
+			if message["method"] != protocol.NotificationRootsListChanged {
+				t.Errorf("%s: expected a roots change notification, got %+v", when, message)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s: the server was not told its roots changed", when)
+		}
+	}
+	expectQuiet := func(when string) {
+		select {
+		case message := <-received:
+			t.Errorf("%s: unexpected message %+v", when, message)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	listRoots := func(serverName string) MCPResponse {
+		var response MCPResponse
+		data, _ := json.Marshal(h.answerServerRequest(serverName, map[string]interface{}{"jsonrpc": "2.0", "id": float64(1), "method": "roots/list"}))
+		_ = json.Unmarshal(data, &response)
+
+		return response
+	}
+	initialize := func(header string, params map[string]interface{}) {
+		r := httptest.NewRequest("POST", "/files", nil)
+		if header != "" {
+			r.Header.Set("X-Client-ID", header)
+		}
+		h.recordRootsClient(r, "files", map[string]interface{}{"method": "initialize", "params": params})
+	}
+
+	response := listRoots("files")
+	result, _ := response.Result.(map[string]interface{})
+	if roots, _ := result["roots"].([]interface{}); response.Error != nil || len(roots) != 1 {
+		t.Fatalf("roots/list before any client: expected the default root, got %+v", response)
+	}
+	if response := listRoots("plain"); response.Error == nil || response.Error.Code != protocol.MethodNotFound {
+		t.Errorf("server without the roots capability: expected method not found, got %+v", response)
+	}
+
+	initialize("", map[string]interface{}{"clientInfo": map[string]interface{}{"name": "cursor"}})
+	expectNotification("new client")
+	want := []protocol.Root{{URI: "file:///srv/shared", Name: "shared"}, {URI: "file:///home/me/cursor"}}
+	if got := h.rootsFor("files"); !reflect.DeepEqual(got, want) {
+		t.Errorf("roots for cursor = %+v, want %+v", got, want)
+	}
+	initialize("cursor", map[string]interface{}{"clientInfo": map[string]interface{}{"name": "Cursor IDE"}})
+	expectQuiet("client with the same roots")
+
+	initialize("", map[string]interface{}{
+		"clientInfo":   map[string]interface{}{"name": "editor"},
+		"capabilities": map[string]interface{}{"roots": map[string]interface{}{"listChanged": true}},
+		"roots":        []interface{}{map[string]interface{}{"uri": "file:///work"}},
+	})
+	expectNotification("client with its own roots")
+	if got := h.rootsFor("files"); !reflect.DeepEqual(got, []protocol.Root{{URI: "file:///work"}}) {
+		t.Errorf("a client's own roots should win, got %+v", got)
+	}
+
+	h.notifyRootsChangedAfterEdit(cfg, cfg)
+	expectQuiet("edit without roots changes")
+	edited := *cfg
+	edited.Roots = config.RootsConfig{Default: []config.RootConfig{{URI: "file:///srv/other"}}}
+	h.notifyRootsChangedAfterEdit(cfg, &edited)
+	expectNotification("edited roots")
+
+	if capabilities := h.proxyClientCapabilities("files"); !reflect.DeepEqual(capabilities["roots"], map[string]interface{}{"listChanged": true}) {
+		t.Errorf("servers using roots should be offered roots with list changes, got %+v", capabilities)
+	}
+	if capabilities := h.proxyClientCapabilities("plain"); capabilities["roots"] != nil {
+		t.Error("servers without the roots capability should not be offered roots")
+	}
+}
@@ -0,0 +1,134 @@
+// internal/server/sampling_api.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+const samplingAPIPath = "/api/sampling"
+
+// samplingReview is the body of an approve or deny call. Approvals may edit the request
+// before it goes to the provider.
+type samplingReview struct {
+	Reviewer string `json:"reviewer,omitempty"`
+	Comments string `json:"comments,omitempty"`
+	protocol.SamplingChanges
+}
+
+// handleSamplingAPI serves the sampling broker's approval queue: GET /api/sampling lists
+// requests (?status= narrows them), GET /api/sampling/{id} shows one, and POST
+// /api/sampling/{id}/approve or /deny reviews a request awaiting approval
+func (h *ProxyHandler) handleSamplingAPI(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, samplingAPIPath), "/"), "/")
+	if parts[0] == "" {
+		parts = nil
+	}
+	write := len(parts) == 2
+	if len(parts) > 2 || (write && parts[1] != "approve" && parts[1] != "deny") {
+		writeAPIError(w, http.StatusNotFound, "not found")
+
+		return
+	}
+	method := http.MethodGet
+	if write {
+		method = http.MethodPost
+	}
+	if r.Method != method {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use "+method)
+
+		return
+	}
+	scope := AdminReadScope
+	if write {
+		scope = AdminWriteScope
+	}
+	if !h.scopeGranted(r, scope, AdminWriteScope) {
+		publishAuthDenied(r, "", "sampling API scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", scope))
+
+		return
+	}
+
+	if len(parts) == 0 {
+		status := r.URL.Query().Get("status")
+		requests := make([]*protocol.SamplingRequest, 0)
+		for _, request := range h.sampling.ListRequests() {
+			if status == "" || request.Status == status {
+				requests = append(requests, request)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled":  h.Manager.Config().Sampling.Enabled,
+			"requests": requests,
+		})
+
+		return
+	}
+
+	id := parts[0]
+	request, exists := h.sampling.GetRequest(id)
+	if !exists {
+		writeAPIError(w, http.StatusNotFound, fmt.Sprintf("sampling request '%s' not found", id))
+
+		return
+	}
+	if !write {
+		_ = json.NewEncoder(w).Encode(request)
+
+		return
+	}
+
+	var review samplingReview
+	body, err := io.ReadAll(io.LimitReader(r.Body, constants.SamplingAPIMaxBodySize))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &review); err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("request body must be a JSON object: %v", err))
+
+			return
+		}
+	}
+	for i, message := range review.Messages {
+		if message.Role != "user" && message.Role != "assistant" {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("message %d: role must be user or assistant", i))
+
+			return
+		}
+	}
+	if review.Reviewer == "" {
+		review.Reviewer = getClientIP(r)
+	}
+
+	verdict := "approved"
+	if parts[1] == "approve" {
+		err = h.sampling.ApproveRequestWithChanges(id, review.Reviewer, review.Comments, &review.SamplingChanges)
+	} else {
+		verdict = "denied"
+		err = h.sampling.RejectRequest(id, review.Reviewer, review.Comments)
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusConflict, err.Error())
+
+		return
+	}
+	h.logger.Info("Sampling request %s %s by %s", id, verdict, review.Reviewer)
+	request, _ = h.sampling.GetRequest(id)
+	_ = json.NewEncoder(w).Encode(request)
+}
@@ -0,0 +1,242 @@
+// internal/server/sampling_broker.go
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/events"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+	"github.com/phildougherty/mcp-compose/internal/sampling"
+)
+
+// samplingRejected is the error code MCP clients answer with when the user declines a
+// sampling request
+const samplingRejected = -1
+
+// samplingParams are the params of a sampling/createMessage request
+type samplingParams struct {
+	Messages []struct {
+		Role    string `json:"role"`
+		Content struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			Data     string `json:"data"`
+			MimeType string `json:"mimeType"`
+		} `json:"content"`
+	} `json:"messages"`
+	ModelPreferences struct {
+		Hints []protocol.ModelHint `json:"hints"`
+	} `json:"modelPreferences"`
+	SystemPrompt  string   `json:"systemPrompt"`
+	Temperature   float64  `json:"temperature"`
+	MaxTokens     int      `json:"maxTokens"`
+	StopSequences []string `json:"stopSequences"`
+}
+
+// isServerRequest reports whether a message from a backend is a request to the proxy, as
+// its MCP client, rather than a response or a notification
+func isServerRequest(message map[string]interface{}) bool {
+	_, hasMethod := message["method"]
+	id, hasID := message["id"]
+
+	return hasMethod && hasID && id != nil
+}
+
+// replyToServerRequest answers a request a backend sent to the proxy through send. It can
+// block for as long as a sampling request waits for review.
+func (h *ProxyHandler) replyToServerRequest(serverName string, message map[string]interface{}, send func(map[string]interface{}) error) {
+	if err := send(h.answerServerRequest(serverName, message)); err != nil {
+		h.logger.Warning("Failed to answer %v request from %s: %v", message["method"], serverName, err)
+	}
+}
+
+// answerServerRequest returns the proxy's reply to a request from a backend. The proxy
+// answers pings, roots/list for servers that use roots, and sampling/createMessage when
+// the sampling broker serves the server.
+func (h *ProxyHandler) answerServerRequest(serverName string, message map[string]interface{}) map[string]interface{} {
+	reply := map[string]interface{}{"jsonrpc": "2.0", "id": message["id"]}
+	method, _ := message["method"].(string)
+	switch method {
+	case "ping":
+		reply["result"] = map[string]interface{}{}
+	case protocol.MethodRootsList:
+		serverCfg, exists := h.Manager.Config().Servers[serverName]
+		if !exists || !config.IsCapabilityEnabled(serverCfg, "roots") {
+			reply["error"] = &MCPError{Code: protocol.MethodNotFound, Message: fmt.Sprintf("Roots are not enabled for server '%s'", serverName)}
+		} else {
+			reply["result"] = map[string]interface{}{"roots": h.rootsFor(serverName)}
+		}
+	case "sampling/createMessage":
+		result, mcpErr := h.createSamplingMessage(serverName, message["params"])
+		if mcpErr != nil {
+			reply["error"] = mcpErr
+		} else {
+			reply["result"] = result
+		}
+	default:
+		h.logger.Warning("Rejecting %s request from %s: not supported by the proxy", method, serverName)
+		reply["error"] = &MCPError{Code: protocol.MethodNotFound, Message: fmt.Sprintf("Method not supported by the proxy: %s", method)}
+	}
+
+	return reply
+}
+
+// createSamplingMessage answers a server's sampling/createMessage request with the
+// configured provider, after the server's human controls let it through
+func (h *ProxyHandler) createSamplingMessage(serverName string, rawParams interface{}) (map[string]interface{}, *MCPError) {
+	cfg := h.Manager.Config()
+	serverCfg, exists := cfg.Servers[serverName]
+	if !cfg.Sampling.Enabled || !exists || !config.IsCapabilityEnabled(serverCfg, "sampling") {
+		h.logger.Warning("Rejecting sampling request from %s: sampling is not enabled for it", serverName)
+
+		return nil, &MCPError{Code: protocol.MethodNotFound, Message: fmt.Sprintf("Sampling is not enabled for server '%s'", serverName)}
+	}
+
+	var params samplingParams
+	data, _ := json.Marshal(rawParams)
+	if err := json.Unmarshal(data, &params); err != nil || len(params.Messages) == 0 {
+
+		return nil, &MCPError{Code: protocol.InvalidParams, Message: "sampling/createMessage needs a list of messages"}
+	}
+	provider, err := sampling.NewProvider(cfg.Sampling)
+	if err != nil {
+
+		return nil, &MCPError{Code: protocol.InternalError, Message: err.Error()}
+	}
+
+	controls := humanControlsFor(serverCfg.Lifecycle.HumanControl)
+	request := &protocol.SamplingRequest{
+		ServerName:   serverName,
+		SystemPrompt: params.SystemPrompt,
+		Model:        sampling.ChooseModel(params.ModelPreferences.Hints, controls.AllowedModels, serverCfg.Sampling.Models, cfg.Sampling.Model),
+		ModelPrefs:   protocol.ModelPreferences{Hints: params.ModelPreferences.Hints},
+		MaxTokens:    params.MaxTokens,
+		StopSequence: params.StopSequences,
+		Temperature:  params.Temperature,
+	}
+	for _, message := range params.Messages {
+		request.Messages = append(request.Messages, protocol.SamplingMessage{
+			Role: message.Role,
+			Content: protocol.SamplingContent{
+				Type:      message.Content.Type,
+				Text:      message.Content.Text,
+				ImageData: message.Content.Data,
+				MimeType:  message.Content.MimeType,
+			},
+		})
+	}
+
+	h.sampling.CleanupOldRequests(constants.SamplingRequestRetention)
+	h.sampling.RegisterHandler("default", provider)
+	h.sampling.SetHumanControls(serverName, controls)
+	request = h.sampling.SubmitRequest(request)
+	publishSamplingEvent(events.SamplingRequested, request, fmt.Sprintf("%s asked for a completion from %s", serverName, request.Model))
+
+	if request.Status == "awaiting_approval" {
+		h.logger.Info("Sampling request %s from %s is waiting for approval", request.ID, serverName)
+		timeout := constants.SamplingApprovalTimeout
+		if controls.TimeoutSeconds > 0 {
+			timeout = time.Duration(controls.TimeoutSeconds) * time.Second
+		}
+		ctx, cancel := context.WithTimeout(h.ctx, timeout)
+		request, err = h.sampling.WaitForReview(ctx, request.ID)
+		cancel()
+		if err != nil {
+
+			return nil, &MCPError{Code: protocol.InternalError, Message: err.Error()}
+		}
+	}
+	if review := request.HumanReview; review != nil {
+		verdict := "approved"
+		if !review.Approved {
+			verdict = "rejected"
+		}
+		publishSamplingEvent(events.SamplingReviewed, request, fmt.Sprintf("Sampling request from %s %s by %s", serverName, verdict, review.Reviewer))
+	}
+	if request.Status == "rejected" {
+		reason := "rejected"
+		if request.HumanReview != nil && request.HumanReview.Comments != "" {
+			reason = request.HumanReview.Comments
+		}
+
+		return nil, &MCPError{Code: samplingRejected, Message: "Sampling request rejected: " + reason}
+	}
+
+	response, err := h.sampling.ProcessSamplingRequest(request.ID)
+	if err != nil {
+		h.logger.Error("Sampling request %s from %s failed: %v", request.ID, serverName, err)
+		publishSamplingEvent(events.SamplingFailed, request, err.Error())
+
+		return nil, &MCPError{Code: protocol.InternalError, Message: err.Error()}
+	}
+	publishSamplingEvent(events.SamplingCompleted, request, fmt.Sprintf("%s answered a sampling request from %s", response.Model, serverName))
+
+	result := map[string]interface{}{
+		"role":    "assistant",
+		"content": map[string]interface{}{"type": "text", "text": response.Content.Text},
+		"model":   response.Model,
+	}
+	if response.StopReason != "" {
+		result["stopReason"] = response.StopReason
+	}
+
+	return result, nil
+}
+
+// humanControlsFor converts a server's lifecycle.human_control for the sampling manager.
+// Servers without one get empty controls, which approve every request.
+func humanControlsFor(humanControl *config.HumanControlConfig) *protocol.HumanControlConfig {
+	if humanControl == nil {
+
+		return &protocol.HumanControlConfig{}
+	}
+
+	return &protocol.HumanControlConfig{
+		RequireApproval:     humanControl.RequireApproval,
+		AutoApprovePatterns: humanControl.AutoApprovePatterns,
+		BlockPatterns:       humanControl.BlockPatterns,
+		MaxTokens:           humanControl.MaxTokens,
+		AllowedModels:       humanControl.AllowedModels,
+		TimeoutSeconds:      humanControl.TimeoutSeconds,
+	}
+}
+
+func publishSamplingEvent(eventType string, request *protocol.SamplingRequest, message string) {
+	level := events.LevelInfo
+	if eventType == events.SamplingFailed || request.Status == "rejected" {
+		level = events.LevelWarn
+	}
+	events.Publish(events.Event{
+		Type:    eventType,
+		Level:   level,
+		Server:  request.ServerName,
+		Message: message,
+		Details: map[string]interface{}{"id": request.ID, "status": request.Status, "model": request.Model},
+	})
+}
+
+// proxyClientCapabilities are the capabilities the proxy declares when it initializes a
+// session with a backend: roots for servers that use them, and sampling when the broker
+// serves the server
+func (h *ProxyHandler) proxyClientCapabilities(serverName string) map[string]interface{} {
+	capabilities := map[string]interface{}{}
+	cfg := h.Manager.Config()
+	serverCfg, exists := cfg.Servers[serverName]
+	if !exists {
+
+		return capabilities
+	}
+	if config.IsCapabilityEnabled(serverCfg, "roots") {
+		capabilities["roots"] = map[string]interface{}{"listChanged": true}
+	}
+	if cfg.Sampling.Enabled && config.IsCapabilityEnabled(serverCfg, "sampling") {
+		capabilities["sampling"] = map[string]interface{}{}
+	}
+
+	return capabilities
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+func TestSamplingBroker(t *testing.T) {
+	var mu sync.Mutex
+	var calls []map[string]interface{}
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		calls = append(calls, body)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"model":"gpt-test","choices":[{"message":{"content":"hello there"},"finish_reason":"stop"}]}`))
+	}))
+	defer provider.Close()
+	callCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(calls)
+	}
+	lastCall := func() map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return calls[len(calls)-1]
+	}
+
+	controls := func(timeout int) config.LifecycleConfig {
+
+		return config.LifecycleConfig{HumanControl: &config.HumanControlConfig{
+			RequireApproval:     true,
+			AutoApprovePatterns: []string{"summarize"},
+			BlockPatterns:       []string{"password"},
+			TimeoutSeconds:      timeout,
+		}}
+	}
+	cfg := &config.ComposeConfig{
+		Servers: map[string]config.ServerConfig{
+			"files": {Command: "cat", Capabilities: []string{"sampling"}, Lifecycle: controls(0)},
+			"slow":  {Command: "cat", Capabilities: []string{"sampling"}, Lifecycle: controls(1)},
+			"plain": {Command: "cat"},
+		},
+		Sampling: config.SamplingBrokerConfig{Enabled: true, Provider: "openai", BaseURL: provider.URL, APIKey: "sk-test", Model: "gpt-test"},
+	}
+	logger := logging.NewLogger("error")
+	h := &ProxyHandler{
+		Manager:  newTestManager(cfg, logger, map[string]*ServerInstance{}),
+		logger:   logger,
+		ctx:      context.Background(),
+		sampling: protocol.NewSamplingManager(),
+	}
+
+	createMessage := func(id int, text string) map[string]interface{} {
+
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      float64(id),
+			"method":  "sampling/createMessage",
+			"params": map[string]interface{}{
+				"messages":     []interface{}{map[string]interface{}{"role": "user", "content": map[string]interface{}{"type": "text", "text": text}}},
+				"systemPrompt": "Be brief",
+				"maxTokens":    float64(50),
+			},
+		}
+	}
+	answer := func(serverName string, message map[string]interface{}) MCPResponse {
+		var response MCPResponse
+		data, _ := json.Marshal(h.answerServerRequest(serverName, message))
+		_ = json.Unmarshal(data, &response)
+
+		return response
+	}
+	api := func(method, path, body string) (int, map[string]interface{}) {
+		recorder := httptest.NewRecorder()
+		h.handleSamplingAPI(recorder, httptest.NewRequest(method, path, strings.NewReader(body)), strings.SplitN(path, "?", 2)[0])
+		var decoded map[string]interface{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), &decoded)
+
+		return recorder.Code, decoded
+	}
+	pendingID := func() string {
+		var id string
+		waitFor(t, func() bool {
+			pending := h.sampling.GetPendingRequests()
+			if len(pending) == 1 {
+				id = pending[0].ID
+			}
+
+			return id != ""
+		})
+
+		return id
+	}
+
+	if response := answer("files", map[string]interface{}{"jsonrpc": "2.0", "id": float64(1), "method": "ping"}); response.Error != nil {
+		t.Errorf("ping: unexpected error %+v", response.Error)
+	}
+	if response := answer("plain", createMessage(2, "summarize this")); response.Error == nil || response.Error.Code != protocol.MethodNotFound {
+		t.Errorf("server without the sampling capability: expected method not found, got %+v", response)
+	}
+
+	response := answer("files", createMessage(3, "Please summarize this"))
+	result, _ := response.Result.(map[string]interface{})
+	content, _ := result["content"].(map[string]interface{})
+	if response.Error != nil || content["text"] != "hello there" || result["stopReason"] != "endTurn" {
+		t.Fatalf("auto-approved request: unexpected response %+v", response)
+	}
+	call := lastCall()
+	messages, _ := call["messages"].([]interface{})
+	if call["model"] != "gpt-test" || call["max_tokens"] != float64(50) || len(messages) != 2 {
+		t.Errorf("unexpected provider call %+v", call)
+	}
+
+	before := callCount()
+	if response := answer("files", createMessage(4, "what is my password")); response.Error == nil || response.Error.Code != samplingRejected {
+		t.Errorf("blocked request: expected a rejection, got %+v", response)
+	}
+	if callCount() != before {
+		t.Error("blocked request reached the provider")
+	}
+
+	replies := make(chan MCPResponse, 1)
+	go func() {
+		replies <- answer("files", createMessage(5, "write a poem"))
+	}()
+	id := pendingID()
+	if status, _ := api(http.MethodPost, "/api/sampling/"+id+"/approve", `{"messages":[{"role":"system","content":{"type":"text","text":"x"}}]}`); status != http.StatusBadRequest {
+		t.Errorf("approval with a system message: expected 400, got %d", status)
+	}
+	status, reviewed := api(http.MethodPost, "/api/sampling/"+id+"/approve", `{"reviewer":"alice","messages":[{"role":"user","content":{"type":"text","text":"write a haiku"}}]}`)
+	if status != http.StatusOK || reviewed["status"] != "approved" {
+		t.Fatalf("approve: expected the approved request, got %d %+v", status, reviewed)
+	}
+	if response := <-replies; response.Error != nil {
+		t.Errorf("approved request: unexpected error %+v", response.Error)
+	}
+	messages, _ = lastCall()["messages"].([]interface{})
+	if edited, _ := messages[1].(map[string]interface{}); edited["content"] != "write a haiku" {
+		t.Errorf("approved request should use the edited messages, got %+v", messages)
+	}
+	if status, _ := api(http.MethodPost, "/api/sampling/"+id+"/deny", ""); status != http.StatusConflict {
+		t.Errorf("reviewing a completed request: expected 409, got %d", status)
+	}
+
+	go func() {
+		replies <- answer("files", createMessage(6, "write a limerick"))
+	}()
+	id = pendingID()
+	if status, _ := api(http.MethodPost, "/api/sampling/"+id+"/deny", `{"comments":"not today"}`); status != http.StatusOK {
+		t.Errorf("deny: expected 200, got %d", status)
+	}
+	if response := <-replies; response.Error == nil || response.Error.Code != samplingRejected || !strings.Contains(response.Error.Message, "not today") {
+		t.Errorf("denied request: expected the reviewer's reason, got %+v", response)
+	}
+
+	if response := answer("slow", createMessage(7, "write an essay")); response.Error == nil || response.Error.Code != samplingRejected {
+		t.Errorf("unreviewed request: expected a rejection after the timeout, got %+v", response)
+	}
+
+	status, listed := api(http.MethodGet, "/api/sampling?status=completed", "")
+	if requests, _ := listed["requests"].([]interface{}); status != http.StatusOK || len(requests) != 2 {
+		t.Errorf("list completed: expected 2 requests, got %d %+v", status, listed)
+	}
+	if status, _ := api(http.MethodGet, "/api/sampling/sampling_missing_1", ""); status != http.StatusNotFound {
+		t.Errorf("unknown request: expected 404, got %d", status)
+	}
+	if status, _ := api(http.MethodGet, "/api/sampling/"+id+"/approve", ""); status != http.StatusMethodNotAllowed {
+		t.Errorf("GET on approve: expected 405, got %d", status)
+	}
+	if capabilities := h.proxyClientCapabilities("files"); capabilities["sampling"] == nil {
+		t.Error("servers served by the broker should be told the proxy supports sampling")
+	}
+	if capabilities := h.proxyClientCapabilities("plain"); capabilities["sampling"] != nil {
+		t.Error("servers without the sampling capability should not be offered sampling")
+	}
+}
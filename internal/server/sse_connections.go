@@ -61,7 +61,7 @@ func (h *ProxyHandler) getSSEConnection(serverName string) (*MCPSSEConnection, e
 	}
 
 	h.logger.Info("Creating new SSE connection for server: %s", serverName)
-	serverConfig, cfgExists := h.Manager.config.Servers[serverName]
+	serverConfig, cfgExists := h.Manager.Config().Servers[serverName]
 	if !cfgExists {
 
 		return nil, fmt.Errorf("configuration for server '%s' not found", serverName)
@@ -108,7 +108,7 @@ func (h *ProxyHandler) createSSEConnection(serverName string, serverConfig confi
 }
 
 func (h *ProxyHandler) getServerSSEURL(serverName string, serverConfig config.ServerConfig) (string, string) {
-	targetHost := fmt.Sprintf("mcp-compose-%s", serverName)
+	targetHost := config.ContainerName(serverName)
 	targetPort := serverConfig.HttpPort
 	if serverConfig.SSEPort > 0 {
 		targetPort = serverConfig.SSEPort
@@ -158,7 +158,7 @@ func (h *ProxyHandler) initializeSSEConnection(conn *MCPSSEConnection) error {
 		"method":  "initialize",
 		"params": map[string]interface{}{
 			"protocolVersion": "2024-11-05",
-			"capabilities":    map[string]interface{}{},
+			"capabilities":    h.proxyClientCapabilities(conn.ServerName),
 			"clientInfo": map[string]interface{}{
 				"name":    "mcp-compose-proxy",
 				"version": "1.0.0",
@@ -336,9 +336,13 @@ func (h *ProxyHandler) getSSESessionEndpoint(conn *MCPSSEConnection) (string, er
 }
 
 func (h *ProxyHandler) readSSEResponses(conn *MCPSSEConnection) {
+	dropped := false
 	defer func() {
 		h.logger.Info("SSE response reader ending for %s", conn.ServerName)
 		h.closeSSEConnection(conn)
+		if dropped && h.ctx.Err() == nil {
+			go h.reconnectSSEConnection(conn)
+		}
 	}()
 
 	h.logger.Info("Starting SSE response reader for %s", conn.ServerName)
@@ -364,6 +368,11 @@ func (h *ProxyHandler) readSSEResponses(conn *MCPSSEConnection) {
 				h.logger.Info("SSE reader scan returned false (EOF?) for %s", conn.ServerName)
 			}
 
+			// A reader still in place means the backend went away rather than the proxy closing it
+			conn.mu.Lock()
+			dropped = conn.sseReader != nil
+			conn.mu.Unlock()
+
 			break
 		}
 
@@ -415,6 +424,15 @@ func (h *ProxyHandler) processSSEMessage(conn *MCPSSEConnection, messageData str
 
 	h.logger.Info("Parsed SSE response for %s: %+v", conn.ServerName, response)
 
+	if isServerRequest(response) {
+		go h.replyToServerRequest(conn.ServerName, response, func(reply map[string]interface{}) error {
+
+			return h.sendSSERequestNoResponse(conn, reply)
+		})
+
+		return
+	}
+
 	// Check if this is a response to a pending request
 	if responseID := response["id"]; responseID != nil {
 		h.logger.Info("SSE response has ID %v (type: %T) for %s", responseID, responseID, conn.ServerName)
@@ -460,6 +478,7 @@ func (h *ProxyHandler) processSSEMessage(conn *MCPSSEConnection, messageData str
 		}
 	} else {
 		h.logger.Info("SSE message without ID from %s (notification?): %s", conn.ServerName, messageData)
+		h.handleServerNotification(conn.ServerName, response)
 	}
 }
 
@@ -639,6 +658,45 @@ func (h *ProxyHandler) sendSSERequestToSession(conn *MCPSSEConnection, request m
 	return nil, fmt.Errorf("session request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
 }
 
+// reconnectSSEConnection replaces a connection whose backend dropped the stream
+func (h *ProxyHandler) reconnectSSEConnection(conn *MCPSSEConnection) {
+	serverName := conn.ServerName
+
+	stillCurrent := func() bool {
+		h.SSEMutex.RLock()
+		defer h.SSEMutex.RUnlock()
+
+		return h.SSEConnections[serverName] == conn
+	}
+
+	connect := func() error {
+		serverConfig, exists := h.Manager.Config().Servers[serverName]
+		if !exists {
+
+			return fmt.Errorf("configuration for server '%s' not found", serverName)
+		}
+		newConn, err := h.createSSEConnection(serverName, serverConfig)
+		if err != nil {
+
+			return err
+		}
+
+		h.SSEMutex.Lock()
+		defer h.SSEMutex.Unlock()
+		if h.SSEConnections[serverName] != conn {
+			h.closeSSEConnection(newConn)
+
+			return nil
+		}
+		h.SSEConnections[serverName] = newConn
+
+		return nil
+	}
+
+	h.logger.Warning("SSE backend %s dropped its stream, reconnecting", serverName)
+	h.reconnectSSEBackend(serverName, stillCurrent, connect)
+}
+
 func (h *ProxyHandler) closeSSEConnection(conn *MCPSSEConnection) {
 	conn.mu.Lock()
 	defer conn.mu.Unlock()
@@ -30,6 +30,9 @@ type EnhancedMCPSSEConnection struct {
 	ServerInfo      map[string]interface{}
 	SessionID       string
 
+	// lastEventID is the backend's most recent SSE id, sent back when reconnecting
+	lastEventID string
+
 	// Enhanced connection management
 	sseResponse *http.Response
 	sseBody     io.ReadCloser
@@ -78,13 +81,13 @@ func (h *ProxyHandler) getEnhancedSSEConnection(serverName string) (*EnhancedMCP
 	h.SSEMutex.RUnlock()
 
 	h.logger.Info("Creating new enhanced SSE connection for server: %s", serverName)
-	serverConfig, cfgExists := h.Manager.config.Servers[serverName]
+	serverConfig, cfgExists := h.Manager.Config().Servers[serverName]
 	if !cfgExists {
 
 		return nil, fmt.Errorf("configuration for server '%s' not found", serverName)
 	}
 
-	newConn, err := h.createEnhancedSSEConnection(serverName, serverConfig)
+	newConn, err := h.createEnhancedSSEConnection(serverName, serverConfig, "")
 	if err != nil {
 
 		return nil, fmt.Errorf("failed to create enhanced SSE connection: %w", err)
@@ -100,7 +103,7 @@ func (h *ProxyHandler) getEnhancedSSEConnection(serverName string) (*EnhancedMCP
 	return newConn, nil
 }
 
-func (h *ProxyHandler) createEnhancedSSEConnection(serverName string, serverConfig config.ServerConfig) (*EnhancedMCPSSEConnection, error) {
+func (h *ProxyHandler) createEnhancedSSEConnection(serverName string, serverConfig config.ServerConfig, lastEventID string) (*EnhancedMCPSSEConnection, error) {
 	baseURL, sseEndpoint := h.getServerSSEURL(serverName, serverConfig)
 
 	conn := &EnhancedMCPSSEConnection{
@@ -114,6 +117,7 @@ func (h *ProxyHandler) createEnhancedSSEConnection(serverName string, serverConf
 		pendingRequests: make(map[string]chan map[string]interface{}),
 		streamChan:      make(chan map[string]interface{}, constants.SSEStreamBuffer), // Buffered channel for streaming
 		nextRequestID:   0,
+		lastEventID:     lastEventID,
 	}
 
 	// Initialize SSE connection
@@ -151,7 +155,7 @@ func (h *ProxyHandler) initializeEnhancedSSEConnection(conn *EnhancedMCPSSEConne
 		"method":  "initialize",
 		"params": map[string]interface{}{
 			"protocolVersion": "2024-11-05",
-			"capabilities":    map[string]interface{}{},
+			"capabilities":    h.proxyClientCapabilities(conn.ServerName),
 			"clientInfo": map[string]interface{}{
 				"name":    "mcp-compose-proxy-enhanced",
 				"version": "1.0.0",
@@ -212,6 +216,9 @@ func (h *ProxyHandler) getEnhancedSSESessionEndpoint(conn *EnhancedMCPSSEConnect
 	httpReq.Header.Set("Accept", "text/event-stream")
 	httpReq.Header.Set("Cache-Control", "no-cache")
 	httpReq.Header.Set("Connection", "keep-alive")
+	if conn.lastEventID != "" {
+		httpReq.Header.Set("Last-Event-ID", conn.lastEventID)
+	}
 
 	resp, err := h.sseClient.Do(httpReq)
 	if err != nil {
@@ -272,9 +279,13 @@ func (h *ProxyHandler) getEnhancedSSESessionEndpoint(conn *EnhancedMCPSSEConnect
 }
 
 func (h *ProxyHandler) readEnhancedSSEResponses(conn *EnhancedMCPSSEConnection) {
+	dropped := false
 	defer func() {
 		h.logger.Info("Enhanced SSE response reader ending for %s", conn.ServerName)
 		h.closeEnhancedSSEConnection(conn)
+		if dropped && h.ctx.Err() == nil {
+			go h.reconnectEnhancedSSEConnection(conn)
+		}
 	}()
 
 	h.logger.Info("Starting enhanced SSE response reader for %s", conn.ServerName)
@@ -301,6 +312,11 @@ func (h *ProxyHandler) readEnhancedSSEResponses(conn *EnhancedMCPSSEConnection)
 				h.logger.Info("Enhanced SSE reader scan returned false (EOF?) for %s", conn.ServerName)
 			}
 
+			// A reader still in place means the backend went away rather than the proxy closing it
+			conn.mu.RLock()
+			dropped = conn.sseReader != nil
+			conn.mu.RUnlock()
+
 			break
 		}
 
@@ -312,6 +328,14 @@ func (h *ProxyHandler) readEnhancedSSEResponses(conn *EnhancedMCPSSEConnection)
 			continue
 		}
 
+		if strings.HasPrefix(line, "id:") {
+			conn.mu.Lock()
+			conn.lastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+			conn.mu.Unlock()
+
+			continue
+		}
+
 		if strings.HasPrefix(line, "event: message") {
 			// Next line should have the message data
 			if reader.Scan() {
@@ -344,6 +368,16 @@ func (h *ProxyHandler) processEnhancedSSEMessage(conn *EnhancedMCPSSEConnection,
 	conn.responseCount++
 	conn.mu.Unlock()
 
+	if isServerRequest(response) {
+		go h.replyToServerRequest(conn.ServerName, response, func(reply map[string]interface{}) error {
+			_, err := h.sendEnhancedSSERequestNoResponse(conn, reply)
+
+			return err
+		})
+
+		return
+	}
+
 	// PERFORMANCE: Direct string lookup, no type conversion needed
 	if responseIDInterface := response["id"]; responseIDInterface != nil {
 		// Convert response ID to string for consistent lookup
@@ -370,6 +404,7 @@ func (h *ProxyHandler) processEnhancedSSEMessage(conn *EnhancedMCPSSEConnection,
 		}
 	} else {
 		// This is a notification or streaming message
+		h.handleServerNotification(conn.ServerName, response)
 		conn.streamMutex.RLock()
 		if conn.streamActive {
 			select {
@@ -559,6 +594,50 @@ func (h *ProxyHandler) sendEnhancedSSERequestNoResponse(conn *EnhancedMCPSSEConn
 	return nil, fmt.Errorf("session request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
 }
 
+// reconnectEnhancedSSEConnection replaces a connection whose backend dropped the stream,
+// resuming from the last event ID the backend sent
+func (h *ProxyHandler) reconnectEnhancedSSEConnection(conn *EnhancedMCPSSEConnection) {
+	serverName := conn.ServerName
+	conn.mu.RLock()
+	lastEventID := conn.lastEventID
+	conn.mu.RUnlock()
+
+	stillCurrent := func() bool {
+		h.SSEMutex.RLock()
+		defer h.SSEMutex.RUnlock()
+
+		return h.EnhancedSSEConnections[serverName] == conn
+	}
+
+	connect := func() error {
+		serverConfig, exists := h.Manager.Config().Servers[serverName]
+		if !exists {
+
+			return fmt.Errorf("configuration for server '%s' not found", serverName)
+		}
+		newConn, err := h.createEnhancedSSEConnection(serverName, serverConfig, lastEventID)
+		if err != nil {
+
+			return err
+		}
+
+		h.SSEMutex.Lock()
+		defer h.SSEMutex.Unlock()
+		if h.EnhancedSSEConnections[serverName] != conn {
+			// A request already opened a fresh connection
+			h.closeEnhancedSSEConnection(newConn)
+
+			return nil
+		}
+		h.EnhancedSSEConnections[serverName] = newConn
+
+		return nil
+	}
+
+	h.logger.Warning("SSE backend %s dropped its stream, reconnecting", serverName)
+	h.reconnectSSEBackend(serverName, stillCurrent, connect)
+}
+
 func (h *ProxyHandler) closeEnhancedSSEConnection(conn *EnhancedMCPSSEConnection) {
 	conn.mu.Lock()
 	defer conn.mu.Unlock()
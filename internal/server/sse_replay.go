@@ -0,0 +1,287 @@
+// internal/server/sse_replay.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/protocol"
+)
+
+// sseEvent is one server-to-client message kept for replay
+type sseEvent struct {
+	seq    uint64
+	data   []byte
+	client string // Only this client receives the event; empty for every client
+}
+
+// sseEventBuffer keeps a server's recent notifications so clients that reconnect with
+// Last-Event-ID receive what they missed. Event IDs are "<epoch>-<seq>"; the epoch changes
+// when the proxy restarts, so IDs from a previous proxy replay the whole buffer.
+type sseEventBuffer struct {
+	epoch       string
+	mu          sync.Mutex
+	events      []sseEvent
+	nextSeq     uint64
+	subscribers map[chan sseEvent]string // Channel -> the client it streams to
+}
+
+func newSSEEventBuffer(epoch string) *sseEventBuffer {
+
+	return &sseEventBuffer{
+		epoch:       epoch,
+		nextSeq:     1,
+		subscribers: make(map[chan sseEvent]string),
+	}
+}
+
+func (b *sseEventBuffer) eventID(seq uint64) string {
+
+	return fmt.Sprintf("%s-%d", b.epoch, seq)
+}
+
+// publish records a message for every client of the server
+func (b *sseEventBuffer) publish(data []byte) {
+	b.publishTo("", data)
+}
+
+// publishTo records a message for one client, or every client when client is empty, and
+// hands it to live subscribers. A subscriber that cannot keep up is disconnected; it
+// resumes from its last event ID when it reconnects.
+func (b *sseEventBuffer) publishTo(client string, data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := sseEvent{seq: b.nextSeq, data: data, client: client}
+	b.nextSeq++
+
+	if len(b.events) >= constants.SSEReplayBufferSize {
+		copy(b.events, b.events[1:])
+		b.events = b.events[:len(b.events)-1]
+	}
+	b.events = append(b.events, event)
+
+	for ch, subscriber := range b.subscribers {
+		if client != "" && client != subscriber {
+
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns the buffered events after lastEventID and a channel of new ones for a
+// client. An empty lastEventID starts from live events only.
+func (b *sseEventBuffer) subscribe(lastEventID, client string) ([]sseEvent, chan sseEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []sseEvent
+	if lastEventID != "" {
+		after := uint64(0)
+		if epoch, seq, found := strings.Cut(lastEventID, "-"); found && epoch == b.epoch {
+			after, _ = strconv.ParseUint(seq, 10, 64)
+		}
+		for _, event := range b.events {
+			if event.seq > after && (event.client == "" || event.client == client) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	ch := make(chan sseEvent, constants.SSEStreamBuffer)
+	b.subscribers[ch] = client
+
+	return backlog, ch
+}
+
+func (b *sseEventBuffer) unsubscribe(ch chan sseEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.subscribers[ch]; exists {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// eventBufferFor returns the replay buffer for a server, creating it on first use
+func (h *ProxyHandler) eventBufferFor(serverName string) *sseEventBuffer {
+	h.sseEventBuffersMu.Lock()
+	defer h.sseEventBuffersMu.Unlock()
+
+	buffer, exists := h.sseEventBuffers[serverName]
+	if !exists {
+		buffer = newSSEEventBuffer(h.sseEpoch)
+		h.sseEventBuffers[serverName] = buffer
+	}
+
+	return buffer
+}
+
+// handleServerNotification processes a message a backend sent outside any request: it
+// updates the catalog cache and queues the message for clients on the server's event
+// stream. Resource updates only go to the clients subscribed to the resource.
+func (h *ProxyHandler) handleServerNotification(serverName string, message map[string]interface{}) {
+	if _, hasID := message["id"]; hasID {
+
+		return
+	}
+
+	h.observeServerNotification(serverName, message)
+	if message["method"] == protocol.NotificationProgress {
+		h.routeProgress(serverName, message)
+
+		return
+	}
+	if message["method"] == protocol.NotificationResourcesUpdated {
+		params, _ := message["params"].(map[string]interface{})
+		uri, _ := params["uri"].(string)
+		h.notifyResourceSubscribers(serverName, uri, "updated")
+
+		return
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		h.logger.Warning("Failed to encode notification from %s for replay: %v", serverName, err)
+
+		return
+	}
+	h.eventBufferFor(serverName).publish(data)
+}
+
+// acceptsEventStream reports whether a GET asks for the server's notification stream
+func acceptsEventStream(r *http.Request) bool {
+
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// handleServerEventStream streams a server's notifications to the client as SSE, first
+// replaying anything buffered after the client's Last-Event-ID
+func (h *ProxyHandler) handleServerEventStream(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance) {
+	if !h.authenticateRequest(w, r, serverName, instance) {
+
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.corsError(w, "Streaming not supported", http.StatusInternalServerError)
+
+		return
+	}
+
+	// Make sure SSE backends are connected so their notifications reach the buffer
+	if serverConfig, exists := h.Manager.Config().Servers[serverName]; exists && serverConfig.Protocol == "sse" {
+		if _, err := h.getOptimalSSEConnection(serverName); err != nil {
+			h.logger.Warning("Event stream for %s opened without a backend connection: %v", serverName, err)
+		}
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	clientID := h.getClientID(r)
+	buffer := h.eventBufferFor(serverName)
+	backlog, events := buffer.subscribe(lastEventID, clientID)
+	defer buffer.unsubscribe(events)
+	h.clientStreamOpened(clientID)
+	defer h.clientStreamClosed(clientID)
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = fmt.Fprintf(w, "retry: %d\n\n", constants.SSEClientRetryHint.Milliseconds())
+	if len(backlog) > 0 {
+		h.logger.Info("Replaying %d buffered event(s) from %s after %q", len(backlog), serverName, lastEventID)
+	}
+	for _, event := range backlog {
+		writeSSEEvent(w, buffer.eventID(event.seq), event.data)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(constants.SSEClientKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+
+			return
+		case <-h.ctx.Done():
+
+			return
+		case event, open := <-events:
+			if !open {
+				h.logger.Warning("Event stream client for %s fell behind; closing so it can resume", serverName)
+
+				return
+			}
+			writeSSEEvent(w, buffer.eventID(event.seq), event.data)
+			flusher.Flush()
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+			h.subscriptionManager.Touch(clientID)
+		}
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, id string, data []byte) {
+	_, _ = fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", id, data)
+}
+
+// reconnectSSEBackend re-establishes a dropped SSE backend connection with exponential backoff.
+// stillCurrent reports whether the dropped connection is still the registered one; once it
+// has been replaced or removed the loop stops. connect opens and registers a new connection.
+func (h *ProxyHandler) reconnectSSEBackend(serverName string, stillCurrent func() bool, connect func() error) {
+	delay := constants.SSEReconnectInitialDelay
+	for attempt := 1; attempt <= constants.SSEReconnectMaxAttempts; attempt++ {
+		select {
+		case <-h.ctx.Done():
+
+			return
+		case <-time.After(delay):
+		}
+
+		if !stillCurrent() {
+
+			return
+		}
+
+		err := connect()
+		if err == nil {
+			h.logger.Info("Reconnected SSE backend %s after %d attempt(s)", serverName, attempt)
+
+			return
+		}
+		h.logger.Warning("SSE backend %s reconnect attempt %d/%d failed: %v", serverName, attempt, constants.SSEReconnectMaxAttempts, err)
+
+		delay *= 2
+		if delay > constants.SSEReconnectMaxDelay {
+			delay = constants.SSEReconnectMaxDelay
+		}
+	}
+
+	h.logger.Error("Giving up reconnecting SSE backend %s; the next request will try again", serverName)
+}
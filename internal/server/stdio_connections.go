@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 )
 
@@ -78,13 +79,13 @@ func (h *ProxyHandler) getStdioConnection(serverName string) (*MCPSTDIOConnectio
 }
 
 func (h *ProxyHandler) createStdioConnection(serverName string) (*MCPSTDIOConnection, error) {
-	serverConfig, exists := h.Manager.config.Servers[serverName]
+	serverConfig, exists := h.Manager.Config().Servers[serverName]
 	if !exists {
 
 		return nil, fmt.Errorf("server %s not found in config", serverName)
 	}
 
-	containerName := fmt.Sprintf("mcp-compose-%s", serverName)
+	containerName := config.ContainerName(serverName)
 	port := serverConfig.StdioHosterPort
 	address := fmt.Sprintf("%s:%d", containerName, port)
 
@@ -156,7 +157,7 @@ func (h *ProxyHandler) initializeStdioConnection(conn *MCPSTDIOConnection) error
 		"method":  "initialize",
 		"params": map[string]interface{}{
 			"protocolVersion": "2024-11-05",
-			"capabilities":    map[string]interface{}{},
+			"capabilities":    h.proxyClientCapabilities(conn.ServerName),
 			"clientInfo": map[string]interface{}{
 				"name":    "mcp-compose-proxy",
 				"version": "1.0.0",
@@ -277,8 +278,30 @@ func (h *ProxyHandler) readStdioResponseWithoutLock(conn *MCPSTDIOConnection) (m
 			h.logger.Debug("Found valid JSON-RPC response from %s", conn.ServerName)
 
 			return response, nil
+		} else if isServerRequest(response) {
+			// The server is waiting on this answer before it finishes our request
+			h.replyToServerRequest(conn.ServerName, response, func(reply map[string]interface{}) error {
+
+				return h.sendStdioRequestWithoutLock(conn, reply)
+			})
+
+			continue
+		} else if isServerRequest(response) {
+			// The server is waiting on this answer before it finishes our request, which
+			// may have been held for review well past the read deadline
+			h.replyToServerRequest(conn.ServerName, response, func(reply map[string]interface{}) error {
+
+				return h.sendStdioRequestWithoutLock(conn, reply)
+			})
+			if err := conn.Connection.SetReadDeadline(time.Now().Add(constants.WriteDeadlineTimeout)); err != nil {
+
+				return nil, fmt.Errorf("failed to set read deadline: %w", err)
+			}
+
+			continue
 		} else if hasMethod {
 			h.logger.Debug("Skipping echoed request/notification from %s: %s", conn.ServerName, line)
+			h.handleServerNotification(conn.ServerName, response)
 
 			continue
 		} else {
@@ -379,6 +402,7 @@ func (h *ProxyHandler) readStdioResponse(conn *MCPSTDIOConnection) (map[string]i
 			return response, nil
 		} else if hasMethod {
 			h.logger.Debug("Skipping echoed request/notification from %s: %s", conn.ServerName, line)
+			h.handleServerNotification(conn.ServerName, response)
 
 			continue
 		} else {
@@ -426,13 +450,13 @@ func (h *ProxyHandler) maintainStdioConnections() {
 }
 
 func (h *ProxyHandler) createFreshStdioConnection(serverName string, timeout time.Duration) (*MCPSTDIOConnection, error) {
-	serverConfig, exists := h.Manager.config.Servers[serverName]
+	serverConfig, exists := h.Manager.Config().Servers[serverName]
 	if !exists {
 
 		return nil, fmt.Errorf("server %s not found in config", serverName)
 	}
 
-	containerName := fmt.Sprintf("mcp-compose-%s", serverName)
+	containerName := config.ContainerName(serverName)
 	port := serverConfig.StdioHosterPort
 	address := fmt.Sprintf("%s:%d", containerName, port)
 
@@ -499,7 +523,7 @@ func (h *ProxyHandler) quickInitializeStdioConnection(conn *MCPSTDIOConnection,
 		"method":  "initialize",
 		"params": map[string]interface{}{
 			"protocolVersion": "2024-11-05",
-			"capabilities":    map[string]interface{}{},
+			"capabilities":    h.proxyClientCapabilities(conn.ServerName),
 			"clientInfo": map[string]interface{}{
 				"name":    "mcp-compose-proxy",
 				"version": "1.0.0",
@@ -560,8 +584,8 @@ func (h *ProxyHandler) readStdioResponseWithTimeout(conn *MCPSTDIOConnection, ti
 }
 
 func (h *ProxyHandler) handleSTDIOServerRequest(w http.ResponseWriter, _ *http.Request, serverName string, requestPayload map[string]interface{}, reqIDVal interface{}, reqMethodVal string) {
-	containerName := fmt.Sprintf("mcp-compose-%s", serverName)
-	serverCfg, cfgExists := h.Manager.config.Servers[serverName]
+	containerName := config.ContainerName(serverName)
+	serverCfg, cfgExists := h.Manager.Config().Servers[serverName]
 	if !cfgExists {
 		h.logger.Error("Config not found for STDIO server %s", serverName)
 		h.sendMCPError(w, reqIDVal, -32603, "Internal server error: missing server config")
@@ -712,9 +736,9 @@ func (h *ProxyHandler) handleSocatSTDIOServerRequest(w http.ResponseWriter, r *h
 func (h *ProxyHandler) sendRawTCPRequestWithRetry(host string, port int, requestPayload map[string]interface{}, timeout time.Duration, attempt int) (map[string]interface{}, error) {
 	// Find server name for connection tracking
 	var serverName string
-	for name, config := range h.Manager.config.Servers {
-		containerName := fmt.Sprintf("mcp-compose-%s", name)
-		if containerName == host && config.StdioHosterPort == port {
+	for name, srvCfg := range h.Manager.Config().Servers {
+		containerName := config.ContainerName(name)
+		if containerName == host && srvCfg.StdioHosterPort == port {
 			serverName = name
 
 			break
@@ -0,0 +1,460 @@
+// internal/server/stdio_mux.go
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+)
+
+// stdioMux shares one long-lived stdio session with a server among every client. Client
+// request IDs are rewritten to IDs unique to the session on the way in and restored on the
+// way out, so concurrent clients only ever see their own responses.
+type stdioMux struct {
+	serverName string
+	transport  io.ReadWriteCloser
+	writeMu    sync.Mutex
+	mu         sync.Mutex
+	pending    map[string]chan map[string]interface{}
+	nextID     uint64
+	initResult json.RawMessage
+	ready      chan struct{}
+	startErr   error
+	closed     chan struct{}
+	closeOnce  sync.Once
+	closeErr   error
+}
+
+// stdioExecTransport talks to a server process started with docker exec -i
+type stdioExecTransport struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func (t *stdioExecTransport) Read(p []byte) (int, error) {
+
+	return t.stdout.Read(p)
+}
+
+func (t *stdioExecTransport) Write(p []byte) (int, error) {
+
+	return t.stdin.Write(p)
+}
+
+func (t *stdioExecTransport) Close() error {
+	err := t.stdin.Close()
+	if t.cmd.Process != nil {
+		_ = t.cmd.Process.Kill()
+	}
+	_ = t.cmd.Wait()
+
+	return err
+}
+
+// stdioSharingEnabled reports whether a stdio server's clients share one multiplexed session
+func stdioSharingEnabled(serverConfig config.ServerConfig) bool {
+
+	return serverConfig.StdioSharing != "per_request"
+}
+
+// getStdioMux returns the server's shared session, starting it on first use or after it died
+func (h *ProxyHandler) getStdioMux(serverName string, serverConfig config.ServerConfig) (*stdioMux, error) {
+	h.stdioMuxesMu.Lock()
+	mux, exists := h.stdioMuxes[serverName]
+	if exists && !mux.isClosed() {
+		h.stdioMuxesMu.Unlock()
+		<-mux.ready
+
+		return mux, mux.startErr
+	}
+	mux = &stdioMux{
+		serverName: serverName,
+		pending:    make(map[string]chan map[string]interface{}),
+		ready:      make(chan struct{}),
+		closed:     make(chan struct{}),
+	}
+	h.stdioMuxes[serverName] = mux
+	h.stdioMuxesMu.Unlock()
+
+	mux.startErr = h.startStdioMux(mux, serverConfig)
+	close(mux.ready)
+	if mux.startErr != nil {
+		mux.shutdown(mux.startErr)
+		h.stdioMuxesMu.Lock()
+		if h.stdioMuxes[serverName] == mux {
+			delete(h.stdioMuxes, serverName)
+		}
+		h.stdioMuxesMu.Unlock()
+
+		return nil, mux.startErr
+	}
+
+	return mux, nil
+}
+
+// startStdioMux opens the transport and performs the MCP handshake once for all clients
+func (h *ProxyHandler) startStdioMux(mux *stdioMux, serverConfig config.ServerConfig) error {
+	transport, err := h.openStdioMuxTransport(mux.serverName, serverConfig)
+	if err != nil {
+
+		return err
+	}
+	mux.transport = transport
+	go h.readStdioMux(mux)
+
+	ctx, cancel := context.WithTimeout(h.ctx, constants.HTTPRequestTimeout)
+	defer cancel()
+
+	response, err := mux.request(ctx, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "initialize",
+		"params": map[string]interface{}{
+			"protocolVersion": "2024-11-05",
+			"capabilities":    h.proxyClientCapabilities(mux.serverName),
+			"clientInfo": map[string]interface{}{
+				"name":    "mcp-compose-proxy",
+				"version": "1.0.0",
+			},
+		},
+	})
+	if err != nil {
+
+		return fmt.Errorf("failed to initialize shared STDIO session: %w", err)
+	}
+	if mcpError, hasError := response["error"]; hasError {
+
+		return fmt.Errorf("initialize failed: %v", mcpError)
+	}
+	if mux.initResult, err = json.Marshal(response["result"]); err != nil {
+
+		return fmt.Errorf("failed to encode initialize result: %w", err)
+	}
+
+	if err := mux.send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/initialized",
+		"params":  map[string]interface{}{},
+	}); err != nil {
+		h.logger.Warning("Failed to send initialized notification to %s: %v (continuing anyway)", mux.serverName, err)
+	}
+
+	h.logger.Info("Started shared STDIO session for %s", mux.serverName)
+
+	return nil
+}
+
+// openStdioMuxTransport connects to the server's socat hoster when it has one, to the stdio
+// its supervisor serves when it runs as a process, otherwise starts a single long-lived copy
+// of its command inside the container
+func (h *ProxyHandler) openStdioMuxTransport(serverName string, serverConfig config.ServerConfig) (io.ReadWriteCloser, error) {
+	containerName := config.ContainerName(serverName)
+
+	if serverConfig.Image == "" && serverConfig.Build.Context == "" && serverConfig.StdioHosterPort == 0 {
+		conn, err := runtime.DialStdio(containerName)
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to connect to the stdio of process %s: %w", containerName, err)
+		}
+
+		return conn, nil
+	}
+
+	if serverConfig.StdioHosterPort > 0 {
+		address := fmt.Sprintf("%s:%d", containerName, serverConfig.StdioHosterPort)
+		ctx, cancel := context.WithTimeout(h.ctx, constants.HTTPContextTimeout)
+		defer cancel()
+
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+		}
+
+		return conn, nil
+	}
+
+	if serverConfig.Command == "" {
+
+		return nil, fmt.Errorf("STDIO server '%s' has no command defined", serverName)
+	}
+
+	execCmdAndArgs := []string{"exec", "-i", containerName, serverConfig.Command}
+	execCmdAndArgs = append(execCmdAndArgs, serverConfig.Args...)
+	cmd := exec.CommandContext(h.ctx, "docker", execCmdAndArgs...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	h.logger.Debug("Starting shared STDIO process for '%s': docker %s", serverName, strings.Join(execCmdAndArgs, " "))
+	if err := cmd.Start(); err != nil {
+
+		return nil, fmt.Errorf("failed to start docker exec: %w", err)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			h.logger.Debug("STDIO server %s stderr: %s", serverName, scanner.Text())
+		}
+	}()
+
+	return &stdioExecTransport{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+}
+
+// readStdioMux routes every line the server writes: responses to the waiting client,
+// notifications to the proxy's notification handling
+func (h *ProxyHandler) readStdioMux(mux *stdioMux) {
+	reader := bufio.NewReaderSize(mux.transport, constants.STDIOBufferSize)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			h.dispatchStdioMuxMessage(mux, trimmed)
+		}
+		if err != nil {
+			h.logger.Warning("Shared STDIO session for %s ended: %v", mux.serverName, err)
+			mux.shutdown(fmt.Errorf("session ended: %w", err))
+
+			return
+		}
+	}
+}
+
+func (h *ProxyHandler) dispatchStdioMuxMessage(mux *stdioMux, line []byte) {
+	var message map[string]interface{}
+	if err := json.Unmarshal(line, &message); err != nil {
+		h.logger.Debug("Skipping non-JSON line from %s: %s", mux.serverName, string(line))
+
+		return
+	}
+
+	if _, hasMethod := message["method"]; hasMethod {
+		if isServerRequest(message) {
+			// Requests from the server can't be routed to one client of a shared session,
+			// so the proxy answers them itself
+			go h.replyToServerRequest(mux.serverName, message, mux.send)
+
+			return
+		}
+		h.handleServerNotification(mux.serverName, message)
+
+		return
+	}
+
+	id, _ := message["id"].(string)
+	mux.mu.Lock()
+	ch, exists := mux.pending[id]
+	delete(mux.pending, id)
+	mux.mu.Unlock()
+	if !exists {
+		h.logger.Debug("Dropping response from %s for unknown request %v", mux.serverName, message["id"])
+
+		return
+	}
+	ch <- message
+}
+
+// request sends a request under a session-unique ID and waits for its response
+func (m *stdioMux) request(ctx context.Context, payload map[string]interface{}) (map[string]interface{}, error) {
+	id := fmt.Sprintf("mux-%d", atomic.AddUint64(&m.nextID, 1))
+	ch := make(chan map[string]interface{}, 1)
+
+	m.mu.Lock()
+	if m.isClosed() {
+		m.mu.Unlock()
+
+		return nil, m.closeErr
+	}
+	m.pending[id] = ch
+	m.mu.Unlock()
+
+	rewritten := make(map[string]interface{}, len(payload))
+	for key, value := range payload {
+		rewritten[key] = value
+	}
+	rewritten["id"] = id
+
+	if err := m.send(rewritten); err != nil {
+		m.forget(id)
+
+		return nil, err
+	}
+
+	select {
+	case response := <-ch:
+
+		return response, nil
+	case <-m.closed:
+
+		return nil, m.closeErr
+	case <-ctx.Done():
+		m.forget(id)
+		reason := "client request timed out or disconnected"
+		if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, ctx.Err()) {
+			reason = cause.Error()
+		}
+		_ = m.send(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "notifications/cancelled",
+			"params": map[string]interface{}{
+				"requestId": id,
+				"reason":    reason,
+			},
+		})
+
+		return nil, fmt.Errorf("request timeout: %w", ctx.Err())
+	}
+}
+
+// send writes one message; writes from concurrent clients never interleave
+func (m *stdioMux) send(message map[string]interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	if _, err := m.transport.Write(append(data, '\n')); err != nil {
+		m.shutdown(fmt.Errorf("failed to write request: %w", err))
+
+		return fmt.Errorf("failed to write request: %w", err)
+	}
+
+	return nil
+}
+
+func (m *stdioMux) forget(id string) {
+	m.mu.Lock()
+	delete(m.pending, id)
+	m.mu.Unlock()
+}
+
+func (m *stdioMux) isClosed() bool {
+	select {
+	case <-m.closed:
+
+		return true
+	default:
+
+		return false
+	}
+}
+
+// shutdown closes the session and fails every waiting request; the next request starts a new one
+func (m *stdioMux) shutdown(err error) {
+	m.closeOnce.Do(func() {
+		m.mu.Lock()
+		m.closeErr = err
+		close(m.closed)
+		m.pending = make(map[string]chan map[string]interface{})
+		m.mu.Unlock()
+		if m.transport != nil {
+			_ = m.transport.Close()
+		}
+	})
+}
+
+// closeStdioMuxes shuts down every shared session, as on reload or proxy shutdown
+func (h *ProxyHandler) closeStdioMuxes() int {
+	h.stdioMuxesMu.Lock()
+	muxes := h.stdioMuxes
+	h.stdioMuxes = make(map[string]*stdioMux)
+	h.stdioMuxesMu.Unlock()
+
+	for name, mux := range muxes {
+		h.logger.Debug("Closing shared STDIO session for %s", name)
+		mux.shutdown(fmt.Errorf("session closed by proxy"))
+	}
+
+	return len(muxes)
+}
+
+// handleMultiplexedSTDIORequest forwards a client request over the server's shared session.
+// The session is initialized once, so client initialize calls are answered from its result.
+func (h *ProxyHandler) handleMultiplexedSTDIORequest(w http.ResponseWriter, r *http.Request, serverName string, serverConfig config.ServerConfig, requestPayload map[string]interface{}, reqIDVal interface{}, reqMethodVal string) {
+	mux, err := h.getStdioMux(serverName, serverConfig)
+	if err != nil {
+		h.logger.Error("Failed to get shared STDIO session for %s: %v", serverName, err)
+		isTimeout := strings.Contains(err.Error(), "timeout")
+		h.recordConnectionEvent(serverName, false, isTimeout)
+		h.sendMCPError(w, reqIDVal, -32001, fmt.Sprintf("Cannot connect to server '%s'", serverName))
+
+		return
+	}
+
+	if reqIDVal == nil {
+		if reqMethodVal != "notifications/initialized" {
+			if err := mux.send(requestPayload); err != nil {
+				h.logger.Warning("Failed to forward %s to %s: %v", reqMethodVal, serverName, err)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+
+		return
+	}
+
+	if reqMethodVal == "initialize" {
+		h.recordConnectionEvent(serverName, true, false)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      reqIDVal,
+			"result":  mux.initResult,
+		})
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), constants.HTTPStreamTimeout)
+	defer cancel()
+
+	response, err := mux.request(ctx, requestPayload)
+	if err != nil {
+		h.logger.Error("Failed to communicate with %s: %v", serverName, err)
+		isTimeout := strings.Contains(err.Error(), "timeout")
+		h.recordConnectionEvent(serverName, false, isTimeout)
+		if isTimeout {
+			h.sendMCPError(w, reqIDVal, -32000, fmt.Sprintf("Server '%s' request timed out", serverName))
+		} else {
+			h.sendMCPError(w, reqIDVal, -32003, fmt.Sprintf("Error communicating with server '%s'", serverName))
+		}
+
+		return
+	}
+
+	response["id"] = reqIDVal
+	h.recordConnectionEvent(serverName, true, false)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
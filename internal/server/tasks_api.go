@@ -0,0 +1,658 @@
+// internal/server/tasks_api.go
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/cron"
+	"github.com/phildougherty/mcp-compose/internal/events"
+)
+
+// Scopes the task API requires of OAuth tokens and trusted-header identities. The proxy API
+// key carries both; write also grants read.
+const (
+	TasksReadScope  = "mcp:tasks:read"
+	TasksWriteScope = "mcp:tasks:write"
+)
+
+const tasksAPIPath = "/api/tasks"
+
+// taskKinds are the task types created through POST /api/tasks/<kind>, each by its own tool
+var taskKinds = map[string]bool{"ai": true, "manual": true, "dependency": true, "watcher": true}
+
+var taskIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:-]*$`)
+
+// taskRoute is the scheduler tool call that serves a task API request
+type taskRoute struct {
+	tool  string
+	args  map[string]interface{}
+	write bool   // needs TasksWriteScope
+	body  bool   // the request body holds tool arguments, checked against the tool's schema
+	list  string // the result is paginated and returned under this key
+	// preview answers with the next run times of a schedule: the task's when tool is set,
+	// otherwise the expression in the schedule query parameter
+	preview bool
+}
+
+// resolveTaskRoute maps a task API method and path to a scheduler tool call. On failure it
+// returns the HTTP status to answer with.
+func resolveTaskRoute(method, path string) (*taskRoute, int, error) {
+	rest := strings.Trim(strings.TrimPrefix(path, tasksAPIPath), "/")
+	var segments []string
+	if rest != "" {
+		segments = strings.Split(rest, "/")
+	}
+
+	allow := func(allowed string) (*taskRoute, int, error) {
+
+		return nil, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed on %s, use %s", method, path, allowed)
+	}
+
+	switch {
+	case len(segments) == 0:
+		switch method {
+		case http.MethodGet:
+
+			return &taskRoute{tool: "list_tasks", list: "tasks"}, 0, nil
+		case http.MethodPost:
+
+			return &taskRoute{tool: "add_task", write: true, body: true}, 0, nil
+		}
+
+		return allow("GET or POST")
+	case len(segments) == 1 && segments[0] == "runs":
+		if method != http.MethodGet {
+
+			return allow("GET")
+		}
+
+		return &taskRoute{tool: "list_run_status", list: "runs"}, 0, nil
+	case len(segments) == 1 && segments[0] == "metrics":
+		if method != http.MethodGet {
+
+			return allow("GET")
+		}
+
+		return &taskRoute{tool: "get_metrics"}, 0, nil
+	case len(segments) == 1 && segments[0] == "schedule-preview":
+		if method != http.MethodGet {
+
+			return allow("GET")
+		}
+
+		return &taskRoute{preview: true}, 0, nil
+	case len(segments) == 1 && method == http.MethodPost && taskKinds[segments[0]]:
+
+		return &taskRoute{tool: "add_" + segments[0] + "_task", write: true, body: true}, 0, nil
+	}
+
+	id := segments[0]
+	if !taskIDPattern.MatchString(id) {
+
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid task id '%s'", id)
+	}
+	idArgs := map[string]interface{}{"id": id}
+
+	if len(segments) == 1 {
+		switch method {
+		case http.MethodGet:
+
+			return &taskRoute{tool: "get_task", args: idArgs}, 0, nil
+		case http.MethodPut:
+
+			return &taskRoute{tool: "update_task", args: idArgs, write: true, body: true}, 0, nil
+		case http.MethodDelete:
+
+			return &taskRoute{tool: "remove_task", args: idArgs, write: true}, 0, nil
+		}
+
+		return allow("GET, PUT or DELETE")
+	}
+	if len(segments) == 2 {
+		switch segments[1] {
+		case "run", "enable", "disable":
+			if method != http.MethodPost {
+
+				return allow("POST")
+			}
+
+			return &taskRoute{tool: segments[1] + "_task", args: idArgs, write: true}, 0, nil
+		case "runs":
+			if method != http.MethodGet {
+
+				return allow("GET")
+			}
+
+			return &taskRoute{tool: "get_run_output", args: map[string]interface{}{"task_id": id}, list: "runs"}, 0, nil
+		case "schedule-preview":
+			if method != http.MethodGet {
+
+				return allow("GET")
+			}
+
+			return &taskRoute{tool: "get_task", args: idArgs, preview: true}, 0, nil
+		}
+	}
+
+	return nil, http.StatusNotFound, fmt.Errorf("unknown task API endpoint %s", path)
+}
+
+// handleTasksAPI serves /api/tasks, a REST front for the task scheduler's tools
+func (h *ProxyHandler) handleTasksAPI(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateScopedRequest(w, r) {
+
+		return
+	}
+
+	route, status, err := resolveTaskRoute(r.Method, path)
+	if err != nil {
+		writeAPIError(w, status, err.Error())
+
+		return
+	}
+	scope := TasksReadScope
+	if route.write {
+		scope = TasksWriteScope
+	}
+	if !h.scopeGranted(r, scope, TasksWriteScope) {
+		publishAuthDenied(r, taskSchedulerService{}.Name(), "task API scope not granted")
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("scope %s is required", scope))
+
+		return
+	}
+
+	serverName := taskSchedulerService{}.Name()
+	instance, exists := h.Manager.GetServerInstance(serverName)
+
+	count, location, err := parsePreviewOptions(r.URL.Query(), instance)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+	if route.preview && route.tool == "" {
+		expr := r.URL.Query().Get("schedule")
+		runs, err := schedulePreview(expr, location, count)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"schedule": expr, "timezone": location.String(), "next_runs": runs})
+
+		return
+	}
+
+	if !exists {
+		writeAPIError(w, http.StatusServiceUnavailable, "the task scheduler is not enabled")
+
+		return
+	}
+
+	limit, offset := 0, 0
+	if route.list != "" {
+		if limit, offset, err = parsePage(r.URL.Query()); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+	}
+
+	args := make(map[string]interface{})
+	if route.body {
+		if err := json.NewDecoder(io.LimitReader(r.Body, constants.TasksAPIMaxBodySize)).Decode(&args); err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("request body must be a JSON object: %v", err))
+
+			return
+		}
+	}
+	for key, value := range route.args {
+		args[key] = value
+	}
+	if route.body {
+		schema, found, err := h.taskToolSchema(r, instance, route.tool)
+		if err != nil {
+			writeAPIError(w, http.StatusBadGateway, err.Error())
+
+			return
+		}
+		if !found {
+			writeAPIError(w, http.StatusNotImplemented, fmt.Sprintf("the task scheduler has no %s tool", route.tool))
+
+			return
+		}
+		if err := validateToolArguments(schema, args); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+	}
+	// Catch a bad schedule before the scheduler stores the task
+	var nextRuns []string
+	if expr, _ := args["schedule"].(string); route.body && expr != "" {
+		if nextRuns, err = schedulePreview(expr, location, count); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+	}
+
+	events.Publish(events.Event{
+		Type:    events.ToolCalled,
+		Server:  serverName,
+		Client:  getClientIP(r),
+		Message: fmt.Sprintf("Task API: %s %s", r.Method, path),
+		Details: map[string]interface{}{"tool": route.tool},
+	})
+
+	result, status, err := h.callTaskTool(r, instance, route.tool, args)
+	if err != nil {
+		writeAPIError(w, status, err.Error())
+
+		return
+	}
+
+	if route.preview {
+		task, _ := result.(map[string]interface{})
+		expr, _ := task["schedule"].(string)
+		if expr == "" {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("task '%s' has no cron schedule", route.args["id"]))
+
+			return
+		}
+		runs, err := schedulePreview(expr, location, count)
+		if err != nil {
+			writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+
+			return
+		}
+		result = map[string]interface{}{"id": route.args["id"], "schedule": expr, "timezone": location.String(), "next_runs": runs}
+	}
+	if task, ok := result.(map[string]interface{}); ok && nextRuns != nil {
+		task["next_runs"] = nextRuns
+	}
+
+	if route.list != "" {
+		items, isList := listItems(result, route.list)
+		if !isList {
+			writeAPIError(w, http.StatusBadGateway, fmt.Sprintf("%s did not return a list", route.tool))
+
+			return
+		}
+		page := map[string]interface{}{route.list: []interface{}{}, "total": len(items), "offset": offset, "limit": limit}
+		if offset < len(items) {
+			end := offset + limit
+			if end > len(items) {
+				end = len(items)
+			}
+			page[route.list] = items[offset:end]
+			if end < len(items) {
+				page["next_offset"] = end
+			}
+		}
+		result = page
+	}
+
+	if route.body && r.Method == http.MethodPost {
+		w.WriteHeader(http.StatusCreated)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// authenticateScopedRequest accepts the proxy API key, an OAuth access token or a trusted
+// identity. Unlike other API endpoints the task and admin APIs take OAuth tokens, whose
+// scopes are then checked.
+func (h *ProxyHandler) authenticateScopedRequest(w http.ResponseWriter, r *http.Request) bool {
+	if handled, ok := h.authenticateTrustedHeaders(w, r); handled {
+
+		return ok
+	}
+
+	apiKey := h.getAPIKeyToCheck()
+	oauth := h.oauthEnabled && h.authServer != nil
+	if apiKey == "" && !oauth {
+
+		return true
+	}
+
+	token := h.extractBearerToken(r)
+	if apiKey != "" && token == apiKey {
+		*r = *r.WithContext(context.WithValue(r.Context(), auth.AuthTypeContextKey, "api_key"))
+
+		return true
+	}
+	if oauth && token != "" {
+		if accessToken, err := h.validateOAuthToken(token); err == nil && accessToken != nil {
+			ctx := context.WithValue(r.Context(), auth.TokenContextKey, accessToken)
+			ctx = context.WithValue(ctx, auth.UserContextKey, accessToken.UserID)
+			ctx = context.WithValue(ctx, auth.ScopeContextKey, accessToken.Scope)
+			ctx = context.WithValue(ctx, auth.AuthTypeContextKey, "oauth")
+			*r = *r.WithContext(ctx)
+
+			return true
+		}
+	}
+
+	h.authFailed(r, "", "invalid access token or API key")
+	if token == "" {
+		h.sendAuthenticationError(w, "missing_token", "Access token required")
+	} else {
+		h.sendAuthenticationError(w, "invalid_token", "Invalid access token or API key")
+	}
+
+	return false
+}
+
+// scopeGranted reports whether the caller may use an endpoint needing scope, which
+// writeScope also grants. Without any authentication configured every caller may.
+func (h *ProxyHandler) scopeGranted(r *http.Request, scope, writeScope string) bool {
+	if authType, _ := r.Context().Value(auth.AuthTypeContextKey).(string); authType == "" {
+
+		return true
+	}
+	hasScope := h.callerScopeChecker(r)
+
+	return hasScope(scope) || hasScope(writeScope)
+}
+
+// callTaskTool calls a scheduler tool and returns its result, decoded from JSON when the
+// tool answered with JSON text. The task API has already authorized the caller, so the call
+// goes straight to the server's transport.
+func (h *ProxyHandler) callTaskTool(r *http.Request, instance *ServerInstance, tool string, args map[string]interface{}) (interface{}, int, error) {
+	result, err := h.callServerMethod(r, instance, "tools/call", map[string]interface{}{"name": tool, "arguments": args})
+	if err != nil {
+
+		return nil, http.StatusBadGateway, err
+	}
+
+	var toolResult struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := json.Unmarshal(result, &toolResult); err != nil {
+
+		return nil, http.StatusBadGateway, fmt.Errorf("unexpected %s result: %w", tool, err)
+	}
+	var text strings.Builder
+	for _, content := range toolResult.Content {
+		if content.Type == "text" {
+			text.WriteString(content.Text)
+		}
+	}
+	if toolResult.IsError {
+		status := http.StatusBadRequest
+		if strings.Contains(strings.ToLower(text.String()), "not found") {
+			status = http.StatusNotFound
+		}
+
+		return nil, status, fmt.Errorf("%s", strings.TrimSpace(text.String()))
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(text.String()), &decoded); err != nil {
+
+		return map[string]interface{}{"result": text.String()}, http.StatusOK, nil
+	}
+
+	return decoded, http.StatusOK, nil
+}
+
+// taskToolSchema returns the input schema of a scheduler tool and whether the tool exists
+func (h *ProxyHandler) taskToolSchema(r *http.Request, instance *ServerInstance, tool string) (map[string]interface{}, bool, error) {
+	result, err := h.callServerMethod(r, instance, "tools/list", nil)
+	if err != nil {
+
+		return nil, false, err
+	}
+	var list struct {
+		Tools []struct {
+			Name        string                 `json:"name"`
+			InputSchema map[string]interface{} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(result, &list); err != nil {
+
+		return nil, false, fmt.Errorf("unexpected tools/list result: %w", err)
+	}
+	for _, candidate := range list.Tools {
+		if candidate.Name == tool {
+
+			return candidate.InputSchema, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// callServerMethod sends one JSON-RPC request to a server and returns its result
+func (h *ProxyHandler) callServerMethod(r *http.Request, instance *ServerInstance, method string, params map[string]interface{}) (json.RawMessage, error) {
+	reqID := h.getNextRequestID()
+	payload := map[string]interface{}{"jsonrpc": "2.0", "id": reqID, "method": method}
+	if params != nil {
+		payload["params"] = params
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	recorder := &mcpResponseRecorder{statusCode: http.StatusOK, headers: make(http.Header)}
+	h.dispatchToTransport(recorder, r, instance.Name, instance, body, payload, reqID, method)
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *MCPError       `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.body, &response); err != nil {
+
+		return nil, fmt.Errorf("server '%s' did not answer %s (HTTP %d)", instance.Name, method, recorder.statusCode)
+	}
+	if response.Error != nil {
+
+		return nil, fmt.Errorf("server '%s' failed %s: %s", instance.Name, method, response.Error.Message)
+	}
+
+	return response.Result, nil
+}
+
+// validateToolArguments checks arguments against the parts of a tool's JSON Schema that
+// matter for a REST caller: required properties, unknown properties when the schema
+// forbids them, and the types and enums of the properties it declares
+func validateToolArguments(schema map[string]interface{}, args map[string]interface{}) error {
+	properties, _ := schema["properties"].(map[string]interface{})
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			field, _ := name.(string)
+			if _, present := args[field]; field != "" && !present {
+
+				return fmt.Errorf("missing required field '%s'", field)
+			}
+		}
+	}
+
+	for name, value := range args {
+		property, declared := properties[name].(map[string]interface{})
+		if !declared {
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+
+				return fmt.Errorf("unknown field '%s'", name)
+			}
+
+			continue
+		}
+		if expected, ok := property["type"].(string); ok && !jsonTypeMatches(expected, value) {
+
+			return fmt.Errorf("field '%s' must be of type %s", name, expected)
+		}
+		if enum, ok := property["enum"].([]interface{}); ok {
+			allowed := false
+			for _, option := range enum {
+				if option == value {
+					allowed = true
+				}
+			}
+			if !allowed {
+
+				return fmt.Errorf("field '%s' must be one of %v", name, enum)
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonTypeMatches reports whether a decoded JSON value has a JSON Schema type
+func jsonTypeMatches(expected string, value interface{}) bool {
+	switch expected {
+	case "string":
+		_, ok := value.(string)
+
+		return ok
+	case "number":
+		_, ok := value.(float64)
+
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+
+		return ok && number == float64(int64(number))
+	case "boolean":
+		_, ok := value.(bool)
+
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+
+		return ok
+	case "null":
+
+		return value == nil
+	}
+
+	return true
+}
+
+// schedulePreview validates a cron expression and returns the next count times it fires,
+// formatted as RFC 3339 in location
+func schedulePreview(expr string, location *time.Location, count int) ([]string, error) {
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+
+		return nil, fmt.Errorf("invalid schedule '%s': %w", expr, err)
+	}
+	times := schedule.NextN(time.Now().In(location), count)
+	if len(times) == 0 {
+
+		return nil, fmt.Errorf("schedule '%s' never fires", expr)
+	}
+	runs := make([]string, len(times))
+	for i, next := range times {
+		runs[i] = next.Format(time.RFC3339)
+	}
+
+	return runs, nil
+}
+
+// parsePreviewOptions reads the count and timezone query parameters of a schedule preview.
+// The timezone defaults to the scheduler's own, from its TZ variable.
+func parsePreviewOptions(query url.Values, instance *ServerInstance) (int, *time.Location, error) {
+	count := constants.TasksAPIPreviewCount
+	if value := query.Get("count"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > constants.TasksAPIMaxPreviewCount {
+
+			return 0, nil, fmt.Errorf("count must be between 1 and %d", constants.TasksAPIMaxPreviewCount)
+		}
+		count = parsed
+	}
+
+	timezone := query.Get("timezone")
+	if timezone == "" && instance != nil {
+		timezone = instance.Config.Env["TZ"]
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+
+		return 0, nil, fmt.Errorf("unknown timezone '%s'", timezone)
+	}
+
+	return count, location, nil
+}
+
+// parsePage reads the limit and offset query parameters
+func parsePage(query url.Values) (int, int, error) {
+	limit, offset := constants.TasksAPIPageSize, 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > constants.TasksAPIMaxPageSize {
+
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", constants.TasksAPIMaxPageSize)
+		}
+		limit = parsed
+	}
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+		offset = parsed
+	}
+
+	return limit, offset, nil
+}
+
+// listItems finds the list in a tool result: the result itself, or its field named key or
+// holding the only list
+func listItems(result interface{}, key string) ([]interface{}, bool) {
+	switch value := result.(type) {
+	case []interface{}:
+
+		return value, true
+	case map[string]interface{}:
+		if items, ok := value[key].([]interface{}); ok {
+
+			return items, true
+		}
+		var found []interface{}
+		lists := 0
+		for _, field := range value {
+			if items, ok := field.([]interface{}); ok {
+				found = items
+				lists++
+			}
+		}
+
+		return found, lists == 1
+	case nil:
+
+		return []interface{}{}, true
+	}
+
+	return nil, false
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
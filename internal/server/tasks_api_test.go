@@ -0,0 +1,170 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolveTaskRoute(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		tool       string
+		args       map[string]interface{}
+		write      bool
+		list       string
+		expectCode int
+	}{
+		{name: "list tasks", method: http.MethodGet, path: "/api/tasks", tool: "list_tasks", list: "tasks"},
+		{name: "add task", method: http.MethodPost, path: "/api/tasks", tool: "add_task", write: true},
+		{name: "add ai task", method: http.MethodPost, path: "/api/tasks/ai", tool: "add_ai_task", write: true},
+		{name: "run status", method: http.MethodGet, path: "/api/tasks/runs", tool: "list_run_status", list: "runs"},
+		{name: "metrics", method: http.MethodGet, path: "/api/tasks/metrics", tool: "get_metrics"},
+		{name: "get task", method: http.MethodGet, path: "/api/tasks/task_1", tool: "get_task", args: map[string]interface{}{"id": "task_1"}},
+		{name: "delete task", method: http.MethodDelete, path: "/api/tasks/task_1", tool: "remove_task", args: map[string]interface{}{"id": "task_1"}, write: true},
+		{name: "run task", method: http.MethodPost, path: "/api/tasks/task_1/run", tool: "run_task", args: map[string]interface{}{"id": "task_1"}, write: true},
+		{name: "task runs", method: http.MethodGet, path: "/api/tasks/task_1/runs", tool: "get_run_output", args: map[string]interface{}{"task_id": "task_1"}, list: "runs"},
+		{name: "preview expression", method: http.MethodGet, path: "/api/tasks/schedule-preview"},
+		{name: "preview task", method: http.MethodGet, path: "/api/tasks/task_1/schedule-preview", tool: "get_task", args: map[string]interface{}{"id": "task_1"}},
+		{name: "wrong method", method: http.MethodDelete, path: "/api/tasks", expectCode: http.StatusMethodNotAllowed},
+		{name: "read-only runs", method: http.MethodPost, path: "/api/tasks/runs", expectCode: http.StatusMethodNotAllowed},
+		{name: "kind is not an id", method: http.MethodGet, path: "/api/tasks/ai", tool: "get_task", args: map[string]interface{}{"id": "ai"}},
+		{name: "invalid id", method: http.MethodGet, path: "/api/tasks/-bad", expectCode: http.StatusBadRequest},
+		{name: "unknown action", method: http.MethodPost, path: "/api/tasks/task_1/pause", expectCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, code, err := resolveTaskRoute(tt.method, tt.path)
+			if tt.expectCode != 0 {
+				if err == nil || code != tt.expectCode {
+					t.Errorf("Expected status %d, got %d (err: %v)", tt.expectCode, code, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if route.tool != tt.tool || route.write != tt.write || route.list != tt.list {
+				t.Errorf("Unexpected route %+v", route)
+			}
+			if len(tt.args) > 0 && !reflect.DeepEqual(route.args, tt.args) {
+				t.Errorf("Expected args %v, got %v", tt.args, route.args)
+			}
+		})
+	}
+}
+
+func TestValidateToolArguments(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name":     map[string]interface{}{"type": "string"},
+			"schedule": map[string]interface{}{"type": "string"},
+			"timeout":  map[string]interface{}{"type": "integer"},
+			"type":     map[string]interface{}{"type": "string", "enum": []interface{}{"shell_command", "AI"}},
+		},
+		"required":             []interface{}{"name", "schedule"},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expectErr bool
+	}{
+		{name: "valid", args: map[string]interface{}{"name": "backup", "schedule": "0 * * * *", "timeout": float64(60)}},
+		{name: "missing required", args: map[string]interface{}{"name": "backup"}, expectErr: true},
+		{name: "wrong type", args: map[string]interface{}{"name": "backup", "schedule": float64(5)}, expectErr: true},
+		{name: "not an integer", args: map[string]interface{}{"name": "backup", "schedule": "@daily", "timeout": 1.5}, expectErr: true},
+		{name: "enum", args: map[string]interface{}{"name": "backup", "schedule": "@daily", "type": "AI"}},
+		{name: "outside enum", args: map[string]interface{}{"name": "backup", "schedule": "@daily", "type": "python"}, expectErr: true},
+		{name: "unknown field", args: map[string]interface{}{"name": "backup", "schedule": "@daily", "owner": "ops"}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateToolArguments(schema, tt.args)
+			if tt.expectErr && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		expectLimit  int
+		expectOffset int
+		expectErr    bool
+	}{
+		{name: "defaults", query: "", expectLimit: 50, expectOffset: 0},
+		{name: "explicit", query: "limit=10&offset=20", expectLimit: 10, expectOffset: 20},
+		{name: "limit too large", query: "limit=501", expectErr: true},
+		{name: "negative offset", query: "offset=-1", expectErr: true},
+		{name: "not a number", query: "limit=ten", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			limit, offset, err := parsePage(query)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if limit != tt.expectLimit || offset != tt.expectOffset {
+				t.Errorf("Expected limit %d offset %d, got %d and %d", tt.expectLimit, tt.expectOffset, limit, offset)
+			}
+		})
+	}
+}
+
+func TestSchedulePreview(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		count     int
+		expectErr bool
+	}{
+		{name: "valid", expr: "0 9 * * MON-FRI", count: 3},
+		{name: "descriptor", expr: "@hourly", count: 1},
+		{name: "invalid", expr: "0 25 * * *", count: 3, expectErr: true},
+		{name: "never fires", expr: "0 0 31 2 *", count: 3, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := schedulePreview(tt.expr, time.UTC, tt.count)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(runs) != tt.count {
+				t.Errorf("Expected %d runs, got %v", tt.count, runs)
+			}
+		})
+	}
+}
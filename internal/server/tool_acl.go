@@ -0,0 +1,96 @@
+// internal/server/tool_acl.go
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/phildougherty/mcp-compose/internal/auth"
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+// checkToolACL decides whether a tool may be called under a server's tools_acl.
+// hasScope reports whether the caller was granted the given scope.
+func checkToolACL(acl *config.ToolACLConfig, toolName string, hasScope func(scope string) bool) error {
+	if acl == nil {
+
+		return nil
+	}
+
+	for _, denied := range acl.Deny {
+		if denied == toolName {
+
+			return fmt.Errorf("tool '%s' is denied by server policy", toolName)
+		}
+	}
+
+	if len(acl.Allow) > 0 {
+		allowed := false
+		for _, name := range acl.Allow {
+			if name == toolName {
+				allowed = true
+
+				break
+			}
+		}
+		if !allowed {
+
+			return fmt.Errorf("tool '%s' is not in the server's allow list", toolName)
+		}
+	}
+
+	if requiredScope, exists := acl.RequireScope[toolName]; exists && !hasScope(requiredScope) {
+
+		return fmt.Errorf("tool '%s' requires scope '%s'", toolName, requiredScope)
+	}
+
+	return nil
+}
+
+// authorizeToolCall enforces the server's tools_acl on a tools/call request.
+// It writes a JSON-RPC error and returns false when the call is not permitted.
+func (h *ProxyHandler) authorizeToolCall(w http.ResponseWriter, r *http.Request, serverName string, requestPayload map[string]interface{}, reqIDVal interface{}) bool {
+	if method, _ := requestPayload["method"].(string); method != "tools/call" {
+
+		return true
+	}
+
+	serverConfig, exists := h.Manager.Config().Servers[serverName]
+	if !exists || serverConfig.ToolsACL == nil {
+
+		return true
+	}
+
+	params, _ := requestPayload["params"].(map[string]interface{})
+	toolName, _ := params["name"].(string)
+	if toolName == "" {
+		h.sendMCPError(w, reqIDVal, -32602, "tools/call requires a tool name")
+
+		return false
+	}
+
+	if err := checkToolACL(serverConfig.ToolsACL, toolName, h.callerScopeChecker(r)); err != nil {
+		h.logger.Warning("Blocked tools/call to %s on server %s: %v", toolName, serverName, err)
+		publishAuthDenied(r, serverName, err.Error())
+		h.sendMCPError(w, reqIDVal, -32001, "Forbidden", err.Error())
+
+		return false
+	}
+
+	return true
+}
+
+// callerScopeChecker reports whether the authenticated caller of r holds a scope
+func (h *ProxyHandler) callerScopeChecker(r *http.Request) func(scope string) bool {
+
+	return func(scope string) bool {
+		// The proxy API key is the operator credential and carries every scope
+		if authType, _ := r.Context().Value(auth.AuthTypeContextKey).(string); authType == "api_key" {
+
+			return true
+		}
+		tokenScope, _ := r.Context().Value(auth.ScopeContextKey).(string)
+
+		return h.hasRequiredScope(tokenScope, scope)
+	}
+}
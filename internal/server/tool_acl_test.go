@@ -0,0 +1,56 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestCheckToolACL(t *testing.T) {
+	acl := &config.ToolACLConfig{
+		Deny:         []string{"delete_file"},
+		RequireScope: map[string]string{"run_task": "mcp:admin"},
+	}
+	allowOnly := &config.ToolACLConfig{
+		Allow: []string{"read_file"},
+	}
+
+	tests := []struct {
+		name      string
+		acl       *config.ToolACLConfig
+		tool      string
+		scopes    []string
+		expectErr bool
+	}{
+		{name: "no acl", acl: nil, tool: "anything", expectErr: false},
+		{name: "denied tool", acl: acl, tool: "delete_file", scopes: []string{"mcp:admin"}, expectErr: true},
+		{name: "unrestricted tool", acl: acl, tool: "read_file", expectErr: false},
+		{name: "scope missing", acl: acl, tool: "run_task", scopes: []string{"mcp:tools"}, expectErr: true},
+		{name: "scope granted", acl: acl, tool: "run_task", scopes: []string{"mcp:admin"}, expectErr: false},
+		{name: "allow list hit", acl: allowOnly, tool: "read_file", expectErr: false},
+		{name: "allow list miss", acl: allowOnly, tool: "write_file", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasScope := func(scope string) bool {
+				for _, s := range tt.scopes {
+					if s == scope {
+
+						return true
+					}
+				}
+
+				return false
+			}
+
+			err := checkToolACL(tt.acl, tt.tool, hasScope)
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected tool '%s' to be blocked", tt.tool)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected tool '%s' to be allowed, got: %v", tt.tool, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// internal/server/tool_bridge.go
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+)
+
+// bridgeTool is a tool as the REST bridge lists it
+type bridgeTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+	Path        string                 `json:"path"`
+}
+
+// bridgeTools lists the tools of a server the caller may call, following tools/list cursors
+func (h *ProxyHandler) bridgeTools(r *http.Request, serverName string, instance *ServerInstance) ([]bridgeTool, error) {
+	acl := h.Manager.Config().Servers[serverName].ToolsACL
+	hasScope := h.callerScopeChecker(r)
+
+	tools := make([]bridgeTool, 0)
+	var params map[string]interface{}
+	for page := 0; page < constants.ToolBridgeMaxListPages; page++ {
+		result, err := h.callServerMethod(r, instance, "tools/list", params)
+		if err != nil {
+
+			return nil, err
+		}
+		var list struct {
+			Tools      []bridgeTool `json:"tools"`
+			NextCursor string       `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(result, &list); err != nil {
+
+			return nil, fmt.Errorf("unexpected tools/list result from %s: %w", serverName, err)
+		}
+		for _, tool := range list.Tools {
+			if checkToolACL(acl, tool.Name, hasScope) != nil {
+
+				continue
+			}
+			if tool.InputSchema == nil {
+				tool.InputSchema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+			}
+			tool.Path = fmt.Sprintf("/%s/tools/%s", serverName, tool.Name)
+			tools = append(tools, tool)
+		}
+		if list.NextCursor == "" {
+
+			break
+		}
+		params = map[string]interface{}{"cursor": list.NextCursor}
+	}
+
+	return tools, nil
+}
+
+// handleToolBridge serves a server's tools over plain HTTP: GET /{server}/tools lists them
+// and POST /{server}/tools/{tool} calls one with the JSON request body as its arguments,
+// checked against the tool's input schema first
+func (h *ProxyHandler) handleToolBridge(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance, toolName string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.authenticateRequest(w, r, serverName, instance) {
+
+		return
+	}
+
+	if toolName == "" {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use GET")
+
+			return
+		}
+		tools, err := h.bridgeTools(r, serverName, instance)
+		if err != nil {
+			writeAPIError(w, http.StatusBadGateway, err.Error())
+
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"server": serverName, "tools": tools})
+
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed - use POST")
+
+		return
+	}
+	body, ok := h.readRequestBody(w, r, serverName)
+	if !ok {
+
+		return
+	}
+	arguments := make(map[string]interface{})
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &arguments); err != nil {
+			writeValidationError(w, fmt.Sprintf("request body must be a JSON object: %v", err))
+
+			return
+		}
+	}
+
+	tools, err := h.bridgeTools(r, serverName, instance)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err.Error())
+
+		return
+	}
+	var tool *bridgeTool
+	for i := range tools {
+		if tools[i].Name == toolName {
+			tool = &tools[i]
+
+			break
+		}
+	}
+	if tool == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Sprintf("server '%s' has no tool '%s'", serverName, toolName))
+
+		return
+	}
+	if err := validateToolArguments(tool.InputSchema, arguments); err != nil {
+		writeValidationError(w, err.Error())
+
+		return
+	}
+
+	h.forwardToolCall(w, r, serverName, instance, toolName, arguments)
+}
+
+// writeValidationError answers in the shape FastAPI uses, which OpenAPI tool clients such as
+// OpenWebUI expect
+func writeValidationError(w http.ResponseWriter, message string) {
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"detail": []map[string]interface{}{{"loc": []string{"body"}, "msg": message, "type": "value_error"}},
+	})
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+func TestToolBridge(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		var result string
+		switch request["method"] {
+		case "tools/list":
+			params, _ := request["params"].(map[string]interface{})
+			if params["cursor"] == "2" {
+				result = `{"tools":[{"name":"delete_file","inputSchema":{"type":"object"}}]}`
+			} else {
+				result = `{"tools":[{"name":"read_file","inputSchema":{"type":"object","properties":{"path":{"type":"string"}},"required":["path"]}}],"nextCursor":"2"}`
+			}
+		case "tools/call":
+			params, _ := request["params"].(map[string]interface{})
+			arguments, _ := params["arguments"].(map[string]interface{})
+			result = fmt.Sprintf(`{"content":[{"type":"text","text":"read %v"}]}`, arguments["path"])
+		default:
+			result = `{}`
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":%s}`, request["id"], result)
+	}))
+	defer backend.Close()
+
+	h := limitedHandler(nil)
+	h.Manager.Config().Servers["files"] = config.ServerConfig{Protocol: "http", ToolsACL: &config.ToolACLConfig{Deny: []string{"delete_file"}}}
+	h.ctx = context.Background()
+	h.httpClient = http.DefaultClient
+	h.sseClient = http.DefaultClient
+	h.catalog = newCatalogCache()
+	h.responses = newResponseCache()
+	h.ServerConnections = map[string]*MCPHTTPConnection{
+		"files": {ServerName: "files", BaseURL: backend.URL, Initialized: true, Healthy: true},
+	}
+	instance := &ServerInstance{Name: "files"}
+
+	call := func(method, tool, body string) (int, interface{}) {
+		recorder := httptest.NewRecorder()
+		h.handleToolBridge(recorder, httptest.NewRequest(method, "/files/tools/"+tool, strings.NewReader(body)), "files", instance, tool)
+		var decoded interface{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("%s %s: response is not JSON: %s", method, tool, recorder.Body.String())
+		}
+
+		return recorder.Code, decoded
+	}
+
+	status, listed := call(http.MethodGet, "", "")
+	list, _ := listed.(map[string]interface{})
+	tools, _ := list["tools"].([]interface{})
+	if status != http.StatusOK || len(tools) != 1 {
+		t.Fatalf("expected only the allowed tool, got %d %v", status, listed)
+	}
+	if path := tools[0].(map[string]interface{})["path"]; path != "/files/tools/read_file" {
+		t.Errorf("unexpected tool path %v", path)
+	}
+
+	if status, _ := call(http.MethodPost, "read_file", `{}`); status != http.StatusUnprocessableEntity {
+		t.Errorf("missing required argument: expected 422, got %d", status)
+	}
+	if status, _ := call(http.MethodPost, "read_file", `[1]`); status != http.StatusUnprocessableEntity {
+		t.Errorf("non-object body: expected 422, got %d", status)
+	}
+	if status, _ := call(http.MethodPost, "delete_file", `{}`); status != http.StatusNotFound {
+		t.Errorf("denied tool: expected 404, got %d", status)
+	}
+	if status, _ := call(http.MethodGet, "read_file", ""); status != http.StatusMethodNotAllowed {
+		t.Errorf("GET on a tool: expected 405, got %d", status)
+	}
+
+	status, called := call(http.MethodPost, "read_file", `{"path":"/etc/hosts"}`)
+	if status != http.StatusOK || called != "read /etc/hosts" {
+		t.Errorf("unexpected call result %d %v", status, called)
+	}
+}
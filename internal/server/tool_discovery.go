@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/phildougherty/mcp-compose/internal/config"
 	"github.com/phildougherty/mcp-compose/internal/constants"
 	"github.com/phildougherty/mcp-compose/internal/openapi"
 )
@@ -22,7 +23,7 @@ func (h *ProxyHandler) refreshToolCache() {
 	h.logger.Info("Refreshing tool cache...")
 	newCache := make(map[string]string)
 
-	for serverName := range h.Manager.config.Servers {
+	for serverName := range h.Manager.Config().Servers {
 		tools, err := h.discoverServerTools(serverName)
 		if err != nil {
 			h.logger.Warning("Failed to discover tools for %s during cache refresh: %v", serverName, err)
@@ -58,7 +59,7 @@ func (h *ProxyHandler) discoverServerTools(serverName string) ([]openapi.ToolSpe
 		return h.getGenericToolForServer(serverName), nil
 	}
 
-	serverConfig := h.Manager.config.Servers[serverName]
+	serverConfig := h.Manager.Config().Servers[serverName]
 
 	// Determine the transport protocol
 	protocol := serverConfig.Protocol
@@ -90,7 +91,7 @@ func (h *ProxyHandler) discoverServerTools(serverName string) ([]openapi.ToolSpe
 		case "stdio":
 			if serverConfig.StdioHosterPort > 0 {
 				// Use socat TCP connection
-				containerName := fmt.Sprintf("mcp-compose-%s", serverName)
+				containerName := config.ContainerName(serverName)
 				socatHost := containerName
 				socatPort := serverConfig.StdioHosterPort
 				response, err = h.sendRawTCPRequestWithRetry(socatHost, socatPort, toolsRequest, timeout, attempt)
@@ -0,0 +1,107 @@
+// internal/server/tracing.go
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/phildougherty/mcp-compose/internal/telemetry"
+)
+
+// tracingResponseWriter records the outcome of a proxied call on its span
+type tracingResponseWriter struct {
+	http.ResponseWriter
+	span       *telemetry.Span
+	statusCode int
+	written    bool
+}
+
+func (tw *tracingResponseWriter) WriteHeader(statusCode int) {
+	if tw.statusCode == 0 {
+		tw.statusCode = statusCode
+	}
+	tw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (tw *tracingResponseWriter) Write(body []byte) (int, error) {
+	if tw.statusCode == 0 {
+		tw.statusCode = http.StatusOK
+	}
+	if !tw.written {
+		tw.written = true
+		tw.recordRPCError(body)
+	}
+
+	return tw.ResponseWriter.Write(body)
+}
+
+func (tw *tracingResponseWriter) Flush() {
+	if flusher, ok := tw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// recordRPCError marks the span failed when the response is a JSON-RPC error
+func (tw *tracingResponseWriter) recordRPCError(body []byte) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+
+		return
+	}
+
+	var envelope struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(trimmed, &envelope) == nil && envelope.Error != nil {
+		tw.span.SetAttribute("rpc.jsonrpc.error_code", envelope.Error.Code)
+		tw.span.SetError(envelope.Error.Message)
+	}
+}
+
+func (tw *tracingResponseWriter) finish() {
+	if tw.statusCode == 0 {
+		tw.statusCode = http.StatusOK
+	}
+	tw.span.SetAttribute("http.status_code", tw.statusCode)
+	if tw.statusCode >= http.StatusInternalServerError {
+		tw.span.SetError(http.StatusText(tw.statusCode))
+	}
+	tw.span.End(nil)
+}
+
+// startProxySpan starts the server span for a proxied JSON-RPC call, joining any trace
+// the client propagated in its traceparent header
+func (h *ProxyHandler) startProxySpan(w http.ResponseWriter, r *http.Request, serverName, transport, method string, requestPayload map[string]interface{}) (*tracingResponseWriter, *http.Request) {
+	ctx := telemetry.Extract(r.Context(), r.Header)
+	ctx, span := h.Manager.tracer.StartSpan(ctx, "mcp.proxy "+method, telemetry.SpanKindServer)
+	span.SetAttribute("rpc.system", "jsonrpc")
+	span.SetAttribute("rpc.method", method)
+	span.SetAttribute("mcp.server", serverName)
+	span.SetAttribute("mcp.transport", transport)
+	if method == "tools/call" {
+		if params, ok := requestPayload["params"].(map[string]interface{}); ok {
+			if toolName, ok := params["name"].(string); ok {
+				span.SetAttribute("mcp.tool", toolName)
+			}
+		}
+	}
+
+	return &tracingResponseWriter{ResponseWriter: w, span: span}, r.WithContext(ctx)
+}
+
+// tracedUpstreamHeaders returns the headers to add to a backend HTTP request: any set by
+// middleware plus the W3C trace context of the current span
+func tracedUpstreamHeaders(ctx context.Context) http.Header {
+	headers := upstreamHeadersFromContext(ctx).Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	telemetry.Inject(ctx, headers)
+
+	return headers
+}
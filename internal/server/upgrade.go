@@ -0,0 +1,146 @@
+// internal/server/upgrade.go
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/runtime"
+)
+
+// ProxyListener is the socket a native proxy serves on. Proxies share their port, so a
+// new one started with --upgrade can bind it while the old one drains its connections.
+type ProxyListener struct {
+	net.Listener
+	port    int
+	pidFile string
+	oldPID  int
+}
+
+// ProxyPIDFile returns the file recording the PID of the proxy serving port
+func ProxyPIDFile(port int) string {
+
+	return filepath.Join(runtime.RunDir(), fmt.Sprintf("proxy-%d.pid", port))
+}
+
+// drainFile holds the drain timeout a proxy taking over port asks the old one to use
+func drainFile(port int) string {
+
+	return filepath.Join(runtime.RunDir(), fmt.Sprintf("proxy-%d.drain", port))
+}
+
+// DrainTimeout returns the drain timeout the proxy that took over port asked for, or
+// fallback when it did not ask for one
+func DrainTimeout(port int, fallback time.Duration) time.Duration {
+	data, err := os.ReadFile(drainFile(port))
+	if err != nil {
+
+		return fallback
+	}
+	_ = os.Remove(drainFile(port))
+	timeout, err := time.ParseDuration(strings.TrimSpace(string(data)))
+	if err != nil || timeout <= 0 {
+
+		return fallback
+	}
+
+	return timeout
+}
+
+// RunningProxy returns the PID of the proxy serving port, or 0 when there is none
+func RunningProxy(port int) int {
+	data, err := os.ReadFile(ProxyPIDFile(port))
+	if err != nil {
+
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 || pid == os.Getpid() || !runtime.ProcessAlive(pid) {
+
+		return 0
+	}
+
+	return pid
+}
+
+// ListenProxy opens the proxy's listener on port of the host interface. Without upgrade it refuses to start next
+// to a running proxy; with upgrade a proxy must be running, and it is told to drain once
+// Activate is called.
+func ListenProxy(host string, port int, upgrade bool) (*ProxyListener, error) {
+	oldPID := RunningProxy(port)
+	switch {
+	case upgrade && oldPID == 0:
+
+		return nil, fmt.Errorf("no proxy is running on port %d to upgrade", port)
+	case upgrade && !upgradeSupported:
+
+		return nil, fmt.Errorf("proxy --upgrade is not supported on this platform")
+	case !upgrade && oldPID != 0:
+
+		return nil, fmt.Errorf("a proxy (PID %d) is already running on port %d; use --upgrade to replace it", oldPID, port)
+	}
+
+	listener, err := listenShared(net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to listen on port %d: %w", port, err)
+	}
+
+	return &ProxyListener{Listener: listener, port: port, pidFile: ProxyPIDFile(port), oldPID: oldPID}, nil
+}
+
+// ListenShared listens on addr the way the proxy does, so a proxy replacing this one can
+// bind addr as well
+func ListenShared(addr string) (net.Listener, error) {
+
+	return listenShared(addr)
+}
+
+// Activate records this process as the port's proxy and, when upgrading, asks the old
+// proxy to stop accepting connections and drain within drainTimeout. Call it once the
+// listener is being served.
+func (l *ProxyListener) Activate(drainTimeout time.Duration) error {
+	if err := os.MkdirAll(filepath.Dir(l.pidFile), constants.DefaultDirMode); err != nil {
+
+		return fmt.Errorf("failed to create run directory: %w", err)
+	}
+	if err := os.WriteFile(l.pidFile, []byte(strconv.Itoa(os.Getpid())), constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write proxy PID file: %w", err)
+	}
+	if l.oldPID == 0 {
+
+		return nil
+	}
+	if err := os.WriteFile(drainFile(l.port), []byte(drainTimeout.String()), constants.DefaultFileMode); err != nil {
+
+		return fmt.Errorf("failed to write drain timeout: %w", err)
+	}
+	if err := requestDrain(l.oldPID); err != nil {
+
+		return fmt.Errorf("failed to signal proxy %d to drain: %w", l.oldPID, err)
+	}
+
+	return nil
+}
+
+// Release removes the PID file unless a newer proxy has taken it over
+func (l *ProxyListener) Release() {
+	data, err := os.ReadFile(l.pidFile)
+	if err != nil || strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+
+		return
+	}
+	_ = os.Remove(l.pidFile)
+}
+
+// NotifyDrain relays the request a new proxy sends when it takes over the port to c
+func NotifyDrain(c chan<- os.Signal) {
+	notifyDrain(c)
+}
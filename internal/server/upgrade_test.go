@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestListenProxy(t *testing.T) {
+	port := freePort(t)
+	pidFile := ProxyPIDFile(port)
+	if err := os.MkdirAll(filepath.Dir(pidFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Remove(pidFile) })
+
+	if _, err := ListenProxy("127.0.0.1", port, true); err == nil || !strings.Contains(err.Error(), "no proxy is running") {
+		t.Errorf("upgrade without a running proxy: got %v", err)
+	}
+
+	// The test's parent process stands in for a running proxy
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getppid())), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListenProxy("127.0.0.1", port, false); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("second proxy without --upgrade: got %v", err)
+	}
+
+	_ = os.Remove(pidFile)
+	listener, err := ListenProxy("127.0.0.1", port, false)
+	if err != nil {
+		t.Fatalf("ListenProxy: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	if err := listener.Activate(time.Second); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+	if data, _ := os.ReadFile(pidFile); string(data) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("PID file holds %q", data)
+	}
+	listener.Release()
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Errorf("PID file not removed on release")
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	port := freePort(t)
+	if err := os.MkdirAll(filepath.Dir(drainFile(port)), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		contents string
+		want     time.Duration
+	}{
+		{"requested", "2m0s", 2 * time.Minute},
+		{"invalid", "soon", 30 * time.Second},
+		{"missing", "", 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.contents != "" {
+				if err := os.WriteFile(drainFile(port), []byte(tt.contents), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := DrainTimeout(port, 30*time.Second); got != tt.want {
+				t.Errorf("DrainTimeout() = %s, want %s", got, tt.want)
+			}
+			if _, err := os.Stat(drainFile(port)); !os.IsNotExist(err) {
+				t.Errorf("drain file left behind")
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// internal/server/upgrade_unix.go
+
+//go:build !windows
+
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const upgradeSupported = true
+
+// listenShared listens on addr with SO_REUSEPORT, so the next proxy can bind it too
+func listenShared(addr string) (net.Listener, error) {
+	lc := net.ListenConfig{
+		Control: func(_, _ string, conn syscall.RawConn) error {
+			var sockErr error
+			err := conn.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			})
+			if err != nil {
+
+				return err
+			}
+
+			return sockErr
+		},
+	}
+
+	return lc.Listen(context.Background(), "tcp", addr)
+}
+
+// requestDrain sends SIGUSR2 to the proxy with pid
+func requestDrain(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+
+		return err
+	}
+
+	return process.Signal(syscall.SIGUSR2)
+}
+
+func notifyDrain(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR2)
+}
@@ -0,0 +1,23 @@
+// internal/server/upgrade_windows.go
+package server
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+// Windows sockets cannot share a port the way SO_REUSEPORT does, so there is no handover
+const upgradeSupported = false
+
+func listenShared(addr string) (net.Listener, error) {
+
+	return net.Listen("tcp", addr)
+}
+
+func requestDrain(_ int) error {
+
+	return errors.New("proxy handover is not supported on Windows")
+}
+
+func notifyDrain(_ chan<- os.Signal) {}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -90,9 +91,9 @@ func getServerNameFromPath(path string) string {
 	return "proxy"
 }
 
-func initializeOAuth(oauthConfig *config.OAuthConfig, logger *logging.Logger) (*auth.AuthorizationServer, *auth.AuthenticationMiddleware, *auth.ResourceMetadataHandler) {
+func initializeOAuth(oauthConfig *config.OAuthConfig, configFile string, logger *logging.Logger) (*auth.AuthorizationServer, *auth.AuthenticationMiddleware, *auth.ResourceMetadataHandler) {
 	// Use the issuer from config, with a sensible default for container environments
-	defaultIssuer := "http://mcp-compose-http-proxy:9876"
+	defaultIssuer := "http://" + config.ContainerName("http-proxy") + ":9876"
 	if oauthConfig.Issuer != "" {
 		defaultIssuer = oauthConfig.Issuer
 	}
@@ -105,9 +106,10 @@ func initializeOAuth(oauthConfig *config.OAuthConfig, logger *logging.Logger) (*
 		UserinfoEndpoint:                       "/oauth/userinfo",
 		RevocationEndpoint:                     "/oauth/revoke",
 		RegistrationEndpoint:                   "/oauth/register",
-		ScopesSupported:                        []string{"mcp:*", "mcp:tools", "mcp:resources", "mcp:prompts"},
+		DeviceAuthorizationEndpoint:            "/oauth/device_authorization",
+		ScopesSupported:                        []string{"mcp:*", "mcp:tools", "mcp:resources", "mcp:prompts", TasksReadScope, TasksWriteScope, AdminReadScope, AdminWriteScope},
 		ResponseTypesSupported:                 []string{"code"},
-		GrantTypesSupported:                    []string{"authorization_code", "client_credentials", "refresh_token"},
+		GrantTypesSupported:                    []string{"authorization_code", "client_credentials", "refresh_token", auth.DeviceCodeGrantType},
 		TokenEndpointAuthMethodsSupported:      []string{"client_secret_post", "client_secret_basic", "none"},
 		RevocationEndpointAuthMethodsSupported: []string{"client_secret_post", "client_secret_basic", "none"},
 		CodeChallengeMethodsSupported:          []string{"plain", "S256"},
@@ -127,17 +129,88 @@ func initializeOAuth(oauthConfig *config.OAuthConfig, logger *logging.Logger) (*
 	authServers := []string{serverConfig.Issuer}
 	resourceMeta := auth.NewResourceMetadataHandler(authServers, serverConfig.ScopesSupported)
 
+	if oauthConfig.Endpoints.DeviceVerification != "" {
+		authServer.SetDeviceVerificationURI(oauthConfig.Endpoints.DeviceVerification)
+	}
+
+	// Validation has checked the durations
+	accessTTL, _ := time.ParseDuration(oauthConfig.Tokens.AccessTokenTTL)
+	refreshTTL, _ := time.ParseDuration(oauthConfig.Tokens.RefreshTokenTTL)
+	codeTTL, _ := time.ParseDuration(oauthConfig.Tokens.CodeTTL)
+	authServer.SetTokenLifetimes(accessTTL, refreshTTL, codeTTL)
+
+	if auth.SignedTokenAlgorithm(oauthConfig.Tokens.Algorithm) {
+		rotation := constants.OAuthKeyRotation
+		if oauthConfig.Tokens.KeyRotation != "" {
+			rotation, _ = time.ParseDuration(oauthConfig.Tokens.KeyRotation)
+		}
+		keyDir := config.ResolveEnvFile(configFile, oauthConfig.Tokens.SigningKeyDir())
+		signer, err := auth.NewTokenSigner(oauthConfig.Tokens.Algorithm, keyDir, rotation, authServer.TokenLifetime())
+		if err != nil {
+			logger.Error("Signed access tokens disabled, issuing opaque tokens: %v", err)
+		} else {
+			jwksURI := strings.TrimRight(serverConfig.Issuer, "/") + constants.JWKSPath
+			authServer.SetTokenSigner(signer, jwksURI)
+			resourceMeta.SetJWKSUri(jwksURI)
+			logger.Info("Signing access tokens with %s; keys in %s are published at %s", oauthConfig.Tokens.Algorithm, keyDir, jwksURI)
+		}
+	}
+
+	// Failed sign-ins and client authentications lock the caller out for a while.
+	// Validation has checked the durations.
+	if bruteForce := oauthConfig.Security.BruteForce; !bruteForce.Disabled {
+		window, _ := time.ParseDuration(bruteForce.Window)
+		lockout, _ := time.ParseDuration(bruteForce.Lockout)
+		maxLockout, _ := time.ParseDuration(bruteForce.MaxLockout)
+		throttle := auth.NewLoginThrottle(bruteForce.MaxFailures, window, lockout, maxLockout)
+		throttle.OnLockout(publishAuthLockout)
+		authServer.SetLoginThrottle(throttle)
+	}
+
+	// Consents are kept next to the compose file, like quota usage
+	if configFile != "" {
+		if err := authServer.SetConsentFile(filepath.Join(filepath.Dir(configFile), constants.OAuthConsentsFile)); err != nil {
+			logger.Warning("Starting without the saved OAuth consents: %v", err)
+		}
+	}
+
 	return authServer, authMiddleware, resourceMeta
 }
 
 // authenticateRequest handles authentication for server requests
 func (h *ProxyHandler) authenticateRequest(w http.ResponseWriter, r *http.Request, serverName string, instance *ServerInstance) bool {
+	// The server's access list applies before any credentials are looked at
+	if !h.allowedServerAddress(w, r, serverName) {
+
+		return false
+	}
+
 	// Skip authentication for OPTIONS requests
 	if r.Method == "OPTIONS" {
 
 		return true
 	}
 
+	// Identity asserted by a trusted reverse proxy replaces token authentication,
+	// but server-specific scope requirements still apply
+	if handled, ok := h.authenticateTrustedHeaders(w, r); handled {
+		if !ok {
+
+			return false
+		}
+		if instance.Config.Authentication != nil && instance.Config.Authentication.RequiredScope != "" {
+			tokenScope, _ := r.Context().Value(auth.ScopeContextKey).(string)
+			if !h.hasRequiredScope(tokenScope, instance.Config.Authentication.RequiredScope) {
+				publishAuthDenied(r, serverName, "required scope not granted")
+				h.sendOAuthError(w, "insufficient_scope", "Required scope not granted: "+instance.Config.Authentication.RequiredScope)
+
+				return false
+			}
+		}
+
+		return true
+	}
+
 	var authenticatedViaOAuth bool
 	var authenticatedViaAPIKey bool
 	var requiresAuth bool
@@ -160,6 +233,7 @@ func (h *ProxyHandler) authenticateRequest(w http.ResponseWriter, r *http.Reques
 	token := h.extractBearerToken(r)
 	if token == "" {
 		if requiresAuth && (instance.Config.Authentication == nil || !instance.Config.Authentication.OptionalAuth) {
+			publishAuthDenied(r, serverName, "access token required")
 			h.sendAuthenticationError(w, "missing_token", "Access token required")
 
 			return false
@@ -176,6 +250,7 @@ func (h *ProxyHandler) authenticateRequest(w http.ResponseWriter, r *http.Reques
 			// Check server-specific OAuth scope requirements
 			if instance.Config.Authentication != nil && instance.Config.Authentication.RequiredScope != "" {
 				if !h.hasRequiredScope(accessToken.Scope, instance.Config.Authentication.RequiredScope) {
+					publishAuthDenied(r, serverName, "required scope not granted")
 					h.sendOAuthError(w, "insufficient_scope", "Required scope not granted: "+instance.Config.Authentication.RequiredScope)
 
 					return false
@@ -216,6 +291,7 @@ func (h *ProxyHandler) authenticateRequest(w http.ResponseWriter, r *http.Reques
 			*instance.Config.Authentication.AllowAPIKey
 
 		if !allowAPIKey {
+			publishAuthDenied(r, serverName, "OAuth required, API key not allowed")
 			h.sendOAuthError(w, "invalid_token", "OAuth authentication required (API key not allowed)")
 
 			return false
@@ -224,6 +300,7 @@ func (h *ProxyHandler) authenticateRequest(w http.ResponseWriter, r *http.Reques
 
 	// Authentication failed
 	if requiresAuth && !authenticatedViaOAuth && !authenticatedViaAPIKey {
+		h.authFailed(r, serverName, "invalid access token or API key")
 		if h.oauthEnabled {
 			h.sendOAuthError(w, "invalid_token", "Invalid access token or API key")
 		} else {
@@ -235,6 +312,7 @@ func (h *ProxyHandler) authenticateRequest(w http.ResponseWriter, r *http.Reques
 
 	// Check if server requires authentication but none was provided
 	if oauthRequired && !instance.Config.Authentication.OptionalAuth && !authenticatedViaOAuth && !authenticatedViaAPIKey {
+		publishAuthDenied(r, serverName, "authentication required")
 		h.sendOAuthError(w, "access_denied", "Authentication required for this server")
 
 		return false
@@ -271,16 +349,62 @@ func (h *ProxyHandler) validateOAuthToken(token string) (*auth.AccessToken, erro
 func (h *ProxyHandler) hasRequiredScope(tokenScope, requiredScope string) bool {
 	if h.authServer == nil {
 
-		return false
+		return auth.ScopeGranted(tokenScope, requiredScope)
 	}
 
 	return h.authServer.HasScope(tokenScope, requiredScope)
 }
 
+// authenticateTrustedHeaders accepts an identity asserted by a trusted reverse proxy.
+// handled is true when the request carried trusted identity headers, in which case ok
+// reports whether access was granted; a refusal has already been written to w.
+func (h *ProxyHandler) authenticateTrustedHeaders(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	if isTrustedHeaderRequest(r) {
+
+		return true, true
+	}
+
+	identity, err := h.trustedHeaderAuth.Authenticate(r)
+	if err != nil {
+		h.logger.Warning("Refusing identity asserted by %s: %v", r.RemoteAddr, err)
+		publishAuthDenied(r, "", err.Error())
+		h.corsError(w, "Forbidden", http.StatusForbidden)
+
+		return true, false
+	}
+	if identity == nil {
+
+		return false, false
+	}
+
+	*r = *r.WithContext(identity.WithContext(r.Context()))
+	h.logger.Debug("Request authenticated via trusted headers as %s (role: %s)", identity.Username, identity.Role)
+
+	return true, true
+}
+
+// isTrustedHeaderRequest reports whether r was already authenticated by a trusted reverse proxy
+func isTrustedHeaderRequest(r *http.Request) bool {
+	authType, _ := r.Context().Value(auth.AuthTypeContextKey).(string)
+
+	return authType == auth.AuthTypeTrustedHeader
+}
+
+// composeConfig returns the configuration the manager is running with, or nil without one.
+// Callers read it once, as a config edit may replace it at any time.
+func (h *ProxyHandler) composeConfig() *config.ComposeConfig {
+	if h.Manager == nil {
+
+		return nil
+	}
+
+	return h.Manager.Config()
+}
+
 func (h *ProxyHandler) getAPIKeyToCheck() string {
 	var apiKeyToCheck string
-	if h.Manager != nil && h.Manager.config != nil && h.Manager.config.ProxyAuth.Enabled {
-		apiKeyToCheck = h.Manager.config.ProxyAuth.APIKey
+	if cfg := h.composeConfig(); cfg != nil && cfg.ProxyAuth.Enabled {
+		apiKeyToCheck = cfg.ProxyAuth.APIKey
 	}
 	if h.APIKey != "" {
 		apiKeyToCheck = h.APIKey
@@ -344,8 +468,8 @@ func (h *ProxyHandler) registerDefaultOAuthClients() {
 	}
 
 	// Register any clients from config
-	if h.Manager != nil && h.Manager.config != nil && h.Manager.config.OAuthClients != nil {
-		for name, clientConfig := range h.Manager.config.OAuthClients {
+	if cfg := h.composeConfig(); cfg != nil && cfg.OAuthClients != nil {
+		for name, clientConfig := range cfg.OAuthClients {
 			// Handle client secret pointer properly
 			var clientSecret string
 			if clientConfig.ClientSecret != nil {
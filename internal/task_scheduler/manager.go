@@ -41,7 +41,7 @@ func (m *Manager) Start() error {
 		})
 
 	// Check if already running
-	status, err := m.runtime.GetContainerStatus("mcp-compose-task-scheduler")
+	status, err := m.runtime.GetContainerStatus(config.ContainerName("task-scheduler"))
 	if err == nil && status == "running" {
 		dashboard.BroadcastActivity("WARN", "service", "task-scheduler", "",
 			"Task scheduler is already running",
@@ -85,13 +85,13 @@ func (m *Manager) Start() error {
 	}
 
 	// Ensure network exists
-	networkExists, _ := m.runtime.NetworkExists("mcp-net")
+	networkExists, _ := m.runtime.NetworkExists(config.DefaultNetwork())
 	if !networkExists {
 		dashboard.BroadcastActivity("INFO", "network", "task-scheduler", "",
 			"Creating mcp-net network...",
 			nil)
 
-		if err := m.runtime.CreateNetwork("mcp-net"); err != nil {
+		if err := m.runtime.CreateNetwork(config.DefaultNetwork()); err != nil {
 			dashboard.BroadcastActivity("ERROR", "network", "task-scheduler", "",
 				"Failed to create mcp-net network",
 				map[string]interface{}{
@@ -116,12 +116,12 @@ func (m *Manager) Start() error {
 
 	// Container options
 	opts := &container.ContainerOptions{
-		Name:     "mcp-compose-task-scheduler",
+		Name:     config.ContainerName("task-scheduler"),
 		Image:    "mcp-compose-task-scheduler:latest",
 		Ports:    []string{fmt.Sprintf("%d:%d", m.config.TaskScheduler.Port, m.config.TaskScheduler.Port)},
 		Env:      env,
-		Networks: []string{"mcp-net"},
-		Volumes:  volumes,
+		Networks: []string{config.DefaultNetwork()},
+		Volumes:  m.config.NamespaceMounts(volumes),
 		User:     "root",
 		CPUs:     m.config.TaskScheduler.CPUs,
 		Memory:   m.config.TaskScheduler.Memory,
@@ -198,7 +198,7 @@ func (m *Manager) Stop() error {
 		"Stopping task scheduler service...",
 		nil)
 
-	if err := m.runtime.StopContainer("mcp-compose-task-scheduler"); err != nil {
+	if err := m.runtime.StopContainer(config.ContainerName("task-scheduler")); err != nil {
 		dashboard.BroadcastActivity("ERROR", "service", "task-scheduler", "",
 			"Failed to stop task scheduler container",
 			map[string]interface{}{
@@ -247,7 +247,7 @@ func (m *Manager) Restart() error {
 
 // Status returns the current status of the task scheduler
 func (m *Manager) Status() (string, error) {
-	status, err := m.runtime.GetContainerStatus("mcp-compose-task-scheduler")
+	status, err := m.runtime.GetContainerStatus(config.ContainerName("task-scheduler"))
 	if err != nil {
 
 		return "stopped", nil
@@ -258,7 +258,7 @@ func (m *Manager) Status() (string, error) {
 
 // IsRunning checks if the task scheduler is currently running
 func (m *Manager) IsRunning() bool {
-	status, err := m.runtime.GetContainerStatus("mcp-compose-task-scheduler")
+	status, err := m.runtime.GetContainerStatus(config.ContainerName("task-scheduler"))
 
 	return err == nil && status == "running"
 }
@@ -275,7 +275,7 @@ func (m *Manager) buildImage() error {
 // buildEnvironment builds the environment variables map
 func (m *Manager) buildEnvironment() map[string]string {
 	env := map[string]string{
-		"TZ":                                 "America/New_York",
+		"TZ":                                 m.config.ProjectTimezone(),
 		"MCP_CRON_SERVER_TRANSPORT":          "sse",
 		"MCP_CRON_SERVER_ADDRESS":            m.config.TaskScheduler.Host,
 		"MCP_CRON_SERVER_PORT":               fmt.Sprintf("%d", m.config.TaskScheduler.Port),
@@ -284,6 +284,7 @@ func (m *Manager) buildEnvironment() map[string]string {
 		"MCP_CRON_LOGGING_LEVEL":             m.config.TaskScheduler.LogLevel,
 		"MCP_CRON_SCHEDULER_DEFAULT_TIMEOUT": "10m",
 	}
+	m.config.ApplyLocale("task-scheduler", env)
 
 	// Add activity broadcasting configuration
 	env["MCP_CRON_ACTIVITY_WEBHOOK"] = "http://mcp-compose-dashboard:3001/api/activity"
@@ -347,5 +348,5 @@ func (m *Manager) waitForHealthy(timeout time.Duration) error {
 // GetLogs retrieves logs from the task scheduler container
 func (m *Manager) GetLogs(follow bool) error {
 
-	return m.runtime.ShowContainerLogs("mcp-compose-task-scheduler", follow)
+	return m.runtime.ShowContainerLogs(config.ContainerName("task-scheduler"), follow)
 }
@@ -0,0 +1,207 @@
+// internal/telemetry/tracer.go
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+	"github.com/phildougherty/mcp-compose/internal/constants"
+	"github.com/phildougherty/mcp-compose/internal/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanKind is the OpenTelemetry span kind
+type SpanKind = trace.SpanKind
+
+const (
+	SpanKindInternal = trace.SpanKindInternal
+	SpanKindServer   = trace.SpanKindServer
+	SpanKindClient   = trace.SpanKindClient
+)
+
+const (
+	otlpTracesPath      = "/v1/traces"
+	instrumentationName = "github.com/phildougherty/mcp-compose"
+)
+
+// propagator reads and writes the W3C traceparent and tracestate headers
+var propagator = propagation.TraceContext{}
+
+// Span is an in-flight operation. A nil *Span is a valid no-op span.
+type Span struct {
+	span trace.Span
+}
+
+// Tracer starts spans on an OpenTelemetry SDK provider that batches them to an OTLP/HTTP collector.
+// A nil *Tracer is valid and records nothing, but still propagates incoming context.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+	logger   *logging.Logger
+}
+
+// NewTracer returns a tracer exporting to cfg.OTLPEndpoint, or nil when tracing is not configured
+func NewTracer(cfg config.ObservabilityConfig, defaultServiceName string, logger *logging.Logger) *Tracer {
+	if cfg.OTLPEndpoint == "" {
+
+		return nil
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+
+	endpoint := otlpTracesURL(cfg.OTLPEndpoint)
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithHeaders(cfg.Headers),
+		otlptracehttp.WithTimeout(constants.HTTPQuickTimeout),
+	)
+	if err != nil {
+		if logger != nil {
+			logger.Error("Failed to create the OTLP trace exporter for %s: %v", endpoint, err)
+		}
+
+		return nil
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithBatchTimeout(constants.TraceExportInterval),
+			sdktrace.WithMaxExportBatchSize(constants.TraceExportBatchSize),
+			sdktrace.WithMaxQueueSize(constants.TraceExportQueueSize),
+		),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+	if logger != nil {
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			logger.Warning("OpenTelemetry export to %s failed: %v", endpoint, err)
+		}))
+		logger.Info("OpenTelemetry tracing enabled for %s, exporting to %s", serviceName, endpoint)
+	}
+
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer(instrumentationName),
+		logger:   logger,
+	}
+}
+
+// otlpTracesURL appends the standard traces path when the endpoint is just a host
+func otlpTracesURL(endpoint string) string {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Path == "" || parsed.Path == "/" {
+
+		return strings.TrimRight(endpoint, "/") + otlpTracesPath
+	}
+
+	return endpoint
+}
+
+// StartSpan starts a span as a child of any span in ctx and returns a context carrying it
+func (t *Tracer) StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if t == nil {
+
+		return ctx, nil
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(kind))
+
+	return ctx, &Span{span: span}
+}
+
+// Shutdown flushes pending spans
+func (t *Tracer) Shutdown() {
+	if t == nil {
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.HTTPQuickTimeout)
+	defer cancel()
+	if err := t.provider.Shutdown(ctx); err != nil && t.logger != nil {
+		t.logger.Warning("Failed to flush pending spans: %v", err)
+	}
+}
+
+// SetAttribute records a key/value on the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+
+		return
+	}
+	s.span.SetAttributes(attributeOf(key, value))
+}
+
+// SetError marks the span as failed without ending it
+func (s *Span) SetError(message string) {
+	if s == nil || message == "" {
+
+		return
+	}
+	s.span.SetStatus(codes.Error, message)
+}
+
+// End finishes the span, marking it failed when err is non-nil
+func (s *Span) End(err error) {
+	if s == nil {
+
+		return
+	}
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+func attributeOf(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+
+		return attribute.String(key, v)
+	case int:
+
+		return attribute.Int(key, v)
+	case int64:
+
+		return attribute.Int64(key, v)
+	case float64:
+
+		return attribute.Float64(key, v)
+	case bool:
+
+		return attribute.Bool(key, v)
+	default:
+
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+// Extract reads the W3C trace context headers into ctx so new spans join the caller's trace
+func Extract(ctx context.Context, headers http.Header) context.Context {
+
+	return propagator.Extract(ctx, propagation.HeaderCarrier(headers))
+}
+
+// Inject writes the current span context to headers as W3C trace context
+func Inject(ctx context.Context, headers http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(headers))
+}
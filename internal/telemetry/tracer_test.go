@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/phildougherty/mcp-compose/internal/config"
+)
+
+const incomingTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestOTLPTracesURL(t *testing.T) {
+	tests := map[string]string{
+		"tempo:4318":                     "http://tempo:4318/v1/traces",
+		"http://tempo:4318/":             "http://tempo:4318/v1/traces",
+		"https://collector.example/otlp": "https://collector.example/otlp",
+		"https://collector.example:4318": "https://collector.example:4318/v1/traces",
+	}
+	for endpoint, expected := range tests {
+		if got := otlpTracesURL(endpoint); got != expected {
+			t.Errorf("otlpTracesURL(%q) = %q, expected %q", endpoint, got, expected)
+		}
+	}
+}
+
+func TestNilTracerPropagatesIncomingContext(t *testing.T) {
+	var tracer *Tracer
+	incoming := http.Header{}
+	incoming.Set("traceparent", incomingTraceparent)
+
+	ctx, span := tracer.StartSpan(Extract(context.Background(), incoming), "test", SpanKindServer)
+	span.SetAttribute("key", "value")
+	span.End(errors.New("ignored"))
+	tracer.Shutdown()
+
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+	if outgoing.Get("traceparent") != incomingTraceparent {
+		t.Errorf("Expected the incoming traceparent to be forwarded, got %q", outgoing.Get("traceparent"))
+	}
+}
+
+func TestTracerExportsToCollector(t *testing.T) {
+	var exports atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" && r.Header.Get("X-Scope-OrgID") == "tenant" {
+			exports.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	tracer := NewTracer(config.ObservabilityConfig{
+		OTLPEndpoint: collector.URL,
+		Headers:      map[string]string{"X-Scope-OrgID": "tenant"},
+	}, "test", nil)
+	if tracer == nil {
+		t.Fatal("Expected a tracer when an endpoint is configured")
+	}
+
+	incoming := http.Header{}
+	incoming.Set("traceparent", incomingTraceparent)
+	ctx, span := tracer.StartSpan(Extract(context.Background(), incoming), "test", SpanKindServer)
+	span.SetAttribute("http.status_code", http.StatusOK)
+
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+	parts := strings.Split(outgoing.Get("traceparent"), "-")
+	if len(parts) != 4 || parts[1] != "4bf92f3577b34da6a3ce929d0e0e4736" || parts[2] == "00f067aa0ba902b7" {
+		t.Errorf("Expected a child span in the caller's trace, got %q", outgoing.Get("traceparent"))
+	}
+
+	span.End(nil)
+	tracer.Shutdown()
+	if exports.Load() == 0 {
+		t.Error("Expected Shutdown to flush the span to the collector")
+	}
+}
+
+func TestNoTracerWithoutEndpoint(t *testing.T) {
+	if tracer := NewTracer(config.ObservabilityConfig{}, "test", nil); tracer != nil {
+		t.Error("Expected no tracer when no endpoint is configured")
+	}
+}